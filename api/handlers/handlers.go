@@ -18,26 +18,83 @@ type Handlers struct {
 	*core.UserHandler
 	*core.UserAdminHandler
 	*core.UserSuperAdminHandler
+	*core.UserInvitationHandler
+	*core.UserEmailChangeHandler
+	*core.PhoneVerificationHandler
+	*core.UserOnboardingHandler
+	*core.AccountDeletionHandler
 	*core.ClientApplicationHandler
+	*core.TenantClientApplicationHandler
 	*core.TranslationHandler
 	*core.RecoveryHandler
 	*core.MFAHandler
+	*core.TOTPHandler
+	*core.SessionHandler
+	*core.AnnouncementHandler
+	*core.TenantExportHandler
+	*core.JobHandler
+	*core.DiagnosticsHandler
+	*core.DelegationHandler
+	*core.AuthFailureHandler
+	*core.SLAHandler
+	*core.LLMConsentHandler
+	*core.DirectorySyncHandler
+	*core.LockoutHandler
+	*core.GroupHandler
+	*core.UserAttributeHandler
+	*core.FileEncryptionHandler
+	*core.RoleHandler
+	*core.EmailTemplateHandler
+	*core.TenantQuotaHandler
+	*core.TenantUsageHandler
+	*core.FeatureFlagHandler
+	*core.TenantEmailProviderHandler
+	*core.ExecutionWebhookHandler
 }
 
 func CreateCoreHandlers(connPool *pgxpool.Pool, authClientPool auth.AuthProvider, multiTenantService *access.MultitenantService, clientAppService *access.ClientApplicationService) Handlers {
 	store := db.NewStore(connPool)
+	clientApplicationHandler := core.NewClientApplicationHandler(store, clientAppService)
 	handlers := Handlers{
-		GlobalConfigHandler:      config.NewGlobalConfigHandler(store, authClientPool),
-		TenantConfigHandler:      config.NewTenantConfigHandler(store, authClientPool),
-		HealthHandler:            health.NewHealthHandler(store),
-		TenantHandler:            core.NewTenantHandler(store, authClientPool, multiTenantService),
-		UserHandler:              core.NewUserHandler(store, authClientPool),
-		UserAdminHandler:         core.NewUserAdminHandler(store, authClientPool),
-		UserSuperAdminHandler:    core.NewUserSuperAdminHandler(store, authClientPool),
-		ClientApplicationHandler: core.NewClientApplicationHandler(store, clientAppService),
-		TranslationHandler:       core.NewTranslationHandler(store),
-		RecoveryHandler:          core.NewRecoveryHandler(authClientPool),
-		MFAHandler:               core.NewMFAHandler(authClientPool),
+		GlobalConfigHandler:            config.NewGlobalConfigHandler(store, authClientPool),
+		TenantConfigHandler:            config.NewTenantConfigHandler(store, authClientPool),
+		HealthHandler:                  health.NewHealthHandler(store),
+		TenantHandler:                  core.NewTenantHandler(store, authClientPool, multiTenantService),
+		UserHandler:                    core.NewUserHandler(store, authClientPool),
+		UserAdminHandler:               core.NewUserAdminHandler(store, authClientPool),
+		UserSuperAdminHandler:          core.NewUserSuperAdminHandler(store, authClientPool),
+		UserInvitationHandler:          core.NewUserInvitationHandler(store, authClientPool),
+		UserEmailChangeHandler:         core.NewUserEmailChangeHandler(store, authClientPool),
+		PhoneVerificationHandler:       core.NewPhoneVerificationHandler(store),
+		UserOnboardingHandler:          core.NewUserOnboardingHandler(store),
+		AccountDeletionHandler:         core.NewAccountDeletionHandler(store, authClientPool),
+		ClientApplicationHandler:       clientApplicationHandler,
+		TenantClientApplicationHandler: core.NewTenantClientApplicationHandler(clientApplicationHandler),
+		TranslationHandler:             core.NewTranslationHandler(store),
+		RecoveryHandler:                core.NewRecoveryHandler(authClientPool),
+		MFAHandler:                     core.NewMFAHandler(authClientPool),
+		TOTPHandler:                    core.NewTOTPHandler(store, authClientPool),
+		SessionHandler:                 core.NewSessionHandler(store, authClientPool),
+		AnnouncementHandler:            core.NewAnnouncementHandler(store),
+		TenantExportHandler:            core.NewTenantExportHandler(store),
+		JobHandler:                     core.NewJobHandler(store),
+		DiagnosticsHandler:             core.NewDiagnosticsHandler(store),
+		DelegationHandler:              core.NewDelegationHandler(store),
+		AuthFailureHandler:             core.NewAuthFailureHandler(store),
+		SLAHandler:                     core.NewSLAHandler(store),
+		LLMConsentHandler:              core.NewLLMConsentHandler(store),
+		DirectorySyncHandler:           core.NewDirectorySyncHandler(store, authClientPool),
+		LockoutHandler:                 core.NewLockoutHandler(store, authClientPool),
+		GroupHandler:                   core.NewGroupHandler(store),
+		UserAttributeHandler:           core.NewUserAttributeHandler(store),
+		FileEncryptionHandler:          core.NewFileEncryptionHandler(store),
+		RoleHandler:                    core.NewRoleHandler(store),
+		EmailTemplateHandler:           core.NewEmailTemplateHandler(store),
+		TenantQuotaHandler:             core.NewTenantQuotaHandler(store),
+		TenantUsageHandler:             core.NewTenantUsageHandler(store),
+		FeatureFlagHandler:             core.NewFeatureFlagHandler(store),
+		TenantEmailProviderHandler:     core.NewTenantEmailProviderHandler(store),
+		ExecutionWebhookHandler:        core.NewExecutionWebhookHandler(store),
 	}
 	return handlers
 }
@@ -1,6 +1,18 @@
 package helpers
 
-import sqlservice "ctoup.com/coreapp/pkg/shared/sql"
+import (
+	"strconv"
+
+	sqlservice "ctoup.com/coreapp/pkg/shared/sql"
+	"github.com/gin-gonic/gin"
+)
+
+// Response headers carrying the pagination of a list
+const (
+	TotalCountHeader = "X-Total-Count"
+	PageHeader       = "X-Page"
+	PageSizeHeader   = "X-Page-Size"
+)
 
 type PagingRequest struct {
 	Page            *int32 `form:"page" binding:"required,min=1"`
@@ -48,3 +60,20 @@ func GetPagingSQL(pagingRequest PagingRequest) sqlservice.PagingSQL {
 		Order:    order,
 	}
 }
+
+// SetPagingHeaders sets the total number of items and the page returned, and
+// exposes these headers to browsers
+func SetPagingHeaders(c *gin.Context, total int64, paging sqlservice.PagingSQL) {
+	c.Header(TotalCountHeader, strconv.FormatInt(total, 10))
+	c.Header(PageHeader, strconv.FormatInt(int64(PageOf(paging)), 10))
+	c.Header(PageSizeHeader, strconv.FormatInt(int64(paging.PageSize), 10))
+	c.Writer.Header().Add("Access-Control-Expose-Headers", TotalCountHeader+", "+PageHeader+", "+PageSizeHeader)
+}
+
+// PageOf returns the page number of the paging, 1 for an empty page size
+func PageOf(paging sqlservice.PagingSQL) int32 {
+	if paging.PageSize <= 0 {
+		return 1
+	}
+	return paging.Offset/paging.PageSize + 1
+}
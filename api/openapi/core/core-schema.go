@@ -18,10 +18,29 @@ const (
 
 // Defines values for APITokenAuditLogAction.
 const (
-	APITokenAuditLogActionCREATED APITokenAuditLogAction = "CREATED"
-	APITokenAuditLogActionREVOKED APITokenAuditLogAction = "REVOKED"
-	APITokenAuditLogActionUPDATED APITokenAuditLogAction = "UPDATED"
-	APITokenAuditLogActionUSED    APITokenAuditLogAction = "USED"
+	APITokenAuditLogActionCREATED       APITokenAuditLogAction = "CREATED"
+	APITokenAuditLogActionDENIEDIP      APITokenAuditLogAction = "DENIED_IP"
+	APITokenAuditLogActionEXPIRED       APITokenAuditLogAction = "EXPIRED"
+	APITokenAuditLogActionEXPIRYWARNING APITokenAuditLogAction = "EXPIRY_WARNING"
+	APITokenAuditLogActionREVOKED       APITokenAuditLogAction = "REVOKED"
+	APITokenAuditLogActionTHROTTLED     APITokenAuditLogAction = "THROTTLED"
+	APITokenAuditLogActionUPDATED       APITokenAuditLogAction = "UPDATED"
+	APITokenAuditLogActionUSED          APITokenAuditLogAction = "USED"
+)
+
+// Defines values for AnnouncementSeverity.
+const (
+	Critical AnnouncementSeverity = "critical"
+	Info     AnnouncementSeverity = "info"
+	Warning  AnnouncementSeverity = "warning"
+)
+
+// Defines values for BulkUserOperationAction.
+const (
+	AssignRole BulkUserOperationAction = "assign-role"
+	Delete     BulkUserOperationAction = "delete"
+	Disable    BulkUserOperationAction = "disable"
+	Enable     BulkUserOperationAction = "enable"
 )
 
 // Defines values for CheckDetailsStatus.
@@ -31,6 +50,20 @@ const (
 	CheckDetailsStatusWarn CheckDetailsStatus = "warn"
 )
 
+// Defines values for DirectoryProvider.
+const (
+	GoogleWorkspace DirectoryProvider = "google_workspace"
+	Microsoft365    DirectoryProvider = "microsoft_365"
+)
+
+// Defines values for DisplayNameViolationCode.
+const (
+	BannedWord DisplayNameViolationCode = "banned_word"
+	Taken      DisplayNameViolationCode = "taken"
+	TooLong    DisplayNameViolationCode = "too_long"
+	TooShort   DisplayNameViolationCode = "too_short"
+)
+
 // Defines values for HealthResponseChecksStatus.
 const (
 	HealthResponseChecksStatusFail HealthResponseChecksStatus = "fail"
@@ -51,6 +84,27 @@ const (
 	Aal2 MFAStatusAal = "aal2"
 )
 
+// Defines values for MembershipDriftKind.
+const (
+	MembershipWithoutUser MembershipDriftKind = "membership_without_user"
+	MissingMembership     MembershipDriftKind = "missing_membership"
+	UnknownTenant         MembershipDriftKind = "unknown_tenant"
+	UserWithoutTenant     MembershipDriftKind = "user_without_tenant"
+)
+
+// Defines values for OAuthTokenRequestGrantType.
+const (
+	ClientCredentials OAuthTokenRequestGrantType = "client_credentials"
+)
+
+// Defines values for PermissionDelegationStatus.
+const (
+	PermissionDelegationStatusACTIVE    PermissionDelegationStatus = "ACTIVE"
+	PermissionDelegationStatusEXPIRED   PermissionDelegationStatus = "EXPIRED"
+	PermissionDelegationStatusREVOKED   PermissionDelegationStatus = "REVOKED"
+	PermissionDelegationStatusSCHEDULED PermissionDelegationStatus = "SCHEDULED"
+)
+
 // Defines values for Role.
 const (
 	ADMIN         Role = "ADMIN"
@@ -59,14 +113,99 @@ const (
 	USER          Role = "USER"
 )
 
+// Defines values for TenantExportDataset.
+const (
+	Audit TenantExportDataset = "audit"
+	Usage TenantExportDataset = "usage"
+)
+
+// Defines values for TenantExportDestination.
+const (
+	Bucket   TenantExportDestination = "bucket"
+	Download TenantExportDestination = "download"
+)
+
+// Defines values for TenantExportFormat.
+const (
+	Csv   TenantExportFormat = "csv"
+	Jsonl TenantExportFormat = "jsonl"
+	Ocsf  TenantExportFormat = "ocsf"
+)
+
+// Defines values for TenantExportFrequency.
+const (
+	Daily  TenantExportFrequency = "daily"
+	Weekly TenantExportFrequency = "weekly"
+)
+
+// Defines values for TenantExportStatus.
+const (
+	Completed TenantExportStatus = "completed"
+	Failed    TenantExportStatus = "failed"
+	Pending   TenantExportStatus = "pending"
+	Running   TenantExportStatus = "running"
+)
+
+// Defines values for TenantIsolationProbeResultOutcome.
+const (
+	TenantIsolationProbeResultOutcomeBlocked TenantIsolationProbeResultOutcome = "blocked"
+	TenantIsolationProbeResultOutcomeError   TenantIsolationProbeResultOutcome = "error"
+	TenantIsolationProbeResultOutcomeLeaked  TenantIsolationProbeResultOutcome = "leaked"
+	TenantIsolationProbeResultOutcomeSkipped TenantIsolationProbeResultOutcome = "skipped"
+)
+
+// Defines values for TenantSettingsChangeOp.
+const (
+	Add    TenantSettingsChangeOp = "add"
+	Change TenantSettingsChangeOp = "change"
+	Remove TenantSettingsChangeOp = "remove"
+)
+
+// Defines values for TenantStatus.
+const (
+	Active          TenantStatus = "active"
+	Archived        TenantStatus = "archived"
+	PendingDeletion TenantStatus = "pending_deletion"
+	Suspended       TenantStatus = "suspended"
+)
+
+// Defines values for TenantUsageRecommendationSeverity.
+const (
+	LimitApproaching TenantUsageRecommendationSeverity = "limit_approaching"
+	LimitReached     TenantUsageRecommendationSeverity = "limit_reached"
+)
+
+// Defines values for TokenIntrospectionResponseTokenType.
+const (
+	AccessToken TokenIntrospectionResponseTokenType = "access_token"
+	ApiToken    TokenIntrospectionResponseTokenType = "api_token"
+)
+
 // Defines values for UserActionSchemaName.
 const (
 	DISABLED      UserActionSchemaName = "DISABLED"
 	EMAILVERIFIED UserActionSchemaName = "EMAIL_VERIFIED"
 )
 
+// Defines values for UserInvitationStatus.
+const (
+	UserInvitationStatusAccepted UserInvitationStatus = "accepted"
+	UserInvitationStatusExpired  UserInvitationStatus = "expired"
+	UserInvitationStatusPending  UserInvitationStatus = "pending"
+	UserInvitationStatusRevoked  UserInvitationStatus = "revoked"
+)
+
+// Defines values for UserMergeMembershipAction.
+const (
+	Merged UserMergeMembershipAction = "merged"
+	Moved  UserMergeMembershipAction = "moved"
+)
+
 // APIToken defines model for APIToken.
 type APIToken struct {
+	// AllowedCidrs Client IP allowlist in CIDR notation, any address is allowed when empty
+	AllowedCidrs *[]string `json:"allowedCidrs,omitempty"`
+
 	// ClientApplicationId ID of the client application this token belongs to
 	ClientApplicationId openapi_types.UUID `json:"clientApplicationId"`
 
@@ -86,6 +225,12 @@ type APIToken struct {
 	LastUsedIp *string `json:"lastUsedIp,omitempty"`
 	Name       string  `json:"name"`
 
+	// RateLimitPerHour Maximum requests per hour, unlimited when null
+	RateLimitPerHour *int32 `json:"rateLimitPerHour"`
+
+	// RateLimitPerMinute Maximum requests per minute, unlimited when null
+	RateLimitPerMinute *int32 `json:"rateLimitPerMinute"`
+
 	// Revoked Whether this token has been revoked
 	Revoked bool `json:"revoked"`
 
@@ -98,6 +243,9 @@ type APIToken struct {
 	// RevokedReason Revoked reason
 	RevokedReason *string `json:"revokedReason,omitempty"`
 
+	// ScopeTemplateId Scope template the token takes its scopes from, exclusive with scopes
+	ScopeTemplateId *openapi_types.UUID `json:"scopeTemplateId,omitempty"`
+
 	// Scopes Permission scopes for this token
 	Scopes *[]string      `json:"scopes"`
 	Status APITokenStatus `json:"status"`
@@ -126,6 +274,12 @@ type APITokenAuditLog struct {
 // APITokenAuditLogAction defines model for APITokenAuditLog.Action.
 type APITokenAuditLogAction string
 
+// APITokenBulkRevokeResult defines model for APITokenBulkRevokeResult.
+type APITokenBulkRevokeResult struct {
+	RevokedCount int                  `json:"revokedCount"`
+	TokenIds     []openapi_types.UUID `json:"tokenIds"`
+}
+
 // APITokenCreated defines model for APITokenCreated.
 type APITokenCreated struct {
 	ApiToken APIToken `json:"apiToken"`
@@ -134,11 +288,147 @@ type APITokenCreated struct {
 	Token string `json:"token"`
 }
 
+// APITokenDailyUsage defines model for APITokenDailyUsage.
+type APITokenDailyUsage struct {
+	Day       openapi_types.Date `json:"day"`
+	Requests  int64              `json:"requests"`
+	UniqueIps int64              `json:"uniqueIps"`
+}
+
+// APITokenEndpointUsage defines model for APITokenEndpointUsage.
+type APITokenEndpointUsage struct {
+	Method string `json:"method"`
+
+	// Path Route template of the endpoint
+	Path     string `json:"path"`
+	Requests int64  `json:"requests"`
+}
+
+// APITokenIPAllowlist defines model for APITokenIPAllowlist.
+type APITokenIPAllowlist struct {
+	// AllowedCidrs Client IP allowlist in CIDR notation, an empty list allows any address
+	AllowedCidrs []string `json:"allowedCidrs"`
+}
+
 // APITokenRevoke defines model for APITokenRevoke.
 type APITokenRevoke struct {
 	Reason string `json:"reason"`
 }
 
+// APITokenUpdate defines model for APITokenUpdate.
+type APITokenUpdate struct {
+	// ExpiresAt New expiry, in the future and not after the current one
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Scopes New scopes of the token, each granted by its current scopes
+	Scopes *[]string `json:"scopes,omitempty"`
+}
+
+// APITokenUsage defines model for APITokenUsage.
+type APITokenUsage struct {
+	Daily         []APITokenDailyUsage    `json:"daily"`
+	From          time.Time               `json:"from"`
+	To            time.Time               `json:"to"`
+	TopEndpoints  []APITokenEndpointUsage `json:"topEndpoints"`
+	TotalRequests int64                   `json:"totalRequests"`
+	UniqueIps     int64                   `json:"uniqueIps"`
+}
+
+// AcceptUserInvitation defines model for AcceptUserInvitation.
+type AcceptUserInvitation struct {
+	Name string `json:"name"`
+
+	// Password Required unless the address already has an account
+	Password *string `json:"password,omitempty"`
+	Token    string  `json:"token"`
+}
+
+// AccountDeletion defines model for AccountDeletion.
+type AccountDeletion struct {
+	// ScheduledFor When the account is erased, unless the deletion is cancelled
+	ScheduledFor time.Time `json:"scheduledFor"`
+
+	// Status scheduled or cancelled
+	Status string `json:"status"`
+}
+
+// AccountDeletionToken defines model for AccountDeletionToken.
+type AccountDeletionToken struct {
+	// Token Token of the link sent by email
+	Token string `json:"token"`
+}
+
+// Announcement defines model for Announcement.
+type Announcement struct {
+	CreatedAt time.Time            `json:"createdAt"`
+	CreatedBy string               `json:"createdBy"`
+	EndsAt    time.Time            `json:"endsAt"`
+	Id        openapi_types.UUID   `json:"id"`
+	Message   string               `json:"message"`
+	Severity  AnnouncementSeverity `json:"severity"`
+	StartsAt  time.Time            `json:"startsAt"`
+
+	// TenantId If null, this is a global announcement shown on every tenant
+	TenantId  *string   `json:"tenantId"`
+	Title     string    `json:"title"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AnnouncementSeverity defines model for AnnouncementSeverity.
+type AnnouncementSeverity string
+
+// AuthFailureHourlyCount defines model for AuthFailureHourlyCount.
+type AuthFailureHourlyCount struct {
+	Count  int64     `json:"count"`
+	Hour   time.Time `json:"hour"`
+	Reason string    `json:"reason"`
+}
+
+// AuthFailureReasonCount defines model for AuthFailureReasonCount.
+type AuthFailureReasonCount struct {
+	Count int64 `json:"count"`
+
+	// Reason invalid_token, expired_token, revoked_token, inactive_application, ip_not_allowed, invalid_signature, invalid_access_token or session_rejected
+	Reason string `json:"reason"`
+}
+
+// AuthFailureSummary defines model for AuthFailureSummary.
+type AuthFailureSummary struct {
+	From    time.Time                `json:"from"`
+	Hourly  []AuthFailureHourlyCount `json:"hourly"`
+	Reasons []AuthFailureReasonCount `json:"reasons"`
+	To      time.Time                `json:"to"`
+	Total   int64                    `json:"total"`
+}
+
+// AuthLockout defines model for AuthLockout.
+type AuthLockout struct {
+	// Disabled The account stays disabled in the auth provider until unlocked
+	Disabled bool `json:"disabled"`
+
+	// LastReason Reason of the last failure, e.g. session_rejected or invalid_mfa_code
+	LastReason  string     `json:"lastReason"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+
+	// Subject User id or client IP
+	Subject string `json:"subject"`
+
+	// SubjectType user or ip
+	SubjectType string `json:"subjectType"`
+
+	// TenantId Tenant of the last failure, empty on the root domain
+	TenantId  string    `json:"tenantId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AuthLockoutUnlock defines model for AuthLockoutUnlock.
+type AuthLockoutUnlock struct {
+	Subject string `json:"subject"`
+
+	// SubjectType user or ip
+	SubjectType string `json:"subjectType"`
+}
+
 // BasicEntity defines model for BasicEntity.
 type BasicEntity struct {
 	Icon *string            `json:"icon,omitempty"`
@@ -146,6 +436,39 @@ type BasicEntity struct {
 	Name string             `json:"name"`
 }
 
+// BulkUserOperation defines model for BulkUserOperation.
+type BulkUserOperation struct {
+	Action BulkUserOperationAction `json:"action"`
+	Role   *Role                   `json:"role,omitempty"`
+
+	// TransferTo With delete, user who takes over the resources owned by the deleted users
+	TransferTo *string `json:"transferTo,omitempty"`
+
+	// TransferToTenantOwner With delete, transfer the resources owned by the deleted users to the tenant owner
+	TransferToTenantOwner *bool    `json:"transferToTenantOwner,omitempty"`
+	UserIds               []string `json:"userIds"`
+}
+
+// BulkUserOperationAction defines model for BulkUserOperation.Action.
+type BulkUserOperationAction string
+
+// BulkUserOperationItem defines model for BulkUserOperationItem.
+type BulkUserOperationItem struct {
+	// Code Reason of a failure, such as NOT_FOUND, FORBIDDEN, SELF, LAST_CUSTOMER_ADMIN or USER_OWNS_RESOURCES
+	Code    *string `json:"code,omitempty"`
+	Error   *string `json:"error,omitempty"`
+	Success bool    `json:"success"`
+	UserId  string  `json:"userId"`
+}
+
+// BulkUserOperationResult defines model for BulkUserOperationResult.
+type BulkUserOperationResult struct {
+	Failed    int                     `json:"failed"`
+	Results   []BulkUserOperationItem `json:"results"`
+	Succeeded int                     `json:"succeeded"`
+	Total     int                     `json:"total"`
+}
+
 // CheckDetails defines model for CheckDetails.
 type CheckDetails struct {
 	// ComponentName The name of the component being checked
@@ -167,6 +490,33 @@ type CheckDetails struct {
 // CheckDetailsStatus The status of this particular component
 type CheckDetailsStatus string
 
+// ClaimsDiscrepancy defines model for ClaimsDiscrepancy.
+type ClaimsDiscrepancy struct {
+	// ActualRoles Roles found in the auth provider claims
+	ActualRoles []string `json:"actualRoles"`
+	Error       *string  `json:"error,omitempty"`
+
+	// ExpectedRoles Roles stored on the tenant membership
+	ExpectedRoles []string `json:"expectedRoles"`
+	Repaired      bool     `json:"repaired"`
+	UserId        string   `json:"userId"`
+}
+
+// ClaimsRebuildReport defines model for ClaimsRebuildReport.
+type ClaimsRebuildReport struct {
+	Completed     bool                `json:"completed"`
+	Discrepancies []ClaimsDiscrepancy `json:"discrepancies"`
+	Failed        int                 `json:"failed"`
+
+	// NextCursor Pass as cursor to resume the rebuild when not completed
+	NextCursor *string `json:"nextCursor,omitempty"`
+	Processed  int     `json:"processed"`
+	Repaired   int     `json:"repaired"`
+
+	// Total Number of active members in the tenant
+	Total int64 `json:"total"`
+}
+
 // ClientApplication defines model for ClientApplication.
 type ClientApplication struct {
 	Active      bool               `json:"active"`
@@ -174,12 +524,108 @@ type ClientApplication struct {
 	Id          openapi_types.UUID `json:"id"`
 	LastUsed    *time.Time         `json:"lastUsed,omitempty"`
 	LastUsedAt  *time.Time         `json:"lastUsedAt,omitempty"`
-	Name        string             `json:"name"`
+
+	// MaxActiveTokens Maximum number of active tokens, null for no limit
+	MaxActiveTokens *int32 `json:"maxActiveTokens"`
+	Name            string `json:"name"`
+
+	// ServiceAccountId User ID the requests of the application run as, its creator when null
+	ServiceAccountId *string `json:"serviceAccountId"`
 
 	// TenantId If null, this is a global application managed by SUPER_ADMIN
 	TenantId *string `json:"tenantId"`
 }
 
+// ClientApplicationClientCredentialsConfig defines model for ClientApplicationClientCredentialsConfig.
+type ClientApplicationClientCredentialsConfig struct {
+	AllowedCidrs       *[]string `json:"allowedCidrs,omitempty"`
+	RateLimitPerHour   *int32    `json:"rateLimitPerHour"`
+	RateLimitPerMinute *int32    `json:"rateLimitPerMinute"`
+	Scopes             []string  `json:"scopes"`
+}
+
+// ClientApplicationConfig Configuration of a client application, without any secret, as exported and imported between environments
+type ClientApplicationConfig struct {
+	ClientCredentials *ClientApplicationClientCredentialsConfig `json:"clientCredentials,omitempty"`
+	Description       *string                                   `json:"description,omitempty"`
+	Name              string                                    `json:"name"`
+	RequestSigning    *ClientApplicationCredentialConfig        `json:"requestSigning,omitempty"`
+
+	// Tokens Active API tokens; a new token value is generated for each on import
+	Tokens []ClientApplicationTokenConfig `json:"tokens"`
+
+	// Version Version of the document format
+	Version int `json:"version"`
+
+	// WebhookUrl URL of the lifecycle webhook
+	WebhookUrl *string `json:"webhookUrl,omitempty"`
+}
+
+// ClientApplicationCredentialConfig defines model for ClientApplicationCredentialConfig.
+type ClientApplicationCredentialConfig struct {
+	Scopes []string `json:"scopes"`
+}
+
+// ClientApplicationImportResult defines model for ClientApplicationImportResult.
+type ClientApplicationImportResult struct {
+	ClientApplication ClientApplication                `json:"clientApplication"`
+	ClientSecret      *ClientSecretCreated             `json:"clientSecret,omitempty"`
+	SigningKey        *SigningKeyCreated               `json:"signingKey,omitempty"`
+	Tokens            []APITokenCreated                `json:"tokens"`
+	Webhook           *ClientApplicationWebhookCreated `json:"webhook,omitempty"`
+}
+
+// ClientApplicationTokenConfig defines model for ClientApplicationTokenConfig.
+type ClientApplicationTokenConfig struct {
+	AllowedCidrs *[]string `json:"allowedCidrs,omitempty"`
+	Description  *string   `json:"description,omitempty"`
+
+	// ExpiresInDays Lifetime of the token
+	ExpiresInDays      int32     `json:"expiresInDays"`
+	Name               string    `json:"name"`
+	RateLimitPerHour   *int32    `json:"rateLimitPerHour"`
+	RateLimitPerMinute *int32    `json:"rateLimitPerMinute"`
+	Scopes             *[]string `json:"scopes,omitempty"`
+}
+
+// ClientApplicationTokenQuota defines model for ClientApplicationTokenQuota.
+type ClientApplicationTokenQuota struct {
+	// MaxActiveTokens Maximum number of active tokens, null for no limit
+	MaxActiveTokens *int32 `json:"maxActiveTokens"`
+}
+
+// ClientApplicationWebhook defines model for ClientApplicationWebhook.
+type ClientApplicationWebhook struct {
+	ClientId  string             `json:"clientId"`
+	CreatedAt time.Time          `json:"createdAt"`
+	CreatedBy string             `json:"createdBy"`
+	Id        openapi_types.UUID `json:"id"`
+	Url       string             `json:"url"`
+}
+
+// ClientApplicationWebhookCreated defines model for ClientApplicationWebhookCreated.
+type ClientApplicationWebhookCreated struct {
+	ClientId  string             `json:"clientId"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// SigningSecret The secret verifying the X-Webhook-Signature header (only returned once upon creation)
+	SigningSecret string `json:"signingSecret"`
+	Url           string `json:"url"`
+}
+
+// ClientSecretCreated defines model for ClientSecretCreated.
+type ClientSecretCreated struct {
+	// ClientId The client application ID, used as OAuth2 client_id
+	ClientId string `json:"clientId"`
+
+	// ClientSecret The client secret (only returned once upon creation)
+	ClientSecret string    `json:"clientSecret"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Scopes       []string  `json:"scopes"`
+	SecretPrefix string    `json:"secretPrefix"`
+}
+
 // ColorSchema defines model for ColorSchema.
 type ColorSchema struct {
 	Accent                   *string `json:"accent,omitempty"`
@@ -229,12 +675,306 @@ type Config struct {
 	Value *string            `json:"value,omitempty"`
 }
 
+// CustomRole defines model for CustomRole.
+type CustomRole struct {
+	CreatedAt   time.Time          `json:"createdAt"`
+	CreatedBy   string             `json:"createdBy"`
+	Description *string            `json:"description,omitempty"`
+	Id          openapi_types.UUID `json:"id"`
+	Name        string             `json:"name"`
+
+	// Permissions Operations granted to the members, such as users:manage
+	Permissions []string  `json:"permissions"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// CustomRoleMember defines model for CustomRoleMember.
+type CustomRoleMember struct {
+	AssignedAt time.Time `json:"assignedAt"`
+	AssignedBy string    `json:"assignedBy"`
+	UserId     string    `json:"userId"`
+}
+
+// DirectoryConnection defines model for DirectoryConnection.
+type DirectoryConnection struct {
+	CreatedAt          time.Time           `json:"createdAt"`
+	CreatedBy          string              `json:"createdBy"`
+	DefaultRoles       []Role              `json:"defaultRoles"`
+	DeprovisionAction  string              `json:"deprovisionAction"`
+	Enabled            bool                `json:"enabled"`
+	LastSyncError      *string             `json:"lastSyncError,omitempty"`
+	LastSyncFinishedAt *time.Time          `json:"lastSyncFinishedAt,omitempty"`
+	LastSyncStartedAt  *time.Time          `json:"lastSyncStartedAt,omitempty"`
+	LastSyncStats      *DirectorySyncStats `json:"lastSyncStats,omitempty"`
+
+	// LastSyncStatus running, succeeded or failed; absent before the first sync
+	LastSyncStatus *string             `json:"lastSyncStatus,omitempty"`
+	Provider       DirectoryProvider   `json:"provider"`
+	RoleRules      []DirectoryRoleRule `json:"roleRules"`
+
+	// Settings Google Workspace uses customer, domain and adminEmail; Microsoft 365 uses directoryId and clientId
+	Settings  DirectorySettings `json:"settings"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// DirectoryConnectionInput defines model for DirectoryConnectionInput.
+type DirectoryConnectionInput struct {
+	// Credentials Service account JSON key or client secret, required on the first save
+	Credentials *string `json:"credentials,omitempty"`
+
+	// DefaultRoles Roles of the users in no rule group; empty leaves them out of the sync
+	DefaultRoles *[]Role `json:"defaultRoles,omitempty"`
+
+	// DeprovisionAction suspend (default) or remove the users who left the directory or its groups
+	DeprovisionAction *string `json:"deprovisionAction,omitempty"`
+
+	// Enabled Whether the scheduled job syncs the directory
+	Enabled  bool              `json:"enabled"`
+	Provider DirectoryProvider `json:"provider"`
+
+	// RoleRules Roles given to the members of each group; a user in several groups gets all their roles
+	RoleRules *[]DirectoryRoleRule `json:"roleRules,omitempty"`
+
+	// Settings Google Workspace uses customer, domain and adminEmail; Microsoft 365 uses directoryId and clientId
+	Settings DirectorySettings `json:"settings"`
+}
+
+// DirectoryProvider defines model for DirectoryProvider.
+type DirectoryProvider string
+
+// DirectoryRoleRule defines model for DirectoryRoleRule.
+type DirectoryRoleRule struct {
+	// Group Group email or id on Google Workspace, group object id on Microsoft 365
+	Group string `json:"group"`
+	Roles []Role `json:"roles"`
+}
+
+// DirectorySettings Google Workspace uses customer, domain and adminEmail; Microsoft 365 uses directoryId and clientId
+type DirectorySettings struct {
+	// AdminEmail Google administrator the service account acts as
+	AdminEmail *string `json:"adminEmail,omitempty"`
+
+	// ClientId Microsoft Entra application id
+	ClientId *string `json:"clientId,omitempty"`
+
+	// Customer Google customer id, my_customer by default
+	Customer *string `json:"customer,omitempty"`
+
+	// DirectoryId Microsoft Entra tenant id
+	DirectoryId *string `json:"directoryId,omitempty"`
+
+	// Domain Limits the Google users to one domain of the customer
+	Domain *string `json:"domain,omitempty"`
+}
+
+// DirectorySyncConflict defines model for DirectorySyncConflict.
+type DirectorySyncConflict struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Detail    string    `json:"detail"`
+	Email     string    `json:"email"`
+
+	// ExternalId Id of the account in the directory, empty for a conflict of the whole sync
+	ExternalId string             `json:"externalId"`
+	Id         openapi_types.UUID `json:"id"`
+
+	// Kind missing_email, duplicate_email, global_role, linked_elsewhere, provision_failed, deprovision_failed or deprovision_limit
+	Kind string `json:"kind"`
+
+	// UserId Platform user the conflict is about, when known
+	UserId *string `json:"userId,omitempty"`
+}
+
+// DirectorySyncStats defines model for DirectorySyncStats.
+type DirectorySyncStats struct {
+	Conflicts     int `json:"conflicts"`
+	Created       int `json:"created"`
+	Deprovisioned int `json:"deprovisioned"`
+
+	// Linked Existing users linked to their directory account
+	Linked      int `json:"linked"`
+	Reactivated int `json:"reactivated"`
+
+	// Seen Users read from the directory
+	Seen int `json:"seen"`
+
+	// Updated Users whose roles changed
+	Updated int `json:"updated"`
+}
+
+// DisplayNameError defines model for DisplayNameError.
+type DisplayNameError struct {
+	Code       string                 `json:"code"`
+	Field      string                 `json:"field"`
+	Message    string                 `json:"message"`
+	Violations []DisplayNameViolation `json:"violations"`
+}
+
+// DisplayNameViolation defines model for DisplayNameViolation.
+type DisplayNameViolation struct {
+	Code    DisplayNameViolationCode `json:"code"`
+	Message string                   `json:"message"`
+}
+
+// DisplayNameViolationCode defines model for DisplayNameViolation.Code.
+type DisplayNameViolationCode string
+
+// EmailChange defines model for EmailChange.
+type EmailChange struct {
+	// Email The new address of a pending or confirmed change, the restored address of a reverted one
+	Email openapi_types.Email `json:"email"`
+
+	// ExpiresAt Expiry of the confirmation link of a pending change, of the revert link of a confirmed one
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Status pending, confirmed or reverted
+	Status string `json:"status"`
+}
+
+// EmailChangeRequest defines model for EmailChangeRequest.
+type EmailChangeRequest struct {
+	// Email The new address
+	Email openapi_types.Email `json:"email"`
+}
+
+// EmailChangeToken defines model for EmailChangeToken.
+type EmailChangeToken struct {
+	// Token Token of the link sent by email
+	Token string `json:"token"`
+}
+
+// EmailEncryptionReport defines model for EmailEncryptionReport.
+type EmailEncryptionReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// CurrentKeyId Key encrypting new addresses
+	CurrentKeyId *string `json:"currentKeyId,omitempty"`
+	DryRun       bool    `json:"dryRun"`
+
+	// Enabled New addresses are encrypted
+	Enabled bool `json:"enabled"`
+
+	// Failed Addresses that could not be decrypted or written
+	Failed int `json:"failed"`
+
+	// Keys Number of encrypted addresses per key id
+	Keys map[string]int64 `json:"keys"`
+
+	// Pending Addresses not stored in the current format
+	Pending int `json:"pending"`
+
+	// Plaintext Number of addresses stored in plaintext
+	Plaintext int64 `json:"plaintext"`
+	Rewritten int   `json:"rewritten"`
+}
+
+// EmailTemplate defines model for EmailTemplate.
+type EmailTemplate struct {
+	// Body HTML body of the email
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	Event     string    `json:"event"`
+	Locale    string    `json:"locale"`
+
+	// Subject Subject of the email, on one line
+	Subject   string    `json:"subject"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// UpdatedBy User who saved the template last
+	UpdatedBy string `json:"updatedBy"`
+}
+
+// EmailTemplateEvent defines model for EmailTemplateEvent.
+type EmailTemplateEvent struct {
+	Event string `json:"event"`
+
+	// Variables Variables the templates of the event may use, as {{name}}
+	Variables []string `json:"variables"`
+}
+
+// EmailTemplates defines model for EmailTemplates.
+type EmailTemplates struct {
+	Events    []EmailTemplateEvent `json:"events"`
+	Templates []EmailTemplate      `json:"templates"`
+}
+
 // ErrorSchema defines model for ErrorSchema.
 type ErrorSchema struct {
 	Code    int32  `json:"code"`
 	Message string `json:"message"`
 }
 
+// ExecutionWebhook defines model for ExecutionWebhook.
+type ExecutionWebhook struct {
+	CreatedAt time.Time          `json:"createdAt"`
+	CreatedBy string             `json:"createdBy"`
+	Id        openapi_types.UUID `json:"id"`
+	SourceId  string             `json:"sourceId"`
+
+	// SourceType prompt or schedule
+	SourceType string `json:"sourceType"`
+	Url        string `json:"url"`
+}
+
+// ExecutionWebhookCreated defines model for ExecutionWebhookCreated.
+type ExecutionWebhookCreated struct {
+	CreatedAt time.Time          `json:"createdAt"`
+	CreatedBy string             `json:"createdBy"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// SigningSecret The secret verifying the X-Webhook-Signature header (only returned when the webhook is set, a new one every time)
+	SigningSecret string `json:"signingSecret"`
+	SourceId      string `json:"sourceId"`
+
+	// SourceType prompt or schedule
+	SourceType string `json:"sourceType"`
+	Url        string `json:"url"`
+}
+
+// FeatureFlag defines model for FeatureFlag.
+type FeatureFlag struct {
+	CreatedAt   time.Time `json:"createdAt"`
+	Description *string   `json:"description,omitempty"`
+
+	// Enabled A disabled flag is off for every tenant
+	Enabled bool   `json:"enabled"`
+	Key     string `json:"key"`
+
+	// RolloutPercentage Share of the tenants without an override the flag is on for
+	RolloutPercentage int       `json:"rolloutPercentage"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+	UpdatedBy         string    `json:"updatedBy"`
+}
+
+// FeatureFlagUpdate defines model for FeatureFlagUpdate.
+type FeatureFlagUpdate struct {
+	Description       *string `json:"description,omitempty"`
+	Enabled           bool    `json:"enabled"`
+	RolloutPercentage int     `json:"rolloutPercentage"`
+}
+
+// FeatureFlagValues Whether each flag is on, by key
+type FeatureFlagValues map[string]bool
+
+// Group defines model for Group.
+type Group struct {
+	CreatedAt   time.Time          `json:"createdAt"`
+	CreatedBy   string             `json:"createdBy"`
+	Description *string            `json:"description,omitempty"`
+	Id          openapi_types.UUID `json:"id"`
+	Name        string             `json:"name"`
+
+	// Roles Roles granted to the members, USER or CUSTOMER_ADMIN
+	Roles     []Role    `json:"roles"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GroupMember defines model for GroupMember.
+type GroupMember struct {
+	AddedAt time.Time `json:"addedAt"`
+	AddedBy string    `json:"addedBy"`
+	UserId  string    `json:"userId"`
+}
+
 // HealthResponse defines model for HealthResponse.
 type HealthResponse struct {
 	// Checks Detailed health check results for API components or dependencies
@@ -282,15 +1022,110 @@ type Identify struct {
 	Email openapi_types.Email `json:"email"`
 }
 
-// MFAStatus defines model for MFAStatus.
-type MFAStatus struct {
-	// Aal Current Authenticator Assurance Level
-	Aal MFAStatusAal `json:"aal"`
+// IndexAdvisorReport defines model for IndexAdvisorReport.
+type IndexAdvisorReport struct {
+	AnalyzedAt      time.Time             `json:"analyzedAt"`
+	Recommendations []IndexRecommendation `json:"recommendations"`
 
-	// AvailableMethods List of available MFA methods
-	AvailableMethods []string `json:"available_methods"`
+	// Statements Number of tenant scoped sorted queries analyzed
+	Statements int `json:"statements"`
+}
 
-	// RecoveryCodesSet Whether recovery codes have been generated
+// IndexRecommendation defines model for IndexRecommendation.
+type IndexRecommendation struct {
+	Calls   int64    `json:"calls"`
+	Columns []string `json:"columns"`
+
+	// MeanExecTimeMs Highest mean execution time of the queries
+	MeanExecTimeMs float64 `json:"meanExecTimeMs"`
+
+	// SampleQuery Most expensive of the queries, as normalized by pg_stat_statements
+	SampleQuery string `json:"sampleQuery"`
+
+	// Statement Statement creating the index without locking writes
+	Statement string `json:"statement"`
+
+	// Statements Number of queries the index would serve
+	Statements      int     `json:"statements"`
+	Table           string  `json:"table"`
+	TotalExecTimeMs float64 `json:"totalExecTimeMs"`
+}
+
+// JobEvent defines model for JobEvent.
+type JobEvent struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Cursor Cursor of the event
+	Cursor string `json:"cursor"`
+
+	// Data Payload of the event, described by dataType and dataVersion
+	Data        *map[string]interface{} `json:"data,omitempty"`
+	DataType    *string                 `json:"dataType,omitempty"`
+	DataVersion *int                    `json:"dataVersion,omitempty"`
+
+	// EventType INFO or ERROR
+	EventType string `json:"eventType"`
+	Message   string `json:"message"`
+
+	// Progress Progress of the job in percent, 100 for the final event
+	Progress int `json:"progress"`
+}
+
+// JobEventsPage defines model for JobEventsPage.
+type JobEventsPage struct {
+	// Cursor Cursor to pass as since on the next call
+	Cursor string `json:"cursor"`
+
+	// Done True when the job is over and every event has been returned
+	Done   bool       `json:"done"`
+	Events []JobEvent `json:"events"`
+
+	// Status Status of the job, running, completed or failed
+	Status string `json:"status"`
+}
+
+// LLMConsentPolicy defines model for LLMConsentPolicy.
+type LLMConsentPolicy struct {
+	// AllowAnalytics Use the executions for analytics
+	AllowAnalytics bool `json:"allowAnalytics"`
+
+	// AllowedRegions Provider regions the data may be sent to, any region when null
+	AllowedRegions *[]string `json:"allowedRegions"`
+
+	// StoreInputs Keep the inputs in the execution records, masked otherwise
+	StoreInputs bool `json:"storeInputs"`
+
+	// StoreOutputs Keep the outputs in the execution records, masked otherwise
+	StoreOutputs bool      `json:"storeOutputs"`
+	TenantId     string    `json:"tenantId"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	UpdatedBy    string    `json:"updatedBy"`
+}
+
+// LLMConsentPolicyUpdate defines model for LLMConsentPolicyUpdate.
+type LLMConsentPolicyUpdate struct {
+	// AllowAnalytics Use the executions for analytics
+	AllowAnalytics bool `json:"allowAnalytics"`
+
+	// AllowedRegions Provider regions the data may be sent to, any region when null
+	AllowedRegions *[]string `json:"allowedRegions"`
+
+	// StoreInputs Keep the inputs in the execution records, masked otherwise
+	StoreInputs bool `json:"storeInputs"`
+
+	// StoreOutputs Keep the outputs in the execution records, masked otherwise
+	StoreOutputs bool `json:"storeOutputs"`
+}
+
+// MFAStatus defines model for MFAStatus.
+type MFAStatus struct {
+	// Aal Current Authenticator Assurance Level
+	Aal MFAStatusAal `json:"aal"`
+
+	// AvailableMethods List of available MFA methods
+	AvailableMethods []string `json:"available_methods"`
+
+	// RecoveryCodesSet Whether recovery codes have been generated
 	RecoveryCodesSet bool `json:"recovery_codes_set"`
 
 	// TotpEnabled Whether TOTP (Authenticator App) is enabled
@@ -303,14 +1138,54 @@ type MFAStatus struct {
 // MFAStatusAal Current Authenticator Assurance Level
 type MFAStatusAal string
 
+// MembershipConsistencyReport defines model for MembershipConsistencyReport.
+type MembershipConsistencyReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// Counts Number of reported rows per kind
+	Counts   map[string]int    `json:"counts"`
+	Drifts   []MembershipDrift `json:"drifts"`
+	DryRun   bool              `json:"dryRun"`
+	Failed   int               `json:"failed"`
+	Repaired int               `json:"repaired"`
+
+	// Truncated More rows than the report limit were found for at least one kind
+	Truncated bool `json:"truncated"`
+}
+
+// MembershipDrift defines model for MembershipDrift.
+type MembershipDrift struct {
+	Email    *string             `json:"email,omitempty"`
+	Error    *string             `json:"error,omitempty"`
+	Kind     MembershipDriftKind `json:"kind"`
+	Repaired bool                `json:"repaired"`
+	TenantId *string             `json:"tenantId,omitempty"`
+	UserId   string              `json:"userId"`
+}
+
+// MembershipDriftKind defines model for MembershipDrift.Kind.
+type MembershipDriftKind string
+
 // NewAPIToken defines model for NewAPIToken.
 type NewAPIToken struct {
+	// AllowedCidrs Client IP allowlist in CIDR notation, any address is allowed when empty
+	AllowedCidrs *[]string `json:"allowedCidrs,omitempty"`
+
 	// ClientApplicationId ID of the client application this token belongs to
 	ClientApplicationId openapi_types.UUID `json:"clientApplicationId"`
 	Description         *string            `json:"description,omitempty"`
 	ExpiresAt           time.Time          `json:"expiresAt"`
 	Name                string             `json:"name"`
 
+	// RateLimitPerHour Maximum requests per hour, unlimited when null
+	RateLimitPerHour *int32 `json:"rateLimitPerHour"`
+
+	// RateLimitPerMinute Maximum requests per minute, unlimited when null
+	RateLimitPerMinute *int32 `json:"rateLimitPerMinute"`
+
+	// ScopeTemplateId Scope template the token takes its scopes from, exclusive with scopes
+	ScopeTemplateId *openapi_types.UUID `json:"scopeTemplateId,omitempty"`
+
 	// Scopes Permission scopes for this token
 	Scopes *[]string `json:"scopes"`
 
@@ -318,6 +1193,18 @@ type NewAPIToken struct {
 	TokenPrefix string `json:"tokenPrefix"`
 }
 
+// NewAnnouncement defines model for NewAnnouncement.
+type NewAnnouncement struct {
+	// EndsAt When the banner expires
+	EndsAt   time.Time            `json:"endsAt"`
+	Message  string               `json:"message"`
+	Severity AnnouncementSeverity `json:"severity"`
+
+	// StartsAt When the banner starts being shown, defaults to now
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	Title    string     `json:"title"`
+}
+
 // NewClientApplication defines model for NewClientApplication.
 type NewClientApplication struct {
 	Active      bool       `json:"active"`
@@ -326,12 +1213,89 @@ type NewClientApplication struct {
 	Name        string     `json:"name"`
 }
 
+// NewClientApplicationWebhook defines model for NewClientApplicationWebhook.
+type NewClientApplicationWebhook struct {
+	// Url https URL of a public host the events are POSTed to
+	Url string `json:"url"`
+}
+
+// NewClientSecret defines model for NewClientSecret.
+type NewClientSecret struct {
+	// AllowedCidrs Client IP allowlist of the access tokens in CIDR notation, any address is allowed when empty
+	AllowedCidrs *[]string `json:"allowedCidrs,omitempty"`
+
+	// RateLimitPerHour Maximum requests per hour of the access tokens, unlimited when null
+	RateLimitPerHour *int32 `json:"rateLimitPerHour"`
+
+	// RateLimitPerMinute Maximum requests per minute of the access tokens, unlimited when null
+	RateLimitPerMinute *int32 `json:"rateLimitPerMinute"`
+
+	// Scopes Scopes the client may request with the client_credentials grant
+	Scopes *[]string `json:"scopes,omitempty"`
+}
+
 // NewConfig defines model for NewConfig.
 type NewConfig struct {
 	Name  string  `json:"name"`
 	Value *string `json:"value,omitempty"`
 }
 
+// NewCustomRole defines model for NewCustomRole.
+type NewCustomRole struct {
+	Description *string `json:"description,omitempty"`
+	Name        string  `json:"name"`
+
+	// Permissions Operations granted to the members, such as users:manage
+	Permissions []string `json:"permissions"`
+}
+
+// NewEmailTemplate defines model for NewEmailTemplate.
+type NewEmailTemplate struct {
+	// Body HTML body of the email
+	Body string `json:"body"`
+
+	// Subject Subject of the email, on one line
+	Subject string `json:"subject"`
+}
+
+// NewExecutionWebhook defines model for NewExecutionWebhook.
+type NewExecutionWebhook struct {
+	// Url https URL of a public host the results are POSTed to
+	Url string `json:"url"`
+}
+
+// NewGroup defines model for NewGroup.
+type NewGroup struct {
+	Description *string `json:"description,omitempty"`
+	Name        string  `json:"name"`
+
+	// Roles Roles granted to the members, USER or CUSTOMER_ADMIN
+	Roles []Role `json:"roles"`
+}
+
+// NewPermissionDelegation defines model for NewPermissionDelegation.
+type NewPermissionDelegation struct {
+	// DelegateId User receiving the operations
+	DelegateId string    `json:"delegateId"`
+	EndsAt     time.Time `json:"endsAt"`
+
+	// Operations Operations delegated, such as users:manage
+	Operations []string `json:"operations"`
+	Reason     *string  `json:"reason,omitempty"`
+
+	// StartsAt Start of the delegation, now when not set
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+}
+
+// NewScopeTemplate defines model for NewScopeTemplate.
+type NewScopeTemplate struct {
+	Description *string `json:"description,omitempty"`
+	Name        string  `json:"name"`
+
+	// Scopes Scopes given to the tokens created from the template
+	Scopes []string `json:"scopes"`
+}
+
 // NewSignUp defines model for NewSignUp.
 type NewSignUp struct {
 	Company     *string `json:"company,omitempty"`
@@ -341,6 +1305,12 @@ type NewSignUp struct {
 	PhoneNumber *string `json:"phoneNumber,omitempty"`
 }
 
+// NewSigningKey defines model for NewSigningKey.
+type NewSigningKey struct {
+	// Scopes Scopes granted to requests signed with this key
+	Scopes *[]string `json:"scopes,omitempty"`
+}
+
 // NewTenant defines model for NewTenant.
 type NewTenant struct {
 	// AllowPasswordSignUp Auth Provider setting to Allow password sign up (can skip)
@@ -360,6 +1330,44 @@ type NewTenant struct {
 	Subdomain  string  `json:"subdomain"`
 }
 
+// NewTenantExport defines model for NewTenantExport.
+type NewTenantExport struct {
+	Dataset TenantExportDataset `json:"dataset"`
+
+	// Destination download keeps the file on the platform; bucket writes it to the bucket of the export_bucket_url tenant config
+	Destination *TenantExportDestination `json:"destination,omitempty"`
+
+	// Format ocsf writes OCSF 1.1 events as JSON Lines and only applies to the audit dataset
+	Format TenantExportFormat `json:"format"`
+
+	// From Start of the period, inclusive
+	From time.Time `json:"from"`
+
+	// To End of the period, exclusive
+	To time.Time `json:"to"`
+}
+
+// NewTenantExportSchedule defines model for NewTenantExportSchedule.
+type NewTenantExportSchedule struct {
+	Dataset TenantExportDataset `json:"dataset"`
+
+	// Destination download keeps the file on the platform; bucket writes it to the bucket of the export_bucket_url tenant config
+	Destination *TenantExportDestination `json:"destination,omitempty"`
+
+	// Format ocsf writes OCSF 1.1 events as JSON Lines and only applies to the audit dataset
+	Format    TenantExportFormat    `json:"format"`
+	Frequency TenantExportFrequency `json:"frequency"`
+}
+
+// NewTenantSubdomainAlias defines model for NewTenantSubdomainAlias.
+type NewTenantSubdomainAlias struct {
+	// Primary Make the alias the primary subdomain, the current one becoming an alias
+	Primary *bool `json:"primary,omitempty"`
+
+	// Subdomain Lowercase DNS label, not used by any tenant
+	Subdomain string `json:"subdomain"`
+}
+
 // NewTranslation defines model for NewTranslation.
 type NewTranslation struct {
 	EntityId   openapi_types.UUID `json:"entity_id"`
@@ -373,12 +1381,130 @@ type NewTranslation struct {
 type NewUser struct {
 	Email string `json:"email"`
 	Name  string `json:"name"`
-	Roles []Role `json:"roles"`
+
+	// PhoneNumber Phone number in the E.164 format, such as +14155550123. It is stored unverified.
+	PhoneNumber *string `json:"phoneNumber,omitempty"`
+	Roles       []Role  `json:"roles"`
 
 	// Silent When true, suppress invitation and welcome emails (both the synchronous Kratos welcome and any asynchronous onboarding follow-up).
 	Silent *bool `json:"silent,omitempty"`
 }
 
+// NewUserAttribute defines model for NewUserAttribute.
+type NewUserAttribute struct {
+	// EnumValues Accepted values of an enum
+	EnumValues *[]string `json:"enumValues,omitempty"`
+	Label      string    `json:"label"`
+	Required   *bool     `json:"required,omitempty"`
+
+	// Type string, number, boolean, date (YYYY-MM-DD) or enum
+	Type string `json:"type"`
+}
+
+// NewUserInvitation defines model for NewUserInvitation.
+type NewUserInvitation struct {
+	Email openapi_types.Email `json:"email"`
+
+	// Roles Roles given to the user when they accept
+	Roles []Role `json:"roles"`
+}
+
+// OAuthError defines model for OAuthError.
+type OAuthError struct {
+	Error            string  `json:"error"`
+	ErrorDescription *string `json:"error_description,omitempty"`
+}
+
+// OAuthTokenRequest defines model for OAuthTokenRequest.
+type OAuthTokenRequest struct {
+	// ClientId Optional when the client authenticates with HTTP Basic
+	ClientId     *string                    `json:"client_id,omitempty"`
+	ClientSecret *string                    `json:"client_secret,omitempty"`
+	GrantType    OAuthTokenRequestGrantType `json:"grant_type"`
+
+	// Scope Space separated scopes, defaults to every scope allowed to the client
+	Scope *string `json:"scope,omitempty"`
+}
+
+// OAuthTokenRequestGrantType defines model for OAuthTokenRequest.GrantType.
+type OAuthTokenRequestGrantType string
+
+// OAuthTokenResponse defines model for OAuthTokenResponse.
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+
+	// ExpiresIn Lifetime of the access token in seconds
+	ExpiresIn int     `json:"expires_in"`
+	Scope     *string `json:"scope,omitempty"`
+	TokenType string  `json:"token_type"`
+}
+
+// OperationPermission defines model for OperationPermission.
+type OperationPermission struct {
+	// Operation Operation guarded by the backend, such as users:manage
+	Operation string `json:"operation"`
+
+	// Roles Roles allowed to perform the operation
+	Roles []Role `json:"roles"`
+}
+
+// PermissionDelegation defines model for PermissionDelegation.
+type PermissionDelegation struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// DelegateId User receiving the operations
+	DelegateId  string             `json:"delegateId"`
+	DelegatorId string             `json:"delegatorId"`
+	EndsAt      time.Time          `json:"endsAt"`
+	Id          openapi_types.UUID `json:"id"`
+
+	// Operations Operations delegated, such as users:manage
+	Operations []string   `json:"operations"`
+	Reason     *string    `json:"reason,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy  *string    `json:"revokedBy,omitempty"`
+
+	// StartsAt Start of the delegation, now when not set
+	StartsAt time.Time                  `json:"startsAt"`
+	Status   PermissionDelegationStatus `json:"status"`
+}
+
+// PermissionDelegationStatus defines model for PermissionDelegation.Status.
+type PermissionDelegationStatus string
+
+// PermissionMatrix defines model for PermissionMatrix.
+type PermissionMatrix struct {
+	Operations []OperationPermission `json:"operations"`
+
+	// Roles Roles the matrix has a column for
+	Roles []Role `json:"roles"`
+
+	// Strict Operations missing from the matrix are denied, allowed otherwise
+	Strict bool `json:"strict"`
+}
+
+// PhoneVerification defines model for PhoneVerification.
+type PhoneVerification struct {
+	// ExpiresAt Expiry of the code sent, while the number is not verified
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// PhoneNumber The number, in the E.164 format
+	PhoneNumber string `json:"phoneNumber"`
+	Verified    bool   `json:"verified"`
+}
+
+// PhoneVerificationConfirm defines model for PhoneVerificationConfirm.
+type PhoneVerificationConfirm struct {
+	// Code Code received by SMS
+	Code string `json:"code"`
+}
+
+// PhoneVerificationRequest defines model for PhoneVerificationRequest.
+type PhoneVerificationRequest struct {
+	// PhoneNumber Number in the E.164 format, such as +14155550123
+	PhoneNumber string `json:"phoneNumber"`
+}
+
 // PublicTenantSchema defines model for PublicTenantSchema.
 type PublicTenantSchema struct {
 	// AllowPasswordSignUp Auth Provider setting to Allow password sign up (can skip)
@@ -387,6 +1513,9 @@ type PublicTenantSchema struct {
 	// AllowSignUp Allow users to sign up for this tenant
 	AllowSignUp bool `json:"allow_sign_up"`
 
+	// Announcements Announcement banners currently shown on the tenant, global ones included
+	Announcements *[]Announcement `json:"announcements,omitempty"`
+
 	// ContractEndDate Optional contract expiry date. When reached, the tenant is automatically disabled.
 	ContractEndDate *time.Time `json:"contract_end_date"`
 
@@ -492,8 +1621,70 @@ type PublicTenantSchema struct {
 		Values *string `json:"values,omitempty"`
 	} `json:"profile"`
 	ResellerId *string `json:"reseller_id"`
-	Subdomain  string  `json:"subdomain"`
-	TenantId   string  `json:"tenant_id"`
+
+	// Status Lifecycle of the tenant. The requests to a suspended tenant are refused
+	// with 423; an archived tenant answers 404 and is left out of the listings.
+	// A tenant pending_deletion answers 404 until its purge; it is set and
+	// left by scheduling and cancelling the purge only.
+	Status          *TenantStatus `json:"status,omitempty"`
+	StatusChangedAt *time.Time    `json:"status_changed_at"`
+
+	// StatusReason Why the tenant was last moved to its status
+	StatusReason *string `json:"status_reason"`
+	Subdomain    string  `json:"subdomain"`
+	TenantId     string  `json:"tenant_id"`
+}
+
+// PublicTenantSettings defines model for PublicTenantSettings.
+type PublicTenantSettings struct {
+	AllowPasswordSignUp bool `json:"allowPasswordSignUp"`
+	AllowSignUp         bool `json:"allowSignUp"`
+
+	// Settings Value of every public setting by key
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// PublicUserInvitation defines model for PublicUserInvitation.
+type PublicUserInvitation struct {
+	Email string `json:"email"`
+
+	// ExistingAccount The address already has an account, no password is asked
+	ExistingAccount bool      `json:"existingAccount"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+
+	// TenantName Name of the tenant, "the administration" on the admin domain
+	TenantName string `json:"tenantName"`
+}
+
+// ReplayBundle defines model for ReplayBundle.
+type ReplayBundle struct {
+	// Bundle Sanitized request (path, query, headers, body), resolved context (tenant, user, roles), downstream calls
+	// and response of the failing request, as read by cmd/replay
+	Bundle    map[string]interface{} `json:"bundle"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Id        openapi_types.UUID     `json:"id"`
+	Method    string                 `json:"method"`
+	RequestId string                 `json:"requestId"`
+	Route     string                 `json:"route"`
+	Status    int                    `json:"status"`
+	TenantId  string                 `json:"tenantId"`
+	UserId    *string                `json:"userId"`
+}
+
+// ReplayBundleSummary defines model for ReplayBundleSummary.
+type ReplayBundleSummary struct {
+	CreatedAt time.Time          `json:"createdAt"`
+	Id        openapi_types.UUID `json:"id"`
+	Method    string             `json:"method"`
+
+	// RequestId X-Request-ID of the failing request, as reported by the user
+	RequestId string `json:"requestId"`
+
+	// Route Route template of the request, e.g. /api/v1/users/:userid
+	Route    string  `json:"route"`
+	Status   int     `json:"status"`
+	TenantId string  `json:"tenantId"`
+	UserId   *string `json:"userId"`
 }
 
 // Role defines model for Role.
@@ -523,6 +1714,109 @@ type SettingsFlow struct {
 	} `json:"ui,omitempty"`
 }
 
+// SLAOverdueItem defines model for SLAOverdueItem.
+type SLAOverdueItem struct {
+	// DueAt End of the SLA target
+	DueAt time.Time `json:"dueAt"`
+
+	// EscalatedAt When the breach was notified to the tenant admins
+	EscalatedAt  *time.Time `json:"escalatedAt,omitempty"`
+	Id           string     `json:"id"`
+	PendingSince time.Time  `json:"pendingSince"`
+
+	// Subject Names the item, such as the invited email
+	Subject  string `json:"subject"`
+	TenantId string `json:"tenantId"`
+
+	// Type Item type, such as invitation
+	Type string `json:"type"`
+}
+
+// ScopeTemplate defines model for ScopeTemplate.
+type ScopeTemplate struct {
+	CreatedAt   time.Time          `json:"createdAt"`
+	CreatedBy   string             `json:"createdBy"`
+	Description *string            `json:"description,omitempty"`
+	Id          openapi_types.UUID `json:"id"`
+	Name        string             `json:"name"`
+
+	// PropagateAt When the scopes are copied to the tokens created from the template, set during a grace period
+	PropagateAt *time.Time `json:"propagateAt,omitempty"`
+
+	// Scopes Scopes given to the tokens created from the template
+	Scopes    []string  `json:"scopes"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ScopeTemplateUpdate defines model for ScopeTemplateUpdate.
+type ScopeTemplateUpdate struct {
+	Description *string `json:"description,omitempty"`
+
+	// GracePeriodHours Hours before the scopes are propagated, right away when 0
+	GracePeriodHours *int   `json:"gracePeriodHours,omitempty"`
+	Name             string `json:"name"`
+
+	// Propagate Copy the new scopes to the active tokens created from the template
+	Propagate *bool `json:"propagate,omitempty"`
+
+	// Scopes Scopes given to the tokens created from the template
+	Scopes []string `json:"scopes"`
+}
+
+// SigningKeyCreated defines model for SigningKeyCreated.
+type SigningKeyCreated struct {
+	// ClientId The client application ID, sent in the X-Client-Id header
+	ClientId     string             `json:"clientId"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	Scopes       []string           `json:"scopes"`
+	SigningKeyId openapi_types.UUID `json:"signingKeyId"`
+
+	// SigningSecret The HMAC signing secret (only returned once upon creation)
+	SigningSecret string `json:"signingSecret"`
+}
+
+// TOTPCode defines model for TOTPCode.
+type TOTPCode struct {
+	// Code Code of the authenticator app, or a recovery code where accepted
+	Code string `json:"code"`
+}
+
+// TOTPEnrollment defines model for TOTPEnrollment.
+type TOTPEnrollment struct {
+	// OtpauthUri otpauth URI of the secret, to show as a QR code
+	OtpauthUri string `json:"otpauthUri"`
+
+	// Secret Base32 secret to enter in an authenticator app
+	Secret string `json:"secret"`
+}
+
+// TOTPRecoveryCodes defines model for TOTPRecoveryCodes.
+type TOTPRecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TOTPStatus defines model for TOTPStatus.
+type TOTPStatus struct {
+	// Enabled The second factor is enforced on every request
+	Enabled   bool       `json:"enabled"`
+	EnabledAt *time.Time `json:"enabledAt,omitempty"`
+
+	// Pending Enrolled, waiting for a first code to be enabled
+	Pending                bool `json:"pending"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// TOTPVerification defines model for TOTPVerification.
+type TOTPVerification struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// MfaToken Send it in the X-MFA-Token header of the next requests
+	MfaToken string `json:"mfaToken"`
+
+	// RecoveryCodes Single use codes, returned once when the verification enabled TOTP
+	RecoveryCodes *[]string `json:"recoveryCodes,omitempty"`
+}
+
 // Tenant defines model for Tenant.
 type Tenant struct {
 	// AllowPasswordSignUp Auth Provider setting to Allow password sign up (can skip)
@@ -540,8 +1834,143 @@ type Tenant struct {
 	IsReseller *bool   `json:"is_reseller,omitempty"`
 	Name       string  `json:"name"`
 	ResellerId *string `json:"reseller_id"`
-	Subdomain  string  `json:"subdomain"`
-	TenantId   string  `json:"tenant_id"`
+
+	// Status Lifecycle of the tenant. The requests to a suspended tenant are refused
+	// with 423; an archived tenant answers 404 and is left out of the listings.
+	// A tenant pending_deletion answers 404 until its purge; it is set and
+	// left by scheduling and cancelling the purge only.
+	Status          *TenantStatus `json:"status,omitempty"`
+	StatusChangedAt *time.Time    `json:"status_changed_at"`
+
+	// StatusReason Why the tenant was last moved to its status
+	StatusReason *string `json:"status_reason"`
+	Subdomain    string  `json:"subdomain"`
+	TenantId     string  `json:"tenant_id"`
+}
+
+// TenantBranding defines model for TenantBranding.
+type TenantBranding struct {
+	BackgroundMobileUrl string      `json:"backgroundMobileUrl"`
+	BackgroundUrl       string      `json:"backgroundUrl"`
+	DarkColors          ColorSchema `json:"darkColors"`
+	DarkModeDefault     bool        `json:"darkModeDefault"`
+
+	// DisplayName Display name of the tenant profile
+	DisplayName string `json:"displayName"`
+
+	// EmailFooter Footer line of the emails of the tenant
+	EmailFooter *string `json:"emailFooter,omitempty"`
+
+	// FontFamily CSS font family of the frontends
+	FontFamily  *string     `json:"fontFamily,omitempty"`
+	LightColors ColorSchema `json:"lightColors"`
+	LogoUrl     string      `json:"logoUrl"`
+
+	// PrimaryColor Main brand color, as #rgb or #rrggbb
+	PrimaryColor *string `json:"primaryColor,omitempty"`
+
+	// ProductName Name of the product shown in place of the platform name
+	ProductName *string `json:"productName,omitempty"`
+
+	// SecondaryColor Second brand color, as #rgb or #rrggbb
+	SecondaryColor *string `json:"secondaryColor,omitempty"`
+	ShowPoweredBy  bool    `json:"showPoweredBy"`
+}
+
+// TenantBrandingUpdate defines model for TenantBrandingUpdate.
+type TenantBrandingUpdate struct {
+	DarkModeDefault *bool   `json:"darkModeDefault,omitempty"`
+	EmailFooter     *string `json:"emailFooter,omitempty"`
+	FontFamily      *string `json:"fontFamily,omitempty"`
+	PrimaryColor    *string `json:"primaryColor,omitempty"`
+	ProductName     *string `json:"productName,omitempty"`
+	SecondaryColor  *string `json:"secondaryColor,omitempty"`
+	ShowPoweredBy   *bool   `json:"showPoweredBy,omitempty"`
+}
+
+// TenantDataExport defines model for TenantDataExport.
+type TenantDataExport struct {
+	// Bytes Size of the archive once completed
+	Bytes       *int64             `json:"bytes,omitempty"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	CreatedBy   string             `json:"createdBy"`
+	JobId       openapi_types.UUID `json:"jobId"`
+
+	// Message Last event of the export, the error when it failed
+	Message *string `json:"message,omitempty"`
+
+	// Progress Percentage of the export done
+	Progress int `json:"progress"`
+
+	// Status running, completed or failed
+	Status string `json:"status"`
+
+	// Tables Files of the archive once completed, one per table
+	Tables   *[]TenantDataExportTable `json:"tables,omitempty"`
+	TenantId string                   `json:"tenantId"`
+}
+
+// TenantDataExportTable defines model for TenantDataExportTable.
+type TenantDataExportTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// TenantEmailProvider defines model for TenantEmailProvider.
+type TenantEmailProvider struct {
+	CreatedAt   time.Time `json:"createdAt"`
+	FromAddress string    `json:"fromAddress"`
+	Host        *string   `json:"host,omitempty"`
+	Port        *int32    `json:"port,omitempty"`
+
+	// Provider smtp or sendgrid
+	Provider  string    `json:"provider"`
+	ReplyTo   *string   `json:"replyTo,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy"`
+	Username  *string   `json:"username,omitempty"`
+}
+
+// TenantEmailProviderUpdate defines model for TenantEmailProviderUpdate.
+type TenantEmailProviderUpdate struct {
+	// FromAddress Address the emails of the tenant are sent from
+	FromAddress string `json:"fromAddress"`
+
+	// Host SMTP host, required by smtp
+	Host *string `json:"host,omitempty"`
+
+	// Port SMTP port, required by smtp
+	Port *int32 `json:"port,omitempty"`
+
+	// Provider smtp or sendgrid
+	Provider string `json:"provider"`
+
+	// ReplyTo Address the answers go to
+	ReplyTo *string `json:"replyTo,omitempty"`
+
+	// Secret SMTP password or SendGrid API key; omit to keep the stored one
+	Secret *string `json:"secret,omitempty"`
+
+	// Username SMTP user
+	Username *string `json:"username,omitempty"`
+}
+
+// TenantFeatureFlag defines model for TenantFeatureFlag.
+type TenantFeatureFlag struct {
+	Enabled bool   `json:"enabled"`
+	Key     string `json:"key"`
+
+	// Override Value the tenant overrides the flag with, if any
+	Override *bool `json:"override,omitempty"`
+
+	// Source Why the flag is on or off, disabled, override or rollout
+	Source string `json:"source"`
+}
+
+// TenantFeatureFlagOverride defines model for TenantFeatureFlagOverride.
+type TenantFeatureFlagOverride struct {
+	Enabled bool `json:"enabled"`
 }
 
 // TenantFeatureLicenses License info per feature for a tenant. Key is the feature name. Only features enabled in TenantFeatures should have an entry.
@@ -556,6 +1985,123 @@ type TenantFeatureLicenses map[string]struct {
 // TenantFeatures Dynamic feature flags for tenants. Each key represents a feature name and the boolean value indicates if it's enabled
 type TenantFeatures map[string]bool
 
+// TenantExport defines model for TenantExport.
+type TenantExport struct {
+	CompletedAt *time.Time          `json:"completedAt,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	Dataset     TenantExportDataset `json:"dataset"`
+
+	// Destination download keeps the file on the platform; bucket writes it to the bucket of the export_bucket_url tenant config
+	Destination TenantExportDestination `json:"destination"`
+
+	// Error Why the export failed
+	Error *string `json:"error,omitempty"`
+
+	// FileName Name of the file once completed; in the bucket destination it is the object key
+	FileName *string `json:"fileName,omitempty"`
+
+	// Format ocsf writes OCSF 1.1 events as JSON Lines and only applies to the audit dataset
+	Format      TenantExportFormat `json:"format"`
+	From        time.Time          `json:"from"`
+	Id          openapi_types.UUID `json:"id"`
+	RequestedBy string             `json:"requestedBy"`
+	RowCount    int64              `json:"rowCount"`
+
+	// ScheduleId Set when the export was created by a schedule
+	ScheduleId *openapi_types.UUID `json:"scheduleId,omitempty"`
+	Status     TenantExportStatus  `json:"status"`
+	To         time.Time           `json:"to"`
+}
+
+// TenantExportDataset defines model for TenantExportDataset.
+type TenantExportDataset string
+
+// TenantExportDestination download keeps the file on the platform; bucket writes it to the bucket of the export_bucket_url tenant config
+type TenantExportDestination string
+
+// TenantExportFormat ocsf writes OCSF 1.1 events as JSON Lines and only applies to the audit dataset
+type TenantExportFormat string
+
+// TenantExportFrequency defines model for TenantExportFrequency.
+type TenantExportFrequency string
+
+// TenantExportSchedule defines model for TenantExportSchedule.
+type TenantExportSchedule struct {
+	CreatedAt time.Time           `json:"createdAt"`
+	CreatedBy string              `json:"createdBy"`
+	Dataset   TenantExportDataset `json:"dataset"`
+
+	// Destination download keeps the file on the platform; bucket writes it to the bucket of the export_bucket_url tenant config
+	Destination TenantExportDestination `json:"destination"`
+
+	// Format ocsf writes OCSF 1.1 events as JSON Lines and only applies to the audit dataset
+	Format    TenantExportFormat    `json:"format"`
+	Frequency TenantExportFrequency `json:"frequency"`
+	Id        openapi_types.UUID    `json:"id"`
+
+	// NextRunAt End of the next period to export, when it will run
+	NextRunAt time.Time `json:"nextRunAt"`
+}
+
+// TenantExportStatus defines model for TenantExportStatus.
+type TenantExportStatus string
+
+// TenantFileEncryption defines model for TenantFileEncryption.
+type TenantFileEncryption struct {
+	EnabledAt time.Time `json:"enabledAt"`
+	EnabledBy string    `json:"enabledBy"`
+
+	// FilesRewritten Number of files encrypted again by the last rewrite
+	FilesRewritten int32     `json:"filesRewritten"`
+	KeyCreatedAt   time.Time `json:"keyCreatedAt"`
+
+	// KeyId ID of the active data key of the tenant
+	KeyId openapi_types.UUID `json:"keyId"`
+
+	// LastError Why the last rewrite failed
+	LastError          *string    `json:"lastError,omitempty"`
+	RewriteCompletedAt *time.Time `json:"rewriteCompletedAt,omitempty"`
+	RewriteRequestedAt time.Time  `json:"rewriteRequestedAt"`
+	RewriteStartedAt   *time.Time `json:"rewriteStartedAt,omitempty"`
+
+	// RewriteStatus idle, pending, running or failed; pending and running files may still use a rotated key or be stored in clear
+	RewriteStatus string `json:"rewriteStatus"`
+
+	// RotatedKeys Number of rotated keys not yet retired
+	RotatedKeys int64  `json:"rotatedKeys"`
+	TenantId    string `json:"tenantId"`
+}
+
+// TenantIsolationProbeResult defines model for TenantIsolationProbeResult.
+type TenantIsolationProbeResult struct {
+	// AttackerTenantId Tenant the forged request acted from
+	AttackerTenantId string    `json:"attackerTenantId"`
+	CreatedAt        time.Time `json:"createdAt"`
+
+	// Detail Response body of a leak or an error
+	Detail  string                            `json:"detail"`
+	Id      openapi_types.UUID                `json:"id"`
+	Outcome TenantIsolationProbeResultOutcome `json:"outcome"`
+
+	// Probe Name of the probe, e.g. user, client_application, api_token or tenant_export
+	Probe string `json:"probe"`
+
+	// ResourceId Requested resource, empty when the probe was skipped
+	ResourceId string `json:"resourceId"`
+
+	// RunId Run the probe belongs to, a run probes two tenants both ways
+	RunId openapi_types.UUID `json:"runId"`
+
+	// StatusCode Status of the response, 0 when no request was made
+	StatusCode int `json:"statusCode"`
+
+	// VictimTenantId Tenant owning the requested resource
+	VictimTenantId string `json:"victimTenantId"`
+}
+
+// TenantIsolationProbeResultOutcome defines model for TenantIsolationProbeResult.Outcome.
+type TenantIsolationProbeResultOutcome string
+
 // TenantProfile defines model for TenantProfile.
 type TenantProfile struct {
 	DarkColors struct {
@@ -642,6 +2188,427 @@ type TenantProfile struct {
 	Values *string `json:"values,omitempty"`
 }
 
+// TenantProvisionRequest defines model for TenantProvisionRequest.
+type TenantProvisionRequest struct {
+	// AllowPasswordSignUp Defaults to the option of the source tenant
+	AllowPasswordSignUp *bool `json:"allowPasswordSignUp,omitempty"`
+
+	// AllowSignUp Defaults to the option of the source tenant
+	AllowSignUp *bool `json:"allowSignUp,omitempty"`
+
+	// Name Name of the new tenant, also its display name
+	Name      string `json:"name"`
+	Subdomain string `json:"subdomain"`
+}
+
+// TenantProvisioning defines model for TenantProvisioning.
+type TenantProvisioning struct {
+	// SourceTenantId Tenant the new tenant was copied from
+	SourceTenantId string                   `json:"sourceTenantId"`
+	Steps          []TenantProvisioningStep `json:"steps"`
+
+	// Template Template the tenant was provisioned from, if any
+	Template *string `json:"template,omitempty"`
+	Tenant   Tenant  `json:"tenant"`
+}
+
+// TenantProvisioningStep defines model for TenantProvisioningStep.
+type TenantProvisioningStep struct {
+	// Copied Items copied into the new tenant
+	Copied int64 `json:"copied"`
+
+	// Error Why the branding or module step failed, the tenant being created without that part
+	Error *string `json:"error,omitempty"`
+
+	// Name settings, setting_values, roles, email_templates, branding, or module:<name>
+	Name string `json:"name"`
+}
+
+// TenantPurge defines model for TenantPurge.
+type TenantPurge struct {
+	Attempts    int                `json:"attempts"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Id          openapi_types.UUID `json:"id"`
+	LastError   *string            `json:"lastError,omitempty"`
+	Name        string             `json:"name"`
+
+	// PreviousStatus Status the tenant gets back when the purge is cancelled
+	PreviousStatus string `json:"previousStatus"`
+
+	// PurgeAfter End of the grace period, when the purge may start
+	PurgeAfter  time.Time  `json:"purgeAfter"`
+	Reason      *string    `json:"reason,omitempty"`
+	RequestedBy string     `json:"requestedBy"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+
+	// Status scheduled, running, completed, failed or cancelled
+	Status string `json:"status"`
+
+	// Steps Report of the steps run so far
+	Steps    []TenantPurgeStep `json:"steps"`
+	TenantId string            `json:"tenantId"`
+}
+
+// TenantPurgeReport defines model for TenantPurgeReport.
+type TenantPurgeReport struct {
+	DryRun   bool              `json:"dryRun"`
+	Steps    []TenantPurgeStep `json:"steps"`
+	TenantId string            `json:"tenantId"`
+}
+
+// TenantPurgeRequest defines model for TenantPurgeRequest.
+type TenantPurgeRequest struct {
+	// Reason Why the tenant is purged, kept as the reason of its status
+	Reason *string `json:"reason,omitempty"`
+}
+
+// TenantPurgeStep defines model for TenantPurgeStep.
+type TenantPurgeStep struct {
+	// Deleted Items deleted, or to delete on a dry run
+	Deleted int64 `json:"deleted"`
+
+	// Details Deletions by table, prefix or kind of item
+	Details *map[string]int64 `json:"details,omitempty"`
+	Error   *string           `json:"error,omitempty"`
+
+	// Name data, users, files, auth_provider, tenant, or module:<name>
+	Name string `json:"name"`
+}
+
+// TenantQuotaLimits Limits of the resources of a tenant, a null limit does not limit the resource
+type TenantQuotaLimits struct {
+	MaxClientApplications *int32 `json:"maxClientApplications"`
+
+	// MaxPromptExecutionsPerMonth Prompt executions per calendar month, UTC
+	MaxPromptExecutionsPerMonth *int32 `json:"maxPromptExecutionsPerMonth"`
+
+	// MaxStorageMb Megabytes of files stored under the prefix of the tenant
+	MaxStorageMb *int32 `json:"maxStorageMb"`
+
+	// MaxUsers Members of the tenant, the removed ones aside
+	MaxUsers *int32 `json:"maxUsers"`
+}
+
+// TenantQuotaResource defines model for TenantQuotaResource.
+type TenantQuotaResource struct {
+	// Exceeded The limit is reached, no more of the resource can be added
+	Exceeded bool `json:"exceeded"`
+
+	// Limit Not limited when null
+	Limit *int32 `json:"limit"`
+
+	// Resource users, client_applications, storage_mb or prompt_executions_per_month
+	Resource string `json:"resource"`
+
+	// Used Current usage; megabytes rounded up for the storage, executions of the current month for the prompts
+	Used int64 `json:"used"`
+}
+
+// TenantQuotaUsage defines model for TenantQuotaUsage.
+type TenantQuotaUsage struct {
+	Resources []TenantQuotaResource `json:"resources"`
+	TenantId  string                `json:"tenantId"`
+}
+
+// TenantSandbox defines model for TenantSandbox.
+type TenantSandbox struct {
+	CreatedAt      time.Time           `json:"createdAt"`
+	CreatedBy      string              `json:"createdBy"`
+	LastResetAt    *time.Time          `json:"lastResetAt,omitempty"`
+	LastResetBy    *string             `json:"lastResetBy,omitempty"`
+	LastResetJobId *openapi_types.UUID `json:"lastResetJobId,omitempty"`
+
+	// LastResetStatus Status of the last reset job, running, completed or failed
+	LastResetStatus *string `json:"lastResetStatus,omitempty"`
+	ResetRunning    bool    `json:"resetRunning"`
+	TenantId        string  `json:"tenantId"`
+
+	// UpdatedAt Last time the template was replaced
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TenantSandboxResetPlan defines model for TenantSandboxResetPlan.
+type TenantSandboxResetPlan struct {
+	// ConfirmationToken Hash of the plan, pass it as confirm to start the reset
+	ConfirmationToken string `json:"confirmationToken"`
+
+	// JobId Job of the started reset
+	JobId *openapi_types.UUID `json:"jobId,omitempty"`
+
+	// MembersToRemove Members removed from the tenant; those without another tenant are deleted
+	MembersToRemove int `json:"membersToRemove"`
+
+	// SettingsChanges Changes re-applying the template
+	SettingsChanges []TenantSettingsChange `json:"settingsChanges"`
+
+	// Steps Data wiped by modules, such as prompts, executions and files
+	Steps    []string `json:"steps"`
+	TenantId string   `json:"tenantId"`
+}
+
+// TenantSettingDefinition defines model for TenantSettingDefinition.
+type TenantSettingDefinition struct {
+	// Default Value of the tenants that did not set the key
+	Default     interface{} `json:"default"`
+	Description string      `json:"description"`
+	Key         string      `json:"key"`
+
+	// Public Served to the unauthenticated frontends
+	Public bool `json:"public"`
+
+	// Schema JSON schema the values of the key must match
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// TenantSettingValues defines model for TenantSettingValues.
+type TenantSettingValues struct {
+	Definitions []TenantSettingDefinition `json:"definitions"`
+
+	// Values Value of every setting by key
+	Values map[string]interface{} `json:"values"`
+}
+
+// TenantSettingValuesUpdate defines model for TenantSettingValuesUpdate.
+type TenantSettingValuesUpdate struct {
+	// Values New value of each key to set, null to reset the key to its default
+	Values map[string]interface{} `json:"values"`
+}
+
+// TenantSettings defines model for TenantSettings.
+type TenantSettings struct {
+	AllowPasswordSignUp bool `json:"allowPasswordSignUp"`
+	AllowSignUp         bool `json:"allowSignUp"`
+
+	// Configs Tenant configs by name
+	Configs map[string]*string `json:"configs"`
+
+	// Features Dynamic feature flags for tenants. Each key represents a feature name and the boolean value indicates if it's enabled
+	Features TenantFeatures `json:"features"`
+	Profile  TenantProfile  `json:"profile"`
+}
+
+// TenantSettingsChange defines model for TenantSettingsChange.
+type TenantSettingsChange struct {
+	NewValue *interface{}           `json:"newValue"`
+	OldValue *interface{}           `json:"oldValue"`
+	Op       TenantSettingsChangeOp `json:"op"`
+
+	// Path Dotted path of the setting, e.g. features.chat or configs.support_email
+	Path string `json:"path"`
+}
+
+// TenantSettingsChangeOp defines model for TenantSettingsChange.Op.
+type TenantSettingsChangeOp string
+
+// TenantSettingsDocument defines model for TenantSettingsDocument.
+type TenantSettingsDocument struct {
+	ExportedAt     time.Time      `json:"exportedAt"`
+	Settings       TenantSettings `json:"settings"`
+	SourceTenantId string         `json:"sourceTenantId"`
+
+	// Version Document format version
+	Version int32 `json:"version"`
+}
+
+// TenantSettingsImportPlan defines model for TenantSettingsImportPlan.
+type TenantSettingsImportPlan struct {
+	Applied bool                   `json:"applied"`
+	Changes []TenantSettingsChange `json:"changes"`
+
+	// Fingerprint Hash of the changes, pass it as confirm to apply them
+	Fingerprint string `json:"fingerprint"`
+}
+
+// TenantStatus Lifecycle of the tenant. The requests to a suspended tenant are refused
+// with 423; an archived tenant answers 404 and is left out of the listings.
+// A tenant pending_deletion answers 404 until its purge; it is set and
+// left by scheduling and cancelling the purge only.
+type TenantStatus string
+
+// TenantStatusChange defines model for TenantStatusChange.
+type TenantStatusChange struct {
+	// Reason Why the status changes, kept with the tenant
+	Reason *string `json:"reason,omitempty"`
+
+	// Status Lifecycle of the tenant. The requests to a suspended tenant are refused
+	// with 423; an archived tenant answers 404 and is left out of the listings.
+	// A tenant pending_deletion answers 404 until its purge; it is set and
+	// left by scheduling and cancelling the purge only.
+	Status TenantStatus `json:"status"`
+}
+
+// TenantSubdomain defines model for TenantSubdomain.
+type TenantSubdomain struct {
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"`
+
+	// Primary Aliases redirect browsers to the primary subdomain
+	Primary   bool   `json:"primary"`
+	Subdomain string `json:"subdomain"`
+}
+
+// TenantTemplate defines model for TenantTemplate.
+type TenantTemplate struct {
+	CreatedAt   time.Time `json:"createdAt"`
+	CreatedBy   string    `json:"createdBy"`
+	Description *string   `json:"description,omitempty"`
+	Name        string    `json:"name"`
+
+	// TenantId Tenant the template copies
+	TenantId  string    `json:"tenantId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TenantTemplateRequest defines model for TenantTemplateRequest.
+type TenantTemplateRequest struct {
+	Description *string `json:"description,omitempty"`
+
+	// TenantId ID of the tenant the template copies
+	TenantId openapi_types.UUID `json:"tenantId"`
+}
+
+// TenantUsage defines model for TenantUsage.
+type TenantUsage struct {
+	// ApiTokens Current API tokens neither revoked nor expired
+	ApiTokens int64                   `json:"apiTokens"`
+	Daily     []TenantUsageDailyCount `json:"daily"`
+	From      time.Time               `json:"from"`
+
+	// StorageBytes Bytes currently stored under the prefix of the tenant
+	StorageBytes int64                    `json:"storageBytes"`
+	TenantId     string                   `json:"tenantId"`
+	To           time.Time                `json:"to"`
+	Totals       []TenantUsageMetricCount `json:"totals"`
+
+	// Users Current members of the tenant, the removed ones aside
+	Users int64 `json:"users"`
+}
+
+// TenantUsageDailyCount defines model for TenantUsageDailyCount.
+type TenantUsageDailyCount struct {
+	Count  int64              `json:"count"`
+	Day    openapi_types.Date `json:"day"`
+	Metric string             `json:"metric"`
+}
+
+// TenantUsageMetricCount defines model for TenantUsageMetricCount.
+type TenantUsageMetricCount struct {
+	Count int64 `json:"count"`
+
+	// Metric api_calls, llm_tokens, users_added or api_tokens_created
+	Metric string `json:"metric"`
+}
+
+// TenantUsageRecommendation defines model for TenantUsageRecommendation.
+type TenantUsageRecommendation struct {
+	// DailyRate Growth of the resource per day, 0 when it has no metered trend
+	DailyRate float64 `json:"dailyRate"`
+	Limit     int32   `json:"limit"`
+
+	// LimitReachedAt When the limit is projected to be reached, null when it is reached already
+	LimitReachedAt *time.Time `json:"limitReachedAt"`
+	Message        string     `json:"message"`
+
+	// Resource users, client_applications, storage_mb or prompt_executions_per_month
+	Resource string                            `json:"resource"`
+	Severity TenantUsageRecommendationSeverity `json:"severity"`
+
+	// SuggestedLimit Limit covering the projected usage of the next 90 days, or of the month for the prompt executions, with 20% headroom
+	SuggestedLimit int64 `json:"suggestedLimit"`
+	Used           int64 `json:"used"`
+}
+
+// TenantUsageRecommendationSeverity defines model for TenantUsageRecommendation.Severity.
+type TenantUsageRecommendationSeverity string
+
+// TenantUsageRecommendations defines model for TenantUsageRecommendations.
+type TenantUsageRecommendations struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// Recommendations The reached limits first, then the soonest reached
+	Recommendations []TenantUsageRecommendation `json:"recommendations"`
+	TenantId        string                      `json:"tenantId"`
+}
+
+// TenantUsageTotals defines model for TenantUsageTotals.
+type TenantUsageTotals struct {
+	TenantId string                   `json:"tenantId"`
+	Totals   []TenantUsageMetricCount `json:"totals"`
+}
+
+// TenantsUsage defines model for TenantsUsage.
+type TenantsUsage struct {
+	From    time.Time           `json:"from"`
+	Tenants []TenantUsageTotals `json:"tenants"`
+	To      time.Time           `json:"to"`
+}
+
+// TokenIntrospectionRequest defines model for TokenIntrospectionRequest.
+type TokenIntrospectionRequest struct {
+	// ClientId Optional when the client authenticates with HTTP Basic
+	ClientId     *string `json:"client_id,omitempty"`
+	ClientSecret *string `json:"client_secret,omitempty"`
+	Token        string  `json:"token"`
+
+	// TokenTypeHint Ignored, API tokens and access tokens are told apart by their format
+	TokenTypeHint *string `json:"token_type_hint,omitempty"`
+}
+
+// TokenIntrospectionResponse defines model for TokenIntrospectionResponse.
+type TokenIntrospectionResponse struct {
+	Active bool `json:"active"`
+
+	// ClientId Client application the token belongs to
+	ClientId *string `json:"client_id,omitempty"`
+	Exp      *int64  `json:"exp,omitempty"`
+	Iat      *int64  `json:"iat,omitempty"`
+
+	// Scope Space separated scopes of the token
+	Scope *string `json:"scope,omitempty"`
+
+	// Sub Creator of an API token, client application of an access token
+	Sub       *string                              `json:"sub,omitempty"`
+	TenantId  *string                              `json:"tenant_id,omitempty"`
+	TokenType *TokenIntrospectionResponseTokenType `json:"token_type,omitempty"`
+}
+
+// TokenIntrospectionResponseTokenType defines model for TokenIntrospectionResponse.TokenType.
+type TokenIntrospectionResponseTokenType string
+
+// TokenPolicy defines model for TokenPolicy.
+type TokenPolicy struct {
+	// AllowedScopes Scopes tokens can be granted, wildcards included, any scope when null
+	AllowedScopes *[]string `json:"allowedScopes"`
+
+	// MaxExpiryDays Longest expiry of the tokens, not limited beyond the global maximum when null
+	MaxExpiryDays *int32 `json:"maxExpiryDays"`
+
+	// RequireDescription Refuse the tokens created without a description
+	RequireDescription bool      `json:"requireDescription"`
+	TenantId           string    `json:"tenantId"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+	UpdatedBy          string    `json:"updatedBy"`
+}
+
+// TokenPolicyUpdate defines model for TokenPolicyUpdate.
+type TokenPolicyUpdate struct {
+	// AllowedScopes Scopes tokens can be granted, wildcards included, any scope when null
+	AllowedScopes *[]string `json:"allowedScopes"`
+
+	// MaxExpiryDays Longest expiry of the tokens, not limited beyond the global maximum when null
+	MaxExpiryDays *int32 `json:"maxExpiryDays"`
+
+	// RequireDescription Refuse the tokens created without a description
+	RequireDescription *bool `json:"requireDescription,omitempty"`
+}
+
+// TokenScope defines model for TokenScope.
+type TokenScope struct {
+	Description string `json:"description"`
+	Name        string `json:"name"`
+}
+
 // Translation defines model for Translation.
 type Translation struct {
 	CreatedAt  time.Time          `json:"created_at"`
@@ -655,6 +2622,18 @@ type Translation struct {
 	Value      string             `json:"value"`
 }
 
+// UnverifiedUser defines model for UnverifiedUser.
+type UnverifiedUser struct {
+	CreatedAt   time.Time  `json:"createdAt"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
+
+	// LastVerificationSentAt Last verification email on record, the expired ones are cleaned up
+	LastVerificationSentAt *time.Time `json:"lastVerificationSentAt,omitempty"`
+	Name                   string     `json:"name"`
+	UserId                 string     `json:"userId"`
+}
+
 // User defines model for User.
 type User struct {
 	CreatedAt     *time.Time `json:"created_at,omitempty"`
@@ -663,6 +2642,15 @@ type User struct {
 	EmailVerified *bool      `json:"email_verified,omitempty"`
 	Id            string     `json:"id"`
 
+	// Labels Labels of the user in the tenant, in the tenant lists
+	Labels *[]string `json:"labels,omitempty"`
+
+	// LastLoginAt First verified request of the latest session, in the tenant or, on the admin domain, in any tenant
+	LastLoginAt *time.Time `json:"last_login_at"`
+
+	// LastLoginIp Client IP of the latest login
+	LastLoginIp *string `json:"last_login_ip"`
+
 	// MembershipStatus Membership status (active, inactive, etc.)
 	MembershipStatus *string            `json:"membership_status"`
 	Name             string             `json:"name"`
@@ -679,25 +2667,263 @@ type UserActionSchema struct {
 // UserActionSchemaName defines model for UserActionSchema.Name.
 type UserActionSchemaName string
 
+// UserAttribute defines model for UserAttribute.
+type UserAttribute struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// EnumValues Accepted values of an enum
+	EnumValues *[]string `json:"enumValues,omitempty"`
+	Key        string    `json:"key"`
+	Label      string    `json:"label"`
+	Required   *bool     `json:"required,omitempty"`
+
+	// Type string, number, boolean, date (YYYY-MM-DD) or enum
+	Type      string    `json:"type"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UserAuditLog defines model for UserAuditLog.
+type UserAuditLog struct {
+	// Action What was done to the account, e.g. role_assigned or password_reset
+	Action string `json:"action"`
+
+	// ActorId User who made the change, empty for system changes
+	ActorId *string `json:"actorId"`
+
+	// Details Change specific details such as the role or the new status
+	Details    *map[string]interface{} `json:"details"`
+	Id         openapi_types.UUID      `json:"id"`
+	OccurredAt time.Time               `json:"occurredAt"`
+}
+
+// UserImpersonation defines model for UserImpersonation.
+type UserImpersonation struct {
+	// ActorId The super admin acting as the user
+	ActorId   string             `json:"actorId"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	Id        openapi_types.UUID `json:"id"`
+	Reason    string             `json:"reason"`
+	TenantId  string             `json:"tenantId"`
+
+	// Token Send it in the X-Impersonation-Token header, it is not returned again
+	Token  string `json:"token"`
+	UserId string `json:"userId"`
+}
+
+// UserImpersonationRequest defines model for UserImpersonationRequest.
+type UserImpersonationRequest struct {
+	// Reason Why the user is impersonated, such as a support ticket
+	Reason string `json:"reason"`
+}
+
+// UserImportJob defines model for UserImportJob.
+type UserImportJob struct {
+	// AlreadyExists Number of users already in the tenant
+	AlreadyExists int                  `json:"alreadyExists"`
+	CompletedAt   *time.Time           `json:"completedAt"`
+	CreatedAt     time.Time            `json:"createdAt"`
+	CreatedBy     string               `json:"createdBy"`
+	Errors        []UserImportRowError `json:"errors"`
+
+	// Failed Number of rows rejected or that failed to be created
+	Failed int                `json:"failed"`
+	Id     openapi_types.UUID `json:"id"`
+
+	// Processed Number of rows read so far
+	Processed int `json:"processed"`
+
+	// Progress Estimated progress, from 0 to 100
+	Progress int `json:"progress"`
+
+	// Status running, completed or failed
+	Status string `json:"status"`
+
+	// Success Number of users created
+	Success int `json:"success"`
+}
+
+// UserImportMapping defines model for UserImportMapping.
+type UserImportMapping struct {
+	// AdminValues Values of the is_customer_admin column giving the CUSTOMER_ADMIN role. When empty
+	// the column is read as a boolean.
+	AdminValues *[]string `json:"adminValues,omitempty"`
+
+	// Columns Name of the column of each field in the file, by field (lastname, firstname, email,
+	// is_customer_admin, silent). Fields not listed use their name as column name.
+	Columns *map[string]string `json:"columns,omitempty"`
+
+	// Delimiter Single character separating the fields, ";" by default
+	Delimiter *string `json:"delimiter,omitempty"`
+
+	// Locale Language of the boolean values, such as fr for oui/non. English values are always accepted.
+	Locale *string `json:"locale,omitempty"`
+}
+
+// UserImportReport defines model for UserImportReport.
+type UserImportReport struct {
+	// AlreadyExists Number of users already in the tenant, an import skips them
+	AlreadyExists int                  `json:"alreadyExists"`
+	Errors        []UserImportRowError `json:"errors"`
+
+	// Invalid Number of rows an import would reject
+	Invalid int `json:"invalid"`
+
+	// Total Number of rows read
+	Total int `json:"total"`
+
+	// Valid Number of users an import would create
+	Valid int `json:"valid"`
+}
+
+// UserImportRowError defines model for UserImportRowError.
+type UserImportRowError struct {
+	// Code invalid_row, invalid_email, duplicate, forbidden or email_exists
+	Code  string  `json:"code"`
+	Email *string `json:"email,omitempty"`
+	Error string  `json:"error"`
+
+	// Line Line number in CSV, the header being line 1
+	Line int `json:"line"`
+}
+
+// UserInvitation defines model for UserInvitation.
+type UserInvitation struct {
+	AcceptedAt *time.Time         `json:"acceptedAt"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	Email      string             `json:"email"`
+	ExpiresAt  time.Time          `json:"expiresAt"`
+	Id         openapi_types.UUID `json:"id"`
+
+	// InvitedBy User who sent the invitation
+	InvitedBy string `json:"invitedBy"`
+	Roles     []Role `json:"roles"`
+	SendCount int32  `json:"sendCount"`
+
+	// SentAt Last time the invitation was sent
+	SentAt time.Time            `json:"sentAt"`
+	Status UserInvitationStatus `json:"status"`
+
+	// UserId User who accepted the invitation
+	UserId *string `json:"userId"`
+}
+
+// UserInvitationStatus defines model for UserInvitation.Status.
+type UserInvitationStatus string
+
+// UserInvitationToken defines model for UserInvitationToken.
+type UserInvitationToken struct {
+	// Token Token of the link sent by email
+	Token string `json:"token"`
+}
+
+// UserLabels defines model for UserLabels.
+type UserLabels struct {
+	// Labels Labels of the user in the tenant, lowercase and sorted
+	Labels []string `json:"labels"`
+}
+
+// UserList defines model for UserList.
+type UserList struct {
+	// Items The users of the page, or their id and name with detail=basic
+	Items    []interface{} `json:"items"`
+	Page     int32         `json:"page"`
+	PageSize int32         `json:"pageSize"`
+
+	// Total Number of users matching the filters across all pages
+	Total int64 `json:"total"`
+}
+
+// UserMerge defines model for UserMerge.
+type UserMerge struct {
+	// DuplicateUserId The user merged into the primary user, then disabled
+	DuplicateUserId string `json:"duplicateUserId"`
+
+	// PrimaryUserId The user kept
+	PrimaryUserId string `json:"primaryUserId"`
+}
+
+// UserMergeMembership defines model for UserMergeMembership.
+type UserMergeMembership struct {
+	// Action moved when the primary user had no membership of the tenant, merged when the roles and labels were added to theirs
+	Action UserMergeMembershipAction `json:"action"`
+
+	// Roles Roles of the primary user in the tenant after the merge
+	Roles    []string `json:"roles"`
+	TenantId string   `json:"tenantId"`
+}
+
+// UserMergeMembershipAction moved when the primary user had no membership of the tenant, merged when the roles and labels were added to theirs
+type UserMergeMembershipAction string
+
+// UserMergeReport defines model for UserMergeReport.
+type UserMergeReport struct {
+	// Disabled Whether the duplicate is disabled
+	Disabled        bool   `json:"disabled"`
+	DryRun          bool   `json:"dryRun"`
+	DuplicateUserId string `json:"duplicateUserId"`
+
+	// Files Profile picture files copied to the primary user
+	Files []string `json:"files"`
+
+	// Kept Number of rows left to the duplicate per table.column, as the primary user already had the same row
+	Kept          map[string]int64      `json:"kept"`
+	Memberships   []UserMergeMembership `json:"memberships"`
+	PrimaryUserId string                `json:"primaryUserId"`
+
+	// Resources Number of owned resources transferred
+	Resources int `json:"resources"`
+
+	// Rows Number of rows moved to the primary user per table.column
+	Rows map[string]int64 `json:"rows"`
+}
+
+// UserOnboarding defines model for UserOnboarding.
+type UserOnboarding struct {
+	// Completed Whether every step is completed
+	Completed bool                 `json:"completed"`
+	Steps     []UserOnboardingStep `json:"steps"`
+}
+
+// UserOnboardingStep defines model for UserOnboardingStep.
+type UserOnboardingStep struct {
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// Step profile_completed, email_verified, first_login, mfa_enrolled or a step registered by the product
+	Step string `json:"step"`
+}
+
+// UserOnboardingUpdate defines model for UserOnboardingUpdate.
+type UserOnboardingUpdate struct {
+	// Steps Steps to mark completed, true, or not, false
+	Steps map[string]bool `json:"steps"`
+}
+
 // UserProfileSchema defines model for UserProfileSchema.
 type UserProfileSchema struct {
-	About                *string   `json:"about,omitempty"`
-	BackgroundPictureURL *string   `json:"backgroundPictureURL,omitempty"`
-	Company              *string   `json:"company,omitempty"`
-	Function             *string   `json:"function,omitempty"`
-	Interests            *[]string `json:"interests,omitempty"`
+	About *string `json:"about,omitempty"`
+
+	// Attributes Values of the custom attributes defined by the tenants of the user
+	Attributes           *map[string]interface{} `json:"attributes,omitempty"`
+	BackgroundPictureURL *string                 `json:"backgroundPictureURL,omitempty"`
+	Company              *string                 `json:"company,omitempty"`
+	Function             *string                 `json:"function,omitempty"`
+	Interests            *[]string               `json:"interests,omitempty"`
 
 	// IsActingReseller Whether the current tenant is a reseller of a the tenant (read-only, derived from auth claims)
 	IsActingReseller *bool `json:"is_acting_reseller,omitempty"`
 
 	// IsReseller Whether the current tenant is a reseller (read-only, derived from auth claims)
-	IsReseller   *bool     `json:"is_reseller,omitempty"`
-	Name         string    `json:"name"`
-	PhoneNumber  *string   `json:"phoneNumber,omitempty"`
-	PictureURL   *string   `json:"pictureURL,omitempty"`
-	Skills       *[]string `json:"skills,omitempty"`
-	SocialMedias *[]string `json:"socialMedias,omitempty"`
-	Title        *string   `json:"title,omitempty"`
+	IsReseller  *bool   `json:"is_reseller,omitempty"`
+	Name        string  `json:"name"`
+	PhoneNumber *string `json:"phoneNumber,omitempty"`
+
+	// PhoneNumberVerified Whether the phone number was confirmed by SMS (read-only)
+	PhoneNumberVerified *bool     `json:"phoneNumberVerified,omitempty"`
+	PictureURL          *string   `json:"pictureURL,omitempty"`
+	Skills              *[]string `json:"skills,omitempty"`
+	SocialMedias        *[]string `json:"socialMedias,omitempty"`
+	Title               *string   `json:"title,omitempty"`
 }
 
 // InternalServerError defines model for InternalServerError.
@@ -711,3 +2937,59 @@ type UnauthorizedError struct {
 	Error   *string `json:"error,omitempty"`
 	Message *string `json:"message,omitempty"`
 }
+
+// UserSession defines model for UserSession.
+type UserSession struct {
+	// Aal Authenticator assurance level, aal1 or aal2
+	Aal             *string    `json:"aal,omitempty"`
+	AuthenticatedAt *time.Time `json:"authenticatedAt,omitempty"`
+
+	// Current The session of the request
+	Current   bool       `json:"current"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Id        string     `json:"id"`
+
+	// IpAddress Address of the last device using the session
+	IpAddress *string    `json:"ipAddress,omitempty"`
+	IssuedAt  *time.Time `json:"issuedAt,omitempty"`
+	Location  *string    `json:"location,omitempty"`
+
+	// Methods Sign-in methods, like password or totp
+	Methods   []string `json:"methods"`
+	UserAgent *string  `json:"userAgent,omitempty"`
+}
+
+// UserTimelineEvent defines model for UserTimelineEvent.
+type UserTimelineEvent struct {
+	// ActorId User who performed the action when it was not the user themselves
+	ActorId *string `json:"actorId"`
+
+	// Category Event category (account, api_token, file, login, membership, prompt)
+	Category string                  `json:"category"`
+	Data     *map[string]interface{} `json:"data"`
+
+	// EventType What happened within the category, e.g. role_assigned or logged_in
+	EventType  string             `json:"eventType"`
+	Id         openapi_types.UUID `json:"id"`
+	OccurredAt time.Time          `json:"occurredAt"`
+}
+
+// UserVerificationReport defines model for UserVerificationReport.
+type UserVerificationReport struct {
+	// Disabled Disabled users, also counted as verified or unverified
+	Disabled    int64     `json:"disabled"`
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// OlderThanDays Age in days of the unverified accounts listed
+	OlderThanDays int32 `json:"olderThanDays"`
+
+	// StaleUnverified Enabled unverified accounts older than olderThanDays
+	StaleUnverified int64 `json:"staleUnverified"`
+	Total           int64 `json:"total"`
+
+	// Truncated More accounts than the limit are unverified, the oldest are listed
+	Truncated  bool             `json:"truncated"`
+	Unverified int64            `json:"unverified"`
+	Users      []UnverifiedUser `json:"users"`
+	Verified   int64            `json:"verified"`
+}
@@ -6,6 +6,7 @@ package core
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oapi-codegen/runtime"
@@ -35,6 +36,18 @@ const (
 	ListTenantConfigsParamsOrderDesc ListTenantConfigsParamsOrder = "desc"
 )
 
+// Defines values for ListTenantClientApplicationsParamsOrder.
+const (
+	ListTenantClientApplicationsParamsOrderAsc  ListTenantClientApplicationsParamsOrder = "asc"
+	ListTenantClientApplicationsParamsOrderDesc ListTenantClientApplicationsParamsOrder = "desc"
+)
+
+// Defines values for ListTenantAPITokensParamsOrder.
+const (
+	ListTenantAPITokensParamsOrderAsc  ListTenantAPITokensParamsOrder = "asc"
+	ListTenantAPITokensParamsOrderDesc ListTenantAPITokensParamsOrder = "desc"
+)
+
 // Defines values for ListTranslationsParamsOrder.
 const (
 	ListTranslationsParamsOrderAsc  ListTranslationsParamsOrder = "asc"
@@ -71,18 +84,53 @@ const (
 	Global ListUsersParamsScope = "global"
 )
 
+// Defines values for ListUserInvitationsParamsStatus.
+const (
+	ListUserInvitationsParamsStatusAccepted ListUserInvitationsParamsStatus = "accepted"
+	ListUserInvitationsParamsStatusExpired  ListUserInvitationsParamsStatus = "expired"
+	ListUserInvitationsParamsStatusPending  ListUserInvitationsParamsStatus = "pending"
+	ListUserInvitationsParamsStatusRevoked  ListUserInvitationsParamsStatus = "revoked"
+)
+
+// Defines values for DeleteUserParamsMode.
+const (
+	DeleteUserParamsModeAnonymize DeleteUserParamsMode = "anonymize"
+	DeleteUserParamsModeDelete    DeleteUserParamsMode = "delete"
+)
+
 // Defines values for UpdateUserStatusJSONBodyName.
 const (
 	UpdateUserStatusJSONBodyNameDISABLED      UpdateUserStatusJSONBodyName = "DISABLED"
 	UpdateUserStatusJSONBodyNameEMAILVERIFIED UpdateUserStatusJSONBodyName = "EMAIL_VERIFIED"
 )
 
+// Defines values for GetProfilePictureParamsSize.
+const (
+	GetProfilePictureParamsSizeLarge  GetProfilePictureParamsSize = "large"
+	GetProfilePictureParamsSizeMedium GetProfilePictureParamsSize = "medium"
+	GetProfilePictureParamsSizeSmall  GetProfilePictureParamsSize = "small"
+)
+
 // Defines values for ListGlobalConfigsParamsOrder.
 const (
 	ListGlobalConfigsParamsOrderAsc  ListGlobalConfigsParamsOrder = "asc"
 	ListGlobalConfigsParamsOrderDesc ListGlobalConfigsParamsOrder = "desc"
 )
 
+// Defines values for ListTenantIsolationProbeResultsParamsOutcome.
+const (
+	ListTenantIsolationProbeResultsParamsOutcomeBlocked ListTenantIsolationProbeResultsParamsOutcome = "blocked"
+	ListTenantIsolationProbeResultsParamsOutcomeError   ListTenantIsolationProbeResultsParamsOutcome = "error"
+	ListTenantIsolationProbeResultsParamsOutcomeLeaked  ListTenantIsolationProbeResultsParamsOutcome = "leaked"
+	ListTenantIsolationProbeResultsParamsOutcomeSkipped ListTenantIsolationProbeResultsParamsOutcome = "skipped"
+)
+
+// Defines values for ExportTenantSettingsParamsFormat.
+const (
+	ExportTenantSettingsParamsFormatJson ExportTenantSettingsParamsFormat = "json"
+	ExportTenantSettingsParamsFormatYaml ExportTenantSettingsParamsFormat = "yaml"
+)
+
 // Defines values for ListTenantsParamsOrder.
 const (
 	ListTenantsParamsOrderAsc  ListTenantsParamsOrder = "asc"
@@ -156,6 +204,33 @@ type GetAPITokenAuditLogsParams struct {
 
 	// PageSize maximum number of results to return
 	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+
+	// Action only the entries of this action, such as USED
+	Action *string `form:"action,omitempty" json:"action,omitempty"`
+
+	// From only the entries written from this time (inclusive)
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only the entries written before this time (exclusive)
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// ExportAPITokenAuditLogsParams defines parameters for ExportAPITokenAuditLogs.
+type ExportAPITokenAuditLogsParams struct {
+	// Format csv (default) or ndjson
+	Format *string `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetAPITokenUsageParams defines parameters for GetAPITokenUsage.
+type GetAPITokenUsageParams struct {
+	// From start of the range (inclusive), defaults to 30 days before to
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To end of the range (exclusive), defaults to now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// TopEndpoints maximum number of endpoints to return
+	TopEndpoints *int32 `form:"topEndpoints,omitempty" json:"topEndpoints,omitempty"`
 }
 
 // ListTenantConfigsParams defines parameters for ListTenantConfigs.
@@ -195,13 +270,37 @@ type UpdateTenantConfigJSONBody struct {
 	Value *string            `json:"value,omitempty"`
 }
 
+// ListDelegationsParams defines parameters for ListDelegations.
+type ListDelegationsParams struct {
+	// All list the delegations of every user of the tenant
+	All *bool `form:"all,omitempty" json:"all,omitempty"`
+
+	// IncludeExpired include the expired and revoked delegations
+	IncludeExpired *bool `form:"includeExpired,omitempty" json:"includeExpired,omitempty"`
+}
+
+// ListJobEventsParams defines parameters for ListJobEvents.
+type ListJobEventsParams struct {
+	// Since Cursor returned by the previous call, omit to read from the first event
+	Since *string `form:"since,omitempty" json:"since,omitempty"`
+
+	// Wait Seconds to wait for new events (default 25, max 60, 0 returns immediately)
+	Wait *int `form:"wait,omitempty" json:"wait,omitempty"`
+
+	// Limit Maximum number of events to return (default 50, max 100)
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // UpdateMeProfileJSONBody defines parameters for UpdateMeProfile.
 type UpdateMeProfileJSONBody struct {
-	About                *string   `json:"about,omitempty"`
-	BackgroundPictureURL *string   `json:"backgroundPictureURL,omitempty"`
-	Company              *string   `json:"company,omitempty"`
-	Function             *string   `json:"function,omitempty"`
-	Interests            *[]string `json:"interests,omitempty"`
+	About *string `json:"about,omitempty"`
+
+	// Attributes Values of the custom attributes defined by the tenants of the user
+	Attributes           *map[string]interface{} `json:"attributes,omitempty"`
+	BackgroundPictureURL *string                 `json:"backgroundPictureURL,omitempty"`
+	Company              *string                 `json:"company,omitempty"`
+	Function             *string                 `json:"function,omitempty"`
+	Interests            *[]string               `json:"interests,omitempty"`
 
 	// IsActingReseller Whether the current tenant is a reseller of a the tenant (read-only, derived from auth claims)
 	IsActingReseller *bool `json:"is_acting_reseller,omitempty"`
@@ -236,6 +335,152 @@ type UploadTenantLogoMultipartBody struct {
 	Picture *openapi_types.File `json:"picture,omitempty"`
 }
 
+// ListTenantAnnouncementsParams defines parameters for ListTenantAnnouncements.
+type ListTenantAnnouncementsParams struct {
+	// IncludeExpired Also return expired announcements
+	IncludeExpired *bool `form:"includeExpired,omitempty" json:"includeExpired,omitempty"`
+
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// GetAuthFailureSummaryParams defines parameters for GetAuthFailureSummary.
+type GetAuthFailureSummaryParams struct {
+	// From start of the range (inclusive), defaults to 7 days before to
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To end of the range (exclusive), defaults to now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// ListTenantClientApplicationsParams defines parameters for ListTenantClientApplications.
+type ListTenantClientApplicationsParams struct {
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+
+	// SortBy field to sort by
+	SortBy *string `form:"sortBy,omitempty" json:"sortBy,omitempty"`
+
+	// Order sort order
+	Order *ListTenantClientApplicationsParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// Q search query
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// IncludeInactive include inactive applications
+	IncludeInactive *bool `form:"includeInactive,omitempty" json:"includeInactive,omitempty"`
+}
+
+// ListTenantClientApplicationsParamsOrder defines parameters for ListTenantClientApplications.
+type ListTenantClientApplicationsParamsOrder string
+
+// ListTenantAPITokensParams defines parameters for ListTenantAPITokens.
+type ListTenantAPITokensParams struct {
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+
+	// SortBy field to sort by
+	SortBy *string `form:"sortBy,omitempty" json:"sortBy,omitempty"`
+
+	// Order sort order
+	Order *ListTenantAPITokensParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// IncludeRevoked include revoked tokens
+	IncludeRevoked *bool `form:"includeRevoked,omitempty" json:"includeRevoked,omitempty"`
+
+	// IncludeExpired include expired tokens
+	IncludeExpired *bool `form:"includeExpired,omitempty" json:"includeExpired,omitempty"`
+}
+
+// ListTenantAPITokensParamsOrder defines parameters for ListTenantAPITokens.
+type ListTenantAPITokensParamsOrder string
+
+// GetTenantAPITokenAuditLogsParams defines parameters for GetTenantAPITokenAuditLogs.
+type GetTenantAPITokenAuditLogsParams struct {
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+
+	// Action only the entries of this action, such as USED
+	Action *string `form:"action,omitempty" json:"action,omitempty"`
+
+	// From only the entries written from this time (inclusive)
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To only the entries written before this time (exclusive)
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// ExportTenantAPITokenAuditLogsParams defines parameters for ExportTenantAPITokenAuditLogs.
+type ExportTenantAPITokenAuditLogsParams struct {
+	// Format csv (default) or ndjson
+	Format *string `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetTenantAPITokenUsageParams defines parameters for GetTenantAPITokenUsage.
+type GetTenantAPITokenUsageParams struct {
+	// From start of the range (inclusive), defaults to 30 days before to
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To end of the range (exclusive), defaults to now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// TopEndpoints maximum number of endpoints to return
+	TopEndpoints *int32 `form:"topEndpoints,omitempty" json:"topEndpoints,omitempty"`
+}
+
+// ListDirectorySyncConflictsParams defines parameters for ListDirectorySyncConflicts.
+type ListDirectorySyncConflictsParams struct {
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListTenantExportsParams defines parameters for ListTenantExports.
+type ListTenantExportsParams struct {
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// ListTenantLockoutsParams defines parameters for ListTenantLockouts.
+type ListTenantLockoutsParams struct {
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListSLAOverdueItemsParams defines parameters for ListSLAOverdueItems.
+type ListSLAOverdueItemsParams struct {
+	// Type item type, such as invitation; every tracked type when omitted
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+
+	// Limit maximum number of results to return
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetTenantUsageParams defines parameters for GetTenantUsage.
+type GetTenantUsageParams struct {
+	// From start of the range, widened to the start of its day (UTC), defaults to 30 days before to
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To end of the range (exclusive), defaults to now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
 // ListTranslationsParams defines parameters for ListTranslations.
 type ListTranslationsParams struct {
 	Page     *int32                       `form:"page,omitempty" json:"page,omitempty"`
@@ -305,7 +550,7 @@ type ListUsersParams struct {
 	// Order sort order
 	Order *ListUsersParamsOrder `form:"order,omitempty" json:"order,omitempty"`
 
-	// Q starts with
+	// Q Words of the email, name or profile, accent and case insensitive, ranked by relevance
 	Q *string `form:"q,omitempty" json:"q,omitempty"`
 
 	// Detail basic or full (default to full)
@@ -315,6 +560,33 @@ type ListUsersParams struct {
 	// "global" (default) lists only holders of a global role (SUPER_ADMIN/ADMIN);
 	// "all" lists every user system-wide and requires SUPER_ADMIN.
 	Scope *ListUsersParamsScope `form:"scope,omitempty" json:"scope,omitempty"`
+
+	// InactiveDays Only the stale accounts: users created more than inactiveDays days ago
+	// without a login since, in the tenant or, on the admin domain, in any tenant.
+	InactiveDays *int32 `form:"inactiveDays,omitempty" json:"inactiveDays,omitempty"`
+
+	// Role Only the users holding the role, in the tenant or, on the admin domain, globally
+	Role *Role `form:"role,omitempty" json:"role,omitempty"`
+
+	// Labels Only the users carrying every one of the labels in the tenant. Not
+	// available on the admin domain, where users have no labels.
+	Labels *[]string `form:"labels,omitempty" json:"labels,omitempty"`
+
+	// Disabled Only the disabled (true) or enabled (false) accounts
+	Disabled *bool `form:"disabled,omitempty" json:"disabled,omitempty"`
+
+	// EmailVerified Only the users whose email is verified (true) or not (false)
+	EmailVerified *bool `form:"emailVerified,omitempty" json:"emailVerified,omitempty"`
+
+	// CreatedFrom Only the users created at or after this instant
+	CreatedFrom *time.Time `form:"createdFrom,omitempty" json:"createdFrom,omitempty"`
+
+	// CreatedTo Only the users created at or before this instant
+	CreatedTo *time.Time `form:"createdTo,omitempty" json:"createdTo,omitempty"`
+
+	// Envelope Wrap the page in a UserList object holding the total, instead of
+	// returning a bare array
+	Envelope *bool `form:"envelope,omitempty" json:"envelope,omitempty"`
 }
 
 // ListUsersParamsOrder defines parameters for ListUsers.
@@ -329,10 +601,89 @@ type CheckUserExistsParams struct {
 	Email openapi_types.Email `form:"email" json:"email"`
 }
 
+// ExportUsersParams defines parameters for ExportUsers.
+type ExportUsersParams struct {
+	// Format csv (default) or xlsx
+	Format *string `form:"format,omitempty" json:"format,omitempty"`
+
+	// Q Words of the email, name or profile, accent and case insensitive, ranked by relevance
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Scope On the admin (tenantless) domain, "global" (default) exports only holders of a global role;
+	// "all" exports every user system-wide and requires SUPER_ADMIN.
+	Scope *string `form:"scope,omitempty" json:"scope,omitempty"`
+}
+
+// ListUserInvitationsParams defines parameters for ListUserInvitations.
+type ListUserInvitationsParams struct {
+	// Status keep the invitations with this status
+	Status *ListUserInvitationsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Limit maximum number of invitations to return
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset number of invitations to skip
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListUserInvitationsParamsStatus defines parameters for ListUserInvitations.
+type ListUserInvitationsParamsStatus string
+
+// GetUserVerificationReportParams defines parameters for GetUserVerificationReport.
+type GetUserVerificationReportParams struct {
+	// OlderThanDays list the unverified accounts created more than this number of days ago
+	OlderThanDays *int32 `form:"olderThanDays,omitempty" json:"olderThanDays,omitempty"`
+
+	// Limit maximum number of unverified accounts to list
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// DeleteUserParams defines parameters for DeleteUser.
+type DeleteUserParams struct {
+	// TransferTo User who takes over the client applications, API tokens and other resources owned by the deleted user
+	TransferTo *string `form:"transferTo,omitempty" json:"transferTo,omitempty"`
+
+	// TransferToTenantOwner Transfer the resources owned by the deleted user to the owner of the tenant they belong to
+	TransferToTenantOwner *bool `form:"transferToTenantOwner,omitempty" json:"transferToTenantOwner,omitempty"`
+
+	// Mode delete (default) removes the user from the tenant, or everywhere from the admin domain. anonymize erases the
+	// user for good (right to erasure): their identity, email and profile are deleted, and the audit records keep a
+	// tombstone id in place of theirs. A tenant admin can only erase a user who belongs to no other tenant.
+	Mode *DeleteUserParamsMode `form:"mode,omitempty" json:"mode,omitempty"`
+
+	// ConfirmIrreversible Must be true with mode=anonymize, an erasure cannot be undone
+	ConfirmIrreversible *bool `form:"confirmIrreversible,omitempty" json:"confirmIrreversible,omitempty"`
+}
+
+// DeleteUserParamsMode defines parameters for DeleteUser.
+type DeleteUserParamsMode string
+
+// ImportUsersFromAdminParams defines parameters for ImportUsersFromAdmin.
+type ImportUsersFromAdminParams struct {
+	// DryRun Only validate the file and return a report
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
 // ImportUsersFromAdminMultipartBody defines parameters for ImportUsersFromAdmin.
 type ImportUsersFromAdminMultipartBody struct {
 	// File CSV file with user data (lastname;firstname;email format)
 	File *openapi_types.File `json:"file,omitempty"`
+
+	// Mapping JSON UserImportMapping describing the layout of the file, when it is not
+	// semicolon separated with the default column names
+	Mapping *string `json:"mapping,omitempty"`
+}
+
+// GetUserAuditLogsParams defines parameters for GetUserAuditLogs.
+type GetUserAuditLogsParams struct {
+	// Actions actions to include (created, updated, role_assigned, role_unassigned, status_changed, removed_from_tenant, deleted, password_reset, email_changed, labels_changed, merged), all when omitted
+	Actions *[]string `form:"actions,omitempty" json:"actions,omitempty"`
+
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
 }
 
 // GetUserFeatureLicensesParams defines parameters for GetUserFeatureLicenses.
@@ -403,16 +754,34 @@ type GetTenantLogoParams struct {
 
 // GetProfilePictureParams defines parameters for GetProfilePicture.
 type GetProfilePictureParams struct {
+	// Size variant of the picture: small (64px), medium (256px) or large (1024px, the default)
+	Size *GetProfilePictureParamsSize `form:"size,omitempty" json:"size,omitempty"`
+
 	// IfNoneMatch ETag value for cache validation
 	IfNoneMatch *string `json:"If-None-Match,omitempty"`
 }
 
+// GetProfilePictureParamsSize defines parameters for GetProfilePicture.
+type GetProfilePictureParamsSize string
+
 // VerifyEmailJSONBody defines parameters for VerifyEmail.
 type VerifyEmailJSONBody struct {
 	// Token Email verification token received via email
 	Token string `json:"token"`
 }
 
+// ListGlobalAnnouncementsParams defines parameters for ListGlobalAnnouncements.
+type ListGlobalAnnouncementsParams struct {
+	// IncludeExpired Also return expired announcements
+	IncludeExpired *bool `form:"includeExpired,omitempty" json:"includeExpired,omitempty"`
+
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
 // ListGlobalConfigsParams defines parameters for ListGlobalConfigs.
 type ListGlobalConfigsParams struct {
 	// Page page number
@@ -450,6 +819,71 @@ type UpdateGlobalConfigJSONBody struct {
 	Value *string            `json:"value,omitempty"`
 }
 
+// GetIndexRecommendationsParams defines parameters for GetIndexRecommendations.
+type GetIndexRecommendationsParams struct {
+	// MinCalls Calls below which the queries are not worth an index (default INDEX_ADVISOR_MIN_CALLS)
+	MinCalls *int64 `form:"minCalls,omitempty" json:"minCalls,omitempty"`
+}
+
+// ListReplayBundlesParams defines parameters for ListReplayBundles.
+type ListReplayBundlesParams struct {
+	TenantId *string `form:"tenantId,omitempty" json:"tenantId,omitempty"`
+
+	// RequestId X-Request-ID of the failing request
+	RequestId *string `form:"requestId,omitempty" json:"requestId,omitempty"`
+
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListTenantIsolationProbeResultsParams defines parameters for ListTenantIsolationProbeResults.
+type ListTenantIsolationProbeResultsParams struct {
+	Outcome *ListTenantIsolationProbeResultsParamsOutcome `form:"outcome,omitempty" json:"outcome,omitempty"`
+
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListTenantIsolationProbeResultsParamsOutcome defines parameters for ListTenantIsolationProbeResults.
+type ListTenantIsolationProbeResultsParamsOutcome string
+
+// ListTenantFileEncryptionParams defines parameters for ListTenantFileEncryption.
+type ListTenantFileEncryptionParams struct {
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListAuthLockoutsParams defines parameters for ListAuthLockouts.
+type ListAuthLockoutsParams struct {
+	// SubjectType user or ip
+	SubjectType *string `form:"subjectType,omitempty" json:"subjectType,omitempty"`
+
+	// TenantId Tenant of the last failure
+	TenantId *string `form:"tenantId,omitempty" json:"tenantId,omitempty"`
+
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ExportTenantSettingsParams defines parameters for ExportTenantSettings.
+type ExportTenantSettingsParams struct {
+	// Format document format, defaults to json
+	Format *ExportTenantSettingsParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// ExportTenantSettingsParamsFormat defines parameters for ExportTenantSettings.
+type ExportTenantSettingsParamsFormat string
+
+// ImportTenantSettingsParams defines parameters for ImportTenantSettings.
+type ImportTenantSettingsParams struct {
+	// Confirm fingerprint returned by the preview, applies the changes when it still matches
+	Confirm *string `form:"confirm,omitempty" json:"confirm,omitempty"`
+}
+
 // ListTenantsParams defines parameters for ListTenants.
 type ListTenantsParams struct {
 	// Page page number
@@ -470,6 +904,9 @@ type ListTenantsParams struct {
 	// ResellerId filter by reseller id
 	ResellerId *string `form:"reseller_id,omitempty" json:"reseller_id,omitempty"`
 
+	// Status filter by lifecycle status; archived tenants are only listed when asked for
+	Status *TenantStatus `form:"status,omitempty" json:"status,omitempty"`
+
 	// Global When true, bypass the automatic reseller scoping that filters results
 	// to the current reseller subdomain. Honored only for SUPER_ADMIN / ADMIN;
 	// ignored otherwise.
@@ -479,6 +916,18 @@ type ListTenantsParams struct {
 // ListTenantsParamsOrder defines parameters for ListTenants.
 type ListTenantsParamsOrder string
 
+// ScheduleTenantPurgeParams defines parameters for ScheduleTenantPurge.
+type ScheduleTenantPurgeParams struct {
+	// DryRun Only report what the purge would delete
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
+// ResetTenantSandboxParams defines parameters for ResetTenantSandbox.
+type ResetTenantSandboxParams struct {
+	// Confirm confirmation token returned by the preview, starts the reset when it still matches
+	Confirm *string `form:"confirm,omitempty" json:"confirm,omitempty"`
+}
+
 // ListUsersFromSuperAdminParams defines parameters for ListUsersFromSuperAdmin.
 type ListUsersFromSuperAdminParams struct {
 	// Page page number
@@ -493,7 +942,7 @@ type ListUsersFromSuperAdminParams struct {
 	// Order sort order
 	Order *ListUsersFromSuperAdminParamsOrder `form:"order,omitempty" json:"order,omitempty"`
 
-	// Q starts with
+	// Q Words of the email, name or profile, accent and case insensitive, ranked by relevance
 	Q *string `form:"q,omitempty" json:"q,omitempty"`
 }
 
@@ -506,6 +955,36 @@ type CheckUserExistsFromSuperAdminParams struct {
 	Email openapi_types.Email `form:"email" json:"email"`
 }
 
+// RebuildUserClaimsFromSuperAdminParams defines parameters for RebuildUserClaimsFromSuperAdmin.
+type RebuildUserClaimsFromSuperAdminParams struct {
+	// Cursor User ID after which to resume
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of users to process in this call
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// DryRun Only report discrepancies without rewriting claims
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
+// DeleteUserFromSuperAdminParams defines parameters for DeleteUserFromSuperAdmin.
+type DeleteUserFromSuperAdminParams struct {
+	// TransferTo User who takes over the client applications, API tokens and other resources owned by the deleted user
+	TransferTo *string `form:"transferTo,omitempty" json:"transferTo,omitempty"`
+
+	// TransferToTenantOwner Transfer the resources owned by the deleted user to the owner of the tenant they belong to
+	TransferToTenantOwner *bool `form:"transferToTenantOwner,omitempty" json:"transferToTenantOwner,omitempty"`
+}
+
+// HardDeleteUserFromSuperAdminParams defines parameters for HardDeleteUserFromSuperAdmin.
+type HardDeleteUserFromSuperAdminParams struct {
+	// TransferTo User who takes over the client applications, API tokens and other resources owned by the deleted user
+	TransferTo *string `form:"transferTo,omitempty" json:"transferTo,omitempty"`
+
+	// TransferToTenantOwner Transfer the resources owned by the deleted user to the owner of the tenant they belong to
+	TransferToTenantOwner *bool `form:"transferToTenantOwner,omitempty" json:"transferToTenantOwner,omitempty"`
+}
+
 // AddUserMembershipFromSuperAdminJSONBody defines parameters for AddUserMembershipFromSuperAdmin.
 type AddUserMembershipFromSuperAdminJSONBody struct {
 	// Roles Roles to assign to the user in this tenant
@@ -527,30 +1006,114 @@ type UpdateUserStatusFromSuperAdminJSONBody struct {
 // UpdateUserStatusFromSuperAdminJSONBodyName defines parameters for UpdateUserStatusFromSuperAdmin.
 type UpdateUserStatusFromSuperAdminJSONBodyName string
 
+// GetUserTimelineFromSuperAdminParams defines parameters for GetUserTimelineFromSuperAdmin.
+type GetUserTimelineFromSuperAdminParams struct {
+	// Types event categories to include (account, api_token, file, login, membership, prompt), all when omitted
+	Types *[]string `form:"types,omitempty" json:"types,omitempty"`
+
+	// Page page number
+	Page *int32 `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize maximum number of results to return
+	PageSize *int32 `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// ListTenantsUsageParams defines parameters for ListTenantsUsage.
+type ListTenantsUsageParams struct {
+	// From start of the range, widened to the start of its day (UTC), defaults to 30 days before to
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To end of the range (exclusive), defaults to now
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// CheckUserMembershipConsistencyParams defines parameters for CheckUserMembershipConsistency.
+type CheckUserMembershipConsistencyParams struct {
+	// Repair Create the memberships missing for users with a legacy tenant
+	Repair *bool `form:"repair,omitempty" json:"repair,omitempty"`
+}
+
+// CheckUserEmailEncryptionParams defines parameters for CheckUserEmailEncryption.
+type CheckUserEmailEncryptionParams struct {
+	// Rewrite Rewrite the addresses not stored in the current format
+	Rewrite *bool `form:"rewrite,omitempty" json:"rewrite,omitempty"`
+}
+
+// MergeUsersParams defines parameters for MergeUsers.
+type MergeUsersParams struct {
+	// Apply Apply the merge instead of reporting it
+	Apply *bool `form:"apply,omitempty" json:"apply,omitempty"`
+}
+
+// RevokeAllAPITokensJSONRequestBody defines body for RevokeAllAPITokens for application/json ContentType.
+type RevokeAllAPITokensJSONRequestBody = APITokenRevoke
+
 // CreateClientApplicationJSONRequestBody defines body for CreateClientApplication for application/json ContentType.
 type CreateClientApplicationJSONRequestBody = NewClientApplication
 
+// ImportClientApplicationConfigJSONRequestBody defines body for ImportClientApplicationConfig for application/json ContentType.
+type ImportClientApplicationConfigJSONRequestBody = ClientApplicationConfig
+
 // UpdateClientApplicationJSONRequestBody defines body for UpdateClientApplication for application/json ContentType.
 type UpdateClientApplicationJSONRequestBody = NewClientApplication
 
+// RotateClientApplicationSecretJSONRequestBody defines body for RotateClientApplicationSecret for application/json ContentType.
+type RotateClientApplicationSecretJSONRequestBody = NewClientSecret
+
+// RotateClientApplicationSigningKeyJSONRequestBody defines body for RotateClientApplicationSigningKey for application/json ContentType.
+type RotateClientApplicationSigningKeyJSONRequestBody = NewSigningKey
+
 // CreateAPITokenJSONRequestBody defines body for CreateAPIToken for application/json ContentType.
 type CreateAPITokenJSONRequestBody = NewAPIToken
 
+// RevokeAllClientApplicationTokensJSONRequestBody defines body for RevokeAllClientApplicationTokens for application/json ContentType.
+type RevokeAllClientApplicationTokensJSONRequestBody = APITokenRevoke
+
+// UpdateAPITokenJSONRequestBody defines body for UpdateAPIToken for application/json ContentType.
+type UpdateAPITokenJSONRequestBody = APITokenUpdate
+
+// UpdateAPITokenIPAllowlistJSONRequestBody defines body for UpdateAPITokenIPAllowlist for application/json ContentType.
+type UpdateAPITokenIPAllowlistJSONRequestBody = APITokenIPAllowlist
+
 // RevokeAPITokenJSONRequestBody defines body for RevokeAPIToken for application/json ContentType.
 type RevokeAPITokenJSONRequestBody = APITokenRevoke
 
+// SetClientApplicationWebhookJSONRequestBody defines body for SetClientApplicationWebhook for application/json ContentType.
+type SetClientApplicationWebhookJSONRequestBody = NewClientApplicationWebhook
+
+// CreateScopeTemplateJSONRequestBody defines body for CreateScopeTemplate for application/json ContentType.
+type CreateScopeTemplateJSONRequestBody = NewScopeTemplate
+
+// UpdateScopeTemplateJSONRequestBody defines body for UpdateScopeTemplate for application/json ContentType.
+type UpdateScopeTemplateJSONRequestBody = ScopeTemplateUpdate
+
 // AddTenantConfigJSONRequestBody defines body for AddTenantConfig for application/json ContentType.
 type AddTenantConfigJSONRequestBody AddTenantConfigJSONBody
 
 // UpdateTenantConfigJSONRequestBody defines body for UpdateTenantConfig for application/json ContentType.
 type UpdateTenantConfigJSONRequestBody UpdateTenantConfigJSONBody
 
+// CreateDelegationJSONRequestBody defines body for CreateDelegation for application/json ContentType.
+type CreateDelegationJSONRequestBody = NewPermissionDelegation
+
+// CreateGroupJSONRequestBody defines body for CreateGroup for application/json ContentType.
+type CreateGroupJSONRequestBody = NewGroup
+
+// UpdateGroupJSONRequestBody defines body for UpdateGroup for application/json ContentType.
+type UpdateGroupJSONRequestBody = NewGroup
+
 // UpdateMeProfileJSONRequestBody defines body for UpdateMeProfile for application/json ContentType.
 type UpdateMeProfileJSONRequestBody UpdateMeProfileJSONBody
 
 // UploadProfilePictureMultipartRequestBody defines body for UploadProfilePicture for multipart/form-data ContentType.
 type UploadProfilePictureMultipartRequestBody UploadProfilePictureMultipartBody
 
+// CreateCustomRoleJSONRequestBody defines body for CreateCustomRole for application/json ContentType.
+type CreateCustomRoleJSONRequestBody = NewCustomRole
+
+// UpdateCustomRoleJSONRequestBody defines body for UpdateCustomRole for application/json ContentType.
+type UpdateCustomRoleJSONRequestBody = NewCustomRole
+
 // UploadTenantBackgroundMultipartRequestBody defines body for UploadTenantBackground for multipart/form-data ContentType.
 type UploadTenantBackgroundMultipartRequestBody UploadTenantBackgroundMultipartBody
 
@@ -560,21 +1123,126 @@ type UploadTenantBackgroundMobileMultipartRequestBody UploadTenantBackgroundMobi
 // UploadTenantLogoMultipartRequestBody defines body for UploadTenantLogo for multipart/form-data ContentType.
 type UploadTenantLogoMultipartRequestBody UploadTenantLogoMultipartBody
 
-// UpdateTenantProfileJSONRequestBody defines body for UpdateTenantProfile for application/json ContentType.
-type UpdateTenantProfileJSONRequestBody = TenantProfile
+// CreateTenantAnnouncementJSONRequestBody defines body for CreateTenantAnnouncement for application/json ContentType.
+type CreateTenantAnnouncementJSONRequestBody = NewAnnouncement
 
-// CreateTranslationJSONRequestBody defines body for CreateTranslation for application/json ContentType.
-type CreateTranslationJSONRequestBody CreateTranslationJSONBody
+// UpdateTenantAnnouncementJSONRequestBody defines body for UpdateTenantAnnouncement for application/json ContentType.
+type UpdateTenantAnnouncementJSONRequestBody = NewAnnouncement
 
-// UpdateTranslationJSONRequestBody defines body for UpdateTranslation for application/json ContentType.
-type UpdateTranslationJSONRequestBody UpdateTranslationJSONBody
+// RevokeAllTenantAPITokensJSONRequestBody defines body for RevokeAllTenantAPITokens for application/json ContentType.
+type RevokeAllTenantAPITokensJSONRequestBody = APITokenRevoke
 
-// AddUserJSONRequestBody defines body for AddUser for application/json ContentType.
-type AddUserJSONRequestBody = NewUser
+// UpdateTenantBrandingJSONRequestBody defines body for UpdateTenantBranding for application/json ContentType.
+type UpdateTenantBrandingJSONRequestBody = TenantBrandingUpdate
 
-// ImportUsersFromAdminMultipartRequestBody defines body for ImportUsersFromAdmin for multipart/form-data ContentType.
+// CreateTenantClientApplicationJSONRequestBody defines body for CreateTenantClientApplication for application/json ContentType.
+type CreateTenantClientApplicationJSONRequestBody = NewClientApplication
+
+// ImportTenantClientApplicationConfigJSONRequestBody defines body for ImportTenantClientApplicationConfig for application/json ContentType.
+type ImportTenantClientApplicationConfigJSONRequestBody = ClientApplicationConfig
+
+// UpdateTenantClientApplicationJSONRequestBody defines body for UpdateTenantClientApplication for application/json ContentType.
+type UpdateTenantClientApplicationJSONRequestBody = NewClientApplication
+
+// RotateTenantClientApplicationSecretJSONRequestBody defines body for RotateTenantClientApplicationSecret for application/json ContentType.
+type RotateTenantClientApplicationSecretJSONRequestBody = NewClientSecret
+
+// RotateTenantClientApplicationSigningKeyJSONRequestBody defines body for RotateTenantClientApplicationSigningKey for application/json ContentType.
+type RotateTenantClientApplicationSigningKeyJSONRequestBody = NewSigningKey
+
+// CreateTenantAPITokenJSONRequestBody defines body for CreateTenantAPIToken for application/json ContentType.
+type CreateTenantAPITokenJSONRequestBody = NewAPIToken
+
+// RevokeAllTenantClientApplicationTokensJSONRequestBody defines body for RevokeAllTenantClientApplicationTokens for application/json ContentType.
+type RevokeAllTenantClientApplicationTokensJSONRequestBody = APITokenRevoke
+
+// UpdateTenantAPITokenJSONRequestBody defines body for UpdateTenantAPIToken for application/json ContentType.
+type UpdateTenantAPITokenJSONRequestBody = APITokenUpdate
+
+// UpdateTenantAPITokenIPAllowlistJSONRequestBody defines body for UpdateTenantAPITokenIPAllowlist for application/json ContentType.
+type UpdateTenantAPITokenIPAllowlistJSONRequestBody = APITokenIPAllowlist
+
+// RevokeTenantAPITokenJSONRequestBody defines body for RevokeTenantAPIToken for application/json ContentType.
+type RevokeTenantAPITokenJSONRequestBody = APITokenRevoke
+
+// SetTenantClientApplicationWebhookJSONRequestBody defines body for SetTenantClientApplicationWebhook for application/json ContentType.
+type SetTenantClientApplicationWebhookJSONRequestBody = NewClientApplicationWebhook
+
+// SaveDirectoryConnectionJSONRequestBody defines body for SaveDirectoryConnection for application/json ContentType.
+type SaveDirectoryConnectionJSONRequestBody = DirectoryConnectionInput
+
+// SaveTenantExportScheduleJSONRequestBody defines body for SaveTenantExportSchedule for application/json ContentType.
+type SaveTenantExportScheduleJSONRequestBody = NewTenantExportSchedule
+
+// CreateTenantExportJSONRequestBody defines body for CreateTenantExport for application/json ContentType.
+type CreateTenantExportJSONRequestBody = NewTenantExport
+
+// SetLLMConsentPolicyJSONRequestBody defines body for SetLLMConsentPolicy for application/json ContentType.
+type SetLLMConsentPolicyJSONRequestBody = LLMConsentPolicyUpdate
+
+// UpdateTenantProfileJSONRequestBody defines body for UpdateTenantProfile for application/json ContentType.
+type UpdateTenantProfileJSONRequestBody = TenantProfile
+
+// CreateTenantScopeTemplateJSONRequestBody defines body for CreateTenantScopeTemplate for application/json ContentType.
+type CreateTenantScopeTemplateJSONRequestBody = NewScopeTemplate
+
+// UpdateTenantScopeTemplateJSONRequestBody defines body for UpdateTenantScopeTemplate for application/json ContentType.
+type UpdateTenantScopeTemplateJSONRequestBody = ScopeTemplateUpdate
+
+// SaveTenantEmailProviderJSONRequestBody defines body for SaveTenantEmailProvider for application/json ContentType.
+type SaveTenantEmailProviderJSONRequestBody = TenantEmailProviderUpdate
+
+// SaveEmailTemplateJSONRequestBody defines body for SaveEmailTemplate for application/json ContentType.
+type SaveEmailTemplateJSONRequestBody = NewEmailTemplate
+
+// SetExecutionWebhookJSONRequestBody defines body for SetExecutionWebhook for application/json ContentType.
+type SetExecutionWebhookJSONRequestBody = NewExecutionWebhook
+
+// UpdateTenantSettingValuesJSONRequestBody defines body for UpdateTenantSettingValues for application/json ContentType.
+type UpdateTenantSettingValuesJSONRequestBody = TenantSettingValuesUpdate
+
+// SaveUserAttributeJSONRequestBody defines body for SaveUserAttribute for application/json ContentType.
+type SaveUserAttributeJSONRequestBody = NewUserAttribute
+
+// CreateTranslationJSONRequestBody defines body for CreateTranslation for application/json ContentType.
+type CreateTranslationJSONRequestBody CreateTranslationJSONBody
+
+// UpdateTranslationJSONRequestBody defines body for UpdateTranslation for application/json ContentType.
+type UpdateTranslationJSONRequestBody UpdateTranslationJSONBody
+
+// AddUserJSONRequestBody defines body for AddUser for application/json ContentType.
+type AddUserJSONRequestBody = NewUser
+
+// ImportUsersFromAdminMultipartRequestBody defines body for ImportUsersFromAdmin for multipart/form-data ContentType.
 type ImportUsersFromAdminMultipartRequestBody ImportUsersFromAdminMultipartBody
 
+// BulkUpdateUsersJSONRequestBody defines body for BulkUpdateUsers for application/json ContentType.
+type BulkUpdateUsersJSONRequestBody = BulkUserOperation
+
+// CreateUserInvitationJSONRequestBody defines body for CreateUserInvitation for application/json ContentType.
+type CreateUserInvitationJSONRequestBody = NewUserInvitation
+
+// RequestEmailChangeJSONRequestBody defines body for RequestEmailChange for application/json ContentType.
+type RequestEmailChangeJSONRequestBody = EmailChangeRequest
+
+// DisableMyTOTPJSONRequestBody defines body for DisableMyTOTP for application/json ContentType.
+type DisableMyTOTPJSONRequestBody = TOTPCode
+
+// RegenerateMyTOTPRecoveryCodesJSONRequestBody defines body for RegenerateMyTOTPRecoveryCodes for application/json ContentType.
+type RegenerateMyTOTPRecoveryCodesJSONRequestBody = TOTPCode
+
+// VerifyMyTOTPJSONRequestBody defines body for VerifyMyTOTP for application/json ContentType.
+type VerifyMyTOTPJSONRequestBody = TOTPCode
+
+// UpdateMyOnboardingJSONRequestBody defines body for UpdateMyOnboarding for application/json ContentType.
+type UpdateMyOnboardingJSONRequestBody = UserOnboardingUpdate
+
+// RequestPhoneVerificationJSONRequestBody defines body for RequestPhoneVerification for application/json ContentType.
+type RequestPhoneVerificationJSONRequestBody = PhoneVerificationRequest
+
+// ConfirmPhoneVerificationJSONRequestBody defines body for ConfirmPhoneVerification for application/json ContentType.
+type ConfirmPhoneVerificationJSONRequestBody = PhoneVerificationConfirm
+
 // UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
 type UpdateUserJSONRequestBody = User
 
@@ -590,42 +1258,111 @@ type ResetPasswordRequestByAdminJSONRequestBody ResetPasswordRequestByAdminJSONB
 // UpdateUserStatusJSONRequestBody defines body for UpdateUserStatus for application/json ContentType.
 type UpdateUserStatusJSONRequestBody UpdateUserStatusJSONBody
 
+// CancelAccountDeletionJSONRequestBody defines body for CancelAccountDeletion for application/json ContentType.
+type CancelAccountDeletionJSONRequestBody = AccountDeletionToken
+
 // IdentifyUserJSONRequestBody defines body for IdentifyUser for application/json ContentType.
 type IdentifyUserJSONRequestBody = Identify
 
+// ConfirmEmailChangeJSONRequestBody defines body for ConfirmEmailChange for application/json ContentType.
+type ConfirmEmailChangeJSONRequestBody = EmailChangeToken
+
+// RevertEmailChangeJSONRequestBody defines body for RevertEmailChange for application/json ContentType.
+type RevertEmailChangeJSONRequestBody = EmailChangeToken
+
+// IssueOAuthTokenFormdataRequestBody defines body for IssueOAuthToken for application/x-www-form-urlencoded ContentType.
+type IssueOAuthTokenFormdataRequestBody = OAuthTokenRequest
+
+// AcceptUserInvitationJSONRequestBody defines body for AcceptUserInvitation for application/json ContentType.
+type AcceptUserInvitationJSONRequestBody = AcceptUserInvitation
+
+// LookupUserInvitationJSONRequestBody defines body for LookupUserInvitation for application/json ContentType.
+type LookupUserInvitationJSONRequestBody = UserInvitationToken
+
 // ResetPasswordRequestJSONRequestBody defines body for ResetPasswordRequest for application/json ContentType.
 type ResetPasswordRequestJSONRequestBody ResetPasswordRequestJSONBody
 
 // SignupJSONRequestBody defines body for Signup for application/json ContentType.
 type SignupJSONRequestBody = NewSignUp
 
+// IntrospectOAuthTokenFormdataRequestBody defines body for IntrospectOAuthToken for application/x-www-form-urlencoded ContentType.
+type IntrospectOAuthTokenFormdataRequestBody = TokenIntrospectionRequest
+
 // VerifyEmailJSONRequestBody defines body for VerifyEmail for application/json ContentType.
 type VerifyEmailJSONRequestBody VerifyEmailJSONBody
 
+// CreateGlobalAnnouncementJSONRequestBody defines body for CreateGlobalAnnouncement for application/json ContentType.
+type CreateGlobalAnnouncementJSONRequestBody = NewAnnouncement
+
+// UpdateGlobalAnnouncementJSONRequestBody defines body for UpdateGlobalAnnouncement for application/json ContentType.
+type UpdateGlobalAnnouncementJSONRequestBody = NewAnnouncement
+
+// SetClientApplicationTokenQuotaJSONRequestBody defines body for SetClientApplicationTokenQuota for application/json ContentType.
+type SetClientApplicationTokenQuotaJSONRequestBody = ClientApplicationTokenQuota
+
+// SaveFeatureFlagJSONRequestBody defines body for SaveFeatureFlag for application/json ContentType.
+type SaveFeatureFlagJSONRequestBody = FeatureFlagUpdate
+
 // AddGlobalConfigJSONRequestBody defines body for AddGlobalConfig for application/json ContentType.
 type AddGlobalConfigJSONRequestBody AddGlobalConfigJSONBody
 
 // UpdateGlobalConfigJSONRequestBody defines body for UpdateGlobalConfig for application/json ContentType.
 type UpdateGlobalConfigJSONRequestBody UpdateGlobalConfigJSONBody
 
+// UnlockAuthSubjectJSONRequestBody defines body for UnlockAuthSubject for application/json ContentType.
+type UnlockAuthSubjectJSONRequestBody = AuthLockoutUnlock
+
+// ProvisionTenantFromTemplateJSONRequestBody defines body for ProvisionTenantFromTemplate for application/json ContentType.
+type ProvisionTenantFromTemplateJSONRequestBody = TenantProvisionRequest
+
+// SaveTenantTemplateJSONRequestBody defines body for SaveTenantTemplate for application/json ContentType.
+type SaveTenantTemplateJSONRequestBody = TenantTemplateRequest
+
 // UpdateTenantFeatureLicensesJSONRequestBody defines body for UpdateTenantFeatureLicenses for application/json ContentType.
 type UpdateTenantFeatureLicensesJSONRequestBody = TenantFeatureLicenses
 
 // UpdateTenantFeaturesJSONRequestBody defines body for UpdateTenantFeatures for application/json ContentType.
 type UpdateTenantFeaturesJSONRequestBody = TenantFeatures
 
+// ImportTenantSettingsJSONRequestBody defines body for ImportTenantSettings for application/json ContentType.
+type ImportTenantSettingsJSONRequestBody = TenantSettingsDocument
+
+// AddTenantSubdomainAliasJSONRequestBody defines body for AddTenantSubdomainAlias for application/json ContentType.
+type AddTenantSubdomainAliasJSONRequestBody = NewTenantSubdomainAlias
+
 // AddTenantJSONRequestBody defines body for AddTenant for application/json ContentType.
 type AddTenantJSONRequestBody = NewTenant
 
 // UpdateTenantJSONRequestBody defines body for UpdateTenant for application/json ContentType.
 type UpdateTenantJSONRequestBody = Tenant
 
+// CloneTenantJSONRequestBody defines body for CloneTenant for application/json ContentType.
+type CloneTenantJSONRequestBody = TenantProvisionRequest
+
+// SetTenantFeatureFlagOverrideJSONRequestBody defines body for SetTenantFeatureFlagOverride for application/json ContentType.
+type SetTenantFeatureFlagOverrideJSONRequestBody = TenantFeatureFlagOverride
+
+// ScheduleTenantPurgeJSONRequestBody defines body for ScheduleTenantPurge for application/json ContentType.
+type ScheduleTenantPurgeJSONRequestBody = TenantPurgeRequest
+
+// SetTenantQuotasJSONRequestBody defines body for SetTenantQuotas for application/json ContentType.
+type SetTenantQuotasJSONRequestBody = TenantQuotaLimits
+
+// SetTenantStatusJSONRequestBody defines body for SetTenantStatus for application/json ContentType.
+type SetTenantStatusJSONRequestBody = TenantStatusChange
+
+// SetTokenPolicyJSONRequestBody defines body for SetTokenPolicy for application/json ContentType.
+type SetTokenPolicyJSONRequestBody = TokenPolicyUpdate
+
 // AddUserFromSuperAdminJSONRequestBody defines body for AddUserFromSuperAdmin for application/json ContentType.
 type AddUserFromSuperAdminJSONRequestBody = NewUser
 
 // UpdateUserFromSuperAdminJSONRequestBody defines body for UpdateUserFromSuperAdmin for application/json ContentType.
 type UpdateUserFromSuperAdminJSONRequestBody = User
 
+// ImpersonateUserFromSuperAdminJSONRequestBody defines body for ImpersonateUserFromSuperAdmin for application/json ContentType.
+type ImpersonateUserFromSuperAdminJSONRequestBody = UserImpersonationRequest
+
 // AddUserMembershipFromSuperAdminJSONRequestBody defines body for AddUserMembershipFromSuperAdmin for application/json ContentType.
 type AddUserMembershipFromSuperAdminJSONRequestBody AddUserMembershipFromSuperAdminJSONBody
 
@@ -635,15 +1372,24 @@ type ResetPasswordRequestBySuperAdminJSONRequestBody ResetPasswordRequestBySuper
 // UpdateUserStatusFromSuperAdminJSONRequestBody defines body for UpdateUserStatusFromSuperAdmin for application/json ContentType.
 type UpdateUserStatusFromSuperAdminJSONRequestBody UpdateUserStatusFromSuperAdminJSONBody
 
+// MergeUsersJSONRequestBody defines body for MergeUsers for application/json ContentType.
+type MergeUsersJSONRequestBody = UserMerge
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (POST /admin-api/v1/api-tokens/revoke-all)
+	RevokeAllAPITokens(c *gin.Context)
+
 	// (GET /admin-api/v1/client-applications)
 	ListClientApplications(c *gin.Context, params ListClientApplicationsParams)
 
 	// (POST /admin-api/v1/client-applications)
 	CreateClientApplication(c *gin.Context)
 
+	// (POST /admin-api/v1/client-applications/import)
+	ImportClientApplicationConfig(c *gin.Context)
+
 	// (DELETE /admin-api/v1/client-applications/{id})
 	DeleteClientApplication(c *gin.Context, id openapi_types.UUID)
 
@@ -653,27 +1399,96 @@ type ServerInterface interface {
 	// (PUT /admin-api/v1/client-applications/{id})
 	UpdateClientApplication(c *gin.Context, id openapi_types.UUID)
 
+	// (PATCH /admin-api/v1/client-applications/{id}/activate)
+	ActivateClientApplication(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /admin-api/v1/client-applications/{id}/config)
+	ExportClientApplicationConfig(c *gin.Context, id openapi_types.UUID)
+
 	// (PATCH /admin-api/v1/client-applications/{id}/deactivate)
 	DeactivateClientApplication(c *gin.Context, id openapi_types.UUID)
 
+	// (DELETE /admin-api/v1/client-applications/{id}/secret)
+	DeleteClientApplicationSecret(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /admin-api/v1/client-applications/{id}/secret)
+	RotateClientApplicationSecret(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /admin-api/v1/client-applications/{id}/service-account)
+	UnlinkClientApplicationServiceAccount(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /admin-api/v1/client-applications/{id}/service-account)
+	LinkClientApplicationServiceAccount(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /admin-api/v1/client-applications/{id}/signing-key)
+	DeleteClientApplicationSigningKey(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /admin-api/v1/client-applications/{id}/signing-key)
+	RotateClientApplicationSigningKey(c *gin.Context, id openapi_types.UUID)
+
 	// (GET /admin-api/v1/client-applications/{id}/tokens)
 	ListAPITokens(c *gin.Context, id openapi_types.UUID, params ListAPITokensParams)
 
 	// (POST /admin-api/v1/client-applications/{id}/tokens)
 	CreateAPIToken(c *gin.Context, id openapi_types.UUID)
 
+	// (POST /admin-api/v1/client-applications/{id}/tokens/revoke-all)
+	RevokeAllClientApplicationTokens(c *gin.Context, id openapi_types.UUID)
+
 	// (DELETE /admin-api/v1/client-applications/{id}/tokens/{tokenId})
 	DeleteAPIToken(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
 
 	// (GET /admin-api/v1/client-applications/{id}/tokens/{tokenId})
 	GetAPITokenById(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
 
+	// (PATCH /admin-api/v1/client-applications/{id}/tokens/{tokenId})
+	UpdateAPIToken(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
 	// (GET /admin-api/v1/client-applications/{id}/tokens/{tokenId}/audit)
 	GetAPITokenAuditLogs(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID, params GetAPITokenAuditLogsParams)
 
+	// (GET /admin-api/v1/client-applications/{id}/tokens/{tokenId}/audit/export)
+	ExportAPITokenAuditLogs(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID, params ExportAPITokenAuditLogsParams)
+
+	// (PUT /admin-api/v1/client-applications/{id}/tokens/{tokenId}/ip-allowlist)
+	UpdateAPITokenIPAllowlist(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
 	// (PATCH /admin-api/v1/client-applications/{id}/tokens/{tokenId}/revoke)
 	RevokeAPIToken(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
 
+	// (GET /admin-api/v1/client-applications/{id}/tokens/{tokenId}/usage)
+	GetAPITokenUsage(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID, params GetAPITokenUsageParams)
+
+	// (DELETE /admin-api/v1/client-applications/{id}/webhook)
+	DeleteClientApplicationWebhook(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /admin-api/v1/client-applications/{id}/webhook)
+	GetClientApplicationWebhook(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /admin-api/v1/client-applications/{id}/webhook)
+	SetClientApplicationWebhook(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /admin-api/v1/scope-templates)
+	ListScopeTemplates(c *gin.Context)
+
+	// (POST /admin-api/v1/scope-templates)
+	CreateScopeTemplate(c *gin.Context)
+
+	// (DELETE /admin-api/v1/scope-templates/{id})
+	DeleteScopeTemplate(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /admin-api/v1/scope-templates/{id})
+	GetScopeTemplate(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /admin-api/v1/scope-templates/{id})
+	UpdateScopeTemplate(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /admin-api/v1/scopes)
+	ListScopes(c *gin.Context)
+
+	// (GET /api/v1/announcements)
+	ListActiveAnnouncements(c *gin.Context)
+
 	// (GET /api/v1/configs/tenant-configs)
 	ListTenantConfigs(c *gin.Context, params ListTenantConfigsParams)
 
@@ -689,6 +1504,45 @@ type ServerInterface interface {
 	// (PUT /api/v1/configs/tenant-configs/{id})
 	UpdateTenantConfig(c *gin.Context, id openapi_types.UUID)
 
+	// (GET /api/v1/delegations)
+	ListDelegations(c *gin.Context, params ListDelegationsParams)
+
+	// (POST /api/v1/delegations)
+	CreateDelegation(c *gin.Context)
+
+	// (DELETE /api/v1/delegations/{id})
+	RevokeDelegation(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/groups)
+	ListGroups(c *gin.Context)
+
+	// (POST /api/v1/groups)
+	CreateGroup(c *gin.Context)
+
+	// (DELETE /api/v1/groups/{id})
+	DeleteGroup(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/groups/{id})
+	GetGroup(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /api/v1/groups/{id})
+	UpdateGroup(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/groups/{id}/members)
+	ListGroupMembers(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/groups/{id}/members/{userid})
+	RemoveGroupMember(c *gin.Context, id openapi_types.UUID, userid string)
+
+	// (PUT /api/v1/groups/{id}/members/{userid})
+	AddGroupMember(c *gin.Context, id openapi_types.UUID, userid string)
+
+	// (GET /api/v1/imports/{id}/errors.csv)
+	DownloadImportErrors(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/jobs/{id}/events)
+	ListJobEvents(c *gin.Context, id openapi_types.UUID, params ListJobEventsParams)
+
 	// (POST /api/v1/me)
 	CreateMeUser(c *gin.Context)
 
@@ -722,6 +1576,231 @@ type ServerInterface interface {
 	// (GET /api/v1/reseller/tenants)
 	ListResellerTenants(c *gin.Context)
 
+	// (GET /api/v1/roles)
+	ListCustomRoles(c *gin.Context)
+
+	// (POST /api/v1/roles)
+	CreateCustomRole(c *gin.Context)
+
+	// (GET /api/v1/roles/permissions)
+	GetPermissionMatrix(c *gin.Context)
+
+	// (DELETE /api/v1/roles/{id})
+	DeleteCustomRole(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/roles/{id})
+	GetCustomRole(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /api/v1/roles/{id})
+	UpdateCustomRole(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/roles/{id}/members)
+	ListCustomRoleMembers(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/roles/{id}/members/{userid})
+	RemoveCustomRoleMember(c *gin.Context, id openapi_types.UUID, userid string)
+
+	// (PUT /api/v1/roles/{id}/members/{userid})
+	AddCustomRoleMember(c *gin.Context, id openapi_types.UUID, userid string)
+
+	// (GET /api/v1/tenant/announcements)
+	ListTenantAnnouncements(c *gin.Context, params ListTenantAnnouncementsParams)
+
+	// (POST /api/v1/tenant/announcements)
+	CreateTenantAnnouncement(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/announcements/{id})
+	DeleteTenantAnnouncement(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/announcements/{id})
+	GetTenantAnnouncement(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /api/v1/tenant/announcements/{id})
+	UpdateTenantAnnouncement(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /api/v1/tenant/api-tokens/revoke-all)
+	RevokeAllTenantAPITokens(c *gin.Context)
+
+	// (GET /api/v1/tenant/auth-failures)
+	GetAuthFailureSummary(c *gin.Context, params GetAuthFailureSummaryParams)
+
+	// (PUT /api/v1/tenant/branding)
+	UpdateTenantBranding(c *gin.Context)
+
+	// (GET /api/v1/tenant/client-applications)
+	ListTenantClientApplications(c *gin.Context, params ListTenantClientApplicationsParams)
+
+	// (POST /api/v1/tenant/client-applications)
+	CreateTenantClientApplication(c *gin.Context)
+
+	// (POST /api/v1/tenant/client-applications/import)
+	ImportTenantClientApplicationConfig(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/client-applications/{id})
+	DeleteTenantClientApplication(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id})
+	GetTenantClientApplicationById(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /api/v1/tenant/client-applications/{id})
+	UpdateTenantClientApplication(c *gin.Context, id openapi_types.UUID)
+
+	// (PATCH /api/v1/tenant/client-applications/{id}/activate)
+	ActivateTenantClientApplication(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id}/config)
+	ExportTenantClientApplicationConfig(c *gin.Context, id openapi_types.UUID)
+
+	// (PATCH /api/v1/tenant/client-applications/{id}/deactivate)
+	DeactivateTenantClientApplication(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/tenant/client-applications/{id}/secret)
+	DeleteTenantClientApplicationSecret(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /api/v1/tenant/client-applications/{id}/secret)
+	RotateTenantClientApplicationSecret(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/tenant/client-applications/{id}/service-account)
+	UnlinkTenantClientApplicationServiceAccount(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /api/v1/tenant/client-applications/{id}/service-account)
+	LinkTenantClientApplicationServiceAccount(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/tenant/client-applications/{id}/signing-key)
+	DeleteTenantClientApplicationSigningKey(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /api/v1/tenant/client-applications/{id}/signing-key)
+	RotateTenantClientApplicationSigningKey(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id}/tokens)
+	ListTenantAPITokens(c *gin.Context, id openapi_types.UUID, params ListTenantAPITokensParams)
+
+	// (POST /api/v1/tenant/client-applications/{id}/tokens)
+	CreateTenantAPIToken(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /api/v1/tenant/client-applications/{id}/tokens/revoke-all)
+	RevokeAllTenantClientApplicationTokens(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/tenant/client-applications/{id}/tokens/{tokenId})
+	DeleteTenantAPIToken(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId})
+	GetTenantAPITokenById(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
+	// (PATCH /api/v1/tenant/client-applications/{id}/tokens/{tokenId})
+	UpdateTenantAPIToken(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/audit)
+	GetTenantAPITokenAuditLogs(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID, params GetTenantAPITokenAuditLogsParams)
+
+	// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/audit/export)
+	ExportTenantAPITokenAuditLogs(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID, params ExportTenantAPITokenAuditLogsParams)
+
+	// (PUT /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/ip-allowlist)
+	UpdateTenantAPITokenIPAllowlist(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
+	// (PATCH /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/revoke)
+	RevokeTenantAPIToken(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/usage)
+	GetTenantAPITokenUsage(c *gin.Context, id openapi_types.UUID, tokenId openapi_types.UUID, params GetTenantAPITokenUsageParams)
+
+	// (DELETE /api/v1/tenant/client-applications/{id}/webhook)
+	DeleteTenantClientApplicationWebhook(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/client-applications/{id}/webhook)
+	GetTenantClientApplicationWebhook(c *gin.Context, id openapi_types.UUID)
+
+	// (POST /api/v1/tenant/client-applications/{id}/webhook)
+	SetTenantClientApplicationWebhook(c *gin.Context, id openapi_types.UUID)
+
+	// (DELETE /api/v1/tenant/directory-sync)
+	DeleteDirectoryConnection(c *gin.Context)
+
+	// (GET /api/v1/tenant/directory-sync)
+	GetDirectoryConnection(c *gin.Context)
+
+	// (PUT /api/v1/tenant/directory-sync)
+	SaveDirectoryConnection(c *gin.Context)
+
+	// (GET /api/v1/tenant/directory-sync/conflicts)
+	ListDirectorySyncConflicts(c *gin.Context, params ListDirectorySyncConflictsParams)
+
+	// (POST /api/v1/tenant/directory-sync/run)
+	RunDirectorySync(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/email-provider)
+	DeleteTenantEmailProvider(c *gin.Context)
+
+	// (GET /api/v1/tenant/email-provider)
+	GetTenantEmailProvider(c *gin.Context)
+
+	// (PUT /api/v1/tenant/email-provider)
+	SaveTenantEmailProvider(c *gin.Context)
+
+	// (POST /api/v1/tenant/email-provider/test)
+	TestTenantEmailProvider(c *gin.Context)
+
+	// (GET /api/v1/tenant/email-templates)
+	ListEmailTemplates(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/email-templates/{event}/{locale})
+	DeleteEmailTemplate(c *gin.Context, event string, locale string)
+
+	// (GET /api/v1/tenant/email-templates/{event}/{locale})
+	GetEmailTemplate(c *gin.Context, event string, locale string)
+
+	// (PUT /api/v1/tenant/email-templates/{event}/{locale})
+	SaveEmailTemplate(c *gin.Context, event string, locale string)
+
+	// (GET /api/v1/tenant/execution-webhooks)
+	ListExecutionWebhooks(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/execution-webhooks/{sourceType}/{sourceId})
+	DeleteExecutionWebhook(c *gin.Context, sourceType string, sourceId string)
+
+	// (GET /api/v1/tenant/execution-webhooks/{sourceType}/{sourceId})
+	GetExecutionWebhook(c *gin.Context, sourceType string, sourceId string)
+
+	// (PUT /api/v1/tenant/execution-webhooks/{sourceType}/{sourceId})
+	SetExecutionWebhook(c *gin.Context, sourceType string, sourceId string)
+
+	// (GET /api/v1/tenant/export-schedules)
+	ListTenantExportSchedules(c *gin.Context)
+
+	// (POST /api/v1/tenant/export-schedules)
+	SaveTenantExportSchedule(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/export-schedules/{id})
+	DeleteTenantExportSchedule(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/exports)
+	ListTenantExports(c *gin.Context, params ListTenantExportsParams)
+
+	// (POST /api/v1/tenant/exports)
+	CreateTenantExport(c *gin.Context)
+
+	// (GET /api/v1/tenant/exports/{id})
+	GetTenantExport(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/exports/{id}/download)
+	DownloadTenantExport(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/feature-flags)
+	GetTenantFeatureFlags(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/llm-consent)
+	DeleteLLMConsentPolicy(c *gin.Context)
+
+	// (GET /api/v1/tenant/llm-consent)
+	GetLLMConsentPolicy(c *gin.Context)
+
+	// (PUT /api/v1/tenant/llm-consent)
+	SetLLMConsentPolicy(c *gin.Context)
+
+	// (GET /api/v1/tenant/lockouts)
+	ListTenantLockouts(c *gin.Context, params ListTenantLockoutsParams)
+
 	// (POST /api/v1/tenant/pictures/background)
 	UploadTenantBackground(c *gin.Context)
 
@@ -737,6 +1816,51 @@ type ServerInterface interface {
 	// (PUT /api/v1/tenant/profile)
 	UpdateTenantProfile(c *gin.Context)
 
+	// (GET /api/v1/tenant/quotas)
+	GetTenantQuotaUsage(c *gin.Context)
+
+	// (GET /api/v1/tenant/scope-templates)
+	ListTenantScopeTemplates(c *gin.Context)
+
+	// (POST /api/v1/tenant/scope-templates)
+	CreateTenantScopeTemplate(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/scope-templates/{id})
+	DeleteTenantScopeTemplate(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/scope-templates/{id})
+	GetTenantScopeTemplate(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /api/v1/tenant/scope-templates/{id})
+	UpdateTenantScopeTemplate(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/tenant/scopes)
+	ListTenantScopes(c *gin.Context)
+
+	// (GET /api/v1/tenant/settings)
+	GetTenantSettingValues(c *gin.Context)
+
+	// (PUT /api/v1/tenant/settings)
+	UpdateTenantSettingValues(c *gin.Context)
+
+	// (GET /api/v1/tenant/sla/overdue)
+	ListSLAOverdueItems(c *gin.Context, params ListSLAOverdueItemsParams)
+
+	// (GET /api/v1/tenant/usage)
+	GetTenantUsage(c *gin.Context, params GetTenantUsageParams)
+
+	// (GET /api/v1/tenant/usage/recommendations)
+	GetTenantUsageRecommendations(c *gin.Context)
+
+	// (GET /api/v1/tenant/user-attributes)
+	ListUserAttributes(c *gin.Context)
+
+	// (DELETE /api/v1/tenant/user-attributes/{key})
+	DeleteUserAttribute(c *gin.Context, key string)
+
+	// (PUT /api/v1/tenant/user-attributes/{key})
+	SaveUserAttribute(c *gin.Context, key string)
+
 	// (GET /api/v1/translations)
 	ListTranslations(c *gin.Context, params ListTranslationsParams)
 
@@ -761,17 +1885,80 @@ type ServerInterface interface {
 	// (POST /api/v1/users)
 	AddUser(c *gin.Context)
 
+	// (POST /api/v1/users/bulk)
+	BulkUpdateUsers(c *gin.Context)
+
 	// (GET /api/v1/users/by-email/{email})
 	GetUserByEmail(c *gin.Context, email string)
 
 	// (GET /api/v1/users/check)
 	CheckUserExists(c *gin.Context, params CheckUserExistsParams)
 
+	// (GET /api/v1/users/export)
+	ExportUsers(c *gin.Context, params ExportUsersParams)
+
 	// (POST /api/v1/users/import)
-	ImportUsersFromAdmin(c *gin.Context)
+	ImportUsersFromAdmin(c *gin.Context, params ImportUsersFromAdminParams)
+
+	// (GET /api/v1/users/import-jobs/{id})
+	GetUserImportJob(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /api/v1/users/invitations)
+	ListUserInvitations(c *gin.Context, params ListUserInvitationsParams)
+
+	// (POST /api/v1/users/invitations)
+	CreateUserInvitation(c *gin.Context)
+
+	// (DELETE /api/v1/users/invitations/{invitationId})
+	RevokeUserInvitation(c *gin.Context, invitationId openapi_types.UUID)
+
+	// (POST /api/v1/users/invitations/{invitationId}/resend)
+	ResendUserInvitation(c *gin.Context, invitationId openapi_types.UUID)
+
+	// (DELETE /api/v1/users/me)
+	DeleteMyAccount(c *gin.Context)
+
+	// (POST /api/v1/users/me/email-change)
+	RequestEmailChange(c *gin.Context)
+
+	// (GET /api/v1/users/me/mfa)
+	GetMyTOTPStatus(c *gin.Context)
+
+	// (POST /api/v1/users/me/mfa/disable)
+	DisableMyTOTP(c *gin.Context)
+
+	// (POST /api/v1/users/me/mfa/enroll)
+	EnrollMyTOTP(c *gin.Context)
+
+	// (POST /api/v1/users/me/mfa/recovery-codes)
+	RegenerateMyTOTPRecoveryCodes(c *gin.Context)
+
+	// (POST /api/v1/users/me/mfa/verify)
+	VerifyMyTOTP(c *gin.Context)
+
+	// (GET /api/v1/users/me/onboarding)
+	GetMyOnboarding(c *gin.Context)
+
+	// (PATCH /api/v1/users/me/onboarding)
+	UpdateMyOnboarding(c *gin.Context)
+
+	// (POST /api/v1/users/me/phone-verification)
+	RequestPhoneVerification(c *gin.Context)
+
+	// (POST /api/v1/users/me/phone-verification/confirm)
+	ConfirmPhoneVerification(c *gin.Context)
+
+	// (GET /api/v1/users/me/sessions)
+	ListMySessions(c *gin.Context)
+
+	// (DELETE /api/v1/users/me/sessions/{sessionid})
+	RevokeMySession(c *gin.Context, sessionid string)
+
+	// (GET /api/v1/users/verification-report)
+	GetUserVerificationReport(c *gin.Context, params GetUserVerificationReportParams)
 
 	// (DELETE /api/v1/users/{userid})
-	DeleteUser(c *gin.Context, userid string)
+	DeleteUser(c *gin.Context, userid string, params DeleteUserParams)
 
 	// (GET /api/v1/users/{userid})
 	GetUserByID(c *gin.Context, userid string)
@@ -779,12 +1966,21 @@ type ServerInterface interface {
 	// (PUT /api/v1/users/{userid})
 	UpdateUser(c *gin.Context, userid string)
 
+	// (GET /api/v1/users/{userid}/audit-logs)
+	GetUserAuditLogs(c *gin.Context, userid string, params GetUserAuditLogsParams)
+
 	// (GET /api/v1/users/{userid}/feature-licenses)
 	GetUserFeatureLicenses(c *gin.Context, userid string, params GetUserFeatureLicensesParams)
 
 	// (PUT /api/v1/users/{userid}/feature-licenses)
 	UpdateUserFeatureLicenses(c *gin.Context, userid string, params UpdateUserFeatureLicensesParams)
 
+	// (DELETE /api/v1/users/{userid}/labels/{label})
+	RemoveUserLabel(c *gin.Context, userid string, label string)
+
+	// (PUT /api/v1/users/{userid}/labels/{label})
+	AddUserLabel(c *gin.Context, userid string, label string)
+
 	// (POST /api/v1/users/{userid}/membership)
 	AddUserMembership(c *gin.Context, userid string)
 
@@ -803,18 +1999,42 @@ type ServerInterface interface {
 	// (POST /api/v1/users/{userid}/roles/{role}/unassign)
 	UnassignRole(c *gin.Context, userid string, role Role)
 
+	// (DELETE /api/v1/users/{userid}/sessions)
+	RevokeUserSessions(c *gin.Context, userid string)
+
 	// (POST /api/v1/users/{userid}/status)
 	UpdateUserStatus(c *gin.Context, userid string)
 	// Identify user and initiate authentication flow
+	// (POST /api/v1/users/{userid}/unlock)
+	UnlockUser(c *gin.Context, userid string)
+
+	// (POST /public-api/v1/account-deletion/cancel)
+	CancelAccountDeletion(c *gin.Context)
+
 	// (POST /public-api/v1/auth/identify)
 	IdentifyUser(c *gin.Context)
 	// Handle password recovery
 	// (GET /public-api/v1/auth/recovery)
 	HandleRecovery(c *gin.Context, params HandleRecoveryParams)
+
+	// (POST /public-api/v1/email-change/confirm)
+	ConfirmEmailChange(c *gin.Context)
+
+	// (POST /public-api/v1/email-change/revert)
+	RevertEmailChange(c *gin.Context)
 	// API Health Check
 	// (GET /public-api/v1/health)
 	GetHealthCheck(c *gin.Context)
 
+	// (POST /public-api/v1/invitations/accept)
+	AcceptUserInvitation(c *gin.Context)
+
+	// (POST /public-api/v1/invitations/lookup)
+	LookupUserInvitation(c *gin.Context)
+
+	// (POST /public-api/v1/oauth/token)
+	IssueOAuthToken(c *gin.Context)
+
 	// (POST /public-api/v1/password-reset-request)
 	ResetPasswordRequest(c *gin.Context)
 
@@ -824,6 +2044,9 @@ type ServerInterface interface {
 	// (GET /public-api/v1/tenant)
 	GetPublicTenant(c *gin.Context)
 
+	// (GET /public-api/v1/tenant/branding)
+	GetPublicTenantBranding(c *gin.Context)
+
 	// (GET /public-api/v1/tenant/pictures/background)
 	GetTenantBackground(c *gin.Context, params GetTenantBackgroundParams)
 
@@ -833,12 +2056,36 @@ type ServerInterface interface {
 	// (GET /public-api/v1/tenant/pictures/logo)
 	GetTenantLogo(c *gin.Context, params GetTenantLogoParams)
 
+	// (GET /public-api/v1/tenant/settings)
+	GetPublicTenantSettings(c *gin.Context)
+
+	// (POST /public-api/v1/token/introspect)
+	IntrospectOAuthToken(c *gin.Context)
+
 	// (GET /public-api/v1/users/{userid}/profile/picture)
 	GetProfilePicture(c *gin.Context, userid string, params GetProfilePictureParams)
 
 	// (POST /public-api/v1/verify-email)
 	VerifyEmail(c *gin.Context)
 
+	// (GET /superadmin-api/v1/announcements)
+	ListGlobalAnnouncements(c *gin.Context, params ListGlobalAnnouncementsParams)
+
+	// (POST /superadmin-api/v1/announcements)
+	CreateGlobalAnnouncement(c *gin.Context)
+
+	// (DELETE /superadmin-api/v1/announcements/{id})
+	DeleteGlobalAnnouncement(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/announcements/{id})
+	GetGlobalAnnouncement(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /superadmin-api/v1/announcements/{id})
+	UpdateGlobalAnnouncement(c *gin.Context, id openapi_types.UUID)
+
+	// (PUT /superadmin-api/v1/client-applications/{id}/token-quota)
+	SetClientApplicationTokenQuota(c *gin.Context, id openapi_types.UUID)
+
 	// (GET /superadmin-api/v1/configs/global-configs)
 	ListGlobalConfigs(c *gin.Context, params ListGlobalConfigsParams)
 
@@ -854,6 +2101,51 @@ type ServerInterface interface {
 	// (PUT /superadmin-api/v1/configs/global-configs/{id})
 	UpdateGlobalConfig(c *gin.Context, id openapi_types.UUID)
 
+	// (GET /superadmin-api/v1/diagnostics/index-recommendations)
+	GetIndexRecommendations(c *gin.Context, params GetIndexRecommendationsParams)
+
+	// (GET /superadmin-api/v1/diagnostics/replay-bundles)
+	ListReplayBundles(c *gin.Context, params ListReplayBundlesParams)
+
+	// (GET /superadmin-api/v1/diagnostics/replay-bundles/{id})
+	GetReplayBundle(c *gin.Context, id openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/diagnostics/support-bundle)
+	GetSupportBundle(c *gin.Context)
+
+	// (GET /superadmin-api/v1/diagnostics/tenant-isolation-probes)
+	ListTenantIsolationProbeResults(c *gin.Context, params ListTenantIsolationProbeResultsParams)
+
+	// (GET /superadmin-api/v1/feature-flags)
+	ListFeatureFlags(c *gin.Context)
+
+	// (DELETE /superadmin-api/v1/feature-flags/{key})
+	DeleteFeatureFlag(c *gin.Context, key string)
+
+	// (PUT /superadmin-api/v1/feature-flags/{key})
+	SaveFeatureFlag(c *gin.Context, key string)
+
+	// (GET /superadmin-api/v1/file-encryption)
+	ListTenantFileEncryption(c *gin.Context, params ListTenantFileEncryptionParams)
+
+	// (GET /superadmin-api/v1/lockouts)
+	ListAuthLockouts(c *gin.Context, params ListAuthLockoutsParams)
+
+	// (POST /superadmin-api/v1/lockouts/unlock)
+	UnlockAuthSubject(c *gin.Context)
+
+	// (GET /superadmin-api/v1/tenant-templates)
+	ListTenantTemplates(c *gin.Context)
+
+	// (DELETE /superadmin-api/v1/tenant-templates/{name})
+	DeleteTenantTemplate(c *gin.Context, name string)
+
+	// (PUT /superadmin-api/v1/tenant-templates/{name})
+	SaveTenantTemplate(c *gin.Context, name string)
+
+	// (POST /superadmin-api/v1/tenant-templates/{name}/provision)
+	ProvisionTenantFromTemplate(c *gin.Context, name string)
+
 	// (GET /superadmin-api/v1/tenant/{tenantid}/feature-licenses)
 	GetTenantFeatureLicenses(c *gin.Context, tenantid openapi_types.UUID)
 
@@ -866,11 +2158,29 @@ type ServerInterface interface {
 	// (PUT /superadmin-api/v1/tenant/{tenantid}/features)
 	UpdateTenantFeatures(c *gin.Context, tenantid openapi_types.UUID)
 
-	// (GET /superadmin-api/v1/tenants)
-	ListTenants(c *gin.Context, params ListTenantsParams)
+	// (GET /superadmin-api/v1/tenant/{tenantid}/settings/export)
+	ExportTenantSettings(c *gin.Context, tenantid openapi_types.UUID, params ExportTenantSettingsParams)
 
-	// (POST /superadmin-api/v1/tenants)
-	AddTenant(c *gin.Context)
+	// (POST /superadmin-api/v1/tenant/{tenantid}/settings/import)
+	ImportTenantSettings(c *gin.Context, tenantid openapi_types.UUID, params ImportTenantSettingsParams)
+
+	// (GET /superadmin-api/v1/tenant/{tenantid}/subdomains)
+	ListTenantSubdomains(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (POST /superadmin-api/v1/tenant/{tenantid}/subdomains)
+	AddTenantSubdomainAlias(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (DELETE /superadmin-api/v1/tenant/{tenantid}/subdomains/{subdomain})
+	DeleteTenantSubdomainAlias(c *gin.Context, tenantid openapi_types.UUID, subdomain string)
+
+	// (POST /superadmin-api/v1/tenant/{tenantid}/subdomains/{subdomain}/primary)
+	SetPrimaryTenantSubdomain(c *gin.Context, tenantid openapi_types.UUID, subdomain string)
+
+	// (GET /superadmin-api/v1/tenants)
+	ListTenants(c *gin.Context, params ListTenantsParams)
+
+	// (POST /superadmin-api/v1/tenants)
+	AddTenant(c *gin.Context)
 
 	// (DELETE /superadmin-api/v1/tenants/{tenantid})
 	DeleteTenant(c *gin.Context, tenantid openapi_types.UUID)
@@ -881,6 +2191,78 @@ type ServerInterface interface {
 	// (PUT /superadmin-api/v1/tenants/{tenantid})
 	UpdateTenant(c *gin.Context, tenantid openapi_types.UUID)
 
+	// (POST /superadmin-api/v1/tenants/{tenantid}/clone)
+	CloneTenant(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/export)
+	StartTenantDataExport(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/export/{jobid})
+	GetTenantDataExport(c *gin.Context, tenantid openapi_types.UUID, jobid openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/export/{jobid}/download)
+	DownloadTenantDataExport(c *gin.Context, tenantid openapi_types.UUID, jobid openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/feature-flags)
+	ListTenantFeatureFlags(c *gin.Context, tenantid string)
+
+	// (DELETE /superadmin-api/v1/tenants/{tenantid}/feature-flags/{key})
+	DeleteTenantFeatureFlagOverride(c *gin.Context, tenantid string, key string)
+
+	// (PUT /superadmin-api/v1/tenants/{tenantid}/feature-flags/{key})
+	SetTenantFeatureFlagOverride(c *gin.Context, tenantid string, key string)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/file-encryption)
+	GetTenantFileEncryption(c *gin.Context, tenantid string)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/file-encryption)
+	EnableTenantFileEncryption(c *gin.Context, tenantid string)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/file-encryption/rotate)
+	RotateTenantFileKey(c *gin.Context, tenantid string)
+
+	// (DELETE /superadmin-api/v1/tenants/{tenantid}/purge)
+	CancelTenantPurge(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/purge)
+	GetTenantPurge(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/purge)
+	ScheduleTenantPurge(c *gin.Context, tenantid openapi_types.UUID, params ScheduleTenantPurgeParams)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/quotas)
+	GetTenantQuotas(c *gin.Context, tenantid string)
+
+	// (PUT /superadmin-api/v1/tenants/{tenantid}/quotas)
+	SetTenantQuotas(c *gin.Context, tenantid string)
+
+	// (DELETE /superadmin-api/v1/tenants/{tenantid}/sandbox)
+	DeleteTenantSandbox(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/sandbox)
+	GetTenantSandbox(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (PUT /superadmin-api/v1/tenants/{tenantid}/sandbox)
+	SetTenantSandbox(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/sandbox/reset)
+	ResetTenantSandbox(c *gin.Context, tenantid openapi_types.UUID, params ResetTenantSandboxParams)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/status)
+	SetTenantStatus(c *gin.Context, tenantid openapi_types.UUID)
+
+	// (DELETE /superadmin-api/v1/tenants/{tenantid}/token-policy)
+	DeleteTokenPolicy(c *gin.Context, tenantid string)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/token-policy)
+	GetTokenPolicy(c *gin.Context, tenantid string)
+
+	// (PUT /superadmin-api/v1/tenants/{tenantid}/token-policy)
+	SetTokenPolicy(c *gin.Context, tenantid string)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/usage/recommendations)
+	ListTenantUsageRecommendations(c *gin.Context, tenantid string)
+
 	// (GET /superadmin-api/v1/tenants/{tenantid}/users)
 	ListUsersFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, params ListUsersFromSuperAdminParams)
 
@@ -890,8 +2272,11 @@ type ServerInterface interface {
 	// (GET /superadmin-api/v1/tenants/{tenantid}/users/check)
 	CheckUserExistsFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, params CheckUserExistsFromSuperAdminParams)
 
+	// (POST /superadmin-api/v1/tenants/{tenantid}/users/claims/rebuild)
+	RebuildUserClaimsFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, params RebuildUserClaimsFromSuperAdminParams)
+
 	// (DELETE /superadmin-api/v1/tenants/{tenantid}/users/{userid})
-	DeleteUserFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
+	DeleteUserFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string, params DeleteUserFromSuperAdminParams)
 
 	// (GET /superadmin-api/v1/tenants/{tenantid}/users/{userid})
 	GetUserByIDFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
@@ -900,7 +2285,13 @@ type ServerInterface interface {
 	UpdateUserFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
 
 	// (DELETE /superadmin-api/v1/tenants/{tenantid}/users/{userid}/hard-delete)
-	HardDeleteUserFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
+	HardDeleteUserFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string, params HardDeleteUserFromSuperAdminParams)
+
+	// (DELETE /superadmin-api/v1/tenants/{tenantid}/users/{userid}/impersonate)
+	EndUserImpersonationFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
+
+	// (POST /superadmin-api/v1/tenants/{tenantid}/users/{userid}/impersonate)
+	ImpersonateUserFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
 
 	// (POST /superadmin-api/v1/tenants/{tenantid}/users/{userid}/membership)
 	AddUserMembershipFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
@@ -922,6 +2313,24 @@ type ServerInterface interface {
 
 	// (POST /superadmin-api/v1/tenants/{tenantid}/users/{userid}/status)
 	UpdateUserStatusFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string)
+
+	// (GET /superadmin-api/v1/tenants/{tenantid}/users/{userid}/timeline)
+	GetUserTimelineFromSuperAdmin(c *gin.Context, tenantid openapi_types.UUID, userid string, params GetUserTimelineFromSuperAdminParams)
+
+	// (GET /superadmin-api/v1/token-policies)
+	ListTokenPolicies(c *gin.Context)
+
+	// (GET /superadmin-api/v1/usage)
+	ListTenantsUsage(c *gin.Context, params ListTenantsUsageParams)
+
+	// (POST /superadmin-api/v1/users/consistency)
+	CheckUserMembershipConsistency(c *gin.Context, params CheckUserMembershipConsistencyParams)
+
+	// (POST /superadmin-api/v1/users/email-encryption)
+	CheckUserEmailEncryption(c *gin.Context, params CheckUserEmailEncryptionParams)
+
+	// (POST /superadmin-api/v1/users/merge)
+	MergeUsers(c *gin.Context, params MergeUsersParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -933,6 +2342,19 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// RevokeAllAPITokens operation middleware
+func (siw *ServerInterfaceWrapper) RevokeAllAPITokens(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeAllAPITokens(c)
+}
+
 // ListClientApplications operation middleware
 func (siw *ServerInterfaceWrapper) ListClientApplications(c *gin.Context) {
 
@@ -1012,6 +2434,19 @@ func (siw *ServerInterfaceWrapper) CreateClientApplication(c *gin.Context) {
 	siw.Handler.CreateClientApplication(c)
 }
 
+// ImportClientApplicationConfig operation middleware
+func (siw *ServerInterfaceWrapper) ImportClientApplicationConfig(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ImportClientApplicationConfig(c)
+}
+
 // DeleteClientApplication operation middleware
 func (siw *ServerInterfaceWrapper) DeleteClientApplication(c *gin.Context) {
 
@@ -1084,8 +2519,8 @@ func (siw *ServerInterfaceWrapper) UpdateClientApplication(c *gin.Context) {
 	siw.Handler.UpdateClientApplication(c, id)
 }
 
-// DeactivateClientApplication operation middleware
-func (siw *ServerInterfaceWrapper) DeactivateClientApplication(c *gin.Context) {
+// ActivateClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) ActivateClientApplication(c *gin.Context) {
 
 	var err error
 
@@ -1105,11 +2540,11 @@ func (siw *ServerInterfaceWrapper) DeactivateClientApplication(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeactivateClientApplication(c, id)
+	siw.Handler.ActivateClientApplication(c, id)
 }
 
-// ListAPITokens operation middleware
-func (siw *ServerInterfaceWrapper) ListAPITokens(c *gin.Context) {
+// ExportClientApplicationConfig operation middleware
+func (siw *ServerInterfaceWrapper) ExportClientApplicationConfig(c *gin.Context) {
 
 	var err error
 
@@ -1122,54 +2557,27 @@ func (siw *ServerInterfaceWrapper) ListAPITokens(c *gin.Context) {
 		return
 	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ListAPITokensParams
-
-	// ------------- Optional query parameter "page" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	// ------------- Optional query parameter "pageSize" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	// ------------- Optional query parameter "sortBy" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
 	}
 
-	// ------------- Optional query parameter "order" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
-		return
-	}
+	siw.Handler.ExportClientApplicationConfig(c, id)
+}
 
-	// ------------- Optional query parameter "includeRevoked" -------------
+// DeactivateClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) DeactivateClientApplication(c *gin.Context) {
 
-	err = runtime.BindQueryParameter("form", true, false, "includeRevoked", c.Request.URL.Query(), &params.IncludeRevoked)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeRevoked: %w", err), http.StatusBadRequest)
-		return
-	}
+	var err error
 
-	// ------------- Optional query parameter "includeExpired" -------------
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
 
-	err = runtime.BindQueryParameter("form", true, false, "includeExpired", c.Request.URL.Query(), &params.IncludeExpired)
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeExpired: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1180,11 +2588,11 @@ func (siw *ServerInterfaceWrapper) ListAPITokens(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ListAPITokens(c, id, params)
+	siw.Handler.DeactivateClientApplication(c, id)
 }
 
-// CreateAPIToken operation middleware
-func (siw *ServerInterfaceWrapper) CreateAPIToken(c *gin.Context) {
+// DeleteClientApplicationSecret operation middleware
+func (siw *ServerInterfaceWrapper) DeleteClientApplicationSecret(c *gin.Context) {
 
 	var err error
 
@@ -1204,11 +2612,11 @@ func (siw *ServerInterfaceWrapper) CreateAPIToken(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CreateAPIToken(c, id)
+	siw.Handler.DeleteClientApplicationSecret(c, id)
 }
 
-// DeleteAPIToken operation middleware
-func (siw *ServerInterfaceWrapper) DeleteAPIToken(c *gin.Context) {
+// RotateClientApplicationSecret operation middleware
+func (siw *ServerInterfaceWrapper) RotateClientApplicationSecret(c *gin.Context) {
 
 	var err error
 
@@ -1221,15 +2629,6 @@ func (siw *ServerInterfaceWrapper) DeleteAPIToken(c *gin.Context) {
 		return
 	}
 
-	// ------------- Path parameter "tokenId" -------------
-	var tokenId openapi_types.UUID
-
-	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
-		return
-	}
-
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -1237,11 +2636,11 @@ func (siw *ServerInterfaceWrapper) DeleteAPIToken(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteAPIToken(c, id, tokenId)
+	siw.Handler.RotateClientApplicationSecret(c, id)
 }
 
-// GetAPITokenById operation middleware
-func (siw *ServerInterfaceWrapper) GetAPITokenById(c *gin.Context) {
+// UnlinkClientApplicationServiceAccount operation middleware
+func (siw *ServerInterfaceWrapper) UnlinkClientApplicationServiceAccount(c *gin.Context) {
 
 	var err error
 
@@ -1254,15 +2653,6 @@ func (siw *ServerInterfaceWrapper) GetAPITokenById(c *gin.Context) {
 		return
 	}
 
-	// ------------- Path parameter "tokenId" -------------
-	var tokenId openapi_types.UUID
-
-	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
-		return
-	}
-
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -1270,11 +2660,11 @@ func (siw *ServerInterfaceWrapper) GetAPITokenById(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetAPITokenById(c, id, tokenId)
+	siw.Handler.UnlinkClientApplicationServiceAccount(c, id)
 }
 
-// GetAPITokenAuditLogs operation middleware
-func (siw *ServerInterfaceWrapper) GetAPITokenAuditLogs(c *gin.Context) {
+// LinkClientApplicationServiceAccount operation middleware
+func (siw *ServerInterfaceWrapper) LinkClientApplicationServiceAccount(c *gin.Context) {
 
 	var err error
 
@@ -1287,31 +2677,27 @@ func (siw *ServerInterfaceWrapper) GetAPITokenAuditLogs(c *gin.Context) {
 		return
 	}
 
-	// ------------- Path parameter "tokenId" -------------
-	var tokenId openapi_types.UUID
-
-	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
 	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetAPITokenAuditLogsParams
+	siw.Handler.LinkClientApplicationServiceAccount(c, id)
+}
 
-	// ------------- Optional query parameter "page" -------------
+// DeleteClientApplicationSigningKey operation middleware
+func (siw *ServerInterfaceWrapper) DeleteClientApplicationSigningKey(c *gin.Context) {
 
-	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
-		return
-	}
+	var err error
 
-	// ------------- Optional query parameter "pageSize" -------------
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
 
-	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1322,11 +2708,11 @@ func (siw *ServerInterfaceWrapper) GetAPITokenAuditLogs(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetAPITokenAuditLogs(c, id, tokenId, params)
+	siw.Handler.DeleteClientApplicationSigningKey(c, id)
 }
 
-// RevokeAPIToken operation middleware
-func (siw *ServerInterfaceWrapper) RevokeAPIToken(c *gin.Context) {
+// RotateClientApplicationSigningKey operation middleware
+func (siw *ServerInterfaceWrapper) RotateClientApplicationSigningKey(c *gin.Context) {
 
 	var err error
 
@@ -1339,15 +2725,6 @@ func (siw *ServerInterfaceWrapper) RevokeAPIToken(c *gin.Context) {
 		return
 	}
 
-	// ------------- Path parameter "tokenId" -------------
-	var tokenId openapi_types.UUID
-
-	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
-		return
-	}
-
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -1355,16 +2732,25 @@ func (siw *ServerInterfaceWrapper) RevokeAPIToken(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.RevokeAPIToken(c, id, tokenId)
+	siw.Handler.RotateClientApplicationSigningKey(c, id)
 }
 
-// ListTenantConfigs operation middleware
-func (siw *ServerInterfaceWrapper) ListTenantConfigs(c *gin.Context) {
+// ListAPITokens operation middleware
+func (siw *ServerInterfaceWrapper) ListAPITokens(c *gin.Context) {
 
 	var err error
 
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ListTenantConfigsParams
+	var params ListAPITokensParams
 
 	// ------------- Optional query parameter "page" -------------
 
@@ -1398,19 +2784,19 @@ func (siw *ServerInterfaceWrapper) ListTenantConfigs(c *gin.Context) {
 		return
 	}
 
-	// ------------- Optional query parameter "q" -------------
+	// ------------- Optional query parameter "includeRevoked" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	err = runtime.BindQueryParameter("form", true, false, "includeRevoked", c.Request.URL.Query(), &params.IncludeRevoked)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeRevoked: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "detail" -------------
+	// ------------- Optional query parameter "includeExpired" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "detail", c.Request.URL.Query(), &params.Detail)
+	err = runtime.BindQueryParameter("form", true, false, "includeExpired", c.Request.URL.Query(), &params.IncludeExpired)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter detail: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeExpired: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1421,24 +2807,11 @@ func (siw *ServerInterfaceWrapper) ListTenantConfigs(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ListTenantConfigs(c, params)
-}
-
-// AddTenantConfig operation middleware
-func (siw *ServerInterfaceWrapper) AddTenantConfig(c *gin.Context) {
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
-
-	siw.Handler.AddTenantConfig(c)
+	siw.Handler.ListAPITokens(c, id, params)
 }
 
-// DeleteTenantConfig operation middleware
-func (siw *ServerInterfaceWrapper) DeleteTenantConfig(c *gin.Context) {
+// CreateAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) CreateAPIToken(c *gin.Context) {
 
 	var err error
 
@@ -1458,11 +2831,11 @@ func (siw *ServerInterfaceWrapper) DeleteTenantConfig(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteTenantConfig(c, id)
+	siw.Handler.CreateAPIToken(c, id)
 }
 
-// GetTenantConfigByID operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantConfigByID(c *gin.Context) {
+// RevokeAllClientApplicationTokens operation middleware
+func (siw *ServerInterfaceWrapper) RevokeAllClientApplicationTokens(c *gin.Context) {
 
 	var err error
 
@@ -1482,11 +2855,11 @@ func (siw *ServerInterfaceWrapper) GetTenantConfigByID(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantConfigByID(c, id)
+	siw.Handler.RevokeAllClientApplicationTokens(c, id)
 }
 
-// UpdateTenantConfig operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTenantConfig(c *gin.Context) {
+// DeleteAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAPIToken(c *gin.Context) {
 
 	var err error
 
@@ -1499,18 +2872,5299 @@ func (siw *ServerInterfaceWrapper) UpdateTenantConfig(c *gin.Context) {
 		return
 	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAPIToken(c, id, tokenId)
+}
+
+// GetAPITokenById operation middleware
+func (siw *ServerInterfaceWrapper) GetAPITokenById(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAPITokenById(c, id, tokenId)
+}
+
+// UpdateAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) UpdateAPIToken(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateAPIToken(c, id, tokenId)
+}
+
+// GetAPITokenAuditLogs operation middleware
+func (siw *ServerInterfaceWrapper) GetAPITokenAuditLogs(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAPITokenAuditLogsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "action" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "action", c.Request.URL.Query(), &params.Action)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter action: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAPITokenAuditLogs(c, id, tokenId, params)
+}
+
+// ExportAPITokenAuditLogs operation middleware
+func (siw *ServerInterfaceWrapper) ExportAPITokenAuditLogs(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportAPITokenAuditLogsParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportAPITokenAuditLogs(c, id, tokenId, params)
+}
+
+// UpdateAPITokenIPAllowlist operation middleware
+func (siw *ServerInterfaceWrapper) UpdateAPITokenIPAllowlist(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateAPITokenIPAllowlist(c, id, tokenId)
+}
+
+// RevokeAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) RevokeAPIToken(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeAPIToken(c, id, tokenId)
+}
+
+// GetAPITokenUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetAPITokenUsage(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAPITokenUsageParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "topEndpoints" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "topEndpoints", c.Request.URL.Query(), &params.TopEndpoints)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter topEndpoints: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAPITokenUsage(c, id, tokenId, params)
+}
+
+// DeleteClientApplicationWebhook operation middleware
+func (siw *ServerInterfaceWrapper) DeleteClientApplicationWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteClientApplicationWebhook(c, id)
+}
+
+// GetClientApplicationWebhook operation middleware
+func (siw *ServerInterfaceWrapper) GetClientApplicationWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetClientApplicationWebhook(c, id)
+}
+
+// SetClientApplicationWebhook operation middleware
+func (siw *ServerInterfaceWrapper) SetClientApplicationWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetClientApplicationWebhook(c, id)
+}
+
+// ListScopeTemplates operation middleware
+func (siw *ServerInterfaceWrapper) ListScopeTemplates(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListScopeTemplates(c)
+}
+
+// CreateScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) CreateScopeTemplate(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateScopeTemplate(c)
+}
+
+// DeleteScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) DeleteScopeTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteScopeTemplate(c, id)
+}
+
+// GetScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) GetScopeTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetScopeTemplate(c, id)
+}
+
+// UpdateScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) UpdateScopeTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateScopeTemplate(c, id)
+}
+
+// ListScopes operation middleware
+func (siw *ServerInterfaceWrapper) ListScopes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListScopes(c)
+}
+
+// ListActiveAnnouncements operation middleware
+func (siw *ServerInterfaceWrapper) ListActiveAnnouncements(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListActiveAnnouncements(c)
+}
+
+// ListTenantConfigs operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantConfigs(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantConfigsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sortBy" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "detail" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "detail", c.Request.URL.Query(), &params.Detail)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter detail: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantConfigs(c, params)
+}
+
+// AddTenantConfig operation middleware
+func (siw *ServerInterfaceWrapper) AddTenantConfig(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddTenantConfig(c)
+}
+
+// DeleteTenantConfig operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantConfig(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantConfig(c, id)
+}
+
+// GetTenantConfigByID operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantConfigByID(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantConfigByID(c, id)
+}
+
+// UpdateTenantConfig operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantConfig(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantConfig(c, id)
+}
+
+// ListDelegations operation middleware
+func (siw *ServerInterfaceWrapper) ListDelegations(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListDelegationsParams
+
+	// ------------- Optional query parameter "all" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "all", c.Request.URL.Query(), &params.All)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter all: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "includeExpired" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeExpired", c.Request.URL.Query(), &params.IncludeExpired)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeExpired: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListDelegations(c, params)
+}
+
+// CreateDelegation operation middleware
+func (siw *ServerInterfaceWrapper) CreateDelegation(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateDelegation(c)
+}
+
+// RevokeDelegation operation middleware
+func (siw *ServerInterfaceWrapper) RevokeDelegation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeDelegation(c, id)
+}
+
+// ListGroups operation middleware
+func (siw *ServerInterfaceWrapper) ListGroups(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListGroups(c)
+}
+
+// CreateGroup operation middleware
+func (siw *ServerInterfaceWrapper) CreateGroup(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateGroup(c)
+}
+
+// DeleteGroup operation middleware
+func (siw *ServerInterfaceWrapper) DeleteGroup(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteGroup(c, id)
+}
+
+// GetGroup operation middleware
+func (siw *ServerInterfaceWrapper) GetGroup(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetGroup(c, id)
+}
+
+// UpdateGroup operation middleware
+func (siw *ServerInterfaceWrapper) UpdateGroup(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateGroup(c, id)
+}
+
+// ListGroupMembers operation middleware
+func (siw *ServerInterfaceWrapper) ListGroupMembers(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListGroupMembers(c, id)
+}
+
+// RemoveGroupMember operation middleware
+func (siw *ServerInterfaceWrapper) RemoveGroupMember(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RemoveGroupMember(c, id, userid)
+}
+
+// AddGroupMember operation middleware
+func (siw *ServerInterfaceWrapper) AddGroupMember(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddGroupMember(c, id, userid)
+}
+
+// DownloadImportErrors operation middleware
+func (siw *ServerInterfaceWrapper) DownloadImportErrors(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DownloadImportErrors(c, id)
+}
+
+// ListJobEvents operation middleware
+func (siw *ServerInterfaceWrapper) ListJobEvents(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListJobEventsParams
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", c.Request.URL.Query(), &params.Since)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter since: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "wait" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "wait", c.Request.URL.Query(), &params.Wait)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter wait: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListJobEvents(c, id, params)
+}
+
+// CreateMeUser operation middleware
+func (siw *ServerInterfaceWrapper) CreateMeUser(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateMeUser(c)
+}
+
+// ResendEmailVerification operation middleware
+func (siw *ServerInterfaceWrapper) ResendEmailVerification(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ResendEmailVerification(c)
+}
+
+// GetMyEmailVerificationStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetMyEmailVerificationStatus(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMyEmailVerificationStatus(c)
+}
+
+// GetMyFeatureLicenses operation middleware
+func (siw *ServerInterfaceWrapper) GetMyFeatureLicenses(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMyFeatureLicenses(c)
+}
+
+// GetMeProfile operation middleware
+func (siw *ServerInterfaceWrapper) GetMeProfile(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMeProfile(c)
+}
+
+// UpdateMeProfile operation middleware
+func (siw *ServerInterfaceWrapper) UpdateMeProfile(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateMeProfile(c)
+}
+
+// UploadProfilePicture operation middleware
+func (siw *ServerInterfaceWrapper) UploadProfilePicture(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UploadProfilePicture(c)
+}
+
+// InitializeSettingsFlow operation middleware
+func (siw *ServerInterfaceWrapper) InitializeSettingsFlow(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.InitializeSettingsFlow(c)
+}
+
+// GetMFAStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetMFAStatus(c *gin.Context) {
+
+	c.Set(BearerAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMFAStatus(c)
+}
+
+// DisableWebAuthn operation middleware
+func (siw *ServerInterfaceWrapper) DisableWebAuthn(c *gin.Context) {
+
+	c.Set(CookieAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DisableWebAuthn(c)
+}
+
+// ListResellerTenants operation middleware
+func (siw *ServerInterfaceWrapper) ListResellerTenants(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListResellerTenants(c)
+}
+
+// ListCustomRoles operation middleware
+func (siw *ServerInterfaceWrapper) ListCustomRoles(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListCustomRoles(c)
+}
+
+// CreateCustomRole operation middleware
+func (siw *ServerInterfaceWrapper) CreateCustomRole(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateCustomRole(c)
+}
+
+// GetPermissionMatrix operation middleware
+func (siw *ServerInterfaceWrapper) GetPermissionMatrix(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetPermissionMatrix(c)
+}
+
+// DeleteCustomRole operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCustomRole(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteCustomRole(c, id)
+}
+
+// GetCustomRole operation middleware
+func (siw *ServerInterfaceWrapper) GetCustomRole(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetCustomRole(c, id)
+}
+
+// UpdateCustomRole operation middleware
+func (siw *ServerInterfaceWrapper) UpdateCustomRole(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateCustomRole(c, id)
+}
+
+// ListCustomRoleMembers operation middleware
+func (siw *ServerInterfaceWrapper) ListCustomRoleMembers(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListCustomRoleMembers(c, id)
+}
+
+// RemoveCustomRoleMember operation middleware
+func (siw *ServerInterfaceWrapper) RemoveCustomRoleMember(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RemoveCustomRoleMember(c, id, userid)
+}
+
+// AddCustomRoleMember operation middleware
+func (siw *ServerInterfaceWrapper) AddCustomRoleMember(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddCustomRoleMember(c, id, userid)
+}
+
+// ListTenantAnnouncements operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantAnnouncements(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantAnnouncementsParams
+
+	// ------------- Optional query parameter "includeExpired" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeExpired", c.Request.URL.Query(), &params.IncludeExpired)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeExpired: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantAnnouncements(c, params)
+}
+
+// CreateTenantAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) CreateTenantAnnouncement(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateTenantAnnouncement(c)
+}
+
+// DeleteTenantAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantAnnouncement(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantAnnouncement(c, id)
+}
+
+// GetTenantAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantAnnouncement(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantAnnouncement(c, id)
+}
+
+// UpdateTenantAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantAnnouncement(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantAnnouncement(c, id)
+}
+
+// RevokeAllTenantAPITokens operation middleware
+func (siw *ServerInterfaceWrapper) RevokeAllTenantAPITokens(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeAllTenantAPITokens(c)
+}
+
+// GetAuthFailureSummary operation middleware
+func (siw *ServerInterfaceWrapper) GetAuthFailureSummary(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuthFailureSummaryParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAuthFailureSummary(c, params)
+}
+
+// UpdateTenantBranding operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantBranding(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantBranding(c)
+}
+
+// ListTenantClientApplications operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantClientApplications(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantClientApplicationsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sortBy" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "includeInactive" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeInactive", c.Request.URL.Query(), &params.IncludeInactive)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeInactive: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantClientApplications(c, params)
+}
+
+// CreateTenantClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) CreateTenantClientApplication(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateTenantClientApplication(c)
+}
+
+// ImportTenantClientApplicationConfig operation middleware
+func (siw *ServerInterfaceWrapper) ImportTenantClientApplicationConfig(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ImportTenantClientApplicationConfig(c)
+}
+
+// DeleteTenantClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantClientApplication(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantClientApplication(c, id)
+}
+
+// GetTenantClientApplicationById operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantClientApplicationById(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantClientApplicationById(c, id)
+}
+
+// UpdateTenantClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantClientApplication(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantClientApplication(c, id)
+}
+
+// ActivateTenantClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) ActivateTenantClientApplication(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ActivateTenantClientApplication(c, id)
+}
+
+// ExportTenantClientApplicationConfig operation middleware
+func (siw *ServerInterfaceWrapper) ExportTenantClientApplicationConfig(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportTenantClientApplicationConfig(c, id)
+}
+
+// DeactivateTenantClientApplication operation middleware
+func (siw *ServerInterfaceWrapper) DeactivateTenantClientApplication(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeactivateTenantClientApplication(c, id)
+}
+
+// DeleteTenantClientApplicationSecret operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantClientApplicationSecret(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantClientApplicationSecret(c, id)
+}
+
+// RotateTenantClientApplicationSecret operation middleware
+func (siw *ServerInterfaceWrapper) RotateTenantClientApplicationSecret(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RotateTenantClientApplicationSecret(c, id)
+}
+
+// UnlinkTenantClientApplicationServiceAccount operation middleware
+func (siw *ServerInterfaceWrapper) UnlinkTenantClientApplicationServiceAccount(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UnlinkTenantClientApplicationServiceAccount(c, id)
+}
+
+// LinkTenantClientApplicationServiceAccount operation middleware
+func (siw *ServerInterfaceWrapper) LinkTenantClientApplicationServiceAccount(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.LinkTenantClientApplicationServiceAccount(c, id)
+}
+
+// DeleteTenantClientApplicationSigningKey operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantClientApplicationSigningKey(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantClientApplicationSigningKey(c, id)
+}
+
+// RotateTenantClientApplicationSigningKey operation middleware
+func (siw *ServerInterfaceWrapper) RotateTenantClientApplicationSigningKey(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RotateTenantClientApplicationSigningKey(c, id)
+}
+
+// ListTenantAPITokens operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantAPITokens(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantAPITokensParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sortBy" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "includeRevoked" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeRevoked", c.Request.URL.Query(), &params.IncludeRevoked)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeRevoked: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "includeExpired" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeExpired", c.Request.URL.Query(), &params.IncludeExpired)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeExpired: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantAPITokens(c, id, params)
+}
+
+// CreateTenantAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) CreateTenantAPIToken(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateTenantAPIToken(c, id)
+}
+
+// RevokeAllTenantClientApplicationTokens operation middleware
+func (siw *ServerInterfaceWrapper) RevokeAllTenantClientApplicationTokens(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeAllTenantClientApplicationTokens(c, id)
+}
+
+// DeleteTenantAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantAPIToken(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantAPIToken(c, id, tokenId)
+}
+
+// GetTenantAPITokenById operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantAPITokenById(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantAPITokenById(c, id, tokenId)
+}
+
+// UpdateTenantAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantAPIToken(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantAPIToken(c, id, tokenId)
+}
+
+// GetTenantAPITokenAuditLogs operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantAPITokenAuditLogs(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTenantAPITokenAuditLogsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "action" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "action", c.Request.URL.Query(), &params.Action)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter action: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantAPITokenAuditLogs(c, id, tokenId, params)
+}
+
+// ExportTenantAPITokenAuditLogs operation middleware
+func (siw *ServerInterfaceWrapper) ExportTenantAPITokenAuditLogs(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportTenantAPITokenAuditLogsParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportTenantAPITokenAuditLogs(c, id, tokenId, params)
+}
+
+// UpdateTenantAPITokenIPAllowlist operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantAPITokenIPAllowlist(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantAPITokenIPAllowlist(c, id, tokenId)
+}
+
+// RevokeTenantAPIToken operation middleware
+func (siw *ServerInterfaceWrapper) RevokeTenantAPIToken(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeTenantAPIToken(c, id, tokenId)
+}
+
+// GetTenantAPITokenUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantAPITokenUsage(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "tokenId" -------------
+	var tokenId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tokenId", c.Param("tokenId"), &tokenId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tokenId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTenantAPITokenUsageParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "topEndpoints" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "topEndpoints", c.Request.URL.Query(), &params.TopEndpoints)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter topEndpoints: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantAPITokenUsage(c, id, tokenId, params)
+}
+
+// DeleteTenantClientApplicationWebhook operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantClientApplicationWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantClientApplicationWebhook(c, id)
+}
+
+// GetTenantClientApplicationWebhook operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantClientApplicationWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantClientApplicationWebhook(c, id)
+}
+
+// SetTenantClientApplicationWebhook operation middleware
+func (siw *ServerInterfaceWrapper) SetTenantClientApplicationWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetTenantClientApplicationWebhook(c, id)
+}
+
+// DeleteDirectoryConnection operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDirectoryConnection(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteDirectoryConnection(c)
+}
+
+// GetDirectoryConnection operation middleware
+func (siw *ServerInterfaceWrapper) GetDirectoryConnection(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetDirectoryConnection(c)
+}
+
+// SaveDirectoryConnection operation middleware
+func (siw *ServerInterfaceWrapper) SaveDirectoryConnection(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SaveDirectoryConnection(c)
+}
+
+// ListDirectorySyncConflicts operation middleware
+func (siw *ServerInterfaceWrapper) ListDirectorySyncConflicts(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListDirectorySyncConflictsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListDirectorySyncConflicts(c, params)
+}
+
+// RunDirectorySync operation middleware
+func (siw *ServerInterfaceWrapper) RunDirectorySync(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RunDirectorySync(c)
+}
+
+// DeleteTenantEmailProvider operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantEmailProvider(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantEmailProvider(c)
+}
+
+// GetTenantEmailProvider operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantEmailProvider(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantEmailProvider(c)
+}
+
+// SaveTenantEmailProvider operation middleware
+func (siw *ServerInterfaceWrapper) SaveTenantEmailProvider(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SaveTenantEmailProvider(c)
+}
+
+// TestTenantEmailProvider operation middleware
+func (siw *ServerInterfaceWrapper) TestTenantEmailProvider(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.TestTenantEmailProvider(c)
+}
+
+// ListEmailTemplates operation middleware
+func (siw *ServerInterfaceWrapper) ListEmailTemplates(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListEmailTemplates(c)
+}
+
+// DeleteEmailTemplate operation middleware
+func (siw *ServerInterfaceWrapper) DeleteEmailTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "event" -------------
+	var event string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "event", c.Param("event"), &event, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter event: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "locale" -------------
+	var locale string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "locale", c.Param("locale"), &locale, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter locale: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteEmailTemplate(c, event, locale)
+}
+
+// GetEmailTemplate operation middleware
+func (siw *ServerInterfaceWrapper) GetEmailTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "event" -------------
+	var event string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "event", c.Param("event"), &event, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter event: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "locale" -------------
+	var locale string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "locale", c.Param("locale"), &locale, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter locale: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetEmailTemplate(c, event, locale)
+}
+
+// SaveEmailTemplate operation middleware
+func (siw *ServerInterfaceWrapper) SaveEmailTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "event" -------------
+	var event string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "event", c.Param("event"), &event, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter event: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "locale" -------------
+	var locale string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "locale", c.Param("locale"), &locale, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter locale: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SaveEmailTemplate(c, event, locale)
+}
+
+// ListExecutionWebhooks operation middleware
+func (siw *ServerInterfaceWrapper) ListExecutionWebhooks(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListExecutionWebhooks(c)
+}
+
+// DeleteExecutionWebhook operation middleware
+func (siw *ServerInterfaceWrapper) DeleteExecutionWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "sourceType" -------------
+	var sourceType string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sourceType", c.Param("sourceType"), &sourceType, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceType: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "sourceId" -------------
+	var sourceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sourceId", c.Param("sourceId"), &sourceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteExecutionWebhook(c, sourceType, sourceId)
+}
+
+// GetExecutionWebhook operation middleware
+func (siw *ServerInterfaceWrapper) GetExecutionWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "sourceType" -------------
+	var sourceType string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sourceType", c.Param("sourceType"), &sourceType, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceType: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "sourceId" -------------
+	var sourceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sourceId", c.Param("sourceId"), &sourceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetExecutionWebhook(c, sourceType, sourceId)
+}
+
+// SetExecutionWebhook operation middleware
+func (siw *ServerInterfaceWrapper) SetExecutionWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "sourceType" -------------
+	var sourceType string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sourceType", c.Param("sourceType"), &sourceType, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceType: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "sourceId" -------------
+	var sourceId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sourceId", c.Param("sourceId"), &sourceId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetExecutionWebhook(c, sourceType, sourceId)
+}
+
+// ListTenantExportSchedules operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantExportSchedules(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantExportSchedules(c)
+}
+
+// SaveTenantExportSchedule operation middleware
+func (siw *ServerInterfaceWrapper) SaveTenantExportSchedule(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SaveTenantExportSchedule(c)
+}
+
+// DeleteTenantExportSchedule operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantExportSchedule(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantExportSchedule(c, id)
+}
+
+// ListTenantExports operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantExports(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantExportsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantExports(c, params)
+}
+
+// CreateTenantExport operation middleware
+func (siw *ServerInterfaceWrapper) CreateTenantExport(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateTenantExport(c)
+}
+
+// GetTenantExport operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantExport(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantExport(c, id)
+}
+
+// DownloadTenantExport operation middleware
+func (siw *ServerInterfaceWrapper) DownloadTenantExport(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DownloadTenantExport(c, id)
+}
+
+// GetTenantFeatureFlags operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantFeatureFlags(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantFeatureFlags(c)
+}
+
+// DeleteLLMConsentPolicy operation middleware
+func (siw *ServerInterfaceWrapper) DeleteLLMConsentPolicy(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteLLMConsentPolicy(c)
+}
+
+// GetLLMConsentPolicy operation middleware
+func (siw *ServerInterfaceWrapper) GetLLMConsentPolicy(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetLLMConsentPolicy(c)
+}
+
+// SetLLMConsentPolicy operation middleware
+func (siw *ServerInterfaceWrapper) SetLLMConsentPolicy(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetLLMConsentPolicy(c)
+}
+
+// ListTenantLockouts operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantLockouts(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantLockoutsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantLockouts(c, params)
+}
+
+// UploadTenantBackground operation middleware
+func (siw *ServerInterfaceWrapper) UploadTenantBackground(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UploadTenantBackground(c)
+}
+
+// UploadTenantBackgroundMobile operation middleware
+func (siw *ServerInterfaceWrapper) UploadTenantBackgroundMobile(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UploadTenantBackgroundMobile(c)
+}
+
+// UploadTenantLogo operation middleware
+func (siw *ServerInterfaceWrapper) UploadTenantLogo(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UploadTenantLogo(c)
+}
+
+// GetTenantProfile operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantProfile(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantProfile(c)
+}
+
+// UpdateTenantProfile operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantProfile(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantProfile(c)
+}
+
+// GetTenantQuotaUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantQuotaUsage(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantQuotaUsage(c)
+}
+
+// ListTenantScopeTemplates operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantScopeTemplates(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantScopeTemplates(c)
+}
+
+// CreateTenantScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) CreateTenantScopeTemplate(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateTenantScopeTemplate(c)
+}
+
+// DeleteTenantScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantScopeTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTenantScopeTemplate(c, id)
+}
+
+// GetTenantScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantScopeTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantScopeTemplate(c, id)
+}
+
+// UpdateTenantScopeTemplate operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantScopeTemplate(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantScopeTemplate(c, id)
+}
+
+// ListTenantScopes operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantScopes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantScopes(c)
+}
+
+// GetTenantSettingValues operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantSettingValues(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantSettingValues(c)
+}
+
+// UpdateTenantSettingValues operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantSettingValues(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTenantSettingValues(c)
+}
+
+// ListSLAOverdueItems operation middleware
+func (siw *ServerInterfaceWrapper) ListSLAOverdueItems(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListSLAOverdueItemsParams
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", c.Request.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter type: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListSLAOverdueItems(c, params)
+}
+
+// GetTenantUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantUsage(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTenantUsageParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantUsage(c, params)
+}
+
+// GetTenantUsageRecommendations operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantUsageRecommendations(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantUsageRecommendations(c)
+}
+
+// ListUserAttributes operation middleware
+func (siw *ServerInterfaceWrapper) ListUserAttributes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListUserAttributes(c)
+}
+
+// DeleteUserAttribute operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUserAttribute(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", c.Param("key"), &key, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteUserAttribute(c, key)
+}
+
+// SaveUserAttribute operation middleware
+func (siw *ServerInterfaceWrapper) SaveUserAttribute(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", c.Param("key"), &key, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SaveUserAttribute(c, key)
+}
+
+// ListTranslations operation middleware
+func (siw *ServerInterfaceWrapper) ListTranslations(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTranslationsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page_size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page_size", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page_size: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sort_by" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort_by", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort_by: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "lang" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "lang", c.Request.URL.Query(), &params.Lang)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter lang: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", c.Request.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter type: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "key", c.Request.URL.Query(), &params.Key)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTranslations(c, params)
+}
+
+// CreateTranslation operation middleware
+func (siw *ServerInterfaceWrapper) CreateTranslation(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateTranslation(c)
+}
+
+// GetTranslation operation middleware
+func (siw *ServerInterfaceWrapper) GetTranslation(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTranslationParams
+
+	// ------------- Required query parameter "entityType" -------------
+
+	if paramValue := c.Query("entityType"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument entityType is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "entityType", c.Request.URL.Query(), &params.EntityType)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter entityType: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "entityId" -------------
+
+	if paramValue := c.Query("entityId"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument entityId is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "entityId", c.Request.URL.Query(), &params.EntityId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter entityId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "field" -------------
+
+	if paramValue := c.Query("field"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument field is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "field", c.Request.URL.Query(), &params.Field)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter field: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "language" -------------
+
+	if paramValue := c.Query("language"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument language is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "language", c.Request.URL.Query(), &params.Language)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter language: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTranslation(c, params)
+}
+
+// DeleteTranslation operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTranslation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTranslation(c, id)
+}
+
+// GetTranslationByID operation middleware
+func (siw *ServerInterfaceWrapper) GetTranslationByID(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTranslationByIDParams
+
+	// ------------- Optional query parameter "lang" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "lang", c.Request.URL.Query(), &params.Lang)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter lang: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTranslationByID(c, id, params)
+}
+
+// UpdateTranslation operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTranslation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateTranslation(c, id)
+}
+
+// ListUsers operation middleware
+func (siw *ServerInterfaceWrapper) ListUsers(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListUsersParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sortBy" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "detail" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "detail", c.Request.URL.Query(), &params.Detail)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter detail: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "scope" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "scope", c.Request.URL.Query(), &params.Scope)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scope: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "inactiveDays" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "inactiveDays", c.Request.URL.Query(), &params.InactiveDays)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter inactiveDays: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "role" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "role", c.Request.URL.Query(), &params.Role)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter role: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "labels" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "labels", c.Request.URL.Query(), &params.Labels)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter labels: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "disabled" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "disabled", c.Request.URL.Query(), &params.Disabled)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter disabled: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "emailVerified" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "emailVerified", c.Request.URL.Query(), &params.EmailVerified)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter emailVerified: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdFrom" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdFrom", c.Request.URL.Query(), &params.CreatedFrom)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdFrom: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdTo" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdTo", c.Request.URL.Query(), &params.CreatedTo)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdTo: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "envelope" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "envelope", c.Request.URL.Query(), &params.Envelope)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter envelope: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListUsers(c, params)
+}
+
+// AddUser operation middleware
+func (siw *ServerInterfaceWrapper) AddUser(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddUser(c)
+}
+
+// BulkUpdateUsers operation middleware
+func (siw *ServerInterfaceWrapper) BulkUpdateUsers(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.BulkUpdateUsers(c)
+}
+
+// GetUserByEmail operation middleware
+func (siw *ServerInterfaceWrapper) GetUserByEmail(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "email" -------------
+	var email string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "email", c.Param("email"), &email, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter email: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserByEmail(c, email)
+}
+
+// CheckUserExists operation middleware
+func (siw *ServerInterfaceWrapper) CheckUserExists(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CheckUserExistsParams
+
+	// ------------- Required query parameter "email" -------------
+
+	if paramValue := c.Query("email"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument email is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "email", c.Request.URL.Query(), &params.Email)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter email: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CheckUserExists(c, params)
+}
+
+// ExportUsers operation middleware
+func (siw *ServerInterfaceWrapper) ExportUsers(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportUsersParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "scope" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "scope", c.Request.URL.Query(), &params.Scope)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scope: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportUsers(c, params)
+}
+
+// ImportUsersFromAdmin operation middleware
+func (siw *ServerInterfaceWrapper) ImportUsersFromAdmin(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportUsersFromAdminParams
+
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ImportUsersFromAdmin(c, params)
+}
+
+// GetUserImportJob operation middleware
+func (siw *ServerInterfaceWrapper) GetUserImportJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserImportJob(c, id)
+}
+
+// ListUserInvitations operation middleware
+func (siw *ServerInterfaceWrapper) ListUserInvitations(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListUserInvitationsParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListUserInvitations(c, params)
+}
+
+// CreateUserInvitation operation middleware
+func (siw *ServerInterfaceWrapper) CreateUserInvitation(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateUserInvitation(c)
+}
+
+// RevokeUserInvitation operation middleware
+func (siw *ServerInterfaceWrapper) RevokeUserInvitation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "invitationId" -------------
+	var invitationId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "invitationId", c.Param("invitationId"), &invitationId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter invitationId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeUserInvitation(c, invitationId)
+}
+
+// ResendUserInvitation operation middleware
+func (siw *ServerInterfaceWrapper) ResendUserInvitation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "invitationId" -------------
+	var invitationId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "invitationId", c.Param("invitationId"), &invitationId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter invitationId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ResendUserInvitation(c, invitationId)
+}
+
+// DeleteMyAccount operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMyAccount(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteMyAccount(c)
+}
+
+// RequestEmailChange operation middleware
+func (siw *ServerInterfaceWrapper) RequestEmailChange(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RequestEmailChange(c)
+}
+
+// GetMyTOTPStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetMyTOTPStatus(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMyTOTPStatus(c)
+}
+
+// DisableMyTOTP operation middleware
+func (siw *ServerInterfaceWrapper) DisableMyTOTP(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DisableMyTOTP(c)
+}
+
+// EnrollMyTOTP operation middleware
+func (siw *ServerInterfaceWrapper) EnrollMyTOTP(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.EnrollMyTOTP(c)
+}
+
+// RegenerateMyTOTPRecoveryCodes operation middleware
+func (siw *ServerInterfaceWrapper) RegenerateMyTOTPRecoveryCodes(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RegenerateMyTOTPRecoveryCodes(c)
+}
+
+// VerifyMyTOTP operation middleware
+func (siw *ServerInterfaceWrapper) VerifyMyTOTP(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.VerifyMyTOTP(c)
+}
+
+// GetMyOnboarding operation middleware
+func (siw *ServerInterfaceWrapper) GetMyOnboarding(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetMyOnboarding(c)
+}
+
+// UpdateMyOnboarding operation middleware
+func (siw *ServerInterfaceWrapper) UpdateMyOnboarding(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateMyOnboarding(c)
+}
+
+// RequestPhoneVerification operation middleware
+func (siw *ServerInterfaceWrapper) RequestPhoneVerification(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RequestPhoneVerification(c)
+}
+
+// ConfirmPhoneVerification operation middleware
+func (siw *ServerInterfaceWrapper) ConfirmPhoneVerification(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ConfirmPhoneVerification(c)
+}
+
+// ListMySessions operation middleware
+func (siw *ServerInterfaceWrapper) ListMySessions(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListMySessions(c)
+}
+
+// RevokeMySession operation middleware
+func (siw *ServerInterfaceWrapper) RevokeMySession(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "sessionid" -------------
+	var sessionid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sessionid", c.Param("sessionid"), &sessionid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sessionid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeMySession(c, sessionid)
+}
+
+// GetUserVerificationReport operation middleware
+func (siw *ServerInterfaceWrapper) GetUserVerificationReport(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUserVerificationReportParams
+
+	// ------------- Optional query parameter "olderThanDays" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "olderThanDays", c.Request.URL.Query(), &params.OlderThanDays)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter olderThanDays: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserVerificationReport(c, params)
+}
+
+// DeleteUser operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUser(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteUserParams
+
+	// ------------- Optional query parameter "transferTo" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "transferTo", c.Request.URL.Query(), &params.TransferTo)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter transferTo: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "transferToTenantOwner" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "transferToTenantOwner", c.Request.URL.Query(), &params.TransferToTenantOwner)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter transferToTenantOwner: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "mode" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "mode", c.Request.URL.Query(), &params.Mode)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter mode: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "confirmIrreversible" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "confirmIrreversible", c.Request.URL.Query(), &params.ConfirmIrreversible)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter confirmIrreversible: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteUser(c, userid, params)
+}
+
+// GetUserByID operation middleware
+func (siw *ServerInterfaceWrapper) GetUserByID(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserByID(c, userid)
+}
+
+// UpdateUser operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUser(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateUser(c, userid)
+}
+
+// GetUserAuditLogs operation middleware
+func (siw *ServerInterfaceWrapper) GetUserAuditLogs(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUserAuditLogsParams
+
+	// ------------- Optional query parameter "actions" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actions", c.Request.URL.Query(), &params.Actions)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter actions: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserAuditLogs(c, userid, params)
+}
+
+// GetUserFeatureLicenses operation middleware
+func (siw *ServerInterfaceWrapper) GetUserFeatureLicenses(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUserFeatureLicensesParams
+
+	// ------------- Optional query parameter "tenant_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tenant_id", c.Request.URL.Query(), &params.TenantId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenant_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserFeatureLicenses(c, userid, params)
+}
+
+// UpdateUserFeatureLicenses operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUserFeatureLicenses(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateUserFeatureLicensesParams
+
+	// ------------- Optional query parameter "tenant_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tenant_id", c.Request.URL.Query(), &params.TenantId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenant_id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateUserFeatureLicenses(c, userid, params)
+}
+
+// RemoveUserLabel operation middleware
+func (siw *ServerInterfaceWrapper) RemoveUserLabel(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "label" -------------
+	var label string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "label", c.Param("label"), &label, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter label: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RemoveUserLabel(c, userid, label)
+}
+
+// AddUserLabel operation middleware
+func (siw *ServerInterfaceWrapper) AddUserLabel(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "label" -------------
+	var label string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "label", c.Param("label"), &label, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter label: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddUserLabel(c, userid, label)
+}
+
+// AddUserMembership operation middleware
+func (siw *ServerInterfaceWrapper) AddUserMembership(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddUserMembership(c, userid)
+}
+
+// ResetPasswordRequestByAdmin operation middleware
+func (siw *ServerInterfaceWrapper) ResetPasswordRequestByAdmin(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ResetPasswordRequestByAdmin(c, userid)
+}
+
+// ReactivateUser operation middleware
+func (siw *ServerInterfaceWrapper) ReactivateUser(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ReactivateUser(c, userid)
+}
+
+// RemoveUserFromTenant operation middleware
+func (siw *ServerInterfaceWrapper) RemoveUserFromTenant(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RemoveUserFromTenant(c, userid)
+}
+
+// AssignRole operation middleware
+func (siw *ServerInterfaceWrapper) AssignRole(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "role" -------------
+	var role Role
+
+	err = runtime.BindStyledParameterWithOptions("simple", "role", c.Param("role"), &role, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter role: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AssignRole(c, userid, role)
+}
+
+// UnassignRole operation middleware
+func (siw *ServerInterfaceWrapper) UnassignRole(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "role" -------------
+	var role Role
+
+	err = runtime.BindStyledParameterWithOptions("simple", "role", c.Param("role"), &role, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter role: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UnassignRole(c, userid, role)
+}
+
+// RevokeUserSessions operation middleware
+func (siw *ServerInterfaceWrapper) RevokeUserSessions(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevokeUserSessions(c, userid)
+}
+
+// UpdateUserStatus operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUserStatus(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateUserStatus(c, userid)
+}
+
+// UnlockUser operation middleware
+func (siw *ServerInterfaceWrapper) UnlockUser(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UnlockUser(c, userid)
+}
+
+// CancelAccountDeletion operation middleware
+func (siw *ServerInterfaceWrapper) CancelAccountDeletion(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CancelAccountDeletion(c)
+}
+
+// IdentifyUser operation middleware
+func (siw *ServerInterfaceWrapper) IdentifyUser(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.IdentifyUser(c)
+}
+
+// HandleRecovery operation middleware
+func (siw *ServerInterfaceWrapper) HandleRecovery(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params HandleRecoveryParams
+
+	// ------------- Required query parameter "flow" -------------
+
+	if paramValue := c.Query("flow"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument flow is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "flow", c.Request.URL.Query(), &params.Flow)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flow: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "token" -------------
+
+	if paramValue := c.Query("token"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument token is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "token", c.Request.URL.Query(), &params.Token)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter token: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.HandleRecovery(c, params)
+}
+
+// ConfirmEmailChange operation middleware
+func (siw *ServerInterfaceWrapper) ConfirmEmailChange(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ConfirmEmailChange(c)
+}
+
+// RevertEmailChange operation middleware
+func (siw *ServerInterfaceWrapper) RevertEmailChange(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RevertEmailChange(c)
+}
+
+// GetHealthCheck operation middleware
+func (siw *ServerInterfaceWrapper) GetHealthCheck(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetHealthCheck(c)
+}
+
+// AcceptUserInvitation operation middleware
+func (siw *ServerInterfaceWrapper) AcceptUserInvitation(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AcceptUserInvitation(c)
+}
+
+// LookupUserInvitation operation middleware
+func (siw *ServerInterfaceWrapper) LookupUserInvitation(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.LookupUserInvitation(c)
+}
+
+// IssueOAuthToken operation middleware
+func (siw *ServerInterfaceWrapper) IssueOAuthToken(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.IssueOAuthToken(c)
+}
+
+// ResetPasswordRequest operation middleware
+func (siw *ServerInterfaceWrapper) ResetPasswordRequest(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ResetPasswordRequest(c)
+}
+
+// Signup operation middleware
+func (siw *ServerInterfaceWrapper) Signup(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.Signup(c)
+}
+
+// GetPublicTenant operation middleware
+func (siw *ServerInterfaceWrapper) GetPublicTenant(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetPublicTenant(c)
+}
+
+// GetPublicTenantBranding operation middleware
+func (siw *ServerInterfaceWrapper) GetPublicTenantBranding(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetPublicTenantBranding(c)
+}
+
+// GetTenantBackground operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantBackground(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTenantBackgroundParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantBackground(c, params)
+}
+
+// GetTenantBackgroundMobile operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantBackgroundMobile(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTenantBackgroundMobileParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantBackgroundMobile(c, params)
+}
+
+// GetTenantLogo operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantLogo(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTenantLogoParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTenantLogo(c, params)
+}
+
+// GetPublicTenantSettings operation middleware
+func (siw *ServerInterfaceWrapper) GetPublicTenantSettings(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetPublicTenantSettings(c)
+}
+
+// IntrospectOAuthToken operation middleware
+func (siw *ServerInterfaceWrapper) IntrospectOAuthToken(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.IntrospectOAuthToken(c)
+}
+
+// GetProfilePicture operation middleware
+func (siw *ServerInterfaceWrapper) GetProfilePicture(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetProfilePictureParams
+
+	// ------------- Optional query parameter "size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "size", c.Request.URL.Query(), &params.Size)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter size: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetProfilePicture(c, userid, params)
+}
+
+// VerifyEmail operation middleware
+func (siw *ServerInterfaceWrapper) VerifyEmail(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.VerifyEmail(c)
+}
+
+// ListGlobalAnnouncements operation middleware
+func (siw *ServerInterfaceWrapper) ListGlobalAnnouncements(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListGlobalAnnouncementsParams
+
+	// ------------- Optional query parameter "includeExpired" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeExpired", c.Request.URL.Query(), &params.IncludeExpired)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter includeExpired: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	siw.Handler.UpdateTenantConfig(c, id)
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListGlobalAnnouncements(c, params)
 }
 
-// CreateMeUser operation middleware
-func (siw *ServerInterfaceWrapper) CreateMeUser(c *gin.Context) {
+// CreateGlobalAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) CreateGlobalAnnouncement(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1519,11 +8173,22 @@ func (siw *ServerInterfaceWrapper) CreateMeUser(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CreateMeUser(c)
+	siw.Handler.CreateGlobalAnnouncement(c)
 }
 
-// ResendEmailVerification operation middleware
-func (siw *ServerInterfaceWrapper) ResendEmailVerification(c *gin.Context) {
+// DeleteGlobalAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) DeleteGlobalAnnouncement(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1532,11 +8197,22 @@ func (siw *ServerInterfaceWrapper) ResendEmailVerification(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ResendEmailVerification(c)
+	siw.Handler.DeleteGlobalAnnouncement(c, id)
 }
 
-// GetMyEmailVerificationStatus operation middleware
-func (siw *ServerInterfaceWrapper) GetMyEmailVerificationStatus(c *gin.Context) {
+// GetGlobalAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) GetGlobalAnnouncement(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1545,11 +8221,22 @@ func (siw *ServerInterfaceWrapper) GetMyEmailVerificationStatus(c *gin.Context)
 		}
 	}
 
-	siw.Handler.GetMyEmailVerificationStatus(c)
+	siw.Handler.GetGlobalAnnouncement(c, id)
 }
 
-// GetMyFeatureLicenses operation middleware
-func (siw *ServerInterfaceWrapper) GetMyFeatureLicenses(c *gin.Context) {
+// UpdateGlobalAnnouncement operation middleware
+func (siw *ServerInterfaceWrapper) UpdateGlobalAnnouncement(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1558,11 +8245,22 @@ func (siw *ServerInterfaceWrapper) GetMyFeatureLicenses(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetMyFeatureLicenses(c)
+	siw.Handler.UpdateGlobalAnnouncement(c, id)
 }
 
-// GetMeProfile operation middleware
-func (siw *ServerInterfaceWrapper) GetMeProfile(c *gin.Context) {
+// SetClientApplicationTokenQuota operation middleware
+func (siw *ServerInterfaceWrapper) SetClientApplicationTokenQuota(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1571,11 +8269,64 @@ func (siw *ServerInterfaceWrapper) GetMeProfile(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetMeProfile(c)
+	siw.Handler.SetClientApplicationTokenQuota(c, id)
 }
 
-// UpdateMeProfile operation middleware
-func (siw *ServerInterfaceWrapper) UpdateMeProfile(c *gin.Context) {
+// ListGlobalConfigs operation middleware
+func (siw *ServerInterfaceWrapper) ListGlobalConfigs(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListGlobalConfigsParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sortBy" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "detail" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "detail", c.Request.URL.Query(), &params.Detail)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter detail: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1584,11 +8335,11 @@ func (siw *ServerInterfaceWrapper) UpdateMeProfile(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateMeProfile(c)
+	siw.Handler.ListGlobalConfigs(c, params)
 }
 
-// UploadProfilePicture operation middleware
-func (siw *ServerInterfaceWrapper) UploadProfilePicture(c *gin.Context) {
+// AddGlobalConfig operation middleware
+func (siw *ServerInterfaceWrapper) AddGlobalConfig(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1597,13 +8348,146 @@ func (siw *ServerInterfaceWrapper) UploadProfilePicture(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UploadProfilePicture(c)
+	siw.Handler.AddGlobalConfig(c)
+}
+
+// DeleteGlobalConfig operation middleware
+func (siw *ServerInterfaceWrapper) DeleteGlobalConfig(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteGlobalConfig(c, id)
+}
+
+// GetGlobalConfigByID operation middleware
+func (siw *ServerInterfaceWrapper) GetGlobalConfigByID(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetGlobalConfigByID(c, id)
+}
+
+// UpdateGlobalConfig operation middleware
+func (siw *ServerInterfaceWrapper) UpdateGlobalConfig(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateGlobalConfig(c, id)
+}
+
+// GetIndexRecommendations operation middleware
+func (siw *ServerInterfaceWrapper) GetIndexRecommendations(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetIndexRecommendationsParams
+
+	// ------------- Optional query parameter "minCalls" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minCalls", c.Request.URL.Query(), &params.MinCalls)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter minCalls: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetIndexRecommendations(c, params)
 }
 
-// InitializeSettingsFlow operation middleware
-func (siw *ServerInterfaceWrapper) InitializeSettingsFlow(c *gin.Context) {
+// ListReplayBundles operation middleware
+func (siw *ServerInterfaceWrapper) ListReplayBundles(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListReplayBundlesParams
+
+	// ------------- Optional query parameter "tenantId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tenantId", c.Request.URL.Query(), &params.TenantId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "requestId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "requestId", c.Request.URL.Query(), &params.RequestId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter requestId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
 
-	c.Set(BearerAuthScopes, []string{})
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1612,13 +8496,22 @@ func (siw *ServerInterfaceWrapper) InitializeSettingsFlow(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.InitializeSettingsFlow(c)
+	siw.Handler.ListReplayBundles(c, params)
 }
 
-// GetMFAStatus operation middleware
-func (siw *ServerInterfaceWrapper) GetMFAStatus(c *gin.Context) {
+// GetReplayBundle operation middleware
+func (siw *ServerInterfaceWrapper) GetReplayBundle(c *gin.Context) {
 
-	c.Set(BearerAuthScopes, []string{})
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1627,13 +8520,11 @@ func (siw *ServerInterfaceWrapper) GetMFAStatus(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetMFAStatus(c)
+	siw.Handler.GetReplayBundle(c, id)
 }
 
-// DisableWebAuthn operation middleware
-func (siw *ServerInterfaceWrapper) DisableWebAuthn(c *gin.Context) {
-
-	c.Set(CookieAuthScopes, []string{})
+// GetSupportBundle operation middleware
+func (siw *ServerInterfaceWrapper) GetSupportBundle(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1642,37 +8533,40 @@ func (siw *ServerInterfaceWrapper) DisableWebAuthn(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DisableWebAuthn(c)
+	siw.Handler.GetSupportBundle(c)
 }
 
-// ListResellerTenants operation middleware
-func (siw *ServerInterfaceWrapper) ListResellerTenants(c *gin.Context) {
+// ListTenantIsolationProbeResults operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantIsolationProbeResults(c *gin.Context) {
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	var err error
 
-	siw.Handler.ListResellerTenants(c)
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantIsolationProbeResultsParams
 
-// UploadTenantBackground operation middleware
-func (siw *ServerInterfaceWrapper) UploadTenantBackground(c *gin.Context) {
+	// ------------- Optional query parameter "outcome" -------------
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
+	err = runtime.BindQueryParameter("form", true, false, "outcome", c.Request.URL.Query(), &params.Outcome)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter outcome: %w", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.UploadTenantBackground(c)
-}
+	// ------------- Optional query parameter "limit" -------------
 
-// UploadTenantBackgroundMobile operation middleware
-func (siw *ServerInterfaceWrapper) UploadTenantBackgroundMobile(c *gin.Context) {
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1681,11 +8575,11 @@ func (siw *ServerInterfaceWrapper) UploadTenantBackgroundMobile(c *gin.Context)
 		}
 	}
 
-	siw.Handler.UploadTenantBackgroundMobile(c)
+	siw.Handler.ListTenantIsolationProbeResults(c, params)
 }
 
-// UploadTenantLogo operation middleware
-func (siw *ServerInterfaceWrapper) UploadTenantLogo(c *gin.Context) {
+// ListFeatureFlags operation middleware
+func (siw *ServerInterfaceWrapper) ListFeatureFlags(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1694,11 +8588,22 @@ func (siw *ServerInterfaceWrapper) UploadTenantLogo(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UploadTenantLogo(c)
+	siw.Handler.ListFeatureFlags(c)
 }
 
-// GetTenantProfile operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantProfile(c *gin.Context) {
+// DeleteFeatureFlag operation middleware
+func (siw *ServerInterfaceWrapper) DeleteFeatureFlag(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", c.Param("key"), &key, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1707,11 +8612,22 @@ func (siw *ServerInterfaceWrapper) GetTenantProfile(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantProfile(c)
+	siw.Handler.DeleteFeatureFlag(c, key)
 }
 
-// UpdateTenantProfile operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTenantProfile(c *gin.Context) {
+// SaveFeatureFlag operation middleware
+func (siw *ServerInterfaceWrapper) SaveFeatureFlag(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "key", c.Param("key"), &key, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1720,78 +8636,80 @@ func (siw *ServerInterfaceWrapper) UpdateTenantProfile(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateTenantProfile(c)
+	siw.Handler.SaveFeatureFlag(c, key)
 }
 
-// ListTranslations operation middleware
-func (siw *ServerInterfaceWrapper) ListTranslations(c *gin.Context) {
+// ListTenantFileEncryption operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantFileEncryption(c *gin.Context) {
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ListTranslationsParams
+	var params ListTenantFileEncryptionParams
 
-	// ------------- Optional query parameter "page" -------------
+	// ------------- Optional query parameter "limit" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "page_size" -------------
+	// ------------- Optional query parameter "offset" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "page_size", c.Request.URL.Query(), &params.PageSize)
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page_size: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "sort_by" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "sort_by", c.Request.URL.Query(), &params.SortBy)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort_by: %w", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
 	}
 
-	// ------------- Optional query parameter "order" -------------
+	siw.Handler.ListTenantFileEncryption(c, params)
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
-		return
-	}
+// ListAuthLockouts operation middleware
+func (siw *ServerInterfaceWrapper) ListAuthLockouts(c *gin.Context) {
 
-	// ------------- Optional query parameter "q" -------------
+	var err error
 
-	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListAuthLockoutsParams
+
+	// ------------- Optional query parameter "subjectType" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "subjectType", c.Request.URL.Query(), &params.SubjectType)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter subjectType: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "lang" -------------
+	// ------------- Optional query parameter "tenantId" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "lang", c.Request.URL.Query(), &params.Lang)
+	err = runtime.BindQueryParameter("form", true, false, "tenantId", c.Request.URL.Query(), &params.TenantId)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter lang: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantId: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "type" -------------
+	// ------------- Optional query parameter "limit" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "type", c.Request.URL.Query(), &params.Type)
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter type: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "key" -------------
+	// ------------- Optional query parameter "offset" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "key", c.Request.URL.Query(), &params.Key)
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1802,11 +8720,11 @@ func (siw *ServerInterfaceWrapper) ListTranslations(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ListTranslations(c, params)
+	siw.Handler.ListAuthLockouts(c, params)
 }
 
-// CreateTranslation operation middleware
-func (siw *ServerInterfaceWrapper) CreateTranslation(c *gin.Context) {
+// UnlockAuthSubject operation middleware
+func (siw *ServerInterfaceWrapper) UnlockAuthSubject(c *gin.Context) {
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -1815,74 +8733,81 @@ func (siw *ServerInterfaceWrapper) CreateTranslation(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CreateTranslation(c)
+	siw.Handler.UnlockAuthSubject(c)
 }
 
-// GetTranslation operation middleware
-func (siw *ServerInterfaceWrapper) GetTranslation(c *gin.Context) {
+// ListTenantTemplates operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantTemplates(c *gin.Context) {
 
-	var err error
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTranslationParams
+	siw.Handler.ListTenantTemplates(c)
+}
 
-	// ------------- Required query parameter "entityType" -------------
+// DeleteTenantTemplate operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantTemplate(c *gin.Context) {
 
-	if paramValue := c.Query("entityType"); paramValue != "" {
+	var err error
 
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument entityType is required, but not found"), http.StatusBadRequest)
-		return
-	}
+	// ------------- Path parameter "name" -------------
+	var name string
 
-	err = runtime.BindQueryParameter("form", true, true, "entityType", c.Request.URL.Query(), &params.EntityType)
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter entityType: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Required query parameter "entityId" -------------
-
-	if paramValue := c.Query("entityId"); paramValue != "" {
-
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument entityId is required, but not found"), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "entityId", c.Request.URL.Query(), &params.EntityId)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter entityId: %w", err), http.StatusBadRequest)
-		return
-	}
+	siw.Handler.DeleteTenantTemplate(c, name)
+}
 
-	// ------------- Required query parameter "field" -------------
+// SaveTenantTemplate operation middleware
+func (siw *ServerInterfaceWrapper) SaveTenantTemplate(c *gin.Context) {
 
-	if paramValue := c.Query("field"); paramValue != "" {
+	var err error
 
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument field is required, but not found"), http.StatusBadRequest)
-		return
-	}
+	// ------------- Path parameter "name" -------------
+	var name string
 
-	err = runtime.BindQueryParameter("form", true, true, "field", c.Request.URL.Query(), &params.Field)
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter field: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Required query parameter "language" -------------
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
 
-	if paramValue := c.Query("language"); paramValue != "" {
+	siw.Handler.SaveTenantTemplate(c, name)
+}
 
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument language is required, but not found"), http.StatusBadRequest)
-		return
-	}
+// ProvisionTenantFromTemplate operation middleware
+func (siw *ServerInterfaceWrapper) ProvisionTenantFromTemplate(c *gin.Context) {
 
-	err = runtime.BindQueryParameter("form", true, true, "language", c.Request.URL.Query(), &params.Language)
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter language: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1893,20 +8818,20 @@ func (siw *ServerInterfaceWrapper) GetTranslation(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTranslation(c, params)
+	siw.Handler.ProvisionTenantFromTemplate(c, name)
 }
 
-// DeleteTranslation operation middleware
-func (siw *ServerInterfaceWrapper) DeleteTranslation(c *gin.Context) {
+// GetTenantFeatureLicenses operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantFeatureLicenses(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1917,31 +8842,44 @@ func (siw *ServerInterfaceWrapper) DeleteTranslation(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteTranslation(c, id)
+	siw.Handler.GetTenantFeatureLicenses(c, tenantid)
 }
 
-// GetTranslationByID operation middleware
-func (siw *ServerInterfaceWrapper) GetTranslationByID(c *gin.Context) {
+// UpdateTenantFeatureLicenses operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantFeatureLicenses(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTranslationByIDParams
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
 
-	// ------------- Optional query parameter "lang" -------------
+	siw.Handler.UpdateTenantFeatureLicenses(c, tenantid)
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "lang", c.Request.URL.Query(), &params.Lang)
+// GetTenantFeatures operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantFeatures(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter lang: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1952,20 +8890,20 @@ func (siw *ServerInterfaceWrapper) GetTranslationByID(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTranslationByID(c, id, params)
+	siw.Handler.GetTenantFeatures(c, tenantid)
 }
 
-// UpdateTranslation operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTranslation(c *gin.Context) {
+// UpdateTenantFeatures operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenantFeatures(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -1976,70 +8914,66 @@ func (siw *ServerInterfaceWrapper) UpdateTranslation(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateTranslation(c, id)
+	siw.Handler.UpdateTenantFeatures(c, tenantid)
 }
 
-// ListUsers operation middleware
-func (siw *ServerInterfaceWrapper) ListUsers(c *gin.Context) {
+// ExportTenantSettings operation middleware
+func (siw *ServerInterfaceWrapper) ExportTenantSettings(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ListUsersParams
-
-	// ------------- Optional query parameter "page" -------------
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "pageSize" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
-		return
-	}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportTenantSettingsParams
 
-	// ------------- Optional query parameter "sortBy" -------------
+	// ------------- Optional query parameter "format" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	err = runtime.BindQueryParameter("form", true, false, "format", c.Request.URL.Query(), &params.Format)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter format: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "order" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
 	}
 
-	// ------------- Optional query parameter "q" -------------
+	siw.Handler.ExportTenantSettings(c, tenantid, params)
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
-		return
-	}
+// ImportTenantSettings operation middleware
+func (siw *ServerInterfaceWrapper) ImportTenantSettings(c *gin.Context) {
 
-	// ------------- Optional query parameter "detail" -------------
+	var err error
 
-	err = runtime.BindQueryParameter("form", true, false, "detail", c.Request.URL.Query(), &params.Detail)
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter detail: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "scope" -------------
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportTenantSettingsParams
 
-	err = runtime.BindQueryParameter("form", true, false, "scope", c.Request.URL.Query(), &params.Scope)
+	// ------------- Optional query parameter "confirm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "confirm", c.Request.URL.Query(), &params.Confirm)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scope: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter confirm: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2050,11 +8984,22 @@ func (siw *ServerInterfaceWrapper) ListUsers(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ListUsers(c, params)
+	siw.Handler.ImportTenantSettings(c, tenantid, params)
 }
 
-// AddUser operation middleware
-func (siw *ServerInterfaceWrapper) AddUser(c *gin.Context) {
+// ListTenantSubdomains operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantSubdomains(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -2063,20 +9008,20 @@ func (siw *ServerInterfaceWrapper) AddUser(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.AddUser(c)
+	siw.Handler.ListTenantSubdomains(c, tenantid)
 }
 
-// GetUserByEmail operation middleware
-func (siw *ServerInterfaceWrapper) GetUserByEmail(c *gin.Context) {
+// AddTenantSubdomainAlias operation middleware
+func (siw *ServerInterfaceWrapper) AddTenantSubdomainAlias(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "email" -------------
-	var email string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "email", c.Param("email"), &email, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter email: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2087,29 +9032,29 @@ func (siw *ServerInterfaceWrapper) GetUserByEmail(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetUserByEmail(c, email)
+	siw.Handler.AddTenantSubdomainAlias(c, tenantid)
 }
 
-// CheckUserExists operation middleware
-func (siw *ServerInterfaceWrapper) CheckUserExists(c *gin.Context) {
+// DeleteTenantSubdomainAlias operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantSubdomainAlias(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params CheckUserExistsParams
-
-	// ------------- Required query parameter "email" -------------
-
-	if paramValue := c.Query("email"); paramValue != "" {
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument email is required, but not found"), http.StatusBadRequest)
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "email", c.Request.URL.Query(), &params.Email)
+	// ------------- Path parameter "subdomain" -------------
+	var subdomain string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "subdomain", c.Param("subdomain"), &subdomain, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter email: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter subdomain: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2120,33 +9065,29 @@ func (siw *ServerInterfaceWrapper) CheckUserExists(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.CheckUserExists(c, params)
+	siw.Handler.DeleteTenantSubdomainAlias(c, tenantid, subdomain)
 }
 
-// ImportUsersFromAdmin operation middleware
-func (siw *ServerInterfaceWrapper) ImportUsersFromAdmin(c *gin.Context) {
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+// SetPrimaryTenantSubdomain operation middleware
+func (siw *ServerInterfaceWrapper) SetPrimaryTenantSubdomain(c *gin.Context) {
 
-	siw.Handler.ImportUsersFromAdmin(c)
-}
+	var err error
 
-// DeleteUser operation middleware
-func (siw *ServerInterfaceWrapper) DeleteUser(c *gin.Context) {
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	var err error
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "subdomain" -------------
+	var subdomain string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "subdomain", c.Param("subdomain"), &subdomain, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter subdomain: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2157,79 +9098,78 @@ func (siw *ServerInterfaceWrapper) DeleteUser(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteUser(c, userid)
+	siw.Handler.SetPrimaryTenantSubdomain(c, tenantid, subdomain)
 }
 
-// GetUserByID operation middleware
-func (siw *ServerInterfaceWrapper) GetUserByID(c *gin.Context) {
+// ListTenants operation middleware
+func (siw *ServerInterfaceWrapper) ListTenants(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantsParams
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	// ------------- Optional query parameter "pageSize" -------------
 
-	siw.Handler.GetUserByID(c, userid)
-}
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
 
-// UpdateUser operation middleware
-func (siw *ServerInterfaceWrapper) UpdateUser(c *gin.Context) {
+	// ------------- Optional query parameter "sortBy" -------------
 
-	var err error
+	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Optional query parameter "order" -------------
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	// ------------- Optional query parameter "q" -------------
 
-	siw.Handler.UpdateUser(c, userid)
-}
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
 
-// GetUserFeatureLicenses operation middleware
-func (siw *ServerInterfaceWrapper) GetUserFeatureLicenses(c *gin.Context) {
+	// ------------- Optional query parameter "reseller_id" -------------
 
-	var err error
+	err = runtime.BindQueryParameter("form", true, false, "reseller_id", c.Request.URL.Query(), &params.ResellerId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter reseller_id: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Optional query parameter "status" -------------
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetUserFeatureLicensesParams
-
-	// ------------- Optional query parameter "tenant_id" -------------
+	// ------------- Optional query parameter "global" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "tenant_id", c.Request.URL.Query(), &params.TenantId)
+	err = runtime.BindQueryParameter("form", true, false, "global", c.Request.URL.Query(), &params.Global)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenant_id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter global: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2240,31 +9180,33 @@ func (siw *ServerInterfaceWrapper) GetUserFeatureLicenses(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetUserFeatureLicenses(c, userid, params)
+	siw.Handler.ListTenants(c, params)
 }
 
-// UpdateUserFeatureLicenses operation middleware
-func (siw *ServerInterfaceWrapper) UpdateUserFeatureLicenses(c *gin.Context) {
+// AddTenant operation middleware
+func (siw *ServerInterfaceWrapper) AddTenant(c *gin.Context) {
 
-	var err error
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	siw.Handler.AddTenant(c)
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
-		return
-	}
+// DeleteTenant operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenant(c *gin.Context) {
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params UpdateUserFeatureLicensesParams
+	var err error
 
-	// ------------- Optional query parameter "tenant_id" -------------
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindQueryParameter("form", true, false, "tenant_id", c.Request.URL.Query(), &params.TenantId)
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenant_id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2275,20 +9217,20 @@ func (siw *ServerInterfaceWrapper) UpdateUserFeatureLicenses(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateUserFeatureLicenses(c, userid, params)
+	siw.Handler.DeleteTenant(c, tenantid)
 }
 
-// AddUserMembership operation middleware
-func (siw *ServerInterfaceWrapper) AddUserMembership(c *gin.Context) {
+// GetTenantByID operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantByID(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2299,20 +9241,20 @@ func (siw *ServerInterfaceWrapper) AddUserMembership(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.AddUserMembership(c, userid)
+	siw.Handler.GetTenantByID(c, tenantid)
 }
 
-// ResetPasswordRequestByAdmin operation middleware
-func (siw *ServerInterfaceWrapper) ResetPasswordRequestByAdmin(c *gin.Context) {
+// UpdateTenant operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTenant(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2323,20 +9265,20 @@ func (siw *ServerInterfaceWrapper) ResetPasswordRequestByAdmin(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ResetPasswordRequestByAdmin(c, userid)
+	siw.Handler.UpdateTenant(c, tenantid)
 }
 
-// ReactivateUser operation middleware
-func (siw *ServerInterfaceWrapper) ReactivateUser(c *gin.Context) {
+// CloneTenant operation middleware
+func (siw *ServerInterfaceWrapper) CloneTenant(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2347,20 +9289,20 @@ func (siw *ServerInterfaceWrapper) ReactivateUser(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ReactivateUser(c, userid)
+	siw.Handler.CloneTenant(c, tenantid)
 }
 
-// RemoveUserFromTenant operation middleware
-func (siw *ServerInterfaceWrapper) RemoveUserFromTenant(c *gin.Context) {
+// StartTenantDataExport operation middleware
+func (siw *ServerInterfaceWrapper) StartTenantDataExport(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2371,29 +9313,29 @@ func (siw *ServerInterfaceWrapper) RemoveUserFromTenant(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.RemoveUserFromTenant(c, userid)
+	siw.Handler.StartTenantDataExport(c, tenantid)
 }
 
-// AssignRole operation middleware
-func (siw *ServerInterfaceWrapper) AssignRole(c *gin.Context) {
+// GetTenantDataExport operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantDataExport(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Path parameter "role" -------------
-	var role Role
+	// ------------- Path parameter "jobid" -------------
+	var jobid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "role", c.Param("role"), &role, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "jobid", c.Param("jobid"), &jobid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter role: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2404,29 +9346,29 @@ func (siw *ServerInterfaceWrapper) AssignRole(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.AssignRole(c, userid, role)
+	siw.Handler.GetTenantDataExport(c, tenantid, jobid)
 }
 
-// UnassignRole operation middleware
-func (siw *ServerInterfaceWrapper) UnassignRole(c *gin.Context) {
+// DownloadTenantDataExport operation middleware
+func (siw *ServerInterfaceWrapper) DownloadTenantDataExport(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Path parameter "role" -------------
-	var role Role
+	// ------------- Path parameter "jobid" -------------
+	var jobid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "role", c.Param("role"), &role, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "jobid", c.Param("jobid"), &jobid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter role: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter jobid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2437,20 +9379,20 @@ func (siw *ServerInterfaceWrapper) UnassignRole(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UnassignRole(c, userid, role)
+	siw.Handler.DownloadTenantDataExport(c, tenantid, jobid)
 }
 
-// UpdateUserStatus operation middleware
-func (siw *ServerInterfaceWrapper) UpdateUserStatus(c *gin.Context) {
+// ListTenantFeatureFlags operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantFeatureFlags(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2461,57 +9403,29 @@ func (siw *ServerInterfaceWrapper) UpdateUserStatus(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateUserStatus(c, userid)
-}
-
-// IdentifyUser operation middleware
-func (siw *ServerInterfaceWrapper) IdentifyUser(c *gin.Context) {
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
-
-	siw.Handler.IdentifyUser(c)
+	siw.Handler.ListTenantFeatureFlags(c, tenantid)
 }
 
-// HandleRecovery operation middleware
-func (siw *ServerInterfaceWrapper) HandleRecovery(c *gin.Context) {
+// DeleteTenantFeatureFlagOverride operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantFeatureFlagOverride(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params HandleRecoveryParams
-
-	// ------------- Required query parameter "flow" -------------
-
-	if paramValue := c.Query("flow"); paramValue != "" {
-
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument flow is required, but not found"), http.StatusBadRequest)
-		return
-	}
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
 
-	err = runtime.BindQueryParameter("form", true, true, "flow", c.Request.URL.Query(), &params.Flow)
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter flow: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Required query parameter "token" -------------
-
-	if paramValue := c.Query("token"); paramValue != "" {
-
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument token is required, but not found"), http.StatusBadRequest)
-		return
-	}
+	// ------------- Path parameter "key" -------------
+	var key string
 
-	err = runtime.BindQueryParameter("form", true, true, "token", c.Request.URL.Query(), &params.Token)
+	err = runtime.BindStyledParameterWithOptions("simple", "key", c.Param("key"), &key, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter token: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2522,24 +9436,31 @@ func (siw *ServerInterfaceWrapper) HandleRecovery(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.HandleRecovery(c, params)
+	siw.Handler.DeleteTenantFeatureFlagOverride(c, tenantid, key)
 }
 
-// GetHealthCheck operation middleware
-func (siw *ServerInterfaceWrapper) GetHealthCheck(c *gin.Context) {
+// SetTenantFeatureFlagOverride operation middleware
+func (siw *ServerInterfaceWrapper) SetTenantFeatureFlagOverride(c *gin.Context) {
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
 	}
 
-	siw.Handler.GetHealthCheck(c)
-}
+	// ------------- Path parameter "key" -------------
+	var key string
 
-// ResetPasswordRequest operation middleware
-func (siw *ServerInterfaceWrapper) ResetPasswordRequest(c *gin.Context) {
+	err = runtime.BindStyledParameterWithOptions("simple", "key", c.Param("key"), &key, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter key: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -2548,24 +9469,22 @@ func (siw *ServerInterfaceWrapper) ResetPasswordRequest(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ResetPasswordRequest(c)
+	siw.Handler.SetTenantFeatureFlagOverride(c, tenantid, key)
 }
 
-// Signup operation middleware
-func (siw *ServerInterfaceWrapper) Signup(c *gin.Context) {
+// GetTenantFileEncryption operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantFileEncryption(c *gin.Context) {
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
+	var err error
 
-	siw.Handler.Signup(c)
-}
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
 
-// GetPublicTenant operation middleware
-func (siw *ServerInterfaceWrapper) GetPublicTenant(c *gin.Context) {
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -2574,36 +9493,21 @@ func (siw *ServerInterfaceWrapper) GetPublicTenant(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetPublicTenant(c)
+	siw.Handler.GetTenantFileEncryption(c, tenantid)
 }
 
-// GetTenantBackground operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantBackground(c *gin.Context) {
+// EnableTenantFileEncryption operation middleware
+func (siw *ServerInterfaceWrapper) EnableTenantFileEncryption(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTenantBackgroundParams
-
-	headers := c.Request.Header
-
-	// ------------- Optional header parameter "If-None-Match" -------------
-	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
-		var IfNoneMatch string
-		n := len(valueList)
-		if n != 1 {
-			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
-			return
-		}
-
-		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
-		if err != nil {
-			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
-			return
-		}
-
-		params.IfNoneMatch = &IfNoneMatch
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
 
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2613,36 +9517,21 @@ func (siw *ServerInterfaceWrapper) GetTenantBackground(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantBackground(c, params)
+	siw.Handler.EnableTenantFileEncryption(c, tenantid)
 }
 
-// GetTenantBackgroundMobile operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantBackgroundMobile(c *gin.Context) {
+// RotateTenantFileKey operation middleware
+func (siw *ServerInterfaceWrapper) RotateTenantFileKey(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTenantBackgroundMobileParams
-
-	headers := c.Request.Header
-
-	// ------------- Optional header parameter "If-None-Match" -------------
-	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
-		var IfNoneMatch string
-		n := len(valueList)
-		if n != 1 {
-			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
-			return
-		}
-
-		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
-		if err != nil {
-			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
-			return
-		}
-
-		params.IfNoneMatch = &IfNoneMatch
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
 
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2652,36 +9541,45 @@ func (siw *ServerInterfaceWrapper) GetTenantBackgroundMobile(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantBackgroundMobile(c, params)
+	siw.Handler.RotateTenantFileKey(c, tenantid)
 }
 
-// GetTenantLogo operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantLogo(c *gin.Context) {
+// CancelTenantPurge operation middleware
+func (siw *ServerInterfaceWrapper) CancelTenantPurge(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTenantLogoParams
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	headers := c.Request.Header
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
 
-	// ------------- Optional header parameter "If-None-Match" -------------
-	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
-		var IfNoneMatch string
-		n := len(valueList)
-		if n != 1 {
-			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
 			return
 		}
+	}
 
-		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
-		if err != nil {
-			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
-			return
-		}
+	siw.Handler.CancelTenantPurge(c, tenantid)
+}
 
-		params.IfNoneMatch = &IfNoneMatch
+// GetTenantPurge operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantPurge(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2691,45 +9589,32 @@ func (siw *ServerInterfaceWrapper) GetTenantLogo(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantLogo(c, params)
+	siw.Handler.GetTenantPurge(c, tenantid)
 }
 
-// GetProfilePicture operation middleware
-func (siw *ServerInterfaceWrapper) GetProfilePicture(c *gin.Context) {
+// ScheduleTenantPurge operation middleware
+func (siw *ServerInterfaceWrapper) ScheduleTenantPurge(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "userid" -------------
-	var userid string
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params GetProfilePictureParams
-
-	headers := c.Request.Header
-
-	// ------------- Optional header parameter "If-None-Match" -------------
-	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
-		var IfNoneMatch string
-		n := len(valueList)
-		if n != 1 {
-			siw.ErrorHandler(c, fmt.Errorf("Expected one value for If-None-Match, got %d", n), http.StatusBadRequest)
-			return
-		}
-
-		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
-		if err != nil {
-			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter If-None-Match: %w", err), http.StatusBadRequest)
-			return
-		}
+	var params ScheduleTenantPurgeParams
 
-		params.IfNoneMatch = &IfNoneMatch
+	// ------------- Optional query parameter "dryRun" -------------
 
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
+		return
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2739,11 +9624,22 @@ func (siw *ServerInterfaceWrapper) GetProfilePicture(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetProfilePicture(c, userid, params)
+	siw.Handler.ScheduleTenantPurge(c, tenantid, params)
 }
 
-// VerifyEmail operation middleware
-func (siw *ServerInterfaceWrapper) VerifyEmail(c *gin.Context) {
+// GetTenantQuotas operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantQuotas(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -2752,62 +9648,44 @@ func (siw *ServerInterfaceWrapper) VerifyEmail(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.VerifyEmail(c)
+	siw.Handler.GetTenantQuotas(c, tenantid)
 }
 
-// ListGlobalConfigs operation middleware
-func (siw *ServerInterfaceWrapper) ListGlobalConfigs(c *gin.Context) {
+// SetTenantQuotas operation middleware
+func (siw *ServerInterfaceWrapper) SetTenantQuotas(c *gin.Context) {
 
 	var err error
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ListGlobalConfigsParams
-
-	// ------------- Optional query parameter "page" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	// ------------- Optional query parameter "pageSize" -------------
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid string
 
-	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "sortBy" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
 	}
 
-	// ------------- Optional query parameter "order" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
-		return
-	}
+	siw.Handler.SetTenantQuotas(c, tenantid)
+}
 
-	// ------------- Optional query parameter "q" -------------
+// DeleteTenantSandbox operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTenantSandbox(c *gin.Context) {
 
-	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
-		return
-	}
+	var err error
 
-	// ------------- Optional query parameter "detail" -------------
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindQueryParameter("form", true, false, "detail", c.Request.URL.Query(), &params.Detail)
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter detail: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2818,11 +9696,22 @@ func (siw *ServerInterfaceWrapper) ListGlobalConfigs(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ListGlobalConfigs(c, params)
+	siw.Handler.DeleteTenantSandbox(c, tenantid)
 }
 
-// AddGlobalConfig operation middleware
-func (siw *ServerInterfaceWrapper) AddGlobalConfig(c *gin.Context) {
+// GetTenantSandbox operation middleware
+func (siw *ServerInterfaceWrapper) GetTenantSandbox(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -2831,20 +9720,20 @@ func (siw *ServerInterfaceWrapper) AddGlobalConfig(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.AddGlobalConfig(c)
+	siw.Handler.GetTenantSandbox(c, tenantid)
 }
 
-// DeleteGlobalConfig operation middleware
-func (siw *ServerInterfaceWrapper) DeleteGlobalConfig(c *gin.Context) {
+// SetTenantSandbox operation middleware
+func (siw *ServerInterfaceWrapper) SetTenantSandbox(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2855,20 +9744,31 @@ func (siw *ServerInterfaceWrapper) DeleteGlobalConfig(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteGlobalConfig(c, id)
+	siw.Handler.SetTenantSandbox(c, tenantid)
 }
 
-// GetGlobalConfigByID operation middleware
-func (siw *ServerInterfaceWrapper) GetGlobalConfigByID(c *gin.Context) {
+// ResetTenantSandbox operation middleware
+func (siw *ServerInterfaceWrapper) ResetTenantSandbox(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ResetTenantSandboxParams
+
+	// ------------- Optional query parameter "confirm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "confirm", c.Request.URL.Query(), &params.Confirm)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter confirm: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2879,20 +9779,20 @@ func (siw *ServerInterfaceWrapper) GetGlobalConfigByID(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetGlobalConfigByID(c, id)
+	siw.Handler.ResetTenantSandbox(c, tenantid, params)
 }
 
-// UpdateGlobalConfig operation middleware
-func (siw *ServerInterfaceWrapper) UpdateGlobalConfig(c *gin.Context) {
+// SetTenantStatus operation middleware
+func (siw *ServerInterfaceWrapper) SetTenantStatus(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -2903,16 +9803,16 @@ func (siw *ServerInterfaceWrapper) UpdateGlobalConfig(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateGlobalConfig(c, id)
+	siw.Handler.SetTenantStatus(c, tenantid)
 }
 
-// GetTenantFeatureLicenses operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantFeatureLicenses(c *gin.Context) {
+// DeleteTokenPolicy operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTokenPolicy(c *gin.Context) {
 
 	var err error
 
 	// ------------- Path parameter "tenantid" -------------
-	var tenantid openapi_types.UUID
+	var tenantid string
 
 	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
@@ -2927,16 +9827,16 @@ func (siw *ServerInterfaceWrapper) GetTenantFeatureLicenses(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantFeatureLicenses(c, tenantid)
+	siw.Handler.DeleteTokenPolicy(c, tenantid)
 }
 
-// UpdateTenantFeatureLicenses operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTenantFeatureLicenses(c *gin.Context) {
+// GetTokenPolicy operation middleware
+func (siw *ServerInterfaceWrapper) GetTokenPolicy(c *gin.Context) {
 
 	var err error
 
 	// ------------- Path parameter "tenantid" -------------
-	var tenantid openapi_types.UUID
+	var tenantid string
 
 	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
@@ -2951,16 +9851,16 @@ func (siw *ServerInterfaceWrapper) UpdateTenantFeatureLicenses(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateTenantFeatureLicenses(c, tenantid)
+	siw.Handler.GetTokenPolicy(c, tenantid)
 }
 
-// GetTenantFeatures operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantFeatures(c *gin.Context) {
+// SetTokenPolicy operation middleware
+func (siw *ServerInterfaceWrapper) SetTokenPolicy(c *gin.Context) {
 
 	var err error
 
 	// ------------- Path parameter "tenantid" -------------
-	var tenantid openapi_types.UUID
+	var tenantid string
 
 	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
@@ -2975,16 +9875,16 @@ func (siw *ServerInterfaceWrapper) GetTenantFeatures(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetTenantFeatures(c, tenantid)
+	siw.Handler.SetTokenPolicy(c, tenantid)
 }
 
-// UpdateTenantFeatures operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTenantFeatures(c *gin.Context) {
+// ListTenantUsageRecommendations operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantUsageRecommendations(c *gin.Context) {
 
 	var err error
 
 	// ------------- Path parameter "tenantid" -------------
-	var tenantid openapi_types.UUID
+	var tenantid string
 
 	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
@@ -2999,16 +9899,25 @@ func (siw *ServerInterfaceWrapper) UpdateTenantFeatures(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateTenantFeatures(c, tenantid)
+	siw.Handler.ListTenantUsageRecommendations(c, tenantid)
 }
 
-// ListTenants operation middleware
-func (siw *ServerInterfaceWrapper) ListTenants(c *gin.Context) {
+// ListUsersFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) ListUsersFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ListTenantsParams
+	var params ListUsersFromSuperAdminParams
 
 	// ------------- Optional query parameter "page" -------------
 
@@ -3050,35 +9959,6 @@ func (siw *ServerInterfaceWrapper) ListTenants(c *gin.Context) {
 		return
 	}
 
-	// ------------- Optional query parameter "reseller_id" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "reseller_id", c.Request.URL.Query(), &params.ResellerId)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter reseller_id: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	// ------------- Optional query parameter "global" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "global", c.Request.URL.Query(), &params.Global)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter global: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
-
-	siw.Handler.ListTenants(c, params)
-}
-
-// AddTenant operation middleware
-func (siw *ServerInterfaceWrapper) AddTenant(c *gin.Context) {
-
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -3086,11 +9966,11 @@ func (siw *ServerInterfaceWrapper) AddTenant(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.AddTenant(c)
+	siw.Handler.ListUsersFromSuperAdmin(c, tenantid, params)
 }
 
-// DeleteTenant operation middleware
-func (siw *ServerInterfaceWrapper) DeleteTenant(c *gin.Context) {
+// AddUserFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) AddUserFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3110,11 +9990,11 @@ func (siw *ServerInterfaceWrapper) DeleteTenant(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteTenant(c, tenantid)
+	siw.Handler.AddUserFromSuperAdmin(c, tenantid)
 }
 
-// GetTenantByID operation middleware
-func (siw *ServerInterfaceWrapper) GetTenantByID(c *gin.Context) {
+// CheckUserExistsFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) CheckUserExistsFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3127,27 +10007,21 @@ func (siw *ServerInterfaceWrapper) GetTenantByID(c *gin.Context) {
 		return
 	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
-			return
-		}
-	}
-
-	siw.Handler.GetTenantByID(c, tenantid)
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CheckUserExistsFromSuperAdminParams
 
-// UpdateTenant operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTenant(c *gin.Context) {
+	// ------------- Required query parameter "email" -------------
 
-	var err error
+	if paramValue := c.Query("email"); paramValue != "" {
 
-	// ------------- Path parameter "tenantid" -------------
-	var tenantid openapi_types.UUID
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument email is required, but not found"), http.StatusBadRequest)
+		return
+	}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	err = runtime.BindQueryParameter("form", true, true, "email", c.Request.URL.Query(), &params.Email)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter email: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -3158,11 +10032,11 @@ func (siw *ServerInterfaceWrapper) UpdateTenant(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateTenant(c, tenantid)
+	siw.Handler.CheckUserExistsFromSuperAdmin(c, tenantid, params)
 }
 
-// ListUsersFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) ListUsersFromSuperAdmin(c *gin.Context) {
+// RebuildUserClaimsFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) RebuildUserClaimsFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3176,45 +10050,29 @@ func (siw *ServerInterfaceWrapper) ListUsersFromSuperAdmin(c *gin.Context) {
 	}
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ListUsersFromSuperAdminParams
-
-	// ------------- Optional query parameter "page" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	// ------------- Optional query parameter "pageSize" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
-	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
-		return
-	}
+	var params RebuildUserClaimsFromSuperAdminParams
 
-	// ------------- Optional query parameter "sortBy" -------------
+	// ------------- Optional query parameter "cursor" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "sortBy", c.Request.URL.Query(), &params.SortBy)
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sortBy: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "order" -------------
+	// ------------- Optional query parameter "limit" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	// ------------- Optional query parameter "q" -------------
+	// ------------- Optional query parameter "dryRun" -------------
 
-	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", c.Request.URL.Query(), &params.DryRun)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter dryRun: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -3225,11 +10083,11 @@ func (siw *ServerInterfaceWrapper) ListUsersFromSuperAdmin(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.ListUsersFromSuperAdmin(c, tenantid, params)
+	siw.Handler.RebuildUserClaimsFromSuperAdmin(c, tenantid, params)
 }
 
-// AddUserFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) AddUserFromSuperAdmin(c *gin.Context) {
+// DeleteUserFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) DeleteUserFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3242,6 +10100,34 @@ func (siw *ServerInterfaceWrapper) AddUserFromSuperAdmin(c *gin.Context) {
 		return
 	}
 
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteUserFromSuperAdminParams
+
+	// ------------- Optional query parameter "transferTo" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "transferTo", c.Request.URL.Query(), &params.TransferTo)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter transferTo: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "transferToTenantOwner" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "transferToTenantOwner", c.Request.URL.Query(), &params.TransferToTenantOwner)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter transferToTenantOwner: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -3249,11 +10135,11 @@ func (siw *ServerInterfaceWrapper) AddUserFromSuperAdmin(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.AddUserFromSuperAdmin(c, tenantid)
+	siw.Handler.DeleteUserFromSuperAdmin(c, tenantid, userid, params)
 }
 
-// CheckUserExistsFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) CheckUserExistsFromSuperAdmin(c *gin.Context) {
+// GetUserByIDFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) GetUserByIDFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3266,21 +10152,12 @@ func (siw *ServerInterfaceWrapper) CheckUserExistsFromSuperAdmin(c *gin.Context)
 		return
 	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params CheckUserExistsFromSuperAdminParams
-
-	// ------------- Required query parameter "email" -------------
-
-	if paramValue := c.Query("email"); paramValue != "" {
-
-	} else {
-		siw.ErrorHandler(c, fmt.Errorf("Query argument email is required, but not found"), http.StatusBadRequest)
-		return
-	}
+	// ------------- Path parameter "userid" -------------
+	var userid string
 
-	err = runtime.BindQueryParameter("form", true, true, "email", c.Request.URL.Query(), &params.Email)
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter email: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
 		return
 	}
 
@@ -3291,11 +10168,11 @@ func (siw *ServerInterfaceWrapper) CheckUserExistsFromSuperAdmin(c *gin.Context)
 		}
 	}
 
-	siw.Handler.CheckUserExistsFromSuperAdmin(c, tenantid, params)
+	siw.Handler.GetUserByIDFromSuperAdmin(c, tenantid, userid)
 }
 
-// DeleteUserFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) DeleteUserFromSuperAdmin(c *gin.Context) {
+// UpdateUserFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUserFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3324,11 +10201,11 @@ func (siw *ServerInterfaceWrapper) DeleteUserFromSuperAdmin(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.DeleteUserFromSuperAdmin(c, tenantid, userid)
+	siw.Handler.UpdateUserFromSuperAdmin(c, tenantid, userid)
 }
 
-// GetUserByIDFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) GetUserByIDFromSuperAdmin(c *gin.Context) {
+// HardDeleteUserFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) HardDeleteUserFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3350,6 +10227,25 @@ func (siw *ServerInterfaceWrapper) GetUserByIDFromSuperAdmin(c *gin.Context) {
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params HardDeleteUserFromSuperAdminParams
+
+	// ------------- Optional query parameter "transferTo" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "transferTo", c.Request.URL.Query(), &params.TransferTo)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter transferTo: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "transferToTenantOwner" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "transferToTenantOwner", c.Request.URL.Query(), &params.TransferToTenantOwner)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter transferToTenantOwner: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -3357,11 +10253,11 @@ func (siw *ServerInterfaceWrapper) GetUserByIDFromSuperAdmin(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetUserByIDFromSuperAdmin(c, tenantid, userid)
+	siw.Handler.HardDeleteUserFromSuperAdmin(c, tenantid, userid, params)
 }
 
-// UpdateUserFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) UpdateUserFromSuperAdmin(c *gin.Context) {
+// EndUserImpersonationFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) EndUserImpersonationFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3390,11 +10286,11 @@ func (siw *ServerInterfaceWrapper) UpdateUserFromSuperAdmin(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.UpdateUserFromSuperAdmin(c, tenantid, userid)
+	siw.Handler.EndUserImpersonationFromSuperAdmin(c, tenantid, userid)
 }
 
-// HardDeleteUserFromSuperAdmin operation middleware
-func (siw *ServerInterfaceWrapper) HardDeleteUserFromSuperAdmin(c *gin.Context) {
+// ImpersonateUserFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) ImpersonateUserFromSuperAdmin(c *gin.Context) {
 
 	var err error
 
@@ -3423,7 +10319,7 @@ func (siw *ServerInterfaceWrapper) HardDeleteUserFromSuperAdmin(c *gin.Context)
 		}
 	}
 
-	siw.Handler.HardDeleteUserFromSuperAdmin(c, tenantid, userid)
+	siw.Handler.ImpersonateUserFromSuperAdmin(c, tenantid, userid)
 }
 
 // AddUserMembershipFromSuperAdmin operation middleware
@@ -3675,6 +10571,191 @@ func (siw *ServerInterfaceWrapper) UpdateUserStatusFromSuperAdmin(c *gin.Context
 	siw.Handler.UpdateUserStatusFromSuperAdmin(c, tenantid, userid)
 }
 
+// GetUserTimelineFromSuperAdmin operation middleware
+func (siw *ServerInterfaceWrapper) GetUserTimelineFromSuperAdmin(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "tenantid" -------------
+	var tenantid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tenantid", c.Param("tenantid"), &tenantid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tenantid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "userid" -------------
+	var userid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userid", c.Param("userid"), &userid, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter userid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUserTimelineFromSuperAdminParams
+
+	// ------------- Optional query parameter "types" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "types", c.Request.URL.Query(), &params.Types)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter types: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetUserTimelineFromSuperAdmin(c, tenantid, userid, params)
+}
+
+// ListTokenPolicies operation middleware
+func (siw *ServerInterfaceWrapper) ListTokenPolicies(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTokenPolicies(c)
+}
+
+// ListTenantsUsage operation middleware
+func (siw *ServerInterfaceWrapper) ListTenantsUsage(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTenantsUsageParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTenantsUsage(c, params)
+}
+
+// CheckUserMembershipConsistency operation middleware
+func (siw *ServerInterfaceWrapper) CheckUserMembershipConsistency(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CheckUserMembershipConsistencyParams
+
+	// ------------- Optional query parameter "repair" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "repair", c.Request.URL.Query(), &params.Repair)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter repair: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CheckUserMembershipConsistency(c, params)
+}
+
+// CheckUserEmailEncryption operation middleware
+func (siw *ServerInterfaceWrapper) CheckUserEmailEncryption(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CheckUserEmailEncryptionParams
+
+	// ------------- Optional query parameter "rewrite" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rewrite", c.Request.URL.Query(), &params.Rewrite)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter rewrite: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CheckUserEmailEncryption(c, params)
+}
+
+// MergeUsers operation middleware
+func (siw *ServerInterfaceWrapper) MergeUsers(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params MergeUsersParams
+
+	// ------------- Optional query parameter "apply" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "apply", c.Request.URL.Query(), &params.Apply)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter apply: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.MergeUsers(c, params)
+}
+
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
 	BaseURL      string
@@ -3702,23 +10783,61 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.POST(options.BaseURL+"/admin-api/v1/api-tokens/revoke-all", wrapper.RevokeAllAPITokens)
 	router.GET(options.BaseURL+"/admin-api/v1/client-applications", wrapper.ListClientApplications)
 	router.POST(options.BaseURL+"/admin-api/v1/client-applications", wrapper.CreateClientApplication)
+	router.POST(options.BaseURL+"/admin-api/v1/client-applications/import", wrapper.ImportClientApplicationConfig)
 	router.DELETE(options.BaseURL+"/admin-api/v1/client-applications/:id", wrapper.DeleteClientApplication)
 	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id", wrapper.GetClientApplicationById)
 	router.PUT(options.BaseURL+"/admin-api/v1/client-applications/:id", wrapper.UpdateClientApplication)
+	router.PATCH(options.BaseURL+"/admin-api/v1/client-applications/:id/activate", wrapper.ActivateClientApplication)
+	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/config", wrapper.ExportClientApplicationConfig)
 	router.PATCH(options.BaseURL+"/admin-api/v1/client-applications/:id/deactivate", wrapper.DeactivateClientApplication)
+	router.DELETE(options.BaseURL+"/admin-api/v1/client-applications/:id/secret", wrapper.DeleteClientApplicationSecret)
+	router.POST(options.BaseURL+"/admin-api/v1/client-applications/:id/secret", wrapper.RotateClientApplicationSecret)
+	router.DELETE(options.BaseURL+"/admin-api/v1/client-applications/:id/service-account", wrapper.UnlinkClientApplicationServiceAccount)
+	router.PUT(options.BaseURL+"/admin-api/v1/client-applications/:id/service-account", wrapper.LinkClientApplicationServiceAccount)
+	router.DELETE(options.BaseURL+"/admin-api/v1/client-applications/:id/signing-key", wrapper.DeleteClientApplicationSigningKey)
+	router.POST(options.BaseURL+"/admin-api/v1/client-applications/:id/signing-key", wrapper.RotateClientApplicationSigningKey)
 	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens", wrapper.ListAPITokens)
 	router.POST(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens", wrapper.CreateAPIToken)
+	router.POST(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/revoke-all", wrapper.RevokeAllClientApplicationTokens)
 	router.DELETE(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId", wrapper.DeleteAPIToken)
 	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId", wrapper.GetAPITokenById)
+	router.PATCH(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId", wrapper.UpdateAPIToken)
 	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId/audit", wrapper.GetAPITokenAuditLogs)
+	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId/audit/export", wrapper.ExportAPITokenAuditLogs)
+	router.PUT(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId/ip-allowlist", wrapper.UpdateAPITokenIPAllowlist)
 	router.PATCH(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId/revoke", wrapper.RevokeAPIToken)
+	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/tokens/:tokenId/usage", wrapper.GetAPITokenUsage)
+	router.DELETE(options.BaseURL+"/admin-api/v1/client-applications/:id/webhook", wrapper.DeleteClientApplicationWebhook)
+	router.GET(options.BaseURL+"/admin-api/v1/client-applications/:id/webhook", wrapper.GetClientApplicationWebhook)
+	router.POST(options.BaseURL+"/admin-api/v1/client-applications/:id/webhook", wrapper.SetClientApplicationWebhook)
+	router.GET(options.BaseURL+"/admin-api/v1/scope-templates", wrapper.ListScopeTemplates)
+	router.POST(options.BaseURL+"/admin-api/v1/scope-templates", wrapper.CreateScopeTemplate)
+	router.DELETE(options.BaseURL+"/admin-api/v1/scope-templates/:id", wrapper.DeleteScopeTemplate)
+	router.GET(options.BaseURL+"/admin-api/v1/scope-templates/:id", wrapper.GetScopeTemplate)
+	router.PUT(options.BaseURL+"/admin-api/v1/scope-templates/:id", wrapper.UpdateScopeTemplate)
+	router.GET(options.BaseURL+"/admin-api/v1/scopes", wrapper.ListScopes)
+	router.GET(options.BaseURL+"/api/v1/announcements", wrapper.ListActiveAnnouncements)
 	router.GET(options.BaseURL+"/api/v1/configs/tenant-configs", wrapper.ListTenantConfigs)
 	router.POST(options.BaseURL+"/api/v1/configs/tenant-configs", wrapper.AddTenantConfig)
 	router.DELETE(options.BaseURL+"/api/v1/configs/tenant-configs/:id", wrapper.DeleteTenantConfig)
 	router.GET(options.BaseURL+"/api/v1/configs/tenant-configs/:id", wrapper.GetTenantConfigByID)
 	router.PUT(options.BaseURL+"/api/v1/configs/tenant-configs/:id", wrapper.UpdateTenantConfig)
+	router.GET(options.BaseURL+"/api/v1/delegations", wrapper.ListDelegations)
+	router.POST(options.BaseURL+"/api/v1/delegations", wrapper.CreateDelegation)
+	router.DELETE(options.BaseURL+"/api/v1/delegations/:id", wrapper.RevokeDelegation)
+	router.GET(options.BaseURL+"/api/v1/groups", wrapper.ListGroups)
+	router.POST(options.BaseURL+"/api/v1/groups", wrapper.CreateGroup)
+	router.DELETE(options.BaseURL+"/api/v1/groups/:id", wrapper.DeleteGroup)
+	router.GET(options.BaseURL+"/api/v1/groups/:id", wrapper.GetGroup)
+	router.PUT(options.BaseURL+"/api/v1/groups/:id", wrapper.UpdateGroup)
+	router.GET(options.BaseURL+"/api/v1/groups/:id/members", wrapper.ListGroupMembers)
+	router.DELETE(options.BaseURL+"/api/v1/groups/:id/members/:userid", wrapper.RemoveGroupMember)
+	router.PUT(options.BaseURL+"/api/v1/groups/:id/members/:userid", wrapper.AddGroupMember)
+	router.GET(options.BaseURL+"/api/v1/imports/:id/errors.csv", wrapper.DownloadImportErrors)
+	router.GET(options.BaseURL+"/api/v1/jobs/:id/events", wrapper.ListJobEvents)
 	router.POST(options.BaseURL+"/api/v1/me", wrapper.CreateMeUser)
 	router.POST(options.BaseURL+"/api/v1/me/email-verification/resend", wrapper.ResendEmailVerification)
 	router.GET(options.BaseURL+"/api/v1/me/email-verification/status", wrapper.GetMyEmailVerificationStatus)
@@ -3730,11 +10849,101 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	router.GET(options.BaseURL+"/api/v1/mfa/status", wrapper.GetMFAStatus)
 	router.DELETE(options.BaseURL+"/api/v1/mfa/webauthn", wrapper.DisableWebAuthn)
 	router.GET(options.BaseURL+"/api/v1/reseller/tenants", wrapper.ListResellerTenants)
+	router.GET(options.BaseURL+"/api/v1/roles", wrapper.ListCustomRoles)
+	router.POST(options.BaseURL+"/api/v1/roles", wrapper.CreateCustomRole)
+	router.GET(options.BaseURL+"/api/v1/roles/permissions", wrapper.GetPermissionMatrix)
+	router.DELETE(options.BaseURL+"/api/v1/roles/:id", wrapper.DeleteCustomRole)
+	router.GET(options.BaseURL+"/api/v1/roles/:id", wrapper.GetCustomRole)
+	router.PUT(options.BaseURL+"/api/v1/roles/:id", wrapper.UpdateCustomRole)
+	router.GET(options.BaseURL+"/api/v1/roles/:id/members", wrapper.ListCustomRoleMembers)
+	router.DELETE(options.BaseURL+"/api/v1/roles/:id/members/:userid", wrapper.RemoveCustomRoleMember)
+	router.PUT(options.BaseURL+"/api/v1/roles/:id/members/:userid", wrapper.AddCustomRoleMember)
+	router.GET(options.BaseURL+"/api/v1/tenant/announcements", wrapper.ListTenantAnnouncements)
+	router.POST(options.BaseURL+"/api/v1/tenant/announcements", wrapper.CreateTenantAnnouncement)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/announcements/:id", wrapper.DeleteTenantAnnouncement)
+	router.GET(options.BaseURL+"/api/v1/tenant/announcements/:id", wrapper.GetTenantAnnouncement)
+	router.PUT(options.BaseURL+"/api/v1/tenant/announcements/:id", wrapper.UpdateTenantAnnouncement)
+	router.POST(options.BaseURL+"/api/v1/tenant/api-tokens/revoke-all", wrapper.RevokeAllTenantAPITokens)
+	router.GET(options.BaseURL+"/api/v1/tenant/auth-failures", wrapper.GetAuthFailureSummary)
+	router.PUT(options.BaseURL+"/api/v1/tenant/branding", wrapper.UpdateTenantBranding)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications", wrapper.ListTenantClientApplications)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications", wrapper.CreateTenantClientApplication)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications/import", wrapper.ImportTenantClientApplicationConfig)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/client-applications/:id", wrapper.DeleteTenantClientApplication)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id", wrapper.GetTenantClientApplicationById)
+	router.PUT(options.BaseURL+"/api/v1/tenant/client-applications/:id", wrapper.UpdateTenantClientApplication)
+	router.PATCH(options.BaseURL+"/api/v1/tenant/client-applications/:id/activate", wrapper.ActivateTenantClientApplication)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/config", wrapper.ExportTenantClientApplicationConfig)
+	router.PATCH(options.BaseURL+"/api/v1/tenant/client-applications/:id/deactivate", wrapper.DeactivateTenantClientApplication)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/client-applications/:id/secret", wrapper.DeleteTenantClientApplicationSecret)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications/:id/secret", wrapper.RotateTenantClientApplicationSecret)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/client-applications/:id/service-account", wrapper.UnlinkTenantClientApplicationServiceAccount)
+	router.PUT(options.BaseURL+"/api/v1/tenant/client-applications/:id/service-account", wrapper.LinkTenantClientApplicationServiceAccount)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/client-applications/:id/signing-key", wrapper.DeleteTenantClientApplicationSigningKey)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications/:id/signing-key", wrapper.RotateTenantClientApplicationSigningKey)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens", wrapper.ListTenantAPITokens)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens", wrapper.CreateTenantAPIToken)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/revoke-all", wrapper.RevokeAllTenantClientApplicationTokens)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId", wrapper.DeleteTenantAPIToken)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId", wrapper.GetTenantAPITokenById)
+	router.PATCH(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId", wrapper.UpdateTenantAPIToken)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId/audit", wrapper.GetTenantAPITokenAuditLogs)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId/audit/export", wrapper.ExportTenantAPITokenAuditLogs)
+	router.PUT(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId/ip-allowlist", wrapper.UpdateTenantAPITokenIPAllowlist)
+	router.PATCH(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId/revoke", wrapper.RevokeTenantAPIToken)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/tokens/:tokenId/usage", wrapper.GetTenantAPITokenUsage)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/client-applications/:id/webhook", wrapper.DeleteTenantClientApplicationWebhook)
+	router.GET(options.BaseURL+"/api/v1/tenant/client-applications/:id/webhook", wrapper.GetTenantClientApplicationWebhook)
+	router.POST(options.BaseURL+"/api/v1/tenant/client-applications/:id/webhook", wrapper.SetTenantClientApplicationWebhook)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/directory-sync", wrapper.DeleteDirectoryConnection)
+	router.GET(options.BaseURL+"/api/v1/tenant/directory-sync", wrapper.GetDirectoryConnection)
+	router.PUT(options.BaseURL+"/api/v1/tenant/directory-sync", wrapper.SaveDirectoryConnection)
+	router.GET(options.BaseURL+"/api/v1/tenant/directory-sync/conflicts", wrapper.ListDirectorySyncConflicts)
+	router.POST(options.BaseURL+"/api/v1/tenant/directory-sync/run", wrapper.RunDirectorySync)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/email-provider", wrapper.DeleteTenantEmailProvider)
+	router.GET(options.BaseURL+"/api/v1/tenant/email-provider", wrapper.GetTenantEmailProvider)
+	router.PUT(options.BaseURL+"/api/v1/tenant/email-provider", wrapper.SaveTenantEmailProvider)
+	router.POST(options.BaseURL+"/api/v1/tenant/email-provider/test", wrapper.TestTenantEmailProvider)
+	router.GET(options.BaseURL+"/api/v1/tenant/email-templates", wrapper.ListEmailTemplates)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/email-templates/:event/:locale", wrapper.DeleteEmailTemplate)
+	router.GET(options.BaseURL+"/api/v1/tenant/email-templates/:event/:locale", wrapper.GetEmailTemplate)
+	router.PUT(options.BaseURL+"/api/v1/tenant/email-templates/:event/:locale", wrapper.SaveEmailTemplate)
+	router.GET(options.BaseURL+"/api/v1/tenant/execution-webhooks", wrapper.ListExecutionWebhooks)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/execution-webhooks/:sourceType/:sourceId", wrapper.DeleteExecutionWebhook)
+	router.GET(options.BaseURL+"/api/v1/tenant/execution-webhooks/:sourceType/:sourceId", wrapper.GetExecutionWebhook)
+	router.PUT(options.BaseURL+"/api/v1/tenant/execution-webhooks/:sourceType/:sourceId", wrapper.SetExecutionWebhook)
+	router.GET(options.BaseURL+"/api/v1/tenant/export-schedules", wrapper.ListTenantExportSchedules)
+	router.POST(options.BaseURL+"/api/v1/tenant/export-schedules", wrapper.SaveTenantExportSchedule)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/export-schedules/:id", wrapper.DeleteTenantExportSchedule)
+	router.GET(options.BaseURL+"/api/v1/tenant/exports", wrapper.ListTenantExports)
+	router.POST(options.BaseURL+"/api/v1/tenant/exports", wrapper.CreateTenantExport)
+	router.GET(options.BaseURL+"/api/v1/tenant/exports/:id", wrapper.GetTenantExport)
+	router.GET(options.BaseURL+"/api/v1/tenant/exports/:id/download", wrapper.DownloadTenantExport)
+	router.GET(options.BaseURL+"/api/v1/tenant/feature-flags", wrapper.GetTenantFeatureFlags)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/llm-consent", wrapper.DeleteLLMConsentPolicy)
+	router.GET(options.BaseURL+"/api/v1/tenant/llm-consent", wrapper.GetLLMConsentPolicy)
+	router.PUT(options.BaseURL+"/api/v1/tenant/llm-consent", wrapper.SetLLMConsentPolicy)
+	router.GET(options.BaseURL+"/api/v1/tenant/lockouts", wrapper.ListTenantLockouts)
 	router.POST(options.BaseURL+"/api/v1/tenant/pictures/background", wrapper.UploadTenantBackground)
 	router.POST(options.BaseURL+"/api/v1/tenant/pictures/background-mobile", wrapper.UploadTenantBackgroundMobile)
 	router.POST(options.BaseURL+"/api/v1/tenant/pictures/logo", wrapper.UploadTenantLogo)
 	router.GET(options.BaseURL+"/api/v1/tenant/profile", wrapper.GetTenantProfile)
 	router.PUT(options.BaseURL+"/api/v1/tenant/profile", wrapper.UpdateTenantProfile)
+	router.GET(options.BaseURL+"/api/v1/tenant/quotas", wrapper.GetTenantQuotaUsage)
+	router.GET(options.BaseURL+"/api/v1/tenant/scope-templates", wrapper.ListTenantScopeTemplates)
+	router.POST(options.BaseURL+"/api/v1/tenant/scope-templates", wrapper.CreateTenantScopeTemplate)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/scope-templates/:id", wrapper.DeleteTenantScopeTemplate)
+	router.GET(options.BaseURL+"/api/v1/tenant/scope-templates/:id", wrapper.GetTenantScopeTemplate)
+	router.PUT(options.BaseURL+"/api/v1/tenant/scope-templates/:id", wrapper.UpdateTenantScopeTemplate)
+	router.GET(options.BaseURL+"/api/v1/tenant/scopes", wrapper.ListTenantScopes)
+	router.GET(options.BaseURL+"/api/v1/tenant/settings", wrapper.GetTenantSettingValues)
+	router.PUT(options.BaseURL+"/api/v1/tenant/settings", wrapper.UpdateTenantSettingValues)
+	router.GET(options.BaseURL+"/api/v1/tenant/sla/overdue", wrapper.ListSLAOverdueItems)
+	router.GET(options.BaseURL+"/api/v1/tenant/usage", wrapper.GetTenantUsage)
+	router.GET(options.BaseURL+"/api/v1/tenant/usage/recommendations", wrapper.GetTenantUsageRecommendations)
+	router.GET(options.BaseURL+"/api/v1/tenant/user-attributes", wrapper.ListUserAttributes)
+	router.DELETE(options.BaseURL+"/api/v1/tenant/user-attributes/:key", wrapper.DeleteUserAttribute)
+	router.PUT(options.BaseURL+"/api/v1/tenant/user-attributes/:key", wrapper.SaveUserAttribute)
 	router.GET(options.BaseURL+"/api/v1/translations", wrapper.ListTranslations)
 	router.POST(options.BaseURL+"/api/v1/translations", wrapper.CreateTranslation)
 	router.GET(options.BaseURL+"/api/v1/translations/search", wrapper.GetTranslation)
@@ -3743,53 +10952,142 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	router.PUT(options.BaseURL+"/api/v1/translations/:id", wrapper.UpdateTranslation)
 	router.GET(options.BaseURL+"/api/v1/users", wrapper.ListUsers)
 	router.POST(options.BaseURL+"/api/v1/users", wrapper.AddUser)
+	router.POST(options.BaseURL+"/api/v1/users/bulk", wrapper.BulkUpdateUsers)
 	router.GET(options.BaseURL+"/api/v1/users/by-email/:email", wrapper.GetUserByEmail)
 	router.GET(options.BaseURL+"/api/v1/users/check", wrapper.CheckUserExists)
+	router.GET(options.BaseURL+"/api/v1/users/export", wrapper.ExportUsers)
 	router.POST(options.BaseURL+"/api/v1/users/import", wrapper.ImportUsersFromAdmin)
+	router.GET(options.BaseURL+"/api/v1/users/import-jobs/:id", wrapper.GetUserImportJob)
+	router.GET(options.BaseURL+"/api/v1/users/invitations", wrapper.ListUserInvitations)
+	router.POST(options.BaseURL+"/api/v1/users/invitations", wrapper.CreateUserInvitation)
+	router.DELETE(options.BaseURL+"/api/v1/users/invitations/:invitationId", wrapper.RevokeUserInvitation)
+	router.POST(options.BaseURL+"/api/v1/users/invitations/:invitationId/resend", wrapper.ResendUserInvitation)
+	router.DELETE(options.BaseURL+"/api/v1/users/me", wrapper.DeleteMyAccount)
+	router.POST(options.BaseURL+"/api/v1/users/me/email-change", wrapper.RequestEmailChange)
+	router.GET(options.BaseURL+"/api/v1/users/me/mfa", wrapper.GetMyTOTPStatus)
+	router.POST(options.BaseURL+"/api/v1/users/me/mfa/disable", wrapper.DisableMyTOTP)
+	router.POST(options.BaseURL+"/api/v1/users/me/mfa/enroll", wrapper.EnrollMyTOTP)
+	router.POST(options.BaseURL+"/api/v1/users/me/mfa/recovery-codes", wrapper.RegenerateMyTOTPRecoveryCodes)
+	router.POST(options.BaseURL+"/api/v1/users/me/mfa/verify", wrapper.VerifyMyTOTP)
+	router.GET(options.BaseURL+"/api/v1/users/me/onboarding", wrapper.GetMyOnboarding)
+	router.PATCH(options.BaseURL+"/api/v1/users/me/onboarding", wrapper.UpdateMyOnboarding)
+	router.POST(options.BaseURL+"/api/v1/users/me/phone-verification", wrapper.RequestPhoneVerification)
+	router.POST(options.BaseURL+"/api/v1/users/me/phone-verification/confirm", wrapper.ConfirmPhoneVerification)
+	router.GET(options.BaseURL+"/api/v1/users/me/sessions", wrapper.ListMySessions)
+	router.DELETE(options.BaseURL+"/api/v1/users/me/sessions/:sessionid", wrapper.RevokeMySession)
+	router.GET(options.BaseURL+"/api/v1/users/verification-report", wrapper.GetUserVerificationReport)
 	router.DELETE(options.BaseURL+"/api/v1/users/:userid", wrapper.DeleteUser)
 	router.GET(options.BaseURL+"/api/v1/users/:userid", wrapper.GetUserByID)
 	router.PUT(options.BaseURL+"/api/v1/users/:userid", wrapper.UpdateUser)
+	router.GET(options.BaseURL+"/api/v1/users/:userid/audit-logs", wrapper.GetUserAuditLogs)
 	router.GET(options.BaseURL+"/api/v1/users/:userid/feature-licenses", wrapper.GetUserFeatureLicenses)
 	router.PUT(options.BaseURL+"/api/v1/users/:userid/feature-licenses", wrapper.UpdateUserFeatureLicenses)
+	router.DELETE(options.BaseURL+"/api/v1/users/:userid/labels/:label", wrapper.RemoveUserLabel)
+	router.PUT(options.BaseURL+"/api/v1/users/:userid/labels/:label", wrapper.AddUserLabel)
 	router.POST(options.BaseURL+"/api/v1/users/:userid/membership", wrapper.AddUserMembership)
 	router.POST(options.BaseURL+"/api/v1/users/:userid/password-reset-request", wrapper.ResetPasswordRequestByAdmin)
 	router.POST(options.BaseURL+"/api/v1/users/:userid/reactivate", wrapper.ReactivateUser)
 	router.DELETE(options.BaseURL+"/api/v1/users/:userid/remove-from-tenant", wrapper.RemoveUserFromTenant)
 	router.POST(options.BaseURL+"/api/v1/users/:userid/roles/:role/assign", wrapper.AssignRole)
 	router.POST(options.BaseURL+"/api/v1/users/:userid/roles/:role/unassign", wrapper.UnassignRole)
+	router.DELETE(options.BaseURL+"/api/v1/users/:userid/sessions", wrapper.RevokeUserSessions)
 	router.POST(options.BaseURL+"/api/v1/users/:userid/status", wrapper.UpdateUserStatus)
+	router.POST(options.BaseURL+"/api/v1/users/:userid/unlock", wrapper.UnlockUser)
+	router.POST(options.BaseURL+"/public-api/v1/account-deletion/cancel", wrapper.CancelAccountDeletion)
 	router.POST(options.BaseURL+"/public-api/v1/auth/identify", wrapper.IdentifyUser)
 	router.GET(options.BaseURL+"/public-api/v1/auth/recovery", wrapper.HandleRecovery)
+	router.POST(options.BaseURL+"/public-api/v1/email-change/confirm", wrapper.ConfirmEmailChange)
+	router.POST(options.BaseURL+"/public-api/v1/email-change/revert", wrapper.RevertEmailChange)
 	router.GET(options.BaseURL+"/public-api/v1/health", wrapper.GetHealthCheck)
+	router.POST(options.BaseURL+"/public-api/v1/invitations/accept", wrapper.AcceptUserInvitation)
+	router.POST(options.BaseURL+"/public-api/v1/invitations/lookup", wrapper.LookupUserInvitation)
+	router.POST(options.BaseURL+"/public-api/v1/oauth/token", wrapper.IssueOAuthToken)
 	router.POST(options.BaseURL+"/public-api/v1/password-reset-request", wrapper.ResetPasswordRequest)
 	router.POST(options.BaseURL+"/public-api/v1/sign-up", wrapper.Signup)
 	router.GET(options.BaseURL+"/public-api/v1/tenant", wrapper.GetPublicTenant)
+	router.GET(options.BaseURL+"/public-api/v1/tenant/branding", wrapper.GetPublicTenantBranding)
 	router.GET(options.BaseURL+"/public-api/v1/tenant/pictures/background", wrapper.GetTenantBackground)
 	router.GET(options.BaseURL+"/public-api/v1/tenant/pictures/background-mobile", wrapper.GetTenantBackgroundMobile)
 	router.GET(options.BaseURL+"/public-api/v1/tenant/pictures/logo", wrapper.GetTenantLogo)
+	router.GET(options.BaseURL+"/public-api/v1/tenant/settings", wrapper.GetPublicTenantSettings)
+	router.POST(options.BaseURL+"/public-api/v1/token/introspect", wrapper.IntrospectOAuthToken)
 	router.GET(options.BaseURL+"/public-api/v1/users/:userid/profile/picture", wrapper.GetProfilePicture)
 	router.POST(options.BaseURL+"/public-api/v1/verify-email", wrapper.VerifyEmail)
+	router.GET(options.BaseURL+"/superadmin-api/v1/announcements", wrapper.ListGlobalAnnouncements)
+	router.POST(options.BaseURL+"/superadmin-api/v1/announcements", wrapper.CreateGlobalAnnouncement)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/announcements/:id", wrapper.DeleteGlobalAnnouncement)
+	router.GET(options.BaseURL+"/superadmin-api/v1/announcements/:id", wrapper.GetGlobalAnnouncement)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/announcements/:id", wrapper.UpdateGlobalAnnouncement)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/client-applications/:id/token-quota", wrapper.SetClientApplicationTokenQuota)
 	router.GET(options.BaseURL+"/superadmin-api/v1/configs/global-configs", wrapper.ListGlobalConfigs)
 	router.POST(options.BaseURL+"/superadmin-api/v1/configs/global-configs", wrapper.AddGlobalConfig)
 	router.DELETE(options.BaseURL+"/superadmin-api/v1/configs/global-configs/:id", wrapper.DeleteGlobalConfig)
 	router.GET(options.BaseURL+"/superadmin-api/v1/configs/global-configs/:id", wrapper.GetGlobalConfigByID)
 	router.PUT(options.BaseURL+"/superadmin-api/v1/configs/global-configs/:id", wrapper.UpdateGlobalConfig)
+	router.GET(options.BaseURL+"/superadmin-api/v1/diagnostics/index-recommendations", wrapper.GetIndexRecommendations)
+	router.GET(options.BaseURL+"/superadmin-api/v1/diagnostics/replay-bundles", wrapper.ListReplayBundles)
+	router.GET(options.BaseURL+"/superadmin-api/v1/diagnostics/replay-bundles/:id", wrapper.GetReplayBundle)
+	router.GET(options.BaseURL+"/superadmin-api/v1/diagnostics/support-bundle", wrapper.GetSupportBundle)
+	router.GET(options.BaseURL+"/superadmin-api/v1/diagnostics/tenant-isolation-probes", wrapper.ListTenantIsolationProbeResults)
+	router.GET(options.BaseURL+"/superadmin-api/v1/feature-flags", wrapper.ListFeatureFlags)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/feature-flags/:key", wrapper.DeleteFeatureFlag)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/feature-flags/:key", wrapper.SaveFeatureFlag)
+	router.GET(options.BaseURL+"/superadmin-api/v1/file-encryption", wrapper.ListTenantFileEncryption)
+	router.GET(options.BaseURL+"/superadmin-api/v1/lockouts", wrapper.ListAuthLockouts)
+	router.POST(options.BaseURL+"/superadmin-api/v1/lockouts/unlock", wrapper.UnlockAuthSubject)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenant-templates", wrapper.ListTenantTemplates)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenant-templates/:name", wrapper.DeleteTenantTemplate)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/tenant-templates/:name", wrapper.SaveTenantTemplate)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenant-templates/:name/provision", wrapper.ProvisionTenantFromTemplate)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/feature-licenses", wrapper.GetTenantFeatureLicenses)
 	router.PUT(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/feature-licenses", wrapper.UpdateTenantFeatureLicenses)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/features", wrapper.GetTenantFeatures)
 	router.PUT(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/features", wrapper.UpdateTenantFeatures)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/settings/export", wrapper.ExportTenantSettings)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/settings/import", wrapper.ImportTenantSettings)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/subdomains", wrapper.ListTenantSubdomains)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/subdomains", wrapper.AddTenantSubdomainAlias)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/subdomains/:subdomain", wrapper.DeleteTenantSubdomainAlias)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenant/:tenantid/subdomains/:subdomain/primary", wrapper.SetPrimaryTenantSubdomain)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenants", wrapper.ListTenants)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants", wrapper.AddTenant)
 	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid", wrapper.DeleteTenant)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid", wrapper.GetTenantByID)
 	router.PUT(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid", wrapper.UpdateTenant)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/clone", wrapper.CloneTenant)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/export", wrapper.StartTenantDataExport)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/export/:jobid", wrapper.GetTenantDataExport)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/export/:jobid/download", wrapper.DownloadTenantDataExport)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/feature-flags", wrapper.ListTenantFeatureFlags)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/feature-flags/:key", wrapper.DeleteTenantFeatureFlagOverride)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/feature-flags/:key", wrapper.SetTenantFeatureFlagOverride)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/file-encryption", wrapper.GetTenantFileEncryption)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/file-encryption", wrapper.EnableTenantFileEncryption)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/file-encryption/rotate", wrapper.RotateTenantFileKey)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/purge", wrapper.CancelTenantPurge)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/purge", wrapper.GetTenantPurge)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/purge", wrapper.ScheduleTenantPurge)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/quotas", wrapper.GetTenantQuotas)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/quotas", wrapper.SetTenantQuotas)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/sandbox", wrapper.DeleteTenantSandbox)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/sandbox", wrapper.GetTenantSandbox)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/sandbox", wrapper.SetTenantSandbox)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/sandbox/reset", wrapper.ResetTenantSandbox)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/status", wrapper.SetTenantStatus)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/token-policy", wrapper.DeleteTokenPolicy)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/token-policy", wrapper.GetTokenPolicy)
+	router.PUT(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/token-policy", wrapper.SetTokenPolicy)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/usage/recommendations", wrapper.ListTenantUsageRecommendations)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users", wrapper.ListUsersFromSuperAdmin)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users", wrapper.AddUserFromSuperAdmin)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/check", wrapper.CheckUserExistsFromSuperAdmin)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/claims/rebuild", wrapper.RebuildUserClaimsFromSuperAdmin)
 	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid", wrapper.DeleteUserFromSuperAdmin)
 	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid", wrapper.GetUserByIDFromSuperAdmin)
 	router.PUT(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid", wrapper.UpdateUserFromSuperAdmin)
 	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/hard-delete", wrapper.HardDeleteUserFromSuperAdmin)
+	router.DELETE(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/impersonate", wrapper.EndUserImpersonationFromSuperAdmin)
+	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/impersonate", wrapper.ImpersonateUserFromSuperAdmin)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/membership", wrapper.AddUserMembershipFromSuperAdmin)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/password-reset-request", wrapper.ResetPasswordRequestBySuperAdmin)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/reactivate", wrapper.ReactivateUserFromSuperAdmin)
@@ -3797,4 +11095,10 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/roles/:role/assign", wrapper.AssignRoleFromSuperAdmin)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/roles/:role/unassign", wrapper.UnassignRoleFromSuperAdmin)
 	router.POST(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/status", wrapper.UpdateUserStatusFromSuperAdmin)
+	router.GET(options.BaseURL+"/superadmin-api/v1/tenants/:tenantid/users/:userid/timeline", wrapper.GetUserTimelineFromSuperAdmin)
+	router.GET(options.BaseURL+"/superadmin-api/v1/token-policies", wrapper.ListTokenPolicies)
+	router.GET(options.BaseURL+"/superadmin-api/v1/usage", wrapper.ListTenantsUsage)
+	router.POST(options.BaseURL+"/superadmin-api/v1/users/consistency", wrapper.CheckUserMembershipConsistency)
+	router.POST(options.BaseURL+"/superadmin-api/v1/users/email-encryption", wrapper.CheckUserEmailEncryption)
+	router.POST(options.BaseURL+"/superadmin-api/v1/users/merge", wrapper.MergeUsers)
 }
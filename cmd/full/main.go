@@ -13,6 +13,7 @@ import (
 	rest "ctoup.com/coreapp/internal/server/http"
 
 	connectionRepository "ctoup.com/coreapp/pkg/shared/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
@@ -51,8 +52,9 @@ func main() {
 	// Ensure the log file path exists or create it
 	defer logFile.Close()
 
-	// Create a multi-writer for both the console and the log file
-	multiWriter := zerolog.MultiLevelWriter(logFile, os.Stdout)
+	// Create a multi-writer for both the console and the log file, and keep
+	// the recent warnings and errors for the support bundles
+	multiWriter := zerolog.MultiLevelWriter(logFile, os.Stdout, util.RecentLogs)
 
 	// Set Zerolog to write to the multi-writer
 	log.Logger = zerolog.New(multiWriter).With().Timestamp().Logger()
@@ -95,7 +97,11 @@ func main() {
 		err = errors.Join(err, otelShutdown(context.Background()))
 	}() */
 
-	go rest.RunRESTServer(ctx, connPool, restAddress, connectionString)
+	restDone := make(chan struct{})
+	go func() {
+		rest.RunRESTServer(ctx, connPool, restAddress, connectionString)
+		close(restDone)
+	}()
 
 	// Graceful shutdown setup
 	// Catch shutdown signals
@@ -107,6 +113,8 @@ func main() {
 	log.Info().Msg("Shutting down server...")
 
 	stop()
+	// Wait for the services to flush their buffers
+	<-restDone
 
 	log.Info().Msg("Server exiting")
 }
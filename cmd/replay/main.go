@@ -0,0 +1,333 @@
+// Command replay sends a captured request again, from its replay bundle,
+// against a staging environment.
+//
+// The bundle is read from a file saved from
+// GET /superadmin-api/v1/diagnostics/replay-bundles/{id}, or fetched from the
+// API with -source and -id. Credentials are redacted from the bundles, so the
+// caller authenticates on the target with -header, and sets the redacted
+// fields of the body with -set.
+//
+// Without -execute, the request is printed and not sent.
+//
+//	replay -bundle bundle.json -target https://acme.staging.example.com \
+//	  -header "Authorization: Bearer $STAGING_TOKEN" -set password=secret -execute
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/service"
+)
+
+const redacted = "[REDACTED]"
+
+// skippedHeaders are set by the HTTP client or belong to the original
+// connection
+var skippedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Connection":        true,
+	"Accept-Encoding":   true,
+	"Transfer-Encoding": true,
+	"X-Request-Id":      true,
+	"X-Forwarded-For":   true,
+	"X-Forwarded-Host":  true,
+	"X-Forwarded-Proto": true,
+	"X-Real-Ip":         true,
+}
+
+type multiFlag []string
+
+func (f *multiFlag) String() string { return strings.Join(*f, ", ") }
+
+func (f *multiFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	var headers, sets, sourceHeaders multiFlag
+	bundlePath := flag.String("bundle", "", "replay bundle file")
+	source := flag.String("source", "", "base URL of the API holding the bundle, instead of -bundle")
+	id := flag.String("id", "", "id of the bundle to fetch from -source")
+	target := flag.String("target", "", "base URL of the staging environment (required)")
+	host := flag.String("host", "", "Host header resolving the tenant on the target (default: host of -target)")
+	execute := flag.Bool("execute", false, "send the request, it is only printed otherwise")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of the requests")
+	flag.Var(&headers, "header", "header sent to the target, \"Name: value\" (repeatable)")
+	flag.Var(&sets, "set", "top-level field of the JSON body to set, \"name=value\" (repeatable)")
+	flag.Var(&sourceHeaders, "source-header", "header sent to -source, \"Name: value\" (repeatable)")
+	flag.Parse()
+
+	if *target == "" || (*bundlePath == "") == (*source == "") {
+		flag.Usage()
+		fmt.Fprintln(os.Stderr, "\n-target and one of -bundle or -source are required")
+		os.Exit(2)
+	}
+	client := &http.Client{Timeout: *timeout}
+
+	var data []byte
+	var err error
+	if *bundlePath != "" {
+		data, err = os.ReadFile(*bundlePath)
+	} else {
+		data, err = fetchBundle(client, *source, *id, sourceHeaders)
+	}
+	if err != nil {
+		fail(err)
+	}
+	bundle, err := decodeBundle(data)
+	if err != nil {
+		fail(err)
+	}
+
+	req, warnings, err := buildRequest(bundle, *target, *host, headers, sets)
+	if err != nil {
+		fail(err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	printRequest(req, bundle)
+	if !*execute {
+		fmt.Println("\nDry run, add -execute to send the request")
+		return
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		fail(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("\n--- Response: %d (captured: %d)\n%s\n", resp.StatusCode, bundle.Response.Status, body)
+	if len(bundle.Response.Body) > 0 {
+		fmt.Printf("\n--- Captured response\n%s\n", bundle.Response.Body)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "replay:", err)
+	os.Exit(1)
+}
+
+func fetchBundle(client *http.Client, source, id string, headers []string) ([]byte, error) {
+	if id == "" {
+		return nil, fmt.Errorf("-id is required with -source")
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(source, "/")+"/superadmin-api/v1/diagnostics/replay-bundles/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := setHeaders(req, headers); err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the bundle: %d %s", resp.StatusCode, data)
+	}
+	return data, nil
+}
+
+// decodeBundle accepts the response of the API, holding the bundle, or the
+// bundle itself
+func decodeBundle(data []byte) (service.ReplayBundle, error) {
+	var envelope struct {
+		Bundle json.RawMessage `json:"bundle"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && len(envelope.Bundle) > 0 {
+		data = envelope.Bundle
+	}
+	var bundle service.ReplayBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return bundle, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Version != service.ReplayBundleVersion {
+		return bundle, fmt.Errorf("bundle version %d is not supported, expected %d", bundle.Version, service.ReplayBundleVersion)
+	}
+	return bundle, nil
+}
+
+func buildRequest(bundle service.ReplayBundle, target, host string, headers, sets []string) (*http.Request, []string, error) {
+	warnings := []string{}
+	captured := bundle.Request
+
+	query := url.Values{}
+	for key, values := range captured.Query {
+		if len(values) == 1 && values[0] == redacted {
+			warnings = append(warnings, fmt.Sprintf("query parameter %s is redacted and not sent", key))
+			continue
+		}
+		query[key] = values
+	}
+	u := strings.TrimRight(target, "/") + captured.Path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	body, bodyWarnings, err := buildBody(captured, sets)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings = append(warnings, bodyWarnings...)
+
+	req, err := http.NewRequest(captured.Method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range captured.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if skippedHeaders[name] {
+			continue
+		}
+		if len(values) == 1 && values[0] == redacted {
+			warnings = append(warnings, fmt.Sprintf("header %s is redacted and not sent", name))
+			continue
+		}
+		req.Header[name] = values
+	}
+	if err := setHeaders(req, headers); err != nil {
+		return nil, nil, err
+	}
+	if host != "" {
+		req.Host = host
+	}
+	// The replayed request is told apart in the logs of the target
+	if bundle.RequestID != "" {
+		req.Header.Set("X-Request-ID", "replay-"+bundle.RequestID)
+	}
+	return req, warnings, nil
+}
+
+// buildBody returns the captured JSON body with the -set fields, and warns
+// about the redacted fields left
+func buildBody(captured service.ReplayRequest, sets []string) ([]byte, []string, error) {
+	warnings := []string{}
+	if captured.BodyOmitted != "" {
+		warnings = append(warnings, fmt.Sprintf("the body was not captured (%s), the request is sent without it", captured.BodyOmitted))
+	}
+	if len(captured.Body) == 0 {
+		if len(sets) > 0 {
+			return nil, nil, fmt.Errorf("-set needs a captured JSON body")
+		}
+		return nil, warnings, nil
+	}
+	contentType := ""
+	for name, values := range captured.Headers {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		var values map[string][]string
+		if err := json.Unmarshal(captured.Body, &values); err != nil {
+			return nil, nil, fmt.Errorf("invalid form body: %w", err)
+		}
+		for _, set := range sets {
+			name, value, _ := strings.Cut(set, "=")
+			values[name] = []string{value}
+		}
+		for name, vals := range values {
+			if len(vals) == 1 && vals[0] == redacted {
+				warnings = append(warnings, fmt.Sprintf("form field %s is redacted, set it with -set", name))
+			}
+		}
+		return []byte(url.Values(values).Encode()), warnings, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(captured.Body, &value); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+	if len(sets) > 0 {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("-set needs a JSON object body")
+		}
+		for _, set := range sets {
+			name, field, _ := strings.Cut(set, "=")
+			object[name] = field
+		}
+	}
+	for _, path := range redactedPaths(value, "") {
+		warnings = append(warnings, fmt.Sprintf("body field %s is redacted, set it with -set", path))
+	}
+	body, err := json.Marshal(value)
+	return body, warnings, err
+}
+
+func redactedPaths(value interface{}, prefix string) []string {
+	paths := []string{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			paths = append(paths, redactedPaths(item, strings.TrimPrefix(prefix+"."+key, "."))...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			paths = append(paths, redactedPaths(item, fmt.Sprintf("%s[%d]", prefix, i))...)
+		}
+	case string:
+		if v == redacted {
+			paths = append(paths, prefix)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func setHeaders(req *http.Request, headers []string) error {
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, expected \"Name: value\"", header)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return nil
+}
+
+func printRequest(req *http.Request, bundle service.ReplayBundle) {
+	fmt.Printf("Captured %s, tenant %s, user %s, roles %v\n", bundle.CapturedAt.Format(time.RFC3339), bundle.Context.TenantID, bundle.Context.UserID, bundle.Context.Roles)
+	for _, e := range bundle.Response.Errors {
+		fmt.Println("  logged:", e)
+	}
+	for _, call := range bundle.Calls {
+		if call.Error != "" {
+			fmt.Printf("  failed %s call %s: %s\n", call.Kind, call.Name, call.Error)
+		}
+	}
+	fmt.Printf("\n--- Request\n%s %s\nHost: %s\n", req.Method, req.URL, req.Host)
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(req.Header[name], ", ")
+		// The credentials of the target stay off the terminal
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "auth") || strings.Contains(lower, "token") || strings.Contains(lower, "cookie") {
+			value = "***"
+		}
+		fmt.Printf("%s: %s\n", name, value)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"ctoup.com/coreapp/pkg/shared/server/core"
 
@@ -53,7 +54,8 @@ func RunRESTServer(ctx context.Context, connPool *pgxpool.Pool, address string,
 			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			c.Writer.Header().Add("Vary", "Origin")
 		}
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Session-Token, X-CSRF-Token, Cookie, X-Requested-With, X-App-Source")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Session-Token, X-CSRF-Token, Cookie, X-Requested-With, X-App-Source, X-MFA-Token, X-Impersonation-Token")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Impersonated-By")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE, OPTIONS, PATCH")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -91,4 +93,10 @@ func RunRESTServer(ctx context.Context, connPool *pgxpool.Pool, address string,
 	case err := <-serverErrorChan:
 		log.Err(err).Msg("Server error")
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := serverConfig.Shutdown(shutdownCtx); err != nil {
+		log.Err(err).Msg("Failed to shut down services")
+	}
 }
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountDeletionHandler lets users delete their own account, after a grace
+// period during which the link sent by email cancels the deletion
+type AccountDeletionHandler struct {
+	store           *db.Store
+	authProvider    auth.AuthProvider
+	deletionService *access.AccountDeletionService
+}
+
+func NewAccountDeletionHandler(store *db.Store, authProvider auth.AuthProvider) *AccountDeletionHandler {
+	return &AccountDeletionHandler{
+		store:           store,
+		authProvider:    authProvider,
+		deletionService: access.NewAccountDeletionService(store, authProvider, access.AccountDeletionConfigFromEnv()),
+	}
+}
+
+// (DELETE /api/v1/users/me)
+func (h *AccountDeletionHandler) DeleteMyAccount(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, helpers.ErrorStringResponse("Not authenticated"))
+		return
+	}
+	subdomain, err := util.GetSubdomain(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	authClient, err := h.authProvider.GetAuthClientForSubdomain(c, subdomain)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for subdomain")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if err := h.deletionService.CheckRecentAuthentication(c, authClient, userID, c.GetString(auth.AUTH_SESSION_ID)); err != nil {
+		abortWithAccountDeletionError(c, err, "Failed to check the authentication of the session")
+		return
+	}
+
+	token, request, err := h.deletionService.ScheduleAccountDeletion(c, c.GetString(auth.AUTH_TENANT_ID_KEY), userID)
+	if err != nil {
+		abortWithAccountDeletionError(c, err, "Failed to schedule account deletion")
+		return
+	}
+	if err := h.sendScheduledNotice(c, request, token); err != nil {
+		// Without the link the user cannot cancel, so the deletion is not
+		// kept scheduled
+		logger.Err(err).Str("deletion_request_id", request.ID.String()).Msg("Failed to send account deletion notice")
+		if _, cancelErr := h.deletionService.CancelAccountDeletion(c, token); cancelErr != nil {
+			logger.Err(cancelErr).Str("deletion_request_id", request.ID.String()).Msg("Failed to cancel account deletion without notice")
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusAccepted, core.AccountDeletion{
+		Status:       request.Status,
+		ScheduledFor: request.ScheduledFor,
+	})
+}
+
+// (POST /public-api/v1/account-deletion/cancel)
+func (h *AccountDeletionHandler) CancelAccountDeletion(c *gin.Context) {
+	var req core.CancelAccountDeletionJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	request, err := h.deletionService.CancelAccountDeletion(c, req.Token)
+	if err != nil {
+		abortWithAccountDeletionError(c, err, "Failed to cancel account deletion")
+		return
+	}
+	c.JSON(http.StatusOK, core.AccountDeletion{
+		Status:       request.Status,
+		ScheduledFor: request.ScheduledFor,
+	})
+}
+
+// abortWithAccountDeletionError answers the status matching an error of the
+// account deletion service
+func abortWithAccountDeletionError(c *gin.Context, err error, message string) {
+	if abortIfOwnershipTransferFailed(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, access.ErrReauthenticationRequired):
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrAccountDeletionNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrAccountDeletionScheduled), errors.Is(err, access.ErrAccountDeletionGlobalRole):
+		c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(message)
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+}
+
+// sendScheduledNotice sends the date of the deletion and the link cancelling
+// it to the address of the user
+func (h *AccountDeletionHandler) sendScheduledNotice(c *gin.Context, request repository.CoreUserDeletionRequest, token string) error {
+	link, err := buildTenantURL(c, "/account-deletion/cancel?token="+url.QueryEscape(token), "")
+	if err != nil {
+		return err
+	}
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	templateData := struct {
+		Link         string
+		ScheduledFor string
+	}{
+		Link:         link,
+		ScheduledFor: request.ScheduledFor.UTC().Format(emailChangeDateFormat),
+	}
+	r := emailservice.NewEmailRequest(fromEmail, []string{c.GetString(auth.AUTH_EMAIL)}, "Your account will be deleted", "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if err := r.ParseTemplateWithDomain(c, "account-deletion.html", templateData); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// AnnouncementHandler handles the announcement banner endpoints.
+//
+// Tenant announcements (/api/v1/tenant/announcements) are managed by the
+// tenant admins and scoped to the request tenant. Global announcements
+// (/superadmin-api/v1/announcements) always use the "" scope, whatever the
+// subdomain the SUPER_ADMIN calls from.
+type AnnouncementHandler struct {
+	store               *db.Store
+	announcementService *access.AnnouncementService
+}
+
+func NewAnnouncementHandler(store *db.Store) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		store:               store,
+		announcementService: access.NewAnnouncementService(store),
+	}
+}
+
+func toAPIAnnouncement(announcement repository.CoreAnnouncement) core.Announcement {
+	result := core.Announcement{
+		Id:        announcement.ID,
+		Title:     announcement.Title,
+		Message:   announcement.Message,
+		Severity:  core.AnnouncementSeverity(announcement.Severity),
+		StartsAt:  announcement.StartsAt,
+		EndsAt:    announcement.EndsAt,
+		CreatedBy: announcement.CreatedBy,
+		CreatedAt: announcement.CreatedAt,
+		UpdatedAt: announcement.UpdatedAt,
+	}
+	if announcement.TenantID != "" {
+		result.TenantId = &announcement.TenantID
+	}
+	return result
+}
+
+func toAPIAnnouncements(announcements []repository.CoreAnnouncement) []core.Announcement {
+	result := make([]core.Announcement, len(announcements))
+	for i, announcement := range announcements {
+		result[i] = toAPIAnnouncement(announcement)
+	}
+	return result
+}
+
+func toAnnouncementInput(req core.NewAnnouncement) access.AnnouncementInput {
+	input := access.AnnouncementInput{
+		Title:    req.Title,
+		Message:  req.Message,
+		Severity: string(req.Severity),
+		EndsAt:   req.EndsAt,
+	}
+	if req.StartsAt != nil {
+		input.StartsAt = *req.StartsAt
+	}
+	return input
+}
+
+// tenantAnnouncementScope returns the tenant managed by the caller, or writes
+// the error response when the caller may not manage its tenant announcements
+func tenantAnnouncementScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageAnnouncements); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": err.Error(),
+		})
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "Tenant announcements must be managed from a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func announcementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidAnnouncement):
+		return http.StatusBadRequest
+	case err.Error() == pgx.ErrNoRows.Error():
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// (GET /api/v1/announcements)
+func (h *AnnouncementHandler) ListActiveAnnouncements(c *gin.Context) {
+	announcements, err := h.announcementService.ListActiveAnnouncements(c, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIAnnouncements(announcements))
+}
+
+// (GET /api/v1/tenant/announcements)
+func (h *AnnouncementHandler) ListTenantAnnouncements(c *gin.Context, params core.ListTenantAnnouncementsParams) {
+	tenantID, ok := tenantAnnouncementScope(c)
+	if !ok {
+		return
+	}
+	h.listAnnouncements(c, tenantID, params.IncludeExpired, params.Page, params.PageSize)
+}
+
+// (POST /api/v1/tenant/announcements)
+func (h *AnnouncementHandler) CreateTenantAnnouncement(c *gin.Context) {
+	tenantID, ok := tenantAnnouncementScope(c)
+	if !ok {
+		return
+	}
+	h.createAnnouncement(c, tenantID)
+}
+
+// (GET /api/v1/tenant/announcements/{id})
+func (h *AnnouncementHandler) GetTenantAnnouncement(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := tenantAnnouncementScope(c)
+	if !ok {
+		return
+	}
+	h.getAnnouncement(c, id, tenantID)
+}
+
+// (PUT /api/v1/tenant/announcements/{id})
+func (h *AnnouncementHandler) UpdateTenantAnnouncement(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := tenantAnnouncementScope(c)
+	if !ok {
+		return
+	}
+	h.updateAnnouncement(c, id, tenantID)
+}
+
+// (DELETE /api/v1/tenant/announcements/{id})
+func (h *AnnouncementHandler) DeleteTenantAnnouncement(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := tenantAnnouncementScope(c)
+	if !ok {
+		return
+	}
+	h.deleteAnnouncement(c, id, tenantID)
+}
+
+// (GET /superadmin-api/v1/announcements)
+func (h *AnnouncementHandler) ListGlobalAnnouncements(c *gin.Context, params core.ListGlobalAnnouncementsParams) {
+	h.listAnnouncements(c, "", params.IncludeExpired, params.Page, params.PageSize)
+}
+
+// (POST /superadmin-api/v1/announcements)
+func (h *AnnouncementHandler) CreateGlobalAnnouncement(c *gin.Context) {
+	h.createAnnouncement(c, "")
+}
+
+// (GET /superadmin-api/v1/announcements/{id})
+func (h *AnnouncementHandler) GetGlobalAnnouncement(c *gin.Context, id openapi_types.UUID) {
+	h.getAnnouncement(c, id, "")
+}
+
+// (PUT /superadmin-api/v1/announcements/{id})
+func (h *AnnouncementHandler) UpdateGlobalAnnouncement(c *gin.Context, id openapi_types.UUID) {
+	h.updateAnnouncement(c, id, "")
+}
+
+// (DELETE /superadmin-api/v1/announcements/{id})
+func (h *AnnouncementHandler) DeleteGlobalAnnouncement(c *gin.Context, id openapi_types.UUID) {
+	h.deleteAnnouncement(c, id, "")
+}
+
+func (h *AnnouncementHandler) listAnnouncements(c *gin.Context, tenantID string, includeExpired *bool, page, pageSize *int32) {
+	pagingSql := helpers.GetPagingSQL(helpers.PagingRequest{
+		MaxPageSize:     100,
+		DefaultPage:     1,
+		DefaultPageSize: 20,
+		Page:            page,
+		PageSize:        pageSize,
+	})
+	announcements, err := h.announcementService.ListAnnouncements(c, tenantID,
+		includeExpired != nil && *includeExpired, pagingSql.PageSize, pagingSql.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIAnnouncements(announcements))
+}
+
+func (h *AnnouncementHandler) createAnnouncement(c *gin.Context, tenantID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.NewAnnouncement
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msg("Failed to bind request body")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	announcement, err := h.announcementService.CreateAnnouncement(c, tenantID, toAnnouncementInput(req), c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		c.JSON(announcementErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, toAPIAnnouncement(announcement))
+}
+
+func (h *AnnouncementHandler) getAnnouncement(c *gin.Context, id openapi_types.UUID, tenantID string) {
+	announcement, err := h.announcementService.GetAnnouncement(c, id, tenantID)
+	if err != nil {
+		c.JSON(announcementErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIAnnouncement(announcement))
+}
+
+func (h *AnnouncementHandler) updateAnnouncement(c *gin.Context, id openapi_types.UUID, tenantID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.NewAnnouncement
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msg("Failed to bind request body")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	announcement, err := h.announcementService.UpdateAnnouncement(c, id, tenantID, toAnnouncementInput(req))
+	if err != nil {
+		c.JSON(announcementErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIAnnouncement(announcement))
+}
+
+func (h *AnnouncementHandler) deleteAnnouncement(c *gin.Context, id openapi_types.UUID, tenantID string) {
+	if err := h.announcementService.DeleteAnnouncement(c, id, tenantID); err != nil {
+		c.JSON(announcementErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
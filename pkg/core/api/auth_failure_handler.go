@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthFailureHandler exposes the failed authentications of the request tenant
+// to its administrators
+type AuthFailureHandler struct {
+	authFailureService *access.AuthFailureService
+}
+
+func NewAuthFailureHandler(store *db.Store) *AuthFailureHandler {
+	return &AuthFailureHandler{
+		authFailureService: access.NewAuthFailureService(store),
+	}
+}
+
+// GetAuthFailureSummary returns the failures of the tenant by reason and by hour
+// (GET /api/v1/tenant/auth-failures)
+func (h *AuthFailureHandler) GetAuthFailureSummary(c *gin.Context, params core.GetAuthFailureSummaryParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+
+	to := time.Now()
+	if params.To != nil {
+		to = *params.To
+	}
+	from := to.AddDate(0, 0, -7)
+	if params.From != nil {
+		from = *params.From
+	}
+
+	summary, err := h.authFailureService.GetAuthFailureSummary(c, c.GetString(auth.AUTH_TENANT_ID_KEY), from, to)
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidAuthFailureRange) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get auth failure summary")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := core.AuthFailureSummary{
+		From:    summary.From,
+		To:      summary.To,
+		Total:   summary.Total,
+		Reasons: make([]core.AuthFailureReasonCount, 0, len(summary.Totals)),
+		Hourly:  make([]core.AuthFailureHourlyCount, len(summary.Hourly)),
+	}
+	for reason, count := range summary.Totals {
+		result.Reasons = append(result.Reasons, core.AuthFailureReasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(result.Reasons, func(i, j int) bool {
+		return result.Reasons[i].Reason < result.Reasons[j].Reason
+	})
+	for i, count := range summary.Hourly {
+		result.Hourly[i] = core.AuthFailureHourlyCount{Hour: count.Bucket, Reason: count.Reason, Count: count.Count}
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"ctoup.com/coreapp/api/helpers"
@@ -14,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // ClientApplicationHandler handles client application endpoints.
@@ -55,6 +59,8 @@ func toAPIClientApplication(app repository.CoreClientApplication) core.ClientApp
 		lastUsed := app.LastUsedAt.Time
 		result.LastUsedAt = &lastUsed
 	}
+	result.MaxActiveTokens = util.FromNullableInt4(app.MaxActiveTokens)
+	result.ServiceAccountId = util.FromNullableText(app.ServiceAccountID)
 
 	return result
 }
@@ -100,6 +106,11 @@ func toAPIToken(token repository.ListAPITokensRow) core.APIToken {
 		result.LastUsedIp = &token.LastUsedIp.String
 	}
 
+	result.RateLimitPerMinute = util.FromNullableInt4(token.RateLimitPerMinute)
+	result.RateLimitPerHour = util.FromNullableInt4(token.RateLimitPerHour)
+	result.AllowedCidrs = &token.AllowedCidrs
+	result.ScopeTemplateId = util.FromNullableUUID(token.ScopeTemplateID)
+
 	return result
 }
 
@@ -146,6 +157,11 @@ func toAPITokenSingle(token repository.GetAPITokenByIDRow) core.APIToken {
 		result.LastUsedIp = &token.LastUsedIp.String
 	}
 
+	result.RateLimitPerMinute = util.FromNullableInt4(token.RateLimitPerMinute)
+	result.RateLimitPerHour = util.FromNullableInt4(token.RateLimitPerHour)
+	result.AllowedCidrs = &token.AllowedCidrs
+	result.ScopeTemplateId = util.FromNullableUUID(token.ScopeTemplateID)
+
 	return result
 }
 
@@ -173,10 +189,122 @@ func toAPITokenCreated(token string, apiToken repository.CoreApiToken) core.APIT
 		result.ApiToken.Scopes = &apiToken.Scopes
 	}
 
+	result.ApiToken.RateLimitPerMinute = util.FromNullableInt4(apiToken.RateLimitPerMinute)
+	result.ApiToken.RateLimitPerHour = util.FromNullableInt4(apiToken.RateLimitPerHour)
+	result.ApiToken.AllowedCidrs = &apiToken.AllowedCidrs
+	result.ApiToken.ScopeTemplateId = util.FromNullableUUID(apiToken.ScopeTemplateID)
+
 	return result
 }
 
+func toAPIClientSecretCreated(secret string, record repository.CoreClientApplicationSecret) core.ClientSecretCreated {
+	return core.ClientSecretCreated{
+		ClientId:     record.ClientApplicationID.String(),
+		ClientSecret: secret,
+		SecretPrefix: record.SecretPrefix,
+		Scopes:       record.Scopes,
+		CreatedAt:    record.CreatedAt,
+	}
+}
+
+func toAPISigningKeyCreated(secret string, record repository.CoreClientApplicationSigningKey) core.SigningKeyCreated {
+	return core.SigningKeyCreated{
+		ClientId:      record.ClientApplicationID.String(),
+		SigningKeyId:  record.ID,
+		SigningSecret: secret,
+		Scopes:        record.Scopes,
+		CreatedAt:     record.CreatedAt,
+	}
+}
+
 // Convert audit log to API model
+func toAPIClientApplicationConfig(config access.ClientApplicationConfig) core.ClientApplicationConfig {
+	result := core.ClientApplicationConfig{
+		Version: config.Version,
+		Name:    config.Name,
+		Tokens:  make([]core.ClientApplicationTokenConfig, len(config.Tokens)),
+	}
+	if config.Description != "" {
+		result.Description = &config.Description
+	}
+	for i, token := range config.Tokens {
+		result.Tokens[i] = core.ClientApplicationTokenConfig{
+			Name:               token.Name,
+			ExpiresInDays:      int32(token.ExpiresInDays),
+			RateLimitPerMinute: token.RateLimitPerMinute,
+			RateLimitPerHour:   token.RateLimitPerHour,
+		}
+		if token.Description != "" {
+			result.Tokens[i].Description = &token.Description
+		}
+		if len(token.Scopes) > 0 {
+			result.Tokens[i].Scopes = &token.Scopes
+		}
+		if len(token.AllowedCIDRs) > 0 {
+			result.Tokens[i].AllowedCidrs = &token.AllowedCIDRs
+		}
+	}
+	if config.ClientCredentials != nil {
+		credentials := config.ClientCredentials
+		result.ClientCredentials = &core.ClientApplicationClientCredentialsConfig{
+			Scopes:             credentials.Scopes,
+			RateLimitPerMinute: credentials.RateLimitPerMinute,
+			RateLimitPerHour:   credentials.RateLimitPerHour,
+		}
+		if len(credentials.AllowedCIDRs) > 0 {
+			result.ClientCredentials.AllowedCidrs = &credentials.AllowedCIDRs
+		}
+	}
+	if config.RequestSigning != nil {
+		result.RequestSigning = &core.ClientApplicationCredentialConfig{Scopes: config.RequestSigning.Scopes}
+	}
+	if config.WebhookURL != "" {
+		result.WebhookUrl = &config.WebhookURL
+	}
+	return result
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func fromAPIClientApplicationConfig(config core.ClientApplicationConfig) access.ClientApplicationConfig {
+	result := access.ClientApplicationConfig{
+		Version:     config.Version,
+		Name:        config.Name,
+		Description: stringValue(config.Description),
+		Tokens:      make([]access.APITokenConfig, len(config.Tokens)),
+		WebhookURL:  stringValue(config.WebhookUrl),
+	}
+	for i, token := range config.Tokens {
+		result.Tokens[i] = access.APITokenConfig{
+			Name:               token.Name,
+			Description:        stringValue(token.Description),
+			ExpiresInDays:      int(token.ExpiresInDays),
+			Scopes:             util.ToNullableSlice(token.Scopes),
+			RateLimitPerMinute: token.RateLimitPerMinute,
+			RateLimitPerHour:   token.RateLimitPerHour,
+			AllowedCIDRs:       util.ToNullableSlice(token.AllowedCidrs),
+		}
+	}
+	if config.ClientCredentials != nil {
+		credentials := config.ClientCredentials
+		result.ClientCredentials = &access.ClientCredentialsConfig{
+			Scopes:             credentials.Scopes,
+			RateLimitPerMinute: credentials.RateLimitPerMinute,
+			RateLimitPerHour:   credentials.RateLimitPerHour,
+			AllowedCIDRs:       util.ToNullableSlice(credentials.AllowedCidrs),
+		}
+	}
+	if config.RequestSigning != nil {
+		result.RequestSigning = &access.CredentialConfig{Scopes: config.RequestSigning.Scopes}
+	}
+	return result
+}
+
 func toAPIAuditLog(auditLog repository.CoreApiTokenAuditLog) core.APITokenAuditLog {
 	result := core.APITokenAuditLog{
 		Id:        auditLog.ID,
@@ -196,6 +324,35 @@ func toAPIAuditLog(auditLog repository.CoreApiTokenAuditLog) core.APITokenAuditL
 	return result
 }
 
+func toAPITokenUsage(usage access.APITokenUsage) core.APITokenUsage {
+	result := core.APITokenUsage{
+		From:          usage.From,
+		To:            usage.To,
+		TotalRequests: usage.TotalRequests,
+		UniqueIps:     usage.UniqueIPs,
+		Daily:         make([]core.APITokenDailyUsage, len(usage.Daily)),
+		TopEndpoints:  make([]core.APITokenEndpointUsage, len(usage.TopEndpoints)),
+	}
+
+	for i, day := range usage.Daily {
+		result.Daily[i] = core.APITokenDailyUsage{
+			Day:       openapi_types.Date{Time: day.Day.UTC()},
+			Requests:  day.Requests,
+			UniqueIps: day.UniqueIps,
+		}
+	}
+
+	for i, endpoint := range usage.TopEndpoints {
+		result.TopEndpoints[i] = core.APITokenEndpointUsage{
+			Method:   endpoint.Method,
+			Path:     endpoint.Path,
+			Requests: endpoint.Requests,
+		}
+	}
+
+	return result
+}
+
 // ListClientApplications returns all client applications
 func (h *ClientApplicationHandler) ListClientApplications(c *gin.Context, params core.ListClientApplicationsParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
@@ -252,6 +409,14 @@ func (h *ClientApplicationHandler) ListClientApplications(c *gin.Context, params
 		return
 	}
 
+	total, err := h.clientAppService.CountClientApplications(c, c.GetString(auth.AUTH_TENANT_ID_KEY), searchQuery, includeInactive)
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Msg("Failed to count client applications")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	helpers.SetPagingHeaders(c, total, pagingSql)
+
 	// Convert results to API model
 	result := make([]core.ClientApplication, len(apps))
 	for i, app := range apps {
@@ -291,6 +456,10 @@ func (h *ClientApplicationHandler) CreateClientApplication(c *gin.Context) {
 	)
 
 	if err != nil {
+		if errors.Is(err, access.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Str("userID", userID.(string)).Str("name", req.Name).Msg("Failed to create client application")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
@@ -416,7 +585,7 @@ func (h *ClientApplicationHandler) DeactivateClientApplication(c *gin.Context, i
 	}
 
 	// Deactivate application (scoped to the caller's tenant; empty for global)
-	err := h.clientAppService.DeactivateClientApplication(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	err := h.clientAppService.DeactivateClientApplication(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY), userID.(string))
 	if err != nil {
 		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to deactivate client application")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
@@ -426,196 +595,205 @@ func (h *ClientApplicationHandler) DeactivateClientApplication(c *gin.Context, i
 	c.Status(http.StatusNoContent)
 }
 
-// ListAPITokens lists API tokens for a client application
-func (h *ClientApplicationHandler) ListAPITokens(c *gin.Context, id uuid.UUID, params core.ListAPITokensParams) {
+// ActivateClientApplication activates a deactivated client application
+// (PATCH /admin-api/v1/client-applications/{id}/activate)
+func (h *ClientApplicationHandler) ActivateClientApplication(c *gin.Context, id uuid.UUID) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
-	// Only super admins can access this endpoint
-	userID, exists := c.Get(auth.AUTH_USER_ID)
-	if !exists {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
 		logger.Error().Msg("User not authenticated")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
-	// Set up paging parameters
-	pagingRequest := helpers.PagingRequest{
-		MaxPageSize:     50,
-		DefaultPage:     1,
-		DefaultPageSize: 10,
-		DefaultSortBy:   "created_at",
-		DefaultOrder:    "desc",
-		Page:            params.Page,
-		PageSize:        params.PageSize,
-		SortBy:          params.SortBy,
-		Order:           (*string)(params.Order),
+
+	// Scoped to the caller's tenant; empty for global
+	err := h.clientAppService.ActivateClientApplication(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY), userID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("client application not found")))
+			return
+		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to activate client application")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
 	}
 
-	pagingSql := helpers.GetPagingSQL(pagingRequest)
+	c.Status(http.StatusNoContent)
+}
 
-	// Check for optional parameters
-	includeRevoked := false
-	if params.IncludeRevoked != nil {
-		includeRevoked = *params.IncludeRevoked
-	}
+// LinkClientApplicationServiceAccount makes the requests of a client
+// application run as its service account
+func (h *ClientApplicationHandler) LinkClientApplicationServiceAccount(c *gin.Context, id uuid.UUID) {
+	h.setClientApplicationServiceAccount(c, id, true)
+}
 
-	includeExpired := false
-	if params.IncludeExpired != nil {
-		includeExpired = *params.IncludeExpired
-	}
+// UnlinkClientApplicationServiceAccount makes the requests of a client
+// application run as its creator again
+func (h *ClientApplicationHandler) UnlinkClientApplicationServiceAccount(c *gin.Context, id uuid.UUID) {
+	h.setClientApplicationServiceAccount(c, id, false)
+}
 
-	// List tokens (scoped to the caller's tenant; empty for global)
-	tokens, err := h.clientAppService.ListAPITokens(
-		c,
-		&id,
-		c.GetString(auth.AUTH_TENANT_ID_KEY),
-		pagingSql.PageSize,
-		pagingSql.Offset,
-		pagingSql.SortBy,
-		pagingSql.Order,
-		includeRevoked,
-		includeExpired,
-	)
+func (h *ClientApplicationHandler) setClientApplicationServiceAccount(c *gin.Context, id uuid.UUID, link bool) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
 
+	// Scoped to the caller's tenant; empty for global
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	var app repository.CoreClientApplication
+	var err error
+	if link {
+		app, err = h.clientAppService.LinkServiceAccount(c, id, tenantID, userID)
+	} else {
+		app, err = h.clientAppService.UnlinkServiceAccount(c, id, tenantID, userID)
+	}
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to list API tokens")
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("client application not found")))
+			return
+		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to set client application service account")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
 
-	// Convert results to API model
-	result := make([]core.APIToken, len(tokens))
-	for i, token := range tokens {
-		result[i] = toAPIToken(token)
-	}
-
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, toAPIClientApplication(app))
 }
 
-// CreateAPIToken creates a new API token for a client application
-func (h *ClientApplicationHandler) CreateAPIToken(c *gin.Context, id uuid.UUID) {
+// RotateClientApplicationSecret creates or replaces the OAuth2 client secret of
+// a client application
+func (h *ClientApplicationHandler) RotateClientApplicationSecret(c *gin.Context, id uuid.UUID) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
-
-	// Only super admins can access this endpoint
-	userID, exists := c.Get(auth.AUTH_USER_ID)
-	if !exists {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
 		logger.Error().Msg("User not authenticated")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	// Parse request body
-	var req core.CreateAPITokenJSONRequestBody
+	var req core.RotateClientApplicationSecretJSONRequestBody
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to bind JSON for API token creation")
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
 		return
 	}
-
-	// Prepare parameters
-	description := ""
-	if req.Description != nil {
-		description = *req.Description
-	}
-
-	// Calculate expiry days from timestamp
-	expiresAt := req.ExpiresAt
-
-	// Calculate days from now
-	expiryDays := int(time.Until(expiresAt).Hours() / 24)
-
-	// Handle scopes
 	var scopes []string
 	if req.Scopes != nil {
 		scopes = *req.Scopes
 	}
+	limits := access.APITokenLimits{
+		RateLimitPerMinute: req.RateLimitPerMinute,
+		RateLimitPerHour:   req.RateLimitPerHour,
+	}
+	if req.AllowedCidrs != nil {
+		limits.AllowedCIDRs = *req.AllowedCidrs
+	}
 
-	// Create API token (scoped to the caller's tenant; empty for global). The
-	// service rejects the request if the client application is out of scope.
-	token, apiToken, err := h.clientAppService.CreateAPIToken(
-		c,
-		id,
-		c.GetString(auth.AUTH_TENANT_ID_KEY),
-		req.Name,
-		description,
-		expiryDays,
-		userID.(string),
-		scopes,
-	)
-
+	// Scoped to the caller's tenant; empty for global
+	secret, record, err := h.clientAppService.RotateClientSecret(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY), scopes, limits, userID)
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to create API token")
+		if errors.Is(err, access.ErrInvalidAPITokenRateLimit) || errors.Is(err, access.ErrInvalidCIDR) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to rotate client secret")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
 
-	// Return the token and API token
-	c.JSON(http.StatusCreated, toAPITokenCreated(token, apiToken))
+	c.JSON(http.StatusCreated, toAPIClientSecretCreated(secret, record))
 }
 
-// GetAPITokenById retrieves an API token by ID
-func (h *ClientApplicationHandler) GetAPITokenById(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+// DeleteClientApplicationSecret disables the client credentials grant of a
+// client application
+func (h *ClientApplicationHandler) DeleteClientApplicationSecret(c *gin.Context, id uuid.UUID) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
-	// Only super admins can access this endpoint
-	userID, exists := c.Get(auth.AUTH_USER_ID)
-	if !exists {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
 		logger.Error().Msg("User not authenticated")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	// Get token (scoped to the caller's tenant; empty for global)
-	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	err := h.clientAppService.DeleteClientSecret(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", id.String()).Msg("Failed to get API token")
-
 		if err.Error() == pgx.ErrNoRows.Error() {
 			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
 			return
 		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to delete client secret")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
 
-	if token.ClientApplicationID != id {
-		logger.Error().Msg("API token does not belong to the specified client application")
-		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
-		return
-	}
-
-	c.JSON(http.StatusOK, toAPITokenSingle(token))
+	c.Status(http.StatusNoContent)
 }
 
-// DeleteAPIToken deletes an API token
-func (h *ClientApplicationHandler) DeleteAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+// RotateClientApplicationSigningKey creates or replaces the HMAC request
+// signing key of a client application
+func (h *ClientApplicationHandler) RotateClientApplicationSigningKey(c *gin.Context, id uuid.UUID) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
-	// Only super admins can access this endpoint
-	userID, exists := c.Get(auth.AUTH_USER_ID)
-	if !exists {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
 		logger.Error().Msg("User not authenticated")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
-	// Verify token exists and belongs to the client application (scoped to tenant)
-	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+
+	var req core.RotateClientApplicationSigningKeyJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	var scopes []string
+	if req.Scopes != nil {
+		scopes = *req.Scopes
+	}
+
+	// Scoped to the caller's tenant; empty for global
+	secret, record, err := h.clientAppService.RotateSigningKey(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY), scopes, userID)
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to get API token for deletion")
 		if err.Error() == pgx.ErrNoRows.Error() {
 			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
 			return
 		}
+		if errors.Is(err, access.ErrRequestSigningNotConfigured) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to rotate signing key")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
 
-	if token.ClientApplicationID != id {
-		logger.Error().Msg("API token does not belong to the specified client application")
-		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+	c.JSON(http.StatusCreated, toAPISigningKeyCreated(secret, record))
+}
+
+// DeleteClientApplicationSigningKey disables signed requests for a client
+// application
+func (h *ClientApplicationHandler) DeleteClientApplicationSigningKey(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	// Delete token
-	err = h.clientAppService.DeleteAPIToken(c, tokenId)
+	err := h.clientAppService.DeleteSigningKey(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to delete API token")
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to delete signing key")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
@@ -623,61 +801,703 @@ func (h *ClientApplicationHandler) DeleteAPIToken(c *gin.Context, id uuid.UUID,
 	c.Status(http.StatusNoContent)
 }
 
-// RevokeAPIToken revokes an API token
-func (h *ClientApplicationHandler) RevokeAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+// GetClientApplicationWebhook returns the credential lifecycle webhook of a
+// client application
+func (h *ClientApplicationHandler) GetClientApplicationWebhook(c *gin.Context, id uuid.UUID) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
-	// Only super admins can access this endpoint
-	userID, exists := c.Get(auth.AUTH_USER_ID)
-	if !exists {
-		logger.Error().Msg("User not authenticated")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
 
-	// Verify token exists and belongs to the client application (scoped to tenant)
-	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	record, err := h.clientAppService.GetWebhook(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", id.String()).Msg("Failed to get API token for revocation")
-
 		if err.Error() == pgx.ErrNoRows.Error() {
 			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
 			return
 		}
+		logger.Err(err).Str("appID", id.String()).Msg("Failed to get webhook")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
-	if token.ClientApplicationID != id {
-		logger.Error().Msg("API token does not belong to the specified client application")
-		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+
+	c.JSON(http.StatusOK, core.ClientApplicationWebhook{
+		Id:        record.ID,
+		ClientId:  record.ClientApplicationID.String(),
+		Url:       record.Url,
+		CreatedBy: record.CreatedBy,
+		CreatedAt: record.CreatedAt,
+	})
+}
+
+// SetClientApplicationWebhook creates or replaces the credential lifecycle
+// webhook of a client application
+func (h *ClientApplicationHandler) SetClientApplicationWebhook(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	// Parse request body
-	var req core.RevokeAPITokenJSONRequestBody
+	var req core.SetClientApplicationWebhookJSONRequestBody
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to bind JSON for API token revocation")
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
 		return
 	}
 
-	// Set revocation reason
-	reason := req.Reason
-
-	// Revoke token (scoped to the caller's tenant; empty for global)
-	revokedToken, err := h.clientAppService.RevokeAPIToken(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY), reason, userID.(string))
+	secret, record, err := h.clientAppService.SetWebhook(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY), req.Url, userID)
 	if err != nil {
-		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to revoke API token")
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		if errors.Is(err, access.ErrInvalidWebhookURL) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		if errors.Is(err, access.ErrRequestSigningNotConfigured) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("userID", userID).Str("appID", id.String()).Msg("Failed to set webhook")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
 
-	// Convert to API model
-	apiToken := core.APIToken{
-		Id:                  revokedToken.ID,
-		ClientApplicationId: revokedToken.ClientApplicationID,
-		Name:                revokedToken.Name,
-		TokenPrefix:         revokedToken.TokenPrefix,
-		ExpiresAt:           revokedToken.ExpiresAt,
+	c.JSON(http.StatusCreated, core.ClientApplicationWebhookCreated{
+		Id:            record.ID,
+		ClientId:      record.ClientApplicationID.String(),
+		Url:           record.Url,
+		SigningSecret: secret,
+		CreatedAt:     record.CreatedAt,
+	})
+}
+
+// DeleteClientApplicationWebhook stops the credential lifecycle deliveries of
+// a client application
+func (h *ClientApplicationHandler) DeleteClientApplicationWebhook(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+
+	err := h.clientAppService.DeleteWebhook(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("appID", id.String()).Msg("Failed to delete webhook")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExportClientApplicationConfig returns the configuration of a client
+// application, without its secrets
+func (h *ClientApplicationHandler) ExportClientApplicationConfig(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	config, err := h.clientAppService.ExportClientApplicationConfig(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("appID", id.String()).Msg("Failed to export client application configuration")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIClientApplicationConfig(config))
+}
+
+// ImportClientApplicationConfig creates a client application from an exported
+// configuration, with new secrets
+func (h *ClientApplicationHandler) ImportClientApplicationConfig(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req core.ImportClientApplicationConfigJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	imported, err := h.clientAppService.ImportClientApplicationConfig(c, c.GetString(auth.AUTH_TENANT_ID_KEY), fromAPIClientApplicationConfig(req), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrInvalidClientApplicationConfig), errors.Is(err, access.ErrInvalidWebhookURL):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, access.ErrRequestSigningNotConfigured), errors.Is(err, access.ErrTokenQuotaExceeded),
+			errors.Is(err, access.ErrTenantQuotaExceeded):
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Str("userID", userID).Msg("Failed to import client application configuration")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+
+	result := core.ClientApplicationImportResult{
+		ClientApplication: toAPIClientApplication(imported.Application),
+		Tokens:            make([]core.APITokenCreated, len(imported.Tokens)),
+	}
+	for i, token := range imported.Tokens {
+		result.Tokens[i] = toAPITokenCreated(token.Token, token.APIToken)
+	}
+	if imported.Secret != nil {
+		secret := toAPIClientSecretCreated(imported.ClientSecret, *imported.Secret)
+		result.ClientSecret = &secret
+	}
+	if imported.SigningKey != nil {
+		key := toAPISigningKeyCreated(imported.SigningSecret, *imported.SigningKey)
+		result.SigningKey = &key
+	}
+	if imported.Webhook != nil {
+		result.Webhook = &core.ClientApplicationWebhookCreated{
+			Id:            imported.Webhook.ID,
+			ClientId:      imported.Webhook.ClientApplicationID.String(),
+			Url:           imported.Webhook.Url,
+			SigningSecret: imported.WebhookSecret,
+			CreatedAt:     imported.Webhook.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// IssueOAuthToken is the OAuth2 token endpoint (RFC 6749 section 4.4). Errors
+// use the OAuth2 error format rather than ErrorSchema so standard clients can
+// read them.
+func (h *ClientApplicationHandler) IssueOAuthToken(c *gin.Context) {
+	// Tokens must not be cached (RFC 6749 section 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if c.PostForm("grant_type") != access.OAuthGrantClientCredentials {
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+		return
+	}
+
+	clientID, clientSecret, basicAuth := c.Request.BasicAuth()
+	if !basicAuth {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "client_id and client_secret are required")
+		return
+	}
+
+	token, err := h.clientAppService.IssueClientCredentialsToken(c, clientID, clientSecret, strings.Fields(c.PostForm("scope")))
+	switch {
+	case err == nil:
+	case errors.Is(err, access.ErrInvalidClient):
+		if basicAuth {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(c, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	case errors.Is(err, access.ErrInvalidScope):
+		oauthError(c, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	case errors.Is(err, access.ErrOAuthNotConfigured):
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", err.Error())
+		return
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Str("clientID", clientID).Msg("Failed to issue access token")
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	response := core.OAuthTokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(token.ExpiresIn.Seconds()),
+	}
+	if len(token.Scopes) > 0 {
+		scope := strings.Join(token.Scopes, " ")
+		response.Scope = &scope
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// IntrospectOAuthToken is the OAuth2 token introspection endpoint (RFC 7662).
+// Tokens the caller may not see are reported inactive, like unknown ones, so
+// the response never tells a token of another tenant apart from a bad one.
+// (POST /public-api/v1/token/introspect)
+func (h *ClientApplicationHandler) IntrospectOAuthToken(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	clientID, clientSecret, basicAuth := c.Request.BasicAuth()
+	if !basicAuth {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "client_id and client_secret are required")
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	introspection, err := h.clientAppService.IntrospectToken(c, clientID, clientSecret, token)
+	switch {
+	case err == nil:
+	case errors.Is(err, access.ErrInvalidClient):
+		if basicAuth {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(c, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Str("clientID", clientID).Msg("Failed to introspect token")
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	response := core.TokenIntrospectionResponse{Active: introspection.Active}
+	if introspection.Active {
+		tokenType := core.TokenIntrospectionResponseTokenType(introspection.TokenType)
+		scope := strings.Join(introspection.Scopes, " ")
+		exp := introspection.ExpiresAt.Unix()
+		iat := introspection.IssuedAt.Unix()
+		response.TokenType = &tokenType
+		response.ClientId = &introspection.ClientID
+		response.Sub = &introspection.Subject
+		response.Scope = &scope
+		response.Exp = &exp
+		response.Iat = &iat
+		if introspection.TenantID != "" {
+			response.TenantId = &introspection.TenantID
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func oauthError(c *gin.Context, status int, code string, description string) {
+	response := core.OAuthError{Error: code}
+	if description != "" {
+		response.ErrorDescription = &description
+	}
+	c.JSON(status, response)
+}
+
+// ListAPITokens lists API tokens for a client application
+func (h *ClientApplicationHandler) ListAPITokens(c *gin.Context, id uuid.UUID, params core.ListAPITokensParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	// Only super admins can access this endpoint
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	// Set up paging parameters
+	pagingRequest := helpers.PagingRequest{
+		MaxPageSize:     50,
+		DefaultPage:     1,
+		DefaultPageSize: 10,
+		DefaultSortBy:   "created_at",
+		DefaultOrder:    "desc",
+		Page:            params.Page,
+		PageSize:        params.PageSize,
+		SortBy:          params.SortBy,
+		Order:           (*string)(params.Order),
+	}
+
+	pagingSql := helpers.GetPagingSQL(pagingRequest)
+
+	// Check for optional parameters
+	includeRevoked := false
+	if params.IncludeRevoked != nil {
+		includeRevoked = *params.IncludeRevoked
+	}
+
+	includeExpired := false
+	if params.IncludeExpired != nil {
+		includeExpired = *params.IncludeExpired
+	}
+
+	// List tokens (scoped to the caller's tenant; empty for global)
+	tokens, err := h.clientAppService.ListAPITokens(
+		c,
+		&id,
+		c.GetString(auth.AUTH_TENANT_ID_KEY),
+		pagingSql.PageSize,
+		pagingSql.Offset,
+		pagingSql.SortBy,
+		pagingSql.Order,
+		includeRevoked,
+		includeExpired,
+	)
+
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to list API tokens")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	total, err := h.clientAppService.CountAPITokens(c, &id, c.GetString(auth.AUTH_TENANT_ID_KEY), includeRevoked, includeExpired)
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to count API tokens")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	helpers.SetPagingHeaders(c, total, pagingSql)
+
+	// Convert results to API model
+	result := make([]core.APIToken, len(tokens))
+	for i, token := range tokens {
+		result[i] = toAPIToken(token)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateAPIToken creates a new API token for a client application
+func (h *ClientApplicationHandler) CreateAPIToken(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+
+	// Only super admins can access this endpoint
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// Parse request body
+	var req core.CreateAPITokenJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to bind JSON for API token creation")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Prepare parameters
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	// Calculate expiry days from timestamp
+	expiresAt := req.ExpiresAt
+
+	// Calculate days from now
+	expiryDays := int(time.Until(expiresAt).Hours() / 24)
+
+	// Handle scopes
+	var scopes []string
+	if req.Scopes != nil {
+		scopes = *req.Scopes
+	}
+
+	limits := access.APITokenLimits{
+		RateLimitPerMinute: req.RateLimitPerMinute,
+		RateLimitPerHour:   req.RateLimitPerHour,
+	}
+	if req.AllowedCidrs != nil {
+		limits.AllowedCIDRs = *req.AllowedCidrs
+	}
+
+	// Create API token (scoped to the caller's tenant; empty for global), with
+	// its limits in the same insert. The service rejects the request if the
+	// client application is out of scope.
+	var token string
+	var apiToken repository.CoreApiToken
+	var err error
+	if req.ScopeTemplateId != nil {
+		if len(scopes) > 0 {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("scopes and scopeTemplateId are exclusive")))
+			return
+		}
+		token, apiToken, err = h.clientAppService.CreateAPITokenFromScopeTemplate(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY),
+			req.Name, description, expiryDays, userID.(string), *req.ScopeTemplateId, limits)
+	} else {
+		token, apiToken, err = h.clientAppService.CreateAPITokenWithLimits(
+			c,
+			id,
+			c.GetString(auth.AUTH_TENANT_ID_KEY),
+			req.Name,
+			description,
+			expiryDays,
+			userID.(string),
+			scopes,
+			limits,
+		)
+	}
+
+	if err != nil {
+		if errors.Is(err, access.ErrTokenQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
+		if errors.Is(err, access.ErrTokenPolicyViolation) || errors.Is(err, access.ErrInvalidAPITokenRateLimit) ||
+			errors.Is(err, access.ErrInvalidCIDR) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("userID", userID.(string)).Str("appID", id.String()).Msg("Failed to create API token")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	access.RecordUsage(c, c.GetString(auth.AUTH_TENANT_ID_KEY), access.UsageMetricAPITokensCreated, 1)
+
+	// Return the token and API token
+	c.JSON(http.StatusCreated, toAPITokenCreated(token, apiToken))
+}
+
+// SetClientApplicationTokenQuota sets the maximum number of active tokens of
+// any client application (SUPER_ADMIN only)
+func (h *ClientApplicationHandler) SetClientApplicationTokenQuota(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := auth.Authorize(c, auth.OpSetClientApplicationQuotas); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	var req core.SetClientApplicationTokenQuotaJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	app, err := h.clientAppService.SetClientApplicationTokenQuota(c, id, req.MaxActiveTokens)
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrInvalidTokenQuota):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case err.Error() == pgx.ErrNoRows.Error():
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	logger.Info().Str("clientApplicationID", id.String()).Interface("maxActiveTokens", req.MaxActiveTokens).Msg("Client application token quota updated")
+	c.JSON(http.StatusOK, toAPIClientApplication(app))
+}
+
+// UpdateAPIToken narrows the scopes of an API token and optionally brings
+// its expiry forward, without reissuing its value
+func (h *ClientApplicationHandler) UpdateAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req core.UpdateAPITokenJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	token, err := h.clientAppService.UpdateAPIToken(c, id, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY),
+		req.Scopes, req.ExpiresAt, userID.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrTokenScopesNotNarrowed), errors.Is(err, access.ErrTokenExpiryNotShortened):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to update API token")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+
+	logger.Info().Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Strs("scopes", token.Scopes).Msg("API token updated")
+	c.JSON(http.StatusOK, toAPITokenSingle(token))
+}
+
+// UpdateAPITokenIPAllowlist replaces the client IP allowlist of an API token
+func (h *ClientApplicationHandler) UpdateAPITokenIPAllowlist(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req core.UpdateAPITokenIPAllowlistJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	allowedCidrs, err := access.NormalizeAllowedCIDRs(req.AllowedCidrs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Verify token exists and belongs to the client application (scoped to tenant)
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, tenantID)
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to get API token for IP allowlist update")
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if token.ClientApplicationID != id {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(pgx.ErrNoRows))
+		return
+	}
+
+	if _, err := h.clientAppService.SetAPITokenAllowedCIDRs(c, tokenId, allowedCidrs); err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to update API token IP allowlist")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	token.AllowedCidrs = allowedCidrs
+
+	c.JSON(http.StatusOK, toAPITokenSingle(token))
+}
+
+// GetAPITokenById retrieves an API token by ID
+func (h *ClientApplicationHandler) GetAPITokenById(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	// Only super admins can access this endpoint
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// Get token (scoped to the caller's tenant; empty for global)
+	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", id.String()).Msg("Failed to get API token")
+
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	if token.ClientApplicationID != id {
+		logger.Error().Msg("API token does not belong to the specified client application")
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPITokenSingle(token))
+}
+
+// DeleteAPIToken deletes an API token
+func (h *ClientApplicationHandler) DeleteAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	// Only super admins can access this endpoint
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	// Verify token exists and belongs to the client application (scoped to tenant)
+	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to get API token for deletion")
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	if token.ClientApplicationID != id {
+		logger.Error().Msg("API token does not belong to the specified client application")
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Delete token
+	err = h.clientAppService.DeleteAPIToken(c, tokenId)
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to delete API token")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAPIToken revokes an API token
+func (h *ClientApplicationHandler) RevokeAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	// Only super admins can access this endpoint
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// Verify token exists and belongs to the client application (scoped to tenant)
+	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", id.String()).Msg("Failed to get API token for revocation")
+
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if token.ClientApplicationID != id {
+		logger.Error().Msg("API token does not belong to the specified client application")
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Parse request body
+	var req core.RevokeAPITokenJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to bind JSON for API token revocation")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Set revocation reason
+	reason := req.Reason
+
+	// Revoke token (scoped to the caller's tenant; empty for global)
+	revokedToken, err := h.clientAppService.RevokeAPIToken(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY), reason, userID.(string))
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to revoke API token")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Convert to API model
+	apiToken := core.APIToken{
+		Id:                  revokedToken.ID,
+		ClientApplicationId: revokedToken.ClientApplicationID,
+		Name:                revokedToken.Name,
+		TokenPrefix:         revokedToken.TokenPrefix,
+		ExpiresAt:           revokedToken.ExpiresAt,
 		Revoked:             revokedToken.Revoked,
 		CreatedBy:           revokedToken.CreatedBy,
 		CreatedAt:           revokedToken.CreatedAt,
@@ -708,6 +1528,69 @@ func (h *ClientApplicationHandler) RevokeAPIToken(c *gin.Context, id uuid.UUID,
 	c.JSON(http.StatusOK, apiToken)
 }
 
+// RevokeAllClientApplicationTokens revokes every active API token of a client
+// application with a shared reason
+// (POST /admin-api/v1/client-applications/{id}/tokens/revoke-all)
+func (h *ClientApplicationHandler) RevokeAllClientApplicationTokens(c *gin.Context, id openapi_types.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+
+	// Verify the client application is in the caller's scope
+	if _, err := h.clientAppService.GetClientApplicationByID(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY)); err != nil {
+		logger.Err(err).Str("id", id.String()).Msg("Failed to get client application for bulk token revocation")
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	h.revokeAllAPITokens(c, &id)
+}
+
+// RevokeAllAPITokens revokes every active API token of the client applications
+// in the caller's scope, for incident response
+// (POST /admin-api/v1/api-tokens/revoke-all)
+func (h *ClientApplicationHandler) RevokeAllAPITokens(c *gin.Context) {
+	h.revokeAllAPITokens(c, nil)
+}
+
+func (h *ClientApplicationHandler) revokeAllAPITokens(c *gin.Context, clientApplicationID *uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		logger.Error().Msg("User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req core.RevokeAllAPITokensJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Msg("Failed to bind JSON for bulk API token revocation")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "A revocation reason is required",
+		})
+		return
+	}
+
+	tokenIDs, err := h.clientAppService.RevokeAllAPITokens(c, clientApplicationID, c.GetString(auth.AUTH_TENANT_ID_KEY), reason, userID.(string))
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Msg("Failed to revoke API tokens in bulk")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, core.APITokenBulkRevokeResult{
+		RevokedCount: len(tokenIDs),
+		TokenIds:     tokenIDs,
+	})
+}
+
 // GetAPITokenAuditLogs retrieves audit logs for an API token
 func (h *ClientApplicationHandler) GetAPITokenAuditLogs(c *gin.Context, id uuid.UUID, tokenId uuid.UUID, params core.GetAPITokenAuditLogsParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
@@ -750,8 +1633,23 @@ func (h *ClientApplicationHandler) GetAPITokenAuditLogs(c *gin.Context, id uuid.
 
 	offset := (page - 1) * pageSize
 
+	filter := access.APITokenAuditFilter{}
+	if params.Action != nil {
+		filter.Action = *params.Action
+	}
+	if params.From != nil {
+		filter.From = *params.From
+	}
+	if params.To != nil {
+		filter.To = *params.To
+	}
+
 	// Get audit logs
-	logs, err := h.clientAppService.GetAPITokenAuditLogs(c, tokenId, pageSize, offset)
+	logs, err := h.clientAppService.GetAPITokenAuditLogs(c, tokenId, filter, pageSize, offset)
+	if errors.Is(err, access.ErrInvalidAuditFilter) {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
 	if err != nil {
 		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to get API token audit logs")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
@@ -766,3 +1664,121 @@ func (h *ClientApplicationHandler) GetAPITokenAuditLogs(c *gin.Context, id uuid.
 
 	c.JSON(http.StatusOK, result)
 }
+
+// ExportAPITokenAuditLogs streams every audit log entry of an API token as
+// CSV or NDJSON
+func (h *ClientApplicationHandler) ExportAPITokenAuditLogs(c *gin.Context, id uuid.UUID, tokenId uuid.UUID, params core.ExportAPITokenAuditLogsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+
+	requested := ""
+	if params.Format != nil {
+		requested = *params.Format
+	}
+	format, err := access.APITokenAuditExportFormat(requested)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Verify token exists and belongs to the client application (scoped to tenant)
+	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("tokenID", tokenId.String()).Msg("Failed to get API token for audit export")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if token.ClientApplicationID != id {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(pgx.ErrNoRows))
+		return
+	}
+
+	// The status is sent with the first page: a later failure can only cut
+	// the stream short
+	c.Header("Content-Type", access.APITokenAuditExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "api-token-"+token.TokenPrefix+"-audit."+format))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	written, err := h.clientAppService.ExportAPITokenAuditLogs(c, tokenId, format, c.Writer)
+	if err != nil {
+		logger.Err(err).Str("tokenID", tokenId.String()).Int64("entries", written).Msg("API token audit export interrupted")
+		return
+	}
+	logger.Info().Str("tokenID", tokenId.String()).Int64("entries", written).Msg("Exported API token audit logs")
+}
+
+// GetAPITokenUsage returns request counts per day, unique IPs and top endpoints
+// of an API token over a date range
+func (h *ClientApplicationHandler) GetAPITokenUsage(c *gin.Context, id uuid.UUID, tokenId uuid.UUID, params core.GetAPITokenUsageParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// Verify token exists and belongs to the client application (scoped to tenant)
+	token, err := h.clientAppService.GetAPITokenByID(c, tokenId, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to get API token for usage")
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	if token.ClientApplicationID != id {
+		logger.Error().Msg("API token does not belong to the specified client application")
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(pgx.ErrNoRows))
+		return
+	}
+
+	to := time.Now()
+	if params.To != nil {
+		to = *params.To
+	}
+	from := to.AddDate(0, 0, -30)
+	if params.From != nil {
+		from = *params.From
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("from must be before to")))
+		return
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("date range cannot exceed one year")))
+		return
+	}
+
+	topEndpoints := int32(10)
+	if params.TopEndpoints != nil && *params.TopEndpoints > 0 {
+		topEndpoints = min(*params.TopEndpoints, 100)
+	}
+
+	usage, err := h.clientAppService.GetAPITokenUsage(c, tokenId, from, to, topEndpoints)
+	if err != nil {
+		logger.Err(err).Str("userID", userID.(string)).Str("tokenID", tokenId.String()).Msg("Failed to get API token usage")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPITokenUsage(usage))
+}
+
+// ListScopes lists the scopes that can be granted to client applications and
+// API tokens
+// (GET /admin-api/v1/scopes)
+func (h *ClientApplicationHandler) ListScopes(c *gin.Context) {
+	catalog := access.ScopeCatalog()
+	scopes := make([]core.TokenScope, 0, len(catalog))
+	for _, scope := range catalog {
+		scopes = append(scopes, core.TokenScope{Name: scope.Name, Description: scope.Description})
+	}
+	c.JSON(http.StatusOK, scopes)
+}
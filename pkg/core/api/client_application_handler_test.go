@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	core "ctoup.com/coreapp/api/openapi/core"
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetClientApplicationTokenQuotaRequiresSuperAdmin(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := access.NewClientApplicationService(store)
+	h := NewClientApplicationHandler(store, service)
+
+	tenantID := commontestutils.RandomString(10)
+	app, err := service.CreateClientApplication(context.Background(), tenantID, "quota-app", "", "admin-"+tenantID)
+	require.NoError(t, err)
+	quota := int32(5)
+	body := core.SetClientApplicationTokenQuotaJSONRequestBody{MaxActiveTokens: &quota}
+
+	for _, claims := range []map[string]interface{}{
+		{string(core.CUSTOMERADMIN): true},
+		{string(core.ADMIN): true},
+		{},
+	} {
+		c, w := tenantAdminRequest(t, tenantID, http.MethodPut, body)
+		c.Set(auth.AUTH_CLAIMS, claims)
+		h.SetClientApplicationTokenQuota(c, app.ID)
+		require.Equal(t, http.StatusForbidden, w.Code, claims)
+	}
+	unchanged, err := service.GetClientApplicationByID(context.Background(), app.ID, tenantID)
+	require.NoError(t, err)
+	require.False(t, unchanged.MaxActiveTokens.Valid)
+
+	c, w := tenantAdminRequest(t, tenantID, http.MethodPut, body)
+	c.Set(auth.AUTH_CLAIMS, map[string]interface{}{string(core.SUPERADMIN): true})
+	h.SetClientApplicationTokenQuota(c, app.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated core.ClientApplication
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	require.Equal(t, &quota, updated.MaxActiveTokens)
+}
+
+func TestGetAPITokenUsage(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := access.NewClientApplicationService(store)
+	h := NewClientApplicationHandler(store, service)
+
+	tenantID := commontestutils.RandomString(10)
+	ctx, _ := tenantAdminRequest(t, tenantID, http.MethodPost, nil)
+	app, err := service.CreateClientApplication(ctx, tenantID, "usage-app", "", "admin-"+tenantID)
+	require.NoError(t, err)
+	_, token, err := service.CreateAPIToken(ctx, app.ID, tenantID, "usage-token", "", 30, "admin-"+tenantID, nil)
+	require.NoError(t, err)
+	otherApp, err := service.CreateClientApplication(ctx, tenantID, "other-app", "", "admin-"+tenantID)
+	require.NoError(t, err)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	t.Run("range", func(t *testing.T) {
+		c, w := tenantAdminRequest(t, tenantID, http.MethodGet, nil)
+		h.GetAPITokenUsage(c, app.ID, token.ID, core.GetAPITokenUsageParams{From: &from, To: &to})
+		require.Equal(t, http.StatusOK, w.Code)
+		var usage core.APITokenUsage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+		require.True(t, from.Equal(usage.From))
+		require.True(t, to.Equal(usage.To))
+		require.Zero(t, usage.TotalRequests)
+		require.Empty(t, usage.Daily)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		overAYear := from.AddDate(1, 0, 2)
+		for name, params := range map[string]core.GetAPITokenUsageParams{
+			"to before from": {From: &to, To: &from},
+			"empty":          {From: &from, To: &from},
+			"over a year":    {From: &from, To: &overAYear},
+		} {
+			c, w := tenantAdminRequest(t, tenantID, http.MethodGet, nil)
+			h.GetAPITokenUsage(c, app.ID, token.ID, params)
+			require.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+	})
+
+	t.Run("token of another application", func(t *testing.T) {
+		c, w := tenantAdminRequest(t, tenantID, http.MethodGet, nil)
+		h.GetAPITokenUsage(c, otherApp.ID, token.ID, core.GetAPITokenUsageParams{From: &from, To: &to})
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
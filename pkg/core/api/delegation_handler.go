@@ -0,0 +1,132 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// DelegationHandler handles the delegations of operations between the users
+// of the request tenant
+type DelegationHandler struct {
+	delegationService *access.PermissionDelegationService
+}
+
+func NewDelegationHandler(store *db.Store) *DelegationHandler {
+	return &DelegationHandler{
+		delegationService: access.NewPermissionDelegationService(store),
+	}
+}
+
+func toAPIDelegation(delegation repository.CorePermissionDelegation, now time.Time) core.PermissionDelegation {
+	return core.PermissionDelegation{
+		Id:          delegation.ID,
+		DelegatorId: delegation.DelegatorID,
+		DelegateId:  delegation.DelegateID,
+		Operations:  delegation.Operations,
+		Reason:      util.FromNullableText(delegation.Reason),
+		StartsAt:    delegation.StartsAt,
+		EndsAt:      delegation.EndsAt,
+		Status:      core.PermissionDelegationStatus(access.DelegationStatus(delegation, now)),
+		CreatedAt:   delegation.CreatedAt,
+		RevokedAt:   util.FromNullableTimestamptz(delegation.RevokedAt),
+		RevokedBy:   util.FromNullableText(delegation.RevokedBy),
+	}
+}
+
+// ListDelegations lists the delegations of the caller, or of the whole tenant
+// (GET /api/v1/delegations)
+func (h *DelegationHandler) ListDelegations(c *gin.Context, params core.ListDelegationsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	allUsers := params.All != nil && *params.All
+	if allUsers {
+		if err := auth.Authorize(c, auth.OpManageDelegations); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  http.StatusForbidden,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+	includeExpired := params.IncludeExpired != nil && *params.IncludeExpired
+
+	delegations, err := h.delegationService.ListDelegations(c, c.GetString(auth.AUTH_TENANT_ID_KEY),
+		c.GetString(auth.AUTH_USER_ID), allUsers, includeExpired)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list delegations")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	now := time.Now()
+	result := make([]core.PermissionDelegation, len(delegations))
+	for i, delegation := range delegations {
+		result[i] = toAPIDelegation(delegation, now)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateDelegation delegates operations of the caller to another user
+// (POST /api/v1/delegations)
+func (h *DelegationHandler) CreateDelegation(c *gin.Context) {
+	var req core.CreateDelegationJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	input := access.DelegationInput{
+		DelegateID: req.DelegateId,
+		Operations: req.Operations,
+		EndsAt:     req.EndsAt,
+	}
+	if req.StartsAt != nil {
+		input.StartsAt = *req.StartsAt
+	}
+	if req.Reason != nil {
+		input.Reason = *req.Reason
+	}
+
+	delegation, err := h.delegationService.CreateDelegation(c, input)
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidDelegation) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, toAPIDelegation(delegation, time.Now()))
+}
+
+// RevokeDelegation ends a delegation right away
+// (DELETE /api/v1/delegations/{id})
+func (h *DelegationHandler) RevokeDelegation(c *gin.Context, id openapi_types.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	delegation, err := h.delegationService.RevokeDelegation(c, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrDelegationNotOwned):
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  http.StatusForbidden,
+				"message": err.Error(),
+			})
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Str("id", id.String()).Msg("Failed to revoke delegation")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toAPIDelegation(delegation, time.Now()))
+}
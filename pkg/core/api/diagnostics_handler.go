@@ -0,0 +1,208 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DiagnosticsHandler serves the database diagnostics of the platform, the
+// replay bundles of the failing requests, the results of the tenant
+// isolation probes and the support bundles (/superadmin-api/v1/diagnostics)
+type DiagnosticsHandler struct {
+	indexAdvisor    *access.IndexAdvisorService
+	config          access.IndexAdvisorConfig
+	replayCapture   *access.ReplayCaptureService
+	isolationProbes *access.TenantIsolationProbeService
+	supportBundle   *access.SupportBundleService
+}
+
+func NewDiagnosticsHandler(store *db.Store) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		indexAdvisor:  access.NewIndexAdvisorService(store),
+		config:        access.IndexAdvisorConfigFromEnv(),
+		replayCapture: access.NewReplayCaptureService(store, access.ReplayCaptureConfigFromEnv()),
+		// The results only, the probes run from the server
+		isolationProbes: access.NewTenantIsolationProbeService(store, nil, access.TenantIsolationProbeConfigFromEnv()),
+		supportBundle:   access.NewSupportBundleService(store),
+	}
+}
+
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// (GET /superadmin-api/v1/diagnostics/index-recommendations)
+func (h *DiagnosticsHandler) GetIndexRecommendations(c *gin.Context, params core.GetIndexRecommendationsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	minCalls := h.config.MinCalls
+	if params.MinCalls != nil && *params.MinCalls >= 0 {
+		minCalls = *params.MinCalls
+	}
+
+	report, err := h.indexAdvisor.Analyze(c, minCalls)
+	if err != nil {
+		if errors.Is(err, access.ErrStatStatementsUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, helpers.ErrorResponse(access.ErrStatStatementsUnavailable))
+			return
+		}
+		logger.Err(err).Msg("Failed to analyze indexes")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	recommendations := make([]core.IndexRecommendation, len(report.Recommendations))
+	for i, r := range report.Recommendations {
+		recommendations[i] = core.IndexRecommendation{
+			Table:           r.Table,
+			Columns:         r.Columns,
+			Statement:       r.Statement,
+			Statements:      r.Statements,
+			Calls:           r.Calls,
+			TotalExecTimeMs: durationToMs(r.TotalExecTime),
+			MeanExecTimeMs:  durationToMs(r.MeanExecTime),
+			SampleQuery:     r.SampleQuery,
+		}
+	}
+	c.JSON(http.StatusOK, core.IndexAdvisorReport{
+		AnalyzedAt:      report.AnalyzedAt,
+		Statements:      report.Statements,
+		Recommendations: recommendations,
+	})
+}
+
+// (GET /superadmin-api/v1/diagnostics/replay-bundles)
+func (h *DiagnosticsHandler) ListReplayBundles(c *gin.Context, params core.ListReplayBundlesParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var tenantID, requestID string
+	if params.TenantId != nil {
+		tenantID = *params.TenantId
+	}
+	if params.RequestId != nil {
+		requestID = *params.RequestId
+	}
+	var limit, offset int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	rows, err := h.replayCapture.ListReplayBundles(c, tenantID, requestID, limit, offset)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list replay bundles")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	bundles := make([]core.ReplayBundleSummary, len(rows))
+	for i, row := range rows {
+		bundles[i] = core.ReplayBundleSummary{
+			Id:        row.ID,
+			TenantId:  row.TenantID,
+			RequestId: row.RequestID,
+			UserId:    util.FromNullableText(row.UserID),
+			Method:    row.Method,
+			Route:     row.Route,
+			Status:    int(row.Status),
+			CreatedAt: row.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, bundles)
+}
+
+// (GET /superadmin-api/v1/diagnostics/replay-bundles/{id})
+func (h *DiagnosticsHandler) GetReplayBundle(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	row, err := h.replayCapture.GetReplayBundle(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get replay bundle")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	var bundle map[string]interface{}
+	if err := json.Unmarshal(row.Bundle, &bundle); err != nil {
+		logger.Err(err).Msg("Failed to decode replay bundle")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, core.ReplayBundle{
+		Id:        row.ID,
+		TenantId:  row.TenantID,
+		RequestId: row.RequestID,
+		UserId:    util.FromNullableText(row.UserID),
+		Method:    row.Method,
+		Route:     row.Route,
+		Status:    int(row.Status),
+		CreatedAt: row.CreatedAt,
+		Bundle:    bundle,
+	})
+}
+
+// (GET /superadmin-api/v1/diagnostics/tenant-isolation-probes)
+func (h *DiagnosticsHandler) ListTenantIsolationProbeResults(c *gin.Context, params core.ListTenantIsolationProbeResultsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var outcome string
+	if params.Outcome != nil {
+		outcome = string(*params.Outcome)
+	}
+	var limit, offset int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	rows, err := h.isolationProbes.ListResults(c, outcome, limit, offset)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list tenant isolation probe results")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	results := make([]core.TenantIsolationProbeResult, len(rows))
+	for i, row := range rows {
+		results[i] = core.TenantIsolationProbeResult{
+			Id:               row.ID,
+			RunId:            row.RunID,
+			Probe:            row.Probe,
+			AttackerTenantId: row.AttackerTenantID,
+			VictimTenantId:   row.VictimTenantID,
+			ResourceId:       row.ResourceID,
+			Outcome:          core.TenantIsolationProbeResultOutcome(row.Outcome),
+			StatusCode:       int(row.StatusCode),
+			Detail:           row.Detail,
+			CreatedAt:        row.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// (GET /superadmin-api/v1/diagnostics/support-bundle)
+func (h *DiagnosticsHandler) GetSupportBundle(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	actorID := c.GetString(auth.AUTH_USER_ID)
+	bundle, name, err := h.supportBundle.Build(c, actorID)
+	if err != nil {
+		logger.Err(err).Msg("Failed to build support bundle")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	logger.Info().Str("actor_id", actorID).Int("size", len(bundle)).Msg("Support bundle downloaded")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", bundle)
+}
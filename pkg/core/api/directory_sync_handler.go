@@ -0,0 +1,278 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DirectorySyncHandler serves the directory connection of the tenant and the
+// report of its syncs (/api/v1/tenant/directory-sync)
+type DirectorySyncHandler struct {
+	directorySyncService *access.DirectorySyncService
+}
+
+func NewDirectorySyncHandler(store *db.Store, authClientPool auth.AuthClientPool) *DirectorySyncHandler {
+	return &DirectorySyncHandler{
+		directorySyncService: access.NewDirectorySyncService(store, authClientPool,
+			access.NewSharedUserService(store, authClientPool), access.DirectorySyncConfigFromEnv()),
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func toAPIDirectoryRoles(roles []string) []core.Role {
+	result := make([]core.Role, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, core.Role(role))
+	}
+	return result
+}
+
+func fromAPIDirectoryRoles(roles []core.Role) []string {
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, string(role))
+	}
+	return result
+}
+
+func toAPIDirectoryConnection(conn repository.CoreDirectoryConnection) (core.DirectoryConnection, error) {
+	settings, rules, err := access.DecodeDirectoryConnection(conn)
+	if err != nil {
+		return core.DirectoryConnection{}, err
+	}
+	result := core.DirectoryConnection{
+		Provider:          core.DirectoryProvider(conn.Provider),
+		Enabled:           conn.Enabled,
+		DefaultRoles:      toAPIDirectoryRoles(conn.DefaultRoles),
+		DeprovisionAction: conn.DeprovisionAction,
+		RoleRules:         make([]core.DirectoryRoleRule, 0, len(rules)),
+		Settings: core.DirectorySettings{
+			Customer:    optionalString(settings.Customer),
+			Domain:      optionalString(settings.Domain),
+			AdminEmail:  optionalString(settings.AdminEmail),
+			DirectoryId: optionalString(settings.DirectoryID),
+			ClientId:    optionalString(settings.ClientID),
+		},
+		CreatedBy: conn.CreatedBy,
+		CreatedAt: conn.CreatedAt,
+		UpdatedAt: conn.UpdatedAt,
+	}
+	for _, rule := range rules {
+		result.RoleRules = append(result.RoleRules, core.DirectoryRoleRule{Group: rule.Group, Roles: toAPIDirectoryRoles(rule.Roles)})
+	}
+	if conn.LastSyncStatus.Valid {
+		result.LastSyncStatus = &conn.LastSyncStatus.String
+	}
+	if conn.LastSyncStartedAt.Valid {
+		result.LastSyncStartedAt = &conn.LastSyncStartedAt.Time
+	}
+	if conn.LastSyncFinishedAt.Valid {
+		result.LastSyncFinishedAt = &conn.LastSyncFinishedAt.Time
+	}
+	if conn.LastSyncError.Valid {
+		result.LastSyncError = &conn.LastSyncError.String
+	}
+	if len(conn.LastSyncStats) > 0 {
+		var stats core.DirectorySyncStats
+		if err := json.Unmarshal(conn.LastSyncStats, &stats); err != nil {
+			return core.DirectoryConnection{}, err
+		}
+		result.LastSyncStats = &stats
+	}
+	return result, nil
+}
+
+// directorySyncScope returns the tenant of the caller, or writes the error
+// response unless a CUSTOMER_ADMIN calls from a tenant
+func directorySyncScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageDirectorySync); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": err.Error(),
+		})
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "The directory sync must be managed from a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func directorySyncErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidDirectoryConnection):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrDirectoryConnectionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, access.ErrDirectorySyncRunning):
+		return http.StatusConflict
+	case errors.Is(err, access.ErrDirectorySyncNotConfigured):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *DirectorySyncHandler) writeConnection(c *gin.Context, status int, conn repository.CoreDirectoryConnection) {
+	result, err := toAPIDirectoryConnection(conn)
+	if err != nil {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg("Invalid directory connection")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(status, result)
+}
+
+// (GET /api/v1/tenant/directory-sync)
+func (h *DirectorySyncHandler) GetDirectoryConnection(c *gin.Context) {
+	tenantID, ok := directorySyncScope(c)
+	if !ok {
+		return
+	}
+	conn, err := h.directorySyncService.GetConnection(c, tenantID)
+	if err != nil {
+		c.JSON(directorySyncErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	h.writeConnection(c, http.StatusOK, conn)
+}
+
+// (PUT /api/v1/tenant/directory-sync)
+func (h *DirectorySyncHandler) SaveDirectoryConnection(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID, ok := directorySyncScope(c)
+	if !ok {
+		return
+	}
+	var req core.SaveDirectoryConnectionJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	input := access.DirectoryConnectionInput{
+		Provider: string(req.Provider),
+		Enabled:  req.Enabled,
+		Settings: access.DirectorySettings{
+			Customer:    stringValue(req.Settings.Customer),
+			Domain:      stringValue(req.Settings.Domain),
+			AdminEmail:  stringValue(req.Settings.AdminEmail),
+			DirectoryID: stringValue(req.Settings.DirectoryId),
+			ClientID:    stringValue(req.Settings.ClientId),
+		},
+		Credentials:       stringValue(req.Credentials),
+		DeprovisionAction: stringValue(req.DeprovisionAction),
+	}
+	// The sync grants the roles of the rules on behalf of the caller
+	granted := []core.Role{}
+	if req.DefaultRoles != nil {
+		granted = append(granted, *req.DefaultRoles...)
+		input.DefaultRoles = fromAPIDirectoryRoles(*req.DefaultRoles)
+	}
+	if req.RoleRules != nil {
+		for _, rule := range *req.RoleRules {
+			granted = append(granted, rule.Roles...)
+			input.RoleRules = append(input.RoleRules, access.DirectoryRoleRule{Group: rule.Group, Roles: fromAPIDirectoryRoles(rule.Roles)})
+		}
+	}
+	if err := auth.HasRightsForRoles(c, granted); err != nil {
+		logger.Err(err).Msg("Failed to check directory roles")
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+
+	conn, err := h.directorySyncService.SaveConnection(c, tenantID, c.GetString(auth.AUTH_USER_ID), input)
+	if err != nil {
+		c.JSON(directorySyncErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	h.writeConnection(c, http.StatusOK, conn)
+}
+
+// (DELETE /api/v1/tenant/directory-sync)
+func (h *DirectorySyncHandler) DeleteDirectoryConnection(c *gin.Context) {
+	tenantID, ok := directorySyncScope(c)
+	if !ok {
+		return
+	}
+	if err := h.directorySyncService.DeleteConnection(c, tenantID); err != nil {
+		c.JSON(directorySyncErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (POST /api/v1/tenant/directory-sync/run)
+func (h *DirectorySyncHandler) RunDirectorySync(c *gin.Context) {
+	tenantID, ok := directorySyncScope(c)
+	if !ok {
+		return
+	}
+	// The sync outlives the request, so it gets the request context rather
+	// than the pooled gin context
+	conn, err := h.directorySyncService.StartSync(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(directorySyncErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	h.writeConnection(c, http.StatusAccepted, conn)
+}
+
+// (GET /api/v1/tenant/directory-sync/conflicts)
+func (h *DirectorySyncHandler) ListDirectorySyncConflicts(c *gin.Context, params core.ListDirectorySyncConflictsParams) {
+	tenantID, ok := directorySyncScope(c)
+	if !ok {
+		return
+	}
+	var limit, offset int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	conflicts, err := h.directorySyncService.ListConflicts(c, tenantID, limit, offset)
+	if err != nil {
+		c.JSON(directorySyncErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.DirectorySyncConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		item := core.DirectorySyncConflict{
+			Id:         conflict.ID,
+			ExternalId: conflict.ExternalID,
+			Email:      access.DecryptEmail(pgtype.Text{String: conflict.Email, Valid: true}).String,
+			Kind:       conflict.Kind,
+			Detail:     conflict.Detail,
+			CreatedAt:  conflict.CreatedAt,
+		}
+		if conflict.UserID.Valid {
+			item.UserId = &conflict.UserID.String
+		}
+		result = append(result, item)
+	}
+	c.JSON(http.StatusOK, result)
+}
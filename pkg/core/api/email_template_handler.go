@@ -0,0 +1,156 @@
+package core
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailTemplateHandler handles the templates the request tenant sends its
+// emails with instead of the library ones
+type EmailTemplateHandler struct {
+	templateService *access.EmailTemplateService
+}
+
+func NewEmailTemplateHandler(store *db.Store) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		templateService: access.NewEmailTemplateService(store),
+	}
+}
+
+func toAPIEmailTemplate(template repository.CoreEmailTemplate) core.EmailTemplate {
+	return core.EmailTemplate{
+		Event:     template.EventType,
+		Locale:    template.Locale,
+		Subject:   template.Subject,
+		Body:      template.Body,
+		UpdatedBy: template.UpdatedBy,
+		CreatedAt: template.CreatedAt,
+		UpdatedAt: template.UpdatedAt,
+	}
+}
+
+// emailTemplateTenant checks the caller may manage the email templates and
+// returns its tenant, or writes the error response
+func emailTemplateTenant(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageEmailTemplates); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "The email templates are defined by a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func emailTemplateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidEmailTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrEmailTemplateNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeEmailTemplateError(c *gin.Context, err error, msg string) {
+	status := emailTemplateErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+	}
+	c.JSON(status, helpers.ErrorResponse(err))
+}
+
+// (GET /api/v1/tenant/email-templates)
+func (h *EmailTemplateHandler) ListEmailTemplates(c *gin.Context) {
+	tenantID, ok := emailTemplateTenant(c)
+	if !ok {
+		return
+	}
+	templates, err := h.templateService.ListTemplates(c, tenantID)
+	if err != nil {
+		writeEmailTemplateError(c, err, "Failed to list email templates")
+		return
+	}
+	events := make([]core.EmailTemplateEvent, 0, len(access.EmailTemplateVariables))
+	for _, event := range slices.Sorted(maps.Keys(access.EmailTemplateVariables)) {
+		events = append(events, core.EmailTemplateEvent{
+			Event:     event,
+			Variables: access.EmailTemplateVariables[event],
+		})
+	}
+	result := core.EmailTemplates{
+		Events:    events,
+		Templates: make([]core.EmailTemplate, len(templates)),
+	}
+	for i, template := range templates {
+		result.Templates[i] = toAPIEmailTemplate(template)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (GET /api/v1/tenant/email-templates/{event}/{locale})
+func (h *EmailTemplateHandler) GetEmailTemplate(c *gin.Context, event string, locale string) {
+	tenantID, ok := emailTemplateTenant(c)
+	if !ok {
+		return
+	}
+	template, err := h.templateService.GetTemplate(c, tenantID, event, locale)
+	if err != nil {
+		writeEmailTemplateError(c, err, "Failed to get email template")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIEmailTemplate(template))
+}
+
+// (PUT /api/v1/tenant/email-templates/{event}/{locale})
+func (h *EmailTemplateHandler) SaveEmailTemplate(c *gin.Context, event string, locale string) {
+	tenantID, ok := emailTemplateTenant(c)
+	if !ok {
+		return
+	}
+	var req core.SaveEmailTemplateJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	template, err := h.templateService.SaveTemplate(c, tenantID, event, locale, c.GetString(auth.AUTH_USER_ID), access.EmailTemplateInput{
+		Subject: req.Subject,
+		Body:    req.Body,
+	})
+	if err != nil {
+		writeEmailTemplateError(c, err, "Failed to save email template")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIEmailTemplate(template))
+}
+
+// (DELETE /api/v1/tenant/email-templates/{event}/{locale})
+func (h *EmailTemplateHandler) DeleteEmailTemplate(c *gin.Context, event string, locale string) {
+	tenantID, ok := emailTemplateTenant(c)
+	if !ok {
+		return
+	}
+	if err := h.templateService.DeleteTemplate(c, tenantID, event, locale); err != nil {
+		writeEmailTemplateError(c, err, "Failed to delete email template")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExecutionWebhookHandler handles the webhooks the results of the prompt and
+// schedule executions of the request tenant are delivered to
+type ExecutionWebhookHandler struct {
+	webhookService *access.ExecutionWebhookService
+}
+
+func NewExecutionWebhookHandler(store *db.Store) *ExecutionWebhookHandler {
+	return &ExecutionWebhookHandler{
+		webhookService: access.NewExecutionWebhookService(store),
+	}
+}
+
+func toAPIExecutionWebhook(hook repository.CoreExecutionWebhook) core.ExecutionWebhook {
+	return core.ExecutionWebhook{
+		Id:         hook.ID,
+		SourceType: hook.SourceType,
+		SourceId:   hook.SourceID,
+		Url:        hook.Url,
+		CreatedBy:  hook.CreatedBy,
+		CreatedAt:  hook.CreatedAt,
+	}
+}
+
+// executionWebhookTenant checks the caller may manage the result webhooks and
+// returns its tenant, or writes the error response
+func executionWebhookTenant(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageExecutionWebhooks); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "The result webhooks are defined by a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func executionWebhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidExecutionSource), errors.Is(err, access.ErrInvalidWebhookURL):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrRequestSigningNotConfigured):
+		return http.StatusConflict
+	case errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeExecutionWebhookError(c *gin.Context, err error, msg string) {
+	status := executionWebhookErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+	}
+	c.JSON(status, helpers.ErrorResponse(err))
+}
+
+// (GET /api/v1/tenant/execution-webhooks)
+func (h *ExecutionWebhookHandler) ListExecutionWebhooks(c *gin.Context) {
+	tenantID, ok := executionWebhookTenant(c)
+	if !ok {
+		return
+	}
+	hooks, err := h.webhookService.ListWebhooks(c, tenantID)
+	if err != nil {
+		writeExecutionWebhookError(c, err, "Failed to list execution webhooks")
+		return
+	}
+	result := make([]core.ExecutionWebhook, len(hooks))
+	for i, hook := range hooks {
+		result[i] = toAPIExecutionWebhook(hook)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (GET /api/v1/tenant/execution-webhooks/{sourceType}/{sourceId})
+func (h *ExecutionWebhookHandler) GetExecutionWebhook(c *gin.Context, sourceType string, sourceId string) {
+	tenantID, ok := executionWebhookTenant(c)
+	if !ok {
+		return
+	}
+	hook, err := h.webhookService.GetWebhook(c, tenantID, sourceType, sourceId)
+	if err != nil {
+		writeExecutionWebhookError(c, err, "Failed to get execution webhook")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIExecutionWebhook(hook))
+}
+
+// (PUT /api/v1/tenant/execution-webhooks/{sourceType}/{sourceId})
+func (h *ExecutionWebhookHandler) SetExecutionWebhook(c *gin.Context, sourceType string, sourceId string) {
+	tenantID, ok := executionWebhookTenant(c)
+	if !ok {
+		return
+	}
+	var req core.SetExecutionWebhookJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	secret, hook, err := h.webhookService.SetWebhook(c, tenantID, sourceType, sourceId, req.Url, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		writeExecutionWebhookError(c, err, "Failed to set execution webhook")
+		return
+	}
+	c.JSON(http.StatusOK, core.ExecutionWebhookCreated{
+		Id:            hook.ID,
+		SourceType:    hook.SourceType,
+		SourceId:      hook.SourceID,
+		Url:           hook.Url,
+		SigningSecret: secret,
+		CreatedBy:     hook.CreatedBy,
+		CreatedAt:     hook.CreatedAt,
+	})
+}
+
+// (DELETE /api/v1/tenant/execution-webhooks/{sourceType}/{sourceId})
+func (h *ExecutionWebhookHandler) DeleteExecutionWebhook(c *gin.Context, sourceType string, sourceId string) {
+	tenantID, ok := executionWebhookTenant(c)
+	if !ok {
+		return
+	}
+	if err := h.webhookService.DeleteWebhook(c, tenantID, sourceType, sourceId); err != nil {
+		writeExecutionWebhookError(c, err, "Failed to delete execution webhook")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagHandler exposes the feature flags, their overrides by the
+// tenants and their values for the request tenant
+type FeatureFlagHandler struct {
+	featureFlagService *access.FeatureFlagService
+}
+
+func NewFeatureFlagHandler(store *db.Store) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: access.NewFeatureFlagService(store),
+	}
+}
+
+func toAPIFeatureFlag(flag repository.CoreFeatureFlag) core.FeatureFlag {
+	return core.FeatureFlag{
+		Key:               flag.Key,
+		Description:       util.FromNullableText(flag.Description),
+		Enabled:           flag.Enabled,
+		RolloutPercentage: int(flag.RolloutPercentage),
+		UpdatedBy:         flag.UpdatedBy,
+		CreatedAt:         flag.CreatedAt,
+		UpdatedAt:         flag.UpdatedAt,
+	}
+}
+
+// (GET /api/v1/tenant/feature-flags)
+func (h *FeatureFlagHandler) GetTenantFeatureFlags(c *gin.Context) {
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The feature flags must be read from a tenant"))
+		return
+	}
+	// The middleware has evaluated them unless it failed
+	values := access.FeatureFlagsFromContext(c)
+	if values == nil {
+		var err error
+		if values, err = h.featureFlagService.FlagValues(c, tenantID); err != nil {
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, core.FeatureFlagValues(values))
+}
+
+// (GET /superadmin-api/v1/feature-flags)
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	if err := auth.Authorize(c, auth.OpManageFeatureFlags); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	flags, err := h.featureFlagService.ListFlags(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.FeatureFlag, len(flags))
+	for i, flag := range flags {
+		result[i] = toAPIFeatureFlag(flag)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (DELETE /superadmin-api/v1/feature-flags/{key})
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context, key string) {
+	if err := auth.Authorize(c, auth.OpManageFeatureFlags); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	if err := h.featureFlagService.DeleteFlag(c, key); err != nil {
+		if errors.Is(err, access.ErrFeatureFlagNotFound) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (PUT /superadmin-api/v1/feature-flags/{key})
+func (h *FeatureFlagHandler) SaveFeatureFlag(c *gin.Context, key string) {
+	if err := auth.Authorize(c, auth.OpManageFeatureFlags); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	var req core.SaveFeatureFlagJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	flag, err := h.featureFlagService.SaveFlag(c, key, req.Description, req.Enabled, req.RolloutPercentage, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidFeatureFlag) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIFeatureFlag(flag))
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/feature-flags)
+func (h *FeatureFlagHandler) ListTenantFeatureFlags(c *gin.Context, tenantid string) {
+	if err := auth.Authorize(c, auth.OpManageFeatureFlags); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	evaluations, err := h.featureFlagService.EvaluateFlags(c, tenantid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.TenantFeatureFlag, len(evaluations))
+	for i, evaluation := range evaluations {
+		result[i] = core.TenantFeatureFlag{
+			Key:      evaluation.Key,
+			Enabled:  evaluation.Enabled,
+			Source:   evaluation.Source,
+			Override: evaluation.Override,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (DELETE /superadmin-api/v1/tenants/{tenantid}/feature-flags/{key})
+func (h *FeatureFlagHandler) DeleteTenantFeatureFlagOverride(c *gin.Context, tenantid string, key string) {
+	if err := auth.Authorize(c, auth.OpManageFeatureFlags); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	if err := h.featureFlagService.DeleteOverride(c, tenantid, key); err != nil {
+		if errors.Is(err, access.ErrFeatureFlagNotFound) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (PUT /superadmin-api/v1/tenants/{tenantid}/feature-flags/{key})
+func (h *FeatureFlagHandler) SetTenantFeatureFlagOverride(c *gin.Context, tenantid string, key string) {
+	if err := auth.Authorize(c, auth.OpManageFeatureFlags); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	var req core.SetTenantFeatureFlagOverrideJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if err := h.featureFlagService.SetOverride(c, tenantid, key, req.Enabled, c.GetString(auth.AUTH_USER_ID)); err != nil {
+		if errors.Is(err, access.ErrFeatureFlagNotFound) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		if helpers.AbortIfReferenced(c, err, "tenant_not_found", "The tenant does not exist") {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
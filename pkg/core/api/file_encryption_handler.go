@@ -0,0 +1,121 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// FileEncryptionHandler enables the encryption of the files of the tenants
+// with their own key and shows where their rewrite stands
+type FileEncryptionHandler struct {
+	encryptionService *access.FileEncryptionService
+}
+
+func NewFileEncryptionHandler(store *db.Store) *FileEncryptionHandler {
+	return &FileEncryptionHandler{
+		encryptionService: access.NewFileEncryptionService(store, fileservice.NewFileService(), access.FileEncryptionConfigFromEnv()),
+	}
+}
+
+func toAPITenantFileEncryption(row repository.ListTenantFileEncryptionRow) core.TenantFileEncryption {
+	result := core.TenantFileEncryption{
+		TenantId:           row.TenantID,
+		EnabledBy:          row.EnabledBy,
+		EnabledAt:          row.EnabledAt,
+		RewriteStatus:      row.RewriteStatus,
+		RewriteRequestedAt: row.RewriteRequestedAt,
+		FilesRewritten:     row.FilesRewritten,
+		LastError:          util.FromNullableText(row.LastError),
+		KeyId:              row.KeyID,
+		KeyCreatedAt:       row.KeyCreatedAt,
+		RotatedKeys:        row.RotatedKeys,
+	}
+	if row.RewriteStartedAt.Valid {
+		result.RewriteStartedAt = &row.RewriteStartedAt.Time
+	}
+	if row.RewriteCompletedAt.Valid {
+		result.RewriteCompletedAt = &row.RewriteCompletedAt.Time
+	}
+	return result
+}
+
+func fileEncryptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrFileEncryptionNotEnabled), errors.Is(err, access.ErrFileEncryptionTenant):
+		return http.StatusNotFound
+	case errors.Is(err, access.ErrFileEncryptionNotConfigured):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeFileEncryptionError(c *gin.Context, err error, msg string) {
+	status := fileEncryptionErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+	}
+	c.JSON(status, helpers.ErrorResponse(err))
+}
+
+// (GET /superadmin-api/v1/file-encryption)
+func (h *FileEncryptionHandler) ListTenantFileEncryption(c *gin.Context, params core.ListTenantFileEncryptionParams) {
+	var limit, offset int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	rows, err := h.encryptionService.ListStatuses(c, limit, offset)
+	if err != nil {
+		writeFileEncryptionError(c, err, "Failed to list tenant file encryption")
+		return
+	}
+	result := make([]core.TenantFileEncryption, len(rows))
+	for i, row := range rows {
+		result[i] = toAPITenantFileEncryption(row)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/file-encryption)
+func (h *FileEncryptionHandler) GetTenantFileEncryption(c *gin.Context, tenantID string) {
+	status, err := h.encryptionService.Status(c, tenantID)
+	if err != nil {
+		writeFileEncryptionError(c, err, "Failed to get tenant file encryption")
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantFileEncryption(status))
+}
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/file-encryption)
+func (h *FileEncryptionHandler) EnableTenantFileEncryption(c *gin.Context, tenantID string) {
+	status, err := h.encryptionService.EnableTenant(c, tenantID, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		writeFileEncryptionError(c, err, "Failed to enable tenant file encryption")
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantFileEncryption(status))
+}
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/file-encryption/rotate)
+func (h *FileEncryptionHandler) RotateTenantFileKey(c *gin.Context, tenantID string) {
+	status, err := h.encryptionService.RotateTenantKey(c, tenantID, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		writeFileEncryptionError(c, err, "Failed to rotate tenant file key")
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantFileEncryption(status))
+}
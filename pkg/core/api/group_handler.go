@@ -0,0 +1,270 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// GroupHandler handles the groups of users of the request tenant, whose
+// roles are granted to their members
+type GroupHandler struct {
+	groupService *access.GroupService
+}
+
+func NewGroupHandler(store *db.Store) *GroupHandler {
+	return &GroupHandler{
+		groupService: access.NewGroupService(store),
+	}
+}
+
+func toAPIGroup(group repository.CoreGroup) core.Group {
+	return core.Group{
+		Id:          group.ID,
+		Name:        group.Name,
+		Description: util.FromNullableText(group.Description),
+		Roles:       toAPIRoles(group.Roles),
+		CreatedBy:   group.CreatedBy,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
+
+func fromAPIGroupRoles(roles []core.Role) []string {
+	result := make([]string, len(roles))
+	for i, role := range roles {
+		result[i] = string(role)
+	}
+	return result
+}
+
+// groupScope returns the tenant of the caller, or writes the error response
+// unless the caller may manage the groups
+func groupScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageGroups); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": err.Error(),
+		})
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "The groups must be managed from a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidGroup), errors.Is(err, access.ErrNotTenantMember):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrGroupNotFound), errors.Is(err, access.ErrGroupMemberNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, access.ErrGroupNameTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeGroupError(c *gin.Context, err error, msg string) {
+	status := groupErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+	}
+	c.JSON(status, helpers.ErrorResponse(err))
+}
+
+// bindGroup reads the group of the request, whose roles the caller must hold
+// the rights over
+func bindGroup(c *gin.Context) (access.GroupInput, bool) {
+	var req core.NewGroup
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return access.GroupInput{}, false
+	}
+	if err := auth.HasRightsForRoles(c, req.Roles); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return access.GroupInput{}, false
+	}
+	return access.GroupInput{
+		Name:        req.Name,
+		Description: stringValue(req.Description),
+		Roles:       fromAPIGroupRoles(req.Roles),
+	}, true
+}
+
+// (GET /api/v1/groups)
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	groups, err := h.groupService.ListGroups(c, tenantID)
+	if err != nil {
+		writeGroupError(c, err, "Failed to list groups")
+		return
+	}
+	result := make([]core.Group, len(groups))
+	for i, group := range groups {
+		result[i] = toAPIGroup(group)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (POST /api/v1/groups)
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	input, ok := bindGroup(c)
+	if !ok {
+		return
+	}
+	group, err := h.groupService.CreateGroup(c, tenantID, c.GetString(auth.AUTH_USER_ID), input)
+	if err != nil {
+		writeGroupError(c, err, "Failed to create group")
+		return
+	}
+	c.JSON(http.StatusCreated, toAPIGroup(group))
+}
+
+// (GET /api/v1/groups/{id})
+func (h *GroupHandler) GetGroup(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	group, err := h.groupService.GetGroup(c, tenantID, id)
+	if err != nil {
+		writeGroupError(c, err, "Failed to get group")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIGroup(group))
+}
+
+// (PUT /api/v1/groups/{id})
+func (h *GroupHandler) UpdateGroup(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	group, ok := h.manageableGroup(c, tenantID, id)
+	if !ok {
+		return
+	}
+	input, ok := bindGroup(c)
+	if !ok {
+		return
+	}
+	group, err := h.groupService.UpdateGroup(c, tenantID, group.ID, input)
+	if err != nil {
+		writeGroupError(c, err, "Failed to update group")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIGroup(group))
+}
+
+// (DELETE /api/v1/groups/{id})
+func (h *GroupHandler) DeleteGroup(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.manageableGroup(c, tenantID, id); !ok {
+		return
+	}
+	if err := h.groupService.DeleteGroup(c, tenantID, id); err != nil {
+		writeGroupError(c, err, "Failed to delete group")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (GET /api/v1/groups/{id}/members)
+func (h *GroupHandler) ListGroupMembers(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	members, err := h.groupService.ListMembers(c, tenantID, id)
+	if err != nil {
+		writeGroupError(c, err, "Failed to list group members")
+		return
+	}
+	result := make([]core.GroupMember, len(members))
+	for i, member := range members {
+		result[i] = core.GroupMember{
+			UserId:  member.UserID,
+			AddedBy: member.AddedBy,
+			AddedAt: member.AddedAt,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (PUT /api/v1/groups/{id}/members/{userid})
+func (h *GroupHandler) AddGroupMember(c *gin.Context, id openapi_types.UUID, userID string) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	group, ok := h.manageableGroup(c, tenantID, id)
+	if !ok {
+		return
+	}
+	if err := h.groupService.AddMember(c, group, userID, c.GetString(auth.AUTH_USER_ID)); err != nil {
+		writeGroupError(c, err, "Failed to add group member")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (DELETE /api/v1/groups/{id}/members/{userid})
+func (h *GroupHandler) RemoveGroupMember(c *gin.Context, id openapi_types.UUID, userID string) {
+	tenantID, ok := groupScope(c)
+	if !ok {
+		return
+	}
+	group, ok := h.manageableGroup(c, tenantID, id)
+	if !ok {
+		return
+	}
+	if err := h.groupService.RemoveMember(c, group, userID, c.GetString(auth.AUTH_USER_ID)); err != nil {
+		writeGroupError(c, err, "Failed to remove group member")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// manageableGroup returns the group, or writes the error response unless the
+// caller holds the rights over each of its roles: changing a group or its
+// members changes who holds them
+func (h *GroupHandler) manageableGroup(c *gin.Context, tenantID string, id openapi_types.UUID) (repository.CoreGroup, bool) {
+	group, err := h.groupService.GetGroup(c, tenantID, id)
+	if err != nil {
+		writeGroupError(c, err, "Failed to get group")
+		return repository.CoreGroup{}, false
+	}
+	if err := auth.HasRightsForRoles(c, toAPIRoles(group.Roles)); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return repository.CoreGroup{}, false
+	}
+	return group, true
+}
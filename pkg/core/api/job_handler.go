@@ -0,0 +1,158 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// JobHandler serves the events of long running operations to the clients that
+// cannot use server-sent events (/api/v1/jobs)
+type JobHandler struct {
+	jobService *access.JobService
+}
+
+func NewJobHandler(store *db.Store) *JobHandler {
+	return &JobHandler{jobService: access.NewJobService(store)}
+}
+
+func toAPIJobEvent(jobEvent repository.CoreJobEvent) core.JobEvent {
+	result := core.JobEvent{
+		Cursor:    strconv.FormatInt(jobEvent.ID, 10),
+		EventType: jobEvent.EventType,
+		Message:   jobEvent.Message,
+		Progress:  int(jobEvent.Progress),
+		CreatedAt: jobEvent.CreatedAt,
+	}
+	if jobEvent.DataType != "" {
+		dataVersion := int(jobEvent.DataVersion)
+		result.DataType = &jobEvent.DataType
+		result.DataVersion = &dataVersion
+	}
+	if len(jobEvent.Data) > 0 {
+		var data map[string]interface{}
+		if err := json.Unmarshal(jobEvent.Data, &data); err == nil {
+			result.Data = &data
+		}
+	}
+	return result
+}
+
+// visibleJob returns a job of the tenant, or writes the error response when it
+// does not exist or the caller may not see it
+func (h *JobHandler) visibleJob(c *gin.Context, tenantID string, id openapi_types.UUID) (repository.CoreJob, bool) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	job, err := h.jobService.GetJob(c, tenantID, id)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("job not found")))
+			return repository.CoreJob{}, false
+		}
+		logger.Err(err).Msg("Failed to get job")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return repository.CoreJob{}, false
+	}
+	// Jobs are visible to the user who started them and to the tenant admins
+	if job.CreatedBy != c.GetString(auth.AUTH_USER_ID) && !auth.HasAdminPrivileges(c) {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("job not found")))
+		return repository.CoreJob{}, false
+	}
+	return job, true
+}
+
+// (GET /api/v1/jobs/{id}/events)
+func (h *JobHandler) ListJobEvents(c *gin.Context, id openapi_types.UUID, params core.ListJobEventsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("tenant is required")))
+		return
+	}
+
+	var since int64
+	if params.Since != nil && *params.Since != "" {
+		var err error
+		since, err = strconv.ParseInt(*params.Since, 10, 64)
+		if err != nil || since < 0 {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("invalid cursor")))
+			return
+		}
+	}
+	wait := access.DefaultJobEventsWait
+	if params.Wait != nil {
+		wait = time.Duration(*params.Wait) * time.Second
+	}
+	var limit int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	job, ok := h.visibleJob(c, tenantID, id)
+	if !ok {
+		return
+	}
+
+	result, err := h.jobService.WaitForEvents(c.Request.Context(), job, since, limit, wait)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list job events")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	events := make([]core.JobEvent, 0, len(result.Events))
+	for _, jobEvent := range result.Events {
+		events = append(events, toAPIJobEvent(jobEvent))
+	}
+	c.JSON(http.StatusOK, core.JobEventsPage{
+		Events: events,
+		Cursor: strconv.FormatInt(result.Cursor, 10),
+		Status: result.Status,
+		Done:   result.Done,
+	})
+}
+
+// DownloadImportErrors returns the rows rejected by an import as CSV
+// (GET /api/v1/imports/{id}/errors.csv)
+func (h *JobHandler) DownloadImportErrors(c *gin.Context, id openapi_types.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("tenant is required")))
+		return
+	}
+	job, ok := h.visibleJob(c, tenantID, id)
+	if !ok {
+		return
+	}
+	if job.Kind != access.JobKindUserImport {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("import not found")))
+		return
+	}
+
+	var report bytes.Buffer
+	rows, err := h.jobService.WriteRowErrorsCSV(c, job.ID, &report)
+	if err != nil {
+		logger.Err(err).Str("jobID", job.ID.String()).Msg("Failed to build import error report")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	logger.Info().Str("jobID", job.ID.String()).Int("rows", rows).Msg("Downloaded import error report")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "import-"+job.ID.String()+"-errors.csv"))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", report.Bytes())
+}
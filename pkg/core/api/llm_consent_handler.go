@@ -0,0 +1,124 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// LLMConsentHandler manages what a tenant allows to be done with the data of
+// its prompt executions
+type LLMConsentHandler struct {
+	consentService *access.LLMConsentService
+}
+
+func NewLLMConsentHandler(store *db.Store) *LLMConsentHandler {
+	return &LLMConsentHandler{consentService: access.NewLLMConsentService(store)}
+}
+
+func toAPILLMConsentPolicy(policy repository.CoreLlmConsentPolicy) core.LLMConsentPolicy {
+	result := core.LLMConsentPolicy{
+		TenantId:       policy.TenantID,
+		StoreInputs:    policy.StoreInputs,
+		StoreOutputs:   policy.StoreOutputs,
+		AllowAnalytics: policy.AllowAnalytics,
+		UpdatedBy:      policy.UpdatedBy,
+		UpdatedAt:      policy.UpdatedAt,
+	}
+	if policy.AllowedRegions != nil {
+		result.AllowedRegions = &policy.AllowedRegions
+	}
+	return result
+}
+
+// llmConsentScope returns the tenant of the caller, or writes the error
+// response unless a tenant admin calls from a tenant
+func llmConsentScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageLLMConsent); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The LLM consent must be managed from a tenant"))
+		return "", false
+	}
+	return tenantID, true
+}
+
+// (GET /api/v1/tenant/llm-consent)
+func (h *LLMConsentHandler) GetLLMConsentPolicy(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID, ok := llmConsentScope(c)
+	if !ok {
+		return
+	}
+	policy, err := h.consentService.GetPolicy(c, tenantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to get LLM consent policy")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPILLMConsentPolicy(policy))
+}
+
+// (PUT /api/v1/tenant/llm-consent)
+func (h *LLMConsentHandler) SetLLMConsentPolicy(c *gin.Context) {
+	tenantID, ok := llmConsentScope(c)
+	if !ok {
+		return
+	}
+	var req core.SetLLMConsentPolicyJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	policy, err := h.consentService.SetPolicy(c, tenantID, access.LLMConsentPolicyInput{
+		StoreInputs:    req.StoreInputs,
+		StoreOutputs:   req.StoreOutputs,
+		AllowAnalytics: req.AllowAnalytics,
+		AllowedRegions: req.AllowedRegions,
+	}, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidLLMConsentPolicy) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPILLMConsentPolicy(policy))
+}
+
+// (DELETE /api/v1/tenant/llm-consent)
+func (h *LLMConsentHandler) DeleteLLMConsentPolicy(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID, ok := llmConsentScope(c)
+	if !ok {
+		return
+	}
+	if err := h.consentService.DeletePolicy(c, tenantID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to delete LLM consent policy")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	logger.Info().Str("tenantID", tenantID).Msg("LLM consent policy removed")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// LockoutHandler lists and lifts the lockouts of the users and client IPs
+// that failed to authenticate too often
+type LockoutHandler struct {
+	store               *db.Store
+	lockoutService      *access.LockoutService
+	userActivityService *access.UserActivityService
+}
+
+func NewLockoutHandler(store *db.Store, authProvider auth.AuthProvider) *LockoutHandler {
+	return &LockoutHandler{
+		store:               store,
+		lockoutService:      access.NewLockoutService(store, authProvider, access.LockoutConfigFromEnv()),
+		userActivityService: access.NewUserActivityService(store),
+	}
+}
+
+func toAPIAuthLockouts(rows []repository.CoreAuthLockout) []core.AuthLockout {
+	result := make([]core.AuthLockout, 0, len(rows))
+	for _, row := range rows {
+		lockout := core.AuthLockout{
+			SubjectType: row.SubjectType,
+			Subject:     row.Subject,
+			TenantId:    row.TenantID,
+			LastReason:  row.LastReason,
+			Disabled:    row.Disabled,
+			UpdatedAt:   row.UpdatedAt,
+		}
+		if row.LockedUntil.Valid {
+			lockout.LockedUntil = &row.LockedUntil.Time
+		}
+		result = append(result, lockout)
+	}
+	return result
+}
+
+func lockoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidLockoutSubject):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrLockoutNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// (GET /api/v1/tenant/lockouts)
+func (h *LockoutHandler) ListTenantLockouts(c *gin.Context, params core.ListTenantLockoutsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorStringResponse("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can list the lockouts"))
+		return
+	}
+	// The lockouts of the client IPs are not attached to a tenant
+	subjectType := access.LockoutSubjectUser
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	var limit, offset int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	rows, err := h.lockoutService.ListLockouts(c, &subjectType, &tenantID, limit, offset)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list lockouts")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIAuthLockouts(rows))
+}
+
+// (POST /api/v1/users/{userid}/unlock)
+func (h *LockoutHandler) UnlockUser(c *gin.Context, userID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorStringResponse("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can unlock users"))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+
+	// Only the members of the tenant, and none with more rights than the caller
+	user, err := h.store.GetSharedUserByTenantByID(c, repository.GetSharedUserByTenantByIDParams{
+		ID:       userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorStringResponse("User not found"))
+			return
+		}
+		logger.Err(err).Str("user_id", userID).Msg("Failed to get user")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	roles := make([]core.Role, 0, len(user.Roles)+len(user.TenantRoles))
+	for _, role := range append(user.Roles, user.TenantRoles...) {
+		roles = append(roles, core.Role(role))
+	}
+	if err := auth.HasRightsForRoles(c, roles); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+
+	if _, err := h.lockoutService.Unlock(c, access.LockoutSubjectUser, userID); err != nil {
+		status := lockoutErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Str("user_id", userID).Msg("Failed to unlock user")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+
+	logger.Info().Str("user_id", userID).Str("actor_id", c.GetString(auth.AUTH_USER_ID)).Msg("Unlocked user")
+	_ = h.userActivityService.RecordUserActivity(c, access.UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  access.ActivityCategoryLogin,
+		EventType: "account_unlocked",
+		ActorID:   c.GetString(auth.AUTH_USER_ID),
+	})
+	c.Status(http.StatusNoContent)
+}
+
+// (GET /superadmin-api/v1/lockouts)
+func (h *LockoutHandler) ListAuthLockouts(c *gin.Context, params core.ListAuthLockoutsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var limit, offset int32
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if params.Offset != nil {
+		offset = *params.Offset
+	}
+	rows, err := h.lockoutService.ListLockouts(c, params.SubjectType, params.TenantId, limit, offset)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list lockouts")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIAuthLockouts(rows))
+}
+
+// (POST /superadmin-api/v1/lockouts/unlock)
+func (h *LockoutHandler) UnlockAuthSubject(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.UnlockAuthSubjectJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if _, err := h.lockoutService.Unlock(c, req.SubjectType, req.Subject); err != nil {
+		status := lockoutErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Str("subject_type", req.SubjectType).Msg("Failed to unlock")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+	logger.Info().Str("subject_type", req.SubjectType).Str("subject", req.Subject).
+		Str("actor_id", c.GetString(auth.AUTH_USER_ID)).Msg("Unlocked auth subject")
+	c.Status(http.StatusNoContent)
+}
@@ -114,9 +114,13 @@ func resetPasswordRequest(c *gin.Context, baseAuthClient auth.AuthClient, url, t
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "Reset Password Link", "")
-	if err := r.ParseTemplateWithDomain(c, "email-reset.html", templateData); err != nil {
-		logger.Err(err).Msg("Failed to parse template for reset link")
-		return err
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
+	vars := map[string]string{"link": link, "email": toEmail}
+	if !r.ApplyTenantTemplate(c, emailservice.EventPasswordReset, vars) {
+		if err := r.ParseTemplateWithDomain(c, "email-reset.html", templateData); err != nil {
+			logger.Err(err).Msg("Failed to parse template for reset link")
+			return err
+		}
 	}
 
 	if err := r.SendEmail(); err != nil {
@@ -151,9 +155,13 @@ func sendWelcomeEmail(c *gin.Context, baseAuthClient auth.AuthClient, url, toEma
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "Welcome, Set Your Password", "")
-	if err := r.ParseTemplateWithDomain(c, "email-welcome.html", templateData); err != nil {
-		logger.Err(err).Msg("Failed to parse template for reset link")
-		return err
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
+	vars := map[string]string{"link": link, "email": toEmail}
+	if !r.ApplyTenantTemplate(c, emailservice.EventWelcome, vars) {
+		if err := r.ParseTemplateWithDomain(c, "email-welcome.html", templateData); err != nil {
+			logger.Err(err).Msg("Failed to parse template for reset link")
+			return err
+		}
 	}
 
 	if err := r.SendEmail(); err != nil {
@@ -198,6 +206,7 @@ func sendConfirmationEmail(c *gin.Context, url, toEmail string, confirmationToke
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "Please verify your email address", "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
 	if err := r.ParseTemplateWithDomain(c, "email-verification.html", templateData); err != nil {
 		logger.Err(err).Msg("Failed to parse template for email verification")
 		return err
@@ -241,6 +250,7 @@ func sendAlreadyRegisteredEmail(c *gin.Context, baseAuthClient auth.AuthClient,
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "Sign up attempt on "+tenantName, "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
 	if err := r.ParseTemplateWithDomain(c, "email-already-registered.html", templateData); err != nil {
 		logger.Err(err).Msg("Failed to parse template for already-registered email")
 		return err
@@ -271,6 +281,7 @@ func sendTenantAddedEmail(c *gin.Context, baseAuthClient auth.AuthClient, url, t
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "You've been added to "+tenantName, "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
 	if err := r.ParseTemplateWithDomain(c, "email-tenant-added.html", templateData); err != nil {
 		logger.Err(err).Msg("Failed to parse template for tenant added notification")
 		return err
@@ -308,6 +319,7 @@ func sendMagicLink(c *gin.Context, baseAuthClient auth.AuthClient, origin, toEma
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "Welcome! Access Your Account", "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
 	if err := r.ParseTemplateWithDomain(c, "email-magic-link.html", templateData); err != nil {
 		logger.Err(err).Msg("Failed to parse template for magic link")
 		return err
@@ -337,6 +349,7 @@ func sendSigninEmail(c *gin.Context, origin, toEmail string) error {
 	}
 
 	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, "Sign in to your account", "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
 	if err := r.ParseTemplateWithDomain(c, "email-signin.html", templateData); err != nil {
 		logger.Err(err).Msg("Failed to parse template for signin email")
 		return err
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// PhoneVerificationHandler confirms the phone number of the current user with
+// a code sent by SMS, through the provider selected by SMS_PROVIDER
+type PhoneVerificationHandler struct {
+	store               *db.Store
+	verificationService *access.PhoneVerificationService
+}
+
+func NewPhoneVerificationHandler(store *db.Store) *PhoneVerificationHandler {
+	return &PhoneVerificationHandler{
+		store:               store,
+		verificationService: access.NewPhoneVerificationService(store, access.SMSProviderFromEnv()),
+	}
+}
+
+// (POST /api/v1/users/me/phone-verification)
+func (h *PhoneVerificationHandler) RequestPhoneVerification(c *gin.Context) {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, helpers.ErrorStringResponse("Not authenticated"))
+		return
+	}
+	var req core.RequestPhoneVerificationJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	verification, err := h.verificationService.RequestPhoneVerification(c, userID, req.PhoneNumber)
+	if err != nil {
+		abortWithPhoneVerificationError(c, err, "Failed to send phone verification code")
+		return
+	}
+	c.JSON(http.StatusAccepted, core.PhoneVerification{
+		PhoneNumber: verification.PhoneNumber,
+		Verified:    false,
+		ExpiresAt:   &verification.ExpiresAt,
+	})
+}
+
+// (POST /api/v1/users/me/phone-verification/confirm)
+func (h *PhoneVerificationHandler) ConfirmPhoneVerification(c *gin.Context) {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, helpers.ErrorStringResponse("Not authenticated"))
+		return
+	}
+	var req core.ConfirmPhoneVerificationJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	verification, err := h.verificationService.ConfirmPhoneVerification(c, c.GetString(auth.AUTH_TENANT_ID_KEY), userID, req.Code)
+	if err != nil {
+		abortWithPhoneVerificationError(c, err, "Failed to confirm phone verification code")
+		return
+	}
+	c.JSON(http.StatusOK, core.PhoneVerification{
+		PhoneNumber: verification.PhoneNumber,
+		Verified:    true,
+	})
+}
+
+// abortWithPhoneVerificationError answers the status matching an error of the
+// phone verification service
+func abortWithPhoneVerificationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, access.ErrInvalidPhoneNumber), errors.Is(err, access.ErrInvalidPhoneVerificationCode):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrPhoneVerificationNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrPhoneVerificationRateLimited):
+		c.JSON(http.StatusTooManyRequests, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrSMSUnavailable):
+		c.JSON(http.StatusServiceUnavailable, helpers.ErrorResponse(err))
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(message)
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+}
+
+// abortIfInvalidPhoneNumber answers 400 for a phone number of a new user that
+// is not in the E.164 format
+func abortIfInvalidPhoneNumber(c *gin.Context, phoneNumber *string) bool {
+	if phoneNumber == nil || *phoneNumber == "" {
+		return false
+	}
+	if _, err := access.NormalizePhoneNumber(*phoneNumber); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// RoleHandler describes what the roles of the tenants may do, and manages the
+// custom roles of the request tenant
+type RoleHandler struct {
+	authorizer  *auth.Authorizer
+	roleService *access.RoleService
+}
+
+func NewRoleHandler(store *db.Store) *RoleHandler {
+	return &RoleHandler{
+		authorizer:  auth.DefaultAuthorizer(),
+		roleService: access.NewRoleService(store),
+	}
+}
+
+// GetPermissionMatrix returns the operations each role may perform, from the
+// rules the handlers authorize with. Any authenticated user may read it.
+// (GET /api/v1/roles/permissions)
+func (h *RoleHandler) GetPermissionMatrix(c *gin.Context) {
+	matrix := h.authorizer.PermissionMatrix()
+	result := core.PermissionMatrix{
+		Roles:      auth.MatrixRoles,
+		Strict:     h.authorizer.Strict(),
+		Operations: make([]core.OperationPermission, len(matrix)),
+	}
+	for i, permission := range matrix {
+		result.Operations[i] = core.OperationPermission{
+			Operation: string(permission.Operation),
+			Roles:     permission.Roles,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func toAPICustomRole(role access.CustomRole) core.CustomRole {
+	return core.CustomRole{
+		Id:          role.ID,
+		Name:        role.Name,
+		Description: util.FromNullableText(role.Description),
+		Permissions: role.Permissions,
+		CreatedBy:   role.UserID,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// customRoleScope returns the tenant of the caller, or writes the error
+// response unless the caller may manage the custom roles
+func customRoleScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageRoles); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The custom roles must be managed from a tenant"))
+		return "", false
+	}
+	return tenantID, true
+}
+
+func customRoleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidRole), errors.Is(err, access.ErrNotTenantMember):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrRoleNotFound), errors.Is(err, access.ErrRoleMemberNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, access.ErrRoleNameTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeCustomRoleError(c *gin.Context, err error, msg string) {
+	status := customRoleErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+	}
+	c.JSON(status, helpers.ErrorResponse(err))
+}
+
+// hasPermissions writes the error response unless the caller is allowed each
+// of the permissions, so a custom role never grants more than its author holds
+func hasPermissions(c *gin.Context, permissions []string) bool {
+	for _, permission := range permissions {
+		if err := auth.Authorize(c, auth.Operation(permission)); err != nil {
+			c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+			return false
+		}
+	}
+	return true
+}
+
+// bindCustomRole reads the custom role of the request, whose permissions the
+// caller must be allowed
+func bindCustomRole(c *gin.Context) (access.RoleInput, bool) {
+	var req core.NewCustomRole
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return access.RoleInput{}, false
+	}
+	if !hasPermissions(c, req.Permissions) {
+		return access.RoleInput{}, false
+	}
+	return access.RoleInput{
+		Name:        req.Name,
+		Description: stringValue(req.Description),
+		Permissions: req.Permissions,
+	}, true
+}
+
+// (GET /api/v1/roles)
+func (h *RoleHandler) ListCustomRoles(c *gin.Context) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	roles, err := h.roleService.ListRoles(c, tenantID)
+	if err != nil {
+		writeCustomRoleError(c, err, "Failed to list custom roles")
+		return
+	}
+	result := make([]core.CustomRole, len(roles))
+	for i, role := range roles {
+		result[i] = toAPICustomRole(role)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (POST /api/v1/roles)
+func (h *RoleHandler) CreateCustomRole(c *gin.Context) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	input, ok := bindCustomRole(c)
+	if !ok {
+		return
+	}
+	role, err := h.roleService.CreateRole(c, tenantID, c.GetString(auth.AUTH_USER_ID), input)
+	if err != nil {
+		writeCustomRoleError(c, err, "Failed to create custom role")
+		return
+	}
+	c.JSON(http.StatusCreated, toAPICustomRole(role))
+}
+
+// (GET /api/v1/roles/{id})
+func (h *RoleHandler) GetCustomRole(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	role, err := h.roleService.GetRole(c, tenantID, id)
+	if err != nil {
+		writeCustomRoleError(c, err, "Failed to get custom role")
+		return
+	}
+	c.JSON(http.StatusOK, toAPICustomRole(role))
+}
+
+// (PUT /api/v1/roles/{id})
+func (h *RoleHandler) UpdateCustomRole(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.manageableRole(c, tenantID, id); !ok {
+		return
+	}
+	input, ok := bindCustomRole(c)
+	if !ok {
+		return
+	}
+	role, err := h.roleService.UpdateRole(c, tenantID, id, input)
+	if err != nil {
+		writeCustomRoleError(c, err, "Failed to update custom role")
+		return
+	}
+	c.JSON(http.StatusOK, toAPICustomRole(role))
+}
+
+// (DELETE /api/v1/roles/{id})
+func (h *RoleHandler) DeleteCustomRole(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.manageableRole(c, tenantID, id); !ok {
+		return
+	}
+	if err := h.roleService.DeleteRole(c, tenantID, id); err != nil {
+		writeCustomRoleError(c, err, "Failed to delete custom role")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (GET /api/v1/roles/{id}/members)
+func (h *RoleHandler) ListCustomRoleMembers(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	members, err := h.roleService.ListMembers(c, tenantID, id)
+	if err != nil {
+		writeCustomRoleError(c, err, "Failed to list custom role members")
+		return
+	}
+	result := make([]core.CustomRoleMember, len(members))
+	for i, member := range members {
+		result[i] = core.CustomRoleMember{
+			UserId:     member.UserID,
+			AssignedBy: member.AssignedBy,
+			AssignedAt: member.AssignedAt,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (PUT /api/v1/roles/{id}/members/{userid})
+func (h *RoleHandler) AddCustomRoleMember(c *gin.Context, id openapi_types.UUID, userID string) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	role, ok := h.manageableRole(c, tenantID, id)
+	if !ok {
+		return
+	}
+	if err := h.roleService.AddMember(c, role, userID, c.GetString(auth.AUTH_USER_ID)); err != nil {
+		writeCustomRoleError(c, err, "Failed to assign custom role")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (DELETE /api/v1/roles/{id}/members/{userid})
+func (h *RoleHandler) RemoveCustomRoleMember(c *gin.Context, id openapi_types.UUID, userID string) {
+	tenantID, ok := customRoleScope(c)
+	if !ok {
+		return
+	}
+	role, ok := h.manageableRole(c, tenantID, id)
+	if !ok {
+		return
+	}
+	if err := h.roleService.RemoveMember(c, role, userID, c.GetString(auth.AUTH_USER_ID)); err != nil {
+		writeCustomRoleError(c, err, "Failed to remove custom role")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// manageableRole returns the custom role, or writes the error response unless
+// the caller is allowed each of its permissions: changing a role or its
+// members changes who holds them
+func (h *RoleHandler) manageableRole(c *gin.Context, tenantID string, id openapi_types.UUID) (access.CustomRole, bool) {
+	role, err := h.roleService.GetRole(c, tenantID, id)
+	if err != nil {
+		writeCustomRoleError(c, err, "Failed to get custom role")
+		return access.CustomRole{}, false
+	}
+	if !hasPermissions(c, role.Permissions) {
+		return access.CustomRole{}, false
+	}
+	return role, true
+}
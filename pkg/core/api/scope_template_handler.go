@@ -0,0 +1,160 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func toScopeTemplate(template repository.CoreScopeTemplate) core.ScopeTemplate {
+	return core.ScopeTemplate{
+		Id:          template.ID,
+		Name:        template.Name,
+		Description: util.FromNullableText(template.Description),
+		Scopes:      template.Scopes,
+		PropagateAt: util.FromNullableTimestamptz(template.PropagateAt),
+		CreatedBy:   template.CreatedBy,
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+}
+
+// ListScopeTemplates lists the scope templates of the caller's tenant
+// (GET /admin-api/v1/scope-templates)
+func (h *ClientApplicationHandler) ListScopeTemplates(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	templates, err := h.clientAppService.ListScopeTemplates(c, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		logger.Err(err).Msg("Failed to list scope templates")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.ScopeTemplate, len(templates))
+	for i, template := range templates {
+		result[i] = toScopeTemplate(template)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateScopeTemplate adds a scope template to the caller's tenant
+// (POST /admin-api/v1/scope-templates)
+func (h *ClientApplicationHandler) CreateScopeTemplate(c *gin.Context) {
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	var req core.CreateScopeTemplateJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	template, err := h.clientAppService.CreateScopeTemplate(c, c.GetString(auth.AUTH_TENANT_ID_KEY),
+		req.Name, description, req.Scopes, userID.(string))
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidScopeTemplate) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		if helpers.AbortIfDuplicate(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, toScopeTemplate(template))
+}
+
+// GetScopeTemplate returns a scope template of the caller's tenant
+// (GET /admin-api/v1/scope-templates/{id})
+func (h *ClientApplicationHandler) GetScopeTemplate(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	template, err := h.clientAppService.GetScopeTemplate(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("id", id.String()).Msg("Failed to get scope template")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toScopeTemplate(template))
+}
+
+// UpdateScopeTemplate changes a scope template of the caller's tenant,
+// optionally propagating the new scopes to the tokens created from it
+// (PUT /admin-api/v1/scope-templates/{id})
+func (h *ClientApplicationHandler) UpdateScopeTemplate(c *gin.Context, id uuid.UUID) {
+	userID, exists := c.Get(auth.AUTH_USER_ID)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	var req core.UpdateScopeTemplateJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	update := access.ScopeTemplateUpdate{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	}
+	if req.Description != nil {
+		update.Description = *req.Description
+	}
+	if req.Propagate != nil {
+		update.Propagate = *req.Propagate
+	}
+	if req.GracePeriodHours != nil {
+		update.GracePeriod = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	template, _, err := h.clientAppService.UpdateScopeTemplate(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY), update, userID.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrInvalidScopeTemplate):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		default:
+			if helpers.AbortIfDuplicate(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toScopeTemplate(template))
+}
+
+// DeleteScopeTemplate removes a scope template of the caller's tenant
+// (DELETE /admin-api/v1/scope-templates/{id})
+func (h *ClientApplicationHandler) DeleteScopeTemplate(c *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := h.clientAppService.DeleteScopeTemplate(c, id, c.GetString(auth.AUTH_TENANT_ID_KEY)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("id", id.String()).Msg("Failed to delete scope template")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
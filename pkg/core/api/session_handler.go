@@ -0,0 +1,189 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionHandler lists and revokes the sessions kept by the auth provider,
+// so that a compromised account can be signed out everywhere
+type SessionHandler struct {
+	store               *db.Store
+	authProvider        auth.AuthProvider
+	userActivityService *access.UserActivityService
+}
+
+func NewSessionHandler(store *db.Store, authProvider auth.AuthProvider) *SessionHandler {
+	return &SessionHandler{
+		store:               store,
+		authProvider:        authProvider,
+		userActivityService: access.NewUserActivityService(store),
+	}
+}
+
+func toAPIUserSession(session auth.Session, currentID string) core.UserSession {
+	result := core.UserSession{
+		Id:              session.ID,
+		Current:         session.ID != "" && session.ID == currentID,
+		AuthenticatedAt: session.AuthenticatedAt,
+		IssuedAt:        session.IssuedAt,
+		ExpiresAt:       session.ExpiresAt,
+		Methods:         session.Methods,
+	}
+	if result.Methods == nil {
+		result.Methods = []string{}
+	}
+	if session.AAL != "" {
+		result.Aal = &session.AAL
+	}
+	if session.IPAddress != "" {
+		result.IpAddress = &session.IPAddress
+	}
+	if session.UserAgent != "" {
+		result.UserAgent = &session.UserAgent
+	}
+	if session.Location != "" {
+		result.Location = &session.Location
+	}
+	return result
+}
+
+// authClient returns the auth client of the request domain
+func (h *SessionHandler) authClient(c *gin.Context) (auth.AuthClient, bool) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	subdomain, err := util.GetSubdomain(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return nil, false
+	}
+	authClient, err := h.authProvider.GetAuthClientForSubdomain(c, subdomain)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for subdomain")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return nil, false
+	}
+	return authClient, true
+}
+
+// forgetUser drops the verifications the provider keeps for its grace mode,
+// otherwise a revoked session would still be accepted during an outage
+func (h *SessionHandler) forgetUser(userID string) {
+	if reporter, ok := h.authProvider.(auth.HealthReporter); ok {
+		reporter.GetHealthMonitor().ForgetUser(userID)
+	}
+}
+
+// (GET /api/v1/users/me/sessions)
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+	sessions, err := authClient.ListUserSessions(c, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		logger.Err(err).Msg("Failed to list sessions")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	currentID := c.GetString(auth.AUTH_SESSION_ID)
+	result := make([]core.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, toAPIUserSession(session, currentID))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (DELETE /api/v1/users/me/sessions/{sessionid})
+func (h *SessionHandler) RevokeMySession(c *gin.Context, sessionID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if err := authClient.RevokeUserSession(c, userID, sessionID); err != nil {
+		if auth.IsSessionNotFound(err) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("session_id", sessionID).Msg("Failed to revoke session")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	h.forgetUser(userID)
+
+	_ = h.userActivityService.RecordUserActivity(c, access.UserActivity{
+		TenantID:  c.GetString(auth.AUTH_TENANT_ID_KEY),
+		UserID:    userID,
+		Category:  access.ActivityCategoryLogin,
+		EventType: "session_revoked",
+		Data:      map[string]interface{}{"session_id": sessionID},
+	})
+	c.Status(http.StatusNoContent)
+}
+
+// (DELETE /api/v1/users/{userid}/sessions)
+func (h *SessionHandler) RevokeUserSessions(c *gin.Context, userID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorStringResponse("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can revoke sessions"))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+
+	// Only the members of the tenant, and none with more rights than the caller
+	user, err := h.store.GetSharedUserByTenantByID(c, repository.GetSharedUserByTenantByIDParams{
+		ID:       userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorStringResponse("User not found"))
+			return
+		}
+		logger.Err(err).Str("user_id", userID).Msg("Failed to get user")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	roles := make([]core.Role, 0, len(user.Roles)+len(user.TenantRoles))
+	for _, role := range append(user.Roles, user.TenantRoles...) {
+		roles = append(roles, core.Role(role))
+	}
+	if err := auth.HasRightsForRoles(c, roles); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+	if err := authClient.RevokeUserSessions(c, userID); err != nil {
+		logger.Err(err).Str("user_id", userID).Msg("Failed to revoke sessions")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	h.forgetUser(userID)
+
+	logger.Info().Str("user_id", userID).Str("actor_id", c.GetString(auth.AUTH_USER_ID)).Msg("Revoked all sessions of user")
+	_ = h.userActivityService.RecordUserActivity(c, access.UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  access.ActivityCategoryLogin,
+		EventType: "sessions_revoked",
+		ActorID:   c.GetString(auth.AUTH_USER_ID),
+	})
+	c.Status(http.StatusNoContent)
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"context"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,14 +36,3 @@ func IsSilent(ctx context.Context) bool {
 	b, _ := v.(bool)
 	return b
 }
-
-// parseBoolFlag converts a CSV cell to a boolean. Accepts y/yes/true/1
-// (case-insensitive, surrounding whitespace tolerated). Anything else,
-// including empty, is false.
-func parseBoolFlag(s string) bool {
-	switch strings.ToLower(strings.TrimSpace(s)) {
-	case "y", "yes", "true", "1":
-		return true
-	}
-	return false
-}
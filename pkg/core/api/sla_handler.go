@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// SLAHandler exposes the pending items past their SLA target
+type SLAHandler struct {
+	slaService *access.SLAService
+}
+
+func NewSLAHandler(store *db.Store) *SLAHandler {
+	return &SLAHandler{
+		slaService: access.NewSLAService(store, access.SLAConfigFromEnv()),
+	}
+}
+
+// ListSLAOverdueItems returns the overdue items of the tenant, or of every
+// tenant on the admin domain, oldest first
+// (GET /api/v1/tenant/sla/overdue)
+func (h *SLAHandler) ListSLAOverdueItems(c *gin.Context, params core.ListSLAOverdueItemsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can list overdue items")))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" && !auth.Allowed(c, auth.OpManageGlobalUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("only global user managers can list the overdue items of every tenant")))
+		return
+	}
+
+	itemType := ""
+	if params.Type != nil {
+		itemType = *params.Type
+	}
+	limit := access.DefaultSLAOverdueItems
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+
+	items, err := h.slaService.ListOverdue(c, tenantID, itemType, limit)
+	if err != nil {
+		if errors.Is(err, access.ErrUnknownSLAItemType) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to list overdue SLA items")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := make([]core.SLAOverdueItem, len(items))
+	for i, item := range items {
+		result[i] = core.SLAOverdueItem{
+			Type:         item.Type,
+			Id:           item.ID,
+			TenantId:     item.TenantID,
+			Subject:      item.Subject,
+			PendingSince: item.PendingSince,
+			DueAt:        item.DueAt,
+			EscalatedAt:  item.EscalatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
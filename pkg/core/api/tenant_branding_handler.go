@@ -0,0 +1,113 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// Public URLs of the tenant pictures, resolved from the domain of the tenant
+const (
+	tenantLogoURL             = "/public-api/v1/tenant/pictures/logo"
+	tenantBackgroundURL       = "/public-api/v1/tenant/pictures/background"
+	tenantBackgroundMobileURL = "/public-api/v1/tenant/pictures/background-mobile"
+)
+
+func toAPITenantBranding(tenant repository.CoreTenant, branding service.TenantBranding) core.TenantBranding {
+	return core.TenantBranding{
+		ProductName:         branding.ProductName,
+		DisplayName:         tenant.Profile.DisplayName,
+		PrimaryColor:        branding.PrimaryColor,
+		SecondaryColor:      branding.SecondaryColor,
+		FontFamily:          branding.FontFamily,
+		EmailFooter:         branding.EmailFooter,
+		DarkModeDefault:     branding.DarkModeDefault != nil && *branding.DarkModeDefault,
+		ShowPoweredBy:       branding.ShowPoweredBy == nil || *branding.ShowPoweredBy,
+		LightColors:         tenant.Profile.LightColors,
+		DarkColors:          tenant.Profile.DarkColors,
+		LogoUrl:             tenantLogoURL,
+		BackgroundUrl:       tenantBackgroundURL,
+		BackgroundMobileUrl: tenantBackgroundMobileURL,
+	}
+}
+
+// (GET /public-api/v1/tenant/branding)
+func (s *TenantHandler) GetPublicTenantBranding(ctx *gin.Context) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	tenant, ok := service.GetTenantFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, helpers.ErrorStringResponse("Tenant not found"))
+		return
+	}
+	values := service.TenantSettingValuesFromContext(ctx)
+	if values == nil {
+		var err error
+		values, err = s.tenantSettingsService.GetSettingValues(ctx, tenant.TenantID)
+		if err != nil {
+			logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to get tenant branding")
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, toAPITenantBranding(tenant, service.TenantBrandingFromSettings(values)))
+}
+
+// (PUT /api/v1/tenant/branding)
+func (s *TenantHandler) UpdateTenantBranding(ctx *gin.Context) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	if err := auth.Authorize(ctx, auth.OpManageTenantSettings); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := ctx.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The branding must be set on a tenant"))
+		return
+	}
+	var req core.UpdateTenantBrandingJSONRequestBody
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, ok := service.GetTenantFromContext(ctx)
+	if !ok {
+		var err error
+		if tenant, err = s.store.GetTenantByTenantID(ctx, tenantID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+	}
+
+	branding, err := s.tenantSettingsService.UpdateBranding(ctx, tenantID, service.TenantBranding{
+		PrimaryColor:    req.PrimaryColor,
+		SecondaryColor:  req.SecondaryColor,
+		FontFamily:      req.FontFamily,
+		ProductName:     req.ProductName,
+		EmailFooter:     req.EmailFooter,
+		DarkModeDefault: req.DarkModeDefault,
+		ShowPoweredBy:   req.ShowPoweredBy,
+	}, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		var invalid *service.TenantSettingValuesError
+		if errors.As(err, &invalid) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"message":    invalid.Error(),
+				"code":       "INVALID_TENANT_SETTINGS",
+				"field":      "branding",
+				"violations": invalid.Violations,
+			})
+			return
+		}
+		logger.Err(err).Str("tenant_id", tenantID).Msg("Failed to update tenant branding")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantBranding(tenant, branding))
+}
@@ -0,0 +1,344 @@
+package core
+
+import (
+	"net/http"
+
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TenantClientApplicationHandler serves the tenant self-service client
+// application endpoints (/api/v1/tenant/client-applications). They are
+// restricted to CUSTOMER_ADMIN and delegate to ClientApplicationHandler, which
+// scopes every query to the request tenant.
+type TenantClientApplicationHandler struct {
+	apps *ClientApplicationHandler
+}
+
+func NewTenantClientApplicationHandler(apps *ClientApplicationHandler) *TenantClientApplicationHandler {
+	return &TenantClientApplicationHandler{apps: apps}
+}
+
+// tenantClientApplicationScope writes the error response and returns false
+// unless a CUSTOMER_ADMIN calls from a tenant
+func tenantClientApplicationScope(c *gin.Context) bool {
+	if err := auth.Authorize(c, auth.OpManageTenantClientApplications); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": err.Error(),
+		})
+		return false
+	}
+	if c.GetString(auth.AUTH_TENANT_ID_KEY) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "Tenant client applications must be managed from a tenant",
+		})
+		return false
+	}
+	return true
+}
+
+// (GET /api/v1/tenant/client-applications)
+func (h *TenantClientApplicationHandler) ListTenantClientApplications(c *gin.Context, params core.ListTenantClientApplicationsParams) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ListClientApplications(c, core.ListClientApplicationsParams{
+		Page:            params.Page,
+		PageSize:        params.PageSize,
+		SortBy:          params.SortBy,
+		Order:           (*core.ListClientApplicationsParamsOrder)(params.Order),
+		Q:               params.Q,
+		IncludeInactive: params.IncludeInactive,
+	})
+}
+
+// (POST /api/v1/tenant/client-applications)
+func (h *TenantClientApplicationHandler) CreateTenantClientApplication(c *gin.Context) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.CreateClientApplication(c)
+}
+
+// (GET /api/v1/tenant/client-applications/{id})
+func (h *TenantClientApplicationHandler) GetTenantClientApplicationById(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.GetClientApplicationById(c, id)
+}
+
+// (PUT /api/v1/tenant/client-applications/{id})
+func (h *TenantClientApplicationHandler) UpdateTenantClientApplication(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.UpdateClientApplication(c, id)
+}
+
+// (DELETE /api/v1/tenant/client-applications/{id})
+func (h *TenantClientApplicationHandler) DeleteTenantClientApplication(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeleteClientApplication(c, id)
+}
+
+// (PATCH /api/v1/tenant/client-applications/{id}/deactivate)
+func (h *TenantClientApplicationHandler) DeactivateTenantClientApplication(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeactivateClientApplication(c, id)
+}
+
+// (PATCH /api/v1/tenant/client-applications/{id}/activate)
+func (h *TenantClientApplicationHandler) ActivateTenantClientApplication(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ActivateClientApplication(c, id)
+}
+
+// (PUT /api/v1/tenant/client-applications/{id}/service-account)
+func (h *TenantClientApplicationHandler) LinkTenantClientApplicationServiceAccount(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.LinkClientApplicationServiceAccount(c, id)
+}
+
+// (DELETE /api/v1/tenant/client-applications/{id}/service-account)
+func (h *TenantClientApplicationHandler) UnlinkTenantClientApplicationServiceAccount(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.UnlinkClientApplicationServiceAccount(c, id)
+}
+
+// (POST /api/v1/tenant/client-applications/{id}/secret)
+func (h *TenantClientApplicationHandler) RotateTenantClientApplicationSecret(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.RotateClientApplicationSecret(c, id)
+}
+
+// (DELETE /api/v1/tenant/client-applications/{id}/secret)
+func (h *TenantClientApplicationHandler) DeleteTenantClientApplicationSecret(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeleteClientApplicationSecret(c, id)
+}
+
+// (POST /api/v1/tenant/client-applications/{id}/signing-key)
+func (h *TenantClientApplicationHandler) RotateTenantClientApplicationSigningKey(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.RotateClientApplicationSigningKey(c, id)
+}
+
+// (DELETE /api/v1/tenant/client-applications/{id}/signing-key)
+func (h *TenantClientApplicationHandler) DeleteTenantClientApplicationSigningKey(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeleteClientApplicationSigningKey(c, id)
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/webhook)
+func (h *TenantClientApplicationHandler) GetTenantClientApplicationWebhook(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.GetClientApplicationWebhook(c, id)
+}
+
+// (POST /api/v1/tenant/client-applications/{id}/webhook)
+func (h *TenantClientApplicationHandler) SetTenantClientApplicationWebhook(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.SetClientApplicationWebhook(c, id)
+}
+
+// (DELETE /api/v1/tenant/client-applications/{id}/webhook)
+func (h *TenantClientApplicationHandler) DeleteTenantClientApplicationWebhook(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeleteClientApplicationWebhook(c, id)
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/config)
+func (h *TenantClientApplicationHandler) ExportTenantClientApplicationConfig(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ExportClientApplicationConfig(c, id)
+}
+
+// (POST /api/v1/tenant/client-applications/import)
+func (h *TenantClientApplicationHandler) ImportTenantClientApplicationConfig(c *gin.Context) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ImportClientApplicationConfig(c)
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/tokens)
+func (h *TenantClientApplicationHandler) ListTenantAPITokens(c *gin.Context, id uuid.UUID, params core.ListTenantAPITokensParams) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ListAPITokens(c, id, core.ListAPITokensParams{
+		Page:           params.Page,
+		PageSize:       params.PageSize,
+		SortBy:         params.SortBy,
+		Order:          (*core.ListAPITokensParamsOrder)(params.Order),
+		IncludeRevoked: params.IncludeRevoked,
+		IncludeExpired: params.IncludeExpired,
+	})
+}
+
+// (POST /api/v1/tenant/client-applications/{id}/tokens)
+func (h *TenantClientApplicationHandler) CreateTenantAPIToken(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.CreateAPIToken(c, id)
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId})
+func (h *TenantClientApplicationHandler) GetTenantAPITokenById(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.GetAPITokenById(c, id, tokenId)
+}
+
+// (DELETE /api/v1/tenant/client-applications/{id}/tokens/{tokenId})
+func (h *TenantClientApplicationHandler) DeleteTenantAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeleteAPIToken(c, id, tokenId)
+}
+
+// (PATCH /api/v1/tenant/client-applications/{id}/tokens/{tokenId})
+func (h *TenantClientApplicationHandler) UpdateTenantAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.UpdateAPIToken(c, id, tokenId)
+}
+
+// (PUT /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/ip-allowlist)
+func (h *TenantClientApplicationHandler) UpdateTenantAPITokenIPAllowlist(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.UpdateAPITokenIPAllowlist(c, id, tokenId)
+}
+
+// (PATCH /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/revoke)
+func (h *TenantClientApplicationHandler) RevokeTenantAPIToken(c *gin.Context, id uuid.UUID, tokenId uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.RevokeAPIToken(c, id, tokenId)
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/audit)
+func (h *TenantClientApplicationHandler) GetTenantAPITokenAuditLogs(c *gin.Context, id uuid.UUID, tokenId uuid.UUID, params core.GetTenantAPITokenAuditLogsParams) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.GetAPITokenAuditLogs(c, id, tokenId, core.GetAPITokenAuditLogsParams(params))
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/audit/export)
+func (h *TenantClientApplicationHandler) ExportTenantAPITokenAuditLogs(c *gin.Context, id uuid.UUID, tokenId uuid.UUID, params core.ExportTenantAPITokenAuditLogsParams) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ExportAPITokenAuditLogs(c, id, tokenId, core.ExportAPITokenAuditLogsParams(params))
+}
+
+// (GET /api/v1/tenant/client-applications/{id}/tokens/{tokenId}/usage)
+func (h *TenantClientApplicationHandler) GetTenantAPITokenUsage(c *gin.Context, id uuid.UUID, tokenId uuid.UUID, params core.GetTenantAPITokenUsageParams) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.GetAPITokenUsage(c, id, tokenId, core.GetAPITokenUsageParams(params))
+}
+
+// (POST /api/v1/tenant/client-applications/{id}/tokens/revoke-all)
+func (h *TenantClientApplicationHandler) RevokeAllTenantClientApplicationTokens(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.RevokeAllClientApplicationTokens(c, id)
+}
+
+// (POST /api/v1/tenant/api-tokens/revoke-all)
+func (h *TenantClientApplicationHandler) RevokeAllTenantAPITokens(c *gin.Context) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.RevokeAllAPITokens(c)
+}
+
+// (GET /api/v1/tenant/scopes)
+func (h *TenantClientApplicationHandler) ListTenantScopes(c *gin.Context) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ListScopes(c)
+}
+
+// (GET /api/v1/tenant/scope-templates)
+func (h *TenantClientApplicationHandler) ListTenantScopeTemplates(c *gin.Context) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.ListScopeTemplates(c)
+}
+
+// (POST /api/v1/tenant/scope-templates)
+func (h *TenantClientApplicationHandler) CreateTenantScopeTemplate(c *gin.Context) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.CreateScopeTemplate(c)
+}
+
+// (GET /api/v1/tenant/scope-templates/{id})
+func (h *TenantClientApplicationHandler) GetTenantScopeTemplate(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.GetScopeTemplate(c, id)
+}
+
+// (PUT /api/v1/tenant/scope-templates/{id})
+func (h *TenantClientApplicationHandler) UpdateTenantScopeTemplate(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.UpdateScopeTemplate(c, id)
+}
+
+// (DELETE /api/v1/tenant/scope-templates/{id})
+func (h *TenantClientApplicationHandler) DeleteTenantScopeTemplate(c *gin.Context, id uuid.UUID) {
+	if !tenantClientApplicationScope(c) {
+		return
+	}
+	h.apps.DeleteScopeTemplate(c, id)
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	core "ctoup.com/coreapp/api/openapi/core"
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// tenantAdminRequest builds the context of a request of a CUSTOMER_ADMIN of
+// the tenant, as left by the auth and tenant middlewares
+func tenantAdminRequest(t *testing.T, tenantID, method string, body interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/tenant/client-applications", &payload)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(auth.AUTH_USER_ID, "admin-"+tenantID)
+	c.Set(auth.AUTH_TENANT_ID_KEY, tenantID)
+	c.Set(auth.AUTH_CLAIMS, map[string]interface{}{string(core.CUSTOMERADMIN): true})
+	return c, w
+}
+
+func TestTenantClientApplicationIsolation(t *testing.T) {
+	t.Setenv(access.SecretRequestSigningMasterKey, commontestutils.RandomString(32))
+	store := testutils.NewTestStore(t)
+	service := access.NewClientApplicationServiceWithSecrets(store, access.EnvSecretProvider{})
+	h := NewTenantClientApplicationHandler(NewClientApplicationHandler(store, service))
+
+	tenantA := commontestutils.RandomString(10)
+	tenantB := commontestutils.RandomString(10)
+	ctx, _ := tenantAdminRequest(t, tenantB, http.MethodPost, nil)
+	appB, err := service.CreateClientApplication(ctx, tenantB, "b-app", "tenant B", "admin-"+tenantB)
+	require.NoError(t, err)
+	_, tokenB, err := service.CreateAPIToken(ctx, appB.ID, tenantB, "b-token", "", 30, "admin-"+tenantB, []string{"read", "write"})
+	require.NoError(t, err)
+	appA, err := service.CreateClientApplication(ctx, tenantA, "a-app", "tenant A", "admin-"+tenantA)
+	require.NoError(t, err)
+
+	t.Run("list", func(t *testing.T) {
+		c, w := tenantAdminRequest(t, tenantA, http.MethodGet, nil)
+		h.ListTenantClientApplications(c, core.ListTenantClientApplicationsParams{})
+		require.Equal(t, http.StatusOK, w.Code)
+		var apps []core.ClientApplication
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apps))
+		require.Len(t, apps, 1)
+		require.Equal(t, appA.ID, apps[0].Id)
+
+		c, w = tenantAdminRequest(t, tenantA, http.MethodGet, nil)
+		h.ListTenantAPITokens(c, appB.ID, core.ListTenantAPITokensParams{})
+		require.Equal(t, http.StatusOK, w.Code)
+		var tokens []core.APIToken
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+		require.Empty(t, tokens)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		c, w := tenantAdminRequest(t, tenantA, http.MethodGet, nil)
+		h.GetTenantClientApplicationById(c, appB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		c, w = tenantAdminRequest(t, tenantA, http.MethodGet, nil)
+		h.GetTenantAPITokenById(c, appB.ID, tokenB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		// Nor through an application of the tenant
+		c, w = tenantAdminRequest(t, tenantA, http.MethodGet, nil)
+		h.GetTenantAPITokenById(c, appA.ID, tokenB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		c, w := tenantAdminRequest(t, tenantA, http.MethodPut, core.UpdateTenantClientApplicationJSONRequestBody{Name: "taken over", Active: true})
+		h.UpdateTenantClientApplication(c, appB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		scopes := []string{"read"}
+		c, w = tenantAdminRequest(t, tenantA, http.MethodPatch, core.UpdateTenantAPITokenJSONRequestBody{Scopes: &scopes})
+		h.UpdateTenantAPIToken(c, appB.ID, tokenB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		c, w = tenantAdminRequest(t, tenantA, http.MethodPost, core.RevokeTenantAPITokenJSONRequestBody{Reason: "taken over"})
+		h.RevokeTenantAPIToken(c, appB.ID, tokenB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		c, w := tenantAdminRequest(t, tenantA, http.MethodPost, core.RotateTenantClientApplicationSecretJSONRequestBody{})
+		h.RotateTenantClientApplicationSecret(c, appB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		c, w = tenantAdminRequest(t, tenantA, http.MethodPost, core.RotateTenantClientApplicationSigningKeyJSONRequestBody{})
+		h.RotateTenantClientApplicationSigningKey(c, appB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		body := core.SetTenantClientApplicationWebhookJSONRequestBody{Url: "https://93.184.216.34/hooks"}
+		c, w := tenantAdminRequest(t, tenantA, http.MethodPut, body)
+		h.SetTenantClientApplicationWebhook(c, appB.ID)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		// The same request is accepted on the tenant's own application
+		c, w = tenantAdminRequest(t, tenantA, http.MethodPut, body)
+		h.SetTenantClientApplicationWebhook(c, appA.ID)
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	// Tenant B's application and token are untouched
+	app, err := service.GetClientApplicationByID(context.Background(), appB.ID, tenantB)
+	require.NoError(t, err)
+	require.Equal(t, "b-app", app.Name)
+	token, err := service.GetAPITokenByID(context.Background(), tokenB.ID, tenantB)
+	require.NoError(t, err)
+	require.False(t, token.Revoked)
+	require.ElementsMatch(t, []string{"read", "write"}, token.Scopes)
+	_, err = store.GetClientApplicationSecret(context.Background(), appB.ID)
+	require.Error(t, err)
+	_, err = store.GetClientApplicationWebhook(context.Background(), appB.ID)
+	require.Error(t, err)
+}
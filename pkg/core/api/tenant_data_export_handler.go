@@ -0,0 +1,125 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func toAPITenantDataExport(status service.TenantDataExportStatus) core.TenantDataExport {
+	result := core.TenantDataExport{
+		JobId:     status.Job.ID,
+		TenantId:  status.Job.TenantID,
+		Status:    status.Job.Status,
+		Progress:  status.Progress,
+		CreatedBy: status.Job.CreatedBy,
+		CreatedAt: status.Job.CreatedAt,
+	}
+	if status.Message != "" {
+		result.Message = &status.Message
+	}
+	if status.Job.CompletedAt.Valid {
+		result.CompletedAt = &status.Job.CompletedAt.Time
+	}
+	if status.Result != nil {
+		tables := make([]core.TenantDataExportTable, len(status.Result.Tables))
+		for i, table := range status.Result.Tables {
+			tables[i] = core.TenantDataExportTable{Name: table.Name, Rows: table.Rows}
+		}
+		result.Tables = &tables
+		result.Bytes = &status.Result.Bytes
+	}
+	return result
+}
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/export)
+func (s *TenantHandler) StartTenantDataExport(ctx *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	if err := auth.Authorize(ctx, auth.OpExportTenantData); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	// The export outlives the request, so it gets the request context rather
+	// than the gin context that is recycled once the handler returns
+	job, err := s.tenantDataExportService.StartExport(ctx.Request.Context(), tenant, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to start tenant data export")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.Header("X-Job-Id", job.ID.String())
+	ctx.JSON(http.StatusAccepted, toAPITenantDataExport(service.TenantDataExportStatus{Job: job}))
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/export/{jobid})
+func (s *TenantHandler) GetTenantDataExport(ctx *gin.Context, id uuid.UUID, jobID uuid.UUID) {
+	status, ok := s.tenantDataExport(ctx, id, jobID)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantDataExport(status))
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/export/{jobid}/download)
+func (s *TenantHandler) DownloadTenantDataExport(ctx *gin.Context, id uuid.UUID, jobID uuid.UUID) {
+	status, ok := s.tenantDataExport(ctx, id, jobID)
+	if !ok {
+		return
+	}
+	signedURL, err := s.tenantDataExportService.SignedDownloadURL(ctx, status.Job)
+	if err == nil {
+		ctx.Redirect(http.StatusFound, signedURL)
+		return
+	}
+	if errors.Is(err, service.ErrTenantDataExportNotReady) {
+		ctx.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+		return
+	}
+	if !errors.Is(err, service.ErrSignedURLUnsupported) {
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	content, err := s.tenantDataExportService.ReadArchive(ctx, status.Job)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="`+service.TenantDataExportFileName(status.Job.TenantID, status.Job.ID)+`"`)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, service.TenantDataExportContentType, content)
+}
+
+// tenantDataExport returns an export of the tenant, or writes the error
+// response when the caller may not see it or it does not exist
+func (s *TenantHandler) tenantDataExport(ctx *gin.Context, id uuid.UUID, jobID uuid.UUID) (service.TenantDataExportStatus, bool) {
+	if err := auth.Authorize(ctx, auth.OpExportTenantData); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return service.TenantDataExportStatus{}, false
+	}
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return service.TenantDataExportStatus{}, false
+	}
+	status, err := s.tenantDataExportService.GetExport(ctx, tenant.TenantID, jobID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorStringResponse("Export not found"))
+			return status, false
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return status, false
+	}
+	return status, true
+}
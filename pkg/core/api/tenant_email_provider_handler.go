@@ -0,0 +1,142 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantEmailProviderHandler handles the provider the request tenant sends
+// its emails with instead of the platform one
+type TenantEmailProviderHandler struct {
+	providerService *access.TenantEmailProviderService
+}
+
+func NewTenantEmailProviderHandler(store *db.Store) *TenantEmailProviderHandler {
+	return &TenantEmailProviderHandler{
+		providerService: access.NewTenantEmailProviderService(store),
+	}
+}
+
+func toAPITenantEmailProvider(provider repository.CoreTenantEmailProvider) core.TenantEmailProvider {
+	return core.TenantEmailProvider{
+		Provider:    provider.Provider,
+		Host:        util.FromNullableText(provider.Host),
+		Port:        util.FromNullableInt4(provider.Port),
+		Username:    util.FromNullableText(provider.Username),
+		FromAddress: provider.FromAddress,
+		ReplyTo:     util.FromNullableText(provider.ReplyTo),
+		UpdatedBy:   provider.UpdatedBy,
+		CreatedAt:   provider.CreatedAt,
+		UpdatedAt:   provider.UpdatedAt,
+	}
+}
+
+// emailProviderTenant checks the caller may manage the email provider and
+// returns its tenant, or writes the error response
+func emailProviderTenant(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageEmailProvider); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The email provider is defined by a tenant"))
+		return "", false
+	}
+	return tenantID, true
+}
+
+// (DELETE /api/v1/tenant/email-provider)
+func (h *TenantEmailProviderHandler) DeleteTenantEmailProvider(c *gin.Context) {
+	tenantID, ok := emailProviderTenant(c)
+	if !ok {
+		return
+	}
+	if err := h.providerService.Delete(c, tenantID); err != nil {
+		if errors.Is(err, access.ErrTenantEmailProviderNotFound) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (GET /api/v1/tenant/email-provider)
+func (h *TenantEmailProviderHandler) GetTenantEmailProvider(c *gin.Context) {
+	tenantID, ok := emailProviderTenant(c)
+	if !ok {
+		return
+	}
+	provider, err := h.providerService.Get(c, tenantID)
+	if err != nil {
+		if errors.Is(err, access.ErrTenantEmailProviderNotFound) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantEmailProvider(provider))
+}
+
+// (PUT /api/v1/tenant/email-provider)
+func (h *TenantEmailProviderHandler) SaveTenantEmailProvider(c *gin.Context) {
+	tenantID, ok := emailProviderTenant(c)
+	if !ok {
+		return
+	}
+	var req core.SaveTenantEmailProviderJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	provider, err := h.providerService.Save(c, tenantID, access.TenantEmailProviderInput{
+		Provider:    req.Provider,
+		Host:        req.Host,
+		Port:        req.Port,
+		Username:    req.Username,
+		Secret:      req.Secret,
+		FromAddress: req.FromAddress,
+		ReplyTo:     req.ReplyTo,
+	}, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrInvalidTenantEmailProvider):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, access.ErrTenantEmailProviderNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, helpers.ErrorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantEmailProvider(provider))
+}
+
+// (POST /api/v1/tenant/email-provider/test)
+func (h *TenantEmailProviderHandler) TestTenantEmailProvider(c *gin.Context) {
+	tenantID, ok := emailProviderTenant(c)
+	if !ok {
+		return
+	}
+	if err := h.providerService.SendTest(c, tenantID, c.GetString(auth.AUTH_EMAIL)); err != nil {
+		if errors.Is(err, access.ErrTenantEmailProviderNotFound) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusBadGateway, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// TenantExportHandler serves the compliance exports of the tenant audit trail
+// and API usage (/api/v1/tenant/exports, /api/v1/tenant/export-schedules)
+type TenantExportHandler struct {
+	exportService *access.TenantExportService
+}
+
+func NewTenantExportHandler(store *db.Store) *TenantExportHandler {
+	return &TenantExportHandler{
+		exportService: access.NewTenantExportService(store, fileservice.NewFileService(), access.TenantExportConfigFromEnv()),
+	}
+}
+
+func toAPITenantExport(export repository.CoreTenantExport) core.TenantExport {
+	result := core.TenantExport{
+		Id:          export.ID,
+		Dataset:     core.TenantExportDataset(export.Dataset),
+		Format:      core.TenantExportFormat(export.Format),
+		Destination: core.TenantExportDestination(export.Destination),
+		From:        export.PeriodStart,
+		To:          export.PeriodEnd,
+		Status:      core.TenantExportStatus(export.Status),
+		RowCount:    export.RowCount,
+		RequestedBy: export.RequestedBy,
+		CreatedAt:   export.CreatedAt,
+	}
+	if export.ScheduleID.Valid {
+		scheduleID := openapi_types.UUID(export.ScheduleID.Bytes)
+		result.ScheduleId = &scheduleID
+	}
+	if export.FilePath.Valid {
+		fileName := path.Base(export.FilePath.String)
+		if export.Destination == access.TenantExportDestinationBucket {
+			fileName = export.FilePath.String
+		}
+		result.FileName = &fileName
+	}
+	if export.Error.Valid {
+		result.Error = &export.Error.String
+	}
+	if export.CompletedAt.Valid {
+		result.CompletedAt = &export.CompletedAt.Time
+	}
+	return result
+}
+
+func toAPITenantExportSchedule(schedule repository.CoreTenantExportSchedule) core.TenantExportSchedule {
+	return core.TenantExportSchedule{
+		Id:          schedule.ID,
+		Dataset:     core.TenantExportDataset(schedule.Dataset),
+		Format:      core.TenantExportFormat(schedule.Format),
+		Destination: core.TenantExportDestination(schedule.Destination),
+		Frequency:   core.TenantExportFrequency(schedule.Frequency),
+		NextRunAt:   schedule.NextRunAt,
+		CreatedBy:   schedule.CreatedBy,
+		CreatedAt:   schedule.CreatedAt,
+	}
+}
+
+// tenantExportScope returns the tenant of the caller, or writes the error
+// response unless a CUSTOMER_ADMIN calls from a tenant
+func tenantExportScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageTenantExports); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": err.Error(),
+		})
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "Exports must be managed from a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func tenantExportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidTenantExport), errors.Is(err, access.ErrTenantExportBucketNotConfigured):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrTenantExportNotReady):
+		return http.StatusConflict
+	case err.Error() == pgx.ErrNoRows.Error():
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// (GET /api/v1/tenant/exports)
+func (h *TenantExportHandler) ListTenantExports(c *gin.Context, params core.ListTenantExportsParams) {
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	pagingSql := helpers.GetPagingSQL(helpers.PagingRequest{
+		MaxPageSize:     100,
+		DefaultPage:     1,
+		DefaultPageSize: 20,
+		Page:            params.Page,
+		PageSize:        params.PageSize,
+	})
+	exports, err := h.exportService.ListExports(c, tenantID, pagingSql.PageSize, pagingSql.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.TenantExport, len(exports))
+	for i, export := range exports {
+		result[i] = toAPITenantExport(export)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (POST /api/v1/tenant/exports)
+func (h *TenantExportHandler) CreateTenantExport(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	var req core.CreateTenantExportJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msg("Failed to bind request body")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	exportRequest := access.TenantExportRequest{
+		Dataset: string(req.Dataset),
+		Format:  string(req.Format),
+		From:    req.From,
+		To:      req.To,
+	}
+	if req.Destination != nil {
+		exportRequest.Destination = string(*req.Destination)
+	}
+	// The export outlives the request, so it gets the request context rather
+	// than the pooled gin context
+	export, err := h.exportService.RequestExport(c.Request.Context(), tenantID, exportRequest, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		c.JSON(tenantExportErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusAccepted, toAPITenantExport(export))
+}
+
+// (GET /api/v1/tenant/exports/{id})
+func (h *TenantExportHandler) GetTenantExport(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	export, err := h.exportService.GetExport(c, tenantID, id)
+	if err != nil {
+		c.JSON(tenantExportErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantExport(export))
+}
+
+// (GET /api/v1/tenant/exports/{id}/download)
+func (h *TenantExportHandler) DownloadTenantExport(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	export, err := h.exportService.GetDownloadableExport(c, tenantID, id)
+	if err != nil {
+		c.JSON(tenantExportErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+
+	signedURL, err := h.exportService.SignedDownloadURL(c, export)
+	if err == nil {
+		c.Redirect(http.StatusFound, signedURL)
+		return
+	}
+	if !errors.Is(err, access.ErrSignedURLUnsupported) {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	content, err := h.exportService.ReadExportFile(c, export)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+access.TenantExportFileName(export)+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, access.TenantExportContentType(export.Format), content)
+}
+
+// (GET /api/v1/tenant/export-schedules)
+func (h *TenantExportHandler) ListTenantExportSchedules(c *gin.Context) {
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	schedules, err := h.exportService.ListSchedules(c, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.TenantExportSchedule, len(schedules))
+	for i, schedule := range schedules {
+		result[i] = toAPITenantExportSchedule(schedule)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (POST /api/v1/tenant/export-schedules)
+func (h *TenantExportHandler) SaveTenantExportSchedule(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	var req core.SaveTenantExportScheduleJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msg("Failed to bind request body")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	input := access.TenantExportScheduleInput{
+		Dataset:   string(req.Dataset),
+		Format:    string(req.Format),
+		Frequency: string(req.Frequency),
+	}
+	if req.Destination != nil {
+		input.Destination = string(*req.Destination)
+	}
+	schedule, err := h.exportService.SaveSchedule(c, tenantID, input, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		c.JSON(tenantExportErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantExportSchedule(schedule))
+}
+
+// (DELETE /api/v1/tenant/export-schedules/{id})
+func (h *TenantExportHandler) DeleteTenantExportSchedule(c *gin.Context, id openapi_types.UUID) {
+	tenantID, ok := tenantExportScope(c)
+	if !ok {
+		return
+	}
+	if err := h.exportService.DeleteSchedule(c, tenantID, id); err != nil {
+		c.JSON(tenantExportErrorStatus(err), helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
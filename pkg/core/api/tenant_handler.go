@@ -23,10 +23,32 @@ import (
 
 // https://pkg.go.dev/github.com/go-playground/validator/v10#hdr-One_Of
 type TenantHandler struct {
-	authProvider       auth.AuthProvider
-	multiTenantService *service.MultitenantService
-	FileService        *fileservice.FileService
-	store              *db.Store
+	authProvider              auth.AuthProvider
+	multiTenantService        *service.MultitenantService
+	tenantSettingsService     *service.TenantSettingsService
+	tenantSandboxService      *service.TenantSandboxService
+	tenantDataExportService   *service.TenantDataExportService
+	tenantPurgeService        *service.TenantPurgeService
+	tenantProvisioningService *service.TenantProvisioningService
+	announcementService       *service.AnnouncementService
+	FileService               *fileservice.FileService
+	store                     *db.Store
+}
+
+// publicTenant is the public tenant bootstrap payload
+type publicTenant struct {
+	repository.CoreTenant
+	Announcements []core.Announcement `json:"announcements"`
+}
+
+// activeAnnouncements returns the banners to show with the public tenant. A
+// failure is logged only, so it never blocks the frontend bootstrap.
+func (exh *TenantHandler) activeAnnouncements(c *gin.Context, tenantID string) []core.Announcement {
+	announcements, err := exh.announcementService.ListActiveAnnouncements(c, tenantID)
+	if err != nil {
+		return []core.Announcement{}
+	}
+	return toAPIAnnouncements(announcements)
 }
 
 // (GET /public-api/v1/tenant)
@@ -40,15 +62,20 @@ func (exh *TenantHandler) GetPublicTenant(c *gin.Context) {
 	}
 
 	if utils.IsAdminSubdomain(subdomain) {
-		c.JSON(http.StatusOK, repository.CoreTenant{
-			Subdomain: "www",
-			Name:      "Administration",
-			Features:  subentity.TenantFeatures{},
-			Profile: subentity.TenantProfile{
-				DisplayName: "Administration",
-				LightColors: core.ColorSchema{},
-				DarkColors:  core.ColorSchema{},
+		service.PlatformPublicHeadersFromEnv().Apply(c)
+		c.JSON(http.StatusOK, publicTenant{
+			CoreTenant: repository.CoreTenant{
+				Subdomain: "www",
+				Name:      "Administration",
+				Features:  subentity.TenantFeatures{},
+				Profile: subentity.TenantProfile{
+					DisplayName: "Administration",
+					LightColors: core.ColorSchema{},
+					DarkColors:  core.ColorSchema{},
+				},
 			},
+			// Administration only shows the global announcements
+			Announcements: exh.activeAnnouncements(c, ""),
 		})
 		return
 	}
@@ -73,20 +100,42 @@ func (exh *TenantHandler) GetPublicTenant(c *gin.Context) {
 		}
 	}
 
+	service.GetTenantPublicHeaders(c, exh.store, tenant.TenantID).Apply(c)
 	// write the tenant id to the response
-	c.JSON(http.StatusOK, repository.CoreTenant{
-		Subdomain:       tenant.Subdomain,
-		Name:            tenant.Name,
-		TenantID:        tenant.TenantID,
-		Features:        tenant.Features,
-		FeatureLicenses: publicLicenses,
-		Profile:         tenant.Profile,
-		AllowSignUp:     tenant.AllowSignUp,
-		IsReseller:      tenant.IsReseller,
-		ResellerID:      tenant.ResellerID,
+	c.JSON(http.StatusOK, publicTenant{
+		CoreTenant: repository.CoreTenant{
+			Subdomain:       tenant.Subdomain,
+			Name:            tenant.Name,
+			TenantID:        tenant.TenantID,
+			Features:        tenant.Features,
+			FeatureLicenses: publicLicenses,
+			Profile:         tenant.Profile,
+			AllowSignUp:     tenant.AllowSignUp,
+			IsReseller:      tenant.IsReseller,
+			ResellerID:      tenant.ResellerID,
+		},
+		Announcements: exh.activeAnnouncements(c, tenant.TenantID),
 	})
 }
 
+// callerResellerID is the tenant of the caller when it is a reseller, the
+// reseller of the tenants it creates
+func callerResellerID(c *gin.Context) pgtype.Text {
+	claims, exists := c.Get(auth.AUTH_CLAIMS)
+	if !exists {
+		return pgtype.Text{}
+	}
+	claimsMap := claims.(map[string]interface{})
+	if claimsMap[auth.TENANT_IS_RESELLER] != true {
+		return pgtype.Text{}
+	}
+	authTenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if authTenantID == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: authTenantID, Valid: true}
+}
+
 // AddTenant implements api.ServerInterface.
 func (exh *TenantHandler) AddTenant(c *gin.Context) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
@@ -105,6 +154,10 @@ func (exh *TenantHandler) AddTenant(c *gin.Context) {
 		return
 	}
 
+	if !exh.checkSubdomainNotAlias(c, req.Subdomain) {
+		return
+	}
+
 	tenantConfig := &auth.TenantConfig{
 		DisplayName:         req.Name,
 		Subdomain:           req.Subdomain,
@@ -126,17 +179,7 @@ func (exh *TenantHandler) AddTenant(c *gin.Context) {
 	}
 
 	// If current user is a TENANT_IS_RESELLER of a reseller, set reseller_id
-	var resellerID pgtype.Text
-	claims, exists := c.Get(auth.AUTH_CLAIMS)
-	if exists {
-		claimsMap := claims.(map[string]interface{})
-		if claimsMap[auth.TENANT_IS_RESELLER] == true {
-			authTenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
-			if authTenantID != "" {
-				resellerID = pgtype.Text{String: authTenantID, Valid: true}
-			}
-		}
-	}
+	resellerID := callerResellerID(c)
 
 	// Override with value from request if provided (e.g. by SUPER_ADMIN)
 	if req.ResellerId != nil {
@@ -144,7 +187,7 @@ func (exh *TenantHandler) AddTenant(c *gin.Context) {
 	}
 
 	var isReseller bool
-	if req.IsReseller != nil && auth.IsSuperAdmin(c) {
+	if req.IsReseller != nil && auth.Allowed(c, auth.OpUpdateTenantReseller) {
 		isReseller = *req.IsReseller
 	}
 
@@ -152,7 +195,7 @@ func (exh *TenantHandler) AddTenant(c *gin.Context) {
 	// For resellers the new tenant's reseller_id will be their own tenant_id, so they always qualify.
 	var contractEndDate pgtype.Timestamptz
 	var isDisabled bool
-	canUpdateContract := auth.IsSuperAdmin(c) || auth.IsAdmin(c)
+	canUpdateContract := auth.Allowed(c, auth.OpUpdateTenantContract)
 	if !canUpdateContract {
 		authTenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
 		isCallerReseller, _ := exh.multiTenantService.IsReseller(c, authTenantID)
@@ -245,6 +288,9 @@ func (exh *TenantHandler) UpdateTenant(c *gin.Context, id uuid.UUID) {
 		c.JSON(http.StatusForbidden, "Not allowed to manage this tenant")
 		return
 	}
+	if !exh.checkSubdomainNotAlias(c, req.Subdomain) {
+		return
+	}
 	_, err = tenantManager.UpdateTenant(c, req.TenantId, tenantConfig)
 	if err != nil {
 		logger.Err(err).Msg("Failed to update tenant in auth provider")
@@ -274,13 +320,14 @@ func (exh *TenantHandler) UpdateTenant(c *gin.Context, id uuid.UUID) {
 	}
 
 	// Only SUPER_ADMIN can change is_reseller
-	if auth.IsSuperAdmin(c) && req.IsReseller != nil {
+	canUpdateReseller := auth.Allowed(c, auth.OpUpdateTenantReseller)
+	if canUpdateReseller && req.IsReseller != nil {
 		updateParams.IsReseller = *req.IsReseller
 	}
 
 	// Only SUPER_ADMIN can assign or clear the managing reseller. The selector is
 	// clearable, so an empty/nil value detaches the tenant from its reseller.
-	if auth.IsSuperAdmin(c) {
+	if canUpdateReseller {
 		if req.ResellerId != nil && *req.ResellerId != "" && *req.ResellerId != existing.TenantID {
 			updateParams.ResellerID = pgtype.Text{String: *req.ResellerId, Valid: true}
 		} else {
@@ -290,7 +337,7 @@ func (exh *TenantHandler) UpdateTenant(c *gin.Context, id uuid.UUID) {
 
 	// SUPER_ADMIN, ADMIN, or a reseller managing this specific tenant can update
 	// contract_end_date and is_disabled
-	canUpdateContract := auth.IsSuperAdmin(c) || auth.IsAdmin(c)
+	canUpdateContract := auth.Allowed(c, auth.OpUpdateTenantContract)
 	if !canUpdateContract {
 		authTenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
 		isReseller, _ := exh.multiTenantService.IsReseller(c, authTenantID)
@@ -438,6 +485,13 @@ func (exh *TenantHandler) ListTenants(c *gin.Context, params api.ListTenantsPara
 	if params.ResellerId != nil {
 		query.ResellerID = pgtype.Text{String: *params.ResellerId, Valid: true}
 	}
+	if params.Status != nil {
+		if !service.IsValidTenantStatus(string(*params.Status)) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(service.ErrInvalidTenantStatus))
+			return
+		}
+		query.Status = pgtype.Text{String: string(*params.Status), Valid: true}
+	}
 
 	// If user is TENANT_IS_RESELLER of a reseller, force reseller_id filter.
 	claims, exists := c.Get(auth.AUTH_CLAIMS)
@@ -454,7 +508,7 @@ func (exh *TenantHandler) ListTenants(c *gin.Context, params api.ListTenantsPara
 
 	// SUPER_ADMIN / ADMIN may opt out of all reseller scoping via ?global=true
 	// (e.g. the tenant switcher needs the full list even on a reseller subdomain).
-	if params.Global != nil && *params.Global && (auth.IsSuperAdmin(c) || auth.IsAdmin(c)) {
+	if params.Global != nil && *params.Global && auth.Allowed(c, auth.OpListAllTenants) {
 		query.ResellerID = pgtype.Text{Valid: false}
 	}
 
@@ -472,8 +526,8 @@ func (exh *TenantHandler) ListResellerTenants(c *gin.Context) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
 
 	// Caller must be a CUSTOMER_ADMIN of a reseller tenant
-	if !auth.IsActingReseller(c) && !auth.IsReseller(c) {
-		c.JSON(http.StatusForbidden, helpers.ErrorResponse(fmt.Errorf("forbidden: must be a CUSTOMER_ADMIN of a reseller tenant")))
+	if err := auth.Authorize(c, auth.OpListResellerTenants); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
 		return
 	}
 
@@ -491,9 +545,15 @@ func (exh *TenantHandler) ListResellerTenants(c *gin.Context) {
 func NewTenantHandler(store *db.Store, authProvider auth.AuthProvider, multiTenantService *service.MultitenantService) *TenantHandler {
 	fileService := fileservice.NewFileService()
 	return &TenantHandler{
-		store:              store,
-		authProvider:       authProvider,
-		FileService:        fileService,
-		multiTenantService: multiTenantService,
+		store:                     store,
+		authProvider:              authProvider,
+		FileService:               fileService,
+		multiTenantService:        multiTenantService,
+		tenantSettingsService:     service.NewTenantSettingsService(store),
+		tenantSandboxService:      service.NewTenantSandboxService(store, fileService, multiTenantService),
+		tenantDataExportService:   service.NewTenantDataExportService(store, fileService, service.TenantExportConfigFromEnv().URLExpiry),
+		tenantPurgeService:        service.NewTenantPurgeService(store, fileService, authProvider, multiTenantService, service.TenantPurgeConfigFromEnv()),
+		tenantProvisioningService: service.NewTenantProvisioningService(store, fileService, authProvider, multiTenantService),
+		announcementService:       service.NewAnnouncementService(store),
 	}
 }
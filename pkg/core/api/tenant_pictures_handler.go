@@ -2,7 +2,6 @@ package core
 
 import (
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -10,14 +9,15 @@ import (
 	"ctoup.com/coreapp/api/helpers"
 	"ctoup.com/coreapp/api/openapi/core"
 	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/service"
 	"ctoup.com/coreapp/pkg/shared/util"
 
 	"github.com/gin-gonic/gin"
 )
 
 func getTenantPictureFilePath(tenantID string, pictureType string) string {
-	newFilePath := fmt.Sprintf("/tenants/%s/core/pictures/%s.webp", tenantID, pictureType)
-	return newFilePath
+	return fileservice.TenantPicturePath(tenantID, pictureType)
 }
 
 // getTenantPicture is a generic function to get a tenant picture
@@ -33,6 +33,7 @@ func (s *TenantHandler) getTenantPicture(c *gin.Context, pictureType string) {
 
 	// Try to get the tenant-specific picture
 	filepath := getTenantPictureFilePath(tenantID.(string), pictureType)
+	service.ApplyTenantPublicHeaders(c, s.store)
 
 	s.FileService.GetFile(c, filepath)
 }
@@ -47,7 +48,7 @@ func (s *TenantHandler) uploadTenantPicture(c *gin.Context, pictureType string)
 		c.JSON(http.StatusInternalServerError, errors.New("TenantID not found"))
 		return
 	}
-	if !auth.HasAdminPrivileges(c) {
+	if !auth.Allowed(c, auth.OpManageUsers) {
 		logger.Error().Msg("Only CUSTOMER_ADMIN, ADMIN, SUPER_ADMIN or acting reseller can upload tenant pictures")
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only CUSTOMER_ADMIN, ADMIN, SUPER_ADMIN or acting reseller can upload tenant pictures"})
 		return
@@ -88,6 +89,10 @@ func (s *TenantHandler) uploadTenantPicture(c *gin.Context, pictureType string)
 	// Save the file with tenant-specific name
 	filepath := getTenantPictureFilePath(tenantID.(string), pictureType)
 	if err := s.FileService.SaveFile(c, byteContainer, filepath); err != nil {
+		if errors.Is(err, service.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Str("tenantID", tenantID.(string)).Str("pictureType", pictureType).Msg("Failed to save file")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// tenantProvisioning is the TenantProvisioning payload, the tenant being
+// answered as AddTenant does
+type tenantProvisioning struct {
+	Tenant         repository.CoreTenant         `json:"tenant"`
+	SourceTenantId string                        `json:"sourceTenantId"`
+	Template       *string                       `json:"template,omitempty"`
+	Steps          []core.TenantProvisioningStep `json:"steps"`
+}
+
+func toAPITenantProvisioning(provisioning service.TenantProvisioning) tenantProvisioning {
+	steps := make([]core.TenantProvisioningStep, len(provisioning.Steps))
+	for i, step := range provisioning.Steps {
+		steps[i] = core.TenantProvisioningStep{Name: step.Name, Copied: step.Copied}
+		if step.Error != "" {
+			stepError := step.Error
+			steps[i].Error = &stepError
+		}
+	}
+	return tenantProvisioning{
+		Tenant:         provisioning.Tenant,
+		SourceTenantId: provisioning.SourceTenantID,
+		Template:       provisioning.Template,
+		Steps:          steps,
+	}
+}
+
+func toAPITenantTemplate(template repository.CoreTenantTemplate) core.TenantTemplate {
+	return core.TenantTemplate{
+		Name:        template.Name,
+		Description: util.FromNullableText(template.Description),
+		TenantId:    template.TenantID,
+		CreatedBy:   template.CreatedBy,
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+}
+
+// provisionRequest binds the request body, and returns false when the
+// request was answered
+func (s *TenantHandler) provisionRequest(ctx *gin.Context, resellerID pgtype.Text) (service.TenantProvisionRequest, bool) {
+	var req core.TenantProvisionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return service.TenantProvisionRequest{}, false
+	}
+	if req.Name == "" || req.Subdomain == "" {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("name and subdomain are required"))
+		return service.TenantProvisionRequest{}, false
+	}
+	if !s.checkSubdomainNotAlias(ctx, req.Subdomain) {
+		return service.TenantProvisionRequest{}, false
+	}
+	return service.TenantProvisionRequest{
+		Name:                req.Name,
+		Subdomain:           req.Subdomain,
+		AllowPasswordSignUp: req.AllowPasswordSignUp,
+		AllowSignUp:         req.AllowSignUp,
+		ResellerID:          resellerID,
+		CreatedBy:           ctx.GetString(auth.AUTH_USER_ID),
+	}, true
+}
+
+// respondProvisioning answers the outcome of a provisioning
+func respondProvisioning(ctx *gin.Context, provisioning service.TenantProvisioning, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrTenantTemplateNotFound) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		if helpers.AbortIfDuplicate(ctx, err) {
+			return
+		}
+		logger := util.GetLoggerFromCtx(ctx.Request.Context())
+		logger.Err(err).Msg("Failed to provision tenant")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, toAPITenantProvisioning(provisioning))
+}
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/clone)
+func (s *TenantHandler) CloneTenant(ctx *gin.Context, id uuid.UUID) {
+	if err := auth.Authorize(ctx, auth.OpProvisionTenant); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	source, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	// The copy belongs to the reseller of the source tenant
+	req, ok := s.provisionRequest(ctx, source.ResellerID)
+	if !ok {
+		return
+	}
+	provisioning, err := s.tenantProvisioningService.CloneTenant(ctx, source, req)
+	respondProvisioning(ctx, provisioning, err)
+}
+
+// (GET /superadmin-api/v1/tenant-templates)
+func (s *TenantHandler) ListTenantTemplates(ctx *gin.Context) {
+	if err := auth.Authorize(ctx, auth.OpProvisionTenant); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	templates, err := s.tenantProvisioningService.ListTemplates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.TenantTemplate, len(templates))
+	for i, template := range templates {
+		result[i] = toAPITenantTemplate(template)
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// (PUT /superadmin-api/v1/tenant-templates/{name})
+func (s *TenantHandler) SaveTenantTemplate(ctx *gin.Context, name string) {
+	if err := auth.Authorize(ctx, auth.OpManageTenantTemplates); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	var req core.SaveTenantTemplateJSONRequestBody
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	source, ok := s.managedTenant(ctx, req.TenantId)
+	if !ok {
+		return
+	}
+	template, err := s.tenantProvisioningService.SaveTemplate(ctx, name, req.Description, source, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTenantTemplateName) {
+			ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantTemplate(template))
+}
+
+// (DELETE /superadmin-api/v1/tenant-templates/{name})
+func (s *TenantHandler) DeleteTenantTemplate(ctx *gin.Context, name string) {
+	if err := auth.Authorize(ctx, auth.OpManageTenantTemplates); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	if err := s.tenantProvisioningService.DeleteTemplate(ctx, name); err != nil {
+		if errors.Is(err, service.ErrTenantTemplateNotFound) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// (POST /superadmin-api/v1/tenant-templates/{name}/provision)
+func (s *TenantHandler) ProvisionTenantFromTemplate(ctx *gin.Context, name string) {
+	if err := auth.Authorize(ctx, auth.OpProvisionTenant); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	// A tenant provisioned by a reseller belongs to it
+	req, ok := s.provisionRequest(ctx, callerResellerID(ctx))
+	if !ok {
+		return
+	}
+	provisioning, err := s.tenantProvisioningService.ProvisionFromTemplate(ctx, name, req)
+	respondProvisioning(ctx, provisioning, err)
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func toAPITenantPurgeSteps(steps []service.TenantPurgeStepReport) []core.TenantPurgeStep {
+	result := make([]core.TenantPurgeStep, len(steps))
+	for i, step := range steps {
+		result[i] = core.TenantPurgeStep{Name: step.Name, Deleted: step.Deleted}
+		if len(step.Details) > 0 {
+			details := step.Details
+			result[i].Details = &details
+		}
+		if step.Error != "" {
+			stepError := step.Error
+			result[i].Error = &stepError
+		}
+	}
+	return result
+}
+
+func toAPITenantPurge(purge service.TenantPurge) core.TenantPurge {
+	return core.TenantPurge{
+		Id:             purge.TenantUuid,
+		TenantId:       purge.TenantID,
+		Name:           purge.TenantName,
+		Status:         purge.Status,
+		PreviousStatus: purge.PreviousStatus,
+		Reason:         util.FromNullableText(purge.Reason),
+		RequestedBy:    purge.RequestedBy,
+		PurgeAfter:     purge.PurgeAfter,
+		Attempts:       int(purge.Attempts),
+		Steps:          toAPITenantPurgeSteps(purge.Report),
+		LastError:      util.FromNullableText(purge.LastError),
+		StartedAt:      util.FromNullableTimestamptz(purge.StartedAt),
+		CompletedAt:    util.FromNullableTimestamptz(purge.CompletedAt),
+		CreatedAt:      purge.CreatedAt,
+	}
+}
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/purge)
+func (s *TenantHandler) ScheduleTenantPurge(ctx *gin.Context, id uuid.UUID, params core.ScheduleTenantPurgeParams) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	if err := auth.Authorize(ctx, auth.OpPurgeTenant); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	if params.DryRun != nil && *params.DryRun {
+		report := s.tenantPurgeService.DryRun(ctx, tenant)
+		ctx.JSON(http.StatusOK, core.TenantPurgeReport{
+			TenantId: report.TenantID,
+			DryRun:   report.DryRun,
+			Steps:    toAPITenantPurgeSteps(report.Steps),
+		})
+		return
+	}
+
+	// The body is optional, it only carries the reason
+	var req core.ScheduleTenantPurgeJSONRequestBody
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	purge, err := s.tenantPurgeService.SchedulePurge(ctx, tenant, req.Reason, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTenantPurgeScheduled),
+			errors.Is(err, service.ErrTenantPurgeReseller),
+			errors.Is(err, service.ErrTenantStatusTransition):
+			ctx.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to schedule tenant purge")
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	ctx.JSON(http.StatusAccepted, toAPITenantPurge(purge))
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/purge)
+func (s *TenantHandler) GetTenantPurge(ctx *gin.Context, id uuid.UUID) {
+	if err := auth.Authorize(ctx, auth.OpPurgeTenant); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	// The tenant is not looked up: the purge outlives it
+	purge, err := s.tenantPurgeService.GetPurge(ctx, id)
+	if err != nil {
+		if errors.Is(err, service.ErrTenantPurgeNotFound) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantPurge(purge))
+}
+
+// (DELETE /superadmin-api/v1/tenants/{tenantid}/purge)
+func (s *TenantHandler) CancelTenantPurge(ctx *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	if err := auth.Authorize(ctx, auth.OpPurgeTenant); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	purge, err := s.tenantPurgeService.CancelPurge(ctx, id, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTenantPurgeNotFound):
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		case errors.Is(err, service.ErrTenantPurgeStarted):
+			ctx.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Str("tenant", id.String()).Msg("Failed to cancel tenant purge")
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantPurge(purge))
+}
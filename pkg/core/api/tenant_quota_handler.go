@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantQuotaHandler exposes the limits of the resources of the tenants and
+// their usage
+type TenantQuotaHandler struct {
+	quotaService *access.TenantQuotaService
+}
+
+func NewTenantQuotaHandler(store *db.Store) *TenantQuotaHandler {
+	return &TenantQuotaHandler{
+		quotaService: access.NewTenantQuotaService(store, fileservice.NewFileService()),
+	}
+}
+
+func toAPITenantQuotaUsage(tenantID string, resources []access.TenantQuotaResource) core.TenantQuotaUsage {
+	result := core.TenantQuotaUsage{TenantId: tenantID, Resources: make([]core.TenantQuotaResource, len(resources))}
+	for i, resource := range resources {
+		result.Resources[i] = core.TenantQuotaResource{
+			Resource: resource.Resource,
+			Used:     resource.Used,
+			Limit:    resource.Limit,
+			Exceeded: resource.Exceeded(),
+		}
+	}
+	return result
+}
+
+// writeTenantQuotaUsage answers the usage of the tenant
+func (h *TenantQuotaHandler) writeTenantQuotaUsage(c *gin.Context, tenantID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	resources, err := h.quotaService.GetUsage(c, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to get tenant quota usage")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantQuotaUsage(tenantID, resources))
+}
+
+// (GET /api/v1/tenant/quotas)
+func (h *TenantQuotaHandler) GetTenantQuotaUsage(c *gin.Context) {
+	if err := auth.Authorize(c, auth.OpViewTenantQuotas); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The quotas must be read from a tenant"))
+		return
+	}
+	h.writeTenantQuotaUsage(c, tenantID)
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/quotas)
+func (h *TenantQuotaHandler) GetTenantQuotas(c *gin.Context, tenantid string) {
+	h.writeTenantQuotaUsage(c, tenantid)
+}
+
+// (PUT /superadmin-api/v1/tenants/{tenantid}/quotas)
+func (h *TenantQuotaHandler) SetTenantQuotas(c *gin.Context, tenantid string) {
+	var req core.SetTenantQuotasJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	err := h.quotaService.SetLimits(c, tenantid, access.TenantQuotaLimits{
+		MaxUsers:                    req.MaxUsers,
+		MaxClientApplications:       req.MaxClientApplications,
+		MaxStorageMB:                req.MaxStorageMb,
+		MaxPromptExecutionsPerMonth: req.MaxPromptExecutionsPerMonth,
+	}, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidTenantQuota) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	h.writeTenantQuotaUsage(c, tenantid)
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func toAPITenantSandbox(sandbox repository.CoreTenantSandbox) core.TenantSandbox {
+	result := core.TenantSandbox{
+		TenantId:        sandbox.TenantID,
+		CreatedBy:       sandbox.CreatedBy,
+		CreatedAt:       sandbox.CreatedAt,
+		UpdatedAt:       sandbox.UpdatedAt,
+		ResetRunning:    service.IsSandboxResetRunning(sandbox),
+		LastResetBy:     util.FromNullableText(sandbox.LastResetBy),
+		LastResetStatus: util.FromNullableText(sandbox.LastResetStatus),
+	}
+	if sandbox.LastResetAt.Valid {
+		result.LastResetAt = &sandbox.LastResetAt.Time
+	}
+	if sandbox.LastResetJobID.Valid {
+		jobID := uuid.UUID(sandbox.LastResetJobID.Bytes)
+		result.LastResetJobId = &jobID
+	}
+	return result
+}
+
+func toAPITenantSandboxResetPlan(plan service.TenantSandboxResetPlan) core.TenantSandboxResetPlan {
+	changes := make([]core.TenantSettingsChange, 0, len(plan.SettingsChanges))
+	for _, change := range plan.SettingsChanges {
+		changes = append(changes, core.TenantSettingsChange{
+			Path:     change.Path,
+			Op:       core.TenantSettingsChangeOp(change.Op),
+			OldValue: &change.OldValue,
+			NewValue: &change.NewValue,
+		})
+	}
+	return core.TenantSandboxResetPlan{
+		TenantId:          plan.TenantID,
+		MembersToRemove:   len(plan.Members),
+		SettingsChanges:   changes,
+		Steps:             plan.Steps,
+		ConfirmationToken: plan.ConfirmationToken,
+	}
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/sandbox)
+func (s *TenantHandler) GetTenantSandbox(ctx *gin.Context, id uuid.UUID) {
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	sandbox, err := s.tenantSandboxService.GetSandbox(ctx, tenant.TenantID)
+	if err != nil {
+		if errors.Is(err, service.ErrTenantNotSandbox) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantSandbox(sandbox))
+}
+
+// (PUT /superadmin-api/v1/tenants/{tenantid}/sandbox)
+func (s *TenantHandler) SetTenantSandbox(ctx *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	sandbox, err := s.tenantSandboxService.SetSandbox(ctx, tenant, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to set tenant sandbox")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantSandbox(sandbox))
+}
+
+// (DELETE /superadmin-api/v1/tenants/{tenantid}/sandbox)
+func (s *TenantHandler) DeleteTenantSandbox(ctx *gin.Context, id uuid.UUID) {
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	if err := s.tenantSandboxService.RemoveSandbox(ctx, tenant.TenantID); err != nil {
+		if errors.Is(err, service.ErrTenantNotSandbox) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/sandbox/reset)
+func (s *TenantHandler) ResetTenantSandbox(ctx *gin.Context, id uuid.UUID, params core.ResetTenantSandboxParams) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+
+	// Without confirm this is a preview: the caller reviews what the reset
+	// removes and sends the confirmation token back to start it
+	if params.Confirm == nil || *params.Confirm == "" {
+		plan, err := s.tenantSandboxService.PlanReset(ctx, tenant)
+		if err != nil {
+			s.handleTenantSandboxError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, toAPITenantSandboxResetPlan(plan))
+		return
+	}
+
+	authClient, err := s.authProvider.GetAuthClientForTenant(ctx, tenant.TenantID)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to get auth client for tenant")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	// The reset outlives the request, so it gets the request context rather
+	// than the gin context that is recycled once the handler returns
+	plan, job, err := s.tenantSandboxService.StartReset(ctx.Request.Context(), authClient, tenant, *params.Confirm, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, service.ErrSandboxResetChanged) || errors.Is(err, service.ErrSandboxResetRunning) {
+			ctx.JSON(http.StatusConflict, toAPITenantSandboxResetPlan(plan))
+			return
+		}
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to start tenant sandbox reset")
+		s.handleTenantSandboxError(ctx, err)
+		return
+	}
+	result := toAPITenantSandboxResetPlan(plan)
+	result.JobId = &job.ID
+	ctx.Header("X-Job-Id", job.ID.String())
+	ctx.JSON(http.StatusAccepted, result)
+}
+
+func (s *TenantHandler) handleTenantSandboxError(ctx *gin.Context, err error) {
+	if errors.Is(err, service.ErrTenantNotSandbox) {
+		ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxTenantSettingsDocumentSize bounds the import body; branding and configs
+// are small, anything larger is not a settings document
+const maxTenantSettingsDocumentSize = 1 << 20
+
+// (GET /superadmin-api/v1/tenant/{tenantid}/settings/export)
+func (s *TenantHandler) ExportTenantSettings(ctx *gin.Context, id uuid.UUID, params core.ExportTenantSettingsParams) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	isAllowed, err := auth.IsAllowedToManageTenantByID(ctx, s.store, id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return
+	}
+	if !isAllowed {
+		ctx.JSON(http.StatusForbidden, "Not allowed to manage this tenant")
+		return
+	}
+
+	tenant, err := s.store.GetTenantByID(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	doc, err := s.tenantSettingsService.ExportTenantSettings(ctx, tenant)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to export tenant settings")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	if params.Format != nil && *params.Format == core.ExportTenantSettingsParamsFormatYaml {
+		data, err := service.EncodeTenantSettingsYAML(doc)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", tenant.TenantID+"-settings.yaml"))
+		ctx.Data(http.StatusOK, "application/yaml", data)
+		return
+	}
+	ctx.JSON(http.StatusOK, doc)
+}
+
+// (POST /superadmin-api/v1/tenant/{tenantid}/settings/import)
+func (s *TenantHandler) ImportTenantSettings(ctx *gin.Context, id uuid.UUID, params core.ImportTenantSettingsParams) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	userID, exists := ctx.Get(auth.AUTH_USER_ID)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	isAllowed, err := auth.IsAllowedToManageTenantByID(ctx, s.store, id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return
+	}
+	if !isAllowed {
+		ctx.JSON(http.StatusForbidden, "Not allowed to manage this tenant")
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxTenantSettingsDocumentSize)
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	doc, err := service.DecodeTenantSettingsDocument(body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	tenant, err := s.store.GetTenantByID(ctx, id)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	// Without confirm this is a preview: the caller reviews the diff and sends
+	// its fingerprint back to apply exactly those changes
+	if params.Confirm == nil || *params.Confirm == "" {
+		plan, err := s.tenantSettingsService.PlanTenantSettingsImport(ctx, tenant, doc)
+		if err != nil {
+			s.handleTenantSettingsImportError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, plan)
+		return
+	}
+
+	plan, err := s.tenantSettingsService.ApplyTenantSettingsImport(ctx, tenant, doc, *params.Confirm, userID.(string))
+	if err != nil {
+		if errors.Is(err, service.ErrTenantSettingsDiffChanged) {
+			ctx.JSON(http.StatusConflict, plan)
+			return
+		}
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to import tenant settings")
+		s.handleTenantSettingsImportError(ctx, err)
+		return
+	}
+	if plan.Applied {
+		s.multiTenantService.InvalidateTenant(tenant.TenantID)
+		service.InvalidateTenantPublicHeaders(tenant.TenantID)
+		service.InvalidateDisplayNamePolicy(tenant.TenantID)
+		service.InvalidateReplayCapture(tenant.TenantID)
+	}
+	ctx.JSON(http.StatusOK, plan)
+}
+
+func (s *TenantHandler) handleTenantSettingsImportError(ctx *gin.Context, err error) {
+	if errors.Is(err, service.ErrUnsupportedSettingsVersion) {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+}
+
+func toAPITenantSettingValues(values map[string]interface{}) core.TenantSettingValues {
+	defs := service.TenantSettingDefinitions()
+	result := core.TenantSettingValues{Values: values, Definitions: make([]core.TenantSettingDefinition, len(defs))}
+	for i, def := range defs {
+		var schema map[string]interface{}
+		_ = json.Unmarshal([]byte(def.Schema), &schema)
+		result.Definitions[i] = core.TenantSettingDefinition{
+			Key:         def.Key,
+			Description: def.Description,
+			Schema:      schema,
+			Default:     def.Default,
+			Public:      def.Public,
+		}
+	}
+	return result
+}
+
+// (GET /api/v1/tenant/settings)
+func (s *TenantHandler) GetTenantSettingValues(ctx *gin.Context) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	tenantID := ctx.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The settings must be read from a tenant"))
+		return
+	}
+	values, err := s.tenantSettingsService.GetSettingValues(ctx, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenantID).Msg("Failed to get tenant settings")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantSettingValues(values))
+}
+
+// (PUT /api/v1/tenant/settings)
+func (s *TenantHandler) UpdateTenantSettingValues(ctx *gin.Context) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	if err := auth.Authorize(ctx, auth.OpManageTenantSettings); err != nil {
+		ctx.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := ctx.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The settings must be set on a tenant"))
+		return
+	}
+	var req core.UpdateTenantSettingValuesJSONRequestBody
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	changes := make(map[string]json.RawMessage, len(req.Values))
+	for key, value := range req.Values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		changes[key] = raw
+	}
+
+	values, err := s.tenantSettingsService.UpdateSettingValues(ctx, tenantID, changes, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		var invalid *service.TenantSettingValuesError
+		if errors.As(err, &invalid) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"message":    invalid.Error(),
+				"code":       "INVALID_TENANT_SETTINGS",
+				"field":      "values",
+				"violations": invalid.Violations,
+			})
+			return
+		}
+		logger.Err(err).Str("tenant_id", tenantID).Msg("Failed to update tenant settings")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantSettingValues(values))
+}
+
+// (GET /public-api/v1/tenant/settings)
+func (s *TenantHandler) GetPublicTenantSettings(ctx *gin.Context) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	tenant, ok := service.GetTenantFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, helpers.ErrorStringResponse("Tenant not found"))
+		return
+	}
+	values := service.TenantSettingValuesFromContext(ctx)
+	if values == nil {
+		var err error
+		values, err = s.tenantSettingsService.GetSettingValues(ctx, tenant.TenantID)
+		if err != nil {
+			logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to get tenant settings")
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, core.PublicTenantSettings{
+		AllowSignUp:         tenant.AllowSignUp,
+		AllowPasswordSignUp: tenant.AllowPasswordSignUp,
+		Settings:            service.PublicSettingValues(values),
+	})
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// (POST /superadmin-api/v1/tenants/{tenantid}/status)
+func (s *TenantHandler) SetTenantStatus(ctx *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	var req core.SetTenantStatusJSONRequestBody
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	updated, err := s.multiTenantService.SetTenantStatus(ctx, tenant, string(req.Status), req.Reason, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTenantStatus):
+			ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, service.ErrTenantStatusTransition):
+			ctx.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to set tenant status")
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, updated)
+}
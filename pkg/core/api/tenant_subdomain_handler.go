@@ -0,0 +1,147 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// managedTenant loads the tenant once the caller is checked to manage it.
+// It answers the request and returns false otherwise.
+func (s *TenantHandler) managedTenant(ctx *gin.Context, id uuid.UUID) (repository.CoreTenant, bool) {
+	isAllowed, err := auth.IsAllowedToManageTenantByID(ctx, s.store, id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+		return repository.CoreTenant{}, false
+	}
+	if !isAllowed {
+		ctx.JSON(http.StatusForbidden, "Not allowed to manage this tenant")
+		return repository.CoreTenant{}, false
+	}
+	tenant, err := s.store.GetTenantByID(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return repository.CoreTenant{}, false
+	}
+	return tenant, true
+}
+
+// checkSubdomainNotAlias answers 409 when the subdomain requested for a
+// tenant is an alias, and returns false when the request was answered
+func (s *TenantHandler) checkSubdomainNotAlias(ctx *gin.Context, subdomain string) bool {
+	err := s.multiTenantService.EnsureSubdomainNotAlias(ctx, subdomain)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrSubdomainTaken):
+		ctx.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+	return false
+}
+
+func toAPITenantSubdomain(subdomain service.TenantSubdomain) core.TenantSubdomain {
+	return core.TenantSubdomain{
+		Subdomain: subdomain.Subdomain,
+		Primary:   subdomain.Primary,
+		CreatedBy: subdomain.CreatedBy,
+		CreatedAt: subdomain.CreatedAt,
+	}
+}
+
+// (GET /superadmin-api/v1/tenant/{tenantid}/subdomains)
+func (s *TenantHandler) ListTenantSubdomains(ctx *gin.Context, id uuid.UUID) {
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	subdomains, err := s.multiTenantService.ListTenantSubdomains(ctx, tenant)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.TenantSubdomain, len(subdomains))
+	for i, subdomain := range subdomains {
+		result[i] = toAPITenantSubdomain(subdomain)
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// (POST /superadmin-api/v1/tenant/{tenantid}/subdomains)
+func (s *TenantHandler) AddTenantSubdomainAlias(ctx *gin.Context, id uuid.UUID) {
+	logger := util.GetLoggerFromCtx(ctx.Request.Context())
+	var req core.AddTenantSubdomainAliasJSONRequestBody
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msg("Failed to bind request body")
+		ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+
+	primary := req.Primary != nil && *req.Primary
+	subdomain, err := s.multiTenantService.AddTenantSubdomainAlias(ctx, tenant, req.Subdomain, primary, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSubdomain):
+			ctx.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, service.ErrSubdomainTaken):
+			ctx.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, toAPITenantSubdomain(subdomain))
+}
+
+// (DELETE /superadmin-api/v1/tenant/{tenantid}/subdomains/{subdomain})
+func (s *TenantHandler) DeleteTenantSubdomainAlias(ctx *gin.Context, id uuid.UUID, subdomain string) {
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	if subdomain == tenant.Subdomain {
+		ctx.JSON(http.StatusConflict, helpers.ErrorStringResponse("the primary subdomain cannot be removed"))
+		return
+	}
+	if err := s.multiTenantService.RemoveTenantSubdomainAlias(ctx, tenant, subdomain); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// (POST /superadmin-api/v1/tenant/{tenantid}/subdomains/{subdomain}/primary)
+func (s *TenantHandler) SetPrimaryTenantSubdomain(ctx *gin.Context, id uuid.UUID, subdomain string) {
+	tenant, ok := s.managedTenant(ctx, id)
+	if !ok {
+		return
+	}
+	primary, err := s.multiTenantService.SetPrimaryTenantSubdomain(ctx, tenant, subdomain, ctx.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPITenantSubdomain(primary))
+}
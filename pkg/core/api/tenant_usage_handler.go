@@ -0,0 +1,170 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// TenantUsageHandler exposes the metered usage of the tenants and the
+// recommendations drawn from it
+type TenantUsageHandler struct {
+	meteringService       *access.UsageMeteringService
+	recommendationService *access.UsageRecommendationService
+}
+
+func NewTenantUsageHandler(store *db.Store) *TenantUsageHandler {
+	return &TenantUsageHandler{
+		meteringService:       access.NewUsageMeteringService(store),
+		recommendationService: access.NewUsageRecommendationService(store, access.UsageRecommendationConfigFromEnv()),
+	}
+}
+
+// tenantUsageRange returns the requested range, the last 30 days by default
+func tenantUsageRange(from, to *time.Time) (time.Time, time.Time) {
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -30)
+	if from != nil {
+		start = *from
+	}
+	return start, end
+}
+
+func toAPITenantUsageTotals(totals map[string]int64) []core.TenantUsageMetricCount {
+	result := make([]core.TenantUsageMetricCount, 0, len(totals))
+	for _, metric := range access.UsageMetrics {
+		result = append(result, core.TenantUsageMetricCount{Metric: metric, Count: totals[metric]})
+	}
+	return result
+}
+
+// (GET /api/v1/tenant/usage)
+func (h *TenantUsageHandler) GetTenantUsage(c *gin.Context, params core.GetTenantUsageParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := auth.Authorize(c, auth.OpViewTenantUsage); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The usage must be read from a tenant"))
+		return
+	}
+
+	from, to := tenantUsageRange(params.From, params.To)
+	usage, err := h.meteringService.GetTenantUsage(c, tenantID, from, to)
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidTenantUsageRange) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get tenant usage")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := core.TenantUsage{
+		TenantId:     usage.TenantID,
+		From:         usage.From,
+		To:           usage.To,
+		Users:        usage.Users,
+		ApiTokens:    usage.APITokens,
+		StorageBytes: usage.StorageBytes,
+		Totals:       toAPITenantUsageTotals(usage.Totals),
+		Daily:        make([]core.TenantUsageDailyCount, len(usage.Daily)),
+	}
+	for i, count := range usage.Daily {
+		result.Daily[i] = core.TenantUsageDailyCount{
+			Day:    openapi_types.Date{Time: count.Bucket.UTC()},
+			Metric: count.Metric,
+			Count:  count.Count,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (GET /superadmin-api/v1/usage)
+func (h *TenantUsageHandler) ListTenantsUsage(c *gin.Context, params core.ListTenantsUsageParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	from, to := tenantUsageRange(params.From, params.To)
+	tenants, err := h.meteringService.ListTenantUsageTotals(c, from, to)
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidTenantUsageRange) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to list tenants usage")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := core.TenantsUsage{From: from, To: to, Tenants: make([]core.TenantUsageTotals, len(tenants))}
+	for i, tenant := range tenants {
+		result.Tenants[i] = core.TenantUsageTotals{TenantId: tenant.TenantID, Totals: toAPITenantUsageTotals(tenant.Totals)}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func toAPITenantUsageRecommendations(recommendations access.TenantUsageRecommendations) core.TenantUsageRecommendations {
+	result := core.TenantUsageRecommendations{
+		TenantId:        recommendations.TenantID,
+		GeneratedAt:     recommendations.GeneratedAt,
+		Recommendations: make([]core.TenantUsageRecommendation, len(recommendations.Recommendations)),
+	}
+	for i, rec := range recommendations.Recommendations {
+		result.Recommendations[i] = core.TenantUsageRecommendation{
+			Resource:       rec.Resource,
+			Severity:       core.TenantUsageRecommendationSeverity(rec.Severity),
+			Used:           rec.Used,
+			Limit:          rec.Limit,
+			DailyRate:      rec.DailyRate,
+			LimitReachedAt: rec.LimitReachedAt,
+			SuggestedLimit: rec.SuggestedLimit,
+			Message:        rec.Message,
+		}
+	}
+	return result
+}
+
+// writeTenantUsageRecommendations answers the recommendations of the tenant
+func (h *TenantUsageHandler) writeTenantUsageRecommendations(c *gin.Context, tenantID string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	recommendations, err := h.recommendationService.GetRecommendations(c, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to get tenant usage recommendations")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITenantUsageRecommendations(recommendations))
+}
+
+// (GET /api/v1/tenant/usage/recommendations)
+func (h *TenantUsageHandler) GetTenantUsageRecommendations(c *gin.Context) {
+	if err := auth.Authorize(c, auth.OpViewTenantUsage); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The recommendations must be read from a tenant"))
+		return
+	}
+	h.writeTenantUsageRecommendations(c, tenantID)
+}
+
+// (GET /superadmin-api/v1/tenants/{tenantid}/usage/recommendations)
+func (h *TenantUsageHandler) ListTenantUsageRecommendations(c *gin.Context, tenantid string) {
+	h.writeTenantUsageRecommendations(c, tenantid)
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+func toAPITokenPolicy(policy repository.CoreTokenPolicy) core.TokenPolicy {
+	result := core.TokenPolicy{
+		TenantId:           policy.TenantID,
+		MaxExpiryDays:      util.FromNullableInt4(policy.MaxExpiryDays),
+		RequireDescription: policy.RequireDescription,
+		UpdatedBy:          policy.UpdatedBy,
+		UpdatedAt:          policy.UpdatedAt,
+	}
+	if policy.AllowedScopes != nil {
+		result.AllowedScopes = &policy.AllowedScopes
+	}
+	return result
+}
+
+// ListTokenPolicies lists the token policies of the tenants
+// (GET /superadmin-api/v1/token-policies)
+func (h *ClientApplicationHandler) ListTokenPolicies(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	policies, err := h.clientAppService.ListTokenPolicies(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list token policies")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	result := make([]core.TokenPolicy, len(policies))
+	for i, policy := range policies {
+		result[i] = toAPITokenPolicy(policy)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTokenPolicy returns the token policy of a tenant
+// (GET /superadmin-api/v1/tenants/{tenantid}/token-policy)
+func (h *ClientApplicationHandler) GetTokenPolicy(c *gin.Context, tenantid string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	policy, err := h.clientAppService.GetTokenPolicy(c, tenantid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("tenantID", tenantid).Msg("Failed to get token policy")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITokenPolicy(policy))
+}
+
+// SetTokenPolicy creates or replaces the token policy of a tenant
+// (PUT /superadmin-api/v1/tenants/{tenantid}/token-policy)
+func (h *ClientApplicationHandler) SetTokenPolicy(c *gin.Context, tenantid string) {
+	var req core.SetTokenPolicyJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	input := access.TokenPolicyInput{
+		MaxExpiryDays: req.MaxExpiryDays,
+		AllowedScopes: req.AllowedScopes,
+	}
+	if req.RequireDescription != nil {
+		input.RequireDescription = *req.RequireDescription
+	}
+
+	policy, err := h.clientAppService.SetTokenPolicy(c, tenantid, input, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		if errors.Is(err, access.ErrInvalidTokenPolicy) {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPITokenPolicy(policy))
+}
+
+// DeleteTokenPolicy removes the token policy of a tenant
+// (DELETE /superadmin-api/v1/tenants/{tenantid}/token-policy)
+func (h *ClientApplicationHandler) DeleteTokenPolicy(c *gin.Context, tenantid string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := h.clientAppService.DeleteTokenPolicy(c, tenantid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Str("tenantID", tenantid).Msg("Failed to delete token policy")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	logger.Info().Str("tenantID", tenantid).Msg("Token policy removed")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// TOTPHandler manages the TOTP second factor of the current user. Unlike
+// MFAHandler, it works with every auth provider.
+type TOTPHandler struct {
+	authProvider auth.AuthProvider
+	mfaService   *access.MFAService
+}
+
+func NewTOTPHandler(store *db.Store, authProvider auth.AuthProvider) *TOTPHandler {
+	return &TOTPHandler{
+		authProvider: authProvider,
+		mfaService:   access.NewMFAService(store, access.MFAConfigFromEnv()),
+	}
+}
+
+// totpErrorStatus maps the errors of the MFA service to a status
+func totpErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidMFACode):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrMFANotEnrolled):
+		return http.StatusNotFound
+	case errors.Is(err, access.ErrMFAAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, access.ErrMFALocked):
+		return http.StatusTooManyRequests
+	case errors.Is(err, access.ErrMFANotConfigured):
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// authClient returns the auth client of the request domain, which stores the
+// TOTP claim
+func (h *TOTPHandler) authClient(c *gin.Context) (auth.AuthClient, bool) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	subdomain, err := util.GetSubdomain(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return nil, false
+	}
+	authClient, err := h.authProvider.GetAuthClientForSubdomain(c, subdomain)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for subdomain")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return nil, false
+	}
+	return authClient, true
+}
+
+// (GET /api/v1/users/me/mfa)
+func (h *TOTPHandler) GetMyTOTPStatus(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	status, err := h.mfaService.GetTOTPStatus(c, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		logger.Err(err).Msg("Failed to get TOTP status")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, core.TOTPStatus{
+		Enabled:                status.Enabled,
+		Pending:                status.Pending,
+		EnabledAt:              status.EnabledAt,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+// (POST /api/v1/users/me/mfa/enroll)
+func (h *TOTPHandler) EnrollMyTOTP(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	enrollment, err := h.mfaService.EnrollTOTP(c, c.GetString(auth.AUTH_USER_ID), c.GetString(auth.AUTH_EMAIL))
+	if err != nil {
+		status := totpErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Msg("Failed to enroll TOTP")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, core.TOTPEnrollment{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.URI,
+	})
+}
+
+// (POST /api/v1/users/me/mfa/verify)
+func (h *TOTPHandler) VerifyMyTOTP(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.VerifyMyTOTPJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+
+	verification, err := h.mfaService.VerifyTOTP(c, authClient, c.GetString(auth.AUTH_USER_ID), req.Code)
+	if err != nil {
+		status := totpErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Msg("Failed to verify TOTP")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+	result := core.TOTPVerification{
+		MfaToken:  verification.Token,
+		ExpiresAt: verification.ExpiresAt,
+	}
+	if verification.RecoveryCodes != nil {
+		result.RecoveryCodes = &verification.RecoveryCodes
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
+
+// (POST /api/v1/users/me/mfa/disable)
+func (h *TOTPHandler) DisableMyTOTP(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.DisableMyTOTPJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(c, authClient, c.GetString(auth.AUTH_USER_ID), req.Code); err != nil {
+		status := totpErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Msg("Failed to disable TOTP")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (POST /api/v1/users/me/mfa/recovery-codes)
+func (h *TOTPHandler) RegenerateMyTOTPRecoveryCodes(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.RegenerateMyTOTPRecoveryCodesJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c, c.GetString(auth.AUTH_USER_ID), req.Code)
+	if err != nil {
+		status := totpErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Msg("Failed to regenerate TOTP recovery codes")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, core.TOTPRecoveryCodes{RecoveryCodes: codes})
+}
@@ -1,34 +1,44 @@
 package core
 
 import (
-	"encoding/csv"
+	"bytes"
+	"context"
+	"encoding/json"
 	"io"
-	"strings"
 	"time"
 
 	"errors"
 	"fmt"
 	"net/http"
+	"unicode/utf8"
 
 	"ctoup.com/coreapp/api/helpers"
-	api "ctoup.com/coreapp/api/openapi/core"
 	core "ctoup.com/coreapp/api/openapi/core"
 	"ctoup.com/coreapp/pkg/core/db"
 	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/service"
 	auth "ctoup.com/coreapp/pkg/shared/auth"
 	"ctoup.com/coreapp/pkg/shared/event"
 	"ctoup.com/coreapp/pkg/shared/repository/subentity"
 	access "ctoup.com/coreapp/pkg/shared/service"
 	"ctoup.com/coreapp/pkg/shared/util"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5"
 )
 
 // https://pkg.go.dev/github.com/go-playground/validator/v10#hdr-One_Of
 type UserAdminHandler struct {
-	store        *db.Store
-	authProvider auth.AuthProvider
-	userService  access.UserService
+	store         *db.Store
+	authProvider  auth.AuthProvider
+	userService   access.UserService
+	jobService    *access.JobService
+	exportService *access.UserExportService
+	bulkService   *access.BulkUserService
+	erasure       *access.UserErasureService
+	attributes    *access.UserAttributeService
+	auditLogs     *access.UserAuditLogService
+	labels        *access.UserLabelService
+	verification  *service.EmailVerificationService
 }
 
 func NewUserAdminHandler(store *db.Store, authProvider auth.AuthProvider) *UserAdminHandler {
@@ -43,8 +53,16 @@ func NewUserAdminHandler(store *db.Store, authProvider auth.AuthProvider) *UserA
 	}
 
 	handler := &UserAdminHandler{store: store,
-		authProvider: authProvider,
-		userService:  userService}
+		authProvider:  authProvider,
+		userService:   userService,
+		jobService:    access.NewJobService(store),
+		exportService: access.NewUserExportService(store, userService),
+		bulkService:   access.NewBulkUserService(userService, access.NewUserGuardPolicy(store)),
+		erasure:       access.NewUserErasureService(store),
+		attributes:    access.NewUserAttributeService(store),
+		auditLogs:     access.NewUserAuditLogService(store),
+		labels:        access.NewUserLabelService(store),
+		verification:  service.NewEmailVerificationService(store, authProvider)}
 	return handler
 }
 
@@ -70,6 +88,12 @@ func (uh *UserAdminHandler) AddUser(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, helpers.ErrorResponse(err))
 		return
 	}
+	if abortIfInvalidDisplayName(c, uh.store, tenantID.(string), "", req.Name) {
+		return
+	}
+	if abortIfInvalidPhoneNumber(c, req.PhoneNumber) {
+		return
+	}
 
 	subdomain, err := util.GetSubdomain(c)
 	if err != nil {
@@ -90,10 +114,15 @@ func (uh *UserAdminHandler) AddUser(c *gin.Context) {
 
 	user, err := uh.userService.CreateUser(c, baseAuthClient, tenantID.(string), req, nil)
 	if err != nil {
+		if errors.Is(err, access.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Msg("Failed to add user")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	access.RecordUsage(c, tenantID.(string), access.UsageMetricUsersAdded, 1)
 	if !silent {
 		url, err := getWelcomeEmailURL(c)
 		if err != nil {
@@ -132,6 +161,17 @@ func (uh *UserAdminHandler) UpdateUser(c *gin.Context, userid string) {
 		c.JSON(http.StatusUnauthorized, helpers.ErrorResponse(err))
 		return
 	}
+	if abortIfInvalidDisplayName(c, uh.store, tenantID.(string), userid, req.Name) {
+		return
+	}
+	// The attributes given are merged into the profile, the others are kept
+	var attributes map[string]interface{}
+	if req.Profile != nil && req.Profile.Attributes != nil {
+		attributes = *req.Profile.Attributes
+		if abortIfInvalidUserAttributes(c, uh.attributes.CheckAttributes(c, tenantID.(string), attributes)) {
+			return
+		}
+	}
 
 	subdomain, err := util.GetSubdomain(c)
 	if err != nil {
@@ -153,13 +193,41 @@ func (uh *UserAdminHandler) UpdateUser(c *gin.Context, userid string) {
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	if len(attributes) > 0 {
+		if err := uh.attributes.SetUserAttributes(c, tenantID.(string), userid, attributes); err != nil {
+			logger.Err(err).Msg("Failed to update user attributes")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+	}
 	c.Status(http.StatusNoContent)
 }
 
 // DeleteUser implements openapi.ServerInterface.
-func (uh *UserAdminHandler) DeleteUser(c *gin.Context, userid string) {
+func (uh *UserAdminHandler) DeleteUser(c *gin.Context, userid string, params core.DeleteUserParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
 
+	transfer, err := ownershipTransferFromParams(params.TransferTo, params.TransferToTenantOwner)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	anonymize := false
+	if params.Mode != nil {
+		switch *params.Mode {
+		case core.DeleteUserParamsModeAnonymize:
+			anonymize = true
+		case core.DeleteUserParamsModeDelete:
+		default:
+			c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("mode must be delete or anonymize"))
+			return
+		}
+	}
+	if anonymize && (params.ConfirmIrreversible == nil || !*params.ConfirmIrreversible) {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(access.ErrErasureNotConfirmed))
+		return
+	}
+
 	tenantID, exists := c.Get(auth.AUTH_TENANT_ID_KEY)
 	if !exists {
 		logger.Error().Msg("TenantID not found")
@@ -167,23 +235,16 @@ func (uh *UserAdminHandler) DeleteUser(c *gin.Context, userid string) {
 		return
 	}
 
-	// check if user is deleting self
-	if userid == c.GetString(auth.AUTH_USER_ID) {
-		logger.Error().Msg("Cannot delete self")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Cannot delete self"})
-		return
-	}
 	// check if user has rights to delete user CUSTOMER_ADMIN, ADMIN, SUPER_ADMIN
-	if !auth.HasAdminPrivileges(c) {
+	if !auth.Allowed(c, auth.OpManageUsers) {
 		logger.Error().Msg("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can delete user")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can delete user"})
 		return
 	}
 	var user core.User
-	var err error
 
 	if tenantID == "" {
-		if !auth.IsSuperAdmin(c) {
+		if !auth.Allowed(c, auth.OpManageGlobalUsers) {
 			logger.Error().Msg("Only SUPER_ADMIN can delete user without tenant")
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Only SUPER_ADMIN can delete user without tenant"})
 			return
@@ -225,14 +286,21 @@ func (uh *UserAdminHandler) DeleteUser(c *gin.Context, userid string) {
 		return
 	}
 
+	if anonymize {
+		uh.anonymizeUser(c, baseAuthClient, tenantID.(string), userid, transfer)
+		return
+	}
 	if tenantID == "" {
 		// No tenant context — hard delete the user globally
-		err = uh.userService.DeleteUser(c, baseAuthClient, userid)
+		err = uh.userService.DeleteUser(c, baseAuthClient, userid, transfer)
 	} else {
 		// Tenant context present — soft delete (set membership status to inactive)
-		err = uh.userService.RemoveUserFromTenant(c, baseAuthClient, tenantID.(string), userid)
+		err = uh.userService.DeleteUserFromTenant(c, baseAuthClient, tenantID.(string), userid, transfer)
 	}
 	if err != nil {
+		if abortIfOwnershipTransferFailed(c, err) {
+			return
+		}
 		if helpers.AbortIfReferenced(c, err,
 			"USER_IN_USE",
 			"user is referenced by other records and cannot be deleted") {
@@ -245,6 +313,36 @@ func (uh *UserAdminHandler) DeleteUser(c *gin.Context, userid string) {
 	c.Status(http.StatusNoContent)
 }
 
+// anonymizeUser erases the user for good. From a tenant, the user must not be
+// known anywhere else.
+func (uh *UserAdminHandler) anonymizeUser(c *gin.Context, authClient auth.AuthClient, tenantID, userID string, transfer access.OwnershipTransfer) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if tenantID != "" {
+		if err := uh.erasure.CheckTenantErasure(c, userID); err != nil {
+			if errors.Is(err, access.ErrErasureOutOfTenant) {
+				c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+				return
+			}
+			logger.Err(err).Msg("Failed to check user erasure")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
+		}
+	}
+	if _, err := uh.erasure.AnonymizeUser(c, authClient, userID, transfer); err != nil {
+		if abortIfOwnershipTransferFailed(c, err) {
+			return
+		}
+		logger.Err(err).Msg("Failed to erase user")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	// A provider outage must not let the erased user back in
+	if reporter, ok := uh.authProvider.(auth.HealthReporter); ok {
+		reporter.GetHealthMonitor().ForgetUser(userID)
+	}
+	c.Status(http.StatusNoContent)
+}
+
 // RemoveUserFromTenant removes a user from the current tenant (deletes membership only)
 // (DELETE /api/v1/users/{userid}/remove-from-tenant)
 func (uh *UserAdminHandler) RemoveUserFromTenant(c *gin.Context, userid string) {
@@ -257,15 +355,8 @@ func (uh *UserAdminHandler) RemoveUserFromTenant(c *gin.Context, userid string)
 		return
 	}
 
-	// Check if user is removing self
-	if userid == c.GetString(auth.AUTH_USER_ID) {
-		logger.Error().Msg("Cannot remove self from tenant")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Cannot remove self from tenant"})
-		return
-	}
-
 	// Check if user has rights to remove user (CUSTOMER_ADMIN, ADMIN, SUPER_ADMIN)
-	if !auth.HasAdminPrivileges(c) {
+	if !auth.Allowed(c, auth.OpManageUsers) {
 		logger.Error().Msg("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can remove user from tenant")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can remove user from tenant"})
 		return
@@ -330,7 +421,7 @@ func (uh *UserAdminHandler) GetUserByID(c *gin.Context, id string) {
 
 	// in case root domain is used
 	if tenantID == "" {
-		if !auth.IsSuperAdmin(c) {
+		if !auth.Allowed(c, auth.OpManageGlobalUsers) {
 			logger.Error().Msg("Only SUPER_ADMIN can get user without tenant")
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Only SUPER_ADMIN can get user without tenant"})
 			return
@@ -348,6 +439,11 @@ func (uh *UserAdminHandler) GetUserByID(c *gin.Context, id string) {
 
 	user, err := uh.userService.GetUserByTenantIDByID(c, tenantID.(string), id)
 	if err != nil {
+		// A user of another tenant is answered like an unknown one
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("user not found in this tenant")))
+			return
+		}
 		logger.Err(err).Msg("Failed to get user by ID")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
@@ -378,48 +474,198 @@ func (u *UserAdminHandler) ListUsers(c *gin.Context, params core.ListUsersParams
 	}
 	pagingSql := helpers.GetPagingSQL(pagingRequest)
 
-	like := pgtype.Text{
-		Valid: false,
+	if params.InactiveDays != nil && *params.InactiveDays < 1 {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("inactiveDays must be at least 1")))
+		return
 	}
-
-	if params.Q != nil {
-		like.String = *params.Q + "%"
-		like.Valid = true
+	role, err := access.UserRoleFilter(params.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	labels, err := access.UserLabelsFilter(params.Labels)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if labels != nil && (tenantID.(string) == "" || (params.Scope != nil && *params.Scope == core.All)) {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("labels are set per tenant, list the users of a tenant to filter on them")))
+		return
+	}
+	if params.CreatedFrom != nil && params.CreatedTo != nil && params.CreatedFrom.After(*params.CreatedTo) {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("createdFrom must not be after createdTo")))
+		return
+	}
+	filter := access.UserListFilter{
+		Search:        access.UserSearch(params.Q),
+		InactiveSince: access.InactiveSince(params.InactiveDays),
+		Role:          role,
+		Labels:        labels,
+		Disabled:      util.ToNullableBool(params.Disabled),
+		EmailVerified: util.ToNullableBool(params.EmailVerified),
+		CreatedFrom:   util.ToNullableTimestamptz(params.CreatedFrom),
+		CreatedTo:     util.ToNullableTimestamptz(params.CreatedTo),
 	}
 
 	var users []core.User
-	var err error
+	var total int64
 	if params.Scope != nil && *params.Scope == core.All {
 		// Listing every user system-wide exposes cross-tenant PII — restrict to
 		// super admins (used by the admin domain to find a user to promote to a
 		// global role).
-		if !auth.IsSuperAdmin(c) {
+		if !auth.Allowed(c, auth.OpManageGlobalUsers) {
 			logger.Error().Msg("Only super admins may list all users")
 			c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("only super admins may list all users")))
 			return
 		}
-		users, err = u.userService.ListAllUsers(c, pagingSql, like)
+		users, err = u.userService.ListAllUsers(c, pagingSql, filter)
+		if err == nil {
+			total, err = u.userService.CountAllUsers(c, filter)
+		}
 	} else {
-		users, err = u.userService.ListUsers(c, tenantID.(string), pagingSql, like)
+		users, err = u.userService.ListUsers(c, tenantID.(string), pagingSql, filter)
+		if err == nil {
+			total, err = u.userService.CountUsers(c, tenantID.(string), filter)
+		}
 	}
 	if err != nil {
 		logger.Err(err).Msg("Failed to list users")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	helpers.SetPagingHeaders(c, total, pagingSql)
+
+	items := make([]interface{}, len(users))
 	if params.Detail != nil && *params.Detail == "basic" {
-		basicEntities := make([]subentity.BasicEntity, 0)
-		for _, user := range users {
-			basicEntity := subentity.BasicEntity{
+		for i, user := range users {
+			items[i] = subentity.BasicEntity{
 				ID:   user.Id,
 				Name: user.Profile.Name,
 			}
-			basicEntities = append(basicEntities, basicEntity)
 		}
-		c.JSON(http.StatusOK, basicEntities)
 	} else {
-		c.JSON(http.StatusOK, users)
+		for i, user := range users {
+			items[i] = user
+		}
+	}
+	if params.Envelope != nil && *params.Envelope {
+		c.JSON(http.StatusOK, core.UserList{
+			Items:    items,
+			Total:    total,
+			Page:     helpers.PageOf(pagingSql),
+			PageSize: pagingSql.PageSize,
+		})
+		return
 	}
+	c.JSON(http.StatusOK, items)
+}
+
+// ExportUsers streams the users of ListUsers, with the same filters, as CSV
+// or XLSX
+func (u *UserAdminHandler) ExportUsers(c *gin.Context, params core.ExportUsersParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		logger.Error().Msg("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can export users")
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can export users")))
+		return
+	}
+
+	requested := ""
+	if params.Format != nil {
+		requested = *params.Format
+	}
+	format, err := access.UserExportFormat(requested)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	filter := access.UserExportFilter{TenantID: c.GetString(auth.AUTH_TENANT_ID_KEY), Search: access.UserSearch(params.Q)}
+	if params.Scope != nil && *params.Scope == string(core.All) {
+		if !auth.Allowed(c, auth.OpManageGlobalUsers) {
+			logger.Error().Msg("Only super admins may export all users")
+			c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("only super admins may export all users")))
+			return
+		}
+		filter.All = true
+	}
+
+	// The status is sent with the first page: a later failure can only cut
+	// the file short
+	c.Header("Content-Type", access.UserExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "users-"+time.Now().UTC().Format("20060102")+"."+format))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	written, err := u.exportService.ExportUsers(c, filter, format, c.Writer)
+	if err != nil {
+		logger.Err(err).Int64("users", written).Msg("User export interrupted")
+		return
+	}
+	logger.Info().Int64("users", written).Str("format", format).Msg("Exported users")
+}
+
+// BulkUpdateUsers disables, enables, deletes or assigns a role to a list of
+// users and reports the outcome of each
+// (POST /api/v1/users/bulk)
+func (uh *UserAdminHandler) BulkUpdateUsers(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+
+	var req core.BulkUpdateUsersJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Err(err).Msg("Failed to bind JSON")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	transfer, err := ownershipTransferFromParams(req.TransferTo, req.TransferToTenantOwner)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	subdomain, err := util.GetSubdomain(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	baseAuthClient, err := uh.authProvider.GetAuthClientForSubdomain(c, subdomain)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	results, err := uh.bulkService.Run(c, baseAuthClient, c.GetString(auth.AUTH_TENANT_ID_KEY), req, transfer)
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrInvalidBulkUserOperation):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, auth.ErrForbidden):
+			c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		default:
+			logger.Err(err).Msg("Failed to run bulk user operation")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+
+	response := core.BulkUserOperationResult{
+		Total:   len(results),
+		Results: make([]core.BulkUserOperationItem, len(results)),
+	}
+	for i, result := range results {
+		item := core.BulkUserOperationItem{UserId: result.UserID, Success: result.Success}
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+			item.Code = &results[i].Code
+			item.Error = &results[i].Error
+		}
+		response.Results[i] = item
+	}
+	logger.Info().Str("action", string(req.Action)).Int("succeeded", response.Succeeded).Int("failed", response.Failed).Msg("Bulk user operation")
+	c.JSON(http.StatusOK, response)
 }
 
 // AssignRole implements openopenapi.ServerInterface.
@@ -570,7 +816,7 @@ func (uh *UserAdminHandler) ResetPasswordRequestByAdmin(c *gin.Context, userID s
 	}
 
 	// check if authorized user is admin
-	if !auth.HasAdminPrivileges(c) {
+	if !auth.Allowed(c, auth.OpManageUsers) {
 		logger.Error().Msg("Only RESELLER, admin or super admin can reset password")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Only admin or super admin can reset password"})
 		return
@@ -616,10 +862,126 @@ func (uh *UserAdminHandler) ResetPasswordRequestByAdmin(c *gin.Context, userID s
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	_ = uh.auditLogs.RecordUserAudit(c, access.UserAuditEntry{
+		TenantID: tenantID.(string),
+		UserID:   userID,
+		Action:   access.UserAuditActionPasswordReset,
+		ActorID:  c.GetString(auth.AUTH_USER_ID),
+	})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset email sent"})
 }
 
+// GetUserAuditLogs returns the audit trail of a user of the tenant
+// (GET /api/v1/users/{userid}/audit-logs)
+func (uh *UserAdminHandler) GetUserAuditLogs(c *gin.Context, userID string, params core.GetUserAuditLogsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("not allowed to read the audit logs of users")))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+
+	actions := []string{}
+	if params.Actions != nil {
+		actions = *params.Actions
+	}
+	if err := access.ValidateUserAuditActions(actions); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	pagingSql := helpers.GetPagingSQL(helpers.PagingRequest{
+		MaxPageSize:     100,
+		DefaultPage:     1,
+		DefaultPageSize: 50,
+		Page:            params.Page,
+		PageSize:        params.PageSize,
+	})
+
+	logs, err := uh.auditLogs.ListUserAuditLogs(c, tenantID, userID, actions, pagingSql.PageSize, pagingSql.Offset)
+	if err != nil {
+		logger.Err(err).Str("user_id", userID).Msg("Failed to get user audit logs")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := make([]core.UserAuditLog, len(logs))
+	for i, log := range logs {
+		result[i] = core.UserAuditLog{
+			Id:         log.ID,
+			Action:     log.Action,
+			ActorId:    log.ActorID,
+			OccurredAt: log.OccurredAt,
+		}
+		if log.Details != nil {
+			details := log.Details
+			result[i].Details = &details
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetUserVerificationReport counts the users of the tenant by the state of
+// their email and lists the accounts still unverified after a number of days
+// (GET /api/v1/users/verification-report)
+func (uh *UserAdminHandler) GetUserVerificationReport(c *gin.Context, params core.GetUserVerificationReportParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := auth.Authorize(c, auth.OpManageUsers); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The verification report is made for a tenant"))
+		return
+	}
+
+	olderThanDays := int32(service.DefaultVerificationReportDays)
+	if params.OlderThanDays != nil {
+		olderThanDays = *params.OlderThanDays
+	}
+	limit := int32(100)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if olderThanDays < 0 || limit < 1 || limit > service.MaxVerificationReportUsers {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse(
+			fmt.Sprintf("olderThanDays must be positive and limit between 1 and %d", service.MaxVerificationReportUsers)))
+		return
+	}
+
+	report, err := uh.verification.GetVerificationReport(c, tenantID, int(olderThanDays), limit)
+	if err != nil {
+		logger.Err(err).Msg("Failed to build the verification report")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	users := make([]core.UnverifiedUser, len(report.Users))
+	for i, user := range report.Users {
+		users[i] = core.UnverifiedUser{
+			UserId:                 user.UserID,
+			Email:                  user.Email,
+			Name:                   user.Name,
+			CreatedAt:              user.CreatedAt,
+			LastVerificationSentAt: user.LastVerificationSentAt,
+			LastLoginAt:            user.LastLoginAt,
+		}
+	}
+	c.JSON(http.StatusOK, core.UserVerificationReport{
+		GeneratedAt:     report.GeneratedAt,
+		OlderThanDays:   olderThanDays,
+		Total:           report.Total,
+		Verified:        report.Verified,
+		Unverified:      report.Unverified,
+		Disabled:        report.Disabled,
+		StaleUnverified: report.StaleUnverified,
+		Users:           users,
+		Truncated:       report.Truncated,
+	})
+}
+
 // CheckUserExists checks if a user exists globally by email
 func (uh *UserAdminHandler) CheckUserExists(c *gin.Context, params core.CheckUserExistsParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
@@ -739,10 +1101,15 @@ func (uh *UserAdminHandler) AddUserMembership(c *gin.Context, userid string) {
 	// Add user to tenant (create membership)
 	err = uh.userService.AddUserToTenant(c, baseAuthClient, tenantID.(string), userid, req.Roles, byUserID.(string))
 	if err != nil {
+		if errors.Is(err, access.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Msg("Failed to add user to tenant")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	access.RecordUsage(c, tenantID.(string), access.UsageMetricUsersAdded, 1)
 
 	// Get updated user info
 	user, err := uh.userService.GetUserByTenantIDByID(c, tenantID.(string), userid)
@@ -769,7 +1136,7 @@ func (uh *UserAdminHandler) AddUserMembership(c *gin.Context, userid string) {
 	c.JSON(http.StatusCreated, user)
 }
 
-func (uh *UserAdminHandler) ImportUsersFromAdmin(c *gin.Context) {
+func (uh *UserAdminHandler) ImportUsersFromAdmin(c *gin.Context, params core.ImportUsersFromAdminParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
 	tenantID, exists := c.Get(auth.AUTH_TENANT_ID_KEY)
 	if !exists {
@@ -801,235 +1168,191 @@ func (uh *UserAdminHandler) ImportUsersFromAdmin(c *gin.Context) {
 		return
 	}
 
-	// Open the file
+	// The file is kept in memory, the upload is removed once the request is
+	// answered while the import goes on
 	src, err := file.Open()
 	if err != nil {
 		logger.Err(err).Msg("Failed to open uploaded file")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(fmt.Errorf("error opening file: %v", err)))
 		return
 	}
-	defer src.Close()
-
-	// Parse CSV
-	reader := csv.NewReader(src)
-	reader.Comma = ';' // Set semicolon as delimiter
-
-	// Read header
-	header, err := reader.Read()
+	data, err := io.ReadAll(src)
+	src.Close()
 	if err != nil {
-		logger.Err(err).Msg("Failed to read CSV header")
-		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(fmt.Errorf("error reading CSV header: %v", err)))
+		logger.Err(err).Msg("Failed to read uploaded file")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(fmt.Errorf("error reading file: %v", err)))
 		return
 	}
 
-	// Strip BOM from the first header column if present
-	if len(header) > 0 {
-		header[0] = util.StripBOM(header[0])
+	mapping, err := userImportMappingFromForm(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to read import mapping")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
 	}
-
-	// Validate header
-	requiredColumns := []string{"lastname", "firstname", "email", "is_customer_admin"}
-	missingColumns := []string{}
-
-	// Create a map of header columns for easy lookup
-	headerMap := make(map[string]int)
-	for i, col := range header {
-		headerMap[strings.ToLower(col)] = i
+	reader, err := access.NewUserImportReader(bytes.NewReader(data), mapping)
+	if err != nil {
+		logger.Err(err).Msg("Failed to read CSV header")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
 	}
 
-	// Check for missing required columns
-	for _, required := range requiredColumns {
-		if _, exists := headerMap[required]; !exists {
-			missingColumns = append(missingColumns, required)
+	// Report what an import would do without creating anyone
+	if params.DryRun != nil && *params.DryRun {
+		report, err := access.ValidateUserImport(c, uh.store, tenantID.(string), reader)
+		if err != nil {
+			logger.Err(err).Msg("Failed to validate import file")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+			return
 		}
+		c.JSON(http.StatusOK, toUserImportReportDTO(report))
+		return
 	}
 
-	if len(missingColumns) > 0 {
-		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(fmt.Errorf("invalid CSV format. Missing required columns: %v", missingColumns)))
+	// Events are persisted, so the import can be followed with
+	// GET /api/v1/users/import-jobs/{id} or GET /api/v1/jobs/{id}/events
+	job, err := uh.jobService.StartJob(c, tenantID.(string), access.JobKindUserImport, c.GetString(auth.AUTH_USER_ID))
+	if err != nil {
+		logger.Err(err).Msg("Failed to start import job")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	c.Header("X-Job-Id", job.ID.String())
 
-	// Process records
-	type ImportError struct {
-		Line  int    `json:"line"`
-		Email string `json:"email"`
-		Error string `json:"error"`
-	}
-
-	var (
-		total         int
-		success       int
-		alreadyExists int
-		failed        int
-		errors        []ImportError
-	)
-
-	// Handle streaming case
-	clientChan := make(chan event.ProgressEvent)
-	errorChan := make(chan error, 1)
-
-	// Set headers for SSE before any data is written
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("Transfer-Encoding", "chunked")
-
-	// Process each line
-	lineNum := 1 // Start from 1 to account for header
-	// get total number of lines
-	// Start generation in goroutine
-	go func() {
-		defer close(clientChan)
-
-		for {
-			lineNum++
-			message := fmt.Sprintf(`Processing 
-			line: %d,
-			success: %d,
-			already exists: %d,
-			failed: %d,
-			errors: %v`, lineNum, success, alreadyExists, failed, errors)
-			clientChan <- event.NewProgressEvent("INFO", message, 50)
-
-			record, err := reader.Read()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				errors = append(errors, ImportError{
-					Line:  lineNum,
-					Error: fmt.Sprintf("error reading line: %v", err),
-				})
-				failed++
-				continue
-			}
+	// The import goes on when the client goes away
+	importCtx := c.Copy()
+	importCtx.Request = c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+	go uh.runUserImport(importCtx, baseAuthClient, tenantID.(string), job, reader, int64(len(data)))
 
-			total++
+	// Streaming clients follow the job over server-sent events
+	if acceptsEventStream(c) {
+		streamJobEvents(c, uh.jobService, job)
+		return
+	}
+	c.JSON(http.StatusAccepted, toUserImportJobDTO(job, nil, nil))
+}
 
-			// Extract user data
-			if len(record) < 4 {
-				errors = append(errors, ImportError{
-					Line:  lineNum,
-					Error: fmt.Sprintf("invalid record format, expected at least 4 fields, got %d", len(record)),
-				})
-				failed++
-				continue
-			}
+func toUserImportReportDTO(report access.UserImportReport) core.UserImportReport {
+	errors := make([]core.UserImportRowError, len(report.Errors))
+	for i, rowErr := range report.Errors {
+		errors[i] = toUserImportRowErrorDTO(rowErr)
+	}
+	return core.UserImportReport{
+		Total:         report.Total,
+		Valid:         report.Valid,
+		AlreadyExists: report.AlreadyExists,
+		Invalid:       report.Invalid,
+		Errors:        errors,
+	}
+}
 
-			lastname := record[headerMap["lastname"]]
-			firstname := record[headerMap["firstname"]]
-			email := record[headerMap["email"]]
-			isCustomerAdmin := parseBoolFlag(record[headerMap["is_customer_admin"]])
+// userImportMappingFromForm reads the optional mapping part of an import
+// request, a JSON core.UserImportMapping
+func userImportMappingFromForm(c *gin.Context) (access.UserImportMapping, error) {
+	mapping := access.UserImportMapping{}
+	value := c.PostForm("mapping")
+	if value == "" {
+		return mapping, nil
+	}
+	var dto core.UserImportMapping
+	if err := json.Unmarshal([]byte(value), &dto); err != nil {
+		return mapping, fmt.Errorf("%w: %v", access.ErrInvalidUserImportMapping, err)
+	}
+	if dto.Delimiter != nil && *dto.Delimiter != "" {
+		if utf8.RuneCountInString(*dto.Delimiter) != 1 {
+			return mapping, fmt.Errorf("%w: delimiter must be a single character", access.ErrInvalidUserImportMapping)
+		}
+		mapping.Delimiter, _ = utf8.DecodeRuneInString(*dto.Delimiter)
+	}
+	if dto.Columns != nil {
+		mapping.Columns = *dto.Columns
+	}
+	if dto.AdminValues != nil {
+		mapping.AdminValues = *dto.AdminValues
+	}
+	if dto.Locale != nil {
+		mapping.Locale = *dto.Locale
+	}
+	return mapping, nil
+}
 
-			silent := false
-			if idx, ok := headerMap["silent"]; ok && idx < len(record) {
-				silent = parseBoolFlag(record[idx])
-			}
+func toUserImportRowErrorDTO(rowErr event.ImportRowError) core.UserImportRowError {
+	result := core.UserImportRowError{
+		Line:  rowErr.Line,
+		Code:  rowErr.Code,
+		Error: rowErr.Error,
+	}
+	if rowErr.Email != "" {
+		email := rowErr.Email
+		result.Email = &email
+	}
+	return result
+}
 
-			var req core.AddUserJSONRequestBody
-			req.Email = email
-			req.Name = firstname + " " + lastname
-			req.Roles = []core.Role{}
-			if silent {
-				silentTrue := true
-				req.Silent = &silentTrue
-			}
-			MarkSilent(c, silent)
-
-			// check if user has rights to assign roles
-			if !auth.HasAdminPrivileges(c) {
-				errors = append(errors, ImportError{
-					Line:  lineNum,
-					Email: email,
-					Error: "must be an RESELLER, CUSTOMER_ADMIN or SUPER_ADMIN to assign CUSTOMER_ADMIN role to a user.",
-				})
-				failed++
-				continue
-			}
-			if isCustomerAdmin {
-				req.Roles = []core.Role{api.CUSTOMERADMIN}
-			}
-			_, err = uh.userService.CreateUser(c, baseAuthClient, tenantID.(string), req, nil)
-			if err != nil {
-				logger.Err(err).Msg("Failed to create user")
-				// check if error is a auth provider error and if so, check if it is a duplicate email error
-				if auth.IsEmailAlreadyExists(err) {
-					errors = append(errors, ImportError{
-						Line:  lineNum,
-						Email: email,
-						Error: "email already exists",
-					})
-					alreadyExists++
-					continue
-				} else {
-					errors = append(errors, ImportError{
-						Line:  lineNum,
-						Email: email,
-						Error: fmt.Sprintf("error creating user: %v", err),
-					})
-					failed++
-					continue
-				}
-			}
+// importProgress estimates the progress of an import from the bytes read,
+// keeping 100 for the final event
+func importProgress(offset, size int64) int {
+	if size <= 0 {
+		return 0
+	}
+	return int(min(offset*100/size, 99))
+}
 
-			if !silent {
-				url, err := getWelcomeEmailURL(c)
-				if err != nil {
-					errors = append(errors, ImportError{
-						Line:  lineNum,
-						Email: email,
-						Error: fmt.Sprintf("error getting welcome email url: %v", err),
-					})
-					failed++
-					continue
-				}
-				err = sendWelcomeEmail(c, baseAuthClient, url, req.Email)
-				if err != nil {
-					errors = append(errors, ImportError{
-						Line:  lineNum,
-						Email: email,
-						Error: fmt.Sprintf("error sending welcome email: %v", err),
-					})
-					failed++
-					continue
-				}
-			}
+// ownershipTransferFromParams reads the transfer target of a user deletion,
+// which is either a user or the tenant owner
+func ownershipTransferFromParams(transferTo *string, transferToTenantOwner *bool) (access.OwnershipTransfer, error) {
+	transfer := access.OwnershipTransfer{}
+	if transferTo != nil {
+		transfer.ToUserID = *transferTo
+	}
+	if transferToTenantOwner != nil {
+		transfer.ToTenantOwner = *transferToTenantOwner
+	}
+	if transfer.ToUserID != "" && transfer.ToTenantOwner {
+		return transfer, errors.New("transferTo and transferToTenantOwner are mutually exclusive")
+	}
+	return transfer, nil
+}
 
-			success++
-		}
+// abortIfInvalidDisplayName checks the name against the display name policy of
+// the tenant. It answers 400 with every broken rule, or 500 when the policy
+// cannot be read, and reports whether it did.
+func abortIfInvalidDisplayName(c *gin.Context, store *db.Store, tenantID, userID, name string) bool {
+	err := access.ValidateDisplayName(c, store, tenantID, userID, name)
+	if err == nil {
+		return false
+	}
+	var invalid *access.DisplayNameError
+	if errors.As(err, &invalid) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message":    invalid.Error(),
+			"code":       "INVALID_DISPLAY_NAME",
+			"field":      "name",
+			"violations": invalid.Violations,
+		})
+		return true
+	}
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	logger.Err(err).Str("tenantID", tenantID).Msg("Failed to check display name")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	return true
+}
 
-		// Return results
-		result := fmt.Sprintf(`Finished processing Users. Results:
-			total: %d,
-			success: %d,
-			already exists: %d,
-			failed: %d,
-			errors: %v`,
-			total, success, alreadyExists, failed, errors)
-
-		clientChan <- event.NewProgressEvent("INFO", result, 100)
-	}()
-
-	c.Stream(func(w io.Writer) bool {
-		select {
-		case msg, ok := <-clientChan:
-			if !ok {
-				return false
-			}
-			c.SSEvent("message", msg)
-			return msg.EventType != "ERROR" && msg.Progress != 100
-		case err := <-errorChan:
-			// Send error as SSE event instead of trying to change status code
-			logger.Printf("Error in streaming: %v", err)
-			errEvent := event.NewProgressEvent("ERROR", err.Error(), 100)
-			c.SSEvent("message", errEvent)
-			return false
-		case <-time.After(60 * time.Second):
-			// Send timeout as SSE event
-			timeoutEvent := event.NewProgressEvent("ERROR", "Generation timeout", 100)
-			c.SSEvent("message", timeoutEvent)
-			return false
-		}
-	})
-	// Commit transaction if there were successful imports
+// abortIfOwnershipTransferFailed answers 409 with the owned resources when the
+// deletion needs a transfer target, and 400 when the target is not valid
+func abortIfOwnershipTransferFailed(c *gin.Context, err error) bool {
+	var owns *access.ErrUserOwnsResources
+	switch {
+	case errors.As(err, &owns):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"message":   owns.Error(),
+			"code":      "USER_OWNS_RESOURCES",
+			"resources": owns.Resources,
+		})
+		return true
+	case errors.Is(err, access.ErrInvalidTransferTarget):
+		c.AbortWithStatusJSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return true
+	}
+	return false
 }
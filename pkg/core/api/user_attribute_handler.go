@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// UserAttributeHandler handles the custom profile attributes the request
+// tenant defines for its users
+type UserAttributeHandler struct {
+	attributeService *access.UserAttributeService
+}
+
+func NewUserAttributeHandler(store *db.Store) *UserAttributeHandler {
+	return &UserAttributeHandler{
+		attributeService: access.NewUserAttributeService(store),
+	}
+}
+
+func toAPIUserAttribute(attr repository.CoreUserAttribute) core.UserAttribute {
+	return core.UserAttribute{
+		Key:        attr.Key,
+		Label:      attr.Label,
+		Type:       attr.Type,
+		Required:   &attr.Required,
+		EnumValues: &attr.EnumValues,
+		CreatedAt:  attr.CreatedAt,
+		UpdatedAt:  attr.UpdatedAt,
+	}
+}
+
+// userAttributeTenant returns the tenant of the caller, or writes the error
+// response when there is none
+func userAttributeTenant(c *gin.Context) (string, bool) {
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  http.StatusBadRequest,
+			"message": "The user attributes are defined by a tenant",
+		})
+		return "", false
+	}
+	return tenantID, true
+}
+
+func userAttributeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidUserAttribute):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrUserAttributeNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeUserAttributeError(c *gin.Context, err error, msg string) {
+	status := userAttributeErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+	}
+	c.JSON(status, helpers.ErrorResponse(err))
+}
+
+// abortIfInvalidUserAttributes answers 400 with every rejected attribute, or
+// 500 when they could not be checked. It returns false when err is nil.
+func abortIfInvalidUserAttributes(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var invalid *access.UserAttributesError
+	if errors.As(err, &invalid) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message":    invalid.Error(),
+			"code":       "INVALID_USER_ATTRIBUTES",
+			"field":      "attributes",
+			"violations": invalid.Violations,
+		})
+		return true
+	}
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	logger.Err(err).Msg("Failed to check user attributes")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	return true
+}
+
+// (GET /api/v1/tenant/user-attributes)
+func (h *UserAttributeHandler) ListUserAttributes(c *gin.Context) {
+	tenantID, ok := userAttributeTenant(c)
+	if !ok {
+		return
+	}
+	attrs, err := h.attributeService.ListDefinitions(c, tenantID)
+	if err != nil {
+		writeUserAttributeError(c, err, "Failed to list user attributes")
+		return
+	}
+	result := make([]core.UserAttribute, len(attrs))
+	for i, attr := range attrs {
+		result[i] = toAPIUserAttribute(attr)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (PUT /api/v1/tenant/user-attributes/{key})
+func (h *UserAttributeHandler) SaveUserAttribute(c *gin.Context, key string) {
+	if err := auth.Authorize(c, auth.OpManageUserAttributes); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID, ok := userAttributeTenant(c)
+	if !ok {
+		return
+	}
+	var req core.SaveUserAttributeJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	def := access.UserAttributeDefinition{
+		Key:   key,
+		Label: req.Label,
+		Type:  req.Type,
+	}
+	if req.Required != nil {
+		def.Required = *req.Required
+	}
+	if req.EnumValues != nil {
+		def.EnumValues = *req.EnumValues
+	}
+	attr, err := h.attributeService.SaveDefinition(c, tenantID, def)
+	if err != nil {
+		writeUserAttributeError(c, err, "Failed to save user attribute")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIUserAttribute(attr))
+}
+
+// (DELETE /api/v1/tenant/user-attributes/{key})
+func (h *UserAttributeHandler) DeleteUserAttribute(c *gin.Context, key string) {
+	if err := auth.Authorize(c, auth.OpManageUserAttributes); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID, ok := userAttributeTenant(c)
+	if !ok {
+		return
+	}
+	if err := h.attributeService.DeleteDefinition(c, tenantID, key); err != nil {
+		writeUserAttributeError(c, err, "Failed to delete user attribute")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// emailChangeDateFormat is the format of the expiries shown in the emails
+const emailChangeDateFormat = "January 2, 2006 at 15:04 UTC"
+
+// UserEmailChangeHandler handles the changes of email address of the current
+// user, confirmed by a link sent to the new address and revertible from the
+// previous one
+type UserEmailChangeHandler struct {
+	store         *db.Store
+	authProvider  auth.AuthProvider
+	changeService *access.UserEmailChangeService
+}
+
+func NewUserEmailChangeHandler(store *db.Store, authProvider auth.AuthProvider) *UserEmailChangeHandler {
+	factory := access.NewUserServiceStrategyFactory()
+	userService := factory.CreateUserServiceStrategy(store, authProvider)
+	return &UserEmailChangeHandler{
+		store:         store,
+		authProvider:  authProvider,
+		changeService: access.NewUserEmailChangeService(store, userService),
+	}
+}
+
+// (POST /api/v1/users/me/email-change)
+func (h *UserEmailChangeHandler) RequestEmailChange(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, helpers.ErrorStringResponse("Not authenticated"))
+		return
+	}
+	var req core.RequestEmailChangeJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	token, change, err := h.changeService.RequestEmailChange(c, c.GetString(auth.AUTH_TENANT_ID_KEY), userID, string(req.Email))
+	if err != nil {
+		abortWithEmailChangeError(c, err, "Failed to request email change")
+		return
+	}
+	newEmail := access.EmailChangeAddress(change.NewEmail)
+	if err := h.sendConfirmationEmail(c, change, newEmail, token); err != nil {
+		// The change stays pending, a new request sends another link
+		logger.Err(err).Str("email_change_id", change.ID.String()).Msg("Failed to send email change confirmation")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusAccepted, core.EmailChange{
+		Email:     openapi_types.Email(newEmail),
+		Status:    change.Status,
+		ExpiresAt: change.ExpiresAt,
+	})
+}
+
+// (POST /public-api/v1/email-change/confirm)
+func (h *UserEmailChangeHandler) ConfirmEmailChange(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.ConfirmEmailChangeJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+
+	confirmed, err := h.changeService.ConfirmEmailChange(c, authClient, c.GetString(auth.AUTH_TENANT_ID_KEY), req.Token)
+	if err != nil {
+		abortWithEmailChangeError(c, err, "Failed to confirm email change")
+		return
+	}
+	// The change is made, a lost notice is only logged
+	if err := h.sendChangedNotice(c, confirmed); err != nil {
+		logger.Err(err).Str("email_change_id", confirmed.Change.ID.String()).Msg("Failed to notify the previous address of the email change")
+	}
+	c.JSON(http.StatusOK, core.EmailChange{
+		Email:     openapi_types.Email(confirmed.NewEmail),
+		Status:    confirmed.Change.Status,
+		ExpiresAt: confirmed.Change.RevertExpiresAt.Time,
+	})
+}
+
+// (POST /public-api/v1/email-change/revert)
+func (h *UserEmailChangeHandler) RevertEmailChange(c *gin.Context) {
+	var req core.RevertEmailChangeJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	authClient, ok := h.authClient(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.changeService.RevertEmailChange(c, authClient, c.GetString(auth.AUTH_TENANT_ID_KEY), req.Token)
+	if err != nil {
+		abortWithEmailChangeError(c, err, "Failed to revert email change")
+		return
+	}
+	c.JSON(http.StatusOK, core.EmailChange{
+		Email:     openapi_types.Email(access.EmailChangeAddress(change.OldEmail)),
+		Status:    change.Status,
+		ExpiresAt: change.RevertExpiresAt.Time,
+	})
+}
+
+// authClient returns the auth client of the subdomain of the link, or answers
+// the error
+func (h *UserEmailChangeHandler) authClient(c *gin.Context) (auth.AuthClient, bool) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	subdomain, err := util.GetSubdomain(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return nil, false
+	}
+	authClient, err := h.authProvider.GetAuthClientForSubdomain(c, subdomain)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return nil, false
+	}
+	return authClient, true
+}
+
+// abortWithEmailChangeError answers the status matching an error of the email
+// change service
+func abortWithEmailChangeError(c *gin.Context, err error, message string) {
+	var authErr *auth.AuthError
+	switch {
+	case errors.Is(err, access.ErrEmailChangeNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrEmailChangeAddressInUse):
+		c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrInvalidEmailChange):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrEmailChangeRateLimited):
+		c.JSON(http.StatusTooManyRequests, helpers.ErrorResponse(err))
+	case errors.As(err, &authErr) && !auth.IsProviderUnavailable(err):
+		// Refused by the provider, such as an address it already knows
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(message)
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+}
+
+// sendConfirmationEmail sends the link confirming the change to the new
+// address. It leads to the frontoffice of the tenant, like the invitations.
+func (h *UserEmailChangeHandler) sendConfirmationEmail(c *gin.Context, change repository.CoreUserEmailChange, newEmail, token string) error {
+	link, err := buildTenantURL(c, "/email-change/confirm?token="+url.QueryEscape(token), "")
+	if err != nil {
+		return err
+	}
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	templateData := struct {
+		Link      string
+		Email     string
+		ExpiresAt string
+	}{
+		Link:      link,
+		Email:     newEmail,
+		ExpiresAt: change.ExpiresAt.UTC().Format(emailChangeDateFormat),
+	}
+	r := emailservice.NewEmailRequest(fromEmail, []string{newEmail}, "Confirm your new email address", "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if err := r.ParseTemplateWithDomain(c, "email-change-confirm.html", templateData); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
+
+// sendChangedNotice tells the previous address of a confirmed change, with the
+// link reverting it
+func (h *UserEmailChangeHandler) sendChangedNotice(c *gin.Context, confirmed access.ConfirmedEmailChange) error {
+	link, err := buildTenantURL(c, "/email-change/revert?token="+url.QueryEscape(confirmed.RevertToken), "")
+	if err != nil {
+		return err
+	}
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	templateData := struct {
+		Link      string
+		OldEmail  string
+		NewEmail  string
+		ExpiresAt string
+	}{
+		Link:      link,
+		OldEmail:  confirmed.OldEmail,
+		NewEmail:  confirmed.NewEmail,
+		ExpiresAt: confirmed.Change.RevertExpiresAt.Time.UTC().Format(emailChangeDateFormat),
+	}
+	r := emailservice.NewEmailRequest(fromEmail, []string{confirmed.OldEmail}, "Your email address was changed", "")
+	r.TenantID = c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if err := r.ParseTemplateWithDomain(c, "email-changed.html", templateData); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
@@ -28,7 +28,7 @@ import (
 func (uh *UserAdminHandler) resolveLicenseTenant(c *gin.Context, userid string, tenantIDParam *openapi_types.UUID) (string, subentity.TenantFeatures, bool) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
 
-	if !auth.HasAdminPrivileges(c) {
+	if !auth.Allowed(c, auth.OpManageUsers) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage user licenses"})
 		return "", nil, false
 	}
@@ -32,6 +32,9 @@ type UserHandler struct {
 	userService              access.UserService
 	emailVerificationService *service.EmailVerificationService
 	fileService              *fileservice.FileService
+	userActivityService      *access.UserActivityService
+	attributeService         *access.UserAttributeService
+	auditLogs                *access.UserAuditLogService
 }
 
 func NewUserHandler(store *db.Store, authProvider auth.AuthProvider) *UserHandler {
@@ -52,14 +55,13 @@ func NewUserHandler(store *db.Store, authProvider auth.AuthProvider) *UserHandle
 		userService:              userService,
 		fileService:              fileService,
 		emailVerificationService: emailVerificationService,
+		userActivityService:      access.NewUserActivityService(store),
+		attributeService:         access.NewUserAttributeService(store),
+		auditLogs:                access.NewUserAuditLogService(store),
 	}
 	return handler
 }
 
-func getProfilePictureFilePath(userId any) string {
-	return fileservice.ProfilePictureFilePath(userId.(string))
-}
-
 /**
 * in case user was created in auth provider but not in the store
  */
@@ -76,18 +78,25 @@ func (s *UserHandler) CreateMeUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, "Need to be authenticated")
 		return
 	}
+	email, blindIndex, err := access.EncryptEmail(userEmail.(string))
+	if err != nil {
+		logger.Err(err).Msg("Error encrypting user email")
+		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
 	user, err := s.store.CreateUserByTenant(ctx,
 		repository.CreateUserByTenantParams{
-			ID:      userID.(string),
-			Email:   userEmail.(string),
-			Profile: subentity.UserProfile{},
+			ID:              userID.(string),
+			Email:           email,
+			EmailBlindIndex: blindIndex,
+			Profile:         subentity.UserProfile{},
 		})
 	if err != nil {
 		logger.Err(err).Msg("Error creating user in database")
 		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
-	ctx.JSON(http.StatusCreated, user)
+	ctx.JSON(http.StatusCreated, access.DecryptUserEmail(user))
 }
 
 func (s *UserHandler) GetMeProfile(ctx *gin.Context) {
@@ -175,6 +184,13 @@ func (s *UserHandler) UpdateMeProfile(ctx *gin.Context) {
 		return
 	}
 
+	if abortIfInvalidDisplayName(ctx, s.store, tenantID, authUserID.(string), req.Name) {
+		return
+	}
+	if abortIfInvalidUserAttributes(ctx, s.attributeService.ValidateProfile(ctx, tenantID, req.Attributes)) {
+		return
+	}
+
 	err := s.userService.UpdateUserProfileInDatabase(ctx, tenantID, authUserID.(string), req)
 	if err != nil {
 		logger.Err(err).Msg("Error updating user profile in database")
@@ -222,8 +238,6 @@ func (s *UserHandler) UploadProfilePicture(c *gin.Context) {
 			return
 		}
 	}
-	newFilePath := getProfilePictureFilePath(userId)
-
 	fileContent, err := file.Open()
 	if err != nil {
 		logger.Err(err).Msg("Failed to open file")
@@ -237,8 +251,12 @@ func (s *UserHandler) UploadProfilePicture(c *gin.Context) {
 		return
 	}
 
-	// The file is received, so let's save it
-	if err := s.fileService.SaveFile(c, byteContainer, newFilePath); err != nil {
+	// The file is received, so let's resize it into the variants and save them
+	if err := s.fileService.SaveProfilePicture(c, userId.(string), byteContainer); err != nil {
+		if status := profilePictureErrorStatus(err); status != http.StatusInternalServerError {
+			c.AbortWithStatusJSON(status, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Msg("Failed to save file")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"message": "Unable to save the file",
@@ -246,6 +264,14 @@ func (s *UserHandler) UploadProfilePicture(c *gin.Context) {
 		return
 	}
 
+	_ = s.userActivityService.RecordUserActivity(c, access.UserActivity{
+		TenantID:  c.GetString(auth.AUTH_TENANT_ID_KEY),
+		UserID:    c.GetString(auth.AUTH_USER_ID),
+		Category:  access.ActivityCategoryFile,
+		EventType: "profile_picture_uploaded",
+		Data:      map[string]interface{}{"file_name": file.Filename, "size": file.Size},
+	})
+
 	// File saved successfully. Return proper result
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Your file has been successfully uploaded.",
@@ -253,9 +279,26 @@ func (s *UserHandler) UploadProfilePicture(c *gin.Context) {
 }
 
 func (s *UserHandler) GetProfilePicture(c *gin.Context, userId string, params core.GetProfilePictureParams) {
-	filePath := getProfilePictureFilePath(userId)
+	var size string
+	if params.Size != nil {
+		size = string(*params.Size)
+	}
 
-	s.fileService.GetFile(c, filePath)
+	access.ApplyTenantPublicHeaders(c, s.store)
+	s.fileService.GetProfilePicture(c, userId, size)
+}
+
+func profilePictureErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, fileservice.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, fileservice.ErrUnsupportedImage):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, fileservice.ErrInvalidImage):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func (uh *UserHandler) ResetPasswordRequest(c *gin.Context) {
@@ -388,6 +431,9 @@ func (uh *UserHandler) Signup(c *gin.Context) {
 	}
 
 	// Case 1: new user -> create + send welcome email (password reset link)
+	if abortIfInvalidDisplayName(c, uh.store, tenantID.(string), "", req.Name) {
+		return
+	}
 	newUser := core.NewUser{
 		Email: req.Email,
 		Name:  req.Name,
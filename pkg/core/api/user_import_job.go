@@ -0,0 +1,292 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	auth "ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/event"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// runUserImport creates the users of an import file in the background,
+// recording its progress on the job. c is a copy of the request context that
+// outlives the request.
+func (uh *UserAdminHandler) runUserImport(c *gin.Context, baseAuthClient auth.AuthClient, tenantID string, job repository.CoreJob, reader *access.UserImportReader, size int64) {
+	logger := util.GetLoggerFromCtx(c.Request.Context()).With().Str("jobID", job.ID.String()).Logger()
+	var (
+		total         int
+		success       int
+		alreadyExists int
+		failed        int
+		errors        []event.ImportRowError
+		// errors[reported:] have not been recorded yet
+		reported int
+	)
+	recordEvent := func(progressEvent event.ProgressEvent) {
+		if _, err := uh.jobService.RecordEvent(c, job.ID, progressEvent); err != nil {
+			logger.Err(err).Msg("Failed to record import event")
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Interface("panic", r).Msg("User import failed")
+			recordEvent(event.NewProgressEvent("ERROR", fmt.Sprintf("import failed: %v", r), 100))
+			if err := uh.jobService.FinishJob(c, job.ID, access.JobStatusFailed); err != nil {
+				logger.Err(err).Msg("Failed to fail import job")
+			}
+		}
+	}()
+	validator := access.NewUserImportValidator(c)
+
+	lineNum := 1 // Start from 1 to account for header
+	for {
+		lineNum++
+		message := fmt.Sprintf(`Processing
+			line: %d,
+			success: %d,
+			already exists: %d,
+			failed: %d,
+			errors: %v`, lineNum, success, alreadyExists, failed, errors)
+		progress := event.UserImportProgress{
+			Line:          lineNum,
+			Processed:     total,
+			Success:       success,
+			AlreadyExists: alreadyExists,
+			Failed:        failed,
+			Errors:        append([]event.ImportRowError{}, errors[reported:]...),
+		}
+		reported = len(errors)
+		// Rejected rows are kept for GET /api/v1/users/import-jobs/{id} and
+		// the report of GET /api/v1/imports/{id}/errors.csv
+		if err := uh.jobService.RecordRowErrors(c, job.ID, progress.Errors); err != nil {
+			logger.Err(err).Msg("Failed to record import errors")
+		}
+		recordEvent(event.NewProgressEventWithData("INFO", message, importProgress(reader.InputOffset(), size), progress))
+
+		row, rowErr, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if rowErr == nil {
+			total++
+			rowErr = validator.Check(row)
+		}
+		if rowErr != nil {
+			errors = append(errors, *rowErr)
+			failed++
+			continue
+		}
+		email := row.Email
+		silent := row.Silent
+
+		var req core.AddUserJSONRequestBody
+		req.Email = email
+		req.Name = row.Name()
+		req.Roles = row.Roles()
+		if silent {
+			silentTrue := true
+			req.Silent = &silentTrue
+		}
+		MarkSilent(c, silent)
+
+		_, err = uh.userService.CreateUser(c, baseAuthClient, tenantID, req, nil)
+		if err != nil {
+			logger.Err(err).Msg("Failed to create user")
+			// check if error is a auth provider error and if so, check if it is a duplicate email error
+			if auth.IsEmailAlreadyExists(err) {
+				errors = append(errors, event.ImportRowError{
+					Line:  lineNum,
+					Email: email,
+					Code:  event.ImportErrorEmailExists,
+					Error: "email already exists",
+				})
+				alreadyExists++
+			} else {
+				errors = append(errors, event.ImportRowError{
+					Line:  lineNum,
+					Email: email,
+					Code:  event.ImportErrorCreateFailed,
+					Error: fmt.Sprintf("error creating user: %v", err),
+				})
+				failed++
+			}
+			continue
+		}
+
+		if !silent {
+			url, err := getWelcomeEmailURL(c)
+			if err != nil {
+				errors = append(errors, event.ImportRowError{
+					Line:  lineNum,
+					Email: email,
+					Code:  event.ImportErrorWelcomeEmail,
+					Error: fmt.Sprintf("error getting welcome email url: %v", err),
+				})
+				failed++
+				continue
+			}
+			err = sendWelcomeEmail(c, baseAuthClient, url, req.Email)
+			if err != nil {
+				errors = append(errors, event.ImportRowError{
+					Line:  lineNum,
+					Email: email,
+					Code:  event.ImportErrorWelcomeEmail,
+					Error: fmt.Sprintf("error sending welcome email: %v", err),
+				})
+				failed++
+				continue
+			}
+		}
+
+		success++
+	}
+
+	result := fmt.Sprintf(`Finished processing Users. Results:
+			total: %d,
+			success: %d,
+			already exists: %d,
+			failed: %d,
+			errors: %v`,
+		total, success, alreadyExists, failed, errors)
+
+	recordEvent(event.NewProgressEventWithData("INFO", result, 100, event.UserImportResult{
+		Total:         total,
+		Success:       success,
+		AlreadyExists: alreadyExists,
+		Failed:        failed,
+		Errors:        append([]event.ImportRowError{}, errors...),
+	}))
+	if err := uh.jobService.FinishJob(c, job.ID, access.JobStatusCompleted); err != nil {
+		logger.Err(err).Msg("Failed to complete import job")
+	}
+	logger.Info().Int("total", total).Int("success", success).Int("alreadyExists", alreadyExists).Int("failed", failed).Msg("User import finished")
+}
+
+// GetUserImportJob returns the progress of a user import, or streams its
+// events when the client accepts text/event-stream
+// (GET /api/v1/users/import-jobs/{id})
+func (uh *UserAdminHandler) GetUserImportJob(c *gin.Context, id openapi_types.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(errors.New("tenant is required")))
+		return
+	}
+	job, err := uh.jobService.GetJob(c, tenantID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("import job not found")))
+			return
+		}
+		logger.Err(err).Msg("Failed to get import job")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	// Imports are visible to the user who started them and to the tenant admins
+	if job.Kind != access.JobKindUserImport ||
+		(job.CreatedBy != c.GetString(auth.AUTH_USER_ID) && !auth.HasAdminPrivileges(c)) {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(errors.New("import job not found")))
+		return
+	}
+
+	if acceptsEventStream(c) {
+		streamJobEvents(c, uh.jobService, job)
+		return
+	}
+
+	var latest *repository.CoreJobEvent
+	if jobEvent, err := uh.jobService.GetLatestEvent(c, job.ID); err == nil {
+		latest = &jobEvent
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Err(err).Msg("Failed to get import progress")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	rowErrors, err := uh.jobService.ListRowErrors(c, job.ID)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list import errors")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toUserImportJobDTO(job, latest, rowErrors))
+}
+
+// toUserImportJobDTO reads the counters of the import from its latest event
+func toUserImportJobDTO(job repository.CoreJob, latest *repository.CoreJobEvent, rowErrors []event.ImportRowError) core.UserImportJob {
+	result := core.UserImportJob{
+		Id:          job.ID,
+		Status:      job.Status,
+		CreatedBy:   job.CreatedBy,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: util.FromNullableTimestamptz(job.CompletedAt),
+		Errors:      make([]core.UserImportRowError, len(rowErrors)),
+	}
+	for i, rowErr := range rowErrors {
+		result.Errors[i] = toUserImportRowErrorDTO(rowErr)
+	}
+	if latest == nil {
+		return result
+	}
+	result.Progress = int(latest.Progress)
+	switch latest.DataType {
+	case event.DataTypeUserImportProgress:
+		var progress event.UserImportProgress
+		if err := json.Unmarshal(latest.Data, &progress); err == nil {
+			result.Processed = progress.Processed
+			result.Success = progress.Success
+			result.AlreadyExists = progress.AlreadyExists
+			result.Failed = progress.Failed
+		}
+	case event.DataTypeUserImportResult:
+		var progress event.UserImportResult
+		if err := json.Unmarshal(latest.Data, &progress); err == nil {
+			result.Processed = progress.Total
+			result.Success = progress.Success
+			result.AlreadyExists = progress.AlreadyExists
+			result.Failed = progress.Failed
+		}
+	}
+	return result
+}
+
+// acceptsEventStream reports whether the client asked for server-sent events
+func acceptsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// streamJobEvents sends the events of the job as server-sent events until the
+// job is over or the client goes away
+func streamJobEvents(c *gin.Context, jobService *access.JobService, job repository.CoreJob) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Transfer-Encoding", "chunked")
+
+	var since int64
+	c.Stream(func(w io.Writer) bool {
+		result, err := jobService.WaitForEvents(c.Request.Context(), job, since, 0, access.DefaultJobEventsWait)
+		if err != nil {
+			logger.Err(err).Str("jobID", job.ID.String()).Msg("Failed to read job events")
+			c.SSEvent("message", event.NewProgressEvent("ERROR", err.Error(), 100))
+			return false
+		}
+		for _, jobEvent := range result.Events {
+			c.SSEvent("message", toAPIJobEvent(jobEvent))
+		}
+		since = result.Cursor
+		return !result.Done && c.Request.Context().Err() == nil
+	})
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	core "ctoup.com/coreapp/api/openapi/core"
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/event"
+	access "ctoup.com/coreapp/pkg/shared/service"
+
+	"github.com/stretchr/testify/require"
+)
+
+// importUserService creates the users of an import with create, the other
+// methods of the user service being unused by the import
+type importUserService struct {
+	access.UserService
+	create func(email string) error
+}
+
+func (s importUserService) CreateUser(_ context.Context, _ auth.AuthClient, _ string, req core.NewUser, _ *string) (repository.CoreUser, error) {
+	return repository.CoreUser{}, s.create(req.Email)
+}
+
+// startUserImport starts the import job of file, as ImportUsersFromAdmin does
+// before handing it to the background worker
+func startUserImport(t *testing.T, uh *UserAdminHandler, tenantID, file string) (repository.CoreJob, *access.UserImportReader) {
+	t.Helper()
+	reader, err := access.NewUserImportReader(strings.NewReader(file), access.UserImportMapping{})
+	require.NoError(t, err)
+	job, err := uh.jobService.StartJob(context.Background(), tenantID, access.JobKindUserImport, "admin-"+tenantID)
+	require.NoError(t, err)
+	return job, reader
+}
+
+func getUserImportJob(t *testing.T, uh *UserAdminHandler, tenantID string, job repository.CoreJob) core.UserImportJob {
+	t.Helper()
+	c, w := tenantAdminRequest(t, tenantID, http.MethodGet, nil)
+	uh.GetUserImportJob(c, job.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	var result core.UserImportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestUserImportJobCompletes(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	tenantID := commontestutils.RandomString(10)
+	uh := &UserAdminHandler{
+		store:      store,
+		jobService: access.NewJobService(store),
+		userService: importUserService{create: func(email string) error {
+			switch email {
+			case "taken@example.com":
+				return auth.NewAuthError(auth.ErrorCodeEmailAlreadyExists, "email already exists")
+			case "broken@example.com":
+				return errors.New("provider unavailable")
+			}
+			return nil
+		}},
+	}
+	file := "Lastname;Firstname;Email;Is_Customer_Admin;Silent\n" +
+		"Doe;Jane;jane@example.com;no;1\n" +
+		"Doe;Taken;taken@example.com;no;1\n" +
+		"Doe;Broken;broken@example.com;no;1\n" +
+		"Doe;Bad;not-an-email;no;1\n"
+	job, reader := startUserImport(t, uh, tenantID, file)
+
+	// Running until the worker is done
+	running := getUserImportJob(t, uh, tenantID, job)
+	require.Equal(t, access.JobStatusRunning, running.Status)
+	require.Nil(t, running.CompletedAt)
+	require.Zero(t, running.Progress)
+	require.Empty(t, running.Errors)
+
+	c, _ := tenantAdminRequest(t, tenantID, http.MethodPost, nil)
+	uh.runUserImport(c, nil, tenantID, job, reader, int64(len(file)))
+
+	result := getUserImportJob(t, uh, tenantID, job)
+	require.Equal(t, access.JobStatusCompleted, result.Status)
+	require.NotNil(t, result.CompletedAt)
+	require.Equal(t, 100, result.Progress)
+	require.Equal(t, 4, result.Processed)
+	require.Equal(t, 1, result.Success)
+	require.Equal(t, 1, result.AlreadyExists)
+	require.Equal(t, 2, result.Failed)
+	require.Len(t, result.Errors, 3)
+	require.Equal(t, 3, result.Errors[0].Line)
+	require.Equal(t, event.ImportErrorEmailExists, result.Errors[0].Code)
+	require.Equal(t, 4, result.Errors[1].Line)
+	require.Equal(t, event.ImportErrorCreateFailed, result.Errors[1].Code)
+	require.Contains(t, result.Errors[1].Error, "provider unavailable")
+	require.Equal(t, 5, result.Errors[2].Line)
+	require.Equal(t, event.ImportErrorInvalidEmail, result.Errors[2].Code)
+}
+
+func TestUserImportJobFails(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	tenantID := commontestutils.RandomString(10)
+	uh := &UserAdminHandler{
+		store:      store,
+		jobService: access.NewJobService(store),
+		userService: importUserService{create: func(email string) error {
+			panic("connection lost")
+		}},
+	}
+	file := "Lastname;Firstname;Email;Is_Customer_Admin;Silent\n" +
+		"Doe;Bad;not-an-email;no;1\n" +
+		"Doe;Jane;jane@example.com;no;1\n" +
+		"Doe;John;john@example.com;no;1\n"
+	job, reader := startUserImport(t, uh, tenantID, file)
+
+	c, _ := tenantAdminRequest(t, tenantID, http.MethodPost, nil)
+	uh.runUserImport(c, nil, tenantID, job, reader, int64(len(file)))
+
+	// The failure is the last event of the job
+	latest, err := uh.jobService.GetLatestEvent(context.Background(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, "ERROR", latest.EventType)
+	require.Equal(t, "import failed: connection lost", latest.Message)
+
+	// The rows rejected before the failure are kept
+	result := getUserImportJob(t, uh, tenantID, job)
+	require.Equal(t, access.JobStatusFailed, result.Status)
+	require.NotNil(t, result.CompletedAt)
+	require.Equal(t, 100, result.Progress)
+	require.Len(t, result.Errors, 1)
+	require.Equal(t, 2, result.Errors[0].Line)
+	require.Equal(t, event.ImportErrorInvalidEmail, result.Errors[0].Code)
+}
@@ -0,0 +1,328 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	defaultInvitationPageSize = 50
+	maxInvitationPageSize     = 100
+)
+
+// UserInvitationHandler handles the invitations of users by email, sent by
+// the admins of a tenant, or of the admin domain, and accepted on a public
+// page
+type UserInvitationHandler struct {
+	store             *db.Store
+	authProvider      auth.AuthProvider
+	userService       access.UserService
+	invitationService *access.UserInvitationService
+}
+
+func NewUserInvitationHandler(store *db.Store, authProvider auth.AuthProvider) *UserInvitationHandler {
+	factory := access.NewUserServiceStrategyFactory()
+	userService := factory.CreateUserServiceStrategy(store, authProvider)
+	if initFunc := access.GetUserEventInitFunc(); initFunc != nil {
+		initFunc(userService)
+	}
+	return &UserInvitationHandler{
+		store:             store,
+		authProvider:      authProvider,
+		userService:       userService,
+		invitationService: access.NewUserInvitationService(store, userService),
+	}
+}
+
+func toAPIUserInvitation(invitation repository.CoreUserInvitation, now time.Time) core.UserInvitation {
+	return core.UserInvitation{
+		Id:         invitation.ID,
+		Email:      access.InvitationEmail(invitation),
+		Roles:      toAPIRoles(invitation.Roles),
+		Status:     core.UserInvitationStatus(access.InvitationStatus(invitation, now)),
+		InvitedBy:  invitation.InvitedBy,
+		UserId:     util.FromNullableText(invitation.UserID),
+		ExpiresAt:  invitation.ExpiresAt,
+		SentAt:     invitation.SentAt,
+		SendCount:  invitation.SendCount,
+		AcceptedAt: util.FromNullableTimestamptz(invitation.AcceptedAt),
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+func toAPIRoles(roles []string) []core.Role {
+	apiRoles := make([]core.Role, len(roles))
+	for i, role := range roles {
+		apiRoles[i] = core.Role(role)
+	}
+	return apiRoles
+}
+
+// abortIfNotInvitationManager answers 403 unless the caller manages the users
+// of the tenant, or the global users on the admin domain
+func abortIfNotInvitationManager(c *gin.Context) bool {
+	if !auth.Allowed(c, auth.OpManageUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorStringResponse("Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage invitations"))
+		return true
+	}
+	if c.GetString(auth.AUTH_TENANT_ID_KEY) == "" && !auth.Allowed(c, auth.OpManageGlobalUsers) {
+		c.JSON(http.StatusForbidden, helpers.ErrorStringResponse("Only global user managers can manage the invitations of the admin domain"))
+		return true
+	}
+	return false
+}
+
+// (GET /api/v1/users/invitations)
+func (h *UserInvitationHandler) ListUserInvitations(c *gin.Context, params core.ListUserInvitationsParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if abortIfNotInvitationManager(c) {
+		return
+	}
+	status := ""
+	if params.Status != nil {
+		status = string(*params.Status)
+	}
+	limit := int32(defaultInvitationPageSize)
+	if params.Limit != nil {
+		limit = min(max(*params.Limit, 1), maxInvitationPageSize)
+	}
+	offset := int32(0)
+	if params.Offset != nil && *params.Offset > 0 {
+		offset = *params.Offset
+	}
+
+	invitations, err := h.invitationService.ListInvitations(c, c.GetString(auth.AUTH_TENANT_ID_KEY), status, limit, offset)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list user invitations")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	now := time.Now()
+	result := make([]core.UserInvitation, len(invitations))
+	for i, invitation := range invitations {
+		result[i] = toAPIUserInvitation(invitation, now)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// (POST /api/v1/users/invitations)
+func (h *UserInvitationHandler) CreateUserInvitation(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if abortIfNotInvitationManager(c) {
+		return
+	}
+	var req core.CreateUserInvitationJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if err := auth.HasRightsForRoles(c, req.Roles); err != nil {
+		logger.Err(err).Msg("Failed to check user roles")
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	token, invitation, err := h.invitationService.CreateInvitation(c, tenantID, c.GetString(auth.AUTH_USER_ID), string(req.Email), req.Roles)
+	if err != nil {
+		abortWithInvitationError(c, err, "Failed to create user invitation")
+		return
+	}
+	if err := h.sendInvitationEmail(c, invitation, token); err != nil {
+		// The invitation is kept, the admin can send it again
+		logger.Err(err).Str("invitation_id", invitation.ID.String()).Msg("Failed to send invitation email")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, toAPIUserInvitation(invitation, time.Now()))
+}
+
+// (POST /api/v1/users/invitations/{invitationId}/resend)
+func (h *UserInvitationHandler) ResendUserInvitation(c *gin.Context, invitationId openapi_types.UUID) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if abortIfNotInvitationManager(c) {
+		return
+	}
+	token, invitation, err := h.invitationService.ResendInvitation(c, c.GetString(auth.AUTH_TENANT_ID_KEY), invitationId)
+	if err != nil {
+		abortWithInvitationError(c, err, "Failed to resend user invitation")
+		return
+	}
+	if err := h.sendInvitationEmail(c, invitation, token); err != nil {
+		logger.Err(err).Str("invitation_id", invitation.ID.String()).Msg("Failed to send invitation email")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toAPIUserInvitation(invitation, time.Now()))
+}
+
+// (DELETE /api/v1/users/invitations/{invitationId})
+func (h *UserInvitationHandler) RevokeUserInvitation(c *gin.Context, invitationId openapi_types.UUID) {
+	if abortIfNotInvitationManager(c) {
+		return
+	}
+	if err := h.invitationService.RevokeInvitation(c, c.GetString(auth.AUTH_TENANT_ID_KEY), invitationId); err != nil {
+		abortWithInvitationError(c, err, "Failed to revoke user invitation")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// (POST /public-api/v1/invitations/lookup)
+func (h *UserInvitationHandler) LookupUserInvitation(c *gin.Context) {
+	var req core.LookupUserInvitationJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	invitation, existingAccount, err := h.invitationService.LookupInvitation(c, tenantID, req.Token)
+	if err != nil {
+		abortWithInvitationError(c, err, "Failed to look up user invitation")
+		return
+	}
+	c.JSON(http.StatusOK, core.PublicUserInvitation{
+		Email:           access.InvitationEmail(invitation),
+		TenantName:      h.tenantName(c, tenantID),
+		ExpiresAt:       invitation.ExpiresAt,
+		ExistingAccount: existingAccount,
+	})
+}
+
+// (POST /public-api/v1/invitations/accept)
+func (h *UserInvitationHandler) AcceptUserInvitation(c *gin.Context) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	var req core.AcceptUserInvitationJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	subdomain, err := util.GetSubdomain(c)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	baseAuthClient, err := h.authProvider.GetAuthClientForSubdomain(c, subdomain)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for subdomain")
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	accepted, err := h.invitationService.AcceptInvitation(c, baseAuthClient, tenantID, req.Token, req.Name, req.Password)
+	if err != nil {
+		var invalid *access.DisplayNameError
+		if errors.As(err, &invalid) {
+			abortIfInvalidDisplayName(c, h.store, tenantID, "", req.Name)
+			return
+		}
+		abortWithInvitationError(c, err, "Failed to accept user invitation")
+		return
+	}
+
+	var user core.User
+	if tenantID == "" {
+		user, err = h.userService.GetUserByID(c, accepted.UserID)
+	} else {
+		user, err = h.userService.GetUserByTenantIDByID(c, tenantID, accepted.UserID)
+	}
+	if err != nil {
+		logger.Err(err).Str("user_id", accepted.UserID).Msg("Failed to get user after accepting invitation")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if accepted.Created {
+		c.JSON(http.StatusCreated, user)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// abortWithInvitationError answers the status matching an error of the
+// invitation service
+func abortWithInvitationError(c *gin.Context, err error, message string) {
+	var authErr *auth.AuthError
+	switch {
+	case errors.Is(err, access.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrInvitationAlreadyMember), errors.Is(err, access.ErrInvitationPending):
+		c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrInvalidInvitation), errors.Is(err, access.ErrInvitationPasswordRequired):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	case errors.As(err, &authErr) && !auth.IsProviderUnavailable(err):
+		// Refused by the provider, such as a password breaking its policy
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(message)
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+}
+
+// tenantName returns the name shown to the invited user, the tenant's or the
+// administration's on the admin domain
+func (h *UserInvitationHandler) tenantName(c *gin.Context, tenantID string) string {
+	if tenantID == "" {
+		return "the administration"
+	}
+	tenant, err := h.store.GetTenantByTenantID(c, tenantID)
+	if err != nil {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Str("tenant_id", tenantID).Msg("Failed to get tenant name")
+		return tenantID
+	}
+	return tenant.Name
+}
+
+// sendInvitationEmail sends the link accepting the invitation. It leads to the
+// frontoffice of the tenant, like the welcome email.
+func (h *UserInvitationHandler) sendInvitationEmail(c *gin.Context, invitation repository.CoreUserInvitation, token string) error {
+	link, err := buildTenantURL(c, "/invitation?token="+url.QueryEscape(token), "")
+	if err != nil {
+		return err
+	}
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	inviterName := ""
+	if inviter, err := h.store.GetSharedUserByID(c, invitation.InvitedBy); err == nil {
+		inviterName = inviter.Profile.Name
+	}
+	tenantName := h.tenantName(c, invitation.TenantID)
+
+	templateData := struct {
+		Link        string
+		TenantName  string
+		InviterName string
+		ExpiresAt   string
+	}{
+		Link:        link,
+		TenantName:  tenantName,
+		InviterName: inviterName,
+		ExpiresAt:   invitation.ExpiresAt.UTC().Format("January 2, 2006 at 15:04 UTC"),
+	}
+
+	r := emailservice.NewEmailRequest(fromEmail, []string{access.InvitationEmail(invitation)}, "You're invited to join "+tenantName, "")
+	r.TenantID = invitation.TenantID
+	if err := r.ParseTemplateWithDomain(c, "email-invitation.html", templateData); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
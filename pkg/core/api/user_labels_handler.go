@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// userLabelScope returns the tenant of the caller, or writes the error
+// response unless the caller may manage the users of the tenant
+func userLabelScope(c *gin.Context) (string, bool) {
+	if err := auth.Authorize(c, auth.OpManageUsers); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return "", false
+	}
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, helpers.ErrorStringResponse("The labels of the users must be managed from a tenant"))
+		return "", false
+	}
+	return tenantID, true
+}
+
+func writeUserLabelError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, access.ErrInvalidUserLabel), errors.Is(err, access.ErrTooManyUserLabels):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrNotTenantMember):
+		c.JSON(http.StatusNotFound, helpers.ErrorStringResponse("user not found in this tenant"))
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+}
+
+// AddUserLabel puts a label on a user of the tenant
+// (PUT /api/v1/users/{userid}/labels/{label})
+func (uh *UserAdminHandler) AddUserLabel(c *gin.Context, userID string, label string) {
+	tenantID, ok := userLabelScope(c)
+	if !ok {
+		return
+	}
+	labels, err := uh.labels.AddLabel(c, tenantID, userID, label)
+	if err != nil {
+		writeUserLabelError(c, err, "Failed to add user label")
+		return
+	}
+	c.JSON(http.StatusOK, core.UserLabels{Labels: labels})
+}
+
+// RemoveUserLabel takes a label off a user of the tenant
+// (DELETE /api/v1/users/{userid}/labels/{label})
+func (uh *UserAdminHandler) RemoveUserLabel(c *gin.Context, userID string, label string) {
+	tenantID, ok := userLabelScope(c)
+	if !ok {
+		return
+	}
+	labels, err := uh.labels.RemoveLabel(c, tenantID, userID, label)
+	if err != nil {
+		writeUserLabelError(c, err, "Failed to remove user label")
+		return
+	}
+	c.JSON(http.StatusOK, core.UserLabels{Labels: labels})
+}
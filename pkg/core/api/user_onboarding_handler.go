@@ -0,0 +1,87 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+
+	"ctoup.com/coreapp/api/helpers"
+	core "ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// UserOnboardingHandler serves the onboarding checklist of the current user
+type UserOnboardingHandler struct {
+	onboardingService *access.UserOnboardingService
+}
+
+func NewUserOnboardingHandler(store *db.Store) *UserOnboardingHandler {
+	return &UserOnboardingHandler{
+		onboardingService: access.NewUserOnboardingService(store),
+	}
+}
+
+func toAPIUserOnboarding(onboarding access.Onboarding) core.UserOnboarding {
+	steps := make([]core.UserOnboardingStep, len(onboarding.Steps))
+	for i, step := range onboarding.Steps {
+		steps[i] = core.UserOnboardingStep{
+			Step:        step.Step,
+			Completed:   step.Completed,
+			CompletedAt: step.CompletedAt,
+		}
+	}
+	return core.UserOnboarding{Steps: steps, Completed: onboarding.Completed}
+}
+
+func writeUserOnboardingError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, access.ErrInvalidOnboardingStep):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+	case errors.Is(err, access.ErrOnboardingUserUnknown):
+		// The user of a valid session has no row yet, as before their first
+		// request went through the user middleware
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+	default:
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Err(err).Msg(msg)
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+	}
+}
+
+// (GET /api/v1/users/me/onboarding)
+func (h *UserOnboardingHandler) GetMyOnboarding(c *gin.Context) {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, helpers.ErrorStringResponse("Not authenticated"))
+		return
+	}
+	onboarding, err := h.onboardingService.GetOnboarding(c, userID)
+	if err != nil {
+		writeUserOnboardingError(c, err, "Failed to get onboarding")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIUserOnboarding(onboarding))
+}
+
+// (PATCH /api/v1/users/me/onboarding)
+func (h *UserOnboardingHandler) UpdateMyOnboarding(c *gin.Context) {
+	userID := c.GetString(auth.AUTH_USER_ID)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, helpers.ErrorStringResponse("Not authenticated"))
+		return
+	}
+	var req core.UpdateMyOnboardingJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	onboarding, err := h.onboardingService.UpdateOnboarding(c, userID, req.Steps)
+	if err != nil {
+		writeUserOnboardingError(c, err, "Failed to update onboarding")
+		return
+	}
+	c.JSON(http.StatusOK, toAPIUserOnboarding(onboarding))
+}
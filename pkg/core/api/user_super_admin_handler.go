@@ -10,18 +10,25 @@ import (
 	"ctoup.com/coreapp/pkg/core/db/repository"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	sharedauth "ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
 	access "ctoup.com/coreapp/pkg/shared/service"
 	"ctoup.com/coreapp/pkg/shared/util"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5"
 )
 
 // https://pkg.go.dev/github.com/go-playground/validator/v10#hdr-One_Of
 type UserSuperAdminHandler struct {
-	store        *db.Store
-	authProvider sharedauth.AuthProvider
-	userService  access.UserService
+	store                *db.Store
+	authProvider         sharedauth.AuthProvider
+	userService          access.UserService
+	claimsRebuildService *access.ClaimsRebuildService
+	userActivityService  *access.UserActivityService
+	consistencyService   *access.MembershipConsistencyService
+	emailService         *access.EmailEncryptionService
+	impersonationService *access.ImpersonationService
+	mergeService         *access.UserMergeService
 }
 
 func NewUserSuperAdminHandler(store *db.Store, authProvider sharedauth.AuthProvider) *UserSuperAdminHandler {
@@ -35,8 +42,14 @@ func NewUserSuperAdminHandler(store *db.Store, authProvider sharedauth.AuthProvi
 	}
 
 	handler := &UserSuperAdminHandler{store: store,
-		authProvider: authProvider,
-		userService:  userService}
+		authProvider:         authProvider,
+		userService:          userService,
+		claimsRebuildService: access.NewClaimsRebuildService(store),
+		userActivityService:  access.NewUserActivityService(store),
+		consistencyService:   access.NewMembershipConsistencyService(store),
+		emailService:         access.NewEmailEncryptionService(store),
+		impersonationService: access.NewImpersonationService(store),
+		mergeService:         access.NewUserMergeService(store, fileservice.NewFileService())}
 	return handler
 }
 
@@ -66,6 +79,9 @@ func (uh *UserSuperAdminHandler) AddUserFromSuperAdmin(c *gin.Context, tenantId
 		c.JSON(http.StatusUnauthorized, helpers.ErrorResponse(err))
 		return
 	}
+	if abortIfInvalidPhoneNumber(c, req.PhoneNumber) {
+		return
+	}
 
 	baseAuthClient, err := uh.authProvider.GetAuthClientForTenant(c, tenant.TenantID)
 	if err != nil {
@@ -79,10 +95,15 @@ func (uh *UserSuperAdminHandler) AddUserFromSuperAdmin(c *gin.Context, tenantId
 
 	user, err := uh.userService.CreateUser(c, baseAuthClient, tenant.TenantID, req, nil)
 	if err != nil {
+		if errors.Is(err, access.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Msg("Failed to add user")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	access.RecordUsage(c, tenant.TenantID, access.UsageMetricUsersAdded, 1)
 	if !silent {
 		url, err := getWelcomeEmailURL(c, tenant.Subdomain)
 		if err != nil {
@@ -141,8 +162,13 @@ func (uh *UserSuperAdminHandler) UpdateUserFromSuperAdmin(c *gin.Context, tenant
 }
 
 // DeleteUser implements openapi.ServerInterface.
-func (uh *UserSuperAdminHandler) DeleteUserFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string) {
+func (uh *UserSuperAdminHandler) DeleteUserFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string, params core.DeleteUserFromSuperAdminParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
+	transfer, err := ownershipTransferFromParams(params.TransferTo, params.TransferToTenantOwner)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
 	tenant, err := uh.store.Queries.GetTenantByID(c, tenantId)
 	if err != nil {
 		logger.Err(err).Msg("Failed to get tenant")
@@ -159,8 +185,11 @@ func (uh *UserSuperAdminHandler) DeleteUserFromSuperAdmin(c *gin.Context, tenant
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
-	err = uh.userService.RemoveUserFromTenant(c, baseAuthClient, tenant.TenantID, userid)
+	err = uh.userService.DeleteUserFromTenant(c, baseAuthClient, tenant.TenantID, userid, transfer)
 	if err != nil {
+		if abortIfOwnershipTransferFailed(c, err) {
+			return
+		}
 		logger.Err(err).Msg("Failed to remove user from tenant")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
@@ -263,16 +292,7 @@ func (uh *UserSuperAdminHandler) ListUsersFromSuperAdmin(c *gin.Context, tenantI
 	}
 	pagingSql := helpers.GetPagingSQL(pagingRequest)
 
-	like := pgtype.Text{
-		Valid: false,
-	}
-
-	if params.Q != nil {
-		like.String = *params.Q + "%"
-		like.Valid = true
-	}
-
-	users, err := uh.userService.ListUsers(c, tenant.TenantID, pagingSql, like)
+	users, err := uh.userService.ListUsers(c, tenant.TenantID, pagingSql, access.UserListFilter{Search: access.UserSearch(params.Q)})
 	if err != nil {
 		logger.Err(err).Msg("Failed to list users")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
@@ -436,6 +456,12 @@ func (uh *UserHandler) ResetPasswordRequestBySuperAdmin(c *gin.Context, tenantId
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	_ = uh.auditLogs.RecordUserAudit(c, access.UserAuditEntry{
+		TenantID: tenant.TenantID,
+		UserID:   userID,
+		Action:   access.UserAuditActionPasswordReset,
+		ActorID:  c.GetString(auth.AUTH_USER_ID),
+	})
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset email sent"})
 }
 
@@ -497,6 +523,206 @@ func (uh *UserSuperAdminHandler) CheckUserExistsFromSuperAdmin(c *gin.Context, t
 	})
 }
 
+// RebuildUserClaimsFromSuperAdmin recomputes the provider role claims of the tenant users from the DB (Super Admin)
+func (uh *UserSuperAdminHandler) RebuildUserClaimsFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, params core.RebuildUserClaimsFromSuperAdminParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenant, err := uh.store.Queries.GetTenantByID(c, tenantId)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get tenant")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if !auth.IsAllowedToManageTenant(c, tenant) {
+		logger.Error().Msg("Not allowed to manage this tenant")
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("not allowed to manage this tenant")))
+		return
+	}
+
+	authClient, err := uh.authProvider.GetAuthClientForTenant(c, tenant.TenantID)
+	if err != nil {
+		logger.Err(err).Msg("Failed to get auth client for tenant")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	cursor := ""
+	if params.Cursor != nil {
+		cursor = *params.Cursor
+	}
+	limit := 0
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+	dryRun := params.DryRun != nil && *params.DryRun
+
+	report, err := uh.claimsRebuildService.RebuildTenantClaims(c, authClient, tenant.TenantID, cursor, limit, dryRun)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to rebuild user claims")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	discrepancies := make([]core.ClaimsDiscrepancy, len(report.Discrepancies))
+	for i, d := range report.Discrepancies {
+		discrepancies[i] = core.ClaimsDiscrepancy{
+			UserId:        d.UserID,
+			ExpectedRoles: d.ExpectedRoles,
+			ActualRoles:   d.ActualRoles,
+			Repaired:      d.Repaired,
+		}
+		if d.Error != "" {
+			discrepancies[i].Error = &d.Error
+		}
+	}
+	response := core.ClaimsRebuildReport{
+		Total:         report.Total,
+		Processed:     report.Processed,
+		Repaired:      report.Repaired,
+		Failed:        report.Failed,
+		Completed:     report.Completed,
+		Discrepancies: discrepancies,
+	}
+	if report.NextCursor != "" {
+		response.NextCursor = &report.NextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CheckUserMembershipConsistency reports, and with repair fixes, the drift
+// between core_users and the tenant memberships across all tenants
+func (uh *UserSuperAdminHandler) CheckUserMembershipConsistency(c *gin.Context, params core.CheckUserMembershipConsistencyParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	repair := params.Repair != nil && *params.Repair
+
+	report, err := uh.consistencyService.CheckConsistency(c, repair)
+	if err != nil {
+		logger.Err(err).Msg("Failed to check user membership consistency")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	drifts := make([]core.MembershipDrift, len(report.Drifts))
+	for i, d := range report.Drifts {
+		drifts[i] = core.MembershipDrift{
+			Kind:     core.MembershipDriftKind(d.Kind),
+			UserId:   d.UserID,
+			Repaired: d.Repaired,
+		}
+		if d.TenantID != "" {
+			drifts[i].TenantId = &d.TenantID
+		}
+		if d.Email != "" {
+			drifts[i].Email = &d.Email
+		}
+		if d.Error != "" {
+			drifts[i].Error = &d.Error
+		}
+	}
+	c.JSON(http.StatusOK, core.MembershipConsistencyReport{
+		DryRun:    report.DryRun,
+		CheckedAt: report.CheckedAt,
+		Repaired:  report.Repaired,
+		Failed:    report.Failed,
+		Truncated: report.Truncated,
+		Counts:    report.Counts,
+		Drifts:    drifts,
+	})
+}
+
+// CheckUserEmailEncryption reports how the user emails are stored and, with
+// rewrite, brings them to the current encryption settings
+func (uh *UserSuperAdminHandler) CheckUserEmailEncryption(c *gin.Context, params core.CheckUserEmailEncryptionParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	rewrite := params.Rewrite != nil && *params.Rewrite
+
+	report, err := uh.emailService.CheckEmailEncryption(c, rewrite)
+	if err != nil {
+		logger.Err(err).Msg("Failed to check user email encryption")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := core.EmailEncryptionReport{
+		DryRun:    report.DryRun,
+		CheckedAt: report.CheckedAt,
+		Enabled:   report.Enabled,
+		Plaintext: report.Plaintext,
+		Keys:      report.Keys,
+		Pending:   report.Pending,
+		Rewritten: report.Rewritten,
+		Failed:    report.Failed,
+	}
+	if report.CurrentKeyID != "" {
+		result.CurrentKeyId = &report.CurrentKeyID
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func userMergeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, access.ErrInvalidUserMerge), errors.Is(err, access.ErrUserMergeGlobalRole):
+		return http.StatusBadRequest
+	case errors.Is(err, access.ErrUserMergeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, access.ErrInvalidTransferTarget):
+		// The primary user is not an active member of a tenant the duplicate
+		// owns resources in
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// MergeUsers merges a duplicate user into a primary user. Without apply the
+// merge is only reported.
+func (uh *UserSuperAdminHandler) MergeUsers(c *gin.Context, params core.MergeUsersParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := auth.Authorize(c, auth.OpManageGlobalUsers); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	var req core.MergeUsersJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	apply := params.Apply != nil && *params.Apply
+
+	report, err := uh.mergeService.MergeUsers(c, uh.authProvider.GetAuthClient(), req.PrimaryUserId, req.DuplicateUserId, c.GetString(auth.AUTH_USER_ID), apply)
+	if err != nil {
+		status := userMergeErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Err(err).Msg("Failed to merge users")
+		}
+		c.JSON(status, helpers.ErrorResponse(err))
+		return
+	}
+
+	memberships := make([]core.UserMergeMembership, len(report.Memberships))
+	for i, m := range report.Memberships {
+		memberships[i] = core.UserMergeMembership{
+			TenantId: m.TenantID,
+			Action:   core.UserMergeMembershipAction(m.Action),
+			Roles:    m.Roles,
+		}
+	}
+	c.JSON(http.StatusOK, core.UserMergeReport{
+		DryRun:          report.DryRun,
+		PrimaryUserId:   report.PrimaryUserID,
+		DuplicateUserId: report.DuplicateUserID,
+		Memberships:     memberships,
+		Rows:            report.Rows,
+		Kept:            report.Kept,
+		Resources:       report.Resources,
+		Files:           report.Files,
+		Disabled:        report.Disabled,
+	})
+}
+
 // AddUserMembershipFromSuperAdmin adds an existing user to a specific tenant (Super Admin)
 func (uh *UserSuperAdminHandler) AddUserMembershipFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
@@ -552,10 +778,15 @@ func (uh *UserSuperAdminHandler) AddUserMembershipFromSuperAdmin(c *gin.Context,
 	// Add user to tenant (create membership)
 	err = uh.userService.AddUserToTenant(c, baseAuthClient, tenant.TenantID, userid, req.Roles, byUserId.(string))
 	if err != nil {
+		if errors.Is(err, access.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusConflict, helpers.ErrorResponse(err))
+			return
+		}
 		logger.Err(err).Msg("Failed to add user to tenant")
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	access.RecordUsage(c, tenant.TenantID, access.UsageMetricUsersAdded, 1)
 
 	// Get updated user info
 	user, err := uh.userService.GetUserByTenantIDByID(c, tenant.TenantID, userid)
@@ -615,16 +846,12 @@ func (uh *UserSuperAdminHandler) ReactivateUserFromSuperAdmin(c *gin.Context, te
 // Unlike DeleteUserFromSuperAdmin (which only removes the tenant membership), this removes the user globally.
 // Blocked if the user has active memberships in other tenants — deactivate those first.
 // (DELETE /superadmin-api/v1/tenants/{tenantid}/users/{userid}/hard-delete)
-func (uh *UserSuperAdminHandler) HardDeleteUserFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string) {
+func (uh *UserSuperAdminHandler) HardDeleteUserFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string, params core.HardDeleteUserFromSuperAdminParams) {
 	logger := util.GetLoggerFromCtx(c.Request.Context())
 
-	callerID, exists := c.Get(sharedauth.AUTH_USER_ID)
-	if !exists {
-		c.JSON(http.StatusInternalServerError, helpers.ErrorStringResponse("user_id not found in context"))
-		return
-	}
-	if callerID.(string) == userid {
-		c.JSON(http.StatusForbidden, helpers.ErrorStringResponse("Cannot permanently delete yourself"))
+	transfer, err := ownershipTransferFromParams(params.TransferTo, params.TransferToTenantOwner)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
 		return
 	}
 
@@ -655,7 +882,7 @@ func (uh *UserSuperAdminHandler) HardDeleteUserFromSuperAdmin(c *gin.Context, te
 	logger.Warn().
 		Str("user_id", userid).
 		Str("tenant_id", tenant.TenantID).
-		Str("caller_id", callerID.(string)).
+		Str("caller_id", c.GetString(sharedauth.AUTH_USER_ID)).
 		Msg("SUPER_ADMIN hard deleting user — irreversible")
 
 	baseAuthClient, err := uh.authProvider.GetAuthClientForTenant(c, tenant.TenantID)
@@ -665,7 +892,7 @@ func (uh *UserSuperAdminHandler) HardDeleteUserFromSuperAdmin(c *gin.Context, te
 		return
 	}
 
-	if err := uh.userService.DeleteUser(c, baseAuthClient, userid); err != nil {
+	if err := uh.userService.DeleteUser(c, baseAuthClient, userid, transfer); err != nil {
 		if err.Error() == "no rows in result set" {
 			// No core_users record — user is orphaned (exists in Kratos/memberships only).
 			// Still remove the Kratos identity so the account cannot be used.
@@ -678,6 +905,9 @@ func (uh *UserSuperAdminHandler) HardDeleteUserFromSuperAdmin(c *gin.Context, te
 			c.Status(http.StatusNoContent)
 			return
 		}
+		if abortIfOwnershipTransferFailed(c, err) {
+			return
+		}
 		if helpers.AbortIfReferenced(c, err,
 			"USER_IN_USE",
 			"user is referenced by other records and cannot be deleted") {
@@ -690,3 +920,146 @@ func (uh *UserSuperAdminHandler) HardDeleteUserFromSuperAdmin(c *gin.Context, te
 
 	c.Status(http.StatusNoContent)
 }
+
+// GetUserTimelineFromSuperAdmin returns the merged activity feed of a tenant user for support (Super Admin)
+func (uh *UserSuperAdminHandler) GetUserTimelineFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userId string, params core.GetUserTimelineFromSuperAdminParams) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	tenant, err := uh.store.Queries.GetTenantByID(c, tenantId)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get tenant")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if !auth.IsAllowedToManageTenant(c, tenant) {
+		logger.Error().Msg("Not allowed to manage this tenant")
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(errors.New("not allowed to manage this tenant")))
+		return
+	}
+
+	categories := []string{}
+	if params.Types != nil {
+		categories = *params.Types
+	}
+	if err := access.ValidateTimelineCategories(categories); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+
+	pagingSql := helpers.GetPagingSQL(helpers.PagingRequest{
+		MaxPageSize:     100,
+		DefaultPage:     1,
+		DefaultPageSize: 50,
+		Page:            params.Page,
+		PageSize:        params.PageSize,
+	})
+
+	events, err := uh.userActivityService.ListUserTimeline(c, tenant.TenantID, userId, categories, pagingSql.PageSize, pagingSql.Offset)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Str("user_id", userId).Msg("Failed to get user timeline")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	result := make([]core.UserTimelineEvent, len(events))
+	for i, event := range events {
+		result[i] = core.UserTimelineEvent{
+			Id:         event.ID,
+			Category:   event.Category,
+			EventType:  event.EventType,
+			ActorId:    event.ActorID,
+			OccurredAt: event.OccurredAt,
+		}
+		if event.Data != nil {
+			data := event.Data
+			result[i].Data = &data
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ImpersonateUserFromSuperAdmin lets the super admin act as a tenant user for a short time
+// (POST /superadmin-api/v1/tenants/{tenantid}/users/{userid}/impersonate)
+func (uh *UserSuperAdminHandler) ImpersonateUserFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	// Resellers reach the tenant endpoints too, impersonation stays with the super admins
+	if err := auth.Authorize(c, auth.OpImpersonateUsers); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	var req core.ImpersonateUserFromSuperAdminJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, err := uh.store.Queries.GetTenantByID(c, tenantId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get tenant")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+
+	token, impersonation, err := uh.impersonationService.StartImpersonation(c, c.GetString(auth.AUTH_USER_ID), tenant.TenantID, userid, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, access.ErrImpersonationReasonRequired):
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		case errors.Is(err, access.ErrImpersonationNotAllowed):
+			c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, helpers.ErrorStringResponse("User not found in this tenant"))
+		default:
+			logger.Err(err).Str("userId", userid).Msg("Failed to start impersonation")
+			c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		}
+		return
+	}
+	id, _ := uuid.Parse(impersonation.ID)
+	c.JSON(http.StatusCreated, core.UserImpersonation{
+		Id:        id,
+		Token:     token,
+		ActorId:   impersonation.ActorID,
+		TenantId:  impersonation.TenantID,
+		UserId:    impersonation.UserID,
+		Reason:    impersonation.Reason,
+		ExpiresAt: impersonation.ExpiresAt,
+	})
+}
+
+// EndUserImpersonationFromSuperAdmin ends the impersonations of a tenant user by the caller
+// (DELETE /superadmin-api/v1/tenants/{tenantid}/users/{userid}/impersonate)
+func (uh *UserSuperAdminHandler) EndUserImpersonationFromSuperAdmin(c *gin.Context, tenantId uuid.UUID, userid string) {
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	if err := auth.Authorize(c, auth.OpImpersonateUsers); err != nil {
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(err))
+		return
+	}
+	tenant, err := uh.store.Queries.GetTenantByID(c, tenantId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, helpers.ErrorResponse(err))
+			return
+		}
+		logger.Err(err).Msg("Failed to get tenant")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	ended, err := uh.impersonationService.EndImpersonations(c, c.GetString(auth.AUTH_USER_ID), tenant.TenantID, userid)
+	if err != nil {
+		logger.Err(err).Str("userId", userid).Msg("Failed to end impersonation")
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
+		return
+	}
+	if !ended {
+		c.JSON(http.StatusNotFound, helpers.ErrorStringResponse("No running impersonation of this user"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
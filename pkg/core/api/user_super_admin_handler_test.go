@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	core "ctoup.com/coreapp/api/openapi/core"
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	access "ctoup.com/coreapp/pkg/shared/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createTimelineTenant(t *testing.T, store *db.Store) repository.CoreTenant {
+	t.Helper()
+	tenant, err := store.CreateTenant(context.Background(), repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	return tenant
+}
+
+func TestGetUserTimelineFromSuperAdmin(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	activities := access.NewUserActivityService(store)
+	uh := &UserSuperAdminHandler{store: store, userActivityService: activities}
+	ctx := context.Background()
+
+	tenant := createTimelineTenant(t, store)
+	otherTenant := createTimelineTenant(t, store)
+	userID := commontestutils.RandomString(12)
+	now := time.Now().UTC().Truncate(time.Second)
+	record := func(tenantID, category, eventType string, at time.Time) {
+		require.NoError(t, activities.RecordUserActivity(ctx, access.UserActivity{
+			TenantID:  tenantID,
+			UserID:    userID,
+			Category:  category,
+			EventType: eventType,
+		}))
+		_, err := store.ConnPool.Exec(ctx,
+			"UPDATE core_user_activity_events SET occurred_at = $1 WHERE tenant_id = $2 AND user_id = $3 AND event_type = $4",
+			at, tenantID, userID, eventType)
+		require.NoError(t, err)
+	}
+	record(tenant.TenantID, access.ActivityCategoryLogin, "login", now.Add(-3*time.Hour))
+	record(tenant.TenantID, access.ActivityCategoryFile, "uploaded", now.Add(-2*time.Hour))
+	record(tenant.TenantID, access.ActivityCategoryAccount, "profile_updated", now.Add(-time.Hour))
+	record(otherTenant.TenantID, access.ActivityCategoryAccount, "password_changed", now)
+
+	timeline := func(tenantID uuid.UUID, params core.GetUserTimelineFromSuperAdminParams) []core.UserTimelineEvent {
+		t.Helper()
+		c, w := tenantAdminRequest(t, "", http.MethodGet, nil)
+		c.Set(auth.AUTH_CLAIMS, map[string]interface{}{string(core.SUPERADMIN): true})
+		uh.GetUserTimelineFromSuperAdmin(c, tenantID, userID, params)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var events []core.UserTimelineEvent
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+		return events
+	}
+	eventTypes := func(events []core.UserTimelineEvent) []string {
+		types := make([]string, len(events))
+		for i, event := range events {
+			types[i] = event.EventType
+		}
+		return types
+	}
+
+	t.Run("newest first", func(t *testing.T) {
+		events := timeline(tenant.ID, core.GetUserTimelineFromSuperAdminParams{})
+		require.Equal(t, []string{"profile_updated", "uploaded", "login"}, eventTypes(events))
+		require.True(t, now.Add(-time.Hour).Equal(events[0].OccurredAt))
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		page, pageSize := int32(2), int32(2)
+		events := timeline(tenant.ID, core.GetUserTimelineFromSuperAdminParams{Page: &page, PageSize: &pageSize})
+		require.Equal(t, []string{"login"}, eventTypes(events))
+	})
+
+	t.Run("types", func(t *testing.T) {
+		types := []string{access.ActivityCategoryLogin, access.ActivityCategoryFile}
+		events := timeline(tenant.ID, core.GetUserTimelineFromSuperAdminParams{Types: &types})
+		require.Equal(t, []string{"uploaded", "login"}, eventTypes(events))
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		types := []string{"billing"}
+		c, w := tenantAdminRequest(t, "", http.MethodGet, nil)
+		c.Set(auth.AUTH_CLAIMS, map[string]interface{}{string(core.SUPERADMIN): true})
+		uh.GetUserTimelineFromSuperAdmin(c, tenant.ID, userID, core.GetUserTimelineFromSuperAdminParams{Types: &types})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("tenant scoping", func(t *testing.T) {
+		events := timeline(otherTenant.ID, core.GetUserTimelineFromSuperAdminParams{})
+		require.Equal(t, []string{"password_changed"}, eventTypes(events))
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"ctoup.com/coreapp/pkg/core/db/repository"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"ctoup.com/coreapp/pkg/shared/service"
 	"ctoup.com/coreapp/pkg/shared/util"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -37,6 +38,18 @@ func (exh *TenantConfigHandler) AddTenantConfig(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
 		return
 	}
+	if err := service.ValidateTenantPublicHeaderConfig(req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if err := service.ValidateTenantDisplayNameConfig(req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if err := service.ValidateTenantReplayCaptureConfig(req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
 	userID, exist := c.Get(auth.AUTH_USER_ID)
 	if !exist {
 		// should not happen as the middleware ensures that the user is authenticated
@@ -56,6 +69,9 @@ func (exh *TenantConfigHandler) AddTenantConfig(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	service.InvalidateTenantPublicHeaders(tenantID.(string))
+	service.InvalidateDisplayNamePolicy(tenantID.(string))
+	service.InvalidateReplayCapture(tenantID.(string))
 	c.JSON(http.StatusCreated, tenantConfig)
 }
 
@@ -73,6 +89,18 @@ func (exh *TenantConfigHandler) UpdateTenantConfig(c *gin.Context, id uuid.UUID)
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
 		return
 	}
+	if err := service.ValidateTenantPublicHeaderConfig(req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if err := service.ValidateTenantDisplayNameConfig(req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
+	if err := service.ValidateTenantReplayCaptureConfig(req.Name, req.Value); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(err))
+		return
+	}
 	_, err := exh.store.UpdateTenantConfig(c,
 		repository.UpdateTenantConfigParams{
 			ID:       id,
@@ -85,6 +113,9 @@ func (exh *TenantConfigHandler) UpdateTenantConfig(c *gin.Context, id uuid.UUID)
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	service.InvalidateTenantPublicHeaders(tenantID.(string))
+	service.InvalidateDisplayNamePolicy(tenantID.(string))
+	service.InvalidateReplayCapture(tenantID.(string))
 	c.Status(http.StatusNoContent)
 }
 
@@ -111,6 +142,9 @@ func (exh *TenantConfigHandler) DeleteTenantConfig(c *gin.Context, id uuid.UUID)
 		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(err))
 		return
 	}
+	service.InvalidateTenantPublicHeaders(tenantID.(string))
+	service.InvalidateDisplayNamePolicy(tenantID.(string))
+	service.InvalidateReplayCapture(tenantID.(string))
 	c.Status(http.StatusNoContent)
 }
 
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Per-token request budgets enforced by the API token middleware.
+-- NULL means the token is not rate limited for that window.
+ALTER TABLE core_api_tokens
+    ADD COLUMN rate_limit_per_minute INTEGER NULL,
+    ADD COLUMN rate_limit_per_hour INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE core_api_tokens
+    DROP COLUMN IF EXISTS rate_limit_per_hour,
+    DROP COLUMN IF EXISTS rate_limit_per_minute;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Usage analytics aggregate a token's audit rows over a time range.
+CREATE INDEX idx_api_token_audit_logs_token_action_timestamp
+    ON core_api_token_audit_logs (token_id, action, timestamp);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_api_token_audit_logs_token_action_timestamp;
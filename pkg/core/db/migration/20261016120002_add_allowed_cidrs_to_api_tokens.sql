@@ -0,0 +1,8 @@
+-- +goose Up
+-- Client IP allowlist of an API token. An empty list allows any address.
+ALTER TABLE core_api_tokens
+    ADD COLUMN allowed_cidrs TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE core_api_tokens
+    DROP COLUMN IF EXISTS allowed_cidrs;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Per-user activity feed (logins, membership changes, prompt executions, file
+-- uploads, ...) shown on the support timeline. Rows outlive the membership so a
+-- removal stays visible after the user left the tenant.
+CREATE TABLE core_user_activity_events (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '', -- empty for global events
+    user_id VARCHAR(128) NOT NULL,
+    category VARCHAR(32) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    actor_id VARCHAR(128) NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    data JSONB NULL,
+    CONSTRAINT user_activity_events_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_user_activity_events_tenant_user_occurred_at
+    ON core_user_activity_events (tenant_id, user_id, occurred_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_activity_events;
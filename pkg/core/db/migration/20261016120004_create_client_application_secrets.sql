@@ -0,0 +1,19 @@
+-- +goose Up
+-- OAuth2 client credentials: one secret per client application, used to
+-- exchange client_id/client_secret for short-lived bearer JWTs. Rotating the
+-- secret assigns a new id, which invalidates the JWTs issued with the old one.
+CREATE TABLE core_client_application_secrets (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    client_application_id uuid NOT NULL REFERENCES core_client_applications(id) ON DELETE CASCADE,
+    -- Store only the hash (SHA-256) of the secret
+    secret_hash BYTEA NOT NULL,
+    secret_prefix VARCHAR(8) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}', -- Scopes a client may request
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT client_application_secrets_pk PRIMARY KEY (id),
+    CONSTRAINT unique_client_application_secret UNIQUE (client_application_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_client_application_secrets;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Time-bound announcement banners. Global ones (empty tenant_id) are published
+-- by SUPER_ADMINs and shown on every tenant; tenant ones by CUSTOMER_ADMINs.
+-- An announcement is visible from starts_at until ends_at.
+CREATE TABLE core_announcements (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '', -- empty for global announcements
+    title VARCHAR(200) NOT NULL,
+    message TEXT NOT NULL,
+    severity VARCHAR(16) NOT NULL DEFAULT 'info',
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT announcements_pk PRIMARY KEY (id),
+    CONSTRAINT announcements_severity_check CHECK (severity IN ('info', 'warning', 'critical')),
+    CONSTRAINT announcements_period_check CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_announcements_tenant_id_ends_at ON core_announcements (tenant_id, ends_at);
+
+CREATE TRIGGER update_announcements_modtime
+BEFORE UPDATE ON core_announcements
+FOR EACH ROW
+EXECUTE FUNCTION update_modified_column();
+
+-- +goose Down
+DROP TABLE IF EXISTS core_announcements;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Hashing algorithm of token_hash: 1 = SHA-256, 2 = HMAC-SHA256 with the
+-- server pepper, 3 = argon2id. Older hashes are upgraded on next use.
+ALTER TABLE core_api_tokens
+    ADD COLUMN hash_version SMALLINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE core_api_tokens
+    DROP COLUMN IF EXISTS hash_version;
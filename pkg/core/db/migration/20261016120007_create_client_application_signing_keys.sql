@@ -0,0 +1,16 @@
+-- +goose Up
+-- HMAC request signing: one signing key per client application. The secret is
+-- derived from the server master key and the key id, so nothing secret is
+-- stored; rotating assigns a new id and therefore a new secret.
+CREATE TABLE core_client_application_signing_keys (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    client_application_id uuid NOT NULL REFERENCES core_client_applications(id) ON DELETE CASCADE,
+    scopes TEXT[] NOT NULL DEFAULT '{}', -- Scopes granted to signed requests
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT client_application_signing_keys_pk PRIMARY KEY (id),
+    CONSTRAINT unique_client_application_signing_key UNIQUE (client_application_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_client_application_signing_keys;
@@ -0,0 +1,48 @@
+-- +goose Up
+-- Compliance exports of the tenant audit trail and API usage. A schedule
+-- creates one export per elapsed period; on-demand exports have no schedule.
+CREATE TABLE core_tenant_export_schedules (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    dataset VARCHAR(16) NOT NULL, -- audit, usage
+    format VARCHAR(16) NOT NULL, -- csv, jsonl, ocsf
+    destination VARCHAR(16) NOT NULL, -- download, bucket
+    frequency VARCHAR(16) NOT NULL, -- daily, weekly
+    next_run_at TIMESTAMPTZ NOT NULL, -- end of the next period to export
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT tenant_export_schedules_pk PRIMARY KEY (id),
+    CONSTRAINT unique_tenant_export_schedule UNIQUE (tenant_id, dataset, format)
+);
+
+CREATE INDEX idx_tenant_export_schedules_next_run_at ON core_tenant_export_schedules (next_run_at);
+
+CREATE TABLE core_tenant_exports (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    schedule_id uuid NULL REFERENCES core_tenant_export_schedules(id) ON DELETE SET NULL,
+    dataset VARCHAR(16) NOT NULL,
+    format VARCHAR(16) NOT NULL,
+    destination VARCHAR(16) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    file_path TEXT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    requested_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    completed_at TIMESTAMPTZ NULL,
+    CONSTRAINT tenant_exports_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_tenant_exports_tenant_created_at ON core_tenant_exports (tenant_id, created_at DESC);
+
+-- Exports read the activity of a whole tenant over a period
+CREATE INDEX idx_user_activity_events_tenant_occurred_at
+    ON core_user_activity_events (tenant_id, occurred_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_activity_events_tenant_occurred_at;
+DROP TABLE IF EXISTS core_tenant_exports;
+DROP TABLE IF EXISTS core_tenant_export_schedules;
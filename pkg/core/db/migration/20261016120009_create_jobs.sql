@@ -0,0 +1,34 @@
+-- +goose Up
+-- Long running operations and the progress events they emit. Events are read
+-- back by the long polling endpoint; their id is the cursor clients resume from.
+CREATE TABLE core_jobs (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    kind VARCHAR(64) NOT NULL, -- user_import
+    status VARCHAR(16) NOT NULL DEFAULT 'running', -- running, completed, failed
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    completed_at TIMESTAMPTZ NULL,
+    CONSTRAINT jobs_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_jobs_created_at ON core_jobs (created_at);
+
+CREATE TABLE core_job_events (
+    id BIGSERIAL NOT NULL,
+    job_id uuid NOT NULL REFERENCES core_jobs(id) ON DELETE CASCADE,
+    event_type VARCHAR(16) NOT NULL,
+    message TEXT NOT NULL,
+    progress INT NOT NULL,
+    data_type VARCHAR(64) NOT NULL DEFAULT '',
+    data_version INT NOT NULL DEFAULT 0,
+    data JSONB NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT job_events_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_job_events_job_id ON core_job_events (job_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_job_events;
+DROP TABLE IF EXISTS core_jobs;
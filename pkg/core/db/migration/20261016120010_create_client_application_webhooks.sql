@@ -0,0 +1,16 @@
+-- +goose Up
+-- Credential lifecycle webhooks: one endpoint per client application. As for
+-- the request signing keys, the secret is derived from the server master key
+-- and the webhook id, so nothing secret is stored.
+CREATE TABLE core_client_application_webhooks (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    client_application_id uuid NOT NULL REFERENCES core_client_applications(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT client_application_webhooks_pk PRIMARY KEY (id),
+    CONSTRAINT unique_client_application_webhook UNIQUE (client_application_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_client_application_webhooks;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Additional subdomains of a tenant, kept working after a rebrand. The
+-- primary subdomain stays in core_tenants.subdomain; an alias never equals
+-- the subdomain of a tenant, which the insert query checks.
+CREATE TABLE core_tenant_subdomain_aliases (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    subdomain VARCHAR(255) NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT tenant_subdomain_aliases_pk PRIMARY KEY (id),
+    CONSTRAINT unique_tenant_subdomain_alias UNIQUE (subdomain)
+);
+CREATE INDEX idx_tenant_subdomain_aliases_tenant_id ON core_tenant_subdomain_aliases(tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_subdomain_aliases;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Quota of active (neither revoked nor expired) API tokens per client
+-- application. NULL means unlimited; only SUPER_ADMIN changes it.
+ALTER TABLE core_client_applications
+    ADD COLUMN max_active_tokens INTEGER DEFAULT 100
+    CONSTRAINT client_applications_max_active_tokens_positive CHECK (max_active_tokens IS NULL OR max_active_tokens > 0);
+
+-- +goose Down
+ALTER TABLE core_client_applications DROP COLUMN IF EXISTS max_active_tokens;
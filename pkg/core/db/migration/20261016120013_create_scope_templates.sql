@@ -0,0 +1,29 @@
+-- +goose Up
+-- Named scope bundles, per tenant (NULL tenant_id for global applications),
+-- that API tokens can be created from. propagate_at schedules copying the
+-- template scopes to the active tokens created from it.
+CREATE TABLE core_scope_templates (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    propagate_at TIMESTAMPTZ NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    updated_by VARCHAR(128) NULL,
+    CONSTRAINT scope_templates_pk PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX unique_scope_template_name ON core_scope_templates (COALESCE(tenant_id, ''), name);
+CREATE INDEX idx_scope_templates_propagate_at ON core_scope_templates (propagate_at) WHERE propagate_at IS NOT NULL;
+
+ALTER TABLE core_api_tokens
+    ADD COLUMN scope_template_id uuid NULL REFERENCES core_scope_templates(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_api_tokens_scope_template_id ON core_api_tokens (scope_template_id) WHERE scope_template_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE core_api_tokens DROP COLUMN IF EXISTS scope_template_id;
+DROP TABLE IF EXISTS core_scope_templates;
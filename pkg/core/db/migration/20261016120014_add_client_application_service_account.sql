@@ -0,0 +1,12 @@
+-- +goose Up
+-- Synthetic user identity of the application. When set, requests
+-- authenticated by the application run as this user rather than as the
+-- creator of the application.
+ALTER TABLE core_client_applications
+    ADD COLUMN service_account_id VARCHAR(128) NULL;
+
+CREATE UNIQUE INDEX unique_client_application_service_account ON core_client_applications (service_account_id)
+    WHERE service_account_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE core_client_applications DROP COLUMN IF EXISTS service_account_id;
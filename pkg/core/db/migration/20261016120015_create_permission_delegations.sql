@@ -0,0 +1,29 @@
+-- +goose Up
+-- Time-bound delegation of operations from a user to another in a tenant,
+-- such as a CUSTOMER_ADMIN going on vacation. delegator_roles is the snapshot
+-- of the delegator's roles the delegated operations are evaluated against. A
+-- delegation is honored from starts_at until ends_at unless revoked.
+CREATE TABLE core_permission_delegations (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    delegator_id VARCHAR(128) NOT NULL,
+    delegate_id VARCHAR(128) NOT NULL,
+    operations TEXT[] NOT NULL,
+    delegator_roles TEXT[] NOT NULL DEFAULT '{}',
+    reason TEXT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    revoked_at TIMESTAMPTZ NULL,
+    revoked_by VARCHAR(128) NULL,
+    CONSTRAINT permission_delegations_pk PRIMARY KEY (id),
+    CONSTRAINT permission_delegations_period_check CHECK (ends_at > starts_at),
+    CONSTRAINT permission_delegations_self_check CHECK (delegator_id <> delegate_id)
+);
+
+CREATE INDEX idx_permission_delegations_delegate ON core_permission_delegations (tenant_id, delegate_id, ends_at)
+    WHERE revoked_at IS NULL;
+CREATE INDEX idx_permission_delegations_delegator ON core_permission_delegations (tenant_id, delegator_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_permission_delegations;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Rows rejected by an import job, kept with the job so the failing rows can be
+-- downloaded as a report, fixed and uploaded again.
+CREATE TABLE core_job_row_errors (
+    id BIGSERIAL NOT NULL,
+    job_id uuid NOT NULL REFERENCES core_jobs(id) ON DELETE CASCADE,
+    line INT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    code VARCHAR(64) NOT NULL,
+    message TEXT NOT NULL,
+    CONSTRAINT job_row_errors_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_job_row_errors_job_id ON core_job_row_errors (job_id, line);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_job_row_errors;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Constraints on the API tokens created in a tenant, set by the super admins
+-- to meet the compliance requirements of the tenant. A NULL column does not
+-- constrain tokens.
+CREATE TABLE core_token_policies (
+    tenant_id VARCHAR(64) NOT NULL,
+    max_expiry_days INT NULL CHECK (max_expiry_days > 0),
+    allowed_scopes TEXT[] NULL,
+    require_description BOOLEAN NOT NULL DEFAULT false,
+    updated_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT token_policies_pk PRIMARY KEY (tenant_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_token_policies;
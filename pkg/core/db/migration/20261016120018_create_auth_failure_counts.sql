@@ -0,0 +1,16 @@
+-- +goose Up
+-- Authentication failures per tenant, reason and hour, for the tenant
+-- integration health summary. Failures on the root domain have an empty
+-- tenant_id.
+CREATE TABLE core_auth_failure_counts (
+    tenant_id VARCHAR(64) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    count BIGINT NOT NULL,
+    CONSTRAINT auth_failure_counts_pk PRIMARY KEY (tenant_id, bucket, reason)
+);
+
+CREATE INDEX auth_failure_counts_bucket_idx ON core_auth_failure_counts (bucket);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_auth_failure_counts;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- With email encryption enabled, core_users.email holds the encrypted address
+-- and email_blind_index an HMAC of the normalized address, used for equality
+-- lookups. Encrypted addresses exceed the former 254 characters.
+ALTER TABLE core_users
+    ALTER COLUMN email TYPE TEXT,
+    ADD COLUMN email_blind_index VARCHAR(64) NULL;
+
+CREATE INDEX idx_users_email_blind_index ON core_users (email_blind_index)
+    WHERE email_blind_index IS NOT NULL;
+
+-- +goose Down
+-- Fails while encrypted addresses are stored: rewrite them with encryption
+-- turned off first
+DROP INDEX IF EXISTS idx_users_email_blind_index;
+ALTER TABLE core_users
+    DROP COLUMN IF EXISTS email_blind_index,
+    ALTER COLUMN email TYPE VARCHAR(254);
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Escalations sent for pending items (invitations, approval requests, signup
+-- requests) that exceeded their SLA target. An item is keyed by the time it
+-- became pending, so an invitation sent again can be escalated again.
+CREATE TABLE core_sla_escalations (
+    item_type VARCHAR(32) NOT NULL,
+    item_id VARCHAR(128) NOT NULL,
+    pending_since TIMESTAMPTZ NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '',
+    escalated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT sla_escalations_pk PRIMARY KEY (item_type, item_id, pending_since)
+);
+
+-- Overdue invitations are looked up by status and age
+CREATE INDEX idx_user_tenant_memberships_pending_invited_at
+    ON core_user_tenant_memberships (COALESCE(invited_at, created_at))
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_tenant_memberships_pending_invited_at;
+DROP TABLE IF EXISTS core_sla_escalations;
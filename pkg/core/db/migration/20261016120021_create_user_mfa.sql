@@ -0,0 +1,35 @@
+-- +goose Up
+-- TOTP second factor of a user. The secret is encrypted with a key of
+-- MFA_ENCRYPTION_KEYS; recovery codes are stored as SHA-256 hex digests.
+CREATE TABLE core_user_mfa (
+    user_id VARCHAR NOT NULL,
+    secret_encrypted TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    recovery_code_hashes TEXT[] NOT NULL DEFAULT '{}',
+    -- Last accepted time step, so a code cannot be replayed
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    enabled_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT user_mfa_pk PRIMARY KEY (user_id),
+    CONSTRAINT fk_user_mfa_user FOREIGN KEY (user_id) REFERENCES core_users(id) ON DELETE CASCADE
+);
+
+-- Sessions that passed the TOTP check, by SHA-256 of the token sent in the
+-- X-MFA-Token header
+CREATE TABLE core_user_mfa_sessions (
+    token_hash BYTEA NOT NULL,
+    user_id VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT user_mfa_sessions_pk PRIMARY KEY (token_hash),
+    CONSTRAINT fk_user_mfa_sessions_user FOREIGN KEY (user_id) REFERENCES core_users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_mfa_sessions_user_id ON core_user_mfa_sessions (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_mfa_sessions;
+DROP TABLE IF EXISTS core_user_mfa;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Demo and trial tenants that sales can reset. The template holds the tenant
+-- settings (sign-up options, features, profile, configs) re-applied by a reset.
+CREATE TABLE core_tenant_sandboxes (
+    tenant_id VARCHAR(64) NOT NULL REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    template JSONB NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Set while a reset runs, so two resets never run at once
+    reset_started_at TIMESTAMPTZ,
+    last_reset_at TIMESTAMPTZ,
+    last_reset_by VARCHAR(128),
+    last_reset_job_id UUID,
+    last_reset_status VARCHAR(16),
+    CONSTRAINT tenant_sandboxes_pk PRIMARY KEY (tenant_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_sandboxes;
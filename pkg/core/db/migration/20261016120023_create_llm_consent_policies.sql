@@ -0,0 +1,19 @@
+-- +goose Up
+-- What a tenant allows to be done with the inputs and outputs of its prompt
+-- executions. Prompts may restrict it further, never relax it. A tenant
+-- without a row allows everything, as before the policies existed.
+CREATE TABLE core_llm_consent_policies (
+    tenant_id VARCHAR(64) NOT NULL,
+    store_inputs BOOLEAN NOT NULL DEFAULT true,
+    store_outputs BOOLEAN NOT NULL DEFAULT true,
+    allow_analytics BOOLEAN NOT NULL DEFAULT true,
+    -- provider regions the data may be sent to, NULL for any region
+    allowed_regions TEXT[] NULL,
+    updated_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT llm_consent_policies_pk PRIMARY KEY (tenant_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_llm_consent_policies;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Impersonations of tenant users by super admins, kept after they end as an
+-- audit trail. The token sent in the X-Impersonation-Token header is stored
+-- as its SHA-256.
+CREATE TABLE core_user_impersonations (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    token_hash BYTEA NOT NULL,
+    actor_id VARCHAR NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR NOT NULL,
+    reason TEXT NOT NULL CHECK (length(btrim(reason)) > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ NULL,
+    CONSTRAINT user_impersonations_pk PRIMARY KEY (id),
+    CONSTRAINT user_impersonations_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_user_impersonations_user ON core_user_impersonations (tenant_id, user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_impersonations;
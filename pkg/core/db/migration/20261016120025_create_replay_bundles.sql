@@ -0,0 +1,23 @@
+-- +goose Up
+-- Failing requests of the tenants that opted in to the replay capture, kept
+-- for support to reproduce them. The bundle holds the sanitized request, the
+-- resolved context, the downstream calls and the response.
+CREATE TABLE core_replay_bundles (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    request_id VARCHAR NOT NULL,
+    user_id VARCHAR NULL,
+    method VARCHAR(16) NOT NULL,
+    route VARCHAR NOT NULL,
+    status INTEGER NOT NULL,
+    bundle JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT replay_bundles_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_replay_bundles_tenant ON core_replay_bundles (tenant_id, created_at DESC);
+CREATE INDEX idx_replay_bundles_request ON core_replay_bundles (request_id);
+CREATE INDEX idx_replay_bundles_created_at ON core_replay_bundles (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_replay_bundles;
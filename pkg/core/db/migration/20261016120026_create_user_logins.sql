@@ -0,0 +1,26 @@
+-- +goose Up
+-- Last login of each user per tenant, the tenant is '' on the admin domain.
+-- A login is the first verified request of a provider session: later requests
+-- of the same session leave the row as it is.
+CREATE TABLE core_user_logins (
+    user_id VARCHAR NOT NULL REFERENCES core_users (id) ON DELETE CASCADE,
+    tenant_id VARCHAR(64) NOT NULL,
+    session_id VARCHAR NOT NULL,
+    last_login_at TIMESTAMPTZ NOT NULL,
+    last_login_ip VARCHAR(64) NULL,
+    CONSTRAINT user_logins_pk PRIMARY KEY (user_id, tenant_id)
+);
+
+CREATE INDEX idx_user_logins_tenant ON core_user_logins (tenant_id, last_login_at);
+
+-- The logins recorded by the login webhook so far, so the accounts are not all
+-- stale on the day of the upgrade
+INSERT INTO core_user_logins (user_id, tenant_id, session_id, last_login_at)
+SELECT e.user_id, e.tenant_id, '', MAX(e.occurred_at)
+FROM core_user_activity_events e
+INNER JOIN core_users u ON u.id = e.user_id
+WHERE e.category = 'login' AND e.event_type = 'logged_in'
+GROUP BY e.user_id, e.tenant_id;
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_logins;
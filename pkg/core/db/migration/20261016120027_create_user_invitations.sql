@@ -0,0 +1,29 @@
+-- +goose Up
+-- Invitations to join a tenant, or the admin domain with an empty tenant. The
+-- email is encrypted like core_users.email and only the hash of the token is
+-- kept. An expired invitation stays pending until it is resent or revoked.
+CREATE TABLE core_user_invitations (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    email VARCHAR NOT NULL,
+    email_blind_index VARCHAR NULL,
+    roles VARCHAR[] NOT NULL DEFAULT '{}',
+    token_hash BYTEA NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    invited_by VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NULL, -- the user who accepted
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    send_count INT NOT NULL DEFAULT 1,
+    accepted_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_invitations_pk PRIMARY KEY (id),
+    CONSTRAINT user_invitations_status_check CHECK (status IN ('pending', 'accepted', 'revoked'))
+);
+
+CREATE UNIQUE INDEX idx_user_invitations_token_hash ON core_user_invitations (token_hash);
+CREATE INDEX idx_user_invitations_tenant_created_at ON core_user_invitations (tenant_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_invitations;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Outcomes of the cross-tenant probes: each row is one attempt of a forged
+-- caller of the attacker tenant to read a resource of the victim tenant.
+-- Every outcome other than 'leaked' means the boundary held or the probe
+-- could not run.
+CREATE TABLE core_tenant_isolation_probe_results (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    run_id uuid NOT NULL,
+    probe VARCHAR(64) NOT NULL,
+    attacker_tenant_id VARCHAR(64) NOT NULL,
+    victim_tenant_id VARCHAR(64) NOT NULL,
+    resource_id VARCHAR(128) NOT NULL DEFAULT '',
+    outcome VARCHAR(16) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tenant_isolation_probe_results_pk PRIMARY KEY (id),
+    CONSTRAINT tenant_isolation_probe_results_outcome_check CHECK (outcome IN ('blocked', 'leaked', 'skipped', 'error'))
+);
+
+CREATE INDEX idx_tenant_isolation_probe_results_created_at ON core_tenant_isolation_probe_results (created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_isolation_probe_results;
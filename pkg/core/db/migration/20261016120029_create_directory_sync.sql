@@ -0,0 +1,60 @@
+-- +goose Up
+-- Directory sync of a tenant with its Google Workspace or Microsoft 365
+-- directory. The credentials are encrypted with DIRECTORY_SYNC_ENCRYPTION_KEYS.
+CREATE TABLE core_directory_connections (
+    tenant_id VARCHAR(64) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    settings JSONB NOT NULL DEFAULT '{}', -- domain, customer or directory ids
+    credentials TEXT NOT NULL,
+    role_rules JSONB NOT NULL DEFAULT '[]', -- [{"group": "...", "roles": ["..."]}]
+    default_roles VARCHAR[] NOT NULL DEFAULT '{USER}', -- empty: only the members of a rule group are synced
+    deprovision_action VARCHAR(16) NOT NULL DEFAULT 'suspend',
+    last_sync_status VARCHAR(16) NULL, -- running, succeeded, failed
+    last_sync_started_at TIMESTAMPTZ NULL,
+    last_sync_finished_at TIMESTAMPTZ NULL,
+    last_sync_error TEXT NULL,
+    last_sync_stats JSONB NOT NULL DEFAULT '{}',
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT directory_connections_pk PRIMARY KEY (tenant_id),
+    CONSTRAINT directory_connections_provider_check CHECK (provider IN ('google_workspace', 'microsoft_365')),
+    CONSTRAINT directory_connections_deprovision_check CHECK (deprovision_action IN ('suspend', 'remove'))
+);
+
+-- The users a sync provisioned or linked, by their id in the directory. Only
+-- them are deprovisioned when they leave the directory.
+CREATE TABLE core_directory_users (
+    tenant_id VARCHAR(64) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    roles VARCHAR[] NOT NULL DEFAULT '{}', -- last roles applied by the sync
+    suspended BOOLEAN NOT NULL DEFAULT FALSE,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT directory_users_pk PRIMARY KEY (tenant_id, external_id),
+    CONSTRAINT unique_directory_user UNIQUE (tenant_id, user_id)
+);
+
+CREATE INDEX idx_directory_users_user_id ON core_directory_users (user_id);
+
+-- Directory users the last sync of the tenant could not apply
+CREATE TABLE core_directory_sync_conflicts (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    email VARCHAR NOT NULL DEFAULT '', -- encrypted like core_users.email
+    user_id VARCHAR(128) NULL,
+    kind VARCHAR(32) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT directory_sync_conflicts_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_directory_sync_conflicts_tenant ON core_directory_sync_conflicts (tenant_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_directory_sync_conflicts;
+DROP TABLE IF EXISTS core_directory_users;
+DROP TABLE IF EXISTS core_directory_connections;
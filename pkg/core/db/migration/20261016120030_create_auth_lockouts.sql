@@ -0,0 +1,23 @@
+-- +goose Up
+-- Failed authentications and verifications of a user or a client IP over the
+-- current window. Once the failures reach the threshold the subject is locked
+-- until locked_until; disabled is set when the lockout also disabled the
+-- account in the auth provider, until an administrator unlocks it.
+CREATE TABLE core_auth_lockouts (
+    subject_type VARCHAR(8) NOT NULL,
+    subject VARCHAR(128) NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '',
+    failures INT NOT NULL DEFAULT 0,
+    window_started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_reason VARCHAR(32) NOT NULL DEFAULT '',
+    locked_until TIMESTAMPTZ NULL,
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT auth_lockouts_pk PRIMARY KEY (subject_type, subject),
+    CONSTRAINT auth_lockouts_subject_type_check CHECK (subject_type IN ('user', 'ip'))
+);
+
+CREATE INDEX idx_auth_lockouts_locked_until ON core_auth_lockouts (locked_until) WHERE locked_until IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS core_auth_lockouts;
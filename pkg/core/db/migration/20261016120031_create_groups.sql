@@ -0,0 +1,32 @@
+-- +goose Up
+-- Groups of users within a tenant. The roles of a group are granted to its
+-- members on top of their own, for as long as they belong to it.
+CREATE TABLE core_groups (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    description TEXT NULL,
+    roles VARCHAR[] NOT NULL DEFAULT '{}',
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT groups_pk PRIMARY KEY (id),
+    CONSTRAINT fk_groups_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_groups_tenant_name ON core_groups (tenant_id, lower(name));
+
+CREATE TABLE core_group_members (
+    group_id uuid NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    added_by VARCHAR(128) NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT group_members_pk PRIMARY KEY (group_id, user_id),
+    CONSTRAINT fk_group_members_group FOREIGN KEY (group_id) REFERENCES core_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_group_members_user_id ON core_group_members (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_group_members;
+DROP TABLE IF EXISTS core_groups;
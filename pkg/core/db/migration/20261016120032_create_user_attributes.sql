@@ -0,0 +1,19 @@
+-- +goose Up
+-- Extra profile fields a tenant defines for its users. The values are kept in
+-- the attributes object of core_users.profile.
+CREATE TABLE core_user_attributes (
+    tenant_id VARCHAR(64) NOT NULL,
+    key VARCHAR(64) NOT NULL,
+    label VARCHAR(128) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    enum_values VARCHAR[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_attributes_pk PRIMARY KEY (tenant_id, key),
+    CONSTRAINT fk_user_attributes_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    CONSTRAINT user_attributes_type_check CHECK (type IN ('string', 'number', 'boolean', 'date', 'enum'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_attributes;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Data keys encrypting the files of a tenant, wrapped by a master key. The
+-- active key encrypts the new files; a rotated key still decrypts the files
+-- written with it until they are rewritten, after which it is retired.
+CREATE TABLE core_tenant_file_keys (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    master_key_id VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMPTZ NULL,
+    retired_at TIMESTAMPTZ NULL,
+    CONSTRAINT tenant_file_keys_pk PRIMARY KEY (id),
+    CONSTRAINT fk_tenant_file_keys_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    CONSTRAINT tenant_file_keys_status_check CHECK (status IN ('active', 'rotated', 'retired'))
+);
+
+CREATE UNIQUE INDEX idx_tenant_file_keys_active ON core_tenant_file_keys (tenant_id) WHERE status = 'active';
+
+-- Tenants encrypting their files, with the state of the background rewrite
+-- of their files after encryption was enabled or the key rotated
+CREATE TABLE core_tenant_file_encryption (
+    tenant_id VARCHAR(64) NOT NULL,
+    enabled_by VARCHAR(128) NOT NULL,
+    enabled_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rewrite_status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    rewrite_requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rewrite_started_at TIMESTAMPTZ NULL,
+    rewrite_completed_at TIMESTAMPTZ NULL,
+    files_rewritten INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    CONSTRAINT tenant_file_encryption_pk PRIMARY KEY (tenant_id),
+    CONSTRAINT fk_tenant_file_encryption_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    CONSTRAINT tenant_file_encryption_status_check CHECK (rewrite_status IN ('idle', 'pending', 'running', 'failed'))
+);
+
+CREATE INDEX idx_tenant_file_encryption_pending ON core_tenant_file_encryption (rewrite_requested_at)
+    WHERE rewrite_status IN ('pending', 'running');
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_file_encryption;
+DROP TABLE IF EXISTS core_tenant_file_keys;
@@ -0,0 +1,66 @@
+-- +goose Up
+-- Full-text user search over the email address and the profile, accent and
+-- case insensitive, with trigram matching for typos and partial words
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent is only stable because its dictionary may change, naming the
+-- dictionary lets the wrappers be immutable and used in indexes
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION core_user_search_normalize(value TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(public.unaccent('public.unaccent'::regdictionary, value))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- The searched text of a user: the email address, unless it is encrypted,
+-- the name and the profile fields describing the user
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION core_user_search_text(email TEXT, profile JSONB)
+RETURNS TEXT AS $$
+    SELECT core_user_search_normalize(concat_ws(' ',
+        CASE WHEN email LIKE 'enc:%' THEN NULL ELSE email END,
+        profile->>'name',
+        profile->>'title',
+        profile->>'function',
+        profile->>'company'))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- Words are split on punctuation, so jane.doe@example.com is found by doe
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION core_user_search_vector(email TEXT, profile JSONB)
+RETURNS tsvector AS $$
+    SELECT to_tsvector('simple', regexp_replace(core_user_search_text(email, profile), '[^[:alnum:]]+', ' ', 'g'))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- Every word of the search must start a word of the user
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION core_user_search_query(search TEXT)
+RETURNS tsquery AS $$
+    SELECT to_tsquery('simple', coalesce(string_agg(word || ':*', ' & '), ''))
+    FROM regexp_split_to_table(core_user_search_normalize(search), '[^[:alnum:]]+') AS word
+    WHERE word <> ''
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION core_user_search_rank(email TEXT, profile JSONB, search TEXT)
+RETURNS REAL AS $$
+    SELECT ts_rank(core_user_search_vector(email, profile), core_user_search_query(search))
+        + word_similarity(core_user_search_normalize(search), core_user_search_text(email, profile))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+CREATE INDEX idx_core_users_search_vector ON core_users USING GIN (core_user_search_vector(email, profile));
+CREATE INDEX idx_core_users_search_trgm ON core_users USING GIN (core_user_search_text(email, profile) gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_core_users_search_trgm;
+DROP INDEX IF EXISTS idx_core_users_search_vector;
+DROP FUNCTION IF EXISTS core_user_search_rank(TEXT, JSONB, TEXT);
+DROP FUNCTION IF EXISTS core_user_search_query(TEXT);
+DROP FUNCTION IF EXISTS core_user_search_vector(TEXT, JSONB);
+DROP FUNCTION IF EXISTS core_user_search_text(TEXT, JSONB);
+DROP FUNCTION IF EXISTS core_user_search_normalize(TEXT);
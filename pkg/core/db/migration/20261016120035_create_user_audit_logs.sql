@@ -0,0 +1,21 @@
+-- +goose Up
+-- Admin-facing audit trail of the changes made to a user account: creation,
+-- updates, roles, status, deletion and password resets. Unlike the activity
+-- feed it only holds actions on the account, with who made them.
+CREATE TABLE core_user_audit_logs (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '', -- empty for global users
+    user_id VARCHAR(128) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    actor_id VARCHAR(128) NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    details JSONB NULL,
+    CONSTRAINT user_audit_logs_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_user_audit_logs_tenant_user_occurred_at
+    ON core_user_audit_logs (tenant_id, user_id, occurred_at DESC);
+CREATE INDEX idx_user_audit_logs_actor_id ON core_user_audit_logs (actor_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_audit_logs;
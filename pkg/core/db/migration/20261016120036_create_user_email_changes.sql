@@ -0,0 +1,29 @@
+-- +goose Up
+-- Email changes waiting for the confirmation of the new address. The
+-- addresses are encrypted like core_users.email and only the hashes of the
+-- tokens are kept: the confirmation token sent to the new address, then the
+-- revert token sent to the old one once the change is made.
+CREATE TABLE core_user_email_changes (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    old_email VARCHAR NOT NULL,
+    new_email VARCHAR NOT NULL,
+    token_hash BYTEA NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMPTZ NOT NULL,
+    revert_token_hash BYTEA NULL,
+    revert_expires_at TIMESTAMPTZ NULL,
+    confirmed_at TIMESTAMPTZ NULL,
+    reverted_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_email_changes_pk PRIMARY KEY (id),
+    CONSTRAINT user_email_changes_status_check CHECK (status IN ('pending', 'confirmed', 'reverted', 'cancelled'))
+);
+
+CREATE UNIQUE INDEX idx_user_email_changes_token_hash ON core_user_email_changes (token_hash);
+CREATE UNIQUE INDEX idx_user_email_changes_revert_token_hash ON core_user_email_changes (revert_token_hash);
+CREATE INDEX idx_user_email_changes_user_created_at ON core_user_email_changes (user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_email_changes;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- SMS codes confirming the phone number of a user. Only the hash of a code is
+-- kept, and attempts counts the wrong guesses so a code cannot be brute
+-- forced. The number is verified once a row has a verified_at; the profile
+-- then holds it with phoneNumberVerified.
+CREATE TABLE core_user_phone_verifications (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id VARCHAR(128) NOT NULL,
+    phone_number VARCHAR(16) NOT NULL,
+    code_hash BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    verified_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_phone_verifications_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_user_phone_verifications_user_created_at ON core_user_phone_verifications (user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_phone_verifications;
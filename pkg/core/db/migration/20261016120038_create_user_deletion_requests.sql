@@ -0,0 +1,29 @@
+-- +goose Up
+-- Deletions of their own account requested by users. The account is erased
+-- once scheduled_for is reached, unless the link sent by email cancelled the
+-- request first; only the hash of the cancel token is kept. The erasure
+-- deletes the rows of the user, a failed one is kept with its error for the
+-- operators.
+CREATE TABLE core_user_deletion_requests (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    token_hash BYTEA NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'scheduled',
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    cancelled_at TIMESTAMPTZ NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_deletion_requests_pk PRIMARY KEY (id),
+    CONSTRAINT user_deletion_requests_status_check CHECK (status IN ('scheduled', 'running', 'cancelled', 'failed'))
+);
+
+CREATE UNIQUE INDEX idx_user_deletion_requests_token_hash ON core_user_deletion_requests (token_hash);
+-- A user has at most one deletion in progress
+CREATE UNIQUE INDEX idx_user_deletion_requests_user_scheduled ON core_user_deletion_requests (user_id)
+    WHERE status IN ('scheduled', 'running');
+CREATE INDEX idx_user_deletion_requests_due ON core_user_deletion_requests (scheduled_for)
+    WHERE status = 'scheduled';
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_deletion_requests;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- The state of the identity of each user at the auth provider, disabled and
+-- email verified, so the user lists can filter on it. The provider stays the
+-- source of truth: a row is written each time the application changes the
+-- state, and a login marks the account enabled.
+CREATE TABLE core_user_account_states (
+    user_id VARCHAR NOT NULL REFERENCES core_users (id) ON DELETE CASCADE,
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    email_verified BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_account_states_pk PRIMARY KEY (user_id)
+);
+
+-- The addresses verified through the links of the application so far, the
+-- next login records the others
+INSERT INTO core_user_account_states (user_id, email_verified)
+SELECT DISTINCT t.user_id, true
+FROM core_email_verification_tokens t
+WHERE t.used_at IS NOT NULL;
+
+CREATE INDEX idx_users_created_at ON core_users (created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP TABLE IF EXISTS core_user_account_states;
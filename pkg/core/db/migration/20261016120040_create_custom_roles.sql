@@ -0,0 +1,38 @@
+-- +goose Up
+-- Roles defined by a tenant on top of the built-in ones. A custom role grants
+-- the operations of its permissions to its members. The legacy rows of
+-- core_roles have no tenant and are ignored.
+ALTER TABLE core_roles ADD COLUMN tenant_id VARCHAR(64) NULL;
+ALTER TABLE core_roles ADD COLUMN description TEXT NULL;
+ALTER TABLE core_roles ADD CONSTRAINT fk_roles_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE core_roles DROP CONSTRAINT IF EXISTS core_roles_name_key;
+
+CREATE UNIQUE INDEX idx_roles_tenant_name ON core_roles (tenant_id, lower(name)) WHERE tenant_id IS NOT NULL;
+
+CREATE TABLE core_role_permissions (
+    role_id uuid NOT NULL,
+    operation VARCHAR(128) NOT NULL,
+    CONSTRAINT role_permissions_pk PRIMARY KEY (role_id, operation),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES core_roles(id) ON DELETE CASCADE
+);
+
+CREATE TABLE core_role_members (
+    role_id uuid NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    assigned_by VARCHAR(128) NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT role_members_pk PRIMARY KEY (role_id, user_id),
+    CONSTRAINT fk_role_members_role FOREIGN KEY (role_id) REFERENCES core_roles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_role_members_user_id ON core_role_members (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_role_members;
+DROP TABLE IF EXISTS core_role_permissions;
+DELETE FROM core_roles WHERE tenant_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_roles_tenant_name;
+ALTER TABLE core_roles DROP CONSTRAINT IF EXISTS fk_roles_tenant;
+ALTER TABLE core_roles DROP COLUMN IF EXISTS description;
+ALTER TABLE core_roles DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE core_roles ADD CONSTRAINT core_roles_name_key UNIQUE (name);
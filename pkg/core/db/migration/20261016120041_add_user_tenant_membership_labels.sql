@@ -0,0 +1,10 @@
+-- +goose Up
+-- Free-form labels an admin puts on the users of a tenant to segment them,
+-- e.g. contractor or pilot-group. They grant nothing.
+ALTER TABLE core_user_tenant_memberships ADD COLUMN labels TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_user_tenant_memberships_labels ON core_user_tenant_memberships USING GIN (labels);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_tenant_memberships_labels;
+ALTER TABLE core_user_tenant_memberships DROP COLUMN IF EXISTS labels;
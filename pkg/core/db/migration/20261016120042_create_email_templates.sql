@@ -0,0 +1,18 @@
+-- +goose Up
+-- Subject and body a tenant sets for an email the library sends, per event
+-- and locale. Without one the library template is used.
+CREATE TABLE core_email_templates (
+    tenant_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    locale VARCHAR(16) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    updated_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT email_templates_pk PRIMARY KEY (tenant_id, event_type, locale),
+    CONSTRAINT fk_email_templates_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_email_templates;
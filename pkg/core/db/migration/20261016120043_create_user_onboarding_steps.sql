@@ -0,0 +1,14 @@
+-- +goose Up
+-- The onboarding steps each user completed, such as first_login. The steps the
+-- application can see, the verified email, the first login and the MFA
+-- enrollment, are recorded when the checklist is read; the others are marked
+-- by the frontend.
+CREATE TABLE core_user_onboarding_steps (
+    user_id VARCHAR NOT NULL REFERENCES core_users (id) ON DELETE CASCADE,
+    step VARCHAR(64) NOT NULL,
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_onboarding_steps_pk PRIMARY KEY (user_id, step)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_user_onboarding_steps;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Lifecycle of a tenant: active, suspended (its requests are refused, e.g.
+-- for non-payment) or archived (offboarded, hidden from the listings). It is
+-- independent of is_disabled, set when the contract ends.
+ALTER TABLE core_tenants
+  ADD COLUMN status            VARCHAR(16) NOT NULL DEFAULT 'active',
+  ADD COLUMN status_reason     TEXT NULL,
+  ADD COLUMN status_changed_at TIMESTAMPTZ NULL,
+  ADD CONSTRAINT tenants_status_check CHECK (status IN ('active', 'suspended', 'archived'));
+
+CREATE INDEX idx_tenants_status ON core_tenants (status) WHERE status <> 'active';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tenants_status;
+ALTER TABLE core_tenants
+  DROP CONSTRAINT tenants_status_check,
+  DROP COLUMN status_changed_at,
+  DROP COLUMN status_reason,
+  DROP COLUMN status;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Resource limits of a tenant, set by the super admins. A NULL column does
+-- not limit the resource.
+CREATE TABLE core_tenant_quotas (
+    tenant_id VARCHAR(64) NOT NULL,
+    max_users INT NULL CHECK (max_users >= 0),
+    max_client_applications INT NULL CHECK (max_client_applications >= 0),
+    max_storage_mb INT NULL CHECK (max_storage_mb >= 0),
+    max_prompt_executions_per_month INT NULL CHECK (max_prompt_executions_per_month >= 0),
+    updated_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT tenant_quotas_pk PRIMARY KEY (tenant_id)
+);
+
+-- Size of the files stored under the prefix of a tenant, recorded by the
+-- FileService as they are written and deleted
+CREATE TABLE core_tenant_files (
+    tenant_id VARCHAR(64) NOT NULL,
+    path TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT tenant_files_pk PRIMARY KEY (tenant_id, path)
+);
+
+-- Prompt executions of a tenant per calendar month, UTC
+CREATE TABLE core_tenant_prompt_executions (
+    tenant_id VARCHAR(64) NOT NULL,
+    month DATE NOT NULL,
+    executions INT NOT NULL DEFAULT 0,
+    CONSTRAINT tenant_prompt_executions_pk PRIMARY KEY (tenant_id, month)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_prompt_executions;
+DROP TABLE IF EXISTS core_tenant_files;
+DROP TABLE IF EXISTS core_tenant_quotas;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Metered usage per tenant, metric and day (UTC), for the tenant usage
+-- reports: API calls, LLM tokens consumed, users added, API tokens created.
+CREATE TABLE core_tenant_usage_counts (
+    tenant_id VARCHAR(64) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    metric VARCHAR(32) NOT NULL,
+    count BIGINT NOT NULL,
+    CONSTRAINT tenant_usage_counts_pk PRIMARY KEY (tenant_id, bucket, metric)
+);
+
+CREATE INDEX tenant_usage_counts_bucket_idx ON core_tenant_usage_counts (bucket);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_usage_counts;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Typed settings of a tenant, one JSON value per key. The keys and the JSON
+-- schemas of their values are registered in the code; a missing key takes its
+-- default.
+CREATE TABLE core_tenant_setting_values (
+    tenant_id VARCHAR(64) NOT NULL,
+    key VARCHAR(64) NOT NULL,
+    value JSONB NOT NULL,
+    updated_by VARCHAR(128) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT tenant_setting_values_pk PRIMARY KEY (tenant_id, key)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_setting_values;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- A tenant waiting for its purge is pending_deletion: it answers as if it did
+-- not exist, until the purge deletes it or is cancelled.
+ALTER TABLE core_tenants
+  DROP CONSTRAINT tenants_status_check,
+  ADD CONSTRAINT tenants_status_check CHECK (status IN ('active', 'suspended', 'archived', 'pending_deletion'));
+
+-- Purges of tenants. The purge runs once purge_after is reached, step by step,
+-- the report of each step being saved as it ends so a purge interrupted or
+-- failed resumes after its last completed step. The row has no foreign key to
+-- the tenant: it is kept as the report of the deletion.
+CREATE TABLE core_tenant_purges (
+    tenant_uuid uuid NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    tenant_name VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'scheduled',
+    previous_status VARCHAR(16) NOT NULL,
+    reason TEXT NULL,
+    requested_by VARCHAR(128) NOT NULL,
+    purge_after TIMESTAMPTZ NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    steps JSONB NOT NULL DEFAULT '[]',
+    last_error TEXT NULL,
+    started_at TIMESTAMPTZ NULL,
+    completed_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tenant_purges_pk PRIMARY KEY (tenant_uuid),
+    CONSTRAINT tenant_purges_status_check CHECK (status IN ('scheduled', 'running', 'completed', 'failed', 'cancelled'))
+);
+
+CREATE INDEX idx_tenant_purges_due ON core_tenant_purges (purge_after)
+    WHERE status IN ('scheduled', 'running', 'failed');
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_purges;
+UPDATE core_tenants SET status = 'archived' WHERE status = 'pending_deletion';
+ALTER TABLE core_tenants
+  DROP CONSTRAINT tenants_status_check,
+  ADD CONSTRAINT tenants_status_check CHECK (status IN ('active', 'suspended', 'archived'));
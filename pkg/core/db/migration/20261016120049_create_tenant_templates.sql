@@ -0,0 +1,17 @@
+-- +goose Up
+-- Named templates new tenants are provisioned from. A template is a tenant
+-- kept as a model: provisioning copies its settings, roles, email templates,
+-- branding and module data into the new tenant.
+CREATE TABLE core_tenant_templates (
+    name VARCHAR(64) NOT NULL,
+    description TEXT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tenant_templates_pk PRIMARY KEY (name),
+    CONSTRAINT fk_tenant_templates_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_templates;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Feature flags. A flag is on for the share of the tenants given by its
+-- rollout percentage, each tenant falling in a stable bucket of the flag; a
+-- disabled flag is off everywhere.
+CREATE TABLE core_feature_flags (
+    key VARCHAR(64) NOT NULL,
+    description TEXT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    rollout_percentage INT NOT NULL DEFAULT 0,
+    updated_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT feature_flags_pk PRIMARY KEY (key),
+    CONSTRAINT feature_flags_rollout_check CHECK (rollout_percentage BETWEEN 0 AND 100)
+);
+
+-- Per-tenant overrides, turning an enabled flag on or off for the tenant
+-- whatever its rollout
+CREATE TABLE core_tenant_feature_flags (
+    tenant_id VARCHAR(64) NOT NULL,
+    flag_key VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(128) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tenant_feature_flags_pk PRIMARY KEY (tenant_id, flag_key),
+    CONSTRAINT fk_tenant_feature_flags_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    CONSTRAINT fk_tenant_feature_flags_flag FOREIGN KEY (flag_key) REFERENCES core_feature_flags(key) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_feature_flags;
+DROP TABLE IF EXISTS core_feature_flags;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Email provider of a tenant, replacing the platform SMTP server for the
+-- emails of the tenant. The password or API key is encrypted by the
+-- application with TENANT_EMAIL_PROVIDER_ENCRYPTION_KEYS.
+CREATE TABLE core_tenant_email_providers (
+    tenant_id VARCHAR(64) NOT NULL,
+    provider VARCHAR(16) NOT NULL,
+    host VARCHAR(255) NULL,
+    port INT NULL,
+    username VARCHAR(255) NULL,
+    encrypted_secret TEXT NOT NULL,
+    from_address VARCHAR(255) NOT NULL,
+    reply_to VARCHAR(255) NULL,
+    updated_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tenant_email_providers_pk PRIMARY KEY (tenant_id),
+    CONSTRAINT fk_tenant_email_providers_tenant FOREIGN KEY (tenant_id) REFERENCES core_tenants(tenant_id) ON DELETE CASCADE,
+    CONSTRAINT tenant_email_providers_provider_check CHECK (provider IN ('smtp', 'sendgrid'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_email_providers;
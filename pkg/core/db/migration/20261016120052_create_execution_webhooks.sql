@@ -0,0 +1,21 @@
+-- +goose Up
+-- Result webhooks of the prompts and schedules run by the modules: when an
+-- execution of the prompt, or one started by the schedule, completes, its
+-- result is POSTed to the URL. As for the client application webhooks, the
+-- secret is derived from the server master key and the webhook id.
+CREATE TABLE core_execution_webhooks (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(64) NOT NULL,
+    -- prompt or schedule, identified by the module running it
+    source_type VARCHAR(16) NOT NULL,
+    source_id VARCHAR(128) NOT NULL,
+    url TEXT NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT execution_webhooks_pk PRIMARY KEY (id),
+    CONSTRAINT unique_execution_webhook UNIQUE (tenant_id, source_type, source_id),
+    CONSTRAINT execution_webhook_source_type CHECK (source_type IN ('prompt', 'schedule'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_execution_webhooks;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Request budgets and client IP allowlist of the access tokens issued with a
+-- client secret. NULL means no limit for that window, an empty list allows
+-- any address.
+ALTER TABLE core_client_application_secrets
+    ADD COLUMN rate_limit_per_minute INTEGER NULL,
+    ADD COLUMN rate_limit_per_hour INTEGER NULL,
+    ADD COLUMN allowed_cidrs TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE core_client_application_secrets
+    DROP COLUMN IF EXISTS allowed_cidrs,
+    DROP COLUMN IF EXISTS rate_limit_per_hour,
+    DROP COLUMN IF EXISTS rate_limit_per_minute;
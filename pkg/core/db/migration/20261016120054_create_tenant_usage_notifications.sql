@@ -0,0 +1,13 @@
+-- +goose Up
+-- Usage recommendations notified to a tenant, one per resource and severity,
+-- so a limit being approached is notified again only after a while.
+CREATE TABLE core_tenant_usage_notifications (
+    tenant_id VARCHAR(64) NOT NULL,
+    resource VARCHAR(64) NOT NULL,
+    severity VARCHAR(32) NOT NULL,
+    notified_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT tenant_usage_notifications_pk PRIMARY KEY (tenant_id, resource, severity)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS core_tenant_usage_notifications;
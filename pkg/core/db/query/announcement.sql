@@ -0,0 +1,48 @@
+-- name: CreateAnnouncement :one
+INSERT INTO core_announcements (
+  tenant_id, title, message, severity, starts_at, ends_at, created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetAnnouncementByID :one
+SELECT * FROM core_announcements
+WHERE id = $1 AND tenant_id = $2
+LIMIT 1;
+
+-- name: ListAnnouncements :many
+-- Lists the announcements of one scope, scheduled ones included. Expired
+-- announcements are only returned when include_expired is set.
+SELECT * FROM core_announcements
+WHERE tenant_id = $1
+  AND (sqlc.arg(include_expired)::boolean OR ends_at > clock_timestamp())
+ORDER BY starts_at DESC, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListActiveAnnouncements :many
+-- Returns the announcements currently visible on a tenant, global ones
+-- included, most severe first
+SELECT * FROM core_announcements
+WHERE tenant_id IN ('', sqlc.arg(tenant_id)::text)
+  AND starts_at <= clock_timestamp()
+  AND ends_at > clock_timestamp()
+ORDER BY
+  CASE severity WHEN 'critical' THEN 0 WHEN 'warning' THEN 1 ELSE 2 END,
+  starts_at DESC;
+
+-- name: UpdateAnnouncement :one
+UPDATE core_announcements
+SET
+  title = $3,
+  message = $4,
+  severity = $5,
+  starts_at = $6,
+  ends_at = $7
+WHERE id = $1 AND tenant_id = $2
+RETURNING *;
+
+-- name: DeleteAnnouncement :execrows
+DELETE FROM core_announcements
+WHERE id = $1 AND tenant_id = $2;
@@ -1,14 +1,16 @@
 -- name: CreateAPIToken :one
 INSERT INTO core_api_tokens (
   client_application_id, name, description, token_hash, token_prefix, 
-  expires_at, created_by, scopes
+  expires_at, created_by, scopes, hash_version, scope_template_id,
+  rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+  COALESCE(sqlc.arg(allowed_cidrs)::text[], '{}')
 )
 RETURNING *;
 
 -- name: GetAPITokenByID :one
-SELECT t.*, c.tenant_id 
+SELECT t.*, c.tenant_id, c.service_account_id
 FROM core_api_tokens t
 JOIN core_client_applications c ON t.client_application_id = c.id
 WHERE t.id = $1
@@ -19,14 +21,26 @@ WHERE t.id = $1
   AND t.revoked = false
 LIMIT 1;
 
--- name: GetAPITokenByHash :one
+-- name: GetAPITokensByPrefix :many
+-- Returns the usable tokens sharing a display prefix. The caller verifies the
+-- token against each hash, since salted hashes cannot be looked up directly.
+-- The tokens of a deactivated application are unusable until it is activated.
 SELECT t.*, c.tenant_id 
 FROM core_api_tokens t
 JOIN core_client_applications c ON t.client_application_id = c.id
-WHERE t.token_hash = $1 
+WHERE t.token_prefix = $1 
   AND t.revoked = false
   AND t.expires_at > NOW()
-LIMIT 1;
+  AND c.active = true;
+
+-- name: GetUnusableAPITokensByPrefix :many
+-- Returns the revoked, expired or deactivated tokens sharing a display prefix,
+-- to tell why a token was refused
+SELECT t.id, t.token_hash, t.hash_version, t.revoked, t.expires_at, c.active, c.tenant_id
+FROM core_api_tokens t
+JOIN core_client_applications c ON t.client_application_id = c.id
+WHERE t.token_prefix = $1
+  AND (t.revoked = true OR t.expires_at <= NOW() OR c.active = false);
 
 -- name: ListAPITokens :many
 SELECT t.*, c.name as application_name 
@@ -58,6 +72,35 @@ ORDER BY
 LIMIT $1
 OFFSET $2;
 
+-- name: CountAPITokens :one
+-- Counts the tokens matched by the filters of ListAPITokens
+SELECT COUNT(*)
+FROM core_api_tokens t
+JOIN core_client_applications c ON t.client_application_id = c.id
+WHERE (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (c.tenant_id IS NULL OR c.tenant_id = ''))
+    OR c.tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+  AND (t.client_application_id = sqlc.narg('client_application_id')::uuid OR sqlc.narg('client_application_id') IS NULL)
+  AND (
+    sqlc.narg('include_revoked')::boolean OR t.revoked = false
+  )
+  AND (
+    sqlc.narg('include_expired')::boolean OR t.expires_at > NOW()
+  )
+  AND (
+    UPPER(t.name) LIKE UPPER(sqlc.narg('like')) 
+    OR UPPER(c.name) LIKE UPPER(sqlc.narg('like'))
+    OR sqlc.narg('like') IS NULL
+  );
+
+-- name: CountActiveAPITokens :one
+-- Counts the tokens of the application neither revoked nor expired
+SELECT COUNT(*) FROM core_api_tokens
+WHERE client_application_id = $1
+  AND revoked = false
+  AND expires_at > NOW();
+
 -- name: UpdateAPIToken :one
 UPDATE core_api_tokens
 SET 
@@ -78,6 +121,26 @@ SET
 WHERE id = $1
 RETURNING *;
 
+-- name: RevokeActiveAPITokens :many
+-- Revokes the active tokens of every application in the tenant scope, or of a
+-- single application when client_application_id is set
+UPDATE core_api_tokens t
+SET
+  revoked = true,
+  revoked_at = NOW(),
+  revoked_reason = sqlc.arg('revoked_reason'),
+  revoked_by = sqlc.arg('revoked_by')
+FROM core_client_applications c
+WHERE t.client_application_id = c.id
+  AND (sqlc.narg('client_application_id')::uuid IS NULL OR t.client_application_id = sqlc.narg('client_application_id')::uuid)
+  AND (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (c.tenant_id IS NULL OR c.tenant_id = ''))
+    OR c.tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+  AND t.revoked = false
+  AND t.expires_at > NOW()
+RETURNING t.id, t.client_application_id;
+
 -- name: DeleteAPIToken :one
 DELETE FROM core_api_tokens
 WHERE id = $1
@@ -90,6 +153,20 @@ SET
   last_used_ip = sqlc.narg('ip_address')::varchar
 WHERE id = $1;
 
+-- name: UpdateAPITokensLastUsed :exec
+-- Applies a batch of last-used updates, never moving last_used_at backwards
+UPDATE core_api_tokens t
+SET
+  last_used_at = u.used_at,
+  last_used_ip = u.ip_address
+FROM unnest(
+  sqlc.arg('ids')::uuid[],
+  sqlc.arg('used_at')::timestamptz[],
+  sqlc.arg('ip_addresses')::varchar[]
+) AS u(id, used_at, ip_address)
+WHERE t.id = u.id
+  AND (t.last_used_at IS NULL OR t.last_used_at < u.used_at);
+
 -- name: CreateAPITokenAuditLog :one
 INSERT INTO core_api_token_audit_logs (
   token_id, action, ip_address, user_agent, additional_data
@@ -98,9 +175,190 @@ INSERT INTO core_api_token_audit_logs (
 )
 RETURNING *;
 
+-- name: CreateAPITokenAuditLogs :execrows
+-- Inserts a batch of audit entries, one per array index
+INSERT INTO core_api_token_audit_logs (
+  token_id, action, ip_address, user_agent, additional_data, timestamp
+)
+SELECT u.token_id, u.action, u.ip_address, u.user_agent, NULLIF(u.additional_data, '')::jsonb, u.timestamp
+FROM unnest(
+  sqlc.arg('token_ids')::uuid[],
+  sqlc.arg('actions')::varchar[],
+  sqlc.arg('ip_addresses')::varchar[],
+  sqlc.arg('user_agents')::text[],
+  sqlc.arg('additional_data')::text[],
+  sqlc.arg('timestamps')::timestamptz[]
+) AS u(token_id, action, ip_address, user_agent, additional_data, timestamp);
+
 -- name: GetAPITokenAuditLogs :many
+-- A NULL action, from or to does not filter the entries
 SELECT * FROM core_api_token_audit_logs
 WHERE token_id = $1
+  AND (sqlc.narg('action')::varchar IS NULL OR action = sqlc.narg('action')::varchar)
+  AND (sqlc.narg('from')::timestamptz IS NULL OR timestamp >= sqlc.narg('from')::timestamptz)
+  AND (sqlc.narg('to')::timestamptz IS NULL OR timestamp < sqlc.narg('to')::timestamptz)
 ORDER BY timestamp DESC
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListAPITokenAuditLogsAfter :many
+-- Keyset page of the audit logs of a token, oldest first, so an export is not
+-- shifted by the entries written meanwhile
+SELECT * FROM core_api_token_audit_logs
+WHERE token_id = sqlc.arg('token_id')
+  AND (timestamp, id) > (sqlc.arg('after_timestamp')::timestamptz, sqlc.arg('after_id')::uuid)
+ORDER BY timestamp, id
+LIMIT sqlc.arg('batch_size');
+
+-- name: UpdateAPITokenRateLimits :exec
+UPDATE core_api_tokens
+SET
+  rate_limit_per_minute = sqlc.narg('rate_limit_per_minute'),
+  rate_limit_per_hour = sqlc.narg('rate_limit_per_hour')
+WHERE id = $1;
+
+-- name: GetAPITokenUsageSummary :one
+SELECT
+  COUNT(*) AS total_requests,
+  COUNT(DISTINCT ip_address) AS unique_ips
+FROM core_api_token_audit_logs
+WHERE token_id = sqlc.arg(token_id)
+  AND action = 'USED'
+  AND timestamp >= sqlc.arg(from_time)
+  AND timestamp < sqlc.arg(to_time);
+
+-- name: GetAPITokenUsageByDay :many
+SELECT
+  date_trunc('day', timestamp, 'UTC')::timestamptz AS day,
+  COUNT(*) AS requests,
+  COUNT(DISTINCT ip_address) AS unique_ips
+FROM core_api_token_audit_logs
+WHERE token_id = sqlc.arg(token_id)
+  AND action = 'USED'
+  AND timestamp >= sqlc.arg(from_time)
+  AND timestamp < sqlc.arg(to_time)
+GROUP BY day
+ORDER BY day;
+
+-- name: GetAPITokenTopEndpoints :many
+SELECT
+  COALESCE(additional_data->>'method', '')::text AS method,
+  (additional_data->>'path')::text AS path,
+  COUNT(*) AS requests
+FROM core_api_token_audit_logs
+WHERE token_id = sqlc.arg(token_id)
+  AND action = 'USED'
+  AND timestamp >= sqlc.arg(from_time)
+  AND timestamp < sqlc.arg(to_time)
+  AND additional_data->>'path' IS NOT NULL
+GROUP BY 1, 2
+ORDER BY requests DESC, path
+LIMIT sqlc.arg(max_endpoints);
+
+-- name: UpdateAPITokenAllowedCIDRs :one
+UPDATE core_api_tokens
+SET allowed_cidrs = sqlc.arg('allowed_cidrs')::text[]
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAPITokenHash :exec
+-- Replaces a token hash computed with an older algorithm
+UPDATE core_api_tokens
+SET
+  token_hash = $2,
+  hash_version = $3
+WHERE id = $1 AND hash_version = sqlc.arg('previous_version');
+
+-- name: ListAPITokensExpiringWithoutWarning :many
+SELECT t.id, t.name, t.token_prefix, t.expires_at, t.created_by, t.client_application_id,
+  a.name AS client_application_name, a.tenant_id, u.email AS creator_email
+FROM core_api_tokens t
+JOIN core_client_applications a ON a.id = t.client_application_id
+LEFT JOIN core_users u ON u.id = t.created_by
+WHERE t.revoked = false
+  AND t.expires_at > clock_timestamp()
+  AND t.expires_at <= sqlc.arg(expiring_before)
+  AND NOT EXISTS (
+    SELECT 1 FROM core_api_token_audit_logs l
+    WHERE l.token_id = t.id AND l.action = 'EXPIRY_WARNING'
+  )
+ORDER BY t.expires_at
+LIMIT sqlc.arg(batch_size);
+
+-- name: CreateAPITokenExpiryWarning :one
+-- Only inserts when the token has no warning yet, so concurrent scanners
+-- notify each token once
+INSERT INTO core_api_token_audit_logs (token_id, action, additional_data)
+SELECT sqlc.arg(token_id), 'EXPIRY_WARNING', sqlc.arg(additional_data)
+WHERE NOT EXISTS (
+  SELECT 1 FROM core_api_token_audit_logs
+  WHERE token_id = sqlc.arg(token_id) AND action = 'EXPIRY_WARNING'
+)
+RETURNING id;
+
+-- name: ListExpiredAPITokensWithoutNotice :many
+-- Lists the tokens that expired after the webhook of their application was
+-- configured and were not notified yet
+SELECT t.id, t.name, t.token_prefix, t.expires_at, t.client_application_id
+FROM core_api_tokens t
+JOIN core_client_application_webhooks w ON w.client_application_id = t.client_application_id
+WHERE t.revoked = false
+  AND t.expires_at <= clock_timestamp()
+  AND t.expires_at > w.created_at
+  AND NOT EXISTS (
+    SELECT 1 FROM core_api_token_audit_logs l
+    WHERE l.token_id = t.id AND l.action = 'EXPIRED'
+  )
+ORDER BY t.expires_at
+LIMIT sqlc.arg(batch_size);
+
+-- name: CreateAPITokenExpiredNotice :one
+-- Only inserts when the token has no notice yet, so concurrent scanners
+-- notify each token once
+INSERT INTO core_api_token_audit_logs (token_id, action, additional_data)
+SELECT sqlc.arg(token_id), 'EXPIRED', sqlc.arg(additional_data)
+WHERE NOT EXISTS (
+  SELECT 1 FROM core_api_token_audit_logs
+  WHERE token_id = sqlc.arg(token_id) AND action = 'EXPIRED'
+)
+RETURNING id;
+
+-- name: ListAPITokensCreatedBy :many
+-- Lists the unrevoked tokens owned by the user with the tenant of their application
+SELECT t.id, t.name, COALESCE(a.tenant_id, '')::varchar AS tenant_id
+FROM core_api_tokens t
+JOIN core_client_applications a ON a.id = t.client_application_id
+WHERE t.created_by = $1
+  AND t.revoked = false
+ORDER BY t.created_at;
+
+-- name: TransferAPITokensOwnership :execrows
+UPDATE core_api_tokens t
+SET created_by = sqlc.arg('to_user_id')
+FROM core_client_applications a
+WHERE a.id = t.client_application_id
+  AND t.created_by = sqlc.arg('from_user_id')
+  AND t.revoked = false
+  AND COALESCE(a.tenant_id, '') = sqlc.arg('tenant_id')::varchar;
+
+-- name: ApplyScopeTemplateToAPITokens :many
+-- Copies the template scopes to its active tokens whose scopes differ,
+-- returning the scopes each token had before
+WITH targets AS (
+  SELECT id, scopes FROM core_api_tokens
+  WHERE scope_template_id = $1
+    AND revoked = false
+    AND expires_at > NOW()
+    AND scopes IS DISTINCT FROM sqlc.arg('scopes')::text[]
+  FOR UPDATE
+)
+UPDATE core_api_tokens t
+SET scopes = sqlc.arg('scopes')::text[], updated_at = NOW()
+FROM targets
+WHERE t.id = targets.id
+RETURNING t.id, targets.scopes AS previous_scopes;
+
+-- name: DetachAPITokenFromScopeTemplate :exec
+UPDATE core_api_tokens
+SET scope_template_id = NULL
+WHERE id = $1;
@@ -0,0 +1,24 @@
+-- name: IncrementAuthFailureCounts :exec
+-- Adds a batch of counts, one per array index, to their hourly buckets. The
+-- batch must not repeat a tenant, bucket and reason.
+INSERT INTO core_auth_failure_counts (tenant_id, bucket, reason, count)
+SELECT u.tenant_id, u.bucket, u.reason, u.count
+FROM unnest(
+  sqlc.arg('tenant_ids')::varchar[],
+  sqlc.arg('buckets')::timestamptz[],
+  sqlc.arg('reasons')::varchar[],
+  sqlc.arg('counts')::bigint[]
+) AS u(tenant_id, bucket, reason, count)
+ON CONFLICT (tenant_id, bucket, reason) DO UPDATE SET
+  count = core_auth_failure_counts.count + EXCLUDED.count;
+
+-- name: ListAuthFailureCounts :many
+SELECT * FROM core_auth_failure_counts
+WHERE tenant_id = $1
+  AND bucket >= sqlc.arg('from')::timestamptz
+  AND bucket < sqlc.arg('to')::timestamptz
+ORDER BY bucket, reason;
+
+-- name: DeleteAuthFailureCountsBefore :execrows
+DELETE FROM core_auth_failure_counts
+WHERE bucket < $1;
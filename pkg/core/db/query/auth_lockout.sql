@@ -0,0 +1,51 @@
+-- name: RecordAuthLockoutFailure :one
+-- Counts a failure of the subject, restarting the count when its window
+-- started before window_start
+INSERT INTO core_auth_lockouts (subject_type, subject, tenant_id, failures, last_reason)
+VALUES (sqlc.arg(subject_type), sqlc.arg(subject), sqlc.arg(tenant_id), 1, sqlc.arg(reason))
+ON CONFLICT (subject_type, subject) DO UPDATE SET
+  failures = CASE WHEN core_auth_lockouts.window_started_at < sqlc.arg(window_start)::timestamptz THEN 1 ELSE core_auth_lockouts.failures + 1 END,
+  window_started_at = CASE WHEN core_auth_lockouts.window_started_at < sqlc.arg(window_start)::timestamptz THEN now() ELSE core_auth_lockouts.window_started_at END,
+  tenant_id = EXCLUDED.tenant_id,
+  last_reason = EXCLUDED.last_reason,
+  updated_at = now()
+RETURNING *;
+
+-- name: LockAuthSubject :one
+-- Locks the subject and restarts its count, so the next lock takes as many
+-- failures
+UPDATE core_auth_lockouts
+SET locked_until = sqlc.arg(locked_until)::timestamptz,
+  disabled = disabled OR sqlc.arg(disabled)::boolean,
+  failures = 0,
+  window_started_at = now(),
+  updated_at = now()
+WHERE subject_type = sqlc.arg(subject_type) AND subject = sqlc.arg(subject)
+RETURNING *;
+
+-- name: GetAuthLockout :one
+SELECT * FROM core_auth_lockouts
+WHERE subject_type = $1 AND subject = $2;
+
+-- name: ListAuthLockouts :many
+-- Returns the locked subjects, the most recent first
+SELECT * FROM core_auth_lockouts
+WHERE (locked_until > now() OR disabled)
+  AND (sqlc.narg(subject_type)::varchar IS NULL OR subject_type = sqlc.narg(subject_type)::varchar)
+  AND (sqlc.narg(tenant_id)::varchar IS NULL OR tenant_id = sqlc.narg(tenant_id)::varchar)
+ORDER BY updated_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: DeleteAuthLockout :one
+-- Unlocks the subject and forgets its failures
+DELETE FROM core_auth_lockouts
+WHERE subject_type = $1 AND subject = $2
+RETURNING *;
+
+-- name: DeleteStaleAuthLockouts :execrows
+-- Forgets the subjects neither locked nor disabled without a failure since
+-- before
+DELETE FROM core_auth_lockouts
+WHERE updated_at < $1
+  AND (locked_until IS NULL OR locked_until < now())
+  AND NOT disabled;
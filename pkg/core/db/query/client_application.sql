@@ -31,6 +31,17 @@ ORDER BY
 LIMIT $1
 OFFSET $2;
 
+-- name: CountClientApplications :one
+-- Counts the applications matched by the filters of ListClientApplications
+SELECT COUNT(*)
+FROM core_client_applications
+WHERE (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+  AND (sqlc.narg('include_inactive')::boolean OR active = true)
+  AND (UPPER(name) LIKE UPPER(sqlc.narg('like')) OR sqlc.narg('like') IS NULL);
+
 -- name: UpdateClientApplication :one
 UPDATE core_client_applications
 SET
@@ -43,6 +54,15 @@ WHERE id = $1 AND (
   )
 RETURNING *;
 
+-- name: ActivateClientApplication :one
+UPDATE core_client_applications
+SET active = true
+WHERE id = $1 AND (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+RETURNING id;
+
 -- name: DeactivateClientApplication :one
 UPDATE core_client_applications
 SET active = false
@@ -63,4 +83,52 @@ RETURNING id;
 -- name: UpdateClientApplicationLastUsed :exec
 UPDATE core_client_applications
 SET last_used_at = NOW()
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateClientApplicationsLastUsed :exec
+-- Applies a batch of last-used updates, never moving last_used_at backwards
+UPDATE core_client_applications a
+SET last_used_at = u.used_at
+FROM unnest(
+  sqlc.arg('ids')::uuid[],
+  sqlc.arg('used_at')::timestamptz[]
+) AS u(id, used_at)
+WHERE a.id = u.id
+  AND (a.last_used_at IS NULL OR a.last_used_at < u.used_at);
+
+-- name: ListClientApplicationsCreatedBy :many
+-- Lists the applications owned by the user, global ones with an empty tenant
+SELECT id, name, COALESCE(tenant_id, '')::varchar AS tenant_id
+FROM core_client_applications
+WHERE created_by = $1
+ORDER BY created_at;
+
+-- name: LockClientApplicationTokenQuota :one
+-- Locks the application while a token is created, so concurrent creations
+-- cannot exceed its quota
+SELECT max_active_tokens FROM core_client_applications
+WHERE id = $1
+FOR UPDATE;
+
+-- name: SetClientApplicationMaxActiveTokens :one
+UPDATE core_client_applications
+SET max_active_tokens = sqlc.narg('max_active_tokens')
+WHERE id = $1
+RETURNING *;
+
+-- name: SetClientApplicationServiceAccount :one
+-- Links the application to its service account identity, or unlinks it with
+-- a NULL service_account_id
+UPDATE core_client_applications
+SET service_account_id = sqlc.narg('service_account_id')
+WHERE id = $1 AND (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+RETURNING *;
+
+-- name: TransferClientApplicationsOwnership :execrows
+UPDATE core_client_applications
+SET created_by = sqlc.arg('to_user_id')
+WHERE created_by = sqlc.arg('from_user_id')
+  AND COALESCE(tenant_id, '') = sqlc.arg('tenant_id')::varchar;
@@ -0,0 +1,35 @@
+-- name: UpsertClientApplicationSecret :one
+-- A new id is assigned on rotation so the JWTs issued with the previous
+-- secret no longer verify
+INSERT INTO core_client_application_secrets (
+  client_application_id, secret_hash, secret_prefix, scopes, created_by,
+  rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs
+) VALUES (
+  $1, $2, $3, sqlc.arg('scopes')::text[], $4,
+  $5, $6, COALESCE(sqlc.arg('allowed_cidrs')::text[], '{}')
+)
+ON CONFLICT (client_application_id) DO UPDATE
+SET id = gen_random_uuid(),
+  secret_hash = EXCLUDED.secret_hash,
+  secret_prefix = EXCLUDED.secret_prefix,
+  scopes = EXCLUDED.scopes,
+  created_by = EXCLUDED.created_by,
+  rate_limit_per_minute = EXCLUDED.rate_limit_per_minute,
+  rate_limit_per_hour = EXCLUDED.rate_limit_per_hour,
+  allowed_cidrs = EXCLUDED.allowed_cidrs,
+  created_at = clock_timestamp()
+RETURNING *;
+
+-- name: GetClientApplicationSecret :one
+SELECT s.id, s.client_application_id, s.secret_hash, s.secret_prefix, s.scopes, s.created_by, s.created_at,
+  s.rate_limit_per_minute, s.rate_limit_per_hour, s.allowed_cidrs,
+  a.tenant_id, a.active, a.created_by AS application_created_by,
+  a.service_account_id
+FROM core_client_application_secrets s
+JOIN core_client_applications a ON a.id = s.client_application_id
+WHERE s.client_application_id = $1
+LIMIT 1;
+
+-- name: DeleteClientApplicationSecret :execrows
+DELETE FROM core_client_application_secrets
+WHERE client_application_id = $1;
@@ -0,0 +1,26 @@
+-- name: UpsertClientApplicationSigningKey :one
+-- A new id is assigned on rotation, which changes the derived secret
+INSERT INTO core_client_application_signing_keys (
+  client_application_id, scopes, created_by
+) VALUES (
+  $1, sqlc.arg('scopes')::text[], $2
+)
+ON CONFLICT (client_application_id) DO UPDATE
+SET id = gen_random_uuid(),
+  scopes = EXCLUDED.scopes,
+  created_by = EXCLUDED.created_by,
+  created_at = clock_timestamp()
+RETURNING *;
+
+-- name: GetClientApplicationSigningKey :one
+SELECT k.id, k.client_application_id, k.scopes, k.created_by, k.created_at,
+  a.tenant_id, a.active, a.created_by AS application_created_by,
+  a.service_account_id
+FROM core_client_application_signing_keys k
+JOIN core_client_applications a ON a.id = k.client_application_id
+WHERE k.client_application_id = $1
+LIMIT 1;
+
+-- name: DeleteClientApplicationSigningKey :execrows
+DELETE FROM core_client_application_signing_keys
+WHERE client_application_id = $1;
@@ -0,0 +1,23 @@
+-- name: UpsertClientApplicationWebhook :one
+-- A new id is assigned on every update, which changes the derived secret
+INSERT INTO core_client_application_webhooks (
+  client_application_id, url, created_by
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (client_application_id) DO UPDATE
+SET id = gen_random_uuid(),
+  url = EXCLUDED.url,
+  created_by = EXCLUDED.created_by,
+  created_at = clock_timestamp()
+RETURNING *;
+
+-- name: GetClientApplicationWebhook :one
+SELECT id, client_application_id, url, created_by, created_at
+FROM core_client_application_webhooks
+WHERE client_application_id = $1
+LIMIT 1;
+
+-- name: DeleteClientApplicationWebhook :execrows
+DELETE FROM core_client_application_webhooks
+WHERE client_application_id = $1;
@@ -0,0 +1,102 @@
+-- name: UpsertDirectoryConnection :one
+INSERT INTO core_directory_connections (
+  tenant_id, provider, enabled, settings, credentials, role_rules, default_roles, deprovision_action, created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (tenant_id) DO UPDATE SET
+  provider = EXCLUDED.provider,
+  enabled = EXCLUDED.enabled,
+  settings = EXCLUDED.settings,
+  credentials = EXCLUDED.credentials,
+  role_rules = EXCLUDED.role_rules,
+  default_roles = EXCLUDED.default_roles,
+  deprovision_action = EXCLUDED.deprovision_action,
+  updated_at = NOW()
+RETURNING *;
+
+-- name: GetDirectoryConnection :one
+SELECT * FROM core_directory_connections
+WHERE tenant_id = $1;
+
+-- name: DeleteDirectoryConnection :execrows
+DELETE FROM core_directory_connections
+WHERE tenant_id = $1;
+
+-- name: ListDueDirectoryConnections :many
+-- Lists the enabled connections not synced since dueBefore, the oldest first
+SELECT tenant_id FROM core_directory_connections
+WHERE enabled
+  AND (last_sync_started_at IS NULL OR last_sync_started_at < sqlc.arg(due_before))
+ORDER BY last_sync_started_at NULLS FIRST
+LIMIT sqlc.arg(batch_size);
+
+-- name: ClaimDirectorySync :one
+-- Marks the sync of the tenant running, unless another one started after
+-- staleBefore, so a tenant is synced by one instance at a time
+UPDATE core_directory_connections
+SET last_sync_status = 'running',
+    last_sync_started_at = NOW(),
+    last_sync_error = NULL
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (last_sync_status IS DISTINCT FROM 'running' OR last_sync_started_at < sqlc.arg(stale_before))
+RETURNING *;
+
+-- name: FinishDirectorySync :exec
+UPDATE core_directory_connections
+SET last_sync_status = sqlc.arg(status),
+    last_sync_finished_at = NOW(),
+    last_sync_error = sqlc.narg(error),
+    last_sync_stats = sqlc.arg(stats)
+WHERE tenant_id = sqlc.arg(tenant_id);
+
+-- name: ListDirectoryUsers :many
+SELECT * FROM core_directory_users
+WHERE tenant_id = $1;
+
+-- name: UpsertDirectoryUser :exec
+INSERT INTO core_directory_users (
+  tenant_id, external_id, user_id, roles
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (tenant_id, external_id) DO UPDATE SET
+  user_id = EXCLUDED.user_id,
+  roles = EXCLUDED.roles,
+  suspended = FALSE,
+  last_seen_at = NOW();
+
+-- name: SuspendDirectoryUser :exec
+UPDATE core_directory_users
+SET suspended = TRUE
+WHERE tenant_id = $1 AND external_id = $2;
+
+-- name: DeleteDirectoryUser :exec
+DELETE FROM core_directory_users
+WHERE tenant_id = $1 AND external_id = $2;
+
+-- name: DeleteTenantDirectoryUsers :exec
+DELETE FROM core_directory_users
+WHERE tenant_id = $1;
+
+-- name: DeleteUserDirectoryLinks :execrows
+-- Forgets the directory accounts of an erased user
+DELETE FROM core_directory_users
+WHERE user_id = $1;
+
+-- name: CreateDirectorySyncConflict :exec
+INSERT INTO core_directory_sync_conflicts (
+  tenant_id, external_id, email, user_id, kind, detail
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+);
+
+-- name: DeleteDirectorySyncConflicts :exec
+DELETE FROM core_directory_sync_conflicts
+WHERE tenant_id = $1;
+
+-- name: ListDirectorySyncConflicts :many
+SELECT * FROM core_directory_sync_conflicts
+WHERE tenant_id = sqlc.arg(tenant_id)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,25 @@
+-- name: ListEmailTemplates :many
+SELECT * FROM core_email_templates
+WHERE tenant_id = $1
+ORDER BY event_type, locale;
+
+-- name: GetEmailTemplate :one
+SELECT * FROM core_email_templates
+WHERE tenant_id = $1 AND event_type = $2 AND locale = $3;
+
+-- name: UpsertEmailTemplate :one
+INSERT INTO core_email_templates (
+  tenant_id, event_type, locale, subject, body, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tenant_id, event_type, locale) DO UPDATE
+SET subject = EXCLUDED.subject,
+    body = EXCLUDED.body,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteEmailTemplate :execrows
+DELETE FROM core_email_templates
+WHERE tenant_id = $1 AND event_type = $2 AND locale = $3;
@@ -0,0 +1,27 @@
+-- name: UpsertExecutionWebhook :one
+-- A new id is assigned on every update, which changes the derived secret
+INSERT INTO core_execution_webhooks (
+  tenant_id, source_type, source_id, url, created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (tenant_id, source_type, source_id) DO UPDATE
+SET id = gen_random_uuid(),
+  url = EXCLUDED.url,
+  created_by = EXCLUDED.created_by,
+  created_at = clock_timestamp()
+RETURNING *;
+
+-- name: GetExecutionWebhook :one
+SELECT * FROM core_execution_webhooks
+WHERE tenant_id = $1 AND source_type = $2 AND source_id = $3
+LIMIT 1;
+
+-- name: ListExecutionWebhooks :many
+SELECT * FROM core_execution_webhooks
+WHERE tenant_id = $1
+ORDER BY source_type, source_id;
+
+-- name: DeleteExecutionWebhook :execrows
+DELETE FROM core_execution_webhooks
+WHERE tenant_id = $1 AND source_type = $2 AND source_id = $3;
@@ -0,0 +1,46 @@
+-- name: ListFeatureFlags :many
+SELECT * FROM core_feature_flags
+ORDER BY key;
+
+-- name: GetFeatureFlag :one
+SELECT * FROM core_feature_flags
+WHERE key = $1;
+
+-- name: UpsertFeatureFlag :one
+INSERT INTO core_feature_flags (
+  key, description, enabled, rollout_percentage, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (key) DO UPDATE
+SET description = EXCLUDED.description,
+    enabled = EXCLUDED.enabled,
+    rollout_percentage = EXCLUDED.rollout_percentage,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteFeatureFlag :execrows
+DELETE FROM core_feature_flags
+WHERE key = $1;
+
+-- name: ListTenantFeatureFlagOverrides :many
+SELECT * FROM core_tenant_feature_flags
+WHERE tenant_id = $1
+ORDER BY flag_key;
+
+-- name: UpsertTenantFeatureFlagOverride :one
+INSERT INTO core_tenant_feature_flags (
+  tenant_id, flag_key, enabled, updated_by
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (tenant_id, flag_key) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteTenantFeatureFlagOverride :execrows
+DELETE FROM core_tenant_feature_flags
+WHERE tenant_id = $1 AND flag_key = $2;
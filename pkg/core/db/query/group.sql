@@ -0,0 +1,65 @@
+-- name: CreateGroup :one
+INSERT INTO core_groups (
+  tenant_id, name, description, roles, created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetGroupByID :one
+SELECT * FROM core_groups
+WHERE id = $1 AND tenant_id = $2
+LIMIT 1;
+
+-- name: ListGroups :many
+SELECT * FROM core_groups
+WHERE tenant_id = $1
+ORDER BY lower(name);
+
+-- name: UpdateGroup :one
+UPDATE core_groups
+SET name = $3, description = $4, roles = $5, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2
+RETURNING *;
+
+-- name: DeleteGroup :execrows
+DELETE FROM core_groups
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: AddGroupMember :execrows
+INSERT INTO core_group_members (
+  group_id, user_id, added_by
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (group_id, user_id) DO NOTHING;
+
+-- name: RemoveGroupMember :execrows
+DELETE FROM core_group_members
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: ListGroupMembers :many
+SELECT * FROM core_group_members
+WHERE group_id = $1
+ORDER BY added_at, user_id;
+
+-- name: ListUserGroups :many
+-- Returns the groups of the user in the tenant
+SELECT g.* FROM core_groups g
+JOIN core_group_members m ON m.group_id = g.id
+WHERE g.tenant_id = $1 AND m.user_id = $2
+ORDER BY lower(g.name);
+
+-- name: ListUserGroupRoles :many
+-- Returns the roles the groups of the user grant in the tenant, provided the
+-- user is still an active member of the tenant
+SELECT DISTINCT unnest(g.roles)::text AS role
+FROM core_groups g
+JOIN core_group_members m ON m.group_id = g.id
+JOIN core_user_tenant_memberships tm ON tm.tenant_id = g.tenant_id AND tm.user_id = m.user_id
+WHERE g.tenant_id = $1 AND m.user_id = $2 AND tm.status = 'active'
+ORDER BY role;
+
+-- name: DeleteUserGroupMemberships :execrows
+DELETE FROM core_group_members
+WHERE user_id = $1;
@@ -0,0 +1,23 @@
+-- name: IsStatStatementsInstalled :one
+SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements');
+
+-- name: ListTenantSortedStatements :many
+-- Statements of this database filtering on a tenant and sorting, which is
+-- the shape of the list queries paged with helpers.GetPagingSQL
+SELECT query, calls, total_exec_time, mean_exec_time
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+  AND query ~* '\mtenant_id\M'
+  AND query ~* '\morder\s+by\M'
+ORDER BY total_exec_time DESC
+LIMIT $1;
+
+-- name: ListTableColumns :many
+SELECT table_name::text AS table_name, column_name::text AS column_name
+FROM information_schema.columns
+WHERE table_schema = current_schema();
+
+-- name: ListTableIndexes :many
+SELECT tablename::text AS table_name, indexdef::text AS definition
+FROM pg_indexes
+WHERE schemaname = current_schema();
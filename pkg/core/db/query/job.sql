@@ -0,0 +1,52 @@
+-- name: CreateJob :one
+INSERT INTO core_jobs (
+  tenant_id, kind, created_by
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM core_jobs
+WHERE id = $1 AND tenant_id = sqlc.arg(tenant_id)::text;
+
+-- name: CompleteJob :exec
+UPDATE core_jobs
+SET status = $2, completed_at = clock_timestamp()
+WHERE id = $1;
+
+-- name: CreateJobEvent :one
+INSERT INTO core_job_events (
+  job_id, event_type, message, progress, data_type, data_version, data
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id;
+
+-- name: ListJobEvents :many
+SELECT * FROM core_job_events
+WHERE job_id = $1 AND id > sqlc.arg(since)::bigint
+ORDER BY id
+LIMIT $2;
+
+-- name: GetLatestJobEvent :one
+SELECT * FROM core_job_events
+WHERE job_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: DeleteJobsCreatedBefore :execrows
+DELETE FROM core_jobs
+WHERE created_at < $1;
+
+-- name: CreateJobRowError :exec
+INSERT INTO core_job_row_errors (
+  job_id, line, email, code, message
+) VALUES (
+  $1, $2, $3, $4, $5
+);
+
+-- name: ListJobRowErrors :many
+SELECT * FROM core_job_row_errors
+WHERE job_id = $1
+ORDER BY line, id;
@@ -0,0 +1,22 @@
+-- name: GetLLMConsentPolicy :one
+SELECT * FROM core_llm_consent_policies
+WHERE tenant_id = $1;
+
+-- name: UpsertLLMConsentPolicy :one
+INSERT INTO core_llm_consent_policies (
+  tenant_id, store_inputs, store_outputs, allow_analytics, allowed_regions, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tenant_id) DO UPDATE SET
+  store_inputs = EXCLUDED.store_inputs,
+  store_outputs = EXCLUDED.store_outputs,
+  allow_analytics = EXCLUDED.allow_analytics,
+  allowed_regions = EXCLUDED.allowed_regions,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = clock_timestamp()
+RETURNING *;
+
+-- name: DeleteLLMConsentPolicy :execrows
+DELETE FROM core_llm_consent_policies
+WHERE tenant_id = $1;
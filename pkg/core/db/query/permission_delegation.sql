@@ -0,0 +1,38 @@
+-- name: CreatePermissionDelegation :one
+INSERT INTO core_permission_delegations (
+  tenant_id, delegator_id, delegate_id, operations, delegator_roles, reason, starts_at, ends_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetPermissionDelegationByID :one
+SELECT * FROM core_permission_delegations
+WHERE id = $1 AND tenant_id = $2
+LIMIT 1;
+
+-- name: ListPermissionDelegations :many
+-- Lists the delegations given or received by the user, or every delegation of
+-- the tenant with all_users. Revoked and expired ones are only returned with
+-- include_expired.
+SELECT * FROM core_permission_delegations
+WHERE tenant_id = $1
+  AND (sqlc.arg('all_users')::boolean OR delegator_id = sqlc.arg('user_id') OR delegate_id = sqlc.arg('user_id'))
+  AND (sqlc.arg('include_expired')::boolean OR (revoked_at IS NULL AND ends_at > clock_timestamp()))
+ORDER BY starts_at DESC, id;
+
+-- name: ListActivePermissionDelegations :many
+-- Returns the delegations the user holds right now in the tenant
+SELECT * FROM core_permission_delegations
+WHERE tenant_id = $1
+  AND delegate_id = $2
+  AND revoked_at IS NULL
+  AND starts_at <= clock_timestamp()
+  AND ends_at > clock_timestamp()
+ORDER BY starts_at;
+
+-- name: RevokePermissionDelegation :one
+UPDATE core_permission_delegations
+SET revoked_at = clock_timestamp(), revoked_by = $3
+WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+RETURNING *;
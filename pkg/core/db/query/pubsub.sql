@@ -0,0 +1,4 @@
+-- name: Notify :exec
+-- Sends a notification to the sessions listening to the channel, once the
+-- transaction commits
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
@@ -0,0 +1,30 @@
+-- name: CreateReplayBundle :one
+INSERT INTO core_replay_bundles (
+  tenant_id, request_id, user_id, method, route, status, bundle
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id;
+
+-- name: GetReplayBundle :one
+SELECT * FROM core_replay_bundles
+WHERE id = $1;
+
+-- name: ListReplayBundles :many
+-- Lists the bundles without their content, newest first. A null filter
+-- matches every bundle.
+SELECT id, tenant_id, request_id, user_id, method, route, status, created_at
+FROM core_replay_bundles
+WHERE (sqlc.narg(tenant_id)::text IS NULL OR tenant_id = sqlc.narg(tenant_id)::text)
+  AND (sqlc.narg(request_id)::text IS NULL OR request_id = sqlc.narg(request_id)::text)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: DeleteReplayBundlesCreatedBefore :execrows
+DELETE FROM core_replay_bundles
+WHERE created_at < $1;
+
+-- name: DeleteUserReplayBundles :execrows
+-- The bundles hold the requests of the user, they go with the user
+DELETE FROM core_replay_bundles
+WHERE user_id = $1;
@@ -0,0 +1,80 @@
+-- name: CreateRole :one
+INSERT INTO core_roles (
+  tenant_id, name, description, user_id
+) VALUES (
+  sqlc.arg(tenant_id)::text, sqlc.arg(name), sqlc.arg(description), sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: GetRoleByID :one
+SELECT * FROM core_roles
+WHERE id = $1 AND tenant_id = sqlc.arg(tenant_id)::text
+LIMIT 1;
+
+-- name: ListRoles :many
+SELECT * FROM core_roles
+WHERE tenant_id = sqlc.arg(tenant_id)::text
+ORDER BY lower(name);
+
+-- name: UpdateRole :one
+UPDATE core_roles
+SET name = sqlc.arg(name), description = sqlc.arg(description)
+WHERE id = sqlc.arg(id) AND tenant_id = sqlc.arg(tenant_id)::text
+RETURNING *;
+
+-- name: DeleteRole :execrows
+DELETE FROM core_roles
+WHERE id = $1 AND tenant_id = sqlc.arg(tenant_id)::text;
+
+-- name: AddRolePermissions :exec
+INSERT INTO core_role_permissions (role_id, operation)
+SELECT sqlc.arg(role_id)::uuid, unnest(sqlc.arg(operations)::text[])
+ON CONFLICT (role_id, operation) DO NOTHING;
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM core_role_permissions
+WHERE role_id = $1;
+
+-- name: ListRolePermissions :many
+SELECT operation FROM core_role_permissions
+WHERE role_id = $1
+ORDER BY operation;
+
+-- name: ListTenantRolePermissions :many
+-- Returns the permissions of every custom role of the tenant
+SELECT p.* FROM core_role_permissions p
+JOIN core_roles r ON r.id = p.role_id
+WHERE r.tenant_id = sqlc.arg(tenant_id)::text
+ORDER BY p.role_id, p.operation;
+
+-- name: AddRoleMember :execrows
+INSERT INTO core_role_members (
+  role_id, user_id, assigned_by
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (role_id, user_id) DO NOTHING;
+
+-- name: RemoveRoleMember :execrows
+DELETE FROM core_role_members
+WHERE role_id = $1 AND user_id = $2;
+
+-- name: ListRoleMembers :many
+SELECT * FROM core_role_members
+WHERE role_id = $1
+ORDER BY assigned_at, user_id;
+
+-- name: ListUserRolePermissions :many
+-- Returns the operations the custom roles of the user grant in the tenant,
+-- provided the user is still an active member of the tenant
+SELECT DISTINCT p.operation
+FROM core_role_permissions p
+JOIN core_role_members m ON m.role_id = p.role_id
+JOIN core_roles r ON r.id = p.role_id
+JOIN core_user_tenant_memberships tm ON tm.tenant_id = r.tenant_id AND tm.user_id = m.user_id
+WHERE r.tenant_id = sqlc.arg(tenant_id)::text AND m.user_id = sqlc.arg(user_id) AND tm.status = 'active'
+ORDER BY p.operation;
+
+-- name: DeleteUserRoleMemberships :execrows
+DELETE FROM core_role_members
+WHERE user_id = $1;
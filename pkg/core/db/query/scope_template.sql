@@ -0,0 +1,55 @@
+-- name: CreateScopeTemplate :one
+INSERT INTO core_scope_templates (
+  tenant_id, name, description, scopes, created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetScopeTemplateByID :one
+SELECT * FROM core_scope_templates
+WHERE id = $1 AND (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+LIMIT 1;
+
+-- name: ListScopeTemplates :many
+SELECT * FROM core_scope_templates
+WHERE (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = sqlc.narg('tenant_id')::varchar
+  )
+ORDER BY name;
+
+-- name: UpdateScopeTemplate :one
+-- A NULL propagate_at cancels a propagation still in its grace period
+UPDATE core_scope_templates
+SET
+  name = $2,
+  description = $3,
+  scopes = $4,
+  propagate_at = sqlc.narg('propagate_at'),
+  updated_by = $5,
+  updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteScopeTemplate :execrows
+DELETE FROM core_scope_templates
+WHERE id = $1 AND (
+    (sqlc.narg('tenant_id')::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = sqlc.narg('tenant_id')::varchar
+  );
+
+-- name: ListDueScopeTemplates :many
+-- Templates whose propagation grace period is over
+SELECT * FROM core_scope_templates
+WHERE propagate_at <= NOW()
+ORDER BY propagate_at;
+
+-- name: ClearScopeTemplatePropagation :execrows
+-- Only clears the propagation that was applied, not one scheduled since
+UPDATE core_scope_templates
+SET propagate_at = NULL
+WHERE id = $1 AND propagate_at = $2;
@@ -0,0 +1,54 @@
+-- name: ListOverdueInvitations :many
+-- Lists the pending invitations of the tenant, or of every tenant with a null
+-- tenant, pending since before the time, oldest first
+SELECT utm.id, utm.tenant_id, utm.user_id, u.email, utm.invited_by,
+  COALESCE(utm.invited_at, utm.created_at)::timestamptz AS pending_since,
+  e.escalated_at
+FROM core_user_tenant_memberships utm
+JOIN core_users u ON u.id = utm.user_id
+LEFT JOIN core_sla_escalations e
+  ON e.item_type = 'invitation'
+  AND e.item_id = utm.id::text
+  AND e.pending_since = COALESCE(utm.invited_at, utm.created_at)
+WHERE utm.status = 'pending'
+  AND COALESCE(utm.invited_at, utm.created_at) < sqlc.arg(pending_before)::timestamptz
+  AND (sqlc.narg(tenant_id)::text IS NULL OR utm.tenant_id = sqlc.narg(tenant_id)::text)
+ORDER BY pending_since ASC, utm.id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUnescalatedOverdueInvitations :many
+-- Lists the pending invitations of every tenant, pending since before the
+-- time and not escalated yet, oldest first
+SELECT utm.id, utm.tenant_id, utm.user_id, u.email, utm.invited_by,
+  COALESCE(utm.invited_at, utm.created_at)::timestamptz AS pending_since
+FROM core_user_tenant_memberships utm
+JOIN core_users u ON u.id = utm.user_id
+WHERE utm.status = 'pending'
+  AND COALESCE(utm.invited_at, utm.created_at) < sqlc.arg(pending_before)::timestamptz
+  AND NOT EXISTS (
+    SELECT 1 FROM core_sla_escalations e
+    WHERE e.item_type = 'invitation'
+      AND e.item_id = utm.id::text
+      AND e.pending_since = COALESCE(utm.invited_at, utm.created_at)
+  )
+ORDER BY pending_since ASC, utm.id ASC
+LIMIT sqlc.arg(batch_size);
+
+-- name: CreateSLAEscalation :one
+-- Returns no row when the item was already escalated, so concurrent scanners
+-- escalate each item once
+INSERT INTO core_sla_escalations (item_type, item_id, pending_since, tenant_id)
+VALUES (sqlc.arg(item_type), sqlc.arg(item_id), sqlc.arg(pending_since), sqlc.arg(tenant_id))
+ON CONFLICT DO NOTHING
+RETURNING escalated_at;
+
+-- name: ListTenantAdminEmails :many
+-- Lists the emails of the active CUSTOMER_ADMIN members of the tenant
+SELECT u.email
+FROM core_user_tenant_memberships utm
+JOIN core_users u ON u.id = utm.user_id
+WHERE utm.tenant_id = sqlc.arg(tenant_id)::text
+  AND utm.status = 'active'
+  AND 'CUSTOMER_ADMIN' = ANY(utm.roles)
+  AND u.email IS NOT NULL
+ORDER BY u.email;
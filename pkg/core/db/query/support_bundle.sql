@@ -0,0 +1,24 @@
+-- name: GetDatabaseServerInfo :one
+SELECT version()::text AS server_version,
+  pg_database_size(current_database())::bigint AS size_bytes;
+
+-- name: ListTableStats :many
+-- The largest tables of the schema with their row counts and maintenance
+SELECT relname::text AS name,
+  n_live_tup::bigint AS live_rows,
+  n_dead_tup::bigint AS dead_rows,
+  pg_total_relation_size(relid)::bigint AS size_bytes,
+  last_autovacuum,
+  last_autoanalyze
+FROM pg_stat_user_tables
+WHERE schemaname = current_schema()
+ORDER BY pg_total_relation_size(relid) DESC
+LIMIT $1;
+
+-- name: ListGooseMigrations :many
+-- The last row of a version tells whether it is applied, goose inserting a
+-- row on each up and down
+SELECT DISTINCT ON (version_id) version_id, is_applied, tstamp::timestamptz AS applied_at
+FROM goose_db_version
+WHERE version_id > 0
+ORDER BY version_id, id DESC;
@@ -10,10 +10,22 @@ WHERE tenant_id = $1 LIMIT 1;
 SELECT * FROM core_tenants
 WHERE subdomain = $1 LIMIT 1;
 
+-- name: GetTenantBySubdomainOrAlias :one
+-- Resolves the primary subdomain of a tenant or one of its aliases
+SELECT * FROM core_tenants
+WHERE subdomain = sqlc.arg('subdomain')
+  OR tenant_id = (
+    SELECT a.tenant_id FROM core_tenant_subdomain_aliases a
+    WHERE a.subdomain = sqlc.arg('subdomain')
+  )
+LIMIT 1;
+
 -- name: ListTenants :many
 SELECT * FROM core_tenants
 WHERE (UPPER(name) LIKE UPPER(sqlc.narg('like')) OR sqlc.narg('like') IS NULL)
 AND (reseller_id = sqlc.narg('reseller_id') OR sqlc.narg('reseller_id') IS NULL)
+-- The archived tenants are only listed when asked for
+AND (status = sqlc.narg('status') OR (sqlc.narg('status') IS NULL AND status <> 'archived'))
 ORDER BY
   CASE
             WHEN sqlc.arg('order')::text = 'asc' and sqlc.arg('sortBy')::text = 'tenant_id' THEN "tenant_id"
@@ -50,6 +62,10 @@ SET
 WHERE id = $1
 RETURNING id;
 
+-- name: UpdateTenantSubdomain :exec
+UPDATE core_tenants SET subdomain = $2, updated_at = NOW()
+WHERE tenant_id = $1;
+
 -- name: DisableTenant :exec
 UPDATE core_tenants SET is_disabled = true, updated_at = NOW()
 WHERE tenant_id = $1;
@@ -58,6 +74,17 @@ WHERE tenant_id = $1;
 UPDATE core_tenants SET is_disabled = false, updated_at = NOW()
 WHERE tenant_id = $1;
 
+-- name: UpdateTenantStatus :one
+-- Moves the tenant to the status when it is in one of the from statuses
+UPDATE core_tenants
+SET status = sqlc.arg(status),
+    status_reason = sqlc.narg(status_reason),
+    status_changed_at = NOW(),
+    updated_at = NOW()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND status = ANY(sqlc.arg(from_statuses)::varchar[])
+RETURNING *;
+
 -- name: GetExpiredEnabledTenants :many
 SELECT * FROM core_tenants
 WHERE contract_end_date IS NOT NULL
@@ -80,8 +107,9 @@ WITH reseller AS (
     AND 'CUSTOMER_ADMIN' = ANY(utm.roles)
 )
 SELECT ct.* FROM core_tenants ct
-WHERE ct.tenant_id IN (SELECT tenant_id FROM reseller)
-   OR ct.reseller_id IN (SELECT tenant_id FROM reseller)
+WHERE (ct.tenant_id IN (SELECT tenant_id FROM reseller)
+   OR ct.reseller_id IN (SELECT tenant_id FROM reseller))
+  AND ct.status <> 'archived'
 ORDER BY ct.name ASC;
 
 -- name: UpdateTenantProfile :one
@@ -43,4 +43,9 @@ RETURNING id
 DELETE FROM core_tenant_configs
 WHERE id = $1 and tenant_id = sqlc.arg('tenant_id')::text
 RETURNING id
-;
\ No newline at end of file
+;
+
+-- name: ListAllTenantConfigs :many
+SELECT * FROM core_tenant_configs
+WHERE tenant_id = sqlc.arg('tenant_id')::text
+ORDER BY name ASC;
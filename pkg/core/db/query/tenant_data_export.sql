@@ -0,0 +1,21 @@
+-- name: ListTenantDataExportUsers :many
+-- Members of the tenant, by id after after_user_id, for a full tenant export
+SELECT u.id, u.profile, u.email, u.created_at
+FROM core_users u
+JOIN core_user_tenant_memberships m ON m.user_id = u.id
+WHERE m.tenant_id = sqlc.arg(tenant_id)
+    AND u.id > sqlc.arg(after_user_id)::text
+ORDER BY u.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListTenantDataExportMemberships :many
+SELECT * FROM core_user_tenant_memberships
+WHERE tenant_id = sqlc.arg(tenant_id)
+    AND user_id > sqlc.arg(after_user_id)::text
+ORDER BY user_id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListTenantDataExportConfigs :many
+SELECT * FROM core_tenant_configs
+WHERE tenant_id = $1
+ORDER BY name;
@@ -0,0 +1,25 @@
+-- name: GetTenantEmailProvider :one
+SELECT * FROM core_tenant_email_providers
+WHERE tenant_id = $1;
+
+-- name: UpsertTenantEmailProvider :one
+INSERT INTO core_tenant_email_providers (
+  tenant_id, provider, host, port, username, encrypted_secret, from_address, reply_to, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (tenant_id) DO UPDATE
+SET provider = EXCLUDED.provider,
+    host = EXCLUDED.host,
+    port = EXCLUDED.port,
+    username = EXCLUDED.username,
+    encrypted_secret = EXCLUDED.encrypted_secret,
+    from_address = EXCLUDED.from_address,
+    reply_to = EXCLUDED.reply_to,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteTenantEmailProvider :execrows
+DELETE FROM core_tenant_email_providers
+WHERE tenant_id = $1;
@@ -0,0 +1,121 @@
+-- name: CreateTenantExport :one
+INSERT INTO core_tenant_exports (
+  tenant_id, schedule_id, dataset, format, destination, period_start, period_end, requested_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetTenantExport :one
+SELECT * FROM core_tenant_exports
+WHERE id = $1 AND tenant_id = sqlc.arg(tenant_id)::text;
+
+-- name: ListTenantExports :many
+SELECT * FROM core_tenant_exports
+WHERE tenant_id = sqlc.arg(tenant_id)::text
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: StartTenantExport :exec
+UPDATE core_tenant_exports
+SET status = 'running'
+WHERE id = $1;
+
+-- name: CompleteTenantExport :exec
+UPDATE core_tenant_exports
+SET status = 'completed', file_path = $2, row_count = $3, completed_at = clock_timestamp()
+WHERE id = $1;
+
+-- name: FailTenantExport :exec
+UPDATE core_tenant_exports
+SET status = 'failed', error = $2, completed_at = clock_timestamp()
+WHERE id = $1;
+
+-- name: UpsertTenantExportSchedule :one
+INSERT INTO core_tenant_export_schedules (
+  tenant_id, dataset, format, destination, frequency, next_run_at, created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (tenant_id, dataset, format) DO UPDATE
+SET destination = EXCLUDED.destination,
+    frequency = EXCLUDED.frequency,
+    next_run_at = EXCLUDED.next_run_at,
+    created_by = EXCLUDED.created_by
+RETURNING *;
+
+-- name: ListTenantExportSchedules :many
+SELECT * FROM core_tenant_export_schedules
+WHERE tenant_id = sqlc.arg(tenant_id)::text
+ORDER BY dataset, format;
+
+-- name: DeleteTenantExportSchedule :execrows
+DELETE FROM core_tenant_export_schedules
+WHERE id = $1 AND tenant_id = sqlc.arg(tenant_id)::text;
+
+-- name: ClaimDueTenantExportSchedules :many
+-- Moves the due schedules to their next period and returns them; the period
+-- to export is the one ending at the previous next_run_at. SKIP LOCKED lets
+-- several instances run the scheduler.
+UPDATE core_tenant_export_schedules
+SET next_run_at = next_run_at + CASE frequency WHEN 'weekly' THEN interval '7 days' ELSE interval '1 day' END
+WHERE id IN (
+  SELECT id FROM core_tenant_export_schedules
+  WHERE next_run_at <= now()
+  ORDER BY next_run_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListTenantAuditEvents :many
+-- Audit trail of a tenant over [from_time, to_time): the recorded activity
+-- events and the audit log of the tokens of its client applications, usage
+-- excluded.
+SELECT id, user_id, category, event_type, actor_id, ip_address, occurred_at, data
+FROM (
+  SELECT e.id, e.user_id, e.category, e.event_type, e.actor_id,
+    NULL::varchar AS ip_address, e.occurred_at, e.data
+  FROM core_user_activity_events e
+  WHERE e.tenant_id = sqlc.arg(tenant_id)::text
+    AND e.occurred_at >= sqlc.arg(from_time)
+    AND e.occurred_at < sqlc.arg(to_time)
+  UNION ALL
+  SELECT l.id, t.created_by, 'api_token', l.action, NULL::varchar,
+    l.ip_address, l.timestamp,
+    jsonb_build_object(
+      'token_id', t.id,
+      'token_name', t.name,
+      'client_application_id', t.client_application_id,
+      'user_agent', l.user_agent
+    )
+  FROM core_api_token_audit_logs l
+  JOIN core_api_tokens t ON t.id = l.token_id
+  JOIN core_client_applications a ON a.id = t.client_application_id
+  WHERE a.tenant_id = sqlc.arg(tenant_id)::text
+    AND l.action <> 'USED'
+    AND l.timestamp >= sqlc.arg(from_time)
+    AND l.timestamp < sqlc.arg(to_time)
+) audit
+ORDER BY occurred_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListTenantAPITokenUsage :many
+-- Daily request counts of every token of the tenant over [from_time, to_time)
+SELECT
+  date_trunc('day', l.timestamp, 'UTC')::timestamptz AS day,
+  a.id AS client_application_id,
+  a.name AS client_application_name,
+  t.id AS token_id,
+  t.name AS token_name,
+  COUNT(*) AS requests,
+  COUNT(DISTINCT l.ip_address) AS unique_ips
+FROM core_api_token_audit_logs l
+JOIN core_api_tokens t ON t.id = l.token_id
+JOIN core_client_applications a ON a.id = t.client_application_id
+WHERE a.tenant_id = sqlc.arg(tenant_id)::text
+  AND l.action = 'USED'
+  AND l.timestamp >= sqlc.arg(from_time)
+  AND l.timestamp < sqlc.arg(to_time)
+GROUP BY day, a.id, a.name, t.id, t.name
+ORDER BY day, a.name, t.name;
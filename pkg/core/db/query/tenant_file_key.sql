@@ -0,0 +1,87 @@
+-- name: CreateTenantFileKey :one
+INSERT INTO core_tenant_file_keys (
+  tenant_id, wrapped_key, master_key_id
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetActiveTenantFileKey :one
+SELECT * FROM core_tenant_file_keys
+WHERE tenant_id = $1 AND status = 'active'
+LIMIT 1;
+
+-- name: GetTenantFileKey :one
+SELECT * FROM core_tenant_file_keys
+WHERE id = $1 AND tenant_id = $2
+LIMIT 1;
+
+-- name: RotateTenantFileKey :execrows
+-- Stops the active key of the tenant from encrypting new files
+UPDATE core_tenant_file_keys
+SET status = 'rotated', rotated_at = NOW()
+WHERE tenant_id = $1 AND status = 'active';
+
+-- name: RetireTenantFileKeys :execrows
+-- Retires the keys rotated before the rewrite of the files started, no file
+-- being encrypted with them anymore
+UPDATE core_tenant_file_keys
+SET status = 'retired', retired_at = NOW()
+WHERE tenant_id = $1 AND status = 'rotated' AND rotated_at < sqlc.arg(rewrite_started_at)::timestamptz;
+
+-- name: RequestTenantFileRewrite :one
+-- Enables the encryption of the files of the tenant, and asks for them to be
+-- rewritten with its active key. A running rewrite is run again once done.
+INSERT INTO core_tenant_file_encryption (
+  tenant_id, enabled_by
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (tenant_id) DO UPDATE
+SET rewrite_status = CASE WHEN core_tenant_file_encryption.rewrite_status = 'running' THEN 'running' ELSE 'pending' END,
+    rewrite_requested_at = NOW()
+RETURNING *;
+
+-- name: ClaimTenantFileRewrite :one
+-- Claims the oldest pending rewrite, or a running one whose instance stopped
+-- updating it before stale_before
+UPDATE core_tenant_file_encryption
+SET rewrite_status = 'running', rewrite_started_at = NOW()
+WHERE tenant_id = (
+  SELECT e.tenant_id FROM core_tenant_file_encryption e
+  WHERE e.rewrite_status = 'pending'
+     OR (e.rewrite_status = 'running' AND e.rewrite_started_at < sqlc.arg(stale_before)::timestamptz)
+  ORDER BY e.rewrite_requested_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteTenantFileRewrite :execrows
+UPDATE core_tenant_file_encryption
+SET rewrite_status = CASE WHEN rewrite_requested_at > rewrite_started_at THEN 'pending' ELSE 'idle' END,
+    rewrite_completed_at = NOW(),
+    files_rewritten = $3,
+    last_error = NULL
+WHERE tenant_id = $1 AND rewrite_started_at = $2 AND rewrite_status = 'running';
+
+-- name: FailTenantFileRewrite :execrows
+UPDATE core_tenant_file_encryption
+SET rewrite_status = 'failed',
+    files_rewritten = $3,
+    last_error = $4
+WHERE tenant_id = $1 AND rewrite_started_at = $2 AND rewrite_status = 'running';
+
+-- name: ListTenantFileEncryption :many
+-- Returns the tenants encrypting their files with their active key and the
+-- number of rotated keys some files may still use
+SELECT e.tenant_id, e.enabled_by, e.enabled_at, e.rewrite_status, e.rewrite_requested_at,
+    e.rewrite_started_at, e.rewrite_completed_at, e.files_rewritten, e.last_error,
+    k.id AS key_id, k.created_at AS key_created_at,
+    (SELECT count(*) FROM core_tenant_file_keys r
+     WHERE r.tenant_id = e.tenant_id AND r.status = 'rotated') AS rotated_keys
+FROM core_tenant_file_encryption e
+JOIN core_tenant_file_keys k ON k.tenant_id = e.tenant_id AND k.status = 'active'
+WHERE sqlc.narg(tenant_id)::varchar IS NULL OR e.tenant_id = sqlc.narg(tenant_id)::varchar
+ORDER BY e.tenant_id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,51 @@
+-- name: CreateTenantIsolationProbeResult :exec
+INSERT INTO core_tenant_isolation_probe_results (
+  run_id, probe, attacker_tenant_id, victim_tenant_id, resource_id, outcome, status_code, detail
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: ListTenantIsolationProbeResults :many
+-- Lists the results newest first. A null filter matches every result.
+SELECT * FROM core_tenant_isolation_probe_results
+WHERE (sqlc.narg(outcome)::text IS NULL OR outcome = sqlc.narg(outcome)::text)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: DeleteTenantIsolationProbeResultsBefore :execrows
+DELETE FROM core_tenant_isolation_probe_results
+WHERE created_at < $1;
+
+-- name: ListTenantIsolationProbeTenants :many
+-- Picks tenants at random, so the runs cover every pair over time
+SELECT tenant_id FROM core_tenants
+ORDER BY random()
+LIMIT $1;
+
+-- name: GetTenantIsolationProbeUser :one
+-- Returns an active member of the victim tenant who has no membership in the
+-- attacker tenant and no global role, so the attacker has no right to see them
+SELECT m.user_id FROM core_user_tenant_memberships m
+JOIN core_users u ON u.id = m.user_id
+WHERE m.tenant_id = sqlc.arg(victim_tenant_id)
+  AND m.status = 'active'
+  AND COALESCE(cardinality(u.roles), 0) = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM core_user_tenant_memberships a
+    WHERE a.user_id = m.user_id AND a.tenant_id = sqlc.arg(attacker_tenant_id)
+  )
+LIMIT 1;
+
+-- name: GetTenantIsolationProbeAPIToken :one
+-- Returns a client application of the tenant and one of its tokens, if any
+SELECT a.id AS client_application_id, t.id AS token_id
+FROM core_client_applications a
+LEFT JOIN core_api_tokens t ON t.client_application_id = a.id
+WHERE a.tenant_id = sqlc.arg(tenant_id)::text
+ORDER BY t.id NULLS LAST
+LIMIT 1;
+
+-- name: GetTenantIsolationProbeExport :one
+SELECT id FROM core_tenant_exports
+WHERE tenant_id = $1
+LIMIT 1;
@@ -0,0 +1,278 @@
+-- name: ScheduleTenantPurge :one
+-- Schedules the purge of the tenant. A cancelled purge is scheduled again; no
+-- row is returned when a purge of the tenant is in progress.
+INSERT INTO core_tenant_purges (
+  tenant_uuid, tenant_id, tenant_name, previous_status, reason, requested_by, purge_after
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (tenant_uuid) DO UPDATE
+SET tenant_id = EXCLUDED.tenant_id,
+    tenant_name = EXCLUDED.tenant_name,
+    status = 'scheduled',
+    previous_status = EXCLUDED.previous_status,
+    reason = EXCLUDED.reason,
+    requested_by = EXCLUDED.requested_by,
+    purge_after = EXCLUDED.purge_after,
+    attempts = 0,
+    steps = '[]',
+    last_error = NULL,
+    started_at = NULL,
+    completed_at = NULL,
+    created_at = NOW(),
+    updated_at = NOW()
+WHERE core_tenant_purges.status = 'cancelled'
+RETURNING *;
+
+-- name: GetTenantPurge :one
+SELECT * FROM core_tenant_purges
+WHERE tenant_uuid = $1;
+
+-- name: CancelTenantPurge :one
+-- Cancels a purge during its grace period. No row is returned once the purge
+-- started deleting.
+UPDATE core_tenant_purges
+SET status = 'cancelled', updated_at = NOW()
+WHERE tenant_uuid = $1 AND status = 'scheduled' AND steps = '[]'::jsonb
+RETURNING *;
+
+-- name: ResumeTenantPurge :one
+-- Runs a failed purge again at once, from its last completed step
+UPDATE core_tenant_purges
+SET status = 'scheduled',
+    purge_after = NOW(),
+    attempts = 0,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE tenant_uuid = $1 AND status = 'failed'
+RETURNING *;
+
+-- name: ClaimDueTenantPurges :many
+-- Marks the due purges as running: the scheduled ones whose grace period is
+-- over, and the running ones left by a stopped instance or the failed ones
+-- once the retry delay passed. SKIP LOCKED lets several instances claim
+-- distinct purges.
+UPDATE core_tenant_purges
+SET status = 'running',
+    attempts = attempts + 1,
+    started_at = COALESCE(started_at, NOW()),
+    updated_at = NOW()
+WHERE tenant_uuid IN (
+    SELECT p.tenant_uuid FROM core_tenant_purges p
+    WHERE (p.status = 'scheduled' AND p.purge_after <= NOW())
+       OR (p.status IN ('running', 'failed')
+           AND p.attempts < sqlc.arg(max_attempts)::int
+           AND p.updated_at < sqlc.arg(retry_before)::timestamptz)
+    ORDER BY p.purge_after
+    LIMIT sqlc.arg(max_purges)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SaveTenantPurgeSteps :exec
+UPDATE core_tenant_purges
+SET steps = $2, updated_at = NOW()
+WHERE tenant_uuid = $1;
+
+-- name: CompleteTenantPurge :exec
+UPDATE core_tenant_purges
+SET status = 'completed', last_error = NULL, completed_at = NOW(), updated_at = NOW()
+WHERE tenant_uuid = $1;
+
+-- name: FailTenantPurge :exec
+UPDATE core_tenant_purges
+SET status = 'failed', last_error = sqlc.arg(last_error)::text, updated_at = NOW()
+WHERE tenant_uuid = sqlc.arg(tenant_uuid);
+
+-- name: ListTenantPurgeMembers :many
+-- Members of the tenant, exclusive when the tenant is the only one of the
+-- user and they hold no global role: the purge deletes their account.
+SELECT m.user_id,
+    (COALESCE(cardinality(u.roles), 0) = 0 AND NOT EXISTS (
+        SELECT 1 FROM core_user_tenant_memberships o
+        WHERE o.user_id = m.user_id AND o.tenant_id <> m.tenant_id
+    ))::boolean AS exclusive
+FROM core_user_tenant_memberships m
+INNER JOIN core_users u ON u.id = m.user_id
+WHERE m.tenant_id = $1
+ORDER BY m.user_id;
+
+-- name: CountResellerSubTenants :one
+SELECT COUNT(*) FROM core_tenants
+WHERE reseller_id = $1;
+
+-- name: PurgeTenantAnnouncements :execrows
+DELETE FROM core_announcements
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantAuthFailureCounts :execrows
+DELETE FROM core_auth_failure_counts
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantAuthLockouts :execrows
+DELETE FROM core_auth_lockouts
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantClientApplications :execrows
+DELETE FROM core_client_applications
+WHERE tenant_id = sqlc.arg(tenant_id)::varchar;
+
+-- name: PurgeTenantDirectoryConnections :execrows
+DELETE FROM core_directory_connections
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantDirectorySyncConflicts :execrows
+DELETE FROM core_directory_sync_conflicts
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantDirectoryUsers :execrows
+DELETE FROM core_directory_users
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantEmailTemplates :execrows
+DELETE FROM core_email_templates
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantEmailVerificationTokens :execrows
+DELETE FROM core_email_verification_tokens
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantExecutionWebhooks :execrows
+DELETE FROM core_execution_webhooks
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantGroups :execrows
+DELETE FROM core_groups
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantJobs :execrows
+DELETE FROM core_jobs
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantLlmConsentPolicies :execrows
+DELETE FROM core_llm_consent_policies
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantPermissionDelegations :execrows
+DELETE FROM core_permission_delegations
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantReplayBundles :execrows
+DELETE FROM core_replay_bundles
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantRoles :execrows
+DELETE FROM core_roles
+WHERE tenant_id = sqlc.arg(tenant_id)::varchar;
+
+-- name: PurgeTenantScopeTemplates :execrows
+DELETE FROM core_scope_templates
+WHERE tenant_id = sqlc.arg(tenant_id)::varchar;
+
+-- name: PurgeTenantSlaEscalations :execrows
+DELETE FROM core_sla_escalations
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantConfigs :execrows
+DELETE FROM core_tenant_configs
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantEmailProviders :execrows
+DELETE FROM core_tenant_email_providers
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantExportSchedules :execrows
+DELETE FROM core_tenant_export_schedules
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantExports :execrows
+DELETE FROM core_tenant_exports
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantFeatureFlags :execrows
+DELETE FROM core_tenant_feature_flags
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantFileEncryption :execrows
+DELETE FROM core_tenant_file_encryption
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantFileKeys :execrows
+DELETE FROM core_tenant_file_keys
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantFiles :execrows
+DELETE FROM core_tenant_files
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantPromptExecutions :execrows
+DELETE FROM core_tenant_prompt_executions
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantQuotas :execrows
+DELETE FROM core_tenant_quotas
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantSandboxes :execrows
+DELETE FROM core_tenant_sandboxes
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantSettingValues :execrows
+DELETE FROM core_tenant_setting_values
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantSubdomainAliases :execrows
+DELETE FROM core_tenant_subdomain_aliases
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantTemplates :execrows
+DELETE FROM core_tenant_templates
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUsageCounts :execrows
+DELETE FROM core_tenant_usage_counts
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUsageNotifications :execrows
+DELETE FROM core_tenant_usage_notifications
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantTokenPolicies :execrows
+DELETE FROM core_token_policies
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantTranslations :execrows
+DELETE FROM core_translations
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserActivityEvents :execrows
+DELETE FROM core_user_activity_events
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserAttributes :execrows
+DELETE FROM core_user_attributes
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserAuditLogs :execrows
+DELETE FROM core_user_audit_logs
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserDeletionRequests :execrows
+DELETE FROM core_user_deletion_requests
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserEmailChanges :execrows
+DELETE FROM core_user_email_changes
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserImpersonations :execrows
+DELETE FROM core_user_impersonations
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserInvitations :execrows
+DELETE FROM core_user_invitations
+WHERE tenant_id = $1;
+
+-- name: PurgeTenantUserLogins :execrows
+DELETE FROM core_user_logins
+WHERE tenant_id = $1;
@@ -0,0 +1,71 @@
+-- name: GetTenantQuota :one
+SELECT * FROM core_tenant_quotas
+WHERE tenant_id = $1;
+
+-- name: UpsertTenantQuota :one
+INSERT INTO core_tenant_quotas (
+  tenant_id, max_users, max_client_applications, max_storage_mb,
+  max_prompt_executions_per_month, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tenant_id) DO UPDATE SET
+  max_users = EXCLUDED.max_users,
+  max_client_applications = EXCLUDED.max_client_applications,
+  max_storage_mb = EXCLUDED.max_storage_mb,
+  max_prompt_executions_per_month = EXCLUDED.max_prompt_executions_per_month,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = clock_timestamp()
+RETURNING *;
+
+-- name: GetTenantResourceUsage :one
+-- The resources of the tenant counted by the quotas. The removed memberships
+-- do not count; the prompt executions are those of the current month.
+SELECT
+    (SELECT COUNT(*) FROM core_user_tenant_memberships utm
+        WHERE utm.tenant_id = sqlc.arg(tenant_id) AND utm.status <> 'removed') AS users,
+    (SELECT COUNT(*) FROM core_client_applications ca
+        WHERE ca.tenant_id = sqlc.arg(tenant_id)) AS client_applications,
+    (SELECT COALESCE(SUM(f.size_bytes), 0) FROM core_tenant_files f
+        WHERE f.tenant_id = sqlc.arg(tenant_id))::bigint AS storage_bytes,
+    (SELECT COALESCE(SUM(p.executions), 0) FROM core_tenant_prompt_executions p
+        WHERE p.tenant_id = sqlc.arg(tenant_id)
+        AND p.month = date_trunc('month', NOW() AT TIME ZONE 'UTC')::date)::bigint AS prompt_executions;
+
+-- name: GetTenantStorageExcept :one
+-- Bytes stored by the tenant in the files other than the path, the one about
+-- to be replaced
+SELECT COALESCE(SUM(size_bytes), 0)::bigint AS storage_bytes FROM core_tenant_files
+WHERE tenant_id = $1 AND path <> $2;
+
+-- name: UpsertTenantFile :exec
+INSERT INTO core_tenant_files (tenant_id, path, size_bytes)
+VALUES ($1, $2, $3)
+ON CONFLICT (tenant_id, path) DO UPDATE SET
+  size_bytes = EXCLUDED.size_bytes,
+  updated_at = clock_timestamp();
+
+-- name: DeleteTenantFile :exec
+DELETE FROM core_tenant_files
+WHERE tenant_id = $1 AND path = $2;
+
+-- name: DeleteTenantFiles :exec
+DELETE FROM core_tenant_files
+WHERE tenant_id = $1;
+
+-- name: ReserveTenantPromptExecution :one
+-- Counts a prompt execution of the current month unless the tenant reached
+-- the maximum; no row is returned then
+INSERT INTO core_tenant_prompt_executions AS p (tenant_id, month, executions)
+SELECT sqlc.arg(tenant_id), date_trunc('month', NOW() AT TIME ZONE 'UTC')::date, 1
+WHERE sqlc.narg(max_executions)::int IS NULL OR sqlc.narg(max_executions)::int > 0
+ON CONFLICT (tenant_id, month) DO UPDATE SET executions = p.executions + 1
+WHERE sqlc.narg(max_executions)::int IS NULL OR p.executions < sqlc.narg(max_executions)::int
+RETURNING p.executions;
+
+-- name: ListTenantQuotas :many
+-- The quotas of the tenants after after_tenant_id, by tenant
+SELECT * FROM core_tenant_quotas
+WHERE tenant_id > sqlc.arg(after_tenant_id)::text
+ORDER BY tenant_id
+LIMIT sqlc.arg(batch_size);
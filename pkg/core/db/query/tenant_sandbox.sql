@@ -0,0 +1,51 @@
+-- name: GetTenantSandbox :one
+SELECT * FROM core_tenant_sandboxes
+WHERE tenant_id = $1;
+
+-- name: UpsertTenantSandbox :one
+INSERT INTO core_tenant_sandboxes (tenant_id, template, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (tenant_id) DO UPDATE
+SET template = EXCLUDED.template,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteTenantSandbox :execrows
+DELETE FROM core_tenant_sandboxes
+WHERE tenant_id = $1;
+
+-- name: StartTenantSandboxReset :one
+-- Claims the sandbox for a reset. Returns no row while another reset runs; a
+-- reset older than an hour is considered dead.
+UPDATE core_tenant_sandboxes
+SET reset_started_at = now(),
+    last_reset_by = sqlc.arg(reset_by)::text
+WHERE tenant_id = sqlc.arg(tenant_id)
+    AND (reset_started_at IS NULL OR reset_started_at < now() - interval '1 hour')
+RETURNING *;
+
+-- name: FinishTenantSandboxReset :exec
+UPDATE core_tenant_sandboxes
+SET reset_started_at = NULL,
+    last_reset_at = now(),
+    last_reset_job_id = sqlc.arg(job_id)::uuid,
+    last_reset_status = sqlc.arg(status)::text
+WHERE tenant_id = sqlc.arg(tenant_id);
+
+-- name: ListTenantSandboxResetMembers :many
+-- Members a reset removes: everyone but the tenant owner and the holders of a
+-- kept role, whatever the membership status
+SELECT user_id FROM core_user_tenant_memberships
+WHERE tenant_id = sqlc.arg(tenant_id)
+    AND user_id <> sqlc.arg(owner_id)
+    AND NOT (COALESCE(roles, '{}') && sqlc.arg(kept_roles)::TEXT[])
+ORDER BY user_id;
+
+-- name: DeleteUserWithoutMemberships :execrows
+-- Deletes a user left without any tenant membership nor global role
+DELETE FROM core_users u
+WHERE u.id = $1
+    AND COALESCE(cardinality(u.roles), 0) = 0
+    AND NOT EXISTS (
+        SELECT 1 FROM core_user_tenant_memberships utm WHERE utm.user_id = u.id
+    );
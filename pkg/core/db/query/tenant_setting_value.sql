@@ -0,0 +1,16 @@
+-- name: ListTenantSettingValues :many
+SELECT * FROM core_tenant_setting_values
+WHERE tenant_id = $1
+ORDER BY key;
+
+-- name: UpsertTenantSettingValue :exec
+INSERT INTO core_tenant_setting_values (tenant_id, key, value, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tenant_id, key) DO UPDATE SET
+  value = EXCLUDED.value,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = clock_timestamp();
+
+-- name: DeleteTenantSettingValue :exec
+DELETE FROM core_tenant_setting_values
+WHERE tenant_id = $1 AND key = $2;
@@ -0,0 +1,24 @@
+-- name: CreateTenantSubdomainAlias :one
+-- Inserts nothing when the subdomain is the primary subdomain of a tenant
+INSERT INTO core_tenant_subdomain_aliases (
+  tenant_id, subdomain, created_by
+)
+SELECT sqlc.arg('tenant_id')::varchar, sqlc.arg('subdomain')::varchar, sqlc.arg('created_by')::varchar
+WHERE NOT EXISTS (
+  SELECT 1 FROM core_tenants t WHERE t.subdomain = sqlc.arg('subdomain')::varchar
+)
+RETURNING *;
+
+-- name: GetTenantSubdomainAlias :one
+SELECT * FROM core_tenant_subdomain_aliases
+WHERE subdomain = $1
+LIMIT 1;
+
+-- name: ListTenantSubdomainAliases :many
+SELECT * FROM core_tenant_subdomain_aliases
+WHERE tenant_id = $1
+ORDER BY subdomain;
+
+-- name: DeleteTenantSubdomainAlias :execrows
+DELETE FROM core_tenant_subdomain_aliases
+WHERE tenant_id = $1 AND subdomain = $2;
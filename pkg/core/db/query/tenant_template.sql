@@ -0,0 +1,46 @@
+-- name: UpsertTenantTemplate :one
+INSERT INTO core_tenant_templates (
+  name, description, tenant_id, created_by
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (name) DO UPDATE
+SET description = EXCLUDED.description,
+    tenant_id = EXCLUDED.tenant_id,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetTenantTemplate :one
+SELECT * FROM core_tenant_templates
+WHERE name = $1;
+
+-- name: ListTenantTemplates :many
+SELECT * FROM core_tenant_templates
+ORDER BY name;
+
+-- name: DeleteTenantTemplate :execrows
+DELETE FROM core_tenant_templates
+WHERE name = $1;
+
+-- name: CopyTenantSettingValues :execrows
+-- Copies the typed settings of the source tenant into the target tenant
+INSERT INTO core_tenant_setting_values (tenant_id, key, value, updated_by)
+SELECT sqlc.arg(target_tenant_id)::text, key, value, sqlc.arg(updated_by)::text
+FROM core_tenant_setting_values
+WHERE tenant_id = sqlc.arg(source_tenant_id)::text
+ON CONFLICT (tenant_id, key) DO UPDATE SET
+  value = EXCLUDED.value,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = clock_timestamp();
+
+-- name: CopyTenantEmailTemplates :execrows
+-- Copies the email templates of the source tenant into the target tenant
+INSERT INTO core_email_templates (tenant_id, event_type, locale, subject, body, updated_by)
+SELECT sqlc.arg(target_tenant_id)::text, event_type, locale, subject, body, sqlc.arg(updated_by)::text
+FROM core_email_templates
+WHERE tenant_id = sqlc.arg(source_tenant_id)::text
+ON CONFLICT (tenant_id, event_type, locale) DO UPDATE
+SET subject = EXCLUDED.subject,
+    body = EXCLUDED.body,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW();
@@ -0,0 +1,53 @@
+-- name: IncrementTenantUsageCounts :exec
+-- Adds a batch of counts, one per array index, to their daily buckets. The
+-- batch must not repeat a tenant, bucket and metric.
+INSERT INTO core_tenant_usage_counts (tenant_id, bucket, metric, count)
+SELECT u.tenant_id, u.bucket, u.metric, u.count
+FROM unnest(
+  sqlc.arg('tenant_ids')::varchar[],
+  sqlc.arg('buckets')::timestamptz[],
+  sqlc.arg('metrics')::varchar[],
+  sqlc.arg('counts')::bigint[]
+) AS u(tenant_id, bucket, metric, count)
+ON CONFLICT (tenant_id, bucket, metric) DO UPDATE SET
+  count = core_tenant_usage_counts.count + EXCLUDED.count;
+
+-- name: ListTenantUsageCounts :many
+SELECT * FROM core_tenant_usage_counts
+WHERE tenant_id = $1
+  AND bucket >= sqlc.arg('from')::timestamptz
+  AND bucket < sqlc.arg('to')::timestamptz
+ORDER BY bucket, metric;
+
+-- name: SumTenantUsageCounts :many
+-- The counts of every tenant over the range, by metric
+SELECT tenant_id, metric, SUM(count)::bigint AS count FROM core_tenant_usage_counts
+WHERE bucket >= sqlc.arg('from')::timestamptz
+  AND bucket < sqlc.arg('to')::timestamptz
+GROUP BY tenant_id, metric
+ORDER BY tenant_id, metric;
+
+-- name: GetTenantUsageGauges :one
+-- The current users, active API tokens and stored bytes of the tenant
+SELECT
+    (SELECT COUNT(*) FROM core_user_tenant_memberships utm
+        WHERE utm.tenant_id = sqlc.arg(tenant_id) AND utm.status <> 'removed') AS users,
+    (SELECT COUNT(*) FROM core_api_tokens t
+        JOIN core_client_applications ca ON ca.id = t.client_application_id
+        WHERE ca.tenant_id = sqlc.arg(tenant_id) AND NOT t.revoked AND t.expires_at > NOW()) AS api_tokens,
+    (SELECT COALESCE(SUM(f.size_bytes), 0) FROM core_tenant_files f
+        WHERE f.tenant_id = sqlc.arg(tenant_id))::bigint AS storage_bytes;
+
+-- name: DeleteTenantUsageCountsBefore :execrows
+DELETE FROM core_tenant_usage_counts
+WHERE bucket < $1;
+
+-- name: ClaimTenantUsageNotification :one
+-- Returns no row when the recommendation was notified after notified_before,
+-- so concurrent scanners notify it once and later scans repeat it once
+-- notified_before passed
+INSERT INTO core_tenant_usage_notifications AS n (tenant_id, resource, severity)
+VALUES (sqlc.arg(tenant_id), sqlc.arg(resource), sqlc.arg(severity))
+ON CONFLICT (tenant_id, resource, severity) DO UPDATE SET notified_at = clock_timestamp()
+WHERE n.notified_at < sqlc.arg(notified_before)::timestamptz
+RETURNING n.notified_at;
@@ -0,0 +1,25 @@
+-- name: GetTokenPolicy :one
+SELECT * FROM core_token_policies
+WHERE tenant_id = $1;
+
+-- name: ListTokenPolicies :many
+SELECT * FROM core_token_policies
+ORDER BY tenant_id;
+
+-- name: UpsertTokenPolicy :one
+INSERT INTO core_token_policies (
+  tenant_id, max_expiry_days, allowed_scopes, require_description, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (tenant_id) DO UPDATE SET
+  max_expiry_days = EXCLUDED.max_expiry_days,
+  allowed_scopes = EXCLUDED.allowed_scopes,
+  require_description = EXCLUDED.require_description,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = clock_timestamp()
+RETURNING *;
+
+-- name: DeleteTokenPolicy :execrows
+DELETE FROM core_token_policies
+WHERE tenant_id = $1;
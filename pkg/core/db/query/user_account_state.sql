@@ -0,0 +1,15 @@
+-- name: SetUserAccountState :exec
+-- Records the state of the identity at the auth provider. A null value keeps
+-- the recorded one; unknown users are ignored.
+INSERT INTO core_user_account_states (user_id, disabled, email_verified)
+SELECT u.id, COALESCE(sqlc.narg('disabled')::boolean, false), COALESCE(sqlc.narg('email_verified')::boolean, false)
+FROM core_users u
+WHERE u.id = sqlc.arg(user_id)
+ON CONFLICT (user_id) DO UPDATE
+SET disabled = COALESCE(sqlc.narg('disabled')::boolean, core_user_account_states.disabled),
+    email_verified = COALESCE(sqlc.narg('email_verified')::boolean, core_user_account_states.email_verified),
+    updated_at = NOW();
+
+-- name: ListUserAccountStates :many
+SELECT * FROM core_user_account_states
+WHERE user_id = ANY(sqlc.arg(user_ids)::varchar[]);
@@ -0,0 +1,57 @@
+-- name: CreateUserActivityEvent :exec
+INSERT INTO core_user_activity_events (
+  tenant_id, user_id, category, event_type, actor_id, data
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+);
+
+-- name: ListUserTimeline :many
+-- Merges the recorded activity events with the events derived from the
+-- membership row and from the audit log of the API tokens the user created.
+SELECT id, category, event_type, actor_id, occurred_at, data
+FROM (
+  SELECT e.id, e.category, e.event_type, e.actor_id, e.occurred_at, e.data
+  FROM core_user_activity_events e
+  WHERE e.tenant_id = sqlc.arg(tenant_id)::text
+    AND e.user_id = sqlc.arg(user_id)::text
+  UNION ALL
+  SELECT m.id, 'membership', CASE WHEN m.invited_at IS NULL THEN 'added' ELSE 'invited' END,
+    m.invited_by, COALESCE(m.invited_at, m.created_at), NULL::jsonb
+  FROM core_user_tenant_memberships m
+  WHERE m.tenant_id = sqlc.arg(tenant_id)::text
+    AND m.user_id = sqlc.arg(user_id)::text
+  UNION ALL
+  SELECT m.id, 'membership', 'joined', m.user_id, m.joined_at, NULL::jsonb
+  FROM core_user_tenant_memberships m
+  WHERE m.tenant_id = sqlc.arg(tenant_id)::text
+    AND m.user_id = sqlc.arg(user_id)::text
+    AND m.joined_at IS NOT NULL
+  UNION ALL
+  SELECT l.id, 'api_token', l.action, t.created_by, l.timestamp,
+    jsonb_build_object(
+      'token_id', t.id,
+      'token_name', t.name,
+      'client_application_id', t.client_application_id,
+      'ip_address', l.ip_address
+    )
+  FROM core_api_token_audit_logs l
+  JOIN core_api_tokens t ON t.id = l.token_id
+  JOIN core_client_applications a ON a.id = t.client_application_id
+  WHERE t.created_by = sqlc.arg(user_id)::text
+    AND COALESCE(a.tenant_id, '') = sqlc.arg(tenant_id)::text
+    AND l.action <> 'USED'
+) timeline
+WHERE cardinality(sqlc.arg(categories)::text[]) = 0
+   OR category = ANY(sqlc.arg(categories)::text[])
+ORDER BY occurred_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListUserLastLogins :many
+-- Returns the latest login of each user, in the tenant or, with a null
+-- tenant, in any tenant.
+SELECT user_id, MAX(occurred_at)::timestamptz AS last_login
+FROM core_user_activity_events
+WHERE user_id = ANY(sqlc.arg(user_ids)::text[])
+  AND category = 'login'
+  AND (sqlc.narg(tenant_id)::text IS NULL OR tenant_id = sqlc.narg(tenant_id)::text)
+GROUP BY user_id;
@@ -0,0 +1,34 @@
+-- name: ListUserAttributes :many
+SELECT * FROM core_user_attributes
+WHERE tenant_id = $1
+ORDER BY key;
+
+-- name: UpsertUserAttribute :one
+INSERT INTO core_user_attributes (
+  tenant_id, key, label, type, required, enum_values
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tenant_id, key) DO UPDATE
+SET label = EXCLUDED.label,
+    type = EXCLUDED.type,
+    required = EXCLUDED.required,
+    enum_values = EXCLUDED.enum_values,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteUserAttribute :execrows
+DELETE FROM core_user_attributes
+WHERE tenant_id = $1 AND key = $2;
+
+-- name: MergeSharedUserAttributes :execrows
+-- Merges the attributes into the profile of the user. An attribute set to
+-- null is removed.
+UPDATE core_users
+SET profile = jsonb_set(
+        profile,
+        '{attributes}',
+        jsonb_strip_nulls(COALESCE(profile->'attributes', '{}'::jsonb) || sqlc.arg(attributes)::jsonb),
+        true
+    )
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,15 @@
+-- name: CreateUserAuditLog :exec
+INSERT INTO core_user_audit_logs (
+  tenant_id, user_id, action, actor_id, details
+) VALUES (
+  $1, $2, $3, $4, $5
+);
+
+-- name: ListUserAuditLogs :many
+SELECT id, tenant_id, user_id, action, actor_id, occurred_at, details
+FROM core_user_audit_logs
+WHERE tenant_id = sqlc.arg(tenant_id)::text
+  AND user_id = sqlc.arg(user_id)::text
+  AND (cardinality(sqlc.arg(actions)::text[]) = 0 OR action = ANY(sqlc.arg(actions)::text[]))
+ORDER BY occurred_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
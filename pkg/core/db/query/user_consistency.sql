@@ -0,0 +1,56 @@
+-- name: ListUsersMissingMembership :many
+-- Users whose legacy core_users.tenant_id has no matching membership
+SELECT u.id, u.email, u.roles, u.tenant_id
+FROM core_users u
+JOIN core_tenants t ON t.tenant_id = u.tenant_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM core_user_tenant_memberships utm
+    WHERE utm.user_id = u.id AND utm.tenant_id = u.tenant_id
+  )
+ORDER BY u.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListUsersWithUnknownTenant :many
+-- Users whose legacy core_users.tenant_id points to no tenant
+SELECT u.id, u.email, u.tenant_id
+FROM core_users u
+WHERE u.tenant_id IS NOT NULL AND u.tenant_id <> ''
+  AND NOT EXISTS (SELECT 1 FROM core_tenants t WHERE t.tenant_id = u.tenant_id)
+ORDER BY u.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListUsersWithoutTenant :many
+-- Users with neither a tenant, a membership nor a global role
+SELECT u.id, u.email
+FROM core_users u
+WHERE (u.tenant_id IS NULL OR u.tenant_id = '')
+  AND COALESCE(cardinality(u.roles), 0) = 0
+  AND NOT EXISTS (SELECT 1 FROM core_user_tenant_memberships utm WHERE utm.user_id = u.id)
+ORDER BY u.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListMembershipsWithoutUser :many
+-- Memberships left without a user, only possible where the fk_user
+-- constraint is missing
+SELECT utm.user_id, utm.tenant_id
+FROM core_user_tenant_memberships utm
+WHERE NOT EXISTS (SELECT 1 FROM core_users u WHERE u.id = utm.user_id)
+ORDER BY utm.user_id, utm.tenant_id
+LIMIT sqlc.arg(max_rows);
+
+-- name: CreateMissingUserMembership :execrows
+-- Creates the membership of a legacy user, leaving an existing one untouched
+INSERT INTO core_user_tenant_memberships (
+    user_id,
+    tenant_id,
+    roles,
+    status,
+    joined_at
+) VALUES (
+    $1,
+    sqlc.arg(tenant_id),
+    sqlc.arg(tenant_roles)::TEXT[],
+    'active',
+    NOW()
+)
+ON CONFLICT (user_id, tenant_id) DO NOTHING;
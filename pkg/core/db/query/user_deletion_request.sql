@@ -0,0 +1,43 @@
+-- name: CreateUserDeletionRequest :one
+INSERT INTO core_user_deletion_requests (
+  tenant_id, user_id, token_hash, scheduled_for
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetActiveUserDeletionRequest :one
+-- The deletion of the user that is scheduled or running, if any
+SELECT * FROM core_user_deletion_requests
+WHERE user_id = $1 AND status IN ('scheduled', 'running');
+
+-- name: CancelUserDeletionRequest :one
+-- Uses the cancel token once. No row is returned when the deletion already
+-- started.
+UPDATE core_user_deletion_requests
+SET status = 'cancelled', cancelled_at = NOW()
+WHERE token_hash = $1 AND status = 'scheduled'
+RETURNING *;
+
+-- name: ListDueUserDeletionRequests :many
+SELECT * FROM core_user_deletion_requests
+WHERE status = 'scheduled' AND scheduled_for <= NOW()
+ORDER BY scheduled_for
+LIMIT $1;
+
+-- name: ClaimUserDeletionRequest :one
+-- Marks the deletion as running, so a single instance erases the user and the
+-- link can no longer cancel it
+UPDATE core_user_deletion_requests
+SET status = 'running'
+WHERE id = $1 AND status = 'scheduled'
+RETURNING *;
+
+-- name: FailUserDeletionRequest :exec
+UPDATE core_user_deletion_requests
+SET status = 'failed', last_error = sqlc.arg(last_error)::text
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteUserDeletionRequests :execrows
+DELETE FROM core_user_deletion_requests
+WHERE user_id = $1;
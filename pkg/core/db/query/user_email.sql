@@ -0,0 +1,27 @@
+-- name: ListUserEmailsAfter :many
+-- Keyset pagination over the stored emails, for rewriting them after the
+-- encryption settings changed
+SELECT id, email, email_blind_index
+FROM core_users
+WHERE id > sqlc.arg(after_id)::varchar
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: UpdateUserEmailStorage :execrows
+-- Rewrites the stored form of an email, unless it changed meanwhile
+UPDATE core_users
+SET email = sqlc.arg(email)::text,
+    email_blind_index = sqlc.narg(email_blind_index)::text
+WHERE id = sqlc.arg(id)::varchar
+  AND email = sqlc.arg(previous_email)::text;
+
+-- name: CountUserEmailsByKey :many
+-- Stored emails by encryption key, '' for the plaintext ones
+SELECT
+    (CASE WHEN email LIKE 'enc:%' AND position('@' IN email) = 0
+        THEN split_part(email, ':', 2) ELSE '' END)::text AS key_id,
+    COUNT(*) AS users
+FROM core_users
+WHERE email IS NOT NULL AND email <> ''
+GROUP BY 1
+ORDER BY 1;
@@ -0,0 +1,60 @@
+-- name: CreateUserEmailChange :one
+INSERT INTO core_user_email_changes (
+  tenant_id, user_id, old_email, new_email, token_hash, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: CancelPendingUserEmailChanges :exec
+-- A new request replaces the pending ones, their links stop working
+UPDATE core_user_email_changes
+SET status = 'cancelled'
+WHERE user_id = $1 AND status = 'pending';
+
+-- name: CountRecentUserEmailChanges :one
+SELECT COUNT(*) FROM core_user_email_changes
+WHERE user_id = sqlc.arg(user_id) AND created_at > sqlc.arg(since)::timestamptz;
+
+-- name: GetUserEmailChangeByTokenHash :one
+SELECT * FROM core_user_email_changes
+WHERE token_hash = $1;
+
+-- name: GetUserEmailChangeByRevertTokenHash :one
+SELECT * FROM core_user_email_changes
+WHERE revert_token_hash = $1;
+
+-- name: ClaimUserEmailChange :one
+-- Marks a valid change confirmed, so a token is only used once, and keeps the
+-- hash of the token reverting it
+UPDATE core_user_email_changes
+SET status = 'confirmed',
+    confirmed_at = NOW(),
+    revert_token_hash = sqlc.arg(revert_token_hash),
+    revert_expires_at = sqlc.arg(revert_expires_at)
+WHERE token_hash = sqlc.arg(token_hash) AND status = 'pending' AND expires_at > NOW()
+RETURNING *;
+
+-- name: ReleaseUserEmailChange :exec
+-- Makes a claimed change pending again when it could not be made
+UPDATE core_user_email_changes
+SET status = 'pending',
+    confirmed_at = NULL,
+    revert_token_hash = NULL,
+    revert_expires_at = NULL
+WHERE id = $1 AND status = 'confirmed';
+
+-- name: ClaimUserEmailChangeRevert :one
+UPDATE core_user_email_changes
+SET status = 'reverted', reverted_at = NOW()
+WHERE revert_token_hash = $1 AND status = 'confirmed' AND revert_expires_at > NOW()
+RETURNING *;
+
+-- name: ReleaseUserEmailChangeRevert :exec
+UPDATE core_user_email_changes
+SET status = 'confirmed', reverted_at = NULL
+WHERE id = $1 AND status = 'reverted';
+
+-- name: DeleteUserEmailChanges :execrows
+DELETE FROM core_user_email_changes
+WHERE user_id = $1;
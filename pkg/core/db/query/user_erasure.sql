@@ -0,0 +1,124 @@
+-- name: AnonymizeUserActivityEvents :execrows
+-- Replaces the user with the tombstone in the activity feed. The data of the
+-- user's own events is dropped as it may hold an email or an IP address, the
+-- other events only have the user id replaced.
+UPDATE core_user_activity_events
+SET data = CASE
+        WHEN user_id = sqlc.arg(user_id)::text THEN NULL
+        ELSE replace(data::text, sqlc.arg(user_id)::text, sqlc.arg(tombstone_id)::text)::jsonb
+    END,
+    user_id = CASE WHEN user_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE user_id END,
+    actor_id = CASE WHEN actor_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE actor_id END
+WHERE user_id = sqlc.arg(user_id)::text
+    OR actor_id = sqlc.arg(user_id)::text
+    OR data::text LIKE '%' || sqlc.arg(user_id)::text || '%';
+
+-- name: AnonymizeUserImpersonations :execrows
+UPDATE core_user_impersonations
+SET actor_id = CASE WHEN actor_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE actor_id END,
+    user_id = CASE WHEN user_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE user_id END
+WHERE actor_id = sqlc.arg(user_id)::text
+    OR user_id = sqlc.arg(user_id)::text;
+
+-- name: AnonymizeUserInvitations :execrows
+-- The invitation the user accepted also loses their address
+UPDATE core_user_invitations
+SET email = CASE WHEN user_id = sqlc.arg(user_id)::text THEN '' ELSE email END,
+    email_blind_index = CASE WHEN user_id = sqlc.arg(user_id)::text THEN NULL ELSE email_blind_index END,
+    invited_by = CASE WHEN invited_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE invited_by END,
+    user_id = CASE WHEN user_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE user_id END
+WHERE invited_by = sqlc.arg(user_id)::text
+    OR user_id = sqlc.arg(user_id)::text;
+
+-- name: AnonymizePermissionDelegations :execrows
+UPDATE core_permission_delegations
+SET delegator_id = CASE WHEN delegator_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE delegator_id END,
+    delegate_id = CASE WHEN delegate_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE delegate_id END,
+    revoked_by = CASE WHEN revoked_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE revoked_by END
+WHERE delegator_id = sqlc.arg(user_id)::text
+    OR delegate_id = sqlc.arg(user_id)::text
+    OR revoked_by = sqlc.arg(user_id)::text;
+
+-- name: AnonymizeUserAttributions :one
+-- Replaces the user with the tombstone where a row records who created,
+-- changed or owns it, and returns the number of rows changed
+WITH tenants AS (
+    UPDATE core_tenants SET user_id = sqlc.arg(tombstone_id)::text WHERE user_id = sqlc.arg(user_id)::text RETURNING 1
+), global_configs AS (
+    UPDATE core_global_configs SET user_id = sqlc.arg(tombstone_id)::text WHERE user_id = sqlc.arg(user_id)::text RETURNING 1
+), tenant_configs AS (
+    UPDATE core_tenant_configs SET user_id = sqlc.arg(tombstone_id)::text WHERE user_id = sqlc.arg(user_id)::text RETURNING 1
+), roles AS (
+    UPDATE core_roles SET user_id = sqlc.arg(tombstone_id)::text WHERE user_id = sqlc.arg(user_id)::text RETURNING 1
+), memberships AS (
+    UPDATE core_user_tenant_memberships SET invited_by = sqlc.arg(tombstone_id)::text WHERE invited_by = sqlc.arg(user_id)::text RETURNING 1
+), client_applications AS (
+    UPDATE core_client_applications SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), api_tokens AS (
+    UPDATE core_api_tokens
+    SET created_by = CASE WHEN created_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE created_by END,
+        revoked_by = CASE WHEN revoked_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE revoked_by END
+    WHERE created_by = sqlc.arg(user_id)::text OR revoked_by = sqlc.arg(user_id)::text
+    RETURNING 1
+), client_application_secrets AS (
+    UPDATE core_client_application_secrets SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), client_application_signing_keys AS (
+    UPDATE core_client_application_signing_keys SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), client_application_webhooks AS (
+    UPDATE core_client_application_webhooks SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), announcements AS (
+    UPDATE core_announcements SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), tenant_export_schedules AS (
+    UPDATE core_tenant_export_schedules SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), tenant_exports AS (
+    UPDATE core_tenant_exports SET requested_by = sqlc.arg(tombstone_id)::text WHERE requested_by = sqlc.arg(user_id)::text RETURNING 1
+), jobs AS (
+    UPDATE core_jobs SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), tenant_subdomain_aliases AS (
+    UPDATE core_tenant_subdomain_aliases SET created_by = sqlc.arg(tombstone_id)::text WHERE created_by = sqlc.arg(user_id)::text RETURNING 1
+), scope_templates AS (
+    UPDATE core_scope_templates
+    SET created_by = CASE WHEN created_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE created_by END,
+        updated_by = CASE WHEN updated_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE updated_by END
+    WHERE created_by = sqlc.arg(user_id)::text OR updated_by = sqlc.arg(user_id)::text
+    RETURNING 1
+), token_policies AS (
+    UPDATE core_token_policies SET updated_by = sqlc.arg(tombstone_id)::text WHERE updated_by = sqlc.arg(user_id)::text RETURNING 1
+), tenant_sandboxes AS (
+    UPDATE core_tenant_sandboxes
+    SET created_by = CASE WHEN created_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE created_by END,
+        last_reset_by = CASE WHEN last_reset_by = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE last_reset_by END
+    WHERE created_by = sqlc.arg(user_id)::text OR last_reset_by = sqlc.arg(user_id)::text
+    RETURNING 1
+), llm_consent_policies AS (
+    UPDATE core_llm_consent_policies SET updated_by = sqlc.arg(tombstone_id)::text WHERE updated_by = sqlc.arg(user_id)::text RETURNING 1
+)
+SELECT ((SELECT COUNT(*) FROM tenants)
+    + (SELECT COUNT(*) FROM global_configs)
+    + (SELECT COUNT(*) FROM tenant_configs)
+    + (SELECT COUNT(*) FROM roles)
+    + (SELECT COUNT(*) FROM memberships)
+    + (SELECT COUNT(*) FROM client_applications)
+    + (SELECT COUNT(*) FROM api_tokens)
+    + (SELECT COUNT(*) FROM client_application_secrets)
+    + (SELECT COUNT(*) FROM client_application_signing_keys)
+    + (SELECT COUNT(*) FROM client_application_webhooks)
+    + (SELECT COUNT(*) FROM announcements)
+    + (SELECT COUNT(*) FROM tenant_export_schedules)
+    + (SELECT COUNT(*) FROM tenant_exports)
+    + (SELECT COUNT(*) FROM jobs)
+    + (SELECT COUNT(*) FROM tenant_subdomain_aliases)
+    + (SELECT COUNT(*) FROM scope_templates)
+    + (SELECT COUNT(*) FROM token_policies)
+    + (SELECT COUNT(*) FROM tenant_sandboxes)
+    + (SELECT COUNT(*) FROM llm_consent_policies))::bigint AS anonymized;
+
+-- name: AnonymizeUserAuditLogs :execrows
+-- The details of the changes made to the user may hold their email or name,
+-- the changes they made to others only have the actor replaced.
+UPDATE core_user_audit_logs
+SET details = CASE WHEN user_id = sqlc.arg(user_id)::text THEN NULL ELSE details END,
+    user_id = CASE WHEN user_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE user_id END,
+    actor_id = CASE WHEN actor_id = sqlc.arg(user_id)::text THEN sqlc.arg(tombstone_id)::text ELSE actor_id END
+WHERE user_id = sqlc.arg(user_id)::text
+    OR actor_id = sqlc.arg(user_id)::text;
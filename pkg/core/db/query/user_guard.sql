@@ -0,0 +1,10 @@
+-- name: CountOtherActiveCustomerAdmins :one
+-- The CUSTOMER_ADMINs of the tenant besides the user who can still act: active
+-- members whose account is not disabled
+SELECT count(*) FROM core_user_tenant_memberships utm
+LEFT JOIN core_user_account_states s ON s.user_id = utm.user_id
+WHERE utm.tenant_id = $1
+  AND utm.user_id <> $2
+  AND utm.status = 'active'
+  AND 'CUSTOMER_ADMIN' = ANY(utm.roles)
+  AND NOT COALESCE(s.disabled, false);
@@ -0,0 +1,25 @@
+-- name: CreateUserImpersonation :one
+INSERT INTO core_user_impersonations (
+  token_hash, actor_id, tenant_id, user_id, reason, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetActiveUserImpersonation :one
+-- Returns the impersonation of a token while it runs, for its actor only
+SELECT * FROM core_user_impersonations
+WHERE token_hash = $1
+  AND actor_id = $2
+  AND tenant_id = $3
+  AND ended_at IS NULL
+  AND expires_at > now();
+
+-- name: EndUserImpersonations :execrows
+UPDATE core_user_impersonations
+SET ended_at = clock_timestamp()
+WHERE actor_id = $1
+  AND tenant_id = $2
+  AND user_id = $3
+  AND ended_at IS NULL
+  AND expires_at > now();
@@ -0,0 +1,70 @@
+-- name: CreateUserInvitation :one
+INSERT INTO core_user_invitations (
+  tenant_id, email, email_blind_index, roles, token_hash, invited_by, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetUserInvitation :one
+SELECT * FROM core_user_invitations
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetUserInvitationByTokenHash :one
+SELECT * FROM core_user_invitations
+WHERE token_hash = $1;
+
+-- name: GetPendingUserInvitationByEmail :one
+-- Returns the pending invitation of the address in the tenant, expired ones
+-- included, so an address is not invited twice
+SELECT * FROM core_user_invitations
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND status = 'pending'
+  AND (email = sqlc.arg(email)::text OR email_blind_index = sqlc.narg(email_blind_index)::text)
+LIMIT 1;
+
+-- name: ListUserInvitations :many
+-- Lists the invitations of the tenant, newest first. The expired status is
+-- derived from a pending invitation past its expiry.
+SELECT * FROM core_user_invitations
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (
+    sqlc.narg(status)::text IS NULL
+    OR sqlc.narg(status)::text = CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: RenewUserInvitation :one
+-- Replaces the token of a pending invitation, the previous link stops working
+UPDATE core_user_invitations
+SET token_hash = $3,
+    expires_at = $4,
+    sent_at = NOW(),
+    send_count = send_count + 1
+WHERE id = $1 AND tenant_id = $2 AND status = 'pending'
+RETURNING *;
+
+-- name: RevokeUserInvitation :one
+UPDATE core_user_invitations
+SET status = 'revoked', revoked_at = NOW()
+WHERE id = $1 AND tenant_id = $2 AND status = 'pending'
+RETURNING *;
+
+-- name: ClaimUserInvitation :one
+-- Marks a valid invitation accepted, so a token is only used once
+UPDATE core_user_invitations
+SET status = 'accepted', accepted_at = NOW()
+WHERE token_hash = $1 AND status = 'pending' AND expires_at > NOW()
+RETURNING *;
+
+-- name: ReleaseUserInvitation :exec
+-- Makes a claimed invitation pending again, when the user could not be created
+UPDATE core_user_invitations
+SET status = 'pending', accepted_at = NULL
+WHERE id = $1 AND status = 'accepted' AND user_id IS NULL;
+
+-- name: SetUserInvitationUser :exec
+UPDATE core_user_invitations
+SET user_id = $2
+WHERE id = $1;
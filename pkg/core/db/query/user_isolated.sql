@@ -6,7 +6,7 @@ LIMIT 1;
 
 -- name: GetUserByTenantByEmail :one
 SELECT * FROM core_users
-WHERE email = sqlc.arg(email)::text
+WHERE (email = sqlc.arg(email)::text OR email_blind_index = sqlc.narg(email_blind_index)::text)
 AND tenant_id = sqlc.arg(tenant_id)::text
 LIMIT 1;
 
@@ -20,9 +20,9 @@ OFFSET $2;
 
 -- name: CreateUserByTenant :one
 INSERT INTO core_users (
-  "id", "email", "profile", roles, "tenant_id"
+  "id", "email", "profile", roles, "tenant_id", email_blind_index
 ) VALUES (
-  $1, sqlc.arg(email)::text, $2, sqlc.arg(roles)::VARCHAR[], sqlc.arg(tenant_id)::text
+  $1, sqlc.arg(email)::text, $2, sqlc.arg(roles)::VARCHAR[], sqlc.arg(tenant_id)::text, sqlc.narg(email_blind_index)::text
 )
 RETURNING *;
 
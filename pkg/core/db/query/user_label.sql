@@ -0,0 +1,12 @@
+-- name: GetUserTenantMembershipLabelsForUpdate :one
+-- Locks the membership until the end of the transaction, so concurrent label
+-- changes do not overwrite each other
+SELECT labels FROM core_user_tenant_memberships
+WHERE user_id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: UpdateUserTenantMembershipLabels :one
+UPDATE core_user_tenant_memberships
+SET labels = sqlc.arg(labels)::text[], updated_at = NOW()
+WHERE user_id = sqlc.arg(user_id) AND tenant_id = sqlc.arg(tenant_id)
+RETURNING labels;
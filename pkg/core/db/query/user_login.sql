@@ -0,0 +1,22 @@
+-- name: RecordUserLogin :execrows
+-- Records the login of a provider session. A session already recorded leaves
+-- the row as it is, so only its first verified request counts.
+INSERT INTO core_user_logins (
+  user_id, tenant_id, session_id, last_login_at, last_login_ip
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, tenant_id) DO UPDATE
+SET session_id = EXCLUDED.session_id,
+    last_login_at = EXCLUDED.last_login_at,
+    last_login_ip = EXCLUDED.last_login_ip
+WHERE core_user_logins.session_id IS DISTINCT FROM EXCLUDED.session_id;
+
+-- name: ListUserLastLoginDetails :many
+-- Returns the latest login of each user, in the tenant or, with a null
+-- tenant, in any tenant.
+SELECT DISTINCT ON (user_id) user_id, tenant_id, last_login_at, last_login_ip
+FROM core_user_logins
+WHERE user_id = ANY(sqlc.arg(user_ids)::text[])
+  AND (sqlc.narg(tenant_id)::text IS NULL OR tenant_id = sqlc.narg(tenant_id)::text)
+ORDER BY user_id, last_login_at DESC;
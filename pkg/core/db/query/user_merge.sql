@@ -0,0 +1,147 @@
+-- name: ListUserMembershipsForMerge :many
+SELECT * FROM core_user_tenant_memberships
+WHERE user_id = $1
+ORDER BY tenant_id;
+
+-- name: MoveUserTenantMembership :execrows
+UPDATE core_user_tenant_memberships
+SET user_id = sqlc.arg(to_user_id), updated_at = NOW()
+WHERE user_id = sqlc.arg(from_user_id) AND tenant_id = sqlc.arg(tenant_id);
+
+-- name: MergeUserTenantMembership :one
+-- Adds the roles and labels of another membership of the tenant to the user's.
+-- The membership becomes active when the other one is.
+UPDATE core_user_tenant_memberships
+SET roles = ARRAY(SELECT DISTINCT unnest(roles || sqlc.arg(roles)::text[]) ORDER BY 1),
+    labels = ARRAY(SELECT DISTINCT unnest(labels || sqlc.arg(labels)::text[]) ORDER BY 1),
+    status = CASE WHEN sqlc.arg(status)::text = 'active' THEN 'active' ELSE status END,
+    updated_at = NOW()
+WHERE user_id = sqlc.arg(user_id) AND tenant_id = sqlc.arg(tenant_id)
+RETURNING roles;
+
+-- name: DeleteUserTenantMembership :exec
+DELETE FROM core_user_tenant_memberships
+WHERE user_id = $1 AND tenant_id = $2;
+
+-- name: ListUserForeignKeys :many
+-- Single column foreign keys to the users
+SELECT cl.relname::text AS table_name, a.attname::text AS column_name
+FROM pg_constraint con
+JOIN pg_class cl ON cl.oid = con.conrelid
+JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = con.conkey[1]
+WHERE con.contype = 'f'
+  AND con.confrelid = 'core_users'::regclass
+  AND array_length(con.conkey, 1) = 1
+ORDER BY 1, 2;
+
+-- name: MergeUserGroupMembers :one
+-- Moves the group memberships of the duplicate to the primary user, but the
+-- groups the primary user is a member of already. The rows left to the
+-- duplicate are counted from the snapshot of the statement, taken before the
+-- update.
+WITH moved AS (
+    UPDATE core_group_members AS t
+    SET user_id = sqlc.arg(primary_id)::varchar
+    WHERE t.user_id = sqlc.arg(duplicate_id)::varchar
+        AND NOT EXISTS (
+            SELECT 1 FROM core_group_members x
+            WHERE x.user_id = sqlc.arg(primary_id)::varchar AND x.group_id = t.group_id
+        )
+    RETURNING 1
+)
+SELECT (SELECT COUNT(*) FROM moved)::bigint AS moved,
+    ((SELECT COUNT(*) FROM core_group_members WHERE user_id = sqlc.arg(duplicate_id)::varchar)
+        - (SELECT COUNT(*) FROM moved))::bigint AS kept;
+
+-- name: MergeUserRoleMembers :one
+-- Moves the role memberships of the duplicate to the primary user, but the
+-- roles the primary user holds already. The rows left to the duplicate are
+-- counted from the snapshot of the statement, taken before the update.
+WITH moved AS (
+    UPDATE core_role_members AS t
+    SET user_id = sqlc.arg(primary_id)::varchar
+    WHERE t.user_id = sqlc.arg(duplicate_id)::varchar
+        AND NOT EXISTS (
+            SELECT 1 FROM core_role_members x
+            WHERE x.user_id = sqlc.arg(primary_id)::varchar AND x.role_id = t.role_id
+        )
+    RETURNING 1
+)
+SELECT (SELECT COUNT(*) FROM moved)::bigint AS moved,
+    ((SELECT COUNT(*) FROM core_role_members WHERE user_id = sqlc.arg(duplicate_id)::varchar)
+        - (SELECT COUNT(*) FROM moved))::bigint AS kept;
+
+-- name: MergeUserDirectoryUsers :one
+-- Moves the directory links of the duplicate to the primary user, but in the
+-- tenants where the primary user is linked to a directory user already. The
+-- rows left to the duplicate are counted from the snapshot of the statement,
+-- taken before the update.
+WITH moved AS (
+    UPDATE core_directory_users AS t
+    SET user_id = sqlc.arg(primary_id)::varchar
+    WHERE t.user_id = sqlc.arg(duplicate_id)::varchar
+        AND NOT EXISTS (
+            SELECT 1 FROM core_directory_users x
+            WHERE x.user_id = sqlc.arg(primary_id)::varchar AND x.tenant_id = t.tenant_id
+        )
+    RETURNING 1
+)
+SELECT (SELECT COUNT(*) FROM moved)::bigint AS moved,
+    ((SELECT COUNT(*) FROM core_directory_users WHERE user_id = sqlc.arg(duplicate_id)::varchar)
+        - (SELECT COUNT(*) FROM moved))::bigint AS kept;
+
+-- name: MergeUserLogins :one
+-- Moves the last logins of the duplicate to the primary user, but in the
+-- tenants where the primary user has a login already. The rows left to the
+-- duplicate are counted from the snapshot of the statement, taken before the
+-- update.
+WITH moved AS (
+    UPDATE core_user_logins AS t
+    SET user_id = sqlc.arg(primary_id)::varchar
+    WHERE t.user_id = sqlc.arg(duplicate_id)::varchar
+        AND NOT EXISTS (
+            SELECT 1 FROM core_user_logins x
+            WHERE x.user_id = sqlc.arg(primary_id)::varchar AND x.tenant_id = t.tenant_id
+        )
+    RETURNING 1
+)
+SELECT (SELECT COUNT(*) FROM moved)::bigint AS moved,
+    ((SELECT COUNT(*) FROM core_user_logins WHERE user_id = sqlc.arg(duplicate_id)::varchar)
+        - (SELECT COUNT(*) FROM moved))::bigint AS kept;
+
+-- name: MergeUserOnboardingSteps :one
+-- Moves the completed onboarding steps of the duplicate to the primary user,
+-- but the steps the primary user completed already. The rows left to the
+-- duplicate are counted from the snapshot of the statement, taken before the
+-- update.
+WITH moved AS (
+    UPDATE core_user_onboarding_steps AS t
+    SET user_id = sqlc.arg(primary_id)::varchar
+    WHERE t.user_id = sqlc.arg(duplicate_id)::varchar
+        AND NOT EXISTS (
+            SELECT 1 FROM core_user_onboarding_steps x
+            WHERE x.user_id = sqlc.arg(primary_id)::varchar AND x.step = t.step
+        )
+    RETURNING 1
+)
+SELECT (SELECT COUNT(*) FROM moved)::bigint AS moved,
+    ((SELECT COUNT(*) FROM core_user_onboarding_steps WHERE user_id = sqlc.arg(duplicate_id)::varchar)
+        - (SELECT COUNT(*) FROM moved))::bigint AS kept;
+
+-- name: MergeUserInvitations :execrows
+-- Moves the accepted invitations of the duplicate to the primary user
+UPDATE core_user_invitations
+SET user_id = sqlc.arg(primary_id)::varchar
+WHERE user_id = sqlc.arg(duplicate_id)::varchar;
+
+-- name: MergeUserActivityEvents :execrows
+-- Moves the activity events of the duplicate to the primary user
+UPDATE core_user_activity_events
+SET user_id = sqlc.arg(primary_id)::varchar
+WHERE user_id = sqlc.arg(duplicate_id)::varchar;
+
+-- name: MergeUserAuditLogs :execrows
+-- Moves the audit log entries of the duplicate to the primary user
+UPDATE core_user_audit_logs
+SET user_id = sqlc.arg(primary_id)::varchar
+WHERE user_id = sqlc.arg(duplicate_id)::varchar;
@@ -0,0 +1,92 @@
+-- name: GetUserMFA :one
+SELECT * FROM core_user_mfa
+WHERE user_id = $1;
+
+-- name: UpsertPendingUserMFA :one
+-- Starts or restarts an enrollment. Returns no row when the second factor is
+-- already enabled.
+INSERT INTO core_user_mfa (user_id, secret_encrypted)
+VALUES (sqlc.arg(user_id), sqlc.arg(secret_encrypted))
+ON CONFLICT (user_id) DO UPDATE
+SET secret_encrypted = EXCLUDED.secret_encrypted,
+  recovery_code_hashes = '{}',
+  last_used_step = 0,
+  updated_at = now()
+WHERE core_user_mfa.enabled = false
+RETURNING *;
+
+-- name: EnableUserMFA :one
+-- Confirms a pending enrollment with its first code
+UPDATE core_user_mfa
+SET enabled = true,
+  enabled_at = now(),
+  recovery_code_hashes = sqlc.arg(recovery_code_hashes)::text[],
+  last_used_step = sqlc.arg(last_used_step),
+  failed_attempts = 0,
+  locked_until = NULL,
+  updated_at = now()
+WHERE user_id = sqlc.arg(user_id)
+  AND enabled = false
+RETURNING *;
+
+-- name: RecordUserMFACode :execrows
+-- Accepts a code of a later time step than the last accepted one. No row is
+-- updated when the code was already used.
+UPDATE core_user_mfa
+SET last_used_step = sqlc.arg(last_used_step),
+  failed_attempts = 0,
+  locked_until = NULL,
+  updated_at = now()
+WHERE user_id = sqlc.arg(user_id)
+  AND last_used_step < sqlc.arg(last_used_step);
+
+-- name: UseUserMFARecoveryCode :execrows
+-- Consumes a recovery code. No row is updated when the code is unknown.
+UPDATE core_user_mfa
+SET recovery_code_hashes = array_remove(recovery_code_hashes, sqlc.arg(code_hash)::text),
+  failed_attempts = 0,
+  locked_until = NULL,
+  updated_at = now()
+WHERE user_id = sqlc.arg(user_id)
+  AND enabled = true
+  AND sqlc.arg(code_hash)::text = ANY(recovery_code_hashes);
+
+-- name: RecordUserMFAFailure :exec
+-- Counts a wrong code, and locks the second factor until the time once the
+-- maximum of consecutive failures is reached
+UPDATE core_user_mfa
+SET failed_attempts = CASE WHEN failed_attempts + 1 >= sqlc.arg(max_attempts)::int THEN 0 ELSE failed_attempts + 1 END,
+  locked_until = CASE WHEN failed_attempts + 1 >= sqlc.arg(max_attempts)::int THEN sqlc.arg(locked_until)::timestamptz ELSE locked_until END,
+  updated_at = now()
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: ReplaceUserMFARecoveryCodes :execrows
+UPDATE core_user_mfa
+SET recovery_code_hashes = sqlc.arg(recovery_code_hashes)::text[],
+  updated_at = now()
+WHERE user_id = sqlc.arg(user_id)
+  AND enabled = true;
+
+-- name: DeleteUserMFA :execrows
+DELETE FROM core_user_mfa
+WHERE user_id = $1;
+
+-- name: CreateUserMFASession :exec
+INSERT INTO core_user_mfa_sessions (token_hash, user_id, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: GetUserMFASession :one
+-- Returns when the session passed the TOTP check
+SELECT created_at FROM core_user_mfa_sessions
+WHERE token_hash = $1
+  AND user_id = $2
+  AND expires_at > now();
+
+-- name: DeleteUserMFASessions :exec
+DELETE FROM core_user_mfa_sessions
+WHERE user_id = $1;
+
+-- name: DeleteExpiredUserMFASessions :exec
+DELETE FROM core_user_mfa_sessions
+WHERE user_id = $1
+  AND expires_at <= now();
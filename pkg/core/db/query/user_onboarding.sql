@@ -0,0 +1,24 @@
+-- name: ListUserOnboardingSteps :many
+SELECT * FROM core_user_onboarding_steps
+WHERE user_id = $1
+ORDER BY completed_at, step;
+
+-- name: GetUserOnboardingSignals :one
+-- What the application knows of the onboarding of the user: the verified
+-- email, the first recorded login and the MFA enrollment
+SELECT
+    COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = u.id), false)::boolean AS email_verified,
+    (SELECT MIN(l.last_login_at) FROM core_user_logins l WHERE l.user_id = u.id)::timestamptz AS first_login_at,
+    (SELECT m.enabled_at FROM core_user_mfa m WHERE m.user_id = u.id AND m.enabled)::timestamptz AS mfa_enabled_at
+FROM core_users u
+WHERE u.id = $1;
+
+-- name: CompleteUserOnboardingSteps :execrows
+-- Completing a step again keeps the time it was first completed
+INSERT INTO core_user_onboarding_steps (user_id, step)
+SELECT sqlc.arg(user_id), unnest(sqlc.arg(steps)::varchar[])
+ON CONFLICT (user_id, step) DO NOTHING;
+
+-- name: ResetUserOnboardingSteps :execrows
+DELETE FROM core_user_onboarding_steps
+WHERE user_id = sqlc.arg(user_id) AND step = ANY(sqlc.arg(steps)::varchar[]);
@@ -0,0 +1,49 @@
+-- name: CreateUserPhoneVerification :one
+INSERT INTO core_user_phone_verifications (
+  user_id, phone_number, code_hash, expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: DeletePendingUserPhoneVerifications :exec
+-- A new code replaces the pending ones
+DELETE FROM core_user_phone_verifications
+WHERE user_id = $1 AND verified_at IS NULL;
+
+-- name: CountRecentUserPhoneVerifications :one
+SELECT COUNT(*) FROM core_user_phone_verifications
+WHERE user_id = sqlc.arg(user_id) AND created_at > sqlc.arg(since)::timestamptz;
+
+-- name: GetPendingUserPhoneVerification :one
+SELECT * FROM core_user_phone_verifications
+WHERE user_id = $1 AND verified_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: AttemptUserPhoneVerification :one
+-- Counts a guess before it is checked, so concurrent guesses cannot exceed
+-- the limit. No row is returned once the code expired or ran out of attempts.
+UPDATE core_user_phone_verifications
+SET attempts = attempts + 1
+WHERE id = sqlc.arg(id) AND verified_at IS NULL AND expires_at > NOW()
+  AND attempts < sqlc.arg(max_attempts)::int
+RETURNING *;
+
+-- name: MarkUserPhoneVerificationVerified :one
+UPDATE core_user_phone_verifications
+SET verified_at = NOW()
+WHERE id = $1 AND verified_at IS NULL
+RETURNING *;
+
+-- name: SetUserPhoneNumberVerified :execrows
+-- Stores the confirmed number in the profile, marked verified
+UPDATE core_users
+SET profile = jsonb_set(
+        jsonb_set(profile, '{phoneNumber}', to_jsonb(sqlc.arg(phone_number)::text), true),
+        '{phoneNumberVerified}', 'true'::jsonb, true)
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteUserPhoneVerifications :execrows
+DELETE FROM core_user_phone_verifications
+WHERE user_id = $1;
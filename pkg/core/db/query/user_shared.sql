@@ -26,7 +26,7 @@ SELECT
     utm.tenant_id
 FROM core_users u
 INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
-WHERE u.email = sqlc.arg(email)::text
+WHERE (u.email = sqlc.arg(email)::text OR u.email_blind_index = sqlc.narg(email_blind_index)::text)
     AND utm.tenant_id = sqlc.arg(tenant_id)
     AND utm.status = 'active'
 LIMIT 1;
@@ -41,11 +41,17 @@ FROM core_users u
 INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
 WHERE utm.tenant_id = sqlc.arg(tenant_id)
     AND utm.status = 'active'
+    -- Full-text search over the email and the profile, or an exact email
     AND (
-        email ILIKE sqlc.narg('search_prefix')::text || '%'
-        OR sqlc.narg('search_prefix') IS NULL
+        sqlc.narg('search')::text IS NULL
+        OR u.email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(u.email, u.profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(u.email, u.profile)
     )
-ORDER BY u.created_at
+ORDER BY
+    CASE WHEN sqlc.narg('search')::text IS NULL THEN 0
+        ELSE core_user_search_rank(u.email, u.profile, sqlc.narg('search')::text) END DESC,
+    u.created_at
 LIMIT $1
 OFFSET $2;
 
@@ -60,24 +66,99 @@ SELECT
     u.*,
     utm.roles as tenant_roles,
     utm.status as membership_status,
-    utm.joined_at
+    utm.joined_at,
+    utm.labels as tenant_labels
 FROM core_users u
 INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
 WHERE utm.tenant_id = sqlc.arg(tenant_id)
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        sqlc.narg('search')::text IS NULL
+        OR u.email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(u.email, u.profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(u.email, u.profile)
+    )
+    -- Stale accounts: created before the cutoff and no login in the tenant since
+    AND (
+        sqlc.narg('inactive_since')::timestamptz IS NULL
+        OR (
+            u.created_at < sqlc.narg('inactive_since')::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = u.id
+                  AND l.tenant_id = utm.tenant_id
+                  AND l.last_login_at >= sqlc.narg('inactive_since')::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND (sqlc.narg('role')::text IS NULL OR sqlc.narg('role')::text = ANY(utm.roles))
+    -- Labels: the users carrying every one of them
+    AND (sqlc.narg('labels')::text[] IS NULL OR utm.labels @> sqlc.narg('labels')::text[])
+    AND (
+        sqlc.narg('disabled')::boolean IS NULL
+        OR sqlc.narg('disabled')::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = u.id), false)
+    )
     AND (
-        email ILIKE sqlc.narg('search_prefix')::text || '%'
-        OR sqlc.narg('search_prefix') IS NULL
+        sqlc.narg('email_verified')::boolean IS NULL
+        OR sqlc.narg('email_verified')::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = u.id), false)
     )
-ORDER BY u.created_at
+    AND (sqlc.narg('created_from')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_from')::timestamptz)
+    AND (sqlc.narg('created_to')::timestamptz IS NULL OR u.created_at <= sqlc.narg('created_to')::timestamptz)
+ORDER BY
+    CASE WHEN sqlc.narg('search')::text IS NULL THEN 0
+        ELSE core_user_search_rank(u.email, u.profile, sqlc.narg('search')::text) END DESC,
+    u.created_at
 LIMIT $1
 OFFSET $2;
 
+-- name: CountSharedUsersByTenantAllStatuses :one
+-- Counts the users of ListSharedUsersByTenantAllStatuses, for the pagers
+SELECT COUNT(*)
+FROM core_users u
+INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
+WHERE utm.tenant_id = sqlc.arg(tenant_id)
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        sqlc.narg('search')::text IS NULL
+        OR u.email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(u.email, u.profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(u.email, u.profile)
+    )
+    -- Stale accounts: created before the cutoff and no login in the tenant since
+    AND (
+        sqlc.narg('inactive_since')::timestamptz IS NULL
+        OR (
+            u.created_at < sqlc.narg('inactive_since')::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = u.id
+                  AND l.tenant_id = utm.tenant_id
+                  AND l.last_login_at >= sqlc.narg('inactive_since')::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND (sqlc.narg('role')::text IS NULL OR sqlc.narg('role')::text = ANY(utm.roles))
+    -- Labels: the users carrying every one of them
+    AND (sqlc.narg('labels')::text[] IS NULL OR utm.labels @> sqlc.narg('labels')::text[])
+    AND (
+        sqlc.narg('disabled')::boolean IS NULL
+        OR sqlc.narg('disabled')::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = u.id), false)
+    )
+    AND (
+        sqlc.narg('email_verified')::boolean IS NULL
+        OR sqlc.narg('email_verified')::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = u.id), false)
+    )
+    AND (sqlc.narg('created_from')::timestamptz IS NULL OR u.created_at >= sqlc.narg('created_from')::timestamptz)
+    AND (sqlc.narg('created_to')::timestamptz IS NULL OR u.created_at <= sqlc.narg('created_to')::timestamptz);
+
 -- name: CreateSharedUser :one
 -- USED
 INSERT INTO core_users (
-  "id", "email", "profile", roles
+  "id", "email", "profile", roles, email_blind_index
 ) VALUES (
-  $1, sqlc.arg(email)::text, $2, sqlc.arg(roles)::VARCHAR[]
+  $1, sqlc.arg(email)::text, $2, sqlc.arg(roles)::VARCHAR[], sqlc.narg(email_blind_index)::text
 )
 RETURNING *;
 
@@ -85,9 +166,9 @@ RETURNING *;
 -- USED
 WITH new_user AS (
     INSERT INTO core_users (
-        "id", "email", "profile"
+        "id", "email", "profile", email_blind_index
     ) VALUES (
-        $1, sqlc.arg(email)::text, $2
+        $1, sqlc.arg(email)::text, $2, sqlc.narg(email_blind_index)::text
     )
     RETURNING *
 ),
@@ -279,15 +360,83 @@ FROM core_users
 WHERE 
     -- Use GIN index for array overlap
     roles && sqlc.arg(requested_roles)::VARCHAR[]
-    -- Optimize email search
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        sqlc.narg('search')::text IS NULL
+        OR email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(email, profile)
+    )
+    -- Stale accounts: created before the cutoff and no login anywhere since
+    AND (
+        sqlc.narg('inactive_since')::timestamptz IS NULL
+        OR (
+            created_at < sqlc.narg('inactive_since')::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= sqlc.narg('inactive_since')::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND (sqlc.narg('role')::text IS NULL OR sqlc.narg('role')::text = ANY(roles))
     AND (
-        email ILIKE sqlc.narg('search_prefix')::text || '%'
-        OR sqlc.narg('search_prefix') IS NULL
+        sqlc.narg('disabled')::boolean IS NULL
+        OR sqlc.narg('disabled')::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
     )
-ORDER BY email ASC
+    AND (
+        sqlc.narg('email_verified')::boolean IS NULL
+        OR sqlc.narg('email_verified')::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from')::timestamptz)
+    AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_to')::timestamptz)
+ORDER BY
+    CASE WHEN sqlc.narg('search')::text IS NULL THEN 0
+        ELSE core_user_search_rank(email, profile, sqlc.narg('search')::text) END DESC,
+    email ASC
 LIMIT $1
 OFFSET $2;
 
+-- name: CountSharedUsersByRoles :one
+-- Counts the users of ListSharedUsersByRoles, for the pagers
+SELECT COUNT(*)
+FROM core_users
+WHERE 
+    -- Use GIN index for array overlap
+    roles && sqlc.arg(requested_roles)::VARCHAR[]
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        sqlc.narg('search')::text IS NULL
+        OR email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(email, profile)
+    )
+    -- Stale accounts: created before the cutoff and no login anywhere since
+    AND (
+        sqlc.narg('inactive_since')::timestamptz IS NULL
+        OR (
+            created_at < sqlc.narg('inactive_since')::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= sqlc.narg('inactive_since')::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND (sqlc.narg('role')::text IS NULL OR sqlc.narg('role')::text = ANY(roles))
+    AND (
+        sqlc.narg('disabled')::boolean IS NULL
+        OR sqlc.narg('disabled')::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        sqlc.narg('email_verified')::boolean IS NULL
+        OR sqlc.narg('email_verified')::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from')::timestamptz)
+    AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_to')::timestamptz);
+
 
 -- name: ListSharedUsers :many
 -- List every user system-wide (admin domain, scope=all). Global roles only —
@@ -300,12 +449,79 @@ SELECT
     created_at
 FROM core_users
 WHERE
-    email ILIKE sqlc.narg('search_prefix')::text || '%'
-    OR sqlc.narg('search_prefix') IS NULL
-ORDER BY email ASC
+    -- Full-text search over the email and the profile, or an exact email
+    (
+        sqlc.narg('search')::text IS NULL
+        OR email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(email, profile)
+    )
+    AND (
+        sqlc.narg('inactive_since')::timestamptz IS NULL
+        OR (
+            created_at < sqlc.narg('inactive_since')::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= sqlc.narg('inactive_since')::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND (sqlc.narg('role')::text IS NULL OR sqlc.narg('role')::text = ANY(roles))
+    AND (
+        sqlc.narg('disabled')::boolean IS NULL
+        OR sqlc.narg('disabled')::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        sqlc.narg('email_verified')::boolean IS NULL
+        OR sqlc.narg('email_verified')::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from')::timestamptz)
+    AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_to')::timestamptz)
+ORDER BY
+    CASE WHEN sqlc.narg('search')::text IS NULL THEN 0
+        ELSE core_user_search_rank(email, profile, sqlc.narg('search')::text) END DESC,
+    email ASC
 LIMIT $1
 OFFSET $2;
 
+-- name: CountSharedUsers :one
+-- Counts the users of ListSharedUsers, for the pagers
+SELECT COUNT(*)
+FROM core_users
+WHERE
+    -- Full-text search over the email and the profile, or an exact email
+    (
+        sqlc.narg('search')::text IS NULL
+        OR email_blind_index = sqlc.narg('search_blind_index')::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query(sqlc.narg('search')::text)
+        OR core_user_search_normalize(sqlc.narg('search')::text) <% core_user_search_text(email, profile)
+    )
+    AND (
+        sqlc.narg('inactive_since')::timestamptz IS NULL
+        OR (
+            created_at < sqlc.narg('inactive_since')::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= sqlc.narg('inactive_since')::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND (sqlc.narg('role')::text IS NULL OR sqlc.narg('role')::text = ANY(roles))
+    AND (
+        sqlc.narg('disabled')::boolean IS NULL
+        OR sqlc.narg('disabled')::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        sqlc.narg('email_verified')::boolean IS NULL
+        OR sqlc.narg('email_verified')::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from')::timestamptz)
+    AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_to')::timestamptz);
+
 
 -- name: UpdateUserTenantMembershipStatus :one
 UPDATE core_user_tenant_memberships
@@ -424,7 +640,7 @@ SELECT DISTINCT ON (id)
     profile, 
     created_at
 FROM core_users
-WHERE email = sqlc.arg(email)::text
+WHERE email = sqlc.arg(email)::text OR email_blind_index = sqlc.narg(email_blind_index)::text
 LIMIT 1;
 
 -- name: CountUserTenants :one
@@ -451,3 +667,31 @@ WHERE user_id = sqlc.arg(user_id)
     AND tenant_id = sqlc.arg(tenant_id)
     AND status = 'active'
 RETURNING user_id AS id;
+
+-- name: CountActiveTenantMembers :one
+SELECT COUNT(*)
+FROM core_user_tenant_memberships
+WHERE tenant_id = $1 AND status = 'active';
+
+-- name: ListActiveTenantMembersAfter :many
+-- Keyset pagination over a tenant's active members ordered by user_id, so a
+-- batch job can resume from the last user it processed.
+SELECT utm.*
+FROM core_user_tenant_memberships utm
+WHERE utm.tenant_id = sqlc.arg(tenant_id)
+    AND utm.status = 'active'
+    AND utm.user_id > sqlc.arg(after_user_id)::varchar
+ORDER BY utm.user_id ASC
+LIMIT sqlc.arg(batch_size);
+
+-- name: IsDisplayNameTakenInTenant :one
+-- Check if another active member of the tenant uses the display name, ignoring
+-- case and surrounding spaces
+SELECT EXISTS(
+    SELECT 1 FROM core_users u
+    INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
+    WHERE utm.tenant_id = sqlc.arg(tenant_id)
+        AND utm.status = 'active'
+        AND u.id <> sqlc.arg(user_id)
+        AND LOWER(TRIM(u.profile->>'name')) = LOWER(TRIM(sqlc.arg(name)::text))
+) as is_taken;
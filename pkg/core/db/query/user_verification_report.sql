@@ -0,0 +1,40 @@
+-- name: CountUserVerificationStates :one
+-- The users of the tenant by the state of their identity, as recorded in the
+-- account states. Disabled users are counted as verified or unverified too.
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE COALESCE(s.email_verified, false)) AS verified,
+    COUNT(*) FILTER (WHERE NOT COALESCE(s.email_verified, false)) AS unverified,
+    COUNT(*) FILTER (WHERE COALESCE(s.disabled, false)) AS disabled
+FROM core_user_tenant_memberships utm
+LEFT JOIN core_user_account_states s ON s.user_id = utm.user_id
+WHERE utm.tenant_id = $1;
+
+-- name: ListStaleUnverifiedUsers :many
+-- The enabled users of the tenant whose email is unverified, created before
+-- the date, oldest first, with the last verification email still on record
+SELECT u.id, u.email, u.profile, u.created_at,
+    (SELECT MAX(t.created_at) FROM core_email_verification_tokens t
+        WHERE t.user_id = u.id AND t.tenant_id = utm.tenant_id)::timestamptz AS last_verification_sent_at,
+    (SELECT MAX(l.last_login_at) FROM core_user_logins l
+        WHERE l.user_id = u.id AND l.tenant_id = utm.tenant_id)::timestamptz AS last_login_at
+FROM core_users u
+INNER JOIN core_user_tenant_memberships utm ON utm.user_id = u.id
+LEFT JOIN core_user_account_states s ON s.user_id = u.id
+WHERE utm.tenant_id = sqlc.arg(tenant_id)
+    AND NOT COALESCE(s.email_verified, false)
+    AND NOT COALESCE(s.disabled, false)
+    AND u.created_at < sqlc.arg(created_before)::timestamptz
+ORDER BY u.created_at, u.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: CountStaleUnverifiedUsers :one
+-- Counts the users of ListStaleUnverifiedUsers
+SELECT COUNT(*)
+FROM core_users u
+INNER JOIN core_user_tenant_memberships utm ON utm.user_id = u.id
+LEFT JOIN core_user_account_states s ON s.user_id = u.id
+WHERE utm.tenant_id = sqlc.arg(tenant_id)
+    AND NOT COALESCE(s.email_verified, false)
+    AND NOT COALESCE(s.disabled, false)
+    AND u.created_at < sqlc.arg(created_before)::timestamptz;
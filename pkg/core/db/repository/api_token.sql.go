@@ -13,14 +13,123 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const applyScopeTemplateToAPITokens = `-- name: ApplyScopeTemplateToAPITokens :many
+WITH targets AS (
+  SELECT id, scopes FROM core_api_tokens
+  WHERE scope_template_id = $1
+    AND revoked = false
+    AND expires_at > NOW()
+    AND scopes IS DISTINCT FROM $2::text[]
+  FOR UPDATE
+)
+UPDATE core_api_tokens t
+SET scopes = $2::text[], updated_at = NOW()
+FROM targets
+WHERE t.id = targets.id
+RETURNING t.id, targets.scopes AS previous_scopes
+`
+
+type ApplyScopeTemplateToAPITokensParams struct {
+	ScopeTemplateID pgtype.UUID `json:"scope_template_id"`
+	Scopes          []string    `json:"scopes"`
+}
+
+type ApplyScopeTemplateToAPITokensRow struct {
+	ID             uuid.UUID `json:"id"`
+	PreviousScopes []string  `json:"previous_scopes"`
+}
+
+// Copies the template scopes to its active tokens whose scopes differ,
+// returning the scopes each token had before
+func (q *Queries) ApplyScopeTemplateToAPITokens(ctx context.Context, arg ApplyScopeTemplateToAPITokensParams) ([]ApplyScopeTemplateToAPITokensRow, error) {
+	rows, err := q.db.Query(ctx, applyScopeTemplateToAPITokens, arg.ScopeTemplateID, arg.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApplyScopeTemplateToAPITokensRow{}
+	for rows.Next() {
+		var i ApplyScopeTemplateToAPITokensRow
+		if err := rows.Scan(&i.ID, &i.PreviousScopes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countAPITokens = `-- name: CountAPITokens :one
+SELECT COUNT(*)
+FROM core_api_tokens t
+JOIN core_client_applications c ON t.client_application_id = c.id
+WHERE (
+    ($1::varchar IS NULL AND (c.tenant_id IS NULL OR c.tenant_id = ''))
+    OR c.tenant_id = $1::varchar
+  )
+  AND (t.client_application_id = $2::uuid OR $2 IS NULL)
+  AND (
+    $3::boolean OR t.revoked = false
+  )
+  AND (
+    $4::boolean OR t.expires_at > NOW()
+  )
+  AND (
+    UPPER(t.name) LIKE UPPER($5) 
+    OR UPPER(c.name) LIKE UPPER($5)
+    OR $5 IS NULL
+  )
+`
+
+type CountAPITokensParams struct {
+	TenantID            pgtype.Text `json:"tenant_id"`
+	ClientApplicationID pgtype.UUID `json:"client_application_id"`
+	IncludeRevoked      pgtype.Bool `json:"include_revoked"`
+	IncludeExpired      pgtype.Bool `json:"include_expired"`
+	Like                interface{} `json:"like"`
+}
+
+// Counts the tokens matched by the filters of ListAPITokens
+func (q *Queries) CountAPITokens(ctx context.Context, arg CountAPITokensParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAPITokens,
+		arg.TenantID,
+		arg.ClientApplicationID,
+		arg.IncludeRevoked,
+		arg.IncludeExpired,
+		arg.Like,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countActiveAPITokens = `-- name: CountActiveAPITokens :one
+SELECT COUNT(*) FROM core_api_tokens
+WHERE client_application_id = $1
+  AND revoked = false
+  AND expires_at > NOW()
+`
+
+// Counts the tokens of the application neither revoked nor expired
+func (q *Queries) CountActiveAPITokens(ctx context.Context, clientApplicationID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveAPITokens, clientApplicationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAPIToken = `-- name: CreateAPIToken :one
 INSERT INTO core_api_tokens (
   client_application_id, name, description, token_hash, token_prefix, 
-  expires_at, created_by, scopes
+  expires_at, created_by, scopes, hash_version, scope_template_id,
+  rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+  COALESCE($13::text[], '{}')
 )
-RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip
+RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip, rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs, hash_version, scope_template_id
 `
 
 type CreateAPITokenParams struct {
@@ -32,6 +141,11 @@ type CreateAPITokenParams struct {
 	ExpiresAt           time.Time   `json:"expires_at"`
 	CreatedBy           string      `json:"created_by"`
 	Scopes              []string    `json:"scopes"`
+	HashVersion         int16       `json:"hash_version"`
+	ScopeTemplateID     pgtype.UUID `json:"scope_template_id"`
+	RateLimitPerMinute  pgtype.Int4 `json:"rate_limit_per_minute"`
+	RateLimitPerHour    pgtype.Int4 `json:"rate_limit_per_hour"`
+	AllowedCidrs        []string    `json:"allowed_cidrs"`
 }
 
 func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (CoreApiToken, error) {
@@ -44,6 +158,11 @@ func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams)
 		arg.ExpiresAt,
 		arg.CreatedBy,
 		arg.Scopes,
+		arg.HashVersion,
+		arg.ScopeTemplateID,
+		arg.RateLimitPerMinute,
+		arg.RateLimitPerHour,
+		arg.AllowedCidrs,
 	)
 	var i CoreApiToken
 	err := row.Scan(
@@ -64,6 +183,11 @@ func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams)
 		&i.UpdatedAt,
 		&i.LastUsedAt,
 		&i.LastUsedIp,
+		&i.RateLimitPerMinute,
+		&i.RateLimitPerHour,
+		&i.AllowedCidrs,
+		&i.HashVersion,
+		&i.ScopeTemplateID,
 	)
 	return i, err
 }
@@ -106,6 +230,94 @@ func (q *Queries) CreateAPITokenAuditLog(ctx context.Context, arg CreateAPIToken
 	return i, err
 }
 
+const createAPITokenAuditLogs = `-- name: CreateAPITokenAuditLogs :execrows
+INSERT INTO core_api_token_audit_logs (
+  token_id, action, ip_address, user_agent, additional_data, timestamp
+)
+SELECT u.token_id, u.action, u.ip_address, u.user_agent, NULLIF(u.additional_data, '')::jsonb, u.timestamp
+FROM unnest(
+  $1::uuid[],
+  $2::varchar[],
+  $3::varchar[],
+  $4::text[],
+  $5::text[],
+  $6::timestamptz[]
+) AS u(token_id, action, ip_address, user_agent, additional_data, timestamp)
+`
+
+type CreateAPITokenAuditLogsParams struct {
+	TokenIds       []uuid.UUID `json:"token_ids"`
+	Actions        []string    `json:"actions"`
+	IpAddresses    []string    `json:"ip_addresses"`
+	UserAgents     []string    `json:"user_agents"`
+	AdditionalData []string    `json:"additional_data"`
+	Timestamps     []time.Time `json:"timestamps"`
+}
+
+// Inserts a batch of audit entries, one per array index
+func (q *Queries) CreateAPITokenAuditLogs(ctx context.Context, arg CreateAPITokenAuditLogsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createAPITokenAuditLogs,
+		arg.TokenIds,
+		arg.Actions,
+		arg.IpAddresses,
+		arg.UserAgents,
+		arg.AdditionalData,
+		arg.Timestamps,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createAPITokenExpiredNotice = `-- name: CreateAPITokenExpiredNotice :one
+INSERT INTO core_api_token_audit_logs (token_id, action, additional_data)
+SELECT $1, 'EXPIRED', $2
+WHERE NOT EXISTS (
+  SELECT 1 FROM core_api_token_audit_logs
+  WHERE token_id = $1 AND action = 'EXPIRED'
+)
+RETURNING id
+`
+
+type CreateAPITokenExpiredNoticeParams struct {
+	TokenID        uuid.UUID `json:"token_id"`
+	AdditionalData []byte    `json:"additional_data"`
+}
+
+// Only inserts when the token has no notice yet, so concurrent scanners
+// notify each token once
+func (q *Queries) CreateAPITokenExpiredNotice(ctx context.Context, arg CreateAPITokenExpiredNoticeParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, createAPITokenExpiredNotice, arg.TokenID, arg.AdditionalData)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createAPITokenExpiryWarning = `-- name: CreateAPITokenExpiryWarning :one
+INSERT INTO core_api_token_audit_logs (token_id, action, additional_data)
+SELECT $1, 'EXPIRY_WARNING', $2
+WHERE NOT EXISTS (
+  SELECT 1 FROM core_api_token_audit_logs
+  WHERE token_id = $1 AND action = 'EXPIRY_WARNING'
+)
+RETURNING id
+`
+
+type CreateAPITokenExpiryWarningParams struct {
+	TokenID        uuid.UUID `json:"token_id"`
+	AdditionalData []byte    `json:"additional_data"`
+}
+
+// Only inserts when the token has no warning yet, so concurrent scanners
+// notify each token once
+func (q *Queries) CreateAPITokenExpiryWarning(ctx context.Context, arg CreateAPITokenExpiryWarningParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, createAPITokenExpiryWarning, arg.TokenID, arg.AdditionalData)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
 const deleteAPIToken = `-- name: DeleteAPIToken :one
 DELETE FROM core_api_tokens
 WHERE id = $1
@@ -118,22 +330,47 @@ func (q *Queries) DeleteAPIToken(ctx context.Context, id uuid.UUID) (uuid.UUID,
 	return id, err
 }
 
+const detachAPITokenFromScopeTemplate = `-- name: DetachAPITokenFromScopeTemplate :exec
+UPDATE core_api_tokens
+SET scope_template_id = NULL
+WHERE id = $1
+`
+
+func (q *Queries) DetachAPITokenFromScopeTemplate(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, detachAPITokenFromScopeTemplate, id)
+	return err
+}
+
 const getAPITokenAuditLogs = `-- name: GetAPITokenAuditLogs :many
 SELECT id, token_id, action, ip_address, user_agent, timestamp, additional_data FROM core_api_token_audit_logs
 WHERE token_id = $1
+  AND ($4::varchar IS NULL OR action = $4::varchar)
+  AND ($5::timestamptz IS NULL OR timestamp >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR timestamp < $6::timestamptz)
 ORDER BY timestamp DESC
 LIMIT $2
 OFFSET $3
 `
 
 type GetAPITokenAuditLogsParams struct {
-	TokenID uuid.UUID `json:"token_id"`
-	Limit   int32     `json:"limit"`
-	Offset  int32     `json:"offset"`
+	TokenID uuid.UUID          `json:"token_id"`
+	Limit   int32              `json:"limit"`
+	Offset  int32              `json:"offset"`
+	Action  pgtype.Text        `json:"action"`
+	From    pgtype.Timestamptz `json:"from"`
+	To      pgtype.Timestamptz `json:"to"`
 }
 
+// A NULL action, from or to does not filter the entries
 func (q *Queries) GetAPITokenAuditLogs(ctx context.Context, arg GetAPITokenAuditLogsParams) ([]CoreApiTokenAuditLog, error) {
-	rows, err := q.db.Query(ctx, getAPITokenAuditLogs, arg.TokenID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getAPITokenAuditLogs,
+		arg.TokenID,
+		arg.Limit,
+		arg.Offset,
+		arg.Action,
+		arg.From,
+		arg.To,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -160,17 +397,25 @@ func (q *Queries) GetAPITokenAuditLogs(ctx context.Context, arg GetAPITokenAudit
 	return items, nil
 }
 
-const getAPITokenByHash = `-- name: GetAPITokenByHash :one
-SELECT t.id, t.client_application_id, t.name, t.description, t.token_hash, t.token_prefix, t.expires_at, t.revoked, t.revoked_at, t.revoked_reason, t.revoked_by, t.created_by, t.scopes, t.created_at, t.updated_at, t.last_used_at, t.last_used_ip, c.tenant_id 
+const getAPITokenByID = `-- name: GetAPITokenByID :one
+SELECT t.id, t.client_application_id, t.name, t.description, t.token_hash, t.token_prefix, t.expires_at, t.revoked, t.revoked_at, t.revoked_reason, t.revoked_by, t.created_by, t.scopes, t.created_at, t.updated_at, t.last_used_at, t.last_used_ip, t.rate_limit_per_minute, t.rate_limit_per_hour, t.allowed_cidrs, t.hash_version, t.scope_template_id, c.tenant_id 
 FROM core_api_tokens t
 JOIN core_client_applications c ON t.client_application_id = c.id
-WHERE t.token_hash = $1 
+WHERE t.id = $1
+  AND (
+    ($2::varchar IS NULL AND (c.tenant_id IS NULL OR c.tenant_id = ''))
+    OR c.tenant_id = $2::varchar
+  )
   AND t.revoked = false
-  AND t.expires_at > NOW()
 LIMIT 1
 `
 
-type GetAPITokenByHashRow struct {
+type GetAPITokenByIDParams struct {
+	ID       uuid.UUID   `json:"id"`
+	TenantID pgtype.Text `json:"tenant_id"`
+}
+
+type GetAPITokenByIDRow struct {
 	ID                  uuid.UUID          `json:"id"`
 	ClientApplicationID uuid.UUID          `json:"client_application_id"`
 	Name                string             `json:"name"`
@@ -188,12 +433,17 @@ type GetAPITokenByHashRow struct {
 	UpdatedAt           time.Time          `json:"updated_at"`
 	LastUsedAt          pgtype.Timestamptz `json:"last_used_at"`
 	LastUsedIp          pgtype.Text        `json:"last_used_ip"`
+	RateLimitPerMinute  pgtype.Int4        `json:"rate_limit_per_minute"`
+	RateLimitPerHour    pgtype.Int4        `json:"rate_limit_per_hour"`
+	AllowedCidrs        []string           `json:"allowed_cidrs"`
+	HashVersion         int16              `json:"hash_version"`
+	ScopeTemplateID     pgtype.UUID        `json:"scope_template_id"`
 	TenantID            pgtype.Text        `json:"tenant_id"`
 }
 
-func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash []byte) (GetAPITokenByHashRow, error) {
-	row := q.db.QueryRow(ctx, getAPITokenByHash, tokenHash)
-	var i GetAPITokenByHashRow
+func (q *Queries) GetAPITokenByID(ctx context.Context, arg GetAPITokenByIDParams) (GetAPITokenByIDRow, error) {
+	row := q.db.QueryRow(ctx, getAPITokenByID, arg.ID, arg.TenantID)
+	var i GetAPITokenByIDRow
 	err := row.Scan(
 		&i.ID,
 		&i.ClientApplicationID,
@@ -212,30 +462,156 @@ func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash []byte) (GetA
 		&i.UpdatedAt,
 		&i.LastUsedAt,
 		&i.LastUsedIp,
+		&i.RateLimitPerMinute,
+		&i.RateLimitPerHour,
+		&i.AllowedCidrs,
+		&i.HashVersion,
+		&i.ScopeTemplateID,
 		&i.TenantID,
 	)
 	return i, err
 }
 
-const getAPITokenByID = `-- name: GetAPITokenByID :one
-SELECT t.id, t.client_application_id, t.name, t.description, t.token_hash, t.token_prefix, t.expires_at, t.revoked, t.revoked_at, t.revoked_reason, t.revoked_by, t.created_by, t.scopes, t.created_at, t.updated_at, t.last_used_at, t.last_used_ip, c.tenant_id 
+const getAPITokenTopEndpoints = `-- name: GetAPITokenTopEndpoints :many
+SELECT
+  COALESCE(additional_data->>'method', '')::text AS method,
+  (additional_data->>'path')::text AS path,
+  COUNT(*) AS requests
+FROM core_api_token_audit_logs
+WHERE token_id = $1
+  AND action = 'USED'
+  AND timestamp >= $2
+  AND timestamp < $3
+  AND additional_data->>'path' IS NOT NULL
+GROUP BY 1, 2
+ORDER BY requests DESC, path
+LIMIT $4
+`
+
+type GetAPITokenTopEndpointsParams struct {
+	TokenID      uuid.UUID `json:"token_id"`
+	FromTime     time.Time `json:"from_time"`
+	ToTime       time.Time `json:"to_time"`
+	MaxEndpoints int32     `json:"max_endpoints"`
+}
+
+type GetAPITokenTopEndpointsRow struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Requests int64  `json:"requests"`
+}
+
+func (q *Queries) GetAPITokenTopEndpoints(ctx context.Context, arg GetAPITokenTopEndpointsParams) ([]GetAPITokenTopEndpointsRow, error) {
+	rows, err := q.db.Query(ctx, getAPITokenTopEndpoints,
+		arg.TokenID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MaxEndpoints,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAPITokenTopEndpointsRow{}
+	for rows.Next() {
+		var i GetAPITokenTopEndpointsRow
+		if err := rows.Scan(&i.Method, &i.Path, &i.Requests); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAPITokenUsageByDay = `-- name: GetAPITokenUsageByDay :many
+SELECT
+  date_trunc('day', timestamp, 'UTC')::timestamptz AS day,
+  COUNT(*) AS requests,
+  COUNT(DISTINCT ip_address) AS unique_ips
+FROM core_api_token_audit_logs
+WHERE token_id = $1
+  AND action = 'USED'
+  AND timestamp >= $2
+  AND timestamp < $3
+GROUP BY day
+ORDER BY day
+`
+
+type GetAPITokenUsageByDayParams struct {
+	TokenID  uuid.UUID `json:"token_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetAPITokenUsageByDayRow struct {
+	Day       time.Time `json:"day"`
+	Requests  int64     `json:"requests"`
+	UniqueIps int64     `json:"unique_ips"`
+}
+
+func (q *Queries) GetAPITokenUsageByDay(ctx context.Context, arg GetAPITokenUsageByDayParams) ([]GetAPITokenUsageByDayRow, error) {
+	rows, err := q.db.Query(ctx, getAPITokenUsageByDay, arg.TokenID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAPITokenUsageByDayRow{}
+	for rows.Next() {
+		var i GetAPITokenUsageByDayRow
+		if err := rows.Scan(&i.Day, &i.Requests, &i.UniqueIps); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAPITokenUsageSummary = `-- name: GetAPITokenUsageSummary :one
+SELECT
+  COUNT(*) AS total_requests,
+  COUNT(DISTINCT ip_address) AS unique_ips
+FROM core_api_token_audit_logs
+WHERE token_id = $1
+  AND action = 'USED'
+  AND timestamp >= $2
+  AND timestamp < $3
+`
+
+type GetAPITokenUsageSummaryParams struct {
+	TokenID  uuid.UUID `json:"token_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetAPITokenUsageSummaryRow struct {
+	TotalRequests int64 `json:"total_requests"`
+	UniqueIps     int64 `json:"unique_ips"`
+}
+
+func (q *Queries) GetAPITokenUsageSummary(ctx context.Context, arg GetAPITokenUsageSummaryParams) (GetAPITokenUsageSummaryRow, error) {
+	row := q.db.QueryRow(ctx, getAPITokenUsageSummary, arg.TokenID, arg.FromTime, arg.ToTime)
+	var i GetAPITokenUsageSummaryRow
+	err := row.Scan(&i.TotalRequests, &i.UniqueIps)
+	return i, err
+}
+
+const getAPITokensByPrefix = `-- name: GetAPITokensByPrefix :many
+SELECT t.id, t.client_application_id, t.name, t.description, t.token_hash, t.token_prefix, t.expires_at, t.revoked, t.revoked_at, t.revoked_reason, t.revoked_by, t.created_by, t.scopes, t.created_at, t.updated_at, t.last_used_at, t.last_used_ip, t.rate_limit_per_minute, t.rate_limit_per_hour, t.allowed_cidrs, t.hash_version, t.scope_template_id, c.tenant_id, c.service_account_id
 FROM core_api_tokens t
 JOIN core_client_applications c ON t.client_application_id = c.id
-WHERE t.id = $1
-  AND (
-    ($2::varchar IS NULL AND (c.tenant_id IS NULL OR c.tenant_id = ''))
-    OR c.tenant_id = $2::varchar
-  )
+WHERE t.token_prefix = $1 
   AND t.revoked = false
-LIMIT 1
+  AND t.expires_at > NOW()
+  AND c.active = true
 `
 
-type GetAPITokenByIDParams struct {
-	ID       uuid.UUID   `json:"id"`
-	TenantID pgtype.Text `json:"tenant_id"`
-}
-
-type GetAPITokenByIDRow struct {
+type GetAPITokensByPrefixRow struct {
 	ID                  uuid.UUID          `json:"id"`
 	ClientApplicationID uuid.UUID          `json:"client_application_id"`
 	Name                string             `json:"name"`
@@ -253,37 +629,163 @@ type GetAPITokenByIDRow struct {
 	UpdatedAt           time.Time          `json:"updated_at"`
 	LastUsedAt          pgtype.Timestamptz `json:"last_used_at"`
 	LastUsedIp          pgtype.Text        `json:"last_used_ip"`
+	RateLimitPerMinute  pgtype.Int4        `json:"rate_limit_per_minute"`
+	RateLimitPerHour    pgtype.Int4        `json:"rate_limit_per_hour"`
+	AllowedCidrs        []string           `json:"allowed_cidrs"`
+	HashVersion         int16              `json:"hash_version"`
+	ScopeTemplateID     pgtype.UUID        `json:"scope_template_id"`
 	TenantID            pgtype.Text        `json:"tenant_id"`
+	ServiceAccountID    pgtype.Text        `json:"service_account_id"`
 }
 
-func (q *Queries) GetAPITokenByID(ctx context.Context, arg GetAPITokenByIDParams) (GetAPITokenByIDRow, error) {
-	row := q.db.QueryRow(ctx, getAPITokenByID, arg.ID, arg.TenantID)
-	var i GetAPITokenByIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.ClientApplicationID,
-		&i.Name,
-		&i.Description,
-		&i.TokenHash,
-		&i.TokenPrefix,
-		&i.ExpiresAt,
-		&i.Revoked,
-		&i.RevokedAt,
-		&i.RevokedReason,
-		&i.RevokedBy,
-		&i.CreatedBy,
-		&i.Scopes,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.LastUsedAt,
-		&i.LastUsedIp,
-		&i.TenantID,
+// Returns the usable tokens sharing a display prefix. The caller verifies the
+// token against each hash, since salted hashes cannot be looked up directly.
+// The tokens of a deactivated application are unusable until it is activated.
+func (q *Queries) GetAPITokensByPrefix(ctx context.Context, tokenPrefix string) ([]GetAPITokensByPrefixRow, error) {
+	rows, err := q.db.Query(ctx, getAPITokensByPrefix, tokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAPITokensByPrefixRow{}
+	for rows.Next() {
+		var i GetAPITokensByPrefixRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClientApplicationID,
+			&i.Name,
+			&i.Description,
+			&i.TokenHash,
+			&i.TokenPrefix,
+			&i.ExpiresAt,
+			&i.Revoked,
+			&i.RevokedAt,
+			&i.RevokedReason,
+			&i.RevokedBy,
+			&i.CreatedBy,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			&i.RateLimitPerMinute,
+			&i.RateLimitPerHour,
+			&i.AllowedCidrs,
+			&i.HashVersion,
+			&i.ScopeTemplateID,
+			&i.TenantID,
+			&i.ServiceAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnusableAPITokensByPrefix = `-- name: GetUnusableAPITokensByPrefix :many
+SELECT t.id, t.token_hash, t.hash_version, t.revoked, t.expires_at, c.active, c.tenant_id
+FROM core_api_tokens t
+JOIN core_client_applications c ON t.client_application_id = c.id
+WHERE t.token_prefix = $1
+  AND (t.revoked = true OR t.expires_at <= NOW() OR c.active = false)
+`
+
+type GetUnusableAPITokensByPrefixRow struct {
+	ID          uuid.UUID   `json:"id"`
+	TokenHash   []byte      `json:"token_hash"`
+	HashVersion int16       `json:"hash_version"`
+	Revoked     bool        `json:"revoked"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	Active      bool        `json:"active"`
+	TenantID    pgtype.Text `json:"tenant_id"`
+}
+
+// Returns the revoked, expired or deactivated tokens sharing a display prefix,
+// to tell why a token was refused
+func (q *Queries) GetUnusableAPITokensByPrefix(ctx context.Context, tokenPrefix string) ([]GetUnusableAPITokensByPrefixRow, error) {
+	rows, err := q.db.Query(ctx, getUnusableAPITokensByPrefix, tokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUnusableAPITokensByPrefixRow{}
+	for rows.Next() {
+		var i GetUnusableAPITokensByPrefixRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TokenHash,
+			&i.HashVersion,
+			&i.Revoked,
+			&i.ExpiresAt,
+			&i.Active,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPITokenAuditLogsAfter = `-- name: ListAPITokenAuditLogsAfter :many
+SELECT id, token_id, action, ip_address, user_agent, timestamp, additional_data FROM core_api_token_audit_logs
+WHERE token_id = $1
+  AND (timestamp, id) > ($2::timestamptz, $3::uuid)
+ORDER BY timestamp, id
+LIMIT $4
+`
+
+type ListAPITokenAuditLogsAfterParams struct {
+	TokenID        uuid.UUID `json:"token_id"`
+	AfterTimestamp time.Time `json:"after_timestamp"`
+	AfterID        uuid.UUID `json:"after_id"`
+	BatchSize      int32     `json:"batch_size"`
+}
+
+// Keyset page of the audit logs of a token, oldest first, so an export is not
+// shifted by the entries written meanwhile
+func (q *Queries) ListAPITokenAuditLogsAfter(ctx context.Context, arg ListAPITokenAuditLogsAfterParams) ([]CoreApiTokenAuditLog, error) {
+	rows, err := q.db.Query(ctx, listAPITokenAuditLogsAfter,
+		arg.TokenID,
+		arg.AfterTimestamp,
+		arg.AfterID,
+		arg.BatchSize,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CoreApiTokenAuditLog{}
+	for rows.Next() {
+		var i CoreApiTokenAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TokenID,
+			&i.Action,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Timestamp,
+			&i.AdditionalData,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const listAPITokens = `-- name: ListAPITokens :many
-SELECT t.id, t.client_application_id, t.name, t.description, t.token_hash, t.token_prefix, t.expires_at, t.revoked, t.revoked_at, t.revoked_reason, t.revoked_by, t.created_by, t.scopes, t.created_at, t.updated_at, t.last_used_at, t.last_used_ip, c.name as application_name 
+SELECT t.id, t.client_application_id, t.name, t.description, t.token_hash, t.token_prefix, t.expires_at, t.revoked, t.revoked_at, t.revoked_reason, t.revoked_by, t.created_by, t.scopes, t.created_at, t.updated_at, t.last_used_at, t.last_used_ip, t.rate_limit_per_minute, t.rate_limit_per_hour, t.allowed_cidrs, t.hash_version, t.scope_template_id, c.name as application_name 
 FROM core_api_tokens t
 JOIN core_client_applications c ON t.client_application_id = c.id
 WHERE (
@@ -343,6 +845,11 @@ type ListAPITokensRow struct {
 	UpdatedAt           time.Time          `json:"updated_at"`
 	LastUsedAt          pgtype.Timestamptz `json:"last_used_at"`
 	LastUsedIp          pgtype.Text        `json:"last_used_ip"`
+	RateLimitPerMinute  pgtype.Int4        `json:"rate_limit_per_minute"`
+	RateLimitPerHour    pgtype.Int4        `json:"rate_limit_per_hour"`
+	AllowedCidrs        []string           `json:"allowed_cidrs"`
+	HashVersion         int16              `json:"hash_version"`
+	ScopeTemplateID     pgtype.UUID        `json:"scope_template_id"`
 	ApplicationName     string             `json:"application_name"`
 }
 
@@ -383,6 +890,11 @@ func (q *Queries) ListAPITokens(ctx context.Context, arg ListAPITokensParams) ([
 			&i.UpdatedAt,
 			&i.LastUsedAt,
 			&i.LastUsedIp,
+			&i.RateLimitPerMinute,
+			&i.RateLimitPerHour,
+			&i.AllowedCidrs,
+			&i.HashVersion,
+			&i.ScopeTemplateID,
 			&i.ApplicationName,
 		); err != nil {
 			return nil, err
@@ -395,6 +907,157 @@ func (q *Queries) ListAPITokens(ctx context.Context, arg ListAPITokensParams) ([
 	return items, nil
 }
 
+const listAPITokensCreatedBy = `-- name: ListAPITokensCreatedBy :many
+SELECT t.id, t.name, COALESCE(a.tenant_id, '')::varchar AS tenant_id
+FROM core_api_tokens t
+JOIN core_client_applications a ON a.id = t.client_application_id
+WHERE t.created_by = $1
+  AND t.revoked = false
+ORDER BY t.created_at
+`
+
+type ListAPITokensCreatedByRow struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	TenantID string    `json:"tenant_id"`
+}
+
+// Lists the unrevoked tokens owned by the user with the tenant of their application
+func (q *Queries) ListAPITokensCreatedBy(ctx context.Context, createdBy string) ([]ListAPITokensCreatedByRow, error) {
+	rows, err := q.db.Query(ctx, listAPITokensCreatedBy, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAPITokensCreatedByRow{}
+	for rows.Next() {
+		var i ListAPITokensCreatedByRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.TenantID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPITokensExpiringWithoutWarning = `-- name: ListAPITokensExpiringWithoutWarning :many
+SELECT t.id, t.name, t.token_prefix, t.expires_at, t.created_by, t.client_application_id,
+  a.name AS client_application_name, a.tenant_id, u.email AS creator_email
+FROM core_api_tokens t
+JOIN core_client_applications a ON a.id = t.client_application_id
+LEFT JOIN core_users u ON u.id = t.created_by
+WHERE t.revoked = false
+  AND t.expires_at > clock_timestamp()
+  AND t.expires_at <= $1
+  AND NOT EXISTS (
+    SELECT 1 FROM core_api_token_audit_logs l
+    WHERE l.token_id = t.id AND l.action = 'EXPIRY_WARNING'
+  )
+ORDER BY t.expires_at
+LIMIT $2
+`
+
+type ListAPITokensExpiringWithoutWarningParams struct {
+	ExpiringBefore time.Time `json:"expiring_before"`
+	BatchSize      int32     `json:"batch_size"`
+}
+
+type ListAPITokensExpiringWithoutWarningRow struct {
+	ID                    uuid.UUID   `json:"id"`
+	Name                  string      `json:"name"`
+	TokenPrefix           string      `json:"token_prefix"`
+	ExpiresAt             time.Time   `json:"expires_at"`
+	CreatedBy             string      `json:"created_by"`
+	ClientApplicationID   uuid.UUID   `json:"client_application_id"`
+	ClientApplicationName string      `json:"client_application_name"`
+	TenantID              pgtype.Text `json:"tenant_id"`
+	CreatorEmail          pgtype.Text `json:"creator_email"`
+}
+
+func (q *Queries) ListAPITokensExpiringWithoutWarning(ctx context.Context, arg ListAPITokensExpiringWithoutWarningParams) ([]ListAPITokensExpiringWithoutWarningRow, error) {
+	rows, err := q.db.Query(ctx, listAPITokensExpiringWithoutWarning, arg.ExpiringBefore, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAPITokensExpiringWithoutWarningRow{}
+	for rows.Next() {
+		var i ListAPITokensExpiringWithoutWarningRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TokenPrefix,
+			&i.ExpiresAt,
+			&i.CreatedBy,
+			&i.ClientApplicationID,
+			&i.ClientApplicationName,
+			&i.TenantID,
+			&i.CreatorEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredAPITokensWithoutNotice = `-- name: ListExpiredAPITokensWithoutNotice :many
+SELECT t.id, t.name, t.token_prefix, t.expires_at, t.client_application_id
+FROM core_api_tokens t
+JOIN core_client_application_webhooks w ON w.client_application_id = t.client_application_id
+WHERE t.revoked = false
+  AND t.expires_at <= clock_timestamp()
+  AND t.expires_at > w.created_at
+  AND NOT EXISTS (
+    SELECT 1 FROM core_api_token_audit_logs l
+    WHERE l.token_id = t.id AND l.action = 'EXPIRED'
+  )
+ORDER BY t.expires_at
+LIMIT $1
+`
+
+type ListExpiredAPITokensWithoutNoticeRow struct {
+	ID                  uuid.UUID `json:"id"`
+	Name                string    `json:"name"`
+	TokenPrefix         string    `json:"token_prefix"`
+	ExpiresAt           time.Time `json:"expires_at"`
+	ClientApplicationID uuid.UUID `json:"client_application_id"`
+}
+
+// Lists the tokens that expired after the webhook of their application was
+// configured and were not notified yet
+func (q *Queries) ListExpiredAPITokensWithoutNotice(ctx context.Context, batchSize int32) ([]ListExpiredAPITokensWithoutNoticeRow, error) {
+	rows, err := q.db.Query(ctx, listExpiredAPITokensWithoutNotice, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListExpiredAPITokensWithoutNoticeRow{}
+	for rows.Next() {
+		var i ListExpiredAPITokensWithoutNoticeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TokenPrefix,
+			&i.ExpiresAt,
+			&i.ClientApplicationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const revokeAPIToken = `-- name: RevokeAPIToken :one
 UPDATE core_api_tokens
 SET 
@@ -403,7 +1066,7 @@ SET
   revoked_reason = $2,
   revoked_by = $3
 WHERE id = $1
-RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip
+RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip, rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs, hash_version, scope_template_id
 `
 
 type RevokeAPITokenParams struct {
@@ -433,10 +1096,97 @@ func (q *Queries) RevokeAPIToken(ctx context.Context, arg RevokeAPITokenParams)
 		&i.UpdatedAt,
 		&i.LastUsedAt,
 		&i.LastUsedIp,
+		&i.RateLimitPerMinute,
+		&i.RateLimitPerHour,
+		&i.AllowedCidrs,
+		&i.HashVersion,
+		&i.ScopeTemplateID,
 	)
 	return i, err
 }
 
+const revokeActiveAPITokens = `-- name: RevokeActiveAPITokens :many
+UPDATE core_api_tokens t
+SET
+  revoked = true,
+  revoked_at = NOW(),
+  revoked_reason = $1,
+  revoked_by = $2
+FROM core_client_applications c
+WHERE t.client_application_id = c.id
+  AND ($3::uuid IS NULL OR t.client_application_id = $3::uuid)
+  AND (
+    ($4::varchar IS NULL AND (c.tenant_id IS NULL OR c.tenant_id = ''))
+    OR c.tenant_id = $4::varchar
+  )
+  AND t.revoked = false
+  AND t.expires_at > NOW()
+RETURNING t.id, t.client_application_id
+`
+
+type RevokeActiveAPITokensParams struct {
+	RevokedReason       pgtype.Text `json:"revoked_reason"`
+	RevokedBy           pgtype.Text `json:"revoked_by"`
+	ClientApplicationID pgtype.UUID `json:"client_application_id"`
+	TenantID            pgtype.Text `json:"tenant_id"`
+}
+
+type RevokeActiveAPITokensRow struct {
+	ID                  uuid.UUID `json:"id"`
+	ClientApplicationID uuid.UUID `json:"client_application_id"`
+}
+
+// Revokes the active tokens of every application in the tenant scope, or of a
+// single application when client_application_id is set
+func (q *Queries) RevokeActiveAPITokens(ctx context.Context, arg RevokeActiveAPITokensParams) ([]RevokeActiveAPITokensRow, error) {
+	rows, err := q.db.Query(ctx, revokeActiveAPITokens,
+		arg.RevokedReason,
+		arg.RevokedBy,
+		arg.ClientApplicationID,
+		arg.TenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RevokeActiveAPITokensRow{}
+	for rows.Next() {
+		var i RevokeActiveAPITokensRow
+		if err := rows.Scan(&i.ID, &i.ClientApplicationID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const transferAPITokensOwnership = `-- name: TransferAPITokensOwnership :execrows
+UPDATE core_api_tokens t
+SET created_by = $1
+FROM core_client_applications a
+WHERE a.id = t.client_application_id
+  AND t.created_by = $2
+  AND t.revoked = false
+  AND COALESCE(a.tenant_id, '') = $3::varchar
+`
+
+type TransferAPITokensOwnershipParams struct {
+	ToUserID   string `json:"to_user_id"`
+	FromUserID string `json:"from_user_id"`
+	TenantID   string `json:"tenant_id"`
+}
+
+func (q *Queries) TransferAPITokensOwnership(ctx context.Context, arg TransferAPITokensOwnershipParams) (int64, error) {
+	result, err := q.db.Exec(ctx, transferAPITokensOwnership, arg.ToUserID, arg.FromUserID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateAPIToken = `-- name: UpdateAPIToken :one
 UPDATE core_api_tokens
 SET 
@@ -445,7 +1195,7 @@ SET
   expires_at = $4,
   scopes = $5
 WHERE id = $1
-RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip
+RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip, rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs, hash_version, scope_template_id
 `
 
 type UpdateAPITokenParams struct {
@@ -483,10 +1233,83 @@ func (q *Queries) UpdateAPIToken(ctx context.Context, arg UpdateAPITokenParams)
 		&i.UpdatedAt,
 		&i.LastUsedAt,
 		&i.LastUsedIp,
+		&i.RateLimitPerMinute,
+		&i.RateLimitPerHour,
+		&i.AllowedCidrs,
+		&i.HashVersion,
+		&i.ScopeTemplateID,
 	)
 	return i, err
 }
 
+const updateAPITokenAllowedCIDRs = `-- name: UpdateAPITokenAllowedCIDRs :one
+UPDATE core_api_tokens
+SET allowed_cidrs = $2::text[]
+WHERE id = $1
+RETURNING id, client_application_id, name, description, token_hash, token_prefix, expires_at, revoked, revoked_at, revoked_reason, revoked_by, created_by, scopes, created_at, updated_at, last_used_at, last_used_ip, rate_limit_per_minute, rate_limit_per_hour, allowed_cidrs, hash_version, scope_template_id
+`
+
+type UpdateAPITokenAllowedCIDRsParams struct {
+	ID           uuid.UUID `json:"id"`
+	AllowedCidrs []string  `json:"allowed_cidrs"`
+}
+
+func (q *Queries) UpdateAPITokenAllowedCIDRs(ctx context.Context, arg UpdateAPITokenAllowedCIDRsParams) (CoreApiToken, error) {
+	row := q.db.QueryRow(ctx, updateAPITokenAllowedCIDRs, arg.ID, arg.AllowedCidrs)
+	var i CoreApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.ClientApplicationID,
+		&i.Name,
+		&i.Description,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.ExpiresAt,
+		&i.Revoked,
+		&i.RevokedAt,
+		&i.RevokedReason,
+		&i.RevokedBy,
+		&i.CreatedBy,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RateLimitPerMinute,
+		&i.RateLimitPerHour,
+		&i.AllowedCidrs,
+		&i.HashVersion,
+		&i.ScopeTemplateID,
+	)
+	return i, err
+}
+
+const updateAPITokenHash = `-- name: UpdateAPITokenHash :exec
+UPDATE core_api_tokens
+SET
+  token_hash = $2,
+  hash_version = $3
+WHERE id = $1 AND hash_version = $4
+`
+
+type UpdateAPITokenHashParams struct {
+	ID              uuid.UUID `json:"id"`
+	TokenHash       []byte    `json:"token_hash"`
+	HashVersion     int16     `json:"hash_version"`
+	PreviousVersion int16     `json:"previous_version"`
+}
+
+// Replaces a token hash computed with an older algorithm
+func (q *Queries) UpdateAPITokenHash(ctx context.Context, arg UpdateAPITokenHashParams) error {
+	_, err := q.db.Exec(ctx, updateAPITokenHash,
+		arg.ID,
+		arg.TokenHash,
+		arg.HashVersion,
+		arg.PreviousVersion,
+	)
+	return err
+}
+
 const updateAPITokenLastUsed = `-- name: UpdateAPITokenLastUsed :exec
 UPDATE core_api_tokens
 SET 
@@ -504,3 +1327,48 @@ func (q *Queries) UpdateAPITokenLastUsed(ctx context.Context, arg UpdateAPIToken
 	_, err := q.db.Exec(ctx, updateAPITokenLastUsed, arg.ID, arg.IpAddress)
 	return err
 }
+
+const updateAPITokenRateLimits = `-- name: UpdateAPITokenRateLimits :exec
+UPDATE core_api_tokens
+SET
+  rate_limit_per_minute = $2,
+  rate_limit_per_hour = $3
+WHERE id = $1
+`
+
+type UpdateAPITokenRateLimitsParams struct {
+	ID                 uuid.UUID   `json:"id"`
+	RateLimitPerMinute pgtype.Int4 `json:"rate_limit_per_minute"`
+	RateLimitPerHour   pgtype.Int4 `json:"rate_limit_per_hour"`
+}
+
+func (q *Queries) UpdateAPITokenRateLimits(ctx context.Context, arg UpdateAPITokenRateLimitsParams) error {
+	_, err := q.db.Exec(ctx, updateAPITokenRateLimits, arg.ID, arg.RateLimitPerMinute, arg.RateLimitPerHour)
+	return err
+}
+
+const updateAPITokensLastUsed = `-- name: UpdateAPITokensLastUsed :exec
+UPDATE core_api_tokens t
+SET
+  last_used_at = u.used_at,
+  last_used_ip = u.ip_address
+FROM unnest(
+  $1::uuid[],
+  $2::timestamptz[],
+  $3::varchar[]
+) AS u(id, used_at, ip_address)
+WHERE t.id = u.id
+  AND (t.last_used_at IS NULL OR t.last_used_at < u.used_at)
+`
+
+type UpdateAPITokensLastUsedParams struct {
+	Ids         []uuid.UUID `json:"ids"`
+	UsedAt      []time.Time `json:"used_at"`
+	IpAddresses []string    `json:"ip_addresses"`
+}
+
+// Applies a batch of last-used updates, never moving last_used_at backwards
+func (q *Queries) UpdateAPITokensLastUsed(ctx context.Context, arg UpdateAPITokensLastUsedParams) error {
+	_, err := q.db.Exec(ctx, updateAPITokensLastUsed, arg.Ids, arg.UsedAt, arg.IpAddresses)
+	return err
+}
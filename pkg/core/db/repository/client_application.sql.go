@@ -7,18 +7,66 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const activateClientApplication = `-- name: ActivateClientApplication :one
+UPDATE core_client_applications
+SET active = true
+WHERE id = $1 AND (
+    ($2::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = $2::varchar
+  )
+RETURNING id
+`
+
+type ActivateClientApplicationParams struct {
+	ID       uuid.UUID   `json:"id"`
+	TenantID pgtype.Text `json:"tenant_id"`
+}
+
+func (q *Queries) ActivateClientApplication(ctx context.Context, arg ActivateClientApplicationParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, activateClientApplication, arg.ID, arg.TenantID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const countClientApplications = `-- name: CountClientApplications :one
+SELECT COUNT(*)
+FROM core_client_applications
+WHERE (
+    ($1::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = $1::varchar
+  )
+  AND ($2::boolean OR active = true)
+  AND (UPPER(name) LIKE UPPER($3) OR $3 IS NULL)
+`
+
+type CountClientApplicationsParams struct {
+	TenantID        pgtype.Text `json:"tenant_id"`
+	IncludeInactive pgtype.Bool `json:"include_inactive"`
+	Like            interface{} `json:"like"`
+}
+
+// Counts the applications matched by the filters of ListClientApplications
+func (q *Queries) CountClientApplications(ctx context.Context, arg CountClientApplicationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countClientApplications, arg.TenantID, arg.IncludeInactive, arg.Like)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createClientApplication = `-- name: CreateClientApplication :one
 INSERT INTO core_client_applications (
   name, description, tenant_id, created_by
 ) VALUES (
   $1, $2, $4::varchar, $3
 )
-RETURNING id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at
+RETURNING id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at, max_active_tokens, service_account_id
 `
 
 type CreateClientApplicationParams struct {
@@ -46,6 +94,8 @@ func (q *Queries) CreateClientApplication(ctx context.Context, arg CreateClientA
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastUsedAt,
+		&i.MaxActiveTokens,
+		&i.ServiceAccountID,
 	)
 	return i, err
 }
@@ -94,7 +144,7 @@ func (q *Queries) DeleteClientApplication(ctx context.Context, arg DeleteClientA
 }
 
 const getClientApplicationByID = `-- name: GetClientApplicationByID :one
-SELECT id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at FROM core_client_applications
+SELECT id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at, max_active_tokens, service_account_id FROM core_client_applications
 WHERE id = $1 AND (
     ($2::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
     OR tenant_id = $2::varchar
@@ -120,12 +170,14 @@ func (q *Queries) GetClientApplicationByID(ctx context.Context, arg GetClientApp
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastUsedAt,
+		&i.MaxActiveTokens,
+		&i.ServiceAccountID,
 	)
 	return i, err
 }
 
 const listClientApplications = `-- name: ListClientApplications :many
-SELECT id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at
+SELECT id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at, max_active_tokens, service_account_id
 FROM core_client_applications
 WHERE (
     ($3::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
@@ -179,6 +231,8 @@ func (q *Queries) ListClientApplications(ctx context.Context, arg ListClientAppl
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastUsedAt,
+			&i.MaxActiveTokens,
+			&i.ServiceAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -190,6 +244,144 @@ func (q *Queries) ListClientApplications(ctx context.Context, arg ListClientAppl
 	return items, nil
 }
 
+const listClientApplicationsCreatedBy = `-- name: ListClientApplicationsCreatedBy :many
+SELECT id, name, COALESCE(tenant_id, '')::varchar AS tenant_id
+FROM core_client_applications
+WHERE created_by = $1
+ORDER BY created_at
+`
+
+type ListClientApplicationsCreatedByRow struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	TenantID string    `json:"tenant_id"`
+}
+
+// Lists the applications owned by the user, global ones with an empty tenant
+func (q *Queries) ListClientApplicationsCreatedBy(ctx context.Context, createdBy string) ([]ListClientApplicationsCreatedByRow, error) {
+	rows, err := q.db.Query(ctx, listClientApplicationsCreatedBy, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListClientApplicationsCreatedByRow{}
+	for rows.Next() {
+		var i ListClientApplicationsCreatedByRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.TenantID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockClientApplicationTokenQuota = `-- name: LockClientApplicationTokenQuota :one
+SELECT max_active_tokens FROM core_client_applications
+WHERE id = $1
+FOR UPDATE
+`
+
+// Locks the application while a token is created, so concurrent creations
+// cannot exceed its quota
+func (q *Queries) LockClientApplicationTokenQuota(ctx context.Context, id uuid.UUID) (pgtype.Int4, error) {
+	row := q.db.QueryRow(ctx, lockClientApplicationTokenQuota, id)
+	var max_active_tokens pgtype.Int4
+	err := row.Scan(&max_active_tokens)
+	return max_active_tokens, err
+}
+
+const setClientApplicationMaxActiveTokens = `-- name: SetClientApplicationMaxActiveTokens :one
+UPDATE core_client_applications
+SET max_active_tokens = $2
+WHERE id = $1
+RETURNING id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at, max_active_tokens, service_account_id
+`
+
+type SetClientApplicationMaxActiveTokensParams struct {
+	ID              uuid.UUID   `json:"id"`
+	MaxActiveTokens pgtype.Int4 `json:"max_active_tokens"`
+}
+
+func (q *Queries) SetClientApplicationMaxActiveTokens(ctx context.Context, arg SetClientApplicationMaxActiveTokensParams) (CoreClientApplication, error) {
+	row := q.db.QueryRow(ctx, setClientApplicationMaxActiveTokens, arg.ID, arg.MaxActiveTokens)
+	var i CoreClientApplication
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.TenantID,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.MaxActiveTokens,
+		&i.ServiceAccountID,
+	)
+	return i, err
+}
+
+const setClientApplicationServiceAccount = `-- name: SetClientApplicationServiceAccount :one
+UPDATE core_client_applications
+SET service_account_id = $2
+WHERE id = $1 AND (
+    ($3::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
+    OR tenant_id = $3::varchar
+  )
+RETURNING id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at, max_active_tokens, service_account_id
+`
+
+type SetClientApplicationServiceAccountParams struct {
+	ID               uuid.UUID   `json:"id"`
+	ServiceAccountID pgtype.Text `json:"service_account_id"`
+	TenantID         pgtype.Text `json:"tenant_id"`
+}
+
+// Links the application to its service account identity, or unlinks it with
+// a NULL service_account_id
+func (q *Queries) SetClientApplicationServiceAccount(ctx context.Context, arg SetClientApplicationServiceAccountParams) (CoreClientApplication, error) {
+	row := q.db.QueryRow(ctx, setClientApplicationServiceAccount, arg.ID, arg.ServiceAccountID, arg.TenantID)
+	var i CoreClientApplication
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.TenantID,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.MaxActiveTokens,
+		&i.ServiceAccountID,
+	)
+	return i, err
+}
+
+const transferClientApplicationsOwnership = `-- name: TransferClientApplicationsOwnership :execrows
+UPDATE core_client_applications
+SET created_by = $1
+WHERE created_by = $2
+  AND COALESCE(tenant_id, '') = $3::varchar
+`
+
+type TransferClientApplicationsOwnershipParams struct {
+	ToUserID   string `json:"to_user_id"`
+	FromUserID string `json:"from_user_id"`
+	TenantID   string `json:"tenant_id"`
+}
+
+func (q *Queries) TransferClientApplicationsOwnership(ctx context.Context, arg TransferClientApplicationsOwnershipParams) (int64, error) {
+	result, err := q.db.Exec(ctx, transferClientApplicationsOwnership, arg.ToUserID, arg.FromUserID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateClientApplication = `-- name: UpdateClientApplication :one
 UPDATE core_client_applications
 SET
@@ -200,7 +392,7 @@ WHERE id = $1 AND (
     ($5::varchar IS NULL AND (tenant_id IS NULL OR tenant_id = ''))
     OR tenant_id = $5::varchar
   )
-RETURNING id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at
+RETURNING id, name, description, tenant_id, active, created_by, created_at, updated_at, last_used_at, max_active_tokens, service_account_id
 `
 
 type UpdateClientApplicationParams struct {
@@ -230,6 +422,8 @@ func (q *Queries) UpdateClientApplication(ctx context.Context, arg UpdateClientA
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastUsedAt,
+		&i.MaxActiveTokens,
+		&i.ServiceAccountID,
 	)
 	return i, err
 }
@@ -244,3 +438,25 @@ func (q *Queries) UpdateClientApplicationLastUsed(ctx context.Context, id uuid.U
 	_, err := q.db.Exec(ctx, updateClientApplicationLastUsed, id)
 	return err
 }
+
+const updateClientApplicationsLastUsed = `-- name: UpdateClientApplicationsLastUsed :exec
+UPDATE core_client_applications a
+SET last_used_at = u.used_at
+FROM unnest(
+  $1::uuid[],
+  $2::timestamptz[]
+) AS u(id, used_at)
+WHERE a.id = u.id
+  AND (a.last_used_at IS NULL OR a.last_used_at < u.used_at)
+`
+
+type UpdateClientApplicationsLastUsedParams struct {
+	Ids    []uuid.UUID `json:"ids"`
+	UsedAt []time.Time `json:"used_at"`
+}
+
+// Applies a batch of last-used updates, never moving last_used_at backwards
+func (q *Queries) UpdateClientApplicationsLastUsed(ctx context.Context, arg UpdateClientApplicationsLastUsedParams) error {
+	_, err := q.db.Exec(ctx, updateClientApplicationsLastUsed, arg.Ids, arg.UsedAt)
+	return err
+}
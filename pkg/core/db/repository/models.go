@@ -12,6 +12,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CoreAnnouncement struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Severity  string    `json:"severity"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type CoreApiToken struct {
 	ID                  uuid.UUID          `json:"id"`
 	ClientApplicationID uuid.UUID          `json:"client_application_id"`
@@ -30,6 +43,11 @@ type CoreApiToken struct {
 	UpdatedAt           time.Time          `json:"updated_at"`
 	LastUsedAt          pgtype.Timestamptz `json:"last_used_at"`
 	LastUsedIp          pgtype.Text        `json:"last_used_ip"`
+	RateLimitPerMinute  pgtype.Int4        `json:"rate_limit_per_minute"`
+	RateLimitPerHour    pgtype.Int4        `json:"rate_limit_per_hour"`
+	AllowedCidrs        []string           `json:"allowed_cidrs"`
+	HashVersion         int16              `json:"hash_version"`
+	ScopeTemplateID     pgtype.UUID        `json:"scope_template_id"`
 }
 
 type CoreApiTokenAuditLog struct {
@@ -42,16 +60,117 @@ type CoreApiTokenAuditLog struct {
 	AdditionalData []byte      `json:"additional_data"`
 }
 
+type CoreAuthFailureCount struct {
+	TenantID string    `json:"tenant_id"`
+	Bucket   time.Time `json:"bucket"`
+	Reason   string    `json:"reason"`
+	Count    int64     `json:"count"`
+}
+
+type CoreAuthLockout struct {
+	SubjectType     string             `json:"subject_type"`
+	Subject         string             `json:"subject"`
+	TenantID        string             `json:"tenant_id"`
+	Failures        int32              `json:"failures"`
+	WindowStartedAt time.Time          `json:"window_started_at"`
+	LastReason      string             `json:"last_reason"`
+	LockedUntil     pgtype.Timestamptz `json:"locked_until"`
+	Disabled        bool               `json:"disabled"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
 type CoreClientApplication struct {
-	ID          uuid.UUID          `json:"id"`
-	Name        string             `json:"name"`
-	Description pgtype.Text        `json:"description"`
-	TenantID    pgtype.Text        `json:"tenant_id"`
-	Active      bool               `json:"active"`
-	CreatedBy   string             `json:"created_by"`
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
-	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	ID               uuid.UUID          `json:"id"`
+	Name             string             `json:"name"`
+	Description      pgtype.Text        `json:"description"`
+	TenantID         pgtype.Text        `json:"tenant_id"`
+	Active           bool               `json:"active"`
+	CreatedBy        string             `json:"created_by"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	LastUsedAt       pgtype.Timestamptz `json:"last_used_at"`
+	MaxActiveTokens  pgtype.Int4        `json:"max_active_tokens"`
+	ServiceAccountID pgtype.Text        `json:"service_account_id"`
+}
+
+type CoreClientApplicationSecret struct {
+	ID                  uuid.UUID   `json:"id"`
+	ClientApplicationID uuid.UUID   `json:"client_application_id"`
+	SecretHash          []byte      `json:"secret_hash"`
+	SecretPrefix        string      `json:"secret_prefix"`
+	Scopes              []string    `json:"scopes"`
+	CreatedBy           string      `json:"created_by"`
+	CreatedAt           time.Time   `json:"created_at"`
+	RateLimitPerMinute  pgtype.Int4 `json:"rate_limit_per_minute"`
+	RateLimitPerHour    pgtype.Int4 `json:"rate_limit_per_hour"`
+	AllowedCidrs        []string    `json:"allowed_cidrs"`
+}
+
+type CoreClientApplicationSigningKey struct {
+	ID                  uuid.UUID `json:"id"`
+	ClientApplicationID uuid.UUID `json:"client_application_id"`
+	Scopes              []string  `json:"scopes"`
+	CreatedBy           string    `json:"created_by"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type CoreClientApplicationWebhook struct {
+	ID                  uuid.UUID `json:"id"`
+	ClientApplicationID uuid.UUID `json:"client_application_id"`
+	Url                 string    `json:"url"`
+	CreatedBy           string    `json:"created_by"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type CoreDirectoryConnection struct {
+	TenantID           string             `json:"tenant_id"`
+	Provider           string             `json:"provider"`
+	Enabled            bool               `json:"enabled"`
+	Settings           []byte             `json:"settings"`
+	Credentials        string             `json:"credentials"`
+	RoleRules          []byte             `json:"role_rules"`
+	DefaultRoles       []string           `json:"default_roles"`
+	DeprovisionAction  string             `json:"deprovision_action"`
+	LastSyncStatus     pgtype.Text        `json:"last_sync_status"`
+	LastSyncStartedAt  pgtype.Timestamptz `json:"last_sync_started_at"`
+	LastSyncFinishedAt pgtype.Timestamptz `json:"last_sync_finished_at"`
+	LastSyncError      pgtype.Text        `json:"last_sync_error"`
+	LastSyncStats      []byte             `json:"last_sync_stats"`
+	CreatedBy          string             `json:"created_by"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+type CoreDirectorySyncConflict struct {
+	ID         uuid.UUID   `json:"id"`
+	TenantID   string      `json:"tenant_id"`
+	ExternalID string      `json:"external_id"`
+	Email      string      `json:"email"`
+	UserID     pgtype.Text `json:"user_id"`
+	Kind       string      `json:"kind"`
+	Detail     string      `json:"detail"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type CoreDirectoryUser struct {
+	TenantID   string    `json:"tenant_id"`
+	ExternalID string    `json:"external_id"`
+	UserID     string    `json:"user_id"`
+	Roles      []string  `json:"roles"`
+	Suspended  bool      `json:"suspended"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CoreEmailTemplate struct {
+	TenantID  string    `json:"tenant_id"`
+	EventType string    `json:"event_type"`
+	Locale    string    `json:"locale"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	UpdatedBy string    `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type CoreEmailVerificationToken struct {
@@ -66,6 +185,26 @@ type CoreEmailVerificationToken struct {
 	UpdatedAt time.Time          `json:"updated_at"`
 }
 
+type CoreExecutionWebhook struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	SourceType string    `json:"source_type"`
+	SourceID   string    `json:"source_id"`
+	Url        string    `json:"url"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CoreFeatureFlag struct {
+	Key               string      `json:"key"`
+	Description       pgtype.Text `json:"description"`
+	Enabled           bool        `json:"enabled"`
+	RolloutPercentage int32       `json:"rollout_percentage"`
+	UpdatedBy         string      `json:"updated_by"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+}
+
 type CoreGlobalConfig struct {
 	ID        uuid.UUID   `json:"id"`
 	Name      string      `json:"name"`
@@ -75,17 +214,139 @@ type CoreGlobalConfig struct {
 	UpdatedAt time.Time   `json:"updated_at"`
 }
 
+type CoreGroup struct {
+	ID          uuid.UUID   `json:"id"`
+	TenantID    string      `json:"tenant_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	Roles       []string    `json:"roles"`
+	CreatedBy   string      `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type CoreGroupMember struct {
+	GroupID uuid.UUID `json:"group_id"`
+	UserID  string    `json:"user_id"`
+	AddedBy string    `json:"added_by"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type CoreJob struct {
+	ID          uuid.UUID          `json:"id"`
+	TenantID    string             `json:"tenant_id"`
+	Kind        string             `json:"kind"`
+	Status      string             `json:"status"`
+	CreatedBy   string             `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+type CoreJobEvent struct {
+	ID          int64     `json:"id"`
+	JobID       uuid.UUID `json:"job_id"`
+	EventType   string    `json:"event_type"`
+	Message     string    `json:"message"`
+	Progress    int32     `json:"progress"`
+	DataType    string    `json:"data_type"`
+	DataVersion int32     `json:"data_version"`
+	Data        []byte    `json:"data"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CoreJobRowError struct {
+	ID      int64     `json:"id"`
+	JobID   uuid.UUID `json:"job_id"`
+	Line    int32     `json:"line"`
+	Email   string    `json:"email"`
+	Code    string    `json:"code"`
+	Message string    `json:"message"`
+}
+
+type CoreLlmConsentPolicy struct {
+	TenantID       string    `json:"tenant_id"`
+	StoreInputs    bool      `json:"store_inputs"`
+	StoreOutputs   bool      `json:"store_outputs"`
+	AllowAnalytics bool      `json:"allow_analytics"`
+	AllowedRegions []string  `json:"allowed_regions"`
+	UpdatedBy      string    `json:"updated_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
 type CoreMigration struct {
 	Version int64 `json:"version"`
 	Dirty   bool  `json:"dirty"`
 }
 
+type CorePermissionDelegation struct {
+	ID             uuid.UUID          `json:"id"`
+	TenantID       string             `json:"tenant_id"`
+	DelegatorID    string             `json:"delegator_id"`
+	DelegateID     string             `json:"delegate_id"`
+	Operations     []string           `json:"operations"`
+	DelegatorRoles []string           `json:"delegator_roles"`
+	Reason         pgtype.Text        `json:"reason"`
+	StartsAt       time.Time          `json:"starts_at"`
+	EndsAt         time.Time          `json:"ends_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	RevokedAt      pgtype.Timestamptz `json:"revoked_at"`
+	RevokedBy      pgtype.Text        `json:"revoked_by"`
+}
+
+type CoreReplayBundle struct {
+	ID        uuid.UUID   `json:"id"`
+	TenantID  string      `json:"tenant_id"`
+	RequestID string      `json:"request_id"`
+	UserID    pgtype.Text `json:"user_id"`
+	Method    string      `json:"method"`
+	Route     string      `json:"route"`
+	Status    int32       `json:"status"`
+	Bundle    []byte      `json:"bundle"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
 type CoreRole struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    string    `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Name      string    `json:"name"`
+	ID          uuid.UUID   `json:"id"`
+	UserID      string      `json:"user_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Name        string      `json:"name"`
+	TenantID    pgtype.Text `json:"tenant_id"`
+	Description pgtype.Text `json:"description"`
+}
+
+type CoreRoleMember struct {
+	RoleID     uuid.UUID `json:"role_id"`
+	UserID     string    `json:"user_id"`
+	AssignedBy string    `json:"assigned_by"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+type CoreRolePermission struct {
+	RoleID    uuid.UUID `json:"role_id"`
+	Operation string    `json:"operation"`
+}
+
+type CoreScopeTemplate struct {
+	ID          uuid.UUID          `json:"id"`
+	TenantID    pgtype.Text        `json:"tenant_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Scopes      []string           `json:"scopes"`
+	PropagateAt pgtype.Timestamptz `json:"propagate_at"`
+	CreatedBy   string             `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	UpdatedBy   pgtype.Text        `json:"updated_by"`
+}
+
+type CoreSlaEscalation struct {
+	ItemType     string    `json:"item_type"`
+	ItemID       string    `json:"item_id"`
+	PendingSince time.Time `json:"pending_since"`
+	TenantID     string    `json:"tenant_id"`
+	EscalatedAt  time.Time `json:"escalated_at"`
 }
 
 type CoreTenant struct {
@@ -105,6 +366,9 @@ type CoreTenant struct {
 	ContractEndDate     pgtype.Timestamptz              `json:"contract_end_date"`
 	IsDisabled          bool                            `json:"is_disabled"`
 	FeatureLicenses     subentity.TenantFeatureLicenses `json:"feature_licenses"`
+	Status              string                          `json:"status"`
+	StatusReason        pgtype.Text                     `json:"status_reason"`
+	StatusChangedAt     pgtype.Timestamptz              `json:"status_changed_at"`
 }
 
 type CoreTenantConfig struct {
@@ -117,6 +381,198 @@ type CoreTenantConfig struct {
 	UpdatedAt time.Time   `json:"updated_at"`
 }
 
+type CoreTenantEmailProvider struct {
+	TenantID        string      `json:"tenant_id"`
+	Provider        string      `json:"provider"`
+	Host            pgtype.Text `json:"host"`
+	Port            pgtype.Int4 `json:"port"`
+	Username        pgtype.Text `json:"username"`
+	EncryptedSecret string      `json:"encrypted_secret"`
+	FromAddress     string      `json:"from_address"`
+	ReplyTo         pgtype.Text `json:"reply_to"`
+	UpdatedBy       string      `json:"updated_by"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+type CoreTenantExport struct {
+	ID          uuid.UUID          `json:"id"`
+	TenantID    string             `json:"tenant_id"`
+	ScheduleID  pgtype.UUID        `json:"schedule_id"`
+	Dataset     string             `json:"dataset"`
+	Format      string             `json:"format"`
+	Destination string             `json:"destination"`
+	PeriodStart time.Time          `json:"period_start"`
+	PeriodEnd   time.Time          `json:"period_end"`
+	Status      string             `json:"status"`
+	FilePath    pgtype.Text        `json:"file_path"`
+	RowCount    int64              `json:"row_count"`
+	Error       pgtype.Text        `json:"error"`
+	RequestedBy string             `json:"requested_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+type CoreTenantExportSchedule struct {
+	ID          uuid.UUID `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Dataset     string    `json:"dataset"`
+	Format      string    `json:"format"`
+	Destination string    `json:"destination"`
+	Frequency   string    `json:"frequency"`
+	NextRunAt   time.Time `json:"next_run_at"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CoreTenantFeatureFlag struct {
+	TenantID  string    `json:"tenant_id"`
+	FlagKey   string    `json:"flag_key"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CoreTenantFile struct {
+	TenantID  string    `json:"tenant_id"`
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CoreTenantFileEncryption struct {
+	TenantID           string             `json:"tenant_id"`
+	EnabledBy          string             `json:"enabled_by"`
+	EnabledAt          time.Time          `json:"enabled_at"`
+	RewriteStatus      string             `json:"rewrite_status"`
+	RewriteRequestedAt time.Time          `json:"rewrite_requested_at"`
+	RewriteStartedAt   pgtype.Timestamptz `json:"rewrite_started_at"`
+	RewriteCompletedAt pgtype.Timestamptz `json:"rewrite_completed_at"`
+	FilesRewritten     int32              `json:"files_rewritten"`
+	LastError          pgtype.Text        `json:"last_error"`
+}
+
+type CoreTenantFileKey struct {
+	ID          uuid.UUID          `json:"id"`
+	TenantID    string             `json:"tenant_id"`
+	WrappedKey  []byte             `json:"wrapped_key"`
+	MasterKeyID string             `json:"master_key_id"`
+	Status      string             `json:"status"`
+	CreatedAt   time.Time          `json:"created_at"`
+	RotatedAt   pgtype.Timestamptz `json:"rotated_at"`
+	RetiredAt   pgtype.Timestamptz `json:"retired_at"`
+}
+
+type CoreTenantIsolationProbeResult struct {
+	ID               uuid.UUID `json:"id"`
+	RunID            uuid.UUID `json:"run_id"`
+	Probe            string    `json:"probe"`
+	AttackerTenantID string    `json:"attacker_tenant_id"`
+	VictimTenantID   string    `json:"victim_tenant_id"`
+	ResourceID       string    `json:"resource_id"`
+	Outcome          string    `json:"outcome"`
+	StatusCode       int32     `json:"status_code"`
+	Detail           string    `json:"detail"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type CoreTenantPromptExecution struct {
+	TenantID   string      `json:"tenant_id"`
+	Month      pgtype.Date `json:"month"`
+	Executions int32       `json:"executions"`
+}
+
+type CoreTenantPurge struct {
+	TenantUuid     uuid.UUID          `json:"tenant_uuid"`
+	TenantID       string             `json:"tenant_id"`
+	TenantName     string             `json:"tenant_name"`
+	Status         string             `json:"status"`
+	PreviousStatus string             `json:"previous_status"`
+	Reason         pgtype.Text        `json:"reason"`
+	RequestedBy    string             `json:"requested_by"`
+	PurgeAfter     time.Time          `json:"purge_after"`
+	Attempts       int32              `json:"attempts"`
+	Steps          []byte             `json:"steps"`
+	LastError      pgtype.Text        `json:"last_error"`
+	StartedAt      pgtype.Timestamptz `json:"started_at"`
+	CompletedAt    pgtype.Timestamptz `json:"completed_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type CoreTenantQuota struct {
+	TenantID                    string      `json:"tenant_id"`
+	MaxUsers                    pgtype.Int4 `json:"max_users"`
+	MaxClientApplications       pgtype.Int4 `json:"max_client_applications"`
+	MaxStorageMb                pgtype.Int4 `json:"max_storage_mb"`
+	MaxPromptExecutionsPerMonth pgtype.Int4 `json:"max_prompt_executions_per_month"`
+	UpdatedBy                   string      `json:"updated_by"`
+	CreatedAt                   time.Time   `json:"created_at"`
+	UpdatedAt                   time.Time   `json:"updated_at"`
+}
+
+type CoreTenantSandbox struct {
+	TenantID        string             `json:"tenant_id"`
+	Template        []byte             `json:"template"`
+	CreatedBy       string             `json:"created_by"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	ResetStartedAt  pgtype.Timestamptz `json:"reset_started_at"`
+	LastResetAt     pgtype.Timestamptz `json:"last_reset_at"`
+	LastResetBy     pgtype.Text        `json:"last_reset_by"`
+	LastResetJobID  pgtype.UUID        `json:"last_reset_job_id"`
+	LastResetStatus pgtype.Text        `json:"last_reset_status"`
+}
+
+type CoreTenantSettingValue struct {
+	TenantID  string    `json:"tenant_id"`
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CoreTenantSubdomainAlias struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Subdomain string    `json:"subdomain"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CoreTenantTemplate struct {
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	TenantID    string      `json:"tenant_id"`
+	CreatedBy   string      `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type CoreTenantUsageCount struct {
+	TenantID string    `json:"tenant_id"`
+	Bucket   time.Time `json:"bucket"`
+	Metric   string    `json:"metric"`
+	Count    int64     `json:"count"`
+}
+
+type CoreTenantUsageNotification struct {
+	TenantID   string    `json:"tenant_id"`
+	Resource   string    `json:"resource"`
+	Severity   string    `json:"severity"`
+	NotifiedAt time.Time `json:"notified_at"`
+}
+
+type CoreTokenPolicy struct {
+	TenantID           string      `json:"tenant_id"`
+	MaxExpiryDays      pgtype.Int4 `json:"max_expiry_days"`
+	AllowedScopes      []string    `json:"allowed_scopes"`
+	RequireDescription bool        `json:"require_description"`
+	UpdatedBy          string      `json:"updated_by"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+}
+
 type CoreTranslation struct {
 	ID         uuid.UUID `json:"id"`
 	EntityType string    `json:"entity_type"`
@@ -130,12 +586,155 @@ type CoreTranslation struct {
 }
 
 type CoreUser struct {
-	ID        string                `json:"id"`
-	Profile   subentity.UserProfile `json:"profile"`
-	Email     pgtype.Text           `json:"email"`
-	CreatedAt time.Time             `json:"created_at"`
-	TenantID  pgtype.Text           `json:"tenant_id"`
-	Roles     []string              `json:"roles"`
+	ID              string                `json:"id"`
+	Profile         subentity.UserProfile `json:"profile"`
+	Email           pgtype.Text           `json:"email"`
+	CreatedAt       time.Time             `json:"created_at"`
+	TenantID        pgtype.Text           `json:"tenant_id"`
+	Roles           []string              `json:"roles"`
+	EmailBlindIndex pgtype.Text           `json:"email_blind_index"`
+}
+
+type CoreUserAccountState struct {
+	UserID        string    `json:"user_id"`
+	Disabled      bool      `json:"disabled"`
+	EmailVerified bool      `json:"email_verified"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type CoreUserActivityEvent struct {
+	ID         uuid.UUID   `json:"id"`
+	TenantID   string      `json:"tenant_id"`
+	UserID     string      `json:"user_id"`
+	Category   string      `json:"category"`
+	EventType  string      `json:"event_type"`
+	ActorID    pgtype.Text `json:"actor_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       []byte      `json:"data"`
+}
+
+type CoreUserAttribute struct {
+	TenantID   string    `json:"tenant_id"`
+	Key        string    `json:"key"`
+	Label      string    `json:"label"`
+	Type       string    `json:"type"`
+	Required   bool      `json:"required"`
+	EnumValues []string  `json:"enum_values"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CoreUserAuditLog struct {
+	ID         uuid.UUID   `json:"id"`
+	TenantID   string      `json:"tenant_id"`
+	UserID     string      `json:"user_id"`
+	Action     string      `json:"action"`
+	ActorID    pgtype.Text `json:"actor_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Details    []byte      `json:"details"`
+}
+
+type CoreUserDeletionRequest struct {
+	ID           uuid.UUID          `json:"id"`
+	TenantID     string             `json:"tenant_id"`
+	UserID       string             `json:"user_id"`
+	TokenHash    []byte             `json:"token_hash"`
+	Status       string             `json:"status"`
+	ScheduledFor time.Time          `json:"scheduled_for"`
+	CancelledAt  pgtype.Timestamptz `json:"cancelled_at"`
+	LastError    pgtype.Text        `json:"last_error"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type CoreUserEmailChange struct {
+	ID              uuid.UUID          `json:"id"`
+	TenantID        string             `json:"tenant_id"`
+	UserID          string             `json:"user_id"`
+	OldEmail        string             `json:"old_email"`
+	NewEmail        string             `json:"new_email"`
+	TokenHash       []byte             `json:"token_hash"`
+	Status          string             `json:"status"`
+	ExpiresAt       time.Time          `json:"expires_at"`
+	RevertTokenHash []byte             `json:"revert_token_hash"`
+	RevertExpiresAt pgtype.Timestamptz `json:"revert_expires_at"`
+	ConfirmedAt     pgtype.Timestamptz `json:"confirmed_at"`
+	RevertedAt      pgtype.Timestamptz `json:"reverted_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+type CoreUserImpersonation struct {
+	ID        uuid.UUID          `json:"id"`
+	TokenHash []byte             `json:"token_hash"`
+	ActorID   string             `json:"actor_id"`
+	TenantID  string             `json:"tenant_id"`
+	UserID    string             `json:"user_id"`
+	Reason    string             `json:"reason"`
+	CreatedAt time.Time          `json:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	EndedAt   pgtype.Timestamptz `json:"ended_at"`
+}
+
+type CoreUserInvitation struct {
+	ID              uuid.UUID          `json:"id"`
+	TenantID        string             `json:"tenant_id"`
+	Email           string             `json:"email"`
+	EmailBlindIndex pgtype.Text        `json:"email_blind_index"`
+	Roles           []string           `json:"roles"`
+	TokenHash       []byte             `json:"token_hash"`
+	Status          string             `json:"status"`
+	InvitedBy       string             `json:"invited_by"`
+	UserID          pgtype.Text        `json:"user_id"`
+	ExpiresAt       time.Time          `json:"expires_at"`
+	SentAt          time.Time          `json:"sent_at"`
+	SendCount       int32              `json:"send_count"`
+	AcceptedAt      pgtype.Timestamptz `json:"accepted_at"`
+	RevokedAt       pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+type CoreUserLogin struct {
+	UserID      string      `json:"user_id"`
+	TenantID    string      `json:"tenant_id"`
+	SessionID   string      `json:"session_id"`
+	LastLoginAt time.Time   `json:"last_login_at"`
+	LastLoginIp pgtype.Text `json:"last_login_ip"`
+}
+
+type CoreUserMfa struct {
+	UserID             string             `json:"user_id"`
+	SecretEncrypted    string             `json:"secret_encrypted"`
+	Enabled            bool               `json:"enabled"`
+	RecoveryCodeHashes []string           `json:"recovery_code_hashes"`
+	LastUsedStep       int64              `json:"last_used_step"`
+	FailedAttempts     int32              `json:"failed_attempts"`
+	LockedUntil        pgtype.Timestamptz `json:"locked_until"`
+	CreatedAt          time.Time          `json:"created_at"`
+	EnabledAt          pgtype.Timestamptz `json:"enabled_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+type CoreUserMfaSession struct {
+	TokenHash []byte    `json:"token_hash"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CoreUserOnboardingStep struct {
+	UserID      string    `json:"user_id"`
+	Step        string    `json:"step"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+type CoreUserPhoneVerification struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      string             `json:"user_id"`
+	PhoneNumber string             `json:"phone_number"`
+	CodeHash    []byte             `json:"code_hash"`
+	Attempts    int32              `json:"attempts"`
+	ExpiresAt   time.Time          `json:"expires_at"`
+	VerifiedAt  pgtype.Timestamptz `json:"verified_at"`
+	CreatedAt   time.Time          `json:"created_at"`
 }
 
 type CoreUserTenantMembership struct {
@@ -150,4 +749,22 @@ type CoreUserTenantMembership struct {
 	UpdatedAt       time.Time                       `json:"updated_at"`
 	Roles           []string                        `json:"roles"`
 	FeatureLicenses subentity.TenantFeatureLicenses `json:"feature_licenses"`
+	Labels          []string                        `json:"labels"`
+}
+
+type GooseDbVersion struct {
+	ID        int32            `json:"id"`
+	VersionID int64            `json:"version_id"`
+	IsApplied bool             `json:"is_applied"`
+	Tstamp    pgtype.Timestamp `json:"tstamp"`
+}
+
+type PgStatStatement struct {
+	Userid        uint32  `json:"userid"`
+	Dbid          uint32  `json:"dbid"`
+	Queryid       int64   `json:"queryid"`
+	Query         string  `json:"query"`
+	Calls         int64   `json:"calls"`
+	TotalExecTime float64 `json:"total_exec_time"`
+	MeanExecTime  float64 `json:"mean_exec_time"`
 }
@@ -19,7 +19,7 @@ INSERT INTO core_tenants (
 ) VALUES (
   $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
-RETURNING id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses
+RETURNING id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at
 `
 
 type CreateTenantParams struct {
@@ -66,6 +66,9 @@ func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) (Cor
 		&i.ContractEndDate,
 		&i.IsDisabled,
 		&i.FeatureLicenses,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -103,7 +106,7 @@ func (q *Queries) EnableTenant(ctx context.Context, tenantID string) error {
 }
 
 const getExpiredEnabledTenants = `-- name: GetExpiredEnabledTenants :many
-SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses FROM core_tenants
+SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at FROM core_tenants
 WHERE contract_end_date IS NOT NULL
   AND contract_end_date < NOW()
   AND is_disabled = false
@@ -135,6 +138,9 @@ func (q *Queries) GetExpiredEnabledTenants(ctx context.Context) ([]CoreTenant, e
 			&i.ContractEndDate,
 			&i.IsDisabled,
 			&i.FeatureLicenses,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -147,7 +153,7 @@ func (q *Queries) GetExpiredEnabledTenants(ctx context.Context) ([]CoreTenant, e
 }
 
 const getTenantByID = `-- name: GetTenantByID :one
-SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses FROM core_tenants
+SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at FROM core_tenants
 WHERE id = $1 LIMIT 1
 `
 
@@ -171,12 +177,15 @@ func (q *Queries) GetTenantByID(ctx context.Context, id uuid.UUID) (CoreTenant,
 		&i.ContractEndDate,
 		&i.IsDisabled,
 		&i.FeatureLicenses,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
 const getTenantBySubdomain = `-- name: GetTenantBySubdomain :one
-SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses FROM core_tenants
+SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at FROM core_tenants
 WHERE subdomain = $1 LIMIT 1
 `
 
@@ -200,12 +209,53 @@ func (q *Queries) GetTenantBySubdomain(ctx context.Context, subdomain string) (C
 		&i.ContractEndDate,
 		&i.IsDisabled,
 		&i.FeatureLicenses,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const getTenantBySubdomainOrAlias = `-- name: GetTenantBySubdomainOrAlias :one
+SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at FROM core_tenants
+WHERE subdomain = $1
+  OR tenant_id = (
+    SELECT a.tenant_id FROM core_tenant_subdomain_aliases a
+    WHERE a.subdomain = $1
+  )
+LIMIT 1
+`
+
+// Resolves the primary subdomain of a tenant or one of its aliases
+func (q *Queries) GetTenantBySubdomainOrAlias(ctx context.Context, subdomain string) (CoreTenant, error) {
+	row := q.db.QueryRow(ctx, getTenantBySubdomainOrAlias, subdomain)
+	var i CoreTenant
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Subdomain,
+		&i.AllowPasswordSignUp,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Profile,
+		&i.Features,
+		&i.AllowSignUp,
+		&i.IsReseller,
+		&i.ResellerID,
+		&i.ContractEndDate,
+		&i.IsDisabled,
+		&i.FeatureLicenses,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
 const getTenantByTenantID = `-- name: GetTenantByTenantID :one
-SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses FROM core_tenants
+SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at FROM core_tenants
 WHERE tenant_id = $1 LIMIT 1
 `
 
@@ -229,6 +279,9 @@ func (q *Queries) GetTenantByTenantID(ctx context.Context, tenantID string) (Cor
 		&i.ContractEndDate,
 		&i.IsDisabled,
 		&i.FeatureLicenses,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -241,9 +294,10 @@ WITH reseller AS (
     AND t.is_reseller = true
     AND 'CUSTOMER_ADMIN' = ANY(utm.roles)
 )
-SELECT ct.id, ct.tenant_id, ct.name, ct.subdomain, ct.allow_password_sign_up, ct.user_id, ct.created_at, ct.updated_at, ct.profile, ct.features, ct.allow_sign_up, ct.is_reseller, ct.reseller_id, ct.contract_end_date, ct.is_disabled, ct.feature_licenses FROM core_tenants ct
-WHERE ct.tenant_id IN (SELECT tenant_id FROM reseller)
-   OR ct.reseller_id IN (SELECT tenant_id FROM reseller)
+SELECT ct.id, ct.tenant_id, ct.name, ct.subdomain, ct.allow_password_sign_up, ct.user_id, ct.created_at, ct.updated_at, ct.profile, ct.features, ct.allow_sign_up, ct.is_reseller, ct.reseller_id, ct.contract_end_date, ct.is_disabled, ct.feature_licenses, ct.status, ct.status_reason, ct.status_changed_at FROM core_tenants ct
+WHERE (ct.tenant_id IN (SELECT tenant_id FROM reseller)
+   OR ct.reseller_id IN (SELECT tenant_id FROM reseller))
+  AND ct.status <> 'archived'
 ORDER BY ct.name ASC
 `
 
@@ -273,6 +327,9 @@ func (q *Queries) ListResellerTenants(ctx context.Context, userID string) ([]Cor
 			&i.ContractEndDate,
 			&i.IsDisabled,
 			&i.FeatureLicenses,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -285,19 +342,20 @@ func (q *Queries) ListResellerTenants(ctx context.Context, userID string) ([]Cor
 }
 
 const listTenants = `-- name: ListTenants :many
-SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses FROM core_tenants
+SELECT id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at FROM core_tenants
 WHERE (UPPER(name) LIKE UPPER($3) OR $3 IS NULL)
 AND (reseller_id = $4 OR $4 IS NULL)
+AND (status = $5 OR ($5 IS NULL AND status <> 'archived'))
 ORDER BY
   CASE
-            WHEN $5::text = 'asc' and $6::text = 'tenant_id' THEN "tenant_id"
-            WHEN $5::text = 'asc' and $6::text = 'name' THEN "name"
-            WHEN $5::text = 'asc' and $6::text = 'subdomain' THEN "subdomain"
+            WHEN $6::text = 'asc' and $7::text = 'tenant_id' THEN "tenant_id"
+            WHEN $6::text = 'asc' and $7::text = 'name' THEN "name"
+            WHEN $6::text = 'asc' and $7::text = 'subdomain' THEN "subdomain"
         END ASC,
   CASE
-            WHEN (NOT $5::text = 'asc') and $6::text = 'tenant_id' THEN "tenant_id"
-            WHEN (NOT $5::text = 'asc') and $6::text = 'name' THEN "name"
-            WHEN (NOT $5::text = 'asc') and $6::text = 'subdomain' THEN "subdomain"
+            WHEN (NOT $6::text = 'asc') and $7::text = 'tenant_id' THEN "tenant_id"
+            WHEN (NOT $6::text = 'asc') and $7::text = 'name' THEN "name"
+            WHEN (NOT $6::text = 'asc') and $7::text = 'subdomain' THEN "subdomain"
         END DESC
 LIMIT $1
 OFFSET $2
@@ -308,6 +366,7 @@ type ListTenantsParams struct {
 	Offset     int32       `json:"offset"`
 	Like       interface{} `json:"like"`
 	ResellerID pgtype.Text `json:"reseller_id"`
+	Status     pgtype.Text `json:"status"`
 	Order      string      `json:"order"`
 	SortBy     string      `json:"sortBy"`
 }
@@ -318,6 +377,7 @@ func (q *Queries) ListTenants(ctx context.Context, arg ListTenantsParams) ([]Cor
 		arg.Offset,
 		arg.Like,
 		arg.ResellerID,
+		arg.Status,
 		arg.Order,
 		arg.SortBy,
 	)
@@ -345,6 +405,9 @@ func (q *Queries) ListTenants(ctx context.Context, arg ListTenantsParams) ([]Cor
 			&i.ContractEndDate,
 			&i.IsDisabled,
 			&i.FeatureLicenses,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -456,3 +519,69 @@ func (q *Queries) UpdateTenantProfile(ctx context.Context, arg UpdateTenantProfi
 	err := row.Scan(&id)
 	return id, err
 }
+
+const updateTenantStatus = `-- name: UpdateTenantStatus :one
+UPDATE core_tenants
+SET status = $1,
+    status_reason = $2,
+    status_changed_at = NOW(),
+    updated_at = NOW()
+WHERE tenant_id = $3
+  AND status = ANY($4::varchar[])
+RETURNING id, tenant_id, name, subdomain, allow_password_sign_up, user_id, created_at, updated_at, profile, features, allow_sign_up, is_reseller, reseller_id, contract_end_date, is_disabled, feature_licenses, status, status_reason, status_changed_at
+`
+
+type UpdateTenantStatusParams struct {
+	Status       string      `json:"status"`
+	StatusReason pgtype.Text `json:"status_reason"`
+	TenantID     string      `json:"tenant_id"`
+	FromStatuses []string    `json:"from_statuses"`
+}
+
+// Moves the tenant to the status when it is in one of the from statuses
+func (q *Queries) UpdateTenantStatus(ctx context.Context, arg UpdateTenantStatusParams) (CoreTenant, error) {
+	row := q.db.QueryRow(ctx, updateTenantStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.TenantID,
+		arg.FromStatuses,
+	)
+	var i CoreTenant
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Subdomain,
+		&i.AllowPasswordSignUp,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Profile,
+		&i.Features,
+		&i.AllowSignUp,
+		&i.IsReseller,
+		&i.ResellerID,
+		&i.ContractEndDate,
+		&i.IsDisabled,
+		&i.FeatureLicenses,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const updateTenantSubdomain = `-- name: UpdateTenantSubdomain :exec
+UPDATE core_tenants SET subdomain = $2, updated_at = NOW()
+WHERE tenant_id = $1
+`
+
+type UpdateTenantSubdomainParams struct {
+	TenantID  string `json:"tenant_id"`
+	Subdomain string `json:"subdomain"`
+}
+
+func (q *Queries) UpdateTenantSubdomain(ctx context.Context, arg UpdateTenantSubdomainParams) error {
+	_, err := q.db.Exec(ctx, updateTenantSubdomain, arg.TenantID, arg.Subdomain)
+	return err
+}
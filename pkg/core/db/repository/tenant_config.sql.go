@@ -116,6 +116,40 @@ func (q *Queries) GetTenantConfigByName(ctx context.Context, arg GetTenantConfig
 	return i, err
 }
 
+const listAllTenantConfigs = `-- name: ListAllTenantConfigs :many
+SELECT id, name, value, user_id, tenant_id, created_at, updated_at FROM core_tenant_configs
+WHERE tenant_id = $1::text
+ORDER BY name ASC
+`
+
+func (q *Queries) ListAllTenantConfigs(ctx context.Context, tenantID string) ([]CoreTenantConfig, error) {
+	rows, err := q.db.Query(ctx, listAllTenantConfigs, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CoreTenantConfig{}
+	for rows.Next() {
+		var i CoreTenantConfig
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Value,
+			&i.UserID,
+			&i.TenantID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTenantConfigs = `-- name: ListTenantConfigs :many
 SELECT id, name, value, user_id, tenant_id, created_at, updated_at FROM core_tenant_configs
 WHERE tenant_id = $3::text
@@ -9,23 +9,25 @@ import (
 	"context"
 
 	subentity "ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createUserByTenant = `-- name: CreateUserByTenant :one
 INSERT INTO core_users (
-  "id", "email", "profile", roles, "tenant_id"
+  "id", "email", "profile", roles, "tenant_id", email_blind_index
 ) VALUES (
-  $1, $3::text, $2, $4::VARCHAR[], $5::text
+  $1, $3::text, $2, $4::VARCHAR[], $5::text, $6::text
 )
-RETURNING id, profile, email, created_at, tenant_id, roles
+RETURNING id, profile, email, created_at, tenant_id, roles, email_blind_index
 `
 
 type CreateUserByTenantParams struct {
-	ID       string                `json:"id"`
-	Profile  subentity.UserProfile `json:"profile"`
-	Email    string                `json:"email"`
-	Roles    []string              `json:"roles"`
-	TenantID string                `json:"tenant_id"`
+	ID              string                `json:"id"`
+	Profile         subentity.UserProfile `json:"profile"`
+	Email           string                `json:"email"`
+	Roles           []string              `json:"roles"`
+	TenantID        string                `json:"tenant_id"`
+	EmailBlindIndex pgtype.Text           `json:"email_blind_index"`
 }
 
 func (q *Queries) CreateUserByTenant(ctx context.Context, arg CreateUserByTenantParams) (CoreUser, error) {
@@ -35,6 +37,7 @@ func (q *Queries) CreateUserByTenant(ctx context.Context, arg CreateUserByTenant
 		arg.Email,
 		arg.Roles,
 		arg.TenantID,
+		arg.EmailBlindIndex,
 	)
 	var i CoreUser
 	err := row.Scan(
@@ -44,6 +47,7 @@ func (q *Queries) CreateUserByTenant(ctx context.Context, arg CreateUserByTenant
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 	)
 	return i, err
 }
@@ -68,19 +72,20 @@ func (q *Queries) DeleteUserByTenant(ctx context.Context, arg DeleteUserByTenant
 }
 
 const getUserByTenantByEmail = `-- name: GetUserByTenantByEmail :one
-SELECT id, profile, email, created_at, tenant_id, roles FROM core_users
-WHERE email = $1::text
-AND tenant_id = $2::text
+SELECT id, profile, email, created_at, tenant_id, roles, email_blind_index FROM core_users
+WHERE (email = $1::text OR email_blind_index = $2::text)
+AND tenant_id = $3::text
 LIMIT 1
 `
 
 type GetUserByTenantByEmailParams struct {
-	Email    string `json:"email"`
-	TenantID string `json:"tenant_id"`
+	Email           string      `json:"email"`
+	EmailBlindIndex pgtype.Text `json:"email_blind_index"`
+	TenantID        string      `json:"tenant_id"`
 }
 
 func (q *Queries) GetUserByTenantByEmail(ctx context.Context, arg GetUserByTenantByEmailParams) (CoreUser, error) {
-	row := q.db.QueryRow(ctx, getUserByTenantByEmail, arg.Email, arg.TenantID)
+	row := q.db.QueryRow(ctx, getUserByTenantByEmail, arg.Email, arg.EmailBlindIndex, arg.TenantID)
 	var i CoreUser
 	err := row.Scan(
 		&i.ID,
@@ -89,12 +94,13 @@ func (q *Queries) GetUserByTenantByEmail(ctx context.Context, arg GetUserByTenan
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 	)
 	return i, err
 }
 
 const getUserByTenantByID = `-- name: GetUserByTenantByID :one
-SELECT id, profile, email, created_at, tenant_id, roles FROM core_users
+SELECT id, profile, email, created_at, tenant_id, roles, email_blind_index FROM core_users
 WHERE id = $1
 AND tenant_id = $2::text
 LIMIT 1
@@ -115,12 +121,13 @@ func (q *Queries) GetUserByTenantByID(ctx context.Context, arg GetUserByTenantBy
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 	)
 	return i, err
 }
 
 const listUsersByTenant = `-- name: ListUsersByTenant :many
-SELECT id, profile, email, created_at, tenant_id, roles FROM core_users
+SELECT id, profile, email, created_at, tenant_id, roles, email_blind_index FROM core_users
 WHERE (UPPER(email) LIKE UPPER($3) OR $3 IS NULL)
 AND tenant_id = $4::text
 ORDER BY created_at
@@ -156,6 +163,7 @@ func (q *Queries) ListUsersByTenant(ctx context.Context, arg ListUsersByTenantPa
 			&i.CreatedAt,
 			&i.TenantID,
 			&i.Roles,
+			&i.EmailBlindIndex,
 		); err != nil {
 			return nil, err
 		}
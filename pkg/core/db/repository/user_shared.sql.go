@@ -20,7 +20,7 @@ SET roles = array_append(roles, $1::TEXT), updated_at = NOW()
 WHERE user_id = $2 
   AND tenant_id = $3 
   AND NOT ($1::TEXT = ANY(roles))
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type AddRoleToUserTenantMembershipParams struct {
@@ -44,6 +44,7 @@ func (q *Queries) AddRoleToUserTenantMembership(ctx context.Context, arg AddRole
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -74,7 +75,7 @@ DO UPDATE SET
     invited_at = COALESCE(core_user_tenant_memberships.invited_at, EXCLUDED.invited_at),
     joined_at = NOW(),
     updated_at = NOW()
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type AddSharedUserToTenantParams struct {
@@ -109,6 +110,7 @@ func (q *Queries) AddSharedUserToTenant(ctx context.Context, arg AddSharedUserTo
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -155,6 +157,225 @@ func (q *Queries) CheckUserTenantAccess(ctx context.Context, arg CheckUserTenant
 	return has_access, err
 }
 
+const countActiveTenantMembers = `-- name: CountActiveTenantMembers :one
+SELECT COUNT(*)
+FROM core_user_tenant_memberships
+WHERE tenant_id = $1 AND status = 'active'
+`
+
+func (q *Queries) CountActiveTenantMembers(ctx context.Context, tenantID string) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveTenantMembers, tenantID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSharedUsers = `-- name: CountSharedUsers :one
+SELECT COUNT(*)
+FROM core_users
+WHERE
+    -- Full-text search over the email and the profile, or an exact email
+    (
+        $1::text IS NULL
+        OR email_blind_index = $2::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query($1::text)
+        OR core_user_search_normalize($1::text) <% core_user_search_text(email, profile)
+    )
+    AND (
+        $3::timestamptz IS NULL
+        OR (
+            created_at < $3::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= $3::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND ($4::text IS NULL OR $4::text = ANY(roles))
+    AND (
+        $5::boolean IS NULL
+        OR $5::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        $6::boolean IS NULL
+        OR $6::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND ($7::timestamptz IS NULL OR created_at >= $7::timestamptz)
+    AND ($8::timestamptz IS NULL OR created_at <= $8::timestamptz)
+`
+
+type CountSharedUsersParams struct {
+	Search           pgtype.Text        `json:"search"`
+	SearchBlindIndex pgtype.Text        `json:"search_blind_index"`
+	InactiveSince    pgtype.Timestamptz `json:"inactive_since"`
+	Role             pgtype.Text        `json:"role"`
+	Disabled         pgtype.Bool        `json:"disabled"`
+	EmailVerified    pgtype.Bool        `json:"email_verified"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
+}
+
+// Counts the users of ListSharedUsers, for the pagers
+func (q *Queries) CountSharedUsers(ctx context.Context, arg CountSharedUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSharedUsers,
+		arg.Search,
+		arg.SearchBlindIndex,
+		arg.InactiveSince,
+		arg.Role,
+		arg.Disabled,
+		arg.EmailVerified,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSharedUsersByRoles = `-- name: CountSharedUsersByRoles :one
+SELECT COUNT(*)
+FROM core_users
+WHERE 
+    -- Use GIN index for array overlap
+    roles && $1::VARCHAR[]
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        $2::text IS NULL
+        OR email_blind_index = $3::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query($2::text)
+        OR core_user_search_normalize($2::text) <% core_user_search_text(email, profile)
+    )
+    -- Stale accounts: created before the cutoff and no login anywhere since
+    AND (
+        $4::timestamptz IS NULL
+        OR (
+            created_at < $4::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= $4::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND ($5::text IS NULL OR $5::text = ANY(roles))
+    AND (
+        $6::boolean IS NULL
+        OR $6::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        $7::boolean IS NULL
+        OR $7::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND ($8::timestamptz IS NULL OR created_at >= $8::timestamptz)
+    AND ($9::timestamptz IS NULL OR created_at <= $9::timestamptz)
+`
+
+type CountSharedUsersByRolesParams struct {
+	RequestedRoles   []string           `json:"requested_roles"`
+	Search           pgtype.Text        `json:"search"`
+	SearchBlindIndex pgtype.Text        `json:"search_blind_index"`
+	InactiveSince    pgtype.Timestamptz `json:"inactive_since"`
+	Role             pgtype.Text        `json:"role"`
+	Disabled         pgtype.Bool        `json:"disabled"`
+	EmailVerified    pgtype.Bool        `json:"email_verified"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
+}
+
+// Counts the users of ListSharedUsersByRoles, for the pagers
+func (q *Queries) CountSharedUsersByRoles(ctx context.Context, arg CountSharedUsersByRolesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSharedUsersByRoles,
+		arg.RequestedRoles,
+		arg.Search,
+		arg.SearchBlindIndex,
+		arg.InactiveSince,
+		arg.Role,
+		arg.Disabled,
+		arg.EmailVerified,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSharedUsersByTenantAllStatuses = `-- name: CountSharedUsersByTenantAllStatuses :one
+SELECT COUNT(*)
+FROM core_users u
+INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
+WHERE utm.tenant_id = $1
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        $2::text IS NULL
+        OR u.email_blind_index = $3::text
+        OR core_user_search_vector(u.email, u.profile) @@ core_user_search_query($2::text)
+        OR core_user_search_normalize($2::text) <% core_user_search_text(u.email, u.profile)
+    )
+    -- Stale accounts: created before the cutoff and no login in the tenant since
+    AND (
+        $4::timestamptz IS NULL
+        OR (
+            u.created_at < $4::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = u.id
+                  AND l.tenant_id = utm.tenant_id
+                  AND l.last_login_at >= $4::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND ($5::text IS NULL OR $5::text = ANY(utm.roles))
+    -- Labels: the users carrying every one of them
+    AND ($6::text[] IS NULL OR utm.labels @> $6::text[])
+    AND (
+        $7::boolean IS NULL
+        OR $7::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = u.id), false)
+    )
+    AND (
+        $8::boolean IS NULL
+        OR $8::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = u.id), false)
+    )
+    AND ($9::timestamptz IS NULL OR u.created_at >= $9::timestamptz)
+    AND ($10::timestamptz IS NULL OR u.created_at <= $10::timestamptz)
+`
+
+type CountSharedUsersByTenantAllStatusesParams struct {
+	TenantID         string             `json:"tenant_id"`
+	Search           pgtype.Text        `json:"search"`
+	SearchBlindIndex pgtype.Text        `json:"search_blind_index"`
+	InactiveSince    pgtype.Timestamptz `json:"inactive_since"`
+	Role             pgtype.Text        `json:"role"`
+	Labels           []string           `json:"labels"`
+	Disabled         pgtype.Bool        `json:"disabled"`
+	EmailVerified    pgtype.Bool        `json:"email_verified"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
+}
+
+// Counts the users of ListSharedUsersByTenantAllStatuses, for the pagers
+func (q *Queries) CountSharedUsersByTenantAllStatuses(ctx context.Context, arg CountSharedUsersByTenantAllStatusesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSharedUsersByTenantAllStatuses,
+		arg.TenantID,
+		arg.Search,
+		arg.SearchBlindIndex,
+		arg.InactiveSince,
+		arg.Role,
+		arg.Labels,
+		arg.Disabled,
+		arg.EmailVerified,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUserTenants = `-- name: CountUserTenants :one
 SELECT COUNT(DISTINCT tenant_id)::int
 FROM core_user_tenant_memberships
@@ -171,18 +392,19 @@ func (q *Queries) CountUserTenants(ctx context.Context, userID string) (int32, e
 
 const createSharedUser = `-- name: CreateSharedUser :one
 INSERT INTO core_users (
-  "id", "email", "profile", roles
+  "id", "email", "profile", roles, email_blind_index
 ) VALUES (
-  $1, $3::text, $2, $4::VARCHAR[]
+  $1, $3::text, $2, $4::VARCHAR[], $5::text
 )
-RETURNING id, profile, email, created_at, tenant_id, roles
+RETURNING id, profile, email, created_at, tenant_id, roles, email_blind_index
 `
 
 type CreateSharedUserParams struct {
-	ID      string                `json:"id"`
-	Profile subentity.UserProfile `json:"profile"`
-	Email   string                `json:"email"`
-	Roles   []string              `json:"roles"`
+	ID              string                `json:"id"`
+	Profile         subentity.UserProfile `json:"profile"`
+	Email           string                `json:"email"`
+	Roles           []string              `json:"roles"`
+	EmailBlindIndex pgtype.Text           `json:"email_blind_index"`
 }
 
 // USED
@@ -192,6 +414,7 @@ func (q *Queries) CreateSharedUser(ctx context.Context, arg CreateSharedUserPara
 		arg.Profile,
 		arg.Email,
 		arg.Roles,
+		arg.EmailBlindIndex,
 	)
 	var i CoreUser
 	err := row.Scan(
@@ -201,6 +424,7 @@ func (q *Queries) CreateSharedUser(ctx context.Context, arg CreateSharedUserPara
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 	)
 	return i, err
 }
@@ -208,11 +432,11 @@ func (q *Queries) CreateSharedUser(ctx context.Context, arg CreateSharedUserPara
 const createSharedUserWithTenant = `-- name: CreateSharedUserWithTenant :one
 WITH new_user AS (
     INSERT INTO core_users (
-        "id", "email", "profile"
+        "id", "email", "profile", email_blind_index
     ) VALUES (
-        $1, $3::text, $2
+        $1, $3::text, $2, $4::text
     )
-    RETURNING id, profile, email, created_at, tenant_id, roles
+    RETURNING id, profile, email, created_at, tenant_id, roles, email_blind_index
 ),
 new_membership AS (
     INSERT INTO core_user_tenant_memberships (
@@ -225,11 +449,11 @@ new_membership AS (
         joined_at
     ) VALUES (
         $1,
-        $4,
-        $5::TEXT[],
+        $5,
+        $6::TEXT[],
         'active',
-        $6,
         $7,
+        $8,
         NOW()
     )
     ON CONFLICT (user_id, tenant_id) 
@@ -243,7 +467,7 @@ new_membership AS (
     RETURNING roles as tenant_roles, status as membership_status, joined_at, tenant_id
 )
 SELECT 
-    new_user.id, new_user.profile, new_user.email, new_user.created_at, new_user.tenant_id, new_user.roles,
+    new_user.id, new_user.profile, new_user.email, new_user.created_at, new_user.tenant_id, new_user.roles, new_user.email_blind_index,
     new_membership.tenant_roles,
     new_membership.membership_status,
     new_membership.joined_at,
@@ -253,13 +477,14 @@ CROSS JOIN new_membership
 `
 
 type CreateSharedUserWithTenantParams struct {
-	ID          string                `json:"id"`
-	Profile     subentity.UserProfile `json:"profile"`
-	Email       string                `json:"email"`
-	TenantID    string                `json:"tenant_id"`
-	TenantRoles []string              `json:"tenant_roles"`
-	InvitedBy   pgtype.Text           `json:"invited_by"`
-	InvitedAt   pgtype.Timestamptz    `json:"invited_at"`
+	ID              string                `json:"id"`
+	Profile         subentity.UserProfile `json:"profile"`
+	Email           string                `json:"email"`
+	EmailBlindIndex pgtype.Text           `json:"email_blind_index"`
+	TenantID        string                `json:"tenant_id"`
+	TenantRoles     []string              `json:"tenant_roles"`
+	InvitedBy       pgtype.Text           `json:"invited_by"`
+	InvitedAt       pgtype.Timestamptz    `json:"invited_at"`
 }
 
 type CreateSharedUserWithTenantRow struct {
@@ -269,6 +494,7 @@ type CreateSharedUserWithTenantRow struct {
 	CreatedAt        time.Time          `json:"created_at"`
 	TenantID         pgtype.Text        `json:"tenant_id"`
 	Roles            []string           `json:"roles"`
+	EmailBlindIndex  pgtype.Text        `json:"email_blind_index"`
 	TenantRoles      []string           `json:"tenant_roles"`
 	MembershipStatus string             `json:"membership_status"`
 	JoinedAt         pgtype.Timestamptz `json:"joined_at"`
@@ -281,6 +507,7 @@ func (q *Queries) CreateSharedUserWithTenant(ctx context.Context, arg CreateShar
 		arg.ID,
 		arg.Profile,
 		arg.Email,
+		arg.EmailBlindIndex,
 		arg.TenantID,
 		arg.TenantRoles,
 		arg.InvitedBy,
@@ -294,6 +521,7 @@ func (q *Queries) CreateSharedUserWithTenant(ctx context.Context, arg CreateShar
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 		&i.TenantRoles,
 		&i.MembershipStatus,
 		&i.JoinedAt,
@@ -340,7 +568,7 @@ func (q *Queries) DeleteSharedUserByTenant(ctx context.Context, arg DeleteShared
 }
 
 const getSharedUserByID = `-- name: GetSharedUserByID :one
-SELECT id, profile, email, created_at, tenant_id, roles FROM core_users
+SELECT id, profile, email, created_at, tenant_id, roles, email_blind_index FROM core_users
 WHERE id = $1
 LIMIT 1
 `
@@ -355,28 +583,30 @@ func (q *Queries) GetSharedUserByID(ctx context.Context, id string) (CoreUser, e
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 	)
 	return i, err
 }
 
 const getSharedUserByTenantByEmail = `-- name: GetSharedUserByTenantByEmail :one
 SELECT 
-    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles,
+    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles, u.email_blind_index,
     utm.roles as tenant_roles,
     utm.status as membership_status,
     utm.joined_at,
     utm.tenant_id
 FROM core_users u
 INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
-WHERE u.email = $1::text
-    AND utm.tenant_id = $2
+WHERE (u.email = $1::text OR u.email_blind_index = $2::text)
+    AND utm.tenant_id = $3
     AND utm.status = 'active'
 LIMIT 1
 `
 
 type GetSharedUserByTenantByEmailParams struct {
-	Email    string `json:"email"`
-	TenantID string `json:"tenant_id"`
+	Email           string      `json:"email"`
+	EmailBlindIndex pgtype.Text `json:"email_blind_index"`
+	TenantID        string      `json:"tenant_id"`
 }
 
 type GetSharedUserByTenantByEmailRow struct {
@@ -386,6 +616,7 @@ type GetSharedUserByTenantByEmailRow struct {
 	CreatedAt        time.Time             `json:"created_at"`
 	TenantID         pgtype.Text           `json:"tenant_id"`
 	Roles            []string              `json:"roles"`
+	EmailBlindIndex  pgtype.Text           `json:"email_blind_index"`
 	TenantRoles      []string              `json:"tenant_roles"`
 	MembershipStatus string                `json:"membership_status"`
 	JoinedAt         pgtype.Timestamptz    `json:"joined_at"`
@@ -393,7 +624,7 @@ type GetSharedUserByTenantByEmailRow struct {
 }
 
 func (q *Queries) GetSharedUserByTenantByEmail(ctx context.Context, arg GetSharedUserByTenantByEmailParams) (GetSharedUserByTenantByEmailRow, error) {
-	row := q.db.QueryRow(ctx, getSharedUserByTenantByEmail, arg.Email, arg.TenantID)
+	row := q.db.QueryRow(ctx, getSharedUserByTenantByEmail, arg.Email, arg.EmailBlindIndex, arg.TenantID)
 	var i GetSharedUserByTenantByEmailRow
 	err := row.Scan(
 		&i.ID,
@@ -402,6 +633,7 @@ func (q *Queries) GetSharedUserByTenantByEmail(ctx context.Context, arg GetShare
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 		&i.TenantRoles,
 		&i.MembershipStatus,
 		&i.JoinedAt,
@@ -412,7 +644,7 @@ func (q *Queries) GetSharedUserByTenantByEmail(ctx context.Context, arg GetShare
 
 const getSharedUserByTenantByID = `-- name: GetSharedUserByTenantByID :one
 SELECT 
-    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles,
+    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles, u.email_blind_index,
     utm.roles as tenant_roles,
     utm.status as membership_status,
     utm.joined_at,
@@ -437,6 +669,7 @@ type GetSharedUserByTenantByIDRow struct {
 	CreatedAt        time.Time             `json:"created_at"`
 	TenantID         pgtype.Text           `json:"tenant_id"`
 	Roles            []string              `json:"roles"`
+	EmailBlindIndex  pgtype.Text           `json:"email_blind_index"`
 	TenantRoles      []string              `json:"tenant_roles"`
 	MembershipStatus string                `json:"membership_status"`
 	JoinedAt         pgtype.Timestamptz    `json:"joined_at"`
@@ -453,6 +686,7 @@ func (q *Queries) GetSharedUserByTenantByID(ctx context.Context, arg GetSharedUs
 		&i.CreatedAt,
 		&i.TenantID,
 		&i.Roles,
+		&i.EmailBlindIndex,
 		&i.TenantRoles,
 		&i.MembershipStatus,
 		&i.JoinedAt,
@@ -462,7 +696,7 @@ func (q *Queries) GetSharedUserByTenantByID(ctx context.Context, arg GetSharedUs
 }
 
 const getSharedUserTenantMembership = `-- name: GetSharedUserTenantMembership :one
-SELECT id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses FROM core_user_tenant_memberships
+SELECT id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels FROM core_user_tenant_memberships
 WHERE user_id = $1 AND tenant_id = $2
 LIMIT 1
 `
@@ -487,6 +721,7 @@ func (q *Queries) GetSharedUserTenantMembership(ctx context.Context, arg GetShar
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -550,10 +785,15 @@ SELECT DISTINCT ON (id)
     profile, 
     created_at
 FROM core_users
-WHERE email = $1::text
+WHERE email = $1::text OR email_blind_index = $2::text
 LIMIT 1
 `
 
+type GetUserByEmailGlobalParams struct {
+	Email           string      `json:"email"`
+	EmailBlindIndex pgtype.Text `json:"email_blind_index"`
+}
+
 type GetUserByEmailGlobalRow struct {
 	ID        string                `json:"id"`
 	Email     pgtype.Text           `json:"email"`
@@ -563,8 +803,8 @@ type GetUserByEmailGlobalRow struct {
 
 // Get user by email across all tenants (for checking existence)
 // This returns the first user found with this email
-func (q *Queries) GetUserByEmailGlobal(ctx context.Context, email string) (GetUserByEmailGlobalRow, error) {
-	row := q.db.QueryRow(ctx, getUserByEmailGlobal, email)
+func (q *Queries) GetUserByEmailGlobal(ctx context.Context, arg GetUserByEmailGlobalParams) (GetUserByEmailGlobalRow, error) {
+	row := q.db.QueryRow(ctx, getUserByEmailGlobal, arg.Email, arg.EmailBlindIndex)
 	var i GetUserByEmailGlobalRow
 	err := row.Scan(
 		&i.ID,
@@ -615,6 +855,32 @@ func (q *Queries) GetUserTenantRoles(ctx context.Context, arg GetUserTenantRoles
 	return roles, err
 }
 
+const isDisplayNameTakenInTenant = `-- name: IsDisplayNameTakenInTenant :one
+SELECT EXISTS(
+    SELECT 1 FROM core_users u
+    INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
+    WHERE utm.tenant_id = $1
+        AND utm.status = 'active'
+        AND u.id <> $2
+        AND LOWER(TRIM(u.profile->>'name')) = LOWER(TRIM($3::text))
+) as is_taken
+`
+
+type IsDisplayNameTakenInTenantParams struct {
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+	Name     string `json:"name"`
+}
+
+// Check if another active member of the tenant uses the display name, ignoring
+// case and surrounding spaces
+func (q *Queries) IsDisplayNameTakenInTenant(ctx context.Context, arg IsDisplayNameTakenInTenantParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isDisplayNameTakenInTenant, arg.TenantID, arg.UserID, arg.Name)
+	var is_taken bool
+	err := row.Scan(&is_taken)
+	return is_taken, err
+}
+
 const isUserMemberOfTenant = `-- name: IsUserMemberOfTenant :one
 SELECT EXISTS(
     SELECT 1 FROM core_user_tenant_memberships
@@ -635,9 +901,60 @@ func (q *Queries) IsUserMemberOfTenant(ctx context.Context, arg IsUserMemberOfTe
 	return is_member, err
 }
 
+const listActiveTenantMembersAfter = `-- name: ListActiveTenantMembersAfter :many
+SELECT utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses, utm.labels
+FROM core_user_tenant_memberships utm
+WHERE utm.tenant_id = $1
+    AND utm.status = 'active'
+    AND utm.user_id > $2::varchar
+ORDER BY utm.user_id ASC
+LIMIT $3
+`
+
+type ListActiveTenantMembersAfterParams struct {
+	TenantID    string `json:"tenant_id"`
+	AfterUserID string `json:"after_user_id"`
+	BatchSize   int32  `json:"batch_size"`
+}
+
+// Keyset pagination over a tenant's active members ordered by user_id, so a
+// batch job can resume from the last user it processed.
+func (q *Queries) ListActiveTenantMembersAfter(ctx context.Context, arg ListActiveTenantMembersAfterParams) ([]CoreUserTenantMembership, error) {
+	rows, err := q.db.Query(ctx, listActiveTenantMembersAfter, arg.TenantID, arg.AfterUserID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CoreUserTenantMembership{}
+	for rows.Next() {
+		var i CoreUserTenantMembership
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TenantID,
+			&i.Status,
+			&i.InvitedBy,
+			&i.InvitedAt,
+			&i.JoinedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Roles,
+			&i.FeatureLicenses,
+			&i.Labels,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPendingInvitations = `-- name: ListPendingInvitations :many
 SELECT 
-    utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses,
+    utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses, utm.labels,
     t.name as tenant_name,
     t.subdomain
 FROM core_user_tenant_memberships utm
@@ -658,6 +975,7 @@ type ListPendingInvitationsRow struct {
 	UpdatedAt       time.Time                       `json:"updated_at"`
 	Roles           []string                        `json:"roles"`
 	FeatureLicenses subentity.TenantFeatureLicenses `json:"feature_licenses"`
+	Labels          []string                        `json:"labels"`
 	TenantName      string                          `json:"tenant_name"`
 	Subdomain       string                          `json:"subdomain"`
 }
@@ -683,6 +1001,7 @@ func (q *Queries) ListPendingInvitations(ctx context.Context, userID string) ([]
 			&i.UpdatedAt,
 			&i.Roles,
 			&i.FeatureLicenses,
+			&i.Labels,
 			&i.TenantName,
 			&i.Subdomain,
 		); err != nil {
@@ -705,17 +1024,55 @@ SELECT
     created_at
 FROM core_users
 WHERE
-    email ILIKE $3::text || '%'
-    OR $3 IS NULL
-ORDER BY email ASC
+    -- Full-text search over the email and the profile, or an exact email
+    (
+        $3::text IS NULL
+        OR email_blind_index = $4::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query($3::text)
+        OR core_user_search_normalize($3::text) <% core_user_search_text(email, profile)
+    )
+    AND (
+        $5::timestamptz IS NULL
+        OR (
+            created_at < $5::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= $5::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND ($6::text IS NULL OR $6::text = ANY(roles))
+    AND (
+        $7::boolean IS NULL
+        OR $7::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        $8::boolean IS NULL
+        OR $8::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND ($9::timestamptz IS NULL OR created_at >= $9::timestamptz)
+    AND ($10::timestamptz IS NULL OR created_at <= $10::timestamptz)
+ORDER BY
+    CASE WHEN $3::text IS NULL THEN 0
+        ELSE core_user_search_rank(email, profile, $3::text) END DESC,
+    email ASC
 LIMIT $1
 OFFSET $2
 `
 
 type ListSharedUsersParams struct {
-	Limit        int32       `json:"limit"`
-	Offset       int32       `json:"offset"`
-	SearchPrefix pgtype.Text `json:"search_prefix"`
+	Limit            int32              `json:"limit"`
+	Offset           int32              `json:"offset"`
+	Search           pgtype.Text        `json:"search"`
+	SearchBlindIndex pgtype.Text        `json:"search_blind_index"`
+	InactiveSince    pgtype.Timestamptz `json:"inactive_since"`
+	Role             pgtype.Text        `json:"role"`
+	Disabled         pgtype.Bool        `json:"disabled"`
+	EmailVerified    pgtype.Bool        `json:"email_verified"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
 }
 
 type ListSharedUsersRow struct {
@@ -729,7 +1086,18 @@ type ListSharedUsersRow struct {
 // List every user system-wide (admin domain, scope=all). Global roles only —
 // tenant roles live in core_user_tenant_memberships.
 func (q *Queries) ListSharedUsers(ctx context.Context, arg ListSharedUsersParams) ([]ListSharedUsersRow, error) {
-	rows, err := q.db.Query(ctx, listSharedUsers, arg.Limit, arg.Offset, arg.SearchPrefix)
+	rows, err := q.db.Query(ctx, listSharedUsers,
+		arg.Limit,
+		arg.Offset,
+		arg.Search,
+		arg.SearchBlindIndex,
+		arg.InactiveSince,
+		arg.Role,
+		arg.Disabled,
+		arg.EmailVerified,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -765,21 +1133,57 @@ FROM core_users
 WHERE 
     -- Use GIN index for array overlap
     roles && $3::VARCHAR[]
-    -- Optimize email search
+    -- Full-text search over the email and the profile, or an exact email
     AND (
-        email ILIKE $4::text || '%'
-        OR $4 IS NULL
+        $4::text IS NULL
+        OR email_blind_index = $5::text
+        OR core_user_search_vector(email, profile) @@ core_user_search_query($4::text)
+        OR core_user_search_normalize($4::text) <% core_user_search_text(email, profile)
     )
-ORDER BY email ASC
+    -- Stale accounts: created before the cutoff and no login anywhere since
+    AND (
+        $6::timestamptz IS NULL
+        OR (
+            created_at < $6::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = core_users.id
+                  AND l.last_login_at >= $6::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND ($7::text IS NULL OR $7::text = ANY(roles))
+    AND (
+        $8::boolean IS NULL
+        OR $8::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND (
+        $9::boolean IS NULL
+        OR $9::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = core_users.id), false)
+    )
+    AND ($10::timestamptz IS NULL OR created_at >= $10::timestamptz)
+    AND ($11::timestamptz IS NULL OR created_at <= $11::timestamptz)
+ORDER BY
+    CASE WHEN $4::text IS NULL THEN 0
+        ELSE core_user_search_rank(email, profile, $4::text) END DESC,
+    email ASC
 LIMIT $1
 OFFSET $2
 `
 
 type ListSharedUsersByRolesParams struct {
-	Limit          int32       `json:"limit"`
-	Offset         int32       `json:"offset"`
-	RequestedRoles []string    `json:"requested_roles"`
-	SearchPrefix   pgtype.Text `json:"search_prefix"`
+	Limit            int32              `json:"limit"`
+	Offset           int32              `json:"offset"`
+	RequestedRoles   []string           `json:"requested_roles"`
+	Search           pgtype.Text        `json:"search"`
+	SearchBlindIndex pgtype.Text        `json:"search_blind_index"`
+	InactiveSince    pgtype.Timestamptz `json:"inactive_since"`
+	Role             pgtype.Text        `json:"role"`
+	Disabled         pgtype.Bool        `json:"disabled"`
+	EmailVerified    pgtype.Bool        `json:"email_verified"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
 }
 
 type ListSharedUsersByRolesRow struct {
@@ -795,7 +1199,14 @@ func (q *Queries) ListSharedUsersByRoles(ctx context.Context, arg ListSharedUser
 		arg.Limit,
 		arg.Offset,
 		arg.RequestedRoles,
-		arg.SearchPrefix,
+		arg.Search,
+		arg.SearchBlindIndex,
+		arg.InactiveSince,
+		arg.Role,
+		arg.Disabled,
+		arg.EmailVerified,
+		arg.CreatedFrom,
+		arg.CreatedTo,
 	)
 	if err != nil {
 		return nil, err
@@ -823,7 +1234,7 @@ func (q *Queries) ListSharedUsersByRoles(ctx context.Context, arg ListSharedUser
 
 const listSharedUsersByTenant = `-- name: ListSharedUsersByTenant :many
 SELECT 
-    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles,
+    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles, u.email_blind_index,
     utm.roles as tenant_roles,
     utm.status as membership_status,
     utm.joined_at
@@ -831,20 +1242,27 @@ FROM core_users u
 INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
 WHERE utm.tenant_id = $3
     AND utm.status = 'active'
+    -- Full-text search over the email and the profile, or an exact email
     AND (
-        email ILIKE $4::text || '%'
-        OR $4 IS NULL
+        $4::text IS NULL
+        OR u.email_blind_index = $5::text
+        OR core_user_search_vector(u.email, u.profile) @@ core_user_search_query($4::text)
+        OR core_user_search_normalize($4::text) <% core_user_search_text(u.email, u.profile)
     )
-ORDER BY u.created_at
+ORDER BY
+    CASE WHEN $4::text IS NULL THEN 0
+        ELSE core_user_search_rank(u.email, u.profile, $4::text) END DESC,
+    u.created_at
 LIMIT $1
 OFFSET $2
 `
 
 type ListSharedUsersByTenantParams struct {
-	Limit        int32       `json:"limit"`
-	Offset       int32       `json:"offset"`
-	TenantID     string      `json:"tenant_id"`
-	SearchPrefix pgtype.Text `json:"search_prefix"`
+	Limit            int32       `json:"limit"`
+	Offset           int32       `json:"offset"`
+	TenantID         string      `json:"tenant_id"`
+	Search           pgtype.Text `json:"search"`
+	SearchBlindIndex pgtype.Text `json:"search_blind_index"`
 }
 
 type ListSharedUsersByTenantRow struct {
@@ -854,6 +1272,7 @@ type ListSharedUsersByTenantRow struct {
 	CreatedAt        time.Time             `json:"created_at"`
 	TenantID         pgtype.Text           `json:"tenant_id"`
 	Roles            []string              `json:"roles"`
+	EmailBlindIndex  pgtype.Text           `json:"email_blind_index"`
 	TenantRoles      []string              `json:"tenant_roles"`
 	MembershipStatus string                `json:"membership_status"`
 	JoinedAt         pgtype.Timestamptz    `json:"joined_at"`
@@ -864,7 +1283,8 @@ func (q *Queries) ListSharedUsersByTenant(ctx context.Context, arg ListSharedUse
 		arg.Limit,
 		arg.Offset,
 		arg.TenantID,
-		arg.SearchPrefix,
+		arg.Search,
+		arg.SearchBlindIndex,
 	)
 	if err != nil {
 		return nil, err
@@ -880,6 +1300,7 @@ func (q *Queries) ListSharedUsersByTenant(ctx context.Context, arg ListSharedUse
 			&i.CreatedAt,
 			&i.TenantID,
 			&i.Roles,
+			&i.EmailBlindIndex,
 			&i.TenantRoles,
 			&i.MembershipStatus,
 			&i.JoinedAt,
@@ -896,27 +1317,69 @@ func (q *Queries) ListSharedUsersByTenant(ctx context.Context, arg ListSharedUse
 
 const listSharedUsersByTenantAllStatuses = `-- name: ListSharedUsersByTenantAllStatuses :many
 SELECT
-    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles,
+    u.id, u.profile, u.email, u.created_at, u.tenant_id, u.roles, u.email_blind_index,
     utm.roles as tenant_roles,
     utm.status as membership_status,
-    utm.joined_at
+    utm.joined_at,
+    utm.labels as tenant_labels
 FROM core_users u
 INNER JOIN core_user_tenant_memberships utm ON u.id = utm.user_id
 WHERE utm.tenant_id = $3
+    -- Full-text search over the email and the profile, or an exact email
+    AND (
+        $4::text IS NULL
+        OR u.email_blind_index = $5::text
+        OR core_user_search_vector(u.email, u.profile) @@ core_user_search_query($4::text)
+        OR core_user_search_normalize($4::text) <% core_user_search_text(u.email, u.profile)
+    )
+    -- Stale accounts: created before the cutoff and no login in the tenant since
+    AND (
+        $6::timestamptz IS NULL
+        OR (
+            u.created_at < $6::timestamptz
+            AND NOT EXISTS (
+                SELECT 1 FROM core_user_logins l
+                WHERE l.user_id = u.id
+                  AND l.tenant_id = utm.tenant_id
+                  AND l.last_login_at >= $6::timestamptz
+            )
+        )
+    )
+    -- Role, state at the auth provider and creation date
+    AND ($7::text IS NULL OR $7::text = ANY(utm.roles))
+    -- Labels: the users carrying every one of them
+    AND ($8::text[] IS NULL OR utm.labels @> $8::text[])
+    AND (
+        $9::boolean IS NULL
+        OR $9::boolean = COALESCE((SELECT s.disabled FROM core_user_account_states s WHERE s.user_id = u.id), false)
+    )
     AND (
-        email ILIKE $4::text || '%'
-        OR $4 IS NULL
+        $10::boolean IS NULL
+        OR $10::boolean = COALESCE((SELECT s.email_verified FROM core_user_account_states s WHERE s.user_id = u.id), false)
     )
-ORDER BY u.created_at
+    AND ($11::timestamptz IS NULL OR u.created_at >= $11::timestamptz)
+    AND ($12::timestamptz IS NULL OR u.created_at <= $12::timestamptz)
+ORDER BY
+    CASE WHEN $4::text IS NULL THEN 0
+        ELSE core_user_search_rank(u.email, u.profile, $4::text) END DESC,
+    u.created_at
 LIMIT $1
 OFFSET $2
 `
 
 type ListSharedUsersByTenantAllStatusesParams struct {
-	Limit        int32       `json:"limit"`
-	Offset       int32       `json:"offset"`
-	TenantID     string      `json:"tenant_id"`
-	SearchPrefix pgtype.Text `json:"search_prefix"`
+	Limit            int32              `json:"limit"`
+	Offset           int32              `json:"offset"`
+	TenantID         string             `json:"tenant_id"`
+	Search           pgtype.Text        `json:"search"`
+	SearchBlindIndex pgtype.Text        `json:"search_blind_index"`
+	InactiveSince    pgtype.Timestamptz `json:"inactive_since"`
+	Role             pgtype.Text        `json:"role"`
+	Labels           []string           `json:"labels"`
+	Disabled         pgtype.Bool        `json:"disabled"`
+	EmailVerified    pgtype.Bool        `json:"email_verified"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
 }
 
 type ListSharedUsersByTenantAllStatusesRow struct {
@@ -926,9 +1389,11 @@ type ListSharedUsersByTenantAllStatusesRow struct {
 	CreatedAt        time.Time             `json:"created_at"`
 	TenantID         pgtype.Text           `json:"tenant_id"`
 	Roles            []string              `json:"roles"`
+	EmailBlindIndex  pgtype.Text           `json:"email_blind_index"`
 	TenantRoles      []string              `json:"tenant_roles"`
 	MembershipStatus string                `json:"membership_status"`
 	JoinedAt         pgtype.Timestamptz    `json:"joined_at"`
+	TenantLabels     []string              `json:"tenant_labels"`
 }
 
 func (q *Queries) ListSharedUsersByTenantAllStatuses(ctx context.Context, arg ListSharedUsersByTenantAllStatusesParams) ([]ListSharedUsersByTenantAllStatusesRow, error) {
@@ -936,7 +1401,15 @@ func (q *Queries) ListSharedUsersByTenantAllStatuses(ctx context.Context, arg Li
 		arg.Limit,
 		arg.Offset,
 		arg.TenantID,
-		arg.SearchPrefix,
+		arg.Search,
+		arg.SearchBlindIndex,
+		arg.InactiveSince,
+		arg.Role,
+		arg.Labels,
+		arg.Disabled,
+		arg.EmailVerified,
+		arg.CreatedFrom,
+		arg.CreatedTo,
 	)
 	if err != nil {
 		return nil, err
@@ -952,9 +1425,11 @@ func (q *Queries) ListSharedUsersByTenantAllStatuses(ctx context.Context, arg Li
 			&i.CreatedAt,
 			&i.TenantID,
 			&i.Roles,
+			&i.EmailBlindIndex,
 			&i.TenantRoles,
 			&i.MembershipStatus,
 			&i.JoinedAt,
+			&i.TenantLabels,
 		); err != nil {
 			return nil, err
 		}
@@ -967,7 +1442,7 @@ func (q *Queries) ListSharedUsersByTenantAllStatuses(ctx context.Context, arg Li
 }
 
 const listTenantMembers = `-- name: ListTenantMembers :many
-SELECT utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses
+SELECT utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses, utm.labels
 FROM core_user_tenant_memberships utm
 WHERE utm.tenant_id = $1 AND utm.status = $2
 ORDER BY utm.created_at DESC
@@ -999,6 +1474,7 @@ func (q *Queries) ListTenantMembers(ctx context.Context, arg ListTenantMembersPa
 			&i.UpdatedAt,
 			&i.Roles,
 			&i.FeatureLicenses,
+			&i.Labels,
 		); err != nil {
 			return nil, err
 		}
@@ -1012,7 +1488,7 @@ func (q *Queries) ListTenantMembers(ctx context.Context, arg ListTenantMembersPa
 
 const listUserTenantMemberships = `-- name: ListUserTenantMemberships :many
 SELECT 
-    utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses,
+    utm.id, utm.user_id, utm.tenant_id, utm.status, utm.invited_by, utm.invited_at, utm.joined_at, utm.created_at, utm.updated_at, utm.roles, utm.feature_licenses, utm.labels,
     t.name as tenant_name,
     t.subdomain
 FROM core_user_tenant_memberships utm
@@ -1038,6 +1514,7 @@ type ListUserTenantMembershipsRow struct {
 	UpdatedAt       time.Time                       `json:"updated_at"`
 	Roles           []string                        `json:"roles"`
 	FeatureLicenses subentity.TenantFeatureLicenses `json:"feature_licenses"`
+	Labels          []string                        `json:"labels"`
 	TenantName      string                          `json:"tenant_name"`
 	Subdomain       string                          `json:"subdomain"`
 }
@@ -1063,6 +1540,7 @@ func (q *Queries) ListUserTenantMemberships(ctx context.Context, arg ListUserTen
 			&i.UpdatedAt,
 			&i.Roles,
 			&i.FeatureLicenses,
+			&i.Labels,
 			&i.TenantName,
 			&i.Subdomain,
 		); err != nil {
@@ -1098,7 +1576,7 @@ SET roles = array_remove(roles, $1::TEXT), updated_at = NOW()
 WHERE user_id = $2 
   AND tenant_id = $3 
   AND $1::TEXT = ANY(roles)
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type RemoveRoleFromUserTenantMembershipParams struct {
@@ -1122,6 +1600,7 @@ func (q *Queries) RemoveRoleFromUserTenantMembership(ctx context.Context, arg Re
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -1269,7 +1748,7 @@ SET roles = $2::TEXT[],
     updated_at = NOW()
 WHERE user_id = $1 
     AND tenant_id = $3
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type UpdateSharedUserRolesInTenantParams struct {
@@ -1294,6 +1773,7 @@ func (q *Queries) UpdateSharedUserRolesInTenant(ctx context.Context, arg UpdateS
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -1326,7 +1806,7 @@ const updateUserTenantMembershipJoinedAt = `-- name: UpdateUserTenantMembershipJ
 UPDATE core_user_tenant_memberships
 SET joined_at = $3, status = 'active', updated_at = NOW()
 WHERE user_id = $1 AND tenant_id = $2
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type UpdateUserTenantMembershipJoinedAtParams struct {
@@ -1350,6 +1830,7 @@ func (q *Queries) UpdateUserTenantMembershipJoinedAt(ctx context.Context, arg Up
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -1358,7 +1839,7 @@ const updateUserTenantMembershipRoles = `-- name: UpdateUserTenantMembershipRole
 UPDATE core_user_tenant_memberships
 SET roles = $3, updated_at = NOW()
 WHERE user_id = $1 AND tenant_id = $2
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type UpdateUserTenantMembershipRolesParams struct {
@@ -1382,6 +1863,7 @@ func (q *Queries) UpdateUserTenantMembershipRoles(ctx context.Context, arg Updat
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -1390,7 +1872,7 @@ const updateUserTenantMembershipStatus = `-- name: UpdateUserTenantMembershipSta
 UPDATE core_user_tenant_memberships
 SET status = $3, updated_at = NOW()
 WHERE user_id = $1 AND tenant_id = $2
-RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses
+RETURNING id, user_id, tenant_id, status, invited_by, invited_at, joined_at, created_at, updated_at, roles, feature_licenses, labels
 `
 
 type UpdateUserTenantMembershipStatusParams struct {
@@ -1414,6 +1896,7 @@ func (q *Queries) UpdateUserTenantMembershipStatus(ctx context.Context, arg Upda
 		&i.UpdatedAt,
 		&i.Roles,
 		&i.FeatureLicenses,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -0,0 +1,9 @@
+-- Shape of the goose_db_version table, for sqlc only. goose creates it when
+-- it first runs the migrations, the support bundle reads the applied
+-- versions from it. This file is never run.
+CREATE TABLE goose_db_version (
+  id integer PRIMARY KEY,
+  version_id bigint NOT NULL,
+  is_applied boolean NOT NULL,
+  tstamp timestamp NOT NULL DEFAULT now()
+);
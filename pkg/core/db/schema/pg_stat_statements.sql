@@ -0,0 +1,12 @@
+-- Shape of the pg_stat_statements view, for sqlc only. The extension is
+-- installed by the database administrator rather than by the migrations, the
+-- index advisor checks it is there. This file is never run.
+CREATE TABLE pg_stat_statements (
+  userid oid NOT NULL,
+  dbid oid NOT NULL,
+  queryid bigint NOT NULL,
+  query text NOT NULL,
+  calls bigint NOT NULL,
+  total_exec_time double precision NOT NULL,
+  mean_exec_time double precision NOT NULL
+);
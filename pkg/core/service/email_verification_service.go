@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -11,9 +12,11 @@ import (
 	"ctoup.com/coreapp/pkg/core/db/repository"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	"ctoup.com/coreapp/pkg/shared/emailservice"
+	access "ctoup.com/coreapp/pkg/shared/service"
 	"ctoup.com/coreapp/pkg/shared/util"
 	utils "ctoup.com/coreapp/pkg/shared/util"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const (
@@ -21,8 +24,38 @@ const (
 	EmailVerificationTokenExpiry = 24 * time.Hour
 	// Token length in bytes (will be base64 encoded)
 	TokenLength = 32
+	// Default age in days of the unverified accounts listed by the report
+	DefaultVerificationReportDays = 30
+	// Maximum number of unverified accounts listed by the report
+	MaxVerificationReportUsers = 1000
 )
 
+// UnverifiedUser is an enabled account whose email is still unverified.
+// LastVerificationSentAt is nil when no verification email is on record,
+// the tokens being deleted once expired.
+type UnverifiedUser struct {
+	UserID                 string
+	Email                  string
+	Name                   string
+	CreatedAt              time.Time
+	LastVerificationSentAt *time.Time
+	LastLoginAt            *time.Time
+}
+
+// VerificationReport counts the users of a tenant by the state of their
+// email and lists the enabled accounts still unverified after OlderThanDays
+type VerificationReport struct {
+	GeneratedAt     time.Time
+	OlderThanDays   int
+	Total           int64
+	Verified        int64
+	Unverified      int64
+	Disabled        int64
+	StaleUnverified int64
+	Users           []UnverifiedUser
+	Truncated       bool
+}
+
 type EmailVerificationService struct {
 	store        *db.Store
 	authProvider auth.AuthProvider
@@ -120,6 +153,13 @@ func (s *EmailVerificationService) VerifyEmailToken(ctx *gin.Context, token stri
 		logger.Err(err).Msg("Failed to update user email verification status")
 		return fmt.Errorf("failed to update user email verification status: %w", err)
 	}
+	if err := s.store.SetUserAccountState(ctx, repository.SetUserAccountStateParams{
+		EmailVerified: pgtype.Bool{Bool: true, Valid: true},
+		UserID:        tokenRecord.UserID,
+	}); err != nil {
+		// The user lists show the state until the next change
+		logger.Err(err).Msg("Failed to record the email verification state")
+	}
 
 	// Mark token as used
 	if err := s.store.MarkEmailVerificationTokenAsUsed(ctx, repository.MarkEmailVerificationTokenAsUsedParams{
@@ -153,6 +193,7 @@ func (s *EmailVerificationService) SendVerificationEmail(ctx *gin.Context, email
 
 	// Create email request
 	r := emailservice.NewEmailRequest(fromEmail, []string{email}, "Please verify your email address", "")
+	r.TenantID = ctx.GetString(auth.AUTH_TENANT_ID_KEY)
 
 	if err := r.ParseTemplateWithDomain(ctx, "email-verification.html", templateData); err != nil {
 		logger.Err(err).Msg("Failed to parse email verification template")
@@ -214,6 +255,57 @@ func (s *EmailVerificationService) GetUserVerificationStatus(ctx *gin.Context, u
 	return userRecord.EmailVerified, nil
 }
 
+// GetVerificationReport builds the verification report of the tenant from the
+// account states the verifications and logins record, listing at most limit
+// accounts, the oldest first
+func (s *EmailVerificationService) GetVerificationReport(ctx context.Context, tenantID string, olderThanDays int, limit int32) (VerificationReport, error) {
+	now := time.Now().UTC()
+	report := VerificationReport{GeneratedAt: now, OlderThanDays: olderThanDays, Users: []UnverifiedUser{}}
+
+	counts, err := s.store.CountUserVerificationStates(ctx, tenantID)
+	if err != nil {
+		return report, fmt.Errorf("service.GetVerificationReport: %w", err)
+	}
+	report.Total = counts.Total
+	report.Verified = counts.Verified
+	report.Unverified = counts.Unverified
+	report.Disabled = counts.Disabled
+
+	createdBefore := now.AddDate(0, 0, -olderThanDays)
+	report.StaleUnverified, err = s.store.CountStaleUnverifiedUsers(ctx, repository.CountStaleUnverifiedUsersParams{
+		TenantID:      tenantID,
+		CreatedBefore: createdBefore,
+	})
+	if err != nil {
+		return report, fmt.Errorf("service.GetVerificationReport: %w", err)
+	}
+	rows, err := s.store.ListStaleUnverifiedUsers(ctx, repository.ListStaleUnverifiedUsersParams{
+		TenantID:      tenantID,
+		CreatedBefore: createdBefore,
+		MaxRows:       limit,
+	})
+	if err != nil {
+		return report, fmt.Errorf("service.GetVerificationReport: %w", err)
+	}
+	for _, row := range rows {
+		user := UnverifiedUser{
+			UserID:    row.ID,
+			Email:     access.DecryptEmail(row.Email).String,
+			Name:      row.Profile.Name,
+			CreatedAt: row.CreatedAt,
+		}
+		if row.LastVerificationSentAt.Valid {
+			user.LastVerificationSentAt = &row.LastVerificationSentAt.Time
+		}
+		if row.LastLoginAt.Valid {
+			user.LastLoginAt = &row.LastLoginAt.Time
+		}
+		report.Users = append(report.Users, user)
+	}
+	report.Truncated = int64(len(report.Users)) < report.StaleUnverified
+	return report, nil
+}
+
 // CleanupExpiredTokens removes expired verification tokens
 func (s *EmailVerificationService) CleanupExpiredTokens(ctx *gin.Context) error {
 	if err := s.store.DeleteExpiredEmailVerificationTokens(ctx); err != nil {
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createReportTenant(t *testing.T, store *db.Store) string {
+	t.Helper()
+	tenant, err := store.CreateTenant(context.Background(), repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	return tenant.TenantID
+}
+
+// createReportUser adds a member of the tenant created ageDays ago, with the
+// given account state
+func createReportUser(t *testing.T, store *db.Store, tenantID, name string, ageDays int, verified, disabled bool) string {
+	t.Helper()
+	ctx := context.Background()
+	userID := commontestutils.RandomString(12)
+	_, err := store.CreateSharedUserWithTenant(ctx, repository.CreateSharedUserWithTenantParams{
+		ID:          userID,
+		Email:       strings.ToLower(name) + "-" + userID + "@example.com",
+		Profile:     subentity.UserProfile{Name: name},
+		TenantID:    tenantID,
+		TenantRoles: []string{"USER"},
+	})
+	require.NoError(t, err)
+	_, err = store.ConnPool.Exec(ctx, "UPDATE core_users SET created_at = NOW() - make_interval(days => $1) WHERE id = $2", ageDays, userID)
+	require.NoError(t, err)
+	require.NoError(t, store.SetUserAccountState(ctx, repository.SetUserAccountStateParams{
+		UserID:        userID,
+		Disabled:      pgtype.Bool{Bool: disabled, Valid: true},
+		EmailVerified: pgtype.Bool{Bool: verified, Valid: true},
+	}))
+	return userID
+}
+
+func TestVerificationReport(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewEmailVerificationService(store, nil)
+	ctx := context.Background()
+	tenantID := createReportTenant(t, store)
+
+	oldest := createReportUser(t, store, tenantID, "Oldest", 90, false, false)
+	reminded := createReportUser(t, store, tenantID, "Reminded", 45, false, false)
+	createReportUser(t, store, tenantID, "Verified", 60, true, false)
+	createReportUser(t, store, tenantID, "Disabled", 60, false, true)
+	createReportUser(t, store, tenantID, "Recent", 5, false, false)
+	// Not a member of the tenant
+	createReportUser(t, store, createReportTenant(t, store), "Other", 90, false, false)
+
+	sentAt := time.Now().Add(-48 * time.Hour)
+	_, err := store.CreateEmailVerificationToken(ctx, repository.CreateEmailVerificationTokenParams{
+		UserID:    reminded,
+		TenantID:  tenantID,
+		Token:     commontestutils.RandomString(32),
+		TokenHash: []byte(commontestutils.RandomString(32)),
+		ExpiresAt: sentAt.Add(EmailVerificationTokenExpiry),
+	})
+	require.NoError(t, err)
+	loginAt := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	_, err = store.RecordUserLogin(ctx, repository.RecordUserLoginParams{
+		UserID:      reminded,
+		TenantID:    tenantID,
+		SessionID:   commontestutils.RandomString(16),
+		LastLoginAt: loginAt,
+	})
+	require.NoError(t, err)
+
+	report, err := service.GetVerificationReport(ctx, tenantID, DefaultVerificationReportDays, MaxVerificationReportUsers)
+	require.NoError(t, err)
+	require.Equal(t, DefaultVerificationReportDays, report.OlderThanDays)
+	require.Equal(t, int64(5), report.Total)
+	require.Equal(t, int64(1), report.Verified)
+	require.Equal(t, int64(4), report.Unverified)
+	require.Equal(t, int64(1), report.Disabled)
+	// Neither the verified, the disabled nor the recent account
+	require.Equal(t, int64(2), report.StaleUnverified)
+	require.False(t, report.Truncated)
+	require.Len(t, report.Users, 2)
+
+	first := report.Users[0]
+	require.Equal(t, oldest, first.UserID)
+	require.Equal(t, "Oldest", first.Name)
+	require.Equal(t, "oldest-"+oldest+"@example.com", first.Email)
+	require.Nil(t, first.LastVerificationSentAt)
+	require.Nil(t, first.LastLoginAt)
+
+	second := report.Users[1]
+	require.Equal(t, reminded, second.UserID)
+	require.True(t, second.CreatedAt.After(first.CreatedAt))
+	require.NotNil(t, second.LastVerificationSentAt)
+	require.WithinDuration(t, time.Now(), *second.LastVerificationSentAt, time.Minute)
+	require.NotNil(t, second.LastLoginAt)
+	require.True(t, loginAt.Equal(*second.LastLoginAt))
+
+	// The limit keeps the oldest accounts and reports the rest
+	report, err = service.GetVerificationReport(ctx, tenantID, DefaultVerificationReportDays, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), report.StaleUnverified)
+	require.True(t, report.Truncated)
+	require.Len(t, report.Users, 1)
+	require.Equal(t, oldest, report.Users[0].UserID)
+
+	// A shorter age takes the recent account in
+	report, err = service.GetVerificationReport(ctx, tenantID, 1, MaxVerificationReportUsers)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), report.StaleUnverified)
+}
@@ -1,79 +1,38 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
 
+	access "ctoup.com/coreapp/pkg/shared/service"
 	"github.com/gin-gonic/gin"
 )
 
-// RateLimiter implements a simple in-memory rate limiter
+// RateLimiter allows limit requests per window and key, in memory. It is the
+// shared token bucket limiter with a single window.
 type RateLimiter struct {
-	requests map[string][]time.Time
-	mutex    sync.RWMutex
-	limit    int
-	window   time.Duration
+	limiter *access.MemoryRateLimiter
+	window  access.RateWindow
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 	return &RateLimiter{
-		requests: make(map[string][]time.Time),
-		limit:    limit,
-		window:   window,
+		limiter: access.NewMemoryRateLimiter(),
+		window:  access.RateWindow{Limit: limit, Period: window},
 	}
 }
 
 // IsAllowed checks if a request is allowed for the given key
 func (rl *RateLimiter) IsAllowed(key string) bool {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
-
-	now := time.Now()
-	
-	// Clean up old requests
-	if requests, exists := rl.requests[key]; exists {
-		var validRequests []time.Time
-		for _, reqTime := range requests {
-			if now.Sub(reqTime) < rl.window {
-				validRequests = append(validRequests, reqTime)
-			}
-		}
-		rl.requests[key] = validRequests
-	}
-
-	// Check if limit is exceeded
-	if len(rl.requests[key]) >= rl.limit {
-		return false
-	}
-
-	// Add current request
-	rl.requests[key] = append(rl.requests[key], now)
-	return true
+	allowed, _, _ := rl.limiter.Allow(context.Background(), key, rl.window)
+	return allowed
 }
 
 // GetRemainingRequests returns the number of remaining requests for the key
 func (rl *RateLimiter) GetRemainingRequests(key string) int {
-	rl.mutex.RLock()
-	defer rl.mutex.RUnlock()
-
-	now := time.Now()
-	count := 0
-	
-	if requests, exists := rl.requests[key]; exists {
-		for _, reqTime := range requests {
-			if now.Sub(reqTime) < rl.window {
-				count++
-			}
-		}
-	}
-
-	remaining := rl.limit - count
-	if remaining < 0 {
-		return 0
-	}
-	return remaining
+	return rl.limiter.Remaining(key, rl.window)
 }
 
 // EmailVerificationRateLimiter is a global rate limiter for email verification
@@ -82,11 +41,11 @@ var EmailVerificationRateLimiter = NewRateLimiter(3, 15*time.Minute) // 3 reques
 // CheckEmailVerificationRateLimit checks rate limit for email verification
 func CheckEmailVerificationRateLimit(c *gin.Context, userID string) error {
 	key := fmt.Sprintf("email_verification:%s", userID)
-	
+
 	if !EmailVerificationRateLimiter.IsAllowed(key) {
 		remaining := EmailVerificationRateLimiter.GetRemainingRequests(key)
 		return fmt.Errorf("rate limit exceeded. You can request %d more verification emails in 15 minutes", remaining)
 	}
-	
+
 	return nil
 }
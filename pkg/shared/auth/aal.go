@@ -12,6 +12,17 @@ const (
 	AUTH_AAL_INFO_KEY      = "auth_aal_info"      // Complete AAL info
 )
 
+// CLAIM_MFA_TOTP is set to true in the claims of a user who enabled the TOTP
+// second factor of the core. The auth middleware then requires the
+// X-MFA-Token of a verified session on every request.
+const CLAIM_MFA_TOTP = "mfa_totp"
+
+// HasTOTPEnrolled reports whether the claims carry CLAIM_MFA_TOTP
+func HasTOTPEnrolled(claims map[string]interface{}) bool {
+	enrolled, _ := claims[CLAIM_MFA_TOTP].(bool)
+	return enrolled
+}
+
 // AALInfo contains both current and available AAL levels
 type AALInfo struct {
 	Current      string // Current session AAL (aal1 or aal2)
@@ -0,0 +1,383 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrForbidden matches the errors returned by Authorize
+var ErrForbidden = errors.New("forbidden")
+
+// ForbiddenError is returned by Authorize when a rule denies the operation.
+// Its message is the reason of the rule.
+type ForbiddenError struct {
+	Operation Operation
+	Reason    string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Reason
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// Operation names an action guarded by the authorizer, such as
+// "users:manage" or "roles:assign:ADMIN"
+type Operation string
+
+// Operations of the core. Modules register rules for their own.
+const (
+	OpManageUsers                    Operation = "users:manage"
+	OpManageGlobalUsers              Operation = "users:manage:global"
+	OpManageAnnouncements            Operation = "announcements:manage"
+	OpManageTenantClientApplications Operation = "client_applications:manage:tenant"
+	OpSetClientApplicationQuotas     Operation = "client_applications:quotas:set"
+	OpManageTenantExports            Operation = "tenant_exports:manage"
+	OpManageDirectorySync            Operation = "directory_sync:manage"
+	OpManageDelegations              Operation = "delegations:manage"
+	OpManageGroups                   Operation = "groups:manage"
+	OpManageRoles                    Operation = "roles:manage"
+	OpManageUserAttributes           Operation = "user_attributes:manage"
+	OpManageLLMConsent               Operation = "llm_consent:manage"
+	OpManageEmailTemplates           Operation = "email_templates:manage"
+	OpManageEmailProvider            Operation = "email_provider:manage"
+	OpManageExecutionWebhooks        Operation = "execution_webhooks:manage"
+	OpViewTenantQuotas               Operation = "tenant_quotas:view"
+	OpViewTenantUsage                Operation = "tenant_usage:view"
+	OpManageTenantSettings           Operation = "tenant_settings:manage"
+	OpListResellerTenants            Operation = "tenants:list:reseller"
+	OpListAllTenants                 Operation = "tenants:list:global"
+	OpUpdateTenantContract           Operation = "tenants:contract:update"
+	OpUpdateTenantReseller           Operation = "tenants:reseller:update"
+	OpImpersonateUsers               Operation = "users:impersonate"
+	OpExportTenantData               Operation = "tenants:data:export"
+	OpPurgeTenant                    Operation = "tenants:purge"
+	OpProvisionTenant                Operation = "tenants:provision"
+	OpManageTenantTemplates          Operation = "tenant_templates:manage"
+	OpManageFeatureFlags             Operation = "feature_flags:manage"
+)
+
+// OpAssignRole is the operation of granting or removing the role
+func OpAssignRole(role core.Role) Operation {
+	return Operation("roles:assign:" + string(role))
+}
+
+// Subject roles the rules are written against. Besides the roles of the
+// caller, the reseller flags of its tenant are subject roles too.
+const (
+	SubjectSuperAdmin     = string(core.SUPERADMIN)
+	SubjectAdmin          = string(core.ADMIN)
+	SubjectCustomerAdmin  = string(core.CUSTOMERADMIN)
+	SubjectActingReseller = ACTING_RESELLER
+	SubjectReseller       = TENANT_IS_RESELLER
+)
+
+// Rule allows an operation to the subjects holding one of its roles, or to any
+// authenticated subject. A rule allowing nobody denies the operation.
+type Rule struct {
+	Roles            []string
+	AnyAuthenticated bool
+	// Message is the reason given when the rule denies
+	Message string
+}
+
+// Subject is the caller an operation is authorized for
+type Subject struct {
+	UserID   string
+	TenantID string
+	Roles    []string
+	// Permissions are the operations granted by the custom roles of the
+	// caller, on top of what its roles allow
+	Permissions []Operation
+}
+
+// Decision is the outcome of evaluating an operation for a subject
+type Decision struct {
+	Operation Operation
+	Allowed   bool
+	// Matched is false when no rule is registered for the operation
+	Matched bool
+	Reason  string
+	// Delegation is the delegation that allowed the operation, if any
+	Delegation *Delegation
+}
+
+// Authorizer evaluates operations against declarative rules. In strict mode an
+// operation without a rule is denied; otherwise it is allowed and logged, so
+// rules can be rolled out before enforcing them.
+type Authorizer struct {
+	mu          sync.RWMutex
+	rules       map[Operation]Rule
+	strict      bool
+	delegations DelegationSource
+}
+
+// NewAuthorizer creates an authorizer with the rules of the core
+func NewAuthorizer(strict bool) *Authorizer {
+	a := &Authorizer{rules: map[Operation]Rule{}, strict: strict}
+	a.Register(defaultRules())
+	return a
+}
+
+func defaultRules() map[Operation]Rule {
+	tenantAdmins := []string{SubjectCustomerAdmin, SubjectActingReseller, SubjectAdmin, SubjectSuperAdmin}
+	return map[Operation]Rule{
+		OpManageUsers: {Roles: tenantAdmins,
+			Message: "Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage users"},
+		OpManageGlobalUsers: {Roles: []string{SubjectSuperAdmin},
+			Message: "Only SUPER_ADMIN can manage users without tenant"},
+		OpAssignRole(core.USER): {AnyAuthenticated: true},
+		OpAssignRole(core.CUSTOMERADMIN): {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "must be at a CUSTOMER_ADMIN or SUPER_ADMIN or ADMIN to perform such operation"},
+		OpAssignRole(core.ADMIN): {Roles: []string{SubjectAdmin, SubjectSuperAdmin},
+			Message: "must be an ADMIN or SUPER_ADMIN to perform such operation"},
+		OpAssignRole(core.SUPERADMIN): {Roles: []string{SubjectSuperAdmin},
+			Message: "must be an SUPER_ADMIN to perform such operation"},
+		OpManageAnnouncements: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Need to be an ADMIN to perform such operation"},
+		OpManageTenantClientApplications: {Roles: []string{SubjectCustomerAdmin},
+			Message: "Need to be a CUSTOMER_ADMIN to perform such operation"},
+		OpManageTenantExports: {Roles: []string{SubjectCustomerAdmin},
+			Message: "Need to be a CUSTOMER_ADMIN to perform such operation"},
+		OpManageDirectorySync: {Roles: []string{SubjectCustomerAdmin},
+			Message: "Need to be a CUSTOMER_ADMIN to perform such operation"},
+		OpManageDelegations: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the delegations of other users"},
+		OpManageGroups: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the groups of the tenant"},
+		OpManageRoles: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the custom roles of the tenant"},
+		OpManageUserAttributes: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the user attributes of the tenant"},
+		OpManageLLMConsent: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the LLM data consent of the tenant"},
+		OpManageEmailTemplates: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the email templates of the tenant"},
+		OpManageEmailProvider: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the email provider of the tenant"},
+		OpManageExecutionWebhooks: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the result webhooks of the tenant"},
+		OpViewTenantQuotas: {Roles: tenantAdmins,
+			Message: "Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can view the quotas of the tenant"},
+		OpViewTenantUsage: {Roles: tenantAdmins,
+			Message: "Only RESELLER, CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can view the usage of the tenant"},
+		OpManageTenantSettings: {Roles: []string{SubjectCustomerAdmin, SubjectAdmin, SubjectSuperAdmin},
+			Message: "Only CUSTOMER_ADMIN, ADMIN or SUPER_ADMIN can manage the settings of the tenant"},
+		OpListResellerTenants: {Roles: []string{SubjectActingReseller, SubjectReseller},
+			Message: "forbidden: must be a CUSTOMER_ADMIN of a reseller tenant"},
+		OpListAllTenants: {Roles: []string{SubjectAdmin, SubjectSuperAdmin},
+			Message: "only ADMIN or SUPER_ADMIN may list every tenant"},
+		OpUpdateTenantContract: {Roles: []string{SubjectAdmin, SubjectSuperAdmin},
+			Message: "only ADMIN, SUPER_ADMIN or the reseller of the tenant may update its contract"},
+		OpUpdateTenantReseller: {Roles: []string{SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN may change the reseller of a tenant"},
+		OpImpersonateUsers: {Roles: []string{SubjectSuperAdmin},
+			Message: "Only a SUPER_ADMIN can impersonate a user"},
+		OpExportTenantData: {Roles: []string{SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN may export the data of a tenant"},
+		OpPurgeTenant: {Roles: []string{SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN may purge a tenant"},
+		OpProvisionTenant: {Roles: []string{SubjectReseller, SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN or a reseller may provision a tenant"},
+		OpManageTenantTemplates: {Roles: []string{SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN may manage the tenant templates"},
+		OpManageFeatureFlags: {Roles: []string{SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN may manage the feature flags"},
+		OpSetClientApplicationQuotas: {Roles: []string{SubjectSuperAdmin},
+			Message: "only SUPER_ADMIN may set the token quota of a client application"},
+	}
+}
+
+// Register adds or replaces the rules of operations
+func (a *Authorizer) Register(rules map[Operation]Rule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for op, rule := range rules {
+		a.rules[op] = rule
+	}
+}
+
+// SetStrict switches deny-by-default on or off
+func (a *Authorizer) SetStrict(strict bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.strict = strict
+}
+
+// Evaluate decides whether the subject may perform the operation
+func (a *Authorizer) Evaluate(subject Subject, op Operation) Decision {
+	a.mu.RLock()
+	rule, ok := a.rules[op]
+	strict := a.strict
+	a.mu.RUnlock()
+
+	if !ok {
+		if strict {
+			return Decision{Operation: op, Reason: "no rule for operation " + string(op)}
+		}
+		return Decision{Operation: op, Allowed: true, Reason: "no rule for operation " + string(op)}
+	}
+	decision := Decision{Operation: op, Matched: true}
+	switch {
+	case rule.AnyAuthenticated && subject.UserID != "":
+		decision.Allowed = true
+	case slices.ContainsFunc(subject.Roles, func(role string) bool { return slices.Contains(rule.Roles, role) }):
+		decision.Allowed = true
+	case slices.Contains(subject.Permissions, op) && grantable(op, rule):
+		decision.Allowed = true
+		decision.Reason = "granted by a custom role"
+	default:
+		decision.Reason = rule.Message
+		if decision.Reason == "" {
+			decision.Reason = "not allowed to perform " + string(op)
+		}
+	}
+	return decision
+}
+
+// Grantable reports whether a custom role may grant the operation: a tenant
+// operation a CUSTOMER_ADMIN may perform, other than assigning a built-in role
+func (a *Authorizer) Grantable(op Operation) bool {
+	a.mu.RLock()
+	rule, ok := a.rules[op]
+	a.mu.RUnlock()
+	return ok && grantable(op, rule)
+}
+
+func grantable(op Operation, rule Rule) bool {
+	if strings.HasPrefix(string(op), "roles:assign:") {
+		return false
+	}
+	return rule.AnyAuthenticated || slices.Contains(rule.Roles, SubjectCustomerAdmin)
+}
+
+// Authorize evaluates the operation for the caller of the request and logs
+// the decision. An operation the roles of the caller deny is still allowed by
+// an active delegation; the delegator is then set on the request. The error
+// is a *ForbiddenError.
+func (a *Authorizer) Authorize(c *gin.Context, op Operation) error {
+	subject := SubjectFromContext(c)
+	decision := a.Evaluate(subject, op)
+
+	logger := util.GetLoggerFromCtx(c)
+	a.mu.RLock()
+	delegations := a.delegations
+	a.mu.RUnlock()
+	if !decision.Allowed && decision.Matched && delegations != nil && subject.UserID != "" && subject.TenantID != "" {
+		active, err := delegations.ActiveDelegations(c, subject.TenantID, subject.UserID)
+		if err != nil {
+			logger.Err(err).Str("user_id", subject.UserID).Msg("Failed to load the delegations of the user")
+		} else if delegation, ok := a.EvaluateDelegations(subject, op, active); ok {
+			decision.Allowed = true
+			decision.Reason = "delegated by " + delegation.DelegatorID
+			decision.Delegation = &delegation
+			c.Set(AUTH_DELEGATOR_ID, delegation.DelegatorID)
+			c.Set(AUTH_DELEGATION_ID, delegation.ID)
+		}
+	}
+
+	event := logger.Debug()
+	switch {
+	case !decision.Allowed:
+		event = logger.Info()
+	case !decision.Matched:
+		event = logger.Warn()
+	}
+	event.Str("operation", string(op)).
+		Str("user_id", subject.UserID).
+		Str("tenant_id", subject.TenantID).
+		Strs("roles", subject.Roles).
+		Bool("allowed", decision.Allowed).
+		Str("reason", decision.Reason).
+		Msg("Authorization decision")
+
+	if !decision.Allowed {
+		return &ForbiddenError{Operation: op, Reason: decision.Reason}
+	}
+	return nil
+}
+
+// SubjectFromContext reads the caller from the claims and the permissions set
+// by the auth middleware
+func SubjectFromContext(c *gin.Context) Subject {
+	subject := Subject{
+		UserID:   c.GetString(AUTH_USER_ID),
+		TenantID: c.GetString(AUTH_TENANT_ID_KEY),
+	}
+	for _, permission := range c.GetStringSlice(AUTH_PERMISSIONS) {
+		subject.Permissions = append(subject.Permissions, Operation(permission))
+	}
+	claims, ok := c.Get(AUTH_CLAIMS)
+	if !ok {
+		return subject
+	}
+	claimsMap, ok := claims.(map[string]interface{})
+	if !ok {
+		return subject
+	}
+	for _, role := range []string{SubjectSuperAdmin, SubjectAdmin, SubjectCustomerAdmin, SubjectActingReseller, SubjectReseller} {
+		if claimsMap[role] == true {
+			subject.Roles = append(subject.Roles, role)
+		}
+	}
+	return subject
+}
+
+var defaultAuthorizer = NewAuthorizer(false)
+
+// ConfigureAuthorizationFromEnv applies the environment to the default
+// authorizer. It is called once the environment is loaded.
+//
+// Environment:
+//   - AUTHZ_STRICT_MODE: deny the operations without a rule (default false)
+func ConfigureAuthorizationFromEnv() {
+	value := os.Getenv("AUTHZ_STRICT_MODE")
+	if value == "" {
+		return
+	}
+	strict, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warn().Str("AUTHZ_STRICT_MODE", value).Msg("Invalid boolean, strict mode disabled")
+		return
+	}
+	defaultAuthorizer.SetStrict(strict)
+}
+
+// DefaultAuthorizer returns the authorizer used by Authorize and Allowed
+func DefaultAuthorizer() *Authorizer {
+	return defaultAuthorizer
+}
+
+// RegisterRules adds the rules of a module's operations to the default
+// authorizer
+func RegisterRules(rules map[Operation]Rule) {
+	defaultAuthorizer.Register(rules)
+}
+
+// Authorize checks the operation for the caller with the default authorizer
+func Authorize(c *gin.Context, op Operation) error {
+	return defaultAuthorizer.Authorize(c, op)
+}
+
+// Allowed reports whether the caller may perform the operation
+func Allowed(c *gin.Context, op Operation) bool {
+	return defaultAuthorizer.Authorize(c, op) == nil
+}
+
+// HasPermission reports whether the roles or the custom roles of the caller
+// allow the operation. Unlike Allowed it neither logs the decision nor looks
+// up the delegations, so it suits the checks made on every request.
+func HasPermission(c *gin.Context, op Operation) bool {
+	return defaultAuthorizer.Evaluate(SubjectFromContext(c), op).Allowed
+}
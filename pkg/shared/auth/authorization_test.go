@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateRoleAssignment(t *testing.T) {
+	authorizer := NewAuthorizer(false)
+	customerAdmin := Subject{UserID: "u1", Roles: []string{SubjectCustomerAdmin}}
+	admin := Subject{UserID: "u2", Roles: []string{SubjectAdmin}}
+	superAdmin := Subject{UserID: "u3", Roles: []string{SubjectSuperAdmin}}
+
+	for _, tc := range []struct {
+		subject Subject
+		role    core.Role
+		allowed bool
+	}{
+		{customerAdmin, core.USER, true},
+		{customerAdmin, core.CUSTOMERADMIN, true},
+		{customerAdmin, core.ADMIN, false},
+		{customerAdmin, core.SUPERADMIN, false},
+		{admin, core.ADMIN, true},
+		{admin, core.SUPERADMIN, false},
+		{superAdmin, core.SUPERADMIN, true},
+		{Subject{}, core.USER, false},
+	} {
+		decision := authorizer.Evaluate(tc.subject, OpAssignRole(tc.role))
+		require.Equal(t, tc.allowed, decision.Allowed, "%v assigns %s", tc.subject.Roles, tc.role)
+		require.True(t, decision.Matched)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	authorizer := NewAuthorizer(false)
+	subject := Subject{UserID: "u1", Roles: []string{SubjectSuperAdmin}}
+
+	decision := authorizer.Evaluate(subject, "reports:export")
+	require.True(t, decision.Allowed)
+	require.False(t, decision.Matched)
+
+	authorizer.SetStrict(true)
+	require.False(t, authorizer.Evaluate(subject, "reports:export").Allowed)
+
+	authorizer.Register(map[Operation]Rule{"reports:export": {Roles: []string{SubjectSuperAdmin}}})
+	require.True(t, authorizer.Evaluate(subject, "reports:export").Allowed)
+
+	// A rule allowing nobody denies even in lenient mode
+	authorizer.SetStrict(false)
+	authorizer.Register(map[Operation]Rule{"reports:purge": {Message: "disabled"}})
+	decision = authorizer.Evaluate(subject, "reports:purge")
+	require.False(t, decision.Allowed)
+	require.Equal(t, "disabled", decision.Reason)
+}
+
+func TestAuthorizeFromClaims(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set(AUTH_USER_ID, "u1")
+	c.Set(AUTH_CLAIMS, map[string]interface{}{string(core.CUSTOMERADMIN): true, ACTING_RESELLER: false})
+
+	require.Equal(t, []string{SubjectCustomerAdmin}, SubjectFromContext(c).Roles)
+	require.NoError(t, Authorize(c, OpManageTenantExports))
+
+	err := Authorize(c, OpUpdateTenantReseller)
+	require.True(t, errors.Is(err, ErrForbidden))
+	require.Equal(t, "only SUPER_ADMIN may change the reseller of a tenant", err.Error())
+
+	require.Error(t, HasRightsForRole(c, core.ADMIN))
+	require.True(t, HasAdminPrivileges(c))
+}
+
+func TestCustomRolePermissions(t *testing.T) {
+	authorizer := NewAuthorizer(false)
+	user := Subject{UserID: "u1", Permissions: []Operation{OpManageGroups, OpUpdateTenantReseller, OpAssignRole(core.CUSTOMERADMIN)}}
+
+	decision := authorizer.Evaluate(user, OpManageGroups)
+	require.True(t, decision.Allowed)
+	require.Equal(t, "granted by a custom role", decision.Reason)
+	// Only the tenant operations are granted, never the built-in roles
+	require.False(t, authorizer.Evaluate(user, OpUpdateTenantReseller).Allowed)
+	require.False(t, authorizer.Evaluate(user, OpAssignRole(core.CUSTOMERADMIN)).Allowed)
+	require.False(t, authorizer.Evaluate(user, OpManageUsers).Allowed)
+
+	require.True(t, authorizer.Grantable(OpManageUsers))
+	require.False(t, authorizer.Grantable(OpImpersonateUsers))
+	require.False(t, authorizer.Grantable(OpAssignRole(core.USER)))
+	require.False(t, authorizer.Grantable("reports:export"))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set(AUTH_USER_ID, "u1")
+	c.Set(AUTH_PERMISSIONS, []string{string(OpManageUsers)})
+	require.True(t, HasPermission(c, OpManageUsers))
+	require.False(t, HasPermission(c, OpManageGroups))
+}
+
+type staticDelegations []Delegation
+
+func (d staticDelegations) ActiveDelegations(_ context.Context, _, _ string) ([]Delegation, error) {
+	return d, nil
+}
+
+func TestAuthorizeWithDelegation(t *testing.T) {
+	authorizer := NewAuthorizer(false)
+	authorizer.SetDelegationSource(staticDelegations{
+		{ID: "d1", DelegatorID: "admin", DelegatorRoles: []string{SubjectCustomerAdmin},
+			Operations: []Operation{OpManageAnnouncements, OpUpdateTenantReseller}},
+	})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set(AUTH_USER_ID, "u1")
+	c.Set(AUTH_TENANT_ID_KEY, "t1")
+
+	require.NoError(t, authorizer.Authorize(c, OpManageAnnouncements))
+	require.Equal(t, "admin", c.GetString(AUTH_DELEGATOR_ID))
+	require.Equal(t, "d1", c.GetString(AUTH_DELEGATION_ID))
+
+	// Only what the delegator may do is delegated
+	require.ErrorIs(t, authorizer.Authorize(c, OpUpdateTenantReseller), ErrForbidden)
+	// and only the delegated operations
+	require.ErrorIs(t, authorizer.Authorize(c, OpManageTenantExports), ErrForbidden)
+}
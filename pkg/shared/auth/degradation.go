@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DegradedHeader marks the responses of requests authenticated while the
+	// provider was unreachable, from a verification kept for grace mode
+	DegradedHeader = "X-Auth-Degraded"
+	// DegradedHeaderGrace is the value of DegradedHeader for a verification
+	// served from the grace cache
+	DegradedHeaderGrace = "grace"
+
+	defaultDeferredWritesMax     = 1000
+	defaultDegradedAlertInterval = 5 * time.Minute
+	deferredWriteTimeout         = 10 * time.Second
+)
+
+// DegradationAlert is called when the provider becomes unavailable, again
+// every alert interval while it stays so, and once when it recovers
+type DegradationAlert func(ctx context.Context, status ProviderHealthStatus, pendingWrites int)
+
+// deferredWrite is an auth provider write replayed once the provider is back
+type deferredWrite struct {
+	key      string
+	apply    func(ctx context.Context) error
+	queuedAt time.Time
+}
+
+// DeferredWriteQueue keeps the non-critical provider writes (e.g. claims
+// updates) that failed during an outage, in order. A write queued again under
+// the same key replaces the pending one.
+type DeferredWriteQueue struct {
+	max int
+
+	mu     sync.Mutex
+	writes []deferredWrite
+}
+
+func NewDeferredWriteQueue(max int) *DeferredWriteQueue {
+	if max <= 0 {
+		max = defaultDeferredWritesMax
+	}
+	return &DeferredWriteQueue{max: max}
+}
+
+// Enqueue keeps the write, and returns false when the queue is full
+func (q *DeferredWriteQueue) Enqueue(key string, apply func(ctx context.Context) error) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, write := range q.writes {
+		if write.key == key {
+			q.writes = append(q.writes[:i], q.writes[i+1:]...)
+			break
+		}
+	}
+	if len(q.writes) >= q.max {
+		return false
+	}
+	q.writes = append(q.writes, deferredWrite{key: key, apply: apply, queuedAt: time.Now()})
+	return true
+}
+
+// Len returns the number of pending writes
+func (q *DeferredWriteQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.writes)
+}
+
+// Flush applies the pending writes in order. It stops at the first write
+// failing because the provider is unavailable again, keeping it and the ones
+// after it; writes failing for another reason are dropped.
+func (q *DeferredWriteQueue) Flush(ctx context.Context) (applied int, failed int) {
+	for {
+		q.mu.Lock()
+		if len(q.writes) == 0 {
+			q.mu.Unlock()
+			return applied, failed
+		}
+		write := q.writes[0]
+		q.mu.Unlock()
+
+		writeCtx, cancel := context.WithTimeout(ctx, deferredWriteTimeout)
+		err := write.apply(writeCtx)
+		cancel()
+		if IsProviderUnavailable(err) {
+			return applied, failed
+		}
+
+		q.mu.Lock()
+		// A newer write under the same key may have replaced it meanwhile
+		if len(q.writes) > 0 && q.writes[0].key == write.key && q.writes[0].queuedAt.Equal(write.queuedAt) {
+			q.writes = q.writes[1:]
+		}
+		q.mu.Unlock()
+
+		if err != nil {
+			failed++
+			log.Err(err).Str("key", write.key).Time("queued_at", write.queuedAt).Msg("Deferred auth provider write failed, dropping it")
+			continue
+		}
+		applied++
+	}
+}
+
+// DeferWrite queues a write that failed because the provider is unavailable,
+// to be replayed after the next successful probe. It returns false when
+// degradation mode is off or the queue is full, in which case the caller
+// should report the failure.
+func (m *ProviderHealthMonitor) DeferWrite(key string, apply func(ctx context.Context) error) bool {
+	if !m.GraceEnabled() {
+		return false
+	}
+	if !m.writes.Enqueue(key, apply) {
+		log.Error().Str("provider", m.provider).Str("key", key).Msg("Deferred auth provider write queue is full")
+		return false
+	}
+	return true
+}
+
+// PendingWrites returns the number of deferred writes waiting for the provider
+func (m *ProviderHealthMonitor) PendingWrites() int {
+	return m.writes.Len()
+}
+
+// Degraded reports whether requests are being served in degradation mode:
+// the provider is unavailable and grace mode is on
+func (m *ProviderHealthMonitor) Degraded() bool {
+	return m.GraceEnabled() && !m.Status().Healthy
+}
+
+// OnDegraded registers an alert hook, in addition to the error logged while
+// degraded
+func (m *ProviderHealthMonitor) OnDegraded(alert DegradationAlert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alerts = append(m.alerts, alert)
+}
+
+// afterProbe replays the deferred writes once the provider is back, and
+// raises the alerts while it is not
+func (m *ProviderHealthMonitor) afterProbe(ctx context.Context, status ProviderHealthStatus) {
+	if status.Healthy {
+		if pending := m.writes.Len(); pending > 0 {
+			applied, failed := m.writes.Flush(ctx)
+			log.Info().Str("provider", m.provider).Int("applied", applied).Int("failed", failed).
+				Int("pending", m.writes.Len()).Msg("Replayed deferred auth provider writes")
+		}
+	}
+
+	m.mu.Lock()
+	now := time.Now()
+	notify := false
+	switch {
+	case !status.Healthy && (m.lastAlert.IsZero() || now.Sub(m.lastAlert) >= m.alertInterval):
+		m.lastAlert = now
+		notify = true
+	case status.Healthy && !m.lastAlert.IsZero():
+		m.lastAlert = time.Time{}
+		notify = true
+	}
+	alerts := append([]DegradationAlert(nil), m.alerts...)
+	m.mu.Unlock()
+	if !notify {
+		return
+	}
+
+	pending := m.writes.Len()
+	if status.Healthy {
+		log.Info().Str("provider", m.provider).Int("pending_writes", pending).Msg("Auth provider recovered, leaving degradation mode")
+	} else {
+		log.Error().Bool("alert", true).Str("provider", m.provider).
+			Time("unhealthy_since", status.UnhealthySince).
+			Int("consecutive_failures", status.ConsecutiveFailures).
+			Bool("grace_mode", m.GraceEnabled()).
+			Int("pending_writes", pending).
+			Str("last_error", status.LastError).
+			Msg("Auth provider unavailable")
+	}
+	for _, alert := range alerts {
+		alert(ctx, status, pending)
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Set on the request when an operation was allowed by a delegation, so the
+// action can be attributed to the delegator as well
+const (
+	AUTH_DELEGATOR_ID  = "auth_delegator_id"
+	AUTH_DELEGATION_ID = "auth_delegation_id"
+)
+
+// Delegation lends operations of a delegator to another user of the tenant
+type Delegation struct {
+	ID          string
+	DelegatorID string
+	// DelegatorRoles are the roles of the delegator the operations are
+	// evaluated against
+	DelegatorRoles []string
+	Operations     []Operation
+}
+
+// DelegationSource returns the delegations a user holds right now in a tenant
+type DelegationSource interface {
+	ActiveDelegations(ctx context.Context, tenantID, userID string) ([]Delegation, error)
+}
+
+// SetDelegationSource makes the authorizer allow the operations delegated to
+// the caller. A nil source disables delegations.
+func (a *Authorizer) SetDelegationSource(source DelegationSource) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.delegations = source
+}
+
+// EvaluateDelegations returns the first delegation granting the operation to
+// the subject. A delegation only grants what its rule allows the delegator.
+func (a *Authorizer) EvaluateDelegations(subject Subject, op Operation, delegations []Delegation) (Delegation, bool) {
+	for _, delegation := range delegations {
+		if !slices.Contains(delegation.Operations, op) {
+			continue
+		}
+		decision := a.Evaluate(Subject{
+			UserID:   delegation.DelegatorID,
+			TenantID: subject.TenantID,
+			Roles:    delegation.DelegatorRoles,
+		}, op)
+		if decision.Matched && decision.Allowed {
+			return delegation, true
+		}
+	}
+	return Delegation{}, false
+}
+
+// SetDelegationSource sets the delegation source of the default authorizer
+func SetDelegationSource(source DelegationSource) {
+	defaultAuthorizer.SetDelegationSource(source)
+}
@@ -25,10 +25,14 @@ const (
 	ErrorCodeTenantNotFound      = "tenant-not-found"
 	ErrorCodeSessionAAL2Required = "session_aal2_required"
 	ErrorCodeMFANotConfigured    = "mfa_not_configured"
+	ErrorCodeMFATOTPRequired     = "mfa_totp_required"
 	ErrorCodeInvalidToken        = "invalid_token"
 	ErrorCodeUserNotFound        = "user_not_found"
+	ErrorCodeSessionNotFound     = "session_not_found"
 	ErrorCodeUnauthorized        = "unauthorized"
 	ErrorCodeForbidden           = "forbidden"
+	ErrorCodeProviderUnavailable = "provider_unavailable"
+	ErrorCodeAccountLocked       = "account_locked"
 )
 
 // Helper functions for error checking
@@ -39,6 +43,26 @@ func IsUserNotFound(err error) bool {
 	return false
 }
 
+// IsSessionNotFound reports whether the session does not exist or belongs to
+// another user
+func IsSessionNotFound(err error) bool {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return authErr.Code == ErrorCodeSessionNotFound
+	}
+	return false
+}
+
+// IsProviderUnavailable reports whether the auth provider could not be reached
+// or failed on its side, as opposed to rejecting the credentials
+func IsProviderUnavailable(err error) bool {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return authErr.Code == ErrorCodeProviderUnavailable
+	}
+	return false
+}
+
 func IsEmailAlreadyExists(err error) bool {
 	if authErr, ok := err.(*AuthError); ok {
 		return authErr.Code == ErrorCodeEmailAlreadyExists
@@ -0,0 +1,367 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+)
+
+// ProviderHealthChecker is implemented by providers that can probe their backend
+// (e.g. Kratos admin API readiness)
+type ProviderHealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthReporter is implemented by providers that keep a health monitor, so the
+// server can start the probing and expose it on /readyz
+type HealthReporter interface {
+	GetHealthMonitor() *ProviderHealthMonitor
+}
+
+// ProviderHealthStatus is the last known state of the auth provider
+type ProviderHealthStatus struct {
+	Provider            string        `json:"provider"`
+	Healthy             bool          `json:"healthy"`
+	CheckedAt           time.Time     `json:"checked_at"`
+	ProbeLatency        time.Duration `json:"probe_latency"`
+	VerifyLatency       time.Duration `json:"verify_latency"`
+	LastError           string        `json:"last_error,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	UnhealthySince      time.Time     `json:"unhealthy_since,omitempty"`
+}
+
+type graceEntry struct {
+	user       AuthenticatedUser
+	verifiedAt time.Time
+}
+
+// ProviderHealthMonitor probes the auth provider periodically, records the
+// token verification latency and, when a grace period is configured, keeps the
+// recent successful verifications so they can be served while the provider is
+// briefly unreachable.
+//
+// Configuration:
+//   - AUTH_HEALTH_CHECK_INTERVAL: probe interval (default 30s)
+//   - AUTH_GRACE_PERIOD: how long a verification may be reused during an
+//     outage (default 0, grace mode disabled)
+//   - AUTH_DEFERRED_WRITES_MAX: claims updates kept for replay during an
+//     outage in grace mode (default 1000)
+//   - AUTH_DEGRADED_ALERT_INTERVAL: how often the outage alert is raised again
+//     (default 5m)
+type ProviderHealthMonitor struct {
+	provider    string
+	checker     ProviderHealthChecker
+	interval    time.Duration
+	gracePeriod time.Duration
+
+	mu     sync.RWMutex
+	status ProviderHealthStatus
+	cache  map[string]graceEntry
+
+	writes        *DeferredWriteQueue
+	alertInterval time.Duration
+	alerts        []DegradationAlert
+	lastAlert     time.Time
+
+	startOnce     sync.Once
+	attrs         metric.MeasurementOption
+	probeDuration metric.Float64Histogram
+	verifyLatency metric.Float64Histogram
+	graceServed   metric.Int64Counter
+	degraded      metric.Int64Counter
+}
+
+// NewProviderHealthMonitorFromEnv creates a monitor configured from the
+// environment. The provider is considered healthy until a probe says otherwise.
+func NewProviderHealthMonitorFromEnv(provider string, checker ProviderHealthChecker) *ProviderHealthMonitor {
+	m := NewProviderHealthMonitor(provider, checker,
+		durationFromEnv("AUTH_HEALTH_CHECK_INTERVAL", defaultHealthCheckInterval),
+		durationFromEnv("AUTH_GRACE_PERIOD", 0))
+	m.alertInterval = durationFromEnv("AUTH_DEGRADED_ALERT_INTERVAL", defaultDegradedAlertInterval)
+	if value := os.Getenv("AUTH_DEFERRED_WRITES_MAX"); value != "" {
+		if max, err := strconv.Atoi(value); err == nil && max > 0 {
+			m.writes = NewDeferredWriteQueue(max)
+		} else {
+			log.Warn().Str("AUTH_DEFERRED_WRITES_MAX", value).Msg("Invalid deferred writes limit, using default")
+		}
+	}
+	return m
+}
+
+func NewProviderHealthMonitor(provider string, checker ProviderHealthChecker, interval time.Duration, gracePeriod time.Duration) *ProviderHealthMonitor {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	m := &ProviderHealthMonitor{
+		provider:    provider,
+		checker:     checker,
+		interval:    interval,
+		gracePeriod: gracePeriod,
+		status:      ProviderHealthStatus{Provider: provider, Healthy: true},
+		cache:       make(map[string]graceEntry),
+		writes:      NewDeferredWriteQueue(defaultDeferredWritesMax),
+		attrs:       metric.WithAttributes(attribute.String("provider", provider)),
+	}
+	m.initMetrics()
+	return m
+}
+
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Warn().Str(name, value).Msg("Invalid duration, using default")
+		return fallback
+	}
+	return d
+}
+
+func (m *ProviderHealthMonitor) initMetrics() {
+	meter := otel.Meter("ctoup.com/coreapp/pkg/shared/auth")
+	var err error
+	if m.probeDuration, err = meter.Float64Histogram("auth_provider.probe.duration",
+		metric.WithUnit("ms"), metric.WithDescription("Auth provider health probe latency")); err != nil {
+		log.Err(err).Msg("Failed to create auth provider probe metric")
+	}
+	if m.verifyLatency, err = meter.Float64Histogram("auth_provider.verification.duration",
+		metric.WithUnit("ms"), metric.WithDescription("Auth provider token verification latency")); err != nil {
+		log.Err(err).Msg("Failed to create auth provider verification metric")
+	}
+	if m.graceServed, err = meter.Int64Counter("auth_provider.grace_verifications",
+		metric.WithDescription("Verifications served from the grace cache during a provider outage")); err != nil {
+		log.Err(err).Msg("Failed to create auth provider grace metric")
+	}
+	if m.degraded, err = meter.Int64Counter("auth_provider.degraded_requests",
+		metric.WithDescription("Requests served in degradation mode")); err != nil {
+		log.Err(err).Msg("Failed to create auth provider degraded metric")
+	}
+	_, err = meter.Int64ObservableGauge("auth_provider.deferred_writes",
+		metric.WithDescription("Auth provider writes waiting for the provider to be back"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(m.PendingWrites()), m.attrs)
+			return nil
+		}))
+	if err != nil {
+		log.Err(err).Msg("Failed to create auth provider deferred writes metric")
+	}
+	_, err = meter.Int64ObservableGauge("auth_provider.up",
+		metric.WithDescription("1 when the last auth provider probe succeeded"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			up := int64(0)
+			if m.Status().Healthy {
+				up = 1
+			}
+			o.Observe(up, m.attrs)
+			return nil
+		}))
+	if err != nil {
+		log.Err(err).Msg("Failed to create auth provider up metric")
+	}
+}
+
+// Start probes the provider now and then every interval until ctx is done.
+// Calling it more than once has no effect.
+func (m *ProviderHealthMonitor) Start(ctx context.Context) {
+	m.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(m.interval)
+			defer ticker.Stop()
+			for {
+				m.Probe(ctx)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+// Probe runs one health check and updates the status
+func (m *ProviderHealthMonitor) Probe(ctx context.Context) ProviderHealthStatus {
+	if m.checker == nil {
+		return m.Status()
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := m.checker.CheckHealth(probeCtx)
+	latency := time.Since(start)
+	if m.probeDuration != nil {
+		m.probeDuration.Record(ctx, float64(latency.Microseconds())/1000, m.attrs)
+	}
+
+	m.mu.Lock()
+	m.status.CheckedAt = time.Now()
+	m.status.ProbeLatency = latency
+	if err != nil {
+		m.markFailureLocked(err)
+	} else {
+		m.markHealthyLocked()
+	}
+	m.pruneLocked()
+	status := m.status
+	m.mu.Unlock()
+
+	if err != nil {
+		log.Warn().Err(err).Str("provider", m.provider).Int("consecutive_failures", status.ConsecutiveFailures).Msg("Auth provider health check failed")
+	}
+	m.afterProbe(ctx, status)
+	return status
+}
+
+func (m *ProviderHealthMonitor) markFailureLocked(err error) {
+	if m.status.Healthy {
+		m.status.UnhealthySince = time.Now()
+	}
+	m.status.Healthy = false
+	m.status.ConsecutiveFailures++
+	m.status.LastError = err.Error()
+}
+
+func (m *ProviderHealthMonitor) markHealthyLocked() {
+	m.status.Healthy = true
+	m.status.ConsecutiveFailures = 0
+	m.status.LastError = ""
+	m.status.UnhealthySince = time.Time{}
+}
+
+// pruneLocked drops the verifications too old to be served anymore
+func (m *ProviderHealthMonitor) pruneLocked() {
+	now := time.Now()
+	for key, entry := range m.cache {
+		if now.Sub(entry.verifiedAt) > m.gracePeriod {
+			delete(m.cache, key)
+		}
+	}
+}
+
+// Status returns a snapshot of the provider health
+func (m *ProviderHealthMonitor) Status() ProviderHealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Ready reports whether requests can be authenticated: the provider is healthy,
+// or the outage is still within the grace period
+func (m *ProviderHealthMonitor) Ready() bool {
+	status := m.Status()
+	if status.Healthy {
+		return true
+	}
+	return m.gracePeriod > 0 && time.Since(status.UnhealthySince) < m.gracePeriod
+}
+
+// ObserveVerification records the latency of a token verification. A
+// provider outage error marks the provider unhealthy without waiting for the
+// next probe.
+func (m *ProviderHealthMonitor) ObserveVerification(ctx context.Context, latency time.Duration, err error) {
+	if m.verifyLatency != nil {
+		m.verifyLatency.Record(ctx, float64(latency.Microseconds())/1000, m.attrs)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.VerifyLatency = latency
+	if IsProviderUnavailable(err) {
+		m.markFailureLocked(err)
+	}
+}
+
+// GraceEnabled reports whether verifications are kept for grace mode
+func (m *ProviderHealthMonitor) GraceEnabled() bool {
+	return m.gracePeriod > 0
+}
+
+// graceKey never keeps the session token itself in memory
+func graceKey(tenantID string, sessionToken string) string {
+	sum := sha256.Sum256([]byte(tenantID + ":" + sessionToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// Remember keeps a successful verification for grace mode
+func (m *ProviderHealthMonitor) Remember(tenantID string, sessionToken string, user *AuthenticatedUser) {
+	if !m.GraceEnabled() || user == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache[graceKey(tenantID, sessionToken)] = graceEntry{user: cloneAuthenticatedUser(*user), verifiedAt: time.Now()}
+}
+
+// Recall returns the verification of the session when it succeeded less than
+// the grace period ago. Only call it when the provider is unavailable.
+func (m *ProviderHealthMonitor) Recall(ctx context.Context, tenantID string, sessionToken string) (*AuthenticatedUser, bool) {
+	if !m.GraceEnabled() {
+		return nil, false
+	}
+	m.mu.RLock()
+	entry, ok := m.cache[graceKey(tenantID, sessionToken)]
+	m.mu.RUnlock()
+	if !ok || time.Since(entry.verifiedAt) > m.gracePeriod {
+		return nil, false
+	}
+	if m.graceServed != nil {
+		m.graceServed.Add(ctx, 1, m.attrs)
+	}
+	if m.degraded != nil {
+		m.degraded.Add(ctx, 1, m.attrs)
+	}
+	user := cloneAuthenticatedUser(entry.user)
+	user.Degraded = true
+	return &user, true
+}
+
+// ForgetUser drops the verifications of the user, so that revoked sessions are
+// not served during a later outage
+func (m *ProviderHealthMonitor) ForgetUser(userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, entry := range m.cache {
+		if entry.user.UserID == userID {
+			delete(m.cache, key)
+		}
+	}
+}
+
+// cloneAuthenticatedUser copies the claims so the middleware mutating a
+// request's user does not alter the cached one
+func cloneAuthenticatedUser(user AuthenticatedUser) AuthenticatedUser {
+	user.Claims = maps.Clone(user.Claims)
+	user.TenantMemberships = slices.Clone(user.TenantMemberships)
+	return user
+}
+
+// ProviderHealthCheck adapts the monitor to the /readyz checks
+type ProviderHealthCheck struct {
+	Monitor *ProviderHealthMonitor
+}
+
+func (c ProviderHealthCheck) Pass() bool {
+	return c.Monitor.Ready()
+}
+
+func (c ProviderHealthCheck) Name() string {
+	return "auth-provider-" + c.Monitor.provider
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// fakeHealthChecker fails its probes while err is set
+type fakeHealthChecker struct {
+	mu     sync.Mutex
+	err    error
+	probes int
+}
+
+func (f *fakeHealthChecker) CheckHealth(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.probes++
+	return f.err
+}
+
+func (f *fakeHealthChecker) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *fakeHealthChecker) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.probes
+}
+
+// useMetricReader has the monitors created by the test record their metrics in
+// the returned reader
+func useMetricReader(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	previous := otel.GetMeterProvider()
+	// The instruments of the monitors created before are handed to the first
+	// provider set, the gauges of the test would be ignored by it
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider())
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+	return reader
+}
+
+// upGauge returns the auth_provider.up value observed for the provider
+func upGauge(t *testing.T, reader *sdkmetric.ManualReader, provider string) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "auth_provider.up" {
+				continue
+			}
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			require.True(t, ok)
+			for _, point := range gauge.DataPoints {
+				if value, _ := point.Attributes.Value(attribute.Key("provider")); value.AsString() == provider {
+					return point.Value
+				}
+			}
+		}
+	}
+	t.Fatalf("no auth_provider.up gauge for %s", provider)
+	return 0
+}
+
+func TestNewProviderHealthMonitorDefaultInterval(t *testing.T) {
+	require.Equal(t, defaultHealthCheckInterval, NewProviderHealthMonitor("test", nil, 0, 0).interval)
+	require.Equal(t, time.Second, NewProviderHealthMonitor("test", nil, time.Second, 0).interval)
+
+	// Without a checker the provider stays healthy
+	m := NewProviderHealthMonitor("test", nil, time.Minute, 0)
+	require.True(t, m.Probe(context.Background()).Healthy)
+	require.True(t, m.Ready())
+}
+
+func TestProviderHealthMonitorTransitions(t *testing.T) {
+	reader := useMetricReader(t)
+	checker := &fakeHealthChecker{}
+	m := NewProviderHealthMonitor("transitions", checker, time.Minute, 0)
+	m.alertInterval = time.Hour
+	var alerts []ProviderHealthStatus
+	m.OnDegraded(func(_ context.Context, status ProviderHealthStatus, _ int) {
+		alerts = append(alerts, status)
+	})
+	check := ProviderHealthCheck{Monitor: m}
+	require.Equal(t, "auth-provider-transitions", check.Name())
+	ctx := context.Background()
+
+	status := m.Probe(ctx)
+	require.True(t, status.Healthy)
+	require.False(t, status.CheckedAt.IsZero())
+	require.True(t, check.Pass())
+	require.Equal(t, int64(1), upGauge(t, reader, "transitions"))
+	require.Empty(t, alerts)
+
+	// Degraded from the first failure, the failures counted until recovery
+	checker.fail(errors.New("connection refused"))
+	status = m.Probe(ctx)
+	require.False(t, status.Healthy)
+	require.Equal(t, 1, status.ConsecutiveFailures)
+	require.Equal(t, "connection refused", status.LastError)
+	unhealthySince := status.UnhealthySince
+	require.False(t, unhealthySince.IsZero())
+	require.False(t, check.Pass())
+	require.Equal(t, int64(0), upGauge(t, reader, "transitions"))
+
+	checker.fail(errors.New("timeout"))
+	status = m.Probe(ctx)
+	require.Equal(t, 2, status.ConsecutiveFailures)
+	require.Equal(t, "timeout", status.LastError)
+	require.Equal(t, unhealthySince, status.UnhealthySince)
+	// Raised once per alert interval
+	require.Len(t, alerts, 1)
+	require.False(t, alerts[0].Healthy)
+
+	checker.fail(nil)
+	status = m.Probe(ctx)
+	require.True(t, status.Healthy)
+	require.Zero(t, status.ConsecutiveFailures)
+	require.Empty(t, status.LastError)
+	require.True(t, status.UnhealthySince.IsZero())
+	require.True(t, check.Pass())
+	require.Equal(t, int64(1), upGauge(t, reader, "transitions"))
+	require.Len(t, alerts, 2)
+	require.True(t, alerts[1].Healthy)
+	require.Equal(t, 4, checker.count())
+}
+
+func TestProviderHealthMonitorReadyDuringGracePeriod(t *testing.T) {
+	checker := &fakeHealthChecker{err: errors.New("connection refused")}
+	m := NewProviderHealthMonitor("grace", checker, time.Minute, time.Hour)
+	check := ProviderHealthCheck{Monitor: m}
+
+	require.False(t, m.Probe(context.Background()).Healthy)
+	require.True(t, m.Degraded())
+	require.True(t, check.Pass())
+
+	// Not ready anymore once the outage outlasts the grace period
+	m.mu.Lock()
+	m.status.UnhealthySince = time.Now().Add(-2 * time.Hour)
+	m.mu.Unlock()
+	require.False(t, check.Pass())
+}
+
+func TestProviderHealthMonitorStart(t *testing.T) {
+	t.Run("probes at start", func(t *testing.T) {
+		checker := &fakeHealthChecker{}
+		m := NewProviderHealthMonitor("test", checker, time.Hour, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		m.Start(ctx)
+		m.Start(ctx)
+
+		require.Eventually(t, func() bool { return checker.count() == 1 }, time.Second, time.Millisecond)
+		require.Never(t, func() bool { return checker.count() > 1 }, 50*time.Millisecond, 5*time.Millisecond)
+	})
+
+	t.Run("probes every interval until done", func(t *testing.T) {
+		checker := &fakeHealthChecker{}
+		m := NewProviderHealthMonitor("test", checker, 5*time.Millisecond, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		m.Start(ctx)
+
+		require.Eventually(t, func() bool { return checker.count() >= 3 }, time.Second, time.Millisecond)
+		checker.fail(errors.New("connection refused"))
+		require.Eventually(t, func() bool { return !m.Status().Healthy }, time.Second, time.Millisecond)
+
+		cancel()
+		// At most the probe running when cancelled
+		time.Sleep(20 * time.Millisecond)
+		stopped := checker.count()
+		require.Never(t, func() bool { return checker.count() > stopped }, 50*time.Millisecond, 5*time.Millisecond)
+	})
+}
+
+func TestProviderHealthMonitorForgetUser(t *testing.T) {
+	m := NewProviderHealthMonitor("test", nil, time.Minute, time.Hour)
+	m.Remember("t1", "token-a", &AuthenticatedUser{UserID: "u1", SessionID: "s1"})
+	m.Remember("t1", "token-b", &AuthenticatedUser{UserID: "u1", SessionID: "s2"})
+	m.Remember("t1", "token-c", &AuthenticatedUser{UserID: "u2", SessionID: "s3"})
+
+	user, ok := m.Recall(context.Background(), "t1", "token-a")
+	require.True(t, ok)
+	require.Equal(t, "s1", user.SessionID)
+
+	m.ForgetUser("u1")
+	_, ok = m.Recall(context.Background(), "t1", "token-a")
+	require.False(t, ok)
+	_, ok = m.Recall(context.Background(), "t1", "token-b")
+	require.False(t, ok)
+	_, ok = m.Recall(context.Background(), "t1", "token-c")
+	require.True(t, ok)
+}
+
+func TestProviderHealthMonitorRecallMarksDegraded(t *testing.T) {
+	m := NewProviderHealthMonitor("test", nil, time.Minute, time.Hour)
+	m.Remember("t1", "token-a", &AuthenticatedUser{UserID: "u1"})
+
+	user, ok := m.Recall(context.Background(), "t1", "token-a")
+	require.True(t, ok)
+	require.True(t, user.Degraded)
+}
+
+func TestDeferredWriteQueue(t *testing.T) {
+	q := NewDeferredWriteQueue(2)
+	var applied []string
+	write := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			if err == nil {
+				applied = append(applied, name)
+			}
+			return err
+		}
+	}
+
+	require.True(t, q.Enqueue("a", write("a1", nil)))
+	require.True(t, q.Enqueue("b", write("b", nil)))
+	// Replaces the pending write of the same key
+	require.True(t, q.Enqueue("a", write("a2", nil)))
+	require.False(t, q.Enqueue("c", write("c", nil)))
+	require.Equal(t, 2, q.Len())
+
+	done, failed := q.Flush(context.Background())
+	require.Equal(t, 2, done)
+	require.Equal(t, 0, failed)
+	require.Equal(t, []string{"b", "a2"}, applied)
+
+	// Kept while the provider is still unavailable
+	require.True(t, q.Enqueue("d", write("d", NewAuthError(ErrorCodeProviderUnavailable, "down"))))
+	done, _ = q.Flush(context.Background())
+	require.Equal(t, 0, done)
+	require.Equal(t, 1, q.Len())
+}
+
+func TestProviderHealthMonitorDeferWriteNeedsGraceMode(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	require.False(t, NewProviderHealthMonitor("test", nil, time.Minute, 0).DeferWrite("a", noop))
+
+	m := NewProviderHealthMonitor("test", nil, time.Minute, time.Hour)
+	require.True(t, m.DeferWrite("a", noop))
+	require.Equal(t, 1, m.PendingWrites())
+}
@@ -0,0 +1,20 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// Set on the request when a SUPER_ADMIN acts as another user, so the action
+// can be attributed to the super admin as well
+const (
+	AUTH_IMPERSONATOR_ID  = "auth_impersonator_id"
+	AUTH_IMPERSONATION_ID = "auth_impersonation_id"
+)
+
+// CLAIM_ACT_AS is set in the claims of an impersonated request. It holds the
+// actor, the impersonation and its expiry, for the frontend to show a banner.
+const CLAIM_ACT_AS = "act_as"
+
+// ImpersonatorID returns the super admin acting as the user of the request
+func ImpersonatorID(c *gin.Context) (string, bool) {
+	actorID := c.GetString(AUTH_IMPERSONATOR_ID)
+	return actorID, actorID != ""
+}
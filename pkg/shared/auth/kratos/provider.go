@@ -3,8 +3,11 @@ package kratos
 import (
 	"context"
 	"fmt"
+	"maps"
+	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -32,12 +35,17 @@ func init() {
 			publicURL = "http://localhost:4433"
 		}
 
+		// Calls made for a captured request show in its replay bundle
+		httpClient := &http.Client{Transport: util.ReplayTransport{Kind: "auth_provider"}}
+
 		adminCfg := ory.NewConfiguration()
 		adminCfg.Servers = ory.ServerConfigurations{{URL: adminURL}}
+		adminCfg.HTTPClient = httpClient
 		adminClient := ory.NewAPIClient(adminCfg)
 
 		publicCfg := ory.NewConfiguration()
 		publicCfg.Servers = ory.ServerConfigurations{{URL: publicURL}}
+		publicCfg.HTTPClient = httpClient
 		publicClient := ory.NewAPIClient(publicCfg)
 
 		return NewKratosAuthProvider(ctx, adminClient, publicClient, multitenantService), nil
@@ -49,19 +57,33 @@ type KratosAuthProvider struct {
 	adminClient        *ory.APIClient
 	publicClient       *ory.APIClient
 	multitenantService auth.MultitenantService
+	health             *auth.ProviderHealthMonitor
 }
 
 // NewKratosAuthProvider creates a new Kratos auth provider
 func NewKratosAuthProvider(ctx context.Context, adminClient *ory.APIClient, publicClient *ory.APIClient, multitenantService auth.MultitenantService) *KratosAuthProvider {
-	return &KratosAuthProvider{
+	provider := &KratosAuthProvider{
 		adminClient:        adminClient,
 		publicClient:       publicClient,
 		multitenantService: multitenantService,
 	}
+	provider.health = auth.NewProviderHealthMonitorFromEnv(provider.GetProviderName(), provider)
+	return provider
+}
+
+// CheckHealth probes the readiness endpoint of the Kratos admin API
+func (k *KratosAuthProvider) CheckHealth(ctx context.Context) error {
+	_, _, err := k.adminClient.MetadataAPI.IsReady(ctx).Execute()
+	return err
+}
+
+// GetHealthMonitor returns the monitor tracking Kratos availability
+func (k *KratosAuthProvider) GetHealthMonitor() *auth.ProviderHealthMonitor {
+	return k.health
 }
 
 func (k *KratosAuthProvider) GetAuthClient() auth.AuthClient {
-	return NewKratosAuthClient(k.adminClient, k.publicClient)
+	return NewKratosAuthClient(k.adminClient, k.publicClient).withHealthMonitor(k.health)
 }
 
 func (k *KratosAuthProvider) VerifyToken(c *gin.Context) (*auth.AuthenticatedUser, error) {
@@ -92,7 +114,28 @@ func (k *KratosAuthProvider) VerifyToken(c *gin.Context) (*auth.AuthenticatedUse
 	return k.VerifyTokenWithTenantID(c, tenantID, sessionToken)
 }
 
+// VerifyTokenWithTenantID verifies the session against Kratos. While Kratos is
+// unreachable, a verification of the same session that succeeded within the
+// grace period is served instead of failing the request.
 func (k *KratosAuthProvider) VerifyTokenWithTenantID(ctx context.Context, tenantID string, sessionToken string) (*auth.AuthenticatedUser, error) {
+	start := time.Now()
+	user, err := k.verifyTokenWithTenantID(ctx, tenantID, sessionToken)
+	k.health.ObserveVerification(ctx, time.Since(start), err)
+	if err == nil {
+		k.health.Remember(tenantID, sessionToken, user)
+		return user, nil
+	}
+	if auth.IsProviderUnavailable(err) {
+		if cached, ok := k.health.Recall(ctx, tenantID, sessionToken); ok {
+			logger := util.GetLoggerFromCtx(ctx)
+			logger.Warn().Str("user_id", cached.UserID).Msg("Kratos unavailable, serving cached session verification")
+			return cached, nil
+		}
+	}
+	return user, err
+}
+
+func (k *KratosAuthProvider) verifyTokenWithTenantID(ctx context.Context, tenantID string, sessionToken string) (*auth.AuthenticatedUser, error) {
 
 	authClient := k.GetAuthClient()
 
@@ -124,6 +167,10 @@ func (k *KratosAuthProvider) VerifyTokenWithTenantID(ctx context.Context, tenant
 		}
 	}
 
+	if auth.HasTOTPEnrolled(token.Claims) {
+		claims[auth.CLAIM_MFA_TOTP] = true
+	}
+
 	user := &auth.AuthenticatedUser{
 		UserID:            token.UID,
 		Email:             email,
@@ -131,6 +178,7 @@ func (k *KratosAuthProvider) VerifyTokenWithTenantID(ctx context.Context, tenant
 		Claims:            claims,
 		TenantID:          tenantID,
 		TenantMemberships: []auth.TenantMembership{},
+		SessionID:         token.SessionID,
 	}
 
 	// Populate IsReseller from the current tenant before any early-return so
@@ -229,13 +277,20 @@ func (k *KratosAuthProvider) GetTenantManager() auth.TenantManager {
 }
 
 func (k *KratosAuthProvider) GetAuthClientForSubdomain(ctx context.Context, subdomain string) (auth.AuthClient, error) {
-	// In Kratos organizations, we might still need to map subdomain to organization ID
-	// For now, return the same client since Kratos handles multi-tenancy via organization ID in requests
-	return k.GetAuthClient(), nil
+	// Aliases resolve to the tenant of their primary subdomain, so both get
+	// the client of the same tenant
+	tenantID, err := k.multitenantService.GetTenantIDWithSubdomain(ctx, subdomain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tenant of subdomain %s: %w", subdomain, err)
+	}
+	if tenantID == "" {
+		return k.GetAuthClient(), nil
+	}
+	return k.GetAuthClientForTenant(ctx, tenantID)
 }
 
 func (k *KratosAuthProvider) GetAuthClientForTenant(ctx context.Context, tenantID string) (auth.AuthClient, error) {
-	return NewKratosAuthClient(k.adminClient, k.publicClient), nil
+	return NewKratosAuthClient(k.adminClient, k.publicClient).withHealthMonitor(k.health), nil
 }
 
 func (k *KratosAuthProvider) GetProviderName() string {
@@ -246,6 +301,7 @@ func (k *KratosAuthProvider) GetProviderName() string {
 type KratosAuthClient struct {
 	adminClient  *ory.APIClient
 	publicClient *ory.APIClient
+	health       *auth.ProviderHealthMonitor
 }
 
 // NewKratosAuthClient creates a new Kratos auth client
@@ -256,6 +312,13 @@ func NewKratosAuthClient(adminClient *ory.APIClient, publicClient *ory.APIClient
 	}
 }
 
+// withHealthMonitor lets the client defer its claims updates while Kratos is
+// unavailable
+func (k *KratosAuthClient) withHealthMonitor(health *auth.ProviderHealthMonitor) *KratosAuthClient {
+	k.health = health
+	return k
+}
+
 // GetAdminClient returns the admin API client
 func (k *KratosAuthClient) GetAdminClient() *ory.APIClient {
 	return k.adminClient
@@ -316,6 +379,13 @@ func (k *KratosAuthClient) UpdateUser(ctx context.Context, uid string, user *aut
 	if existing.State != nil {
 		state = string(*existing.State)
 	}
+	// An inactive identity can no longer sign in
+	if disabled := user.GetDisabled(); disabled != nil {
+		state = "active"
+		if *disabled {
+			state = "inactive"
+		}
+	}
 	updateBody := *ory.NewUpdateIdentityBody(existing.SchemaId, state, traits)
 
 	if password := user.GetPassword(); password != nil {
@@ -375,7 +445,39 @@ func (k *KratosAuthClient) GetUserByEmail(ctx context.Context, email string) (*a
 	return convertKratosIdentityToUserRecord(&idents[0]), nil
 }
 
+// SetCustomUserClaims merges the claims into the identity's metadata_public.
+// While Kratos is unavailable in degradation mode, the update is queued and
+// replayed once Kratos is back: the memberships in the database stay the
+// source of truth, the claims only mirror them.
 func (k *KratosAuthClient) SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error {
+	err := k.setCustomUserClaims(ctx, uid, customClaims)
+	if err == nil || !auth.IsProviderUnavailable(err) || k.health == nil {
+		return err
+	}
+	claims := maps.Clone(customClaims)
+	if k.health.DeferWrite(claimsWriteKey(uid, claims), func(ctx context.Context) error {
+		return k.setCustomUserClaims(ctx, uid, claims)
+	}) {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Warn().Str("user_id", uid).Msg("Kratos unavailable, claims update deferred")
+		return nil
+	}
+	return err
+}
+
+// claimsWriteKey identifies the claims an update replaces, so that only the
+// latest pending update of the same claims is replayed
+func claimsWriteKey(uid string, claims map[string]interface{}) string {
+	keys := slices.Sorted(maps.Keys(claims))
+	if membership, ok := claims["tenant_memberships"].(map[string]interface{}); ok {
+		if tenantID, ok := membership["tenant_id"].(string); ok {
+			keys = append(keys, tenantID)
+		}
+	}
+	return "claims:" + uid + ":" + strings.Join(keys, ",")
+}
+
+func (k *KratosAuthClient) setCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error {
 	log := util.GetLoggerFromCtx(ctx)
 	existing, _, err := k.adminClient.IdentityAPI.GetIdentity(ctx, uid).Execute()
 	if err != nil {
@@ -428,6 +530,15 @@ func (k *KratosAuthClient) SetCustomUserClaims(ctx context.Context, uid string,
 		}
 	}
 
+	// Handle the TOTP flag, only kept while set
+	if totp, exists := customClaims[auth.CLAIM_MFA_TOTP]; exists {
+		if enrolled, _ := totp.(bool); enrolled {
+			metadataPublic[auth.CLAIM_MFA_TOTP] = true
+		} else {
+			delete(metadataPublic, auth.CLAIM_MFA_TOTP)
+		}
+	}
+
 	// 3. Save back to metadata
 	metadataPublic["tenant_memberships"] = rawMemberships
 
@@ -443,6 +554,22 @@ func (k *KratosAuthClient) SetCustomUserClaims(ctx context.Context, uid string,
 	return auth.ConvertKratosError(err)
 }
 
+// GetCustomUserClaims returns the identity's metadata_public, where Kratos
+// stores global_roles and tenant_memberships
+func (k *KratosAuthClient) GetCustomUserClaims(ctx context.Context, uid string) (map[string]interface{}, error) {
+	log := util.GetLoggerFromCtx(ctx)
+	existing, _, err := k.adminClient.IdentityAPI.GetIdentity(ctx, uid).Execute()
+	if err != nil {
+		log.Err(err).Msg("Failed to get identity")
+		return nil, auth.ConvertKratosError(err)
+	}
+	metadataPublic, ok := existing.MetadataPublic.(map[string]interface{})
+	if !ok {
+		metadataPublic = make(map[string]interface{})
+	}
+	return metadataPublic, nil
+}
+
 // BuildGlobalRoleClaims creates Kratos-specific claims format for global roles
 // Returns: {"global_roles": ["SUPER_ADMIN", "ADMIN"]}
 func (k *KratosAuthClient) BuildGlobalRoleClaims(roles []string) map[string]interface{} {
@@ -451,6 +578,14 @@ func (k *KratosAuthClient) BuildGlobalRoleClaims(roles []string) map[string]inte
 	}
 }
 
+// BuildMFAClaims creates Kratos-specific claims format for the TOTP flag
+// Returns: {"mfa_totp": true}
+func (k *KratosAuthClient) BuildMFAClaims(totpEnrolled bool) map[string]interface{} {
+	return map[string]interface{}{
+		auth.CLAIM_MFA_TOTP: totpEnrolled,
+	}
+}
+
 func (k *KratosAuthClient) EmailVerificationLink(ctx context.Context, email string) (string, error) {
 	logger := util.GetLoggerFromCtx(ctx)
 	// For Kratos, we need to use the Admin API to create verification links
@@ -539,12 +674,18 @@ func (k *KratosAuthClient) VerifyIDToken(ctx context.Context, sessionToken strin
 
 	// Use the SDK but inject the Cookie header into the context
 	// This keeps your code clean and leverages the SDK's built-in types
-	session, _, err := k.publicClient.FrontendAPI.ToSession(ctx).
+	session, resp, err := k.publicClient.FrontendAPI.ToSession(ctx).
 		Cookie(cookieString). // The SDK has a .Cookie() method for this!
 		Execute()
 
 	if err != nil {
 		logger.Err(err).Msg("Failed to verify session token with Kratos")
+		// No response or a server error means Kratos could not decide, which
+		// grace mode must tell apart from a rejected session. A cancelled
+		// request says nothing about Kratos.
+		if ctx.Err() == nil && (resp == nil || resp.StatusCode >= 500) {
+			return nil, &auth.AuthError{Code: auth.ErrorCodeProviderUnavailable, Message: err.Error()}
+		}
 		return nil, auth.ConvertKratosError(err)
 	}
 
@@ -574,6 +715,10 @@ func (k *KratosAuthClient) VerifyIDToken(ctx context.Context, sessionToken strin
 				claims[auth.AUTH_TENANT_MEMBERSHIPS] = tenantMemberships
 			}
 
+			if enrolled, ok := metadataPublic[auth.CLAIM_MFA_TOTP].(bool); ok {
+				claims[auth.CLAIM_MFA_TOTP] = enrolled
+			}
+
 			// For backward compatibility, also set tenant_id and subdomain
 			if tenantID, ok := metadataPublic["tenant_id"].(string); ok {
 				claims["tenant_id"] = tenantID
@@ -603,11 +748,91 @@ func (k *KratosAuthClient) VerifyIDToken(ctx context.Context, sessionToken strin
 	}
 
 	return &auth.Token{
-		UID:    session.Identity.Id,
-		Claims: claims,
+		UID:       session.Identity.Id,
+		Claims:    claims,
+		SessionID: session.Id,
 	}, nil
 }
 
+func (k *KratosAuthClient) ListUserSessions(ctx context.Context, uid string) ([]auth.Session, error) {
+	log := util.GetLoggerFromCtx(ctx)
+	sessions, resp, err := k.adminClient.IdentityAPI.ListIdentitySessions(ctx, uid).Active(true).Execute()
+	if err != nil {
+		// An identity without sessions answers 404
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return []auth.Session{}, nil
+		}
+		log.Err(err).Str("user_id", uid).Msg("Failed to list identity sessions")
+		return nil, auth.ConvertKratosError(err)
+	}
+	result := make([]auth.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, convertKratosSession(session))
+	}
+	return result, nil
+}
+
+func (k *KratosAuthClient) RevokeUserSessions(ctx context.Context, uid string) error {
+	log := util.GetLoggerFromCtx(ctx)
+	resp, err := k.adminClient.IdentityAPI.DeleteIdentitySessions(ctx, uid).Execute()
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		log.Err(err).Str("user_id", uid).Msg("Failed to delete identity sessions")
+		return auth.ConvertKratosError(err)
+	}
+	return nil
+}
+
+func (k *KratosAuthClient) RevokeUserSession(ctx context.Context, uid string, sessionID string) error {
+	log := util.GetLoggerFromCtx(ctx)
+	notFound := &auth.AuthError{Code: auth.ErrorCodeSessionNotFound, Message: "session not found"}
+	// The admin API disables any session, so check it is one of the user first
+	session, resp, err := k.adminClient.IdentityAPI.GetSession(ctx, sessionID).Expand([]string{"Identity"}).Execute()
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest) {
+			return notFound
+		}
+		log.Err(err).Str("session_id", sessionID).Msg("Failed to get session")
+		return auth.ConvertKratosError(err)
+	}
+	if session.Identity == nil || session.Identity.Id != uid {
+		return notFound
+	}
+	if _, err := k.adminClient.IdentityAPI.DisableSession(ctx, sessionID).Execute(); err != nil {
+		log.Err(err).Str("session_id", sessionID).Msg("Failed to disable session")
+		return auth.ConvertKratosError(err)
+	}
+	return nil
+}
+
+func convertKratosSession(session ory.Session) auth.Session {
+	result := auth.Session{
+		ID:              session.Id,
+		AuthenticatedAt: session.AuthenticatedAt,
+		IssuedAt:        session.IssuedAt,
+		ExpiresAt:       session.ExpiresAt,
+		Methods:         []string{},
+	}
+	if session.AuthenticatorAssuranceLevel != nil {
+		result.AAL = string(*session.AuthenticatorAssuranceLevel)
+	}
+	for _, method := range session.AuthenticationMethods {
+		if method.Method != nil && !containsString(result.Methods, *method.Method) {
+			result.Methods = append(result.Methods, *method.Method)
+		}
+	}
+	// Devices are appended as the session is used, the last one is the most recent
+	if len(session.Devices) > 0 {
+		device := session.Devices[len(session.Devices)-1]
+		result.IPAddress = device.GetIpAddress()
+		result.UserAgent = device.GetUserAgent()
+		result.Location = device.GetLocation()
+	}
+	return result
+}
+
 func (k *KratosAuthClient) EmailVerificationLinkWithSettings(ctx context.Context, email string, settings *auth.ActionCodeSettings) (string, error) {
 	return k.EmailVerificationLink(ctx, email)
 }
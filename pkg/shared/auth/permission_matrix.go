@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"slices"
+
+	"ctoup.com/coreapp/api/openapi/core"
+)
+
+// MatrixRoles are the roles the permission matrix has a column for
+var MatrixRoles = []core.Role{core.USER, core.CUSTOMERADMIN, core.ADMIN, core.SUPERADMIN}
+
+// OperationPermission lists the roles allowed to perform an operation
+type OperationPermission struct {
+	Operation Operation
+	Roles     []core.Role
+}
+
+// matrixSubject is a subject holding the role alone. USER stands for an
+// authenticated subject without any admin role.
+func matrixSubject(role core.Role) Subject {
+	subject := Subject{UserID: "permission-matrix"}
+	if role != core.USER {
+		subject.Roles = []string{string(role)}
+	}
+	return subject
+}
+
+// PermissionMatrix evaluates every registered operation for each of the
+// matrix roles, with the same rules Authorize applies. Operations are sorted
+// by name. Reseller rules and delegations depend on the tenant and the user,
+// so they are not part of it.
+func (a *Authorizer) PermissionMatrix() []OperationPermission {
+	a.mu.RLock()
+	operations := make([]Operation, 0, len(a.rules))
+	for op := range a.rules {
+		operations = append(operations, op)
+	}
+	a.mu.RUnlock()
+	slices.Sort(operations)
+
+	matrix := make([]OperationPermission, 0, len(operations))
+	for _, op := range operations {
+		permission := OperationPermission{Operation: op, Roles: []core.Role{}}
+		for _, role := range MatrixRoles {
+			if a.Evaluate(matrixSubject(role), op).Allowed {
+				permission.Roles = append(permission.Roles, role)
+			}
+		}
+		matrix = append(matrix, permission)
+	}
+	return matrix
+}
+
+// Strict reports whether operations without a rule are denied
+func (a *Authorizer) Strict() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.strict
+}
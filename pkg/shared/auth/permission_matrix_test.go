@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"slices"
+	"testing"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionMatrix(t *testing.T) {
+	authorizer := NewAuthorizer(false)
+	authorizer.Register(map[Operation]Rule{"reports:purge": {Message: "disabled"}})
+
+	roles := map[Operation][]core.Role{}
+	for _, permission := range authorizer.PermissionMatrix() {
+		roles[permission.Operation] = permission.Roles
+	}
+
+	require.Equal(t, []core.Role{core.USER, core.CUSTOMERADMIN, core.ADMIN, core.SUPERADMIN}, roles[OpAssignRole(core.USER)])
+	require.Equal(t, []core.Role{core.CUSTOMERADMIN, core.ADMIN, core.SUPERADMIN}, roles[OpManageUsers])
+	require.Equal(t, []core.Role{core.ADMIN, core.SUPERADMIN}, roles[OpAssignRole(core.ADMIN)])
+	require.Equal(t, []core.Role{core.SUPERADMIN}, roles[OpUpdateTenantReseller])
+	require.Empty(t, roles[OpListResellerTenants])
+	require.Empty(t, roles["reports:purge"])
+
+	// The matrix agrees with the decisions of the authorizer
+	for op, allowed := range roles {
+		for _, role := range MatrixRoles {
+			decision := authorizer.Evaluate(matrixSubject(role), op)
+			require.Equal(t, decision.Allowed, slices.Contains(allowed, role), "%s for %s", op, role)
+		}
+	}
+}
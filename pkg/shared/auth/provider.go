@@ -14,6 +14,8 @@ const (
 	AUTH_EMAIL              = "auth_email"
 	AUTH_USER_ID            = "auth_user_id"
 	AUTH_CLAIMS             = "auth_claims"
+	AUTH_PERMISSIONS        = "auth_permissions"
+	AUTH_SESSION_ID         = "auth_session_id"
 	AUTH_TENANT_ID_KEY      = "auth_tenant_id"
 	AUTH_TENANT_MEMBERSHIPS = "tenant_memberships"
 	AUTH_IS_RESELLER        = "is_reseller"
@@ -69,6 +71,9 @@ type AuthenticatedUser struct {
 	IsReseller        bool                   `json:"is_reseller"`                  // Is the current tenant a reseller
 	IsActingReseller  bool                   `json:"is_acting_reseller"`           // Is the current tenant managed by a reseller
 	TenantAllowSignUp bool                   `json:"tenant_allow_sign_up"`         // Tenant.AllowSignUp — drives AccessScope
+	SessionID         string                 `json:"session_id,omitempty"`         // Provider session of the request, when the provider has sessions
+	Degraded          bool                   `json:"-"`                            // Verification reused from grace mode while the provider is unavailable
+	Permissions       []string               `json:"permissions,omitempty"`        // Operations granted by the custom roles of the user in the tenant
 }
 
 func (au *AuthenticatedUser) GetClaimsArray() []string {
@@ -213,8 +218,22 @@ type ActionCodeSettings struct {
 
 // Token represents an authentication token
 type Token struct {
-	UID    string
-	Claims map[string]interface{}
+	UID       string
+	Claims    map[string]interface{}
+	SessionID string
+}
+
+// Session is an active sign-in of a user
+type Session struct {
+	ID              string
+	AuthenticatedAt *time.Time
+	IssuedAt        *time.Time
+	ExpiresAt       *time.Time
+	AAL             string
+	Methods         []string
+	IPAddress       string
+	UserAgent       string
+	Location        string
 }
 
 // AuthClient defines the interface for authentication operations
@@ -229,11 +248,19 @@ type AuthClient interface {
 
 	// Custom Claims (Roles/Permissions)
 	SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error
+	// GetCustomUserClaims returns the claims currently stored by the provider
+	// Kratos: the identity's metadata_public
+	GetCustomUserClaims(ctx context.Context, uid string) (map[string]interface{}, error)
 
 	// BuildGlobalRoleClaims creates a provider-specific claims map for global roles
 	// Kratos: {"global_roles": ["SUPER_ADMIN", "ADMIN"]}
 	BuildGlobalRoleClaims(roles []string) map[string]interface{}
 
+	// BuildMFAClaims creates a provider-specific claims map flagging whether
+	// the user enabled the TOTP second factor
+	// Kratos: {"mfa_totp": true}, kept in metadata_public
+	BuildMFAClaims(totpEnrolled bool) map[string]interface{}
+
 	// Email Actions
 	EmailVerificationLink(ctx context.Context, email string) (string, error)
 	PasswordResetLink(ctx context.Context, email string) (string, error)
@@ -244,6 +271,17 @@ type AuthClient interface {
 	// Token Verification
 	VerifyIDToken(ctx context.Context, idToken string) (*Token, error)
 
+	// Sessions
+	// ListUserSessions returns the active sessions of the user
+	// Kratos: the identity sessions
+	ListUserSessions(ctx context.Context, uid string) ([]Session, error)
+	// RevokeUserSessions signs the user out everywhere
+	// Kratos: deletes the identity sessions; Firebase: revokes the refresh tokens
+	RevokeUserSessions(ctx context.Context, uid string) error
+	// RevokeUserSession signs out a single session of the user. Returns an
+	// ErrorCodeSessionNotFound error when it is not a session of the user.
+	RevokeUserSession(ctx context.Context, uid string, sessionID string) error
+
 	// Provider Capabilities
 	// RequiresRecoveryProxy returns true if the provider needs a backend proxy endpoint
 	// for password recovery (like Kratos), false if recovery links work directly (like Firebase)
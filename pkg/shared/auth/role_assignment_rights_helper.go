@@ -18,21 +18,15 @@ const (
 	ACTING_RESELLER          = "ACTING_RESELLER"
 )
 
+// HasRightsForRole checks that the caller may grant or remove the role
 func HasRightsForRole(c *gin.Context, role core.Role) error {
-	if role == core.CUSTOMERADMIN && (!IsCustomerAdmin(c) && !IsSuperAdmin(c) && !IsAdmin(c)) {
-		return errors.New("must be at a CUSTOMER_ADMIN or SUPER_ADMIN or ADMIN to perform such operation")
-	}
-	if role == core.ADMIN && (!IsSuperAdmin(c) && !IsAdmin(c)) {
-		return errors.New("must be an ADMIN or SUPER_ADMIN to perform such operation")
-	}
-	if role == core.SUPERADMIN && !IsSuperAdmin(c) {
-		return errors.New("must be an SUPER_ADMIN to perform such operation")
-	}
-	return nil
+	return Authorize(c, OpAssignRole(role))
 }
 
+// HasAdminPrivileges reports whether the caller may manage the users of its
+// tenant
 func HasAdminPrivileges(c *gin.Context) bool {
-	return IsCustomerAdmin(c) || IsActingReseller(c) || IsSuperAdmin(c) || IsAdmin(c)
+	return Allowed(c, OpManageUsers)
 }
 
 func HasRightsForRoles(c *gin.Context, roles []core.Role) error {
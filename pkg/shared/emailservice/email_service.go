@@ -2,9 +2,9 @@ package emailservice
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
-	"net/smtp"
 	"os"
 	"path/filepath"
 	"strings"
@@ -40,12 +40,58 @@ func InitializeSMTPConfig() *SMTPConfig {
 	return smtpConfig
 }
 
+// EmailAssets holds the branding assets exposed to every template through the
+// logoURL and footer functions
+type EmailAssets struct {
+	LogoURL string
+	Footer  string
+}
+
+// AssetResolver returns the email assets of the tenant serving a request
+type AssetResolver func(ctx *gin.Context) EmailAssets
+
+var assetResolver AssetResolver
+
+// SetAssetResolver registers the resolver ParseTemplateWithDomain uses when the
+// request carries no assets of its own
+func SetAssetResolver(resolver AssetResolver) {
+	assetResolver = resolver
+}
+
+// Events of the emails whose subject and body a tenant may replace
+const (
+	EventWelcome       = "welcome"
+	EventPasswordReset = "password_reset"
+)
+
+// TemplateOverride is the subject and HTML body a tenant set for an email
+type TemplateOverride struct {
+	Subject string
+	Body    string
+}
+
+// TemplateResolver returns the template the tenant serving a request set for
+// the event, rendered with the variables, or false to use the library one
+type TemplateResolver func(ctx *gin.Context, event string, vars map[string]string) (TemplateOverride, bool)
+
+var templateResolver TemplateResolver
+
+// SetTemplateResolver registers the resolver ApplyTenantTemplate uses
+func SetTemplateResolver(resolver TemplateResolver) {
+	templateResolver = resolver
+}
+
 // EmailRequest struct handles email request data
 type EmailRequest struct {
 	From    string
 	To      []string
 	Subject string
 	Body    string
+	Assets  EmailAssets
+	// ReplyTo is the address the answers go to, From when empty
+	ReplyTo string
+	// TenantID selects the email provider of the tenant, if it configured one
+	TenantID string
 }
 
 func NewEmailRequest(from string, to []string, subject, body string) *EmailRequest {
@@ -57,30 +103,32 @@ func NewEmailRequest(from string, to []string, subject, body string) *EmailReque
 	}
 }
 
-// SendEmail sends an email using the SMTP configuration
+// SendEmail sends the email with the provider of its tenant, from the
+// addresses the tenant configured, or with the platform SMTP server when the
+// tenant configured none
 func (r *EmailRequest) SendEmail() error {
-	smtpCfg := InitializeSMTPConfig()
-	auth := smtp.PlainAuth("", smtpCfg.Username, smtpCfg.Password, smtpCfg.Host)
-
-	//msg := []byte(subject + mime + "\n" + r.Body)
-
-	msg := []byte("To: " + r.To[0] + "\r\n" +
-		"From: " + r.From + "\r\n" +
-		"Subject: " + r.Subject + "\r\n" +
-		"MIME-Version: 1.0\r\n" +
-		"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
-		"\r\n" + r.Body)
-
-	addr := smtpCfg.Host + ":" + smtpCfg.Port
-	if err := smtp.SendMail(addr, auth, r.From, r.To, msg); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+	if r.TenantID != "" && transportResolver != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), transportResolveTimeout)
+		transport, ok := transportResolver(ctx, r.TenantID)
+		cancel()
+		if ok {
+			r.From = transport.From
+			if r.ReplyTo == "" {
+				r.ReplyTo = transport.ReplyTo
+			}
+			return transport.Sender.Send(r)
+		}
 	}
-	return nil
+	return platformSender().Send(r)
 }
 
 // ParseTemplate parses an HTML template and replaces placeholders with actual data
 func (r *EmailRequest) ParseTemplate(templateFileName string, data interface{}) error {
-	t, err := template.ParseFiles(templateFileName)
+	assets := r.Assets
+	t, err := template.New(filepath.Base(templateFileName)).Funcs(template.FuncMap{
+		"logoURL": func() string { return assets.LogoURL },
+		"footer":  func() string { return assets.Footer },
+	}).ParseFiles(templateFileName)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -93,6 +141,21 @@ func (r *EmailRequest) ParseTemplate(templateFileName string, data interface{})
 	return nil
 }
 
+// ApplyTenantTemplate replaces the subject and the body with the template the
+// request tenant set for the event, and reports whether there was one
+func (r *EmailRequest) ApplyTenantTemplate(ctx *gin.Context, event string, vars map[string]string) bool {
+	if templateResolver == nil {
+		return false
+	}
+	override, ok := templateResolver(ctx, event, vars)
+	if !ok {
+		return false
+	}
+	r.Subject = override.Subject
+	r.Body = override.Body
+	return true
+}
+
 // ParseTemplateWithDomain parses an HTML template using domain-aware hierarchical lookup
 // It searches for templates in the following order:
 // 1. templates/domain/subdomain/templateName
@@ -104,6 +167,9 @@ func (r *EmailRequest) ParseTemplateWithDomain(ctx *gin.Context, templateName st
 		return fmt.Errorf("failed to find template: %w", err)
 	}
 
+	if r.Assets == (EmailAssets{}) && assetResolver != nil {
+		r.Assets = assetResolver(ctx)
+	}
 	return r.ParseTemplate(templatePath, data)
 }
 
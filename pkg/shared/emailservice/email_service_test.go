@@ -80,3 +80,22 @@ func TestGetTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTemplateAssets(t *testing.T) {
+	templatePath := "../../../templates/email-tenant-added.html"
+	data := struct {
+		TenantName string
+		Link       string
+	}{TenantName: "Acme", Link: "https://acme.example.com"}
+
+	r := NewEmailRequest("noreply@example.com", []string{"user@example.com"}, "subject", "")
+	r.Assets = EmailAssets{LogoURL: "https://cdn.example.com/acme.png", Footer: "Sent by Acme"}
+	assert.NoError(t, r.ParseTemplate(templatePath, data))
+	assert.Contains(t, r.Body, `<img src="https://cdn.example.com/acme.png"`)
+	assert.Contains(t, r.Body, "Sent by Acme")
+
+	// Without a logo the image is left out
+	r = NewEmailRequest("noreply@example.com", []string{"user@example.com"}, "subject", "")
+	assert.NoError(t, r.ParseTemplate(templatePath, data))
+	assert.NotContains(t, r.Body, "<img")
+}
@@ -0,0 +1,158 @@
+package emailservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"time"
+)
+
+// Providers an email can be sent with
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+)
+
+// Sender delivers an email
+type Sender interface {
+	Send(r *EmailRequest) error
+}
+
+// SMTPSender sends the emails through an SMTP server
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+func (s SMTPSender) Send(r *EmailRequest) error {
+	auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
+
+	headers := "To: " + r.To[0] + "\r\n" +
+		"From: " + r.From + "\r\n"
+	if r.ReplyTo != "" {
+		headers += "Reply-To: " + r.ReplyTo + "\r\n"
+	}
+	msg := []byte(headers +
+		"Subject: " + r.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
+		"\r\n" + r.Body)
+
+	addr := s.Host + ":" + s.Port
+	if err := smtp.SendMail(addr, auth, r.From, r.To, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// sendGridEndpoint is the mail send endpoint of the SendGrid v3 API
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender sends the emails with the SendGrid API and an API key
+type SendGridSender struct {
+	APIKey string
+	// Endpoint replaces the SendGrid endpoint, for tests
+	Endpoint string
+	Client   *http.Client
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+func (s SendGridSender) Send(r *EmailRequest) error {
+	to := make([]sendGridAddress, len(r.To))
+	for i, address := range r.To {
+		to[i] = sendGridAddress{Email: address}
+	}
+	message := sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: to}},
+		From:             sendGridAddress{Email: r.From},
+		Subject:          r.Subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: r.Body}},
+	}
+	if r.ReplyTo != "" {
+		message.ReplyTo = &sendGridAddress{Email: r.ReplyTo}
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = sendGridEndpoint
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send email: sendgrid answered %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// TenantTransport is the provider a tenant configured with the addresses its
+// emails are sent from
+type TenantTransport struct {
+	Sender  Sender
+	From    string
+	ReplyTo string
+}
+
+// TransportResolver returns the provider the tenant configured, or false to
+// send with the platform SMTP server
+type TransportResolver func(ctx context.Context, tenantID string) (TenantTransport, bool)
+
+var transportResolver TransportResolver
+
+// SetTransportResolver registers the resolver SendEmail uses for the requests
+// of a tenant
+func SetTransportResolver(resolver TransportResolver) {
+	transportResolver = resolver
+}
+
+// transportResolveTimeout bounds the lookup of the provider of a tenant
+const transportResolveTimeout = 10 * time.Second
+
+// platformSender sends with the SMTP server of the SMTP_* variables
+func platformSender() Sender {
+	cfg := InitializeSMTPConfig()
+	return SMTPSender{Host: cfg.Host, Port: cfg.Port, Username: cfg.Username, Password: cfg.Password}
+}
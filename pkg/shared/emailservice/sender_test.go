@@ -0,0 +1,65 @@
+package emailservice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	sent []EmailRequest
+}
+
+func (s *recordingSender) Send(r *EmailRequest) error {
+	s.sent = append(s.sent, *r)
+	return nil
+}
+
+func TestSendEmailWithTenantTransport(t *testing.T) {
+	sender := &recordingSender{}
+	SetTransportResolver(func(ctx context.Context, tenantID string) (TenantTransport, bool) {
+		if tenantID != "acme" {
+			return TenantTransport{}, false
+		}
+		return TenantTransport{Sender: sender, From: "noreply@acme.com", ReplyTo: "support@acme.com"}, true
+	})
+	t.Cleanup(func() { SetTransportResolver(nil) })
+
+	r := NewEmailRequest("noreply@ctoup.com", []string{"user@acme.com"}, "Welcome", "<p>Hello</p>")
+	r.TenantID = "acme"
+	require.NoError(t, r.SendEmail())
+	require.Len(t, sender.sent, 1)
+	require.Equal(t, "noreply@acme.com", sender.sent[0].From)
+	require.Equal(t, "support@acme.com", sender.sent[0].ReplyTo)
+	require.Equal(t, "Welcome", sender.sent[0].Subject)
+}
+
+func TestSendGridSender(t *testing.T) {
+	var message sendGridMessage
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authorization = req.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&message))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	r := NewEmailRequest("noreply@acme.com", []string{"a@acme.com", "b@acme.com"}, "Welcome", "<p>Hello</p>")
+	r.ReplyTo = "support@acme.com"
+	require.NoError(t, SendGridSender{APIKey: "key", Endpoint: server.URL}.Send(r))
+	require.Equal(t, "Bearer key", authorization)
+	require.Equal(t, []sendGridAddress{{Email: "a@acme.com"}, {Email: "b@acme.com"}}, message.Personalizations[0].To)
+	require.Equal(t, "noreply@acme.com", message.From.Email)
+	require.Equal(t, "support@acme.com", message.ReplyTo.Email)
+	require.Equal(t, []sendGridContent{{Type: "text/html", Value: "<p>Hello</p>"}}, message.Content)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "invalid key", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+	require.ErrorContains(t, SendGridSender{APIKey: "key", Endpoint: failing.URL}.Send(r), "401")
+}
@@ -0,0 +1,115 @@
+package event
+
+import (
+	"embed"
+	"fmt"
+)
+
+// EventData is a structured event payload. Every type and version has a JSON
+// schema in schemas/<type>.v<version>.json; changing the meaning of a field or
+// removing one needs a new version, adding an optional field does not.
+type EventData interface {
+	DataType() string
+	DataVersion() int
+}
+
+// Event data types
+const (
+	DataTypeUserImportProgress = "user_import.progress"
+	DataTypeUserImportResult   = "user_import.result"
+	DataTypeTenantSandboxReset = "tenant_sandbox.reset"
+	DataTypeTenantDataExport   = "tenant_data_export.result"
+)
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// Schema returns the JSON schema of an event data type and version
+func Schema(dataType string, version int) ([]byte, error) {
+	schema, err := schemas.ReadFile(fmt.Sprintf("schemas/%s.v%d.json", dataType, version))
+	if err != nil {
+		return nil, fmt.Errorf("no schema for event data %s v%d", dataType, version)
+	}
+	return schema, nil
+}
+
+// Codes of the rows rejected by an import
+const (
+	ImportErrorInvalidRow   = "invalid_row"
+	ImportErrorInvalidEmail = "invalid_email"
+	ImportErrorDuplicate    = "duplicate"
+	ImportErrorForbidden    = "forbidden"
+	ImportErrorEmailExists  = "email_exists"
+	ImportErrorCreateFailed = "create_failed"
+	ImportErrorWelcomeEmail = "welcome_email_failed"
+)
+
+// ImportRowError is a row rejected by an import. Code is one of the
+// ImportError* constants, Error the message for humans.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Email string `json:"email,omitempty"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+// UserImportProgress is sent while a user import runs. Errors only holds the
+// rows rejected since the previous event.
+type UserImportProgress struct {
+	Line          int              `json:"line"`
+	Processed     int              `json:"processed"`
+	Success       int              `json:"success"`
+	AlreadyExists int              `json:"alreadyExists"`
+	Failed        int              `json:"failed"`
+	Errors        []ImportRowError `json:"errors"`
+}
+
+func (UserImportProgress) DataType() string { return DataTypeUserImportProgress }
+func (UserImportProgress) DataVersion() int { return 1 }
+
+// UserImportResult is the last event of a user import, with every rejected row
+type UserImportResult struct {
+	Total         int              `json:"total"`
+	Success       int              `json:"success"`
+	AlreadyExists int              `json:"alreadyExists"`
+	Failed        int              `json:"failed"`
+	Errors        []ImportRowError `json:"errors"`
+}
+
+func (UserImportResult) DataType() string { return DataTypeUserImportResult }
+func (UserImportResult) DataVersion() int { return 1 }
+
+// TenantSandboxResetStep is what a step of a sandbox reset removed
+type TenantSandboxResetStep struct {
+	Name    string `json:"name"`
+	Deleted int64  `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TenantSandboxReset is the last event of a sandbox tenant reset
+type TenantSandboxReset struct {
+	TenantID        string                   `json:"tenantId"`
+	MembersRemoved  int                      `json:"membersRemoved"`
+	UsersDeleted    int                      `json:"usersDeleted"`
+	SettingsChanges int                      `json:"settingsChanges"`
+	Steps           []TenantSandboxResetStep `json:"steps"`
+}
+
+func (TenantSandboxReset) DataType() string { return DataTypeTenantSandboxReset }
+func (TenantSandboxReset) DataVersion() int { return 1 }
+
+// TenantDataExportTable is the number of rows exported from a table
+type TenantDataExportTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// TenantDataExport is the last event of a completed full tenant export
+type TenantDataExport struct {
+	TenantID string                  `json:"tenantId"`
+	Tables   []TenantDataExportTable `json:"tables"`
+	Bytes    int64                   `json:"bytes"`
+}
+
+func (TenantDataExport) DataType() string { return DataTypeTenantDataExport }
+func (TenantDataExport) DataVersion() int { return 1 }
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDataSchemas(t *testing.T) {
+	payloads := []EventData{
+		UserImportProgress{Errors: []ImportRowError{{Line: 2, Error: "invalid"}}},
+		UserImportResult{Errors: []ImportRowError{}},
+		TenantSandboxReset{Steps: []TenantSandboxResetStep{{Name: "files", Error: "failed"}}},
+		TenantDataExport{Tables: []TenantDataExportTable{{Name: "users", Rows: 2}}},
+	}
+	for _, data := range payloads {
+		t.Run(data.DataType(), func(t *testing.T) {
+			raw, err := Schema(data.DataType(), data.DataVersion())
+			require.NoError(t, err)
+			var schema struct {
+				Required   []string                   `json:"required"`
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			require.NoError(t, json.Unmarshal(raw, &schema))
+
+			encoded, err := json.Marshal(data)
+			require.NoError(t, err)
+			var fields map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(encoded, &fields))
+
+			for _, required := range schema.Required {
+				require.Contains(t, fields, required)
+			}
+			for field := range fields {
+				require.Contains(t, schema.Properties, field)
+			}
+		})
+	}
+
+	_, err := Schema(DataTypeUserImportProgress, 99)
+	require.Error(t, err)
+}
+
+func TestProgressEventJSON(t *testing.T) {
+	legacy, err := json.Marshal(NewProgressEvent("INFO", "done", 100))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"eventType":"INFO","message":"done","progress":100}`, string(legacy))
+
+	typed, err := json.Marshal(NewProgressEventWithData("INFO", "done", 100, UserImportResult{Total: 1, Success: 1, Errors: []ImportRowError{}}))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"eventType": "INFO",
+		"message": "done",
+		"progress": 100,
+		"dataType": "user_import.result",
+		"dataVersion": 1,
+		"data": {"total": 1, "success": 1, "alreadyExists": 0, "failed": 0, "errors": []}
+	}`, string(typed))
+}
@@ -5,9 +5,15 @@ type Event struct {
 	Message   string `json:"message"`
 }
 
+// ProgressEvent is streamed to clients during long running operations.
+// Message stays human readable for older clients; Data carries the same
+// information as a structured payload described by DataType and DataVersion.
 type ProgressEvent struct {
 	Event
-	Progress int `json:"progress"`
+	Progress    int       `json:"progress"`
+	DataType    string    `json:"dataType,omitempty"`
+	DataVersion int       `json:"dataVersion,omitempty"`
+	Data        EventData `json:"data,omitempty"`
 }
 
 func NewProgressEvent(eventType string, message string, progress int) ProgressEvent {
@@ -18,3 +24,15 @@ func NewProgressEvent(eventType string, message string, progress int) ProgressEv
 		Progress: progress,
 	}
 }
+
+// NewProgressEventWithData creates a progress event carrying a structured
+// payload
+func NewProgressEventWithData(eventType string, message string, progress int, data EventData) ProgressEvent {
+	progressEvent := NewProgressEvent(eventType, message, progress)
+	if data != nil {
+		progressEvent.DataType = data.DataType()
+		progressEvent.DataVersion = data.DataVersion()
+		progressEvent.Data = data
+	}
+	return progressEvent
+}
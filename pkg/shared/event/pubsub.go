@@ -0,0 +1,411 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MaxTopicLength is the longest topic, the limit of a Postgres channel
+	MaxTopicLength = 63
+	// MaxPayloadSize is the largest payload. A Postgres notification holds
+	// 8000 bytes, which the payload takes once base64 encoded.
+	MaxPayloadSize = 6000
+	// SubscriptionBuffer is how many messages a subscription holds before the
+	// next ones are dropped
+	SubscriptionBuffer = 100
+
+	PubSubDriverMemory   = "memory"
+	PubSubDriverRedis    = "redis"
+	PubSubDriverPostgres = "postgres"
+
+	redisPubSubPrefix     = "pubsub:"
+	postgresListenBackoff = time.Second
+)
+
+var (
+	ErrInvalidTopic    = errors.New("the topic must have between 1 and 63 bytes")
+	ErrPayloadTooLarge = errors.New("the payload exceeds 6000 bytes")
+	ErrPubSubClosed    = errors.New("the pub/sub is closed")
+)
+
+// Message is a payload published on a topic
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// Publisher sends a payload to the current subscribers of a topic
+type Publisher interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+}
+
+// Subscription delivers the messages of a topic until it is closed, or the
+// context it was subscribed with is done, which closes Messages
+type Subscription interface {
+	Messages() <-chan Message
+	Close() error
+}
+
+// Subscriber subscribes to a topic. The subscription receives the messages
+// published once Subscribe returned.
+type Subscriber interface {
+	Subscribe(ctx context.Context, topic string) (Subscription, error)
+}
+
+// PubSub is a Publisher and a Subscriber. Every implementation has the same
+// semantics, so code written against one runs unchanged on the others:
+//   - delivery is at most once: a message published while nobody subscribes,
+//     or while a subscription buffer is full, is lost for that subscription
+//   - the messages of a topic arrive in the order they were published
+//   - topics and payloads are limited to MaxTopicLength and MaxPayloadSize
+type PubSub interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// NewPubSubFromEnv returns the PubSub selected by the environment. Without
+// any setting a single instance is assumed and messages stay in process.
+//
+// Environment:
+//   - PUBSUB_DRIVER: memory, redis or postgres (default redis when REDIS_URL
+//     is set, memory otherwise). redis uses redisClient, the client of
+//     REDIS_URL shared by the process. postgres uses LISTEN/NOTIFY on connPool
+//     and holds a pool connection per subscription.
+func NewPubSubFromEnv(connPool *pgxpool.Pool, redisClient *redis.Client) PubSub {
+	driver := strings.ToLower(os.Getenv("PUBSUB_DRIVER"))
+	if driver == "" {
+		driver = PubSubDriverMemory
+		if os.Getenv("REDIS_URL") != "" {
+			driver = PubSubDriverRedis
+		}
+	}
+	switch driver {
+	case PubSubDriverMemory:
+	case PubSubDriverRedis:
+		if redisClient != nil {
+			return NewRedisPubSub(redisClient)
+		}
+		log.Warn().Msg("No Redis client for the redis pub/sub, falling back to an in-process pub/sub")
+	case PubSubDriverPostgres:
+		if connPool != nil {
+			return NewPostgresPubSub(connPool)
+		}
+		log.Warn().Msg("No database for the postgres pub/sub, falling back to an in-process pub/sub")
+	default:
+		log.Warn().Str("PUBSUB_DRIVER", driver).Msg("Invalid value, using default")
+	}
+	return NewMemoryPubSub()
+}
+
+func validateMessage(topic string, payload []byte) error {
+	if err := validateTopic(topic); err != nil {
+		return err
+	}
+	if len(payload) > MaxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+	return nil
+}
+
+func validateTopic(topic string) error {
+	if topic == "" || len(topic) > MaxTopicLength {
+		return ErrInvalidTopic
+	}
+	return nil
+}
+
+// subscription is the buffer shared by the implementations. Its sender
+// closes messages once closeFn stopped the delivery.
+type subscription struct {
+	topic    string
+	messages chan Message
+	done     chan struct{}
+	once     sync.Once
+	closeFn  func() error
+	closeErr error
+}
+
+func newSubscription(topic string) *subscription {
+	return &subscription{
+		topic:    topic,
+		messages: make(chan Message, SubscriptionBuffer),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+func (s *subscription) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.closeErr = s.closeFn()
+	})
+	return s.closeErr
+}
+
+// deliver never blocks the publisher: a subscriber that does not keep up
+// loses the message
+func (s *subscription) deliver(msg Message) {
+	select {
+	case s.messages <- msg:
+	default:
+		log.Warn().Str("topic", s.topic).Msg("Subscription buffer full, message dropped")
+	}
+}
+
+// closeWhenDone closes the subscription with the context it was subscribed
+// with
+func (s *subscription) closeWhenDone(ctx context.Context) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+}
+
+// MemoryPubSub delivers the messages within the process
+type MemoryPubSub struct {
+	mu     sync.Mutex
+	topics map[string]map[*subscription]struct{}
+	closed bool
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{topics: make(map[string]map[*subscription]struct{})}
+}
+
+func (p *MemoryPubSub) Publish(_ context.Context, topic string, payload []byte) error {
+	if err := validateMessage(topic, payload); err != nil {
+		return err
+	}
+	// Delivering under the lock orders the messages of concurrent publishers
+	// the same way for every subscriber
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPubSubClosed
+	}
+	for sub := range p.topics[topic] {
+		sub.deliver(Message{Topic: topic, Payload: bytes.Clone(payload)})
+	}
+	return nil
+}
+
+func (p *MemoryPubSub) Subscribe(ctx context.Context, topic string) (Subscription, error) {
+	if err := validateTopic(topic); err != nil {
+		return nil, err
+	}
+	sub := newSubscription(topic)
+	sub.closeFn = func() error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if subs, ok := p.topics[topic]; ok {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(p.topics, topic)
+			}
+			close(sub.messages)
+		}
+		return nil
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPubSubClosed
+	}
+	if p.topics[topic] == nil {
+		p.topics[topic] = make(map[*subscription]struct{})
+	}
+	p.topics[topic][sub] = struct{}{}
+	p.mu.Unlock()
+
+	sub.closeWhenDone(ctx)
+	return sub, nil
+}
+
+// Close closes every subscription
+func (p *MemoryPubSub) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	open := []*subscription{}
+	for _, subs := range p.topics {
+		for sub := range subs {
+			open = append(open, sub)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, sub := range open {
+		sub.Close()
+	}
+	return nil
+}
+
+// RedisPubSub shares the messages across instances through Redis channels
+type RedisPubSub struct {
+	client *redis.Client
+}
+
+func NewRedisPubSub(client *redis.Client) *RedisPubSub {
+	return &RedisPubSub{client: client}
+}
+
+func (p *RedisPubSub) Publish(ctx context.Context, topic string, payload []byte) error {
+	if err := validateMessage(topic, payload); err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, redisPubSubPrefix+topic, payload).Err()
+}
+
+func (p *RedisPubSub) Subscribe(ctx context.Context, topic string) (Subscription, error) {
+	if err := validateTopic(topic); err != nil {
+		return nil, err
+	}
+	ps := p.client.Subscribe(ctx, redisPubSubPrefix+topic)
+	// Wait for the confirmation, after which no message is missed
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, fmt.Errorf("event.Subscribe: %w", err)
+	}
+	sub := newSubscription(topic)
+	sub.closeFn = ps.Close
+	go func() {
+		defer close(sub.messages)
+		for msg := range ps.Channel() {
+			sub.deliver(Message{Topic: topic, Payload: []byte(msg.Payload)})
+		}
+	}()
+	sub.closeWhenDone(ctx)
+	return sub, nil
+}
+
+// Close does nothing, the client belongs to the caller
+func (p *RedisPubSub) Close() error {
+	return nil
+}
+
+// PostgresPubSub shares the messages across instances through LISTEN/NOTIFY.
+// The payloads are base64 encoded, notifications being text.
+type PostgresPubSub struct {
+	connPool *pgxpool.Pool
+}
+
+func NewPostgresPubSub(connPool *pgxpool.Pool) *PostgresPubSub {
+	return &PostgresPubSub{connPool: connPool}
+}
+
+func (p *PostgresPubSub) Publish(ctx context.Context, topic string, payload []byte) error {
+	if err := validateMessage(topic, payload); err != nil {
+		return err
+	}
+	return repository.New(p.connPool).Notify(ctx, repository.NotifyParams{
+		Channel: topic,
+		Payload: base64.StdEncoding.EncodeToString(payload),
+	})
+}
+
+func (p *PostgresPubSub) Subscribe(ctx context.Context, topic string) (Subscription, error) {
+	if err := validateTopic(topic); err != nil {
+		return nil, err
+	}
+	conn, err := p.listen(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("event.Subscribe: %w", err)
+	}
+	listenCtx, cancel := context.WithCancel(context.Background())
+	sub := newSubscription(topic)
+	sub.closeFn = func() error {
+		cancel()
+		return nil
+	}
+	go p.forward(listenCtx, conn, sub)
+	sub.closeWhenDone(ctx)
+	return sub, nil
+}
+
+// listen holds a pool connection listening to the topic
+func (p *PostgresPubSub) listen(ctx context.Context, topic string) (*pgxpool.Conn, error) {
+	conn, err := p.connPool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{topic}.Sanitize()); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// forward delivers the notifications until ctx is cancelled. A lost
+// connection is replaced, the notifications sent meanwhile being lost.
+func (p *PostgresPubSub) forward(ctx context.Context, conn *pgxpool.Conn, sub *subscription) {
+	defer close(sub.messages)
+	defer func() {
+		if conn == nil {
+			return
+		}
+		// A connection interrupted by the cancellation is closed, and
+		// dropped by the pool on release
+		if !conn.Conn().IsClosed() {
+			conn.Exec(context.Background(), "UNLISTEN *")
+		}
+		conn.Release()
+	}()
+
+	for {
+		if conn == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(postgresListenBackoff):
+			}
+			var err error
+			if conn, err = p.listen(ctx, sub.topic); err != nil {
+				log.Err(err).Str("topic", sub.topic).Msg("Failed to listen to the topic")
+				continue
+			}
+		}
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Err(err).Str("topic", sub.topic).Msg("Lost the connection listening to the topic")
+			conn.Conn().Close(context.Background())
+			conn.Release()
+			conn = nil
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(notification.Payload)
+		if err != nil {
+			log.Err(err).Str("topic", sub.topic).Msg("Invalid notification payload")
+			continue
+		}
+		sub.deliver(Message{Topic: sub.topic, Payload: payload})
+	}
+}
+
+// Close does nothing, the pool belongs to the caller
+func (p *PostgresPubSub) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPubSubOrderingAndAtMostOnce(t *testing.T) {
+	ctx := context.Background()
+	pubsub := NewMemoryPubSub()
+	defer pubsub.Close()
+
+	// Nobody subscribes yet, the message is lost
+	require.NoError(t, pubsub.Publish(ctx, "jobs", []byte("early")))
+
+	first, err := pubsub.Subscribe(ctx, "jobs")
+	require.NoError(t, err)
+	second, err := pubsub.Subscribe(ctx, "jobs")
+	require.NoError(t, err)
+	other, err := pubsub.Subscribe(ctx, "users")
+	require.NoError(t, err)
+
+	for i := 0; i < SubscriptionBuffer+5; i++ {
+		require.NoError(t, pubsub.Publish(ctx, "jobs", []byte(fmt.Sprint(i))))
+	}
+	for _, sub := range []Subscription{first, second} {
+		for i := 0; i < SubscriptionBuffer; i++ {
+			msg := <-sub.Messages()
+			require.Equal(t, "jobs", msg.Topic)
+			require.Equal(t, fmt.Sprint(i), string(msg.Payload))
+		}
+		// The messages past the buffer were dropped
+		require.Empty(t, sub.Messages())
+	}
+	require.Empty(t, other.Messages())
+
+	require.NoError(t, first.Close())
+	_, open := <-first.Messages()
+	require.False(t, open)
+	require.NoError(t, pubsub.Publish(ctx, "jobs", []byte("late")))
+	require.Equal(t, "late", string((<-second.Messages()).Payload))
+}
+
+func TestMemoryPubSubClose(t *testing.T) {
+	pubsub := NewMemoryPubSub()
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := pubsub.Subscribe(ctx, "jobs")
+	require.NoError(t, err)
+	cancel()
+	_, open := <-sub.Messages()
+	require.False(t, open)
+
+	sub, err = pubsub.Subscribe(context.Background(), "jobs")
+	require.NoError(t, err)
+	require.NoError(t, pubsub.Close())
+	_, open = <-sub.Messages()
+	require.False(t, open)
+	require.ErrorIs(t, pubsub.Publish(context.Background(), "jobs", nil), ErrPubSubClosed)
+	_, err = pubsub.Subscribe(context.Background(), "jobs")
+	require.ErrorIs(t, err, ErrPubSubClosed)
+}
+
+func TestPubSubLimits(t *testing.T) {
+	pubsub := NewMemoryPubSub()
+	ctx := context.Background()
+	require.ErrorIs(t, pubsub.Publish(ctx, "", nil), ErrInvalidTopic)
+	require.ErrorIs(t, pubsub.Publish(ctx, strings.Repeat("t", MaxTopicLength+1), nil), ErrInvalidTopic)
+	require.ErrorIs(t, pubsub.Publish(ctx, "jobs", make([]byte, MaxPayloadSize+1)), ErrPayloadTooLarge)
+	_, err := pubsub.Subscribe(ctx, "")
+	require.ErrorIs(t, err, ErrInvalidTopic)
+}
+
+func TestNewPubSubFromEnv(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+	t.Setenv("REDIS_URL", "")
+	t.Setenv("PUBSUB_DRIVER", "")
+	require.IsType(t, &MemoryPubSub{}, NewPubSubFromEnv(nil, nil))
+
+	t.Setenv("REDIS_URL", "redis://localhost:6379/0")
+	require.IsType(t, &RedisPubSub{}, NewPubSubFromEnv(nil, client))
+	// The redis driver cannot work without the client, REDIS_URL being invalid
+	require.IsType(t, &MemoryPubSub{}, NewPubSubFromEnv(nil, nil))
+	// The client is shared with the rest of the process and stays open
+	require.NoError(t, NewRedisPubSub(client).Close())
+	require.NotErrorIs(t, client.Ping(context.Background()).Err(), redis.ErrClosed)
+
+	// Without a pool the postgres driver cannot work
+	t.Setenv("PUBSUB_DRIVER", "postgres")
+	require.IsType(t, &MemoryPubSub{}, NewPubSubFromEnv(nil, client))
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// encryptedFileMagic starts an encrypted file, followed by the id of the data
+// key, the nonce and the AES-GCM ciphertext
+var encryptedFileMagic = []byte("CTUPENC1")
+
+const encryptedFileHeaderSize = 8 + 16
+
+var (
+	// ErrEncryptedFile is returned by SignedURL for an encrypted file, which
+	// only the service can decrypt
+	ErrEncryptedFile = errors.New("the file is encrypted and cannot be served by a signed URL")
+	// ErrUnknownFileKey is returned for a file encrypted with a data key the
+	// keyring does not hold
+	ErrUnknownFileKey = errors.New("file encrypted with an unknown key")
+)
+
+// DataKey is an AES-256 key encrypting the files of a tenant
+type DataKey struct {
+	ID  uuid.UUID
+	Key []byte
+}
+
+// TenantKeyring provides the data keys of the tenants that encrypt their
+// files
+type TenantKeyring interface {
+	// CurrentKey returns the key encrypting the new files of the tenant, nil
+	// when the tenant does not encrypt its files
+	CurrentKey(ctx context.Context, tenantID string) (*DataKey, error)
+	// Key returns a key of the tenant by id, current or not
+	Key(ctx context.Context, tenantID string, id uuid.UUID) (*DataKey, error)
+}
+
+var (
+	keyringMu sync.RWMutex
+	keyring   TenantKeyring
+)
+
+// SetTenantKeyring enables the encryption of the tenant files by every
+// FileService. Without a keyring the files are stored as given.
+func SetTenantKeyring(k TenantKeyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	keyring = k
+}
+
+func currentKeyring() TenantKeyring {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	return keyring
+}
+
+// TenantFilePrefix is the prefix of the files of a tenant
+func TenantFilePrefix(tenantID string) string {
+	return "/tenants/" + tenantID + "/"
+}
+
+// tenantOfPath returns the tenant a file belongs to, empty for the files
+// outside of TenantFilePrefix
+func tenantOfPath(filename string) string {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(filename, "/"), "tenants/")
+	if !ok {
+		return ""
+	}
+	tenantID, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return ""
+	}
+	return tenantID
+}
+
+// EncryptedFileKeyID returns the id of the data key of an encrypted file
+func EncryptedFileKeyID(data []byte) (uuid.UUID, bool) {
+	if len(data) < encryptedFileHeaderSize || !bytes.Equal(data[:len(encryptedFileMagic)], encryptedFileMagic) {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromBytes(data[len(encryptedFileMagic):encryptedFileHeaderSize])
+	return id, err == nil
+}
+
+func newFileAEAD(key *DataKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptFile seals the file for the tenant, which is authenticated with it
+// so a file copied to another tenant does not decrypt there
+func encryptFile(key *DataKey, tenantID string, data []byte) ([]byte, error) {
+	aead, err := newFileAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, encryptedFileHeaderSize+aead.NonceSize()+len(data)+aead.Overhead())
+	out = append(out, encryptedFileMagic...)
+	out = append(out, key.ID[:]...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, []byte(tenantID)), nil
+}
+
+// decryptFile returns the content of a file, unchanged when it is not
+// encrypted
+func decryptFile(ctx context.Context, k TenantKeyring, tenantID string, data []byte) ([]byte, error) {
+	keyID, ok := EncryptedFileKeyID(data)
+	if !ok {
+		return data, nil
+	}
+	if k == nil {
+		return nil, fmt.Errorf("%w %s: no keyring", ErrUnknownFileKey, keyID)
+	}
+	key, err := k.Key(ctx, tenantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w %s", ErrUnknownFileKey, keyID)
+	}
+	aead, err := newFileAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed := data[encryptedFileHeaderSize:]
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted file")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt file: %w", err)
+	}
+	return plain, nil
+}
+
+// seal returns the content to store for a file: encrypted with the current
+// key of its tenant, if any
+func seal(ctx context.Context, filename string, data []byte) ([]byte, error) {
+	k := currentKeyring()
+	tenantID := tenantOfPath(filename)
+	if k == nil || tenantID == "" {
+		return data, nil
+	}
+	key, err := k.CurrentKey(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get the data key of tenant %s: %w", tenantID, err)
+	}
+	if key == nil {
+		return data, nil
+	}
+	return encryptFile(key, tenantID, data)
+}
+
+// open returns the content of a stored file
+func open(ctx context.Context, filename string, data []byte) ([]byte, error) {
+	return decryptFile(ctx, currentKeyring(), tenantOfPath(filename), data)
+}
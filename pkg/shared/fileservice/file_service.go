@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/storage" // GCS client
 	"ctoup.com/coreapp/pkg/shared/util"
@@ -41,6 +42,7 @@ import (
 
 type FileService struct {
 	bucket *blob.Bucket
+	images ImageProcessor
 }
 
 func NewFileService() *FileService {
@@ -100,9 +102,15 @@ func NewFileService() *FileService {
 
 	return &FileService{
 		bucket: b,
+		images: NewImageProcessor(ImageConfigFromEnv()),
 	}
 }
 
+// SetImageProcessor replaces the processor of the uploaded images
+func (fs *FileService) SetImageProcessor(p ImageProcessor) {
+	fs.images = p
+}
+
 // createGCSBucketIfNotExists uses the GCS client to create a bucket if it does not exist.
 func createGCSBucketIfNotExists(ctx context.Context, bucketName string) error {
 	log.Info().Msgf("Checking for existence of GCS bucket: %s", bucketName)
@@ -243,9 +251,19 @@ func createAzureContainerIfNotExists(ctx context.Context, containerName string)
 	return nil
 }
 
-// SaveFile writes data to a file in the specified bucket.
+// SaveFile writes data to a file in the specified bucket. The files of a
+// tenant with a data key are encrypted, and refused once the tenant reached
+// its storage quota.
 func (fs *FileService) SaveFile(ctx context.Context, data []byte, filename string) error {
 	logger := util.GetLoggerFromCtx(ctx)
+	data, err := seal(ctx, filename, data)
+	if err != nil {
+		logger.Err(err).Msg("Failed to encrypt file")
+		return err
+	}
+	if err := checkStorage(ctx, filename, int64(len(data))); err != nil {
+		return err
+	}
 	// We can now use the `fs.bucket` attribute directly.
 	w, err := fs.bucket.NewWriter(ctx, filename, nil)
 	if err != nil {
@@ -261,7 +279,13 @@ func (fs *FileService) SaveFile(ctx context.Context, data []byte, filename strin
 	}
 
 	// Close the writer to finalize the write operation.
-	return w.Close()
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := recordStorage(ctx, filename, int64(len(data))); err != nil {
+		logger.Err(err).Msgf("Failed to record the size of file %s", filename)
+	}
+	return nil
 }
 
 // DeleteFile deletes a file from the specified bucket.
@@ -272,16 +296,39 @@ func (fs *FileService) DeleteFile(ctx context.Context, filename string) error {
 		logger.Err(err).Msgf("Failed to delete file %s", filename)
 		return err
 	}
+	if err := recordStorage(ctx, filename, -1); err != nil {
+		logger.Err(err).Msgf("Failed to forget the size of file %s", filename)
+	}
 	return nil
 }
 
-// CopyFile copies a file from src to dst within the same bucket.
+// CopyFile copies a file from src to dst within the same bucket. A copy to
+// another tenant is encrypted again for it.
 func (fs *FileService) CopyFile(ctx context.Context, dst, src string) error {
 	logger := util.GetLoggerFromCtx(ctx)
+	if currentKeyring() != nil && tenantOfPath(dst) != tenantOfPath(src) {
+		data, err := fs.ReadFileBytes(ctx, src)
+		if err != nil {
+			logger.Err(err).Msgf("Failed to read file %s to copy", src)
+			return err
+		}
+		return fs.SaveFile(ctx, data, dst)
+	}
+	attrs, err := fs.bucket.Attributes(ctx, src)
+	if err != nil {
+		logger.Err(err).Msgf("Failed to read the attributes of file %s to copy", src)
+		return err
+	}
+	if err := checkStorage(ctx, dst, attrs.Size); err != nil {
+		return err
+	}
 	if err := fs.bucket.Copy(ctx, dst, src, nil); err != nil {
 		logger.Err(err).Msgf("Failed to copy file from %s to %s", src, dst)
 		return err
 	}
+	if err := recordStorage(ctx, dst, attrs.Size); err != nil {
+		logger.Err(err).Msgf("Failed to record the size of file %s", dst)
+	}
 	return nil
 }
 
@@ -312,7 +359,32 @@ func (fs *FileService) ReadFileBytes(ctx context.Context, filename string) ([]by
 		return nil, fmt.Errorf("open file %s: %w", filename, err)
 	}
 	defer reader.Close()
-	return io.ReadAll(reader)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s: %w", filename, err)
+	}
+	return open(ctx, filename, data)
+}
+
+// SignedURL returns a URL granting read access to the file until expiry. It
+// fails with gcerrors.Unimplemented on storage that cannot sign URLs, such as
+// the file system, and with ErrEncryptedFile for an encrypted file.
+func (fs *FileService) SignedURL(ctx context.Context, filename string, expiry time.Duration) (string, error) {
+	if currentKeyring() != nil && tenantOfPath(filename) != "" {
+		reader, err := fs.bucket.NewRangeReader(ctx, filename, 0, encryptedFileHeaderSize, nil)
+		if err != nil {
+			return "", err
+		}
+		header, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return "", err
+		}
+		if _, encrypted := EncryptedFileKeyID(header); encrypted {
+			return "", ErrEncryptedFile
+		}
+	}
+	return fs.bucket.SignedURL(ctx, filename, &blob.SignedURLOptions{Expiry: expiry})
 }
 
 // GetFile retrieves a file from the specified bucket and writes its contents to the HTTP response.
@@ -335,6 +407,9 @@ func (fs *FileService) GetFile(ctx *gin.Context, filename string) error {
 
 	// Read the file content to generate ETag
 	content, err := io.ReadAll(reader)
+	if err == nil {
+		content, err = open(ctx, filename, content)
+	}
 	if err != nil {
 		logger.Err(err).Msg("Failed to read file content")
 		ctx.AbortWithError(500, err)
@@ -347,10 +422,14 @@ func (fs *FileService) GetFile(ctx *gin.Context, filename string) error {
 	// Set content type based on file extension
 	contentType := fs.getContentType(filename)
 
-	// Set cache headers
+	// Set cache headers, keeping a Cache-Control the caller already set
+	cacheControl := ctx.Writer.Header().Get("Cache-Control")
+	if cacheControl == "" {
+		cacheControl = "public, max-age=3600" // Cache for 1 hour
+	}
 	ctx.Header("ETag", etag)
 	ctx.Header("Content-Type", contentType)
-	ctx.Header("Cache-Control", "public, max-age=3600") // Cache for 1 hour
+	ctx.Header("Cache-Control", cacheControl)
 
 	// Add headers to encourage caching for CORS requests
 	ctx.Header("Vary", "Origin, Authorization")
@@ -366,7 +445,7 @@ func (fs *FileService) GetFile(ctx *gin.Context, filename string) error {
 
 		if clientETag == serverETag {
 			// Override any no-cache directives for unchanged content
-			ctx.Header("Cache-Control", "public, max-age=3600")
+			ctx.Header("Cache-Control", cacheControl)
 			ctx.Status(http.StatusNotModified)
 			return nil
 		}
@@ -413,3 +492,123 @@ func (fs *FileService) getContentType(filename string) string {
 		return contentType
 	}
 }
+
+// SaveProfilePicture validates an uploaded picture and stores it in each of
+// the ProfilePictureVariants
+func (fs *FileService) SaveProfilePicture(ctx context.Context, userID string, data []byte) error {
+	variants, err := fs.images.Process(data, ProfilePictureVariants)
+	if err != nil {
+		return err
+	}
+	for _, variant := range ProfilePictureVariants {
+		if err := fs.SaveFile(ctx, variants[variant.Name], ProfilePicturePath(userID, variant.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProfilePicture writes a variant of the profile picture of the user. A
+// picture uploaded before the variants existed is only stored in the large
+// one, which is served for every size.
+func (fs *FileService) GetProfilePicture(ctx *gin.Context, userID, variant string) error {
+	filename := ProfilePicturePath(userID, variant)
+	if filename != ProfilePictureFilePath(userID) {
+		exists, err := fs.FileExists(ctx, filename)
+		if err != nil {
+			ctx.AbortWithError(500, err)
+			return err
+		}
+		if !exists {
+			filename = ProfilePictureFilePath(userID)
+		}
+	}
+	return fs.GetFile(ctx, filename)
+}
+
+// ListFiles returns the paths of the files stored under the prefix
+func (fs *FileService) ListFiles(ctx context.Context, prefix string) ([]string, error) {
+	paths := []string{}
+	iter := fs.bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return paths, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !obj.IsDir {
+			paths = append(paths, obj.Key)
+		}
+	}
+}
+
+// TenantFileSizes returns the size of every file stored under the prefix of
+// the tenant, by path
+func (fs *FileService) TenantFileSizes(ctx context.Context, tenantID string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+	iter := fs.bucket.List(&blob.ListOptions{Prefix: TenantFilePrefix(tenantID)})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return sizes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !obj.IsDir {
+			sizes[obj.Key] = obj.Size
+		}
+	}
+}
+
+// RewriteTenantFiles encrypts the files of the tenant with its current data
+// key, the files encrypted with another key or stored in plaintext being
+// rewritten. It returns the number of files rewritten.
+func (fs *FileService) RewriteTenantFiles(ctx context.Context, tenantID string) (int, error) {
+	k := currentKeyring()
+	if k == nil {
+		return 0, errors.New("no tenant keyring")
+	}
+	key, err := k.CurrentKey(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if key == nil {
+		return 0, nil
+	}
+	rewritten := 0
+	iter := fs.bucket.List(&blob.ListOptions{Prefix: TenantFilePrefix(tenantID)})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			return rewritten, nil
+		}
+		if err != nil {
+			return rewritten, err
+		}
+		if obj.IsDir {
+			continue
+		}
+		stored, err := fs.bucket.ReadAll(ctx, obj.Key)
+		if err != nil {
+			return rewritten, fmt.Errorf("read file %s: %w", obj.Key, err)
+		}
+		if keyID, ok := EncryptedFileKeyID(stored); ok && keyID == key.ID {
+			continue
+		}
+		data, err := decryptFile(ctx, k, tenantID, stored)
+		if err != nil {
+			return rewritten, fmt.Errorf("decrypt file %s: %w", obj.Key, err)
+		}
+		sealed, err := encryptFile(key, tenantID, data)
+		if err != nil {
+			return rewritten, err
+		}
+		if err := fs.bucket.WriteAll(ctx, obj.Key, sealed, nil); err != nil {
+			return rewritten, fmt.Errorf("write file %s: %w", obj.Key, err)
+		}
+		rewritten++
+	}
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // Registers the PNG decoder
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Names of the image variants
+const (
+	ImageVariantSmall  = "small"
+	ImageVariantMedium = "medium"
+	ImageVariantLarge  = "large"
+)
+
+const (
+	DefaultImageMaxBytes     = 10 << 20
+	DefaultImageMaxDimension = 4096
+	DefaultImageJPEGQuality  = 85
+)
+
+// ImageVariant is a size an image is stored in. It fits in a MaxSize square
+// and keeps its aspect ratio; a smaller image is not enlarged.
+type ImageVariant struct {
+	Name    string
+	MaxSize int
+}
+
+// ProfilePictureVariants are the sizes a profile picture is stored in
+var ProfilePictureVariants = []ImageVariant{
+	{Name: ImageVariantSmall, MaxSize: 64},
+	{Name: ImageVariantMedium, MaxSize: 256},
+	{Name: ImageVariantLarge, MaxSize: 1024},
+}
+
+var supportedImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+var (
+	// ErrInvalidImage is returned for an image that does not decode or whose
+	// dimensions exceed the maximum
+	ErrInvalidImage     = errors.New("invalid image")
+	ErrUnsupportedImage = errors.New("unsupported image type, expected JPEG, PNG or GIF")
+	ErrImageTooLarge    = errors.New("image file too large")
+)
+
+// ImageProcessor turns an uploaded image into the variants to store
+type ImageProcessor interface {
+	// Process validates the image and returns it encoded in each variant, by
+	// variant name. The variants carry none of the metadata of the upload.
+	Process(data []byte, variants []ImageVariant) (map[string][]byte, error)
+}
+
+// ImageConfig limits the uploaded images
+type ImageConfig struct {
+	// MaxBytes is the maximum size of an uploaded file
+	MaxBytes int64
+	// MaxDimension is the maximum width and height of an uploaded image, which
+	// bounds the memory needed to decode it
+	MaxDimension int
+	JPEGQuality  int
+}
+
+// ImageConfigFromEnv reads the limits of the uploaded images.
+//
+// Environment:
+//   - IMAGE_UPLOAD_MAX_BYTES: maximum size of an uploaded file (default 10 MiB)
+//   - IMAGE_MAX_DIMENSION: maximum width and height in pixels (default 4096)
+//   - IMAGE_JPEG_QUALITY: quality of the stored variants, 1 to 100 (default 85)
+func ImageConfigFromEnv() ImageConfig {
+	cfg := ImageConfig{
+		MaxBytes:     DefaultImageMaxBytes,
+		MaxDimension: DefaultImageMaxDimension,
+		JPEGQuality:  DefaultImageJPEGQuality,
+	}
+	if v := os.Getenv("IMAGE_UPLOAD_MAX_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			cfg.MaxBytes = n
+		} else {
+			log.Warn().Str("IMAGE_UPLOAD_MAX_BYTES", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("IMAGE_MAX_DIMENSION"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxDimension = n
+		} else {
+			log.Warn().Str("IMAGE_MAX_DIMENSION", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("IMAGE_JPEG_QUALITY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= 100 {
+			cfg.JPEGQuality = n
+		} else {
+			log.Warn().Str("IMAGE_JPEG_QUALITY", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// stdImageProcessor decodes JPEG, PNG and GIF images with the standard
+// library and encodes the variants as JPEG, on a white background for the
+// transparent images. Re-encoding drops the EXIF data, once the orientation
+// it records has been applied.
+type stdImageProcessor struct {
+	cfg ImageConfig
+}
+
+func NewImageProcessor(cfg ImageConfig) ImageProcessor {
+	return &stdImageProcessor{cfg: cfg}
+}
+
+func (p *stdImageProcessor) Process(data []byte, variants []ImageVariant) (map[string][]byte, error) {
+	if p.cfg.MaxBytes > 0 && int64(len(data)) > p.cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: the maximum is %d bytes", ErrImageTooLarge, p.cfg.MaxBytes)
+	}
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(supportedImageTypes, contentType) {
+		return nil, fmt.Errorf("%w, got %s", ErrUnsupportedImage, contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width > p.cfg.MaxDimension || config.Height > p.cfg.MaxDimension {
+		return nil, fmt.Errorf("%w: %dx%d exceeds the maximum of %dx%d pixels",
+			ErrInvalidImage, config.Width, config.Height, p.cfg.MaxDimension, p.cfg.MaxDimension)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	flat := flattenImage(img)
+	if contentType == "image/jpeg" {
+		flat = orientImage(flat, jpegOrientation(data))
+	}
+
+	// Each variant is resized from the next larger one
+	ordered := slices.Clone(variants)
+	slices.SortFunc(ordered, func(a, b ImageVariant) int { return b.MaxSize - a.MaxSize })
+	result := make(map[string][]byte, len(variants))
+	current := flat
+	for _, variant := range ordered {
+		current = resizeToFit(current, variant.MaxSize)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, current, &jpeg.Options{Quality: p.cfg.JPEGQuality}); err != nil {
+			return nil, err
+		}
+		result[variant.Name] = buf.Bytes()
+	}
+	return result, nil
+}
+
+// flattenImage draws the image on a white background
+func flattenImage(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	return dst
+}
+
+// resizeToFit scales the image down to fit in a maxSize square, averaging
+// the source pixels each target pixel covers
+func resizeToFit(src *image.RGBA, maxSize int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxSize && h <= maxSize {
+		return src
+	}
+	dw, dh := maxSize, maxSize
+	if w >= h {
+		dh = max(1, h*maxSize/w)
+	} else {
+		dw = max(1, w*maxSize/h)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := y * h / dh
+		y1 := max((y+1)*h/dh, y0+1)
+		for x := 0; x < dw; x++ {
+			x0 := x * w / dw
+			x1 := max((x+1)*w/dw, x0+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// orientImage applies an EXIF orientation, so the image displays upright
+// without it
+func orientImage(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flipped
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90° clockwise to display
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90° counterclockwise to display
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, 1 (upright)
+// when it records none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// The metadata segments come before the start of scan
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag of the first IFD of a TIFF
+// structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		offset := ifd + 2 + e*12
+		if offset+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[offset:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[offset+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
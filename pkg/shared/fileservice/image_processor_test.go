@@ -0,0 +1,172 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testImageConfig() ImageConfig {
+	return ImageConfig{MaxBytes: DefaultImageMaxBytes, MaxDimension: DefaultImageMaxDimension, JPEGQuality: DefaultImageJPEGQuality}
+}
+
+// testImage returns a w x h image, red on its left half and blue on its right
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withEXIFOrientation inserts an EXIF segment recording the orientation
+// after the start of image marker of a JPEG
+func withEXIFOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+	result := append([]byte{}, data[:2]...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+func decodeVariant(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "jpeg", format)
+	return img
+}
+
+func TestImageProcessorResizesIntoVariants(t *testing.T) {
+	p := NewImageProcessor(testImageConfig())
+
+	variants, err := p.Process(encodePNG(t, testImage(2000, 1000)), ProfilePictureVariants)
+	require.NoError(t, err)
+	require.Len(t, variants, len(ProfilePictureVariants))
+	// Each variant fits in its square and keeps the aspect ratio
+	for name, size := range map[string]image.Point{
+		ImageVariantSmall:  {64, 32},
+		ImageVariantMedium: {256, 128},
+		ImageVariantLarge:  {1024, 512},
+	} {
+		require.Equal(t, size, decodeVariant(t, variants[name]).Bounds().Size(), name)
+	}
+
+	// A smaller image is not enlarged
+	variants, err = p.Process(encodeJPEG(t, testImage(100, 200)), ProfilePictureVariants)
+	require.NoError(t, err)
+	require.Equal(t, image.Pt(32, 64), decodeVariant(t, variants[ImageVariantSmall]).Bounds().Size())
+	require.Equal(t, image.Pt(100, 200), decodeVariant(t, variants[ImageVariantMedium]).Bounds().Size())
+	require.Equal(t, image.Pt(100, 200), decodeVariant(t, variants[ImageVariantLarge]).Bounds().Size())
+}
+
+func TestImageProcessorFlattensTransparency(t *testing.T) {
+	p := NewImageProcessor(testImageConfig())
+
+	variants, err := p.Process(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 10, 10))), ProfilePictureVariants)
+	require.NoError(t, err)
+	r, g, b, _ := decodeVariant(t, variants[ImageVariantSmall]).At(5, 5).RGBA()
+	require.Greater(t, r>>8, uint32(250))
+	require.Greater(t, g>>8, uint32(250))
+	require.Greater(t, b>>8, uint32(250))
+}
+
+func TestImageProcessorStripsEXIF(t *testing.T) {
+	p := NewImageProcessor(testImageConfig())
+	// Rotated 90° clockwise to display: the red left half goes on top
+	data := withEXIFOrientation(encodeJPEG(t, testImage(40, 20)), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	variants, err := p.Process(data, ProfilePictureVariants)
+	require.NoError(t, err)
+	for name, variant := range variants {
+		require.False(t, bytes.Contains(variant, []byte("Exif")), name)
+		require.Equal(t, 1, jpegOrientation(variant), name)
+	}
+	img := decodeVariant(t, variants[ImageVariantLarge])
+	require.Equal(t, image.Pt(20, 40), img.Bounds().Size())
+	r, _, b, _ := img.At(10, 5).RGBA()
+	require.Greater(t, r, b)
+	r, _, b, _ = img.At(10, 35).RGBA()
+	require.Greater(t, b, r)
+}
+
+func TestImageProcessorRejectsFormats(t *testing.T) {
+	p := NewImageProcessor(testImageConfig())
+
+	for name, data := range map[string][]byte{
+		"text": []byte("not an image at all"),
+		"pdf":  []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"),
+		"bmp":  append([]byte("BM"), make([]byte, 64)...),
+	} {
+		_, err := p.Process(data, ProfilePictureVariants)
+		require.ErrorIs(t, err, ErrUnsupportedImage, name)
+	}
+
+	// A supported type that does not decode
+	truncated := encodePNG(t, testImage(20, 20))[:40]
+	_, err := p.Process(truncated, ProfilePictureVariants)
+	require.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestImageProcessorLimits(t *testing.T) {
+	data := encodePNG(t, testImage(300, 200))
+
+	t.Run("file size", func(t *testing.T) {
+		cfg := testImageConfig()
+		cfg.MaxBytes = int64(len(data) - 1)
+		_, err := NewImageProcessor(cfg).Process(data, ProfilePictureVariants)
+		require.ErrorIs(t, err, ErrImageTooLarge)
+
+		cfg.MaxBytes = int64(len(data))
+		_, err = NewImageProcessor(cfg).Process(data, ProfilePictureVariants)
+		require.NoError(t, err)
+	})
+
+	t.Run("dimensions", func(t *testing.T) {
+		cfg := testImageConfig()
+		cfg.MaxDimension = 299
+		_, err := NewImageProcessor(cfg).Process(data, ProfilePictureVariants)
+		require.ErrorIs(t, err, ErrInvalidImage)
+
+		cfg.MaxDimension = 300
+		_, err = NewImageProcessor(cfg).Process(data, ProfilePictureVariants)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// TenantStorageQuota keeps the storage of the tenants within their quota. It
+// is given the files stored under TenantFilePrefix, with their stored size.
+type TenantStorageQuota interface {
+	// CheckFile returns an error when writing size bytes to the file would
+	// take the tenant over its quota
+	CheckFile(ctx context.Context, tenantID, filename string, size int64) error
+	// FileSaved records the size of a file written
+	FileSaved(ctx context.Context, tenantID, filename string, size int64) error
+	// FileDeleted forgets a file deleted
+	FileDeleted(ctx context.Context, tenantID, filename string) error
+}
+
+var (
+	storageQuotaMu sync.RWMutex
+	storageQuota   TenantStorageQuota
+)
+
+// SetTenantStorageQuota enables the storage quota of the tenants in every
+// FileService. Without one the files are not accounted.
+func SetTenantStorageQuota(q TenantStorageQuota) {
+	storageQuotaMu.Lock()
+	defer storageQuotaMu.Unlock()
+	storageQuota = q
+}
+
+func currentStorageQuota() TenantStorageQuota {
+	storageQuotaMu.RLock()
+	defer storageQuotaMu.RUnlock()
+	return storageQuota
+}
+
+// checkStorage checks a file to write against the quota of its tenant
+func checkStorage(ctx context.Context, filename string, size int64) error {
+	q := currentStorageQuota()
+	tenantID := tenantOfPath(filename)
+	if q == nil || tenantID == "" {
+		return nil
+	}
+	return q.CheckFile(ctx, tenantID, filename, size)
+}
+
+// recordStorage records a file written or, with a negative size, deleted.
+// The accounting is best effort: its failures are returned for logging, the
+// file operation is done.
+func recordStorage(ctx context.Context, filename string, size int64) error {
+	q := currentStorageQuota()
+	tenantID := tenantOfPath(filename)
+	if q == nil || tenantID == "" {
+		return nil
+	}
+	if size < 0 {
+		return q.FileDeleted(ctx, tenantID, filename)
+	}
+	return q.FileSaved(ctx, tenantID, filename, size)
+}
@@ -0,0 +1,10 @@
+package service
+
+// TenantPictureTypes are the branding pictures a tenant can upload
+var TenantPictureTypes = []string{"logo", "bg", "bg-mobile"}
+
+// TenantPicturePath returns the object-storage path of a branding picture of
+// the tenant, one of TenantPictureTypes
+func TenantPicturePath(tenantID, pictureType string) string {
+	return "/tenants/" + tenantID + "/core/pictures/" + pictureType + ".webp"
+}
@@ -5,6 +5,28 @@ package service
 // cleanup code (GDPR erasure in skeells, account-recovery flows, etc.)
 // can address the exact same key the upload handler writes — without
 // re-deriving the path format and risking silent drift.
+//
+// It holds the large variant of the picture; ProfilePictureFilePaths lists
+// every variant.
 func ProfilePictureFilePath(userID string) string {
 	return "/core/users/" + userID + "/profile-picture.jpg"
 }
+
+// ProfilePicturePath returns the path of a variant of the profile picture,
+// the large one for an empty variant
+func ProfilePicturePath(userID, variant string) string {
+	if variant == "" || variant == ImageVariantLarge {
+		return ProfilePictureFilePath(userID)
+	}
+	return "/core/users/" + userID + "/profile-picture-" + variant + ".jpg"
+}
+
+// ProfilePictureFilePaths returns the paths of every variant of the profile
+// picture, for the code deleting it
+func ProfilePictureFilePaths(userID string) []string {
+	paths := make([]string, 0, len(ProfilePictureVariants))
+	for _, variant := range ProfilePictureVariants {
+		paths = append(paths, ProfilePicturePath(userID, variant.Name))
+	}
+	return paths
+}
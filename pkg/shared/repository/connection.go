@@ -75,7 +75,13 @@ func NewPostgresConnector(connectionString string) PostgresConnector {
 
 // Connect always throws error
 func (r PostgresConnector) Connect() (*pgxpool.Pool, error) {
-	connPool, err := pgxpool.New(context.Background(), r.connectionString)
+	config, err := pgxpool.ParseConfig(r.connectionString)
+	if err != nil {
+		log.Printf("connect error %v \n", err)
+		return nil, err
+	}
+	config.ConnConfig.Tracer = ReplayQueryTracer{}
+	connPool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		log.Printf("connect error %v \n", err)
 	}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+type replayQueryStartKey struct{}
+
+type replayQueryStart struct {
+	name string
+	at   time.Time
+}
+
+// ReplayQueryTracer records the queries of captured requests, by their sqlc
+// name, for the replay bundles. Requests that are not captured only pay a
+// context lookup.
+type ReplayQueryTracer struct{}
+
+func (ReplayQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if util.GetReplayRecorder(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, replayQueryStartKey{}, replayQueryStart{name: queryName(data.SQL), at: time.Now()})
+}
+
+func (ReplayQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(replayQueryStartKey{}).(replayQueryStart)
+	if !ok {
+		return
+	}
+	call := util.ReplayCall{
+		Kind:      "database",
+		Name:      start.name,
+		StartedAt: start.at,
+		Duration:  time.Since(start.at),
+		Rows:      data.CommandTag.RowsAffected(),
+	}
+	if data.Err != nil {
+		call.Error = data.Err.Error()
+	}
+	util.RecordReplayCall(ctx, call)
+}
+
+// queryName returns the name of a sqlc query, from its "-- name: X :kind"
+// line. Other statements are named by their first word, their text may hold
+// values.
+func queryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "query"
+}
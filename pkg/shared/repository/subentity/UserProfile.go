@@ -10,6 +10,12 @@ type UserProfile struct {
 	Interests            []string `json:"interests"`
 	Skills               []string `json:"skills"`
 	PhoneNumber          string   `json:"phoneNumber"`
-	Function             string   `json:"function"`
-	Company              string   `json:"company"`
+	// PhoneNumberVerified is set once PhoneNumber is confirmed by SMS, and
+	// cleared when the number changes
+	PhoneNumberVerified bool   `json:"phoneNumberVerified"`
+	Function            string `json:"function"`
+	Company             string `json:"company"`
+	// Attributes holds the values of the custom attributes defined by the
+	// tenants of the user
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
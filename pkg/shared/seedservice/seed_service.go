@@ -10,6 +10,7 @@ import (
 	"ctoup.com/coreapp/pkg/core/db/repository"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"ctoup.com/coreapp/pkg/shared/service"
 	"github.com/rs/zerolog/log"
 )
 
@@ -91,7 +92,7 @@ func (ss *SeedService) seedAdminUser(c context.Context, qtx *repository.Queries,
 			EmailVerified(false).
 			Password(adminPassword).
 			DisplayName(adminName).
-			PhotoURL("/images/avatar-1.jpeg").
+			PhotoURL(service.PlatformAssetsFromEnv().DefaultAvatarURL).
 			Disabled(false)
 
 		userRecord, err := ss.client.CreateUser(c, params)
@@ -110,13 +111,19 @@ func (ss *SeedService) seedAdminUser(c context.Context, qtx *repository.Queries,
 			return err
 		}
 
+		email, blindIndex, err := service.EncryptEmail(adminEmail)
+		if err != nil {
+			log.Err(err).Msg("Error encrypting admin email")
+			return err
+		}
 		_, err = qtx.CreateUserByTenant(c, repository.CreateUserByTenantParams{
 			ID:    userRecord.UID,
-			Email: adminEmail,
+			Email: email,
 			Profile: subentity.UserProfile{
 				Name: adminName,
 			},
-			Roles: []string{string(core.SUPERADMIN), string(core.ADMIN)},
+			Roles:           []string{string(core.SUPERADMIN), string(core.ADMIN)},
+			EmailBlindIndex: blindIndex,
 		})
 		if err != nil {
 			log.Err(err).Msg("Error creating user in database")
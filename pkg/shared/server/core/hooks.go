@@ -0,0 +1,226 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// HookPoint is a step of the request lifecycle where plugin hooks run
+type HookPoint string
+
+const (
+	// OnTenantResolved runs after the tenant middleware, before authentication.
+	// The tenant ID is "" on the admin and auth subdomains.
+	OnTenantResolved HookPoint = "tenant_resolved"
+	// OnRequestAuthenticated runs after the auth middleware, for authenticated
+	// requests only: public routes do not trigger it.
+	OnRequestAuthenticated HookPoint = "request_authenticated"
+	// OnResponse runs once per request, just before the response headers are
+	// written, so hooks can still add headers. c.Writer.Status() is the status
+	// about to be sent. It also runs for requests aborted by a middleware.
+	OnResponse HookPoint = "response"
+)
+
+// HookFunc is a plugin hook. For OnTenantResolved and OnRequestAuthenticated,
+// an error aborts the request: a *HookError sets the response status and
+// message, any other error responds 500 without exposing it. For OnResponse
+// the response can no longer change, so errors are only logged.
+type HookFunc func(c *gin.Context) error
+
+// Hook is a named plugin hook. Hooks of a point run by ascending Order, then in
+// registration order; the first failing hook stops the following ones.
+type Hook struct {
+	Name  string
+	Order int
+	Fn    HookFunc
+}
+
+// HookError rejects a request with the given status and message
+type HookError struct {
+	Status  int
+	Message string
+}
+
+func (e *HookError) Error() string {
+	return e.Message
+}
+
+func NewHookError(status int, message string) *HookError {
+	return &HookError{Status: status, Message: message}
+}
+
+// hookRegistry holds the hooks registered by the embedding application. Like
+// authMiddlewareSlot, the router captures its bound methods when routes are
+// registered and the hooks are looked up per request, so hooks registered
+// after NewServerConfig apply to every route.
+type hookRegistry struct {
+	mu    sync.RWMutex
+	hooks map[HookPoint][]Hook
+}
+
+func newHookRegistry() *hookRegistry {
+	return &hookRegistry{hooks: map[HookPoint][]Hook{}}
+}
+
+func (r *hookRegistry) register(point HookPoint, hook Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Copy on write, requests in flight keep iterating over the previous slice
+	hooks := append(slices.Clone(r.hooks[point]), hook)
+	slices.SortStableFunc(hooks, func(a, b Hook) int {
+		return a.Order - b.Order
+	})
+	r.hooks[point] = hooks
+}
+
+func (r *hookRegistry) get(point HookPoint) []Hook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.hooks[point]
+}
+
+// run calls the hooks of point and returns the first error
+func (r *hookRegistry) run(c *gin.Context, point HookPoint) (string, error) {
+	for _, hook := range r.get(point) {
+		if err := hook.Fn(c); err != nil {
+			return hook.Name, err
+		}
+	}
+	return "", nil
+}
+
+// runOrAbort runs the hooks of point and aborts the request on error
+func (r *hookRegistry) runOrAbort(c *gin.Context, point HookPoint) bool {
+	name, err := r.run(c, point)
+	if err == nil {
+		return true
+	}
+	var hookErr *HookError
+	if errors.As(err, &hookErr) {
+		c.AbortWithStatusJSON(hookErr.Status, gin.H{
+			"status":  hookErr.Status,
+			"message": hookErr.Message,
+		})
+		return false
+	}
+	logger := util.GetLoggerFromCtx(c.Request.Context())
+	logger.Err(err).Str("hook", name).Str("point", string(point)).Msg("Plugin hook failed")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		"status":  http.StatusInternalServerError,
+		"message": "Internal server error",
+	})
+	return false
+}
+
+func (r *hookRegistry) tenantResolved(c *gin.Context) {
+	if r.runOrAbort(c, OnTenantResolved) {
+		c.Next()
+	}
+}
+
+func (r *hookRegistry) requestAuthenticated(c *gin.Context) {
+	if c.GetString(auth.AUTH_USER_ID) == "" {
+		c.Next()
+		return
+	}
+	if r.runOrAbort(c, OnRequestAuthenticated) {
+		c.Next()
+	}
+}
+
+func (r *hookRegistry) response(c *gin.Context) {
+	if len(r.get(OnResponse)) == 0 {
+		c.Next()
+		return
+	}
+	w := &hookResponseWriter{ResponseWriter: c.Writer, before: func() {
+		name, err := r.run(c, OnResponse)
+		if err != nil {
+			logger := util.GetLoggerFromCtx(c.Request.Context())
+			logger.Err(err).Str("hook", name).Str("point", string(OnResponse)).Msg("Plugin hook failed")
+		}
+	}}
+	c.Writer = w
+	c.Next()
+	// Nothing was written, gin writes the headers after the chain returns
+	w.runBefore()
+}
+
+// hookResponseWriter runs before once, right before the headers are written
+type hookResponseWriter struct {
+	gin.ResponseWriter
+	before func()
+	done   bool
+}
+
+func (w *hookResponseWriter) runBefore() {
+	if w.done || w.ResponseWriter.Written() {
+		return
+	}
+	w.done = true
+	w.before()
+}
+
+func (w *hookResponseWriter) WriteHeaderNow() {
+	w.runBefore()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *hookResponseWriter) Write(data []byte) (int, error) {
+	w.runBefore()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *hookResponseWriter) WriteString(s string) (int, error) {
+	w.runBefore()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *hookResponseWriter) Flush() {
+	w.runBefore()
+	w.ResponseWriter.Flush()
+}
+
+// Hijacked connections, such as websockets, skip the response hooks
+func (w *hookResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.done = true
+	return w.ResponseWriter.Hijack()
+}
+
+// RegisterHook adds a plugin hook at a point of the request lifecycle.
+// OnTenantResolved and OnRequestAuthenticated run within APIOptions.Middlewares,
+// so for the core routes and the module routes registered with them:
+//
+//  1. Request ID middleware
+//  2. Tenant middleware, then the OnTenantResolved hooks
+//  3. Auth middleware, then the OnRequestAuthenticated hooks
+//
+// OnResponse hooks are installed on the router and run for every route.
+//
+// Hooks run on the request goroutine and must not keep the gin.Context.
+//
+// Panics on clear misuse: nil receiver, unknown point, nil hook function or a
+// ServerConfig that wasn't produced by NewServerConfig.
+func (sc *ServerConfig) RegisterHook(point HookPoint, hook Hook) {
+	if sc == nil {
+		panic("RegisterHook: ServerConfig is nil")
+	}
+	if point != OnTenantResolved && point != OnRequestAuthenticated && point != OnResponse {
+		panic("RegisterHook: unknown hook point " + string(point))
+	}
+	if hook.Fn == nil {
+		panic("RegisterHook: hook function is nil")
+	}
+	if sc.hooks == nil {
+		panic("RegisterHook: ServerConfig.hooks is nil — ServerConfig was not built via NewServerConfig")
+	}
+	sc.hooks.register(point, hook)
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// newHookTestRouter mirrors the server wiring: OnResponse on the router, the
+// other points called in sequence like APIOptions.Middlewares
+func newHookTestRouter(hooks *hookRegistry, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(hooks.response)
+	router.GET("/test", func(c *gin.Context) {
+		for _, middleware := range []gin.HandlerFunc{
+			hooks.tenantResolved,
+			func(c *gin.Context) { c.Set(auth.AUTH_USER_ID, c.Query("user")) },
+			hooks.requestAuthenticated,
+		} {
+			middleware(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+		handler(c)
+	})
+	return router
+}
+
+func TestHooksOrderAndAbort(t *testing.T) {
+	hooks := newHookRegistry()
+	var calls []string
+	record := func(name string) HookFunc {
+		return func(c *gin.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	hooks.register(OnRequestAuthenticated, Hook{Name: "authenticated", Fn: record("authenticated")})
+	hooks.register(OnTenantResolved, Hook{Name: "second", Order: 10, Fn: record("second")})
+	hooks.register(OnTenantResolved, Hook{Name: "first", Order: -1, Fn: record("first")})
+	hooks.register(OnTenantResolved, Hook{Name: "third", Order: 10, Fn: record("third")})
+	router := newHookTestRouter(hooks, func(c *gin.Context) {
+		calls = append(calls, "handler")
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test?user=u1", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, []string{"first", "second", "third", "authenticated", "handler"}, calls)
+
+	// Unauthenticated requests skip OnRequestAuthenticated
+	calls = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	require.Equal(t, []string{"first", "second", "third", "handler"}, calls)
+
+	// A HookError sets the response, later hooks and the handler do not run
+	hooks.register(OnTenantResolved, Hook{Name: "reject", Order: 5, Fn: func(c *gin.Context) error {
+		return NewHookError(http.StatusForbidden, "Tenant not allowed")
+	}})
+	calls = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test?user=u1", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "Tenant not allowed")
+	require.Equal(t, []string{"first"}, calls)
+
+	// Other errors are not exposed
+	failing := newHookRegistry()
+	failing.register(OnRequestAuthenticated, Hook{Name: "failing", Fn: func(c *gin.Context) error {
+		return errors.New("database is down")
+	}})
+	w = httptest.NewRecorder()
+	newHookTestRouter(failing, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test?user=u1", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NotContains(t, w.Body.String(), "database is down")
+}
+
+func TestResponseHooks(t *testing.T) {
+	hooks := newHookRegistry()
+	var statuses []int
+	hooks.register(OnResponse, Hook{Name: "header", Fn: func(c *gin.Context) error {
+		statuses = append(statuses, c.Writer.Status())
+		c.Header("X-Plugin", "on")
+		return nil
+	}})
+	hooks.register(OnResponse, Hook{Name: "failing", Order: 1, Fn: func(c *gin.Context) error {
+		return errors.New("ignored")
+	}})
+	hooks.register(OnTenantResolved, Hook{Name: "reject", Fn: func(c *gin.Context) error {
+		if c.Query("reject") != "" {
+			return NewHookError(http.StatusForbidden, "Rejected")
+		}
+		return nil
+	}})
+
+	for _, tc := range []struct {
+		name    string
+		url     string
+		handler gin.HandlerFunc
+		status  int
+	}{
+		{"body", "/test", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) }, http.StatusCreated},
+		{"no body", "/test", func(c *gin.Context) { c.Status(http.StatusAccepted) }, http.StatusAccepted},
+		{"aborted", "/test?reject=1", func(c *gin.Context) {}, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			statuses = nil
+			w := httptest.NewRecorder()
+			newHookTestRouter(hooks, tc.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, "on", w.Header().Get("X-Plugin"))
+			require.Equal(t, []int{tc.status}, statuses)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"sync"
 
 	"ctoup.com/coreapp/api/handlers"
@@ -15,6 +17,9 @@ import (
 	"ctoup.com/coreapp/pkg/core/db"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	_ "ctoup.com/coreapp/pkg/shared/auth/kratos"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/event"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
 	"ctoup.com/coreapp/pkg/shared/service"
 
 	"ctoup.com/coreapp/pkg/shared/seedservice"
@@ -38,6 +43,28 @@ type ServerConfig struct {
 	TenantMiddleware gin.HandlerFunc
 	AuthMiddleware   *service.AuthMiddleware
 	APIOptions       core.GinServerOptions
+	// PromptConcurrency caps the prompt executions in flight per tenant and
+	// user; modules add PromptConcurrency.Middleware() to those routes
+	PromptConcurrency *service.ConcurrencyLimiter
+	// LLMConsent resolves what may be done with the data of a prompt
+	// execution; modules call ResolveConsent before running a prompt and
+	// apply the result to the execution record
+	LLMConsent *service.LLMConsentService
+	// TenantQuotas limits the resources of the tenants; modules call
+	// ReservePromptExecution before running a prompt and refuse it on
+	// ErrTenantQuotaExceeded
+	TenantQuotas *service.TenantQuotaService
+	// ExecutionWebhooks delivers the results of the prompt executions to the
+	// webhooks of their prompt and schedule; modules call
+	// NotifyExecutionCompleted once an execution finished
+	ExecutionWebhooks *service.ExecutionWebhookService
+	// FeatureFlags evaluates the feature flags of the tenants; its middleware
+	// already stores those of the request tenant, read them with
+	// service.FeatureEnabled or gate routes with service.RequireFeatureFlag
+	FeatureFlags *service.FeatureFlagService
+	// PubSub carries the events between the parts of the application, in
+	// process or across instances depending on PUBSUB_DRIVER and REDIS_URL
+	PubSub event.PubSub
 
 	// authSlot is the indirection through which the auth middleware actually
 	// runs. APIOptions.Middlewares holds the bound method value authSlot.handle,
@@ -45,6 +72,18 @@ type ServerConfig struct {
 	// calls to WrapAuthMiddleware mutate authSlot.inner and are seen by every
 	// registered route (core + module). See WrapAuthMiddleware.
 	authSlot *authMiddlewareSlot
+
+	// hooks holds the plugin hooks run by the middleware chain, see RegisterHook
+	hooks *hookRegistry
+
+	clientAppService *service.ClientApplicationService
+}
+
+// Shutdown flushes the work the core services buffer in memory, such as the
+// API token usage, and closes the pub/sub. Call it once the router no longer
+// serves requests.
+func (sc *ServerConfig) Shutdown(ctx context.Context) error {
+	return errors.Join(sc.clientAppService.Close(ctx), sc.PubSub.Close())
 }
 
 // authMiddlewareSlot is a mutable container for the coreapp auth middleware.
@@ -82,6 +121,14 @@ func setupHealthCheck(router *gin.Engine, defaultChecks ...checks.Check) {
 	healthcheck.New(router, config.DefaultConfig(), allChecks)
 }
 
+// setupReadinessCheck serves /readyz, which unlike /healthz also fails while
+// dependencies needed to serve requests, such as the auth provider, are down
+func setupReadinessCheck(router *gin.Engine, readyChecks ...checks.Check) {
+	readyConfig := config.DefaultConfig()
+	readyConfig.HealthPath = "/readyz"
+	healthcheck.New(router, readyConfig, readyChecks)
+}
+
 func initializeServerConfig(connPool *pgxpool.Pool, cors gin.HandlerFunc, additionalChecks ...checks.Check) *ServerConfig {
 	coreStore := db.NewStore(connPool)
 
@@ -91,6 +138,12 @@ func initializeServerConfig(connPool *pgxpool.Pool, cors gin.HandlerFunc, additi
 	}
 	router := gin.Default()
 	router.Use(cors)
+	// Router level, unlike the API middlewares below, so it wraps the handler
+	hooks := newHookRegistry()
+	router.Use(hooks.response)
+	// Router level as well, it stores the bundle once the handler returned
+	replayCapture := service.NewReplayCaptureService(coreStore, service.ReplayCaptureConfigFromEnv())
+	router.Use(replayCapture.Capture())
 
 	err := connPool.Ping(context.Background())
 	if err != nil {
@@ -112,7 +165,52 @@ func initializeServerConfig(connPool *pgxpool.Pool, cors gin.HandlerFunc, additi
 		log.Fatal().Err(err).Msg("Failed to initialize auth provider")
 	}
 
+	readyChecks := slices.Clone(checks)
+	if reporter, ok := authProvider.(auth.HealthReporter); ok {
+		monitor := reporter.GetHealthMonitor()
+		monitor.Start(context.Background())
+		readyChecks = append(readyChecks, auth.ProviderHealthCheck{Monitor: monitor})
+	}
+	setupReadinessCheck(router, readyChecks...)
+	supportChecks := make([]service.HealthCheck, len(readyChecks))
+	for i, check := range readyChecks {
+		supportChecks[i] = check
+	}
+	service.SetSupportBundleChecks(supportChecks...)
+
+	emailservice.SetAssetResolver(service.TenantEmailAssetResolver(coreStore))
+	emailservice.SetTemplateResolver(service.TenantEmailTemplateResolver(coreStore))
+	emailservice.SetTransportResolver(service.TenantEmailTransportResolver(coreStore))
+	auth.ConfigureAuthorizationFromEnv()
+	auth.SetDelegationSource(service.NewPermissionDelegationService(coreStore))
+	service.RegisterAPITokenAuditEnricher(service.RequestAPITokenAuditEnricher)
+	service.NewAuthFailureService(coreStore).Start(context.Background(), service.AuthFailureConfigFromEnv())
+	service.NewUsageMeteringService(coreStore).Start(context.Background(), service.UsageMeteringConfigFromEnv())
+	service.NewLockoutService(coreStore, authProvider, service.LockoutConfigFromEnv()).Start(context.Background())
+	if err := service.InitEmailEncryption(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Invalid email encryption settings")
+	}
+	if err := service.InitFileEncryption(context.Background(), coreStore); err != nil {
+		log.Fatal().Err(err).Msg("Invalid file encryption settings")
+	}
+	service.InitTenantStorageQuota(coreStore)
+
 	clientAppService := service.NewClientApplicationService(coreStore)
+	tokenExpiryConfig := service.TokenExpiryNotifierConfigFromEnv()
+	clientAppService.StartTokenExpiryNotifier(context.Background(), tokenExpiryConfig)
+	clientAppService.StartExpiredTokenWebhooks(context.Background(), tokenExpiryConfig.Interval)
+	clientAppService.StartScopeTemplatePropagation(context.Background(), service.ScopeTemplatePropagationIntervalFromEnv())
+	service.NewMembershipConsistencyService(coreStore).StartMembershipConsistencyJob(context.Background(), service.MembershipConsistencyConfigFromEnv())
+	service.NewTenantExportService(coreStore, fileservice.NewFileService(), service.TenantExportConfigFromEnv()).StartTenantExportScheduler(context.Background())
+	service.NewFileEncryptionService(coreStore, fileservice.NewFileService(), service.FileEncryptionConfigFromEnv()).StartRewrites(context.Background())
+	service.NewJobService(coreStore).StartJobCleanup(context.Background(), service.JobConfigFromEnv())
+	service.NewIndexAdvisorService(coreStore).StartIndexAdvisorJob(context.Background(), service.IndexAdvisorConfigFromEnv())
+	service.NewSLAService(coreStore, service.SLAConfigFromEnv()).StartSLAEscalations(context.Background())
+	service.NewUsageRecommendationService(coreStore, service.UsageRecommendationConfigFromEnv()).StartNotifications(context.Background())
+	replayCapture.StartReplayBundleCleanup(context.Background())
+	service.NewDirectorySyncService(coreStore, authProvider, service.NewSharedUserService(coreStore, authProvider), service.DirectorySyncConfigFromEnv()).StartDirectorySync(context.Background())
+	service.NewAccountDeletionService(coreStore, authProvider, service.AccountDeletionConfigFromEnv()).StartAccountDeletions(context.Background())
+	service.NewTenantPurgeService(coreStore, fileservice.NewFileService(), authProvider, multiTenantService, service.TenantPurgeConfigFromEnv()).StartTenantPurges(context.Background())
 
 	// Create the combined auth middleware with the generic auth provider
 	authMiddleware := service.NewAuthMiddleware(
@@ -124,21 +222,34 @@ func initializeServerConfig(connPool *pgxpool.Pool, cors gin.HandlerFunc, additi
 	var middlewares []core.MiddlewareFunc
 
 	tenantMiddleware := service.NewTenantMiddleware(nil, multiTenantService)
+	featureFlags := service.NewFeatureFlagService(coreStore)
 
 	// Auth dispatches through authSlot so WrapAuthMiddleware can layer
 	// behavior on top without depending on slice position.
 	//
+	// Plugin hooks (see RegisterHook) run between the steps.
+	//
 	// 1. Request ID middleware
-	// 2. Tenant middleware (extract tenant ID)
-	// 3. Auth middleware (verify token, via authSlot)
-	// 4. Logger enrichment (stamp tenant_id/user_id onto the request logger)
+	// 2. Tenant middleware (extract tenant ID), then OnTenantResolved hooks
+	//    and the feature flags of the tenant
+	// 3. Replay recording, for the tenants that opted in to the capture
+	// 4. Auth middleware (verify token, via authSlot), then OnRequestAuthenticated hooks
+	// 5. Logger enrichment (stamp tenant_id/user_id onto the request logger)
+	// 6. User guards, refusing to disable, demote or delete oneself or the
+	//    last CUSTOMER_ADMIN of a tenant
 	authSlot := &authMiddlewareSlot{inner: authMiddleware.MiddlewareFunc()}
 
 	middlewares = []core.MiddlewareFunc{
 		core.MiddlewareFunc(service.RequestIDMiddleware()),
 		core.MiddlewareFunc(tenantMiddleware.MiddlewareFunc()),
+		core.MiddlewareFunc(hooks.tenantResolved),
+		core.MiddlewareFunc(featureFlags.Middleware()),
+		core.MiddlewareFunc(replayCapture.Record()),
 		core.MiddlewareFunc(authSlot.handle),
+		core.MiddlewareFunc(hooks.requestAuthenticated),
+		core.MiddlewareFunc(service.UsageMeteringMiddleware()),
 		core.MiddlewareFunc(service.LoggerEnrichmentMiddleware()),
+		core.MiddlewareFunc(service.NewUserGuardPolicy(coreStore).Middleware()),
 	}
 
 	apiOptions := core.GinServerOptions{
@@ -151,15 +262,24 @@ func initializeServerConfig(connPool *pgxpool.Pool, cors gin.HandlerFunc, additi
 	seedService.Seed()
 
 	handlers := handlers.CreateCoreHandlers(connPool, authProvider, multiTenantService, clientAppService)
+	service.NewTenantIsolationProbeService(coreStore, handlers, service.TenantIsolationProbeConfigFromEnv()).StartTenantIsolationProbes(context.Background())
 
 	core.RegisterHandlersWithOptions(router, handlers, apiOptions)
 
 	return &ServerConfig{
-		Router:           router,
-		AuthProvider:     authProvider,
-		TenantMiddleware: tenantMiddleware.MiddlewareFunc(),
-		AuthMiddleware:   authMiddleware,
-		APIOptions:       apiOptions,
-		authSlot:         authSlot,
+		Router:            router,
+		AuthProvider:      authProvider,
+		TenantMiddleware:  tenantMiddleware.MiddlewareFunc(),
+		AuthMiddleware:    authMiddleware,
+		APIOptions:        apiOptions,
+		PromptConcurrency: service.NewConcurrencyLimiter(service.ConcurrencyLimiterConfigFromEnv()),
+		LLMConsent:        service.NewLLMConsentService(coreStore),
+		TenantQuotas:      service.NewTenantQuotaService(coreStore, fileservice.NewFileService()),
+		ExecutionWebhooks: service.NewExecutionWebhookService(coreStore),
+		FeatureFlags:      featureFlags,
+		PubSub:            event.NewPubSubFromEnv(connPool, service.SharedRedisClient()),
+		authSlot:          authSlot,
+		hooks:             hooks,
+		clientAppService:  clientAppService,
 	}
 }
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tavsec/gin-healthcheck/controllers"
+)
+
+type providerHealth struct {
+	err error
+}
+
+func (p *providerHealth) CheckHealth(context.Context) error {
+	return p.err
+}
+
+func TestReadinessCheckFollowsAuthProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := &providerHealth{}
+	monitor := auth.NewProviderHealthMonitor("fake", provider, time.Minute, 0)
+	router := gin.New()
+	setupReadinessCheck(router, auth.ProviderHealthCheck{Monitor: monitor})
+
+	ready := func() (int, []controllers.CheckStatus) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var statuses []controllers.CheckStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+		return w.Code, statuses
+	}
+
+	monitor.Probe(context.Background())
+	code, statuses := ready()
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []controllers.CheckStatus{{Name: "auth-provider-fake", Pass: true}}, statuses)
+
+	provider.err = errors.New("connection refused")
+	monitor.Probe(context.Background())
+	code, statuses = ready()
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, []controllers.CheckStatus{{Name: "auth-provider-fake", Pass: false}}, statuses)
+
+	provider.err = nil
+	monitor.Probe(context.Background())
+	code, _ = ready()
+	require.Equal(t, http.StatusOK, code)
+}
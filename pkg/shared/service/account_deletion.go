@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultAccountDeletionGracePeriod = 14 * 24 * time.Hour
+	DefaultAccountDeletionReauthAge   = 5 * time.Minute
+	DefaultAccountDeletionInterval    = 15 * time.Minute
+
+	accountDeletionTokenPrefix = "acd_"
+	// Due deletions erased per run, the next run takes the rest
+	accountDeletionBatchSize = 50
+)
+
+var (
+	// ErrReauthenticationRequired is returned when the session of the request
+	// did not authenticate recently enough for the action
+	ErrReauthenticationRequired = errors.New("sign in again to confirm this action")
+	ErrAccountDeletionScheduled = errors.New("the deletion of the account is already scheduled")
+	ErrAccountDeletionNotFound  = errors.New("no scheduled account deletion, the link is invalid or the deletion already started")
+	// ErrAccountDeletionGlobalRole keeps the platform administrators from
+	// deleting their own account, another one has to remove their role first
+	ErrAccountDeletionGlobalRole = errors.New("accounts holding a global role cannot be deleted by their user")
+)
+
+// AccountDeletionConfig configures the self-service account deletions.
+//
+// Environment:
+//   - ACCOUNT_DELETION_GRACE_PERIOD: delay before the account is erased, during
+//     which the emailed link cancels the deletion (default 336h)
+//   - ACCOUNT_DELETION_REAUTH_MAX_AGE: how recently the session must have
+//     authenticated to request the deletion (default 5m)
+//   - ACCOUNT_DELETION_INTERVAL: scan interval of the job erasing the due
+//     accounts (default 15m, 0 disables the job)
+type AccountDeletionConfig struct {
+	GracePeriod  time.Duration
+	ReauthMaxAge time.Duration
+	Interval     time.Duration
+}
+
+func AccountDeletionConfigFromEnv() AccountDeletionConfig {
+	cfg := AccountDeletionConfig{
+		GracePeriod:  DefaultAccountDeletionGracePeriod,
+		ReauthMaxAge: DefaultAccountDeletionReauthAge,
+		Interval:     DefaultAccountDeletionInterval,
+	}
+	if v := os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.GracePeriod = d
+		} else {
+			log.Warn().Str("ACCOUNT_DELETION_GRACE_PERIOD", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("ACCOUNT_DELETION_REAUTH_MAX_AGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.ReauthMaxAge = d
+		} else {
+			log.Warn().Str("ACCOUNT_DELETION_REAUTH_MAX_AGE", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("ACCOUNT_DELETION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("ACCOUNT_DELETION_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// AccountDeletionService lets users delete their own account. The deletion
+// is scheduled after a grace period, then runs the erasure of the admin
+// deletion, the resources of the user going to the owners of their tenants.
+type AccountDeletionService struct {
+	store        *db.Store
+	authProvider auth.AuthProvider
+	erasure      *UserErasureService
+	cfg          AccountDeletionConfig
+}
+
+func NewAccountDeletionService(store *db.Store, authProvider auth.AuthProvider, cfg AccountDeletionConfig) *AccountDeletionService {
+	return &AccountDeletionService{
+		store:        store,
+		authProvider: authProvider,
+		erasure:      NewUserErasureService(store),
+		cfg:          cfg,
+	}
+}
+
+// accountDeletionTransfer is the transfer of the self-service deletions, the
+// user cannot name someone to inherit their resources
+var accountDeletionTransfer = OwnershipTransfer{ToTenantOwner: true}
+
+// CheckRecentAuthentication returns ErrReauthenticationRequired unless the
+// session of the request authenticated within the configured age
+func (s *AccountDeletionService) CheckRecentAuthentication(ctx context.Context, authClient auth.AuthClient, userID, sessionID string) error {
+	if sessionID == "" {
+		return ErrReauthenticationRequired
+	}
+	sessions, err := authClient.ListUserSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service.CheckRecentAuthentication: %w", err)
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return checkAuthenticatedAt(session.AuthenticatedAt, s.cfg.ReauthMaxAge, time.Now())
+		}
+	}
+	return ErrReauthenticationRequired
+}
+
+func checkAuthenticatedAt(authenticatedAt *time.Time, maxAge time.Duration, now time.Time) error {
+	if authenticatedAt == nil || now.Sub(*authenticatedAt) > maxAge {
+		return ErrReauthenticationRequired
+	}
+	return nil
+}
+
+// ScheduleAccountDeletion plans the erasure of the user after the grace
+// period and returns the token of the link cancelling it. The checks the
+// erasure will make are made now, so a deletion that would fail is refused.
+func (s *AccountDeletionService) ScheduleAccountDeletion(ctx context.Context, tenantID, userID string) (string, repository.CoreUserDeletionRequest, error) {
+	user, err := s.store.GetSharedUserByID(ctx, userID)
+	if err != nil {
+		return "", repository.CoreUserDeletionRequest{}, fmt.Errorf("service.ScheduleAccountDeletion: %w", err)
+	}
+	if len(user.Roles) > 0 {
+		return "", repository.CoreUserDeletionRequest{}, ErrAccountDeletionGlobalRole
+	}
+
+	token, hash, err := newAccountDeletionToken()
+	if err != nil {
+		return "", repository.CoreUserDeletionRequest{}, fmt.Errorf("service.ScheduleAccountDeletion: %w", err)
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return "", repository.CoreUserDeletionRequest{}, fmt.Errorf("service.ScheduleAccountDeletion: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	if err := checkOwnershipTransfer(ctx, tx, "", userID, accountDeletionTransfer); err != nil {
+		return "", repository.CoreUserDeletionRequest{}, err
+	}
+	request, err := qtx.CreateUserDeletionRequest(ctx, repository.CreateUserDeletionRequestParams{
+		TenantID:     tenantID,
+		UserID:       userID,
+		TokenHash:    hash,
+		ScheduledFor: time.Now().Add(s.cfg.GracePeriod),
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return "", repository.CoreUserDeletionRequest{}, ErrAccountDeletionScheduled
+	}
+	if err != nil {
+		return "", repository.CoreUserDeletionRequest{}, fmt.Errorf("service.ScheduleAccountDeletion: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", repository.CoreUserDeletionRequest{}, fmt.Errorf("service.ScheduleAccountDeletion: %w", err)
+	}
+
+	s.record(ctx, request, "account_deletion_scheduled", userID)
+	return token, request, nil
+}
+
+// CancelAccountDeletion uses the token of the emailed link once, until the
+// deletion starts
+func (s *AccountDeletionService) CancelAccountDeletion(ctx context.Context, token string) (repository.CoreUserDeletionRequest, error) {
+	if !strings.HasPrefix(token, accountDeletionTokenPrefix) {
+		return repository.CoreUserDeletionRequest{}, ErrAccountDeletionNotFound
+	}
+	request, err := s.store.CancelUserDeletionRequest(ctx, accountDeletionTokenHash(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserDeletionRequest{}, ErrAccountDeletionNotFound
+	}
+	if err != nil {
+		return repository.CoreUserDeletionRequest{}, fmt.Errorf("service.CancelAccountDeletion: %w", err)
+	}
+	// The link stands for the user, whoever follows it
+	s.record(ctx, request, "account_deletion_cancelled", request.UserID)
+	return request, nil
+}
+
+// StartAccountDeletions runs EraseDueAccounts now and then every interval
+// until ctx is done. It does nothing when the interval is 0.
+func (s *AccountDeletionService) StartAccountDeletions(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Info().Msg("Account deletions disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.EraseDueAccounts(ctx); err != nil {
+				log.Err(err).Msg("Account deletion scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// EraseDueAccounts erases the users whose grace period is over and returns
+// how many were erased. A deletion is claimed before it runs, so a single
+// instance erases a user; a failed one is kept as failed with its error and
+// is not retried.
+func (s *AccountDeletionService) EraseDueAccounts(ctx context.Context) (int, error) {
+	requests, err := s.store.ListDueUserDeletionRequests(ctx, accountDeletionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("service.EraseDueAccounts: %w", err)
+	}
+	erased := 0
+	for _, request := range requests {
+		request, err := s.store.ClaimUserDeletionRequest(ctx, request.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Cancelled or claimed by another instance meanwhile
+			continue
+		}
+		if err != nil {
+			return erased, fmt.Errorf("service.EraseDueAccounts: %w", err)
+		}
+		if err := s.eraseAccount(ctx, request); err != nil {
+			log.Err(err).Str("deletion_request_id", request.ID.String()).Msg("Failed to erase account")
+			if err := s.store.FailUserDeletionRequest(ctx, repository.FailUserDeletionRequestParams{
+				LastError: err.Error(),
+				ID:        request.ID,
+			}); err != nil {
+				log.Err(err).Str("deletion_request_id", request.ID.String()).Msg("Failed to record account deletion failure")
+			}
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+// eraseAccount erases the user with the auth client of the tenant the
+// deletion was requested from. The erasure deletes the request.
+func (s *AccountDeletionService) eraseAccount(ctx context.Context, request repository.CoreUserDeletionRequest) error {
+	var authClient auth.AuthClient
+	var err error
+	if request.TenantID != "" {
+		authClient, err = s.authProvider.GetAuthClientForTenant(ctx, request.TenantID)
+		if err != nil {
+			return err
+		}
+	} else {
+		authClient = s.authProvider.GetAuthClient()
+	}
+	// The user is the actor, the erasure replaces them with the tombstone
+	if _, err := s.erasure.EraseUser(ctx, authClient, request.UserID, request.UserID, accountDeletionTransfer); err != nil {
+		return err
+	}
+	// A provider outage must not let the erased user back in
+	if reporter, ok := s.authProvider.(auth.HealthReporter); ok {
+		reporter.GetHealthMonitor().ForgetUser(request.UserID)
+	}
+	return nil
+}
+
+func (s *AccountDeletionService) record(ctx context.Context, request repository.CoreUserDeletionRequest, eventType, actorID string) {
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  request.TenantID,
+		UserID:    request.UserID,
+		ActorID:   actorID,
+		Category:  ActivityCategoryAccount,
+		EventType: eventType,
+		Data: map[string]interface{}{
+			"deletion_request_id": request.ID.String(),
+			"scheduled_for":       request.ScheduledFor,
+		},
+	}); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", request.UserID).Str("event_type", eventType).Msg("Failed to record account deletion on the timeline")
+	}
+}
+
+func newAccountDeletionToken() (string, []byte, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, err
+	}
+	token := accountDeletionTokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+	return token, accountDeletionTokenHash(token), nil
+}
+
+func accountDeletionTokenHash(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
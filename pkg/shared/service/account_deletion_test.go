@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountDeletionConfigFromEnv(t *testing.T) {
+	cfg := AccountDeletionConfigFromEnv()
+	require.Equal(t, DefaultAccountDeletionGracePeriod, cfg.GracePeriod)
+	require.Equal(t, DefaultAccountDeletionReauthAge, cfg.ReauthMaxAge)
+	require.Equal(t, DefaultAccountDeletionInterval, cfg.Interval)
+
+	t.Setenv("ACCOUNT_DELETION_GRACE_PERIOD", "72h")
+	t.Setenv("ACCOUNT_DELETION_REAUTH_MAX_AGE", "0")
+	t.Setenv("ACCOUNT_DELETION_INTERVAL", "0")
+	cfg = AccountDeletionConfigFromEnv()
+	require.Equal(t, 72*time.Hour, cfg.GracePeriod)
+	// A session can never be recent enough with 0, the default is kept
+	require.Equal(t, DefaultAccountDeletionReauthAge, cfg.ReauthMaxAge)
+	require.Zero(t, cfg.Interval)
+}
+
+func TestCheckAuthenticatedAt(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Minute)
+	old := now.Add(-time.Hour)
+
+	require.NoError(t, checkAuthenticatedAt(&recent, 5*time.Minute, now))
+	require.ErrorIs(t, checkAuthenticatedAt(&old, 5*time.Minute, now), ErrReauthenticationRequired)
+	require.ErrorIs(t, checkAuthenticatedAt(nil, 5*time.Minute, now), ErrReauthenticationRequired)
+}
+
+func TestCancelAccountDeletionRejectsForeignTokens(t *testing.T) {
+	token, hash, err := newAccountDeletionToken()
+	require.NoError(t, err)
+	require.Equal(t, hash, accountDeletionTokenHash(token))
+
+	// Tokens of other links are refused before any lookup
+	s := &AccountDeletionService{}
+	_, err = s.CancelAccountDeletion(context.Background(), "emc_"+token[len(accountDeletionTokenPrefix):])
+	require.ErrorIs(t, err, ErrAccountDeletionNotFound)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Announcement severities, most severe last
+const (
+	AnnouncementSeverityInfo     = "info"
+	AnnouncementSeverityWarning  = "warning"
+	AnnouncementSeverityCritical = "critical"
+
+	maxAnnouncementTitleLength = 200
+)
+
+var announcementSeverities = []string{
+	AnnouncementSeverityInfo,
+	AnnouncementSeverityWarning,
+	AnnouncementSeverityCritical,
+}
+
+// ErrInvalidAnnouncement is wrapped by every validation error of an announcement
+var ErrInvalidAnnouncement = errors.New("invalid announcement")
+
+// activeAnnouncementsCache keeps the banners shown with the public tenant,
+// keyed by tenant id ("" for the global ones only)
+var activeAnnouncementsCache = NewPublicCache[[]repository.CoreAnnouncement](DefaultPublicCacheTTL)
+
+// invalidateActiveAnnouncements drops the cached banners affected by a change
+// of the scope. Global banners show on every tenant.
+func invalidateActiveAnnouncements(tenantID string) {
+	if tenantID == "" {
+		activeAnnouncementsCache.InvalidateAll()
+		return
+	}
+	activeAnnouncementsCache.Invalidate(tenantID)
+}
+
+// AnnouncementInput is the editable content of an announcement. A zero
+// StartsAt publishes it immediately.
+type AnnouncementInput struct {
+	Title    string
+	Message  string
+	Severity string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// AnnouncementService manages the announcement banners. Announcements are
+// scoped like client applications: a tenant ID for tenant banners, "" for the
+// global ones published by SUPER_ADMINs and shown on every tenant. They are
+// hidden before StartsAt and expire at EndsAt without any cleanup job.
+type AnnouncementService struct {
+	store *db.Store
+}
+
+func NewAnnouncementService(store *db.Store) *AnnouncementService {
+	return &AnnouncementService{store: store}
+}
+
+// validate checks the input and defaults StartsAt to now
+func (input *AnnouncementInput) validate(now time.Time) error {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Message = strings.TrimSpace(input.Message)
+	if input.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidAnnouncement)
+	}
+	if len([]rune(input.Title)) > maxAnnouncementTitleLength {
+		return fmt.Errorf("%w: title must be at most %d characters", ErrInvalidAnnouncement, maxAnnouncementTitleLength)
+	}
+	if input.Message == "" {
+		return fmt.Errorf("%w: message is required", ErrInvalidAnnouncement)
+	}
+	if !slices.Contains(announcementSeverities, input.Severity) {
+		return fmt.Errorf("%w: severity must be one of %s", ErrInvalidAnnouncement, strings.Join(announcementSeverities, ", "))
+	}
+	if input.StartsAt.IsZero() {
+		input.StartsAt = now
+	}
+	if !input.EndsAt.After(input.StartsAt) {
+		return fmt.Errorf("%w: endsAt must be after startsAt", ErrInvalidAnnouncement)
+	}
+	if !input.EndsAt.After(now) {
+		return fmt.Errorf("%w: endsAt must be in the future", ErrInvalidAnnouncement)
+	}
+	return nil
+}
+
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, tenantID string, input AnnouncementInput, createdBy string) (repository.CoreAnnouncement, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if err := input.validate(time.Now()); err != nil {
+		return repository.CoreAnnouncement{}, err
+	}
+	announcement, err := s.store.CreateAnnouncement(ctx, repository.CreateAnnouncementParams{
+		TenantID:  tenantID,
+		Title:     input.Title,
+		Message:   input.Message,
+		Severity:  input.Severity,
+		StartsAt:  input.StartsAt,
+		EndsAt:    input.EndsAt,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to create announcement")
+		return repository.CoreAnnouncement{}, fmt.Errorf("service.CreateAnnouncement: %w", err)
+	}
+	invalidateActiveAnnouncements(tenantID)
+	return announcement, nil
+}
+
+func (s *AnnouncementService) GetAnnouncement(ctx context.Context, id uuid.UUID, tenantID string) (repository.CoreAnnouncement, error) {
+	return s.store.GetAnnouncementByID(ctx, repository.GetAnnouncementByIDParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+}
+
+// ListAnnouncements lists the announcements of one scope for management,
+// scheduled ones included
+func (s *AnnouncementService) ListAnnouncements(ctx context.Context, tenantID string, includeExpired bool, limit, offset int32) ([]repository.CoreAnnouncement, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	announcements, err := s.store.ListAnnouncements(ctx, repository.ListAnnouncementsParams{
+		TenantID:       tenantID,
+		Limit:          limit,
+		Offset:         offset,
+		IncludeExpired: includeExpired,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to list announcements")
+		return nil, fmt.Errorf("service.ListAnnouncements: %w", err)
+	}
+	return announcements, nil
+}
+
+// ListActiveAnnouncements returns the banners to show on a tenant right now,
+// global ones included. An empty tenantID returns the global ones only. The
+// result is cached, the changes made through this service show right away.
+func (s *AnnouncementService) ListActiveAnnouncements(ctx context.Context, tenantID string) ([]repository.CoreAnnouncement, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	announcements, err := activeAnnouncementsCache.GetWithTTL(ctx, tenantID, func(ctx context.Context) ([]repository.CoreAnnouncement, time.Duration, error) {
+		announcements, err := s.store.ListActiveAnnouncements(ctx, tenantID)
+		if err != nil {
+			return nil, 0, err
+		}
+		// An expired banner must not outlive its end in the cache
+		ttl := DefaultPublicCacheTTL
+		for _, announcement := range announcements {
+			ttl = min(ttl, time.Until(announcement.EndsAt))
+		}
+		return announcements, ttl, nil
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to list active announcements")
+		return nil, fmt.Errorf("service.ListActiveAnnouncements: %w", err)
+	}
+	return announcements, nil
+}
+
+func (s *AnnouncementService) UpdateAnnouncement(ctx context.Context, id uuid.UUID, tenantID string, input AnnouncementInput) (repository.CoreAnnouncement, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if err := input.validate(time.Now()); err != nil {
+		return repository.CoreAnnouncement{}, err
+	}
+	announcement, err := s.store.UpdateAnnouncement(ctx, repository.UpdateAnnouncementParams{
+		ID:       id,
+		TenantID: tenantID,
+		Title:    input.Title,
+		Message:  input.Message,
+		Severity: input.Severity,
+		StartsAt: input.StartsAt,
+		EndsAt:   input.EndsAt,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.CoreAnnouncement{}, err
+		}
+		logger.Err(err).Str("announcementID", id.String()).Msg("Failed to update announcement")
+		return repository.CoreAnnouncement{}, fmt.Errorf("service.UpdateAnnouncement: %w", err)
+	}
+	invalidateActiveAnnouncements(tenantID)
+	return announcement, nil
+}
+
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, id uuid.UUID, tenantID string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	deleted, err := s.store.DeleteAnnouncement(ctx, repository.DeleteAnnouncementParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		logger.Err(err).Str("announcementID", id.String()).Msg("Failed to delete announcement")
+		return fmt.Errorf("service.DeleteAnnouncement: %w", err)
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	invalidateActiveAnnouncements(tenantID)
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func containsAnnouncement(announcements []repository.CoreAnnouncement, target repository.CoreAnnouncement) bool {
+	for _, announcement := range announcements {
+		if announcement.ID == target.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnnouncementScheduling(t *testing.T) {
+	service := NewAnnouncementService(testutils.NewTestStore(t))
+	ctx := context.Background()
+	tenantID := commontestutils.RandomString(10)
+	createdBy := commontestutils.RandomString(10)
+
+	current, err := service.CreateAnnouncement(ctx, tenantID, AnnouncementInput{
+		Title:    "Maintenance tonight",
+		Message:  commontestutils.RandomString(20),
+		Severity: AnnouncementSeverityWarning,
+		EndsAt:   time.Now().Add(time.Hour),
+	}, createdBy)
+	require.NoError(t, err)
+	require.False(t, current.StartsAt.IsZero())
+
+	scheduled, err := service.CreateAnnouncement(ctx, tenantID, AnnouncementInput{
+		Title:    "New feature",
+		Message:  commontestutils.RandomString(20),
+		Severity: AnnouncementSeverityInfo,
+		StartsAt: time.Now().Add(time.Hour),
+		EndsAt:   time.Now().Add(2 * time.Hour),
+	}, createdBy)
+	require.NoError(t, err)
+
+	global, err := service.CreateAnnouncement(ctx, "", AnnouncementInput{
+		Title:    "Platform incident",
+		Message:  commontestutils.RandomString(20),
+		Severity: AnnouncementSeverityCritical,
+		EndsAt:   time.Now().Add(time.Hour),
+	}, createdBy)
+	require.NoError(t, err)
+	defer service.DeleteAnnouncement(ctx, global.ID, "")
+
+	t.Run("active announcements include global ones but not scheduled ones", func(t *testing.T) {
+		active, err := service.ListActiveAnnouncements(ctx, tenantID)
+		require.NoError(t, err)
+		require.True(t, containsAnnouncement(active, current))
+		require.True(t, containsAnnouncement(active, global))
+		require.False(t, containsAnnouncement(active, scheduled))
+	})
+
+	t.Run("management list includes scheduled announcements", func(t *testing.T) {
+		announcements, err := service.ListAnnouncements(ctx, tenantID, false, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, announcements, 2)
+	})
+
+	t.Run("other tenants do not see the announcement", func(t *testing.T) {
+		active, err := service.ListActiveAnnouncements(ctx, commontestutils.RandomString(10))
+		require.NoError(t, err)
+		require.False(t, containsAnnouncement(active, current))
+
+		_, err = service.GetAnnouncement(ctx, current.ID, commontestutils.RandomString(10))
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, service.DeleteAnnouncement(ctx, scheduled.ID, tenantID))
+		require.ErrorIs(t, service.DeleteAnnouncement(ctx, scheduled.ID, tenantID), pgx.ErrNoRows)
+	})
+}
+
+func TestAnnouncementValidation(t *testing.T) {
+	service := NewAnnouncementService(testutils.NewTestStore(t))
+	ctx := context.Background()
+	tenantID := commontestutils.RandomString(10)
+
+	valid := AnnouncementInput{
+		Title:    "Maintenance",
+		Message:  "The service will be down for 10 minutes",
+		Severity: AnnouncementSeverityInfo,
+		EndsAt:   time.Now().Add(time.Hour),
+	}
+
+	t.Run("unknown severity", func(t *testing.T) {
+		input := valid
+		input.Severity = "urgent"
+		_, err := service.CreateAnnouncement(ctx, tenantID, input, "creator")
+		require.ErrorIs(t, err, ErrInvalidAnnouncement)
+	})
+
+	t.Run("already expired", func(t *testing.T) {
+		input := valid
+		input.StartsAt = time.Now().Add(-2 * time.Hour)
+		input.EndsAt = time.Now().Add(-time.Hour)
+		_, err := service.CreateAnnouncement(ctx, tenantID, input, "creator")
+		require.ErrorIs(t, err, ErrInvalidAnnouncement)
+	})
+
+	t.Run("ends before it starts", func(t *testing.T) {
+		input := valid
+		input.StartsAt = time.Now().Add(2 * time.Hour)
+		_, err := service.CreateAnnouncement(ctx, tenantID, input, "creator")
+		require.ErrorIs(t, err, ErrInvalidAnnouncement)
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// APITokenAuditEntry identifies the audit entry an enricher is called for
+type APITokenAuditEntry struct {
+	TokenID uuid.UUID
+	Action  string
+}
+
+// APITokenAuditEnricher returns fields to add to the additional data of an
+// API token audit entry, such as a request ID, a route or a geolocation. ctx
+// is the *gin.Context of the request when the entry is written for one, and a
+// plain context in background jobs. The fields written by the core win over
+// the ones of an enricher.
+type APITokenAuditEnricher func(ctx context.Context, entry APITokenAuditEntry) map[string]interface{}
+
+var (
+	auditEnrichersMu sync.RWMutex
+	auditEnrichers   []APITokenAuditEnricher
+)
+
+// RegisterAPITokenAuditEnricher adds an enricher, called for every audit
+// entry in the order of registration
+func RegisterAPITokenAuditEnricher(enricher APITokenAuditEnricher) {
+	auditEnrichersMu.Lock()
+	defer auditEnrichersMu.Unlock()
+	auditEnrichers = append(auditEnrichers, enricher)
+}
+
+// RequestAPITokenAuditEnricher adds the request ID and the route template of
+// the request the entry is written for
+func RequestAPITokenAuditEnricher(ctx context.Context, _ APITokenAuditEntry) map[string]interface{} {
+	fields := map[string]interface{}{}
+	requestID, _ := ctx.Value(util.RequestIDKey).(string)
+	if c, ok := ctx.(*gin.Context); ok {
+		if id := c.GetString(string(util.RequestIDKey)); id != "" {
+			requestID = id
+		}
+		if route := c.FullPath(); route != "" {
+			fields["route"] = route
+		}
+	}
+	if requestID != "" {
+		fields["request_id"] = requestID
+	}
+	return fields
+}
+
+// enrichAPITokenAuditData merges the fields of the registered enrichers into
+// data, the JSON object of the entry or nil. An enricher that panics is
+// skipped: enrichment never fails an audit write.
+func enrichAPITokenAuditData(ctx context.Context, entry APITokenAuditEntry, data []byte) []byte {
+	auditEnrichersMu.RLock()
+	enrichers := slices.Clone(auditEnrichers)
+	auditEnrichersMu.RUnlock()
+	if len(enrichers) == 0 {
+		return data
+	}
+
+	var fields map[string]interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return data
+		}
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	added := false
+	for _, enricher := range enrichers {
+		for key, value := range callAuditEnricher(ctx, enricher, entry) {
+			if _, exists := fields[key]; !exists {
+				fields[key] = value
+				added = true
+			}
+		}
+	}
+	if !added {
+		return data
+	}
+	enriched, err := json.Marshal(fields)
+	if err != nil {
+		log.Warn().Err(err).Str("tokenID", entry.TokenID.String()).Msg("Failed to encode enriched audit data")
+		return data
+	}
+	return enriched
+}
+
+func callAuditEnricher(ctx context.Context, enricher APITokenAuditEnricher, entry APITokenAuditEntry) (fields map[string]interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("action", entry.Action).Msg("API token audit enricher panicked")
+			fields = nil
+		}
+	}()
+	return enricher(ctx, entry)
+}
+
+// ErrInvalidAuditFilter is returned for an unknown action or an empty range
+var ErrInvalidAuditFilter = errors.New("unknown audit action, or the range ends before it starts")
+
+var tokenAuditActions = []string{
+	TokenAuditCreated, TokenAuditUsed, TokenAuditRevoked, TokenAuditUpdated,
+	TokenAuditThrottled, TokenAuditDeniedIP, TokenAuditExpiryWarning, TokenAuditExpired,
+}
+
+// APITokenAuditFilter narrows the audit entries of a token. A zero field does
+// not filter.
+type APITokenAuditFilter struct {
+	Action string
+	// From is inclusive, To exclusive
+	From time.Time
+	To   time.Time
+}
+
+func (f APITokenAuditFilter) validate() error {
+	if f.Action != "" && !slices.Contains(tokenAuditActions, f.Action) {
+		return ErrInvalidAuditFilter
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.To.After(f.From) {
+		return ErrInvalidAuditFilter
+	}
+	return nil
+}
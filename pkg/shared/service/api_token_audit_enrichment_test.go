@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func withAuditEnrichers(t *testing.T, enrichers ...APITokenAuditEnricher) {
+	auditEnrichersMu.Lock()
+	previous := auditEnrichers
+	auditEnrichers = nil
+	auditEnrichersMu.Unlock()
+	t.Cleanup(func() {
+		auditEnrichersMu.Lock()
+		auditEnrichers = previous
+		auditEnrichersMu.Unlock()
+	})
+	for _, enricher := range enrichers {
+		RegisterAPITokenAuditEnricher(enricher)
+	}
+}
+
+func TestEnrichAPITokenAuditData(t *testing.T) {
+	entry := APITokenAuditEntry{TokenID: uuid.New(), Action: TokenAuditUpdated}
+
+	t.Run("no enricher keeps the data", func(t *testing.T) {
+		withAuditEnrichers(t)
+		require.Nil(t, enrichAPITokenAuditData(context.Background(), entry, nil))
+	})
+
+	t.Run("enricher fields are merged, core fields win", func(t *testing.T) {
+		withAuditEnrichers(t,
+			func(_ context.Context, got APITokenAuditEntry) map[string]interface{} {
+				require.Equal(t, entry, got)
+				return map[string]interface{}{"geo": "FR", "updated_by": "enricher"}
+			},
+			func(context.Context, APITokenAuditEntry) map[string]interface{} {
+				panic("broken enricher")
+			},
+		)
+		data := enrichAPITokenAuditData(context.Background(), entry, []byte(`{"updated_by":"admin"}`))
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		require.Equal(t, map[string]interface{}{"geo": "FR", "updated_by": "admin"}, fields)
+	})
+
+	t.Run("request enricher adds the request ID and route", func(t *testing.T) {
+		withAuditEnrichers(t, RequestAPITokenAuditEnricher)
+		router := gin.New()
+		var data []byte
+		router.POST("/api/v1/client-applications/:id/tokens", func(c *gin.Context) {
+			c.Set(string(util.RequestIDKey), "req-42")
+			data = enrichAPITokenAuditData(c, entry, nil)
+		})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/client-applications/1/tokens", nil))
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		require.Equal(t, "req-42", fields["request_id"])
+		require.Equal(t, "/api/v1/client-applications/:id/tokens", fields["route"])
+	})
+}
+
+func TestAPITokenAuditFilterValidate(t *testing.T) {
+	now := time.Now()
+	require.NoError(t, APITokenAuditFilter{}.validate())
+	require.NoError(t, APITokenAuditFilter{Action: TokenAuditUsed, From: now.Add(-time.Hour), To: now}.validate())
+	require.ErrorIs(t, APITokenAuditFilter{Action: "DELETED"}.validate(), ErrInvalidAuditFilter)
+	require.ErrorIs(t, APITokenAuditFilter{From: now, To: now}.validate(), ErrInvalidAuditFilter)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultTokenExpiryWarningDays    = 7
+	DefaultTokenExpiryCheckInterval  = time.Hour
+	tokenExpiryScanBatchSize         = 100
+	TokenExpiringWebhookEvent        = "api_token.expiring"
+	tokenExpiringEmailTemplate       = "email-token-expiring.html"
+	tokenExpiringEmailSubject        = "Your API token is about to expire"
+	tokenExpiryNotificationTimeout   = 30 * time.Second
+	tokenExpiryNotificationMaxTokens = 1000
+)
+
+// TokenExpiryNotifierConfig configures the expiry warning job.
+//
+// Environment:
+//   - API_TOKEN_EXPIRY_WARNING_DAYS: warn this many days before expiry (default 7, 0 disables the job)
+//   - API_TOKEN_EXPIRY_CHECK_INTERVAL: scan interval (default 1h)
+//   - API_TOKEN_EXPIRY_WEBHOOK_URL / API_TOKEN_EXPIRY_WEBHOOK_SECRET: optional webhook target
+type TokenExpiryNotifierConfig struct {
+	WarningDays   int
+	Interval      time.Duration
+	WebhookURL    string
+	WebhookSecret string
+}
+
+func TokenExpiryNotifierConfigFromEnv() TokenExpiryNotifierConfig {
+	cfg := TokenExpiryNotifierConfig{
+		WarningDays:   DefaultTokenExpiryWarningDays,
+		Interval:      DefaultTokenExpiryCheckInterval,
+		WebhookURL:    os.Getenv("API_TOKEN_EXPIRY_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("API_TOKEN_EXPIRY_WEBHOOK_SECRET"),
+	}
+	if v := os.Getenv("API_TOKEN_EXPIRY_WARNING_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days >= 0 {
+			cfg.WarningDays = days
+		} else {
+			log.Warn().Str("API_TOKEN_EXPIRY_WARNING_DAYS", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("API_TOKEN_EXPIRY_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("API_TOKEN_EXPIRY_CHECK_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// TokenExpiryNotification is the webhook payload of an expiring token
+type TokenExpiryNotification struct {
+	TokenID               string    `json:"tokenId"`
+	TokenName             string    `json:"tokenName"`
+	TokenPrefix           string    `json:"tokenPrefix"`
+	ClientApplicationID   string    `json:"clientApplicationId"`
+	ClientApplicationName string    `json:"clientApplicationName"`
+	TenantID              string    `json:"tenantId,omitempty"`
+	CreatedBy             string    `json:"createdBy"`
+	ExpiresAt             time.Time `json:"expiresAt"`
+	DaysLeft              int       `json:"daysLeft"`
+}
+
+// StartTokenExpiryNotifier runs NotifyExpiringAPITokens now and then every
+// interval until ctx is done. It does nothing when WarningDays is 0.
+func (s *ClientApplicationService) StartTokenExpiryNotifier(ctx context.Context, cfg TokenExpiryNotifierConfig) {
+	if cfg.WarningDays <= 0 {
+		log.Info().Msg("API token expiry notifications disabled")
+		return
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultTokenExpiryCheckInterval
+	}
+	dispatcher := webhook.NewDispatcher()
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.NotifyExpiringAPITokens(ctx, cfg, dispatcher); err != nil {
+				log.Err(err).Msg("API token expiry scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// NotifyExpiringAPITokens warns the creator of every active token expiring
+// within cfg.WarningDays that was not warned yet, by email and through the
+// configured webhook. Each warning is recorded as an EXPIRY_WARNING audit
+// entry before notifying, which also keeps the token from being picked again.
+// It returns the number of tokens warned.
+func (s *ClientApplicationService) NotifyExpiringAPITokens(ctx context.Context, cfg TokenExpiryNotifierConfig, dispatcher *webhook.Dispatcher) (int, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	expiringBefore := time.Now().Add(time.Duration(cfg.WarningDays) * 24 * time.Hour)
+
+	warned := 0
+	for warned < tokenExpiryNotificationMaxTokens {
+		tokens, err := s.store.ListAPITokensExpiringWithoutWarning(ctx, repository.ListAPITokensExpiringWithoutWarningParams{
+			ExpiringBefore: expiringBefore,
+			BatchSize:      tokenExpiryScanBatchSize,
+		})
+		if err != nil {
+			logger.Err(err).Msg("Failed to list expiring API tokens")
+			return warned, fmt.Errorf("service.NotifyExpiringAPITokens: %w", err)
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		for _, token := range tokens {
+			claimed, err := s.warnExpiringAPIToken(ctx, cfg, dispatcher, token)
+			if err != nil {
+				return warned, fmt.Errorf("service.NotifyExpiringAPITokens: %w", err)
+			}
+			if claimed {
+				warned++
+			}
+		}
+		if len(tokens) < tokenExpiryScanBatchSize {
+			break
+		}
+	}
+	if warned > 0 {
+		logger.Info().Int("tokens", warned).Msg("Sent API token expiry warnings")
+	}
+	return warned, nil
+}
+
+// warnExpiringAPIToken records the warning then notifies. It reports false when
+// another instance already warned about the token.
+func (s *ClientApplicationService) warnExpiringAPIToken(ctx context.Context, cfg TokenExpiryNotifierConfig, dispatcher *webhook.Dispatcher, token repository.ListAPITokensExpiringWithoutWarningRow) (bool, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	notification := TokenExpiryNotification{
+		TokenID:               token.ID.String(),
+		TokenName:             token.Name,
+		TokenPrefix:           token.TokenPrefix,
+		ClientApplicationID:   token.ClientApplicationID.String(),
+		ClientApplicationName: token.ClientApplicationName,
+		TenantID:              token.TenantID.String,
+		CreatedBy:             token.CreatedBy,
+		ExpiresAt:             token.ExpiresAt,
+		DaysLeft:              int(math.Ceil(time.Until(token.ExpiresAt).Hours() / 24)),
+	}
+
+	additionalData, err := json.Marshal(map[string]interface{}{
+		"expires_at": token.ExpiresAt,
+		"days_left":  notification.DaysLeft,
+	})
+	if err != nil {
+		return false, err
+	}
+	_, err = s.store.CreateAPITokenExpiryWarning(ctx, repository.CreateAPITokenExpiryWarningParams{
+		TokenID:        token.ID,
+		AdditionalData: additionalData,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to record API token expiry warning")
+		return false, err
+	}
+
+	notifyCtx, cancel := context.WithTimeout(ctx, tokenExpiryNotificationTimeout)
+	defer cancel()
+
+	// Notification failures are logged only: the audit entry already exists
+	// and the token stays listed with its expiry date
+	if creatorEmail := DecryptEmail(token.CreatorEmail); creatorEmail.Valid && creatorEmail.String != "" {
+		if err := sendTokenExpiringEmail(creatorEmail.String, notification, GetTenantAssets(ctx, s.store.Queries, token.TenantID.String)); err != nil {
+			logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to send API token expiry email")
+		}
+	}
+	if cfg.WebhookURL != "" && dispatcher != nil {
+		envelope := webhook.Envelope{
+			ID:        uuid.New().String(),
+			EventType: TokenExpiringWebhookEvent,
+			CreatedAt: time.Now().UTC(),
+			Data:      notification,
+		}
+		target := webhook.Target{URL: cfg.WebhookURL, Secret: cfg.WebhookSecret}
+		if err := dispatcher.Deliver(notifyCtx, target, envelope, ""); err != nil {
+			logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to deliver API token expiry webhook")
+		}
+	}
+	return true, nil
+}
+
+func sendTokenExpiringEmail(toEmail string, notification TokenExpiryNotification, assets TenantAssets) error {
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	r := emailservice.NewEmailRequest(fromEmail, []string{toEmail}, tokenExpiringEmailSubject, "")
+	r.Assets = assets.Email()
+	r.TenantID = notification.TenantID
+	// No request here to resolve a domain specific template, use the base one
+	if err := r.ParseTemplate(filepath.Join("templates", tokenExpiringEmailTemplate), notification); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LastUsedCache remembers when the last-used timestamp of a token or an
+// application was persisted, so it is written at most once per interval.
+// Claim reports whether the caller should write it now, and if so records
+// the write.
+type LastUsedCache interface {
+	Claim(ctx context.Context, key string, interval time.Duration) (bool, error)
+}
+
+// NewLastUsedCacheFromEnv returns a Redis backed cache when REDIS_URL is set,
+// so instances share the interval of a hot token, and an in-memory one
+// otherwise.
+func NewLastUsedCacheFromEnv() LastUsedCache {
+	if client := SharedRedisClient(); client != nil {
+		return NewRedisLastUsedCache(client)
+	}
+	return NewMemoryLastUsedCache()
+}
+
+// MemoryLastUsedCache keeps the write times in process
+type MemoryLastUsedCache struct {
+	mu        sync.Mutex
+	written   map[string]time.Time
+	lastPrune time.Time
+}
+
+func NewMemoryLastUsedCache() *MemoryLastUsedCache {
+	return &MemoryLastUsedCache{written: make(map[string]time.Time), lastPrune: time.Now()}
+}
+
+func (c *MemoryLastUsedCache) Claim(_ context.Context, key string, interval time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	// Entries older than the interval no longer delay anything
+	if now.Sub(c.lastPrune) >= interval {
+		for k, at := range c.written {
+			if now.Sub(at) >= interval {
+				delete(c.written, k)
+			}
+		}
+		c.lastPrune = now
+	}
+	if at, ok := c.written[key]; ok && now.Sub(at) < interval {
+		return false, nil
+	}
+	c.written[key] = now
+	return true, nil
+}
+
+// RedisLastUsedCache shares the write times across instances through keys
+// expiring after the interval
+type RedisLastUsedCache struct {
+	client *redis.Client
+}
+
+func NewRedisLastUsedCache(client *redis.Client) *RedisLastUsedCache {
+	return &RedisLastUsedCache{client: client}
+}
+
+func (c *RedisLastUsedCache) Claim(ctx context.Context, key string, interval time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, "last_used:"+key, 1, interval).Result()
+}
@@ -0,0 +1,34 @@
+package service
+
+import "time"
+
+// TokenRateLimit is the request budget of an API token. A zero value for a
+// window means that window is not limited.
+type TokenRateLimit struct {
+	PerMinute int
+	PerHour   int
+}
+
+// IsUnlimited reports whether no window is limited
+func (l TokenRateLimit) IsUnlimited() bool {
+	return l.PerMinute <= 0 && l.PerHour <= 0
+}
+
+// Windows returns the budget as the windows of a RateLimiter
+func (l TokenRateLimit) Windows() []RateWindow {
+	return []RateWindow{
+		{Limit: l.PerMinute, Period: time.Minute},
+		{Limit: l.PerHour, Period: time.Hour},
+	}
+}
+
+// apiTokenRateLimitKey is the RateLimiter key of a token
+func apiTokenRateLimitKey(tokenID string) string {
+	return "api_token:" + tokenID
+}
+
+// clientCredentialsRateLimitKey is the RateLimiter key of the access tokens
+// issued with a client secret
+func clientCredentialsRateLimitKey(secretID string) string {
+	return "client_credentials:" + secretID
+}
@@ -1,16 +1,25 @@
 package service
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
 	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,6 +27,8 @@ func setupTestAPITokenService(t *testing.T) (*ClientApplicationService, *commont
 	store := testutils.NewTestStore(t)
 	mockAuth := &commontestutils.MockAuthenticator{}
 	service := NewClientApplicationService(store)
+	// The webhooks of the tests are served on the loopback interface
+	service.webhooks = webhook.NewDispatcher()
 	// Build a real test context so ClientIP()/GetHeader() (used in the token
 	// audit path) have an engine and a request to read from.
 	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
@@ -189,6 +200,62 @@ func TestRevokeAPIToken(t *testing.T) {
 	})
 }
 
+func TestRevokeAllAPITokens(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+
+	app := createTestClientApplication(t, service)
+	tenantID := app.TenantID.String
+	sibling, err := service.CreateClientApplication(ctx, tenantID, commontestutils.RandomString(10), "", "creator")
+	require.NoError(t, err)
+	other := createTestClientApplication(t, service)
+
+	newToken := func(appID uuid.UUID, tenantID string) uuid.UUID {
+		_, apiToken, err := service.CreateAPIToken(ctx, appID, tenantID, commontestutils.RandomString(8), "", 30, "creator", []string{"read"})
+		require.NoError(t, err)
+		return apiToken.ID
+	}
+	first := newToken(app.ID, tenantID)
+	second := newToken(app.ID, tenantID)
+	siblingToken := newToken(sibling.ID, tenantID)
+	otherToken := newToken(other.ID, other.TenantID.String)
+
+	t.Run("revokes the tokens of one application", func(t *testing.T) {
+		revoked, err := service.RevokeAllAPITokens(ctx, &app.ID, tenantID, "compromised", "admin")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uuid.UUID{first, second}, revoked)
+
+		logs, err := service.GetAPITokenAuditLogs(ctx, first, APITokenAuditFilter{}, 100, 0)
+		require.NoError(t, err)
+		found := false
+		for _, log := range logs {
+			if log.Action == TokenAuditRevoked {
+				found = true
+				require.Contains(t, string(log.AdditionalData), "compromised")
+			}
+		}
+		require.True(t, found)
+
+		_, err = service.GetAPITokenByID(ctx, siblingToken, tenantID)
+		require.NoError(t, err)
+	})
+
+	t.Run("revokes the remaining tokens of the tenant", func(t *testing.T) {
+		revoked, err := service.RevokeAllAPITokens(ctx, nil, tenantID, "incident", "admin")
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{siblingToken}, revoked)
+
+		// Another tenant's tokens stay active
+		_, err = service.GetAPITokenByID(ctx, otherToken, other.TenantID.String)
+		require.NoError(t, err)
+	})
+
+	t.Run("an application of another tenant is out of scope", func(t *testing.T) {
+		revoked, err := service.RevokeAllAPITokens(ctx, &other.ID, tenantID, "incident", "admin")
+		require.NoError(t, err)
+		require.Empty(t, revoked)
+	})
+}
+
 func TestListAPITokens(t *testing.T) {
 	service, _, ctx := setupTestAPITokenService(t)
 
@@ -218,6 +285,11 @@ func TestListAPITokens(t *testing.T) {
 
 		// Verify sorting
 		require.True(t, tokens[0].CreatedAt.After(tokens[1].CreatedAt))
+
+		// The count spans every page
+		total, err := service.CountAPITokens(ctx, &app.ID, app.TenantID.String, false, false)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, total)
 	})
 
 	t.Run("list tokens with filters", func(t *testing.T) {
@@ -326,3 +398,538 @@ func TestDeleteAPIToken(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestAPITokenIPAllowlist(t *testing.T) {
+	t.Run("normalizes addresses and prefixes", func(t *testing.T) {
+		cidrs, err := NormalizeAllowedCIDRs([]string{" 10.1.2.3/8 ", "192.168.1.10", "2001:db8::1/32", "10.0.0.0/8"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"10.0.0.0/8", "192.168.1.10/32", "2001:db8::/32"}, cidrs)
+	})
+
+	t.Run("rejects invalid entries", func(t *testing.T) {
+		_, err := NormalizeAllowedCIDRs([]string{"10.0.0.0/33"})
+		require.Error(t, err)
+		_, err = NormalizeAllowedCIDRs([]string{"not-an-ip"})
+		require.Error(t, err)
+	})
+
+	t.Run("matches client IPs", func(t *testing.T) {
+		cidrs := []string{"10.0.0.0/8", "2001:db8::/32"}
+		require.True(t, IsIPAllowed("10.20.30.40", cidrs))
+		require.True(t, IsIPAllowed("::ffff:10.20.30.40", cidrs))
+		require.True(t, IsIPAllowed("2001:db8::42", cidrs))
+		require.False(t, IsIPAllowed("192.168.1.1", cidrs))
+		require.False(t, IsIPAllowed("", cidrs))
+		require.True(t, IsIPAllowed("192.168.1.1", nil))
+	})
+}
+
+func TestUpdateAPIToken(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	tenantID := app.TenantID.String
+
+	_, token, err := service.CreateAPIToken(ctx, app.ID, tenantID, commontestutils.RandomString(10), "", 30, app.CreatedBy, []string{"users:*", "files:read"})
+	require.NoError(t, err)
+
+	t.Run("scopes can be narrowed", func(t *testing.T) {
+		scopes := []string{"users:read"}
+		updated, err := service.UpdateAPIToken(ctx, app.ID, token.ID, tenantID, &scopes, nil, app.CreatedBy)
+		require.NoError(t, err)
+		require.Equal(t, scopes, updated.Scopes)
+		require.WithinDuration(t, token.ExpiresAt, updated.ExpiresAt, time.Second)
+	})
+
+	t.Run("scopes cannot be widened", func(t *testing.T) {
+		scopes := []string{"users:write"}
+		_, err := service.UpdateAPIToken(ctx, app.ID, token.ID, tenantID, &scopes, nil, app.CreatedBy)
+		require.ErrorIs(t, err, ErrTokenScopesNotNarrowed)
+	})
+
+	t.Run("expiry can only be brought forward", func(t *testing.T) {
+		later := token.ExpiresAt.Add(time.Hour)
+		_, err := service.UpdateAPIToken(ctx, app.ID, token.ID, tenantID, nil, &later, app.CreatedBy)
+		require.ErrorIs(t, err, ErrTokenExpiryNotShortened)
+
+		sooner := time.Now().Add(24 * time.Hour)
+		updated, err := service.UpdateAPIToken(ctx, app.ID, token.ID, tenantID, nil, &sooner, app.CreatedBy)
+		require.NoError(t, err)
+		require.WithinDuration(t, sooner, updated.ExpiresAt, time.Second)
+	})
+
+	t.Run("the update is audited", func(t *testing.T) {
+		logs, err := service.GetAPITokenAuditLogs(ctx, token.ID, APITokenAuditFilter{}, 10, 0)
+		require.NoError(t, err)
+		updates := 0
+		for _, entry := range logs {
+			if entry.Action == TokenAuditUpdated {
+				updates++
+			}
+		}
+		require.Equal(t, 2, updates)
+
+		filtered, err := service.GetAPITokenAuditLogs(ctx, token.ID,
+			APITokenAuditFilter{Action: TokenAuditUpdated, From: time.Now().Add(-time.Hour)}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, filtered, updates)
+
+		filtered, err = service.GetAPITokenAuditLogs(ctx, token.ID, APITokenAuditFilter{To: time.Now().Add(-time.Hour)}, 10, 0)
+		require.NoError(t, err)
+		require.Empty(t, filtered)
+	})
+
+	t.Run("a token of another application is not found", func(t *testing.T) {
+		_, err := service.UpdateAPIToken(ctx, uuid.New(), token.ID, tenantID, nil, nil, app.CreatedBy)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestAPITokenQuota(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	require.True(t, app.MaxActiveTokens.Valid)
+
+	limit := int32(2)
+	app, err := service.SetClientApplicationTokenQuota(ctx, app.ID, &limit)
+	require.NoError(t, err)
+	require.Equal(t, limit, app.MaxActiveTokens.Int32)
+
+	_, first, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+	_, _, err = service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+
+	_, _, err = service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.ErrorIs(t, err, ErrTokenQuotaExceeded)
+
+	t.Run("revoked tokens free the quota", func(t *testing.T) {
+		_, err := service.RevokeAPIToken(ctx, first.ID, app.TenantID.String, "quota test", app.CreatedBy)
+		require.NoError(t, err)
+		_, _, err = service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("no quota means no limit", func(t *testing.T) {
+		app, err := service.SetClientApplicationTokenQuota(ctx, app.ID, nil)
+		require.NoError(t, err)
+		require.False(t, app.MaxActiveTokens.Valid)
+		_, _, err = service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects quotas below one", func(t *testing.T) {
+		zero := int32(0)
+		_, err := service.SetClientApplicationTokenQuota(ctx, app.ID, &zero)
+		require.ErrorIs(t, err, ErrInvalidTokenQuota)
+	})
+}
+
+func TestNotifyExpiringAPITokens(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	cfg := TokenExpiryNotifierConfig{WarningDays: 7}
+
+	_, expiring, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 2, commontestutils.RandomString(10), []string{"read"})
+	require.NoError(t, err)
+	_, later, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, commontestutils.RandomString(10), []string{"read"})
+	require.NoError(t, err)
+
+	countWarnings := func(tokenID uuid.UUID) int {
+		logs, err := service.GetAPITokenAuditLogs(ctx, tokenID, APITokenAuditFilter{}, 100, 0)
+		require.NoError(t, err)
+		count := 0
+		for _, log := range logs {
+			if log.Action == TokenAuditExpiryWarning {
+				count++
+			}
+		}
+		return count
+	}
+
+	warned, err := service.NotifyExpiringAPITokens(ctx, cfg, nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, warned, 1)
+	require.Equal(t, 1, countWarnings(expiring.ID))
+	require.Equal(t, 0, countWarnings(later.ID))
+
+	// A token is warned only once
+	_, err = service.NotifyExpiringAPITokens(ctx, cfg, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, countWarnings(expiring.ID))
+}
+
+func TestClientApplicationWebhooks(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	service.signing = RequestSigningConfig{MasterKey: []byte(commontestutils.RandomString(32))}
+	app := createTestClientApplication(t, service)
+
+	var secret string
+	received := make(chan webhook.Envelope, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+		if !webhook.Verify(secret, timestamp, body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var envelope webhook.Envelope
+		_ = json.Unmarshal(body, &envelope)
+		received <- envelope
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, _, err := service.SetWebhook(ctx, app.ID, app.TenantID.String, "ftp://example.com", app.CreatedBy)
+	require.ErrorIs(t, err, ErrInvalidWebhookURL)
+
+	secret, _, err = service.SetWebhook(ctx, app.ID, app.TenantID.String, server.URL, app.CreatedBy)
+	require.NoError(t, err)
+	require.Contains(t, secret, WebhookSecretPrefix)
+
+	next := func() webhook.Envelope {
+		select {
+		case envelope := <-received:
+			return envelope
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not delivered")
+			return webhook.Envelope{}
+		}
+	}
+
+	_, token, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+	require.Equal(t, APITokenCreatedWebhookEvent, next().EventType)
+
+	_, err = service.RevokeAPIToken(ctx, token.ID, app.TenantID.String, "rotated", app.CreatedBy)
+	require.NoError(t, err)
+	require.Equal(t, APITokenRevokedWebhookEvent, next().EventType)
+
+	require.NoError(t, service.DeactivateClientApplication(ctx, app.ID, app.TenantID.String, app.CreatedBy))
+	require.Equal(t, ClientApplicationDeactivatedWebhookEvent, next().EventType)
+
+	require.NoError(t, service.DeleteWebhook(ctx, app.ID, app.TenantID.String))
+	_, err = service.GetWebhook(ctx, app.ID, app.TenantID.String)
+	require.Error(t, err)
+}
+
+func TestExportAPITokenAuditLogs(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	_, token, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	_, err = service.store.CreateAPITokenAuditLogs(ctx, repository.CreateAPITokenAuditLogsParams{
+		TokenIds:       []uuid.UUID{token.ID, token.ID},
+		Actions:        []string{"USED", "USED"},
+		IpAddresses:    []string{"10.0.0.1", "10.0.0.2"},
+		UserAgents:     []string{"curl", "curl"},
+		AdditionalData: []string{`{"path":"/a"}`, `{"path":"/b"}`},
+		Timestamps:     []time.Time{now.Add(time.Minute), now.Add(2 * time.Minute)},
+	})
+	require.NoError(t, err)
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		written, err := service.ExportAPITokenAuditLogs(ctx, token.ID, "", &out)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, written)
+
+		rows, err := csv.NewReader(&out).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		require.Equal(t, apiTokenAuditCSVHeader, rows[0])
+		require.Equal(t, TokenAuditCreated, rows[1][2])
+		require.Equal(t, `{"path":"/b"}`, rows[3][6])
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		var out bytes.Buffer
+		written, err := service.ExportAPITokenAuditLogs(ctx, token.ID, APITokenAuditExportFormatNDJSON, &out)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, written)
+
+		decoder := json.NewDecoder(&out)
+		var records []apiTokenAuditRecord
+		for decoder.More() {
+			var record apiTokenAuditRecord
+			require.NoError(t, decoder.Decode(&record))
+			records = append(records, record)
+		}
+		require.Len(t, records, 3)
+		require.Equal(t, "10.0.0.1", records[1].IPAddress)
+		require.JSONEq(t, `{"path":"/a"}`, string(records[1].AdditionalData))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := service.ExportAPITokenAuditLogs(ctx, token.ID, "xml", io.Discard)
+		require.ErrorIs(t, err, ErrInvalidAuditExportFormat)
+	})
+}
+
+func TestGetAPITokenUsage(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	_, token, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+	_, other, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+	entries := []struct {
+		token     uuid.UUID
+		action    string
+		ip        string
+		data      string
+		timestamp time.Time
+	}{
+		// From is inclusive and to exclusive
+		{token.ID, TokenAuditUsed, "10.0.0.1", `{"method":"GET","path":"/a"}`, from},
+		{token.ID, TokenAuditUsed, "10.0.0.5", `{"method":"GET","path":"/d"}`, from.Add(-time.Second)},
+		{token.ID, TokenAuditUsed, "10.0.0.4", `{"method":"GET","path":"/c"}`, to},
+		{token.ID, TokenAuditUsed, "10.0.0.2", `{"method":"GET","path":"/a"}`, from.Add(10 * time.Hour)},
+		{token.ID, TokenAuditUsed, "10.0.0.1", `{"method":"POST","path":"/b"}`, from.Add(33 * time.Hour)},
+		{token.ID, TokenAuditUsed, "10.0.0.3", `{"method":"GET","path":"/c"}`, from.Add(60 * time.Hour)},
+		{token.ID, TokenAuditUsed, "10.0.0.3", `{"method":"GET","path":"/a"}`, to.Add(-time.Second)},
+		// Neither the other actions nor the other tokens count
+		{token.ID, TokenAuditDeniedIP, "10.0.0.9", `{"method":"GET","path":"/a"}`, from.Add(11 * time.Hour)},
+		{other.ID, TokenAuditUsed, "10.0.0.8", `{"method":"GET","path":"/a"}`, from.Add(12 * time.Hour)},
+	}
+	params := repository.CreateAPITokenAuditLogsParams{}
+	for _, entry := range entries {
+		params.TokenIds = append(params.TokenIds, entry.token)
+		params.Actions = append(params.Actions, entry.action)
+		params.IpAddresses = append(params.IpAddresses, entry.ip)
+		params.UserAgents = append(params.UserAgents, "curl")
+		params.AdditionalData = append(params.AdditionalData, entry.data)
+		params.Timestamps = append(params.Timestamps, entry.timestamp)
+	}
+	_, err = service.store.CreateAPITokenAuditLogs(ctx, params)
+	require.NoError(t, err)
+
+	usage, err := service.GetAPITokenUsage(ctx, token.ID, from, to, 10)
+	require.NoError(t, err)
+	require.Equal(t, from, usage.From)
+	require.Equal(t, to, usage.To)
+	require.EqualValues(t, 5, usage.TotalRequests)
+	require.EqualValues(t, 3, usage.UniqueIPs)
+
+	require.Len(t, usage.Daily, 3)
+	for i, day := range []repository.GetAPITokenUsageByDayRow{
+		{Day: from, Requests: 2, UniqueIps: 2},
+		{Day: from.AddDate(0, 0, 1), Requests: 1, UniqueIps: 1},
+		{Day: from.AddDate(0, 0, 2), Requests: 2, UniqueIps: 1},
+	} {
+		require.True(t, day.Day.Equal(usage.Daily[i].Day), "day %d: %s", i, usage.Daily[i].Day)
+		require.Equal(t, day.Requests, usage.Daily[i].Requests, "day %d", i)
+		require.Equal(t, day.UniqueIps, usage.Daily[i].UniqueIps, "day %d", i)
+	}
+
+	// The most called first, the ties by path
+	require.Equal(t, []repository.GetAPITokenTopEndpointsRow{
+		{Method: "GET", Path: "/a", Requests: 3},
+		{Method: "POST", Path: "/b", Requests: 1},
+		{Method: "GET", Path: "/c", Requests: 1},
+	}, usage.TopEndpoints)
+
+	usage, err = service.GetAPITokenUsage(ctx, token.ID, from, to, 2)
+	require.NoError(t, err)
+	require.Len(t, usage.TopEndpoints, 2)
+	require.Equal(t, "/b", usage.TopEndpoints[1].Path)
+
+	// A range without usage
+	usage, err = service.GetAPITokenUsage(ctx, token.ID, to.AddDate(0, 0, 1), to.AddDate(0, 0, 2), 10)
+	require.NoError(t, err)
+	require.Zero(t, usage.TotalRequests)
+	require.Zero(t, usage.UniqueIPs)
+	require.Empty(t, usage.Daily)
+	require.Empty(t, usage.TopEndpoints)
+}
+
+func TestAPITokenUsageWriter(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	service.usage = newTokenUsageWriter(service.store, TokenUsageWriterConfig{
+		Async:         true,
+		FlushInterval: time.Hour,
+		BatchSize:     100,
+	})
+
+	tokenString, apiToken, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, commontestutils.RandomString(10), []string{"read"})
+	require.NoError(t, err)
+
+	countUsed := func() int {
+		logs, err := service.GetAPITokenAuditLogs(ctx, apiToken.ID, APITokenAuditFilter{}, 100, 0)
+		require.NoError(t, err)
+		count := 0
+		for _, log := range logs {
+			if log.Action == TokenAuditUsed {
+				count++
+			}
+		}
+		return count
+	}
+
+	for range 3 {
+		_, err := service.VerifyAPIToken(ctx, tokenString)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 0, countUsed())
+
+	require.NoError(t, service.Close(ctx))
+	require.Equal(t, 3, countUsed())
+	token, err := service.GetAPITokenByID(ctx, apiToken.ID, app.TenantID.String)
+	require.NoError(t, err)
+	require.True(t, token.LastUsedAt.Valid)
+
+	// Once closed, usage is written synchronously
+	_, err = service.VerifyAPIToken(ctx, tokenString)
+	require.NoError(t, err)
+	require.Equal(t, 4, countUsed())
+}
+
+func TestAPITokenLastUsedDebounce(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	service.usage = newTokenUsageWriter(service.store, TokenUsageWriterConfig{
+		Async:            true,
+		FlushInterval:    50 * time.Millisecond,
+		BatchSize:        1,
+		LastUsedInterval: time.Hour,
+	})
+
+	tokenString, apiToken, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, commontestutils.RandomString(10), nil)
+	require.NoError(t, err)
+	lastUsed := func() time.Time {
+		token, err := service.GetAPITokenByID(ctx, apiToken.ID, app.TenantID.String)
+		require.NoError(t, err)
+		return token.LastUsedAt.Time
+	}
+
+	_, err = service.VerifyAPIToken(ctx, tokenString)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return !lastUsed().IsZero() }, 5*time.Second, 20*time.Millisecond)
+	first := lastUsed()
+
+	// Within the interval the update waits, while the audit entry is written
+	_, err = service.VerifyAPIToken(ctx, tokenString)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	require.True(t, first.Equal(lastUsed()))
+
+	// Close writes the pending update
+	require.NoError(t, service.Close(ctx))
+	require.True(t, lastUsed().After(first))
+}
+
+func TestAPITokenLastUsedSyncDebounce(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	service.usage = newTokenUsageWriter(service.store, TokenUsageWriterConfig{
+		LastUsedInterval: time.Hour,
+		LastUsedCache:    NewMemoryLastUsedCache(),
+	})
+
+	tokenString, apiToken, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, commontestutils.RandomString(10), nil)
+	require.NoError(t, err)
+	lastUsed := func() time.Time {
+		token, err := service.GetAPITokenByID(ctx, apiToken.ID, app.TenantID.String)
+		require.NoError(t, err)
+		return token.LastUsedAt.Time
+	}
+
+	_, err = service.VerifyAPIToken(ctx, tokenString)
+	require.NoError(t, err)
+	first := lastUsed()
+	require.False(t, first.IsZero())
+
+	// Written once per interval, even in sync mode
+	_, err = service.VerifyAPIToken(ctx, tokenString)
+	require.NoError(t, err)
+	require.True(t, first.Equal(lastUsed()))
+}
+
+func TestCheckAPITokenRateLimitRetryAfter(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	service.usage = newTokenUsageWriter(service.store, TokenUsageWriterConfig{})
+	limiter, _ := newTestMemoryRateLimiter()
+	service.rateLimiter = limiter
+
+	_, apiToken, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, commontestutils.RandomString(10), nil)
+	require.NoError(t, err)
+	tokenRow := repository.GetAPITokensByPrefixRow{
+		ID:                  apiToken.ID,
+		ClientApplicationID: app.ID,
+		RateLimitPerMinute:  pgtype.Int4{Int32: 2, Valid: true},
+	}
+	check := func() (bool, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/resource", nil)
+		return service.CheckAPITokenRateLimit(c, tokenRow), w
+	}
+
+	for i := 0; i < 2; i++ {
+		allowed, w := check()
+		require.True(t, allowed)
+		require.Empty(t, w.Header().Get("Retry-After"))
+	}
+	allowed, w := check()
+	require.False(t, allowed)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	// Two requests a minute, the next one is allowed in 30 seconds
+	require.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	logs, err := service.GetAPITokenAuditLogs(ctx, apiToken.ID, APITokenAuditFilter{Action: TokenAuditThrottled}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs[0].AdditionalData, &data))
+	require.EqualValues(t, 30, data["retry_after_seconds"])
+
+	// A token without limits is never throttled
+	tokenRow.RateLimitPerMinute = pgtype.Int4{}
+	allowed, _ = check()
+	require.True(t, allowed)
+}
+
+func TestCreateAPITokenWithLimits(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	perMinute, perHour := int32(10), int32(100)
+
+	_, apiToken, err := service.CreateAPITokenWithLimits(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30,
+		commontestutils.RandomString(10), nil, APITokenLimits{
+			RateLimitPerMinute: &perMinute,
+			RateLimitPerHour:   &perHour,
+			AllowedCIDRs:       []string{"10.0.0.1", "192.168.1.0/24"},
+		})
+	require.NoError(t, err)
+	// The limits are part of the inserted row
+	require.Equal(t, pgtype.Int4{Int32: perMinute, Valid: true}, apiToken.RateLimitPerMinute)
+	require.Equal(t, pgtype.Int4{Int32: perHour, Valid: true}, apiToken.RateLimitPerHour)
+	require.Equal(t, []string{"10.0.0.1/32", "192.168.1.0/24"}, apiToken.AllowedCidrs)
+
+	_, unlimited, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30, commontestutils.RandomString(10), nil)
+	require.NoError(t, err)
+	require.False(t, unlimited.RateLimitPerMinute.Valid)
+	require.Empty(t, unlimited.AllowedCidrs)
+
+	countTokens := func() int64 {
+		count, err := service.CountAPITokens(ctx, &app.ID, app.TenantID.String, true, true)
+		require.NoError(t, err)
+		return count
+	}
+	before := countTokens()
+	zero := int32(0)
+	_, _, err = service.CreateAPITokenWithLimits(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30,
+		commontestutils.RandomString(10), nil, APITokenLimits{RateLimitPerHour: &zero})
+	require.ErrorIs(t, err, ErrInvalidAPITokenRateLimit)
+	_, _, err = service.CreateAPITokenWithLimits(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(10), "", 30,
+		commontestutils.RandomString(10), nil, APITokenLimits{AllowedCIDRs: []string{"10.0.0.0/33"}})
+	require.ErrorIs(t, err, ErrInvalidCIDR)
+	// Invalid limits create no token
+	require.Equal(t, before, countTokens())
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultTokenUsageFlushInterval = 2 * time.Second
+	DefaultTokenUsageBatchSize     = 500
+	DefaultTokenLastUsedInterval   = time.Minute
+	// The buffer holds this many batches before Record falls back to a
+	// synchronous write
+	tokenUsageBufferBatches = 10
+)
+
+// TokenUsageWriterConfig configures how token usage is persisted.
+//
+// Environment:
+//   - API_TOKEN_USAGE_WRITE_MODE: async (default) or sync. In sync mode every
+//     request writes its audit entry and last-used timestamps before returning.
+//   - API_TOKEN_USAGE_FLUSH_INTERVAL: async flush interval (default 2s)
+//   - API_TOKEN_USAGE_BATCH_SIZE: flush as soon as this many entries are buffered (default 500)
+//   - API_TOKEN_LAST_USED_INTERVAL: the last-used timestamps of a token or an
+//     application are written at most once per interval (default 1m, 0
+//     writes them every time). Audit entries keep the time of every request.
+//     With REDIS_URL set, the interval is shared by all instances.
+type TokenUsageWriterConfig struct {
+	Async            bool
+	FlushInterval    time.Duration
+	BatchSize        int
+	LastUsedInterval time.Duration
+	// LastUsedCache records the last-used writes across instances; in sync
+	// mode an in-memory cache is used when nil
+	LastUsedCache LastUsedCache
+}
+
+func TokenUsageWriterConfigFromEnv() TokenUsageWriterConfig {
+	cfg := TokenUsageWriterConfig{
+		Async:            true,
+		FlushInterval:    DefaultTokenUsageFlushInterval,
+		BatchSize:        DefaultTokenUsageBatchSize,
+		LastUsedInterval: DefaultTokenLastUsedInterval,
+		LastUsedCache:    NewLastUsedCacheFromEnv(),
+	}
+	switch v := strings.ToLower(os.Getenv("API_TOKEN_USAGE_WRITE_MODE")); v {
+	case "", "async":
+	case "sync":
+		cfg.Async = false
+	default:
+		log.Warn().Str("API_TOKEN_USAGE_WRITE_MODE", v).Msg("Invalid value, using default")
+	}
+	if v := os.Getenv("API_TOKEN_USAGE_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.FlushInterval = d
+		} else {
+			log.Warn().Str("API_TOKEN_USAGE_FLUSH_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("API_TOKEN_USAGE_BATCH_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil && size > 0 {
+			cfg.BatchSize = size
+		} else {
+			log.Warn().Str("API_TOKEN_USAGE_BATCH_SIZE", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("API_TOKEN_LAST_USED_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.LastUsedInterval = d
+		} else {
+			log.Warn().Str("API_TOKEN_LAST_USED_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// tokenUsage is one audit entry, optionally bumping the last-used timestamps
+// of the token and its client application. Without an action no audit entry
+// is written, and without a token only the application is bumped.
+type tokenUsage struct {
+	TokenID             uuid.UUID
+	ClientApplicationID uuid.UUID
+	Action              string
+	IPAddress           string
+	UserAgent           string
+	AdditionalData      []byte
+	UpdateLastUsed      bool
+	At                  time.Time
+}
+
+// tokenUsageWriter keeps the token usage writes off the request path. In
+// async mode entries are buffered and written in batches on every flush
+// interval, when a batch is full and on Close.
+type tokenUsageWriter struct {
+	store   *db.Store
+	cfg     TokenUsageWriterConfig
+	entries chan tokenUsage
+	stop    chan struct{}
+	done    chan struct{}
+	// mu orders the sends on entries before Close, so the final drain sees
+	// every queued entry
+	mu     sync.RWMutex
+	closed bool
+
+	// Owned by run: the latest last-used update of each token and application
+	// waiting for its interval, and when each was last written
+	pendingTokens map[uuid.UUID]tokenUsage
+	pendingApps   map[uuid.UUID]time.Time
+	writtenTokens map[uuid.UUID]time.Time
+	writtenApps   map[uuid.UUID]time.Time
+}
+
+func newTokenUsageWriter(store *db.Store, cfg TokenUsageWriterConfig) *tokenUsageWriter {
+	w := &tokenUsageWriter{store: store, cfg: cfg}
+	if !cfg.Async {
+		if w.cfg.LastUsedCache == nil {
+			w.cfg.LastUsedCache = NewMemoryLastUsedCache()
+		}
+		return w
+	}
+	// The async writer already debounces in process, only a cache shared
+	// with other instances adds to it
+	if _, local := w.cfg.LastUsedCache.(*MemoryLastUsedCache); local {
+		w.cfg.LastUsedCache = nil
+	}
+	if w.cfg.BatchSize <= 0 {
+		w.cfg.BatchSize = DefaultTokenUsageBatchSize
+	}
+	if w.cfg.FlushInterval <= 0 {
+		w.cfg.FlushInterval = DefaultTokenUsageFlushInterval
+	}
+	w.entries = make(chan tokenUsage, w.cfg.BatchSize*tokenUsageBufferBatches)
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	w.pendingTokens = map[uuid.UUID]tokenUsage{}
+	w.pendingApps = map[uuid.UUID]time.Time{}
+	w.writtenTokens = map[uuid.UUID]time.Time{}
+	w.writtenApps = map[uuid.UUID]time.Time{}
+	go w.run()
+	return w
+}
+
+// Record persists the usage, or queues it in async mode. When the buffer is
+// full the entry is written synchronously rather than dropped.
+func (w *tokenUsageWriter) Record(ctx context.Context, usage tokenUsage) {
+	if usage.At.IsZero() {
+		usage.At = time.Now()
+	}
+	if usage.Action != "" {
+		// Enriched now, the request is gone once the entry is flushed
+		usage.AdditionalData = enrichAPITokenAuditData(ctx,
+			APITokenAuditEntry{TokenID: usage.TokenID, Action: usage.Action}, usage.AdditionalData)
+	}
+	if w.entries != nil && w.enqueue(usage) {
+		return
+	}
+	w.write(ctx, usage)
+}
+
+func (w *tokenUsageWriter) enqueue(usage tokenUsage) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	select {
+	case w.entries <- usage:
+		return true
+	default:
+		log.Warn().Msg("Token usage buffer is full, writing synchronously")
+		return false
+	}
+}
+
+// TouchClientApplication bumps the last-used timestamp of an application
+// authenticated without a token, by a signed request or client credentials
+func (w *tokenUsageWriter) TouchClientApplication(ctx context.Context, clientApplicationID uuid.UUID) {
+	w.Record(ctx, tokenUsage{ClientApplicationID: clientApplicationID, UpdateLastUsed: true})
+}
+
+// Close writes the buffered entries and stops the background writer. Entries
+// recorded afterwards are written synchronously.
+func (w *tokenUsageWriter) Close(ctx context.Context) error {
+	if w.entries == nil {
+		return nil
+	}
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *tokenUsageWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]tokenUsage, 0, w.cfg.BatchSize)
+	flush := func(force bool) {
+		if len(batch) > 0 {
+			w.writeBatch(context.Background(), batch)
+			batch = batch[:0]
+		}
+		w.writeLastUsed(context.Background(), time.Now(), force)
+	}
+	for {
+		select {
+		case usage := <-w.entries:
+			batch = append(batch, usage)
+			if len(batch) >= w.cfg.BatchSize {
+				flush(false)
+			}
+		case <-ticker.C:
+			flush(false)
+		case <-w.stop:
+			w.drain(&batch)
+			flush(true)
+			return
+		}
+	}
+}
+
+// drain moves the entries already queued into the batch, flushing full
+// batches on the way
+func (w *tokenUsageWriter) drain(batch *[]tokenUsage) {
+	for {
+		select {
+		case usage := <-w.entries:
+			*batch = append(*batch, usage)
+			if len(*batch) >= w.cfg.BatchSize {
+				w.writeBatch(context.Background(), *batch)
+				*batch = (*batch)[:0]
+			}
+		default:
+			return
+		}
+	}
+}
+
+// write is the synchronous path, one statement per update
+func (w *tokenUsageWriter) write(ctx context.Context, usage tokenUsage) {
+	if usage.Action != "" {
+		_, err := w.store.CreateAPITokenAuditLog(ctx, repository.CreateAPITokenAuditLogParams{
+			TokenID:        usage.TokenID,
+			Action:         usage.Action,
+			IpAddress:      pgtype.Text{String: usage.IPAddress, Valid: true},
+			UserAgent:      pgtype.Text{String: usage.UserAgent, Valid: true},
+			AdditionalData: usage.AdditionalData,
+		})
+		if err != nil {
+			log.Err(err).Str("tokenID", usage.TokenID.String()).Str("action", usage.Action).Msg("Failed to create audit log for token usage")
+		}
+	}
+	if !usage.UpdateLastUsed {
+		return
+	}
+	if usage.TokenID != uuid.Nil && w.claimLastUsed(ctx, "api_token:"+usage.TokenID.String()) {
+		err := w.store.UpdateAPITokenLastUsed(ctx, repository.UpdateAPITokenLastUsedParams{
+			ID:        usage.TokenID,
+			IpAddress: pgtype.Text{String: usage.IPAddress, Valid: true},
+		})
+		if err != nil {
+			log.Err(err).Str("tokenID", usage.TokenID.String()).Msg("Failed to update token last used timestamp")
+		}
+	}
+	if !w.claimLastUsed(ctx, "client_application:"+usage.ClientApplicationID.String()) {
+		return
+	}
+	err := w.store.UpdateClientApplicationLastUsed(ctx, usage.ClientApplicationID)
+	if err != nil {
+		log.Err(err).Str("clientApplicationID", usage.ClientApplicationID.String()).Msg("Failed to update client application last used timestamp")
+	}
+}
+
+// claimLastUsed reports whether the last-used timestamp of key is due, i.e.
+// was not written within the interval, by this instance or, with Redis,
+// another one. A cache error lets the write through.
+func (w *tokenUsageWriter) claimLastUsed(ctx context.Context, key string) bool {
+	if w.cfg.LastUsedInterval <= 0 || w.cfg.LastUsedCache == nil {
+		return true
+	}
+	ok, err := w.cfg.LastUsedCache.Claim(ctx, key, w.cfg.LastUsedInterval)
+	if err != nil {
+		log.Err(err).Str("key", key).Msg("Failed to check last used cache")
+		return true
+	}
+	return ok
+}
+
+// writeBatch inserts the audit entries in one statement and queues the
+// last-used updates for writeLastUsed
+func (w *tokenUsageWriter) writeBatch(ctx context.Context, batch []tokenUsage) {
+	audit := repository.CreateAPITokenAuditLogsParams{}
+	for _, usage := range batch {
+		if usage.UpdateLastUsed {
+			w.queueLastUsed(usage)
+		}
+		if usage.Action == "" {
+			continue
+		}
+		audit.TokenIds = append(audit.TokenIds, usage.TokenID)
+		audit.Actions = append(audit.Actions, usage.Action)
+		audit.IpAddresses = append(audit.IpAddresses, usage.IPAddress)
+		audit.UserAgents = append(audit.UserAgents, usage.UserAgent)
+		audit.AdditionalData = append(audit.AdditionalData, string(usage.AdditionalData))
+		audit.Timestamps = append(audit.Timestamps, usage.At)
+	}
+	if len(audit.TokenIds) == 0 {
+		return
+	}
+
+	if _, err := w.store.CreateAPITokenAuditLogs(ctx, audit); err != nil {
+		// A token deleted since the request fails the whole statement, retry
+		// one by one to keep the other entries
+		log.Err(err).Int("entries", len(audit.TokenIds)).Msg("Failed to write token audit batch, retrying entries individually")
+		for _, usage := range batch {
+			if usage.Action == "" {
+				continue
+			}
+			usage.UpdateLastUsed = false
+			w.write(ctx, usage)
+		}
+	}
+}
+
+// queueLastUsed keeps the latest last-used update of the token and its
+// application
+func (w *tokenUsageWriter) queueLastUsed(usage tokenUsage) {
+	if usage.TokenID != uuid.Nil {
+		if latest, ok := w.pendingTokens[usage.TokenID]; !ok || usage.At.After(latest.At) {
+			w.pendingTokens[usage.TokenID] = usage
+		}
+	}
+	if latest, ok := w.pendingApps[usage.ClientApplicationID]; !ok || usage.At.After(latest) {
+		w.pendingApps[usage.ClientApplicationID] = usage.At
+	}
+}
+
+// writeLastUsed applies the queued last-used updates of the tokens and
+// applications not written within the interval, or all of them when forced.
+// A row busy with requests is thus written once per interval instead of once
+// per flush, and ends up with the time of its latest use.
+func (w *tokenUsageWriter) writeLastUsed(ctx context.Context, now time.Time, force bool) {
+	due := func(written map[uuid.UUID]time.Time, id uuid.UUID) bool {
+		at, ok := written[id]
+		return force || !ok || now.Sub(at) >= w.cfg.LastUsedInterval
+	}
+
+	tokens := repository.UpdateAPITokensLastUsedParams{}
+	for id, usage := range w.pendingTokens {
+		if !due(w.writtenTokens, id) {
+			continue
+		}
+		delete(w.pendingTokens, id)
+		w.writtenTokens[id] = now
+		if !force && !w.claimLastUsed(ctx, "api_token:"+id.String()) {
+			continue
+		}
+		tokens.Ids = append(tokens.Ids, id)
+		tokens.UsedAt = append(tokens.UsedAt, usage.At)
+		tokens.IpAddresses = append(tokens.IpAddresses, usage.IPAddress)
+	}
+	if len(tokens.Ids) > 0 {
+		if err := w.store.UpdateAPITokensLastUsed(ctx, tokens); err != nil {
+			log.Err(err).Int("tokens", len(tokens.Ids)).Msg("Failed to update token last used timestamps")
+		}
+	}
+
+	apps := repository.UpdateClientApplicationsLastUsedParams{}
+	for id, at := range w.pendingApps {
+		if !due(w.writtenApps, id) {
+			continue
+		}
+		delete(w.pendingApps, id)
+		w.writtenApps[id] = now
+		if !force && !w.claimLastUsed(ctx, "client_application:"+id.String()) {
+			continue
+		}
+		apps.Ids = append(apps.Ids, id)
+		apps.UsedAt = append(apps.UsedAt, at)
+	}
+	if len(apps.Ids) > 0 {
+		if err := w.store.UpdateClientApplicationsLastUsed(ctx, apps); err != nil {
+			log.Err(err).Int("applications", len(apps.Ids)).Msg("Failed to update client application last used timestamps")
+		}
+	}
+
+	// An expired write time no longer delays anything
+	for _, written := range []map[uuid.UUID]time.Time{w.writtenTokens, w.writtenApps} {
+		for id, at := range written {
+			if now.Sub(at) >= w.cfg.LastUsedInterval {
+				delete(written, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Reasons of an authentication failure
+const (
+	AuthFailureInvalidToken        = "invalid_token"
+	AuthFailureExpiredToken        = "expired_token"
+	AuthFailureRevokedToken        = "revoked_token"
+	AuthFailureInactiveApplication = "inactive_application"
+	AuthFailureIPNotAllowed        = "ip_not_allowed"
+	AuthFailureInvalidSignature    = "invalid_signature"
+	AuthFailureInvalidAccessToken  = "invalid_access_token"
+	// AuthFailureSessionRejected covers unknown and expired sessions alike:
+	// the auth provider does not tell them apart
+	AuthFailureSessionRejected = "session_rejected"
+)
+
+const (
+	DefaultAuthFailureFlushInterval = time.Minute
+	DefaultAuthFailureRetention     = 90 * 24 * time.Hour
+	// MaxAuthFailureSummaryRange bounds the range of a summary request
+	MaxAuthFailureSummaryRange = 31 * 24 * time.Hour
+)
+
+// ErrInvalidAuthFailureRange is returned for a summary range that is empty or
+// longer than MaxAuthFailureSummaryRange
+var ErrInvalidAuthFailureRange = errors.New("the range must end after it starts and span at most 31 days")
+
+// AuthFailureConfig configures the persisted failure counts.
+//
+// Environment:
+//   - AUTH_FAILURE_FLUSH_INTERVAL: how often the counts are written (default 1m)
+//   - AUTH_FAILURE_RETENTION: how long the hourly counts are kept (default 2160h)
+type AuthFailureConfig struct {
+	FlushInterval time.Duration
+	Retention     time.Duration
+}
+
+func AuthFailureConfigFromEnv() AuthFailureConfig {
+	cfg := AuthFailureConfig{
+		FlushInterval: DefaultAuthFailureFlushInterval,
+		Retention:     DefaultAuthFailureRetention,
+	}
+	if v := os.Getenv("AUTH_FAILURE_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.FlushInterval = d
+		} else {
+			log.Warn().Str("AUTH_FAILURE_FLUSH_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("AUTH_FAILURE_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Retention = d
+		} else {
+			log.Warn().Str("AUTH_FAILURE_RETENTION", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+type authFailureKey struct {
+	tenantID string
+	bucket   time.Time
+	reason   string
+}
+
+// AuthFailureService counts the authentication failures of each tenant. The
+// counts are aggregated in memory and written every flush interval, so a burst
+// of failures costs one upsert per tenant, hour and reason.
+type AuthFailureService struct {
+	store *db.Store
+
+	mu      sync.Mutex
+	pending map[authFailureKey]int64
+}
+
+func NewAuthFailureService(store *db.Store) *AuthFailureService {
+	return &AuthFailureService{store: store, pending: map[authFailureKey]int64{}}
+}
+
+var (
+	authFailuresMu sync.RWMutex
+	authFailures   *AuthFailureService
+
+	authFailureCounterOnce sync.Once
+	authFailureCounter     metric.Int64Counter
+)
+
+// Start makes s the recorder of the failures and writes the counts every
+// interval until ctx is done, then a last time
+func (s *AuthFailureService) Start(ctx context.Context, cfg AuthFailureConfig) {
+	authFailuresMu.Lock()
+	authFailures = s
+	authFailuresMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(cfg.FlushInterval)
+		defer ticker.Stop()
+		var lastPurge time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				s.Flush(context.Background())
+				return
+			case <-ticker.C:
+			}
+			s.Flush(ctx)
+			if time.Since(lastPurge) >= time.Hour {
+				lastPurge = time.Now()
+				if _, err := s.store.DeleteAuthFailureCountsBefore(ctx, time.Now().Add(-cfg.Retention)); err != nil {
+					log.Err(err).Msg("Failed to purge auth failure counts")
+				}
+			}
+		}
+	}()
+}
+
+// RecordAuthFailure counts a failure in the auth.failures metric and, once an
+// AuthFailureService is started, in the tenant summary. tenantID is empty on
+// the root domain.
+func RecordAuthFailure(ctx context.Context, tenantID, reason string) {
+	authFailureCounterOnce.Do(func() {
+		var err error
+		authFailureCounter, err = otel.Meter("ctoup.com/coreapp/pkg/shared/service").Int64Counter("auth.failures",
+			metric.WithDescription("Failed authentications by tenant and reason"))
+		if err != nil {
+			log.Err(err).Msg("Failed to create auth failure metric")
+		}
+	})
+	if authFailureCounter != nil {
+		authFailureCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("tenant_id", tenantID), attribute.String("reason", reason)))
+	}
+
+	authFailuresMu.RLock()
+	s := authFailures
+	authFailuresMu.RUnlock()
+	if s != nil {
+		s.add(tenantID, reason, time.Now())
+	}
+}
+
+func (s *AuthFailureService) add(tenantID, reason string, at time.Time) {
+	key := authFailureKey{tenantID: tenantID, bucket: at.UTC().Truncate(time.Hour), reason: reason}
+	s.mu.Lock()
+	s.pending[key]++
+	s.mu.Unlock()
+}
+
+// Flush writes the pending counts. On failure they are merged back and
+// retried with the next flush.
+func (s *AuthFailureService) Flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[authFailureKey]int64{}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	params := repository.IncrementAuthFailureCountsParams{}
+	for key, count := range pending {
+		params.TenantIds = append(params.TenantIds, key.tenantID)
+		params.Buckets = append(params.Buckets, key.bucket)
+		params.Reasons = append(params.Reasons, key.reason)
+		params.Counts = append(params.Counts, count)
+	}
+	if err := s.store.IncrementAuthFailureCounts(ctx, params); err != nil {
+		log.Err(err).Int("counts", len(pending)).Msg("Failed to write auth failure counts")
+		s.mu.Lock()
+		for key, count := range pending {
+			s.pending[key] += count
+		}
+		s.mu.Unlock()
+	}
+}
+
+// AuthFailureSummary are the failures of a tenant over a range, by reason and
+// by hour
+type AuthFailureSummary struct {
+	From   time.Time
+	To     time.Time
+	Total  int64
+	Totals map[string]int64
+	Hourly []repository.CoreAuthFailureCount
+}
+
+// GetAuthFailureSummary summarizes the written counts of a tenant; the
+// failures of the last flush interval are not included yet
+func (s *AuthFailureService) GetAuthFailureSummary(ctx context.Context, tenantID string, from, to time.Time) (AuthFailureSummary, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if !to.After(from) || to.Sub(from) > MaxAuthFailureSummaryRange {
+		return AuthFailureSummary{}, ErrInvalidAuthFailureRange
+	}
+	counts, err := s.store.ListAuthFailureCounts(ctx, repository.ListAuthFailureCountsParams{
+		TenantID: tenantID,
+		From:     from.UTC().Truncate(time.Hour),
+		To:       to,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to list auth failure counts")
+		return AuthFailureSummary{}, fmt.Errorf("service.GetAuthFailureSummary: %w", err)
+	}
+	summary := AuthFailureSummary{From: from, To: to, Totals: map[string]int64{}, Hourly: counts}
+	for _, count := range counts {
+		summary.Total += count.Count
+		summary.Totals[count.Reason] += count.Count
+	}
+	return summary, nil
+}
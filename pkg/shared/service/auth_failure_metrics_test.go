@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthFailureServiceAggregatesByHour(t *testing.T) {
+	s := NewAuthFailureService(nil)
+	at := time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC)
+
+	s.add("tenant-a", AuthFailureInvalidToken, at)
+	s.add("tenant-a", AuthFailureInvalidToken, at.Add(30*time.Minute))
+	s.add("tenant-a", AuthFailureInvalidToken, at.Add(time.Hour))
+	s.add("tenant-b", AuthFailureInvalidToken, at)
+	s.add("tenant-a", AuthFailureIPNotAllowed, at)
+
+	hour := at.Truncate(time.Hour)
+	require.Len(t, s.pending, 4)
+	require.Equal(t, int64(2), s.pending[authFailureKey{tenantID: "tenant-a", bucket: hour, reason: AuthFailureInvalidToken}])
+	require.Equal(t, int64(1), s.pending[authFailureKey{tenantID: "tenant-a", bucket: hour.Add(time.Hour), reason: AuthFailureInvalidToken}])
+}
+
+func TestGetAuthFailureSummaryRange(t *testing.T) {
+	s := NewAuthFailureService(nil)
+	to := time.Now()
+
+	_, err := s.GetAuthFailureSummary(context.Background(), "tenant-a", to, to)
+	require.ErrorIs(t, err, ErrInvalidAuthFailureRange)
+
+	_, err = s.GetAuthFailureSummary(context.Background(), "tenant-a", to.Add(-MaxAuthFailureSummaryRange-time.Hour), to)
+	require.ErrorIs(t, err, ErrInvalidAuthFailureRange)
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// Subjects of a lockout
+const (
+	LockoutSubjectUser = "user"
+	LockoutSubjectIP   = "ip"
+)
+
+// Actions on a user reaching the threshold. Both lock the user for the
+// cooldown; disable also disables the account in the auth provider until an
+// administrator unlocks it.
+const (
+	LockoutActionCooldown = "cooldown"
+	LockoutActionDisable  = "disable"
+)
+
+// Reasons of the verification failures counted for a user, on top of the
+// authentication failures
+const (
+	LockoutReasonInvalidMFACode  = "invalid_mfa_code"
+	LockoutReasonInvalidMFAToken = "invalid_mfa_token"
+)
+
+const (
+	DefaultLockoutUserMaxFailures = 10
+	DefaultLockoutIPMaxFailures   = 50
+	DefaultLockoutWindow          = 15 * time.Minute
+	DefaultLockoutCooldown        = 15 * time.Minute
+	DefaultLockoutPageSize        = 50
+	MaxLockoutPageSize            = 500
+	// lockoutRecheck is how long a lock known to this instance is trusted
+	// before it is read again, so an unlock on another instance applies
+	lockoutRecheck = 30 * time.Second
+)
+
+var (
+	// ErrLockoutNotFound is returned when unlocking a subject that is not locked
+	ErrLockoutNotFound = errors.New("the subject is not locked")
+	// ErrInvalidLockoutSubject is returned for an unknown subject type
+	ErrInvalidLockoutSubject = errors.New("the subject type must be user or ip")
+)
+
+// LockoutConfig configures the brute-force protection. A threshold of 0
+// disables the lockout of its subjects.
+//
+// Environment:
+//   - AUTH_LOCKOUT_USER_MAX_FAILURES: failures of a user within the window before locking (default 10)
+//   - AUTH_LOCKOUT_IP_MAX_FAILURES: failures from a client IP within the window before locking (default 50)
+//   - AUTH_LOCKOUT_WINDOW: window over which the failures are counted (default 15m)
+//   - AUTH_LOCKOUT_COOLDOWN: lock duration (default 15m)
+//   - AUTH_LOCKOUT_USER_ACTION: cooldown or disable (default cooldown)
+type LockoutConfig struct {
+	UserMaxFailures int
+	IPMaxFailures   int
+	Window          time.Duration
+	Cooldown        time.Duration
+	UserAction      string
+}
+
+func LockoutConfigFromEnv() LockoutConfig {
+	cfg := LockoutConfig{
+		UserMaxFailures: DefaultLockoutUserMaxFailures,
+		IPMaxFailures:   DefaultLockoutIPMaxFailures,
+		Window:          DefaultLockoutWindow,
+		Cooldown:        DefaultLockoutCooldown,
+		UserAction:      LockoutActionCooldown,
+	}
+	if v := os.Getenv("AUTH_LOCKOUT_USER_MAX_FAILURES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.UserMaxFailures = n
+		} else {
+			log.Warn().Str("AUTH_LOCKOUT_USER_MAX_FAILURES", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("AUTH_LOCKOUT_IP_MAX_FAILURES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.IPMaxFailures = n
+		} else {
+			log.Warn().Str("AUTH_LOCKOUT_IP_MAX_FAILURES", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("AUTH_LOCKOUT_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Window = d
+		} else {
+			log.Warn().Str("AUTH_LOCKOUT_WINDOW", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("AUTH_LOCKOUT_COOLDOWN"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Cooldown = d
+		} else {
+			log.Warn().Str("AUTH_LOCKOUT_COOLDOWN", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("AUTH_LOCKOUT_USER_ACTION"); v != "" {
+		if v == LockoutActionCooldown || v == LockoutActionDisable {
+			cfg.UserAction = v
+		} else {
+			log.Warn().Str("AUTH_LOCKOUT_USER_ACTION", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// LockoutStatus is the lock of a subject. Until is zero for an account that
+// stays disabled until unlocked.
+type LockoutStatus struct {
+	Until    time.Time
+	Disabled bool
+}
+
+// RetryAfter is how long the subject stays locked, 0 when only an
+// administrator can unlock it
+func (l LockoutStatus) RetryAfter(now time.Time) time.Duration {
+	if l.Disabled || !l.Until.After(now) {
+		return 0
+	}
+	return l.Until.Sub(now)
+}
+
+type lockoutKey struct {
+	subjectType string
+	subject     string
+}
+
+type lockoutEntry struct {
+	status    LockoutStatus
+	checkedAt time.Time
+}
+
+// LockoutService locks the users and the client IPs that fail to
+// authenticate or verify too often. The failures are counted in the database,
+// shared by the instances; each instance keeps the locks it learned of in
+// memory, so a request of a subject that is not locked costs no query.
+type LockoutService struct {
+	store        *db.Store
+	authProvider auth.AuthProvider
+	cfg          LockoutConfig
+
+	mu     sync.Mutex
+	locked map[lockoutKey]lockoutEntry
+}
+
+func NewLockoutService(store *db.Store, authProvider auth.AuthProvider, cfg LockoutConfig) *LockoutService {
+	return &LockoutService{store: store, authProvider: authProvider, cfg: cfg, locked: map[lockoutKey]lockoutEntry{}}
+}
+
+var (
+	lockoutsMu sync.RWMutex
+	lockouts   *LockoutService
+)
+
+func currentLockoutService() *LockoutService {
+	lockoutsMu.RLock()
+	defer lockoutsMu.RUnlock()
+	return lockouts
+}
+
+// Start makes s the recorder of the failures and forgets the stale counts
+// every hour until ctx is done
+func (s *LockoutService) Start(ctx context.Context) {
+	lockoutsMu.Lock()
+	lockouts = s
+	lockoutsMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// A count older than the window restarts anyway
+			if _, err := s.store.DeleteStaleAuthLockouts(ctx, time.Now().Add(-s.cfg.Window)); err != nil {
+				log.Err(err).Msg("Failed to purge auth lockouts")
+			}
+		}
+	}()
+}
+
+// RecordLockoutFailure counts a failure of the subject once a LockoutService
+// is started, and locks it at the threshold. tenantID is the tenant of the
+// request, empty on the root domain.
+func RecordLockoutFailure(ctx context.Context, subjectType, subject, tenantID, reason string) {
+	if s := currentLockoutService(); s != nil {
+		s.RecordFailure(ctx, subjectType, subject, tenantID, reason)
+	}
+}
+
+// CheckLockout returns the lock of the subject, if the started LockoutService
+// knows of one
+func CheckLockout(ctx context.Context, subjectType, subject string) (LockoutStatus, bool) {
+	if s := currentLockoutService(); s != nil {
+		return s.Check(ctx, subjectType, subject)
+	}
+	return LockoutStatus{}, false
+}
+
+// requestTenantID returns the tenant of the request ctx belongs to, if any
+func requestTenantID(ctx context.Context) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.GetString(auth.AUTH_TENANT_ID_KEY)
+	}
+	return ""
+}
+
+func (s *LockoutService) maxFailures(subjectType string) int {
+	if subjectType == LockoutSubjectUser {
+		return s.cfg.UserMaxFailures
+	}
+	return s.cfg.IPMaxFailures
+}
+
+func lockoutStatusOf(row repository.CoreAuthLockout) LockoutStatus {
+	status := LockoutStatus{Disabled: row.Disabled}
+	if row.LockedUntil.Valid {
+		status.Until = row.LockedUntil.Time
+	}
+	return status
+}
+
+func (l LockoutStatus) active(now time.Time) bool {
+	return l.Disabled || l.Until.After(now)
+}
+
+// remember keeps the lock of the subject in memory, or forgets it
+func (s *LockoutService) remember(key lockoutKey, status LockoutStatus, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status.active(now) {
+		s.locked[key] = lockoutEntry{status: status, checkedAt: now}
+	} else {
+		delete(s.locked, key)
+	}
+}
+
+// RecordFailure counts a failure of the subject and locks it at the
+// threshold
+func (s *LockoutService) RecordFailure(ctx context.Context, subjectType, subject, tenantID, reason string) {
+	logger := util.GetLoggerFromCtx(ctx)
+	threshold := s.maxFailures(subjectType)
+	if threshold <= 0 || subject == "" {
+		return
+	}
+	now := time.Now()
+	key := lockoutKey{subjectType: subjectType, subject: subject}
+	row, err := s.store.RecordAuthLockoutFailure(ctx, repository.RecordAuthLockoutFailureParams{
+		SubjectType: subjectType,
+		Subject:     subject,
+		TenantID:    tenantID,
+		Reason:      reason,
+		WindowStart: now.Add(-s.cfg.Window),
+	})
+	if err != nil {
+		logger.Err(err).Str("subject_type", subjectType).Msg("Failed to record auth lockout failure")
+		return
+	}
+	if int(row.Failures) < threshold {
+		// Locked by another instance, learned here
+		s.remember(key, lockoutStatusOf(row), now)
+		return
+	}
+
+	disable := subjectType == LockoutSubjectUser && s.cfg.UserAction == LockoutActionDisable
+	row, err = s.store.LockAuthSubject(ctx, repository.LockAuthSubjectParams{
+		LockedUntil: now.Add(s.cfg.Cooldown),
+		Disabled:    disable,
+		SubjectType: subjectType,
+		Subject:     subject,
+	})
+	if err != nil {
+		logger.Err(err).Str("subject_type", subjectType).Msg("Failed to lock auth subject")
+		return
+	}
+	s.remember(key, lockoutStatusOf(row), now)
+	logger.Warn().Str("subject_type", subjectType).Str("subject", subject).Str("tenant_id", tenantID).
+		Str("reason", reason).Bool("disabled", disable).Msg("Locked out after too many failures")
+
+	if subjectType != LockoutSubjectUser {
+		return
+	}
+	if disable {
+		s.setUserDisabled(ctx, tenantID, subject, true)
+	}
+	if tenantID != "" {
+		if err := recordUserActivity(ctx, s.store, UserActivity{
+			TenantID:  tenantID,
+			UserID:    subject,
+			Category:  ActivityCategoryLogin,
+			EventType: "account_locked",
+			Data:      map[string]interface{}{"reason": reason, "disabled": disable},
+		}); err != nil {
+			logger.Err(err).Str("user_id", subject).Msg("Failed to record account lock")
+		}
+	}
+}
+
+// Check returns the lock of the subject known to this instance. A lock is
+// read again after lockoutRecheck, to apply the unlocks of other instances.
+func (s *LockoutService) Check(ctx context.Context, subjectType, subject string) (LockoutStatus, bool) {
+	now := time.Now()
+	key := lockoutKey{subjectType: subjectType, subject: subject}
+	s.mu.Lock()
+	entry, found := s.locked[key]
+	s.mu.Unlock()
+	if !found {
+		return LockoutStatus{}, false
+	}
+	if now.Sub(entry.checkedAt) > lockoutRecheck {
+		row, err := s.store.GetAuthLockout(ctx, repository.GetAuthLockoutParams{SubjectType: subjectType, Subject: subject})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			entry.status = LockoutStatus{}
+		case err != nil:
+			// The lock holds until it can be read
+			logger := util.GetLoggerFromCtx(ctx)
+			logger.Err(err).Str("subject_type", subjectType).Msg("Failed to read auth lockout")
+		default:
+			entry.status = lockoutStatusOf(row)
+		}
+		s.remember(key, entry.status, now)
+	}
+	if !entry.status.active(now) {
+		s.remember(key, LockoutStatus{}, now)
+		return LockoutStatus{}, false
+	}
+	return entry.status, true
+}
+
+// Unlock lifts the lock of the subject and forgets its failures. A user
+// disabled by the lockout is enabled again.
+func (s *LockoutService) Unlock(ctx context.Context, subjectType, subject string) (repository.CoreAuthLockout, error) {
+	if subjectType != LockoutSubjectUser && subjectType != LockoutSubjectIP {
+		return repository.CoreAuthLockout{}, ErrInvalidLockoutSubject
+	}
+	row, err := s.store.DeleteAuthLockout(ctx, repository.DeleteAuthLockoutParams{SubjectType: subjectType, Subject: subject})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreAuthLockout{}, ErrLockoutNotFound
+	}
+	if err != nil {
+		return repository.CoreAuthLockout{}, fmt.Errorf("service.Unlock: %w", err)
+	}
+	key := lockoutKey{subjectType: subjectType, subject: subject}
+	s.remember(key, LockoutStatus{}, time.Now())
+	// The started service answers the middleware of this instance
+	if current := currentLockoutService(); current != nil && current != s {
+		current.remember(key, LockoutStatus{}, time.Now())
+	}
+	if row.Disabled && subjectType == LockoutSubjectUser {
+		s.setUserDisabled(ctx, row.TenantID, subject, false)
+	}
+	return row, nil
+}
+
+// ListLockouts lists the locked subjects, optionally of one type or tenant
+func (s *LockoutService) ListLockouts(ctx context.Context, subjectType, tenantID *string, limit, offset int32) ([]repository.CoreAuthLockout, error) {
+	if limit <= 0 {
+		limit = DefaultLockoutPageSize
+	}
+	params := repository.ListAuthLockoutsParams{
+		Limit:  min(limit, MaxLockoutPageSize),
+		Offset: max(offset, 0),
+	}
+	if subjectType != nil {
+		params.SubjectType = pgtype.Text{String: *subjectType, Valid: true}
+	}
+	if tenantID != nil {
+		params.TenantID = pgtype.Text{String: *tenantID, Valid: true}
+	}
+	rows, err := s.store.ListAuthLockouts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListLockouts: %w", err)
+	}
+	return rows, nil
+}
+
+// setUserDisabled disables or enables the account in the auth provider. A
+// disabled account is also signed out everywhere. Failures are only logged:
+// the lock holds in the middleware either way.
+func (s *LockoutService) setUserDisabled(ctx context.Context, tenantID, userID string, disabled bool) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if s.authProvider == nil {
+		return
+	}
+	authClient, err := s.authProvider.GetAuthClientForTenant(ctx, tenantID)
+	if err != nil {
+		logger.Err(err).Str("user_id", userID).Msg("Failed to get auth client for lockout")
+		return
+	}
+	if _, err := authClient.UpdateUser(ctx, userID, (&auth.UserToUpdate{}).Disabled(disabled)); err != nil {
+		logger.Err(err).Str("user_id", userID).Bool("disabled", disabled).Msg("Failed to update locked out account")
+		return
+	}
+	recordUserAccountState(ctx, s.store, userID, pgtype.Bool{Bool: disabled, Valid: true}, pgtype.Bool{})
+	if disabled {
+		if err := authClient.RevokeUserSessions(ctx, userID); err != nil {
+			logger.Err(err).Str("user_id", userID).Msg("Failed to revoke sessions of locked out account")
+		}
+		if reporter, ok := s.authProvider.(auth.HealthReporter); ok {
+			reporter.GetHealthMonitor().ForgetUser(userID)
+		}
+	}
+}
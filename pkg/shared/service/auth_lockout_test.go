@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutConfigFromEnv(t *testing.T) {
+	t.Setenv("AUTH_LOCKOUT_USER_MAX_FAILURES", "5")
+	t.Setenv("AUTH_LOCKOUT_IP_MAX_FAILURES", "-1")
+	t.Setenv("AUTH_LOCKOUT_WINDOW", "10m")
+	t.Setenv("AUTH_LOCKOUT_COOLDOWN", "soon")
+	t.Setenv("AUTH_LOCKOUT_USER_ACTION", "disable")
+
+	cfg := LockoutConfigFromEnv()
+	require.Equal(t, 5, cfg.UserMaxFailures)
+	require.Equal(t, DefaultLockoutIPMaxFailures, cfg.IPMaxFailures)
+	require.Equal(t, 10*time.Minute, cfg.Window)
+	require.Equal(t, DefaultLockoutCooldown, cfg.Cooldown)
+	require.Equal(t, LockoutActionDisable, cfg.UserAction)
+}
+
+func TestLockoutStatusRetryAfter(t *testing.T) {
+	now := time.Now()
+	require.Equal(t, time.Minute, LockoutStatus{Until: now.Add(time.Minute)}.RetryAfter(now))
+	require.Zero(t, LockoutStatus{Until: now.Add(-time.Minute)}.RetryAfter(now))
+	require.Zero(t, LockoutStatus{Until: now.Add(time.Minute), Disabled: true}.RetryAfter(now))
+}
+
+func TestLockoutCheckRemembersLocks(t *testing.T) {
+	service := NewLockoutService(nil, nil, LockoutConfig{})
+	ctx := context.Background()
+	now := time.Now()
+
+	_, locked := service.Check(ctx, LockoutSubjectIP, "10.0.0.1")
+	require.False(t, locked)
+
+	service.remember(lockoutKey{LockoutSubjectIP, "10.0.0.1"}, LockoutStatus{Until: now.Add(time.Minute)}, now)
+	status, locked := service.Check(ctx, LockoutSubjectIP, "10.0.0.1")
+	require.True(t, locked)
+	require.True(t, status.Until.After(now))
+	_, locked = service.Check(ctx, LockoutSubjectUser, "10.0.0.1")
+	require.False(t, locked)
+
+	// An expired cooldown is forgotten
+	service.locked[lockoutKey{LockoutSubjectUser, "u1"}] = lockoutEntry{status: LockoutStatus{Until: now.Add(-time.Second)}, checkedAt: now}
+	_, locked = service.Check(ctx, LockoutSubjectUser, "u1")
+	require.False(t, locked)
+	require.NotContains(t, service.locked, lockoutKey{LockoutSubjectUser, "u1"})
+}
+
+func TestLockoutUnlockRejectsUnknownSubject(t *testing.T) {
+	_, err := NewLockoutService(nil, nil, LockoutConfig{}).Unlock(context.Background(), "device", "x")
+	require.ErrorIs(t, err, ErrInvalidLockoutSubject)
+}
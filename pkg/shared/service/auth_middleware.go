@@ -1,14 +1,18 @@
 package service
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"ctoup.com/coreapp/api/openapi/core"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	"ctoup.com/coreapp/pkg/shared/auth/kratos"
 	"ctoup.com/coreapp/pkg/shared/util"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,8 +23,13 @@ const (
 
 // AuthMiddleware combines both API token and provider-based authentication
 type AuthMiddleware struct {
-	authProvider auth.AuthProvider
-	apiToken     *ClientApplicationService
+	authProvider  auth.AuthProvider
+	apiToken      *ClientApplicationService
+	mfa           *MFAService
+	impersonation *ImpersonationService
+	lastLogin     *LastLoginService
+	groups        *GroupService
+	roles         *RoleService
 }
 
 // NewAuthMiddleware creates a new combined authentication middleware
@@ -28,10 +37,18 @@ func NewAuthMiddleware(
 	authProvider auth.AuthProvider,
 	apiToken *ClientApplicationService,
 ) *AuthMiddleware {
-	return &AuthMiddleware{
+	am := &AuthMiddleware{
 		authProvider: authProvider,
 		apiToken:     apiToken,
 	}
+	if apiToken != nil {
+		am.mfa = NewMFAService(apiToken.store, MFAConfigFromEnv())
+		am.impersonation = NewImpersonationService(apiToken.store)
+		am.lastLogin = NewLastLoginService(apiToken.store, NewLastUsedCacheFromEnv())
+		am.groups = NewGroupService(apiToken.store)
+		am.roles = NewRoleService(apiToken.store)
+	}
+	return am
 }
 
 // MiddlewareFunc implements OR authentication logic
@@ -43,6 +60,11 @@ func (am *AuthMiddleware) MiddlewareFunc() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		if !checkLockout(c, LockoutSubjectIP, c.ClientIP()) {
+			c.Abort()
+			return
+		}
+		failureReason := AuthFailureSessionRejected
 
 		// Check for API token first
 		// API token auth is only valid for non-user-management endpoints
@@ -51,33 +73,109 @@ func (am *AuthMiddleware) MiddlewareFunc() gin.HandlerFunc {
 				!strings.HasPrefix(c.Request.URL.Path, "/admin-api") &&
 				!strings.HasPrefix(c.Request.URL.Path, "/superadmin-api")) {
 
+			// HMAC signed request. The signature names its application, so a
+			// failed verification is final.
+			if IsSignedRequest(c) {
+				principal, err := am.apiToken.VerifySignedRequest(c)
+				if err != nil {
+					if !errors.Is(err, ErrRequestSigningNotConfigured) {
+						RecordAuthFailure(c, c.GetString(auth.AUTH_TENANT_ID_KEY), AuthFailureInvalidSignature)
+						RecordLockoutFailure(c, LockoutSubjectIP, c.ClientIP(), c.GetString(auth.AUTH_TENANT_ID_KEY), AuthFailureInvalidSignature)
+					}
+					abortSignedRequest(c, err)
+					return
+				}
+				// The signing key is only valid on its application's tenant
+				if principal.TenantID != c.GetString(auth.AUTH_TENANT_ID_KEY) {
+					c.JSON(http.StatusForbidden, gin.H{
+						"status":  http.StatusForbidden,
+						"message": "Signed request is not valid for this tenant",
+					})
+					c.Abort()
+					return
+				}
+				setClientCredentialsPrincipal(c, principal)
+				c.Set(auth.AUTH_USER_ID, principal.UserID())
+				c.Next()
+				return
+			}
+
 			// X-Api-Key header based authentication can only perform for non-user-management endpoints
 			token := c.GetHeader(XApiKeyHeader)
 			if token != "" {
 				tokenRow, err := am.apiToken.VerifyAPIToken(c, token)
 				if err == nil {
+					if !am.apiToken.CheckAPITokenRateLimit(c, tokenRow) {
+						c.Abort()
+						return
+					}
 					// API token is valid, store info and continue
 					c.Set("api_token", tokenRow)
 					c.Set("api_token_scopes", tokenRow.Scopes)
-					c.Set(auth.AUTH_USER_ID, tokenRow.CreatedBy)
+					c.Set(auth.AUTH_USER_ID, requestUserID(tokenRow.ServiceAccountID, tokenRow.CreatedBy))
 					c.Next()
 					return
 				} else {
 					// API token is invalid
+					RecordLockoutFailure(c, LockoutSubjectIP, c.ClientIP(), c.GetString(auth.AUTH_TENANT_ID_KEY), AuthFailureInvalidToken)
+					message := "Invalid API token"
+					if errors.Is(err, ErrAPITokenIPNotAllowed) {
+						message = err.Error()
+					}
 					c.JSON(http.StatusForbidden, gin.H{
 						"status":  http.StatusForbidden,
-						"message": "Invalid API token",
+						"message": message,
 					})
 					c.Abort()
 					return
 				}
 			}
+
+			// OAuth2 client credentials access token. Anything else in the
+			// Authorization header is left to the auth provider.
+			if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && IsClientCredentialsToken(bearer) {
+				if principal, err := am.apiToken.VerifyClientCredentialsToken(c, bearer); err == nil {
+					// The token is only valid on its application's tenant
+					if principal.TenantID != c.GetString(auth.AUTH_TENANT_ID_KEY) {
+						c.JSON(http.StatusForbidden, gin.H{
+							"status":  http.StatusForbidden,
+							"message": "Access token is not valid for this tenant",
+						})
+						c.Abort()
+						return
+					}
+					if !am.apiToken.CheckClientCredentialsLimits(c, principal) {
+						c.Abort()
+						return
+					}
+					setClientCredentialsPrincipal(c, principal)
+					c.Set(auth.AUTH_USER_ID, principal.UserID())
+					c.Next()
+					return
+				}
+				// The provider rejects it as well, the failure is counted once
+				failureReason = AuthFailureInvalidAccessToken
+			}
 		}
 
 		// Use provider-based authentication
 		user, err := am.authProvider.VerifyToken(c)
 		if err != nil {
 			log.Err(err).Str("provider", am.authProvider.GetProviderName()).Msg("authentication failed")
+			if !auth.IsProviderUnavailable(err) && sentCredentials(c) {
+				RecordAuthFailure(c, c.GetString(auth.AUTH_TENANT_ID_KEY), failureReason)
+				RecordLockoutFailure(c, LockoutSubjectIP, c.ClientIP(), c.GetString(auth.AUTH_TENANT_ID_KEY), failureReason)
+			}
+			// The session was not rejected: tell clients to retry rather than
+			// sign the user out
+			if auth.IsProviderUnavailable(err) {
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"status":  http.StatusServiceUnavailable,
+					"message": http.StatusText(http.StatusServiceUnavailable),
+				})
+				c.Abort()
+				return
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"status":  http.StatusUnauthorized,
 				"message": http.StatusText(http.StatusUnauthorized),
@@ -86,6 +184,23 @@ func (am *AuthMiddleware) MiddlewareFunc() gin.HandlerFunc {
 			return
 		}
 
+		// The session could not be verified with the provider, a recent
+		// verification of it was reused
+		if user.Degraded {
+			c.Header(auth.DegradedHeader, auth.DegradedHeaderGrace)
+		}
+
+		// A locked account is refused even with a valid session
+		if !checkLockout(c, LockoutSubjectUser, user.UserID) {
+			c.Abort()
+			return
+		}
+
+		// The roles granted by the groups of the user in the tenant, and the
+		// permissions granted by their custom roles
+		user = withGroupRoles(c, am.groups, user)
+		user = withRolePermissions(c, am.roles, user)
+
 		// Store authenticated user info in context
 		am.setAuthenticatedUser(c, user)
 
@@ -94,20 +209,75 @@ func (am *AuthMiddleware) MiddlewareFunc() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		// Check the TOTP second factor of the core
+		if !am.checkTOTPRequirements(c, user) {
+			c.Abort()
+			return
+		}
+		// A SUPER_ADMIN acting as a tenant user
+		if token := c.GetHeader(ImpersonationTokenHeader); token != "" {
+			if !am.impersonate(c, user, token) {
+				c.Abort()
+				return
+			}
+		}
 		// Check AAL requirements
 		if !am.checkAALRequirements(c) {
 			c.Abort()
 			return
 		}
+		if am.lastLogin != nil {
+			am.lastLogin.RecordLogin(c, user)
+		}
 		c.Next()
 	}
 }
 
+// sentCredentials reports whether the request carried a session or a token,
+// so that anonymous requests are not counted as authentication failures
+func sentCredentials(c *gin.Context) bool {
+	if c.GetHeader("Authorization") != "" || c.GetHeader("X-Session-Token") != "" {
+		return true
+	}
+	_, err := c.Cookie("ory_kratos_session")
+	return err == nil
+}
+
+// checkLockout writes the error response and returns false when the subject
+// is locked out: 429 for a client IP, 403 for an account
+func checkLockout(c *gin.Context, subjectType, subject string) bool {
+	status, locked := CheckLockout(c, subjectType, subject)
+	if !locked {
+		return true
+	}
+	if retryAfter := status.RetryAfter(time.Now()); retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+	}
+	if subjectType == LockoutSubjectIP {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"status":  http.StatusTooManyRequests,
+			"message": "Too many failed authentications, try again later",
+		})
+		return false
+	}
+	message := "The account is locked after too many failed verifications, try again later"
+	if status.Disabled {
+		message = "The account is locked after too many failed verifications, contact an administrator"
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"id":      auth.ErrorCodeAccountLocked,
+		"message": message,
+	})
+	return false
+}
+
 // setAuthenticatedUser stores user info in gin context
 func (am *AuthMiddleware) setAuthenticatedUser(c *gin.Context, user *auth.AuthenticatedUser) {
 	c.Set(auth.AUTH_EMAIL, user.Email)
 	c.Set(auth.AUTH_USER_ID, user.UserID)
 	c.Set(auth.AUTH_CLAIMS, user.Claims)
+	c.Set(auth.AUTH_PERMISSIONS, user.Permissions)
+	c.Set(auth.AUTH_SESSION_ID, user.SessionID)
 	c.Set(auth.AUTH_IS_RESELLER, user.IsReseller)
 	c.Set(auth.AUTH_IS_ACTING_RESELLER, user.IsActingReseller)
 
@@ -121,10 +291,10 @@ func (am *AuthMiddleware) setAuthenticatedUser(c *gin.Context, user *auth.Authen
 		c.Set(auth.AUTH_TENANT_MEMBERSHIPS, user.TenantMemberships)
 	}
 
-	// Resolve and stash AccessScope for downstream modules. AUTH_CLAIMS must be
-	// set above before the role-check helpers run, since they read claims off
-	// the gin context.
-	isAdmin := auth.IsAdmin(c) || auth.IsCustomerAdmin(c) || auth.IsSuperAdmin(c)
+	// Resolve and stash AccessScope for downstream modules. AUTH_CLAIMS and
+	// AUTH_PERMISSIONS must be set above before the permission check runs,
+	// since it reads them off the gin context.
+	isAdmin := auth.HasPermission(c, auth.OpManageUsers)
 	c.Set(auth.AUTH_ACCESS_SCOPE, auth.AccessScope{
 		TenantID:      user.TenantID,
 		UserID:        user.UserID,
@@ -136,11 +306,13 @@ func (am *AuthMiddleware) setAuthenticatedUser(c *gin.Context, user *auth.Authen
 func (am *AuthMiddleware) checkPermissions(c *gin.Context, user *auth.AuthenticatedUser) bool {
 	claims := user.Claims
 
-	// Only admin users can alter users
+	// Only admin users can alter users, except for the self-service endpoints
+	// every user needs on their own account
 	if strings.HasPrefix(c.Request.URL.Path, "/api/v1/users") &&
-		util.Contains([]string{"POST", "PUT", "PATCH", "DELETE"}, c.Request.Method) {
+		util.Contains([]string{"POST", "PUT", "PATCH", "DELETE"}, c.Request.Method) &&
+		!isSelfServiceUserRoute(c.Request.Method, c.Request.URL.Path) {
 
-		if claims[string(core.SUPERADMIN)] == true || claims[string(core.ADMIN)] == true || claims[string(core.CUSTOMERADMIN)] == true || claims[string(auth.ACTING_RESELLER)] == true {
+		if auth.HasPermission(c, auth.OpManageUsers) {
 			return true
 		}
 		c.JSON(http.StatusForbidden, gin.H{
@@ -186,6 +358,117 @@ func (am *AuthMiddleware) checkPermissions(c *gin.Context, user *auth.Authentica
 	return true
 }
 
+// selfServiceUserRoute is an /api/v1/users endpoint that only acts on the
+// caller's own account. Without a method it covers the path and its
+// sub-paths, with a method only that method on the exact path.
+type selfServiceUserRoute struct {
+	method string
+	path   string
+}
+
+// selfServiceUserRoutes are the routes every user needs on their own account,
+// each self-service /users/me endpoint adds its route here
+var selfServiceUserRoutes = []selfServiceUserRoute{
+	{path: "/api/v1/users/me/email-change"},
+	{path: "/api/v1/users/me/mfa"},
+	{path: "/api/v1/users/me/onboarding"},
+	{path: "/api/v1/users/me/phone-verification"},
+	{path: "/api/v1/users/me/sessions"},
+	// Deletion of the own account, the other /users/me methods stay admin only
+	{method: http.MethodDelete, path: "/api/v1/users/me"},
+}
+
+func isSelfServiceUserRoute(method, path string) bool {
+	for _, route := range selfServiceUserRoutes {
+		if route.method != "" {
+			if method == route.method && path == route.path {
+				return true
+			}
+			continue
+		}
+		if path == route.path || strings.HasPrefix(path, route.path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// impersonate replaces the super admin of the request by the user they
+// impersonate, whose permissions are then checked instead
+func (am *AuthMiddleware) impersonate(c *gin.Context, actor *auth.AuthenticatedUser, token string) bool {
+	if am.impersonation == nil || actor.Claims[string(core.SUPERADMIN)] != true {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": "Only SUPER_ADMIN can impersonate users",
+		})
+		return false
+	}
+	impersonation, ok, err := am.impersonation.VerifyImpersonation(c, actor.UserID, c.GetString(auth.AUTH_TENANT_ID_KEY), token)
+	var user *auth.AuthenticatedUser
+	if err == nil && ok {
+		user, err = am.impersonation.ImpersonatedUser(c, impersonation)
+	}
+	if err != nil && !errors.Is(err, ErrImpersonationNotAllowed) && !errors.Is(err, pgx.ErrNoRows) {
+		log.Err(err).Str("user_id", actor.UserID).Msg("Failed to verify impersonation")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  http.StatusServiceUnavailable,
+			"message": http.StatusText(http.StatusServiceUnavailable),
+		})
+		return false
+	}
+	if !ok || user == nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": "Impersonation token is invalid or expired",
+		})
+		return false
+	}
+
+	user = withGroupRoles(c, am.groups, user)
+	user = withRolePermissions(c, am.roles, user)
+	am.setAuthenticatedUser(c, user)
+	c.Set(auth.AUTH_IMPERSONATOR_ID, actor.UserID)
+	c.Set(auth.AUTH_IMPERSONATION_ID, impersonation.ID)
+	c.Header(ImpersonatedByHeader, actor.UserID)
+	log.Info().Str("impersonation_id", impersonation.ID).Str("actor_id", actor.UserID).
+		Str("tenant_id", impersonation.TenantID).Str("user_id", impersonation.UserID).
+		Str("method", c.Request.Method).Str("path", c.Request.URL.Path).Msg("Impersonated request")
+	return am.checkPermissions(c, user)
+}
+
+// checkTOTPRequirements requires the X-MFA-Token of a verified session from
+// the users who enabled TOTP, except on the endpoints that verify it. A
+// verified session is at AAL2 for the rest of the request.
+func (am *AuthMiddleware) checkTOTPRequirements(c *gin.Context, user *auth.AuthenticatedUser) bool {
+	if am.mfa == nil || !auth.HasTOTPEnrolled(user.Claims) {
+		return true
+	}
+	if token := c.GetHeader(MFATokenHeader); token != "" {
+		verifiedAt, ok, err := am.mfa.VerifyMFASession(c, user.UserID, token)
+		if err != nil {
+			log.Err(err).Str("user_id", user.UserID).Msg("Failed to verify MFA session")
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  http.StatusServiceUnavailable,
+				"message": http.StatusText(http.StatusServiceUnavailable),
+			})
+			return false
+		}
+		if ok {
+			c.Set(auth.AUTH_AAL_INFO_KEY, MFAAALInfo(verifiedAt))
+			return true
+		}
+		RecordLockoutFailure(c, LockoutSubjectUser, user.UserID, c.GetString(auth.AUTH_TENANT_ID_KEY), LockoutReasonInvalidMFAToken)
+	}
+	if strings.HasPrefix(c.Request.URL.Path, "/api/v1/users/me/mfa") {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"id":      auth.ErrorCodeMFATOTPRequired,
+		"message": "Enter a code of your authenticator app to continue.",
+	})
+	return false
+}
+
 // checkAALRequirements validates AAL-based access control
 func (am *AuthMiddleware) checkAALRequirements(c *gin.Context) bool {
 	// Check AAL requirements for Kratos provider
@@ -201,6 +484,9 @@ func (am *AuthMiddleware) checkAALRequirements(c *gin.Context) bool {
 
 	// Only admin users can alter users
 	if strings.HasPrefix(c.Request.URL.Path, "/api/v1/users") ||
+		strings.HasPrefix(c.Request.URL.Path, "/api/v1/tenant/client-applications") ||
+		strings.HasPrefix(c.Request.URL.Path, "/api/v1/tenant/api-tokens") ||
+		strings.HasPrefix(c.Request.URL.Path, "/api/v1/tenant/export") ||
 		strings.HasPrefix(c.Request.URL.Path, "/admin-api") ||
 		strings.HasPrefix(c.Request.URL.Path, "/superadmin-api") {
 		// Check AAL requirements for Kratos provider
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAuthProvider authenticates every request as its user
+type stubAuthProvider struct {
+	auth.AuthProvider
+	user *auth.AuthenticatedUser
+}
+
+func (p *stubAuthProvider) VerifyToken(c *gin.Context) (*auth.AuthenticatedUser, error) {
+	return p.user, nil
+}
+
+func (p *stubAuthProvider) GetProviderName() string {
+	return "stub"
+}
+
+// serveAsMember runs a request of a plain tenant member through the
+// AuthMiddleware and returns the status, 204 when it reached the handler
+func serveAsMember(t *testing.T, method, path string) int {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	member := &auth.AuthenticatedUser{
+		UserID:   "member-1",
+		TenantID: "tenant-1",
+		Claims:   map[string]interface{}{string(core.USER): true},
+	}
+	router := gin.New()
+	router.Use(NewAuthMiddleware(&stubAuthProvider{user: member}, nil).MiddlewareFunc())
+	router.Handle(method, path, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func TestAuthMiddlewareMemberUserEndpoints(t *testing.T) {
+	require.Equal(t, http.StatusNoContent, serveAsMember(t, http.MethodPost, "/api/v1/users/me/email-change"))
+	require.Equal(t, http.StatusNoContent, serveAsMember(t, http.MethodPost, "/api/v1/users/me/phone-verification"))
+	require.Equal(t, http.StatusNoContent, serveAsMember(t, http.MethodPost, "/api/v1/users/me/phone-verification/confirm"))
+	require.Equal(t, http.StatusForbidden, serveAsMember(t, http.MethodPost, "/api/v1/users/42/status"))
+}
+
+func TestAuthMiddlewareMemberDeletesOwnAccount(t *testing.T) {
+	// deleteMyAccount is reachable, deleting another user is not
+	require.Equal(t, http.StatusNoContent, serveAsMember(t, http.MethodDelete, "/api/v1/users/me"))
+	require.Equal(t, http.StatusForbidden, serveAsMember(t, http.MethodDelete, "/api/v1/users/42"))
+	require.Equal(t, http.StatusForbidden, serveAsMember(t, http.MethodPatch, "/api/v1/users/me"))
+}
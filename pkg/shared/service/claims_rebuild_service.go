@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+)
+
+const (
+	ClaimsRebuildBatchSize    = 50
+	ClaimsRebuildDefaultLimit = 500
+	ClaimsRebuildMaxLimit     = 5000
+)
+
+// ClaimsDiscrepancy describes a user whose provider claims did not match the
+// roles stored on their tenant membership
+type ClaimsDiscrepancy struct {
+	UserID        string
+	ExpectedRoles []string
+	ActualRoles   []string
+	Repaired      bool
+	Error         string
+}
+
+// ClaimsRebuildReport is the outcome of one RebuildTenantClaims run. When
+// Completed is false, pass NextCursor back to resume where the run stopped.
+type ClaimsRebuildReport struct {
+	Total         int64
+	Processed     int
+	Repaired      int
+	Failed        int
+	NextCursor    string
+	Completed     bool
+	Discrepancies []ClaimsDiscrepancy
+}
+
+// ClaimsRebuildService recomputes the tenant_memberships claims held by the
+// auth provider from core_user_tenant_memberships, which is the source of truth
+type ClaimsRebuildService struct {
+	store *db.Store
+}
+
+func NewClaimsRebuildService(store *db.Store) *ClaimsRebuildService {
+	return &ClaimsRebuildService{store: store}
+}
+
+// RebuildTenantClaims walks the tenant's active members after cursor in
+// batches, compares the provider claims with the DB roles and rewrites the
+// claims that drifted. At most limit users are processed per call; with
+// dryRun the discrepancies are only reported.
+func (s *ClaimsRebuildService) RebuildTenantClaims(ctx context.Context, authClient auth.AuthClient, tenantID string, cursor string, limit int, dryRun bool) (ClaimsRebuildReport, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if limit <= 0 {
+		limit = ClaimsRebuildDefaultLimit
+	}
+	if limit > ClaimsRebuildMaxLimit {
+		limit = ClaimsRebuildMaxLimit
+	}
+
+	report := ClaimsRebuildReport{Discrepancies: []ClaimsDiscrepancy{}, NextCursor: cursor}
+	total, err := s.store.CountActiveTenantMembers(ctx, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenantID).Msg("Failed to count tenant members")
+		return report, fmt.Errorf("service.RebuildTenantClaims: %w", err)
+	}
+	report.Total = total
+
+	for report.Processed < limit {
+		batchSize := min(ClaimsRebuildBatchSize, limit-report.Processed)
+		members, err := s.store.ListActiveTenantMembersAfter(ctx, repository.ListActiveTenantMembersAfterParams{
+			TenantID:    tenantID,
+			AfterUserID: report.NextCursor,
+			BatchSize:   int32(batchSize),
+		})
+		if err != nil {
+			logger.Err(err).Str("tenant_id", tenantID).Str("cursor", report.NextCursor).Msg("Failed to list tenant members")
+			return report, fmt.Errorf("service.RebuildTenantClaims: %w", err)
+		}
+
+		for _, member := range members {
+			if d, drifted := s.rebuildMemberClaims(ctx, authClient, member, dryRun); drifted {
+				report.Discrepancies = append(report.Discrepancies, d)
+				if d.Repaired {
+					report.Repaired++
+				}
+				if d.Error != "" {
+					report.Failed++
+				}
+			}
+			report.Processed++
+			report.NextCursor = member.UserID
+		}
+
+		logger.Info().
+			Str("tenant_id", tenantID).
+			Int("processed", report.Processed).
+			Int64("total", report.Total).
+			Int("discrepancies", len(report.Discrepancies)).
+			Msg("Claims rebuild progress")
+
+		if len(members) < batchSize {
+			report.Completed = true
+			break
+		}
+	}
+	if report.Completed {
+		report.NextCursor = ""
+	}
+	return report, nil
+}
+
+func (s *ClaimsRebuildService) rebuildMemberClaims(ctx context.Context, authClient auth.AuthClient, member repository.CoreUserTenantMembership, dryRun bool) (ClaimsDiscrepancy, bool) {
+	logger := util.GetLoggerFromCtx(ctx)
+	expected := append([]string{}, member.Roles...)
+	sort.Strings(expected)
+	d := ClaimsDiscrepancy{UserID: member.UserID, ExpectedRoles: expected, ActualRoles: []string{}}
+
+	claims, err := authClient.GetCustomUserClaims(ctx, member.UserID)
+	if err != nil {
+		logger.Err(err).Str("user_id", member.UserID).Msg("Failed to read provider claims")
+		d.Error = err.Error()
+		return d, true
+	}
+
+	actual, found := membershipRolesFromClaims(claims, member.TenantID)
+	d.ActualRoles = actual
+	if found && slices.Equal(expected, actual) {
+		return d, false
+	}
+	if dryRun {
+		return d, true
+	}
+
+	err = authClient.SetCustomUserClaims(ctx, member.UserID, map[string]interface{}{
+		"tenant_memberships": map[string]interface{}{
+			"tenant_id": member.TenantID,
+			"roles":     member.Roles,
+		},
+	})
+	if err != nil {
+		logger.Err(err).Str("user_id", member.UserID).Msg("Failed to rewrite provider claims")
+		d.Error = err.Error()
+		return d, true
+	}
+	d.Repaired = true
+	return d, true
+}
+
+// membershipRolesFromClaims extracts the sorted roles of the tenant_memberships
+// entry matching tenantID
+func membershipRolesFromClaims(claims map[string]interface{}, tenantID string) ([]string, bool) {
+	memberships, ok := claims["tenant_memberships"].([]interface{})
+	if !ok {
+		return []string{}, false
+	}
+	for _, m := range memberships {
+		membership, ok := m.(map[string]interface{})
+		if !ok || membership["tenant_id"] != tenantID {
+			continue
+		}
+		roles := []string{}
+		if rawRoles, ok := membership["roles"].([]interface{}); ok {
+			for _, r := range rawRoles {
+				if role, ok := r.(string); ok {
+					roles = append(roles, role)
+				}
+			}
+		}
+		sort.Strings(roles)
+		return roles, true
+	}
+	return []string{}, false
+}
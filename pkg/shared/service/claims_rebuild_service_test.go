@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+
+	"github.com/stretchr/testify/require"
+)
+
+// claimsAuthClient keeps the provider claims of the users in memory
+type claimsAuthClient struct {
+	auth.AuthClient
+	mu     sync.Mutex
+	claims map[string]map[string]interface{}
+	reads  map[string]int
+	writes map[string]int
+	// onRead runs after every claims read, with the number of reads so far
+	onRead func(reads int)
+	total  int
+}
+
+func newClaimsAuthClient() *claimsAuthClient {
+	return &claimsAuthClient{
+		claims: map[string]map[string]interface{}{},
+		reads:  map[string]int{},
+		writes: map[string]int{},
+	}
+}
+
+func membershipClaims(tenantID string, roles ...string) map[string]interface{} {
+	rawRoles := make([]interface{}, len(roles))
+	for i, role := range roles {
+		rawRoles[i] = role
+	}
+	return map[string]interface{}{
+		"tenant_memberships": []interface{}{
+			map[string]interface{}{"tenant_id": tenantID, "roles": rawRoles},
+		},
+	}
+}
+
+func (c *claimsAuthClient) GetCustomUserClaims(ctx context.Context, uid string) (map[string]interface{}, error) {
+	c.mu.Lock()
+	c.reads[uid]++
+	c.total++
+	claims, total := c.claims[uid], c.total
+	c.mu.Unlock()
+	if c.onRead != nil {
+		c.onRead(total)
+	}
+	if claims == nil {
+		return map[string]interface{}{}, nil
+	}
+	return claims, nil
+}
+
+func (c *claimsAuthClient) SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes[uid]++
+	membership := customClaims["tenant_memberships"].(map[string]interface{})
+	c.claims[uid] = membershipClaims(membership["tenant_id"].(string), membership["roles"].([]string)...)
+	return nil
+}
+
+// setupClaimsRebuildTenant creates a tenant with count active members, every
+// third one with drifted claims, and returns their sorted IDs and the drifted
+// ones
+func setupClaimsRebuildTenant(t *testing.T, service *ClaimsRebuildService, client *claimsAuthClient, count int) (string, []string, map[string]bool) {
+	ctx := context.Background()
+	tenantID := commontestutils.RandomString(10)
+	_, err := service.store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  tenantID,
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+
+	prefix := commontestutils.RandomString(6)
+	userIDs := make([]string, count)
+	drifted := map[string]bool{}
+	for i := range userIDs {
+		userID := fmt.Sprintf("%s-%03d", prefix, i)
+		userIDs[i] = userID
+		_, err := service.store.CreateSharedUser(ctx, repository.CreateSharedUserParams{
+			ID:      userID,
+			Email:   commontestutils.RandomString(8) + "@example.com",
+			Profile: subentity.UserProfile{},
+			Roles:   []string{},
+		})
+		require.NoError(t, err)
+		_, err = service.store.AddSharedUserToTenant(ctx, repository.AddSharedUserToTenantParams{
+			UserID:      userID,
+			TenantID:    tenantID,
+			TenantRoles: []string{"USER"},
+			Status:      "active",
+		})
+		require.NoError(t, err)
+
+		if i%3 == 0 {
+			client.claims[userID] = membershipClaims(tenantID, "CUSTOMER_ADMIN")
+			drifted[userID] = true
+		} else {
+			client.claims[userID] = membershipClaims(tenantID, "USER")
+		}
+	}
+	sort.Strings(userIDs)
+	return tenantID, userIDs, drifted
+}
+
+func TestRebuildTenantClaimsBatches(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewClaimsRebuildService(store)
+	client := newClaimsAuthClient()
+	ctx := context.Background()
+	tenantID, userIDs, drifted := setupClaimsRebuildTenant(t, service, client, 2*ClaimsRebuildBatchSize+20)
+
+	t.Run("dry run reports without writing", func(t *testing.T) {
+		report, err := service.RebuildTenantClaims(ctx, client, tenantID, "", ClaimsRebuildMaxLimit, true)
+		require.NoError(t, err)
+		require.True(t, report.Completed)
+		require.Empty(t, report.NextCursor)
+		require.EqualValues(t, len(userIDs), report.Total)
+		require.Equal(t, len(userIDs), report.Processed)
+		require.Len(t, report.Discrepancies, len(drifted))
+		require.Zero(t, report.Repaired)
+		require.Empty(t, client.writes)
+		for _, d := range report.Discrepancies {
+			require.True(t, drifted[d.UserID], d.UserID)
+			require.Equal(t, []string{"USER"}, d.ExpectedRoles)
+			require.Equal(t, []string{"CUSTOMER_ADMIN"}, d.ActualRoles)
+		}
+	})
+
+	t.Run("limit stops between batches and the cursor resumes", func(t *testing.T) {
+		limit := ClaimsRebuildBatchSize + 10
+		first, err := service.RebuildTenantClaims(ctx, client, tenantID, "", limit, false)
+		require.NoError(t, err)
+		require.False(t, first.Completed)
+		require.Equal(t, limit, first.Processed)
+		require.Equal(t, userIDs[limit-1], first.NextCursor)
+
+		second, err := service.RebuildTenantClaims(ctx, client, tenantID, first.NextCursor, limit, false)
+		require.NoError(t, err)
+		require.False(t, second.Completed)
+		require.Equal(t, userIDs[2*limit-1], second.NextCursor)
+
+		last, err := service.RebuildTenantClaims(ctx, client, tenantID, second.NextCursor, limit, false)
+		require.NoError(t, err)
+		require.True(t, last.Completed)
+		require.Empty(t, last.NextCursor)
+		require.Equal(t, len(userIDs)-2*limit, last.Processed)
+
+		// Every drifted member was repaired exactly once over the three runs
+		require.Equal(t, len(drifted), first.Repaired+second.Repaired+last.Repaired)
+		for userID := range drifted {
+			require.Equal(t, 1, client.writes[userID], userID)
+		}
+
+		report, err := service.RebuildTenantClaims(ctx, client, tenantID, "", ClaimsRebuildMaxLimit, true)
+		require.NoError(t, err)
+		require.Empty(t, report.Discrepancies)
+	})
+}
+
+func TestRebuildTenantClaimsResumesAfterInterruption(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewClaimsRebuildService(store)
+	client := newClaimsAuthClient()
+	tenantID, userIDs, drifted := setupClaimsRebuildTenant(t, service, client, 2*ClaimsRebuildBatchSize+20)
+
+	// The run is cancelled within its second batch: the members of that
+	// batch are still processed, the next batch cannot be read
+	ctx, cancel := context.WithCancel(context.Background())
+	client.onRead = func(reads int) {
+		if reads == ClaimsRebuildBatchSize+5 {
+			cancel()
+		}
+	}
+	interrupted, err := service.RebuildTenantClaims(ctx, client, tenantID, "", ClaimsRebuildMaxLimit, false)
+	require.Error(t, err)
+	require.False(t, interrupted.Completed)
+	require.Equal(t, 2*ClaimsRebuildBatchSize, interrupted.Processed)
+	require.Equal(t, userIDs[interrupted.Processed-1], interrupted.NextCursor)
+
+	client.onRead = nil
+	resumed, err := service.RebuildTenantClaims(context.Background(), client, tenantID, interrupted.NextCursor, ClaimsRebuildMaxLimit, false)
+	require.NoError(t, err)
+	require.True(t, resumed.Completed)
+	require.Equal(t, len(userIDs)-interrupted.Processed, resumed.Processed)
+	require.Equal(t, len(drifted), interrupted.Repaired+resumed.Repaired)
+
+	// No member was read twice across the interruption
+	for _, userID := range userIDs {
+		require.Equal(t, 1, client.reads[userID], userID)
+	}
+}
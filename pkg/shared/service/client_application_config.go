@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ClientApplicationConfigVersion is the version of the configuration
+// documents produced by ExportClientApplicationConfig
+const ClientApplicationConfigVersion = 1
+
+// ErrInvalidClientApplicationConfig is returned when an imported configuration
+// is malformed
+var ErrInvalidClientApplicationConfig = errors.New("invalid client application configuration")
+
+// ClientApplicationConfig is the configuration of a client application,
+// without its secrets, as moved between environments
+type ClientApplicationConfig struct {
+	Version           int
+	Name              string
+	Description       string
+	Tokens            []APITokenConfig
+	ClientCredentials *ClientCredentialsConfig
+	RequestSigning    *CredentialConfig
+	WebhookURL        string
+}
+
+// APITokenConfig describes a token to issue; its value is never exported
+type APITokenConfig struct {
+	Name               string
+	Description        string
+	ExpiresInDays      int
+	Scopes             []string
+	RateLimitPerMinute *int32
+	RateLimitPerHour   *int32
+	AllowedCIDRs       []string
+}
+
+// CredentialConfig holds the scopes of a signing key
+type CredentialConfig struct {
+	Scopes []string
+}
+
+// ClientCredentialsConfig holds the scopes of a client secret and the limits
+// of its access tokens
+type ClientCredentialsConfig struct {
+	Scopes             []string
+	RateLimitPerMinute *int32
+	RateLimitPerHour   *int32
+	AllowedCIDRs       []string
+}
+
+// ImportedAPIToken is a token created by an import with its value
+type ImportedAPIToken struct {
+	Token    string
+	APIToken repository.CoreApiToken
+}
+
+// ImportedClientApplication is the outcome of an import. The secrets are only
+// returned here.
+type ImportedClientApplication struct {
+	Application   repository.CoreClientApplication
+	Tokens        []ImportedAPIToken
+	ClientSecret  string
+	Secret        *repository.CoreClientApplicationSecret
+	SigningSecret string
+	SigningKey    *repository.CoreClientApplicationSigningKey
+	WebhookSecret string
+	Webhook       *repository.CoreClientApplicationWebhook
+}
+
+// ExportClientApplicationConfig returns the configuration of the application:
+// its metadata, its active tokens with their scopes, rate limits and IP
+// allowlists, the scopes of its client secret and signing key, and its webhook
+// URL. Token lifetimes are exported rather than expiry dates, so an import
+// issues tokens valid as long as the originals were.
+func (s *ClientApplicationService) ExportClientApplicationConfig(ctx context.Context, id uuid.UUID, tenantID string) (ClientApplicationConfig, error) {
+	app, err := s.GetClientApplicationByID(ctx, id, tenantID)
+	if err != nil {
+		return ClientApplicationConfig{}, err
+	}
+	config := ClientApplicationConfig{
+		Version:     ClientApplicationConfigVersion,
+		Name:        app.Name,
+		Description: app.Description.String,
+		Tokens:      []APITokenConfig{},
+	}
+
+	const pageSize = 100
+	for offset := int32(0); ; offset += pageSize {
+		tokens, err := s.ListAPITokens(ctx, &id, tenantID, pageSize, offset, "created_at", "asc", false, false)
+		if err != nil {
+			return ClientApplicationConfig{}, fmt.Errorf("service.ExportClientApplicationConfig: %w", err)
+		}
+		for _, token := range tokens {
+			config.Tokens = append(config.Tokens, APITokenConfig{
+				Name:               token.Name,
+				Description:        token.Description.String,
+				ExpiresInDays:      int(math.Round(token.ExpiresAt.Sub(token.CreatedAt).Hours() / 24)),
+				Scopes:             token.Scopes,
+				RateLimitPerMinute: util.FromNullableInt4(token.RateLimitPerMinute),
+				RateLimitPerHour:   util.FromNullableInt4(token.RateLimitPerHour),
+				AllowedCIDRs:       token.AllowedCidrs,
+			})
+		}
+		if len(tokens) < pageSize {
+			break
+		}
+	}
+
+	secret, err := s.store.GetClientApplicationSecret(ctx, id)
+	switch {
+	case err == nil:
+		config.ClientCredentials = &ClientCredentialsConfig{
+			Scopes:             secret.Scopes,
+			RateLimitPerMinute: util.FromNullableInt4(secret.RateLimitPerMinute),
+			RateLimitPerHour:   util.FromNullableInt4(secret.RateLimitPerHour),
+			AllowedCIDRs:       secret.AllowedCidrs,
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return ClientApplicationConfig{}, fmt.Errorf("service.ExportClientApplicationConfig: %w", err)
+	}
+	key, err := s.store.GetClientApplicationSigningKey(ctx, id)
+	switch {
+	case err == nil:
+		config.RequestSigning = &CredentialConfig{Scopes: key.Scopes}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return ClientApplicationConfig{}, fmt.Errorf("service.ExportClientApplicationConfig: %w", err)
+	}
+	hook, err := s.store.GetClientApplicationWebhook(ctx, id)
+	switch {
+	case err == nil:
+		config.WebhookURL = hook.Url
+	case !errors.Is(err, pgx.ErrNoRows):
+		return ClientApplicationConfig{}, fmt.Errorf("service.ExportClientApplicationConfig: %w", err)
+	}
+	return config, nil
+}
+
+// validateClientApplicationConfig checks the whole configuration, so an import
+// fails before creating anything
+func (s *ClientApplicationService) validateClientApplicationConfig(ctx context.Context, config *ClientApplicationConfig) error {
+	if config.Version != ClientApplicationConfigVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidClientApplicationConfig, config.Version)
+	}
+	if config.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidClientApplicationConfig)
+	}
+	for i := range config.Tokens {
+		token := &config.Tokens[i]
+		if token.Name == "" {
+			return fmt.Errorf("%w: token %d has no name", ErrInvalidClientApplicationConfig, i)
+		}
+		if (token.RateLimitPerMinute != nil && *token.RateLimitPerMinute < 1) ||
+			(token.RateLimitPerHour != nil && *token.RateLimitPerHour < 1) {
+			return fmt.Errorf("%w: rate limits of token %q must be positive", ErrInvalidClientApplicationConfig, token.Name)
+		}
+		cidrs, err := NormalizeAllowedCIDRs(token.AllowedCIDRs)
+		if err != nil {
+			return fmt.Errorf("%w: token %q: %v", ErrInvalidClientApplicationConfig, token.Name, err)
+		}
+		token.AllowedCIDRs = cidrs
+	}
+	if config.ClientCredentials != nil {
+		credentials := config.ClientCredentials
+		limits, err := APITokenLimits{
+			RateLimitPerMinute: credentials.RateLimitPerMinute,
+			RateLimitPerHour:   credentials.RateLimitPerHour,
+			AllowedCIDRs:       credentials.AllowedCIDRs,
+		}.normalize()
+		if err != nil {
+			return fmt.Errorf("%w: client credentials: %v", ErrInvalidClientApplicationConfig, err)
+		}
+		credentials.AllowedCIDRs = limits.AllowedCIDRs
+	}
+	if config.WebhookURL != "" {
+		if err := s.validateWebhookURL(ctx, config.WebhookURL); err != nil {
+			return err
+		}
+	}
+	if (config.RequestSigning != nil || config.WebhookURL != "") && len(s.signing.MasterKey) == 0 {
+		return ErrRequestSigningNotConfigured
+	}
+	return nil
+}
+
+// ImportClientApplicationConfig creates an application from an exported
+// configuration in the tenant, generating new token values and secrets. If a
+// step fails the application is deleted again, so an import is all or
+// nothing.
+func (s *ClientApplicationService) ImportClientApplicationConfig(ctx *gin.Context, tenantID string,
+	config ClientApplicationConfig, createdBy string) (ImportedClientApplication, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if err := s.validateClientApplicationConfig(ctx, &config); err != nil {
+		return ImportedClientApplication{}, err
+	}
+
+	app, err := s.CreateClientApplication(ctx, tenantID, config.Name, config.Description, createdBy)
+	if err != nil {
+		return ImportedClientApplication{}, fmt.Errorf("service.ImportClientApplicationConfig: %w", err)
+	}
+	imported, err := s.applyClientApplicationConfig(ctx, app, tenantID, config, createdBy)
+	if err != nil {
+		if deleteErr := s.DeleteClientApplication(ctx, app.ID, tenantID); deleteErr != nil {
+			logger.Err(deleteErr).Str("clientApplicationID", app.ID.String()).Msg("Failed to remove partially imported client application")
+		}
+		return ImportedClientApplication{}, fmt.Errorf("service.ImportClientApplicationConfig: %w", err)
+	}
+	logger.Info().Str("clientApplicationID", app.ID.String()).Int("tokens", len(imported.Tokens)).Msg("Imported client application configuration")
+	return imported, nil
+}
+
+func (s *ClientApplicationService) applyClientApplicationConfig(ctx *gin.Context, app repository.CoreClientApplication, tenantID string,
+	config ClientApplicationConfig, createdBy string) (ImportedClientApplication, error) {
+	imported := ImportedClientApplication{Application: app, Tokens: []ImportedAPIToken{}}
+	for _, tokenConfig := range config.Tokens {
+		value, token, err := s.CreateAPITokenWithLimits(ctx, app.ID, tenantID, tokenConfig.Name, tokenConfig.Description,
+			tokenConfig.ExpiresInDays, createdBy, tokenConfig.Scopes, APITokenLimits{
+				RateLimitPerMinute: tokenConfig.RateLimitPerMinute,
+				RateLimitPerHour:   tokenConfig.RateLimitPerHour,
+				AllowedCIDRs:       tokenConfig.AllowedCIDRs,
+			})
+		if err != nil {
+			return imported, err
+		}
+		imported.Tokens = append(imported.Tokens, ImportedAPIToken{Token: value, APIToken: token})
+	}
+
+	if config.ClientCredentials != nil {
+		credentials := config.ClientCredentials
+		secret, record, err := s.RotateClientSecret(ctx, app.ID, tenantID, credentials.Scopes, APITokenLimits{
+			RateLimitPerMinute: credentials.RateLimitPerMinute,
+			RateLimitPerHour:   credentials.RateLimitPerHour,
+			AllowedCIDRs:       credentials.AllowedCIDRs,
+		}, createdBy)
+		if err != nil {
+			return imported, err
+		}
+		imported.ClientSecret, imported.Secret = secret, &record
+	}
+	if config.RequestSigning != nil {
+		secret, record, err := s.RotateSigningKey(ctx, app.ID, tenantID, config.RequestSigning.Scopes, createdBy)
+		if err != nil {
+			return imported, err
+		}
+		imported.SigningSecret, imported.SigningKey = secret, &record
+	}
+	if config.WebhookURL != "" {
+		secret, record, err := s.SetWebhook(ctx, app.ID, tenantID, config.WebhookURL, createdBy)
+		if err != nil {
+			return imported, err
+		}
+		imported.WebhookSecret, imported.Webhook = secret, &record
+	}
+	return imported, nil
+}
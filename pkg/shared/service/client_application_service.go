@@ -3,10 +3,16 @@ package service
 import (
 	"context"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,37 +20,90 @@ import (
 	"ctoup.com/coreapp/pkg/core/db/repository"
 	"ctoup.com/coreapp/pkg/shared/auth"
 	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // Client application service constants
 const (
 	// Token format: prefix_random_base62
-	TokenPrefix        = "api_"
-	TokenRandomLength  = 32
-	TokenPrefixLength  = 8   // First 8 chars for display/identification
-	DefaultTokenExpiry = 90  // 90 days default expiry
-	MaxTokenExpiry     = 365 // Maximum token expiry in days
-	TokenAuditCreated  = "CREATED"
-	TokenAuditUsed     = "USED"
-	TokenAuditRevoked  = "REVOKED"
-	TokenAuditUpdated  = "UPDATED"
+	TokenPrefix             = "api_"
+	TokenRandomLength       = 32
+	TokenPrefixLength       = 8   // First 8 chars for display/identification
+	DefaultTokenExpiry      = 90  // 90 days default expiry
+	MaxTokenExpiry          = 365 // Maximum token expiry in days
+	TokenAuditCreated       = "CREATED"
+	TokenAuditUsed          = "USED"
+	TokenAuditRevoked       = "REVOKED"
+	TokenAuditUpdated       = "UPDATED"
+	TokenAuditThrottled     = "THROTTLED"
+	TokenAuditDeniedIP      = "DENIED_IP"
+	TokenAuditExpiryWarning = "EXPIRY_WARNING"
+	TokenAuditExpired       = "EXPIRED"
 )
 
+// ErrAPITokenIPNotAllowed is returned by VerifyAPIToken when the client IP is
+// outside the token allowlist
+var ErrAPITokenIPNotAllowed = errors.New("client IP is not allowed for this API token")
+
+// ErrTokenQuotaExceeded is returned by CreateAPIToken when the application
+// already has as many active tokens as its quota allows
+var ErrTokenQuotaExceeded = errors.New("the client application has reached its maximum number of active tokens")
+
+// ErrInvalidTokenQuota is returned for a quota of active tokens below one
+var ErrInvalidTokenQuota = errors.New("maximum number of active tokens must be positive")
+
+// ErrTokenScopesNotNarrowed is returned by UpdateAPIToken when a requested
+// scope is not granted by the current scopes of the token
+var ErrTokenScopesNotNarrowed = errors.New("token scopes can only be narrowed")
+
+// ErrTokenExpiryNotShortened is returned by UpdateAPIToken for an expiry
+// after the current one or in the past
+var ErrTokenExpiryNotShortened = errors.New("token expiry can only be brought forward, and must be in the future")
+
 // ClientApplicationService handles client applications and API tokens
 type ClientApplicationService struct {
-	store *db.Store
+	store       *db.Store
+	rateLimiter RateLimiter
+	oauth       OAuthConfig
+	hashers     *TokenHashers
+	usage       *tokenUsageWriter
+	signing     RequestSigningConfig
+	signatures  *signatureReplayCache
+	webhooks    *webhook.Dispatcher
 }
 
-// NewClientApplicationService creates a new client application service
+// NewClientApplicationService creates a new client application service, its
+// secrets read from the provider set with SetSecretProvider or selected by
+// SECRET_PROVIDER
 func NewClientApplicationService(store *db.Store) *ClientApplicationService {
+	return NewClientApplicationServiceWithSecrets(store, currentSecretProvider())
+}
+
+// NewClientApplicationServiceWithSecrets creates a client application service
+// reading its secrets from the given provider
+func NewClientApplicationServiceWithSecrets(store *db.Store, secrets SecretProvider) *ClientApplicationService {
+	ctx := context.Background()
 	return &ClientApplicationService{
-		store: store,
+		store:       store,
+		rateLimiter: NewRateLimiterFromEnv(),
+		oauth:       OAuthConfigFromSecrets(ctx, secrets),
+		hashers:     NewTokenHashersFromSecrets(ctx, secrets),
+		usage:       newTokenUsageWriter(store, TokenUsageWriterConfigFromEnv()),
+		signing:     RequestSigningConfigFromSecrets(ctx, secrets),
+		signatures:  newSignatureReplayCache(),
+		webhooks:    webhook.NewPublicDispatcher(),
 	}
 }
 
+// Close writes the buffered token usage. It should be called on shutdown.
+func (s *ClientApplicationService) Close(ctx context.Context) error {
+	return s.usage.Close(ctx)
+}
+
 // CreateClientApplication creates a new client application
 func (s *ClientApplicationService) CreateClientApplication(ctx context.Context, tenantID string, name, description, createdBy string) (repository.CoreClientApplication, error) {
 	logger := util.GetLoggerFromCtx(ctx)
@@ -52,6 +111,9 @@ func (s *ClientApplicationService) CreateClientApplication(ctx context.Context,
 	var tenantIDParam *string
 	if tenantID != "" {
 		tenantIDParam = &tenantID
+		if err := NewTenantQuotaService(s.store, nil).CheckQuota(ctx, tenantID, QuotaResourceClientApplications); err != nil {
+			return repository.CoreClientApplication{}, err
+		}
 	}
 
 	app, err := s.store.CreateClientApplication(ctx, repository.CreateClientApplicationParams{
@@ -131,6 +193,39 @@ func (s *ClientApplicationService) ListClientApplications(ctx context.Context, t
 	return apps, nil
 }
 
+// CountClientApplications counts the applications ListClientApplications
+// returns across all pages
+func (s *ClientApplicationService) CountClientApplications(ctx context.Context, tenantID string,
+	searchQuery string, includeInactive bool) (int64, error) {
+	var tenantIDParam *string
+	if tenantID != "" {
+		tenantIDParam = &tenantID
+	}
+
+	var includeInactiveParam *bool
+	if includeInactive {
+		includeInactiveParam = &includeInactive
+	}
+
+	var likeParam *pgtype.Text
+	if searchQuery != "" {
+		likeParam = &pgtype.Text{
+			String: searchQuery + "%",
+			Valid:  true,
+		}
+	}
+
+	count, err := s.store.CountClientApplications(ctx, repository.CountClientApplicationsParams{
+		TenantID:        util.ToNullableText(tenantIDParam),
+		IncludeInactive: util.ToNullableBool(includeInactiveParam),
+		Like:            likeParam,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("service.CountClientApplications: %w", err)
+	}
+	return count, nil
+}
+
 // UpdateClientApplication updates a client application
 func (s *ClientApplicationService) UpdateClientApplication(ctx context.Context, id uuid.UUID,
 	tenantID string, name, description string, active bool) (repository.CoreClientApplication, error) {
@@ -158,8 +253,9 @@ func (s *ClientApplicationService) UpdateClientApplication(ctx context.Context,
 	return app, nil
 }
 
-// DeactivateClientApplication deactivates a client application
-func (s *ClientApplicationService) DeactivateClientApplication(ctx context.Context, id uuid.UUID, tenantID string) error {
+// DeactivateClientApplication deactivates a client application. Its tokens are
+// rejected until it is activated again, which makes deactivation a kill switch.
+func (s *ClientApplicationService) DeactivateClientApplication(ctx context.Context, id uuid.UUID, tenantID, deactivatedBy string) error {
 
 	logger := util.GetLoggerFromCtx(ctx)
 
@@ -178,9 +274,56 @@ func (s *ClientApplicationService) DeactivateClientApplication(ctx context.Conte
 		return err
 	}
 
+	s.recordClientApplicationActivity(ctx, id, tenantID, deactivatedBy, "client_application_deactivated")
+	s.notifyWebhook(ctx, ClientApplicationDeactivatedWebhookEvent, ClientApplicationWebhookEvent{
+		ClientApplicationID: id.String(),
+		Actor:               deactivatedBy,
+	})
+	return nil
+}
+
+// ActivateClientApplication activates a client application deactivated
+// earlier. The tokens that have not expired nor been revoked meanwhile are
+// accepted again.
+func (s *ClientApplicationService) ActivateClientApplication(ctx context.Context, id uuid.UUID, tenantID, activatedBy string) error {
+
+	logger := util.GetLoggerFromCtx(ctx)
+
+	var tenantIDParam *string
+	if tenantID != "" {
+		tenantIDParam = &tenantID
+	}
+
+	_, err := s.store.ActivateClientApplication(ctx, repository.ActivateClientApplicationParams{
+		ID:       id,
+		TenantID: util.ToNullableText(tenantIDParam),
+	})
+
+	if err != nil {
+		logger.Err(err).Str("id", id.String()).Msg("Failed to activate client application")
+		return err
+	}
+
+	s.recordClientApplicationActivity(ctx, id, tenantID, activatedBy, "client_application_activated")
 	return nil
 }
 
+// recordClientApplicationActivity keeps the audit trail of the kill switch on
+// the timeline of the user who flipped it
+func (s *ClientApplicationService) recordClientApplicationActivity(ctx context.Context, id uuid.UUID, tenantID, userID, eventType string) {
+	if userID == "" {
+		return
+	}
+	// Failures are logged by recordUserActivity
+	_ = recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryAPIToken,
+		EventType: eventType,
+		Data:      map[string]interface{}{"client_application_id": id.String()},
+	})
+}
+
 // DeleteClientApplication deletes a client application
 func (s *ClientApplicationService) DeleteClientApplication(ctx context.Context, id uuid.UUID, tenantID string) error {
 
@@ -204,7 +347,8 @@ func (s *ClientApplicationService) DeleteClientApplication(ctx context.Context,
 	return nil
 }
 
-// GenerateSecureToken generates a secure random token
+// GenerateSecureToken generates a secure random token and its hash, computed
+// with the current token hasher
 func (s *ClientApplicationService) GenerateSecureToken() (string, string, []byte, error) {
 	// Generate random bytes
 	randomBytes := make([]byte, TokenRandomLength)
@@ -223,18 +367,63 @@ func (s *ClientApplicationService) GenerateSecureToken() (string, string, []byte
 	tokenPrefix := token[:TokenPrefixLength]
 
 	// Hash token for storage
-	hash := sha256.Sum256([]byte(token))
-	tokenHash := hash[:]
+	tokenHash, err := s.hashers.Current().Hash(token)
+	if err != nil {
+		return "", "", nil, err
+	}
 
 	return token, tokenPrefix, tokenHash, nil
 }
 
+// APITokenLimits are the request budget and the IP allowlist a token is
+// created with. Nil rate limits and an empty allowlist do not limit it.
+type APITokenLimits struct {
+	RateLimitPerMinute *int32
+	RateLimitPerHour   *int32
+	AllowedCIDRs       []string
+}
+
+// ErrInvalidAPITokenRateLimit is returned for a rate limit below one request
+var ErrInvalidAPITokenRateLimit = errors.New("rate limits must be positive")
+
+// normalize checks the rate limits and returns the limits with a normalized
+// allowlist
+func (l APITokenLimits) normalize() (APITokenLimits, error) {
+	if (l.RateLimitPerMinute != nil && *l.RateLimitPerMinute < 1) ||
+		(l.RateLimitPerHour != nil && *l.RateLimitPerHour < 1) {
+		return APITokenLimits{}, ErrInvalidAPITokenRateLimit
+	}
+	cidrs, err := NormalizeAllowedCIDRs(l.AllowedCIDRs)
+	if err != nil {
+		return APITokenLimits{}, err
+	}
+	l.AllowedCIDRs = cidrs
+	return l, nil
+}
+
 // CreateAPIToken creates a new API token for a client application
 func (s *ClientApplicationService) CreateAPIToken(ctx *gin.Context, clientApplicationID uuid.UUID,
 	tenantID string, name, description string, expiresInDays int, createdBy string, scopes []string) (string, repository.CoreApiToken, error) {
+	return s.createAPIToken(ctx, clientApplicationID, tenantID, name, description, expiresInDays, createdBy, scopes, pgtype.UUID{}, APITokenLimits{})
+}
+
+// CreateAPITokenWithLimits creates a token whose rate limits and IP allowlist
+// are stored with it, so it is never usable without them
+func (s *ClientApplicationService) CreateAPITokenWithLimits(ctx *gin.Context, clientApplicationID uuid.UUID, tenantID string,
+	name, description string, expiresInDays int, createdBy string, scopes []string, limits APITokenLimits) (string, repository.CoreApiToken, error) {
+	return s.createAPIToken(ctx, clientApplicationID, tenantID, name, description, expiresInDays, createdBy, scopes, pgtype.UUID{}, limits)
+}
+
+func (s *ClientApplicationService) createAPIToken(ctx *gin.Context, clientApplicationID uuid.UUID, tenantID string, name, description string,
+	expiresInDays int, createdBy string, scopes []string, scopeTemplateID pgtype.UUID, limits APITokenLimits) (string, repository.CoreApiToken, error) {
 
 	logger := util.GetLoggerFromCtx(ctx)
 
+	limits, err := limits.normalize()
+	if err != nil {
+		return "", repository.CoreApiToken{}, err
+	}
+
 	var tenantIDParam *string
 	if tenantID != "" {
 		tenantIDParam = &tenantID
@@ -257,6 +446,12 @@ func (s *ClientApplicationService) CreateAPIToken(ctx *gin.Context, clientApplic
 		return "", repository.CoreApiToken{}, fmt.Errorf("cannot create token for inactive application")
 	}
 
+	expiresInDays, err = s.applyTokenPolicy(ctx, app.TenantID, description, expiresInDays, scopes)
+	if err != nil {
+		logger.Warn().Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Token refused by the tenant token policy")
+		return "", repository.CoreApiToken{}, err
+	}
+
 	// Generate token
 	token, tokenPrefix, tokenHash, err := s.GenerateSecureToken()
 	if err != nil {
@@ -279,7 +474,7 @@ func (s *ClientApplicationService) CreateAPIToken(ctx *gin.Context, clientApplic
 		scopesArray = scopes
 	}
 
-	apiToken, err := s.store.CreateAPIToken(ctx, repository.CreateAPITokenParams{
+	apiToken, err := s.createAPITokenWithinQuota(ctx, repository.CreateAPITokenParams{
 		ClientApplicationID: clientApplicationID,
 		Name:                name,
 		Description:         pgtype.Text{String: description, Valid: true},
@@ -288,6 +483,11 @@ func (s *ClientApplicationService) CreateAPIToken(ctx *gin.Context, clientApplic
 		ExpiresAt:           expiryTime,
 		CreatedBy:           createdBy,
 		Scopes:              scopesArray,
+		HashVersion:         s.hashers.Current().Version(),
+		ScopeTemplateID:     scopeTemplateID,
+		RateLimitPerMinute:  util.ToNullableInt4(limits.RateLimitPerMinute),
+		RateLimitPerHour:    util.ToNullableInt4(limits.RateLimitPerHour),
+		AllowedCidrs:        limits.AllowedCIDRs,
 	})
 
 	if err != nil {
@@ -304,7 +504,7 @@ func (s *ClientApplicationService) CreateAPIToken(ctx *gin.Context, clientApplic
 		Action:         TokenAuditCreated,
 		IpAddress:      pgtype.Text{String: ipAddress, Valid: true},
 		UserAgent:      pgtype.Text{String: userAgent, Valid: true},
-		AdditionalData: nil,
+		AdditionalData: enrichAPITokenAuditData(ctx, APITokenAuditEntry{TokenID: apiToken.ID, Action: TokenAuditCreated}, nil),
 	})
 
 	if err != nil {
@@ -318,9 +518,67 @@ func (s *ClientApplicationService) CreateAPIToken(ctx *gin.Context, clientApplic
 		logger.Warn().Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to update client application last used timestamp")
 	}
 
+	s.notifyWebhook(ctx, APITokenCreatedWebhookEvent, ClientApplicationWebhookEvent{
+		ClientApplicationID: clientApplicationID.String(),
+		TokenID:             apiToken.ID.String(),
+		TokenName:           apiToken.Name,
+		TokenPrefix:         apiToken.TokenPrefix,
+		ExpiresAt:           &apiToken.ExpiresAt,
+		Actor:               createdBy,
+	})
 	return token, apiToken, nil
 }
 
+// createAPITokenWithinQuota inserts the token unless the application has
+// reached its quota of active tokens. The application row is locked until
+// the insert commits, so concurrent creations are counted one after another.
+func (s *ClientApplicationService) createAPITokenWithinQuota(ctx context.Context, params repository.CreateAPITokenParams) (repository.CoreApiToken, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return repository.CoreApiToken{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	quota, err := qtx.LockClientApplicationTokenQuota(ctx, params.ClientApplicationID)
+	if err != nil {
+		return repository.CoreApiToken{}, err
+	}
+	if quota.Valid {
+		active, err := qtx.CountActiveAPITokens(ctx, params.ClientApplicationID)
+		if err != nil {
+			return repository.CoreApiToken{}, err
+		}
+		if active >= int64(quota.Int32) {
+			return repository.CoreApiToken{}, ErrTokenQuotaExceeded
+		}
+	}
+	apiToken, err := qtx.CreateAPIToken(ctx, params)
+	if err != nil {
+		return repository.CoreApiToken{}, err
+	}
+	return apiToken, tx.Commit(ctx)
+}
+
+// SetClientApplicationTokenQuota sets the maximum number of active tokens of
+// the application, nil for no limit. Tokens above a lowered quota stay valid;
+// new ones are refused until enough of them are revoked or expire.
+func (s *ClientApplicationService) SetClientApplicationTokenQuota(ctx context.Context, id uuid.UUID, maxActiveTokens *int32) (repository.CoreClientApplication, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if maxActiveTokens != nil && *maxActiveTokens < 1 {
+		return repository.CoreClientApplication{}, ErrInvalidTokenQuota
+	}
+	app, err := s.store.SetClientApplicationMaxActiveTokens(ctx, repository.SetClientApplicationMaxActiveTokensParams{
+		ID:              id,
+		MaxActiveTokens: util.ToNullableInt4(maxActiveTokens),
+	})
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", id.String()).Msg("Failed to set client application token quota")
+		return repository.CoreClientApplication{}, err
+	}
+	return app, nil
+}
+
 // GetAPITokenByID retrieves an API token by ID
 func (s *ClientApplicationService) GetAPITokenByID(ctx context.Context, id uuid.UUID, tenantID string) (repository.GetAPITokenByIDRow, error) {
 	logger := util.GetLoggerFromCtx(ctx)
@@ -388,6 +646,112 @@ func (s *ClientApplicationService) ListAPITokens(ctx context.Context, clientAppl
 	return tokens, nil
 }
 
+// CountAPITokens counts the tokens ListAPITokens returns across all pages
+func (s *ClientApplicationService) CountAPITokens(ctx context.Context, clientApplicationID *uuid.UUID,
+	tenantID string, includeRevoked, includeExpired bool) (int64, error) {
+	var tenantIDParam *string
+	if tenantID != "" {
+		tenantIDParam = &tenantID
+	}
+
+	var includeRevokedParam *bool
+	if includeRevoked {
+		includeRevokedParam = &includeRevoked
+	}
+
+	var includeExpiredParam *bool
+	if includeExpired {
+		includeExpiredParam = &includeExpired
+	}
+
+	count, err := s.store.CountAPITokens(ctx, repository.CountAPITokensParams{
+		ClientApplicationID: util.ToNullableUUID(clientApplicationID),
+		TenantID:            util.ToNullableText(tenantIDParam),
+		IncludeRevoked:      util.ToNullableBool(includeRevokedParam),
+		IncludeExpired:      util.ToNullableBool(includeExpiredParam),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("service.CountAPITokens: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateAPIToken narrows the scopes and optionally brings forward the expiry
+// of a token of the client application, keeping its value. Every requested
+// scope must be granted by the current ones, see ScopeGrants; nil scopes or
+// expiresAt leave them unchanged. Narrowed scopes detach the token from its
+// scope template. The change is audited as UPDATED.
+func (s *ClientApplicationService) UpdateAPIToken(ctx *gin.Context, clientApplicationID, id uuid.UUID, tenantID string,
+	scopes *[]string, expiresAt *time.Time, updatedBy string) (repository.GetAPITokenByIDRow, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	token, err := s.GetAPITokenByID(ctx, id, tenantID)
+	if err != nil {
+		return repository.GetAPITokenByIDRow{}, err
+	}
+	if token.ClientApplicationID != clientApplicationID {
+		return repository.GetAPITokenByIDRow{}, pgx.ErrNoRows
+	}
+
+	newScopes := token.Scopes
+	if scopes != nil {
+		for _, scope := range *scopes {
+			if !HasScope(token.Scopes, scope) {
+				return repository.GetAPITokenByIDRow{}, fmt.Errorf("%w: %q", ErrTokenScopesNotNarrowed, scope)
+			}
+		}
+		newScopes = slices.Compact(slices.Sorted(slices.Values(*scopes)))
+	}
+	newExpiresAt := token.ExpiresAt
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) || expiresAt.After(token.ExpiresAt) {
+			return repository.GetAPITokenByIDRow{}, ErrTokenExpiryNotShortened
+		}
+		newExpiresAt = *expiresAt
+	}
+
+	updated, err := s.store.UpdateAPIToken(ctx, repository.UpdateAPITokenParams{
+		ID:          id,
+		Name:        token.Name,
+		Description: token.Description,
+		ExpiresAt:   newExpiresAt,
+		Scopes:      newScopes,
+	})
+	if err != nil {
+		logger.Err(err).Str("id", id.String()).Msg("Failed to update API token")
+		return repository.GetAPITokenByIDRow{}, fmt.Errorf("service.UpdateAPIToken: %w", err)
+	}
+	// Scopes set by hand no longer follow the template the token was created from
+	if scopes != nil && token.ScopeTemplateID.Valid {
+		if err := s.store.DetachAPITokenFromScopeTemplate(ctx, id); err != nil {
+			return repository.GetAPITokenByIDRow{}, fmt.Errorf("service.UpdateAPIToken: %w", err)
+		}
+		token.ScopeTemplateID = pgtype.UUID{}
+	}
+
+	additionalData, _ := json.Marshal(map[string]interface{}{
+		"updated_by":          updatedBy,
+		"previous_scopes":     token.Scopes,
+		"scopes":              updated.Scopes,
+		"previous_expires_at": token.ExpiresAt,
+		"expires_at":          updated.ExpiresAt,
+	})
+	_, err = s.store.CreateAPITokenAuditLog(ctx, repository.CreateAPITokenAuditLogParams{
+		TokenID:        id,
+		Action:         TokenAuditUpdated,
+		IpAddress:      pgtype.Text{String: ctx.ClientIP(), Valid: true},
+		UserAgent:      pgtype.Text{String: ctx.GetHeader("User-Agent"), Valid: true},
+		AdditionalData: enrichAPITokenAuditData(ctx, APITokenAuditEntry{TokenID: id, Action: TokenAuditUpdated}, additionalData),
+	})
+	if err != nil {
+		logger.Err(err).Str("tokenID", id.String()).Msg("Failed to create audit log for token update")
+	}
+
+	token.Scopes = updated.Scopes
+	token.ExpiresAt = updated.ExpiresAt
+	token.UpdatedAt = updated.UpdatedAt
+	return token, nil
+}
+
 // RevokeAPIToken revokes an API token
 func (s *ClientApplicationService) RevokeAPIToken(ctx *gin.Context, id uuid.UUID, tenantID, reason, revokedBy string) (repository.CoreApiToken, error) {
 	logger := util.GetLoggerFromCtx(ctx)
@@ -435,7 +799,7 @@ func (s *ClientApplicationService) RevokeAPIToken(ctx *gin.Context, id uuid.UUID
 		Action:         TokenAuditRevoked,
 		IpAddress:      pgtype.Text{String: ipAddress, Valid: true},
 		UserAgent:      pgtype.Text{String: userAgent, Valid: true},
-		AdditionalData: nil,
+		AdditionalData: enrichAPITokenAuditData(ctx, APITokenAuditEntry{TokenID: id, Action: TokenAuditRevoked}, nil),
 	})
 
 	if err != nil {
@@ -443,9 +807,95 @@ func (s *ClientApplicationService) RevokeAPIToken(ctx *gin.Context, id uuid.UUID
 		// Don't fail the revocation if audit log fails
 	}
 
+	s.notifyWebhook(ctx, APITokenRevokedWebhookEvent, ClientApplicationWebhookEvent{
+		ClientApplicationID: revokedToken.ClientApplicationID.String(),
+		TokenID:             revokedToken.ID.String(),
+		TokenName:           revokedToken.Name,
+		TokenPrefix:         revokedToken.TokenPrefix,
+		Reason:              reason,
+		Actor:               revokedBy,
+	})
 	return revokedToken, nil
 }
 
+// RevokeAllAPITokens revokes the active tokens of a client application, or of
+// every application in the caller's scope when clientApplicationID is nil.
+// The revocations and their audit entries, one per token, are written in a
+// single transaction so an incident response never leaves a token half revoked.
+func (s *ClientApplicationService) RevokeAllAPITokens(ctx *gin.Context, clientApplicationID *uuid.UUID, tenantID, reason, revokedBy string) ([]uuid.UUID, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+
+	var tenantIDParam *string
+	if tenantID != "" {
+		tenantIDParam = &tenantID
+	}
+	params := repository.RevokeActiveAPITokensParams{
+		RevokedReason: pgtype.Text{String: reason, Valid: true},
+		RevokedBy:     pgtype.Text{String: revokedBy, Valid: true},
+		TenantID:      util.ToNullableText(tenantIDParam),
+	}
+	if clientApplicationID != nil {
+		params.ClientApplicationID = pgtype.UUID{Bytes: *clientApplicationID, Valid: true}
+	}
+
+	additionalData, err := json.Marshal(map[string]any{
+		"reason": reason,
+		"bulk":   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	revoked, err := qtx.RevokeActiveAPITokens(ctx, params)
+	if err != nil {
+		logger.Err(err).Msg("Failed to revoke API tokens")
+		return nil, err
+	}
+
+	tokenIDs := make([]uuid.UUID, len(revoked))
+	for i, token := range revoked {
+		tokenIDs[i] = token.ID
+	}
+	if len(tokenIDs) > 0 {
+		now := time.Now()
+		audit := repository.CreateAPITokenAuditLogsParams{}
+		for _, tokenID := range tokenIDs {
+			audit.TokenIds = append(audit.TokenIds, tokenID)
+			audit.Actions = append(audit.Actions, TokenAuditRevoked)
+			audit.IpAddresses = append(audit.IpAddresses, ctx.ClientIP())
+			audit.UserAgents = append(audit.UserAgents, ctx.GetHeader("User-Agent"))
+			entry := APITokenAuditEntry{TokenID: tokenID, Action: TokenAuditRevoked}
+			audit.AdditionalData = append(audit.AdditionalData, string(enrichAPITokenAuditData(ctx, entry, additionalData)))
+			audit.Timestamps = append(audit.Timestamps, now)
+		}
+		if _, err := qtx.CreateAPITokenAuditLogs(ctx, audit); err != nil {
+			logger.Err(err).Int("tokens", len(tokenIDs)).Msg("Failed to create audit logs for bulk token revocation")
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	logger.Info().Int("tokens", len(tokenIDs)).Str("revokedBy", revokedBy).Msg("Revoked API tokens in bulk")
+	for _, token := range revoked {
+		s.notifyWebhook(ctx, APITokenRevokedWebhookEvent, ClientApplicationWebhookEvent{
+			ClientApplicationID: token.ClientApplicationID.String(),
+			TokenID:             token.ID.String(),
+			Reason:              reason,
+			Actor:               revokedBy,
+		})
+	}
+	return tokenIDs, nil
+}
+
 // DeleteAPIToken deletes an API token
 func (s *ClientApplicationService) DeleteAPIToken(ctx context.Context, id uuid.UUID) error {
 	logger := util.GetLoggerFromCtx(ctx)
@@ -459,14 +909,26 @@ func (s *ClientApplicationService) DeleteAPIToken(ctx context.Context, id uuid.U
 	return nil
 }
 
-// GetAPITokenAuditLogs retrieves audit logs for an API token
-func (s *ClientApplicationService) GetAPITokenAuditLogs(ctx context.Context, tokenID uuid.UUID, limit, offset int32) ([]repository.CoreApiTokenAuditLog, error) {
+// GetAPITokenAuditLogs retrieves audit logs for an API token, newest first
+func (s *ClientApplicationService) GetAPITokenAuditLogs(ctx context.Context, tokenID uuid.UUID, filter APITokenAuditFilter,
+	limit, offset int32) ([]repository.CoreApiTokenAuditLog, error) {
 	logger := util.GetLoggerFromCtx(ctx)
-	logs, err := s.store.GetAPITokenAuditLogs(ctx, repository.GetAPITokenAuditLogsParams{
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	params := repository.GetAPITokenAuditLogsParams{
 		TokenID: tokenID,
 		Limit:   limit,
 		Offset:  offset,
-	})
+		Action:  pgtype.Text{String: filter.Action, Valid: filter.Action != ""},
+	}
+	if !filter.From.IsZero() {
+		params.From = pgtype.Timestamptz{Time: filter.From, Valid: true}
+	}
+	if !filter.To.IsZero() {
+		params.To = pgtype.Timestamptz{Time: filter.To, Valid: true}
+	}
+	logs, err := s.store.GetAPITokenAuditLogs(ctx, params)
 
 	if err != nil {
 		logger.Err(err).Str("tokenID", tokenID.String()).Msg("Failed to get API token audit logs")
@@ -476,60 +938,328 @@ func (s *ClientApplicationService) GetAPITokenAuditLogs(ctx context.Context, tok
 	return logs, nil
 }
 
+// APITokenUsage aggregates the USED audit entries of a token over a time range
+type APITokenUsage struct {
+	From          time.Time
+	To            time.Time
+	TotalRequests int64
+	UniqueIPs     int64
+	Daily         []repository.GetAPITokenUsageByDayRow
+	TopEndpoints  []repository.GetAPITokenTopEndpointsRow
+}
+
+// GetAPITokenUsage computes request counts per day, unique client IPs and the
+// most called endpoints of a token between from (inclusive) and to (exclusive)
+func (s *ClientApplicationService) GetAPITokenUsage(ctx context.Context, tokenID uuid.UUID, from, to time.Time, maxEndpoints int32) (APITokenUsage, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	usage := APITokenUsage{From: from, To: to}
+
+	summary, err := s.store.GetAPITokenUsageSummary(ctx, repository.GetAPITokenUsageSummaryParams{
+		TokenID:  tokenID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		logger.Err(err).Str("tokenID", tokenID.String()).Msg("Failed to get API token usage summary")
+		return usage, fmt.Errorf("service.GetAPITokenUsage: %w", err)
+	}
+	usage.TotalRequests = summary.TotalRequests
+	usage.UniqueIPs = summary.UniqueIps
+
+	usage.Daily, err = s.store.GetAPITokenUsageByDay(ctx, repository.GetAPITokenUsageByDayParams{
+		TokenID:  tokenID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		logger.Err(err).Str("tokenID", tokenID.String()).Msg("Failed to get API token daily usage")
+		return usage, fmt.Errorf("service.GetAPITokenUsage: %w", err)
+	}
+
+	usage.TopEndpoints, err = s.store.GetAPITokenTopEndpoints(ctx, repository.GetAPITokenTopEndpointsParams{
+		TokenID:      tokenID,
+		FromTime:     from,
+		ToTime:       to,
+		MaxEndpoints: maxEndpoints,
+	})
+	if err != nil {
+		logger.Err(err).Str("tokenID", tokenID.String()).Msg("Failed to get API token top endpoints")
+		return usage, fmt.Errorf("service.GetAPITokenUsage: %w", err)
+	}
+
+	return usage, nil
+}
+
 // VerifyAPIToken verifies an API token and returns the associated application and token if valid
-func (s *ClientApplicationService) VerifyAPIToken(ctx *gin.Context, tokenString string) (repository.GetAPITokenByHashRow, error) {
+func (s *ClientApplicationService) VerifyAPIToken(ctx *gin.Context, tokenString string) (repository.GetAPITokensByPrefixRow, error) {
 	logger := util.GetLoggerFromCtx(ctx)
 	// Sanitize token
 	tokenString = strings.TrimSpace(tokenString)
 
-	// Hash token for lookup
-	hash := sha256.Sum256([]byte(tokenString))
-	tokenHash := hash[:]
-
-	// Look up token by hash
-	token, err := s.store.GetAPITokenByHash(ctx, tokenHash)
+	token, err := s.findAPIToken(ctx, tokenString)
 	if err != nil {
 		logger.Err(err).Msg("Failed to verify API token")
-		return repository.GetAPITokenByHashRow{}, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			tenantID, reason := s.apiTokenFailureReason(ctx, tokenString)
+			RecordAuthFailure(ctx, tenantID, reason)
+		}
+		return repository.GetAPITokensByPrefixRow{}, err
 	}
 
 	// Create audit log entry for token usage
 	ipAddress := ctx.ClientIP()
 	userAgent := ctx.GetHeader("User-Agent")
 
-	_, err = s.store.CreateAPITokenAuditLog(ctx, repository.CreateAPITokenAuditLogParams{
-		TokenID:        token.ID,
-		Action:         TokenAuditUsed,
-		IpAddress:      pgtype.Text{String: ipAddress, Valid: true},
-		UserAgent:      pgtype.Text{String: userAgent, Valid: true},
-		AdditionalData: nil,
+	// Record the route template rather than the raw path so usage analytics
+	// group requests per endpoint instead of per resource ID
+	path := ctx.FullPath()
+	if path == "" {
+		path = ctx.Request.URL.Path
+	}
+	additionalData, _ := json.Marshal(map[string]string{
+		"method": ctx.Request.Method,
+		"path":   path,
 	})
 
-	if err != nil {
-		logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to create audit log for token usage")
-		// Don't fail the verification if audit log fails
+	action := TokenAuditUsed
+	allowed := IsIPAllowed(ipAddress, token.AllowedCidrs)
+	if !allowed {
+		action = TokenAuditDeniedIP
 	}
 
-	// Update token last used
-	err = s.store.UpdateAPITokenLastUsed(ctx, repository.UpdateAPITokenLastUsedParams{
-		ID:        token.ID,
-		IpAddress: pgtype.Text{String: ipAddress, Valid: true},
+	// The audit entry and the last used timestamps of the token and its
+	// application are written in the background unless the writer is in sync
+	// mode; a failed write never fails the verification
+	s.usage.Record(ctx, tokenUsage{
+		TokenID:             token.ID,
+		ClientApplicationID: token.ClientApplicationID,
+		Action:              action,
+		IPAddress:           ipAddress,
+		UserAgent:           userAgent,
+		AdditionalData:      additionalData,
+		UpdateLastUsed:      allowed,
 	})
 
+	if !allowed {
+		logger.Warn().Str("tokenID", token.ID.String()).Str("ip", ipAddress).Msg("API token used from a non allowed IP address")
+		RecordAuthFailure(ctx, token.TenantID.String, AuthFailureIPNotAllowed)
+		return repository.GetAPITokensByPrefixRow{}, ErrAPITokenIPNotAllowed
+	}
+
+	return token, nil
+}
+
+// findAPIToken looks the token up by its prefix and checks it against the
+// candidate hashes. A hash computed with an older algorithm is upgraded to the
+// current one.
+func (s *ClientApplicationService) findAPIToken(ctx context.Context, tokenString string) (repository.GetAPITokensByPrefixRow, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if !strings.HasPrefix(tokenString, TokenPrefix) || len(tokenString) <= TokenPrefixLength {
+		return repository.GetAPITokensByPrefixRow{}, pgx.ErrNoRows
+	}
+
+	candidates, err := s.store.GetAPITokensByPrefix(ctx, tokenString[:TokenPrefixLength])
 	if err != nil {
-		logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to update token last used timestamp")
+		return repository.GetAPITokensByPrefixRow{}, err
+	}
+	for _, candidate := range candidates {
+		hasher := s.hashers.ForVersion(candidate.HashVersion)
+		if hasher == nil {
+			logger.Error().Str("tokenID", candidate.ID.String()).Int16("hashVersion", candidate.HashVersion).Msg("No hasher configured for API token hash version")
+			continue
+		}
+		if !hasher.Verify(tokenString, candidate.TokenHash) {
+			continue
+		}
+		if s.hashers.NeedsUpgrade(candidate.HashVersion) {
+			s.upgradeAPITokenHash(ctx, candidate, tokenString)
+		}
+		return candidate, nil
 	}
+	return repository.GetAPITokensByPrefixRow{}, pgx.ErrNoRows
+}
 
-	// Update the client application's last used timestamp
-	err = s.store.UpdateClientApplicationLastUsed(ctx, token.ClientApplicationID)
+// apiTokenFailureReason tells a revoked, expired or deactivated token from an
+// unknown one, and returns the tenant to count the failure in: the tenant of
+// the token when it is known, the tenant of the request otherwise
+func (s *ClientApplicationService) apiTokenFailureReason(ctx *gin.Context, tokenString string) (string, string) {
+	tenantID := ctx.GetString(auth.AUTH_TENANT_ID_KEY)
+	if !strings.HasPrefix(tokenString, TokenPrefix) || len(tokenString) <= TokenPrefixLength {
+		return tenantID, AuthFailureInvalidToken
+	}
+	candidates, err := s.store.GetUnusableAPITokensByPrefix(ctx, tokenString[:TokenPrefixLength])
+	if err != nil {
+		return tenantID, AuthFailureInvalidToken
+	}
+	for _, candidate := range candidates {
+		hasher := s.hashers.ForVersion(candidate.HashVersion)
+		if hasher == nil || !hasher.Verify(tokenString, candidate.TokenHash) {
+			continue
+		}
+		switch {
+		case candidate.Revoked:
+			return candidate.TenantID.String, AuthFailureRevokedToken
+		case !candidate.ExpiresAt.After(time.Now()):
+			return candidate.TenantID.String, AuthFailureExpiredToken
+		default:
+			return candidate.TenantID.String, AuthFailureInactiveApplication
+		}
+	}
+	return tenantID, AuthFailureInvalidToken
+}
+
+// upgradeAPITokenHash rehashes a token with the current hasher. Failures are
+// logged only: the old hash keeps working and the upgrade is retried on next use.
+func (s *ClientApplicationService) upgradeAPITokenHash(ctx context.Context, token repository.GetAPITokensByPrefixRow, tokenString string) {
+	logger := util.GetLoggerFromCtx(ctx)
+	current := s.hashers.Current()
+	tokenHash, err := current.Hash(tokenString)
+	if err == nil {
+		err = s.store.UpdateAPITokenHash(ctx, repository.UpdateAPITokenHashParams{
+			ID:              token.ID,
+			TokenHash:       tokenHash,
+			HashVersion:     current.Version(),
+			PreviousVersion: token.HashVersion,
+		})
+	}
 	if err != nil {
-		logger.Err(err).Str("clientApplicationID", token.ClientApplicationID.String()).Msg("Failed to update client application last used timestamp")
+		logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to upgrade API token hash")
+		return
 	}
+	logger.Info().Str("tokenID", token.ID.String()).Int16("from", token.HashVersion).Int16("to", current.Version()).Msg("Upgraded API token hash")
+}
 
+// SetAPITokenAllowedCIDRs replaces the IP allowlist of a token; an empty list
+// allows any address
+func (s *ClientApplicationService) SetAPITokenAllowedCIDRs(ctx context.Context, id uuid.UUID, cidrs []string) (repository.CoreApiToken, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	normalized, err := NormalizeAllowedCIDRs(cidrs)
+	if err != nil {
+		return repository.CoreApiToken{}, err
+	}
+	token, err := s.store.UpdateAPITokenAllowedCIDRs(ctx, repository.UpdateAPITokenAllowedCIDRsParams{
+		ID:           id,
+		AllowedCidrs: normalized,
+	})
+	if err != nil {
+		logger.Err(err).Str("id", id.String()).Msg("Failed to update API token IP allowlist")
+		return repository.CoreApiToken{}, fmt.Errorf("service.SetAPITokenAllowedCIDRs: %w", err)
+	}
 	return token, nil
 }
 
-// APITokenMiddleware is a middleware for API token authentication
+// ErrInvalidCIDR is returned for an allowlist entry that is neither a CIDR
+// nor an IP address
+var ErrInvalidCIDR = errors.New("invalid CIDR")
+
+// NormalizeAllowedCIDRs validates an allowlist and returns it in canonical
+// form. Bare addresses are accepted as single host prefixes.
+func NormalizeAllowedCIDRs(cidrs []string) ([]string, error) {
+	normalized := make([]string, 0, len(cidrs))
+	seen := make(map[string]bool, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%w %q: %w", ErrInvalidCIDR, cidr, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		value := prefix.Masked().String()
+		if !seen[value] {
+			seen[value] = true
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized, nil
+}
+
+// IsIPAllowed reports whether ip matches one of the allowlist prefixes. An
+// empty allowlist allows every address.
+func IsIPAllowed(ip string, cidrs []string) bool {
+	if len(cidrs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAPITokenRateLimits stores the request budget of a token; nil removes the
+// limit for that window
+func (s *ClientApplicationService) SetAPITokenRateLimits(ctx context.Context, id uuid.UUID, perMinute, perHour *int32) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	err := s.store.UpdateAPITokenRateLimits(ctx, repository.UpdateAPITokenRateLimitsParams{
+		ID:                 id,
+		RateLimitPerMinute: util.ToNullableInt4(perMinute),
+		RateLimitPerHour:   util.ToNullableInt4(perHour),
+	})
+	if err != nil {
+		logger.Err(err).Str("id", id.String()).Msg("Failed to update API token rate limits")
+		return err
+	}
+	return nil
+}
+
+// CheckAPITokenRateLimit consumes one request from the token budget. When the
+// budget is exhausted it records a THROTTLED audit entry, responds 429 with a
+// Retry-After header and returns false; the caller must abort the request.
+func (s *ClientApplicationService) CheckAPITokenRateLimit(c *gin.Context, token repository.GetAPITokensByPrefixRow) bool {
+	logger := util.GetLoggerFromCtx(c)
+	limit := TokenRateLimit{
+		PerMinute: int(token.RateLimitPerMinute.Int32),
+		PerHour:   int(token.RateLimitPerHour.Int32),
+	}
+	if limit.IsUnlimited() {
+		return true
+	}
+	allowed, retryAfter, err := s.rateLimiter.Allow(c, apiTokenRateLimitKey(token.ID.String()), limit.Windows()...)
+	if err != nil {
+		// Fail open: a limiter outage must not take the API down
+		logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to check API token rate limit")
+		return true
+	}
+	if allowed {
+		return true
+	}
+
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	additionalData, _ := json.Marshal(map[string]interface{}{
+		"retry_after_seconds":   retryAfterSeconds,
+		"rate_limit_per_minute": limit.PerMinute,
+		"rate_limit_per_hour":   limit.PerHour,
+	})
+	s.usage.Record(c, tokenUsage{
+		TokenID:             token.ID,
+		ClientApplicationID: token.ClientApplicationID,
+		Action:              TokenAuditThrottled,
+		IPAddress:           c.ClientIP(),
+		UserAgent:           c.GetHeader("User-Agent"),
+		AdditionalData:      additionalData,
+	})
+
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"status":  http.StatusTooManyRequests,
+		"message": "API token rate limit exceeded",
+	})
+	return false
+}
+
+// APITokenMiddleware is a middleware for API token authentication. When the
+// tenant of the request is already resolved, a token of another tenant is
+// refused; otherwise the tenant of the token is set for the tenant middleware.
 func APITokenMiddleware(clientAppService *ClientApplicationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Public routes bypass token auth
@@ -564,33 +1294,86 @@ func APITokenMiddleware(clientAppService *ClientApplicationService) gin.HandlerF
 			return
 		}
 
+		// Client credentials access tokens are JWTs
+		if IsClientCredentialsToken(token) {
+			principal, err := clientAppService.VerifyClientCredentialsToken(c, token)
+			if err != nil {
+				// Token verification failed, let the next middleware handle auth
+				c.Next()
+				return
+			}
+			if !setTokenTenant(c, principal.TenantID) {
+				return
+			}
+			if !clientAppService.CheckClientCredentialsLimits(c, principal) {
+				c.Abort()
+				return
+			}
+			setClientCredentialsPrincipal(c, principal)
+			c.Next()
+			return
+		}
+
 		// Verify token
 		apiToken, err := clientAppService.VerifyAPIToken(c, token)
+		if errors.Is(err, ErrAPITokenIPNotAllowed) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  http.StatusForbidden,
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			// Token verification failed, let the next middleware handle auth
 			c.Next()
 			return
 		}
 
+		if !setTokenTenant(c, apiToken.TenantID.String) {
+			return
+		}
+		if !clientAppService.CheckAPITokenRateLimit(c, apiToken) {
+			c.Abort()
+			return
+		}
+
 		// Store token info in context for later use
 		c.Set("api_token", apiToken)
 		c.Set("api_token_scopes", apiToken.Scopes)
 
-		// If token has a tenant ID, set it for the tenant middleware
-		if apiToken.TenantID.Valid {
-			c.Set(auth.AUTH_TENANT_ID_KEY, apiToken.TenantID.String)
-		}
-
 		c.Next()
 	}
 }
 
+// setTokenTenant checks the tenant of a token against the tenant already
+// resolved for the request, or sets it when none is. A mismatch is answered
+// with a 403 and false is returned.
+func setTokenTenant(c *gin.Context, tokenTenantID string) bool {
+	if requestTenantID, resolved := c.Get(auth.AUTH_TENANT_ID_KEY); resolved {
+		if requestTenantID != tokenTenantID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  http.StatusForbidden,
+				"message": "Token is not valid for this tenant",
+			})
+			c.Abort()
+			return false
+		}
+		return true
+	}
+	if tokenTenantID != "" {
+		c.Set(auth.AUTH_TENANT_ID_KEY, tokenTenantID)
+	}
+	return true
+}
+
 // HexEncodeTokenHash returns a hex-encoded token hash for display
 func HexEncodeTokenHash(hash []byte) string {
 	return hex.EncodeToString(hash)
 }
 
-// ValidateTokenScopes validates that a token has the required scopes
+// ValidateTokenScopes validates that a token has the required scopes. A
+// token scope grants the scopes below it in the hierarchy, see ScopeGrants.
 func ValidateTokenScopes(c *gin.Context, requiredScopes []string) bool {
 	// If no required scopes, allow access
 	if len(requiredScopes) == 0 {
@@ -610,7 +1393,7 @@ func ValidateTokenScopes(c *gin.Context, requiredScopes []string) bool {
 	}
 
 	for _, requiredScope := range requiredScopes {
-		if !util.Contains(scopes, requiredScope) {
+		if !HasScope(scopes, requiredScope) {
 			return false
 		}
 	}
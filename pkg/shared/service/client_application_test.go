@@ -1,16 +1,25 @@
 package service
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	commontestutils "ctoup.com/coreapp/internal/testutils"
 	"ctoup.com/coreapp/pkg/core/db"
 	"ctoup.com/coreapp/pkg/core/db/repository"
 
 	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 )
 
@@ -106,7 +115,7 @@ func TestDeactivateClientApplication(t *testing.T) {
 	t.Run("successful deactivation", func(t *testing.T) {
 		app := createTestClientApplication(t, service)
 
-		err := service.DeactivateClientApplication(ctx, app.ID, app.TenantID.String)
+		err := service.DeactivateClientApplication(ctx, app.ID, app.TenantID.String, "")
 
 		require.NoError(t, err)
 
@@ -114,7 +123,497 @@ func TestDeactivateClientApplication(t *testing.T) {
 	tenantID := commontestutils.RandomString(10) // Add random tenant ID
 
 	t.Run("non-existing application", func(t *testing.T) {
-		err := service.DeactivateClientApplication(ctx, uuid.New(), tenantID)
+		err := service.DeactivateClientApplication(ctx, uuid.New(), tenantID, "")
 		require.Error(t, err)
 	})
 }
+
+func TestActivateClientApplication(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	adminID := commontestutils.RandomString(10)
+	token, _, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, commontestutils.RandomString(8), "", 30, "creator", nil)
+	require.NoError(t, err)
+
+	// Deactivation is a kill switch for the tokens of the application
+	require.NoError(t, service.DeactivateClientApplication(ctx, app.ID, app.TenantID.String, adminID))
+	deactivated, err := service.GetClientApplicationByID(ctx, app.ID, app.TenantID.String)
+	require.NoError(t, err)
+	require.False(t, deactivated.Active)
+	_, err = service.VerifyAPIToken(ctx, token)
+	require.Error(t, err)
+
+	require.NoError(t, service.ActivateClientApplication(ctx, app.ID, app.TenantID.String, adminID))
+	activated, err := service.GetClientApplicationByID(ctx, app.ID, app.TenantID.String)
+	require.NoError(t, err)
+	require.True(t, activated.Active)
+	_, err = service.VerifyAPIToken(ctx, token)
+	require.NoError(t, err)
+
+	// Both switches are on the timeline of the admin
+	events, err := NewUserActivityService(service.store).ListUserTimeline(ctx, app.TenantID.String, adminID, []string{ActivityCategoryAPIToken}, 10, 0)
+	require.NoError(t, err)
+	eventTypes := []string{}
+	for _, event := range events {
+		if event.Data["client_application_id"] == app.ID.String() {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+	}
+	require.ElementsMatch(t, []string{"client_application_deactivated", "client_application_activated"}, eventTypes)
+
+	t.Run("other tenant", func(t *testing.T) {
+		err := service.ActivateClientApplication(ctx, app.ID, commontestutils.RandomString(10), adminID)
+		require.Error(t, err)
+	})
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	service, _ := setupTestClientApplicationService(t)
+	service.oauth = OAuthConfig{
+		SigningKey: []byte(commontestutils.RandomString(32)),
+		Issuer:     DefaultOAuthIssuer,
+		TTL:        DefaultOAuthAccessTokenTTL,
+	}
+	ctx := &gin.Context{}
+	app := createTestClientApplication(t, service)
+
+	secret, record, err := service.RotateClientSecret(ctx, app.ID, app.TenantID.String, []string{"read", "write"}, APITokenLimits{}, app.CreatedBy)
+	require.NoError(t, err)
+	require.Equal(t, secret[:TokenPrefixLength], record.SecretPrefix)
+
+	t.Run("issues and verifies a token", func(t *testing.T) {
+		token, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, []string{"read"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"read"}, token.Scopes)
+		require.True(t, IsClientCredentialsToken(token.AccessToken))
+
+		principal, err := service.VerifyClientCredentialsToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, app.ID, principal.ClientApplicationID)
+		require.Equal(t, app.TenantID.String, principal.TenantID)
+		require.Equal(t, []string{"read"}, principal.Scopes)
+	})
+
+	t.Run("rejects invalid credentials and scopes", func(t *testing.T) {
+		_, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), "cs_wrong", nil)
+		require.ErrorIs(t, err, ErrInvalidClient)
+		_, err = service.IssueClientCredentialsToken(ctx, uuid.New().String(), secret, nil)
+		require.ErrorIs(t, err, ErrInvalidClient)
+		_, err = service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, []string{"admin"})
+		require.ErrorIs(t, err, ErrInvalidScope)
+	})
+
+	t.Run("rotation revokes issued tokens", func(t *testing.T) {
+		token, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, nil)
+		require.NoError(t, err)
+
+		_, _, err = service.RotateClientSecret(ctx, app.ID, app.TenantID.String, []string{"read"}, APITokenLimits{}, app.CreatedBy)
+		require.NoError(t, err)
+
+		_, err = service.VerifyClientCredentialsToken(ctx, token.AccessToken)
+		require.ErrorIs(t, err, ErrInvalidAccessToken)
+		_, err = service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, nil)
+		require.ErrorIs(t, err, ErrInvalidClient)
+	})
+}
+
+func TestIsClientCredentialsToken(t *testing.T) {
+	sign := func(method jwt.SigningMethod, key interface{}, claims ClientCredentialsClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+	claims := ClientCredentialsClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: DefaultOAuthIssuer, Subject: uuid.NewString()},
+		SecretID:         uuid.NewString(),
+	}
+	key := []byte(commontestutils.RandomString(32))
+	require.True(t, IsClientCredentialsToken(sign(jwt.SigningMethodHS256, key, claims)))
+
+	// Other JWTs are left to the auth provider
+	require.False(t, IsClientCredentialsToken(sign(jwt.SigningMethodHS512, key, claims)))
+	noSecret := claims
+	noSecret.SecretID = ""
+	require.False(t, IsClientCredentialsToken(sign(jwt.SigningMethodHS256, key, noSecret)))
+	userSubject := claims
+	userSubject.Subject = "user-1"
+	require.False(t, IsClientCredentialsToken(sign(jwt.SigningMethodHS256, key, userSubject)))
+
+	require.False(t, IsClientCredentialsToken("header.payload.signature"))
+	require.False(t, IsClientCredentialsToken(TokenPrefix+"abc.def.ghi"))
+}
+
+// clientCredentialsRouter answers the request tenant behind the middleware, after resolving
+// the request to tenantID like the tenant middleware; an empty tenantID
+// leaves it unresolved
+func clientCredentialsRouter(tenantID string, middleware gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if tenantID != "" {
+		router.Use(func(c *gin.Context) {
+			c.Set(auth.AUTH_TENANT_ID_KEY, tenantID)
+		})
+	}
+	router.Use(middleware)
+	router.GET("/api/v1/resource", func(c *gin.Context) {
+		if _, ok := c.Get(OAuthClientKey); !ok {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.String(http.StatusOK, c.GetString(auth.AUTH_TENANT_ID_KEY))
+	})
+	return router
+}
+
+func serveAccessToken(router *gin.Engine, accessToken, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/resource", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestClientCredentialsTokenTenant(t *testing.T) {
+	service, _ := setupTestClientApplicationService(t)
+	service.oauth = OAuthConfig{
+		SigningKey: []byte(commontestutils.RandomString(32)),
+		Issuer:     DefaultOAuthIssuer,
+		TTL:        DefaultOAuthAccessTokenTTL,
+	}
+	ctx := &gin.Context{}
+	app := createTestClientApplication(t, service)
+	otherTenantID := commontestutils.RandomString(10)
+
+	secret, _, err := service.RotateClientSecret(ctx, app.ID, app.TenantID.String, []string{"read"}, APITokenLimits{}, app.CreatedBy)
+	require.NoError(t, err)
+	accessToken, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, nil)
+	require.NoError(t, err)
+	const remoteAddr = "192.0.2.10:1234"
+
+	t.Run("api token middleware", func(t *testing.T) {
+		w := serveAccessToken(clientCredentialsRouter(otherTenantID, APITokenMiddleware(service)), accessToken.AccessToken, remoteAddr)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		w = serveAccessToken(clientCredentialsRouter(app.TenantID.String, APITokenMiddleware(service)), accessToken.AccessToken, remoteAddr)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// Without a resolved tenant the token tenant is set
+		w = serveAccessToken(clientCredentialsRouter("", APITokenMiddleware(service)), accessToken.AccessToken, remoteAddr)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, app.TenantID.String, w.Body.String())
+	})
+
+	t.Run("auth middleware", func(t *testing.T) {
+		middleware := NewAuthMiddleware(&stubAuthProvider{}, service).MiddlewareFunc()
+		w := serveAccessToken(clientCredentialsRouter(otherTenantID, middleware), accessToken.AccessToken, remoteAddr)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		w = serveAccessToken(clientCredentialsRouter(app.TenantID.String, middleware), accessToken.AccessToken, remoteAddr)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("api token of another tenant", func(t *testing.T) {
+		tokenString, _, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, "token", "", 30, app.CreatedBy, []string{"read"})
+		require.NoError(t, err)
+		router := clientCredentialsRouter(otherTenantID, APITokenMiddleware(service))
+		w := serveAccessToken(router, tokenString, remoteAddr)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestClientCredentialsTokenLimits(t *testing.T) {
+	service, _ := setupTestClientApplicationService(t)
+	service.oauth = OAuthConfig{
+		SigningKey: []byte(commontestutils.RandomString(32)),
+		Issuer:     DefaultOAuthIssuer,
+		TTL:        DefaultOAuthAccessTokenTTL,
+	}
+	limiter, _ := newTestMemoryRateLimiter()
+	service.rateLimiter = limiter
+	ctx := &gin.Context{}
+	app := createTestClientApplication(t, service)
+
+	perMinute := int32(2)
+	_, _, err := service.RotateClientSecret(ctx, app.ID, app.TenantID.String, nil, APITokenLimits{RateLimitPerMinute: new(int32)}, app.CreatedBy)
+	require.ErrorIs(t, err, ErrInvalidAPITokenRateLimit)
+	_, _, err = service.RotateClientSecret(ctx, app.ID, app.TenantID.String, nil, APITokenLimits{AllowedCIDRs: []string{"10.0.0.0/33"}}, app.CreatedBy)
+	require.ErrorIs(t, err, ErrInvalidCIDR)
+
+	secret, record, err := service.RotateClientSecret(ctx, app.ID, app.TenantID.String, nil, APITokenLimits{
+		RateLimitPerMinute: &perMinute,
+		AllowedCIDRs:       []string{"10.0.0.0/8"},
+	}, app.CreatedBy)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/8"}, record.AllowedCidrs)
+	accessToken, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, nil)
+	require.NoError(t, err)
+	router := clientCredentialsRouter(app.TenantID.String, APITokenMiddleware(service))
+
+	w := serveAccessToken(router, accessToken.AccessToken, "192.0.2.10:1234")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), ErrClientIPNotAllowed.Error())
+
+	// The budget is shared by the tokens issued with the secret
+	other, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, serveAccessToken(router, accessToken.AccessToken, "10.1.2.3:1234").Code)
+	require.Equal(t, http.StatusOK, serveAccessToken(router, other.AccessToken, "10.1.2.3:1234").Code)
+	w = serveAccessToken(router, accessToken.AccessToken, "10.1.2.3:1234")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestTokenIntrospection(t *testing.T) {
+	service, _ := setupTestClientApplicationService(t)
+	service.oauth = OAuthConfig{
+		SigningKey: []byte(commontestutils.RandomString(32)),
+		Issuer:     DefaultOAuthIssuer,
+		TTL:        DefaultOAuthAccessTokenTTL,
+	}
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	caller := createTestClientApplication(t, service)
+	callerSecret, _, err := service.RotateClientSecret(ctx, caller.ID, caller.TenantID.String, nil, APITokenLimits{}, caller.CreatedBy)
+	require.NoError(t, err)
+
+	app, err := service.CreateClientApplication(ctx, caller.TenantID.String, commontestutils.RandomString(10), "", "creator")
+	require.NoError(t, err)
+	tokenString, apiToken, err := service.CreateAPIToken(ctx, app.ID, app.TenantID.String, "token", "", 30, "creator", []string{"read"})
+	require.NoError(t, err)
+
+	t.Run("active api token", func(t *testing.T) {
+		result, err := service.IntrospectToken(ctx, caller.ID.String(), callerSecret, tokenString)
+		require.NoError(t, err)
+		require.True(t, result.Active)
+		require.Equal(t, IntrospectedTokenTypeAPIToken, result.TokenType)
+		require.Equal(t, app.ID.String(), result.ClientID)
+		require.Equal(t, caller.TenantID.String, result.TenantID)
+		require.Equal(t, []string{"read"}, result.Scopes)
+		require.Equal(t, apiToken.ExpiresAt.Unix(), result.ExpiresAt.Unix())
+	})
+
+	t.Run("active access token", func(t *testing.T) {
+		accessToken, err := service.IssueClientCredentialsToken(ctx, caller.ID.String(), callerSecret, nil)
+		require.NoError(t, err)
+		result, err := service.IntrospectToken(ctx, caller.ID.String(), callerSecret, accessToken.AccessToken)
+		require.NoError(t, err)
+		require.True(t, result.Active)
+		require.Equal(t, IntrospectedTokenTypeAccessToken, result.TokenType)
+		require.Equal(t, caller.ID.String(), result.ClientID)
+		require.False(t, result.ExpiresAt.IsZero())
+	})
+
+	t.Run("unknown and revoked tokens are inactive", func(t *testing.T) {
+		result, err := service.IntrospectToken(ctx, caller.ID.String(), callerSecret, TokenPrefix+commontestutils.RandomString(32))
+		require.NoError(t, err)
+		require.False(t, result.Active)
+
+		_, err = service.RevokeAPIToken(ctx, apiToken.ID, app.TenantID.String, "test", "admin")
+		require.NoError(t, err)
+		result, err = service.IntrospectToken(ctx, caller.ID.String(), callerSecret, tokenString)
+		require.NoError(t, err)
+		require.False(t, result.Active)
+	})
+
+	t.Run("tokens of another tenant are inactive", func(t *testing.T) {
+		other := createTestClientApplication(t, service)
+		otherToken, _, err := service.CreateAPIToken(ctx, other.ID, other.TenantID.String, "token", "", 30, "creator", nil)
+		require.NoError(t, err)
+		result, err := service.IntrospectToken(ctx, caller.ID.String(), callerSecret, otherToken)
+		require.NoError(t, err)
+		require.False(t, result.Active)
+	})
+
+	t.Run("rejects invalid client credentials", func(t *testing.T) {
+		_, err := service.IntrospectToken(ctx, caller.ID.String(), "cs_wrong", tokenString)
+		require.ErrorIs(t, err, ErrInvalidClient)
+	})
+}
+
+func TestSignedRequests(t *testing.T) {
+	service, _ := setupTestClientApplicationService(t)
+	service.signing = RequestSigningConfig{
+		MasterKey: []byte(commontestutils.RandomString(32)),
+		ClockSkew: time.Minute,
+	}
+	ctx := &gin.Context{}
+	app := createTestClientApplication(t, service)
+
+	secret, _, err := service.RotateSigningKey(ctx, app.ID, app.TenantID.String, []string{"read"}, app.CreatedBy)
+	require.NoError(t, err)
+
+	signedContext := func(secret string, timestamp int64, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/items?page=1", strings.NewReader(body))
+		c.Request.Header.Set(SignatureClientIDHeader, app.ID.String())
+		c.Request.Header.Set(SignatureTimestampHeader, strconv.FormatInt(timestamp, 10))
+		c.Request.Header.Set(SignatureHeader, RequestSignature(secret, http.MethodPost, "/api/v1/items?page=1", timestamp, []byte(body)))
+		return c
+	}
+
+	t.Run("verifies and keeps the body readable", func(t *testing.T) {
+		c := signedContext(secret, time.Now().Unix(), `{"name":"item"}`)
+		principal, err := service.VerifySignedRequest(c)
+		require.NoError(t, err)
+		require.Equal(t, app.ID, principal.ClientApplicationID)
+		require.Equal(t, []string{"read"}, principal.Scopes)
+
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"name":"item"}`, string(body))
+	})
+
+	t.Run("rejects replays, stale timestamps and tampering", func(t *testing.T) {
+		timestamp := time.Now().Unix()
+		_, err := service.VerifySignedRequest(signedContext(secret, timestamp, "replayed"))
+		require.NoError(t, err)
+		_, err = service.VerifySignedRequest(signedContext(secret, timestamp, "replayed"))
+		require.ErrorIs(t, err, ErrSignatureReplayed)
+
+		_, err = service.VerifySignedRequest(signedContext(secret, time.Now().Add(-2*time.Minute).Unix(), ""))
+		require.ErrorIs(t, err, ErrSignatureExpired)
+
+		c := signedContext(secret, time.Now().Unix(), "original")
+		c.Request.Body = io.NopCloser(strings.NewReader("tampered"))
+		_, err = service.VerifySignedRequest(c)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("rotation invalidates the previous secret", func(t *testing.T) {
+		_, _, err := service.RotateSigningKey(ctx, app.ID, app.TenantID.String, nil, app.CreatedBy)
+		require.NoError(t, err)
+		_, err = service.VerifySignedRequest(signedContext(secret, time.Now().Unix(), "after rotation"))
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestClientApplicationConfigExportImport(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	service.signing = RequestSigningConfig{MasterKey: []byte(commontestutils.RandomString(32))}
+	app := createTestClientApplication(t, service)
+	tenantID := app.TenantID.String
+
+	perMinute := int32(60)
+	_, token, err := service.CreateAPIToken(ctx, app.ID, tenantID, "reporting", "nightly export", 30, app.CreatedBy, []string{"reports:read"})
+	require.NoError(t, err)
+	require.NoError(t, service.SetAPITokenRateLimits(ctx, token.ID, &perMinute, nil))
+	_, err = service.SetAPITokenAllowedCIDRs(ctx, token.ID, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	_, _, err = service.RotateClientSecret(ctx, app.ID, tenantID, []string{"reports:read"}, APITokenLimits{}, app.CreatedBy)
+	require.NoError(t, err)
+	_, _, err = service.SetWebhook(ctx, app.ID, tenantID, "https://hooks.example.com/credentials", app.CreatedBy)
+	require.NoError(t, err)
+
+	config, err := service.ExportClientApplicationConfig(ctx, app.ID, tenantID)
+	require.NoError(t, err)
+	require.Equal(t, app.Name, config.Name)
+	require.Len(t, config.Tokens, 1)
+	require.Equal(t, 30, config.Tokens[0].ExpiresInDays)
+	require.Equal(t, []string{"10.0.0.0/8"}, config.Tokens[0].AllowedCIDRs)
+	require.Equal(t, &perMinute, config.Tokens[0].RateLimitPerMinute)
+	require.NotNil(t, config.ClientCredentials)
+	require.Nil(t, config.RequestSigning)
+	require.Equal(t, "https://hooks.example.com/credentials", config.WebhookURL)
+
+	t.Run("import creates new secrets", func(t *testing.T) {
+		targetTenant := commontestutils.RandomString(10)
+		imported, err := service.ImportClientApplicationConfig(ctx, targetTenant, config, app.CreatedBy)
+		require.NoError(t, err)
+		require.NotEqual(t, app.ID, imported.Application.ID)
+		require.Equal(t, targetTenant, imported.Application.TenantID.String)
+		require.Len(t, imported.Tokens, 1)
+		require.NotEqual(t, token.TokenPrefix, imported.Tokens[0].APIToken.TokenPrefix)
+		require.Equal(t, []string{"10.0.0.0/8"}, imported.Tokens[0].APIToken.AllowedCidrs)
+		require.NotEmpty(t, imported.ClientSecret)
+		require.NotEmpty(t, imported.WebhookSecret)
+
+		roundTrip, err := service.ExportClientApplicationConfig(ctx, imported.Application.ID, targetTenant)
+		require.NoError(t, err)
+		require.Equal(t, config, roundTrip)
+	})
+
+	t.Run("invalid configuration creates nothing", func(t *testing.T) {
+		invalid := config
+		invalid.Tokens = []APITokenConfig{{Name: "bad", AllowedCIDRs: []string{"not-an-ip"}}}
+		targetTenant := commontestutils.RandomString(10)
+		_, err := service.ImportClientApplicationConfig(ctx, targetTenant, invalid, app.CreatedBy)
+		require.ErrorIs(t, err, ErrInvalidClientApplicationConfig)
+
+		apps, err := service.ListClientApplications(ctx, targetTenant, 10, 0, "name", "asc", "", false)
+		require.NoError(t, err)
+		require.Empty(t, apps)
+	})
+}
+
+func TestCountClientApplications(t *testing.T) {
+	service, _ := setupTestClientApplicationService(t)
+	ctx := &gin.Context{}
+	tenantID := commontestutils.RandomString(10)
+
+	for _, name := range []string{"billing-sync", "billing-export", "crm"} {
+		_, err := service.CreateClientApplication(ctx, tenantID, name, "", "creator")
+		require.NoError(t, err)
+	}
+
+	total, err := service.CountClientApplications(ctx, tenantID, "", false)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+
+	total, err = service.CountClientApplications(ctx, tenantID, "billing", false)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+
+	total, err = service.CountClientApplications(ctx, commontestutils.RandomString(10), "", false)
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
+func TestClientApplicationServiceAccount(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	service.oauth = OAuthConfig{
+		SigningKey: []byte(commontestutils.RandomString(32)),
+		Issuer:     DefaultOAuthIssuer,
+		TTL:        DefaultOAuthAccessTokenTTL,
+	}
+	app := createTestClientApplication(t, service)
+	tenantID := app.TenantID.String
+
+	tokenString, _, err := service.CreateAPIToken(ctx, app.ID, tenantID, commontestutils.RandomString(10), "", 30, app.CreatedBy, nil)
+	require.NoError(t, err)
+	secret, _, err := service.RotateClientSecret(ctx, app.ID, tenantID, []string{"read"}, APITokenLimits{}, app.CreatedBy)
+	require.NoError(t, err)
+
+	t.Run("requests run as the creator until a service account is linked", func(t *testing.T) {
+		token, err := service.VerifyAPIToken(ctx, tokenString)
+		require.NoError(t, err)
+		require.Equal(t, app.CreatedBy, requestUserID(token.ServiceAccountID, token.CreatedBy))
+	})
+
+	t.Run("requests run as the linked service account", func(t *testing.T) {
+		linked, err := service.LinkServiceAccount(ctx, app.ID, tenantID, app.CreatedBy)
+		require.NoError(t, err)
+		require.Equal(t, ServiceAccountID(app.ID), linked.ServiceAccountID.String)
+		require.True(t, IsServiceAccountID(linked.ServiceAccountID.String))
+
+		token, err := service.VerifyAPIToken(ctx, tokenString)
+		require.NoError(t, err)
+		require.Equal(t, ServiceAccountID(app.ID), requestUserID(token.ServiceAccountID, token.CreatedBy))
+
+		accessToken, err := service.IssueClientCredentialsToken(ctx, app.ID.String(), secret, nil)
+		require.NoError(t, err)
+		principal, err := service.VerifyClientCredentialsToken(ctx, accessToken.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, ServiceAccountID(app.ID), principal.UserID())
+	})
+
+	t.Run("unlinking restores the creator", func(t *testing.T) {
+		unlinked, err := service.UnlinkServiceAccount(ctx, app.ID, tenantID, app.CreatedBy)
+		require.NoError(t, err)
+		require.False(t, unlinked.ServiceAccountID.Valid)
+
+		_, err = service.LinkServiceAccount(ctx, app.ID, commontestutils.RandomString(10), app.CreatedBy)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Credential lifecycle events POSTed to the webhook of a client application
+const (
+	APITokenCreatedWebhookEvent              = "api_token.created"
+	APITokenRevokedWebhookEvent              = "api_token.revoked"
+	APITokenExpiredWebhookEvent              = "api_token.expired"
+	ClientApplicationDeactivatedWebhookEvent = "client_application.deactivated"
+
+	WebhookSecretPrefix = "whsec_"
+
+	// Covers every attempt of the dispatcher with its backoff
+	clientApplicationWebhookTimeout = 2 * time.Minute
+)
+
+// ErrInvalidWebhookURL is returned when the webhook URL is not an https URL of
+// a public host
+var ErrInvalidWebhookURL = errors.New("invalid webhook URL")
+
+// ClientApplicationWebhookEvent is the data of a credential lifecycle webhook.
+// The token fields are empty for application events.
+type ClientApplicationWebhookEvent struct {
+	ClientApplicationID string     `json:"clientApplicationId"`
+	TokenID             string     `json:"tokenId,omitempty"`
+	TokenName           string     `json:"tokenName,omitempty"`
+	TokenPrefix         string     `json:"tokenPrefix,omitempty"`
+	ExpiresAt           *time.Time `json:"expiresAt,omitempty"`
+	Reason              string     `json:"reason,omitempty"`
+	Actor               string     `json:"actor,omitempty"`
+}
+
+// SetWebhook creates or replaces the webhook of the application. The secret
+// signing the deliveries is only returned here; like the request signing
+// secret it is derived from the master key, and changes with every update.
+func (s *ClientApplicationService) SetWebhook(ctx context.Context, clientApplicationID uuid.UUID, tenantID string,
+	webhookURL, createdBy string) (string, repository.CoreClientApplicationWebhook, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if len(s.signing.MasterKey) == 0 {
+		return "", repository.CoreClientApplicationWebhook{}, ErrRequestSigningNotConfigured
+	}
+	if err := s.validateWebhookURL(ctx, webhookURL); err != nil {
+		return "", repository.CoreClientApplicationWebhook{}, err
+	}
+
+	// Scope the application to the caller's tenant
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return "", repository.CoreClientApplicationWebhook{}, err
+	}
+
+	record, err := s.store.UpsertClientApplicationWebhook(ctx, repository.UpsertClientApplicationWebhookParams{
+		ClientApplicationID: clientApplicationID,
+		Url:                 webhookURL,
+		CreatedBy:           createdBy,
+	})
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to store webhook")
+		return "", repository.CoreClientApplicationWebhook{}, err
+	}
+	return s.webhookSecret(record.ID), record, nil
+}
+
+// GetWebhook returns the webhook of the application, without its secret
+func (s *ClientApplicationService) GetWebhook(ctx context.Context, clientApplicationID uuid.UUID, tenantID string) (repository.CoreClientApplicationWebhook, error) {
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return repository.CoreClientApplicationWebhook{}, err
+	}
+	return s.store.GetClientApplicationWebhook(ctx, clientApplicationID)
+}
+
+// DeleteWebhook removes the webhook of the application
+func (s *ClientApplicationService) DeleteWebhook(ctx context.Context, clientApplicationID uuid.UUID, tenantID string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteClientApplicationWebhook(ctx, clientApplicationID)
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to delete webhook")
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// validateWebhookURL refuses the URLs the webhook dispatcher would not
+// deliver to. The server POSTs from its own network, so a tenant must not
+// reach internal addresses through a webhook.
+func (s *ClientApplicationService) validateWebhookURL(ctx context.Context, webhookURL string) error {
+	if err := s.webhooks.ValidateTarget(ctx, webhookURL); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhookURL, err)
+	}
+	return nil
+}
+
+func (s *ClientApplicationService) webhookSecret(webhookID uuid.UUID) string {
+	mac := hmac.New(sha256.New, s.signing.MasterKey)
+	mac.Write([]byte("client-application-webhook:"))
+	mac.Write(webhookID[:])
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// notifyWebhook delivers the event to the webhook of the application, if it
+// has one. The delivery runs in the background, outliving the request, and
+// its failures are only logged: the change it reports is already committed.
+func (s *ClientApplicationService) notifyWebhook(ctx context.Context, eventType string, event ClientApplicationWebhookEvent) {
+	// Only the request logger is kept: ctx may be a gin context, which is
+	// reused once the request is served
+	logger := util.GetLoggerFromCtx(ctx)
+	ctx = context.WithValue(context.Background(), util.LoggerKey, logger)
+	go func() {
+		if _, err := s.deliverWebhook(ctx, eventType, event); err != nil {
+			logger.Err(err).Str("event", eventType).Str("clientApplicationID", event.ClientApplicationID).Msg("Failed to deliver client application webhook")
+		}
+	}()
+}
+
+// deliverWebhook sends the event and reports whether the application has a
+// webhook
+func (s *ClientApplicationService) deliverWebhook(ctx context.Context, eventType string, event ClientApplicationWebhookEvent) (bool, error) {
+	if len(s.signing.MasterKey) == 0 {
+		return false, nil
+	}
+	applicationID, err := uuid.Parse(event.ClientApplicationID)
+	if err != nil {
+		return false, err
+	}
+	hook, err := s.store.GetClientApplicationWebhook(ctx, applicationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, clientApplicationWebhookTimeout)
+	defer cancel()
+	envelope := webhook.Envelope{
+		ID:        uuid.New().String(),
+		EventType: eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      event,
+	}
+	target := webhook.Target{URL: hook.Url, Secret: s.webhookSecret(hook.ID)}
+	return true, s.webhooks.Deliver(ctx, target, envelope, "")
+}
+
+// StartExpiredTokenWebhooks runs NotifyExpiredAPITokens now and then every
+// interval until ctx is done. It does nothing when request signing is not
+// configured, as no webhook can be signed.
+func (s *ClientApplicationService) StartExpiredTokenWebhooks(ctx context.Context, interval time.Duration) {
+	if len(s.signing.MasterKey) == 0 {
+		log.Info().Msg("Client application webhooks disabled, REQUEST_SIGNING_MASTER_KEY is not set")
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultTokenExpiryCheckInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.NotifyExpiredAPITokens(ctx); err != nil {
+				log.Err(err).Msg("Expired API token scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// NotifyExpiredAPITokens sends the api_token.expired event of the tokens that
+// expired since the webhook of their application was set. Each notice is
+// recorded as an EXPIRED audit entry first, so a token is notified once even
+// when several instances scan. It returns the number of tokens notified.
+func (s *ClientApplicationService) NotifyExpiredAPITokens(ctx context.Context) (int, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+
+	notified := 0
+	for notified < tokenExpiryNotificationMaxTokens {
+		tokens, err := s.store.ListExpiredAPITokensWithoutNotice(ctx, tokenExpiryScanBatchSize)
+		if err != nil {
+			logger.Err(err).Msg("Failed to list expired API tokens")
+			return notified, fmt.Errorf("service.NotifyExpiredAPITokens: %w", err)
+		}
+		for _, token := range tokens {
+			additionalData, err := json.Marshal(map[string]interface{}{"expires_at": token.ExpiresAt})
+			if err != nil {
+				return notified, fmt.Errorf("service.NotifyExpiredAPITokens: %w", err)
+			}
+			_, err = s.store.CreateAPITokenExpiredNotice(ctx, repository.CreateAPITokenExpiredNoticeParams{
+				TokenID:        token.ID,
+				AdditionalData: additionalData,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				logger.Err(err).Str("tokenID", token.ID.String()).Msg("Failed to record API token expiry")
+				return notified, fmt.Errorf("service.NotifyExpiredAPITokens: %w", err)
+			}
+			expiresAt := token.ExpiresAt
+			s.notifyWebhook(ctx, APITokenExpiredWebhookEvent, ClientApplicationWebhookEvent{
+				ClientApplicationID: token.ClientApplicationID.String(),
+				TokenID:             token.ID.String(),
+				TokenName:           token.Name,
+				TokenPrefix:         token.TokenPrefix,
+				ExpiresAt:           &expiresAt,
+			})
+			notified++
+		}
+		if len(tokens) < tokenExpiryScanBatchSize {
+			break
+		}
+	}
+	if notified > 0 {
+		logger.Info().Int("tokens", notified).Msg("Sent API token expired webhooks")
+	}
+	return notified, nil
+}
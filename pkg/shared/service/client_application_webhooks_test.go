@@ -0,0 +1,42 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWebhookRejectsInternalURLs(t *testing.T) {
+	service := &ClientApplicationService{
+		signing:  RequestSigningConfig{MasterKey: []byte("0123456789abcdef0123456789abcdef")},
+		webhooks: webhook.NewPublicDispatcher(),
+	}
+	for _, rawURL := range []string{
+		"ftp://example.com/hook",
+		"http://93.184.216.34/hook",
+		"https://localhost/hook",
+		"https://127.0.0.1:8080/hook",
+		"https://10.0.0.12/hook",
+		"https://172.20.1.1/hook",
+		"https://169.254.169.254/latest/meta-data/iam",
+		"https://[::1]/hook",
+	} {
+		_, _, err := service.SetWebhook(t.Context(), uuid.New(), "tenant-1", rawURL, "admin-1")
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+	}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	_, err := service.ImportClientApplicationConfig(ctx, "tenant-1", ClientApplicationConfig{
+		Version:    ClientApplicationConfigVersion,
+		Name:       "imported",
+		WebhookURL: "https://192.168.0.10/hook",
+	}, "admin-1")
+	require.ErrorIs(t, err, ErrInvalidWebhookURL)
+}
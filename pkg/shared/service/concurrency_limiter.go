@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultConcurrencyPerTenant = 10
+	DefaultConcurrencyPerUser   = 2
+	DefaultConcurrencyQueueSize = 20
+	DefaultConcurrencyMaxWait   = 10 * time.Second
+	// Used for Retry-After until a request has completed
+	defaultConcurrencyDuration = 5 * time.Second
+)
+
+// ConcurrencyLimiterConfig bounds the requests in flight on the routes guarded
+// by a ConcurrencyLimiter, such as prompt executions. A zero limit disables
+// that scope.
+//
+// Environment:
+//   - PROMPT_CONCURRENCY_PER_TENANT: in-flight requests per tenant (default 10)
+//   - PROMPT_CONCURRENCY_PER_USER: in-flight requests per user (default 2)
+//   - PROMPT_CONCURRENCY_QUEUE_SIZE: requests waiting for a slot per tenant or
+//     user before new ones are rejected (default 20, 0 rejects at once)
+//   - PROMPT_CONCURRENCY_MAX_WAIT: how long a queued request waits for a slot
+//     (default 10s)
+type ConcurrencyLimiterConfig struct {
+	PerTenant int
+	PerUser   int
+	QueueSize int
+	MaxWait   time.Duration
+}
+
+func ConcurrencyLimiterConfigFromEnv() ConcurrencyLimiterConfig {
+	cfg := ConcurrencyLimiterConfig{
+		PerTenant: DefaultConcurrencyPerTenant,
+		PerUser:   DefaultConcurrencyPerUser,
+		QueueSize: DefaultConcurrencyQueueSize,
+		MaxWait:   DefaultConcurrencyMaxWait,
+	}
+	for name, target := range map[string]*int{
+		"PROMPT_CONCURRENCY_PER_TENANT": &cfg.PerTenant,
+		"PROMPT_CONCURRENCY_PER_USER":   &cfg.PerUser,
+		"PROMPT_CONCURRENCY_QUEUE_SIZE": &cfg.QueueSize,
+	} {
+		if v := os.Getenv(name); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+				*target = n
+			} else {
+				log.Warn().Str(name, v).Msg("Invalid value, using default")
+			}
+		}
+	}
+	if v := os.Getenv("PROMPT_CONCURRENCY_MAX_WAIT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.MaxWait = d
+		} else {
+			log.Warn().Str("PROMPT_CONCURRENCY_MAX_WAIT", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// concurrencySlots counts the requests in flight for a tenant or a user, and
+// the requests waiting for one of them to finish, first come first served
+type concurrencySlots struct {
+	inFlight int
+	waiters  []chan struct{}
+}
+
+// ConcurrencyLimiter caps the requests in flight per tenant and per user.
+// Requests over the cap wait in a bounded queue; those that cannot get a slot
+// are rejected with 429, their position in the queue and an estimate of when
+// to retry. Limits are per instance.
+type ConcurrencyLimiter struct {
+	cfg   ConcurrencyLimiterConfig
+	mu    sync.Mutex
+	slots map[string]*concurrencySlots
+	// avgDuration is a moving average of the guarded requests, for Retry-After
+	avgDuration time.Duration
+}
+
+func NewConcurrencyLimiter(cfg ConcurrencyLimiterConfig) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		cfg:         cfg,
+		slots:       make(map[string]*concurrencySlots),
+		avgDuration: defaultConcurrencyDuration,
+	}
+}
+
+// ConcurrencyRejection tells why a request did not get a slot
+type ConcurrencyRejection struct {
+	// Scope is "tenant" or "user"
+	Scope string
+	Limit int
+	// QueuePosition is the place the request had, or would have had, in the
+	// queue of the scope; 1 is next
+	QueuePosition int
+	RetryAfter    time.Duration
+}
+
+// Acquire takes a slot for the tenant and for the user, waiting in the queues
+// up to MaxWait. An empty tenant or user is not limited. On success release
+// must be called once the request is done; otherwise the rejection is returned.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, tenantID, userID string) (release func(), rejection *ConcurrencyRejection) {
+	// The user slot is taken first, so a user waiting on their own limit does
+	// not hold a slot of the tenant meanwhile
+	var releases []func()
+	releaseAll := func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for _, scope := range []struct {
+		name  string
+		key   string
+		limit int
+	}{
+		{"user", userID, l.cfg.PerUser},
+		{"tenant", tenantID, l.cfg.PerTenant},
+	} {
+		if scope.key == "" || scope.limit <= 0 {
+			continue
+		}
+		r, position := l.acquire(ctx, scope.name+":"+scope.key, scope.limit)
+		if r == nil {
+			releaseAll()
+			return nil, &ConcurrencyRejection{
+				Scope:         scope.name,
+				Limit:         scope.limit,
+				QueuePosition: position,
+				RetryAfter:    l.retryAfter(position, scope.limit),
+			}
+		}
+		releases = append(releases, r)
+	}
+
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.observe(time.Since(start))
+			releaseAll()
+		})
+	}, nil
+}
+
+// acquire takes a slot of the key. It returns a nil release and the queue
+// position when the queue is full or the wait timed out.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, key string, limit int) (func(), int) {
+	l.mu.Lock()
+	slots, ok := l.slots[key]
+	if !ok {
+		slots = &concurrencySlots{}
+		l.slots[key] = slots
+	}
+	if slots.inFlight < limit && len(slots.waiters) == 0 {
+		slots.inFlight++
+		l.mu.Unlock()
+		return func() { l.release(key) }, 0
+	}
+	position := len(slots.waiters) + 1
+	if position > l.cfg.QueueSize || l.cfg.MaxWait <= 0 {
+		l.mu.Unlock()
+		return nil, position
+	}
+	ready := make(chan struct{})
+	slots.waiters = append(slots.waiters, ready)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.MaxWait)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return func() { l.release(key) }, 0
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, w := range slots.waiters {
+		if w == ready {
+			slots.waiters = append(slots.waiters[:i], slots.waiters[i+1:]...)
+			return nil, i + 1
+		}
+	}
+	// The slot was handed over while timing out, keep it
+	return func() { l.release(key) }, 0
+}
+
+// release hands the slot to the first waiter, or frees it
+func (l *ConcurrencyLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots := l.slots[key]
+	if len(slots.waiters) > 0 {
+		close(slots.waiters[0])
+		slots.waiters = slots.waiters[1:]
+		return
+	}
+	slots.inFlight--
+	if slots.inFlight == 0 {
+		delete(l.slots, key)
+	}
+}
+
+func (l *ConcurrencyLimiter) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.avgDuration = (l.avgDuration*4 + d) / 5
+}
+
+// retryAfter estimates when the request at position would get a slot, each
+// round of limit requests taking the average duration
+func (l *ConcurrencyLimiter) retryAfter(position, limit int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rounds := math.Ceil(float64(position) / float64(limit))
+	return time.Duration(rounds) * l.avgDuration
+}
+
+// Middleware guards the routes it is applied to, such as prompt executions,
+// per tenant and user of the request. It must run after the tenant and auth
+// middlewares. Rejected requests get 429 with Retry-After and
+// X-Queue-Position headers.
+func (l *ConcurrencyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		release, rejection := l.Acquire(c.Request.Context(), c.GetString(auth.AUTH_TENANT_ID_KEY), c.GetString(auth.AUTH_USER_ID))
+		if rejection != nil {
+			retryAfterSeconds := int(math.Ceil(rejection.RetryAfter.Seconds()))
+			logger.Warn().
+				Str("scope", rejection.Scope).
+				Int("limit", rejection.Limit).
+				Int("queuePosition", rejection.QueuePosition).
+				Msg("Too many requests in flight")
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+			c.Header("X-Queue-Position", strconv.Itoa(rejection.QueuePosition))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"status":            http.StatusTooManyRequests,
+				"message":           "Too many requests in flight for this " + rejection.Scope,
+				"scope":             rejection.Scope,
+				"limit":             rejection.Limit,
+				"queuePosition":     rejection.QueuePosition,
+				"retryAfterSeconds": retryAfterSeconds,
+			})
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
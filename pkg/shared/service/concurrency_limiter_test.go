@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejects over the user limit with the queue position", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(ConcurrencyLimiterConfig{PerTenant: 10, PerUser: 1})
+		release, rejection := limiter.Acquire(ctx, "t1", "u1")
+		require.Nil(t, rejection)
+
+		_, rejection = limiter.Acquire(ctx, "t1", "u1")
+		require.NotNil(t, rejection)
+		require.Equal(t, "user", rejection.Scope)
+		require.Equal(t, 1, rejection.QueuePosition)
+		require.Positive(t, rejection.RetryAfter)
+
+		other, rejection := limiter.Acquire(ctx, "t1", "u2")
+		require.Nil(t, rejection)
+		other()
+
+		release()
+		release, rejection = limiter.Acquire(ctx, "t1", "u1")
+		require.Nil(t, rejection)
+		release()
+	})
+
+	t.Run("a user rejected by the tenant limit gives their slot back", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(ConcurrencyLimiterConfig{PerTenant: 1, PerUser: 1})
+		release, rejection := limiter.Acquire(ctx, "t1", "u1")
+		require.Nil(t, rejection)
+
+		_, rejection = limiter.Acquire(ctx, "t1", "u2")
+		require.NotNil(t, rejection)
+		require.Equal(t, "tenant", rejection.Scope)
+
+		release()
+		release, rejection = limiter.Acquire(ctx, "t1", "u2")
+		require.Nil(t, rejection)
+		release()
+	})
+
+	t.Run("queued requests get the slot in order", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(ConcurrencyLimiterConfig{PerTenant: 1, QueueSize: 1, MaxWait: time.Second})
+		release, rejection := limiter.Acquire(ctx, "t1", "")
+		require.Nil(t, rejection)
+
+		acquired := make(chan func())
+		go func() {
+			queued, rejection := limiter.Acquire(ctx, "t1", "")
+			require.Nil(t, rejection)
+			acquired <- queued
+		}()
+		require.Eventually(t, func() bool {
+			limiter.mu.Lock()
+			defer limiter.mu.Unlock()
+			return len(limiter.slots["tenant:t1"].waiters) == 1
+		}, time.Second, time.Millisecond)
+
+		// The queue is full
+		_, rejection = limiter.Acquire(ctx, "t1", "")
+		require.NotNil(t, rejection)
+		require.Equal(t, 2, rejection.QueuePosition)
+
+		release()
+		(<-acquired)()
+		require.Empty(t, limiter.slots)
+	})
+
+	t.Run("a queued request times out", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(ConcurrencyLimiterConfig{PerUser: 1, QueueSize: 5, MaxWait: 10 * time.Millisecond})
+		release, _ := limiter.Acquire(ctx, "", "u1")
+		defer release()
+
+		_, rejection := limiter.Acquire(ctx, "", "u1")
+		require.NotNil(t, rejection)
+		require.Equal(t, 1, rejection.QueuePosition)
+		require.Empty(t, limiter.slots["user:u1"].waiters)
+	})
+
+	t.Run("the middleware answers 429 with hints", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		limiter := NewConcurrencyLimiter(ConcurrencyLimiterConfig{PerUser: 1})
+		release, _ := limiter.Acquire(ctx, "t1", "u1")
+		defer release()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/prompts/execute", nil)
+		c.Set(auth.AUTH_TENANT_ID_KEY, "t1")
+		c.Set(auth.AUTH_USER_ID, "u1")
+		limiter.Middleware()(c)
+
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "1", w.Header().Get("X-Queue-Position"))
+		require.NotEmpty(t, w.Header().Get("Retry-After"))
+	})
+}
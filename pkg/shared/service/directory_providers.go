@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/oauth2/google"
+)
+
+// Directory providers
+const (
+	DirectoryProviderGoogleWorkspace = "google_workspace"
+	DirectoryProviderMicrosoft365    = "microsoft_365"
+)
+
+const (
+	googleDirectoryBaseURL  = "https://admin.googleapis.com/admin/directory/v1"
+	microsoftGraphBaseURL   = "https://graph.microsoft.com/v1.0"
+	microsoftLoginBaseURL   = "https://login.microsoftonline.com"
+	directoryResponseLimit  = 10 << 20
+	googleDirectoryPageSize = "500"
+	microsoftGraphPageSize  = "999"
+)
+
+// DirectoryUser is an account of the directory of a tenant
+type DirectoryUser struct {
+	ExternalID string
+	Email      string
+	Name       string
+	// Active is false for a suspended, archived or disabled account
+	Active bool
+}
+
+// DirectoryClient reads the users and the groups of a directory
+type DirectoryClient interface {
+	ListUsers(ctx context.Context) ([]DirectoryUser, error)
+	// ListGroupMembers returns the ids of the users of the group, including
+	// those of its nested groups
+	ListGroupMembers(ctx context.Context, group string) ([]string, error)
+}
+
+// DirectorySettings locates the directory. Google Workspace uses Customer,
+// Domain and AdminEmail; Microsoft 365 uses DirectoryID and ClientID.
+type DirectorySettings struct {
+	// Customer is the Google customer id, my_customer by default
+	Customer string `json:"customer,omitempty"`
+	// Domain limits the Google users to one domain of the customer
+	Domain string `json:"domain,omitempty"`
+	// AdminEmail is the administrator the Google service account acts as,
+	// through domain-wide delegation
+	AdminEmail string `json:"adminEmail,omitempty"`
+	// DirectoryID is the Microsoft Entra tenant id
+	DirectoryID string `json:"directoryId,omitempty"`
+	// ClientID is the id of the Microsoft Entra application
+	ClientID string `json:"clientId,omitempty"`
+}
+
+// validateDirectorySettings checks the settings and the credentials of a
+// provider: the key of a Google service account, or the secret of a
+// Microsoft Entra application
+func validateDirectorySettings(provider string, settings DirectorySettings, credentials string) error {
+	switch provider {
+	case DirectoryProviderGoogleWorkspace:
+		if settings.AdminEmail == "" {
+			return errors.New("adminEmail is required for Google Workspace")
+		}
+		if _, err := google.JWTConfigFromJSON([]byte(credentials)); err != nil {
+			return errors.New("the credentials must be the JSON key of a service account")
+		}
+	case DirectoryProviderMicrosoft365:
+		if settings.DirectoryID == "" || settings.ClientID == "" {
+			return errors.New("directoryId and clientId are required for Microsoft 365")
+		}
+		if strings.TrimSpace(credentials) == "" {
+			return errors.New("the credentials must be the client secret of the application")
+		}
+	default:
+		return fmt.Errorf("unknown provider %q", provider)
+	}
+	return nil
+}
+
+// newDirectoryClient returns the client of a provider, authenticated with
+// its credentials
+func newDirectoryClient(ctx context.Context, provider string, settings DirectorySettings, credentials string) (DirectoryClient, error) {
+	if err := validateDirectorySettings(provider, settings, credentials); err != nil {
+		return nil, err
+	}
+	switch provider {
+	case DirectoryProviderGoogleWorkspace:
+		cfg, err := google.JWTConfigFromJSON([]byte(credentials),
+			"https://www.googleapis.com/auth/admin.directory.user.readonly",
+			"https://www.googleapis.com/auth/admin.directory.group.member.readonly")
+		if err != nil {
+			return nil, err
+		}
+		cfg.Subject = settings.AdminEmail
+		return &googleDirectoryClient{http: cfg.Client(ctx), baseURL: googleDirectoryBaseURL, settings: settings}, nil
+	default:
+		cfg := clientcredentials.Config{
+			ClientID:     settings.ClientID,
+			ClientSecret: credentials,
+			TokenURL:     microsoftLoginBaseURL + "/" + url.PathEscape(settings.DirectoryID) + "/oauth2/v2.0/token",
+			Scopes:       []string{"https://graph.microsoft.com/.default"},
+		}
+		return &microsoftDirectoryClient{http: cfg.Client(ctx), baseURL: microsoftGraphBaseURL}, nil
+	}
+}
+
+// getDirectoryJSON decodes the JSON response of a directory API
+func getDirectoryJSON(ctx context.Context, client *http.Client, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, directoryResponseLimit))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("directory answered %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// googleDirectoryClient reads the Admin SDK Directory API
+type googleDirectoryClient struct {
+	http     *http.Client
+	baseURL  string
+	settings DirectorySettings
+}
+
+func (c *googleDirectoryClient) ListUsers(ctx context.Context) ([]DirectoryUser, error) {
+	query := url.Values{"maxResults": {googleDirectoryPageSize}}
+	if c.settings.Domain != "" {
+		query.Set("domain", c.settings.Domain)
+	} else if c.settings.Customer != "" {
+		query.Set("customer", c.settings.Customer)
+	} else {
+		query.Set("customer", "my_customer")
+	}
+	users := []DirectoryUser{}
+	for {
+		var page struct {
+			Users []struct {
+				ID           string `json:"id"`
+				PrimaryEmail string `json:"primaryEmail"`
+				Name         struct {
+					FullName string `json:"fullName"`
+				} `json:"name"`
+				Suspended bool `json:"suspended"`
+				Archived  bool `json:"archived"`
+			} `json:"users"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := getDirectoryJSON(ctx, c.http, c.baseURL+"/users?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		for _, u := range page.Users {
+			users = append(users, DirectoryUser{
+				ExternalID: u.ID,
+				Email:      u.PrimaryEmail,
+				Name:       u.Name.FullName,
+				Active:     !u.Suspended && !u.Archived,
+			})
+		}
+		if page.NextPageToken == "" {
+			return users, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+func (c *googleDirectoryClient) ListGroupMembers(ctx context.Context, group string) ([]string, error) {
+	query := url.Values{"maxResults": {"200"}, "includeDerivedMembership": {"true"}}
+	ids := []string{}
+	for {
+		var page struct {
+			Members []struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"members"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		endpoint := c.baseURL + "/groups/" + url.PathEscape(group) + "/members?" + query.Encode()
+		if err := getDirectoryJSON(ctx, c.http, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Members {
+			if m.Type == "USER" {
+				ids = append(ids, m.ID)
+			}
+		}
+		if page.NextPageToken == "" {
+			return ids, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// microsoftDirectoryClient reads the users and the groups of Microsoft Graph
+type microsoftDirectoryClient struct {
+	http    *http.Client
+	baseURL string
+}
+
+func (c *microsoftDirectoryClient) ListUsers(ctx context.Context) ([]DirectoryUser, error) {
+	endpoint := c.baseURL + "/users?" + url.Values{
+		"$select": {"id,mail,userPrincipalName,displayName,accountEnabled"},
+		"$top":    {microsoftGraphPageSize},
+	}.Encode()
+	users := []DirectoryUser{}
+	for endpoint != "" {
+		var page struct {
+			Value []struct {
+				ID                string `json:"id"`
+				Mail              string `json:"mail"`
+				UserPrincipalName string `json:"userPrincipalName"`
+				DisplayName       string `json:"displayName"`
+				AccountEnabled    bool   `json:"accountEnabled"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := getDirectoryJSON(ctx, c.http, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, u := range page.Value {
+			email := u.Mail
+			if email == "" {
+				email = u.UserPrincipalName
+			}
+			users = append(users, DirectoryUser{
+				ExternalID: u.ID,
+				Email:      email,
+				Name:       u.DisplayName,
+				Active:     u.AccountEnabled,
+			})
+		}
+		endpoint = page.NextLink
+	}
+	return users, nil
+}
+
+func (c *microsoftDirectoryClient) ListGroupMembers(ctx context.Context, group string) ([]string, error) {
+	endpoint := c.baseURL + "/groups/" + url.PathEscape(group) + "/transitiveMembers/microsoft.graph.user?" + url.Values{
+		"$select": {"id"},
+		"$top":    {microsoftGraphPageSize},
+	}.Encode()
+	ids := []string{}
+	for endpoint != "" {
+		var page struct {
+			Value []struct {
+				ID string `json:"id"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := getDirectoryJSON(ctx, c.http, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Value {
+			ids = append(ids, m.ID)
+		}
+		endpoint = page.NextLink
+	}
+	return ids, nil
+}
@@ -0,0 +1,866 @@
+package service
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// SecretDirectorySyncEncryptionKeys names the keys encrypting the directory
+// credentials, in the id:base64 format of MFA_ENCRYPTION_KEYS
+const SecretDirectorySyncEncryptionKeys = "DIRECTORY_SYNC_ENCRYPTION_KEYS"
+
+// Deprovision actions, applied to the synced users who left the directory or
+// its rules
+const (
+	DirectoryDeprovisionSuspend = "suspend"
+	DirectoryDeprovisionRemove  = "remove"
+)
+
+// Sync statuses
+const (
+	DirectorySyncRunning   = "running"
+	DirectorySyncSucceeded = "succeeded"
+	DirectorySyncFailed    = "failed"
+)
+
+// Conflict kinds, the directory users a sync could not apply
+const (
+	DirectoryConflictMissingEmail      = "missing_email"
+	DirectoryConflictDuplicateEmail    = "duplicate_email"
+	DirectoryConflictGlobalRole        = "global_role"
+	DirectoryConflictLinkedElsewhere   = "linked_elsewhere"
+	DirectoryConflictProvisionFailed   = "provision_failed"
+	DirectoryConflictDeprovisionFailed = "deprovision_failed"
+	DirectoryConflictDeprovisionLimit  = "deprovision_limit"
+)
+
+const (
+	// DirectorySyncActorID is recorded as the actor of the changes of a sync
+	DirectorySyncActorID                = "directory-sync"
+	DefaultDirectorySyncInterval        = time.Hour
+	DefaultDirectoryMaxDeprovisionRatio = 0.5
+	DefaultDirectorySyncConflicts       = 50
+	MaxDirectorySyncConflicts           = 500
+	MaxDirectoryRoleRules               = 50
+	directorySyncBatchSize              = 20
+	// directorySyncStaleAfter is when a running sync is taken for crashed
+	directorySyncStaleAfter = 2 * time.Hour
+	directorySyncTimeout    = 30 * time.Minute
+)
+
+var (
+	// ErrInvalidDirectoryConnection is returned for invalid connection settings
+	ErrInvalidDirectoryConnection = errors.New("invalid directory connection")
+	// ErrDirectoryConnectionNotFound is returned when the tenant has no connection
+	ErrDirectoryConnectionNotFound = errors.New("the tenant has no directory connection")
+	// ErrDirectorySyncRunning is returned when a sync of the tenant is running
+	ErrDirectorySyncRunning = errors.New("a directory sync is already running")
+	// ErrDirectorySyncNotConfigured is returned when no DIRECTORY_SYNC_ENCRYPTION_KEYS are set
+	ErrDirectorySyncNotConfigured = errors.New("directory sync is not configured")
+)
+
+// DirectoryRoleRule gives the roles to the members of a directory group: its
+// email or id on Google Workspace, its object id on Microsoft 365
+type DirectoryRoleRule struct {
+	Group string   `json:"group"`
+	Roles []string `json:"roles"`
+}
+
+// DirectoryConnectionInput configures the connection of a tenant. Empty
+// credentials keep the current ones.
+type DirectoryConnectionInput struct {
+	Provider          string
+	Enabled           bool
+	Settings          DirectorySettings
+	Credentials       string
+	RoleRules         []DirectoryRoleRule
+	DefaultRoles      []string
+	DeprovisionAction string
+}
+
+// DirectorySyncStats counts what a sync changed
+type DirectorySyncStats struct {
+	Seen          int `json:"seen"`
+	Created       int `json:"created"`
+	Linked        int `json:"linked"`
+	Updated       int `json:"updated"`
+	Reactivated   int `json:"reactivated"`
+	Deprovisioned int `json:"deprovisioned"`
+	Conflicts     int `json:"conflicts"`
+}
+
+// DirectorySyncConfig configures the sync job.
+//
+// Environment:
+//   - DIRECTORY_SYNC_INTERVAL: time between two syncs of a tenant (default 1h, 0 disables the job)
+//   - DIRECTORY_SYNC_MAX_DEPROVISION_RATIO: share of the synced users a sync may
+//     deprovision at once, above it nobody is (default 0.5, 1 disables the limit)
+type DirectorySyncConfig struct {
+	Interval            time.Duration
+	MaxDeprovisionRatio float64
+}
+
+func DirectorySyncConfigFromEnv() DirectorySyncConfig {
+	cfg := DirectorySyncConfig{
+		Interval:            DefaultDirectorySyncInterval,
+		MaxDeprovisionRatio: DefaultDirectoryMaxDeprovisionRatio,
+	}
+	if v := os.Getenv("DIRECTORY_SYNC_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("DIRECTORY_SYNC_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("DIRECTORY_SYNC_MAX_DEPROVISION_RATIO"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 && r <= 1 {
+			cfg.MaxDeprovisionRatio = r
+		} else {
+			log.Warn().Str("DIRECTORY_SYNC_MAX_DEPROVISION_RATIO", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// DirectorySyncService provisions the users of a tenant from its Google
+// Workspace or Microsoft 365 directory. The directory is the source of truth
+// for the users it synced: their roles follow the group rules and they are
+// deprovisioned when they leave it. The members added otherwise are left
+// alone unless the directory holds their address.
+type DirectorySyncService struct {
+	store          *db.Store
+	authClientPool auth.AuthClientPool
+	userService    UserService
+	cfg            DirectorySyncConfig
+	keys           map[string]cipher.AEAD
+	currentKeyID   string
+	newClient      func(ctx context.Context, provider string, settings DirectorySettings, credentials string) (DirectoryClient, error)
+}
+
+func NewDirectorySyncService(store *db.Store, authClientPool auth.AuthClientPool, userService UserService, cfg DirectorySyncConfig) *DirectorySyncService {
+	s := &DirectorySyncService{
+		store:          store,
+		authClientPool: authClientPool,
+		userService:    userService,
+		cfg:            cfg,
+		keys:           map[string]cipher.AEAD{},
+		newClient:      newDirectoryClient,
+	}
+	keys, currentKeyID, err := parseAEADKeys(readSecret(context.Background(), currentSecretProvider(), SecretDirectorySyncEncryptionKeys))
+	if err != nil {
+		log.Err(err).Msgf("Invalid %s, directory sync is disabled", SecretDirectorySyncEncryptionKeys)
+		return s
+	}
+	s.keys, s.currentKeyID = keys, currentKeyID
+	return s
+}
+
+func (s *DirectorySyncService) encryptCredentials(credentials string) (string, error) {
+	if s.currentKeyID == "" {
+		return "", ErrDirectorySyncNotConfigured
+	}
+	aead := s.keys[s.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(credentials), nil)
+	return s.currentKeyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *DirectorySyncService) decryptCredentials(stored string) (string, error) {
+	keyID, encoded, ok := strings.Cut(stored, ":")
+	if !ok {
+		return "", errors.New("malformed directory credentials")
+	}
+	aead, found := s.keys[keyID]
+	if !found {
+		return "", fmt.Errorf("directory credentials encrypted with the unknown key %q", keyID)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed directory credentials")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt directory credentials: %w", err)
+	}
+	return string(plain), nil
+}
+
+// DecodeDirectoryConnection returns the settings and the role rules of a
+// stored connection
+func DecodeDirectoryConnection(conn repository.CoreDirectoryConnection) (DirectorySettings, []DirectoryRoleRule, error) {
+	var settings DirectorySettings
+	if err := json.Unmarshal(conn.Settings, &settings); err != nil {
+		return DirectorySettings{}, nil, err
+	}
+	rules := []DirectoryRoleRule{}
+	if err := json.Unmarshal(conn.RoleRules, &rules); err != nil {
+		return DirectorySettings{}, nil, err
+	}
+	return settings, rules, nil
+}
+
+// validateDirectoryRoles refuses the roles a tenant membership cannot hold
+func validateDirectoryRoles(roles []string) error {
+	for _, role := range roles {
+		if !slices.Contains([]string{string(core.USER), string(core.CUSTOMERADMIN)}, role) {
+			return fmt.Errorf("%w: the role %q cannot be given by a directory", ErrInvalidDirectoryConnection, role)
+		}
+	}
+	return nil
+}
+
+// SaveConnection creates or replaces the connection of the tenant, after
+// checking the settings. The credentials are stored encrypted.
+func (s *DirectorySyncService) SaveConnection(ctx context.Context, tenantID, createdBy string, input DirectoryConnectionInput) (repository.CoreDirectoryConnection, error) {
+	if input.DeprovisionAction == "" {
+		input.DeprovisionAction = DirectoryDeprovisionSuspend
+	}
+	if input.DeprovisionAction != DirectoryDeprovisionSuspend && input.DeprovisionAction != DirectoryDeprovisionRemove {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("%w: unknown deprovision action %q", ErrInvalidDirectoryConnection, input.DeprovisionAction)
+	}
+	if len(input.RoleRules) > MaxDirectoryRoleRules {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("%w: at most %d role rules", ErrInvalidDirectoryConnection, MaxDirectoryRoleRules)
+	}
+	if err := validateDirectoryRoles(input.DefaultRoles); err != nil {
+		return repository.CoreDirectoryConnection{}, err
+	}
+	for _, rule := range input.RoleRules {
+		if strings.TrimSpace(rule.Group) == "" || len(rule.Roles) == 0 {
+			return repository.CoreDirectoryConnection{}, fmt.Errorf("%w: a role rule needs a group and roles", ErrInvalidDirectoryConnection)
+		}
+		if err := validateDirectoryRoles(rule.Roles); err != nil {
+			return repository.CoreDirectoryConnection{}, err
+		}
+	}
+	if s.currentKeyID == "" {
+		return repository.CoreDirectoryConnection{}, ErrDirectorySyncNotConfigured
+	}
+
+	credentials := input.Credentials
+	if credentials == "" {
+		current, err := s.store.GetDirectoryConnection(ctx, tenantID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.CoreDirectoryConnection{}, fmt.Errorf("%w: the credentials are required", ErrInvalidDirectoryConnection)
+		}
+		if err != nil {
+			return repository.CoreDirectoryConnection{}, fmt.Errorf("service.SaveConnection: %w", err)
+		}
+		if credentials, err = s.decryptCredentials(current.Credentials); err != nil {
+			return repository.CoreDirectoryConnection{}, fmt.Errorf("service.SaveConnection: %w", err)
+		}
+	}
+	if err := validateDirectorySettings(input.Provider, input.Settings, credentials); err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("%w: %s", ErrInvalidDirectoryConnection, err)
+	}
+	encrypted, err := s.encryptCredentials(credentials)
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("service.SaveConnection: %w", err)
+	}
+	settings, err := json.Marshal(input.Settings)
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("service.SaveConnection: %w", err)
+	}
+	if input.RoleRules == nil {
+		input.RoleRules = []DirectoryRoleRule{}
+	}
+	rules, err := json.Marshal(input.RoleRules)
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("service.SaveConnection: %w", err)
+	}
+	if input.DefaultRoles == nil {
+		input.DefaultRoles = []string{}
+	}
+
+	conn, err := s.store.UpsertDirectoryConnection(ctx, repository.UpsertDirectoryConnectionParams{
+		TenantID:          tenantID,
+		Provider:          input.Provider,
+		Enabled:           input.Enabled,
+		Settings:          settings,
+		Credentials:       encrypted,
+		RoleRules:         rules,
+		DefaultRoles:      input.DefaultRoles,
+		DeprovisionAction: input.DeprovisionAction,
+		CreatedBy:         createdBy,
+	})
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("service.SaveConnection: %w", err)
+	}
+	return conn, nil
+}
+
+// GetConnection returns the connection of the tenant
+func (s *DirectorySyncService) GetConnection(ctx context.Context, tenantID string) (repository.CoreDirectoryConnection, error) {
+	conn, err := s.store.GetDirectoryConnection(ctx, tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreDirectoryConnection{}, ErrDirectoryConnectionNotFound
+	}
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("service.GetConnection: %w", err)
+	}
+	return conn, nil
+}
+
+// DeleteConnection disconnects the directory. The synced users stay members
+// of the tenant, and are managed like the others from then on.
+func (s *DirectorySyncService) DeleteConnection(ctx context.Context, tenantID string) error {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("service.DeleteConnection: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	deleted, err := qtx.DeleteDirectoryConnection(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteConnection: %w", err)
+	}
+	if deleted == 0 {
+		return ErrDirectoryConnectionNotFound
+	}
+	if err := qtx.DeleteTenantDirectoryUsers(ctx, tenantID); err != nil {
+		return fmt.Errorf("service.DeleteConnection: %w", err)
+	}
+	if err := qtx.DeleteDirectorySyncConflicts(ctx, tenantID); err != nil {
+		return fmt.Errorf("service.DeleteConnection: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("service.DeleteConnection: %w", err)
+	}
+	return nil
+}
+
+// ListConflicts lists the conflicts of the last sync of the tenant
+func (s *DirectorySyncService) ListConflicts(ctx context.Context, tenantID string, limit, offset int32) ([]repository.CoreDirectorySyncConflict, error) {
+	if limit <= 0 {
+		limit = DefaultDirectorySyncConflicts
+	}
+	conflicts, err := s.store.ListDirectorySyncConflicts(ctx, repository.ListDirectorySyncConflictsParams{
+		TenantID: tenantID,
+		Limit:    min(limit, MaxDirectorySyncConflicts),
+		Offset:   max(offset, 0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListConflicts: %w", err)
+	}
+	return conflicts, nil
+}
+
+// claim marks the sync of the tenant running
+func (s *DirectorySyncService) claim(ctx context.Context, tenantID string) (repository.CoreDirectoryConnection, error) {
+	conn, err := s.store.ClaimDirectorySync(ctx, repository.ClaimDirectorySyncParams{
+		TenantID:    tenantID,
+		StaleBefore: pgtype.Timestamptz{Time: time.Now().Add(-directorySyncStaleAfter), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := s.GetConnection(ctx, tenantID); err != nil {
+			return repository.CoreDirectoryConnection{}, err
+		}
+		return repository.CoreDirectoryConnection{}, ErrDirectorySyncRunning
+	}
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, fmt.Errorf("service.claim: %w", err)
+	}
+	return conn, nil
+}
+
+// StartSync starts a sync of the tenant in the background and returns the
+// connection marked running
+func (s *DirectorySyncService) StartSync(ctx context.Context, tenantID string) (repository.CoreDirectoryConnection, error) {
+	conn, err := s.claim(ctx, tenantID)
+	if err != nil {
+		return repository.CoreDirectoryConnection{}, err
+	}
+	go s.run(context.WithoutCancel(ctx), conn)
+	return conn, nil
+}
+
+// SyncTenant syncs the tenant now
+func (s *DirectorySyncService) SyncTenant(ctx context.Context, tenantID string) (DirectorySyncStats, error) {
+	conn, err := s.claim(ctx, tenantID)
+	if err != nil {
+		return DirectorySyncStats{}, err
+	}
+	return s.run(ctx, conn)
+}
+
+// StartDirectorySync syncs the due tenants now and then every minute until ctx
+// is done. It does nothing when the interval is 0.
+func (s *DirectorySyncService) StartDirectorySync(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Info().Msg("Directory sync disabled")
+		return
+	}
+	if s.currentKeyID == "" {
+		log.Info().Msgf("Directory sync disabled, %s is not set", SecretDirectorySyncEncryptionKeys)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(min(s.cfg.Interval, time.Minute))
+		defer ticker.Stop()
+		for {
+			if err := s.syncDueTenants(ctx); err != nil {
+				log.Err(err).Msg("Directory sync scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *DirectorySyncService) syncDueTenants(ctx context.Context) error {
+	tenantIDs, err := s.store.ListDueDirectoryConnections(ctx, repository.ListDueDirectoryConnectionsParams{
+		DueBefore: pgtype.Timestamptz{Time: time.Now().Add(-s.cfg.Interval), Valid: true},
+		BatchSize: directorySyncBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("service.syncDueTenants: %w", err)
+	}
+	for _, tenantID := range tenantIDs {
+		// Another instance took it, or the sync failed and was recorded
+		if _, err := s.SyncTenant(ctx, tenantID); err != nil && !errors.Is(err, ErrDirectorySyncRunning) {
+			log.Err(err).Str("tenant_id", tenantID).Msg("Directory sync failed")
+		}
+	}
+	return nil
+}
+
+// run syncs a claimed connection and records the outcome on it
+func (s *DirectorySyncService) run(ctx context.Context, conn repository.CoreDirectoryConnection) (DirectorySyncStats, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	ctx, cancel := context.WithTimeout(ctx, directorySyncTimeout)
+	defer cancel()
+
+	stats, syncErr := s.sync(ctx, conn)
+	status := DirectorySyncSucceeded
+	var lastError pgtype.Text
+	if syncErr != nil {
+		status = DirectorySyncFailed
+		lastError = pgtype.Text{String: syncErr.Error(), Valid: true}
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return stats, fmt.Errorf("service.run: %w", err)
+	}
+	if err := s.store.FinishDirectorySync(context.WithoutCancel(ctx), repository.FinishDirectorySyncParams{
+		Status:   pgtype.Text{String: status, Valid: true},
+		Error:    lastError,
+		Stats:    data,
+		TenantID: conn.TenantID,
+	}); err != nil {
+		logger.Err(err).Str("tenant_id", conn.TenantID).Msg("Failed to record directory sync outcome")
+	}
+	if syncErr != nil {
+		logger.Err(syncErr).Str("tenant_id", conn.TenantID).Msg("Directory sync failed")
+		return stats, syncErr
+	}
+	logger.Info().Str("tenant_id", conn.TenantID).Interface("stats", stats).Msg("Directory sync completed")
+	return stats, nil
+}
+
+// directoryAssignment is a directory user to provision with its roles
+type directoryAssignment struct {
+	User  DirectoryUser
+	Roles []string
+}
+
+// directoryConflict is a directory user a sync cannot apply
+type directoryConflict struct {
+	ExternalID string
+	Email      string
+	UserID     string
+	Kind       string
+	Detail     string
+}
+
+// planDirectorySync returns the active users to provision with the roles of
+// their groups, or the default roles when no rule matches. Without default
+// roles, the users of no rule group are out of the sync.
+func planDirectorySync(users []DirectoryUser, groupMembers map[string][]string, rules []DirectoryRoleRule, defaultRoles []string) ([]directoryAssignment, []directoryConflict) {
+	memberOf := map[string][]int{}
+	for i, rule := range rules {
+		for _, id := range groupMembers[rule.Group] {
+			memberOf[id] = append(memberOf[id], i)
+		}
+	}
+
+	sorted := slices.Clone(users)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ExternalID < sorted[j].ExternalID })
+	assignments := []directoryAssignment{}
+	conflicts := []directoryConflict{}
+	emails := map[string]string{}
+	for _, user := range sorted {
+		if !user.Active {
+			continue
+		}
+		roles := []string{}
+		for _, i := range memberOf[user.ExternalID] {
+			for _, role := range rules[i].Roles {
+				if !slices.Contains(roles, role) {
+					roles = append(roles, role)
+				}
+			}
+		}
+		if len(roles) == 0 {
+			roles = slices.Clone(defaultRoles)
+		}
+		if len(roles) == 0 {
+			continue
+		}
+		sort.Strings(roles)
+
+		user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+		if user.Email == "" {
+			conflicts = append(conflicts, directoryConflict{ExternalID: user.ExternalID, Kind: DirectoryConflictMissingEmail,
+				Detail: "the directory account has no email"})
+			continue
+		}
+		if other, taken := emails[user.Email]; taken {
+			conflicts = append(conflicts, directoryConflict{ExternalID: user.ExternalID, Email: user.Email, Kind: DirectoryConflictDuplicateEmail,
+				Detail: "the address is also the one of the directory account " + other})
+			continue
+		}
+		emails[user.Email] = user.ExternalID
+		assignments = append(assignments, directoryAssignment{User: user, Roles: roles})
+	}
+	return assignments, conflicts
+}
+
+// sync reads the directory and applies it to the tenant
+func (s *DirectorySyncService) sync(ctx context.Context, conn repository.CoreDirectoryConnection) (DirectorySyncStats, error) {
+	stats := DirectorySyncStats{}
+	settings, rules, err := DecodeDirectoryConnection(conn)
+	if err != nil {
+		return stats, fmt.Errorf("invalid connection: %w", err)
+	}
+	credentials, err := s.decryptCredentials(conn.Credentials)
+	if err != nil {
+		return stats, err
+	}
+	client, err := s.newClient(ctx, conn.Provider, settings, credentials)
+	if err != nil {
+		return stats, err
+	}
+	users, err := client.ListUsers(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("list directory users: %w", err)
+	}
+	groupMembers := map[string][]string{}
+	for _, rule := range rules {
+		if _, listed := groupMembers[rule.Group]; listed {
+			continue
+		}
+		members, err := client.ListGroupMembers(ctx, rule.Group)
+		if err != nil {
+			return stats, fmt.Errorf("list members of group %s: %w", rule.Group, err)
+		}
+		groupMembers[rule.Group] = members
+	}
+	authClient, err := s.authClientPool.GetAuthClientForTenant(ctx, conn.TenantID)
+	if err != nil {
+		return stats, err
+	}
+
+	assignments, conflicts := planDirectorySync(users, groupMembers, rules, conn.DefaultRoles)
+	stats.Seen = len(users)
+	links, err := s.store.ListDirectoryUsers(ctx, conn.TenantID)
+	if err != nil {
+		return stats, err
+	}
+	linkByExternalID := map[string]repository.CoreDirectoryUser{}
+	externalIDByUser := map[string]string{}
+	for _, link := range links {
+		linkByExternalID[link.ExternalID] = link
+		externalIDByUser[link.UserID] = link.ExternalID
+	}
+
+	desired := map[string]bool{}
+	for _, assignment := range assignments {
+		desired[assignment.User.ExternalID] = true
+		if conflict := s.provision(ctx, authClient, conn.TenantID, assignment, linkByExternalID, externalIDByUser, &stats); conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	conflicts = append(conflicts, s.deprovision(ctx, authClient, conn, links, desired, &stats)...)
+
+	// The report holds the conflicts of the last sync only
+	if err := s.store.DeleteDirectorySyncConflicts(ctx, conn.TenantID); err != nil {
+		return stats, err
+	}
+	for _, conflict := range conflicts {
+		email, _, err := EncryptEmail(conflict.Email)
+		if err != nil {
+			return stats, err
+		}
+		if err := s.store.CreateDirectorySyncConflict(ctx, repository.CreateDirectorySyncConflictParams{
+			TenantID:   conn.TenantID,
+			ExternalID: conflict.ExternalID,
+			Email:      email,
+			UserID:     pgtype.Text{String: conflict.UserID, Valid: conflict.UserID != ""},
+			Kind:       conflict.Kind,
+			Detail:     conflict.Detail,
+		}); err != nil {
+			return stats, err
+		}
+	}
+	stats.Conflicts = len(conflicts)
+	return stats, nil
+}
+
+// provision creates or links the user of the assignment and gives them its
+// roles in the tenant
+func (s *DirectorySyncService) provision(ctx context.Context, authClient auth.AuthClient, tenantID string, assignment directoryAssignment,
+	links map[string]repository.CoreDirectoryUser, externalIDByUser map[string]string, stats *DirectorySyncStats) *directoryConflict {
+	logger := util.GetLoggerFromCtx(ctx)
+	user := assignment.User
+	conflict := func(userID, kind, detail string) *directoryConflict {
+		return &directoryConflict{ExternalID: user.ExternalID, Email: user.Email, UserID: userID, Kind: kind, Detail: detail}
+	}
+
+	link, linked := links[user.ExternalID]
+	userID := link.UserID
+	if !linked {
+		existing, err := s.userService.GetUserByEmailGlobal(ctx, user.Email)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return conflict("", DirectoryConflictProvisionFailed, err.Error())
+		}
+		if existing != nil {
+			if other, taken := externalIDByUser[existing.Id]; taken && other != user.ExternalID {
+				return conflict(existing.Id, DirectoryConflictLinkedElsewhere, "the user is synced from the directory account "+other)
+			}
+			userID = existing.Id
+		}
+	}
+
+	eventType := ""
+	if userID == "" {
+		created, err := s.userService.CreateUser(ctx, authClient, tenantID, core.NewUser{
+			Email: user.Email,
+			Name:  user.Name,
+			Roles: convertToRoleDTOs(assignment.Roles),
+		}, nil)
+		if err != nil {
+			return conflict("", DirectoryConflictProvisionFailed, err.Error())
+		}
+		userID = created.ID
+		// The directory owns the address
+		if _, err := authClient.UpdateUser(ctx, userID, (&auth.UserToUpdate{}).EmailVerified(true)); err != nil {
+			logger.Err(err).Str("user_id", userID).Msg("Failed to mark the directory address verified")
+		} else {
+			recordUserAccountState(ctx, s.store, userID, pgtype.Bool{}, pgtype.Bool{Bool: true, Valid: true})
+		}
+		stats.Created++
+		eventType = "directory_provisioned"
+	} else {
+		global, err := s.store.GetSharedUserByID(ctx, userID)
+		if err != nil {
+			return conflict(userID, DirectoryConflictProvisionFailed, err.Error())
+		}
+		// The platform administrators are never managed from a tenant
+		if len(global.Roles) > 0 {
+			return conflict(userID, DirectoryConflictGlobalRole, "the user holds a global role")
+		}
+		membership, err := s.store.GetSharedUserTenantMembership(ctx, repository.GetSharedUserTenantMembershipParams{
+			UserID:   userID,
+			TenantID: tenantID,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return conflict(userID, DirectoryConflictProvisionFailed, err.Error())
+		}
+		switch {
+		case err != nil || membership.Status != "active":
+			if err := s.userService.AddUserToTenant(ctx, authClient, tenantID, userID, convertToRoleDTOs(assignment.Roles), DirectorySyncActorID); err != nil {
+				return conflict(userID, DirectoryConflictProvisionFailed, err.Error())
+			}
+			if linked && link.Suspended {
+				stats.Reactivated++
+			} else {
+				stats.Linked++
+			}
+			eventType = "directory_provisioned"
+		case !sameRoles(membership.Roles, assignment.Roles):
+			if err := s.updateRoles(ctx, authClient, tenantID, userID, assignment.Roles); err != nil {
+				return conflict(userID, DirectoryConflictProvisionFailed, err.Error())
+			}
+			stats.Updated++
+			eventType = "directory_roles_updated"
+		default:
+			if !linked {
+				stats.Linked++
+			}
+		}
+	}
+
+	if err := s.store.UpsertDirectoryUser(ctx, repository.UpsertDirectoryUserParams{
+		TenantID:   tenantID,
+		ExternalID: user.ExternalID,
+		UserID:     userID,
+		Roles:      assignment.Roles,
+	}); err != nil {
+		return conflict(userID, DirectoryConflictProvisionFailed, err.Error())
+	}
+	externalIDByUser[userID] = user.ExternalID
+	if eventType != "" {
+		s.recordActivity(ctx, tenantID, userID, eventType, map[string]interface{}{
+			"external_id": user.ExternalID,
+			"roles":       assignment.Roles,
+		})
+	}
+	return nil
+}
+
+// updateRoles replaces the roles of a member, in the database and in the
+// claims of the auth provider
+func (s *DirectorySyncService) updateRoles(ctx context.Context, authClient auth.AuthClient, tenantID, userID string, roles []string) error {
+	if _, err := s.store.UpdateSharedUserRolesInTenant(ctx, repository.UpdateSharedUserRolesInTenantParams{
+		UserID:      userID,
+		TenantRoles: roles,
+		TenantID:    tenantID,
+	}); err != nil {
+		return err
+	}
+	return authClient.SetCustomUserClaims(ctx, userID, map[string]interface{}{
+		"tenant_memberships": map[string]interface{}{
+			"tenant_id": tenantID,
+			"roles":     roles,
+		},
+	})
+}
+
+// deprovision applies the deprovision action to the synced users no longer
+// in the directory or its rules. Nobody is deprovisioned when more users than
+// the ratio allows left, as an empty or misconfigured directory looks the
+// same.
+func (s *DirectorySyncService) deprovision(ctx context.Context, authClient auth.AuthClient, conn repository.CoreDirectoryConnection,
+	links []repository.CoreDirectoryUser, desired map[string]bool, stats *DirectorySyncStats) []directoryConflict {
+	active := 0
+	gone := []repository.CoreDirectoryUser{}
+	for _, link := range links {
+		if link.Suspended {
+			continue
+		}
+		active++
+		if !desired[link.ExternalID] {
+			gone = append(gone, link)
+		}
+	}
+	if len(gone) > 1 && float64(len(gone)) > s.cfg.MaxDeprovisionRatio*float64(active) {
+		return []directoryConflict{{
+			Kind:   DirectoryConflictDeprovisionLimit,
+			Detail: fmt.Sprintf("%d of the %d synced users left the directory, more than the limit, nobody was deprovisioned", len(gone), active),
+		}}
+	}
+
+	conflicts := []directoryConflict{}
+	for _, link := range gone {
+		var err error
+		if conn.DeprovisionAction == DirectoryDeprovisionRemove {
+			err = s.removeMember(ctx, conn.TenantID, link)
+		} else {
+			err = s.suspendMember(ctx, conn.TenantID, link)
+		}
+		if err != nil {
+			conflicts = append(conflicts, directoryConflict{ExternalID: link.ExternalID, UserID: link.UserID,
+				Kind: DirectoryConflictDeprovisionFailed, Detail: err.Error()})
+			continue
+		}
+		stats.Deprovisioned++
+		s.recordActivity(ctx, conn.TenantID, link.UserID, "directory_deprovisioned", map[string]interface{}{
+			"external_id": link.ExternalID,
+			"action":      conn.DeprovisionAction,
+		})
+	}
+	return conflicts
+}
+
+// suspendMember suspends the membership and keeps the link, so the user gets
+// it back if they return to the directory
+func (s *DirectorySyncService) suspendMember(ctx context.Context, tenantID string, link repository.CoreDirectoryUser) error {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+	if _, err := qtx.UpdateUserTenantMembershipStatus(ctx, repository.UpdateUserTenantMembershipStatusParams{
+		UserID:   link.UserID,
+		TenantID: tenantID,
+		Status:   "suspended",
+	}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err := qtx.SuspendDirectoryUser(ctx, repository.SuspendDirectoryUserParams{TenantID: tenantID, ExternalID: link.ExternalID}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// removeMember removes the membership like an admin would, and forgets the
+// link
+func (s *DirectorySyncService) removeMember(ctx context.Context, tenantID string, link repository.CoreDirectoryUser) error {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+	if _, err := qtx.DeleteSharedUserByTenant(ctx, repository.DeleteSharedUserByTenantParams{
+		UserID:   link.UserID,
+		TenantID: tenantID,
+	}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if err := qtx.DeleteDirectoryUser(ctx, repository.DeleteDirectoryUserParams{TenantID: tenantID, ExternalID: link.ExternalID}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// recordActivity adds a change of the sync to the timeline of the user. The
+// change is already applied, so a failure is only logged.
+func (s *DirectorySyncService) recordActivity(ctx context.Context, tenantID, userID, eventType string, data map[string]interface{}) {
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryMembership,
+		EventType: eventType,
+		ActorID:   DirectorySyncActorID,
+		Data:      data,
+	}); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", userID).Str("event_type", eventType).Msg("Failed to record directory sync activity")
+	}
+}
+
+// sameRoles compares two role lists as sets
+func sameRoles(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDirectorySync(t *testing.T) {
+	users := []DirectoryUser{
+		{ExternalID: "u1", Email: " Alice@Example.com", Active: true},
+		{ExternalID: "u2", Email: "bob@example.com", Active: true},
+		{ExternalID: "u3", Email: "carol@example.com", Active: false},
+		{ExternalID: "u4", Email: "", Active: true},
+		{ExternalID: "u5", Email: "alice@example.com", Active: true},
+		{ExternalID: "u6", Email: "dave@example.com", Active: true},
+	}
+	groups := map[string][]string{
+		"admins": {"u1", "u3"},
+		"staff":  {"u1", "u2", "u4", "u5"},
+	}
+	rules := []DirectoryRoleRule{
+		{Group: "staff", Roles: []string{"USER"}},
+		{Group: "admins", Roles: []string{"CUSTOMER_ADMIN"}},
+	}
+
+	assignments, conflicts := planDirectorySync(users, groups, rules, nil)
+	require.Len(t, assignments, 2)
+	require.Equal(t, "alice@example.com", assignments[0].User.Email)
+	require.Equal(t, []string{"CUSTOMER_ADMIN", "USER"}, assignments[0].Roles)
+	require.Equal(t, "u2", assignments[1].User.ExternalID)
+	require.Equal(t, []string{"USER"}, assignments[1].Roles)
+	require.Len(t, conflicts, 2)
+	require.Equal(t, DirectoryConflictMissingEmail, conflicts[0].Kind)
+	require.Equal(t, DirectoryConflictDuplicateEmail, conflicts[1].Kind)
+	require.Equal(t, "u5", conflicts[1].ExternalID)
+
+	// With default roles, the users of no rule group are synced too
+	assignments, _ = planDirectorySync(users, groups, rules, []string{"USER"})
+	require.Len(t, assignments, 3)
+	require.Equal(t, "u6", assignments[2].User.ExternalID)
+}
+
+func TestDirectoryDeprovisionLimit(t *testing.T) {
+	service := &DirectorySyncService{cfg: DirectorySyncConfig{MaxDeprovisionRatio: 0.5}}
+	links := []repository.CoreDirectoryUser{
+		{ExternalID: "u1", UserID: "a"},
+		{ExternalID: "u2", UserID: "b"},
+		{ExternalID: "u3", UserID: "c"},
+		{ExternalID: "u4", UserID: "d", Suspended: true},
+	}
+	stats := DirectorySyncStats{}
+	conflicts := service.deprovision(context.Background(), nil, repository.CoreDirectoryConnection{}, links, map[string]bool{"u1": true}, &stats)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, DirectoryConflictDeprovisionLimit, conflicts[0].Kind)
+	require.Zero(t, stats.Deprovisioned)
+}
+
+func TestDirectoryCredentialsRoundTrip(t *testing.T) {
+	keys, currentKeyID, err := parseAEADKeys("k1:" + testEmailKey(1))
+	require.NoError(t, err)
+	service := &DirectorySyncService{keys: keys, currentKeyID: currentKeyID}
+
+	encrypted, err := service.encryptCredentials("secret")
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "secret")
+	plain, err := service.decryptCredentials(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "secret", plain)
+
+	_, err = (&DirectorySyncService{}).encryptCredentials("secret")
+	require.ErrorIs(t, err, ErrDirectorySyncNotConfigured)
+}
+
+func TestMicrosoftDirectoryClientPaging(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []map[string]interface{}{
+					{"id": "u1", "mail": "alice@example.com", "displayName": "Alice", "accountEnabled": true},
+				},
+				"@odata.nextLink": server.URL + "/users?page=2",
+			})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []map[string]interface{}{
+					{"id": "u2", "userPrincipalName": "bob@example.com", "accountEnabled": false},
+				},
+			})
+		}
+	}))
+	defer server.Close()
+
+	client := &microsoftDirectoryClient{http: server.Client(), baseURL: server.URL}
+	users, err := client.ListUsers(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []DirectoryUser{
+		{ExternalID: "u1", Email: "alice@example.com", Name: "Alice", Active: true},
+		{ExternalID: "u2", Email: "bob@example.com", Active: false},
+	}, users)
+}
+
+func TestValidateDirectorySettings(t *testing.T) {
+	require.NoError(t, validateDirectorySettings(DirectoryProviderMicrosoft365, DirectorySettings{DirectoryID: "d", ClientID: "c"}, "secret"))
+	require.Error(t, validateDirectorySettings(DirectoryProviderMicrosoft365, DirectorySettings{DirectoryID: "d"}, "secret"))
+	require.Error(t, validateDirectorySettings(DirectoryProviderGoogleWorkspace, DirectorySettings{AdminEmail: "admin@example.com"}, "not json"))
+	require.Error(t, validateDirectorySettings("okta", DirectorySettings{}, "secret"))
+}
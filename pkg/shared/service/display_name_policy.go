@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+)
+
+// Tenant configs of the display name policy. Without them any name is
+// accepted, as before the policies existed.
+const (
+	TenantDisplayNameMinLengthConfig   = "display_name_min_length"
+	TenantDisplayNameMaxLengthConfig   = "display_name_max_length"
+	TenantDisplayNameBannedWordsConfig = "display_name_banned_words"
+	TenantDisplayNameUniqueConfig      = "display_name_unique"
+
+	maxDisplayNameLength = 200
+)
+
+// Codes of the display name violations, stable for the frontend to localize
+const (
+	DisplayNameTooShort = "too_short"
+	DisplayNameTooLong  = "too_long"
+	DisplayNameBanned   = "banned_word"
+	DisplayNameTaken    = "taken"
+)
+
+// ErrInvalidDisplayName is matched by every DisplayNameError
+var ErrInvalidDisplayName = errors.New("invalid display name")
+
+// DisplayNameViolation is one rule of the policy a name breaks
+type DisplayNameViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// DisplayNameError lists every rule a name breaks, so a form can show them
+// all at once
+type DisplayNameError struct {
+	Violations []DisplayNameViolation
+}
+
+func (e *DisplayNameError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidDisplayName, strings.Join(messages, ", "))
+}
+
+func (e *DisplayNameError) Is(target error) bool {
+	return target == ErrInvalidDisplayName
+}
+
+// DisplayNamePolicy are the rules the display names of a tenant follow. Zero
+// lengths do not bound the name.
+type DisplayNamePolicy struct {
+	MinLength   int
+	MaxLength   int
+	BannedWords []string
+	Unique      bool
+}
+
+// displayNamePolicyCache keeps the policy of each tenant, keyed by tenant id
+var displayNamePolicyCache = NewPublicCache[DisplayNamePolicy](DefaultPublicCacheTTL)
+
+// ValidateTenantDisplayNameConfig checks the value of a display_name_* tenant
+// config. Other configs are not checked.
+func ValidateTenantDisplayNameConfig(name string, value *string) error {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return (&DisplayNamePolicy{}).set(name, *value)
+}
+
+func (p *DisplayNamePolicy) set(name, value string) error {
+	value = strings.TrimSpace(value)
+	switch name {
+	case TenantDisplayNameMinLengthConfig, TenantDisplayNameMaxLengthConfig:
+		length, err := strconv.Atoi(value)
+		if err != nil || length < 0 || length > maxDisplayNameLength {
+			return fmt.Errorf("%s: must be a number between 0 and %d", name, maxDisplayNameLength)
+		}
+		if name == TenantDisplayNameMinLengthConfig {
+			p.MinLength = length
+		} else {
+			p.MaxLength = length
+		}
+	case TenantDisplayNameBannedWordsConfig:
+		words := []string{}
+		for _, word := range strings.Split(value, ",") {
+			if word = normalizeDisplayName(word); word != "" {
+				words = append(words, word)
+			}
+		}
+		p.BannedWords = words
+	case TenantDisplayNameUniqueConfig:
+		unique, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: must be true or false", name)
+		}
+		p.Unique = unique
+	}
+	return nil
+}
+
+// Check returns the rules of the policy the name breaks, uniqueness aside
+func (p DisplayNamePolicy) Check(name string) []DisplayNameViolation {
+	violations := []DisplayNameViolation{}
+	length := len([]rune(strings.TrimSpace(name)))
+	if length < p.MinLength {
+		violations = append(violations, DisplayNameViolation{
+			Code:    DisplayNameTooShort,
+			Message: fmt.Sprintf("must be at least %d characters", p.MinLength),
+		})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, DisplayNameViolation{
+			Code:    DisplayNameTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", p.MaxLength),
+		})
+	}
+	if len(p.BannedWords) > 0 {
+		// Whole words only, so a banned word inside a longer one is accepted
+		normalized := " " + normalizeDisplayName(name) + " "
+		for _, word := range p.BannedWords {
+			if strings.Contains(normalized, " "+word+" ") {
+				violations = append(violations, DisplayNameViolation{
+					Code:    DisplayNameBanned,
+					Message: "contains a word that is not allowed",
+				})
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// normalizeDisplayName lowercases the name and keeps its words separated by
+// single spaces, punctuation dropped
+func normalizeDisplayName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// GetDisplayNamePolicy returns the display name policy of a tenant. Invalid
+// configs are ignored and logged.
+func GetDisplayNamePolicy(ctx context.Context, store *db.Store, tenantID string) (DisplayNamePolicy, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	policy, err := displayNamePolicyCache.Get(ctx, tenantID, func(ctx context.Context) (DisplayNamePolicy, error) {
+		policy := DisplayNamePolicy{}
+		configs, err := store.ListAllTenantConfigs(ctx, tenantID)
+		if err != nil {
+			return policy, err
+		}
+		for _, config := range configs {
+			if !config.Value.Valid || config.Value.String == "" {
+				continue
+			}
+			if err := policy.set(config.Name, config.Value.String); err != nil {
+				logger.Warn().Err(err).Str("tenantID", tenantID).Msg("Ignoring invalid display name policy config")
+			}
+		}
+		return policy, nil
+	})
+	if err != nil {
+		return DisplayNamePolicy{}, fmt.Errorf("service.GetDisplayNamePolicy: %w", err)
+	}
+	return policy, nil
+}
+
+// InvalidateDisplayNamePolicy drops the cached policy of a tenant after a
+// change of its display_name_* configs
+func InvalidateDisplayNamePolicy(tenantID string) {
+	displayNamePolicyCache.Invalidate(tenantID)
+}
+
+// ValidateDisplayName checks the name of a user of the tenant against its
+// policy. userID excludes the user from the uniqueness check, empty for a new
+// user. The broken rules are returned as a *DisplayNameError.
+func ValidateDisplayName(ctx context.Context, store *db.Store, tenantID, userID, name string) error {
+	if tenantID == "" {
+		return nil
+	}
+	policy, err := GetDisplayNamePolicy(ctx, store, tenantID)
+	if err != nil {
+		return err
+	}
+	violations := policy.Check(name)
+	if policy.Unique && strings.TrimSpace(name) != "" {
+		taken, err := store.IsDisplayNameTakenInTenant(ctx, repository.IsDisplayNameTakenInTenantParams{
+			TenantID: tenantID,
+			UserID:   userID,
+			Name:     name,
+		})
+		if err != nil {
+			return fmt.Errorf("service.ValidateDisplayName: %w", err)
+		}
+		if taken {
+			violations = append(violations, DisplayNameViolation{
+				Code:    DisplayNameTaken,
+				Message: "is already used in this tenant",
+			})
+		}
+	}
+	if len(violations) > 0 {
+		return &DisplayNameError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTenantDisplayNameConfig(t *testing.T) {
+	valid := map[string]string{
+		TenantDisplayNameMinLengthConfig:   "3",
+		TenantDisplayNameMaxLengthConfig:   "40",
+		TenantDisplayNameBannedWordsConfig: "admin, Support ,,",
+		TenantDisplayNameUniqueConfig:      "true",
+		"support_email":                    "not checked",
+	}
+	for name, value := range valid {
+		require.NoError(t, ValidateTenantDisplayNameConfig(name, &value), name)
+	}
+
+	invalid := map[string]string{
+		TenantDisplayNameMinLengthConfig: "-1",
+		TenantDisplayNameMaxLengthConfig: "1000",
+		TenantDisplayNameUniqueConfig:    "sometimes",
+	}
+	for name, value := range invalid {
+		require.Error(t, ValidateTenantDisplayNameConfig(name, &value), name)
+	}
+}
+
+func TestDisplayNamePolicyCheck(t *testing.T) {
+	policy := DisplayNamePolicy{}
+	for _, name := range []string{"", "Jane Doe"} {
+		require.Empty(t, policy.Check(name), "no policy accepts any name")
+	}
+
+	require.NoError(t, policy.set(TenantDisplayNameMinLengthConfig, "3"))
+	require.NoError(t, policy.set(TenantDisplayNameMaxLengthConfig, "12"))
+	require.NoError(t, policy.set(TenantDisplayNameBannedWordsConfig, "admin, Support"))
+
+	codes := func(name string) []string {
+		result := []string{}
+		for _, violation := range policy.Check(name) {
+			result = append(result, violation.Code)
+		}
+		return result
+	}
+	require.Empty(t, codes("Jane Doe"))
+	require.Equal(t, []string{DisplayNameTooShort}, codes(" Al "))
+	// lengths count characters, not bytes
+	require.Empty(t, codes("Zoë Ångström"))
+	require.Equal(t, []string{DisplayNameTooLong}, codes("Jane Elizabeth Doe"))
+	require.Equal(t, []string{DisplayNameBanned}, codes("The ADMIN!"))
+	require.Equal(t, []string{DisplayNameBanned}, codes("support-team"))
+	// whole words only
+	require.Empty(t, codes("Badminton"))
+	require.Equal(t, []string{DisplayNameTooShort}, codes(""))
+}
+
+func TestDisplayNameError(t *testing.T) {
+	err := error(&DisplayNameError{Violations: []DisplayNameViolation{
+		{Code: DisplayNameTooShort, Message: "must be at least 3 characters"},
+		{Code: DisplayNameTaken, Message: "is already used in this tenant"},
+	}})
+	require.ErrorIs(t, err, ErrInvalidDisplayName)
+	require.Equal(t, "invalid display name: must be at least 3 characters, is already used in this tenant", err.Error())
+
+	var invalid *DisplayNameError
+	require.True(t, errors.As(err, &invalid))
+	require.Len(t, invalid.Violations, 2)
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// Secrets of the email encryption, read through the SecretProvider
+const (
+	// SecretEmailEncryptionKeys lists id:base64 AES-256 keys separated by
+	// commas. The first one encrypts; the others only decrypt, until the
+	// addresses are rewritten with the first one.
+	SecretEmailEncryptionKeys = "EMAIL_ENCRYPTION_KEYS"
+	// SecretEmailBlindIndexKey is the base64 HMAC key of the blind index
+	SecretEmailBlindIndexKey = "EMAIL_BLIND_INDEX_KEY"
+)
+
+const (
+	// encryptedEmailPrefix starts a stored address of the form
+	// enc:<key id>:<base64 nonce and ciphertext>
+	encryptedEmailPrefix  = "enc:"
+	emailRewriteBatchSize = 500
+)
+
+var aeadKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+var (
+	ErrEmailEncryptionConfig = errors.New("invalid email encryption settings")
+	// ErrUnknownEmailKey is returned for an address encrypted with a key that
+	// is no longer configured
+	ErrUnknownEmailKey = errors.New("email encrypted with an unknown key")
+)
+
+// EmailCipher encrypts the user emails stored in core_users, with a blind
+// index for the equality lookups. When disabled, addresses are written in
+// plaintext but the ones encrypted earlier are still decrypted with the
+// configured keys.
+type EmailCipher struct {
+	enabled       bool
+	currentKeyID  string
+	keys          map[string]cipher.AEAD
+	blindIndexKey []byte
+	// err fails every write of a cipher whose settings could not be loaded,
+	// rather than storing plaintext addresses by mistake
+	err error
+}
+
+// NewEmailCipher parses the keys of SecretEmailEncryptionKeys and
+// SecretEmailBlindIndexKey. Encryption needs both.
+func NewEmailCipher(enabled bool, keys, blindIndexKey string) (*EmailCipher, error) {
+	aeads, currentKeyID, err := parseAEADKeys(keys)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEmailEncryptionConfig, err)
+	}
+	c := &EmailCipher{enabled: enabled, keys: aeads, currentKeyID: currentKeyID}
+	if blindIndexKey != "" {
+		key, err := base64.StdEncoding.DecodeString(blindIndexKey)
+		if err != nil || len(key) < 32 {
+			return nil, fmt.Errorf("%w: the blind index key must be at least 32 bytes in base64", ErrEmailEncryptionConfig)
+		}
+		c.blindIndexKey = key
+	}
+	if enabled && (c.currentKeyID == "" || c.blindIndexKey == nil) {
+		return nil, fmt.Errorf("%w: %s and %s are required", ErrEmailEncryptionConfig, SecretEmailEncryptionKeys, SecretEmailBlindIndexKey)
+	}
+	return c, nil
+}
+
+// parseAEADKeys parses id:base64 AES-256 keys separated by commas, and
+// returns their AES-GCM ciphers by id with the id of the first key
+func parseAEADKeys(keys string) (map[string]cipher.AEAD, string, error) {
+	aeads := map[string]cipher.AEAD{}
+	currentKeyID := ""
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !aeadKeyIDPattern.MatchString(id) {
+			return nil, "", errors.New("keys must be id:base64 with an id of letters, digits, - or _")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, "", fmt.Errorf("key %s must be 32 bytes in base64", id)
+		}
+		if _, exists := aeads[id]; exists {
+			return nil, "", fmt.Errorf("key %s is listed twice", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, "", err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, "", err
+		}
+		aeads[id] = aead
+		if currentKeyID == "" {
+			currentKeyID = id
+		}
+	}
+	return aeads, currentKeyID, nil
+}
+
+// EmailCipherFromSecrets loads the email encryption settings.
+//
+// Environment:
+//   - EMAIL_ENCRYPTION: encrypt the stored user emails (default false)
+func EmailCipherFromSecrets(ctx context.Context, provider SecretProvider) (*EmailCipher, error) {
+	enabled := false
+	if v := os.Getenv("EMAIL_ENCRYPTION"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			enabled = b
+		} else {
+			return nil, fmt.Errorf("%w: EMAIL_ENCRYPTION=%q", ErrEmailEncryptionConfig, v)
+		}
+	}
+	return NewEmailCipher(enabled,
+		readSecret(ctx, provider, SecretEmailEncryptionKeys),
+		readSecret(ctx, provider, SecretEmailBlindIndexKey))
+}
+
+// Enabled reports whether new addresses are encrypted
+func (c *EmailCipher) Enabled() bool {
+	return c.enabled
+}
+
+// CurrentKeyID is the id of the key encrypting new addresses
+func (c *EmailCipher) CurrentKeyID() string {
+	return c.currentKeyID
+}
+
+// Encrypt returns the stored form of an address and its blind index, which is
+// null when encryption is disabled
+func (c *EmailCipher) Encrypt(email string) (string, pgtype.Text, error) {
+	if c.err != nil {
+		return "", pgtype.Text{}, c.err
+	}
+	if !c.enabled || email == "" {
+		return email, pgtype.Text{}, nil
+	}
+	aead := c.keys[c.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", pgtype.Text{}, err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(email), nil)
+	stored := encryptedEmailPrefix + c.currentKeyID + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+	return stored, c.BlindIndex(email), nil
+}
+
+// BlindIndex returns the HMAC of the normalized address, or null without a
+// blind index key. It is computed even with encryption disabled so the
+// addresses encrypted earlier can still be found.
+func (c *EmailCipher) BlindIndex(email string) pgtype.Text {
+	if c.blindIndexKey == nil || email == "" {
+		return pgtype.Text{}
+	}
+	mac := hmac.New(sha256.New, c.blindIndexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return pgtype.Text{String: hex.EncodeToString(mac.Sum(nil)), Valid: true}
+}
+
+// Decrypt returns the address of a stored form, unchanged when it is in
+// plaintext
+func (c *EmailCipher) Decrypt(stored string) (string, error) {
+	keyID, ok := encryptedEmailKeyID(stored)
+	if !ok {
+		return stored, nil
+	}
+	aead, found := c.keys[keyID]
+	if !found {
+		return "", fmt.Errorf("%w %q", ErrUnknownEmailKey, keyID)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(stored[len(encryptedEmailPrefix)+len(keyID)+1:])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted email")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt email: %w", err)
+	}
+	return string(plain), nil
+}
+
+// encryptedEmailKeyID returns the key id of an encrypted address. A plaintext
+// address always has an @, which the encrypted form never contains.
+func encryptedEmailKeyID(stored string) (string, bool) {
+	if !strings.HasPrefix(stored, encryptedEmailPrefix) || strings.Contains(stored, "@") {
+		return "", false
+	}
+	keyID, _, ok := strings.Cut(stored[len(encryptedEmailPrefix):], ":")
+	return keyID, ok
+}
+
+var (
+	emailCipherMu sync.RWMutex
+	emailCipher   *EmailCipher
+)
+
+// SetEmailCipher replaces the cipher of the user emails, for embedding
+// applications that load it themselves
+func SetEmailCipher(c *EmailCipher) {
+	emailCipherMu.Lock()
+	defer emailCipherMu.Unlock()
+	emailCipher = c
+}
+
+func currentEmailCipher() *EmailCipher {
+	emailCipherMu.RLock()
+	c := emailCipher
+	emailCipherMu.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	emailCipherMu.Lock()
+	defer emailCipherMu.Unlock()
+	if emailCipher == nil {
+		loaded, err := EmailCipherFromSecrets(context.Background(), currentSecretProvider())
+		if err != nil {
+			log.Err(err).Msg("Failed to load the email encryption settings, user emails cannot be written")
+			loaded = &EmailCipher{keys: map[string]cipher.AEAD{}, err: err}
+		}
+		emailCipher = loaded
+	}
+	return emailCipher
+}
+
+// InitEmailEncryption loads the email encryption settings through the secret
+// provider, so invalid settings stop the start rather than the first write
+func InitEmailEncryption(ctx context.Context) error {
+	c, err := EmailCipherFromSecrets(ctx, currentSecretProvider())
+	if err != nil {
+		return err
+	}
+	SetEmailCipher(c)
+	return nil
+}
+
+// EncryptEmail returns the stored form and blind index of an address to write
+// to core_users
+func EncryptEmail(email string) (string, pgtype.Text, error) {
+	return currentEmailCipher().Encrypt(email)
+}
+
+// EmailBlindIndex returns the blind index to look an address up with, next to
+// the plaintext address
+func EmailBlindIndex(email string) pgtype.Text {
+	return currentEmailCipher().BlindIndex(email)
+}
+
+// searchBlindIndex returns the blind index of a user search, so an exact
+// address also finds the encrypted ones; words of an address only match the
+// plaintext addresses
+func searchBlindIndex(search pgtype.Text) pgtype.Text {
+	if !search.Valid {
+		return pgtype.Text{}
+	}
+	return EmailBlindIndex(search.String)
+}
+
+// DecryptEmail returns the address of a core_users email. An address that
+// cannot be decrypted is logged and returned as null.
+func DecryptEmail(stored pgtype.Text) pgtype.Text {
+	if !stored.Valid {
+		return stored
+	}
+	email, err := currentEmailCipher().Decrypt(stored.String)
+	if err != nil {
+		log.Err(err).Msg("Failed to decrypt user email")
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: email, Valid: true}
+}
+
+// DecryptUserEmail returns the user with its address decrypted and without
+// its blind index, ready to be returned by the API
+func DecryptUserEmail(user repository.CoreUser) repository.CoreUser {
+	user.Email = DecryptEmail(user.Email)
+	user.EmailBlindIndex = pgtype.Text{}
+	return user
+}
+
+// EmailEncryptionReport is the state of the stored addresses. Keys counts the
+// encrypted addresses by key id; Pending addresses are not in the current
+// format: plaintext or encrypted with another key while encryption is on,
+// encrypted while it is off, or with a stale blind index.
+type EmailEncryptionReport struct {
+	DryRun       bool
+	CheckedAt    time.Time
+	Enabled      bool
+	CurrentKeyID string
+	Plaintext    int64
+	Keys         map[string]int64
+	Pending      int
+	Rewritten    int
+	Failed       int
+}
+
+// EmailEncryptionService rewrites the stored addresses after the encryption
+// settings changed: turning it on or off, rotating the encryption key or the
+// blind index key
+type EmailEncryptionService struct {
+	store *db.Store
+}
+
+func NewEmailEncryptionService(store *db.Store) *EmailEncryptionService {
+	return &EmailEncryptionService{store: store}
+}
+
+// CheckEmailEncryption reports the addresses not in the current format and,
+// with rewrite, rewrites them. An address encrypted with a key that is no
+// longer configured is counted as failed.
+func (s *EmailEncryptionService) CheckEmailEncryption(ctx context.Context, rewrite bool) (EmailEncryptionReport, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	c := currentEmailCipher()
+	if c.err != nil {
+		return EmailEncryptionReport{}, fmt.Errorf("service.CheckEmailEncryption: %w", c.err)
+	}
+	report := EmailEncryptionReport{
+		DryRun:       !rewrite,
+		CheckedAt:    time.Now().UTC(),
+		Enabled:      c.enabled,
+		CurrentKeyID: c.currentKeyID,
+		Keys:         map[string]int64{},
+	}
+
+	afterID := ""
+	for {
+		rows, err := s.store.ListUserEmailsAfter(ctx, repository.ListUserEmailsAfterParams{
+			AfterID:   afterID,
+			BatchSize: emailRewriteBatchSize,
+		})
+		if err != nil {
+			logger.Err(err).Msg("Failed to list user emails")
+			return report, fmt.Errorf("service.CheckEmailEncryption: %w", err)
+		}
+		for _, row := range rows {
+			afterID = row.ID
+			if !row.Email.Valid || row.Email.String == "" {
+				continue
+			}
+			stored, blindIndex, pending, err := c.rewriteEmail(row.Email.String, row.EmailBlindIndex)
+			if err != nil {
+				logger.Err(err).Str("userID", row.ID).Msg("Failed to rewrite user email")
+				report.Failed++
+				continue
+			}
+			if !pending {
+				continue
+			}
+			report.Pending++
+			if !rewrite {
+				continue
+			}
+			updated, err := s.store.UpdateUserEmailStorage(ctx, repository.UpdateUserEmailStorageParams{
+				Email:           stored,
+				EmailBlindIndex: blindIndex,
+				ID:              row.ID,
+				PreviousEmail:   row.Email.String,
+			})
+			if err != nil {
+				logger.Err(err).Str("userID", row.ID).Msg("Failed to rewrite user email")
+				report.Failed++
+				continue
+			}
+			// A row updated meanwhile was written in the current format
+			if updated > 0 {
+				report.Rewritten++
+			}
+		}
+		if len(rows) < emailRewriteBatchSize {
+			break
+		}
+	}
+
+	counts, err := s.store.CountUserEmailsByKey(ctx)
+	if err != nil {
+		logger.Err(err).Msg("Failed to count user emails")
+		return report, fmt.Errorf("service.CheckEmailEncryption: %w", err)
+	}
+	for _, count := range counts {
+		if count.KeyID == "" {
+			report.Plaintext = count.Users
+		} else {
+			report.Keys[count.KeyID] = count.Users
+		}
+	}
+	return report, nil
+}
+
+// rewriteEmail returns the current format of a stored address and whether it
+// differs from the stored one
+func (c *EmailCipher) rewriteEmail(stored string, blindIndex pgtype.Text) (string, pgtype.Text, bool, error) {
+	email, err := c.Decrypt(stored)
+	if err != nil {
+		return "", pgtype.Text{}, false, err
+	}
+	keyID, encrypted := encryptedEmailKeyID(stored)
+	if !c.enabled {
+		return email, pgtype.Text{}, encrypted || blindIndex.Valid, nil
+	}
+	expected := c.BlindIndex(email)
+	if encrypted && keyID == c.currentKeyID && blindIndex == expected {
+		return stored, blindIndex, false, nil
+	}
+	stored, expected, err = c.Encrypt(email)
+	return stored, expected, true, err
+}
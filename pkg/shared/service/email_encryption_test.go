@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func testEmailKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestEmailCipherRoundTrip(t *testing.T) {
+	c, err := NewEmailCipher(true, "k1:"+testEmailKey('a'), testEmailKey('i'))
+	require.NoError(t, err)
+
+	stored, blindIndex, err := c.Encrypt("Jane@Example.com")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(stored, "enc:k1:"))
+	require.NotContains(t, stored, "@")
+	require.True(t, blindIndex.Valid)
+	require.Equal(t, c.BlindIndex(" jane@example.com"), blindIndex)
+
+	email, err := c.Decrypt(stored)
+	require.NoError(t, err)
+	require.Equal(t, "Jane@Example.com", email)
+
+	email, err = c.Decrypt("plain@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "plain@example.com", email)
+}
+
+func TestEmailCipherKeyRotation(t *testing.T) {
+	old, err := NewEmailCipher(true, "k1:"+testEmailKey('a'), testEmailKey('i'))
+	require.NoError(t, err)
+	stored, blindIndex, err := old.Encrypt("jane@example.com")
+	require.NoError(t, err)
+
+	c, err := NewEmailCipher(true, "k2:"+testEmailKey('b')+",k1:"+testEmailKey('a'), testEmailKey('i'))
+	require.NoError(t, err)
+	require.Equal(t, "k2", c.CurrentKeyID())
+
+	email, err := c.Decrypt(stored)
+	require.NoError(t, err)
+	require.Equal(t, "jane@example.com", email)
+
+	rewritten, rewrittenIndex, pending, err := c.rewriteEmail(stored, blindIndex)
+	require.NoError(t, err)
+	require.True(t, pending)
+	require.True(t, strings.HasPrefix(rewritten, "enc:k2:"))
+	require.Equal(t, blindIndex, rewrittenIndex)
+
+	_, _, pending, err = c.rewriteEmail(rewritten, rewrittenIndex)
+	require.NoError(t, err)
+	require.False(t, pending)
+
+	_, err = old.Decrypt(rewritten)
+	require.ErrorIs(t, err, ErrUnknownEmailKey)
+}
+
+func TestEmailCipherDisabled(t *testing.T) {
+	enabled, err := NewEmailCipher(true, "k1:"+testEmailKey('a'), testEmailKey('i'))
+	require.NoError(t, err)
+	stored, blindIndex, err := enabled.Encrypt("jane@example.com")
+	require.NoError(t, err)
+
+	c, err := NewEmailCipher(false, "k1:"+testEmailKey('a'), testEmailKey('i'))
+	require.NoError(t, err)
+
+	written, writtenIndex, err := c.Encrypt("joe@example.com")
+	require.NoError(t, err)
+	require.Equal(t, "joe@example.com", written)
+	require.False(t, writtenIndex.Valid)
+
+	plain, plainIndex, pending, err := c.rewriteEmail(stored, blindIndex)
+	require.NoError(t, err)
+	require.True(t, pending)
+	require.Equal(t, "jane@example.com", plain)
+	require.Equal(t, pgtype.Text{}, plainIndex)
+
+	_, _, pending, err = c.rewriteEmail("joe@example.com", pgtype.Text{})
+	require.NoError(t, err)
+	require.False(t, pending)
+}
+
+func TestNewEmailCipherInvalidSettings(t *testing.T) {
+	for name, tc := range map[string]struct {
+		enabled        bool
+		keys, indexKey string
+	}{
+		"enabled without keys":  {enabled: true, indexKey: testEmailKey('i')},
+		"enabled without index": {enabled: true, keys: "k1:" + testEmailKey('a')},
+		"short key":             {keys: "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		"missing id":            {keys: testEmailKey('a')},
+		"duplicate id":          {keys: "k1:" + testEmailKey('a') + ",k1:" + testEmailKey('b')},
+		"short index key":       {indexKey: base64.StdEncoding.EncodeToString([]byte("short"))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEmailCipher(tc.enabled, tc.keys, tc.indexKey)
+			require.ErrorIs(t, err, ErrEmailEncryptionConfig)
+		})
+	}
+}
+
+func TestEmailCipherWithLoadErrorRefusesWrites(t *testing.T) {
+	c := &EmailCipher{err: ErrEmailEncryptionConfig}
+	_, _, err := c.Encrypt("jane@example.com")
+	require.ErrorIs(t, err, ErrEmailEncryptionConfig)
+}
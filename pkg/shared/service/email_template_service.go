@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// DefaultEmailTemplateLocale is the locale of the template used when none
+	// matches the languages of the request
+	DefaultEmailTemplateLocale = "default"
+	maxEmailTemplateSubject    = 255
+	maxEmailTemplateBody       = 100 * 1024
+)
+
+var (
+	// ErrInvalidEmailTemplate is wrapped by every validation error of a template
+	ErrInvalidEmailTemplate  = errors.New("invalid email template")
+	ErrEmailTemplateNotFound = errors.New("email template not found")
+)
+
+// EmailTemplateVariables lists, for each event whose email a tenant may
+// customize, the variables its templates may use
+var EmailTemplateVariables = map[string][]string{
+	emailservice.EventWelcome:       {"link", "email", "tenant_name"},
+	emailservice.EventPasswordReset: {"link", "email", "tenant_name"},
+}
+
+var (
+	emailTemplateVariablePattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_]+)\s*\}\}`)
+	emailTemplateLocalePattern   = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+// EmailTemplateInput is the subject and body of a template, where {{name}}
+// stands for the variable name
+type EmailTemplateInput struct {
+	Subject string
+	Body    string
+}
+
+// EmailTemplateService manages the templates replacing the library ones for
+// the emails a tenant sends, per event and locale
+type EmailTemplateService struct {
+	store *db.Store
+}
+
+func NewEmailTemplateService(store *db.Store) *EmailTemplateService {
+	return &EmailTemplateService{store: store}
+}
+
+// NormalizeEmailTemplateLocale lowercases a locale, a language tag such as
+// fr or pt-br, or the default locale
+func NormalizeEmailTemplateLocale(locale string) (string, error) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if locale != DefaultEmailTemplateLocale && !emailTemplateLocalePattern.MatchString(locale) {
+		return "", fmt.Errorf("%w: %q is not a language tag such as en or pt-br, nor %s", ErrInvalidEmailTemplate, locale, DefaultEmailTemplateLocale)
+	}
+	return locale, nil
+}
+
+func validateEmailTemplateEvent(event string) error {
+	if _, ok := EmailTemplateVariables[event]; !ok {
+		return fmt.Errorf("%w: unknown event %q", ErrInvalidEmailTemplate, event)
+	}
+	return nil
+}
+
+func validateEmailTemplate(event string, input EmailTemplateInput) (EmailTemplateInput, error) {
+	if err := validateEmailTemplateEvent(event); err != nil {
+		return input, err
+	}
+	input.Subject = strings.TrimSpace(input.Subject)
+	if input.Subject == "" || len(input.Subject) > maxEmailTemplateSubject {
+		return input, fmt.Errorf("%w: the subject must have between 1 and %d characters", ErrInvalidEmailTemplate, maxEmailTemplateSubject)
+	}
+	if strings.ContainsAny(input.Subject, "\r\n") {
+		return input, fmt.Errorf("%w: the subject is a single line", ErrInvalidEmailTemplate)
+	}
+	if strings.TrimSpace(input.Body) == "" || len(input.Body) > maxEmailTemplateBody {
+		return input, fmt.Errorf("%w: the body must have between 1 and %d bytes", ErrInvalidEmailTemplate, maxEmailTemplateBody)
+	}
+	for _, text := range []string{input.Subject, input.Body} {
+		for _, match := range emailTemplateVariablePattern.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(EmailTemplateVariables[event], match[1]) {
+				return input, fmt.Errorf("%w: unknown variable %q for %s, use one of %s",
+					ErrInvalidEmailTemplate, match[1], event, strings.Join(EmailTemplateVariables[event], ", "))
+			}
+		}
+	}
+	return input, nil
+}
+
+// RenderEmailTemplate replaces the variables of the template. The values are
+// HTML escaped in the body and kept on one line in the subject.
+func RenderEmailTemplate(input EmailTemplateInput, vars map[string]string) emailservice.TemplateOverride {
+	interpolate := func(text string, escape func(string) string) string {
+		return emailTemplateVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+			name := emailTemplateVariablePattern.FindStringSubmatch(match)[1]
+			return escape(vars[name])
+		})
+	}
+	return emailservice.TemplateOverride{
+		Subject: interpolate(input.Subject, func(value string) string {
+			return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+		}),
+		Body: interpolate(input.Body, html.EscapeString),
+	}
+}
+
+// EmailTemplateLocales returns the locales to look a template up with for an
+// Accept-Language header: each language tag, then its primary language, then
+// the default locale
+func EmailTemplateLocales(acceptLanguage string) []string {
+	var tags, languages []string
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		tag, err := NormalizeEmailTemplateLocale(tag)
+		if err != nil || tag == DefaultEmailTemplateLocale {
+			continue
+		}
+		tags = append(tags, tag)
+		language, _, _ := strings.Cut(tag, "-")
+		languages = append(languages, language)
+	}
+	locales := []string{}
+	for _, locale := range append(append(tags, languages...), DefaultEmailTemplateLocale) {
+		if !slices.Contains(locales, locale) {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+func (s *EmailTemplateService) ListTemplates(ctx context.Context, tenantID string) ([]repository.CoreEmailTemplate, error) {
+	templates, err := s.store.ListEmailTemplates(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListTemplates: %w", err)
+	}
+	return templates, nil
+}
+
+func (s *EmailTemplateService) GetTemplate(ctx context.Context, tenantID, event, locale string) (repository.CoreEmailTemplate, error) {
+	if err := validateEmailTemplateEvent(event); err != nil {
+		return repository.CoreEmailTemplate{}, err
+	}
+	locale, err := NormalizeEmailTemplateLocale(locale)
+	if err != nil {
+		return repository.CoreEmailTemplate{}, err
+	}
+	template, err := s.store.GetEmailTemplate(ctx, repository.GetEmailTemplateParams{
+		TenantID:  tenantID,
+		EventType: event,
+		Locale:    locale,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreEmailTemplate{}, ErrEmailTemplateNotFound
+	}
+	if err != nil {
+		return repository.CoreEmailTemplate{}, fmt.Errorf("service.GetTemplate: %w", err)
+	}
+	return template, nil
+}
+
+// SaveTemplate creates or replaces the template of the event in the locale
+func (s *EmailTemplateService) SaveTemplate(ctx context.Context, tenantID, event, locale, updatedBy string, input EmailTemplateInput) (repository.CoreEmailTemplate, error) {
+	input, err := validateEmailTemplate(event, input)
+	if err != nil {
+		return repository.CoreEmailTemplate{}, err
+	}
+	locale, err = NormalizeEmailTemplateLocale(locale)
+	if err != nil {
+		return repository.CoreEmailTemplate{}, err
+	}
+	template, err := s.store.UpsertEmailTemplate(ctx, repository.UpsertEmailTemplateParams{
+		TenantID:  tenantID,
+		EventType: event,
+		Locale:    locale,
+		Subject:   input.Subject,
+		Body:      input.Body,
+		UpdatedBy: updatedBy,
+	})
+	if err != nil {
+		return repository.CoreEmailTemplate{}, fmt.Errorf("service.SaveTemplate: %w", err)
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes the template of the event in the locale, so the
+// library one is sent again
+func (s *EmailTemplateService) DeleteTemplate(ctx context.Context, tenantID, event, locale string) error {
+	if err := validateEmailTemplateEvent(event); err != nil {
+		return err
+	}
+	locale, err := NormalizeEmailTemplateLocale(locale)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteEmailTemplate(ctx, repository.DeleteEmailTemplateParams{
+		TenantID:  tenantID,
+		EventType: event,
+		Locale:    locale,
+	})
+	if err != nil {
+		return fmt.Errorf("service.DeleteTemplate: %w", err)
+	}
+	if deleted == 0 {
+		return ErrEmailTemplateNotFound
+	}
+	return nil
+}
+
+// Render renders the template of the event in the first of the locales the
+// tenant has one for, and reports whether there was one
+func (s *EmailTemplateService) Render(ctx context.Context, tenantID, event string, locales []string, vars map[string]string) (emailservice.TemplateOverride, bool, error) {
+	for _, locale := range locales {
+		template, err := s.store.GetEmailTemplate(ctx, repository.GetEmailTemplateParams{
+			TenantID:  tenantID,
+			EventType: event,
+			Locale:    locale,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return emailservice.TemplateOverride{}, false, fmt.Errorf("service.Render: %w", err)
+		}
+		return RenderEmailTemplate(EmailTemplateInput{Subject: template.Subject, Body: template.Body}, vars), true, nil
+	}
+	return emailservice.TemplateOverride{}, false, nil
+}
+
+// TenantEmailTemplateResolver renders the templates of the request tenant in
+// the languages of the request, to register with
+// emailservice.SetTemplateResolver. The library template is used when the
+// tenant has none or it cannot be read.
+func TenantEmailTemplateResolver(store *db.Store) emailservice.TemplateResolver {
+	templates := NewEmailTemplateService(store)
+	return func(ctx *gin.Context, event string, vars map[string]string) (emailservice.TemplateOverride, bool) {
+		tenantID := ctx.GetString(auth.AUTH_TENANT_ID_KEY)
+		if tenantID == "" {
+			return emailservice.TemplateOverride{}, false
+		}
+		logger := util.GetLoggerFromCtx(ctx)
+		if _, ok := vars["tenant_name"]; !ok {
+			if tenant, err := store.GetTenantByTenantID(ctx, tenantID); err == nil {
+				vars["tenant_name"] = tenant.Name
+			}
+		}
+		override, ok, err := templates.Render(ctx, tenantID, event, EmailTemplateLocales(ctx.GetHeader("Accept-Language")), vars)
+		if err != nil {
+			logger.Err(err).Str("event", event).Msg("Failed to load the email template of the tenant, using the library one")
+			return emailservice.TemplateOverride{}, false
+		}
+		return override, ok
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEmailTemplateLocale(t *testing.T) {
+	for input, want := range map[string]string{"fr": "fr", " pt_BR ": "pt-br", "Default": "default"} {
+		locale, err := NormalizeEmailTemplateLocale(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, locale)
+	}
+	for _, invalid := range []string{"", "f", "french!", "fr-"} {
+		_, err := NormalizeEmailTemplateLocale(invalid)
+		require.ErrorIs(t, err, ErrInvalidEmailTemplate, invalid)
+	}
+}
+
+func TestValidateEmailTemplate(t *testing.T) {
+	input, err := validateEmailTemplate(emailservice.EventWelcome, EmailTemplateInput{
+		Subject: " Welcome to {{tenant_name}} ",
+		Body:    `<a href="{{ link }}">Sign in</a> as {{email}}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Welcome to {{tenant_name}}", input.Subject)
+
+	for name, invalid := range map[string]EmailTemplateInput{
+		"empty subject":    {Subject: " ", Body: "body"},
+		"long subject":     {Subject: strings.Repeat("x", maxEmailTemplateSubject+1), Body: "body"},
+		"multiline":        {Subject: "Hello\r\nBcc: x@example.com", Body: "body"},
+		"empty body":       {Subject: "Hello", Body: " "},
+		"unknown variable": {Subject: "Hello", Body: "{{password}}"},
+	} {
+		_, err := validateEmailTemplate(emailservice.EventWelcome, invalid)
+		require.ErrorIs(t, err, ErrInvalidEmailTemplate, name)
+	}
+
+	_, err = validateEmailTemplate("newsletter", EmailTemplateInput{Subject: "Hello", Body: "body"})
+	require.ErrorIs(t, err, ErrInvalidEmailTemplate)
+}
+
+func TestRenderEmailTemplate(t *testing.T) {
+	override := RenderEmailTemplate(EmailTemplateInput{
+		Subject: "Welcome to {{ tenant_name }}",
+		Body:    `<p>{{tenant_name}}</p><a href="{{link}}">Reset</a>{{missing}}`,
+	}, map[string]string{
+		"tenant_name": "A&B\nBcc: x@example.com",
+		"link":        "https://example.com/?a=1&b=2",
+	})
+	require.Equal(t, "Welcome to A&B Bcc: x@example.com", override.Subject)
+	require.Equal(t, "<p>A&amp;B\nBcc: x@example.com</p><a href=\"https://example.com/?a=1&amp;b=2\">Reset</a>", override.Body)
+}
+
+func TestEmailTemplateLocales(t *testing.T) {
+	require.Equal(t, []string{"default"}, EmailTemplateLocales(""))
+	require.Equal(t, []string{"fr-ca", "fr", "en-us", "en", "default"},
+		EmailTemplateLocales("fr-CA,fr;q=0.9, en-US;q=0.8,*;q=0.5,de;q=0"))
+}
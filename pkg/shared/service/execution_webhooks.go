@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Sources a result webhook is declared on
+const (
+	ExecutionSourcePrompt   = "prompt"
+	ExecutionSourceSchedule = "schedule"
+)
+
+// Result webhook events, and the statuses of the executions they report
+const (
+	ExecutionCompletedWebhookEvent = "execution.completed"
+	ExecutionFailedWebhookEvent    = "execution.failed"
+
+	ExecutionStatusSucceeded = "succeeded"
+	ExecutionStatusFailed    = "failed"
+
+	maxExecutionSourceIDLength = 128
+	// Covers every attempt of the dispatcher with its backoff
+	executionWebhookTimeout = 2 * time.Minute
+)
+
+// ErrInvalidExecutionSource is returned for a source that is not a prompt or
+// a schedule, or without an ID
+var ErrInvalidExecutionSource = errors.New("the source must be a prompt or a schedule with an ID of at most 128 characters")
+
+// ExecutionResult is the outcome of a prompt execution, reported by the
+// module that ran it. It is the data of the result webhooks.
+type ExecutionResult struct {
+	ExecutionID string `json:"executionId"`
+	PromptID    string `json:"promptId"`
+	// ScheduleID is set when a schedule started the execution
+	ScheduleID  string      `json:"scheduleId,omitempty"`
+	Status      string      `json:"status"`
+	Output      interface{} `json:"output,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	StartedAt   time.Time   `json:"startedAt"`
+	CompletedAt time.Time   `json:"completedAt"`
+	// ResultURL is sent instead of the result when it is too large for a
+	// webhook, for the receiver to fetch it
+	ResultURL string `json:"-"`
+}
+
+// ExecutionWebhookService keeps the result webhooks of the prompts and
+// schedules, and delivers the results of their executions
+type ExecutionWebhookService struct {
+	store    *db.Store
+	signing  RequestSigningConfig
+	webhooks *webhook.Dispatcher
+}
+
+// NewExecutionWebhookService creates the service, signing the deliveries with
+// a secret derived from REQUEST_SIGNING_MASTER_KEY
+func NewExecutionWebhookService(store *db.Store) *ExecutionWebhookService {
+	return &ExecutionWebhookService{
+		store:    store,
+		signing:  RequestSigningConfigFromSecrets(context.Background(), currentSecretProvider()),
+		webhooks: webhook.NewPublicDispatcher(),
+	}
+}
+
+func validateExecutionSource(sourceType, sourceID string) error {
+	if (sourceType != ExecutionSourcePrompt && sourceType != ExecutionSourceSchedule) ||
+		sourceID == "" || len(sourceID) > maxExecutionSourceIDLength {
+		return ErrInvalidExecutionSource
+	}
+	return nil
+}
+
+// SetWebhook creates or replaces the result webhook of a prompt or schedule
+// of the tenant. The secret signing the deliveries is only returned here, and
+// changes with every update.
+func (s *ExecutionWebhookService) SetWebhook(ctx context.Context, tenantID, sourceType, sourceID,
+	webhookURL, createdBy string) (string, repository.CoreExecutionWebhook, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if err := validateExecutionSource(sourceType, sourceID); err != nil {
+		return "", repository.CoreExecutionWebhook{}, err
+	}
+	if len(s.signing.MasterKey) == 0 {
+		return "", repository.CoreExecutionWebhook{}, ErrRequestSigningNotConfigured
+	}
+	// The server POSTs from its own network, a tenant must not reach
+	// internal addresses through a webhook
+	if err := s.webhooks.ValidateTarget(ctx, webhookURL); err != nil {
+		return "", repository.CoreExecutionWebhook{}, fmt.Errorf("%w: %w", ErrInvalidWebhookURL, err)
+	}
+
+	record, err := s.store.UpsertExecutionWebhook(ctx, repository.UpsertExecutionWebhookParams{
+		TenantID:   tenantID,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+		Url:        webhookURL,
+		CreatedBy:  createdBy,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Str("source", sourceType+"/"+sourceID).Msg("Failed to store execution webhook")
+		return "", repository.CoreExecutionWebhook{}, fmt.Errorf("service.SetWebhook: %w", err)
+	}
+	return s.webhookSecret(record.ID), record, nil
+}
+
+// GetWebhook returns the result webhook of a prompt or schedule, without its
+// secret
+func (s *ExecutionWebhookService) GetWebhook(ctx context.Context, tenantID, sourceType, sourceID string) (repository.CoreExecutionWebhook, error) {
+	if err := validateExecutionSource(sourceType, sourceID); err != nil {
+		return repository.CoreExecutionWebhook{}, err
+	}
+	return s.store.GetExecutionWebhook(ctx, repository.GetExecutionWebhookParams{
+		TenantID:   tenantID,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+	})
+}
+
+// ListWebhooks returns the result webhooks of the tenant
+func (s *ExecutionWebhookService) ListWebhooks(ctx context.Context, tenantID string) ([]repository.CoreExecutionWebhook, error) {
+	return s.store.ListExecutionWebhooks(ctx, tenantID)
+}
+
+// DeleteWebhook removes the result webhook of a prompt or schedule
+func (s *ExecutionWebhookService) DeleteWebhook(ctx context.Context, tenantID, sourceType, sourceID string) error {
+	if err := validateExecutionSource(sourceType, sourceID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteExecutionWebhook(ctx, repository.DeleteExecutionWebhookParams{
+		TenantID:   tenantID,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.DeleteWebhook: %w", err)
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (s *ExecutionWebhookService) webhookSecret(webhookID uuid.UUID) string {
+	mac := hmac.New(sha256.New, s.signing.MasterKey)
+	mac.Write([]byte("execution-webhook:"))
+	mac.Write(webhookID[:])
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// NotifyExecutionCompleted delivers the result of a finished execution to the
+// webhooks of its prompt and of the schedule that started it. Modules call it
+// once the execution is recorded. The deliveries run in the background,
+// outliving the request, and their failures are only logged.
+func (s *ExecutionWebhookService) NotifyExecutionCompleted(ctx context.Context, tenantID string, result ExecutionResult) {
+	// Only the request logger is kept: ctx may be a gin context, which is
+	// reused once the request is served
+	logger := util.GetLoggerFromCtx(ctx)
+	ctx = context.WithValue(context.Background(), util.LoggerKey, logger)
+	go func() {
+		if _, err := s.deliverResult(ctx, tenantID, result); err != nil {
+			logger.Err(err).Str("tenantID", tenantID).Str("executionID", result.ExecutionID).Msg("Failed to deliver execution result webhook")
+		}
+	}()
+}
+
+// deliverResult sends the result to the webhooks of the execution and returns
+// the number of webhooks it was delivered to
+func (s *ExecutionWebhookService) deliverResult(ctx context.Context, tenantID string, result ExecutionResult) (int, error) {
+	if len(s.signing.MasterKey) == 0 {
+		return 0, nil
+	}
+	sources := [][2]string{{ExecutionSourcePrompt, result.PromptID}}
+	if result.ScheduleID != "" {
+		sources = append(sources, [2]string{ExecutionSourceSchedule, result.ScheduleID})
+	}
+
+	eventType := ExecutionCompletedWebhookEvent
+	if result.Status == ExecutionStatusFailed {
+		eventType = ExecutionFailedWebhookEvent
+	}
+	ctx, cancel := context.WithTimeout(ctx, executionWebhookTimeout)
+	defer cancel()
+
+	delivered := 0
+	var errs []error
+	for _, source := range sources {
+		hook, err := s.store.GetExecutionWebhook(ctx, repository.GetExecutionWebhookParams{
+			TenantID:   tenantID,
+			SourceType: source[0],
+			SourceID:   source[1],
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		envelope := webhook.Envelope{
+			ID:        uuid.New().String(),
+			EventType: eventType,
+			CreatedAt: time.Now().UTC(),
+			Data:      result,
+		}
+		target := webhook.Target{URL: hook.Url, Secret: s.webhookSecret(hook.ID)}
+		if err := s.webhooks.Deliver(ctx, target, envelope, result.ResultURL); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", source[0], source[1], err))
+			continue
+		}
+		delivered++
+	}
+	return delivered, errors.Join(errs...)
+}
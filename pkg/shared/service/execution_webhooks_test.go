@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSigningKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestExecutionWebhookSetRejectsInvalidInput(t *testing.T) {
+	service := &ExecutionWebhookService{
+		signing:  RequestSigningConfig{MasterKey: testSigningKey},
+		webhooks: webhook.NewPublicDispatcher(),
+	}
+	for _, source := range [][2]string{{"flow", "p-1"}, {ExecutionSourcePrompt, ""}, {ExecutionSourceSchedule, string(make([]byte, 129))}} {
+		_, _, err := service.SetWebhook(t.Context(), "tenant-1", source[0], source[1], "https://example.com/hook", "admin-1")
+		assert.ErrorIs(t, err, ErrInvalidExecutionSource, source[0])
+	}
+	for _, rawURL := range []string{
+		"http://example.com/hook",
+		"https://127.0.0.1/hook",
+		"https://10.1.2.3/hook",
+		"https://169.254.169.254/latest/meta-data",
+	} {
+		_, _, err := service.SetWebhook(t.Context(), "tenant-1", ExecutionSourcePrompt, "p-1", rawURL, "admin-1")
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+	}
+
+	service.signing = RequestSigningConfig{}
+	_, _, err := service.SetWebhook(t.Context(), "tenant-1", ExecutionSourcePrompt, "p-1", "https://example.com/hook", "admin-1")
+	require.ErrorIs(t, err, ErrRequestSigningNotConfigured)
+}
+
+func TestExecutionWebhookSecret(t *testing.T) {
+	service := &ExecutionWebhookService{signing: RequestSigningConfig{MasterKey: testSigningKey}}
+	id := uuid.New()
+	secret := service.webhookSecret(id)
+	require.Contains(t, secret, WebhookSecretPrefix)
+	require.Equal(t, secret, service.webhookSecret(id))
+	require.NotEqual(t, secret, service.webhookSecret(uuid.New()))
+}
+
+func TestExecutionWebhookDelivery(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewExecutionWebhookService(store)
+	service.signing = RequestSigningConfig{MasterKey: testSigningKey}
+	// The webhooks of the tests are served on the loopback interface
+	service.webhooks = webhook.NewDispatcher().WithRetryPolicy(1, time.Millisecond)
+
+	type delivery struct {
+		path  string
+		event string
+		valid bool
+		data  ExecutionResult
+	}
+	var mu sync.Mutex
+	var received []delivery
+	secrets := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+		var envelope struct {
+			Data ExecutionResult `json:"data"`
+		}
+		_ = json.Unmarshal(body, &envelope)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, delivery{
+			path:  r.URL.Path,
+			event: r.Header.Get(webhook.EventHeader),
+			valid: webhook.Verify(secrets[r.URL.Path], timestamp, body, r.Header.Get(webhook.SignatureHeader)),
+			data:  envelope.Data,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tenantID := "tenant-" + uuid.NewString()[:8]
+	promptSecret, promptHook, err := service.SetWebhook(t.Context(), tenantID, ExecutionSourcePrompt, "prompt-1", server.URL+"/prompt", "admin-1")
+	require.NoError(t, err)
+	require.Equal(t, ExecutionSourcePrompt, promptHook.SourceType)
+	scheduleSecret, _, err := service.SetWebhook(t.Context(), tenantID, ExecutionSourceSchedule, "schedule-1", server.URL+"/schedule", "admin-1")
+	require.NoError(t, err)
+	secrets["/prompt"] = promptSecret
+	secrets["/schedule"] = scheduleSecret
+
+	hooks, err := service.ListWebhooks(t.Context(), tenantID)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+
+	t.Run("scheduled execution reaches both webhooks", func(t *testing.T) {
+		received = nil
+		delivered, err := service.deliverResult(t.Context(), tenantID, ExecutionResult{
+			ExecutionID: "exec-1",
+			PromptID:    "prompt-1",
+			ScheduleID:  "schedule-1",
+			Status:      ExecutionStatusSucceeded,
+			Output:      "done",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, delivered)
+		require.Len(t, received, 2)
+		for _, d := range received {
+			require.True(t, d.valid, d.path)
+			require.Equal(t, ExecutionCompletedWebhookEvent, d.event)
+			require.Equal(t, "exec-1", d.data.ExecutionID)
+		}
+	})
+
+	t.Run("failed execution of another tenant is not delivered", func(t *testing.T) {
+		received = nil
+		delivered, err := service.deliverResult(t.Context(), "other-tenant", ExecutionResult{
+			ExecutionID: "exec-2",
+			PromptID:    "prompt-1",
+			Status:      ExecutionStatusFailed,
+		})
+		require.NoError(t, err)
+		require.Zero(t, delivered)
+		require.Empty(t, received)
+	})
+
+	t.Run("failed execution reports the failure", func(t *testing.T) {
+		received = nil
+		delivered, err := service.deliverResult(t.Context(), tenantID, ExecutionResult{
+			ExecutionID: "exec-3",
+			PromptID:    "prompt-1",
+			Status:      ExecutionStatusFailed,
+			Error:       "model unavailable",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, delivered)
+		require.Len(t, received, 1)
+		require.Equal(t, ExecutionFailedWebhookEvent, received[0].event)
+		require.Equal(t, "model unavailable", received[0].data.Error)
+	})
+
+	t.Run("deleted webhook is no longer delivered", func(t *testing.T) {
+		require.NoError(t, service.DeleteWebhook(t.Context(), tenantID, ExecutionSourcePrompt, "prompt-1"))
+		require.ErrorIs(t, service.DeleteWebhook(t.Context(), tenantID, ExecutionSourcePrompt, "prompt-1"), pgx.ErrNoRows)
+		_, err := service.GetWebhook(t.Context(), tenantID, ExecutionSourcePrompt, "prompt-1")
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		received = nil
+		delivered, err := service.deliverResult(t.Context(), tenantID, ExecutionResult{ExecutionID: "exec-4", PromptID: "prompt-1"})
+		require.NoError(t, err)
+		require.Zero(t, delivered)
+		require.Empty(t, received)
+	})
+}
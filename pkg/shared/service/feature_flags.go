@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Sources of the value of a flag for a tenant
+const (
+	FeatureFlagSourceDisabled = "disabled"
+	FeatureFlagSourceOverride = "override"
+	FeatureFlagSourceRollout  = "rollout"
+)
+
+const featureFlagsContextKey = "feature_flags"
+
+var featureFlagKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+var (
+	// ErrFeatureFlagNotFound is returned for a flag, or an override, that does not exist
+	ErrFeatureFlagNotFound = errors.New("feature flag not found")
+	// ErrInvalidFeatureFlag is returned for a malformed key or a rollout
+	// percentage out of 0 to 100
+	ErrInvalidFeatureFlag = errors.New("a feature flag key is dotted lowercase words of at most 64 characters, its rollout a percentage from 0 to 100")
+)
+
+// FeatureFlagEvaluation is the value of a flag for a tenant and why
+type FeatureFlagEvaluation struct {
+	Key     string
+	Enabled bool
+	Source  string
+	// Override is the override of the tenant, if any
+	Override *bool
+}
+
+// featureFlagsCache keeps the flag definitions under a single key, and
+// featureFlagOverridesCache the overrides of each tenant by tenant id
+var (
+	featureFlagsCache         = NewPublicCache[[]repository.CoreFeatureFlag](DefaultPublicCacheTTL)
+	featureFlagOverridesCache = NewPublicCache[map[string]bool](DefaultPublicCacheTTL)
+)
+
+// FeatureFlagService manages the feature flags and evaluates them for the
+// tenants. A disabled flag is off for every tenant. An enabled flag is on for
+// the tenants with an override turning it on, off for those with one turning
+// it off, and otherwise on for the share of the tenants given by its rollout
+// percentage: each tenant falls in a stable bucket from 0 to 99 of the flag,
+// so raising the percentage only adds tenants.
+type FeatureFlagService struct {
+	store *db.Store
+}
+
+func NewFeatureFlagService(store *db.Store) *FeatureFlagService {
+	return &FeatureFlagService{store: store}
+}
+
+// ListFlags returns the flags by key
+func (s *FeatureFlagService) ListFlags(ctx context.Context) ([]repository.CoreFeatureFlag, error) {
+	return featureFlagsCache.Get(ctx, "", func(ctx context.Context) ([]repository.CoreFeatureFlag, error) {
+		return s.store.ListFeatureFlags(ctx)
+	})
+}
+
+// SaveFlag creates or updates the flag
+func (s *FeatureFlagService) SaveFlag(ctx context.Context, key string, description *string, enabled bool, rolloutPercentage int, updatedBy string) (repository.CoreFeatureFlag, error) {
+	if len(key) > 64 || !featureFlagKeyPattern.MatchString(key) || rolloutPercentage < 0 || rolloutPercentage > 100 {
+		return repository.CoreFeatureFlag{}, ErrInvalidFeatureFlag
+	}
+	flag, err := s.store.UpsertFeatureFlag(ctx, repository.UpsertFeatureFlagParams{
+		Key:               key,
+		Description:       util.ToNullableText(description),
+		Enabled:           enabled,
+		RolloutPercentage: int32(rolloutPercentage),
+		UpdatedBy:         updatedBy,
+	})
+	if err != nil {
+		return repository.CoreFeatureFlag{}, fmt.Errorf("service.SaveFlag: %w", err)
+	}
+	featureFlagsCache.InvalidateAll()
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("flag", key).Bool("enabled", enabled).
+		Int("rollout_percentage", rolloutPercentage).Str("updated_by", updatedBy).Msg("Feature flag saved")
+	return flag, nil
+}
+
+// DeleteFlag deletes the flag with its overrides
+func (s *FeatureFlagService) DeleteFlag(ctx context.Context, key string) error {
+	deleted, err := s.store.DeleteFeatureFlag(ctx, key)
+	if err != nil {
+		return fmt.Errorf("service.DeleteFlag: %w", err)
+	}
+	if deleted == 0 {
+		return ErrFeatureFlagNotFound
+	}
+	featureFlagsCache.InvalidateAll()
+	featureFlagOverridesCache.InvalidateAll()
+	return nil
+}
+
+// SetOverride turns the flag on or off for the tenant whatever its rollout
+func (s *FeatureFlagService) SetOverride(ctx context.Context, tenantID, key string, enabled bool, updatedBy string) error {
+	if _, err := s.store.GetFeatureFlag(ctx, key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrFeatureFlagNotFound
+		}
+		return fmt.Errorf("service.SetOverride: %w", err)
+	}
+	if _, err := s.store.UpsertTenantFeatureFlagOverride(ctx, repository.UpsertTenantFeatureFlagOverrideParams{
+		TenantID:  tenantID,
+		FlagKey:   key,
+		Enabled:   enabled,
+		UpdatedBy: updatedBy,
+	}); err != nil {
+		return fmt.Errorf("service.SetOverride: %w", err)
+	}
+	featureFlagOverridesCache.Invalidate(tenantID)
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("tenant_id", tenantID).Str("flag", key).Bool("enabled", enabled).
+		Str("updated_by", updatedBy).Msg("Feature flag overridden")
+	return nil
+}
+
+// DeleteOverride gives the tenant the rollout of the flag back
+func (s *FeatureFlagService) DeleteOverride(ctx context.Context, tenantID, key string) error {
+	deleted, err := s.store.DeleteTenantFeatureFlagOverride(ctx, repository.DeleteTenantFeatureFlagOverrideParams{
+		TenantID: tenantID,
+		FlagKey:  key,
+	})
+	if err != nil {
+		return fmt.Errorf("service.DeleteOverride: %w", err)
+	}
+	if deleted == 0 {
+		return ErrFeatureFlagNotFound
+	}
+	featureFlagOverridesCache.Invalidate(tenantID)
+	return nil
+}
+
+func (s *FeatureFlagService) overrides(ctx context.Context, tenantID string) (map[string]bool, error) {
+	return featureFlagOverridesCache.Get(ctx, tenantID, func(ctx context.Context) (map[string]bool, error) {
+		rows, err := s.store.ListTenantFeatureFlagOverrides(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		overrides := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			overrides[row.FlagKey] = row.Enabled
+		}
+		return overrides, nil
+	})
+}
+
+// EvaluateFlags returns every flag for the tenant with the source of its value
+func (s *FeatureFlagService) EvaluateFlags(ctx context.Context, tenantID string) ([]FeatureFlagEvaluation, error) {
+	flags, err := s.ListFlags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.EvaluateFlags: %w", err)
+	}
+	overrides, err := s.overrides(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.EvaluateFlags: %w", err)
+	}
+	evaluations := make([]FeatureFlagEvaluation, len(flags))
+	for i, flag := range flags {
+		var override *bool
+		if enabled, ok := overrides[flag.Key]; ok {
+			override = &enabled
+		}
+		evaluations[i] = evaluateFeatureFlag(flag, override, tenantID)
+	}
+	return evaluations, nil
+}
+
+// FlagValues returns whether each flag is on for the tenant, by key
+func (s *FeatureFlagService) FlagValues(ctx context.Context, tenantID string) (map[string]bool, error) {
+	evaluations, err := s.EvaluateFlags(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]bool, len(evaluations))
+	for _, evaluation := range evaluations {
+		values[evaluation.Key] = evaluation.Enabled
+	}
+	return values, nil
+}
+
+func evaluateFeatureFlag(flag repository.CoreFeatureFlag, override *bool, tenantID string) FeatureFlagEvaluation {
+	evaluation := FeatureFlagEvaluation{Key: flag.Key, Override: override}
+	switch {
+	case !flag.Enabled:
+		evaluation.Source = FeatureFlagSourceDisabled
+	case override != nil:
+		evaluation.Source = FeatureFlagSourceOverride
+		evaluation.Enabled = *override
+	default:
+		evaluation.Source = FeatureFlagSourceRollout
+		evaluation.Enabled = featureFlagBucket(flag.Key, tenantID) < int(flag.RolloutPercentage)
+	}
+	return evaluation
+}
+
+// featureFlagBucket places the tenant in a bucket from 0 to 99 of the flag.
+// The bucket depends on the flag too, so the same tenants are not always the
+// first to get every flag.
+func featureFlagBucket(key, tenantID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + tenantID))
+	return int(h.Sum32() % 100)
+}
+
+// Middleware evaluates the flags of the request tenant and stores them in the
+// context, where handlers read them with FeatureFlagsFromContext and
+// FeatureEnabled. It runs after the tenant middleware; requests without a
+// tenant get no flag. A failure is logged only and leaves every flag off.
+func (s *FeatureFlagService) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+		if tenantID != "" {
+			if values, err := s.FlagValues(c, tenantID); err == nil {
+				c.Set(featureFlagsContextKey, values)
+			} else {
+				log.Err(err).Str("tenant_id", tenantID).Msg("Failed to evaluate feature flags")
+			}
+		}
+		c.Next()
+	}
+}
+
+// FeatureFlagsFromContext returns the flags of the request tenant, evaluated
+// by the feature flag middleware; nil outside a tenant. The map is shared and
+// must not be modified.
+func FeatureFlagsFromContext(c *gin.Context) map[string]bool {
+	if values, ok := c.Get(featureFlagsContextKey); ok {
+		return values.(map[string]bool)
+	}
+	return nil
+}
+
+// FeatureEnabled reports whether the flag is on for the request tenant. An
+// unknown flag is off.
+func FeatureEnabled(c *gin.Context, key string) bool {
+	return FeatureFlagsFromContext(c)[key]
+}
+
+// RequireFeatureFlag answers 404 to the requests of the tenants the flag is
+// off for, so a feature not rolled out to a tenant does not exist for it
+func RequireFeatureFlag(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FeatureEnabled(c, key) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"status":  http.StatusNotFound,
+				"message": "Not found",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagBucket(t *testing.T) {
+	require.Equal(t, featureFlagBucket("chat", "tenant-1"), featureFlagBucket("chat", "tenant-1"))
+
+	on := 0
+	for i := 0; i < 1000; i++ {
+		bucket := featureFlagBucket("chat", commontestutils.RandomString(12))
+		require.True(t, bucket >= 0 && bucket < 100)
+		if bucket < 30 {
+			on++
+		}
+	}
+	// About 30% of the tenants fall under a 30% rollout
+	require.InDelta(t, 300, on, 60)
+}
+
+func TestEvaluateFeatureFlag(t *testing.T) {
+	on, off := true, false
+	flag := repository.CoreFeatureFlag{Key: "chat", Enabled: true, RolloutPercentage: 100}
+
+	evaluation := evaluateFeatureFlag(flag, nil, "tenant-1")
+	require.True(t, evaluation.Enabled)
+	require.Equal(t, FeatureFlagSourceRollout, evaluation.Source)
+
+	flag.RolloutPercentage = 0
+	require.False(t, evaluateFeatureFlag(flag, nil, "tenant-1").Enabled)
+
+	evaluation = evaluateFeatureFlag(flag, &on, "tenant-1")
+	require.True(t, evaluation.Enabled)
+	require.Equal(t, FeatureFlagSourceOverride, evaluation.Source)
+
+	flag.RolloutPercentage = 100
+	require.False(t, evaluateFeatureFlag(flag, &off, "tenant-1").Enabled)
+
+	// A disabled flag is off whatever the override
+	flag.Enabled = false
+	evaluation = evaluateFeatureFlag(flag, &on, "tenant-1")
+	require.False(t, evaluation.Enabled)
+	require.Equal(t, FeatureFlagSourceDisabled, evaluation.Source)
+}
+
+func TestSaveFeatureFlagValidation(t *testing.T) {
+	s := NewFeatureFlagService(nil)
+	for _, key := range []string{"", "Chat", "1chat", "chat-v2", "chat.", strings.Repeat("a", 65)} {
+		_, err := s.SaveFlag(context.Background(), key, nil, true, 50, "user")
+		require.ErrorIs(t, err, ErrInvalidFeatureFlag, key)
+	}
+	for _, rollout := range []int{-1, 101} {
+		_, err := s.SaveFlag(context.Background(), "chat", nil, true, rollout, "user")
+		require.ErrorIs(t, err, ErrInvalidFeatureFlag)
+	}
+}
+
+func TestRequireFeatureFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(featureFlagsContextKey, map[string]bool{"chat": true, "search": false})
+		c.Next()
+	})
+	router.GET("/chat", RequireFeatureFlag("chat"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/search", RequireFeatureFlag("search"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/unknown", RequireFeatureFlag("unknown"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for path, status := range map[string]int{"/chat": http.StatusOK, "/search": http.StatusNotFound, "/unknown": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, status, w.Code, path)
+	}
+}
+
+func TestFeatureFlagService(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	s := NewFeatureFlagService(store)
+	ctx := context.Background()
+	createdBy := commontestutils.RandomString(10)
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    createdBy,
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+
+	key := "test." + strings.ToLower(commontestutils.RandomString(8))
+	_, err = s.SaveFlag(ctx, key, nil, true, 0, createdBy)
+	require.NoError(t, err)
+	values, err := s.FlagValues(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.False(t, values[key])
+
+	require.NoError(t, s.SetOverride(ctx, tenant.TenantID, key, true, createdBy))
+	values, err = s.FlagValues(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.True(t, values[key])
+
+	_, err = s.SaveFlag(ctx, key, nil, true, 100, createdBy)
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteOverride(ctx, tenant.TenantID, key))
+	values, err = s.FlagValues(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.True(t, values[key])
+	require.ErrorIs(t, s.DeleteOverride(ctx, tenant.TenantID, key), ErrFeatureFlagNotFound)
+
+	require.ErrorIs(t, s.SetOverride(ctx, tenant.TenantID, "test.unknown", true, createdBy), ErrFeatureFlagNotFound)
+
+	require.NoError(t, s.DeleteFlag(ctx, key))
+	values, err = s.FlagValues(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.NotContains(t, values, key)
+	require.ErrorIs(t, s.DeleteFlag(ctx, key), ErrFeatureFlagNotFound)
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// SecretFileEncryptionMasterKeys lists id:base64 AES-256 master keys separated
+// by commas, read through the SecretProvider. The first one wraps the new data
+// keys of the tenants; the others only unwrap the data keys wrapped earlier.
+const SecretFileEncryptionMasterKeys = "FILE_ENCRYPTION_MASTER_KEYS"
+
+// States of the rewrite of the files of a tenant
+const (
+	FileRewriteIdle    = "idle"
+	FileRewritePending = "pending"
+	FileRewriteRunning = "running"
+	FileRewriteFailed  = "failed"
+)
+
+const (
+	DefaultFileRewriteInterval = time.Minute
+	// fileRewriteStaleAfter is how long a running rewrite is left to its
+	// instance before another one takes it over
+	fileRewriteStaleAfter = 30 * time.Minute
+	dataKeySize           = 32
+)
+
+var (
+	// ErrFileEncryptionNotConfigured is returned when enabling the encryption
+	// without master keys
+	ErrFileEncryptionNotConfigured = errors.New("file encryption is not configured: " + SecretFileEncryptionMasterKeys + " is not set")
+	ErrFileEncryptionNotEnabled    = errors.New("the tenant does not encrypt its files")
+	ErrFileEncryptionTenant        = errors.New("tenant not found")
+)
+
+// TenantFileKeyring holds the data keys of core_tenant_file_keys, wrapped by
+// the master keys. A data key is unwrapped once and kept in memory.
+type TenantFileKeyring struct {
+	store              *db.Store
+	masterKeys         map[string]cipher.AEAD
+	currentMasterKeyID string
+	// current is the active key of each tenant, nil for the tenants that do
+	// not encrypt their files
+	current *PublicCache[*fileservice.DataKey]
+	keys    sync.Map
+}
+
+// NewTenantFileKeyring parses the master keys of
+// SecretFileEncryptionMasterKeys
+func NewTenantFileKeyring(store *db.Store, masterKeys string) (*TenantFileKeyring, error) {
+	aeads, currentMasterKeyID, err := parseAEADKeys(masterKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", SecretFileEncryptionMasterKeys, err)
+	}
+	if currentMasterKeyID == "" {
+		return nil, ErrFileEncryptionNotConfigured
+	}
+	return &TenantFileKeyring{
+		store:              store,
+		masterKeys:         aeads,
+		currentMasterKeyID: currentMasterKeyID,
+		current:            NewPublicCache[*fileservice.DataKey](DefaultPublicCacheTTL),
+	}, nil
+}
+
+func (k *TenantFileKeyring) CurrentKey(ctx context.Context, tenantID string) (*fileservice.DataKey, error) {
+	return k.current.Get(ctx, tenantID, func(ctx context.Context) (*fileservice.DataKey, error) {
+		row, err := k.store.GetActiveTenantFileKey(ctx, tenantID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return k.unwrap(row)
+	})
+}
+
+func (k *TenantFileKeyring) Key(ctx context.Context, tenantID string, id uuid.UUID) (*fileservice.DataKey, error) {
+	if key, ok := k.keys.Load(id); ok {
+		return key.(*fileservice.DataKey), nil
+	}
+	row, err := k.store.GetTenantFileKey(ctx, repository.GetTenantFileKeyParams{ID: id, TenantID: tenantID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return k.unwrap(row)
+}
+
+// unwrap decrypts a data key with its master key. The tenant is
+// authenticated with it, so a key moved to another tenant does not unwrap.
+func (k *TenantFileKeyring) unwrap(row repository.CoreTenantFileKey) (*fileservice.DataKey, error) {
+	if key, ok := k.keys.Load(row.ID); ok {
+		return key.(*fileservice.DataKey), nil
+	}
+	aead, ok := k.masterKeys[row.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("data key %s is wrapped by the unknown master key %q", row.ID, row.MasterKeyID)
+	}
+	if len(row.WrappedKey) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed data key %s", row.ID)
+	}
+	nonce, sealed := row.WrappedKey[:aead.NonceSize()], row.WrappedKey[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, []byte(row.TenantID))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key %s: %w", row.ID, err)
+	}
+	key := &fileservice.DataKey{ID: row.ID, Key: plain}
+	k.keys.Store(row.ID, key)
+	return key, nil
+}
+
+// wrap encrypts a data key of the tenant with the current master key
+func (k *TenantFileKeyring) wrap(tenantID string, plain []byte) ([]byte, error) {
+	aead := k.masterKeys[k.currentMasterKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, []byte(tenantID)), nil
+}
+
+// createKey generates the new active data key of the tenant
+func (k *TenantFileKeyring) createKey(ctx context.Context, qtx *repository.Queries, tenantID string) (repository.CoreTenantFileKey, error) {
+	plain := make([]byte, dataKeySize)
+	if _, err := rand.Read(plain); err != nil {
+		return repository.CoreTenantFileKey{}, err
+	}
+	wrapped, err := k.wrap(tenantID, plain)
+	if err != nil {
+		return repository.CoreTenantFileKey{}, err
+	}
+	return qtx.CreateTenantFileKey(ctx, repository.CreateTenantFileKeyParams{
+		TenantID:    tenantID,
+		WrappedKey:  wrapped,
+		MasterKeyID: k.currentMasterKeyID,
+	})
+}
+
+var (
+	tenantFileKeyringMu sync.RWMutex
+	tenantFileKeyring   *TenantFileKeyring
+)
+
+// InitFileEncryption loads the master keys through the secret provider and
+// installs the keyring in the FileService layer. Without master keys the
+// files are stored as given and the encryption cannot be enabled.
+func InitFileEncryption(ctx context.Context, store *db.Store) error {
+	masterKeys := readSecret(ctx, currentSecretProvider(), SecretFileEncryptionMasterKeys)
+	if masterKeys == "" {
+		log.Info().Msg("File encryption disabled, " + SecretFileEncryptionMasterKeys + " is not set")
+		return nil
+	}
+	keyring, err := NewTenantFileKeyring(store, masterKeys)
+	if err != nil {
+		return err
+	}
+	tenantFileKeyringMu.Lock()
+	tenantFileKeyring = keyring
+	tenantFileKeyringMu.Unlock()
+	fileservice.SetTenantKeyring(keyring)
+	return nil
+}
+
+func currentTenantFileKeyring() *TenantFileKeyring {
+	tenantFileKeyringMu.RLock()
+	defer tenantFileKeyringMu.RUnlock()
+	return tenantFileKeyring
+}
+
+// FileEncryptionConfig sets the background rewrite of the tenant files
+type FileEncryptionConfig struct {
+	// RewriteInterval is how often pending rewrites are looked for, zero
+	// disables them on this instance
+	RewriteInterval time.Duration
+}
+
+// FileEncryptionConfigFromEnv reads the rewrite settings.
+//
+// Environment:
+//   - FILE_ENCRYPTION_REWRITE_INTERVAL: how often pending rewrites are looked
+//     for (default 1m, 0 disables them on this instance)
+func FileEncryptionConfigFromEnv() FileEncryptionConfig {
+	cfg := FileEncryptionConfig{RewriteInterval: DefaultFileRewriteInterval}
+	if v := os.Getenv("FILE_ENCRYPTION_REWRITE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.RewriteInterval = d
+		} else {
+			log.Warn().Str("FILE_ENCRYPTION_REWRITE_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// FileEncryptionService enables the encryption of the files of a tenant with
+// its own data key, rotates the key, and rewrites the files in the background
+// with the active key of their tenant
+type FileEncryptionService struct {
+	store *db.Store
+	files *fileservice.FileService
+	cfg   FileEncryptionConfig
+}
+
+func NewFileEncryptionService(store *db.Store, files *fileservice.FileService, cfg FileEncryptionConfig) *FileEncryptionService {
+	return &FileEncryptionService{store: store, files: files, cfg: cfg}
+}
+
+// EnableTenant creates the data key of the tenant, unless it has one, and
+// asks for its files to be rewritten. A failed rewrite is resumed.
+func (s *FileEncryptionService) EnableTenant(ctx context.Context, tenantID, actorID string) (repository.ListTenantFileEncryptionRow, error) {
+	keyring := currentTenantFileKeyring()
+	if keyring == nil {
+		return repository.ListTenantFileEncryptionRow{}, ErrFileEncryptionNotConfigured
+	}
+	if _, err := s.store.GetTenantByTenantID(ctx, tenantID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ListTenantFileEncryptionRow{}, ErrFileEncryptionTenant
+		}
+		return repository.ListTenantFileEncryptionRow{}, fmt.Errorf("service.EnableTenant: %w", err)
+	}
+	err := s.withTx(ctx, func(qtx *repository.Queries) error {
+		if _, err := qtx.GetActiveTenantFileKey(ctx, tenantID); errors.Is(err, pgx.ErrNoRows) {
+			if _, err := keyring.createKey(ctx, qtx, tenantID); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		_, err := qtx.RequestTenantFileRewrite(ctx, repository.RequestTenantFileRewriteParams{
+			TenantID:  tenantID,
+			EnabledBy: actorID,
+		})
+		return err
+	})
+	if err != nil {
+		return repository.ListTenantFileEncryptionRow{}, fmt.Errorf("service.EnableTenant: %w", err)
+	}
+	keyring.current.Invalidate(tenantID)
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("tenant_id", tenantID).Str("actor_id", actorID).Msg("Tenant file encryption enabled")
+	return s.Status(ctx, tenantID)
+}
+
+// RotateTenantKey replaces the data key of the tenant. The files are
+// rewritten with the new key in the background, the old key decrypting them
+// meanwhile.
+func (s *FileEncryptionService) RotateTenantKey(ctx context.Context, tenantID, actorID string) (repository.ListTenantFileEncryptionRow, error) {
+	keyring := currentTenantFileKeyring()
+	if keyring == nil {
+		return repository.ListTenantFileEncryptionRow{}, ErrFileEncryptionNotConfigured
+	}
+	err := s.withTx(ctx, func(qtx *repository.Queries) error {
+		rotated, err := qtx.RotateTenantFileKey(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		if rotated == 0 {
+			return ErrFileEncryptionNotEnabled
+		}
+		if _, err := keyring.createKey(ctx, qtx, tenantID); err != nil {
+			return err
+		}
+		_, err = qtx.RequestTenantFileRewrite(ctx, repository.RequestTenantFileRewriteParams{
+			TenantID:  tenantID,
+			EnabledBy: actorID,
+		})
+		return err
+	})
+	if errors.Is(err, ErrFileEncryptionNotEnabled) {
+		return repository.ListTenantFileEncryptionRow{}, err
+	}
+	if err != nil {
+		return repository.ListTenantFileEncryptionRow{}, fmt.Errorf("service.RotateTenantKey: %w", err)
+	}
+	keyring.current.Invalidate(tenantID)
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("tenant_id", tenantID).Str("actor_id", actorID).Msg("Tenant file key rotated")
+	return s.Status(ctx, tenantID)
+}
+
+func (s *FileEncryptionService) withTx(ctx context.Context, fn func(qtx *repository.Queries) error) error {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(s.store.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Status returns the encryption of the files of the tenant
+func (s *FileEncryptionService) Status(ctx context.Context, tenantID string) (repository.ListTenantFileEncryptionRow, error) {
+	rows, err := s.store.ListTenantFileEncryption(ctx, repository.ListTenantFileEncryptionParams{
+		TenantID: pgtype.Text{String: tenantID, Valid: true},
+		Limit:    1,
+	})
+	if err != nil {
+		return repository.ListTenantFileEncryptionRow{}, fmt.Errorf("service.Status: %w", err)
+	}
+	if len(rows) == 0 {
+		return repository.ListTenantFileEncryptionRow{}, ErrFileEncryptionNotEnabled
+	}
+	return rows[0], nil
+}
+
+// ListStatuses lists the tenants encrypting their files
+func (s *FileEncryptionService) ListStatuses(ctx context.Context, limit, offset int32) ([]repository.ListTenantFileEncryptionRow, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.store.ListTenantFileEncryption(ctx, repository.ListTenantFileEncryptionParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListStatuses: %w", err)
+	}
+	return rows, nil
+}
+
+// StartRewrites runs the pending rewrites every RewriteInterval until ctx is
+// done
+func (s *FileEncryptionService) StartRewrites(ctx context.Context) {
+	if s.cfg.RewriteInterval <= 0 || currentTenantFileKeyring() == nil {
+		log.Info().Msg("Tenant file rewrites disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.RewriteInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.RunRewrites(ctx); err != nil {
+				log.Err(err).Msg("Tenant file rewrites failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunRewrites claims the pending rewrites one tenant at a time and rewrites
+// the files of each with its active key. The rotated keys are retired once
+// no file uses them. A failed rewrite is recorded on its row and waits to be
+// resumed by EnableTenant. It returns the number of tenants rewritten.
+func (s *FileEncryptionService) RunRewrites(ctx context.Context) (int, error) {
+	keyring := currentTenantFileKeyring()
+	if keyring == nil {
+		return 0, nil
+	}
+	done := 0
+	for {
+		claimed, err := s.store.ClaimTenantFileRewrite(ctx, time.Now().Add(-fileRewriteStaleAfter))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return done, nil
+		}
+		if err != nil {
+			return done, fmt.Errorf("service.RunRewrites: %w", err)
+		}
+		// The key may have been rotated on another instance
+		keyring.current.Invalidate(claimed.TenantID)
+		if err := s.rewrite(ctx, claimed); err != nil {
+			return done, err
+		}
+		done++
+	}
+}
+
+func (s *FileEncryptionService) rewrite(ctx context.Context, claimed repository.CoreTenantFileEncryption) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	rewritten, err := s.files.RewriteTenantFiles(ctx, claimed.TenantID)
+	if err != nil {
+		logger.Err(err).Str("tenant_id", claimed.TenantID).Int("rewritten", rewritten).Msg("Failed to rewrite tenant files")
+		_, failErr := s.store.FailTenantFileRewrite(ctx, repository.FailTenantFileRewriteParams{
+			TenantID:         claimed.TenantID,
+			RewriteStartedAt: claimed.RewriteStartedAt,
+			FilesRewritten:   int32(rewritten),
+			LastError:        pgtype.Text{String: err.Error(), Valid: true},
+		})
+		if failErr != nil {
+			return fmt.Errorf("service.RunRewrites: %w", failErr)
+		}
+		return nil
+	}
+	if _, err := s.store.RetireTenantFileKeys(ctx, repository.RetireTenantFileKeysParams{
+		TenantID:         claimed.TenantID,
+		RewriteStartedAt: claimed.RewriteStartedAt.Time,
+	}); err != nil {
+		return fmt.Errorf("service.RunRewrites: %w", err)
+	}
+	if _, err := s.store.CompleteTenantFileRewrite(ctx, repository.CompleteTenantFileRewriteParams{
+		TenantID:         claimed.TenantID,
+		RewriteStartedAt: claimed.RewriteStartedAt,
+		FilesRewritten:   int32(rewritten),
+	}); err != nil {
+		return fmt.Errorf("service.RunRewrites: %w", err)
+	}
+	logger.Info().Str("tenant_id", claimed.TenantID).Int("rewritten", rewritten).Msg("Tenant files rewritten")
+	return nil
+}
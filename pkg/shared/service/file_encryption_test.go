@@ -0,0 +1,44 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantFileKeyringUnwrap(t *testing.T) {
+	old, err := NewTenantFileKeyring(nil, "m1:"+testEmailKey('a'))
+	require.NoError(t, err)
+	plain := bytes.Repeat([]byte{7}, dataKeySize)
+	wrapped, err := old.wrap("tenant-a", plain)
+	require.NoError(t, err)
+	row := repository.CoreTenantFileKey{ID: uuid.New(), TenantID: "tenant-a", WrappedKey: wrapped, MasterKeyID: "m1"}
+
+	// A rotated master key still unwraps the data keys it wrapped
+	k, err := NewTenantFileKeyring(nil, "m2:"+testEmailKey('b')+",m1:"+testEmailKey('a'))
+	require.NoError(t, err)
+	key, err := k.unwrap(row)
+	require.NoError(t, err)
+	require.Equal(t, row.ID, key.ID)
+	require.Equal(t, plain, key.Key)
+
+	moved := row
+	moved.ID = uuid.New()
+	moved.TenantID = "tenant-b"
+	_, err = k.unwrap(moved)
+	require.Error(t, err)
+
+	unknown := row
+	unknown.ID = uuid.New()
+	unknown.MasterKeyID = "m3"
+	_, err = k.unwrap(unknown)
+	require.ErrorContains(t, err, "unknown master key")
+}
+
+func TestNewTenantFileKeyringRequiresKeys(t *testing.T) {
+	_, err := NewTenantFileKeyring(nil, "m1:not-base64")
+	require.Error(t, err)
+}
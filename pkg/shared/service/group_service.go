@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxGroupNameLength = 128
+	// The roles granted by the groups are read again after this delay, so the
+	// changes made on another instance apply
+	groupRolesCacheTTL = 30 * time.Second
+)
+
+// GroupRoles are the roles a group can grant. The platform roles, ADMIN and
+// SUPER_ADMIN, are never granted through a tenant.
+var GroupRoles = []string{string(core.USER), string(core.CUSTOMERADMIN)}
+
+var (
+	// ErrInvalidGroup is wrapped by every validation error of a group
+	ErrInvalidGroup        = errors.New("invalid group")
+	ErrGroupNotFound       = errors.New("group not found")
+	ErrGroupNameTaken      = errors.New("a group with this name already exists")
+	ErrGroupMemberNotFound = errors.New("the user is not a member of the group")
+	// ErrNotTenantMember is returned when adding a user who is not an active
+	// member of the tenant to a group
+	ErrNotTenantMember = errors.New("the user is not an active member of the tenant")
+)
+
+// GroupInput is a group to create or the new state of a group
+type GroupInput struct {
+	Name        string
+	Description string
+	Roles       []string
+}
+
+type groupRolesKey struct {
+	tenantID string
+	userID   string
+}
+
+type groupRolesEntry struct {
+	roles    []string
+	loadedAt time.Time
+}
+
+// groupRolesCache is shared by the GroupService instances of the process, so
+// the changes made through the API apply to the auth middleware right away
+var groupRolesCache = struct {
+	sync.Mutex
+	entries map[groupRolesKey]groupRolesEntry
+}{entries: map[groupRolesKey]groupRolesEntry{}}
+
+// GroupService manages the groups of users of a tenant. The roles of a group
+// are granted to its members by the auth middleware, on top of their own, so
+// permissions can be managed by team.
+type GroupService struct {
+	store *db.Store
+}
+
+func NewGroupService(store *db.Store) *GroupService {
+	return &GroupService{store: store}
+}
+
+func normalizeGroupInput(input GroupInput) (GroupInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+	if input.Name == "" || len(input.Name) > maxGroupNameLength {
+		return input, fmt.Errorf("%w: the name must have between 1 and %d characters", ErrInvalidGroup, maxGroupNameLength)
+	}
+	input.Roles = slices.Compact(slices.Sorted(slices.Values(input.Roles)))
+	for _, role := range input.Roles {
+		if !slices.Contains(GroupRoles, role) {
+			return input, fmt.Errorf("%w: a group cannot grant %s", ErrInvalidGroup, role)
+		}
+	}
+	return input, nil
+}
+
+func groupStoreError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrGroupNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation:
+		return ErrGroupNameTaken
+	default:
+		return fmt.Errorf("service.%s: %w", op, err)
+	}
+}
+
+// CreateGroup creates a group in the tenant
+func (s *GroupService) CreateGroup(ctx context.Context, tenantID, createdBy string, input GroupInput) (repository.CoreGroup, error) {
+	input, err := normalizeGroupInput(input)
+	if err != nil {
+		return repository.CoreGroup{}, err
+	}
+	group, err := s.store.CreateGroup(ctx, repository.CreateGroupParams{
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+		Roles:       input.Roles,
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return repository.CoreGroup{}, groupStoreError("CreateGroup", err)
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("group_id", group.ID.String()).Strs("roles", group.Roles).Msg("Group created")
+	return group, nil
+}
+
+// GetGroup returns a group of the tenant
+func (s *GroupService) GetGroup(ctx context.Context, tenantID string, id uuid.UUID) (repository.CoreGroup, error) {
+	group, err := s.store.GetGroupByID(ctx, repository.GetGroupByIDParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return repository.CoreGroup{}, groupStoreError("GetGroup", err)
+	}
+	return group, nil
+}
+
+// ListGroups lists the groups of the tenant by name
+func (s *GroupService) ListGroups(ctx context.Context, tenantID string) ([]repository.CoreGroup, error) {
+	groups, err := s.store.ListGroups(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListGroups: %w", err)
+	}
+	return groups, nil
+}
+
+// UpdateGroup replaces the name, description and roles of a group
+func (s *GroupService) UpdateGroup(ctx context.Context, tenantID string, id uuid.UUID, input GroupInput) (repository.CoreGroup, error) {
+	input, err := normalizeGroupInput(input)
+	if err != nil {
+		return repository.CoreGroup{}, err
+	}
+	group, err := s.store.UpdateGroup(ctx, repository.UpdateGroupParams{
+		ID:          id,
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+		Roles:       input.Roles,
+	})
+	if err != nil {
+		return repository.CoreGroup{}, groupStoreError("UpdateGroup", err)
+	}
+	forgetGroupRoles(tenantID, "")
+	return group, nil
+}
+
+// DeleteGroup deletes a group, and the roles it granted with it
+func (s *GroupService) DeleteGroup(ctx context.Context, tenantID string, id uuid.UUID) error {
+	deleted, err := s.store.DeleteGroup(ctx, repository.DeleteGroupParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return fmt.Errorf("service.DeleteGroup: %w", err)
+	}
+	if deleted == 0 {
+		return ErrGroupNotFound
+	}
+	forgetGroupRoles(tenantID, "")
+	return nil
+}
+
+// ListMembers lists the members of a group of the tenant
+func (s *GroupService) ListMembers(ctx context.Context, tenantID string, id uuid.UUID) ([]repository.CoreGroupMember, error) {
+	if _, err := s.GetGroup(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	members, err := s.store.ListGroupMembers(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListMembers: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds an active member of the tenant to a group. Adding a member
+// twice does nothing.
+func (s *GroupService) AddMember(ctx context.Context, group repository.CoreGroup, userID, actorID string) error {
+	membership, err := s.store.GetSharedUserTenantMembership(ctx, repository.GetSharedUserTenantMembershipParams{
+		UserID:   userID,
+		TenantID: group.TenantID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("service.AddMember: %w", err)
+	}
+	if err != nil || membership.Status != "active" {
+		return ErrNotTenantMember
+	}
+	added, err := s.store.AddGroupMember(ctx, repository.AddGroupMemberParams{
+		GroupID: group.ID,
+		UserID:  userID,
+		AddedBy: actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.AddMember: %w", err)
+	}
+	if added > 0 {
+		forgetGroupRoles(group.TenantID, userID)
+		s.recordGroupActivity(ctx, group, userID, actorID, "group_joined")
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a group
+func (s *GroupService) RemoveMember(ctx context.Context, group repository.CoreGroup, userID, actorID string) error {
+	removed, err := s.store.RemoveGroupMember(ctx, repository.RemoveGroupMemberParams{
+		GroupID: group.ID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.RemoveMember: %w", err)
+	}
+	if removed == 0 {
+		return ErrGroupMemberNotFound
+	}
+	forgetGroupRoles(group.TenantID, userID)
+	s.recordGroupActivity(ctx, group, userID, actorID, "group_left")
+	return nil
+}
+
+// GroupRoles returns the roles the groups of the user grant in the tenant
+func (s *GroupService) GroupRoles(ctx context.Context, tenantID, userID string) ([]string, error) {
+	key := groupRolesKey{tenantID: tenantID, userID: userID}
+	now := time.Now()
+	groupRolesCache.Lock()
+	entry, found := groupRolesCache.entries[key]
+	groupRolesCache.Unlock()
+	if found && now.Sub(entry.loadedAt) < groupRolesCacheTTL {
+		return entry.roles, nil
+	}
+
+	roles, err := s.store.ListUserGroupRoles(ctx, repository.ListUserGroupRolesParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("service.GroupRoles: %w", err)
+	}
+	groupRolesCache.Lock()
+	defer groupRolesCache.Unlock()
+	// Expired entries are dropped as they are met, which bounds the cache
+	// to the users of the last TTL
+	for k, e := range groupRolesCache.entries {
+		if now.Sub(e.loadedAt) >= groupRolesCacheTTL {
+			delete(groupRolesCache.entries, k)
+		}
+	}
+	groupRolesCache.entries[key] = groupRolesEntry{roles: roles, loadedAt: now}
+	return roles, nil
+}
+
+// forgetGroupRoles drops the cached roles of the user, or of every user of the
+// tenant when userID is empty
+func forgetGroupRoles(tenantID, userID string) {
+	groupRolesCache.Lock()
+	defer groupRolesCache.Unlock()
+	for key := range groupRolesCache.entries {
+		if key.tenantID == tenantID && (userID == "" || key.userID == userID) {
+			delete(groupRolesCache.entries, key)
+		}
+	}
+}
+
+// withGroupRoles returns the user with the roles of their groups in the
+// tenant added to the claims. The user itself is left untouched, the
+// provider may cache it.
+func withGroupRoles(ctx context.Context, groups *GroupService, user *auth.AuthenticatedUser) *auth.AuthenticatedUser {
+	if groups == nil || user.TenantID == "" {
+		return user
+	}
+	roles, err := groups.GroupRoles(ctx, user.TenantID, user.UserID)
+	if err != nil {
+		// Without its groups the user keeps their own roles
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", user.UserID).Msg("Failed to resolve group roles")
+		return user
+	}
+	var claims map[string]interface{}
+	for _, role := range roles {
+		if user.Claims[role] == true {
+			continue
+		}
+		if claims == nil {
+			claims = maps.Clone(user.Claims)
+			if claims == nil {
+				claims = map[string]interface{}{}
+			}
+		}
+		claims[role] = true
+	}
+	if claims == nil {
+		return user
+	}
+	granted := *user
+	granted.Claims = claims
+	return &granted
+}
+
+func (s *GroupService) recordGroupActivity(ctx context.Context, group repository.CoreGroup, userID, actorID, eventType string) {
+	if actorID == userID {
+		actorID = ""
+	}
+	// Failures are logged by recordUserActivity
+	_ = recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  group.TenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryMembership,
+		EventType: eventType,
+		ActorID:   actorID,
+		Data: map[string]interface{}{
+			"group_id":   group.ID.String(),
+			"group_name": group.Name,
+			"roles":      group.Roles,
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeGroupInput(t *testing.T) {
+	input, err := normalizeGroupInput(GroupInput{Name: " Support ", Roles: []string{"USER", "CUSTOMER_ADMIN", "USER"}})
+	require.NoError(t, err)
+	require.Equal(t, "Support", input.Name)
+	require.Equal(t, []string{"CUSTOMER_ADMIN", "USER"}, input.Roles)
+
+	_, err = normalizeGroupInput(GroupInput{Name: "  "})
+	require.ErrorIs(t, err, ErrInvalidGroup)
+	_, err = normalizeGroupInput(GroupInput{Name: "Platform", Roles: []string{"SUPER_ADMIN"}})
+	require.ErrorIs(t, err, ErrInvalidGroup)
+}
+
+func TestWithGroupRoles(t *testing.T) {
+	groups := NewGroupService(nil)
+	ctx := context.Background()
+	user := &auth.AuthenticatedUser{
+		UserID:   "u1",
+		TenantID: "t1",
+		Claims:   map[string]interface{}{"USER": true},
+	}
+	groupRolesCache.Lock()
+	groupRolesCache.entries[groupRolesKey{tenantID: "t1", userID: "u1"}] = groupRolesEntry{
+		roles:    []string{"CUSTOMER_ADMIN", "USER"},
+		loadedAt: time.Now(),
+	}
+	groupRolesCache.Unlock()
+	t.Cleanup(func() { forgetGroupRoles("t1", "") })
+
+	granted := withGroupRoles(ctx, groups, user)
+	require.Equal(t, true, granted.Claims["CUSTOMER_ADMIN"])
+	require.Equal(t, true, granted.Claims["USER"])
+	// The user of the provider is left untouched
+	require.NotContains(t, user.Claims, "CUSTOMER_ADMIN")
+
+	// Without a tenant, or without groups, the user is unchanged
+	require.Same(t, user, withGroupRoles(ctx, nil, user))
+	global := &auth.AuthenticatedUser{UserID: "u1"}
+	require.Same(t, global, withGroupRoles(ctx, groups, global))
+
+	forgetGroupRoles("t1", "u1")
+	groupRolesCache.Lock()
+	require.NotContains(t, groupRolesCache.entries, groupRolesKey{tenantID: "t1", userID: "u1"})
+	groupRolesCache.Unlock()
+}
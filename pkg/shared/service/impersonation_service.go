@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ImpersonationTokenHeader carries the impersonation token, next to the
+	// session of the super admin
+	ImpersonationTokenHeader = "X-Impersonation-Token"
+	// ImpersonatedByHeader is set on the responses of impersonated requests
+	ImpersonatedByHeader = "X-Impersonated-By"
+
+	DefaultImpersonationTTL = 15 * time.Minute
+	MaxImpersonationTTL     = time.Hour
+
+	impersonationTokenPrefix = "imp_"
+	maxImpersonationReason   = 500
+)
+
+var (
+	ErrImpersonationReasonRequired = errors.New("a reason is required to impersonate a user")
+	// ErrImpersonationNotAllowed is returned for targets that cannot be
+	// impersonated: the caller itself, global admins and inactive members
+	ErrImpersonationNotAllowed = errors.New("this user cannot be impersonated")
+)
+
+// Impersonation is a running impersonation of a tenant user by a super admin
+type Impersonation struct {
+	ID        string
+	ActorID   string
+	TenantID  string
+	UserID    string
+	Reason    string
+	ExpiresAt time.Time
+}
+
+// ImpersonationService lets super admins act as a tenant user for a short
+// time, to reproduce what the user sees. Every impersonation is kept with its
+// reason, recorded on the timeline of the user, and every impersonated
+// request is logged.
+//
+// Environment:
+//   - IMPERSONATION_TTL: lifetime of an impersonation token (default 15m, at
+//     most 1h)
+type ImpersonationService struct {
+	store *db.Store
+	ttl   time.Duration
+}
+
+func NewImpersonationService(store *db.Store) *ImpersonationService {
+	ttl := DefaultImpersonationTTL
+	if v := os.Getenv("IMPERSONATION_TTL"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+			ttl = min(parsed, MaxImpersonationTTL)
+		} else {
+			log.Warn().Str("IMPERSONATION_TTL", v).Msg("Invalid duration, using default")
+		}
+	}
+	return &ImpersonationService{store: store, ttl: ttl}
+}
+
+// StartImpersonation checks the target is an active member of the tenant
+// without a global role and returns the token to send in the
+// X-Impersonation-Token header
+func (s *ImpersonationService) StartImpersonation(ctx context.Context, actorID, tenantID, userID, reason string) (string, Impersonation, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", Impersonation{}, ErrImpersonationReasonRequired
+	}
+	if len(reason) > maxImpersonationReason {
+		return "", Impersonation{}, fmt.Errorf("%w: at most %d characters", ErrImpersonationReasonRequired, maxImpersonationReason)
+	}
+	if actorID == userID {
+		return "", Impersonation{}, ErrImpersonationNotAllowed
+	}
+	target, err := s.store.GetSharedUserByTenantByID(ctx, repository.GetSharedUserByTenantByIDParams{
+		ID:       userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return "", Impersonation{}, fmt.Errorf("service.StartImpersonation: %w", err)
+	}
+	if len(target.Roles) > 0 || target.MembershipStatus != "active" {
+		return "", Impersonation{}, ErrImpersonationNotAllowed
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", Impersonation{}, fmt.Errorf("service.StartImpersonation: %w", err)
+	}
+	token := impersonationTokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+	hash := sha256.Sum256([]byte(token))
+	row, err := s.store.CreateUserImpersonation(ctx, repository.CreateUserImpersonationParams{
+		TokenHash: hash[:],
+		ActorID:   actorID,
+		TenantID:  tenantID,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return "", Impersonation{}, fmt.Errorf("service.StartImpersonation: %w", err)
+	}
+	impersonation := toImpersonation(row)
+
+	logger.Info().Str("impersonation_id", impersonation.ID).Str("actor_id", actorID).Str("tenant_id", tenantID).
+		Str("user_id", userID).Str("reason", reason).Time("expires_at", impersonation.ExpiresAt).Msg("Impersonation started")
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryAccount,
+		EventType: "impersonation_started",
+		ActorID:   actorID,
+		Data: map[string]interface{}{
+			"impersonation_id": impersonation.ID,
+			"reason":           reason,
+			"expires_at":       impersonation.ExpiresAt,
+		},
+	}); err != nil {
+		logger.Err(err).Str("impersonation_id", impersonation.ID).Msg("Failed to record impersonation on the timeline")
+	}
+	return token, impersonation, nil
+}
+
+// EndImpersonations ends the running impersonations of the user by the actor.
+// It reports whether one was running.
+func (s *ImpersonationService) EndImpersonations(ctx context.Context, actorID, tenantID, userID string) (bool, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	ended, err := s.store.EndUserImpersonations(ctx, repository.EndUserImpersonationsParams{
+		ActorID:  actorID,
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("service.EndImpersonations: %w", err)
+	}
+	if ended == 0 {
+		return false, nil
+	}
+	logger.Info().Str("actor_id", actorID).Str("tenant_id", tenantID).Str("user_id", userID).Msg("Impersonation ended")
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryAccount,
+		EventType: "impersonation_ended",
+		ActorID:   actorID,
+	}); err != nil {
+		logger.Err(err).Msg("Failed to record impersonation on the timeline")
+	}
+	return true, nil
+}
+
+// VerifyImpersonation returns the running impersonation of a token, for the
+// super admin who started it and on its tenant only. ok is false for an
+// unknown, ended, expired or foreign token.
+func (s *ImpersonationService) VerifyImpersonation(ctx context.Context, actorID, tenantID, token string) (Impersonation, bool, error) {
+	if !strings.HasPrefix(token, impersonationTokenPrefix) || tenantID == "" {
+		return Impersonation{}, false, nil
+	}
+	hash := sha256.Sum256([]byte(token))
+	row, err := s.store.GetActiveUserImpersonation(ctx, repository.GetActiveUserImpersonationParams{
+		TokenHash: hash[:],
+		ActorID:   actorID,
+		TenantID:  tenantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Impersonation{}, false, nil
+		}
+		return Impersonation{}, false, fmt.Errorf("service.VerifyImpersonation: %w", err)
+	}
+	return toImpersonation(row), true, nil
+}
+
+// ImpersonatedUser returns the user of an impersonation as the auth
+// middleware sees it, with the roles of their membership and the act_as claim
+func (s *ImpersonationService) ImpersonatedUser(ctx context.Context, impersonation Impersonation) (*auth.AuthenticatedUser, error) {
+	target, err := s.store.GetSharedUserByTenantByID(ctx, repository.GetSharedUserByTenantByIDParams{
+		ID:       impersonation.UserID,
+		TenantID: impersonation.TenantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ImpersonatedUser: %w", err)
+	}
+	// The membership may have changed since the impersonation started
+	if len(target.Roles) > 0 || target.MembershipStatus != "active" {
+		return nil, ErrImpersonationNotAllowed
+	}
+	tenant, err := s.store.GetTenantByTenantID(ctx, impersonation.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ImpersonatedUser: %w", err)
+	}
+	return impersonatedUser(impersonation, target, tenant.AllowSignUp), nil
+}
+
+func impersonatedUser(impersonation Impersonation, target repository.GetSharedUserByTenantByIDRow, allowSignUp bool) *auth.AuthenticatedUser {
+	claims := map[string]interface{}{
+		auth.CLAIM_ACT_AS: map[string]interface{}{
+			"actor_id":         impersonation.ActorID,
+			"impersonation_id": impersonation.ID,
+			"expires_at":       impersonation.ExpiresAt,
+		},
+	}
+	roles := slices.Clone(target.TenantRoles)
+	for _, role := range roles {
+		// A membership never grants the global roles
+		if role != string(core.SUPERADMIN) && role != string(core.ADMIN) {
+			claims[role] = true
+		}
+	}
+	return &auth.AuthenticatedUser{
+		UserID:            target.ID,
+		Email:             DecryptEmail(target.Email).String,
+		EmailVerified:     true,
+		Claims:            claims,
+		TenantID:          impersonation.TenantID,
+		TenantMemberships: []auth.TenantMembership{{TenantID: impersonation.TenantID, Roles: roles}},
+		TenantAllowSignUp: allowSignUp,
+	}
+}
+
+func toImpersonation(row repository.CoreUserImpersonation) Impersonation {
+	return Impersonation{
+		ID:        row.ID.String(),
+		ActorID:   row.ActorID,
+		TenantID:  row.TenantID,
+		UserID:    row.UserID,
+		Reason:    row.Reason,
+		ExpiresAt: row.ExpiresAt,
+	}
+}
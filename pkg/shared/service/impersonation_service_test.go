@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonatedUser(t *testing.T) {
+	impersonation := Impersonation{
+		ID:        "0b7c5d1e-3f7a-4c55-9d2e-6a1b2c3d4e5f",
+		ActorID:   "super-admin",
+		TenantID:  "tenant-1",
+		UserID:    "user-1",
+		Reason:    "ticket 42",
+		ExpiresAt: time.Now().Add(DefaultImpersonationTTL),
+	}
+	target := repository.GetSharedUserByTenantByIDRow{
+		ID:               "user-1",
+		TenantRoles:      []string{"CUSTOMER_ADMIN", "ADMIN"},
+		MembershipStatus: "active",
+	}
+
+	user := impersonatedUser(impersonation, target, true)
+	require.Equal(t, "user-1", user.UserID)
+	require.Equal(t, "tenant-1", user.TenantID)
+	require.True(t, user.TenantAllowSignUp)
+	require.Equal(t, true, user.Claims["CUSTOMER_ADMIN"])
+	// a membership never grants a global role
+	require.NotContains(t, user.Claims, "ADMIN")
+	require.Len(t, user.TenantMemberships, 1)
+
+	actAs, ok := user.Claims[auth.CLAIM_ACT_AS].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "super-admin", actAs["actor_id"])
+	require.Equal(t, impersonation.ID, actAs["impersonation_id"])
+
+	// the roles of the membership row are left untouched
+	require.Equal(t, []string{"CUSTOMER_ADMIN", "ADMIN"}, target.TenantRoles)
+}
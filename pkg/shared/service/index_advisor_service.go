@@ -0,0 +1,352 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultIndexAdvisorInterval = 24 * time.Hour
+	DefaultIndexAdvisorMinCalls = 100
+	// indexAdvisorMaxStatements bounds the statements read from
+	// pg_stat_statements, the most expensive first
+	indexAdvisorMaxStatements = 500
+	indexAdvisorTenantColumn  = "tenant_id"
+	postgresMaxIdentifierLen  = 63
+)
+
+var ErrStatStatementsUnavailable = errors.New("pg_stat_statements is not available, add it to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements")
+
+// IndexAdvisorConfig configures the periodic analysis.
+//
+// Environment:
+//   - INDEX_ADVISOR_INTERVAL: analysis interval (default 24h, 0 disables the job)
+//   - INDEX_ADVISOR_MIN_CALLS: calls below which a query is not worth an index (default 100)
+type IndexAdvisorConfig struct {
+	Interval time.Duration
+	MinCalls int64
+}
+
+func IndexAdvisorConfigFromEnv() IndexAdvisorConfig {
+	cfg := IndexAdvisorConfig{Interval: DefaultIndexAdvisorInterval, MinCalls: DefaultIndexAdvisorMinCalls}
+	if v := os.Getenv("INDEX_ADVISOR_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("INDEX_ADVISOR_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("INDEX_ADVISOR_MIN_CALLS"); v != "" {
+		if calls, err := strconv.ParseInt(v, 10, 64); err == nil && calls >= 0 {
+			cfg.MinCalls = calls
+		} else {
+			log.Warn().Str("INDEX_ADVISOR_MIN_CALLS", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// IndexRecommendation is a (tenant_id, sort column) index missing for the
+// tenant scoped list queries of a table
+type IndexRecommendation struct {
+	Table   string
+	Columns []string
+	// Statement creates the index without locking writes
+	Statement string
+	// Statements is the number of queries the index would serve, Calls,
+	// TotalExecTime and MeanExecTime their statistics
+	Statements    int
+	Calls         int64
+	TotalExecTime time.Duration
+	MeanExecTime  time.Duration
+	// SampleQuery is the most expensive of these queries, as normalized by
+	// pg_stat_statements
+	SampleQuery string
+}
+
+// IndexAdvisorReport is the outcome of one analysis
+type IndexAdvisorReport struct {
+	AnalyzedAt time.Time
+	// Statements is the number of tenant scoped sorted queries analyzed
+	Statements      int
+	Recommendations []IndexRecommendation
+}
+
+// IndexAdvisorService recommends the indexes missing for the tenant scoped,
+// sorted list queries, from the statistics of pg_stat_statements
+type IndexAdvisorService struct {
+	store *db.Store
+}
+
+func NewIndexAdvisorService(store *db.Store) *IndexAdvisorService {
+	return &IndexAdvisorService{store: store}
+}
+
+// StartIndexAdvisorJob runs Analyze now and then every interval until ctx is
+// done, logging the recommendations. It does nothing when the interval is 0.
+func (s *IndexAdvisorService) StartIndexAdvisorJob(ctx context.Context, cfg IndexAdvisorConfig) {
+	if cfg.Interval <= 0 {
+		log.Info().Msg("Index advisor job disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			report, err := s.Analyze(ctx, cfg.MinCalls)
+			if errors.Is(err, ErrStatStatementsUnavailable) {
+				log.Info().Msg("Index advisor job stopped, pg_stat_statements is not available")
+				return
+			}
+			if err != nil {
+				log.Err(err).Msg("Index advisor analysis failed")
+			}
+			for _, recommendation := range report.Recommendations {
+				log.Warn().Str("table", recommendation.Table).
+					Int64("calls", recommendation.Calls).
+					Dur("meanExecTime", recommendation.MeanExecTime).
+					Str("statement", recommendation.Statement).
+					Msg("Missing index for tenant scoped queries")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// indexCandidate accumulates the statistics of the queries an index would serve
+type indexCandidate struct {
+	recommendation IndexRecommendation
+	maxTotal       time.Duration
+}
+
+// Analyze reads the tenant scoped sorted queries from pg_stat_statements and
+// recommends a (tenant_id, sort column) index for each table and sort column
+// no existing index starts with. Queries called less than minCalls times in
+// total are ignored.
+func (s *IndexAdvisorService) Analyze(ctx context.Context, minCalls int64) (IndexAdvisorReport, error) {
+	report := IndexAdvisorReport{AnalyzedAt: time.Now().UTC(), Recommendations: []IndexRecommendation{}}
+
+	installed, err := s.store.IsStatStatementsInstalled(ctx)
+	if err != nil {
+		return report, fmt.Errorf("service.Analyze: %w", err)
+	}
+	if !installed {
+		return report, ErrStatStatementsUnavailable
+	}
+
+	columns, err := s.tableColumns(ctx)
+	if err != nil {
+		return report, fmt.Errorf("service.Analyze: %w", err)
+	}
+	indexes, err := s.tableIndexes(ctx)
+	if err != nil {
+		return report, fmt.Errorf("service.Analyze: %w", err)
+	}
+
+	statements, err := s.store.ListTenantSortedStatements(ctx, indexAdvisorMaxStatements)
+	if err != nil {
+		// Querying the view fails when the library is not preloaded
+		return report, fmt.Errorf("service.Analyze: %w: %w", ErrStatStatementsUnavailable, err)
+	}
+
+	candidates := map[string]*indexCandidate{}
+	for _, row := range statements {
+		statement, ok := parseTenantSortedStatement(row.Query)
+		if !ok || !slices.Contains(columns[statement.table], indexAdvisorTenantColumn) {
+			continue
+		}
+		report.Statements++
+		for _, column := range statement.sortColumns {
+			if column == indexAdvisorTenantColumn || !slices.Contains(columns[statement.table], column) {
+				continue
+			}
+			indexColumns := []string{indexAdvisorTenantColumn, column}
+			if hasIndexPrefix(indexes[statement.table], indexColumns) {
+				continue
+			}
+			key := statement.table + "." + column
+			candidate, exists := candidates[key]
+			if !exists {
+				candidate = &indexCandidate{recommendation: IndexRecommendation{
+					Table:     statement.table,
+					Columns:   indexColumns,
+					Statement: createIndexStatement(statement.table, indexColumns),
+				}}
+				candidates[key] = candidate
+			}
+			totalExecTime := msToDuration(row.TotalExecTime)
+			recommendation := &candidate.recommendation
+			recommendation.Statements++
+			recommendation.Calls += row.Calls
+			recommendation.TotalExecTime += totalExecTime
+			recommendation.MeanExecTime = max(recommendation.MeanExecTime, msToDuration(row.MeanExecTime))
+			if totalExecTime >= candidate.maxTotal {
+				candidate.maxTotal = totalExecTime
+				recommendation.SampleQuery = row.Query
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if candidate.recommendation.Calls >= minCalls {
+			report.Recommendations = append(report.Recommendations, candidate.recommendation)
+		}
+	}
+	slices.SortFunc(report.Recommendations, func(a, b IndexRecommendation) int {
+		return cmp.Or(cmp.Compare(b.TotalExecTime, a.TotalExecTime), strings.Compare(a.Statement, b.Statement))
+	})
+
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Int("statements", report.Statements).Int("recommendations", len(report.Recommendations)).Msg("Index advisor analysis done")
+	return report, nil
+}
+
+func (s *IndexAdvisorService) tableColumns(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.store.ListTableColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string][]string{}
+	for _, row := range rows {
+		columns[row.TableName] = append(columns[row.TableName], row.ColumnName)
+	}
+	return columns, nil
+}
+
+// tableIndexes returns the columns of every index, by table
+func (s *IndexAdvisorService) tableIndexes(ctx context.Context) (map[string][][]string, error) {
+	rows, err := s.store.ListTableIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexes := map[string][][]string{}
+	for _, row := range rows {
+		indexes[row.TableName] = append(indexes[row.TableName], parseIndexColumns(row.Definition))
+	}
+	return indexes, nil
+}
+
+func msToDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// hasIndexPrefix reports whether one of the indexes starts with columns
+func hasIndexPrefix(indexes [][]string, columns []string) bool {
+	return slices.ContainsFunc(indexes, func(index []string) bool {
+		return len(index) >= len(columns) && slices.Equal(index[:len(columns)], columns)
+	})
+}
+
+func createIndexStatement(table string, columns []string) string {
+	name := "idx_" + table + "_" + strings.Join(columns, "_")
+	if len(name) > postgresMaxIdentifierLen {
+		name = name[:postgresMaxIdentifierLen]
+	}
+	return fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s);", name, table, strings.Join(columns, ", "))
+}
+
+var (
+	fromTableRegexp    = regexp.MustCompile(`(?is)\bfrom\s+"?([a-z_][a-z0-9_]*)"?(?:\s+(?:as\s+)?([a-z_][a-z0-9_]*))?`)
+	orderByRegexp      = regexp.MustCompile(`(?is)\border\s+by\s+(.*?)(?:\blimit\b|\boffset\b|\bfor\s+update\b|$)`)
+	caseThenRegexp     = regexp.MustCompile(`(?is)\bthen\s+("?[a-z_][a-z0-9_.]*"?)\s+(?:when|else|end)\b`)
+	sortColumnRegexp   = regexp.MustCompile(`(?is)^\s*("?[a-z_][a-z0-9_.]*"?)(?:\s+(?:asc|desc|nulls\s+\w+))*\s*$`)
+	indexColumnsRegexp = regexp.MustCompile(`(?is)\busing\s+\w+\s*\((.*?)\)(?:\s+include\b|\s+where\b|\s*$)`)
+	// Words that can follow the table where an alias would be
+	sqlKeywords = []string{"where", "join", "inner", "left", "right", "full", "cross", "on", "order", "group", "limit", "offset", "union", "having", "for", "natural", "using", "returning"}
+)
+
+// tenantSortedStatement is the part of a query the advisor looks at
+type tenantSortedStatement struct {
+	table       string
+	sortColumns []string
+}
+
+// parseTenantSortedStatement extracts the main table of a query and the
+// columns it sorts that table by, for both plain ORDER BY lists and the
+// CASE WHEN ... THEN column END lists generated for dynamic sorting. It
+// returns false for queries that do not filter that table on tenant_id.
+func parseTenantSortedStatement(query string) (tenantSortedStatement, bool) {
+	from := fromTableRegexp.FindStringSubmatch(query)
+	if from == nil {
+		return tenantSortedStatement{}, false
+	}
+	table := strings.ToLower(from[1])
+	alias := strings.ToLower(from[2])
+	if alias == "" || slices.Contains(sqlKeywords, alias) {
+		alias = table
+	}
+
+	// Unqualified, or qualified with the main table
+	tenantFilter := regexp.MustCompile(`(?is)(?:^|[^a-z0-9_."])(?:` + regexp.QuoteMeta(alias) + `\.)?"?tenant_id"?\s*(?:=|\bin\b)`)
+	if !tenantFilter.MatchString(query) {
+		return tenantSortedStatement{}, false
+	}
+
+	orderBy := orderByRegexp.FindAllStringSubmatch(query, -1)
+	if orderBy == nil {
+		return tenantSortedStatement{}, false
+	}
+	clause := orderBy[len(orderBy)-1][1]
+
+	var expressions []string
+	if cases := caseThenRegexp.FindAllStringSubmatch(clause, -1); cases != nil {
+		for _, match := range cases {
+			expressions = append(expressions, match[1])
+		}
+	} else {
+		for _, part := range strings.Split(clause, ",") {
+			if match := sortColumnRegexp.FindStringSubmatch(part); match != nil {
+				expressions = append(expressions, match[1])
+			}
+		}
+	}
+
+	statement := tenantSortedStatement{table: table}
+	for _, expression := range expressions {
+		column := strings.ToLower(strings.ReplaceAll(expression, `"`, ""))
+		if qualifier, name, qualified := strings.Cut(column, "."); qualified {
+			if qualifier != alias {
+				continue
+			}
+			column = name
+		}
+		if !slices.Contains(statement.sortColumns, column) {
+			statement.sortColumns = append(statement.sortColumns, column)
+		}
+	}
+	return statement, len(statement.sortColumns) > 0
+}
+
+// parseIndexColumns returns the columns of an index definition from
+// pg_indexes, with the expressions kept as written
+func parseIndexColumns(definition string) []string {
+	match := indexColumnsRegexp.FindStringSubmatch(definition)
+	if match == nil {
+		return nil
+	}
+	var columns []string
+	for _, part := range strings.Split(match[1], ",") {
+		fields := strings.Fields(strings.TrimSpace(part))
+		if len(fields) == 0 {
+			continue
+		}
+		columns = append(columns, strings.ToLower(strings.Trim(fields[0], `"`)))
+	}
+	return columns
+}
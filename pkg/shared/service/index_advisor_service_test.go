@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTenantSortedStatement(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		query   string
+		table   string
+		columns []string
+		ok      bool
+	}{
+		{
+			name: "dynamic sort",
+			query: `SELECT id, name, created_at FROM core_client_applications
+WHERE ($1::TEXT IS NULL OR tenant_id = $1)
+ORDER BY
+  CASE WHEN $2::TEXT = $3 AND $4::TEXT = $5 THEN name END ASC,
+  CASE WHEN $2::TEXT = $6 AND $4::TEXT != $7 THEN created_at END DESC
+LIMIT $8 OFFSET $9`,
+			table:   "core_client_applications",
+			columns: []string{"name", "created_at"},
+			ok:      true,
+		},
+		{
+			name: "case with several branches",
+			query: `SELECT * FROM core_tenant_configs WHERE tenant_id = $1 ORDER BY
+  CASE WHEN $2::text = $3 and $4::text = $5 THEN "name" WHEN $2::text = $6 and $4::text = $7 THEN "value" END ASC
+LIMIT $8`,
+			table:   "core_tenant_configs",
+			columns: []string{"name", "value"},
+			ok:      true,
+		},
+		{
+			name:    "plain order by with alias",
+			query:   `SELECT e.id FROM core_tenant_exports e WHERE e.tenant_id = $1 ORDER BY e.created_at DESC, e.id LIMIT $2`,
+			table:   "core_tenant_exports",
+			columns: []string{"created_at", "id"},
+			ok:      true,
+		},
+		{
+			name:  "tenant filter on a joined table",
+			query: `SELECT t.id FROM core_api_tokens t JOIN core_client_applications c ON c.id = t.client_application_id WHERE c.tenant_id = $1 ORDER BY t.created_at`,
+			ok:    false,
+		},
+		{
+			name:  "no tenant filter",
+			query: `SELECT id FROM core_tenants ORDER BY name`,
+			ok:    false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			statement, ok := parseTenantSortedStatement(tc.query)
+			require.Equal(t, tc.ok, ok)
+			if tc.ok {
+				require.Equal(t, tc.table, statement.table)
+				require.Equal(t, tc.columns, statement.sortColumns)
+			}
+		})
+	}
+}
+
+func TestIndexCoverage(t *testing.T) {
+	indexes := [][]string{
+		parseIndexColumns("CREATE INDEX idx_a ON public.core_announcements USING btree (tenant_id, created_at DESC)"),
+		parseIndexColumns(`CREATE UNIQUE INDEX core_tenants_pkey ON public.core_tenants USING btree ("id")`),
+		parseIndexColumns("CREATE INDEX idx_b ON public.core_announcements USING btree (tenant_id) WHERE (deleted_at IS NULL)"),
+	}
+	require.Equal(t, []string{"tenant_id", "created_at"}, indexes[0])
+	require.Equal(t, []string{"id"}, indexes[1])
+	require.Equal(t, []string{"tenant_id"}, indexes[2])
+
+	require.True(t, hasIndexPrefix(indexes, []string{"tenant_id", "created_at"}))
+	require.False(t, hasIndexPrefix(indexes, []string{"tenant_id", "title"}))
+
+	require.Equal(t, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_core_announcements_tenant_id_title ON core_announcements (tenant_id, title);",
+		createIndexStatement("core_announcements", []string{"tenant_id", "title"}))
+}
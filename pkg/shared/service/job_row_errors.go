@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/event"
+	"github.com/google/uuid"
+)
+
+var importErrorsCSVHeader = []string{"line", "email", "error_code", "message"}
+
+// RecordRowErrors keeps the rows rejected by an import with its job. They are
+// deleted with the job once its retention is over.
+func (s *JobService) RecordRowErrors(ctx context.Context, jobID uuid.UUID, rowErrors []event.ImportRowError) error {
+	for _, rowError := range rowErrors {
+		err := s.store.CreateJobRowError(ctx, repository.CreateJobRowErrorParams{
+			JobID:   jobID,
+			Line:    int32(rowError.Line),
+			Email:   rowError.Email,
+			Code:    rowError.Code,
+			Message: rowError.Error,
+		})
+		if err != nil {
+			return fmt.Errorf("service.RecordRowErrors: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListRowErrors returns the rows rejected by an import so far, by line number
+func (s *JobService) ListRowErrors(ctx context.Context, jobID uuid.UUID) ([]event.ImportRowError, error) {
+	rowErrors, err := s.store.ListJobRowErrors(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListRowErrors: %w", err)
+	}
+	result := make([]event.ImportRowError, len(rowErrors))
+	for i, rowError := range rowErrors {
+		result[i] = event.ImportRowError{
+			Line:  int(rowError.Line),
+			Email: rowError.Email,
+			Code:  rowError.Code,
+			Error: rowError.Message,
+		}
+	}
+	return result, nil
+}
+
+// WriteRowErrorsCSV writes the rows rejected by an import, by line number. The
+// file uses the semicolon delimiter of the import files, so it opens with the
+// same settings as the file it reports on.
+func (s *JobService) WriteRowErrorsCSV(ctx context.Context, jobID uuid.UUID, w io.Writer) (int, error) {
+	rowErrors, err := s.store.ListJobRowErrors(ctx, jobID)
+	if err != nil {
+		return 0, fmt.Errorf("service.WriteRowErrorsCSV: %w", err)
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	if err := writer.Write(importErrorsCSVHeader); err != nil {
+		return 0, fmt.Errorf("service.WriteRowErrorsCSV: %w", err)
+	}
+	for _, rowError := range rowErrors {
+		if err := writer.Write([]string{
+			strconv.Itoa(int(rowError.Line)),
+			rowError.Email,
+			rowError.Code,
+			rowError.Message,
+		}); err != nil {
+			return 0, fmt.Errorf("service.WriteRowErrorsCSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return 0, fmt.Errorf("service.WriteRowErrorsCSV: %w", err)
+	}
+	return len(rowErrors), nil
+}
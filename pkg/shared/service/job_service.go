@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/event"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Job kinds and statuses
+const (
+	JobKindUserImport         = "user_import"
+	JobKindTenantSandboxReset = "tenant_sandbox_reset"
+	JobKindTenantDataExport   = "tenant_data_export"
+
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+const (
+	DefaultJobRetention       = 7 * 24 * time.Hour
+	DefaultJobEventsWait      = 25 * time.Second
+	MaxJobEventsWait          = 60 * time.Second
+	jobCleanupInterval        = time.Hour
+	jobEventsPollInterval     = 500 * time.Millisecond
+	jobEventsMaxBatchSize     = 100
+	jobEventsDefaultBatchSize = 50
+)
+
+// JobConfig configures the retention of jobs and their events.
+//
+// Environment:
+//   - JOB_RETENTION: how long jobs and their events are kept (default 168h, 0 keeps them forever)
+type JobConfig struct {
+	Retention time.Duration
+}
+
+func JobConfigFromEnv() JobConfig {
+	cfg := JobConfig{Retention: DefaultJobRetention}
+	if v := os.Getenv("JOB_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Retention = d
+		} else {
+			log.Warn().Str("JOB_RETENTION", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// JobService persists the progress events of long running operations, so
+// clients can follow them over SSE while the operation runs or read them back
+// later with long polling
+type JobService struct {
+	store *db.Store
+}
+
+func NewJobService(store *db.Store) *JobService {
+	return &JobService{store: store}
+}
+
+// JobEvents is a batch of events read after a cursor
+type JobEvents struct {
+	Events []repository.CoreJobEvent
+	// Cursor is the id of the last returned event, or the requested cursor
+	// when there is none, to pass as since on the next call
+	Cursor int64
+	Status string
+	// Done reports the job is over and every event has been returned
+	Done bool
+}
+
+// StartJob records a running job
+func (s *JobService) StartJob(ctx context.Context, tenantID, kind, createdBy string) (repository.CoreJob, error) {
+	job, err := s.store.CreateJob(ctx, repository.CreateJobParams{
+		TenantID:  tenantID,
+		Kind:      kind,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return repository.CoreJob{}, fmt.Errorf("service.StartJob: %w", err)
+	}
+	return job, nil
+}
+
+// RecordEvent appends an event to a job and returns its cursor
+func (s *JobService) RecordEvent(ctx context.Context, jobID uuid.UUID, progressEvent event.ProgressEvent) (int64, error) {
+	var data []byte
+	if progressEvent.Data != nil {
+		var err error
+		if data, err = json.Marshal(progressEvent.Data); err != nil {
+			return 0, fmt.Errorf("service.RecordEvent: %w", err)
+		}
+	}
+	id, err := s.store.CreateJobEvent(ctx, repository.CreateJobEventParams{
+		JobID:       jobID,
+		EventType:   progressEvent.EventType,
+		Message:     progressEvent.Message,
+		Progress:    int32(progressEvent.Progress),
+		DataType:    progressEvent.DataType,
+		DataVersion: int32(progressEvent.DataVersion),
+		Data:        data,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("service.RecordEvent: %w", err)
+	}
+	return id, nil
+}
+
+// FinishJob marks a job completed or failed
+func (s *JobService) FinishJob(ctx context.Context, jobID uuid.UUID, status string) error {
+	if err := s.store.CompleteJob(ctx, repository.CompleteJobParams{ID: jobID, Status: status}); err != nil {
+		return fmt.Errorf("service.FinishJob: %w", err)
+	}
+	return nil
+}
+
+// GetJob returns a job of the tenant
+func (s *JobService) GetJob(ctx context.Context, tenantID string, id uuid.UUID) (repository.CoreJob, error) {
+	return s.store.GetJob(ctx, repository.GetJobParams{ID: id, TenantID: tenantID})
+}
+
+// GetLatestEvent returns the last event recorded by a job, pgx.ErrNoRows when
+// there is none yet
+func (s *JobService) GetLatestEvent(ctx context.Context, jobID uuid.UUID) (repository.CoreJobEvent, error) {
+	return s.store.GetLatestJobEvent(ctx, jobID)
+}
+
+// WaitForEvents returns the events of the job after the since cursor. When
+// there are none yet it waits up to wait for new ones, checking the database
+// so events recorded by another instance are seen too. It returns early when
+// the job ends or ctx is cancelled.
+func (s *JobService) WaitForEvents(ctx context.Context, job repository.CoreJob, since int64, limit int32, wait time.Duration) (JobEvents, error) {
+	if limit <= 0 || limit > jobEventsMaxBatchSize {
+		limit = jobEventsDefaultBatchSize
+	}
+	wait = min(max(wait, 0), MaxJobEventsWait)
+	deadline := time.Now().Add(wait)
+
+	for {
+		// Read the status before the events: a job seen as over has recorded
+		// every event it will ever have
+		current, err := s.store.GetJob(ctx, repository.GetJobParams{ID: job.ID, TenantID: job.TenantID})
+		if err != nil {
+			return JobEvents{}, fmt.Errorf("service.WaitForEvents: %w", err)
+		}
+		events, err := s.store.ListJobEvents(ctx, repository.ListJobEventsParams{
+			JobID: job.ID,
+			Limit: limit,
+			Since: since,
+		})
+		if err != nil {
+			return JobEvents{}, fmt.Errorf("service.WaitForEvents: %w", err)
+		}
+
+		over := current.Status != JobStatusRunning
+		if len(events) > 0 || over || !time.Now().Before(deadline) {
+			result := JobEvents{
+				Events: events,
+				Cursor: since,
+				Status: current.Status,
+				Done:   over && len(events) < int(limit),
+			}
+			if len(events) > 0 {
+				result.Cursor = events[len(events)-1].ID
+			}
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return JobEvents{Events: events, Cursor: since, Status: current.Status}, nil
+		case <-time.After(min(jobEventsPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// StartJobCleanup deletes the jobs older than the retention, with their events
+func (s *JobService) StartJobCleanup(ctx context.Context, cfg JobConfig) {
+	if cfg.Retention <= 0 {
+		log.Info().Msg("Job cleanup disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(jobCleanupInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.DeleteExpiredJobs(ctx, cfg.Retention); err != nil {
+				log.Err(err).Msg("Job cleanup failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// DeleteExpiredJobs deletes the jobs created more than retention ago
+func (s *JobService) DeleteExpiredJobs(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := s.store.DeleteJobsCreatedBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("service.DeleteExpiredJobs: %w", err)
+	}
+	if deleted > 0 {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Info().Int64("jobs", deleted).Msg("Deleted expired jobs")
+	}
+	return deleted, nil
+}
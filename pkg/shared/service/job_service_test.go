@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/event"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobEventsLongPolling(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewJobService(store)
+	ctx := context.Background()
+	tenantID := commontestutils.RandomString(10)
+
+	job, err := service.StartJob(ctx, tenantID, JobKindUserImport, commontestutils.RandomString(12))
+	require.NoError(t, err)
+	require.Equal(t, JobStatusRunning, job.Status)
+
+	// Another tenant does not see the job
+	_, err = service.GetJob(ctx, commontestutils.RandomString(10), job.ID)
+	require.Error(t, err)
+
+	// Nothing recorded yet: the wait expires with the cursor unchanged
+	result, err := service.WaitForEvents(ctx, job, 0, 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, result.Events)
+	require.Equal(t, int64(0), result.Cursor)
+	require.False(t, result.Done)
+
+	for i := 1; i <= 3; i++ {
+		_, err = service.RecordEvent(ctx, job.ID, event.NewProgressEventWithData("INFO", "processing", i*10, event.UserImportProgress{Line: i}))
+		require.NoError(t, err)
+	}
+
+	// Batches follow the cursor
+	first, err := service.WaitForEvents(ctx, job, 0, 2, time.Second)
+	require.NoError(t, err)
+	require.Len(t, first.Events, 2)
+	require.Equal(t, first.Events[1].ID, first.Cursor)
+	require.Equal(t, event.UserImportProgress{}.DataType(), first.Events[0].DataType)
+	var progress event.UserImportProgress
+	require.NoError(t, json.Unmarshal(first.Events[0].Data, &progress))
+	require.Equal(t, 1, progress.Line)
+
+	second, err := service.WaitForEvents(ctx, job, first.Cursor, 2, time.Second)
+	require.NoError(t, err)
+	require.Len(t, second.Events, 1)
+	require.Equal(t, int32(30), second.Events[0].Progress)
+	require.False(t, second.Done)
+
+	// A waiting call returns as soon as an event is recorded
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = service.RecordEvent(ctx, job.ID, event.NewProgressEvent("INFO", "done", 100))
+		_ = service.FinishJob(ctx, job.ID, JobStatusCompleted)
+	}()
+	last, err := service.WaitForEvents(ctx, job, second.Cursor, 10, 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, last.Events, 1)
+	require.Equal(t, int32(100), last.Events[0].Progress)
+
+	// Once the job is over the last batch reports done without waiting
+	final, err := service.WaitForEvents(ctx, job, last.Cursor, 10, 5*time.Second)
+	require.NoError(t, err)
+	require.Empty(t, final.Events)
+	require.Equal(t, JobStatusCompleted, final.Status)
+	require.True(t, final.Done)
+}
+
+func TestImportRowErrorsReport(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewJobService(store)
+	ctx := context.Background()
+
+	job, err := service.StartJob(ctx, commontestutils.RandomString(10), JobKindUserImport, commontestutils.RandomString(12))
+	require.NoError(t, err)
+
+	require.NoError(t, service.RecordRowErrors(ctx, job.ID, []event.ImportRowError{
+		{Line: 5, Email: "taken@example.com", Code: event.ImportErrorEmailExists, Error: "email already exists"},
+		{Line: 2, Code: event.ImportErrorInvalidRow, Error: "invalid record format; expected 4 fields"},
+	}))
+	require.NoError(t, service.RecordRowErrors(ctx, job.ID, nil))
+
+	var report strings.Builder
+	rows, err := service.WriteRowErrorsCSV(ctx, job.ID, &report)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows)
+	require.Equal(t, "line;email;error_code;message\n"+
+		"2;;invalid_row;\"invalid record format; expected 4 fields\"\n"+
+		"5;taken@example.com;email_exists;email already exists\n", report.String())
+}
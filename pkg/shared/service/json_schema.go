@@ -0,0 +1,178 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema the tenant settings are described
+// with: type, enum, bounds of numbers, strings and arrays, pattern, items,
+// properties, required and additionalProperties
+type jsonSchema struct {
+	Type                 string                 `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	MaxItems             *int                   `json:"maxItems"`
+	Items                *jsonSchema            `json:"items"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+
+	pattern *regexp.Regexp
+}
+
+var jsonSchemaTypes = []string{"", "string", "number", "integer", "boolean", "object", "array"}
+
+// parseJSONSchema reads a schema, refusing the keywords of the subset with an
+// invalid value
+func parseJSONSchema(raw string) (*jsonSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, err
+	}
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *jsonSchema) compile() error {
+	if !slices.Contains(jsonSchemaTypes, s.Type) {
+		return fmt.Errorf("unsupported type %q", s.Type)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = pattern
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(); err != nil {
+			return err
+		}
+	}
+	for _, property := range s.Properties {
+		if err := property.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate returns the messages of the violations of value, decoded from
+// JSON, prefixed by their path
+func (s *jsonSchema) validate(path string, value interface{}) []string {
+	if msg := s.checkType(value); msg != "" {
+		return []string{path + " " + msg}
+	}
+	violations := []string{}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e interface{}) bool { return jsonEqual(e, value) }) {
+		violations = append(violations, path+" must be one of "+jsonList(s.Enum))
+	}
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			violations = append(violations, fmt.Sprintf("%s must be at least %v", path, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			violations = append(violations, fmt.Sprintf("%s must be at most %v", path, *s.Maximum))
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			violations = append(violations, fmt.Sprintf("%s must have at least %d characters", path, *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			violations = append(violations, fmt.Sprintf("%s must have at most %d characters", path, *s.MaxLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			violations = append(violations, fmt.Sprintf("%s must match %s", path, s.Pattern))
+		}
+	case []interface{}:
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			violations = append(violations, fmt.Sprintf("%s must have at most %d items", path, *s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				violations = append(violations, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				violations = append(violations, path+"."+name+" is required")
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			switch {
+			case ok:
+				violations = append(violations, property.validate(path+"."+name, v[name])...)
+			case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+				violations = append(violations, path+"."+name+" is not allowed")
+			}
+		}
+	}
+	return violations
+}
+
+func (s *jsonSchema) checkType(value interface{}) string {
+	switch s.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case "integer":
+		if v, ok := value.(float64); !ok || v != math.Trunc(v) {
+			return "must be an integer"
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return "must be an object"
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return "must be an array"
+		}
+	}
+	return ""
+}
+
+func jsonEqual(a, b interface{}) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
+
+func jsonList(values []interface{}) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		raw, _ := json.Marshal(value)
+		items[i] = string(raw)
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := parseJSONSchema(`{
+		"type": "object",
+		"required": ["name"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"size": {"type": "integer", "minimum": 1, "maximum": 10},
+			"mode": {"enum": ["light", "dark"]},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+		}
+	}`)
+	require.NoError(t, err)
+
+	require.Empty(t, schema.validate("v", map[string]interface{}{"name": "abc", "size": 3.0, "mode": "dark"}))
+
+	violations := schema.validate("v", map[string]interface{}{
+		"name":  "A",
+		"size":  2.5,
+		"mode":  "blue",
+		"tags":  []interface{}{"a", 1.0, "c"},
+		"extra": true,
+	})
+	require.ElementsMatch(t, []string{
+		"v.extra is not allowed",
+		`v.mode must be one of "light", "dark"`,
+		"v.name must have at least 2 characters",
+		"v.name must match ^[a-z]+$",
+		"v.size must be an integer",
+		"v.tags must have at most 2 items",
+		"v.tags[1] must be a string",
+	}, violations)
+
+	require.Equal(t, []string{"v.name is required"}, schema.validate("v", map[string]interface{}{}))
+	require.Equal(t, []string{"v must be an object"}, schema.validate("v", "text"))
+}
+
+func TestParseJSONSchemaInvalid(t *testing.T) {
+	_, err := parseJSONSchema(`{"type": "date"}`)
+	require.Error(t, err)
+	_, err = parseJSONSchema(`{"type": "string", "pattern": "("}`)
+	require.Error(t, err)
+	_, err = parseJSONSchema(`{"properties": {"a": {"type": "map"}}}`)
+	require.Error(t, err)
+	_, err = parseJSONSchema(`not json`)
+	require.Error(t, err)
+}
@@ -27,7 +27,7 @@ func NewKratosTenantService(store *db.Store, authProvider auth.AuthProvider) *Kr
 // AssignUserToTenant assigns a user to a tenant by updating their Kratos metadata
 func (kts *KratosTenantService) AssignUserToTenant(ctx context.Context, userID string, subdomain string) error {
 	// Get tenant from database
-	tenant, err := kts.store.GetTenantBySubdomain(ctx, subdomain)
+	tenant, err := kts.store.GetTenantBySubdomainOrAlias(ctx, subdomain)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant: %w", err)
 	}
@@ -92,7 +92,7 @@ func (kts *KratosTenantService) RemoveUserFromTenant(ctx context.Context, userID
 func (kts *KratosTenantService) CreateUserWithTenant(ctx context.Context, email string, password string, subdomain string, roles []string) (*auth.UserRecord, error) {
 	logger := util.GetLoggerFromCtx(ctx)
 	// Get tenant from database
-	tenant, err := kts.store.GetTenantBySubdomain(ctx, subdomain)
+	tenant, err := kts.store.GetTenantBySubdomainOrAlias(ctx, subdomain)
 	if err != nil {
 		logger.Err(err).Str("subdomain", subdomain).Msg("Failed to get tenant")
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
@@ -136,7 +136,7 @@ func (kts *KratosTenantService) CreateUserWithTenant(ctx context.Context, email
 func (kts *KratosTenantService) ListTenantUsers(ctx context.Context, subdomain string) ([]*auth.UserRecord, error) {
 	logger := util.GetLoggerFromCtx(ctx)
 	// Get tenant from database
-	tenant, err := kts.store.GetTenantBySubdomain(ctx, subdomain)
+	tenant, err := kts.store.GetTenantBySubdomainOrAlias(ctx, subdomain)
 	if err != nil {
 		logger.Err(err).Str("subdomain", subdomain).Msg("Failed to get tenant")
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
@@ -164,7 +164,7 @@ func (kts *KratosTenantService) ValidateUserTenantAccess(ctx context.Context, us
 	}
 
 	// Get requested tenant from database
-	tenant, err := kts.store.GetTenantBySubdomain(ctx, subdomain)
+	tenant, err := kts.store.GetTenantBySubdomainOrAlias(ctx, subdomain)
 	if err != nil {
 		logger.Err(err).Str("subdomain", subdomain).Msg("Failed to get tenant")
 		return false, fmt.Errorf("failed to get tenant: %w", err)
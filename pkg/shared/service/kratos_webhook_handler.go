@@ -143,6 +143,14 @@ func (kwh *KratosWebhookHandler) HandleLoginWebhook(c *gin.Context) {
 		logger.Warn().
 			Str("user_id", payload.Identity.ID).
 			Msg("User logged in without tenant assignment")
+	} else {
+		_ = recordUserActivity(c.Request.Context(), kwh.tenantService.store, UserActivity{
+			TenantID:  tenantMetadata.TenantID,
+			UserID:    payload.Identity.ID,
+			Category:  ActivityCategoryLogin,
+			EventType: "logged_in",
+			Data:      map[string]interface{}{"flow_id": payload.Flow.ID},
+		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
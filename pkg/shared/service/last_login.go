@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// lastLoginClaimInterval spares the database the requests of a session
+// already recorded. A session claimed again after it only costs an update
+// matching no row.
+const lastLoginClaimInterval = 12 * time.Hour
+
+// UserListFilter narrows the users listed by ListUsers and ListAllUsers
+type UserListFilter struct {
+	// Search matches words of the email, name and profile, or an exact
+	// email, see UserSearch
+	Search pgtype.Text
+	// InactiveSince keeps the users created before it and without a login
+	// since, the stale accounts
+	InactiveSince pgtype.Timestamptz
+	// Role keeps the users holding the role, in the tenant for the tenant
+	// lists and globally otherwise
+	Role pgtype.Text
+	// Labels keeps the users carrying every one of the labels. Labels are set
+	// per tenant, so only the tenant lists apply it.
+	Labels []string
+	// Disabled and EmailVerified match the state of the account at the auth
+	// provider, as mirrored in core_user_account_states
+	Disabled      pgtype.Bool
+	EmailVerified pgtype.Bool
+	// CreatedFrom and CreatedTo bound the creation date, both inclusive
+	CreatedFrom pgtype.Timestamptz
+	CreatedTo   pgtype.Timestamptz
+}
+
+// LastLoginService records the last login of the users. A login is the first
+// verified request of a provider session, so it is recorded whatever the
+// flow the session came from.
+type LastLoginService struct {
+	store  *db.Store
+	claims LastUsedCache
+}
+
+func NewLastLoginService(store *db.Store, claims LastUsedCache) *LastLoginService {
+	return &LastLoginService{store: store, claims: claims}
+}
+
+// RecordLogin records the request as the login of the user in the tenant of
+// the request, unless its session is already recorded. Requests without a
+// session and impersonated requests are not logins of the user. The write
+// does not delay the request.
+func (s *LastLoginService) RecordLogin(c *gin.Context, user *auth.AuthenticatedUser) {
+	if user == nil || user.SessionID == "" || c.GetString(auth.AUTH_IMPERSONATOR_ID) != "" {
+		return
+	}
+	logger := util.GetLoggerFromCtx(c)
+	tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+	claimed, err := s.claims.Claim(c.Request.Context(), "login:"+user.UserID+":"+tenantID+":"+user.SessionID, lastLoginClaimInterval)
+	if err != nil {
+		// The upsert ignores a session already recorded, the cache only
+		// spares the database
+		logger.Warn().Err(err).Msg("Failed to claim the last login write")
+	} else if !claimed {
+		return
+	}
+
+	params := repository.RecordUserLoginParams{
+		UserID:      user.UserID,
+		TenantID:    tenantID,
+		SessionID:   user.SessionID,
+		LastLoginAt: time.Now(),
+		LastLoginIp: pgtype.Text{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+	}
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		if _, err := s.store.RecordUserLogin(ctx, params); err != nil {
+			logger.Err(err).Str("user_id", params.UserID).Str("tenant_id", tenantID).Msg("Failed to record last login")
+		}
+		// A disabled account cannot sign in, so one that does is enabled
+		recordUserAccountState(ctx, s.store, params.UserID, pgtype.Bool{Bool: false, Valid: true}, pgtype.Bool{})
+	}()
+}
+
+// setLastLogins fills the last login of the users, in the tenant or, with a
+// null tenant, in any tenant
+func setLastLogins(ctx context.Context, store *db.Store, users []core.User, tenantID pgtype.Text) error {
+	if len(users) == 0 {
+		return nil
+	}
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.Id
+	}
+	logins, err := store.ListUserLastLoginDetails(ctx, repository.ListUserLastLoginDetailsParams{
+		UserIds:  userIDs,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.setLastLogins: %w", err)
+	}
+	byUser := make(map[string]repository.ListUserLastLoginDetailsRow, len(logins))
+	for _, login := range logins {
+		byUser[login.UserID] = login
+	}
+	for i := range users {
+		login, ok := byUser[users[i].Id]
+		if !ok {
+			continue
+		}
+		users[i].LastLoginAt = &login.LastLoginAt
+		users[i].LastLoginIp = util.FromNullableText(login.LastLoginIp)
+	}
+	return nil
+}
+
+// UserSearch returns the search of the q parameter of the user lists, null
+// without one
+func UserSearch(q *string) pgtype.Text {
+	if q == nil || strings.TrimSpace(*q) == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: strings.TrimSpace(*q), Valid: true}
+}
+
+// InactiveSince returns the cutoff of the stale accounts filter, days before
+// now, and a null cutoff without the filter
+func InactiveSince(days *int32) pgtype.Timestamptz {
+	if days == nil || *days <= 0 {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, -int(*days)), Valid: true}
+}
+
+// UserRoleFilter returns the role filter of the user lists, null without one.
+// The binding of the query does not check the enum, so unknown roles are
+// refused here.
+func UserRoleFilter(role *core.Role) (pgtype.Text, error) {
+	if role == nil || *role == "" {
+		return pgtype.Text{}, nil
+	}
+	switch *role {
+	case core.ADMIN, core.CUSTOMERADMIN, core.SUPERADMIN, core.USER:
+		return pgtype.Text{String: string(*role), Valid: true}, nil
+	}
+	return pgtype.Text{}, fmt.Errorf("unknown role %s", *role)
+}
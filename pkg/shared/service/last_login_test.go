@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type countingLastUsedCache struct {
+	keys []string
+}
+
+func (c *countingLastUsedCache) Claim(_ context.Context, key string, _ time.Duration) (bool, error) {
+	c.keys = append(c.keys, key)
+	// Nothing is written in the test, every session is already recorded
+	return false, nil
+}
+
+func TestRecordLoginSkipsNonLogins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := &countingLastUsedCache{}
+	service := NewLastLoginService(nil, claims)
+	newContext := func() *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		c.Set(auth.AUTH_TENANT_ID_KEY, "t1")
+		return c
+	}
+
+	// No provider session
+	service.RecordLogin(newContext(), &auth.AuthenticatedUser{UserID: "u1"})
+	// A SUPER_ADMIN acting as the user
+	c := newContext()
+	c.Set(auth.AUTH_IMPERSONATOR_ID, "admin")
+	service.RecordLogin(c, &auth.AuthenticatedUser{UserID: "u1", SessionID: "s1"})
+	require.Empty(t, claims.keys)
+
+	service.RecordLogin(newContext(), &auth.AuthenticatedUser{UserID: "u1", SessionID: "s1"})
+	require.Equal(t, []string{"login:u1:t1:s1"}, claims.keys)
+}
+
+func TestInactiveSince(t *testing.T) {
+	require.False(t, InactiveSince(nil).Valid)
+	zero := int32(0)
+	require.False(t, InactiveSince(&zero).Valid)
+
+	days := int32(30)
+	cutoff := InactiveSince(&days)
+	require.True(t, cutoff.Valid)
+	require.WithinDuration(t, time.Now().AddDate(0, 0, -30), cutoff.Time, time.Minute)
+}
+
+func TestUserSearch(t *testing.T) {
+	require.False(t, UserSearch(nil).Valid)
+	blank := "  "
+	require.False(t, UserSearch(&blank).Valid)
+
+	q := " Jane Doe "
+	search := UserSearch(&q)
+	require.True(t, search.Valid)
+	require.Equal(t, "Jane Doe", search.String)
+}
+
+func TestUserRoleFilter(t *testing.T) {
+	role, err := UserRoleFilter(nil)
+	require.NoError(t, err)
+	require.False(t, role.Valid)
+
+	admin := core.CUSTOMERADMIN
+	role, err = UserRoleFilter(&admin)
+	require.NoError(t, err)
+	require.Equal(t, "CUSTOMER_ADMIN", role.String)
+
+	unknown := core.Role("OWNER")
+	_, err = UserRoleFilter(&unknown)
+	require.Error(t, err)
+}
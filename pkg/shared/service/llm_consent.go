@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrLLMRegionNotAllowed is returned by CheckRegion for a provider region
+	// the consent of the execution excludes
+	ErrLLMRegionNotAllowed = errors.New("provider region not allowed by the LLM data consent")
+	// ErrInvalidLLMConsentPolicy is returned for an empty region name
+	ErrInvalidLLMConsentPolicy = errors.New("allowed regions must not be empty names")
+)
+
+// LLMRedacted replaces the inputs and outputs an execution record may not keep
+const LLMRedacted = "[redacted]"
+
+// LLMConsent says what may be done with the data of a prompt execution.
+// AllowedRegions nil allows every provider region.
+type LLMConsent struct {
+	StoreInputs    bool
+	StoreOutputs   bool
+	Analytics      bool
+	AllowedRegions []string
+}
+
+// DefaultLLMConsent applies to the tenants without a consent policy
+var DefaultLLMConsent = LLMConsent{StoreInputs: true, StoreOutputs: true, Analytics: true}
+
+// PromptLLMConsent are the consent flags of a prompt. A nil field leaves the
+// choice of the tenant.
+type PromptLLMConsent struct {
+	StoreInputs    *bool
+	StoreOutputs   *bool
+	Analytics      *bool
+	AllowedRegions *[]string
+}
+
+// Restrict narrows the consent with the flags of a prompt. A prompt can only
+// withdraw what its tenant allows, so the most restrictive choice wins.
+func (c LLMConsent) Restrict(prompt PromptLLMConsent) LLMConsent {
+	result := LLMConsent{
+		StoreInputs:    c.StoreInputs && (prompt.StoreInputs == nil || *prompt.StoreInputs),
+		StoreOutputs:   c.StoreOutputs && (prompt.StoreOutputs == nil || *prompt.StoreOutputs),
+		Analytics:      c.Analytics && (prompt.Analytics == nil || *prompt.Analytics),
+		AllowedRegions: slices.Clone(c.AllowedRegions),
+	}
+	if prompt.AllowedRegions != nil {
+		regions := normalizeLLMRegions(*prompt.AllowedRegions)
+		if result.AllowedRegions == nil {
+			result.AllowedRegions = regions
+		} else {
+			result.AllowedRegions = slices.DeleteFunc(result.AllowedRegions, func(region string) bool {
+				return !slices.Contains(regions, region)
+			})
+		}
+	}
+	return result
+}
+
+// AllowsRegion reports whether the data may be sent to a provider region
+func (c LLMConsent) AllowsRegion(region string) bool {
+	return c.AllowedRegions == nil || slices.Contains(c.AllowedRegions, strings.ToLower(strings.TrimSpace(region)))
+}
+
+// CheckRegion returns ErrLLMRegionNotAllowed when the data may not be sent to
+// the provider region. Executions call it before reaching the provider.
+func (c LLMConsent) CheckRegion(region string) error {
+	if !c.AllowsRegion(region) {
+		return fmt.Errorf("%w: %s", ErrLLMRegionNotAllowed, region)
+	}
+	return nil
+}
+
+// Persist reports whether an execution record is worth storing at all. It is
+// not when neither its input nor its output may be kept.
+func (c LLMConsent) Persist() bool {
+	return c.StoreInputs || c.StoreOutputs
+}
+
+// Metadata describes the consent applied to an execution, to be kept in the
+// metadata of its record
+func (c LLMConsent) Metadata() map[string]interface{} {
+	consent := map[string]interface{}{
+		"store_inputs":  c.StoreInputs,
+		"store_outputs": c.StoreOutputs,
+		"analytics":     c.Analytics,
+	}
+	if c.AllowedRegions != nil {
+		consent["allowed_regions"] = c.AllowedRegions
+	}
+	return map[string]interface{}{"llm_consent": consent}
+}
+
+// LLMExecutionRecord is the part of a prompt execution the consent governs
+type LLMExecutionRecord struct {
+	Input    string
+	Output   string
+	Metadata map[string]interface{}
+}
+
+// Apply masks the input and output the record may not keep and adds the
+// consent to its metadata
+func (c LLMConsent) Apply(record LLMExecutionRecord) LLMExecutionRecord {
+	if !c.StoreInputs && record.Input != "" {
+		record.Input = LLMRedacted
+	}
+	if !c.StoreOutputs && record.Output != "" {
+		record.Output = LLMRedacted
+	}
+	metadata := make(map[string]interface{}, len(record.Metadata)+1)
+	for key, value := range record.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range c.Metadata() {
+		metadata[key] = value
+	}
+	record.Metadata = metadata
+	return record
+}
+
+// LLMConsentPolicyInput is the consent policy of a tenant
+type LLMConsentPolicyInput struct {
+	StoreInputs    bool
+	StoreOutputs   bool
+	AllowAnalytics bool
+	// AllowedRegions nil allows every region, empty allows none
+	AllowedRegions *[]string
+}
+
+// LLMConsentService keeps the LLM data consent of the tenants. Modules running
+// prompts resolve the consent of each execution through ResolveConsent.
+type LLMConsentService struct {
+	store *db.Store
+}
+
+func NewLLMConsentService(store *db.Store) *LLMConsentService {
+	return &LLMConsentService{store: store}
+}
+
+// GetPolicy returns the consent policy of a tenant, pgx.ErrNoRows when it has
+// none
+func (s *LLMConsentService) GetPolicy(ctx context.Context, tenantID string) (repository.CoreLlmConsentPolicy, error) {
+	return s.store.GetLLMConsentPolicy(ctx, tenantID)
+}
+
+// SetPolicy creates or replaces the consent policy of a tenant. It applies to
+// the executions started afterwards.
+func (s *LLMConsentService) SetPolicy(ctx context.Context, tenantID string, input LLMConsentPolicyInput, updatedBy string) (repository.CoreLlmConsentPolicy, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	var regions []string
+	if input.AllowedRegions != nil {
+		for _, region := range *input.AllowedRegions {
+			if strings.TrimSpace(region) == "" {
+				return repository.CoreLlmConsentPolicy{}, ErrInvalidLLMConsentPolicy
+			}
+		}
+		regions = normalizeLLMRegions(*input.AllowedRegions)
+	}
+
+	policy, err := s.store.UpsertLLMConsentPolicy(ctx, repository.UpsertLLMConsentPolicyParams{
+		TenantID:       tenantID,
+		StoreInputs:    input.StoreInputs,
+		StoreOutputs:   input.StoreOutputs,
+		AllowAnalytics: input.AllowAnalytics,
+		AllowedRegions: regions,
+		UpdatedBy:      updatedBy,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to set LLM consent policy")
+		return repository.CoreLlmConsentPolicy{}, fmt.Errorf("service.SetLLMConsentPolicy: %w", err)
+	}
+	logger.Info().Str("tenantID", tenantID).Bool("storeInputs", input.StoreInputs).Bool("storeOutputs", input.StoreOutputs).
+		Bool("allowAnalytics", input.AllowAnalytics).Strs("allowedRegions", regions).Msg("LLM consent policy set")
+	return policy, nil
+}
+
+// DeletePolicy removes the consent policy of a tenant, pgx.ErrNoRows when it
+// has none
+func (s *LLMConsentService) DeletePolicy(ctx context.Context, tenantID string) error {
+	deleted, err := s.store.DeleteLLMConsentPolicy(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteLLMConsentPolicy: %w", err)
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ResolveConsent returns the consent of an execution of a prompt in a tenant
+func (s *LLMConsentService) ResolveConsent(ctx context.Context, tenantID string, prompt PromptLLMConsent) (LLMConsent, error) {
+	policy, err := s.store.GetLLMConsentPolicy(ctx, tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultLLMConsent.Restrict(prompt), nil
+	}
+	if err != nil {
+		return LLMConsent{}, fmt.Errorf("service.ResolveConsent: %w", err)
+	}
+	return LLMConsentFromPolicy(policy).Restrict(prompt), nil
+}
+
+// LLMConsentFromPolicy converts a stored policy
+func LLMConsentFromPolicy(policy repository.CoreLlmConsentPolicy) LLMConsent {
+	return LLMConsent{
+		StoreInputs:    policy.StoreInputs,
+		StoreOutputs:   policy.StoreOutputs,
+		Analytics:      policy.AllowAnalytics,
+		AllowedRegions: policy.AllowedRegions,
+	}
+}
+
+// normalizeLLMRegions lowercases the region names and drops duplicates. It
+// never returns nil, an empty list allows no region.
+func normalizeLLMRegions(regions []string) []string {
+	result := make([]string, 0, len(regions))
+	for _, region := range regions {
+		region = strings.ToLower(strings.TrimSpace(region))
+		if region != "" && !slices.Contains(result, region) {
+			result = append(result, region)
+		}
+	}
+	return result
+}
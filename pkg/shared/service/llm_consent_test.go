@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMConsentRestrict(t *testing.T) {
+	no, yes := false, true
+
+	// a prompt withdraws what the tenant allows, never grants more
+	consent := DefaultLLMConsent.Restrict(PromptLLMConsent{StoreOutputs: &no})
+	require.True(t, consent.StoreInputs)
+	require.False(t, consent.StoreOutputs)
+	require.True(t, consent.Analytics)
+
+	tenant := LLMConsent{StoreInputs: false, StoreOutputs: true, Analytics: false, AllowedRegions: []string{"eu", "us"}}
+	consent = tenant.Restrict(PromptLLMConsent{StoreInputs: &yes, Analytics: &yes})
+	require.False(t, consent.StoreInputs)
+	require.False(t, consent.Analytics)
+	require.Equal(t, []string{"eu", "us"}, consent.AllowedRegions)
+
+	consent = tenant.Restrict(PromptLLMConsent{AllowedRegions: &[]string{"EU ", "apac"}})
+	require.Equal(t, []string{"eu"}, consent.AllowedRegions)
+	require.Equal(t, []string{"eu", "us"}, tenant.AllowedRegions)
+
+	consent = DefaultLLMConsent.Restrict(PromptLLMConsent{AllowedRegions: &[]string{"us"}})
+	require.Equal(t, []string{"us"}, consent.AllowedRegions)
+}
+
+func TestLLMConsentRegions(t *testing.T) {
+	require.True(t, DefaultLLMConsent.AllowsRegion("ap-southeast"))
+	require.NoError(t, DefaultLLMConsent.CheckRegion("ap-southeast"))
+
+	consent := LLMConsentFromPolicy(repository.CoreLlmConsentPolicy{AllowedRegions: []string{"eu"}})
+	require.True(t, consent.AllowsRegion(" EU"))
+	require.ErrorIs(t, consent.CheckRegion("us"), ErrLLMRegionNotAllowed)
+
+	// an empty list is not "any region"
+	none := LLMConsentFromPolicy(repository.CoreLlmConsentPolicy{AllowedRegions: []string{}})
+	require.False(t, none.AllowsRegion("eu"))
+}
+
+func TestLLMConsentApply(t *testing.T) {
+	consent := LLMConsent{StoreInputs: false, StoreOutputs: true, Analytics: false}
+	require.True(t, consent.Persist())
+
+	record := consent.Apply(LLMExecutionRecord{
+		Input:    "my salary is 100k",
+		Output:   "noted",
+		Metadata: map[string]interface{}{"model": "gpt"},
+	})
+	require.Equal(t, LLMRedacted, record.Input)
+	require.Equal(t, "noted", record.Output)
+	require.Equal(t, "gpt", record.Metadata["model"])
+	require.Equal(t, map[string]interface{}{
+		"store_inputs":  false,
+		"store_outputs": true,
+		"analytics":     false,
+	}, record.Metadata["llm_consent"])
+
+	require.False(t, LLMConsent{Analytics: true}.Persist())
+}
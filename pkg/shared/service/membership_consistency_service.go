@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/rs/zerolog/log"
+)
+
+// Kinds of drift between core_users and core_user_tenant_memberships
+const (
+	// The user has a legacy core_users.tenant_id but no membership in that
+	// tenant. This is the only kind that can be repaired.
+	DriftMissingMembership = "missing_membership"
+	// The legacy core_users.tenant_id points to a tenant that does not exist
+	DriftUnknownTenant = "unknown_tenant"
+	// The user belongs to no tenant and has no global role
+	DriftUserWithoutTenant = "user_without_tenant"
+	// The membership references a user that does not exist
+	DriftMembershipWithoutUser = "membership_without_user"
+)
+
+const (
+	MembershipConsistencyMaxRows           = 1000
+	DefaultMembershipConsistencyInterval   = 24 * time.Hour
+	membershipConsistencyDefaultTenantRole = "USER"
+)
+
+// tenantMembershipRoles are the roles that belong on a membership rather than
+// on core_users
+var tenantMembershipRoles = []string{"USER", "ADMIN", "CUSTOMER_ADMIN"}
+
+// MembershipDrift is one inconsistent row found by the checker
+type MembershipDrift struct {
+	Kind     string
+	UserID   string
+	TenantID string
+	Email    string
+	Repaired bool
+	Error    string
+}
+
+// MembershipConsistencyReport is the outcome of one check. Truncated is set
+// when a kind had more than MembershipConsistencyMaxRows rows; run the check
+// again after repairing to see the rest.
+type MembershipConsistencyReport struct {
+	DryRun    bool
+	CheckedAt time.Time
+	Repaired  int
+	Failed    int
+	Truncated bool
+	Counts    map[string]int
+	Drifts    []MembershipDrift
+}
+
+// MembershipConsistencyConfig configures the periodic check.
+//
+// Environment:
+//   - MEMBERSHIP_CONSISTENCY_CHECK_INTERVAL: check interval (default 24h, 0 disables the job)
+//   - MEMBERSHIP_CONSISTENCY_AUTO_REPAIR: create the missing memberships (default false, report only)
+type MembershipConsistencyConfig struct {
+	Interval   time.Duration
+	AutoRepair bool
+}
+
+func MembershipConsistencyConfigFromEnv() MembershipConsistencyConfig {
+	cfg := MembershipConsistencyConfig{Interval: DefaultMembershipConsistencyInterval}
+	if v := os.Getenv("MEMBERSHIP_CONSISTENCY_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("MEMBERSHIP_CONSISTENCY_CHECK_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("MEMBERSHIP_CONSISTENCY_AUTO_REPAIR"); v != "" {
+		if repair, err := strconv.ParseBool(v); err == nil {
+			cfg.AutoRepair = repair
+		} else {
+			log.Warn().Str("MEMBERSHIP_CONSISTENCY_AUTO_REPAIR", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// MembershipConsistencyService detects the rows left behind by deployments
+// that predate the membership model: users only known through
+// core_users.tenant_id, and memberships or users attached to nothing
+type MembershipConsistencyService struct {
+	store *db.Store
+}
+
+func NewMembershipConsistencyService(store *db.Store) *MembershipConsistencyService {
+	return &MembershipConsistencyService{store: store}
+}
+
+// StartMembershipConsistencyJob runs CheckConsistency now and then every
+// interval until ctx is done. It does nothing when the interval is 0.
+func (s *MembershipConsistencyService) StartMembershipConsistencyJob(ctx context.Context, cfg MembershipConsistencyConfig) {
+	if cfg.Interval <= 0 {
+		log.Info().Msg("Membership consistency job disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.CheckConsistency(ctx, cfg.AutoRepair); err != nil {
+				log.Err(err).Msg("Membership consistency check failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckConsistency reports the drift between core_users and the memberships.
+// With repair, the missing memberships are created; the other kinds are only
+// flagged since they need a human decision.
+func (s *MembershipConsistencyService) CheckConsistency(ctx context.Context, repair bool) (MembershipConsistencyReport, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	report := MembershipConsistencyReport{
+		DryRun:    !repair,
+		CheckedAt: time.Now().UTC(),
+		Counts: map[string]int{
+			DriftMissingMembership:     0,
+			DriftUnknownTenant:         0,
+			DriftUserWithoutTenant:     0,
+			DriftMembershipWithoutUser: 0,
+		},
+		Drifts: []MembershipDrift{},
+	}
+	add := func(drift MembershipDrift, rows int) {
+		report.Drifts = append(report.Drifts, drift)
+		report.Counts[drift.Kind]++
+		if rows >= MembershipConsistencyMaxRows {
+			report.Truncated = true
+		}
+	}
+
+	missing, err := s.store.ListUsersMissingMembership(ctx, MembershipConsistencyMaxRows)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list users missing a membership")
+		return report, fmt.Errorf("service.CheckConsistency: %w", err)
+	}
+	for _, user := range missing {
+		drift := MembershipDrift{
+			Kind:     DriftMissingMembership,
+			UserID:   user.ID,
+			TenantID: user.TenantID.String,
+			Email:    DecryptEmail(user.Email).String,
+		}
+		if repair {
+			s.repairMissingMembership(ctx, user, &drift)
+			if drift.Repaired {
+				report.Repaired++
+			} else {
+				report.Failed++
+			}
+		}
+		add(drift, len(missing))
+	}
+
+	unknownTenant, err := s.store.ListUsersWithUnknownTenant(ctx, MembershipConsistencyMaxRows)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list users with an unknown tenant")
+		return report, fmt.Errorf("service.CheckConsistency: %w", err)
+	}
+	for _, user := range unknownTenant {
+		add(MembershipDrift{
+			Kind:     DriftUnknownTenant,
+			UserID:   user.ID,
+			TenantID: user.TenantID.String,
+			Email:    DecryptEmail(user.Email).String,
+		}, len(unknownTenant))
+	}
+
+	withoutTenant, err := s.store.ListUsersWithoutTenant(ctx, MembershipConsistencyMaxRows)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list users without tenant")
+		return report, fmt.Errorf("service.CheckConsistency: %w", err)
+	}
+	for _, user := range withoutTenant {
+		add(MembershipDrift{
+			Kind:   DriftUserWithoutTenant,
+			UserID: user.ID,
+			Email:  DecryptEmail(user.Email).String,
+		}, len(withoutTenant))
+	}
+
+	withoutUser, err := s.store.ListMembershipsWithoutUser(ctx, MembershipConsistencyMaxRows)
+	if err != nil {
+		logger.Err(err).Msg("Failed to list memberships without user")
+		return report, fmt.Errorf("service.CheckConsistency: %w", err)
+	}
+	for _, membership := range withoutUser {
+		add(MembershipDrift{
+			Kind:     DriftMembershipWithoutUser,
+			UserID:   membership.UserID,
+			TenantID: membership.TenantID,
+		}, len(withoutUser))
+	}
+
+	if len(report.Drifts) > 0 {
+		logger.Warn().
+			Bool("dry_run", report.DryRun).
+			Int(DriftMissingMembership, report.Counts[DriftMissingMembership]).
+			Int(DriftUnknownTenant, report.Counts[DriftUnknownTenant]).
+			Int(DriftUserWithoutTenant, report.Counts[DriftUserWithoutTenant]).
+			Int(DriftMembershipWithoutUser, report.Counts[DriftMembershipWithoutUser]).
+			Int("repaired", report.Repaired).
+			Int("failed", report.Failed).
+			Msg("Membership consistency drift detected")
+	}
+	return report, nil
+}
+
+// repairMissingMembership creates the membership with the tenant roles found
+// on the legacy user row, USER when there are none
+func (s *MembershipConsistencyService) repairMissingMembership(ctx context.Context, user repository.ListUsersMissingMembershipRow, drift *MembershipDrift) {
+	logger := util.GetLoggerFromCtx(ctx)
+	roles := legacyTenantRoles(user.Roles)
+	created, err := s.store.CreateMissingUserMembership(ctx, repository.CreateMissingUserMembershipParams{
+		UserID:      user.ID,
+		TenantID:    user.TenantID.String,
+		TenantRoles: roles,
+	})
+	if err != nil {
+		logger.Err(err).Str("user_id", user.ID).Str("tenant_id", user.TenantID.String).Msg("Failed to create missing membership")
+		drift.Error = err.Error()
+		return
+	}
+	// Zero rows means the membership appeared since the listing, which is
+	// the state the repair aims for
+	drift.Repaired = true
+	if created > 0 {
+		logger.Info().Str("user_id", user.ID).Str("tenant_id", user.TenantID.String).Strs("roles", roles).Msg("Created missing membership")
+	}
+}
+
+func legacyTenantRoles(roles []string) []string {
+	tenantRoles := []string{}
+	for _, role := range roles {
+		if slices.Contains(tenantMembershipRoles, role) && !slices.Contains(tenantRoles, role) {
+			tenantRoles = append(tenantRoles, role)
+		}
+	}
+	if len(tenantRoles) == 0 {
+		tenantRoles = append(tenantRoles, membershipConsistencyDefaultTenantRole)
+	}
+	return tenantRoles
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+
+	"github.com/stretchr/testify/require"
+)
+
+func findDrift(report MembershipConsistencyReport, kind, userID string) (MembershipDrift, bool) {
+	for _, drift := range report.Drifts {
+		if drift.Kind == kind && drift.UserID == userID {
+			return drift, true
+		}
+	}
+	return MembershipDrift{}, false
+}
+
+func TestMembershipConsistency(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewMembershipConsistencyService(store)
+	ctx := context.Background()
+
+	tenantID := commontestutils.RandomString(10)
+	_, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  tenantID,
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+
+	// Legacy user: tenant on core_users, no membership
+	legacyUserID := commontestutils.RandomString(12)
+	_, err = store.CreateSharedUser(ctx, repository.CreateSharedUserParams{
+		ID:      legacyUserID,
+		Email:   commontestutils.RandomString(8) + "@example.com",
+		Profile: subentity.UserProfile{},
+		Roles:   []string{"CUSTOMER_ADMIN"},
+	})
+	require.NoError(t, err)
+	_, err = store.ConnPool.Exec(ctx, "UPDATE core_users SET tenant_id = $1 WHERE id = $2", tenantID, legacyUserID)
+	require.NoError(t, err)
+
+	// Orphan user: no tenant, no membership, no role
+	orphanUserID := commontestutils.RandomString(12)
+	_, err = store.CreateSharedUser(ctx, repository.CreateSharedUserParams{
+		ID:      orphanUserID,
+		Email:   commontestutils.RandomString(8) + "@example.com",
+		Profile: subentity.UserProfile{},
+		Roles:   []string{},
+	})
+	require.NoError(t, err)
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		report, err := service.CheckConsistency(ctx, false)
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+
+		drift, found := findDrift(report, DriftMissingMembership, legacyUserID)
+		require.True(t, found)
+		require.Equal(t, tenantID, drift.TenantID)
+		require.False(t, drift.Repaired)
+
+		_, found = findDrift(report, DriftUserWithoutTenant, orphanUserID)
+		require.True(t, found)
+
+		_, err = store.GetSharedUserTenantMembership(ctx, repository.GetSharedUserTenantMembershipParams{
+			UserID:   legacyUserID,
+			TenantID: tenantID,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("repair creates the missing membership", func(t *testing.T) {
+		report, err := service.CheckConsistency(ctx, true)
+		require.NoError(t, err)
+		drift, found := findDrift(report, DriftMissingMembership, legacyUserID)
+		require.True(t, found)
+		require.True(t, drift.Repaired)
+
+		membership, err := store.GetSharedUserTenantMembership(ctx, repository.GetSharedUserTenantMembershipParams{
+			UserID:   legacyUserID,
+			TenantID: tenantID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"CUSTOMER_ADMIN"}, membership.Roles)
+
+		// Orphans are flagged, never repaired
+		report, err = service.CheckConsistency(ctx, true)
+		require.NoError(t, err)
+		_, found = findDrift(report, DriftMissingMembership, legacyUserID)
+		require.False(t, found)
+		_, found = findDrift(report, DriftUserWithoutTenant, orphanUserID)
+		require.True(t, found)
+	})
+}
+
+func TestLegacyTenantRoles(t *testing.T) {
+	require.Equal(t, []string{"USER"}, legacyTenantRoles(nil))
+	require.Equal(t, []string{"USER"}, legacyTenantRoles([]string{"SUPER_ADMIN"}))
+	require.Equal(t, []string{"ADMIN", "USER"}, legacyTenantRoles([]string{"ADMIN", "SUPER_ADMIN", "USER", "ADMIN"}))
+}
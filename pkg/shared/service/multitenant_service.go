@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"sync"
+	"time"
 
 	"ctoup.com/coreapp/pkg/core/db"
 	"ctoup.com/coreapp/pkg/core/db/repository"
@@ -40,9 +41,18 @@ func (uh *MultitenantService) loadTenantPreferContext(ctx context.Context, tenan
 	return getTenantCache().get(ctx, uh.store, tenantID)
 }
 
+// TenantMap maps subdomains to tenant IDs. Primary subdomains are kept until
+// they no longer match the tenant; aliases expire with the tenant cache so
+// removing one takes effect on every instance.
 type TenantMap struct {
 	sync.RWMutex
-	data map[string]string
+	data    map[string]string
+	aliases map[string]tenantAliasEntry
+}
+
+type tenantAliasEntry struct {
+	tenantID  string
+	expiresAt time.Time
 }
 
 var tenantMapInstance *TenantMap
@@ -52,12 +62,44 @@ var once sync.Once
 func GetTenantMap() *TenantMap {
 	once.Do(func() {
 		tenantMapInstance = &TenantMap{
-			data: make(map[string]string),
+			data:    make(map[string]string),
+			aliases: make(map[string]tenantAliasEntry),
 		}
 	})
 	return tenantMapInstance
 }
 
+// lookup returns the tenant ID mapped to the subdomain and whether the
+// subdomain is an alias
+func (m *TenantMap) lookup(subdomain string) (string, bool, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	if tenantID, ok := m.data[subdomain]; ok {
+		return tenantID, false, true
+	}
+	if entry, ok := m.aliases[subdomain]; ok && time.Now().Before(entry.expiresAt) {
+		return entry.tenantID, true, true
+	}
+	return "", false, false
+}
+
+func (m *TenantMap) store(subdomain string, tenant repository.CoreTenant) {
+	m.Lock()
+	defer m.Unlock()
+	if tenant.Subdomain == subdomain {
+		m.data[subdomain] = tenant.TenantID
+		return
+	}
+	m.aliases[subdomain] = tenantAliasEntry{tenantID: tenant.TenantID, expiresAt: time.Now().Add(DefaultTenantCacheTTL)}
+}
+
+func (m *TenantMap) forget(subdomain string) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.data, subdomain)
+	delete(m.aliases, subdomain)
+}
+
 type MultitenantService struct {
 	store *db.Store
 }
@@ -71,37 +113,20 @@ func (uh *MultitenantService) GetStore() *db.Store {
 	return uh.store
 }
 
-// Map subdomain to tenant ID. On a cold subdomain miss the loaded tenant is
-// also written into the tenant cache so a subsequent GetTenantByTenantIDCached
-// in the same request does not re-query the DB.
+// Map subdomain to tenant ID, the subdomain being the primary subdomain of
+// the tenant or one of its aliases. On a cold subdomain miss the loaded tenant
+// is also written into the tenant cache so a subsequent
+// GetTenantByTenantIDCached in the same request does not re-query the DB.
 func (uh *MultitenantService) GetTenantIDWithSubdomain(ctx context.Context, subdomain string) (string, error) {
 	if util.IsAdminSubdomain(subdomain) || subdomain == "auth" {
 		return "", nil
 	}
 
-	tenantMap := GetTenantMap()
-
-	tenantMap.RLock()
-	tenantID, exists := tenantMap.data[subdomain]
-	tenantMap.RUnlock()
-
-	// If the subdomain is not found in the map, return an error
-	if !exists {
-		tenant, err := uh.store.GetTenantBySubdomain(ctx, subdomain)
-		if err != nil {
-			return "", err
-		}
-
-		tenantID = tenant.TenantID
-		tenantMap.Lock()
-		tenantMap.data[tenant.Subdomain] = tenantID
-		tenantMap.Unlock()
-		// Warm the tenant-record cache too — the caller almost always wants the
-		// full tenant next (tenant_middleware, auth flow), and skipping this
-		// would cost a redundant DB round-trip.
-		getTenantCache().put(tenant)
+	tenant, err := uh.GetTenantBySubdomainCached(ctx, subdomain)
+	if err != nil {
+		return "", err
 	}
-	return tenantID, nil
+	return tenant.TenantID, nil
 }
 
 // GetTenantAllowSignUp returns the tenant's AllowSignUp flag.
@@ -148,27 +173,32 @@ func (uh *MultitenantService) GetTenantByTenantIDCached(ctx context.Context, ten
 	return getTenantCache().get(ctx, uh.store, tenantID)
 }
 
-// GetTenantBySubdomainCached returns the tenant record for the given subdomain.
-// On warm paths the subdomain→tenant_id mapping (TenantMap) and the tenant
-// record cache are both hit — no DB query. On cold paths it does a single
-// GetTenantBySubdomain query and populates both caches.
+// GetTenantBySubdomainCached returns the tenant record for the given subdomain,
+// which is either the primary subdomain of the tenant or one of its aliases;
+// compare it with the Subdomain of the tenant to tell them apart. On warm
+// paths the subdomain→tenant_id mapping (TenantMap) and the tenant record
+// cache are both hit — no DB query. On cold paths it does a single
+// GetTenantBySubdomainOrAlias query and populates both caches.
 func (uh *MultitenantService) GetTenantBySubdomainCached(ctx context.Context, subdomain string) (repository.CoreTenant, error) {
 	tenantMap := GetTenantMap()
-	tenantMap.RLock()
-	tenantID, hasMapping := tenantMap.data[subdomain]
-	tenantMap.RUnlock()
-
-	if hasMapping {
-		return getTenantCache().get(ctx, uh.store, tenantID)
+	if tenantID, isAlias, ok := tenantMap.lookup(subdomain); ok {
+		tenant, err := getTenantCache().get(ctx, uh.store, tenantID)
+		if err != nil {
+			return repository.CoreTenant{}, err
+		}
+		// A primary subdomain changed since it was mapped is resolved again,
+		// it may have become an alias
+		if isAlias || tenant.Subdomain == subdomain {
+			return tenant, nil
+		}
+		tenantMap.forget(subdomain)
 	}
 
-	tenant, err := uh.store.GetTenantBySubdomain(ctx, subdomain)
+	tenant, err := uh.store.GetTenantBySubdomainOrAlias(ctx, subdomain)
 	if err != nil {
 		return repository.CoreTenant{}, err
 	}
-	tenantMap.Lock()
-	tenantMap.data[tenant.Subdomain] = tenant.TenantID
-	tenantMap.Unlock()
+	tenantMap.store(subdomain, tenant)
 	getTenantCache().put(tenant)
 	return tenant, nil
 }
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// OAuth2 client credentials constants
+const (
+	OAuthGrantClientCredentials = "client_credentials"
+	ClientSecretPrefix          = "cs_"
+	DefaultOAuthAccessTokenTTL  = 15 * time.Minute
+	DefaultOAuthIssuer          = "ctoup.com/coreapp"
+	minOAuthSigningKeyLength    = 32
+	// Context key holding the ClientCredentialsPrincipal of a request
+	// authenticated with a client credentials access token
+	OAuthClientKey = "oauth_client"
+)
+
+var (
+	ErrOAuthNotConfigured = errors.New("client credentials grant is not configured")
+	ErrInvalidClient      = errors.New("invalid client credentials")
+	ErrInvalidScope       = errors.New("requested scope is not allowed for this client")
+	ErrInvalidAccessToken = errors.New("invalid access token")
+	// ErrClientIPNotAllowed is returned for an access token used from an
+	// address outside the allowlist of its client secret
+	ErrClientIPNotAllowed = errors.New("client IP is not allowed for this client application")
+)
+
+// OAuthConfig configures the client credentials access tokens.
+//
+// Environment:
+//   - OAUTH_JWT_SIGNING_KEY: HS256 signing key, at least 32 bytes (the grant is
+//     disabled when unset)
+//   - OAUTH_JWT_ISSUER: iss claim (default ctoup.com/coreapp)
+//   - OAUTH_ACCESS_TOKEN_TTL: access token lifetime (default 15m)
+type OAuthConfig struct {
+	SigningKey []byte
+	Issuer     string
+	TTL        time.Duration
+}
+
+func OAuthConfigFromEnv() OAuthConfig {
+	return OAuthConfigFromSecrets(context.Background(), EnvSecretProvider{})
+}
+
+// OAuthConfigFromSecrets reads the signing key from the secret provider and
+// the other settings from the environment
+func OAuthConfigFromSecrets(ctx context.Context, secrets SecretProvider) OAuthConfig {
+	cfg := OAuthConfig{
+		Issuer: DefaultOAuthIssuer,
+		TTL:    DefaultOAuthAccessTokenTTL,
+	}
+	if key := readSecret(ctx, secrets, SecretOAuthJWTSigningKey); key != "" {
+		if len(key) < minOAuthSigningKeyLength {
+			log.Error().Int("min_length", minOAuthSigningKeyLength).Msg("OAUTH_JWT_SIGNING_KEY is too short, client credentials grant disabled")
+		} else {
+			cfg.SigningKey = []byte(key)
+		}
+	}
+	if issuer := os.Getenv("OAUTH_JWT_ISSUER"); issuer != "" {
+		cfg.Issuer = issuer
+	}
+	if v := os.Getenv("OAUTH_ACCESS_TOKEN_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil && ttl > 0 {
+			cfg.TTL = ttl
+		} else {
+			log.Warn().Str("OAUTH_ACCESS_TOKEN_TTL", v).Msg("Invalid duration, using default")
+		}
+	}
+	return cfg
+}
+
+// ClientCredentialsClaims are the claims of a client credentials access token.
+// The subject is the client application ID.
+type ClientCredentialsClaims struct {
+	jwt.RegisteredClaims
+	Scope    string `json:"scope,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+	// SecretID identifies the client secret the token was issued with, so
+	// rotating the secret revokes the outstanding tokens
+	SecretID string `json:"sid"`
+}
+
+// OAuthAccessToken is an issued client credentials access token
+type OAuthAccessToken struct {
+	AccessToken string
+	ExpiresIn   time.Duration
+	Scopes      []string
+}
+
+// ClientCredentialsPrincipal is the client application authenticated by an
+// access token
+type ClientCredentialsPrincipal struct {
+	ClientApplicationID uuid.UUID
+	TenantID            string
+	Scopes              []string
+	// CreatedBy is the creator of the client application
+	CreatedBy string
+	// ServiceAccountID is the service account of the application, if linked
+	ServiceAccountID string
+	IssuedAt         time.Time
+	ExpiresAt        time.Time
+	// SecretID is the client secret the token was issued with; its rate limit
+	// and IP allowlist apply to the requests of the token
+	SecretID     uuid.UUID
+	RateLimit    TokenRateLimit
+	AllowedCIDRs []string
+}
+
+// RotateClientSecret creates the client secret of the application, replacing
+// the previous one. The secret is only returned here; only its hash is stored.
+// The limits apply to every access token issued with the secret.
+func (s *ClientApplicationService) RotateClientSecret(ctx context.Context, clientApplicationID uuid.UUID, tenantID string,
+	scopes []string, limits APITokenLimits, createdBy string) (string, repository.CoreClientApplicationSecret, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+
+	limits, err := limits.normalize()
+	if err != nil {
+		return "", repository.CoreClientApplicationSecret{}, err
+	}
+
+	// Scope the application to the caller's tenant
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return "", repository.CoreClientApplicationSecret{}, err
+	}
+
+	secret, secretPrefix, secretHash, err := generateClientSecret()
+	if err != nil {
+		logger.Err(err).Msg("Failed to generate client secret")
+		return "", repository.CoreClientApplicationSecret{}, err
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	record, err := s.store.UpsertClientApplicationSecret(ctx, repository.UpsertClientApplicationSecretParams{
+		ClientApplicationID: clientApplicationID,
+		SecretHash:          secretHash,
+		SecretPrefix:        secretPrefix,
+		CreatedBy:           createdBy,
+		RateLimitPerMinute:  util.ToNullableInt4(limits.RateLimitPerMinute),
+		RateLimitPerHour:    util.ToNullableInt4(limits.RateLimitPerHour),
+		Scopes:              scopes,
+		AllowedCidrs:        limits.AllowedCIDRs,
+	})
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to store client secret")
+		return "", repository.CoreClientApplicationSecret{}, err
+	}
+	return secret, record, nil
+}
+
+// DeleteClientSecret removes the client secret of the application, which
+// disables the client credentials grant and revokes the issued tokens
+func (s *ClientApplicationService) DeleteClientSecret(ctx context.Context, clientApplicationID uuid.UUID, tenantID string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteClientApplicationSecret(ctx, clientApplicationID)
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to delete client secret")
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// IssueClientCredentialsToken implements the client_credentials grant: it
+// authenticates the client and signs an access token carrying the granted
+// scopes and the application tenant. An empty requestedScopes grants every
+// scope allowed to the client.
+func (s *ClientApplicationService) IssueClientCredentialsToken(ctx context.Context, clientID, clientSecret string, requestedScopes []string) (OAuthAccessToken, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if len(s.oauth.SigningKey) == 0 {
+		return OAuthAccessToken{}, ErrOAuthNotConfigured
+	}
+
+	secret, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return OAuthAccessToken{}, err
+	}
+	applicationID := secret.ClientApplicationID
+
+	scopes := secret.Scopes
+	if len(requestedScopes) > 0 {
+		for _, scope := range requestedScopes {
+			if !HasScope(secret.Scopes, scope) {
+				return OAuthAccessToken{}, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+			}
+		}
+		scopes = requestedScopes
+	}
+
+	now := time.Now()
+	claims := ClientCredentialsClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.oauth.Issuer,
+			Subject:   applicationID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.oauth.TTL)),
+			ID:        uuid.New().String(),
+		},
+		Scope:    strings.Join(scopes, " "),
+		TenantID: secret.TenantID.String,
+		SecretID: secret.ID.String(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.oauth.SigningKey)
+	if err != nil {
+		logger.Err(err).Msg("Failed to sign access token")
+		return OAuthAccessToken{}, err
+	}
+
+	s.usage.TouchClientApplication(ctx, applicationID)
+	return OAuthAccessToken{AccessToken: signed, ExpiresIn: s.oauth.TTL, Scopes: scopes}, nil
+}
+
+// authenticateClient checks the client_id/client_secret pair of an active
+// client application
+func (s *ClientApplicationService) authenticateClient(ctx context.Context, clientID, clientSecret string) (repository.GetClientApplicationSecretRow, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	applicationID, err := uuid.Parse(clientID)
+	if err != nil {
+		return repository.GetClientApplicationSecretRow{}, ErrInvalidClient
+	}
+	secret, err := s.store.GetClientApplicationSecret(ctx, applicationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.GetClientApplicationSecretRow{}, ErrInvalidClient
+		}
+		logger.Err(err).Str("clientID", clientID).Msg("Failed to get client secret")
+		return repository.GetClientApplicationSecretRow{}, err
+	}
+	hash := sha256.Sum256([]byte(strings.TrimSpace(clientSecret)))
+	if subtle.ConstantTimeCompare(hash[:], secret.SecretHash) != 1 || !secret.Active {
+		logger.Warn().Str("clientID", clientID).Msg("Client credentials rejected")
+		return repository.GetClientApplicationSecretRow{}, ErrInvalidClient
+	}
+	return secret, nil
+}
+
+// VerifyClientCredentialsToken checks the signature and expiry of an access
+// token, then that its client secret was not rotated and its application is
+// still active
+func (s *ClientApplicationService) VerifyClientCredentialsToken(ctx context.Context, tokenString string) (ClientCredentialsPrincipal, error) {
+	if len(s.oauth.SigningKey) == 0 {
+		return ClientCredentialsPrincipal{}, ErrOAuthNotConfigured
+	}
+
+	var claims ClientCredentialsClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.oauth.SigningKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.oauth.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return ClientCredentialsPrincipal{}, fmt.Errorf("%w: %w", ErrInvalidAccessToken, err)
+	}
+
+	applicationID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return ClientCredentialsPrincipal{}, ErrInvalidAccessToken
+	}
+	secret, err := s.store.GetClientApplicationSecret(ctx, applicationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ClientCredentialsPrincipal{}, ErrInvalidAccessToken
+		}
+		return ClientCredentialsPrincipal{}, err
+	}
+	if !secret.Active || secret.ID.String() != claims.SecretID {
+		return ClientCredentialsPrincipal{}, ErrInvalidAccessToken
+	}
+
+	return ClientCredentialsPrincipal{
+		ClientApplicationID: applicationID,
+		TenantID:            secret.TenantID.String,
+		Scopes:              strings.Fields(claims.Scope),
+		CreatedBy:           secret.ApplicationCreatedBy,
+		ServiceAccountID:    secret.ServiceAccountID.String,
+		IssuedAt:            claims.IssuedAt.Time,
+		ExpiresAt:           claims.ExpiresAt.Time,
+		SecretID:            secret.ID,
+		RateLimit: TokenRateLimit{
+			PerMinute: int(secret.RateLimitPerMinute.Int32),
+			PerHour:   int(secret.RateLimitPerHour.Int32),
+		},
+		AllowedCIDRs: secret.AllowedCidrs,
+	}, nil
+}
+
+// IsClientCredentialsToken reports whether a bearer value looks like an access
+// token of this server: an HS256 JWT naming a client application and the
+// secret it was issued with. The signature is not checked here, other JWTs
+// are left to the auth provider.
+func IsClientCredentialsToken(token string) bool {
+	if strings.HasPrefix(token, TokenPrefix) || strings.Count(token, ".") != 2 {
+		return false
+	}
+	var claims ClientCredentialsClaims
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &claims)
+	if err != nil || parsed.Method != jwt.SigningMethodHS256 {
+		return false
+	}
+	if _, err := uuid.Parse(claims.Subject); err != nil {
+		return false
+	}
+	_, err = uuid.Parse(claims.SecretID)
+	return err == nil
+}
+
+// CheckClientCredentialsLimits applies the IP allowlist and the rate limit of
+// the client secret to a request authenticated with its access token. It
+// responds 403 or 429 with a Retry-After header and returns false when the
+// request is refused; the caller must abort it.
+func (s *ClientApplicationService) CheckClientCredentialsLimits(c *gin.Context, principal ClientCredentialsPrincipal) bool {
+	logger := util.GetLoggerFromCtx(c)
+	if !IsIPAllowed(c.ClientIP(), principal.AllowedCIDRs) {
+		logger.Warn().Str("clientApplicationID", principal.ClientApplicationID.String()).Str("ip", c.ClientIP()).Msg("Access token used from a non allowed IP address")
+		RecordAuthFailure(c, principal.TenantID, AuthFailureIPNotAllowed)
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  http.StatusForbidden,
+			"message": ErrClientIPNotAllowed.Error(),
+		})
+		return false
+	}
+
+	if principal.RateLimit.IsUnlimited() {
+		return true
+	}
+	allowed, retryAfter, err := s.rateLimiter.Allow(c, clientCredentialsRateLimitKey(principal.SecretID.String()), principal.RateLimit.Windows()...)
+	if err != nil {
+		// Fail open like the API tokens
+		logger.Err(err).Str("clientApplicationID", principal.ClientApplicationID.String()).Msg("Failed to check client credentials rate limit")
+		return true
+	}
+	if allowed {
+		return true
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"status":  http.StatusTooManyRequests,
+		"message": "Client application rate limit exceeded",
+	})
+	return false
+}
+
+// UserID is the user the requests of the client run as
+func (p ClientCredentialsPrincipal) UserID() string {
+	if p.ServiceAccountID != "" {
+		return p.ServiceAccountID
+	}
+	return p.CreatedBy
+}
+
+// setClientCredentialsPrincipal exposes the client to the handlers the same
+// way API tokens do, so ValidateTokenScopes applies to both
+func setClientCredentialsPrincipal(c *gin.Context, principal ClientCredentialsPrincipal) {
+	c.Set(OAuthClientKey, principal)
+	c.Set("api_token_scopes", principal.Scopes)
+}
+
+func generateClientSecret() (string, string, []byte, error) {
+	randomBytes := make([]byte, TokenRandomLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", nil, err
+	}
+	secret := ClientSecretPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+	hash := sha256.Sum256([]byte(secret))
+	return secret, secret[:TokenPrefixLength], hash[:], nil
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of resources owned by users in core
+const (
+	OwnedResourceClientApplication = "client_application"
+	OwnedResourceAPIToken          = "api_token"
+)
+
+// Activity events recorded on both sides of a transfer
+const (
+	activityOwnershipTransferredOut = "ownership_transferred_out"
+	activityOwnershipTransferredIn  = "ownership_transferred_in"
+)
+
+var ErrInvalidTransferTarget = errors.New("invalid ownership transfer target")
+
+// OwnedResource is a resource created by a user that must outlive them.
+// TenantID is empty for global resources.
+type OwnedResource struct {
+	Kind     string `json:"kind"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// OwnedResourceProvider lets a module, such as prompts, take part in the
+// ownership transfer of deleted users. Both methods run in the transaction of
+// the deletion.
+type OwnedResourceProvider interface {
+	// ListOwnedResources returns the resources owned by the user in every tenant
+	ListOwnedResources(ctx context.Context, tx pgx.Tx, userID string) ([]OwnedResource, error)
+	// TransferOwnedResources gives the user's resources in the tenant, the
+	// global ones when tenantID is empty, to another user
+	TransferOwnedResources(ctx context.Context, tx pgx.Tx, tenantID, fromUserID, toUserID string) (int64, error)
+}
+
+var (
+	ownedResourceProvidersMu sync.RWMutex
+	ownedResourceProviders   = []OwnedResourceProvider{clientApplicationOwnership{}}
+)
+
+// RegisterOwnedResourceProvider adds a provider to the ownership transfer run
+// when a user is deleted
+func RegisterOwnedResourceProvider(provider OwnedResourceProvider) {
+	ownedResourceProvidersMu.Lock()
+	defer ownedResourceProvidersMu.Unlock()
+	ownedResourceProviders = append(ownedResourceProviders, provider)
+}
+
+func getOwnedResourceProviders() []OwnedResourceProvider {
+	ownedResourceProvidersMu.RLock()
+	defer ownedResourceProvidersMu.RUnlock()
+	return append([]OwnedResourceProvider{}, ownedResourceProviders...)
+}
+
+// OwnershipTransfer names who inherits the resources of a deleted user: an
+// explicit user, or the owner of the tenant each resource belongs to. Global
+// resources can only go to an explicit user.
+type OwnershipTransfer struct {
+	ToUserID      string
+	ToTenantOwner bool
+}
+
+func (t OwnershipTransfer) isSet() bool {
+	return t.ToUserID != "" || t.ToTenantOwner
+}
+
+// ErrUserOwnsResources blocks the deletion of a user who owns resources when
+// no transfer target is given. Callers surface it as 409 Conflict with the
+// list of resources.
+type ErrUserOwnsResources struct {
+	Resources []OwnedResource
+}
+
+func (e *ErrUserOwnsResources) Error() string {
+	kinds := map[string]int{}
+	for _, resource := range e.Resources {
+		kinds[resource.Kind]++
+	}
+	counts := make([]string, 0, len(kinds))
+	for kind, count := range kinds {
+		counts = append(counts, fmt.Sprintf("%d %s", count, kind))
+	}
+	sort.Strings(counts)
+	return fmt.Sprintf(
+		"user owns %s — transfer them to another user or the tenant owner first",
+		strings.Join(counts, ", "),
+	)
+}
+
+// transferOwnedResources moves the resources of the user to the transfer
+// target inside tx. With a tenantID only the resources of that tenant are
+// concerned, the user keeping the others; an empty tenantID covers every
+// resource, for a global deletion.
+func transferOwnedResources(ctx context.Context, tx pgx.Tx, tenantID, userID string, transfer OwnershipTransfer, actorID string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	qtx := repository.New(tx)
+	providers := getOwnedResourceProviders()
+
+	byTenant, err := listOwnedResourcesByTenant(ctx, tx, tenantID, userID, transfer)
+	if err != nil {
+		return err
+	}
+	for resourceTenantID, owned := range byTenant {
+		toUserID, err := resolveTransferTarget(ctx, qtx, resourceTenantID, userID, transfer)
+		if err != nil {
+			return err
+		}
+		for _, provider := range providers {
+			if _, err := provider.TransferOwnedResources(ctx, tx, resourceTenantID, userID, toUserID); err != nil {
+				return fmt.Errorf("service.TransferOwnedResources: %w", err)
+			}
+		}
+
+		logger.Info().
+			Str("from_user_id", userID).
+			Str("to_user_id", toUserID).
+			Str("tenant_id", resourceTenantID).
+			Int("resources", len(owned)).
+			Msg("Transferred ownership of deleted user's resources")
+		if err := recordOwnershipTransfer(ctx, qtx, resourceTenantID, userID, toUserID, actorID, owned); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOwnershipTransfer returns the error transferOwnedResources would
+// return, without moving anything. It lets a deletion planned for later be
+// refused now.
+func checkOwnershipTransfer(ctx context.Context, tx pgx.Tx, tenantID, userID string, transfer OwnershipTransfer) error {
+	byTenant, err := listOwnedResourcesByTenant(ctx, tx, tenantID, userID, transfer)
+	if err != nil {
+		return err
+	}
+	qtx := repository.New(tx)
+	for resourceTenantID := range byTenant {
+		if _, err := resolveTransferTarget(ctx, qtx, resourceTenantID, userID, transfer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOwnedResourcesByTenant returns the resources of the user concerned by
+// the deletion, grouped by tenant, or ErrUserOwnsResources when there are
+// some and no transfer target
+func listOwnedResourcesByTenant(ctx context.Context, tx pgx.Tx, tenantID, userID string, transfer OwnershipTransfer) (map[string][]OwnedResource, error) {
+	resources := []OwnedResource{}
+	for _, provider := range getOwnedResourceProviders() {
+		owned, err := provider.ListOwnedResources(ctx, tx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("service.TransferOwnedResources: %w", err)
+		}
+		for _, resource := range owned {
+			if tenantID == "" || resource.TenantID == tenantID {
+				resources = append(resources, resource)
+			}
+		}
+	}
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	if !transfer.isSet() {
+		return nil, &ErrUserOwnsResources{Resources: resources}
+	}
+
+	byTenant := map[string][]OwnedResource{}
+	for _, resource := range resources {
+		byTenant[resource.TenantID] = append(byTenant[resource.TenantID], resource)
+	}
+	return byTenant, nil
+}
+
+// resolveTransferTarget returns the user inheriting the resources of the
+// tenant, who must be an active member of it
+func resolveTransferTarget(ctx context.Context, qtx *repository.Queries, tenantID, fromUserID string, transfer OwnershipTransfer) (string, error) {
+	toUserID := transfer.ToUserID
+	if transfer.ToTenantOwner {
+		if tenantID == "" {
+			return "", fmt.Errorf("%w: global resources have no tenant owner, name a user to transfer them to", ErrInvalidTransferTarget)
+		}
+		tenant, err := qtx.GetTenantByTenantID(ctx, tenantID)
+		if err != nil {
+			return "", fmt.Errorf("service.TransferOwnedResources: %w", err)
+		}
+		toUserID = tenant.UserID
+	}
+	if toUserID == fromUserID {
+		return "", fmt.Errorf("%w: the deleted user cannot receive their own resources", ErrInvalidTransferTarget)
+	}
+
+	if _, err := qtx.GetSharedUserByID(ctx, toUserID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%w: user %s does not exist", ErrInvalidTransferTarget, toUserID)
+		}
+		return "", fmt.Errorf("service.TransferOwnedResources: %w", err)
+	}
+	if tenantID != "" {
+		isMember, err := qtx.IsUserMemberOfTenant(ctx, repository.IsUserMemberOfTenantParams{
+			UserID:   toUserID,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			return "", fmt.Errorf("service.TransferOwnedResources: %w", err)
+		}
+		if !isMember {
+			return "", fmt.Errorf("%w: user %s is not an active member of tenant %s", ErrInvalidTransferTarget, toUserID, tenantID)
+		}
+	}
+	return toUserID, nil
+}
+
+// recordOwnershipTransfer writes the audit entries in the transaction, so
+// they exist exactly when the transfer does
+func recordOwnershipTransfer(ctx context.Context, qtx *repository.Queries, tenantID, fromUserID, toUserID, actorID string, resources []OwnedResource) error {
+	entries := []struct {
+		userID    string
+		eventType string
+		data      map[string]interface{}
+	}{
+		{fromUserID, activityOwnershipTransferredOut, map[string]interface{}{"to_user_id": toUserID, "resources": resources}},
+		{toUserID, activityOwnershipTransferredIn, map[string]interface{}{"from_user_id": fromUserID, "resources": resources}},
+	}
+	for _, entry := range entries {
+		data, err := json.Marshal(entry.data)
+		if err != nil {
+			return fmt.Errorf("service.TransferOwnedResources: %w", err)
+		}
+		err = qtx.CreateUserActivityEvent(ctx, repository.CreateUserActivityEventParams{
+			TenantID:  tenantID,
+			UserID:    entry.userID,
+			Category:  ActivityCategoryAccount,
+			EventType: entry.eventType,
+			ActorID:   pgtype.Text{String: actorID, Valid: actorID != ""},
+			Data:      data,
+		})
+		if err != nil {
+			return fmt.Errorf("service.TransferOwnedResources: %w", err)
+		}
+	}
+	return nil
+}
+
+// clientApplicationOwnership transfers the client applications and the API
+// tokens created by the user. Revoked tokens keep their creator.
+type clientApplicationOwnership struct{}
+
+func (clientApplicationOwnership) ListOwnedResources(ctx context.Context, tx pgx.Tx, userID string) ([]OwnedResource, error) {
+	qtx := repository.New(tx)
+	resources := []OwnedResource{}
+	applications, err := qtx.ListClientApplicationsCreatedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, application := range applications {
+		resources = append(resources, OwnedResource{
+			Kind:     OwnedResourceClientApplication,
+			ID:       application.ID.String(),
+			Name:     application.Name,
+			TenantID: application.TenantID,
+		})
+	}
+	tokens, err := qtx.ListAPITokensCreatedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		resources = append(resources, OwnedResource{
+			Kind:     OwnedResourceAPIToken,
+			ID:       token.ID.String(),
+			Name:     token.Name,
+			TenantID: token.TenantID,
+		})
+	}
+	return resources, nil
+}
+
+func (clientApplicationOwnership) TransferOwnedResources(ctx context.Context, tx pgx.Tx, tenantID, fromUserID, toUserID string) (int64, error) {
+	qtx := repository.New(tx)
+	applications, err := qtx.TransferClientApplicationsOwnership(ctx, repository.TransferClientApplicationsOwnershipParams{
+		ToUserID:   toUserID,
+		FromUserID: fromUserID,
+		TenantID:   tenantID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	tokens, err := qtx.TransferAPITokensOwnership(ctx, repository.TransferAPITokensOwnershipParams{
+		ToUserID:   toUserID,
+		FromUserID: fromUserID,
+		TenantID:   tenantID,
+	})
+	if err != nil {
+		return applications, err
+	}
+	return applications + tokens, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestTenantMember(t *testing.T, store *db.Store, tenantID string) string {
+	ctx := context.Background()
+	userID := commontestutils.RandomString(12)
+	_, err := store.CreateSharedUser(ctx, repository.CreateSharedUserParams{
+		ID:      userID,
+		Email:   commontestutils.RandomString(8) + "@example.com",
+		Profile: subentity.UserProfile{},
+		Roles:   []string{},
+	})
+	require.NoError(t, err)
+	_, err = store.AddSharedUserToTenant(ctx, repository.AddSharedUserToTenantParams{
+		UserID:      userID,
+		TenantID:    tenantID,
+		TenantRoles: []string{"USER"},
+		Status:      "active",
+	})
+	require.NoError(t, err)
+	return userID
+}
+
+func transferInTx(t *testing.T, store *db.Store, tenantID, userID string, transfer OwnershipTransfer) error {
+	ctx := context.Background()
+	tx, err := store.ConnPool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	if err := transferOwnedResources(ctx, tx, tenantID, userID, transfer, ""); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func TestTransferOwnedResources(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	ctx := context.Background()
+
+	ownerID := commontestutils.RandomString(12)
+	tenantID := commontestutils.RandomString(10)
+	_, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    ownerID,
+		TenantID:  tenantID,
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	_, err = store.CreateSharedUser(ctx, repository.CreateSharedUserParams{
+		ID:      ownerID,
+		Email:   commontestutils.RandomString(8) + "@example.com",
+		Profile: subentity.UserProfile{},
+		Roles:   []string{},
+	})
+	require.NoError(t, err)
+	_, err = store.AddSharedUserToTenant(ctx, repository.AddSharedUserToTenantParams{
+		UserID:      ownerID,
+		TenantID:    tenantID,
+		TenantRoles: []string{"CUSTOMER_ADMIN"},
+		Status:      "active",
+	})
+	require.NoError(t, err)
+
+	leavingID := createTestTenantMember(t, store, tenantID)
+	colleagueID := createTestTenantMember(t, store, tenantID)
+	outsiderID := createTestTenantMember(t, store, commontestutils.RandomString(10))
+
+	app, err := store.CreateClientApplication(ctx, repository.CreateClientApplicationParams{
+		Name:      commontestutils.RandomString(10),
+		CreatedBy: leavingID,
+		TenantID:  pgtype.Text{String: tenantID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	t.Run("blocked without a transfer target", func(t *testing.T) {
+		err := transferInTx(t, store, tenantID, leavingID, OwnershipTransfer{})
+		var owns *ErrUserOwnsResources
+		require.True(t, errors.As(err, &owns))
+		require.Len(t, owns.Resources, 1)
+		require.Equal(t, OwnedResourceClientApplication, owns.Resources[0].Kind)
+		require.Equal(t, app.ID.String(), owns.Resources[0].ID)
+	})
+
+	t.Run("target must be a member of the tenant", func(t *testing.T) {
+		err := transferInTx(t, store, tenantID, leavingID, OwnershipTransfer{ToUserID: outsiderID})
+		require.ErrorIs(t, err, ErrInvalidTransferTarget)
+		err = transferInTx(t, store, tenantID, leavingID, OwnershipTransfer{ToUserID: leavingID})
+		require.ErrorIs(t, err, ErrInvalidTransferTarget)
+	})
+
+	t.Run("transfer to an explicit user", func(t *testing.T) {
+		require.NoError(t, transferInTx(t, store, tenantID, leavingID, OwnershipTransfer{ToUserID: colleagueID}))
+		updated, err := store.GetClientApplicationByID(ctx, repository.GetClientApplicationByIDParams{
+			ID:       app.ID,
+			TenantID: pgtype.Text{String: tenantID, Valid: true},
+		})
+		require.NoError(t, err)
+		require.Equal(t, colleagueID, updated.CreatedBy)
+
+		timeline, err := store.ListUserTimeline(ctx, repository.ListUserTimelineParams{
+			TenantID:   tenantID,
+			UserID:     colleagueID,
+			Categories: []string{ActivityCategoryAccount},
+			Limit:      10,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, timeline)
+		require.Equal(t, activityOwnershipTransferredIn, timeline[0].EventType)
+
+		// Nothing left to transfer
+		require.NoError(t, transferInTx(t, store, tenantID, leavingID, OwnershipTransfer{}))
+	})
+
+	t.Run("transfer to the tenant owner", func(t *testing.T) {
+		require.NoError(t, transferInTx(t, store, tenantID, colleagueID, OwnershipTransfer{ToTenantOwner: true}))
+		updated, err := store.GetClientApplicationByID(ctx, repository.GetClientApplicationByIDParams{
+			ID:       app.ID,
+			TenantID: pgtype.Text{String: tenantID, Valid: true},
+		})
+		require.NoError(t, err)
+		require.Equal(t, ownerID, updated.CreatedBy)
+	})
+}
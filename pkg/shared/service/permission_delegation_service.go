@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxDelegationPeriod bounds how long a delegation lasts
+const MaxDelegationPeriod = 90 * 24 * time.Hour
+
+// Statuses of a delegation, derived from its period and revocation
+const (
+	DelegationStatusScheduled = "SCHEDULED"
+	DelegationStatusActive    = "ACTIVE"
+	DelegationStatusExpired   = "EXPIRED"
+	DelegationStatusRevoked   = "REVOKED"
+)
+
+var (
+	// ErrInvalidDelegation is wrapped by every validation error of a delegation
+	ErrInvalidDelegation = errors.New("invalid delegation")
+	// ErrDelegationNotOwned is returned when revoking the delegation of other
+	// users without being allowed to manage delegations
+	ErrDelegationNotOwned = errors.New("only the delegator, the delegate or a tenant admin can revoke a delegation")
+)
+
+// DelegationInput is a delegation to create. A zero StartsAt starts it now.
+type DelegationInput struct {
+	DelegateID string
+	Operations []string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	Reason     string
+}
+
+// PermissionDelegationService lets users lend a subset of their operations to
+// another member of the tenant for a period, such as during a vacation. It is
+// the auth.DelegationSource of the authorizer: a delegation grants the
+// delegated operations the roles of the delegator allowed when it was
+// created, and expires at its end without any cleanup job.
+type PermissionDelegationService struct {
+	store *db.Store
+}
+
+func NewPermissionDelegationService(store *db.Store) *PermissionDelegationService {
+	return &PermissionDelegationService{store: store}
+}
+
+// DelegationStatus tells whether the delegation is honored at the time
+func DelegationStatus(delegation repository.CorePermissionDelegation, now time.Time) string {
+	switch {
+	case delegation.RevokedAt.Valid:
+		return DelegationStatusRevoked
+	case !now.Before(delegation.EndsAt):
+		return DelegationStatusExpired
+	case now.Before(delegation.StartsAt):
+		return DelegationStatusScheduled
+	default:
+		return DelegationStatusActive
+	}
+}
+
+// CreateDelegation delegates operations of the caller, in the request tenant,
+// to the delegate. The caller must be allowed each operation by their own
+// roles: delegated operations cannot be delegated again, nor can the
+// management of delegations.
+func (s *PermissionDelegationService) CreateDelegation(c *gin.Context, input DelegationInput) (repository.CorePermissionDelegation, error) {
+	logger := util.GetLoggerFromCtx(c)
+	subject := auth.SubjectFromContext(c)
+	if subject.TenantID == "" || subject.UserID == "" {
+		return repository.CorePermissionDelegation{}, fmt.Errorf("%w: delegations are made from a tenant", ErrInvalidDelegation)
+	}
+	input.DelegateID = strings.TrimSpace(input.DelegateID)
+	if input.DelegateID == "" || input.DelegateID == subject.UserID {
+		return repository.CorePermissionDelegation{}, fmt.Errorf("%w: the delegate must be another user", ErrInvalidDelegation)
+	}
+
+	now := time.Now()
+	if input.StartsAt.IsZero() {
+		input.StartsAt = now
+	}
+	switch {
+	case !input.EndsAt.After(input.StartsAt) || !input.EndsAt.After(now):
+		return repository.CorePermissionDelegation{}, fmt.Errorf("%w: the delegation must end in the future, after it starts", ErrInvalidDelegation)
+	case input.EndsAt.Sub(input.StartsAt) > MaxDelegationPeriod:
+		return repository.CorePermissionDelegation{}, fmt.Errorf("%w: a delegation lasts at most %d days", ErrInvalidDelegation, int(MaxDelegationPeriod.Hours()/24))
+	}
+
+	operations := slices.Compact(slices.Sorted(slices.Values(input.Operations)))
+	if len(operations) == 0 {
+		return repository.CorePermissionDelegation{}, fmt.Errorf("%w: no operation to delegate", ErrInvalidDelegation)
+	}
+	for _, op := range operations {
+		if auth.Operation(op) == auth.OpManageDelegations {
+			return repository.CorePermissionDelegation{}, fmt.Errorf("%w: %s cannot be delegated", ErrInvalidDelegation, op)
+		}
+		decision := auth.DefaultAuthorizer().Evaluate(subject, auth.Operation(op))
+		if !decision.Matched || !decision.Allowed {
+			return repository.CorePermissionDelegation{}, fmt.Errorf("%w: %s is not granted to you", ErrInvalidDelegation, op)
+		}
+	}
+
+	membership, err := s.store.GetSharedUserTenantMembership(c, repository.GetSharedUserTenantMembershipParams{
+		UserID:   input.DelegateID,
+		TenantID: subject.TenantID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repository.CorePermissionDelegation{}, fmt.Errorf("service.CreateDelegation: %w", err)
+	}
+	if err != nil || membership.Status != "active" {
+		return repository.CorePermissionDelegation{}, fmt.Errorf("%w: the delegate is not an active member of the tenant", ErrInvalidDelegation)
+	}
+
+	delegation, err := s.store.CreatePermissionDelegation(c, repository.CreatePermissionDelegationParams{
+		TenantID:       subject.TenantID,
+		DelegatorID:    subject.UserID,
+		DelegateID:     input.DelegateID,
+		Operations:     operations,
+		DelegatorRoles: append([]string{}, subject.Roles...),
+		Reason:         pgtype.Text{String: input.Reason, Valid: input.Reason != ""},
+		StartsAt:       input.StartsAt,
+		EndsAt:         input.EndsAt,
+	})
+	if err != nil {
+		logger.Err(err).Str("delegateID", input.DelegateID).Msg("Failed to create delegation")
+		return repository.CorePermissionDelegation{}, fmt.Errorf("service.CreateDelegation: %w", err)
+	}
+
+	s.recordDelegationActivity(c, delegation, "delegation_granted", "delegation_received", subject.UserID)
+	logger.Info().Str("delegationID", delegation.ID.String()).Str("delegateID", delegation.DelegateID).
+		Strs("operations", delegation.Operations).Time("endsAt", delegation.EndsAt).Msg("Delegation created")
+	return delegation, nil
+}
+
+// ListDelegations lists the delegations given or received by the user, or
+// every delegation of the tenant with allUsers
+func (s *PermissionDelegationService) ListDelegations(ctx context.Context, tenantID, userID string, allUsers, includeExpired bool) ([]repository.CorePermissionDelegation, error) {
+	delegations, err := s.store.ListPermissionDelegations(ctx, repository.ListPermissionDelegationsParams{
+		TenantID:       tenantID,
+		AllUsers:       allUsers,
+		UserID:         userID,
+		IncludeExpired: includeExpired,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListDelegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// RevokeDelegation ends a delegation of the request tenant right away. The
+// delegator and the delegate can revoke it, tenant admins any delegation.
+func (s *PermissionDelegationService) RevokeDelegation(c *gin.Context, id uuid.UUID) (repository.CorePermissionDelegation, error) {
+	subject := auth.SubjectFromContext(c)
+	delegation, err := s.store.GetPermissionDelegationByID(c, repository.GetPermissionDelegationByIDParams{
+		ID:       id,
+		TenantID: subject.TenantID,
+	})
+	if err != nil {
+		return repository.CorePermissionDelegation{}, err
+	}
+	// Evaluated on the roles of the caller only, a delegate cannot revoke the
+	// delegations of others
+	if subject.UserID != delegation.DelegatorID && subject.UserID != delegation.DelegateID &&
+		!auth.DefaultAuthorizer().Evaluate(subject, auth.OpManageDelegations).Allowed {
+		return repository.CorePermissionDelegation{}, ErrDelegationNotOwned
+	}
+
+	revoked, err := s.store.RevokePermissionDelegation(c, repository.RevokePermissionDelegationParams{
+		ID:        id,
+		TenantID:  subject.TenantID,
+		RevokedBy: pgtype.Text{String: subject.UserID, Valid: true},
+	})
+	if err != nil {
+		return repository.CorePermissionDelegation{}, err
+	}
+	s.recordDelegationActivity(c, revoked, "delegation_revoked", "delegation_revoked", subject.UserID)
+	return revoked, nil
+}
+
+// ActiveDelegations implements auth.DelegationSource
+func (s *PermissionDelegationService) ActiveDelegations(ctx context.Context, tenantID, userID string) ([]auth.Delegation, error) {
+	rows, err := s.store.ListActivePermissionDelegations(ctx, repository.ListActivePermissionDelegationsParams{
+		TenantID:   tenantID,
+		DelegateID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ActiveDelegations: %w", err)
+	}
+	delegations := make([]auth.Delegation, len(rows))
+	for i, row := range rows {
+		operations := make([]auth.Operation, len(row.Operations))
+		for j, op := range row.Operations {
+			operations[j] = auth.Operation(op)
+		}
+		delegations[i] = auth.Delegation{
+			ID:             row.ID.String(),
+			DelegatorID:    row.DelegatorID,
+			DelegatorRoles: row.DelegatorRoles,
+			Operations:     operations,
+		}
+	}
+	return delegations, nil
+}
+
+// recordDelegationActivity puts the change on the timelines of both users
+func (s *PermissionDelegationService) recordDelegationActivity(ctx context.Context, delegation repository.CorePermissionDelegation,
+	delegatorEvent, delegateEvent, actorID string) {
+	data := map[string]interface{}{
+		"delegation_id": delegation.ID.String(),
+		"delegator_id":  delegation.DelegatorID,
+		"delegate_id":   delegation.DelegateID,
+		"operations":    delegation.Operations,
+		"starts_at":     delegation.StartsAt,
+		"ends_at":       delegation.EndsAt,
+	}
+	for userID, eventType := range map[string]string{
+		delegation.DelegatorID: delegatorEvent,
+		delegation.DelegateID:  delegateEvent,
+	} {
+		actor := actorID
+		if actor == userID {
+			actor = ""
+		}
+		// Failures are logged by recordUserActivity
+		_ = recordUserActivity(ctx, s.store, UserActivity{
+			TenantID:  delegation.TenantID,
+			UserID:    userID,
+			Category:  ActivityCategoryMembership,
+			EventType: eventType,
+			ActorID:   actor,
+			Data:      data,
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func delegationTestContext(userID, tenantID string, claims map[string]interface{}) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/delegations", nil)
+	c.Set(auth.AUTH_USER_ID, userID)
+	c.Set(auth.AUTH_TENANT_ID_KEY, tenantID)
+	c.Set(auth.AUTH_CLAIMS, claims)
+	return c
+}
+
+func TestPermissionDelegations(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewPermissionDelegationService(store)
+	tenantID := commontestutils.RandomString(10)
+	adminID := createTestTenantMember(t, store, tenantID)
+	delegateID := createTestTenantMember(t, store, tenantID)
+	outsiderID := createTestTenantMember(t, store, commontestutils.RandomString(10))
+
+	admin := delegationTestContext(adminID, tenantID, map[string]interface{}{auth.SubjectCustomerAdmin: true})
+	delegate := delegationTestContext(delegateID, tenantID, map[string]interface{}{})
+	validInput := func() DelegationInput {
+		return DelegationInput{
+			DelegateID: delegateID,
+			Operations: []string{string(auth.OpManageUsers)},
+			EndsAt:     time.Now().Add(7 * 24 * time.Hour),
+			Reason:     "vacation",
+		}
+	}
+
+	t.Run("invalid delegations are rejected", func(t *testing.T) {
+		input := validInput()
+		input.DelegateID = outsiderID
+		_, err := service.CreateDelegation(admin, input)
+		require.ErrorIs(t, err, ErrInvalidDelegation)
+
+		input = validInput()
+		input.EndsAt = time.Now().Add(MaxDelegationPeriod + time.Hour)
+		_, err = service.CreateDelegation(admin, input)
+		require.ErrorIs(t, err, ErrInvalidDelegation)
+
+		input = validInput()
+		input.Operations = []string{string(auth.OpManageDelegations)}
+		_, err = service.CreateDelegation(admin, input)
+		require.ErrorIs(t, err, ErrInvalidDelegation)
+
+		// The delegate holds no admin role to delegate
+		input = validInput()
+		input.DelegateID = adminID
+		_, err = service.CreateDelegation(delegate, input)
+		require.ErrorIs(t, err, ErrInvalidDelegation)
+	})
+
+	delegation, err := service.CreateDelegation(admin, validInput())
+	require.NoError(t, err)
+	require.Equal(t, []string{auth.SubjectCustomerAdmin}, delegation.DelegatorRoles)
+	require.Equal(t, DelegationStatusActive, DelegationStatus(delegation, time.Now()))
+
+	t.Run("an active delegation grants its operations", func(t *testing.T) {
+		delegations, err := service.ActiveDelegations(admin, tenantID, delegateID)
+		require.NoError(t, err)
+		require.Len(t, delegations, 1)
+
+		subject := auth.SubjectFromContext(delegate)
+		granted, ok := auth.DefaultAuthorizer().EvaluateDelegations(subject, auth.OpManageUsers, delegations)
+		require.True(t, ok)
+		require.Equal(t, adminID, granted.DelegatorID)
+		_, ok = auth.DefaultAuthorizer().EvaluateDelegations(subject, auth.OpManageAnnouncements, delegations)
+		require.False(t, ok)
+
+		delegations, err = service.ActiveDelegations(admin, commontestutils.RandomString(10), delegateID)
+		require.NoError(t, err)
+		require.Empty(t, delegations)
+	})
+
+	t.Run("both users see the delegation", func(t *testing.T) {
+		for _, userID := range []string{adminID, delegateID} {
+			delegations, err := service.ListDelegations(admin, tenantID, userID, false, false)
+			require.NoError(t, err)
+			require.Len(t, delegations, 1)
+		}
+	})
+
+	t.Run("only the parties or an admin revoke it", func(t *testing.T) {
+		outsider := delegationTestContext(outsiderID, tenantID, map[string]interface{}{})
+		_, err := service.RevokeDelegation(outsider, delegation.ID)
+		require.ErrorIs(t, err, ErrDelegationNotOwned)
+
+		revoked, err := service.RevokeDelegation(delegate, delegation.ID)
+		require.NoError(t, err)
+		require.Equal(t, DelegationStatusRevoked, DelegationStatus(revoked, time.Now()))
+
+		_, err = service.RevokeDelegation(admin, delegation.ID)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		delegations, err := service.ActiveDelegations(admin, tenantID, delegateID)
+		require.NoError(t, err)
+		require.Empty(t, delegations)
+	})
+
+	t.Run("a scheduled delegation is not active yet", func(t *testing.T) {
+		input := validInput()
+		input.StartsAt = time.Now().Add(24 * time.Hour)
+		scheduled, err := service.CreateDelegation(admin, input)
+		require.NoError(t, err)
+		require.Equal(t, DelegationStatusScheduled, DelegationStatus(scheduled, time.Now()))
+
+		delegations, err := service.ActiveDelegations(admin, tenantID, delegateID)
+		require.NoError(t, err)
+		require.Empty(t, delegations)
+	})
+}
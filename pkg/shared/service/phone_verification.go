@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	PhoneVerificationCodeTTL = 10 * time.Minute
+
+	phoneVerificationCodeDigits = 6
+	// A code is checked at most maxPhoneVerificationAttempts times
+	maxPhoneVerificationAttempts = 5
+	// At most maxPhoneVerificationRequests codes per user in
+	// phoneVerificationWindow, each one is an SMS paid for
+	maxPhoneVerificationRequests = 5
+	phoneVerificationWindow      = time.Hour
+)
+
+var (
+	ErrInvalidPhoneNumber = errors.New("invalid phone number, use the E.164 format such as +14155550123")
+	// ErrPhoneVerificationNotFound is returned when the user has no code to
+	// check: none was sent, it expired or it ran out of attempts
+	ErrPhoneVerificationNotFound    = errors.New("no pending phone verification, request a new code")
+	ErrInvalidPhoneVerificationCode = errors.New("invalid verification code")
+	ErrPhoneVerificationRateLimited = errors.New("too many verification codes requested, try again later")
+)
+
+// PhoneVerificationService confirms the phone number of a user with a code
+// sent by SMS. The confirmed number is stored in the profile with
+// phoneNumberVerified, which a later change of the number clears.
+type PhoneVerificationService struct {
+	store *db.Store
+	sms   SMSProvider
+}
+
+func NewPhoneVerificationService(store *db.Store, sms SMSProvider) *PhoneVerificationService {
+	return &PhoneVerificationService{store: store, sms: sms}
+}
+
+// RequestPhoneVerification sends a code to the number. It replaces the
+// pending code of the user.
+func (s *PhoneVerificationService) RequestPhoneVerification(ctx context.Context, userID, phoneNumber string) (repository.CoreUserPhoneVerification, error) {
+	phoneNumber, err := NormalizePhoneNumber(phoneNumber)
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, err
+	}
+	recent, err := s.store.CountRecentUserPhoneVerifications(ctx, repository.CountRecentUserPhoneVerificationsParams{
+		UserID: userID,
+		Since:  time.Now().Add(-phoneVerificationWindow),
+	})
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.RequestPhoneVerification: %w", err)
+	}
+	if recent >= maxPhoneVerificationRequests {
+		return repository.CoreUserPhoneVerification{}, ErrPhoneVerificationRateLimited
+	}
+
+	code, err := newPhoneVerificationCode()
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.RequestPhoneVerification: %w", err)
+	}
+	if err := s.store.DeletePendingUserPhoneVerifications(ctx, userID); err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.RequestPhoneVerification: %w", err)
+	}
+	verification, err := s.store.CreateUserPhoneVerification(ctx, repository.CreateUserPhoneVerificationParams{
+		UserID:      userID,
+		PhoneNumber: phoneNumber,
+		CodeHash:    phoneVerificationCodeHash(userID, phoneNumber, code),
+		ExpiresAt:   time.Now().Add(PhoneVerificationCodeTTL),
+	})
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.RequestPhoneVerification: %w", err)
+	}
+
+	body := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(PhoneVerificationCodeTTL.Minutes()))
+	if err := s.sms.SendSMS(ctx, phoneNumber, body); err != nil {
+		// A code that never arrived does not count against the user
+		if deleteErr := s.store.DeletePendingUserPhoneVerifications(context.WithoutCancel(ctx), userID); deleteErr != nil {
+			logger := util.GetLoggerFromCtx(ctx)
+			logger.Err(deleteErr).Str("user_id", userID).Msg("Failed to delete unsent phone verification")
+		}
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.RequestPhoneVerification: %w", err)
+	}
+	return verification, nil
+}
+
+// ConfirmPhoneVerification checks the code sent to the user. The number is
+// then stored in the profile, verified.
+func (s *PhoneVerificationService) ConfirmPhoneVerification(ctx context.Context, tenantID, userID, code string) (repository.CoreUserPhoneVerification, error) {
+	code = strings.TrimSpace(code)
+	verification, err := s.store.GetPendingUserPhoneVerification(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserPhoneVerification{}, ErrPhoneVerificationNotFound
+	}
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.ConfirmPhoneVerification: %w", err)
+	}
+	verification, err = s.store.AttemptUserPhoneVerification(ctx, repository.AttemptUserPhoneVerificationParams{
+		ID:          verification.ID,
+		MaxAttempts: maxPhoneVerificationAttempts,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserPhoneVerification{}, ErrPhoneVerificationNotFound
+	}
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.ConfirmPhoneVerification: %w", err)
+	}
+	if !hmac.Equal(phoneVerificationCodeHash(userID, verification.PhoneNumber, code), verification.CodeHash) {
+		return repository.CoreUserPhoneVerification{}, ErrInvalidPhoneVerificationCode
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.ConfirmPhoneVerification: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	verification, err = qtx.MarkUserPhoneVerificationVerified(ctx, verification.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserPhoneVerification{}, ErrPhoneVerificationNotFound
+	}
+	if err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.ConfirmPhoneVerification: %w", err)
+	}
+	if _, err := qtx.SetUserPhoneNumberVerified(ctx, repository.SetUserPhoneNumberVerifiedParams{
+		PhoneNumber: verification.PhoneNumber,
+		ID:          userID,
+	}); err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.ConfirmPhoneVerification: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return repository.CoreUserPhoneVerification{}, fmt.Errorf("service.ConfirmPhoneVerification: %w", err)
+	}
+
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryAccount,
+		EventType: "phone_number_verified",
+		Data:      map[string]interface{}{"phone_verification_id": verification.ID.String()},
+	}); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", userID).Msg("Failed to record phone verification on the timeline")
+	}
+	return verification, nil
+}
+
+// NormalizePhoneNumber returns a number in the E.164 format: a + then 8 to 15
+// digits, the first one not 0. Spaces, dots, dashes and parentheses are
+// ignored, and a leading 00 is read as +.
+func NormalizePhoneNumber(number string) (string, error) {
+	number = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, number)
+	if strings.HasPrefix(number, "00") {
+		number = "+" + number[2:]
+	}
+	digits, ok := strings.CutPrefix(number, "+")
+	if !ok || len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidPhoneNumber
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+	return number, nil
+}
+
+func newPhoneVerificationCode() (string, error) {
+	limit := big.NewInt(1)
+	for range phoneVerificationCodeDigits {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", phoneVerificationCodeDigits, n), nil
+}
+
+// phoneVerificationCodeHash binds the code to the user and the number, so the
+// hash of a short code is not reused across rows
+func phoneVerificationCodeHash(userID, phoneNumber, code string) []byte {
+	hash := sha256.Sum256([]byte(userID + ":" + phoneNumber + ":" + code))
+	return hash[:]
+}
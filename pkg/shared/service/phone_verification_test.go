@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	for input, expected := range map[string]string{
+		"+14155550123":       "+14155550123",
+		"+1 (415) 555-0123":  "+14155550123",
+		"0033 6 12 34 56 78": "+33612345678",
+		"+33.6.12.34.56.78":  "+33612345678",
+	} {
+		number, err := NormalizePhoneNumber(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, number)
+	}
+
+	for _, input := range []string{"", "4155550123", "+0612345678", "+1415", "+1415555012345678", "+1415555O123"} {
+		_, err := NormalizePhoneNumber(input)
+		require.ErrorIs(t, err, ErrInvalidPhoneNumber, input)
+	}
+}
+
+func TestNewPhoneVerificationCode(t *testing.T) {
+	code, err := newPhoneVerificationCode()
+	require.NoError(t, err)
+	require.Regexp(t, "^[0-9]{6}$", code)
+
+	// The hash is bound to the user and the number
+	hash := phoneVerificationCodeHash("user-1", "+14155550123", code)
+	require.Equal(t, hash, phoneVerificationCodeHash("user-1", "+14155550123", code))
+	require.NotEqual(t, hash, phoneVerificationCodeHash("user-2", "+14155550123", code))
+	require.NotEqual(t, hash, phoneVerificationCodeHash("user-1", "+14155550124", code))
+}
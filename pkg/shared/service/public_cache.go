@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultPublicCacheTTL bounds how long a change takes to show on the public
+// endpoints of the other instances, which are not told about invalidations
+const DefaultPublicCacheTTL = 30 * time.Second
+
+type publicCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// PublicCache is a read-through cache for the data served by the public
+// endpoints (tenant bootstrap, branding, picture headers), which are requested
+// on every page load. Concurrent misses of a key share one load, failed loads
+// are not kept, and the write paths call Invalidate so the instance that made
+// the change serves it right away.
+//
+// A cached value is shared by every caller and must not be modified.
+type PublicCache[V any] struct {
+	ttl       time.Duration
+	mu        sync.RWMutex
+	entries   map[string]publicCacheEntry[V]
+	sf        singleflight.Group
+	lastSweep time.Time
+}
+
+// NewPublicCache returns a cache keeping the values for ttl, unless the loader
+// of GetWithTTL says otherwise
+func NewPublicCache[V any](ttl time.Duration) *PublicCache[V] {
+	if ttl <= 0 {
+		ttl = DefaultPublicCacheTTL
+	}
+	return &PublicCache[V]{
+		ttl:       ttl,
+		entries:   make(map[string]publicCacheEntry[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the cached value of the key, or loads and keeps it
+func (c *PublicCache[V]) Get(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	return c.GetWithTTL(ctx, key, func(ctx context.Context) (V, time.Duration, error) {
+		value, err := load(ctx)
+		return value, c.ttl, err
+	})
+}
+
+// GetWithTTL is Get for values that know how long they stay valid, such as a
+// list holding an item about to expire. The TTL returned by the loader is
+// capped by the one of the cache; zero or less serves the value uncached.
+func (c *PublicCache[V]) GetWithTTL(ctx context.Context, key string, load func(ctx context.Context) (V, time.Duration, error)) (V, error) {
+	if value, ok := c.lookup(key); ok {
+		return value, nil
+	}
+	result, err, _ := c.sf.Do(key, func() (any, error) {
+		// Another caller may have filled the key while this one waited
+		if value, ok := c.lookup(key); ok {
+			return value, nil
+		}
+		value, ttl, err := load(ctx)
+		if err != nil {
+			return value, err
+		}
+		if ttl > 0 {
+			c.put(key, value, min(ttl, c.ttl))
+		}
+		return value, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return result.(V), nil
+}
+
+// Invalidate drops the keys, the next reads load them again
+func (c *PublicCache[V]) Invalidate(keys ...string) {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+}
+
+// InvalidateAll drops every key, for changes that apply to all of them
+func (c *PublicCache[V]) InvalidateAll() {
+	c.mu.Lock()
+	c.entries = make(map[string]publicCacheEntry[V])
+	c.mu.Unlock()
+}
+
+func (c *PublicCache[V]) lookup(key string) (V, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// put keeps the value and, once per TTL, drops the expired entries so keys
+// that are no longer read (deleted tenants, unknown subdomains) do not pile up
+func (c *PublicCache[V]) put(key string, value V, ttl time.Duration) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = publicCacheEntry[V]{value: value, expiresAt: now.Add(ttl)}
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicCacheReadThrough(t *testing.T) {
+	ctx := context.Background()
+	cache := NewPublicCache[string](time.Minute)
+	var loads atomic.Int32
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		return "value", nil
+	}
+
+	// concurrent misses share one load
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.Get(ctx, "tenant-1", load)
+			require.NoError(t, err)
+			require.Equal(t, "value", value)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, loads.Load())
+
+	cache.Invalidate("tenant-1")
+	_, err := cache.Get(ctx, "tenant-1", load)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, loads.Load())
+
+	cache.InvalidateAll()
+	_, err = cache.Get(ctx, "tenant-1", load)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, loads.Load())
+}
+
+func TestPublicCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := NewPublicCache[int](time.Minute)
+	loads := 0
+	loadFor := func(ttl time.Duration) func(context.Context) (int, time.Duration, error) {
+		return func(context.Context) (int, time.Duration, error) {
+			loads++
+			return loads, ttl, nil
+		}
+	}
+
+	// a value without a TTL is served uncached
+	value, err := cache.GetWithTTL(ctx, "key", loadFor(0))
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+	value, err = cache.GetWithTTL(ctx, "key", loadFor(time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, 2, value)
+
+	// the value expires with its own TTL
+	time.Sleep(5 * time.Millisecond)
+	value, err = cache.GetWithTTL(ctx, "key", loadFor(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+	value, err = cache.GetWithTTL(ctx, "key", loadFor(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+}
+
+func TestPublicCacheKeepsNoError(t *testing.T) {
+	ctx := context.Background()
+	cache := NewPublicCache[string](time.Minute)
+	failure := errors.New("database down")
+
+	_, err := cache.Get(ctx, "key", func(context.Context) (string, error) { return "", failure })
+	require.ErrorIs(t, err, failure)
+
+	value, err := cache.Get(ctx, "key", func(context.Context) (string, error) { return "value", nil })
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+}
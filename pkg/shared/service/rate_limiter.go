@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateWindow is a budget of Limit requests per Period, refilled continuously
+type RateWindow struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimiter consumes one request of a key from every window. A request is
+// only counted when every window has room for it; when it is not allowed,
+// retryAfter tells how long until it would be.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, windows ...RateWindow) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// NewRateLimiterFromEnv returns a Redis backed limiter when REDIS_URL is set,
+// so limits are shared across instances, and an in-memory one otherwise.
+func NewRateLimiterFromEnv() RateLimiter {
+	if client := SharedRedisClient(); client != nil {
+		return NewRedisRateLimiter(client)
+	}
+	return NewMemoryRateLimiter()
+}
+
+// rateLimitPruneInterval is how often idle buckets are dropped
+const rateLimitPruneInterval = time.Minute
+
+type bucketKey struct {
+	key    string
+	period time.Duration
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill tops up the bucket for the elapsed time, capped at the limit
+func (b *tokenBucket) refill(now time.Time, w RateWindow) {
+	rate := float64(w.Limit) / float64(w.Period)
+	b.tokens = math.Min(float64(w.Limit), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+}
+
+// MemoryRateLimiter keeps one token bucket per key and window in process.
+// A bucket idle for its whole period is full again and is dropped.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[bucketKey]*tokenBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: make(map[bucketKey]*tokenBucket), lastPrune: time.Now(), now: time.Now}
+}
+
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string, windows ...RateWindow) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.prune(now)
+
+	buckets := make([]*tokenBucket, 0, len(windows))
+	var retryAfter time.Duration
+	for _, w := range windows {
+		if w.Limit <= 0 {
+			continue
+		}
+		b := l.bucket(key, w, now)
+		if b.tokens < 1 {
+			rate := float64(w.Limit) / float64(w.Period)
+			retryAfter = max(retryAfter, time.Duration(math.Ceil((1-b.tokens)/rate)))
+		}
+		buckets = append(buckets, b)
+	}
+	// Only consume when every window has room, so a request rejected by the
+	// hourly budget does not eat into the per-minute one.
+	if retryAfter > 0 {
+		return false, retryAfter, nil
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0, nil
+}
+
+// Remaining returns the whole requests left to the key in the window
+func (l *MemoryRateLimiter) Remaining(key string, w RateWindow) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[bucketKey{key, w.Period}]
+	if !ok {
+		return w.Limit
+	}
+	b.refill(now, w)
+	return int(b.tokens)
+}
+
+func (l *MemoryRateLimiter) bucket(key string, w RateWindow, now time.Time) *tokenBucket {
+	k := bucketKey{key, w.Period}
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &tokenBucket{tokens: float64(w.Limit), last: now}
+		l.buckets[k] = b
+	}
+	b.refill(now, w)
+	return b
+}
+
+// prune drops the buckets unused for their whole period, they are full again
+func (l *MemoryRateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.last) >= k.period {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastPrune = now
+}
+
+// tokenBucketScript checks then consumes one token in every bucket passed as
+// KEYS, with limit/period(ms) pairs in ARGV after the current time in ms.
+// It returns {allowed, retryAfterMs}.
+var tokenBucketScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local tokens = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+  local capacity = tonumber(ARGV[i * 2])
+  local window = tonumber(ARGV[i * 2 + 1])
+  local rate = capacity / window
+  local b = redis.call('HMGET', key, 'tokens', 'ts')
+  local t = tonumber(b[1]) or capacity
+  local ts = tonumber(b[2]) or now
+  t = math.min(capacity, t + (now - ts) * rate)
+  tokens[i] = t
+  if t < 1 then
+    wait = math.max(wait, math.ceil((1 - t) / rate))
+  end
+end
+local allowed = 0
+if wait == 0 then allowed = 1 end
+for i, key in ipairs(KEYS) do
+  local t = tokens[i]
+  if allowed == 1 then t = t - 1 end
+  redis.call('HSET', key, 'tokens', t, 'ts', now)
+  redis.call('PEXPIRE', key, tonumber(ARGV[i * 2 + 1]))
+end
+return {allowed, wait}
+`)
+
+// RedisRateLimiter shares the token buckets across instances through Redis.
+// The buckets expire after their period, when they would be full again.
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client}
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, windows ...RateWindow) (bool, time.Duration, error) {
+	keys := []string{}
+	args := []interface{}{time.Now().UnixMilli()}
+	for _, w := range windows {
+		if w.Limit <= 0 {
+			continue
+		}
+		keys = append(keys, "rate_limit:"+key+":"+w.Period.String())
+		args = append(args, w.Limit, w.Period.Milliseconds())
+	}
+	if len(keys) == 0 {
+		return true, 0, nil
+	}
+	res, err := tokenBucketScript.Run(ctx, l.client, keys, args...).Int64Slice()
+	if err != nil {
+		return true, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
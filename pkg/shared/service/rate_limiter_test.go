@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMemoryRateLimiter returns a limiter on a clock the test moves
+func newTestMemoryRateLimiter() (*MemoryRateLimiter, *time.Time) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	l := NewMemoryRateLimiter()
+	l.lastPrune = now
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestMemoryRateLimiterConsumesBucket(t *testing.T) {
+	l, now := newTestMemoryRateLimiter()
+	ctx := context.Background()
+	perMinute := RateWindow{Limit: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		allowed, _, err := l.Allow(ctx, "token-1", perMinute)
+		require.NoError(t, err)
+		require.True(t, allowed, "request %d", i)
+	}
+	require.Zero(t, l.Remaining("token-1", perMinute))
+
+	allowed, retryAfter, err := l.Allow(ctx, "token-1", perMinute)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	// One request every 20 seconds
+	require.Equal(t, 20*time.Second, retryAfter)
+
+	// Another key has its own bucket
+	allowed, _, _ = l.Allow(ctx, "token-2", perMinute)
+	require.True(t, allowed)
+
+	*now = now.Add(10 * time.Second)
+	allowed, retryAfter, _ = l.Allow(ctx, "token-1", perMinute)
+	require.False(t, allowed)
+	require.Equal(t, 10*time.Second, retryAfter)
+
+	*now = now.Add(retryAfter)
+	allowed, _, _ = l.Allow(ctx, "token-1", perMinute)
+	require.True(t, allowed)
+}
+
+func TestMemoryRateLimiterRejectionConsumesNothing(t *testing.T) {
+	l, now := newTestMemoryRateLimiter()
+	ctx := context.Background()
+	limit := TokenRateLimit{PerMinute: 5, PerHour: 2}
+	perMinute, perHour := limit.Windows()[0], limit.Windows()[1]
+
+	for i := 0; i < 2; i++ {
+		allowed, _, _ := l.Allow(ctx, "token-1", limit.Windows()...)
+		require.True(t, allowed)
+	}
+	for i := 0; i < 3; i++ {
+		allowed, retryAfter, err := l.Allow(ctx, "token-1", limit.Windows()...)
+		require.NoError(t, err)
+		require.False(t, allowed)
+		// The hourly budget refills one request every 30 minutes
+		require.Equal(t, 30*time.Minute, retryAfter)
+	}
+	// The rejected requests did not eat into the per-minute budget
+	require.Equal(t, 3, l.Remaining("token-1", perMinute))
+	require.Zero(t, l.Remaining("token-1", perHour))
+
+	// Nor into the hourly one when the minute is the exhausted window
+	*now = now.Add(time.Hour)
+	tight := []RateWindow{{Limit: 1, Period: time.Minute}, {Limit: 10, Period: time.Hour}}
+	allowed, _, _ := l.Allow(ctx, "token-2", tight...)
+	require.True(t, allowed)
+	allowed, retryAfter, _ := l.Allow(ctx, "token-2", tight...)
+	require.False(t, allowed)
+	require.Equal(t, time.Minute, retryAfter)
+	require.Equal(t, 9, l.Remaining("token-2", tight[1]))
+}
+
+func TestMemoryRateLimiterUnlimited(t *testing.T) {
+	l, _ := newTestMemoryRateLimiter()
+	for i := 0; i < 100; i++ {
+		allowed, _, err := l.Allow(context.Background(), "token-1", TokenRateLimit{}.Windows()...)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+	require.Empty(t, l.buckets)
+}
+
+func TestMemoryRateLimiterEvictsIdleBuckets(t *testing.T) {
+	l, now := newTestMemoryRateLimiter()
+	ctx := context.Background()
+	perMinute := RateWindow{Limit: 1, Period: time.Minute}
+	perHour := RateWindow{Limit: 1, Period: time.Hour}
+
+	_, _, _ = l.Allow(ctx, "idle", perMinute)
+	_, _, _ = l.Allow(ctx, "hourly", perHour)
+	require.Len(t, l.buckets, 2)
+
+	// The minute bucket is full again and dropped, the hourly one is kept
+	*now = now.Add(2 * time.Minute)
+	_, _, _ = l.Allow(ctx, "active", perMinute)
+	require.Len(t, l.buckets, 2)
+	require.NotContains(t, l.buckets, bucketKey{"idle", time.Minute})
+	require.Contains(t, l.buckets, bucketKey{"hourly", time.Hour})
+
+	// A dropped bucket starts full
+	allowed, _, _ := l.Allow(ctx, "idle", perMinute)
+	require.True(t, allowed)
+}
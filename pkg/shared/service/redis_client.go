@@ -0,0 +1,33 @@
+package service
+
+import (
+	"os"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	redisClientOnce sync.Once
+	redisClient     *redis.Client
+)
+
+// SharedRedisClient returns the Redis client of REDIS_URL, opened once and
+// shared by the rate limiter, the caches and the pub/sub of the process, or nil
+// when REDIS_URL is unset or invalid
+func SharedRedisClient() *redis.Client {
+	redisClientOnce.Do(func() {
+		redisURL := os.Getenv("REDIS_URL")
+		if redisURL == "" {
+			return
+		}
+		opts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.Err(err).Msg("Invalid REDIS_URL, falling back to in-memory rate limits, caches and pub/sub")
+			return
+		}
+		redisClient = redis.NewClient(opts)
+	})
+	return redisClient
+}
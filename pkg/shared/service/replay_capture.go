@@ -0,0 +1,543 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// TenantReplayCaptureConfig is the tenant config opting the tenant in to
+	// the replay capture, "true" or "false"
+	TenantReplayCaptureConfig = "replay_capture_enabled"
+
+	DefaultReplayBundleRetention  = 72 * time.Hour
+	DefaultReplayCaptureMinStatus = http.StatusInternalServerError
+	DefaultReplayCaptureMaxBody   = 64 << 10
+
+	DefaultReplayBundlesPage = 20
+	MaxReplayBundlesPage     = 100
+
+	// ReplayBundleVersion is bumped when the layout of the bundle changes, so
+	// the replay tool can refuse the bundles it does not understand
+	ReplayBundleVersion = 1
+
+	replayRedacted              = "[REDACTED]"
+	replayBundleCleanupInterval = time.Hour
+	replayBundleWriteTimeout    = 5 * time.Second
+	replayCaptureKey            = "replay_capture"
+)
+
+// replaySensitiveKeys are redacted from the headers, the query and the JSON
+// bodies when one of them is part of the key, case ignored
+var replaySensitiveKeys = []string{
+	"password", "secret", "token", "otp", "credential", "signature",
+	"authorization", "cookie", "apikey", "api_key", "api-key", "session",
+	"csrf", "recovery", "private",
+}
+
+// replaySensitiveNames are redacted when they are the whole key, they are
+// too short to be matched inside longer keys
+var replaySensitiveNames = []string{"code", "pin", "key"}
+
+// ReplayCaptureConfig configures the capture of failing requests.
+//
+// Environment:
+//   - REPLAY_BUNDLE_RETENTION: how long the bundles are kept (default 72h, 0 keeps them forever)
+//   - REPLAY_CAPTURE_MIN_STATUS: lowest response status captured (default 500, 400 to 599)
+//   - REPLAY_CAPTURE_MAX_BODY: bytes of the request and response bodies kept (default 65536)
+type ReplayCaptureConfig struct {
+	Retention    time.Duration
+	MinStatus    int
+	MaxBodyBytes int
+}
+
+func ReplayCaptureConfigFromEnv() ReplayCaptureConfig {
+	cfg := ReplayCaptureConfig{
+		Retention:    DefaultReplayBundleRetention,
+		MinStatus:    DefaultReplayCaptureMinStatus,
+		MaxBodyBytes: DefaultReplayCaptureMaxBody,
+	}
+	if v := os.Getenv("REPLAY_BUNDLE_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Retention = d
+		} else {
+			log.Warn().Str("REPLAY_BUNDLE_RETENTION", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("REPLAY_CAPTURE_MIN_STATUS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 400 && n <= 599 {
+			cfg.MinStatus = n
+		} else {
+			log.Warn().Str("REPLAY_CAPTURE_MIN_STATUS", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("REPLAY_CAPTURE_MAX_BODY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.MaxBodyBytes = n
+		} else {
+			log.Warn().Str("REPLAY_CAPTURE_MAX_BODY", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// ValidateTenantReplayCaptureConfig checks the value of the
+// replay_capture_enabled tenant config. Other configs are not checked.
+func ValidateTenantReplayCaptureConfig(name string, value *string) error {
+	if name != TenantReplayCaptureConfig || value == nil || *value == "" {
+		return nil
+	}
+	if _, err := strconv.ParseBool(strings.TrimSpace(*value)); err != nil {
+		return fmt.Errorf("%s: must be true or false", name)
+	}
+	return nil
+}
+
+// replayCaptureCache keeps whether each tenant opted in, keyed by tenant id
+var replayCaptureCache = NewPublicCache[bool](DefaultPublicCacheTTL)
+
+// InvalidateReplayCapture drops the cached opt-in of a tenant after a change
+// of its replay_capture_enabled config
+func InvalidateReplayCapture(tenantID string) {
+	replayCaptureCache.Invalidate(tenantID)
+}
+
+// ReplayRequest is the sanitized request of a bundle
+type ReplayRequest struct {
+	Method        string              `json:"method"`
+	Route         string              `json:"route"`
+	Path          string              `json:"path"`
+	Host          string              `json:"host"`
+	PathParams    map[string]string   `json:"pathParams,omitempty"`
+	Query         map[string][]string `json:"query,omitempty"`
+	Headers       map[string][]string `json:"headers"`
+	Body          json.RawMessage     `json:"body,omitempty"`
+	BodyOmitted   string              `json:"bodyOmitted,omitempty"`
+	BodyTruncated bool                `json:"bodyTruncated,omitempty"`
+}
+
+// ReplayContext is what the middlewares resolved for the request
+type ReplayContext struct {
+	TenantID       string   `json:"tenantId"`
+	UserID         string   `json:"userId,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+	ImpersonatorID string   `json:"impersonatorId,omitempty"`
+	APIToken       bool     `json:"apiToken,omitempty"`
+}
+
+// ReplayResponse is the failure the request ended with. Errors holds the
+// errors and warnings logged while serving it, with the gin errors.
+type ReplayResponse struct {
+	Status        int             `json:"status"`
+	DurationMs    int64           `json:"durationMs"`
+	Body          json.RawMessage `json:"body,omitempty"`
+	BodyOmitted   string          `json:"bodyOmitted,omitempty"`
+	BodyTruncated bool            `json:"bodyTruncated,omitempty"`
+	Errors        []string        `json:"errors,omitempty"`
+}
+
+// ReplayBundle is the full context of a failing request, enough for support
+// to understand it and for cmd/replay to send it again
+type ReplayBundle struct {
+	Version      int               `json:"version"`
+	RequestID    string            `json:"requestId"`
+	CapturedAt   time.Time         `json:"capturedAt"`
+	Request      ReplayRequest     `json:"request"`
+	Context      ReplayContext     `json:"context"`
+	Calls        []util.ReplayCall `json:"calls"`
+	DroppedCalls int               `json:"droppedCalls,omitempty"`
+	Response     ReplayResponse    `json:"response"`
+}
+
+// ReplayCaptureService stores the failing requests of the tenants that opted
+// in as replay bundles. Record starts recording once the tenant is resolved,
+// Capture stores the bundle when the request fails. Both must be installed,
+// see ServerConfig.
+type ReplayCaptureService struct {
+	store  *db.Store
+	config ReplayCaptureConfig
+}
+
+func NewReplayCaptureService(store *db.Store, config ReplayCaptureConfig) *ReplayCaptureService {
+	return &ReplayCaptureService{store: store, config: config}
+}
+
+// IsReplayCaptureEnabled reports whether the tenant opted in. A failed lookup
+// disables the capture, it must never fail the request.
+func (s *ReplayCaptureService) IsReplayCaptureEnabled(ctx context.Context, tenantID string) bool {
+	if tenantID == "" {
+		return false
+	}
+	enabled, err := replayCaptureCache.Get(ctx, tenantID, func(ctx context.Context) (bool, error) {
+		config, err := s.store.GetTenantConfigByName(ctx, repository.GetTenantConfigByNameParams{
+			Name:     TenantReplayCaptureConfig,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		enabled, _ := strconv.ParseBool(strings.TrimSpace(config.Value.String))
+		return enabled, nil
+	})
+	if err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Warn().Err(err).Str("tenantID", tenantID).Msg("Failed to read the replay capture config")
+		return false
+	}
+	return enabled
+}
+
+// replayCapture is the state of a recorded request, kept on the gin context
+type replayCapture struct {
+	recorder      *util.ReplayRecorder
+	writer        *replayResponseWriter
+	body          []byte
+	bodyTruncated bool
+	startedAt     time.Time
+}
+
+// Record starts recording the request when its tenant opted in: the body is
+// kept as the handler reads it, the downstream calls and the logged errors are
+// collected, and the response is kept if it fails. It runs with the API
+// middlewares, after the tenant middleware.
+func (s *ReplayCaptureService) Record() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.IsReplayCaptureEnabled(c.Request.Context(), c.GetString(auth.AUTH_TENANT_ID_KEY)) {
+			c.Next()
+			return
+		}
+		capture := &replayCapture{
+			recorder:  util.NewReplayRecorder(),
+			startedAt: time.Now(),
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(s.config.MaxBodyBytes)+1))
+			if err == nil {
+				capture.bodyTruncated = len(body) > s.config.MaxBodyBytes
+				capture.body = body[:min(len(body), s.config.MaxBodyBytes)]
+			}
+			c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body), Closer: c.Request.Body}
+		}
+
+		ctx := util.WithReplayRecorder(c.Request.Context(), capture.recorder)
+		logger := util.GetLoggerFromCtx(ctx).Hook(capture.recorder)
+		ctx = context.WithValue(ctx, util.LoggerKey, logger)
+		c.Request = c.Request.WithContext(ctx)
+
+		capture.writer = &replayResponseWriter{ResponseWriter: c.Writer, minStatus: s.config.MinStatus, max: s.config.MaxBodyBytes}
+		c.Writer = capture.writer
+		c.Set(replayCaptureKey, capture)
+		c.Next()
+	}
+}
+
+// Capture stores the bundle of the recorded requests that failed. It is
+// installed on the router, so it runs once the handler returned.
+func (s *ReplayCaptureService) Capture() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		value, ok := c.Get(replayCaptureKey)
+		if !ok {
+			return
+		}
+		capture := value.(*replayCapture)
+		if c.Writer.Status() < s.config.MinStatus {
+			return
+		}
+		bundle := s.buildBundle(c, capture)
+		// The client has its response, the bundle is written without it
+		ctx := context.WithoutCancel(c.Request.Context())
+		go s.storeBundle(ctx, bundle)
+	}
+}
+
+func (s *ReplayCaptureService) buildBundle(c *gin.Context, capture *replayCapture) ReplayBundle {
+	bundle := ReplayBundle{
+		Version:    ReplayBundleVersion,
+		RequestID:  c.GetString(string(util.RequestIDKey)),
+		CapturedAt: time.Now().UTC(),
+		Request: ReplayRequest{
+			Method:  c.Request.Method,
+			Route:   c.FullPath(),
+			Path:    c.Request.URL.Path,
+			Host:    c.Request.Host,
+			Query:   sanitizeReplayValues(c.Request.URL.Query()),
+			Headers: sanitizeReplayValues(url.Values(c.Request.Header)),
+		},
+		Context: ReplayContext{
+			TenantID:       c.GetString(auth.AUTH_TENANT_ID_KEY),
+			UserID:         c.GetString(auth.AUTH_USER_ID),
+			Roles:          replayRoles(c),
+			ImpersonatorID: c.GetString(auth.AUTH_IMPERSONATOR_ID),
+		},
+		Response: ReplayResponse{
+			Status:     c.Writer.Status(),
+			DurationMs: time.Since(capture.startedAt).Milliseconds(),
+		},
+	}
+	if bundle.Request.Route == "" {
+		bundle.Request.Route = bundle.Request.Path
+	}
+	if len(c.Params) > 0 {
+		bundle.Request.PathParams = map[string]string{}
+		for _, param := range c.Params {
+			bundle.Request.PathParams[param.Key] = param.Value
+		}
+	}
+	_, bundle.Context.APIToken = c.Get("api_token")
+
+	bundle.Request.Body, bundle.Request.BodyOmitted = sanitizeReplayBody(c.Request.Header.Get("Content-Type"), capture.body, capture.bodyTruncated)
+	bundle.Request.BodyTruncated = capture.bodyTruncated
+	bundle.Response.Body, bundle.Response.BodyOmitted = sanitizeReplayBody(c.Writer.Header().Get("Content-Type"), capture.writer.body.Bytes(), capture.writer.truncated)
+	bundle.Response.BodyTruncated = capture.writer.truncated
+
+	bundle.Calls, bundle.DroppedCalls = capture.recorder.Calls()
+	bundle.Response.Errors = capture.recorder.Logs()
+	for _, err := range c.Errors {
+		bundle.Response.Errors = append(bundle.Response.Errors, err.Error())
+	}
+	return bundle
+}
+
+func (s *ReplayCaptureService) storeBundle(ctx context.Context, bundle ReplayBundle) {
+	logger := util.GetLoggerFromCtx(ctx)
+	ctx, cancel := context.WithTimeout(ctx, replayBundleWriteTimeout)
+	defer cancel()
+	// The bundle is not recorded into itself
+	ctx = util.WithReplayRecorder(ctx, nil)
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		logger.Err(err).Msg("Failed to encode replay bundle")
+		return
+	}
+	id, err := s.store.CreateReplayBundle(ctx, repository.CreateReplayBundleParams{
+		TenantID:  bundle.Context.TenantID,
+		RequestID: bundle.RequestID,
+		UserID:    pgtype.Text{String: bundle.Context.UserID, Valid: bundle.Context.UserID != ""},
+		Method:    bundle.Request.Method,
+		Route:     bundle.Request.Route,
+		Status:    int32(bundle.Response.Status),
+		Bundle:    data,
+	})
+	if err != nil {
+		logger.Err(err).Msg("Failed to store replay bundle")
+		return
+	}
+	logger.Info().Str("replay_bundle_id", id.String()).Int("status", bundle.Response.Status).Msg("Replay bundle captured")
+}
+
+// ListReplayBundles lists the bundles, newest first. Empty filters match
+// every bundle.
+func (s *ReplayCaptureService) ListReplayBundles(ctx context.Context, tenantID, requestID string, limit, offset int32) ([]repository.ListReplayBundlesRow, error) {
+	if limit <= 0 {
+		limit = DefaultReplayBundlesPage
+	}
+	limit = min(limit, MaxReplayBundlesPage)
+	offset = max(offset, 0)
+	rows, err := s.store.ListReplayBundles(ctx, repository.ListReplayBundlesParams{
+		TenantID:  pgtype.Text{String: tenantID, Valid: tenantID != ""},
+		RequestID: pgtype.Text{String: requestID, Valid: requestID != ""},
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListReplayBundles: %w", err)
+	}
+	return rows, nil
+}
+
+// GetReplayBundle returns a bundle with its content
+func (s *ReplayCaptureService) GetReplayBundle(ctx context.Context, id uuid.UUID) (repository.CoreReplayBundle, error) {
+	row, err := s.store.GetReplayBundle(ctx, id)
+	if err != nil {
+		return repository.CoreReplayBundle{}, fmt.Errorf("service.GetReplayBundle: %w", err)
+	}
+	return row, nil
+}
+
+// StartReplayBundleCleanup deletes the bundles older than the retention
+func (s *ReplayCaptureService) StartReplayBundleCleanup(ctx context.Context) {
+	if s.config.Retention <= 0 {
+		log.Info().Msg("Replay bundle cleanup disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(replayBundleCleanupInterval)
+		defer ticker.Stop()
+		for {
+			deleted, err := s.store.DeleteReplayBundlesCreatedBefore(ctx, time.Now().Add(-s.config.Retention))
+			if err != nil {
+				log.Err(err).Msg("Replay bundle cleanup failed")
+			} else if deleted > 0 {
+				log.Info().Int64("bundles", deleted).Msg("Deleted expired replay bundles")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// replayRoles returns the roles of the caller, global and in the tenant
+func replayRoles(c *gin.Context) []string {
+	roles := []string{}
+	if claims, ok := c.Get(auth.AUTH_CLAIMS); ok {
+		if claims, ok := claims.(map[string]interface{}); ok {
+			for name, value := range claims {
+				// Roles are the upper case claims set to true
+				if value == true && name == strings.ToUpper(name) {
+					roles = append(roles, name)
+				}
+			}
+		}
+	}
+	if tenantRoles, err := auth.GetUserTenantRoles(c); err == nil {
+		roles = append(roles, tenantRoles...)
+	}
+	slices.Sort(roles)
+	return slices.Compact(roles)
+}
+
+func isReplaySensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if slices.Contains(replaySensitiveNames, key) {
+		return true
+	}
+	for _, part := range replaySensitiveKeys {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizeReplayValues(values url.Values) map[string][]string {
+	if len(values) == 0 {
+		return nil
+	}
+	sanitized := make(map[string][]string, len(values))
+	for key, vals := range values {
+		if isReplaySensitiveKey(key) {
+			sanitized[key] = []string{replayRedacted}
+			continue
+		}
+		sanitized[key] = slices.Clone(vals)
+	}
+	return sanitized
+}
+
+// sanitizeReplayBody returns a JSON or form body with its sensitive fields
+// redacted. Other bodies, such as uploads, are omitted with the reason.
+func sanitizeReplayBody(contentType string, body []byte, truncated bool) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if truncated {
+			return nil, "truncated JSON body"
+		}
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, "invalid JSON body"
+		}
+		data, err := json.Marshal(redactReplayJSON(value))
+		if err != nil {
+			return nil, "invalid JSON body"
+		}
+		return data, ""
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, "invalid form body"
+		}
+		data, _ := json.Marshal(sanitizeReplayValues(values))
+		return data, ""
+	default:
+		return nil, fmt.Sprintf("%d bytes of %s", len(body), mediaType)
+	}
+}
+
+func redactReplayJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isReplaySensitiveKey(key) {
+				v[key] = replayRedacted
+			} else {
+				v[key] = redactReplayJSON(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactReplayJSON(item)
+		}
+	}
+	return value
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// replayResponseWriter keeps the body of a failing response
+type replayResponseWriter struct {
+	gin.ResponseWriter
+	minStatus int
+	max       int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *replayResponseWriter) keep(data []byte) {
+	if w.Status() < w.minStatus {
+		return
+	}
+	room := w.max - w.body.Len()
+	if len(data) > room {
+		w.truncated = true
+		data = data[:max(room, 0)]
+	}
+	w.body.Write(data)
+}
+
+func (w *replayResponseWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *replayResponseWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
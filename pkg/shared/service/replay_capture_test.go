@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeReplayBody(t *testing.T) {
+	body := `{"email":"jane@example.com","password":"hunter2","profile":{"apiKey":"k1","countryCode":"FR"},"items":[{"token":"t"}],"code":"123456"}`
+	data, omitted := sanitizeReplayBody("application/json; charset=utf-8", []byte(body), false)
+	require.Empty(t, omitted)
+
+	var sanitized map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &sanitized))
+	require.Equal(t, "jane@example.com", sanitized["email"])
+	require.Equal(t, replayRedacted, sanitized["password"])
+	require.Equal(t, replayRedacted, sanitized["code"])
+	profile := sanitized["profile"].(map[string]interface{})
+	require.Equal(t, replayRedacted, profile["apiKey"])
+	require.Equal(t, "FR", profile["countryCode"])
+	require.Equal(t, replayRedacted, sanitized["items"].([]interface{})[0].(map[string]interface{})["token"])
+
+	// A cut JSON body cannot be sanitized, it is left out
+	data, omitted = sanitizeReplayBody("application/json", []byte(body[:20]), true)
+	require.Nil(t, data)
+	require.Equal(t, "truncated JSON body", omitted)
+
+	data, omitted = sanitizeReplayBody("multipart/form-data; boundary=x", []byte("binary"), false)
+	require.Nil(t, data)
+	require.Equal(t, "6 bytes of multipart/form-data", omitted)
+}
+
+func TestSanitizeReplayValues(t *testing.T) {
+	sanitized := sanitizeReplayValues(map[string][]string{
+		"Authorization":         {"Bearer abc"},
+		"X-Impersonation-Token": {"imp_abc"},
+		"Content-Type":          {"application/json"},
+	})
+	require.Equal(t, []string{replayRedacted}, sanitized["Authorization"])
+	require.Equal(t, []string{replayRedacted}, sanitized["X-Impersonation-Token"])
+	require.Equal(t, []string{"application/json"}, sanitized["Content-Type"])
+}
+
+func TestReplayCaptureRecord(t *testing.T) {
+	tenantID := "replay-tenant"
+	_, err := replayCaptureCache.Get(context.Background(), tenantID, func(ctx context.Context) (bool, error) {
+		return true, nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { InvalidateReplayCapture(tenantID) })
+
+	s := NewReplayCaptureService(nil, ReplayCaptureConfig{MinStatus: 500, MaxBodyBytes: 1024})
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(auth.AUTH_TENANT_ID_KEY, tenantID)
+	}, s.Record())
+	var capture *replayCapture
+	router.POST("/api/v1/users/:userid", func(c *gin.Context) {
+		// The handler still reads the whole body
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"name":"Jane"}`, string(body))
+		util.RecordReplayCall(c.Request.Context(), util.ReplayCall{Kind: "database", Name: "UpdateSharedUser", Error: "deadlock detected"})
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+		logger.Error().Msg("Failed to update user")
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "deadlock detected"})
+
+		value, _ := c.Get(replayCaptureKey)
+		capture = value.(*replayCapture)
+	})
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/u1", strings.NewReader(`{"name":"Jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	require.NotNil(t, capture)
+	require.Equal(t, `{"name":"Jane"}`, string(capture.body))
+	require.JSONEq(t, `{"message":"deadlock detected"}`, capture.writer.body.String())
+	calls, dropped := capture.recorder.Calls()
+	require.Zero(t, dropped)
+	require.Len(t, calls, 1)
+	require.Equal(t, "UpdateSharedUser", calls[0].Name)
+	require.Equal(t, []string{"error: Failed to update user"}, capture.recorder.Logs())
+}
+
+func TestReplayCaptureRecordDisabled(t *testing.T) {
+	s := NewReplayCaptureService(nil, ReplayCaptureConfig{MinStatus: 500, MaxBodyBytes: 1024})
+	router := gin.New()
+	router.Use(s.Record())
+	router.GET("/public-api/v1/tenant", func(c *gin.Context) {
+		_, captured := c.Get(replayCaptureKey)
+		require.False(t, captured)
+		require.Nil(t, util.GetReplayRecorder(c.Request.Context()))
+		c.Status(http.StatusInternalServerError)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/public-api/v1/tenant", nil))
+}
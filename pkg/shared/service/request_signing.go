@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Request signing constants
+const (
+	SignatureHeader            = "X-Signature"
+	SignatureTimestampHeader   = "X-Signature-Timestamp"
+	SignatureClientIDHeader    = "X-Client-Id"
+	SigningSecretPrefix        = "ss_"
+	DefaultSignatureClockSkew  = 5 * time.Minute
+	maxSignedBodyBytes         = 10 << 20
+	minRequestSigningKeyLength = 32
+)
+
+var (
+	ErrRequestSigningNotConfigured = errors.New("request signing is not configured")
+	ErrInvalidSignature            = errors.New("invalid request signature")
+	ErrSignatureExpired            = errors.New("request signature timestamp is outside the allowed clock skew")
+	ErrSignatureReplayed           = errors.New("request signature was already used")
+)
+
+// RequestSigningConfig configures HMAC signed requests.
+//
+// Environment:
+//   - REQUEST_SIGNING_MASTER_KEY: key the per-application signing and webhook
+//     secrets are derived from, at least 32 bytes (signed requests are rejected
+//     and webhooks disabled when unset). Changing it invalidates every secret.
+//   - REQUEST_SIGNING_CLOCK_SKEW: accepted distance between the signature
+//     timestamp and the server clock (default 5m)
+type RequestSigningConfig struct {
+	MasterKey []byte
+	ClockSkew time.Duration
+}
+
+func RequestSigningConfigFromEnv() RequestSigningConfig {
+	return RequestSigningConfigFromSecrets(context.Background(), EnvSecretProvider{})
+}
+
+// RequestSigningConfigFromSecrets reads the master key from the secret
+// provider and the other settings from the environment
+func RequestSigningConfigFromSecrets(ctx context.Context, secrets SecretProvider) RequestSigningConfig {
+	cfg := RequestSigningConfig{ClockSkew: DefaultSignatureClockSkew}
+	if key := readSecret(ctx, secrets, SecretRequestSigningMasterKey); key != "" {
+		if len(key) < minRequestSigningKeyLength {
+			log.Error().Int("min_length", minRequestSigningKeyLength).Msg("REQUEST_SIGNING_MASTER_KEY is too short, request signing disabled")
+		} else {
+			cfg.MasterKey = []byte(key)
+		}
+	}
+	if v := os.Getenv("REQUEST_SIGNING_CLOCK_SKEW"); v != "" {
+		if skew, err := time.ParseDuration(v); err == nil && skew > 0 {
+			cfg.ClockSkew = skew
+		} else {
+			log.Warn().Str("REQUEST_SIGNING_CLOCK_SKEW", v).Msg("Invalid duration, using default")
+		}
+	}
+	return cfg
+}
+
+// RequestSignature computes the signature a client sends in the X-Signature
+// header: the hex HMAC-SHA256, keyed with the signing secret, of
+//
+//	METHOD "\n" REQUEST_URI "\n" TIMESTAMP "\n" hex(SHA-256(body))
+//
+// where REQUEST_URI is the path with its raw query string and TIMESTAMP the
+// X-Signature-Timestamp header (Unix seconds).
+func RequestSignature(secret, method, requestURI string, timestamp int64, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", method, requestURI, timestamp, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signatureReplayCache remembers the signatures accepted within the clock skew
+// window. It is local to the instance: behind several instances a replay is
+// only caught by the instance that served the original request.
+type signatureReplayCache struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func newSignatureReplayCache() *signatureReplayCache {
+	return &signatureReplayCache{seen: map[string]time.Time{}}
+}
+
+// add records the signature until expiresAt and reports false when it was
+// already recorded
+func (r *signatureReplayCache) add(signature string, expiresAt time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if now.Sub(r.lastPrune) > time.Minute {
+		for sig, expiry := range r.seen {
+			if now.After(expiry) {
+				delete(r.seen, sig)
+			}
+		}
+		r.lastPrune = now
+	}
+	if expiry, ok := r.seen[signature]; ok && now.Before(expiry) {
+		return false
+	}
+	r.seen[signature] = expiresAt
+	return true
+}
+
+// RotateSigningKey creates the request signing key of the application,
+// replacing the previous one. The secret is only returned here; it is derived
+// again from the key id when verifying.
+func (s *ClientApplicationService) RotateSigningKey(ctx context.Context, clientApplicationID uuid.UUID, tenantID string,
+	scopes []string, createdBy string) (string, repository.CoreClientApplicationSigningKey, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if len(s.signing.MasterKey) == 0 {
+		return "", repository.CoreClientApplicationSigningKey{}, ErrRequestSigningNotConfigured
+	}
+
+	// Scope the application to the caller's tenant
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return "", repository.CoreClientApplicationSigningKey{}, err
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	record, err := s.store.UpsertClientApplicationSigningKey(ctx, repository.UpsertClientApplicationSigningKeyParams{
+		ClientApplicationID: clientApplicationID,
+		CreatedBy:           createdBy,
+		Scopes:              scopes,
+	})
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to store signing key")
+		return "", repository.CoreClientApplicationSigningKey{}, err
+	}
+	return s.signingSecret(record.ID), record, nil
+}
+
+// DeleteSigningKey removes the signing key of the application, which disables
+// signed requests for it
+func (s *ClientApplicationService) DeleteSigningKey(ctx context.Context, clientApplicationID uuid.UUID, tenantID string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	if _, err := s.GetClientApplicationByID(ctx, clientApplicationID, tenantID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteClientApplicationSigningKey(ctx, clientApplicationID)
+	if err != nil {
+		logger.Err(err).Str("clientApplicationID", clientApplicationID.String()).Msg("Failed to delete signing key")
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (s *ClientApplicationService) signingSecret(keyID uuid.UUID) string {
+	mac := hmac.New(sha256.New, s.signing.MasterKey)
+	mac.Write([]byte("request-signing:"))
+	mac.Write(keyID[:])
+	return SigningSecretPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignedRequest authenticates a request signed with the signing secret
+// of the application named in X-Client-Id. The body is read and restored for
+// the handlers.
+func (s *ClientApplicationService) VerifySignedRequest(c *gin.Context) (ClientCredentialsPrincipal, error) {
+	logger := util.GetLoggerFromCtx(c)
+	if len(s.signing.MasterKey) == 0 {
+		return ClientCredentialsPrincipal{}, ErrRequestSigningNotConfigured
+	}
+
+	applicationID, err := uuid.Parse(c.GetHeader(SignatureClientIDHeader))
+	if err != nil {
+		return ClientCredentialsPrincipal{}, ErrInvalidSignature
+	}
+	timestamp, err := strconv.ParseInt(c.GetHeader(SignatureTimestampHeader), 10, 64)
+	if err != nil {
+		return ClientCredentialsPrincipal{}, ErrInvalidSignature
+	}
+	signedAt := time.Unix(timestamp, 0)
+	if skew := time.Since(signedAt).Abs(); skew > s.signing.ClockSkew {
+		return ClientCredentialsPrincipal{}, ErrSignatureExpired
+	}
+	signature, err := hex.DecodeString(c.GetHeader(SignatureHeader))
+	if err != nil {
+		return ClientCredentialsPrincipal{}, ErrInvalidSignature
+	}
+
+	key, err := s.store.GetClientApplicationSigningKey(c, applicationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ClientCredentialsPrincipal{}, ErrInvalidSignature
+		}
+		logger.Err(err).Str("clientID", applicationID.String()).Msg("Failed to get signing key")
+		return ClientCredentialsPrincipal{}, err
+	}
+	if !key.Active {
+		return ClientCredentialsPrincipal{}, ErrInvalidSignature
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodyBytes))
+		if err != nil {
+			return ClientCredentialsPrincipal{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	expected, _ := hex.DecodeString(RequestSignature(s.signingSecret(key.ID), c.Request.Method, c.Request.URL.RequestURI(), timestamp, body))
+	if !hmac.Equal(expected, signature) {
+		logger.Warn().Str("clientID", applicationID.String()).Msg("Request signature rejected")
+		return ClientCredentialsPrincipal{}, ErrInvalidSignature
+	}
+	if !s.signatures.add(hex.EncodeToString(signature), signedAt.Add(s.signing.ClockSkew)) {
+		logger.Warn().Str("clientID", applicationID.String()).Msg("Replayed request signature rejected")
+		return ClientCredentialsPrincipal{}, ErrSignatureReplayed
+	}
+
+	s.usage.TouchClientApplication(c, applicationID)
+	return ClientCredentialsPrincipal{
+		ClientApplicationID: applicationID,
+		TenantID:            key.TenantID.String,
+		Scopes:              key.Scopes,
+		CreatedBy:           key.ApplicationCreatedBy,
+		ServiceAccountID:    key.ServiceAccountID.String,
+	}, nil
+}
+
+// IsSignedRequest reports whether the request carries a signature
+func IsSignedRequest(c *gin.Context) bool {
+	return c.GetHeader(SignatureHeader) != ""
+}
+
+// SignedRequestMiddleware authenticates signed requests for routes that do not
+// go through AuthMiddleware. Unsigned requests are left to the next
+// middleware; a signed request that fails verification is rejected.
+func SignedRequestMiddleware(clientAppService *ClientApplicationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsSignedRequest(c) {
+			c.Next()
+			return
+		}
+		principal, err := clientAppService.VerifySignedRequest(c)
+		if err != nil {
+			abortSignedRequest(c, err)
+			return
+		}
+		setClientCredentialsPrincipal(c, principal)
+		if principal.TenantID != "" {
+			c.Set(auth.AUTH_TENANT_ID_KEY, principal.TenantID)
+		}
+		c.Next()
+	}
+}
+
+func abortSignedRequest(c *gin.Context, err error) {
+	status := http.StatusUnauthorized
+	message := ErrInvalidSignature.Error()
+	switch {
+	case errors.Is(err, ErrSignatureExpired), errors.Is(err, ErrSignatureReplayed), errors.Is(err, ErrRequestSigningNotConfigured):
+		message = err.Error()
+	case !errors.Is(err, ErrInvalidSignature):
+		status = http.StatusInternalServerError
+		message = http.StatusText(status)
+	}
+	c.JSON(status, gin.H{
+		"status":  status,
+		"message": message,
+	})
+	c.Abort()
+}
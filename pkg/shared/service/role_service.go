@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxRoleNameLength = 128
+	// The permissions granted by the custom roles are read again after this
+	// delay, so the changes made on another instance apply
+	rolePermissionsCacheTTL = 30 * time.Second
+)
+
+var (
+	// ErrInvalidRole is wrapped by every validation error of a custom role
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleNameTaken      = errors.New("a role with this name already exists")
+	ErrRoleMemberNotFound = errors.New("the user does not hold the role")
+)
+
+// RoleInput is a custom role to create or the new state of a custom role
+type RoleInput struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// CustomRole is a role of a tenant with the operations it grants
+type CustomRole struct {
+	repository.CoreRole
+	Permissions []string
+}
+
+type rolePermissionsEntry struct {
+	permissions []string
+	loadedAt    time.Time
+}
+
+// rolePermissionsCache is shared by the RoleService instances of the process,
+// so the changes made through the API apply to the auth middleware right away
+var rolePermissionsCache = struct {
+	sync.Mutex
+	entries map[groupRolesKey]rolePermissionsEntry
+}{entries: map[groupRolesKey]rolePermissionsEntry{}}
+
+// RoleService manages the custom roles of a tenant. A custom role grants
+// fine-grained permissions, the operations of the authorizer, to the users
+// holding it on top of their built-in roles.
+type RoleService struct {
+	store *db.Store
+}
+
+func NewRoleService(store *db.Store) *RoleService {
+	return &RoleService{store: store}
+}
+
+func normalizeRoleInput(input RoleInput) (RoleInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+	if input.Name == "" || len(input.Name) > maxRoleNameLength {
+		return input, fmt.Errorf("%w: the name must have between 1 and %d characters", ErrInvalidRole, maxRoleNameLength)
+	}
+	for _, builtIn := range []core.Role{core.USER, core.CUSTOMERADMIN, core.ADMIN, core.SUPERADMIN} {
+		if strings.EqualFold(input.Name, string(builtIn)) {
+			return input, fmt.Errorf("%w: %s is a built-in role", ErrInvalidRole, builtIn)
+		}
+	}
+	input.Permissions = slices.Compact(slices.Sorted(slices.Values(input.Permissions)))
+	if len(input.Permissions) == 0 {
+		return input, fmt.Errorf("%w: a role grants at least one permission", ErrInvalidRole)
+	}
+	for _, permission := range input.Permissions {
+		if !auth.DefaultAuthorizer().Grantable(auth.Operation(permission)) {
+			return input, fmt.Errorf("%w: a custom role cannot grant %s", ErrInvalidRole, permission)
+		}
+	}
+	return input, nil
+}
+
+func roleStoreError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrRoleNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation:
+		return ErrRoleNameTaken
+	default:
+		return fmt.Errorf("service.%s: %w", op, err)
+	}
+}
+
+// CreateRole creates a custom role in the tenant
+func (s *RoleService) CreateRole(ctx context.Context, tenantID, createdBy string, input RoleInput) (CustomRole, error) {
+	input, err := normalizeRoleInput(input)
+	if err != nil {
+		return CustomRole{}, err
+	}
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return CustomRole{}, fmt.Errorf("service.CreateRole: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	role, err := qtx.CreateRole(ctx, repository.CreateRoleParams{
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return CustomRole{}, roleStoreError("CreateRole", err)
+	}
+	if err := qtx.AddRolePermissions(ctx, repository.AddRolePermissionsParams{RoleID: role.ID, Operations: input.Permissions}); err != nil {
+		return CustomRole{}, fmt.Errorf("service.CreateRole: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return CustomRole{}, fmt.Errorf("service.CreateRole: %w", err)
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("role_id", role.ID.String()).Strs("permissions", input.Permissions).Msg("Custom role created")
+	return CustomRole{CoreRole: role, Permissions: input.Permissions}, nil
+}
+
+// GetRole returns a custom role of the tenant
+func (s *RoleService) GetRole(ctx context.Context, tenantID string, id uuid.UUID) (CustomRole, error) {
+	role, err := s.store.GetRoleByID(ctx, repository.GetRoleByIDParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return CustomRole{}, roleStoreError("GetRole", err)
+	}
+	permissions, err := s.store.ListRolePermissions(ctx, role.ID)
+	if err != nil {
+		return CustomRole{}, fmt.Errorf("service.GetRole: %w", err)
+	}
+	return CustomRole{CoreRole: role, Permissions: permissions}, nil
+}
+
+// ListRoles lists the custom roles of the tenant by name
+func (s *RoleService) ListRoles(ctx context.Context, tenantID string) ([]CustomRole, error) {
+	roles, err := s.store.ListRoles(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListRoles: %w", err)
+	}
+	permissions, err := s.store.ListTenantRolePermissions(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListRoles: %w", err)
+	}
+	return withPermissions(roles, permissions), nil
+}
+
+func withPermissions(roles []repository.CoreRole, permissions []repository.CoreRolePermission) []CustomRole {
+	byRole := map[uuid.UUID][]string{}
+	for _, permission := range permissions {
+		byRole[permission.RoleID] = append(byRole[permission.RoleID], permission.Operation)
+	}
+	result := make([]CustomRole, len(roles))
+	for i, role := range roles {
+		result[i] = CustomRole{CoreRole: role, Permissions: byRole[role.ID]}
+		if result[i].Permissions == nil {
+			result[i].Permissions = []string{}
+		}
+	}
+	return result
+}
+
+// UpdateRole replaces the name, description and permissions of a custom role
+func (s *RoleService) UpdateRole(ctx context.Context, tenantID string, id uuid.UUID, input RoleInput) (CustomRole, error) {
+	input, err := normalizeRoleInput(input)
+	if err != nil {
+		return CustomRole{}, err
+	}
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return CustomRole{}, fmt.Errorf("service.UpdateRole: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	role, err := qtx.UpdateRole(ctx, repository.UpdateRoleParams{
+		ID:          id,
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+	})
+	if err != nil {
+		return CustomRole{}, roleStoreError("UpdateRole", err)
+	}
+	if err := qtx.DeleteRolePermissions(ctx, role.ID); err != nil {
+		return CustomRole{}, fmt.Errorf("service.UpdateRole: %w", err)
+	}
+	if err := qtx.AddRolePermissions(ctx, repository.AddRolePermissionsParams{RoleID: role.ID, Operations: input.Permissions}); err != nil {
+		return CustomRole{}, fmt.Errorf("service.UpdateRole: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return CustomRole{}, fmt.Errorf("service.UpdateRole: %w", err)
+	}
+	forgetRolePermissions(tenantID, "")
+	return CustomRole{CoreRole: role, Permissions: input.Permissions}, nil
+}
+
+// DeleteRole deletes a custom role, and the permissions it granted with it
+func (s *RoleService) DeleteRole(ctx context.Context, tenantID string, id uuid.UUID) error {
+	deleted, err := s.store.DeleteRole(ctx, repository.DeleteRoleParams{ID: id, TenantID: tenantID})
+	if err != nil {
+		return fmt.Errorf("service.DeleteRole: %w", err)
+	}
+	if deleted == 0 {
+		return ErrRoleNotFound
+	}
+	forgetRolePermissions(tenantID, "")
+	return nil
+}
+
+// ListMembers lists the users holding a custom role of the tenant
+func (s *RoleService) ListMembers(ctx context.Context, tenantID string, id uuid.UUID) ([]repository.CoreRoleMember, error) {
+	if _, err := s.store.GetRoleByID(ctx, repository.GetRoleByIDParams{ID: id, TenantID: tenantID}); err != nil {
+		return nil, roleStoreError("ListMembers", err)
+	}
+	members, err := s.store.ListRoleMembers(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListMembers: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember assigns a custom role to an active member of the tenant.
+// Assigning it twice does nothing.
+func (s *RoleService) AddMember(ctx context.Context, role CustomRole, userID, actorID string) error {
+	membership, err := s.store.GetSharedUserTenantMembership(ctx, repository.GetSharedUserTenantMembershipParams{
+		UserID:   userID,
+		TenantID: role.TenantID.String,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("service.AddMember: %w", err)
+	}
+	if err != nil || membership.Status != "active" {
+		return ErrNotTenantMember
+	}
+	added, err := s.store.AddRoleMember(ctx, repository.AddRoleMemberParams{
+		RoleID:     role.ID,
+		UserID:     userID,
+		AssignedBy: actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.AddMember: %w", err)
+	}
+	if added > 0 {
+		forgetRolePermissions(role.TenantID.String, userID)
+		s.recordRoleActivity(ctx, role, userID, actorID, "custom_role_assigned")
+	}
+	return nil
+}
+
+// RemoveMember removes a custom role from a user
+func (s *RoleService) RemoveMember(ctx context.Context, role CustomRole, userID, actorID string) error {
+	removed, err := s.store.RemoveRoleMember(ctx, repository.RemoveRoleMemberParams{
+		RoleID: role.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.RemoveMember: %w", err)
+	}
+	if removed == 0 {
+		return ErrRoleMemberNotFound
+	}
+	forgetRolePermissions(role.TenantID.String, userID)
+	s.recordRoleActivity(ctx, role, userID, actorID, "custom_role_unassigned")
+	return nil
+}
+
+// RolePermissions returns the operations the custom roles of the user grant
+// in the tenant
+func (s *RoleService) RolePermissions(ctx context.Context, tenantID, userID string) ([]string, error) {
+	key := groupRolesKey{tenantID: tenantID, userID: userID}
+	now := time.Now()
+	rolePermissionsCache.Lock()
+	entry, found := rolePermissionsCache.entries[key]
+	rolePermissionsCache.Unlock()
+	if found && now.Sub(entry.loadedAt) < rolePermissionsCacheTTL {
+		return entry.permissions, nil
+	}
+
+	permissions, err := s.store.ListUserRolePermissions(ctx, repository.ListUserRolePermissionsParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("service.RolePermissions: %w", err)
+	}
+	rolePermissionsCache.Lock()
+	defer rolePermissionsCache.Unlock()
+	// Expired entries are dropped as they are met, which bounds the cache
+	// to the users of the last TTL
+	for k, e := range rolePermissionsCache.entries {
+		if now.Sub(e.loadedAt) >= rolePermissionsCacheTTL {
+			delete(rolePermissionsCache.entries, k)
+		}
+	}
+	rolePermissionsCache.entries[key] = rolePermissionsEntry{permissions: permissions, loadedAt: now}
+	return permissions, nil
+}
+
+// forgetRolePermissions drops the cached permissions of the user, or of every
+// user of the tenant when userID is empty
+func forgetRolePermissions(tenantID, userID string) {
+	rolePermissionsCache.Lock()
+	defer rolePermissionsCache.Unlock()
+	for key := range rolePermissionsCache.entries {
+		if key.tenantID == tenantID && (userID == "" || key.userID == userID) {
+			delete(rolePermissionsCache.entries, key)
+		}
+	}
+}
+
+// withRolePermissions returns the user with the permissions of their custom
+// roles in the tenant. The user itself is left untouched, the provider may
+// cache it.
+func withRolePermissions(ctx context.Context, roles *RoleService, user *auth.AuthenticatedUser) *auth.AuthenticatedUser {
+	if roles == nil || user.TenantID == "" {
+		return user
+	}
+	permissions, err := roles.RolePermissions(ctx, user.TenantID, user.UserID)
+	if err != nil {
+		// Without its custom roles the user keeps their built-in roles
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", user.UserID).Msg("Failed to resolve custom role permissions")
+		return user
+	}
+	if len(permissions) == 0 && len(user.Permissions) == 0 {
+		return user
+	}
+	granted := *user
+	granted.Permissions = permissions
+	return &granted
+}
+
+func (s *RoleService) recordRoleActivity(ctx context.Context, role CustomRole, userID, actorID, eventType string) {
+	if actorID == userID {
+		actorID = ""
+	}
+	// Failures are logged by recordUserActivity
+	_ = recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  role.TenantID.String,
+		UserID:    userID,
+		Category:  ActivityCategoryMembership,
+		EventType: eventType,
+		ActorID:   actorID,
+		Data: map[string]interface{}{
+			"role_id":     role.ID.String(),
+			"role_name":   role.Name,
+			"permissions": role.Permissions,
+		},
+	})
+}
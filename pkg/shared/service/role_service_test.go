@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeRoleInput(t *testing.T) {
+	input, err := normalizeRoleInput(RoleInput{Name: " Support ", Permissions: []string{"users:manage", "groups:manage", "users:manage"}})
+	require.NoError(t, err)
+	require.Equal(t, "Support", input.Name)
+	require.Equal(t, []string{"groups:manage", "users:manage"}, input.Permissions)
+
+	for _, invalid := range []RoleInput{
+		{Name: "  ", Permissions: []string{"users:manage"}},
+		{Name: "customer_admin", Permissions: []string{"users:manage"}},
+		{Name: "Support"},
+		{Name: "Support", Permissions: []string{"tenants:reseller:update"}},
+		{Name: "Support", Permissions: []string{"roles:assign:CUSTOMER_ADMIN"}},
+		{Name: "Support", Permissions: []string{"reports:export"}},
+	} {
+		_, err := normalizeRoleInput(invalid)
+		require.ErrorIs(t, err, ErrInvalidRole, "%+v", invalid)
+	}
+}
+
+func TestWithRolePermissions(t *testing.T) {
+	roles := NewRoleService(nil)
+	ctx := context.Background()
+	user := &auth.AuthenticatedUser{UserID: "u1", TenantID: "t1"}
+	rolePermissionsCache.Lock()
+	rolePermissionsCache.entries[groupRolesKey{tenantID: "t1", userID: "u1"}] = rolePermissionsEntry{
+		permissions: []string{"users:manage"},
+		loadedAt:    time.Now(),
+	}
+	rolePermissionsCache.Unlock()
+	t.Cleanup(func() { forgetRolePermissions("t1", "") })
+
+	granted := withRolePermissions(ctx, roles, user)
+	require.Equal(t, []string{"users:manage"}, granted.Permissions)
+	// The user of the provider is left untouched
+	require.Empty(t, user.Permissions)
+
+	// Without a tenant, or without custom roles, the user is unchanged
+	require.Same(t, user, withRolePermissions(ctx, nil, user))
+	global := &auth.AuthenticatedUser{UserID: "u1"}
+	require.Same(t, global, withRolePermissions(ctx, roles, global))
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultScopeTemplatePropagationInterval is how often templates whose grace
+// period is over are propagated to their tokens
+const DefaultScopeTemplatePropagationInterval = time.Minute
+
+// ErrInvalidScopeTemplate is returned for a template without a name, or a
+// negative grace period
+var ErrInvalidScopeTemplate = errors.New("scope template needs a name, and a grace period cannot be negative")
+
+// ScopeTemplatePropagationIntervalFromEnv reads
+// SCOPE_TEMPLATE_PROPAGATION_INTERVAL (default 1m)
+func ScopeTemplatePropagationIntervalFromEnv() time.Duration {
+	if v := os.Getenv("SCOPE_TEMPLATE_PROPAGATION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Warn().Str("SCOPE_TEMPLATE_PROPAGATION_INTERVAL", v).Msg("Invalid value, using default")
+	}
+	return DefaultScopeTemplatePropagationInterval
+}
+
+// scopeTemplateTenant is the tenant filter of the template queries, NULL for
+// the global templates
+func scopeTemplateTenant(tenantID string) pgtype.Text {
+	return pgtype.Text{String: tenantID, Valid: tenantID != ""}
+}
+
+func normalizeTemplateScopes(scopes []string) []string {
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			normalized = append(normalized, scope)
+		}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(normalized)))
+}
+
+// ListScopeTemplates lists the templates of the tenant, or the global ones
+// when tenantID is empty, by name
+func (s *ClientApplicationService) ListScopeTemplates(ctx context.Context, tenantID string) ([]repository.CoreScopeTemplate, error) {
+	templates, err := s.store.ListScopeTemplates(ctx, scopeTemplateTenant(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("service.ListScopeTemplates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetScopeTemplate returns a template of the caller's tenant
+func (s *ClientApplicationService) GetScopeTemplate(ctx context.Context, id uuid.UUID, tenantID string) (repository.CoreScopeTemplate, error) {
+	return s.store.GetScopeTemplateByID(ctx, repository.GetScopeTemplateByIDParams{
+		ID:       id,
+		TenantID: scopeTemplateTenant(tenantID),
+	})
+}
+
+// CreateScopeTemplate adds a named scope bundle to the tenant
+func (s *ClientApplicationService) CreateScopeTemplate(ctx context.Context, tenantID, name, description string,
+	scopes []string, createdBy string) (repository.CoreScopeTemplate, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return repository.CoreScopeTemplate{}, ErrInvalidScopeTemplate
+	}
+	template, err := s.store.CreateScopeTemplate(ctx, repository.CreateScopeTemplateParams{
+		TenantID:    scopeTemplateTenant(tenantID),
+		Name:        name,
+		Description: pgtype.Text{String: description, Valid: description != ""},
+		Scopes:      normalizeTemplateScopes(scopes),
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		logger.Err(err).Str("name", name).Msg("Failed to create scope template")
+		return repository.CoreScopeTemplate{}, fmt.Errorf("service.CreateScopeTemplate: %w", err)
+	}
+	return template, nil
+}
+
+// ScopeTemplateUpdate is a change to a template. With Propagate, the new
+// scopes are copied to the active tokens created from the template once
+// GracePeriod is over, right away when it is zero; otherwise only tokens
+// created afterwards get them.
+type ScopeTemplateUpdate struct {
+	Name        string
+	Description string
+	Scopes      []string
+	Propagate   bool
+	GracePeriod time.Duration
+}
+
+// UpdateScopeTemplate changes a template of the caller's tenant. It returns
+// the number of tokens updated right away.
+func (s *ClientApplicationService) UpdateScopeTemplate(ctx *gin.Context, id uuid.UUID, tenantID string,
+	update ScopeTemplateUpdate, updatedBy string) (repository.CoreScopeTemplate, int, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	update.Name = strings.TrimSpace(update.Name)
+	if update.Name == "" || update.GracePeriod < 0 {
+		return repository.CoreScopeTemplate{}, 0, ErrInvalidScopeTemplate
+	}
+	if _, err := s.GetScopeTemplate(ctx, id, tenantID); err != nil {
+		return repository.CoreScopeTemplate{}, 0, err
+	}
+
+	var propagateAt pgtype.Timestamptz
+	if update.Propagate && update.GracePeriod > 0 {
+		propagateAt = pgtype.Timestamptz{Time: time.Now().Add(update.GracePeriod), Valid: true}
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return repository.CoreScopeTemplate{}, 0, fmt.Errorf("service.UpdateScopeTemplate: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	template, err := qtx.UpdateScopeTemplate(ctx, repository.UpdateScopeTemplateParams{
+		ID:          id,
+		Name:        update.Name,
+		Description: pgtype.Text{String: update.Description, Valid: update.Description != ""},
+		Scopes:      normalizeTemplateScopes(update.Scopes),
+		PropagateAt: propagateAt,
+		UpdatedBy:   pgtype.Text{String: updatedBy, Valid: true},
+	})
+	if err != nil {
+		logger.Err(err).Str("id", id.String()).Msg("Failed to update scope template")
+		return repository.CoreScopeTemplate{}, 0, fmt.Errorf("service.UpdateScopeTemplate: %w", err)
+	}
+	updated := 0
+	if update.Propagate && update.GracePeriod == 0 {
+		updated, err = applyScopeTemplate(ctx, qtx, template, updatedBy, ctx.ClientIP(), ctx.GetHeader("User-Agent"))
+		if err != nil {
+			return repository.CoreScopeTemplate{}, 0, fmt.Errorf("service.UpdateScopeTemplate: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return repository.CoreScopeTemplate{}, 0, fmt.Errorf("service.UpdateScopeTemplate: %w", err)
+	}
+
+	logger.Info().Str("id", id.String()).Strs("scopes", template.Scopes).Bool("propagate", update.Propagate).
+		Dur("gracePeriod", update.GracePeriod).Int("tokens", updated).Msg("Scope template updated")
+	return template, updated, nil
+}
+
+// DeleteScopeTemplate removes a template of the caller's tenant. Tokens
+// created from it keep their scopes.
+func (s *ClientApplicationService) DeleteScopeTemplate(ctx context.Context, id uuid.UUID, tenantID string) error {
+	deleted, err := s.store.DeleteScopeTemplate(ctx, repository.DeleteScopeTemplateParams{
+		ID:       id,
+		TenantID: scopeTemplateTenant(tenantID),
+	})
+	if err != nil {
+		return fmt.Errorf("service.DeleteScopeTemplate: %w", err)
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// CreateAPITokenFromScopeTemplate creates a token with the scopes of a
+// template of the caller's tenant, and the given limits. The token stays
+// linked to the template, so propagated changes reach it.
+func (s *ClientApplicationService) CreateAPITokenFromScopeTemplate(ctx *gin.Context, clientApplicationID uuid.UUID, tenantID string,
+	name, description string, expiresInDays int, createdBy string, templateID uuid.UUID, limits APITokenLimits) (string, repository.CoreApiToken, error) {
+	template, err := s.GetScopeTemplate(ctx, templateID, tenantID)
+	if err != nil {
+		return "", repository.CoreApiToken{}, err
+	}
+	return s.createAPIToken(ctx, clientApplicationID, tenantID, name, description, expiresInDays, createdBy,
+		template.Scopes, pgtype.UUID{Bytes: template.ID, Valid: true}, limits)
+}
+
+// applyScopeTemplate copies the template scopes to its active tokens, with an
+// UPDATED audit entry per token changed
+func applyScopeTemplate(ctx context.Context, q *repository.Queries, template repository.CoreScopeTemplate,
+	actor, ipAddress, userAgent string) (int, error) {
+	tokens, err := q.ApplyScopeTemplateToAPITokens(ctx, repository.ApplyScopeTemplateToAPITokensParams{
+		ScopeTemplateID: pgtype.UUID{Bytes: template.ID, Valid: true},
+		Scopes:          template.Scopes,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, token := range tokens {
+		additionalData, err := json.Marshal(map[string]interface{}{
+			"updated_by":        actor,
+			"scope_template_id": template.ID,
+			"previous_scopes":   token.PreviousScopes,
+			"scopes":            template.Scopes,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if _, err := q.CreateAPITokenAuditLog(ctx, repository.CreateAPITokenAuditLogParams{
+			TokenID:        token.ID,
+			Action:         TokenAuditUpdated,
+			IpAddress:      pgtype.Text{String: ipAddress, Valid: ipAddress != ""},
+			UserAgent:      pgtype.Text{String: userAgent, Valid: userAgent != ""},
+			AdditionalData: enrichAPITokenAuditData(ctx, APITokenAuditEntry{TokenID: token.ID, Action: TokenAuditUpdated}, additionalData),
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(tokens), nil
+}
+
+// StartScopeTemplatePropagation propagates, every interval, the templates
+// whose grace period is over
+func (s *ClientApplicationService) StartScopeTemplatePropagation(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultScopeTemplatePropagationInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.PropagateDueScopeTemplates(ctx); err != nil {
+				log.Err(err).Msg("Scope template propagation failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PropagateDueScopeTemplates applies the templates whose grace period is over
+// to their tokens, each in its own transaction. It returns the number of
+// tokens updated.
+func (s *ClientApplicationService) PropagateDueScopeTemplates(ctx context.Context) (int, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	templates, err := s.store.ListDueScopeTemplates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("service.PropagateDueScopeTemplates: %w", err)
+	}
+	total := 0
+	for _, template := range templates {
+		updated, err := s.propagateScopeTemplate(ctx, template)
+		if err != nil {
+			logger.Err(err).Str("scopeTemplateID", template.ID.String()).Msg("Failed to propagate scope template")
+			continue
+		}
+		logger.Info().Str("scopeTemplateID", template.ID.String()).Int("tokens", updated).Msg("Scope template propagated")
+		total += updated
+	}
+	return total, nil
+}
+
+func (s *ClientApplicationService) propagateScopeTemplate(ctx context.Context, template repository.CoreScopeTemplate) (int, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	// Clearing first claims the propagation: another instance, or an update
+	// of the template meanwhile, leaves nothing to clear
+	cleared, err := qtx.ClearScopeTemplatePropagation(ctx, repository.ClearScopeTemplatePropagationParams{
+		ID:          template.ID,
+		PropagateAt: template.PropagateAt,
+	})
+	if err != nil || cleared == 0 {
+		return 0, err
+	}
+	updated, err := applyScopeTemplate(ctx, qtx, template, template.UpdatedBy.String, "", "")
+	if err != nil {
+		return 0, err
+	}
+	return updated, tx.Commit(ctx)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeTemplates(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	tenantID := app.TenantID.String
+
+	template, err := service.CreateScopeTemplate(ctx, tenantID, commontestutils.RandomString(10), "",
+		[]string{"users:read", "files:read", "users:read"}, app.CreatedBy)
+	require.NoError(t, err)
+	require.Equal(t, []string{"files:read", "users:read"}, template.Scopes)
+
+	_, token, err := service.CreateAPITokenFromScopeTemplate(ctx, app.ID, tenantID, commontestutils.RandomString(10), "", 30, app.CreatedBy, template.ID, APITokenLimits{})
+	require.NoError(t, err)
+	require.Equal(t, template.Scopes, token.Scopes)
+	require.Equal(t, template.ID, [16]byte(token.ScopeTemplateID.Bytes))
+
+	t.Run("templates of another tenant are not found", func(t *testing.T) {
+		_, err := service.GetScopeTemplate(ctx, template.ID, commontestutils.RandomString(10))
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("a change without propagation only reaches new tokens", func(t *testing.T) {
+		_, updated, err := service.UpdateScopeTemplate(ctx, template.ID, tenantID, ScopeTemplateUpdate{
+			Name:   template.Name,
+			Scopes: []string{"files:read"},
+		}, app.CreatedBy)
+		require.NoError(t, err)
+		require.Zero(t, updated)
+
+		current, err := service.GetAPITokenByID(ctx, token.ID, tenantID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"files:read", "users:read"}, current.Scopes)
+	})
+
+	t.Run("a propagated change is applied and audited", func(t *testing.T) {
+		_, updated, err := service.UpdateScopeTemplate(ctx, template.ID, tenantID, ScopeTemplateUpdate{
+			Name:      template.Name,
+			Scopes:    []string{"users:read"},
+			Propagate: true,
+		}, app.CreatedBy)
+		require.NoError(t, err)
+		require.Equal(t, 1, updated)
+
+		current, err := service.GetAPITokenByID(ctx, token.ID, tenantID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"users:read"}, current.Scopes)
+
+		logs, err := service.GetAPITokenAuditLogs(ctx, token.ID, APITokenAuditFilter{}, 10, 0)
+		require.NoError(t, err)
+		audited := false
+		for _, entry := range logs {
+			audited = audited || entry.Action == TokenAuditUpdated
+		}
+		require.True(t, audited)
+	})
+
+	t.Run("a grace period delays the propagation", func(t *testing.T) {
+		changed, _, err := service.UpdateScopeTemplate(ctx, template.ID, tenantID, ScopeTemplateUpdate{
+			Name:        template.Name,
+			Scopes:      []string{"files:read", "users:read"},
+			Propagate:   true,
+			GracePeriod: 50 * time.Millisecond,
+		}, app.CreatedBy)
+		require.NoError(t, err)
+		require.True(t, changed.PropagateAt.Valid)
+
+		current, err := service.GetAPITokenByID(ctx, token.ID, tenantID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"users:read"}, current.Scopes)
+
+		require.Eventually(t, func() bool {
+			_, err := service.PropagateDueScopeTemplates(ctx)
+			require.NoError(t, err)
+			current, err := service.GetAPITokenByID(ctx, token.ID, tenantID)
+			require.NoError(t, err)
+			return len(current.Scopes) == 2
+		}, 5*time.Second, 100*time.Millisecond)
+
+		changed, err = service.GetScopeTemplate(ctx, template.ID, tenantID)
+		require.NoError(t, err)
+		require.False(t, changed.PropagateAt.Valid)
+	})
+
+	t.Run("narrowing a token detaches it from the template", func(t *testing.T) {
+		scopes := []string{"files:read"}
+		updated, err := service.UpdateAPIToken(ctx, app.ID, token.ID, tenantID, &scopes, nil, app.CreatedBy)
+		require.NoError(t, err)
+		require.False(t, updated.ScopeTemplateID.Valid)
+	})
+
+	t.Run("a deleted template is gone", func(t *testing.T) {
+		require.NoError(t, service.DeleteScopeTemplate(ctx, template.ID, tenantID))
+		require.ErrorIs(t, service.DeleteScopeTemplate(ctx, template.ID, tenantID), pgx.ErrNoRows)
+	})
+}
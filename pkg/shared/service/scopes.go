@@ -0,0 +1,86 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// AdminScope grants every scope
+const AdminScope = "admin"
+
+// ScopeWildcard ends a scope granting every scope below it: "users:*" grants
+// "users:read" and "users:profile:write"
+const ScopeWildcard = "*"
+
+const scopeSeparator = ":"
+
+// ScopeDefinition describes a scope that tokens can be granted
+type ScopeDefinition struct {
+	Name        string
+	Description string
+}
+
+var (
+	scopeCatalogMu sync.RWMutex
+	// scopeCatalog holds the scopes checked by the core, modules add theirs
+	// with RegisterScopes
+	scopeCatalog = map[string]ScopeDefinition{
+		AdminScope:   {Name: AdminScope, Description: "Full access, grants every scope"},
+		"users:read": {Name: "users:read", Description: "Look up users of the tenant"},
+	}
+)
+
+// RegisterScopes adds the scopes checked by a module to the catalog. A scope
+// registered twice keeps the last description.
+func RegisterScopes(definitions ...ScopeDefinition) {
+	scopeCatalogMu.Lock()
+	defer scopeCatalogMu.Unlock()
+	for _, definition := range definitions {
+		scopeCatalog[definition.Name] = definition
+	}
+}
+
+// ScopeCatalog returns the known scopes sorted by name, with a wildcard for
+// every level of the hierarchy, such as "users:*"
+func ScopeCatalog() []ScopeDefinition {
+	scopeCatalogMu.RLock()
+	defer scopeCatalogMu.RUnlock()
+	catalog := make(map[string]ScopeDefinition, len(scopeCatalog))
+	for name, definition := range scopeCatalog {
+		catalog[name] = definition
+		parts := strings.Split(name, scopeSeparator)
+		for i := 1; i < len(parts); i++ {
+			prefix := strings.Join(parts[:i], scopeSeparator)
+			wildcard := prefix + scopeSeparator + ScopeWildcard
+			if _, ok := catalog[wildcard]; !ok {
+				catalog[wildcard] = ScopeDefinition{Name: wildcard, Description: "Every " + prefix + " scope"}
+			}
+		}
+	}
+	result := make([]ScopeDefinition, 0, len(catalog))
+	for _, definition := range catalog {
+		result = append(result, definition)
+	}
+	slices.SortFunc(result, func(a, b ScopeDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// ScopeGrants reports whether the granted scope covers the required one:
+// the same scope, the admin scope, or a wildcard above it
+func ScopeGrants(granted, required string) bool {
+	if granted == required || granted == AdminScope {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(granted, scopeSeparator+ScopeWildcard)
+	return ok && strings.HasPrefix(required, prefix+scopeSeparator)
+}
+
+// HasScope reports whether one of the granted scopes covers the required one
+func HasScope(granted []string, required string) bool {
+	return slices.ContainsFunc(granted, func(scope string) bool {
+		return ScopeGrants(scope, required)
+	})
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeGrants(t *testing.T) {
+	for _, tc := range []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{"users:read", "users:read", true},
+		{"users:read", "users:write", false},
+		{"users:*", "users:read", true},
+		{"users:*", "users:profile:write", true},
+		{"users:profile:*", "users:profile:write", true},
+		{"users:profile:*", "users:read", false},
+		{"users:*", "users", false},
+		{"users:*", "usersx:read", false},
+		{"admin", "users:read", true},
+		{"admin", "anything", true},
+		{"*", "users:read", false},
+	} {
+		require.Equal(t, tc.want, ScopeGrants(tc.granted, tc.required), "%s grants %s", tc.granted, tc.required)
+	}
+}
+
+func TestValidateTokenScopes(t *testing.T) {
+	c, _ := gin.CreateTestContext(nil)
+	require.False(t, ValidateTokenScopes(c, []string{"users:read"}))
+	require.True(t, ValidateTokenScopes(c, nil))
+
+	c.Set("api_token_scopes", []string{"users:*", "files:read"})
+	require.True(t, ValidateTokenScopes(c, []string{"users:read", "users:write", "files:read"}))
+	require.False(t, ValidateTokenScopes(c, []string{"users:read", "files:write"}))
+}
+
+func TestScopeCatalog(t *testing.T) {
+	RegisterScopes(ScopeDefinition{Name: "reports:export:pdf", Description: "Export reports as PDF"})
+
+	names := map[string]string{}
+	for _, scope := range ScopeCatalog() {
+		names[scope.Name] = scope.Description
+	}
+	require.Contains(t, names, AdminScope)
+	require.Contains(t, names, "users:read")
+	require.Contains(t, names, "users:*")
+	require.Equal(t, "Export reports as PDF", names["reports:export:pdf"])
+	require.Contains(t, names, "reports:*")
+	require.Contains(t, names, "reports:export:*")
+}
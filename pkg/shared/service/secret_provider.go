@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2/google"
+)
+
+// Names of the client application secrets. With the environment provider they
+// are the variable names; Vault keys and Secret Manager secret IDs use the
+// same names.
+const (
+	SecretAPITokenHashPepper      = "API_TOKEN_HASH_PEPPER"
+	SecretRequestSigningMasterKey = "REQUEST_SIGNING_MASTER_KEY"
+	SecretOAuthJWTSigningKey      = "OAUTH_JWT_SIGNING_KEY"
+)
+
+const (
+	SecretProviderEnv   = "env"
+	SecretProviderVault = "vault"
+	SecretProviderGCP   = "gcp"
+)
+
+// secretFetchTimeout bounds the secret lookups done while the service starts
+const secretFetchTimeout = 10 * time.Second
+
+// ErrSecretNotFound is returned by a provider that does not hold the secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves the secrets of ClientApplicationService: the token
+// hash pepper, the request signing master key and the OAuth signing key. They
+// are read once, when the service is created, so a rotated secret is picked
+// up on restart.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// EnvSecretProvider reads the secrets from the environment variables of the
+// same name
+type EnvSecretProvider struct{}
+
+func (EnvSecretProvider) Secret(_ context.Context, name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// fallbackSecretProvider reads the secrets missing from primary in fallback,
+// so secrets can be moved out of the environment one at a time
+type fallbackSecretProvider struct {
+	primary  SecretProvider
+	fallback SecretProvider
+}
+
+func (p fallbackSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	value, err := p.primary.Secret(ctx, name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return p.fallback.Secret(ctx, name)
+	}
+	return value, err
+}
+
+// VaultSecretProvider reads the secrets from the keys of one Vault KV secret.
+// Both KV versions are supported: with version 2 the path includes the data
+// segment, as in secret/data/coreapp.
+type VaultSecretProvider struct {
+	Address   string
+	Token     string
+	Namespace string
+	Path      string
+	Client    *http.Client
+
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+func (p *VaultSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	data, err := p.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[name].(string)
+	if !ok || value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// load reads the secret once for all the keys
+func (p *VaultSecretProvider) load(ctx context.Context) (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.data != nil {
+		return p.data, nil
+	}
+
+	endpoint := strings.TrimSuffix(p.Address, "/") + "/v1/" + strings.TrimPrefix(p.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := fetchSecretJSON(p.httpClient(), req, &body); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	data := body.Data
+	// KV version 2 nests the keys under data.data, next to the metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	p.data = data
+	return data, nil
+}
+
+func (p *VaultSecretProvider) httpClient() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return http.DefaultClient
+}
+
+// GCPSecretProvider reads the latest version of the Secret Manager secrets of
+// a project, through the REST API
+type GCPSecretProvider struct {
+	Project string
+	// BaseURL defaults to https://secretmanager.googleapis.com
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewGCPSecretProvider authenticates with the application default credentials
+func NewGCPSecretProvider(ctx context.Context, project string) (*GCPSecretProvider, error) {
+	client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, fmt.Errorf("gcp secret manager: %w", err)
+	}
+	return &GCPSecretProvider{Project: project, Client: client}, nil
+}
+
+func (p *GCPSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = "https://secretmanager.googleapis.com"
+	}
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/latest:access",
+		strings.TrimSuffix(baseURL, "/"), url.PathEscape(p.Project), url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: %w", err)
+	}
+	var body struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if err := fetchSecretJSON(client, req, &body); err != nil {
+		if errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("gcp secret manager: %w", err)
+	}
+	value, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: %w", err)
+	}
+	if len(value) == 0 {
+		return "", ErrSecretNotFound
+	}
+	return string(value), nil
+}
+
+func fetchSecretJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		// The body may echo the request, it is not logged
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// SecretProviderFromEnv selects the secret provider. The secrets an external
+// provider does not hold are still read from the environment.
+//
+// Environment:
+//   - SECRET_PROVIDER: env (default), vault or gcp
+//   - VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE: Vault server and credentials
+//   - VAULT_SECRET_PATH: KV secret holding the keys, such as secret/data/coreapp
+//   - GCP_SECRET_PROJECT: project of the Secret Manager secrets
+func SecretProviderFromEnv(ctx context.Context) SecretProvider {
+	switch provider := os.Getenv("SECRET_PROVIDER"); provider {
+	case "", SecretProviderEnv:
+		return EnvSecretProvider{}
+	case SecretProviderVault:
+		address, path := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_SECRET_PATH")
+		if address == "" || path == "" {
+			log.Error().Msg("VAULT_ADDR and VAULT_SECRET_PATH are required by the vault secret provider, reading secrets from the environment")
+			return EnvSecretProvider{}
+		}
+		return fallbackSecretProvider{
+			primary: &VaultSecretProvider{
+				Address:   address,
+				Token:     os.Getenv("VAULT_TOKEN"),
+				Namespace: os.Getenv("VAULT_NAMESPACE"),
+				Path:      path,
+			},
+			fallback: EnvSecretProvider{},
+		}
+	case SecretProviderGCP:
+		project := os.Getenv("GCP_SECRET_PROJECT")
+		if project == "" {
+			log.Error().Msg("GCP_SECRET_PROJECT is required by the gcp secret provider, reading secrets from the environment")
+			return EnvSecretProvider{}
+		}
+		gcp, err := NewGCPSecretProvider(ctx, project)
+		if err != nil {
+			log.Err(err).Msg("Failed to create the gcp secret provider, reading secrets from the environment")
+			return EnvSecretProvider{}
+		}
+		return fallbackSecretProvider{primary: gcp, fallback: EnvSecretProvider{}}
+	default:
+		log.Warn().Str("SECRET_PROVIDER", provider).Msg("Invalid value, using default")
+		return EnvSecretProvider{}
+	}
+}
+
+var (
+	secretProviderMu sync.RWMutex
+	secretProvider   SecretProvider
+)
+
+// SetSecretProvider replaces the provider of the client application services
+// created afterwards, for embedding applications with their own secret store
+func SetSecretProvider(provider SecretProvider) {
+	secretProviderMu.Lock()
+	defer secretProviderMu.Unlock()
+	secretProvider = provider
+}
+
+func currentSecretProvider() SecretProvider {
+	secretProviderMu.RLock()
+	provider := secretProvider
+	secretProviderMu.RUnlock()
+	if provider != nil {
+		return provider
+	}
+
+	secretProviderMu.Lock()
+	defer secretProviderMu.Unlock()
+	if secretProvider == nil {
+		secretProvider = SecretProviderFromEnv(context.Background())
+	}
+	return secretProvider
+}
+
+// readSecret returns the secret, or "" when the provider does not hold it or
+// fails. A failure is logged: the feature depending on the secret stays
+// disabled rather than preventing the start.
+func readSecret(ctx context.Context, provider SecretProvider, name string) string {
+	ctx, cancel := context.WithTimeout(ctx, secretFetchTimeout)
+	defer cancel()
+	value, err := provider.Secret(ctx, name)
+	if err != nil {
+		if !errors.Is(err, ErrSecretNotFound) {
+			log.Err(err).Str("secret", name).Msg("Failed to read secret")
+		}
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultSecretProvider(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "/v1/secret/data/coreapp", r.URL.Path)
+		require.Equal(t, "root-token", r.Header.Get("X-Vault-Token"))
+		w.Write([]byte(`{"data":{"data":{"API_TOKEN_HASH_PEPPER":"pepper-from-vault"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	vault := &VaultSecretProvider{Address: server.URL, Token: "root-token", Path: "secret/data/coreapp"}
+	t.Setenv(SecretOAuthJWTSigningKey, "signing-key-from-env")
+	provider := fallbackSecretProvider{primary: vault, fallback: EnvSecretProvider{}}
+
+	value, err := provider.Secret(context.Background(), SecretAPITokenHashPepper)
+	require.NoError(t, err)
+	require.Equal(t, "pepper-from-vault", value)
+
+	value, err = provider.Secret(context.Background(), SecretOAuthJWTSigningKey)
+	require.NoError(t, err)
+	require.Equal(t, "signing-key-from-env", value)
+
+	_, err = provider.Secret(context.Background(), SecretRequestSigningMasterKey)
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	require.Equal(t, 1, requests)
+}
+
+func TestGCPSecretProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/acme/secrets/"+SecretRequestSigningMasterKey+"/versions/latest:access" {
+			http.NotFound(w, r)
+			return
+		}
+		data := base64.StdEncoding.EncodeToString([]byte("master-key-from-secret-manager"))
+		w.Write([]byte(`{"name":"latest","payload":{"data":"` + data + `"}}`))
+	}))
+	defer server.Close()
+
+	gcp := &GCPSecretProvider{Project: "acme", BaseURL: server.URL}
+	value, err := gcp.Secret(context.Background(), SecretRequestSigningMasterKey)
+	require.NoError(t, err)
+	require.Equal(t, "master-key-from-secret-manager", value)
+
+	_, err = gcp.Secret(context.Background(), SecretAPITokenHashPepper)
+	require.ErrorIs(t, err, ErrSecretNotFound)
+}
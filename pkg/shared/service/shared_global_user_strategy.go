@@ -10,7 +10,6 @@ import (
 	"ctoup.com/coreapp/pkg/shared/repository/subentity"
 	sqlservice "ctoup.com/coreapp/pkg/shared/sql"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // GlobalUserStrategy handles operations for global users
@@ -32,24 +31,34 @@ func (g *GlobalUserStrategy) CreateUser(c context.Context, authClient auth.AuthC
 		}
 	}
 
-	user, err := qtx.CreateSharedUser(c,
+	profile := subentity.UserProfile{
+		Name: req.Name,
+	}
+	if req.PhoneNumber != nil {
+		profile.PhoneNumber = *req.PhoneNumber
+	}
+
+	email, blindIndex, err := EncryptEmail(req.Email)
+	if err != nil {
+		return user, err
+	}
+	user, err = qtx.CreateSharedUser(c,
 		repository.CreateSharedUserParams{
-			ID:    userRecord.UID,
-			Email: req.Email,
-			Profile: subentity.UserProfile{
-				Name: req.Name,
-			},
-			Roles: convertToRoles(req.Roles),
+			ID:              userRecord.UID,
+			Email:           email,
+			Profile:         profile,
+			Roles:           convertToRoles(req.Roles),
+			EmailBlindIndex: blindIndex,
 		})
-	return user, err
+	return DecryptUserEmail(user), err
 }
 
+// UpdateUser leaves the email address alone: it only changes through the email
+// change flow, confirmed by the new address (UserEmailChangeService)
 func (g *GlobalUserStrategy) UpdateUser(c context.Context, authClient auth.AuthClient, qtx *repository.Queries, req core.UpdateUserJSONRequestBody) error {
 	params := (&auth.UserToUpdate{}).
-		Email(req.Email).
-		EmailVerified(false).
 		DisplayName(req.Name).
-		PhotoURL("/images/avatar-1.jpeg").
+		PhotoURL(PlatformAssetsFromEnv().DefaultAvatarURL).
 		Disabled(false)
 
 	if _, err := authClient.UpdateUser(c, req.Id, params); err != nil {
@@ -80,13 +89,20 @@ func (g *GlobalUserStrategy) UpdateSharedProfile(ctx context.Context, store *db.
 	return err
 }
 
-func (g *GlobalUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error) {
+func (g *GlobalUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error) {
 	// Query via user_tenant_memberships table
 	adminUsers, err := store.ListSharedUsersByRoles(c, repository.ListSharedUsersByRolesParams{
-		RequestedRoles: []string{string(core.SUPERADMIN), string(core.ADMIN)},
-		Limit:          pagingSql.PageSize,
-		Offset:         pagingSql.Offset,
-		SearchPrefix:   like,
+		RequestedRoles:   []string{string(core.SUPERADMIN), string(core.ADMIN)},
+		Limit:            pagingSql.PageSize,
+		Offset:           pagingSql.Offset,
+		Search:           filter.Search,
+		SearchBlindIndex: searchBlindIndex(filter.Search),
+		InactiveSince:    filter.InactiveSince,
+		Role:             filter.Role,
+		Disabled:         filter.Disabled,
+		EmailVerified:    filter.EmailVerified,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
 	})
 	if err != nil {
 		return []core.User{}, err
@@ -98,7 +114,7 @@ func (g *GlobalUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSq
 		user := core.User{
 			Id:        membership.ID,
 			Name:      membership.Profile.Name,
-			Email:     membership.Email.String,
+			Email:     DecryptEmail(membership.Email).String,
 			Roles:     convertToRoleDTOs(membership.Roles),
 			CreatedAt: &membership.CreatedAt,
 		}
@@ -108,6 +124,20 @@ func (g *GlobalUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSq
 	return users, nil
 }
 
+func (g *GlobalUserStrategy) CountUsers(c *gin.Context, store *db.Store, filter UserListFilter) (int64, error) {
+	return store.CountSharedUsersByRoles(c, repository.CountSharedUsersByRolesParams{
+		RequestedRoles:   []string{string(core.SUPERADMIN), string(core.ADMIN)},
+		Search:           filter.Search,
+		SearchBlindIndex: searchBlindIndex(filter.Search),
+		InactiveSince:    filter.InactiveSince,
+		Role:             filter.Role,
+		Disabled:         filter.Disabled,
+		EmailVerified:    filter.EmailVerified,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
+	})
+}
+
 // AssignRole grants a global role. core_users.roles is the source of truth: we
 // read it, merge the new role in, write it back, then mirror the full set into
 // Kratos via global_roles. The previous AssignRoleWithRowsAffected path filtered
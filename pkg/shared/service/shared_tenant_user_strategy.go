@@ -10,7 +10,6 @@ import (
 	"ctoup.com/coreapp/pkg/shared/repository/subentity"
 	sqlservice "ctoup.com/coreapp/pkg/shared/sql"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // TenantUserStrategy handles operations for tenant users
@@ -38,20 +37,28 @@ func (g *TenantUserStrategy) CreateUser(c context.Context, authClient auth.AuthC
 	profile := subentity.UserProfile{
 		Name: req.Name,
 	}
+	if req.PhoneNumber != nil {
+		profile.PhoneNumber = *req.PhoneNumber
+	}
 
+	email, blindIndex, err := EncryptEmail(req.Email)
+	if err != nil {
+		return user, err
+	}
 	sharedUser, err := qtx.CreateSharedUserWithTenant(c,
 		repository.CreateSharedUserWithTenantParams{
-			ID:          userRecord.UID,
-			Email:       req.Email,
-			Profile:     profile,
-			TenantRoles: convertToRoles(req.Roles),
-			TenantID:    g.tenantID,
+			ID:              userRecord.UID,
+			Email:           email,
+			EmailBlindIndex: blindIndex,
+			Profile:         profile,
+			TenantRoles:     convertToRoles(req.Roles),
+			TenantID:        g.tenantID,
 		})
 	if err != nil {
 		return user, err
 	}
 	user.CreatedAt = sharedUser.CreatedAt
-	user.Email = sharedUser.Email
+	user.Email = DecryptEmail(sharedUser.Email)
 	user.ID = sharedUser.ID
 	user.Profile = profile
 	user.Roles = sharedUser.TenantRoles
@@ -90,13 +97,21 @@ func (g *TenantUserStrategy) UpdateSharedProfile(ctx context.Context, store *db.
 	return err
 }
 
-func (g *TenantUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error) {
+func (g *TenantUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error) {
 	// Query via user_tenant_memberships table (all statuses)
 	memberships, err := store.ListSharedUsersByTenantAllStatuses(c, repository.ListSharedUsersByTenantAllStatusesParams{
-		TenantID:     g.tenantID,
-		Limit:        pagingSql.PageSize,
-		Offset:       pagingSql.Offset,
-		SearchPrefix: like,
+		TenantID:         g.tenantID,
+		Limit:            pagingSql.PageSize,
+		Offset:           pagingSql.Offset,
+		Search:           filter.Search,
+		SearchBlindIndex: searchBlindIndex(filter.Search),
+		InactiveSince:    filter.InactiveSince,
+		Role:             filter.Role,
+		Labels:           filter.Labels,
+		Disabled:         filter.Disabled,
+		EmailVerified:    filter.EmailVerified,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
 	})
 	if err != nil {
 		return []core.User{}, err
@@ -109,10 +124,11 @@ func (g *TenantUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSq
 		user := core.User{
 			Id:               membership.ID,
 			Name:             membership.Profile.Name,
-			Email:            membership.Email.String,
+			Email:            DecryptEmail(membership.Email).String,
 			Roles:            convertToRoleDTOs(membership.TenantRoles),
 			CreatedAt:        &membership.CreatedAt,
 			MembershipStatus: &membershipStatus,
+			Labels:           &membership.TenantLabels,
 		}
 		users[j] = user
 	}
@@ -120,6 +136,21 @@ func (g *TenantUserStrategy) ListUsers(c *gin.Context, store *db.Store, pagingSq
 	return users, nil
 }
 
+func (g *TenantUserStrategy) CountUsers(c *gin.Context, store *db.Store, filter UserListFilter) (int64, error) {
+	return store.CountSharedUsersByTenantAllStatuses(c, repository.CountSharedUsersByTenantAllStatusesParams{
+		TenantID:         g.tenantID,
+		Search:           filter.Search,
+		SearchBlindIndex: searchBlindIndex(filter.Search),
+		InactiveSince:    filter.InactiveSince,
+		Role:             filter.Role,
+		Labels:           filter.Labels,
+		Disabled:         filter.Disabled,
+		EmailVerified:    filter.EmailVerified,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
+	})
+}
+
 func (g *TenantUserStrategy) AssignRole(qtx *repository.Queries, c *gin.Context, authClient auth.AuthClient, tenantId string, userID string, role core.Role) error {
 	err := auth.HasRightsForRole(c, role)
 	if err != nil {
@@ -61,7 +61,8 @@ type UserStrategy interface {
 	CreateUser(c context.Context, authClient auth.AuthClient, qtx *repository.Queries, userRecord *auth.UserRecord, req core.NewUser, password *string) (repository.CoreUser, error)
 	UpdateUser(c context.Context, authClient auth.AuthClient, qtx *repository.Queries, req core.UpdateUserJSONRequestBody) error
 	UpdateSharedProfile(ctx context.Context, store *db.Store, userID string, req subentity.UserProfile) error
-	ListUsers(c *gin.Context, store *db.Store, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error)
+	ListUsers(c *gin.Context, store *db.Store, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error)
+	CountUsers(c *gin.Context, store *db.Store, filter UserListFilter) (int64, error)
 	AssignRole(qtx *repository.Queries, c *gin.Context, authClient auth.AuthClient, tenantId string, userID string, role core.Role) error
 	UnAssignRole(qtx *repository.Queries, c *gin.Context, authClient auth.AuthClient, tenantId string, userID string, role core.Role) error
 }
@@ -106,9 +107,19 @@ func (uh *SharedUserService) CreateUser(c context.Context, authClient auth.AuthC
 		if err := validateTenantScopedRoles(req.Roles); err != nil {
 			return user, err
 		}
+		if err := NewTenantQuotaService(uh.store, nil).CheckQuota(c, tenantId, QuotaResourceUsers); err != nil {
+			return user, err
+		}
 	}
 
 	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if req.PhoneNumber != nil && *req.PhoneNumber != "" {
+		phoneNumber, err := NormalizePhoneNumber(*req.PhoneNumber)
+		if err != nil {
+			return user, err
+		}
+		req.PhoneNumber = &phoneNumber
+	}
 
 	tx, err := uh.store.ConnPool.Begin(c)
 	if err != nil {
@@ -121,7 +132,7 @@ func (uh *SharedUserService) CreateUser(c context.Context, authClient auth.AuthC
 		Email(req.Email).
 		EmailVerified(false).
 		DisplayName(req.Name).
-		PhotoURL("/images/avatar-1.jpeg").
+		PhotoURL(GetTenantAssets(c, qtx, tenantId).DefaultAvatarURL).
 		Disabled(false)
 
 	if password != nil {
@@ -141,6 +152,7 @@ func (uh *SharedUserService) CreateUser(c context.Context, authClient auth.AuthC
 			if err = tx.Commit(c); err != nil {
 				return user, err
 			}
+			uh.recordAudit(c, tenantId, user.ID, UserAuditActionRoleAssigned, map[string]interface{}{"roles": req.Roles})
 			return user, nil
 		}
 		return user, err
@@ -156,6 +168,7 @@ func (uh *SharedUserService) CreateUser(c context.Context, authClient auth.AuthC
 	if err != nil {
 		return user, err
 	}
+	uh.recordAudit(c, tenantId, user.ID, UserAuditActionCreated, map[string]interface{}{"roles": req.Roles})
 
 	// Call the optional callbacks if set. UserCreatedCallback fires for every
 	// new user. UserAddedToTenantCallback also fires here because CreateUser
@@ -188,13 +201,18 @@ func (uh *SharedUserService) attachGlobalRolesToExistingUser(
 	if err != nil {
 		// No DB row yet (auth-provider-only user) — create one carrying the
 		// requested global roles.
+		email, blindIndex, encryptErr := EncryptEmail(req.Email)
+		if encryptErr != nil {
+			return user, encryptErr
+		}
 		user, err = qtx.CreateSharedUser(c, repository.CreateSharedUserParams{
 			ID:    authUser.UID,
-			Email: req.Email,
+			Email: email,
 			Profile: subentity.UserProfile{
 				Name: req.Name,
 			},
-			Roles: convertToRoles(req.Roles),
+			Roles:           convertToRoles(req.Roles),
+			EmailBlindIndex: blindIndex,
 		})
 		if err != nil {
 			return user, err
@@ -217,7 +235,7 @@ func (uh *SharedUserService) attachGlobalRolesToExistingUser(
 	if err = authClient.SetCustomUserClaims(c, authUser.UID, claims); err != nil {
 		return user, err
 	}
-	return user, nil
+	return DecryptUserEmail(user), nil
 }
 
 func mergeRoleStrings(existing, additions []string) []string {
@@ -259,17 +277,32 @@ func (uh *SharedUserService) UpdateUser(c *gin.Context, authClient auth.AuthClie
 	}
 
 	err = tx.Commit(c)
-
-	return err
+	if err != nil {
+		return err
+	}
+	uh.recordAudit(c, tenantId, userId, UserAuditActionUpdated, map[string]interface{}{
+		"name":  req.Name,
+		"roles": req.Roles,
+	})
+	return nil
 }
 
+// UpdateUserProfileInDatabase replaces the profile of the user. The phone
+// number stays verified only when it is unchanged, see PhoneVerificationService.
 func (uh *SharedUserService) UpdateUserProfileInDatabase(ctx context.Context, tenantId string, userID string, req subentity.UserProfile) error {
+	req.PhoneNumberVerified = false
+	if current, err := uh.store.GetSharedUserByID(ctx, userID); err == nil {
+		req.PhoneNumberVerified = current.Profile.PhoneNumberVerified && current.Profile.PhoneNumber == req.PhoneNumber
+	}
 	strategy := uh.getStrategy(tenantId)
 	err := strategy.UpdateSharedProfile(ctx, uh.store, userID, req)
 	return err
 }
 
-func (uh *SharedUserService) DeleteUser(c *gin.Context, authClient auth.AuthClient, userId string) error {
+// DeleteUser removes the user globally. The resources they own in any tenant
+// go to the transfer target; without one the deletion fails with
+// ErrUserOwnsResources when there are any.
+func (uh *SharedUserService) DeleteUser(c *gin.Context, authClient auth.AuthClient, userId string, transfer OwnershipTransfer) error {
 	logger := util.GetLoggerFromCtx(c)
 
 	tx, err := uh.store.ConnPool.Begin(c)
@@ -280,6 +313,10 @@ func (uh *SharedUserService) DeleteUser(c *gin.Context, authClient auth.AuthClie
 	defer tx.Rollback(c)
 	qtx := uh.store.Queries.WithTx(tx)
 
+	if err := transferOwnedResources(c, tx, "", userId, transfer, c.GetString(auth.AUTH_USER_ID)); err != nil {
+		return err
+	}
+
 	_, err = qtx.DeleteSharedUser(c, userId)
 	if err != nil {
 		logger.Err(err).Str("user_id", userId).Msg("Failed to delete user from database")
@@ -299,12 +336,26 @@ func (uh *SharedUserService) DeleteUser(c *gin.Context, authClient auth.AuthClie
 
 	if err != nil {
 		logger.Err(err).Msg("Failed to commit transaction")
+		return err
 	}
-
-	return err
+	// The trail of a global deletion is kept in the tenant it was made from
+	uh.recordAudit(c, c.GetString(auth.AUTH_TENANT_ID_KEY), userId, UserAuditActionDeleted, transferDetails(transfer))
+	return nil
 }
 
 func (uh *SharedUserService) RemoveUserFromTenant(c *gin.Context, authClient auth.AuthClient, tenantId string, userId string) error {
+	return uh.removeUserFromTenant(c, tenantId, userId, nil)
+}
+
+// DeleteUserFromTenant deactivates the user's membership like
+// RemoveUserFromTenant, after handing the resources they own in the tenant to
+// the transfer target. Without one the deletion fails with
+// ErrUserOwnsResources when there are any.
+func (uh *SharedUserService) DeleteUserFromTenant(c *gin.Context, authClient auth.AuthClient, tenantId string, userId string, transfer OwnershipTransfer) error {
+	return uh.removeUserFromTenant(c, tenantId, userId, &transfer)
+}
+
+func (uh *SharedUserService) removeUserFromTenant(c *gin.Context, tenantId string, userId string, transfer *OwnershipTransfer) error {
 	logger := util.GetLoggerFromCtx(c)
 	tx, err := uh.store.ConnPool.Begin(c)
 	if err != nil {
@@ -314,6 +365,12 @@ func (uh *SharedUserService) RemoveUserFromTenant(c *gin.Context, authClient aut
 	defer tx.Rollback(c)
 	qtx := uh.store.Queries.WithTx(tx)
 
+	if transfer != nil {
+		if err := transferOwnedResources(c, tx, tenantId, userId, *transfer, c.GetString(auth.AUTH_USER_ID)); err != nil {
+			return err
+		}
+	}
+
 	_, err = qtx.DeleteSharedUserByTenant(c, repository.DeleteSharedUserByTenantParams{
 		UserID:   userId,
 		TenantID: tenantId,
@@ -327,9 +384,16 @@ func (uh *SharedUserService) RemoveUserFromTenant(c *gin.Context, authClient aut
 
 	if err != nil {
 		logger.Err(err).Msg("Failed to commit transaction")
+		return err
 	}
 
-	return err
+	uh.recordActivity(c, tenantId, userId, ActivityCategoryMembership, "removed", nil)
+	if transfer != nil {
+		uh.recordAudit(c, tenantId, userId, UserAuditActionDeleted, transferDetails(*transfer))
+	} else {
+		uh.recordAudit(c, tenantId, userId, UserAuditActionRemovedFromTenant, nil)
+	}
+	return nil
 }
 
 func (uh *SharedUserService) GetFullUserByID(c *gin.Context, authClient auth.AuthClient, tenantID string, id string) (FullUser, error) {
@@ -356,7 +420,7 @@ func (uh *SharedUserService) GetFullUserByID(c *gin.Context, authClient auth.Aut
 	user := core.User{
 		Id:        coreUser.ID,
 		Name:      coreUser.Profile.Name,
-		Email:     coreUser.Email.String,
+		Email:     DecryptEmail(coreUser.Email).String,
 		Roles:     roles,
 		CreatedAt: &coreUser.CreatedAt,
 	}
@@ -378,8 +442,9 @@ func (uh *SharedUserService) GetUserByEmail(c *gin.Context, tenantID string, ema
 	logger := util.GetLoggerFromCtx(c)
 	fullUser := core.User{}
 	dbUser, err := uh.store.GetSharedUserByTenantByEmail(c, repository.GetSharedUserByTenantByEmailParams{
-		Email:    email,
-		TenantID: tenantID,
+		Email:           email,
+		EmailBlindIndex: EmailBlindIndex(email),
+		TenantID:        tenantID,
 	})
 	if err != nil {
 		logger.Err(err).Str("email", email).Msg("Failed to get user from database")
@@ -396,23 +461,42 @@ func (uh *SharedUserService) GetUserByEmail(c *gin.Context, tenantID string, ema
 	user := core.User{
 		Id:        dbUser.ID,
 		Name:      dbUser.Profile.Name,
-		Email:     dbUser.Email.String,
+		Email:     DecryptEmail(dbUser.Email).String,
 		Roles:     roles,
 		CreatedAt: &dbUser.CreatedAt,
 	}
 	return user, nil
 }
 
-func (uh *SharedUserService) ListUsers(c *gin.Context, tenantId string, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error) {
+func (uh *SharedUserService) ListUsers(c *gin.Context, tenantId string, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error) {
 	strategy := uh.getStrategy(tenantId)
-	return strategy.ListUsers(c, uh.store, pagingSql, like)
+	users, err := strategy.ListUsers(c, uh.store, pagingSql, filter)
+	if err != nil {
+		return users, err
+	}
+	// The global users log in on any tenant
+	loginTenant := pgtype.Text{}
+	if strategy.Strategy() != StrategyTypeGlobal {
+		loginTenant = pgtype.Text{String: tenantId, Valid: true}
+	}
+	if err := setLastLogins(c, uh.store, users, loginTenant); err != nil {
+		return users, err
+	}
+	return users, setAccountStates(c, uh.store, users)
 }
 
-func (uh *SharedUserService) ListAllUsers(c *gin.Context, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error) {
+func (uh *SharedUserService) ListAllUsers(c *gin.Context, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error) {
 	rows, err := uh.store.ListSharedUsers(c, repository.ListSharedUsersParams{
-		Limit:        pagingSql.PageSize,
-		Offset:       pagingSql.Offset,
-		SearchPrefix: like,
+		Limit:            pagingSql.PageSize,
+		Offset:           pagingSql.Offset,
+		Search:           filter.Search,
+		SearchBlindIndex: searchBlindIndex(filter.Search),
+		InactiveSince:    filter.InactiveSince,
+		Role:             filter.Role,
+		Disabled:         filter.Disabled,
+		EmailVerified:    filter.EmailVerified,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
 	})
 	if err != nil {
 		return []core.User{}, err
@@ -423,12 +507,32 @@ func (uh *SharedUserService) ListAllUsers(c *gin.Context, pagingSql sqlservice.P
 		users[i] = core.User{
 			Id:        row.ID,
 			Name:      row.Profile.Name,
-			Email:     row.Email.String,
+			Email:     DecryptEmail(row.Email).String,
 			Roles:     convertToRoleDTOs(row.Roles),
 			CreatedAt: &row.CreatedAt,
 		}
 	}
-	return users, nil
+	if err := setLastLogins(c, uh.store, users, pgtype.Text{}); err != nil {
+		return users, err
+	}
+	return users, setAccountStates(c, uh.store, users)
+}
+
+func (uh *SharedUserService) CountUsers(c *gin.Context, tenantId string, filter UserListFilter) (int64, error) {
+	return uh.getStrategy(tenantId).CountUsers(c, uh.store, filter)
+}
+
+func (uh *SharedUserService) CountAllUsers(c *gin.Context, filter UserListFilter) (int64, error) {
+	return uh.store.CountSharedUsers(c, repository.CountSharedUsersParams{
+		Search:           filter.Search,
+		SearchBlindIndex: searchBlindIndex(filter.Search),
+		InactiveSince:    filter.InactiveSince,
+		Role:             filter.Role,
+		Disabled:         filter.Disabled,
+		EmailVerified:    filter.EmailVerified,
+		CreatedFrom:      filter.CreatedFrom,
+		CreatedTo:        filter.CreatedTo,
+	})
 }
 
 func (uh *SharedUserService) AssignRole(c *gin.Context, authClient auth.AuthClient, tenantId string, userID string, role core.Role) error {
@@ -459,6 +563,8 @@ func (uh *SharedUserService) AssignRole(c *gin.Context, authClient auth.AuthClie
 		logger.Err(err).Msg("Failed to commit transaction")
 		return err
 	}
+	uh.recordActivity(c, tenantId, userID, ActivityCategoryMembership, "role_assigned", map[string]interface{}{"role": role})
+	uh.recordAudit(c, tenantId, userID, UserAuditActionRoleAssigned, map[string]interface{}{"role": role})
 	return nil
 }
 
@@ -485,6 +591,8 @@ func (uh *SharedUserService) UnassignRole(c *gin.Context, authClient auth.AuthCl
 		logger.Err(err).Msg("Failed to commit transaction")
 		return err
 	}
+	uh.recordActivity(c, tenantId, userID, ActivityCategoryMembership, "role_unassigned", map[string]interface{}{"role": role})
+	uh.recordAudit(c, tenantId, userID, UserAuditActionRoleUnassigned, map[string]interface{}{"role": role})
 	return nil
 }
 func (uh *SharedUserService) UpdateUserStatus(c *gin.Context, authClient auth.AuthClient, tenantId string, userID string, requestName string, requestValue bool) error {
@@ -502,14 +610,65 @@ func (uh *SharedUserService) UpdateUserStatus(c *gin.Context, authClient auth.Au
 		logger.Err(err).Str("user_id", userID).Msg("Failed to update user status")
 		return err
 	}
+	switch requestName {
+	case "EMAIL_VERIFIED":
+		recordUserAccountState(c, uh.store, userID, pgtype.Bool{}, pgtype.Bool{Bool: requestValue, Valid: true})
+	case "DISABLED":
+		recordUserAccountState(c, uh.store, userID, pgtype.Bool{Bool: requestValue, Valid: true}, pgtype.Bool{})
+	}
+	status := map[string]interface{}{
+		"status": requestName,
+		"value":  requestValue,
+	}
+	uh.recordActivity(c, tenantId, userID, ActivityCategoryAccount, "status_changed", status)
+	uh.recordAudit(c, tenantId, userID, UserAuditActionStatusChanged, status)
 	return nil
 }
 
+// recordActivity adds an admin action on userID to the user's timeline. The
+// change is already committed, so a failure is only logged.
+func (uh *SharedUserService) recordActivity(c *gin.Context, tenantID string, userID string, category string, eventType string, data map[string]interface{}) {
+	_ = recordUserActivity(c, uh.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  category,
+		EventType: eventType,
+		ActorID:   c.GetString(auth.AUTH_USER_ID),
+		Data:      data,
+	})
+}
+
+// recordAudit adds a change of userID to the audit trail of the tenant. The
+// change is already committed, so a failure is only logged.
+func (uh *SharedUserService) recordAudit(c context.Context, tenantID string, userID string, action string, details map[string]interface{}) {
+	_ = recordUserAudit(c, uh.store, UserAuditEntry{
+		TenantID: tenantID,
+		UserID:   userID,
+		Action:   action,
+		ActorID:  auditActor(c),
+		Details:  details,
+	})
+}
+
+// transferDetails describes where the resources of a deleted user went
+func transferDetails(transfer OwnershipTransfer) map[string]interface{} {
+	if !transfer.isSet() {
+		return nil
+	}
+	if transfer.ToTenantOwner {
+		return map[string]interface{}{"transferred_to": "tenant_owner"}
+	}
+	return map[string]interface{}{"transferred_to": transfer.ToUserID}
+}
+
 // AddUserToTenant adds an existing user to a tenant (creates membership)
 func (uh *SharedUserService) AddUserToTenant(c context.Context, authClient auth.AuthClient, tenantID, userID string, roles []core.Role, invitedBy string) error {
 	if err := validateTenantScopedRoles(roles); err != nil {
 		return err
 	}
+	if err := NewTenantQuotaService(uh.store, nil).CheckQuota(c, tenantID, QuotaResourceUsers); err != nil {
+		return err
+	}
 
 	logger := util.GetLoggerFromCtx(c)
 	// Check if user exists
@@ -591,7 +750,7 @@ func (uh *SharedUserService) GetUserByTenantIDByID(c *gin.Context, tenantID stri
 	user := core.User{
 		Id:    dbUser.ID,
 		Name:  dbUser.Profile.Name,
-		Email: dbUser.Email.String,
+		Email: DecryptEmail(dbUser.Email).String,
 		Profile: &core.UserProfileSchema{
 			Name:                 dbUser.Profile.Name,
 			Title:                &dbUser.Profile.Title,
@@ -602,12 +761,23 @@ func (uh *SharedUserService) GetUserByTenantIDByID(c *gin.Context, tenantID stri
 			Interests:            &dbUser.Profile.Interests,
 			Skills:               &dbUser.Profile.Skills,
 			PhoneNumber:          &dbUser.Profile.PhoneNumber,
+			PhoneNumberVerified:  &dbUser.Profile.PhoneNumberVerified,
 			Function:             &dbUser.Profile.Function,
 			Company:              &dbUser.Profile.Company,
+			Attributes:           profileAttributes(dbUser.Profile.Attributes),
 		},
 		Roles:     roles,
 		CreatedAt: &dbUser.CreatedAt,
 	}
 
-	return user, err
+	loginTenant := pgtype.Text{}
+	if strategy.Strategy() != StrategyTypeGlobal {
+		loginTenant = pgtype.Text{String: tenantID, Valid: true}
+	}
+	users := []core.User{user}
+	if err := setLastLogins(c, uh.store, users, loginTenant); err != nil {
+		logger.Err(err).Str("user_id", id).Msg("Failed to get the last login of the user")
+		return core.User{}, err
+	}
+	return users[0], nil
 }
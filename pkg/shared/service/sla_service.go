@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// SLAItemInvitation is the item type of a pending tenant invitation
+const SLAItemInvitation = "invitation"
+
+const (
+	DefaultInvitationSLATarget = 72 * time.Hour
+	DefaultSLACheckInterval    = 15 * time.Minute
+	DefaultSLAOverdueItems     = 100
+	MaxSLAOverdueItems         = 500
+	SLABreachedWebhookEvent    = "sla.breached"
+	slaBreachedEmailTemplate   = "email-sla-breached.html"
+	slaBreachedEmailSubject    = "A pending request is overdue"
+	slaScanBatchSize           = 100
+	slaEscalationMaxItems      = 1000
+	slaNotificationTimeout     = 30 * time.Second
+)
+
+// ErrUnknownSLAItemType is returned when listing an item type that has no
+// source or no target
+var ErrUnknownSLAItemType = errors.New("unknown SLA item type")
+
+// SLAItem is a pending item tracked against its SLA target
+type SLAItem struct {
+	Type     string
+	ID       string
+	TenantID string
+	// Subject names the item for a human, such as the invited email
+	Subject      string
+	PendingSince time.Time
+	DueAt        time.Time
+	// EscalatedAt is set once the breach was notified
+	EscalatedAt *time.Time
+}
+
+// SLASource lists the pending items of one type. Both methods return the
+// items pending since before pendingBefore, oldest first.
+type SLASource interface {
+	// ListOverdue lists the items of the tenant, or of every tenant when
+	// tenantID is empty, with EscalatedAt set on the escalated ones
+	ListOverdue(ctx context.Context, tenantID string, pendingBefore time.Time, limit int32) ([]SLAItem, error)
+	// ListUnescalated lists the items of every tenant not escalated yet
+	ListUnescalated(ctx context.Context, pendingBefore time.Time, limit int32) ([]SLAItem, error)
+}
+
+var (
+	slaSourcesMu sync.RWMutex
+	slaSources   = map[string]func(store *db.Store) SLASource{
+		SLAItemInvitation: func(store *db.Store) SLASource { return invitationSLASource{store: store} },
+	}
+)
+
+// RegisterSLASource tracks the items of another type, such as the approval
+// requests of a module. Escalations of any type are recorded in
+// core_sla_escalations, keyed by type, ID and pending time. The type gets
+// a target through SLA_TARGETS.
+func RegisterSLASource(itemType string, newSource func(store *db.Store) SLASource) {
+	slaSourcesMu.Lock()
+	defer slaSourcesMu.Unlock()
+	slaSources[itemType] = newSource
+}
+
+// SLAConfig configures the targets and the escalation job.
+//
+// Environment:
+//   - SLA_TARGETS: comma separated type=duration pairs, such as
+//     "invitation=48h,approval=24h" (default invitation=72h, 0 stops tracking a type)
+//   - SLA_CHECK_INTERVAL: escalation scan interval (default 15m, 0 disables the job)
+//   - SLA_ESCALATION_WEBHOOK_URL / SLA_ESCALATION_WEBHOOK_SECRET: optional webhook target
+type SLAConfig struct {
+	Targets       map[string]time.Duration
+	Interval      time.Duration
+	WebhookURL    string
+	WebhookSecret string
+}
+
+func SLAConfigFromEnv() SLAConfig {
+	cfg := SLAConfig{
+		Targets:       map[string]time.Duration{SLAItemInvitation: DefaultInvitationSLATarget},
+		Interval:      DefaultSLACheckInterval,
+		WebhookURL:    os.Getenv("SLA_ESCALATION_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("SLA_ESCALATION_WEBHOOK_SECRET"),
+	}
+	if v := os.Getenv("SLA_TARGETS"); v != "" {
+		if targets, err := parseSLATargets(v); err == nil {
+			for itemType, target := range targets {
+				cfg.Targets[itemType] = target
+			}
+		} else {
+			log.Warn().Err(err).Str("SLA_TARGETS", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("SLA_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("SLA_CHECK_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// parseSLATargets parses type=duration pairs separated by commas
+func parseSLATargets(v string) (map[string]time.Duration, error) {
+	targets := map[string]time.Duration{}
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		itemType, duration, ok := strings.Cut(pair, "=")
+		itemType = strings.TrimSpace(itemType)
+		if !ok || itemType == "" {
+			return nil, fmt.Errorf("%q is not a type=duration pair", pair)
+		}
+		target, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil || target < 0 {
+			return nil, fmt.Errorf("invalid duration for %q", itemType)
+		}
+		targets[itemType] = target
+	}
+	return targets, nil
+}
+
+// SLABreachNotification is the email data and webhook payload of an item that
+// exceeded its target
+type SLABreachNotification struct {
+	ItemType     string    `json:"itemType"`
+	ItemID       string    `json:"itemId"`
+	TenantID     string    `json:"tenantId,omitempty"`
+	Subject      string    `json:"subject"`
+	PendingSince time.Time `json:"pendingSince"`
+	DueAt        time.Time `json:"dueAt"`
+}
+
+// SLAService measures pending items against the target of their type
+type SLAService struct {
+	store *db.Store
+	cfg   SLAConfig
+}
+
+func NewSLAService(store *db.Store, cfg SLAConfig) *SLAService {
+	return &SLAService{store: store, cfg: cfg}
+}
+
+// trackedSources returns the sources of the types with a positive target
+func (s *SLAService) trackedSources() map[string]SLASource {
+	slaSourcesMu.RLock()
+	defer slaSourcesMu.RUnlock()
+	sources := map[string]SLASource{}
+	for itemType, newSource := range slaSources {
+		if s.cfg.Targets[itemType] > 0 {
+			sources[itemType] = newSource(s.store)
+		}
+	}
+	return sources
+}
+
+// ListOverdue lists the items past their target, of one type or of every type
+// when itemType is empty, oldest first. An empty tenantID lists the items of
+// every tenant.
+func (s *SLAService) ListOverdue(ctx context.Context, tenantID, itemType string, limit int) ([]SLAItem, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if limit <= 0 {
+		limit = DefaultSLAOverdueItems
+	}
+	limit = min(limit, MaxSLAOverdueItems)
+
+	sources := s.trackedSources()
+	if itemType != "" {
+		source, ok := sources[itemType]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownSLAItemType, itemType)
+		}
+		sources = map[string]SLASource{itemType: source}
+	}
+
+	now := time.Now()
+	items := []SLAItem{}
+	for t, source := range sources {
+		target := s.cfg.Targets[t]
+		overdue, err := source.ListOverdue(ctx, tenantID, now.Add(-target), int32(limit))
+		if err != nil {
+			logger.Err(err).Str("itemType", t).Msg("Failed to list overdue SLA items")
+			return nil, fmt.Errorf("service.ListOverdue: %w", err)
+		}
+		for _, item := range overdue {
+			item.DueAt = item.PendingSince.Add(target)
+			items = append(items, item)
+		}
+	}
+	sortSLAItems(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// sortSLAItems orders the items oldest first, across types
+func sortSLAItems(items []SLAItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].PendingSince.Equal(items[j].PendingSince) {
+			return items[i].PendingSince.Before(items[j].PendingSince)
+		}
+		if items[i].Type != items[j].Type {
+			return items[i].Type < items[j].Type
+		}
+		return items[i].ID < items[j].ID
+	})
+}
+
+// StartSLAEscalations runs EscalateOverdue now and then every interval until
+// ctx is done. It does nothing when the interval is 0.
+func (s *SLAService) StartSLAEscalations(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Info().Msg("SLA escalations disabled")
+		return
+	}
+	dispatcher := webhook.NewDispatcher()
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.EscalateOverdue(ctx, dispatcher); err != nil {
+				log.Err(err).Msg("SLA escalation scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// EscalateOverdue notifies the customer admins of the tenant, and the
+// configured webhook, of every item past its target that was not escalated
+// yet. The escalation is recorded before notifying, so an item is escalated
+// once even with several instances running. It returns the number of items
+// escalated.
+func (s *SLAService) EscalateOverdue(ctx context.Context, dispatcher *webhook.Dispatcher) (int, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	escalated := 0
+	for itemType, source := range s.trackedSources() {
+		target := s.cfg.Targets[itemType]
+		for escalated < slaEscalationMaxItems {
+			items, err := source.ListUnescalated(ctx, time.Now().Add(-target), slaScanBatchSize)
+			if err != nil {
+				logger.Err(err).Str("itemType", itemType).Msg("Failed to list overdue SLA items")
+				return escalated, fmt.Errorf("service.EscalateOverdue: %w", err)
+			}
+			if len(items) == 0 {
+				break
+			}
+			for _, item := range items {
+				item.Type = itemType
+				item.DueAt = item.PendingSince.Add(target)
+				claimed, err := s.escalate(ctx, dispatcher, item)
+				if err != nil {
+					return escalated, fmt.Errorf("service.EscalateOverdue: %w", err)
+				}
+				if claimed {
+					escalated++
+				}
+			}
+			if len(items) < slaScanBatchSize {
+				break
+			}
+		}
+	}
+	if escalated > 0 {
+		logger.Info().Int("items", escalated).Msg("Escalated overdue SLA items")
+	}
+	return escalated, nil
+}
+
+// escalate records the escalation then notifies. It reports false when
+// another instance already escalated the item.
+func (s *SLAService) escalate(ctx context.Context, dispatcher *webhook.Dispatcher, item SLAItem) (bool, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	_, err := s.store.CreateSLAEscalation(ctx, repository.CreateSLAEscalationParams{
+		ItemType:     item.Type,
+		ItemID:       item.ID,
+		PendingSince: item.PendingSince,
+		TenantID:     item.TenantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		logger.Err(err).Str("itemType", item.Type).Str("itemID", item.ID).Msg("Failed to record SLA escalation")
+		return false, err
+	}
+
+	notification := SLABreachNotification{
+		ItemType:     item.Type,
+		ItemID:       item.ID,
+		TenantID:     item.TenantID,
+		Subject:      item.Subject,
+		PendingSince: item.PendingSince,
+		DueAt:        item.DueAt,
+	}
+	notifyCtx, cancel := context.WithTimeout(ctx, slaNotificationTimeout)
+	defer cancel()
+
+	// The escalation stays recorded when notifying fails, and the item stays
+	// listed on the dashboard
+	if item.TenantID != "" {
+		emails, err := s.store.ListTenantAdminEmails(notifyCtx, item.TenantID)
+		if err != nil {
+			logger.Err(err).Str("tenantID", item.TenantID).Msg("Failed to list tenant admins for SLA escalation")
+		}
+		recipients := make([]string, 0, len(emails))
+		for _, email := range emails {
+			if plain := DecryptEmail(email); plain.Valid && plain.String != "" {
+				recipients = append(recipients, plain.String)
+			}
+		}
+		if len(recipients) > 0 {
+			if err := sendSLABreachedEmail(recipients, notification, GetTenantAssets(ctx, s.store.Queries, item.TenantID)); err != nil {
+				logger.Err(err).Str("itemType", item.Type).Str("itemID", item.ID).Msg("Failed to send SLA escalation email")
+			}
+		}
+	}
+	if s.cfg.WebhookURL != "" && dispatcher != nil {
+		envelope := webhook.Envelope{
+			ID:        uuid.New().String(),
+			EventType: SLABreachedWebhookEvent,
+			CreatedAt: time.Now().UTC(),
+			Data:      notification,
+		}
+		target := webhook.Target{URL: s.cfg.WebhookURL, Secret: s.cfg.WebhookSecret}
+		if err := dispatcher.Deliver(notifyCtx, target, envelope, ""); err != nil {
+			logger.Err(err).Str("itemType", item.Type).Str("itemID", item.ID).Msg("Failed to deliver SLA escalation webhook")
+		}
+	}
+	return true, nil
+}
+
+func sendSLABreachedEmail(toEmails []string, notification SLABreachNotification, assets TenantAssets) error {
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	r := emailservice.NewEmailRequest(fromEmail, toEmails, slaBreachedEmailSubject, "")
+	r.Assets = assets.Email()
+	r.TenantID = notification.TenantID
+	if err := r.ParseTemplate(filepath.Join("templates", slaBreachedEmailTemplate), notification); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
+
+// invitationSLASource tracks the pending tenant memberships, from the time
+// they were last invited
+type invitationSLASource struct {
+	store *db.Store
+}
+
+func (s invitationSLASource) ListOverdue(ctx context.Context, tenantID string, pendingBefore time.Time, limit int32) ([]SLAItem, error) {
+	rows, err := s.store.ListOverdueInvitations(ctx, repository.ListOverdueInvitationsParams{
+		PendingBefore: pendingBefore,
+		TenantID:      pgtype.Text{String: tenantID, Valid: tenantID != ""},
+		RowLimit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]SLAItem, len(rows))
+	for i, row := range rows {
+		items[i] = SLAItem{
+			Type:         SLAItemInvitation,
+			ID:           row.ID.String(),
+			TenantID:     row.TenantID,
+			Subject:      DecryptEmail(row.Email).String,
+			PendingSince: row.PendingSince,
+		}
+		if row.EscalatedAt.Valid {
+			escalatedAt := row.EscalatedAt.Time
+			items[i].EscalatedAt = &escalatedAt
+		}
+	}
+	return items, nil
+}
+
+func (s invitationSLASource) ListUnescalated(ctx context.Context, pendingBefore time.Time, limit int32) ([]SLAItem, error) {
+	rows, err := s.store.ListUnescalatedOverdueInvitations(ctx, repository.ListUnescalatedOverdueInvitationsParams{
+		PendingBefore: pendingBefore,
+		BatchSize:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]SLAItem, len(rows))
+	for i, row := range rows {
+		items[i] = SLAItem{
+			Type:         SLAItemInvitation,
+			ID:           row.ID.String(),
+			TenantID:     row.TenantID,
+			Subject:      DecryptEmail(row.Email).String,
+			PendingSince: row.PendingSince,
+		}
+	}
+	return items, nil
+}
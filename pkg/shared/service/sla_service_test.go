@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSLATargets(t *testing.T) {
+	targets, err := parseSLATargets(" invitation=48h, approval = 24h ,signup=0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{
+		"invitation": 48 * time.Hour,
+		"approval":   24 * time.Hour,
+		"signup":     0,
+	}, targets)
+
+	for _, v := range []string{"invitation", "=24h", "invitation=soon", "invitation=-1h"} {
+		_, err := parseSLATargets(v)
+		require.Error(t, err, v)
+	}
+}
+
+func TestSLAConfigFromEnv(t *testing.T) {
+	t.Setenv("SLA_TARGETS", "approval=24h")
+	t.Setenv("SLA_CHECK_INTERVAL", "0")
+	cfg := SLAConfigFromEnv()
+	require.Equal(t, DefaultInvitationSLATarget, cfg.Targets[SLAItemInvitation])
+	require.Equal(t, 24*time.Hour, cfg.Targets["approval"])
+	require.Zero(t, cfg.Interval)
+
+	t.Setenv("SLA_TARGETS", "invitation=tomorrow")
+	cfg = SLAConfigFromEnv()
+	require.Equal(t, map[string]time.Duration{SLAItemInvitation: DefaultInvitationSLATarget}, cfg.Targets)
+}
+
+func TestSortSLAItems(t *testing.T) {
+	now := time.Now()
+	items := []SLAItem{
+		{Type: SLAItemInvitation, ID: "b", PendingSince: now.Add(-time.Hour)},
+		{Type: "approval", ID: "c", PendingSince: now.Add(-2 * time.Hour)},
+		{Type: SLAItemInvitation, ID: "a", PendingSince: now.Add(-time.Hour)},
+	}
+	sortSLAItems(items)
+	require.Equal(t, []string{"c", "a", "b"}, []string{items[0].ID, items[1].ID, items[2].ID})
+}
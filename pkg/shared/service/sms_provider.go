@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SMS providers
+const (
+	SMSProviderNone   = "none"
+	SMSProviderLog    = "log"
+	SMSProviderTwilio = "twilio"
+)
+
+const (
+	twilioBaseURL       = "https://api.twilio.com"
+	smsResponseLimit    = 1 << 20
+	twilioAccountPrefix = "AC"
+)
+
+// ErrSMSUnavailable is returned when no SMS provider is configured
+var ErrSMSUnavailable = errors.New("sms is not configured")
+
+// SMSProvider sends the text messages, such as the phone verification codes.
+// The number is in the E.164 format.
+type SMSProvider interface {
+	SendSMS(ctx context.Context, to string, body string) error
+}
+
+// noSMSProvider refuses every message, so the features needing SMS answer
+// that they are unavailable instead of failing silently
+type noSMSProvider struct{}
+
+func (noSMSProvider) SendSMS(context.Context, string, string) error {
+	return ErrSMSUnavailable
+}
+
+// LogSMSProvider writes the messages to the log instead of sending them. It is
+// meant for development, the log holds the codes.
+type LogSMSProvider struct{}
+
+func (LogSMSProvider) SendSMS(_ context.Context, to string, body string) error {
+	log.Info().Str("to", to).Str("body", body).Msg("SMS not sent, log provider")
+	return nil
+}
+
+// TwilioSMSProvider sends the messages through the Messages resource of the
+// Twilio REST API. From is a number of the account, or MessagingServiceSID
+// picks the sender from a messaging service.
+type TwilioSMSProvider struct {
+	AccountSID          string
+	AuthToken           string
+	From                string
+	MessagingServiceSID string
+	// BaseURL defaults to https://api.twilio.com
+	BaseURL string
+	Client  *http.Client
+}
+
+func (p *TwilioSMSProvider) SendSMS(ctx context.Context, to string, body string) error {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = twilioBaseURL
+	}
+	form := url.Values{"To": {to}, "Body": {body}}
+	if p.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", p.MessagingServiceSID)
+	} else {
+		form.Set("From", p.From)
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimSuffix(baseURL, "/"), url.PathEscape(p.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.AccountSID, p.AuthToken)
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	// The error body holds a code and a message, never the text sent
+	var twilioErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, smsResponseLimit))
+	if json.Unmarshal(data, &twilioErr) == nil && twilioErr.Message != "" {
+		return fmt.Errorf("twilio: status %d, error %d: %s", resp.StatusCode, twilioErr.Code, twilioErr.Message)
+	}
+	return fmt.Errorf("twilio: unexpected status %d", resp.StatusCode)
+}
+
+// SMSProviderFromEnv selects the SMS provider. Without one, the phone numbers
+// cannot be verified.
+//
+// Environment:
+//   - SMS_PROVIDER: none (default), log or twilio
+//   - TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN: credentials of the account
+//   - TWILIO_FROM_NUMBER or TWILIO_MESSAGING_SERVICE_SID: sender
+func SMSProviderFromEnv() SMSProvider {
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "", SMSProviderNone:
+		return noSMSProvider{}
+	case SMSProviderLog:
+		return LogSMSProvider{}
+	case SMSProviderTwilio:
+		p := &TwilioSMSProvider{
+			AccountSID:          os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:           os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:                os.Getenv("TWILIO_FROM_NUMBER"),
+			MessagingServiceSID: os.Getenv("TWILIO_MESSAGING_SERVICE_SID"),
+		}
+		if !strings.HasPrefix(p.AccountSID, twilioAccountPrefix) || p.AuthToken == "" || (p.From == "" && p.MessagingServiceSID == "") {
+			log.Error().Msg("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER or TWILIO_MESSAGING_SERVICE_SID are required by the twilio sms provider, sms are disabled")
+			return noSMSProvider{}
+		}
+		return p
+	default:
+		log.Warn().Str("SMS_PROVIDER", provider).Msg("Invalid value, sms are disabled")
+		return noSMSProvider{}
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwilioSMSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "AC123", user)
+		require.Equal(t, "secret", password)
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("To") == "+15005550001" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":21211,"message":"The 'To' number is not a valid phone number.","status":400}`))
+			return
+		}
+		require.Equal(t, "+14155550123", r.PostForm.Get("To"))
+		require.Equal(t, "+14155550100", r.PostForm.Get("From"))
+		require.Equal(t, "Your code is 123456", r.PostForm.Get("Body"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	twilio := &TwilioSMSProvider{AccountSID: "AC123", AuthToken: "secret", From: "+14155550100", BaseURL: server.URL}
+	require.NoError(t, twilio.SendSMS(context.Background(), "+14155550123", "Your code is 123456"))
+
+	err := twilio.SendSMS(context.Background(), "+15005550001", "Your code is 123456")
+	require.ErrorContains(t, err, "21211")
+}
+
+func TestSMSProviderFromEnv(t *testing.T) {
+	t.Setenv("SMS_PROVIDER", "")
+	require.ErrorIs(t, SMSProviderFromEnv().SendSMS(context.Background(), "+14155550123", "code"), ErrSMSUnavailable)
+
+	t.Setenv("SMS_PROVIDER", SMSProviderLog)
+	require.IsType(t, LogSMSProvider{}, SMSProviderFromEnv())
+
+	// Twilio without a sender is disabled rather than failing on every message
+	t.Setenv("SMS_PROVIDER", SMSProviderTwilio)
+	t.Setenv("TWILIO_ACCOUNT_SID", "AC123")
+	t.Setenv("TWILIO_AUTH_TOKEN", "secret")
+	t.Setenv("TWILIO_FROM_NUMBER", "")
+	t.Setenv("TWILIO_MESSAGING_SERVICE_SID", "")
+	require.IsType(t, noSMSProvider{}, SMSProviderFromEnv())
+
+	t.Setenv("TWILIO_FROM_NUMBER", "+14155550100")
+	require.IsType(t, &TwilioSMSProvider{}, SMSProviderFromEnv())
+}
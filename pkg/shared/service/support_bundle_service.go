@@ -0,0 +1,451 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/internal/version"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/migration"
+	"ctoup.com/coreapp/pkg/shared/util"
+)
+
+const (
+	supportBundleMaxTables    = 50
+	supportBundleQueryTimeout = 10 * time.Second
+	supportBundleRedactedMail = "[EMAIL]"
+)
+
+// supportSensitiveEnvParts are redacted from the configuration on top of the
+// replaySensitiveKeys, the variable names being coarser than JSON keys
+var supportSensitiveEnvParts = []string{"key", "pass", "dsn"}
+
+var (
+	connStringPasswordPattern = regexp.MustCompile(`(?i)(password=)[^\s;]+`)
+	emailPattern              = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+var processStartedAt = time.Now()
+
+// HealthCheck is a component check of the server, such as the checks of
+// /readyz
+type HealthCheck interface {
+	Pass() bool
+	Name() string
+}
+
+var (
+	supportBundleChecksMu sync.RWMutex
+	supportBundleChecks   []HealthCheck
+)
+
+// SetSupportBundleChecks sets the component checks reported by the support
+// bundles
+func SetSupportBundleChecks(checks ...HealthCheck) {
+	supportBundleChecksMu.Lock()
+	defer supportBundleChecksMu.Unlock()
+	supportBundleChecks = slices.Clone(checks)
+}
+
+// SupportBundleManifest describes the content of a support bundle
+type SupportBundleManifest struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	GeneratedBy string    `json:"generated_by"`
+	Hostname    string    `json:"hostname"`
+	Version     string    `json:"version"`
+	Files       []string  `json:"files"`
+	// Errors are the sections that could not be collected, by file
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type supportVersionInfo struct {
+	Version        string    `json:"version"`
+	GoVersion      string    `json:"go_version"`
+	OS             string    `json:"os"`
+	Arch           string    `json:"arch"`
+	Module         string    `json:"module,omitempty"`
+	VCSRevision    string    `json:"vcs_revision,omitempty"`
+	VCSTime        string    `json:"vcs_time,omitempty"`
+	VCSModified    bool      `json:"vcs_modified,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	Uptime         string    `json:"uptime"`
+	Goroutines     int       `json:"goroutines"`
+	HeapAllocBytes uint64    `json:"heap_alloc_bytes"`
+	NumGC          uint32    `json:"num_gc"`
+}
+
+type supportConfigEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type supportPoolStats struct {
+	MaxConns             int32   `json:"max_conns"`
+	TotalConns           int32   `json:"total_conns"`
+	IdleConns            int32   `json:"idle_conns"`
+	AcquiredConns        int32   `json:"acquired_conns"`
+	AcquireCount         int64   `json:"acquire_count"`
+	EmptyAcquireCount    int64   `json:"empty_acquire_count"`
+	CanceledAcquireCount int64   `json:"canceled_acquire_count"`
+	AcquireDurationMs    float64 `json:"acquire_duration_ms"`
+}
+
+type supportTableStats struct {
+	Name            string     `json:"name"`
+	LiveRows        int64      `json:"live_rows"`
+	DeadRows        int64      `json:"dead_rows"`
+	SizeBytes       int64      `json:"size_bytes"`
+	LastAutovacuum  *time.Time `json:"last_autovacuum,omitempty"`
+	LastAutoanalyze *time.Time `json:"last_autoanalyze,omitempty"`
+}
+
+type supportDatabaseInfo struct {
+	ServerVersion string              `json:"server_version"`
+	SizeBytes     int64               `json:"size_bytes"`
+	Pool          supportPoolStats    `json:"pool"`
+	Tables        []supportTableStats `json:"tables"`
+}
+
+type supportMigrationStatus struct {
+	Applied         int        `json:"applied"`
+	LatestApplied   int64      `json:"latest_applied"`
+	LatestAppliedAt *time.Time `json:"latest_applied_at,omitempty"`
+	// Pending are the core migrations not applied
+	Pending []int64 `json:"pending"`
+	// OtherApplied counts the applied migrations that are not core ones,
+	// those of the modules of the application
+	OtherApplied int `json:"other_applied"`
+}
+
+type supportHealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// SupportBundleService assembles the diagnostic snapshot of the server that
+// operators attach to support tickets. Secrets and email addresses are
+// redacted from it.
+type SupportBundleService struct {
+	store *db.Store
+	logs  *util.RecentLogBuffer
+}
+
+func NewSupportBundleService(store *db.Store) *SupportBundleService {
+	return &SupportBundleService{store: store, logs: util.RecentLogs}
+}
+
+// Build returns the bundle as a zip archive and its file name. A section that
+// cannot be collected is left out and its error reported in the manifest.
+func (s *SupportBundleService) Build(ctx context.Context, actorID string) ([]byte, string, error) {
+	hostname, _ := os.Hostname()
+	manifest := SupportBundleManifest{
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: actorID,
+		Hostname:    hostname,
+		Version:     version.Version,
+		Files:       []string{"manifest.json"},
+		Errors:      map[string]string{},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	add := func(name string, collect func(ctx context.Context) ([]byte, error)) error {
+		ctx, cancel := context.WithTimeout(ctx, supportBundleQueryTimeout)
+		defer cancel()
+		data, err := collect(ctx)
+		if err != nil {
+			manifest.Errors[name] = err.Error()
+			return nil
+		}
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, name)
+		return nil
+	}
+	sections := []struct {
+		name    string
+		collect func(ctx context.Context) ([]byte, error)
+	}{
+		{"version.json", jsonSection(s.versionInfo)},
+		{"config.json", jsonSection(s.configSummary)},
+		{"health.json", jsonSection(s.healthChecks)},
+		{"database.json", jsonSection(s.databaseInfo)},
+		{"migrations.json", jsonSection(s.migrationStatus)},
+		{"logs/recent.jsonl", s.recentLogs},
+	}
+	for _, section := range sections {
+		if err := add(section.name, section.collect); err != nil {
+			return nil, "", fmt.Errorf("service.Build: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("service.Build: %w", err)
+	}
+	w, err := archive.Create("manifest.json")
+	if err != nil {
+		return nil, "", fmt.Errorf("service.Build: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, "", fmt.Errorf("service.Build: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, "", fmt.Errorf("service.Build: %w", err)
+	}
+	name := "support-bundle-" + manifest.GeneratedAt.Format("20060102-150405") + ".zip"
+	return buf.Bytes(), name, nil
+}
+
+func jsonSection[T any](collect func(ctx context.Context) (T, error)) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		value, err := collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(value, "", "  ")
+	}
+}
+
+func (s *SupportBundleService) versionInfo(_ context.Context) (supportVersionInfo, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	info := supportVersionInfo{
+		Version:        version.Version,
+		GoVersion:      runtime.Version(),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		StartedAt:      processStartedAt.UTC(),
+		Uptime:         time.Since(processStartedAt).Round(time.Second).String(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		NumGC:          mem.NumGC,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Module = build.Main.Path
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.VCSRevision = setting.Value
+			case "vcs.time":
+				info.VCSTime = setting.Value
+			case "vcs.modified":
+				info.VCSModified = setting.Value == "true"
+			}
+		}
+	}
+	return info, nil
+}
+
+// configSummary lists the environment of the process, the secrets redacted
+func (s *SupportBundleService) configSummary(_ context.Context) ([]supportConfigEntry, error) {
+	entries := []supportConfigEntry{}
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		entries = append(entries, supportConfigEntry{Name: name, Value: redactConfigValue(name, value)})
+	}
+	slices.SortFunc(entries, func(a, b supportConfigEntry) int { return strings.Compare(a.Name, b.Name) })
+	return entries, nil
+}
+
+func isSupportSensitiveEnv(name string) bool {
+	if isReplaySensitiveKey(name) {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, part := range supportSensitiveEnvParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConfigValue hides the value of a secret variable, and the passwords
+// and sensitive parameters of the URLs and connection strings
+func redactConfigValue(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	if isSupportSensitiveEnv(name) {
+		return replayRedacted
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		if query := u.Query(); len(query) > 0 {
+			u.RawQuery = url.Values(sanitizeReplayValues(query)).Encode()
+		}
+		return u.Redacted()
+	}
+	return connStringPasswordPattern.ReplaceAllString(value, "${1}"+replayRedacted)
+}
+
+func (s *SupportBundleService) healthChecks(_ context.Context) ([]supportHealthCheck, error) {
+	supportBundleChecksMu.RLock()
+	checks := slices.Clone(supportBundleChecks)
+	supportBundleChecksMu.RUnlock()
+	result := make([]supportHealthCheck, 0, len(checks))
+	for _, check := range checks {
+		status := "pass"
+		if !check.Pass() {
+			status = "fail"
+		}
+		result = append(result, supportHealthCheck{Name: check.Name(), Status: status})
+	}
+	return result, nil
+}
+
+func (s *SupportBundleService) databaseInfo(ctx context.Context) (supportDatabaseInfo, error) {
+	stat := s.store.ConnPool.Stat()
+	info := supportDatabaseInfo{
+		Pool: supportPoolStats{
+			MaxConns:             stat.MaxConns(),
+			TotalConns:           stat.TotalConns(),
+			IdleConns:            stat.IdleConns(),
+			AcquiredConns:        stat.AcquiredConns(),
+			AcquireCount:         stat.AcquireCount(),
+			EmptyAcquireCount:    stat.EmptyAcquireCount(),
+			CanceledAcquireCount: stat.CanceledAcquireCount(),
+			AcquireDurationMs:    durationMs(stat.AcquireDuration()),
+		},
+		Tables: []supportTableStats{},
+	}
+	server, err := s.store.GetDatabaseServerInfo(ctx)
+	if err != nil {
+		return info, err
+	}
+	info.ServerVersion = server.ServerVersion
+	info.SizeBytes = server.SizeBytes
+	tables, err := s.store.ListTableStats(ctx, supportBundleMaxTables)
+	if err != nil {
+		return info, err
+	}
+	for _, table := range tables {
+		info.Tables = append(info.Tables, supportTableStats{
+			Name:            table.Name,
+			LiveRows:        table.LiveRows,
+			DeadRows:        table.DeadRows,
+			SizeBytes:       table.SizeBytes,
+			LastAutovacuum:  util.FromNullableTimestamptz(table.LastAutovacuum),
+			LastAutoanalyze: util.FromNullableTimestamptz(table.LastAutoanalyze),
+		})
+	}
+	return info, nil
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// migrationStatus compares the migrations applied by goose with the core
+// migrations embedded in the binary
+func (s *SupportBundleService) migrationStatus(ctx context.Context) (supportMigrationStatus, error) {
+	status := supportMigrationStatus{Pending: []int64{}}
+	core, err := coreMigrationVersions()
+	if err != nil {
+		return status, err
+	}
+	migrations, err := s.store.ListGooseMigrations(ctx)
+	if err != nil {
+		return status, err
+	}
+	applied := map[int64]bool{}
+	for _, m := range migrations {
+		if !m.IsApplied {
+			continue
+		}
+		applied[m.VersionID] = true
+		status.Applied++
+		if m.VersionID > status.LatestApplied {
+			status.LatestApplied = m.VersionID
+			status.LatestAppliedAt = &m.AppliedAt
+		}
+		if !slices.Contains(core, m.VersionID) {
+			status.OtherApplied++
+		}
+	}
+	for _, v := range core {
+		if !applied[v] {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+	return status, nil
+}
+
+// coreMigrationVersions returns the versions of the embedded core migrations,
+// read from the timestamp their names start with
+func coreMigrationVersions() ([]int64, error) {
+	entries, err := fs.ReadDir(migration.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	versions := []int64{}
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		if v, err := strconv.ParseInt(prefix, 10, 64); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+// recentLogs returns the last warnings and errors as JSON lines, their
+// sensitive fields and the email addresses redacted
+func (s *SupportBundleService) recentLogs(_ context.Context) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, line := range s.logs.Lines() {
+		buf.Write(redactLogLine(bytes.TrimRight(line, "\n")))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func redactLogLine(line []byte) []byte {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		// A line of the console writer
+		return emailPattern.ReplaceAll(line, []byte(supportBundleRedactedMail))
+	}
+	data, err := json.Marshal(redactEmails(redactReplayJSON(fields)))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func redactEmails(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return emailPattern.ReplaceAllString(v, supportBundleRedactedMail)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = redactEmails(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactEmails(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactConfigValue(t *testing.T) {
+	require.Equal(t, replayRedacted, redactConfigValue("FILE_ENCRYPTION_MASTER_KEYS", "k1:abc"))
+	require.Equal(t, replayRedacted, redactConfigValue("DB_PASSWORD", "hunter2"))
+	require.Equal(t, replayRedacted, redactConfigValue("SMTP_PASS", "hunter2"))
+	require.Equal(t, "gcs", redactConfigValue("FILE_STORAGE_PROVIDER", "gcs"))
+	require.Equal(t, "", redactConfigValue("EMPTY_SECRET", ""))
+
+	redacted := redactConfigValue("DATABASE_URL", "postgres://app:hunter2@db:5432/core?sslmode=disable")
+	require.NotContains(t, redacted, "hunter2")
+	require.Contains(t, redacted, "app:")
+	require.Contains(t, redacted, "sslmode=disable")
+
+	redacted = redactConfigValue("WEBHOOK_URL", "https://hooks.example.com/x?token=abc&team=1")
+	require.NotContains(t, redacted, "abc")
+	require.Contains(t, redacted, "team=1")
+
+	require.Equal(t, "host=db user=app password="+replayRedacted+" dbname=core",
+		redactConfigValue("DB_CONN", "host=db user=app password=hunter2 dbname=core"))
+}
+
+func TestRedactLogLine(t *testing.T) {
+	line := redactLogLine([]byte(`{"level":"error","password":"hunter2","message":"Failed to invite jane@example.com","error":"duplicate"}`))
+	require.NotContains(t, string(line), "hunter2")
+	require.NotContains(t, string(line), "jane@example.com")
+	require.Contains(t, string(line), `"error":"duplicate"`)
+
+	line = redactLogLine([]byte(`ERR Failed to invite jane@example.com`))
+	require.Equal(t, "ERR Failed to invite "+supportBundleRedactedMail, string(line))
+}
+
+func TestSupportBundleRecentLogs(t *testing.T) {
+	logs := util.NewRecentLogBuffer(2)
+	logger := zerolog.New(logs)
+	logger.Info().Msg("ignored")
+	logger.Warn().Msg("first")
+	logger.Error().Str("token", "abc").Msg("second")
+	logger.Error().Msg("third")
+
+	s := &SupportBundleService{logs: logs}
+	data, err := s.recentLogs(context.Background())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "second")
+	require.NotContains(t, lines[0], "abc")
+	require.Contains(t, lines[1], "third")
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant configs overriding the platform assets
+const (
+	TenantAssetDefaultAvatarConfig = "asset_default_avatar_url"
+	TenantAssetEmailLogoConfig     = "asset_email_logo_url"
+	TenantAssetEmailFooterConfig   = "asset_email_footer"
+)
+
+const (
+	DefaultAvatarURL   = "/images/avatar-1.jpeg"
+	DefaultEmailFooter = "© CTO Up. All rights reserved."
+)
+
+// TenantAssets are the assets used for new users and outgoing emails.
+//
+// Environment (platform defaults, overridden per tenant by the asset_* tenant configs):
+//   - DEFAULT_AVATAR_URL: photo of new users (default /images/avatar-1.jpeg)
+//   - EMAIL_LOGO_URL: logo at the top of emails (default none)
+//   - EMAIL_FOOTER: footer line of emails (default "© CTO Up. All rights reserved.")
+type TenantAssets struct {
+	DefaultAvatarURL string
+	EmailLogoURL     string
+	EmailFooter      string
+}
+
+func PlatformAssetsFromEnv() TenantAssets {
+	assets := TenantAssets{
+		DefaultAvatarURL: DefaultAvatarURL,
+		EmailLogoURL:     os.Getenv("EMAIL_LOGO_URL"),
+		EmailFooter:      DefaultEmailFooter,
+	}
+	if v := os.Getenv("DEFAULT_AVATAR_URL"); v != "" {
+		assets.DefaultAvatarURL = v
+	}
+	if v := os.Getenv("EMAIL_FOOTER"); v != "" {
+		assets.EmailFooter = v
+	}
+	return assets
+}
+
+// GetTenantAssets returns the platform assets overridden by the tenant
+// configs, the email footer by the branding.email_footer setting first. A
+// failed lookup is logged and falls back to the platform assets, so
+// that branding never blocks a user creation or an email.
+func GetTenantAssets(ctx context.Context, q *repository.Queries, tenantID string) TenantAssets {
+	assets := PlatformAssetsFromEnv()
+	if tenantID == "" {
+		return assets
+	}
+	configs, err := q.ListAllTenantConfigs(ctx, tenantID)
+	if err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to load tenant assets, using platform defaults")
+		return assets
+	}
+	for _, config := range configs {
+		if !config.Value.Valid || config.Value.String == "" {
+			continue
+		}
+		switch config.Name {
+		case TenantAssetDefaultAvatarConfig:
+			assets.DefaultAvatarURL = config.Value.String
+		case TenantAssetEmailLogoConfig:
+			assets.EmailLogoURL = config.Value.String
+		case TenantAssetEmailFooterConfig:
+			assets.EmailFooter = config.Value.String
+		}
+	}
+	settings, err := q.ListTenantSettingValues(ctx, tenantID)
+	if err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to load tenant branding, using the configured email footer")
+		return assets
+	}
+	for _, setting := range settings {
+		var footer string
+		if setting.Key == TenantSettingBrandingEmailFooter && json.Unmarshal(setting.Value, &footer) == nil && footer != "" {
+			assets.EmailFooter = footer
+		}
+	}
+	return assets
+}
+
+// Email returns the assets exposed to the email templates
+func (a TenantAssets) Email() emailservice.EmailAssets {
+	return emailservice.EmailAssets{
+		LogoURL: a.EmailLogoURL,
+		Footer:  a.EmailFooter,
+	}
+}
+
+// TenantEmailAssetResolver resolves the email assets of the request tenant,
+// to register with emailservice.SetAssetResolver
+func TenantEmailAssetResolver(store *db.Store) emailservice.AssetResolver {
+	return func(ctx *gin.Context) emailservice.EmailAssets {
+		return GetTenantAssets(ctx, store.Queries, ctx.GetString(auth.AUTH_TENANT_ID_KEY)).Email()
+	}
+}
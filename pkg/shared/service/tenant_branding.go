@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Branding settings of the white-label frontends, public like the branding
+// flags. Unset, the frontends use their own defaults.
+const (
+	TenantSettingBrandingPrimaryColor   = "branding.primary_color"
+	TenantSettingBrandingSecondaryColor = "branding.secondary_color"
+	TenantSettingBrandingFontFamily     = "branding.font_family"
+	TenantSettingBrandingProductName    = "branding.product_name"
+	TenantSettingBrandingEmailFooter    = "branding.email_footer"
+)
+
+const tenantBrandingColorSchema = `{"type": "string", "pattern": "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"}`
+
+func init() {
+	for _, def := range []TenantSettingDefinition{
+		{Key: TenantSettingBrandingPrimaryColor, Description: "Main brand color, as #rgb or #rrggbb",
+			Schema: tenantBrandingColorSchema, Public: true},
+		{Key: TenantSettingBrandingSecondaryColor, Description: "Second brand color, as #rgb or #rrggbb",
+			Schema: tenantBrandingColorSchema, Public: true},
+		{Key: TenantSettingBrandingFontFamily, Description: "CSS font family of the frontends, such as Inter, sans-serif",
+			Schema: `{"type": "string", "pattern": "^[A-Za-z0-9 ,'-]{1,100}$"}`, Public: true},
+		{Key: TenantSettingBrandingProductName, Description: "Name of the product shown in place of the platform name",
+			Schema: `{"type": "string", "minLength": 1, "maxLength": 100}`, Public: true},
+		{Key: TenantSettingBrandingEmailFooter, Description: "Footer line of the emails of the tenant",
+			Schema: `{"type": "string", "minLength": 1, "maxLength": 500}`, Public: true},
+	} {
+		if err := RegisterTenantSetting(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// TenantBranding gathers the branding settings of a tenant. A nil field is
+// unset: read, the tenant did not set it; written, it is reset to its default.
+type TenantBranding struct {
+	PrimaryColor    *string
+	SecondaryColor  *string
+	FontFamily      *string
+	ProductName     *string
+	EmailFooter     *string
+	DarkModeDefault *bool
+	ShowPoweredBy   *bool
+}
+
+// TenantBrandingFromSettings reads the branding from the setting values of a
+// tenant, as returned by GetSettingValues
+func TenantBrandingFromSettings(values map[string]interface{}) TenantBranding {
+	text := func(key string) *string {
+		if v, ok := values[key].(string); ok {
+			return &v
+		}
+		return nil
+	}
+	flag := func(key string) *bool {
+		if v, ok := values[key].(bool); ok {
+			return &v
+		}
+		return nil
+	}
+	return TenantBranding{
+		PrimaryColor:    text(TenantSettingBrandingPrimaryColor),
+		SecondaryColor:  text(TenantSettingBrandingSecondaryColor),
+		FontFamily:      text(TenantSettingBrandingFontFamily),
+		ProductName:     text(TenantSettingBrandingProductName),
+		EmailFooter:     text(TenantSettingBrandingEmailFooter),
+		DarkModeDefault: flag(TenantSettingBrandingDarkMode),
+		ShowPoweredBy:   flag(TenantSettingBrandingPoweredBy),
+	}
+}
+
+// settingChanges returns every branding setting, the nil fields as null
+func (b TenantBranding) settingChanges() (map[string]json.RawMessage, error) {
+	fields := map[string]interface{}{
+		TenantSettingBrandingPrimaryColor:   b.PrimaryColor,
+		TenantSettingBrandingSecondaryColor: b.SecondaryColor,
+		TenantSettingBrandingFontFamily:     b.FontFamily,
+		TenantSettingBrandingProductName:    b.ProductName,
+		TenantSettingBrandingEmailFooter:    b.EmailFooter,
+		TenantSettingBrandingDarkMode:       b.DarkModeDefault,
+		TenantSettingBrandingPoweredBy:      b.ShowPoweredBy,
+	}
+	changes := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		changes[key] = raw
+	}
+	return changes, nil
+}
+
+// UpdateBranding replaces the whole branding of the tenant, the nil fields
+// going back to their defaults. Rejected values are returned as a
+// *TenantSettingValuesError, keyed by setting.
+func (s *TenantSettingsService) UpdateBranding(ctx context.Context, tenantID string, branding TenantBranding, updatedBy string) (TenantBranding, error) {
+	changes, err := branding.settingChanges()
+	if err != nil {
+		return TenantBranding{}, fmt.Errorf("service.UpdateBranding: %w", err)
+	}
+	values, err := s.UpdateSettingValues(ctx, tenantID, changes, updatedBy)
+	if err != nil {
+		return TenantBranding{}, err
+	}
+	return TenantBrandingFromSettings(values), nil
+}
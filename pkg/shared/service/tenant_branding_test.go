@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantBrandingSchemas(t *testing.T) {
+	for key, cases := range map[string]map[interface{}]bool{
+		TenantSettingBrandingPrimaryColor: {"#1a2B3c": true, "#fff": true, "red": false, "#12345": false, 3.0: false},
+		TenantSettingBrandingFontFamily:   {"Inter, sans-serif": true, "'Open Sans', Arial": true, "Inter; color: red": false, "": false},
+		TenantSettingBrandingProductName:  {"Acme Portal": true, "": false},
+	} {
+		def, ok := tenantSettingDefinition(key)
+		require.True(t, ok, key)
+		require.True(t, def.Public, key)
+		for value, valid := range cases {
+			require.Equal(t, valid, len(def.schema.validate(key, value)) == 0, "%s: %v", key, value)
+		}
+	}
+}
+
+func TestTenantBrandingFromSettings(t *testing.T) {
+	branding := TenantBrandingFromSettings(map[string]interface{}{
+		TenantSettingBrandingPrimaryColor: "#112233",
+		TenantSettingBrandingProductName:  nil,
+		TenantSettingBrandingDarkMode:     true,
+	})
+	require.Equal(t, "#112233", *branding.PrimaryColor)
+	require.Nil(t, branding.ProductName)
+	require.Nil(t, branding.SecondaryColor)
+	require.True(t, *branding.DarkModeDefault)
+	require.Nil(t, branding.ShowPoweredBy)
+}
+
+func TestTenantBrandingSettingChanges(t *testing.T) {
+	color, poweredBy := "#abc", false
+	changes, err := TenantBranding{PrimaryColor: &color, ShowPoweredBy: &poweredBy}.settingChanges()
+	require.NoError(t, err)
+	require.Len(t, changes, 7)
+	require.JSONEq(t, `"#abc"`, string(changes[TenantSettingBrandingPrimaryColor]))
+	require.JSONEq(t, `false`, string(changes[TenantSettingBrandingPoweredBy]))
+	// Omitted fields are reset to their defaults
+	require.JSONEq(t, `null`, string(changes[TenantSettingBrandingEmailFooter]))
+	require.JSONEq(t, `null`, string(changes[TenantSettingBrandingDarkMode]))
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/event"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"gocloud.dev/gcerrors"
+)
+
+const (
+	tenantDataExportPageSize = 500
+	tenantDataExportTimeout  = time.Hour
+	// TenantDataExportContentType is the media type of the archives
+	TenantDataExportContentType = "application/zip"
+)
+
+// ErrTenantDataExportNotReady is returned for the archive of an export that
+// did not complete
+var ErrTenantDataExportNotReady = errors.New("tenant data export is not completed")
+
+// TenantDataExportFunc writes the rows a module keeps for a tenant, calling
+// emit with each row, encoded as JSON
+type TenantDataExportFunc func(ctx context.Context, tenantID string, emit func(row interface{}) error) error
+
+type tenantDataExportTable struct {
+	name   string
+	export TenantDataExportFunc
+}
+
+var (
+	tenantDataExportTablesMu sync.RWMutex
+	tenantDataExportTables   []tenantDataExportTable
+)
+
+// RegisterTenantDataExportTable lets a module, such as prompts, add its data
+// to the full tenant exports, as <name>.ndjson. Tables run in registration
+// order, after the core tables.
+func RegisterTenantDataExportTable(name string, export TenantDataExportFunc) {
+	tenantDataExportTablesMu.Lock()
+	defer tenantDataExportTablesMu.Unlock()
+	tenantDataExportTables = append(tenantDataExportTables, tenantDataExportTable{name: name, export: export})
+}
+
+func getTenantDataExportTables() []tenantDataExportTable {
+	tenantDataExportTablesMu.RLock()
+	defer tenantDataExportTablesMu.RUnlock()
+	return append([]tenantDataExportTable{}, tenantDataExportTables...)
+}
+
+// TenantDataExportPrefix is where the archives of the exports of the tenant
+// are stored
+func TenantDataExportPrefix(tenantID string) string {
+	return "/tenant-data-exports/" + tenantID + "/"
+}
+
+// TenantDataExportPath is where the archive of an export is stored. It is
+// outside of the files of the tenant, so it is neither counted in its storage
+// quota nor listed in the next exports.
+func TenantDataExportPath(tenantID string, jobID uuid.UUID) string {
+	return TenantDataExportPrefix(tenantID) + jobID.String() + ".zip"
+}
+
+// TenantDataExportFileName is the name the archive is downloaded as
+func TenantDataExportFileName(tenantID string, jobID uuid.UUID) string {
+	return "tenant-" + tenantID + "-" + jobID.String() + ".zip"
+}
+
+// TenantDataExportStatus is the progress of a full tenant export, with its
+// result once completed
+type TenantDataExportStatus struct {
+	Job      repository.CoreJob
+	Progress int
+	Message  string
+	Result   *event.TenantDataExport
+}
+
+// TenantDataExportService exports every row a tenant owns, for offboarding
+// and portability: a ZIP archive with one NDJSON file per table, named after
+// its columns, built as a job and downloaded through a signed URL.
+type TenantDataExportService struct {
+	store     *db.Store
+	files     *fileservice.FileService
+	jobs      *JobService
+	urlExpiry time.Duration
+}
+
+func NewTenantDataExportService(store *db.Store, files *fileservice.FileService, urlExpiry time.Duration) *TenantDataExportService {
+	return &TenantDataExportService{store: store, files: files, jobs: NewJobService(store), urlExpiry: urlExpiry}
+}
+
+// StartExport starts the export of the tenant as a job of kind
+// tenant_data_export, followed with the events of the job
+func (s *TenantDataExportService) StartExport(ctx context.Context, tenant repository.CoreTenant, userID string) (repository.CoreJob, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	job, err := s.jobs.StartJob(ctx, tenant.TenantID, JobKindTenantDataExport, userID)
+	if err != nil {
+		return job, fmt.Errorf("service.StartExport: %w", err)
+	}
+	logger.Info().
+		Str("tenant_id", tenant.TenantID).
+		Str("job_id", job.ID.String()).
+		Str("user_id", userID).
+		Msg("Tenant data export started")
+
+	// The export goes on when the client goes away
+	go s.runExport(context.WithoutCancel(ctx), tenant, job)
+	return job, nil
+}
+
+// GetExport returns the progress of an export of the tenant, pgx.ErrNoRows
+// when the job is not one
+func (s *TenantDataExportService) GetExport(ctx context.Context, tenantID string, jobID uuid.UUID) (TenantDataExportStatus, error) {
+	job, err := s.jobs.GetJob(ctx, tenantID, jobID)
+	if err != nil {
+		return TenantDataExportStatus{}, err
+	}
+	if job.Kind != JobKindTenantDataExport {
+		return TenantDataExportStatus{}, pgx.ErrNoRows
+	}
+	status := TenantDataExportStatus{Job: job}
+	latest, err := s.jobs.GetLatestEvent(ctx, job.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status, nil
+	}
+	if err != nil {
+		return status, fmt.Errorf("service.GetExport: %w", err)
+	}
+	status.Progress = int(latest.Progress)
+	status.Message = latest.Message
+	if latest.DataType == event.DataTypeTenantDataExport {
+		var result event.TenantDataExport
+		if err := json.Unmarshal(latest.Data, &result); err == nil {
+			status.Result = &result
+		}
+	}
+	return status, nil
+}
+
+// SignedDownloadURL signs a short-lived URL of the archive of a completed
+// export, ErrSignedURLUnsupported when the storage cannot
+func (s *TenantDataExportService) SignedDownloadURL(ctx context.Context, job repository.CoreJob) (string, error) {
+	if job.Status != JobStatusCompleted {
+		return "", ErrTenantDataExportNotReady
+	}
+	signed, err := s.files.SignedURL(ctx, TenantDataExportPath(job.TenantID, job.ID), s.urlExpiry)
+	if gcerrors.Code(err) == gcerrors.Unimplemented || errors.Is(err, fileservice.ErrEncryptedFile) {
+		return "", ErrSignedURLUnsupported
+	}
+	return signed, err
+}
+
+// ReadArchive returns the archive of a completed export, for storage that
+// cannot sign URLs
+func (s *TenantDataExportService) ReadArchive(ctx context.Context, job repository.CoreJob) ([]byte, error) {
+	if job.Status != JobStatusCompleted {
+		return nil, ErrTenantDataExportNotReady
+	}
+	return s.files.ReadFileBytes(ctx, TenantDataExportPath(job.TenantID, job.ID))
+}
+
+// runExport writes the tables to the archive and stores it. Any failing
+// table fails the export, as a partial archive would be taken for the whole
+// data of the tenant.
+func (s *TenantDataExportService) runExport(ctx context.Context, tenant repository.CoreTenant, job repository.CoreJob) {
+	logger := util.GetLoggerFromCtx(ctx).With().Str("tenant_id", tenant.TenantID).Str("job_id", job.ID.String()).Logger()
+	ctx, cancel := context.WithTimeout(ctx, tenantDataExportTimeout)
+	defer cancel()
+
+	result, err := s.writeArchive(ctx, tenant, job)
+	if err != nil {
+		logger.Err(err).Msg("Tenant data export failed")
+		s.recordEvent(ctx, job, event.NewProgressEvent("ERROR", "Export failed: "+err.Error(), 100))
+		if err := s.jobs.FinishJob(ctx, job.ID, JobStatusFailed); err != nil {
+			logger.Err(err).Msg("Failed to finish tenant data export job")
+		}
+		return
+	}
+	s.recordEvent(ctx, job, event.NewProgressEventWithData("INFO", "Export completed", 100, result))
+	if err := s.jobs.FinishJob(ctx, job.ID, JobStatusCompleted); err != nil {
+		logger.Err(err).Msg("Failed to finish tenant data export job")
+	}
+	logger.Info().Int64("bytes", result.Bytes).Int("tables", len(result.Tables)).Msg("Tenant data export completed")
+}
+
+func (s *TenantDataExportService) writeArchive(ctx context.Context, tenant repository.CoreTenant, job repository.CoreJob) (event.TenantDataExport, error) {
+	result := event.TenantDataExport{TenantID: tenant.TenantID, Tables: []event.TenantDataExportTable{}}
+	tables := append(s.coreTables(tenant), getTenantDataExportTables()...)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for i, table := range tables {
+		s.recordEvent(ctx, job, event.NewProgressEvent("INFO", "Exporting "+table.name, 5+85*i/len(tables)))
+		rows, err := writeTenantDataExportTable(ctx, archive, tenant.TenantID, table)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", table.name, err)
+		}
+		result.Tables = append(result.Tables, event.TenantDataExportTable{Name: table.name, Rows: rows})
+	}
+	manifest, err := archive.Create("manifest.json")
+	if err != nil {
+		return result, err
+	}
+	if err := json.NewEncoder(manifest).Encode(tenantDataExportManifest{
+		TenantID:   tenant.TenantID,
+		JobID:      job.ID.String(),
+		ExportedAt: time.Now().UTC(),
+		Tables:     result.Tables,
+	}); err != nil {
+		return result, err
+	}
+	if err := archive.Close(); err != nil {
+		return result, err
+	}
+
+	s.recordEvent(ctx, job, event.NewProgressEvent("INFO", "Storing the archive", 90))
+	if err := s.files.SaveFile(ctx, buf.Bytes(), TenantDataExportPath(tenant.TenantID, job.ID)); err != nil {
+		return result, err
+	}
+	result.Bytes = int64(buf.Len())
+	return result, nil
+}
+
+type tenantDataExportManifest struct {
+	TenantID   string                        `json:"tenantId"`
+	JobID      string                        `json:"jobId"`
+	ExportedAt time.Time                     `json:"exportedAt"`
+	Tables     []event.TenantDataExportTable `json:"tables"`
+}
+
+func writeTenantDataExportTable(ctx context.Context, archive *zip.Writer, tenantID string, table tenantDataExportTable) (int64, error) {
+	w, err := archive.Create(table.name + ".ndjson")
+	if err != nil {
+		return 0, err
+	}
+	return exportNDJSON(ctx, w, tenantID, table.export)
+}
+
+// exportNDJSON writes the rows of export to w, one JSON object per line, and
+// returns their number
+func exportNDJSON(ctx context.Context, w io.Writer, tenantID string, export TenantDataExportFunc) (int64, error) {
+	encoder := json.NewEncoder(w)
+	var rows int64
+	err := export(ctx, tenantID, func(row interface{}) error {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+		rows++
+		return nil
+	})
+	return rows, err
+}
+
+// coreTables are the tables of the core, the tenant itself first
+func (s *TenantDataExportService) coreTables(tenant repository.CoreTenant) []tenantDataExportTable {
+	return []tenantDataExportTable{
+		{name: "tenant", export: func(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+			return emit(tenant)
+		}},
+		{name: "users", export: s.exportUsers},
+		{name: "memberships", export: s.exportMemberships},
+		{name: "configs", export: s.exportConfigs},
+		{name: "settings", export: s.exportSettings},
+		{name: "files", export: s.exportFiles},
+	}
+}
+
+type tenantDataExportUser struct {
+	ID        string                `json:"id"`
+	Email     string                `json:"email,omitempty"`
+	Profile   subentity.UserProfile `json:"profile"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+func (s *TenantDataExportService) exportUsers(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+	after := ""
+	for {
+		users, err := s.store.ListTenantDataExportUsers(ctx, repository.ListTenantDataExportUsersParams{
+			TenantID:    tenantID,
+			AfterUserID: after,
+			MaxRows:     tenantDataExportPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := emit(tenantDataExportUser{
+				ID:        user.ID,
+				Email:     DecryptEmail(user.Email).String,
+				Profile:   user.Profile,
+				CreatedAt: user.CreatedAt,
+			}); err != nil {
+				return err
+			}
+			after = user.ID
+		}
+		if len(users) < tenantDataExportPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *TenantDataExportService) exportMemberships(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+	after := ""
+	for {
+		memberships, err := s.store.ListTenantDataExportMemberships(ctx, repository.ListTenantDataExportMembershipsParams{
+			TenantID:    tenantID,
+			AfterUserID: after,
+			MaxRows:     tenantDataExportPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, membership := range memberships {
+			if err := emit(membership); err != nil {
+				return err
+			}
+			after = membership.UserID
+		}
+		if len(memberships) < tenantDataExportPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *TenantDataExportService) exportConfigs(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+	configs, err := s.store.ListTenantDataExportConfigs(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		if err := emit(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tenantDataExportSetting struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	UpdatedBy string          `json:"updated_by"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (s *TenantDataExportService) exportSettings(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+	values, err := s.store.ListTenantSettingValues(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := emit(tenantDataExportSetting{
+			Key:       value.Key,
+			Value:     value.Value,
+			UpdatedBy: value.UpdatedBy,
+			UpdatedAt: value.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tenantDataExportFile struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// exportFiles lists the files of the tenant, as stored in the bucket; their
+// content is not part of the archive
+func (s *TenantDataExportService) exportFiles(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+	sizes, err := s.files.TenantFileSizes(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(sizes))
+	for path := range sizes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := emit(tenantDataExportFile{Path: path, SizeBytes: sizes[path]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TenantDataExportService) recordEvent(ctx context.Context, job repository.CoreJob, progressEvent event.ProgressEvent) {
+	if _, err := s.jobs.RecordEvent(ctx, job.ID, progressEvent); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("jobID", job.ID.String()).Msg("Failed to record tenant data export event")
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTenantDataExportTable(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	prompts := tenantDataExportTable{name: "prompts", export: func(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+		for _, name := range []string{"welcome", "summary"} {
+			if err := emit(map[string]string{"tenant_id": tenantID, "name": name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	rows, err := writeTenantDataExportTable(ctx, archive, "tenant-a", prompts)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rows)
+
+	failing := tenantDataExportTable{name: "broken", export: func(ctx context.Context, tenantID string, emit func(interface{}) error) error {
+		return errors.New("unavailable")
+	}}
+	_, err = writeTenantDataExportTable(ctx, archive, "tenant-a", failing)
+	require.Error(t, err)
+	require.NoError(t, archive.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, "prompts.ndjson", reader.File[0].Name)
+	file, err := reader.File[0].Open()
+	require.NoError(t, err)
+	defer file.Close()
+
+	lines := []map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]string
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Equal(t, []map[string]string{
+		{"tenant_id": "tenant-a", "name": "welcome"},
+		{"tenant_id": "tenant-a", "name": "summary"},
+	}, lines)
+}
+
+func TestTenantDataExportPath(t *testing.T) {
+	jobID := uuid.MustParse("0b1e7c52-5a44-4c5e-9d5b-3f3a2b1c0d9e")
+	path := TenantDataExportPath("tenant-a", jobID)
+	require.Equal(t, "/tenant-data-exports/tenant-a/0b1e7c52-5a44-4c5e-9d5b-3f3a2b1c0d9e.zip", path)
+	// Outside of the files of the tenant, so neither quota nor manifest see it
+	require.NotContains(t, path, fileservice.TenantFilePrefix("tenant-a"))
+}
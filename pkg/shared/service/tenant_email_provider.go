@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// SecretTenantEmailProviderEncryptionKeys names the keys encrypting the SMTP
+// passwords and API keys of the tenants, in the id:base64 format of
+// MFA_ENCRYPTION_KEYS
+const SecretTenantEmailProviderEncryptionKeys = "TENANT_EMAIL_PROVIDER_ENCRYPTION_KEYS"
+
+const tenantEmailProviderTestSubject = "Email provider test"
+
+var (
+	// ErrTenantEmailProviderNotFound is returned when the tenant uses the
+	// platform provider
+	ErrTenantEmailProviderNotFound = errors.New("the tenant has no email provider")
+	// ErrTenantEmailProviderNotConfigured is returned when the encryption keys
+	// are not set
+	ErrTenantEmailProviderNotConfigured = errors.New("tenant email providers are disabled, " + SecretTenantEmailProviderEncryptionKeys + " is not set")
+	// ErrInvalidTenantEmailProvider is returned for an incomplete provider
+	ErrInvalidTenantEmailProvider = errors.New("invalid email provider")
+)
+
+// TenantEmailProviderInput is the provider a tenant sends its emails with
+type TenantEmailProviderInput struct {
+	// Provider is emailservice.ProviderSMTP or emailservice.ProviderSendGrid
+	Provider string
+	Host     *string
+	Port     *int32
+	Username *string
+	// Secret is the SMTP password or the API key; nil keeps the stored one
+	Secret      *string
+	FromAddress string
+	ReplyTo     *string
+}
+
+// tenantEmailTransportsCache keeps the transport of each tenant by tenant id,
+// configured false for the tenants using the platform provider
+type cachedTenantEmailTransport struct {
+	transport  emailservice.TenantTransport
+	configured bool
+}
+
+var tenantEmailTransportsCache = NewPublicCache[cachedTenantEmailTransport](DefaultPublicCacheTTL)
+
+// TenantEmailProviderService manages the email providers the tenants replace
+// the platform SMTP server with. The SMTP password or API key is stored
+// encrypted and never returned.
+type TenantEmailProviderService struct {
+	store        *db.Store
+	keys         map[string]cipher.AEAD
+	currentKeyID string
+}
+
+func NewTenantEmailProviderService(store *db.Store) *TenantEmailProviderService {
+	s := &TenantEmailProviderService{store: store, keys: map[string]cipher.AEAD{}}
+	keys, currentKeyID, err := parseAEADKeys(readSecret(context.Background(), currentSecretProvider(), SecretTenantEmailProviderEncryptionKeys))
+	if err != nil {
+		log.Err(err).Msgf("Invalid %s, tenant email providers are disabled", SecretTenantEmailProviderEncryptionKeys)
+		return s
+	}
+	s.keys, s.currentKeyID = keys, currentKeyID
+	return s
+}
+
+func (s *TenantEmailProviderService) encryptSecret(secret string) (string, error) {
+	if s.currentKeyID == "" {
+		return "", ErrTenantEmailProviderNotConfigured
+	}
+	aead := s.keys[s.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(secret), nil)
+	return s.currentKeyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *TenantEmailProviderService) decryptSecret(stored string) (string, error) {
+	keyID, encoded, ok := strings.Cut(stored, ":")
+	if !ok {
+		return "", errors.New("malformed email provider secret")
+	}
+	aead, found := s.keys[keyID]
+	if !found {
+		return "", fmt.Errorf("email provider secret encrypted with the unknown key %q", keyID)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed email provider secret")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt email provider secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// validateTenantEmailProvider checks the input, secret apart
+func validateTenantEmailProvider(input TenantEmailProviderInput) error {
+	switch input.Provider {
+	case emailservice.ProviderSMTP:
+		if input.Host == nil || *input.Host == "" {
+			return fmt.Errorf("%w: the SMTP host is required", ErrInvalidTenantEmailProvider)
+		}
+		if input.Port == nil || *input.Port < 1 || *input.Port > 65535 {
+			return fmt.Errorf("%w: the SMTP port must be from 1 to 65535", ErrInvalidTenantEmailProvider)
+		}
+	case emailservice.ProviderSendGrid:
+	default:
+		return fmt.Errorf("%w: the provider must be %s or %s", ErrInvalidTenantEmailProvider, emailservice.ProviderSMTP, emailservice.ProviderSendGrid)
+	}
+	if address, err := mail.ParseAddress(input.FromAddress); err != nil || address.Address != input.FromAddress {
+		return fmt.Errorf("%w: the from address is not an email address", ErrInvalidTenantEmailProvider)
+	}
+	if input.ReplyTo != nil {
+		if address, err := mail.ParseAddress(*input.ReplyTo); err != nil || address.Address != *input.ReplyTo {
+			return fmt.Errorf("%w: the reply-to address is not an email address", ErrInvalidTenantEmailProvider)
+		}
+	}
+	return nil
+}
+
+// Get returns the provider of the tenant
+func (s *TenantEmailProviderService) Get(ctx context.Context, tenantID string) (repository.CoreTenantEmailProvider, error) {
+	provider, err := s.store.GetTenantEmailProvider(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.CoreTenantEmailProvider{}, ErrTenantEmailProviderNotFound
+		}
+		return repository.CoreTenantEmailProvider{}, fmt.Errorf("service.Get: %w", err)
+	}
+	return provider, nil
+}
+
+// Save sets the provider of the tenant. Without a secret the stored one is
+// kept, which an API key provider requires.
+func (s *TenantEmailProviderService) Save(ctx context.Context, tenantID string, input TenantEmailProviderInput, updatedBy string) (repository.CoreTenantEmailProvider, error) {
+	if err := validateTenantEmailProvider(input); err != nil {
+		return repository.CoreTenantEmailProvider{}, err
+	}
+	if s.currentKeyID == "" {
+		return repository.CoreTenantEmailProvider{}, ErrTenantEmailProviderNotConfigured
+	}
+
+	var encryptedSecret string
+	if input.Secret != nil {
+		encrypted, err := s.encryptSecret(*input.Secret)
+		if err != nil {
+			return repository.CoreTenantEmailProvider{}, fmt.Errorf("service.Save: %w", err)
+		}
+		encryptedSecret = encrypted
+	} else {
+		current, err := s.store.GetTenantEmailProvider(ctx, tenantID)
+		switch {
+		case err == nil:
+			encryptedSecret = current.EncryptedSecret
+		case !errors.Is(err, pgx.ErrNoRows):
+			return repository.CoreTenantEmailProvider{}, fmt.Errorf("service.Save: %w", err)
+		case input.Provider == emailservice.ProviderSendGrid:
+			return repository.CoreTenantEmailProvider{}, fmt.Errorf("%w: the API key is required", ErrInvalidTenantEmailProvider)
+		default:
+			// An SMTP server without authentication
+			if encryptedSecret, err = s.encryptSecret(""); err != nil {
+				return repository.CoreTenantEmailProvider{}, fmt.Errorf("service.Save: %w", err)
+			}
+		}
+	}
+
+	provider, err := s.store.UpsertTenantEmailProvider(ctx, repository.UpsertTenantEmailProviderParams{
+		TenantID:        tenantID,
+		Provider:        input.Provider,
+		Host:            util.ToNullableText(input.Host),
+		Port:            util.ToNullableInt4(input.Port),
+		Username:        util.ToNullableText(input.Username),
+		EncryptedSecret: encryptedSecret,
+		FromAddress:     input.FromAddress,
+		ReplyTo:         util.ToNullableText(input.ReplyTo),
+		UpdatedBy:       updatedBy,
+	})
+	if err != nil {
+		return repository.CoreTenantEmailProvider{}, fmt.Errorf("service.Save: %w", err)
+	}
+	tenantEmailTransportsCache.Invalidate(tenantID)
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("tenant_id", tenantID).Str("provider", input.Provider).Str("updated_by", updatedBy).Msg("Tenant email provider saved")
+	return provider, nil
+}
+
+// Delete sends the emails of the tenant with the platform provider again
+func (s *TenantEmailProviderService) Delete(ctx context.Context, tenantID string) error {
+	deleted, err := s.store.DeleteTenantEmailProvider(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.Delete: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTenantEmailProviderNotFound
+	}
+	tenantEmailTransportsCache.Invalidate(tenantID)
+	return nil
+}
+
+// SendTest sends an email with the provider of the tenant, so an admin checks
+// the credentials before the tenant emails depend on them
+func (s *TenantEmailProviderService) SendTest(ctx context.Context, tenantID, to string) error {
+	provider, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	transport, err := s.transport(provider)
+	if err != nil {
+		return fmt.Errorf("service.SendTest: %w", err)
+	}
+	r := emailservice.NewEmailRequest(transport.From, []string{to}, tenantEmailProviderTestSubject,
+		"<p>The email provider of your organization works.</p>")
+	r.ReplyTo = transport.ReplyTo
+	return transport.Sender.Send(r)
+}
+
+// transport decrypts the secret of the provider into its sender
+func (s *TenantEmailProviderService) transport(provider repository.CoreTenantEmailProvider) (emailservice.TenantTransport, error) {
+	secret, err := s.decryptSecret(provider.EncryptedSecret)
+	if err != nil {
+		return emailservice.TenantTransport{}, err
+	}
+	transport := emailservice.TenantTransport{From: provider.FromAddress, ReplyTo: provider.ReplyTo.String}
+	switch provider.Provider {
+	case emailservice.ProviderSMTP:
+		transport.Sender = emailservice.SMTPSender{
+			Host:     provider.Host.String,
+			Port:     strconv.Itoa(int(provider.Port.Int32)),
+			Username: provider.Username.String,
+			Password: secret,
+		}
+	case emailservice.ProviderSendGrid:
+		transport.Sender = emailservice.SendGridSender{APIKey: secret}
+	default:
+		return emailservice.TenantTransport{}, fmt.Errorf("unknown email provider %q", provider.Provider)
+	}
+	return transport, nil
+}
+
+// TenantEmailTransportResolver selects the provider of the tenant of an
+// email, to register with emailservice.SetTransportResolver. The platform
+// SMTP server is used when the tenant has none or it cannot be read.
+func TenantEmailTransportResolver(store *db.Store) emailservice.TransportResolver {
+	providers := NewTenantEmailProviderService(store)
+	return func(ctx context.Context, tenantID string) (emailservice.TenantTransport, bool) {
+		cached, err := tenantEmailTransportsCache.Get(ctx, tenantID, func(ctx context.Context) (cachedTenantEmailTransport, error) {
+			provider, err := store.GetTenantEmailProvider(ctx, tenantID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return cachedTenantEmailTransport{}, nil
+			}
+			if err != nil {
+				return cachedTenantEmailTransport{}, err
+			}
+			transport, err := providers.transport(provider)
+			if err != nil {
+				return cachedTenantEmailTransport{}, err
+			}
+			return cachedTenantEmailTransport{transport: transport, configured: true}, nil
+		})
+		if err != nil {
+			log.Err(err).Str("tenant_id", tenantID).Msg("Failed to load the email provider of the tenant, using the platform one")
+			return emailservice.TenantTransport{}, false
+		}
+		return cached.transport, cached.configured
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTenantEmailProvider(t *testing.T) {
+	host, port, badPort := "smtp.example.com", int32(587), int32(0)
+	replyTo, badAddress := "support@example.com", "Support <support@example.com>"
+
+	valid := []TenantEmailProviderInput{
+		{Provider: emailservice.ProviderSMTP, Host: &host, Port: &port, FromAddress: "noreply@example.com"},
+		{Provider: emailservice.ProviderSendGrid, FromAddress: "noreply@example.com", ReplyTo: &replyTo},
+	}
+	for _, input := range valid {
+		require.NoError(t, validateTenantEmailProvider(input), input.Provider)
+	}
+
+	invalid := []TenantEmailProviderInput{
+		{Provider: "mailgun", FromAddress: "noreply@example.com"},
+		{Provider: emailservice.ProviderSMTP, Port: &port, FromAddress: "noreply@example.com"},
+		{Provider: emailservice.ProviderSMTP, Host: &host, Port: &badPort, FromAddress: "noreply@example.com"},
+		{Provider: emailservice.ProviderSendGrid, FromAddress: "noreply"},
+		{Provider: emailservice.ProviderSendGrid, FromAddress: badAddress},
+		{Provider: emailservice.ProviderSendGrid, FromAddress: "noreply@example.com", ReplyTo: &badAddress},
+	}
+	for _, input := range invalid {
+		require.ErrorIs(t, validateTenantEmailProvider(input), ErrInvalidTenantEmailProvider, input)
+	}
+}
+
+func TestTenantEmailProviderTransport(t *testing.T) {
+	keys, currentKeyID, err := parseAEADKeys("k1:" + testEmailKey(1))
+	require.NoError(t, err)
+	s := &TenantEmailProviderService{keys: keys, currentKeyID: currentKeyID}
+
+	encrypted, err := s.encryptSecret("api-key")
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "api-key")
+
+	transport, err := s.transport(repository.CoreTenantEmailProvider{
+		Provider:        emailservice.ProviderSendGrid,
+		EncryptedSecret: encrypted,
+		FromAddress:     "noreply@example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, emailservice.SendGridSender{APIKey: "api-key"}, transport.Sender)
+	require.Equal(t, "noreply@example.com", transport.From)
+
+	_, err = (&TenantEmailProviderService{}).Save(context.Background(), "tenant", TenantEmailProviderInput{
+		Provider:    emailservice.ProviderSendGrid,
+		FromAddress: "noreply@example.com",
+	}, "user")
+	require.ErrorIs(t, err, ErrTenantEmailProviderNotConfigured)
+}
+
+func TestTenantEmailProviderService(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	keys, currentKeyID, err := parseAEADKeys("k1:" + testEmailKey(1))
+	require.NoError(t, err)
+	s := &TenantEmailProviderService{store: store, keys: keys, currentKeyID: currentKeyID}
+	ctx := context.Background()
+	createdBy := commontestutils.RandomString(10)
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    createdBy,
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Get(ctx, tenant.TenantID)
+	require.ErrorIs(t, err, ErrTenantEmailProviderNotFound)
+
+	// An API key provider needs its key
+	input := TenantEmailProviderInput{Provider: emailservice.ProviderSendGrid, FromAddress: "noreply@example.com"}
+	_, err = s.Save(ctx, tenant.TenantID, input, createdBy)
+	require.ErrorIs(t, err, ErrInvalidTenantEmailProvider)
+
+	apiKey := "api-key"
+	input.Secret = &apiKey
+	_, err = s.Save(ctx, tenant.TenantID, input, createdBy)
+	require.NoError(t, err)
+
+	// Without a secret the stored one is kept
+	replyTo := "support@example.com"
+	input.Secret, input.ReplyTo = nil, &replyTo
+	provider, err := s.Save(ctx, tenant.TenantID, input, createdBy)
+	require.NoError(t, err)
+	require.Equal(t, "support@example.com", provider.ReplyTo.String)
+	transport, err := s.transport(provider)
+	require.NoError(t, err)
+	require.Equal(t, emailservice.SendGridSender{APIKey: "api-key"}, transport.Sender)
+	require.Equal(t, "support@example.com", transport.ReplyTo)
+
+	require.NoError(t, s.Delete(ctx, tenant.TenantID))
+	require.ErrorIs(t, s.Delete(ctx, tenant.TenantID), ErrTenantEmailProviderNotFound)
+}
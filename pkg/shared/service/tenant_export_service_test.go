@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantExportWrite(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewTenantExportService(store, nil, TenantExportConfigFromEnv())
+	ctx := context.Background()
+	tenantID := commontestutils.RandomString(10)
+	userID := commontestutils.RandomString(12)
+	actorID := commontestutils.RandomString(12)
+
+	from := time.Now().Add(-time.Minute)
+	err := store.CreateUserActivityEvent(ctx, repository.CreateUserActivityEventParams{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Category:  ActivityCategoryLogin,
+		EventType: "login_succeeded",
+		ActorID:   pgtype.Text{String: actorID, Valid: true},
+		Data:      []byte(`{"method":"password"}`),
+	})
+	require.NoError(t, err)
+	// Another tenant's activity stays out of the export
+	err = store.CreateUserActivityEvent(ctx, repository.CreateUserActivityEventParams{
+		TenantID:  commontestutils.RandomString(10),
+		UserID:    userID,
+		Category:  ActivityCategoryLogin,
+		EventType: "login_succeeded",
+	})
+	require.NoError(t, err)
+	to := time.Now().Add(time.Minute)
+
+	newExport := func(dataset, format string) repository.CoreTenantExport {
+		export, err := store.CreateTenantExport(ctx, repository.CreateTenantExportParams{
+			TenantID:    tenantID,
+			Dataset:     dataset,
+			Format:      format,
+			Destination: TenantExportDestinationDownload,
+			PeriodStart: from,
+			PeriodEnd:   to,
+			RequestedBy: actorID,
+		})
+		require.NoError(t, err)
+		return export
+	}
+
+	t.Run("audit as csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows, err := service.writeExport(ctx, &buf, newExport(TenantExportDatasetAudit, TenantExportFormatCSV))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, rows)
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		require.Equal(t, tenantAuditCSVHeader, records[0])
+		require.Equal(t, ActivityCategoryLogin, records[1][2])
+		require.Equal(t, userID, records[1][4])
+		require.Equal(t, actorID, records[1][5])
+	})
+
+	t.Run("audit as ocsf", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows, err := service.writeExport(ctx, &buf, newExport(TenantExportDatasetAudit, TenantExportFormatOCSF))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, rows)
+
+		var event ocsfEvent
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &event))
+		require.Equal(t, ocsfClassAuthentication, event.ClassUID)
+		require.Equal(t, ocsfClassAuthentication*100+ocsfActivityOther, event.TypeUID)
+		require.Equal(t, tenantID, event.Metadata.TenantUID)
+		require.Equal(t, userID, event.User.UID)
+		require.Equal(t, actorID, event.Actor.User.UID)
+	})
+
+	t.Run("empty usage as jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows, err := service.writeExport(ctx, &buf, newExport(TenantExportDatasetUsage, TenantExportFormatJSONL))
+		require.NoError(t, err)
+		require.Zero(t, rows)
+		require.Empty(t, buf.String())
+	})
+}
+
+func TestTenantExportValidation(t *testing.T) {
+	require.NoError(t, validateTenantExport(TenantExportDatasetAudit, TenantExportFormatOCSF, TenantExportDestinationBucket))
+	require.ErrorIs(t, validateTenantExport(TenantExportDatasetUsage, TenantExportFormatOCSF, TenantExportDestinationDownload), ErrInvalidTenantExport)
+	require.ErrorIs(t, validateTenantExport("users", TenantExportFormatCSV, TenantExportDestinationDownload), ErrInvalidTenantExport)
+	require.ErrorIs(t, validateTenantExport(TenantExportDatasetAudit, TenantExportFormatCSV, "email"), ErrInvalidTenantExport)
+
+	// Thursday afternoon
+	now := time.Date(2026, 10, 15, 15, 4, 5, 0, time.UTC)
+	require.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), nextExportPeriodEnd(now, TenantExportFrequencyDaily))
+	require.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), nextExportPeriodEnd(now, TenantExportFrequencyWeekly))
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// Outcomes of a tenant isolation probe
+const (
+	TenantIsolationProbeBlocked = "blocked"
+	TenantIsolationProbeLeaked  = "leaked"
+	TenantIsolationProbeSkipped = "skipped"
+	TenantIsolationProbeError   = "error"
+)
+
+const (
+	DefaultTenantIsolationProbeInterval  = time.Hour
+	DefaultTenantIsolationProbeRetention = 30 * 24 * time.Hour
+	DefaultTenantIsolationProbeResults   = 50
+	MaxTenantIsolationProbeResults       = 500
+	TenantIsolationLeakWebhookEvent      = "tenant_isolation.probe_leaked"
+	// TenantIsolationProbeUserID is the user the forged requests act as, so
+	// the authorization logs of the probes are told apart from real traffic
+	TenantIsolationProbeUserID  = "tenant-isolation-probe"
+	tenantIsolationProbeTimeout = 30 * time.Second
+)
+
+// ErrTenantIsolationProbeSkipped is returned by a probe when the victim tenant
+// has no resource to aim at
+var ErrTenantIsolationProbeSkipped = errors.New("the victim tenant has no resource to probe")
+
+// TenantIsolationProbeFunc requests a resource of the victim tenant through the
+// API, with c forged as a customer admin of the attacker tenant, and returns
+// the id of the resource. The outcome is read from the response written on c.
+type TenantIsolationProbeFunc func(c *gin.Context, store *db.Store, api core.ServerInterface, victimTenantID string) (string, error)
+
+type tenantIsolationProbe struct {
+	name  string
+	probe TenantIsolationProbeFunc
+}
+
+var (
+	tenantIsolationProbesMu sync.RWMutex
+	tenantIsolationProbes   = []tenantIsolationProbe{
+		{name: "user", probe: probeTenantUser},
+		{name: "client_application", probe: probeTenantClientApplication},
+		{name: "api_token", probe: probeTenantAPIToken},
+		{name: "tenant_export", probe: probeTenantExport},
+	}
+)
+
+// RegisterTenantIsolationProbe adds a probe run against every pair of
+// tenants, such as a read of the prompts of a module
+func RegisterTenantIsolationProbe(name string, probe TenantIsolationProbeFunc) {
+	tenantIsolationProbesMu.Lock()
+	defer tenantIsolationProbesMu.Unlock()
+	tenantIsolationProbes = append(tenantIsolationProbes, tenantIsolationProbe{name: name, probe: probe})
+}
+
+func getTenantIsolationProbes() []tenantIsolationProbe {
+	tenantIsolationProbesMu.RLock()
+	defer tenantIsolationProbesMu.RUnlock()
+	return append([]tenantIsolationProbe{}, tenantIsolationProbes...)
+}
+
+// TenantIsolationProbeConfig configures the probe job.
+//
+// Environment:
+//   - TENANT_ISOLATION_PROBE_INTERVAL: time between two runs (default 1h, 0 disables the job)
+//   - TENANT_ISOLATION_PROBE_RETENTION: how long the results are kept (default 720h)
+//   - TENANT_ISOLATION_ALERT_WEBHOOK_URL / TENANT_ISOLATION_ALERT_WEBHOOK_SECRET:
+//     optional webhook target notified of every leak
+type TenantIsolationProbeConfig struct {
+	Interval      time.Duration
+	Retention     time.Duration
+	WebhookURL    string
+	WebhookSecret string
+}
+
+func TenantIsolationProbeConfigFromEnv() TenantIsolationProbeConfig {
+	cfg := TenantIsolationProbeConfig{
+		Interval:      DefaultTenantIsolationProbeInterval,
+		Retention:     DefaultTenantIsolationProbeRetention,
+		WebhookURL:    os.Getenv("TENANT_ISOLATION_ALERT_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("TENANT_ISOLATION_ALERT_WEBHOOK_SECRET"),
+	}
+	if v := os.Getenv("TENANT_ISOLATION_PROBE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("TENANT_ISOLATION_PROBE_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("TENANT_ISOLATION_PROBE_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Retention = d
+		} else {
+			log.Warn().Str("TENANT_ISOLATION_PROBE_RETENTION", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// TenantIsolationLeak is the webhook payload of a probe that read a resource
+// of another tenant
+type TenantIsolationLeak struct {
+	RunID            uuid.UUID `json:"runId"`
+	Probe            string    `json:"probe"`
+	AttackerTenantID string    `json:"attackerTenantId"`
+	VictimTenantID   string    `json:"victimTenantId"`
+	ResourceID       string    `json:"resourceId"`
+	StatusCode       int       `json:"statusCode"`
+}
+
+// TenantIsolationProbeService verifies the boundary between the tenants while
+// the service runs: it requests the resources of a tenant through the API
+// handlers, acting as an admin of another tenant, and records whether each
+// request was refused.
+type TenantIsolationProbeService struct {
+	store *db.Store
+	api   core.ServerInterface
+	cfg   TenantIsolationProbeConfig
+}
+
+// NewTenantIsolationProbeService returns the service probing api. api may be
+// nil to only list the results.
+func NewTenantIsolationProbeService(store *db.Store, api core.ServerInterface, cfg TenantIsolationProbeConfig) *TenantIsolationProbeService {
+	return &TenantIsolationProbeService{store: store, api: api, cfg: cfg}
+}
+
+// StartTenantIsolationProbes runs RunProbes now and then every interval until
+// ctx is done. It does nothing when the interval is 0.
+func (s *TenantIsolationProbeService) StartTenantIsolationProbes(ctx context.Context) {
+	if s.cfg.Interval <= 0 || s.api == nil {
+		log.Info().Msg("Tenant isolation probes disabled")
+		return
+	}
+	dispatcher := webhook.NewDispatcher()
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.RunProbes(ctx, dispatcher); err != nil {
+				log.Err(err).Msg("Tenant isolation probe run failed")
+			}
+			if deleted, err := s.store.DeleteTenantIsolationProbeResultsBefore(ctx, time.Now().Add(-s.cfg.Retention)); err != nil {
+				log.Err(err).Msg("Failed to delete old tenant isolation probe results")
+			} else if deleted > 0 {
+				log.Info().Int64("results", deleted).Msg("Deleted old tenant isolation probe results")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunProbes picks two tenants at random and runs every probe from each of
+// them against the other. Every leak is logged and sent to the webhook. It
+// returns the results recorded, none when there are less than two tenants.
+func (s *TenantIsolationProbeService) RunProbes(ctx context.Context, dispatcher *webhook.Dispatcher) ([]repository.CreateTenantIsolationProbeResultParams, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	tenants, err := s.store.ListTenantIsolationProbeTenants(ctx, 2)
+	if err != nil {
+		return nil, fmt.Errorf("service.RunProbes: %w", err)
+	}
+	if len(tenants) < 2 {
+		return nil, nil
+	}
+
+	runID := uuid.New()
+	results := []repository.CreateTenantIsolationProbeResultParams{}
+	leaks := 0
+	for _, pair := range [][2]string{{tenants[0], tenants[1]}, {tenants[1], tenants[0]}} {
+		for _, probe := range getTenantIsolationProbes() {
+			result := s.runProbe(ctx, probe, pair[0], pair[1])
+			result.RunID = runID
+			if err := s.store.CreateTenantIsolationProbeResult(ctx, result); err != nil {
+				return results, fmt.Errorf("service.RunProbes: %w", err)
+			}
+			results = append(results, result)
+			if result.Outcome == TenantIsolationProbeLeaked {
+				leaks++
+				s.alert(ctx, dispatcher, result)
+			}
+		}
+	}
+	logger.Info().Str("run_id", runID.String()).Int("probes", len(results)).Int("leaks", leaks).
+		Msg("Tenant isolation probes completed")
+	return results, nil
+}
+
+// runProbe runs one probe from the attacker tenant against the victim tenant.
+// A panic of the handler is recorded as an error, not a crash of the job.
+func (s *TenantIsolationProbeService) runProbe(ctx context.Context, probe tenantIsolationProbe, attackerTenantID, victimTenantID string) (result repository.CreateTenantIsolationProbeResultParams) {
+	result = repository.CreateTenantIsolationProbeResultParams{
+		Probe:            probe.name,
+		AttackerTenantID: attackerTenantID,
+		VictimTenantID:   victimTenantID,
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			result.Outcome = TenantIsolationProbeError
+			result.Detail = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+
+	probeCtx, cancel := context.WithTimeout(ctx, tenantIsolationProbeTimeout)
+	defer cancel()
+	c, recorder := newTenantIsolationProbeContext(probeCtx, probe.name, attackerTenantID)
+	resourceID, err := probe.probe(c, s.store, s.api, victimTenantID)
+	result.ResourceID = resourceID
+	switch {
+	case errors.Is(err, ErrTenantIsolationProbeSkipped):
+		result.Outcome = TenantIsolationProbeSkipped
+	case err != nil:
+		result.Outcome = TenantIsolationProbeError
+		result.Detail = err.Error()
+	default:
+		result.StatusCode = int32(c.Writer.Status())
+		result.Outcome = TenantIsolationProbeOutcome(c.Writer.Written(), c.Writer.Status())
+		if result.Outcome != TenantIsolationProbeBlocked {
+			result.Detail = recorder.Body.String()
+		}
+	}
+	return result
+}
+
+// TenantIsolationProbeOutcome classifies the response to a forged request: a
+// success is a leak, a server error or no response at all says nothing about
+// the boundary, and anything else is a refusal
+func TenantIsolationProbeOutcome(written bool, status int) string {
+	switch {
+	case !written:
+		return TenantIsolationProbeError
+	case status >= 200 && status < 300:
+		return TenantIsolationProbeLeaked
+	case status >= 500:
+		return TenantIsolationProbeError
+	default:
+		return TenantIsolationProbeBlocked
+	}
+}
+
+// newTenantIsolationProbeContext forges the context the auth middleware would
+// set for a customer admin of the tenant
+func newTenantIsolationProbeContext(ctx context.Context, probe, tenantID string) (*gin.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/tenant-isolation-probes/"+probe, nil)
+	c.Set(auth.AUTH_TENANT_ID_KEY, tenantID)
+	c.Set(auth.AUTH_USER_ID, TenantIsolationProbeUserID)
+	c.Set(auth.AUTH_CLAIMS, map[string]interface{}{auth.SubjectCustomerAdmin: true})
+	c.Set(auth.AUTH_ACCESS_SCOPE, auth.AccessScope{TenantID: tenantID, UserID: TenantIsolationProbeUserID})
+	return c, recorder
+}
+
+func (s *TenantIsolationProbeService) alert(ctx context.Context, dispatcher *webhook.Dispatcher, result repository.CreateTenantIsolationProbeResultParams) {
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Error().Str("run_id", result.RunID.String()).Str("probe", result.Probe).
+		Str("attacker_tenant_id", result.AttackerTenantID).Str("victim_tenant_id", result.VictimTenantID).
+		Str("resource_id", result.ResourceID).Int32("status", result.StatusCode).
+		Msg("Tenant isolation probe read a resource of another tenant")
+	if s.cfg.WebhookURL == "" || dispatcher == nil {
+		return
+	}
+	notifyCtx, cancel := context.WithTimeout(ctx, tenantIsolationProbeTimeout)
+	defer cancel()
+	envelope := webhook.Envelope{
+		ID:        uuid.New().String(),
+		EventType: TenantIsolationLeakWebhookEvent,
+		CreatedAt: time.Now().UTC(),
+		Data: TenantIsolationLeak{
+			RunID:            result.RunID,
+			Probe:            result.Probe,
+			AttackerTenantID: result.AttackerTenantID,
+			VictimTenantID:   result.VictimTenantID,
+			ResourceID:       result.ResourceID,
+			StatusCode:       int(result.StatusCode),
+		},
+	}
+	target := webhook.Target{URL: s.cfg.WebhookURL, Secret: s.cfg.WebhookSecret}
+	if err := dispatcher.Deliver(notifyCtx, target, envelope, ""); err != nil {
+		logger.Err(err).Str("probe", result.Probe).Msg("Failed to deliver tenant isolation alert webhook")
+	}
+}
+
+// ListResults lists the recorded results newest first, only those with the
+// outcome when it is not empty
+func (s *TenantIsolationProbeService) ListResults(ctx context.Context, outcome string, limit, offset int32) ([]repository.CoreTenantIsolationProbeResult, error) {
+	if limit <= 0 {
+		limit = DefaultTenantIsolationProbeResults
+	}
+	results, err := s.store.ListTenantIsolationProbeResults(ctx, repository.ListTenantIsolationProbeResultsParams{
+		Outcome: pgtype.Text{String: outcome, Valid: outcome != ""},
+		Limit:   min(limit, MaxTenantIsolationProbeResults),
+		Offset:  max(offset, 0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListResults: %w", err)
+	}
+	return results, nil
+}
+
+// skipWhenNone turns the absence of a victim resource into a skip
+func skipWhenNone(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTenantIsolationProbeSkipped
+	}
+	return err
+}
+
+func probeTenantUser(c *gin.Context, store *db.Store, api core.ServerInterface, victimTenantID string) (string, error) {
+	userID, err := store.GetTenantIsolationProbeUser(c, repository.GetTenantIsolationProbeUserParams{
+		VictimTenantID:   victimTenantID,
+		AttackerTenantID: c.GetString(auth.AUTH_TENANT_ID_KEY),
+	})
+	if err != nil {
+		return "", skipWhenNone(err)
+	}
+	api.GetUserByID(c, userID)
+	return userID, nil
+}
+
+func probeTenantClientApplication(c *gin.Context, store *db.Store, api core.ServerInterface, victimTenantID string) (string, error) {
+	row, err := store.GetTenantIsolationProbeAPIToken(c, victimTenantID)
+	if err != nil {
+		return "", skipWhenNone(err)
+	}
+	api.GetTenantClientApplicationById(c, row.ClientApplicationID)
+	return row.ClientApplicationID.String(), nil
+}
+
+func probeTenantAPIToken(c *gin.Context, store *db.Store, api core.ServerInterface, victimTenantID string) (string, error) {
+	row, err := store.GetTenantIsolationProbeAPIToken(c, victimTenantID)
+	if err != nil {
+		return "", skipWhenNone(err)
+	}
+	if !row.TokenID.Valid {
+		return "", ErrTenantIsolationProbeSkipped
+	}
+	tokenID := uuid.UUID(row.TokenID.Bytes)
+	api.GetTenantAPITokenById(c, row.ClientApplicationID, tokenID)
+	return tokenID.String(), nil
+}
+
+func probeTenantExport(c *gin.Context, store *db.Store, api core.ServerInterface, victimTenantID string) (string, error) {
+	exportID, err := store.GetTenantIsolationProbeExport(c, victimTenantID)
+	if err != nil {
+		return "", skipWhenNone(err)
+	}
+	api.GetTenantExport(c, exportID)
+	return exportID.String(), nil
+}
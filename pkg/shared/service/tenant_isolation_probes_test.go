@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantIsolationProbeOutcome(t *testing.T) {
+	require.Equal(t, TenantIsolationProbeLeaked, TenantIsolationProbeOutcome(true, http.StatusOK))
+	require.Equal(t, TenantIsolationProbeBlocked, TenantIsolationProbeOutcome(true, http.StatusNotFound))
+	require.Equal(t, TenantIsolationProbeBlocked, TenantIsolationProbeOutcome(true, http.StatusForbidden))
+	require.Equal(t, TenantIsolationProbeError, TenantIsolationProbeOutcome(true, http.StatusInternalServerError))
+	require.Equal(t, TenantIsolationProbeError, TenantIsolationProbeOutcome(false, http.StatusOK))
+}
+
+func TestRunTenantIsolationProbe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := NewTenantIsolationProbeService(nil, nil, TenantIsolationProbeConfig{})
+	run := func(probe TenantIsolationProbeFunc) (outcome string, status int32) {
+		result := service.runProbe(context.Background(), tenantIsolationProbe{name: "test", probe: probe}, "attacker", "victim")
+		require.Equal(t, "attacker", result.AttackerTenantID)
+		require.Equal(t, "victim", result.VictimTenantID)
+		return result.Outcome, result.StatusCode
+	}
+	respond := func(status int) TenantIsolationProbeFunc {
+		return func(c *gin.Context, _ *db.Store, _ core.ServerInterface, _ string) (string, error) {
+			// The request acts as a customer admin of the attacker tenant
+			require.Equal(t, "attacker", c.GetString(auth.AUTH_TENANT_ID_KEY))
+			require.Equal(t, []string{auth.SubjectCustomerAdmin}, auth.SubjectFromContext(c).Roles)
+			c.JSON(status, gin.H{})
+			return "r1", nil
+		}
+	}
+
+	outcome, status := run(respond(http.StatusOK))
+	require.Equal(t, TenantIsolationProbeLeaked, outcome)
+	require.EqualValues(t, http.StatusOK, status)
+	outcome, status = run(respond(http.StatusNotFound))
+	require.Equal(t, TenantIsolationProbeBlocked, outcome)
+	require.EqualValues(t, http.StatusNotFound, status)
+
+	outcome, _ = run(func(*gin.Context, *db.Store, core.ServerInterface, string) (string, error) {
+		return "", ErrTenantIsolationProbeSkipped
+	})
+	require.Equal(t, TenantIsolationProbeSkipped, outcome)
+	outcome, _ = run(func(*gin.Context, *db.Store, core.ServerInterface, string) (string, error) {
+		panic("handler failure")
+	})
+	require.Equal(t, TenantIsolationProbeError, outcome)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+// Lifecycle statuses of a tenant. A suspended tenant is kept but its requests
+// are refused; an archived tenant is offboarded and hidden from the listings.
+const (
+	TenantStatusActive    = "active"
+	TenantStatusSuspended = "suspended"
+	TenantStatusArchived  = "archived"
+)
+
+var (
+	// ErrInvalidTenantStatus is returned for a status that is not a lifecycle status
+	ErrInvalidTenantStatus = errors.New("tenant status must be active, suspended or archived")
+	// ErrTenantStatusTransition is returned when the tenant cannot move from its
+	// status to the requested one, or its status changed meanwhile
+	ErrTenantStatusTransition = errors.New("tenant status transition not allowed")
+)
+
+// tenantStatusTransitions lists the statuses a tenant can move to from each
+// status. An archived tenant is restored to active before being suspended.
+var tenantStatusTransitions = map[string][]string{
+	TenantStatusActive:    {TenantStatusSuspended, TenantStatusArchived},
+	TenantStatusSuspended: {TenantStatusActive, TenantStatusArchived},
+	TenantStatusArchived:  {TenantStatusActive},
+}
+
+// IsValidTenantStatus reports whether the status is a lifecycle status
+func IsValidTenantStatus(status string) bool {
+	_, ok := tenantStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionTenantStatus reports whether a tenant can move from a status to another
+func CanTransitionTenantStatus(from, to string) bool {
+	return slices.Contains(tenantStatusTransitions[from], to)
+}
+
+// tenantStatusSources returns the statuses from which a tenant can move to the status
+func tenantStatusSources(to string) []string {
+	sources := []string{}
+	for from, targets := range tenantStatusTransitions {
+		if slices.Contains(targets, to) {
+			sources = append(sources, from)
+		}
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// SetTenantStatus moves the tenant to the status, recording the reason, and
+// drops it from the cache so the tenant middleware applies it at once.
+// Setting the status the tenant already has changes nothing.
+func (uh *MultitenantService) SetTenantStatus(ctx context.Context, tenant repository.CoreTenant,
+	status string, reason *string, updatedBy string) (repository.CoreTenant, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if !IsValidTenantStatus(status) {
+		return repository.CoreTenant{}, ErrInvalidTenantStatus
+	}
+	if tenant.Status == status {
+		return tenant, nil
+	}
+	if !CanTransitionTenantStatus(tenant.Status, status) {
+		return repository.CoreTenant{}, fmt.Errorf("%w: from %s to %s", ErrTenantStatusTransition, tenant.Status, status)
+	}
+
+	// The update only applies from a source status, so a concurrent change
+	// cannot lead to a transition that is not allowed
+	updated, err := uh.store.UpdateTenantStatus(ctx, repository.UpdateTenantStatusParams{
+		Status:       status,
+		StatusReason: util.ToNullableText(reason),
+		TenantID:     tenant.TenantID,
+		FromStatuses: tenantStatusSources(status),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.CoreTenant{}, fmt.Errorf("%w: the status of the tenant changed", ErrTenantStatusTransition)
+		}
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Str("status", status).Msg("Failed to update tenant status")
+		return repository.CoreTenant{}, fmt.Errorf("service.SetTenantStatus: %w", err)
+	}
+	uh.InvalidateTenant(tenant.TenantID)
+	logger.Info().Str("tenant_id", tenant.TenantID).Str("from", tenant.Status).Str("status", status).
+		Str("updated_by", updatedBy).Msg("Tenant status changed")
+	return updated, nil
+}
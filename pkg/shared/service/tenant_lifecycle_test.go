@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/util"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantStatusTransitions(t *testing.T) {
+	require.True(t, CanTransitionTenantStatus(TenantStatusActive, TenantStatusSuspended))
+	require.True(t, CanTransitionTenantStatus(TenantStatusSuspended, TenantStatusArchived))
+	require.True(t, CanTransitionTenantStatus(TenantStatusArchived, TenantStatusActive))
+	require.False(t, CanTransitionTenantStatus(TenantStatusArchived, TenantStatusSuspended))
+	require.False(t, CanTransitionTenantStatus(TenantStatusActive, TenantStatusActive))
+	require.False(t, IsValidTenantStatus("deleted"))
+
+	require.Equal(t, []string{TenantStatusActive, TenantStatusSuspended}, tenantStatusSources(TenantStatusArchived))
+	require.Equal(t, []string{TenantStatusArchived, TenantStatusSuspended}, tenantStatusSources(TenantStatusActive))
+}
+
+func TestSetTenantStatus(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewMultitenantService(store)
+	ctx := context.Background()
+	createdBy := commontestutils.RandomString(10)
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    createdBy,
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, TenantStatusActive, tenant.Status)
+
+	listed := func(status pgtype.Text) bool {
+		tenants, err := store.ListTenants(ctx, repository.ListTenantsParams{
+			Limit:  50,
+			Like:   util.ToNullableText(&tenant.Name),
+			Status: status,
+			Order:  "asc",
+			SortBy: "name",
+		})
+		require.NoError(t, err)
+		return len(tenants) == 1
+	}
+
+	t.Run("a suspended tenant is reported by the cache", func(t *testing.T) {
+		_, err := service.GetTenantBySubdomainCached(ctx, tenant.Subdomain)
+		require.NoError(t, err)
+
+		reason := "unpaid invoices"
+		suspended, err := service.SetTenantStatus(ctx, tenant, TenantStatusSuspended, &reason, createdBy)
+		require.NoError(t, err)
+		require.Equal(t, TenantStatusSuspended, suspended.Status)
+		require.Equal(t, reason, suspended.StatusReason.String)
+		require.True(t, suspended.StatusChangedAt.Valid)
+
+		cached, err := service.GetTenantBySubdomainCached(ctx, tenant.Subdomain)
+		require.NoError(t, err)
+		require.Equal(t, TenantStatusSuspended, cached.Status)
+		tenant = suspended
+	})
+
+	t.Run("an archived tenant is hidden from the listing", func(t *testing.T) {
+		require.True(t, listed(pgtype.Text{}))
+		archived, err := service.SetTenantStatus(ctx, tenant, TenantStatusArchived, nil, createdBy)
+		require.NoError(t, err)
+		require.False(t, archived.StatusReason.Valid)
+		require.False(t, listed(pgtype.Text{}))
+		require.True(t, listed(pgtype.Text{String: TenantStatusArchived, Valid: true}))
+
+		_, err = service.SetTenantStatus(ctx, archived, TenantStatusSuspended, nil, createdBy)
+		require.ErrorIs(t, err, ErrTenantStatusTransition)
+		// A stale copy of the tenant cannot bypass the transitions
+		stale := archived
+		stale.Status = TenantStatusActive
+		_, err = service.SetTenantStatus(ctx, stale, TenantStatusSuspended, nil, createdBy)
+		require.ErrorIs(t, err, ErrTenantStatusTransition)
+		_, err = service.SetTenantStatus(ctx, archived, "deleted", nil, createdBy)
+		require.ErrorIs(t, err, ErrInvalidTenantStatus)
+
+		restored, err := service.SetTenantStatus(ctx, archived, TenantStatusActive, nil, createdBy)
+		require.NoError(t, err)
+		require.Equal(t, TenantStatusActive, restored.Status)
+		require.True(t, listed(pgtype.Text{}))
+	})
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"ctoup.com/coreapp/pkg/shared/auth"
 	utils "ctoup.com/coreapp/pkg/shared/util"
@@ -14,12 +15,14 @@ import (
 // TenantMiddleware is middleware to extract tenant information from the request and set it in the context
 type TenantMiddleware struct {
 	multitenantService *MultitenantService
+	settingsService    *TenantSettingsService
 }
 
 // New is constructor of the middleware
 func NewTenantMiddleware(unAuthorized func(c *gin.Context), multitenantService *MultitenantService) *TenantMiddleware {
 	return &TenantMiddleware{
 		multitenantService: multitenantService,
+		settingsService:    NewTenantSettingsService(multitenantService.store),
 	}
 }
 
@@ -63,6 +66,26 @@ func (fam *TenantMiddleware) MiddlewareFunc() gin.HandlerFunc {
 			ctx.Abort()
 			return
 		}
+		// An archived tenant is offboarded and a tenant pending deletion waits
+		// for its purge: their subdomain answers as if they did not exist. A
+		// suspended one is kept, locked until reactivated.
+		switch tenant.Status {
+		case TenantStatusArchived, TenantStatusPendingDeletion:
+			log.Info().Str("subdomain", subdomain).Str("status", tenant.Status).Msg("Tenant archived")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"status":  http.StatusNotFound,
+				"message": "Tenant not found",
+			})
+			ctx.Abort()
+			return
+		case TenantStatusSuspended:
+			ctx.JSON(http.StatusLocked, gin.H{
+				"status":  http.StatusLocked,
+				"message": "Tenant has been suspended",
+			})
+			ctx.Abort()
+			return
+		}
 		if tenant.IsDisabled {
 			ctx.JSON(http.StatusForbidden, gin.H{
 				"status":  http.StatusForbidden,
@@ -72,8 +95,58 @@ func (fam *TenantMiddleware) MiddlewareFunc() gin.HandlerFunc {
 			return
 		}
 
+		if tenant.Subdomain != subdomain && redirectToPrimarySubdomain(ctx, tenant.Subdomain) {
+			return
+		}
+
 		ctx.Set(auth.AUTH_TENANT, tenant)
 		ctx.Set(auth.AUTH_TENANT_ID_KEY, tenant.TenantID)
+		// The settings are cached like the tenant; handlers read them with
+		// TenantSettingValuesFromContext. A failure only leaves them unset.
+		if values, err := fam.settingsService.GetSettingValues(ctx, tenant.TenantID); err == nil {
+			ctx.Set(tenantSettingValuesContextKey, values)
+		} else {
+			log.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to load tenant settings")
+		}
 		ctx.Next()
 	}
 }
+
+// PrimarySubdomainHeader names the primary subdomain of the tenant on the
+// responses to requests made through one of its aliases
+const PrimarySubdomainHeader = "X-Tenant-Primary-Subdomain"
+
+// redirectToPrimarySubdomain handles a request made through a subdomain
+// alias. Browser navigations are redirected to the same URL on the primary
+// subdomain; API calls are served, with headers pointing at the primary
+// subdomain so clients can migrate. It reports whether the request was
+// answered.
+func redirectToPrimarySubdomain(ctx *gin.Context, primary string) bool {
+	ctx.Header(PrimarySubdomainHeader, primary)
+	target, err := utils.URLWithSubdomain(ctx, primary)
+	if err != nil {
+		log.Warn().Err(err).Str("subdomain", primary).Msg("Failed to build primary subdomain URL")
+		return false
+	}
+	ctx.Header("Link", "<"+target.String()+`>; rel="canonical"`)
+	if !isBrowserNavigation(ctx.Request) {
+		return false
+	}
+	// Temporary, so browsers do not cache it: the alias may be made primary
+	// later, and a cached redirect would then loop
+	ctx.Redirect(http.StatusTemporaryRedirect, target.String())
+	ctx.Abort()
+	return true
+}
+
+// isBrowserNavigation reports whether the request is a page load rather than
+// an API call: a GET or HEAD accepting HTML
+func isBrowserNavigation(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if mode := r.Header.Get("Sec-Fetch-Mode"); mode != "" {
+		return mode == "navigate"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Steps of a provisioning, in the order they run. The module steps follow
+// the core ones.
+const (
+	TenantCloneStepSettings       = "settings"
+	TenantCloneStepSettingValues  = "setting_values"
+	TenantCloneStepRoles          = "roles"
+	TenantCloneStepEmailTemplates = "email_templates"
+	TenantCloneStepBranding       = "branding"
+)
+
+var tenantTemplateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+var (
+	// ErrTenantTemplateNotFound is returned for a template name that does not exist
+	ErrTenantTemplateNotFound = errors.New("tenant template not found")
+	// ErrInvalidTenantTemplateName is returned for a name that is not lowercase
+	// letters, digits and dashes
+	ErrInvalidTenantTemplateName = errors.New("a template name is 1 to 64 lowercase letters, digits or dashes")
+	// ErrTenantProvisioningUnsupported is returned when the auth provider
+	// cannot create tenants
+	ErrTenantProvisioningUnsupported = errors.New("tenant operations not supported")
+)
+
+// TenantCloneFunc copies the data a module keeps for the source tenant into
+// the target tenant, such as its prompts, and returns how many items it
+// copied. It runs once the target tenant exists with its core settings.
+type TenantCloneFunc func(ctx context.Context, sourceTenantID, targetTenantID, userID string) (int64, error)
+
+type tenantCloneStep struct {
+	name  string
+	clone TenantCloneFunc
+}
+
+var (
+	tenantCloneStepsMu sync.RWMutex
+	tenantCloneSteps   []tenantCloneStep
+)
+
+// RegisterTenantCloneStep lets a module copy its data when a tenant is
+// provisioned from another. Steps run in registration order, after the core
+// steps, and are reported as module:<name>.
+func RegisterTenantCloneStep(name string, clone TenantCloneFunc) {
+	tenantCloneStepsMu.Lock()
+	defer tenantCloneStepsMu.Unlock()
+	tenantCloneSteps = append(tenantCloneSteps, tenantCloneStep{name: "module:" + name, clone: clone})
+}
+
+func getTenantCloneSteps() []tenantCloneStep {
+	tenantCloneStepsMu.RLock()
+	defer tenantCloneStepsMu.RUnlock()
+	return append([]tenantCloneStep{}, tenantCloneSteps...)
+}
+
+// TenantProvisionRequest describes the tenant to create. The sign-up options
+// default to those of the source tenant.
+type TenantProvisionRequest struct {
+	Name                string
+	Subdomain           string
+	AllowPasswordSignUp *bool
+	AllowSignUp         *bool
+	ResellerID          pgtype.Text
+	CreatedBy           string
+}
+
+// TenantCloneStepReport is what a step of a provisioning copied. A failed
+// module or branding step leaves the tenant created, without that part.
+type TenantCloneStepReport struct {
+	Name   string `json:"name"`
+	Copied int64  `json:"copied"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TenantProvisioning is a tenant created from a source tenant, directly or
+// through a template
+type TenantProvisioning struct {
+	Tenant         repository.CoreTenant
+	SourceTenantID string
+	Template       *string
+	Steps          []TenantCloneStepReport
+}
+
+// TenantProvisioningService creates tenants as copies of another: its
+// settings, typed settings, custom roles, email templates, branding pictures
+// and the module data of the registered clone steps. Members, files other than
+// the branding, quotas and licenses are not copied.
+type TenantProvisioningService struct {
+	store              *db.Store
+	settings           *TenantSettingsService
+	files              *fileservice.FileService
+	authProvider       auth.AuthProvider
+	multiTenantService *MultitenantService
+}
+
+func NewTenantProvisioningService(store *db.Store, files *fileservice.FileService, authProvider auth.AuthProvider,
+	multiTenantService *MultitenantService) *TenantProvisioningService {
+	return &TenantProvisioningService{
+		store:              store,
+		settings:           NewTenantSettingsService(store),
+		files:              files,
+		authProvider:       authProvider,
+		multiTenantService: multiTenantService,
+	}
+}
+
+// ListTemplates returns the templates by name
+func (s *TenantProvisioningService) ListTemplates(ctx context.Context) ([]repository.CoreTenantTemplate, error) {
+	return s.store.ListTenantTemplates(ctx)
+}
+
+// SaveTemplate creates the template, or points an existing one at the source
+// tenant
+func (s *TenantProvisioningService) SaveTemplate(ctx context.Context, name string, description *string, source repository.CoreTenant, userID string) (repository.CoreTenantTemplate, error) {
+	if !tenantTemplateNamePattern.MatchString(name) {
+		return repository.CoreTenantTemplate{}, ErrInvalidTenantTemplateName
+	}
+	template, err := s.store.UpsertTenantTemplate(ctx, repository.UpsertTenantTemplateParams{
+		Name:        name,
+		Description: util.ToNullableText(description),
+		TenantID:    source.TenantID,
+		CreatedBy:   userID,
+	})
+	if err != nil {
+		return repository.CoreTenantTemplate{}, fmt.Errorf("service.SaveTemplate: %w", err)
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes the template. Its source tenant is kept.
+func (s *TenantProvisioningService) DeleteTemplate(ctx context.Context, name string) error {
+	deleted, err := s.store.DeleteTenantTemplate(ctx, name)
+	if err != nil {
+		return fmt.Errorf("service.DeleteTemplate: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTenantTemplateNotFound
+	}
+	return nil
+}
+
+// ProvisionFromTemplate creates a tenant as a copy of the source tenant of
+// the template
+func (s *TenantProvisioningService) ProvisionFromTemplate(ctx context.Context, name string, req TenantProvisionRequest) (TenantProvisioning, error) {
+	template, err := s.store.GetTenantTemplate(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TenantProvisioning{}, ErrTenantTemplateNotFound
+		}
+		return TenantProvisioning{}, fmt.Errorf("service.ProvisionFromTemplate: %w", err)
+	}
+	source, err := s.store.GetTenantByTenantID(ctx, template.TenantID)
+	if err != nil {
+		return TenantProvisioning{}, fmt.Errorf("service.ProvisionFromTemplate: %w", err)
+	}
+	provisioning, err := s.CloneTenant(ctx, source, req)
+	if err != nil {
+		return provisioning, err
+	}
+	provisioning.Template = &template.Name
+	return provisioning, nil
+}
+
+// CloneTenant creates a tenant in the auth provider and in the database as a
+// copy of the source tenant. The tenant, its settings, roles and email
+// templates are created in one transaction, the tenant of the auth provider
+// being deleted again when it fails. The branding and module steps run once
+// the tenant exists; their failures are reported, not rolled back.
+func (s *TenantProvisioningService) CloneTenant(ctx context.Context, source repository.CoreTenant, req TenantProvisionRequest) (TenantProvisioning, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	tenantManager := s.authProvider.GetTenantManager()
+	if tenantManager == nil {
+		return TenantProvisioning{}, ErrTenantProvisioningUnsupported
+	}
+
+	allowPasswordSignUp := source.AllowPasswordSignUp
+	if req.AllowPasswordSignUp != nil {
+		allowPasswordSignUp = *req.AllowPasswordSignUp
+	}
+	allowSignUp := source.AllowSignUp
+	if req.AllowSignUp != nil {
+		allowSignUp = *req.AllowSignUp
+	}
+
+	authTenant, err := tenantManager.CreateTenant(ctx, &auth.TenantConfig{
+		DisplayName:         req.Name,
+		Subdomain:           req.Subdomain,
+		AllowPasswordSignUp: allowPasswordSignUp,
+	})
+	if err != nil {
+		return TenantProvisioning{}, fmt.Errorf("service.CloneTenant: %w", err)
+	}
+
+	tenant, steps, err := s.copyTenant(ctx, source, repository.CreateTenantParams{
+		UserID:              req.CreatedBy,
+		Name:                req.Name,
+		TenantID:            authTenant.ID,
+		Subdomain:           req.Subdomain,
+		AllowPasswordSignUp: allowPasswordSignUp,
+		AllowSignUp:         allowSignUp,
+		ResellerID:          req.ResellerID,
+	})
+	if err != nil {
+		if rbErr := tenantManager.DeleteTenant(ctx, authTenant.ID); rbErr != nil {
+			logger.Err(rbErr).Str("tenant_id", authTenant.ID).Msg("Failed to rollback tenant creation in auth provider")
+		}
+		return TenantProvisioning{}, fmt.Errorf("service.CloneTenant: %w", err)
+	}
+
+	steps = append(steps, s.copyBranding(ctx, source.TenantID, tenant.TenantID))
+	for _, step := range getTenantCloneSteps() {
+		result := TenantCloneStepReport{Name: step.name}
+		result.Copied, err = step.clone(ctx, source.TenantID, tenant.TenantID, req.CreatedBy)
+		if err != nil {
+			logger.Err(err).Str("tenant_id", tenant.TenantID).Str("step", step.name).Msg("Failed to clone module data")
+			result.Error = err.Error()
+		}
+		steps = append(steps, result)
+	}
+
+	logger.Info().Str("tenant_id", tenant.TenantID).Str("source_tenant_id", source.TenantID).Msg("Tenant provisioned")
+	return TenantProvisioning{Tenant: tenant, SourceTenantID: source.TenantID, Steps: steps}, nil
+}
+
+// copyTenant creates the tenant row and copies the database parts of the
+// source tenant in one transaction
+func (s *TenantProvisioningService) copyTenant(ctx context.Context, source repository.CoreTenant, params repository.CreateTenantParams) (repository.CoreTenant, []TenantCloneStepReport, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return repository.CoreTenant{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	tenant, err := qtx.CreateTenant(ctx, params)
+	if err != nil {
+		return repository.CoreTenant{}, nil, err
+	}
+
+	steps := []TenantCloneStepReport{}
+	copied, err := s.copySettings(ctx, qtx, source, tenant, params.UserID)
+	if err != nil {
+		return repository.CoreTenant{}, nil, fmt.Errorf("%s: %w", TenantCloneStepSettings, err)
+	}
+	steps = append(steps, TenantCloneStepReport{Name: TenantCloneStepSettings, Copied: copied})
+
+	copied, err = qtx.CopyTenantSettingValues(ctx, repository.CopyTenantSettingValuesParams{
+		TargetTenantID: tenant.TenantID,
+		UpdatedBy:      params.UserID,
+		SourceTenantID: source.TenantID,
+	})
+	if err != nil {
+		return repository.CoreTenant{}, nil, fmt.Errorf("%s: %w", TenantCloneStepSettingValues, err)
+	}
+	steps = append(steps, TenantCloneStepReport{Name: TenantCloneStepSettingValues, Copied: copied})
+
+	copied, err = copyTenantRoles(ctx, qtx, source.TenantID, tenant.TenantID, params.UserID)
+	if err != nil {
+		return repository.CoreTenant{}, nil, fmt.Errorf("%s: %w", TenantCloneStepRoles, err)
+	}
+	steps = append(steps, TenantCloneStepReport{Name: TenantCloneStepRoles, Copied: copied})
+
+	copied, err = qtx.CopyTenantEmailTemplates(ctx, repository.CopyTenantEmailTemplatesParams{
+		TargetTenantID: tenant.TenantID,
+		UpdatedBy:      params.UserID,
+		SourceTenantID: source.TenantID,
+	})
+	if err != nil {
+		return repository.CoreTenant{}, nil, fmt.Errorf("%s: %w", TenantCloneStepEmailTemplates, err)
+	}
+	steps = append(steps, TenantCloneStepReport{Name: TenantCloneStepEmailTemplates, Copied: copied})
+
+	// The tenant is read again for the features and profile just applied
+	tenant, err = qtx.GetTenantByTenantID(ctx, tenant.TenantID)
+	if err != nil {
+		return repository.CoreTenant{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return repository.CoreTenant{}, nil, err
+	}
+	return tenant, steps, nil
+}
+
+// copySettings applies the settings of the source tenant to the tenant, as a
+// settings import would, keeping the name and sign-up options of the tenant.
+// It returns the number of settings changed.
+func (s *TenantProvisioningService) copySettings(ctx context.Context, q *repository.Queries, source, tenant repository.CoreTenant, userID string) (int64, error) {
+	target, err := s.settings.currentSettings(ctx, q, source)
+	if err != nil {
+		return 0, err
+	}
+	target.AllowPasswordSignUp = tenant.AllowPasswordSignUp
+	target.AllowSignUp = tenant.AllowSignUp
+	target.Profile.DisplayName = tenant.Name
+
+	current, err := s.settings.currentSettings(ctx, q, tenant)
+	if err != nil {
+		return 0, err
+	}
+	plan, err := planTenantSettings(current, target)
+	if err != nil {
+		return 0, err
+	}
+	if err := applyTenantSettings(ctx, q, tenant, current, target, plan.Changes, userID); err != nil {
+		return 0, err
+	}
+	return int64(len(plan.Changes)), nil
+}
+
+// copyTenantRoles creates the custom roles of the source tenant with their
+// permissions in the target tenant. Their members are not copied.
+func copyTenantRoles(ctx context.Context, q *repository.Queries, sourceTenantID, targetTenantID, userID string) (int64, error) {
+	roles, err := q.ListRoles(ctx, sourceTenantID)
+	if err != nil {
+		return 0, err
+	}
+	permissions, err := q.ListTenantRolePermissions(ctx, sourceTenantID)
+	if err != nil {
+		return 0, err
+	}
+	operations := make(map[uuid.UUID][]string, len(roles))
+	for _, permission := range permissions {
+		operations[permission.RoleID] = append(operations[permission.RoleID], permission.Operation)
+	}
+
+	for _, role := range roles {
+		created, err := q.CreateRole(ctx, repository.CreateRoleParams{
+			TenantID:    targetTenantID,
+			Name:        role.Name,
+			Description: role.Description,
+			CreatedBy:   userID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if len(operations[role.ID]) == 0 {
+			continue
+		}
+		if err := q.AddRolePermissions(ctx, repository.AddRolePermissionsParams{
+			RoleID:     created.ID,
+			Operations: operations[role.ID],
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(roles)), nil
+}
+
+// copyBranding copies the branding pictures the source tenant uploaded
+func (s *TenantProvisioningService) copyBranding(ctx context.Context, sourceTenantID, targetTenantID string) TenantCloneStepReport {
+	result := TenantCloneStepReport{Name: TenantCloneStepBranding}
+	if s.files == nil {
+		return result
+	}
+	for _, pictureType := range fileservice.TenantPictureTypes {
+		src := fileservice.TenantPicturePath(sourceTenantID, pictureType)
+		exists, err := s.files.FileExists(ctx, src)
+		if err == nil && exists {
+			err = s.files.CopyFile(ctx, fileservice.TenantPicturePath(targetTenantID, pictureType), src)
+			if err == nil {
+				result.Copied++
+			}
+		}
+		if err != nil {
+			result.Error = fmt.Sprintf("%s: %s", pictureType, err)
+			return result
+		}
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterTenantCloneStep(t *testing.T) {
+	tenantCloneStepsMu.Lock()
+	saved := tenantCloneSteps
+	tenantCloneSteps = nil
+	tenantCloneStepsMu.Unlock()
+	t.Cleanup(func() {
+		tenantCloneStepsMu.Lock()
+		tenantCloneSteps = saved
+		tenantCloneStepsMu.Unlock()
+	})
+
+	RegisterTenantCloneStep("prompts", func(ctx context.Context, sourceTenantID, targetTenantID, userID string) (int64, error) {
+		return 7, nil
+	})
+	steps := getTenantCloneSteps()
+	require.Len(t, steps, 1)
+	require.Equal(t, "module:prompts", steps[0].name)
+	copied, err := steps[0].clone(context.Background(), "source", "target", "user")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), copied)
+}
+
+func TestTenantTemplateName(t *testing.T) {
+	s := NewTenantProvisioningService(nil, nil, nil, nil)
+	for _, name := range []string{"", "Standard", "-standard", "standard customer", strings.Repeat("a", 65)} {
+		_, err := s.SaveTemplate(context.Background(), name, nil, repository.CoreTenant{}, "user")
+		require.ErrorIs(t, err, ErrInvalidTenantTemplateName, name)
+	}
+}
+
+func TestTenantProvisioningCopyTenant(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	s := NewTenantProvisioningService(store, nil, nil, NewMultitenantService(store))
+	ctx := context.Background()
+	createdBy := commontestutils.RandomString(10)
+
+	source, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:      createdBy,
+		TenantID:    commontestutils.RandomString(10),
+		Name:        commontestutils.RandomString(10),
+		Subdomain:   strings.ToLower(commontestutils.RandomString(10)),
+		AllowSignUp: true,
+	})
+	require.NoError(t, err)
+	_, err = store.UpdateTenantFeatures(ctx, repository.UpdateTenantFeaturesParams{ID: source.ID, Features: subentity.TenantFeatures{"chat": true}})
+	require.NoError(t, err)
+	_, err = store.UpdateTenantProfile(ctx, repository.UpdateTenantProfileParams{
+		TenantID: source.TenantID,
+		Profile:  subentity.TenantProfile{DisplayName: source.Name, Values: "standard"},
+	})
+	require.NoError(t, err)
+	source, err = store.GetTenantByID(ctx, source.ID)
+	require.NoError(t, err)
+
+	role, err := store.CreateRole(ctx, repository.CreateRoleParams{TenantID: source.TenantID, Name: "Auditor", CreatedBy: createdBy})
+	require.NoError(t, err)
+	require.NoError(t, store.AddRolePermissions(ctx, repository.AddRolePermissionsParams{
+		RoleID:     role.ID,
+		Operations: []string{"users:manage"},
+	}))
+	_, err = store.UpsertEmailTemplate(ctx, repository.UpsertEmailTemplateParams{
+		TenantID:  source.TenantID,
+		EventType: "invitation",
+		Locale:    "en",
+		Subject:   "Welcome",
+		Body:      "Hello",
+		UpdatedBy: createdBy,
+	})
+	require.NoError(t, err)
+
+	tenant, steps, err := s.copyTenant(ctx, source, repository.CreateTenantParams{
+		UserID:      createdBy,
+		TenantID:    commontestutils.RandomString(10),
+		Name:        commontestutils.RandomString(10),
+		Subdomain:   strings.ToLower(commontestutils.RandomString(10)),
+		AllowSignUp: source.AllowSignUp,
+	})
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, step := range steps {
+		names = append(names, step.Name)
+		require.Empty(t, step.Error, step.Name)
+	}
+	require.Equal(t, []string{TenantCloneStepSettings, TenantCloneStepSettingValues,
+		TenantCloneStepRoles, TenantCloneStepEmailTemplates}, names)
+
+	require.True(t, tenant.Features["chat"])
+	require.Equal(t, "standard", tenant.Profile.Values)
+	// The copy keeps its own name
+	require.Equal(t, tenant.Name, tenant.Profile.DisplayName)
+
+	roles, err := store.ListRoles(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	require.Equal(t, "Auditor", roles[0].Name)
+	require.NotEqual(t, role.ID, roles[0].ID)
+	operations, err := store.ListRolePermissions(ctx, roles[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:manage"}, operations)
+
+	templates, err := store.ListEmailTemplates(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, "Welcome", templates[0].Subject)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Tenant configs overriding the headers of the public tenant endpoints
+const (
+	TenantPublicCacheControlConfig = "public_cache_control"
+	TenantPublicCORSOriginsConfig  = "public_cors_origins"
+	TenantPublicRobotsConfig       = "public_robots"
+)
+
+// DefaultPublicRobots keeps tenant pictures and bootstrap data out of search
+// engines unless the tenant opts in
+const DefaultPublicRobots = "noindex, nofollow"
+
+// publicHeadersCacheTTL bounds how long a config change takes to show on the
+// public endpoints, which are requested on every page load
+const publicHeadersCacheTTL = 30 * time.Second
+
+var (
+	cacheControlDirectives = map[string]bool{
+		"public": false, "private": false, "no-cache": false, "no-store": false, "no-transform": false,
+		"must-revalidate": false, "proxy-revalidate": false, "immutable": false,
+		"max-age": true, "s-maxage": true, "stale-while-revalidate": true, "stale-if-error": true,
+	}
+	robotsDirectives = map[string]bool{
+		"all": true, "index": true, "follow": true, "none": true, "noindex": true, "nofollow": true,
+		"noarchive": true, "nosnippet": true, "noimageindex": true, "notranslate": true,
+	}
+)
+
+// TenantPublicHeaders are the caching, CORS and indexing headers of the public
+// tenant endpoints: pictures, the bootstrap tenant and profile pictures.
+//
+// Environment (platform defaults, overridden per tenant by the public_* tenant configs):
+//   - PUBLIC_CACHE_CONTROL: Cache-Control of the responses (default none: pictures
+//     are cached for an hour, the bootstrap tenant is not cached)
+//   - PUBLIC_CORS_ORIGINS: comma-separated origins allowed to read them
+//     cross-origin, on top of the server CORS policy (default none)
+//   - PUBLIC_ROBOTS: X-Robots-Tag of the responses (default "noindex, nofollow")
+type TenantPublicHeaders struct {
+	CacheControl string
+	CORSOrigins  []string
+	Robots       string
+}
+
+func PlatformPublicHeadersFromEnv() TenantPublicHeaders {
+	headers := TenantPublicHeaders{Robots: DefaultPublicRobots}
+	for name, value := range map[string]string{
+		TenantPublicCacheControlConfig: os.Getenv("PUBLIC_CACHE_CONTROL"),
+		TenantPublicCORSOriginsConfig:  os.Getenv("PUBLIC_CORS_ORIGINS"),
+		TenantPublicRobotsConfig:       os.Getenv("PUBLIC_ROBOTS"),
+	} {
+		if value == "" {
+			continue
+		}
+		if err := headers.set(name, value); err != nil {
+			log.Warn().Str(strings.ToUpper(name), value).Err(err).Msg("Invalid value, using default")
+		}
+	}
+	return headers
+}
+
+// ValidateTenantPublicHeaderConfig checks the value of a public_* tenant
+// config. Other configs are not checked.
+func ValidateTenantPublicHeaderConfig(name string, value *string) error {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return (&TenantPublicHeaders{}).set(name, *value)
+}
+
+func (h *TenantPublicHeaders) set(name, value string) error {
+	value = strings.TrimSpace(value)
+	switch name {
+	case TenantPublicCacheControlConfig:
+		if err := checkDirectives(value, func(directive, argument string, hasArgument bool) bool {
+			needsArgument, known := cacheControlDirectives[directive]
+			if !known || needsArgument != hasArgument {
+				return false
+			}
+			_, err := strconv.ParseUint(argument, 10, 32)
+			return !hasArgument || err == nil
+		}); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		h.CacheControl = value
+	case TenantPublicRobotsConfig:
+		if err := checkDirectives(value, func(directive, _ string, hasArgument bool) bool {
+			return robotsDirectives[directive] && !hasArgument
+		}); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		h.Robots = value
+	case TenantPublicCORSOriginsConfig:
+		origins := []string{}
+		for _, origin := range strings.Split(value, ",") {
+			origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+			parsed, err := url.Parse(origin)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" || parsed.Path != "" {
+				return fmt.Errorf("%s: %q is not an origin such as https://www.example.com", name, origin)
+			}
+			origins = append(origins, origin)
+		}
+		h.CORSOrigins = origins
+	}
+	return nil
+}
+
+// checkDirectives validates a comma-separated list of directive[=argument]
+func checkDirectives(value string, valid func(directive, argument string, hasArgument bool) bool) error {
+	for _, part := range strings.Split(value, ",") {
+		directive, argument, hasArgument := strings.Cut(strings.ToLower(strings.TrimSpace(part)), "=")
+		if !valid(directive, argument, hasArgument) {
+			return fmt.Errorf("invalid directive %q", strings.TrimSpace(part))
+		}
+	}
+	return nil
+}
+
+// publicHeadersCache keeps the headers of each tenant, keyed by tenant id
+var publicHeadersCache = NewPublicCache[TenantPublicHeaders](publicHeadersCacheTTL)
+
+// GetTenantPublicHeaders returns the platform headers overridden by the tenant
+// configs. An invalid config is ignored and a failed lookup falls back to the
+// platform headers, both are logged.
+func GetTenantPublicHeaders(ctx context.Context, store *db.Store, tenantID string) TenantPublicHeaders {
+	if tenantID == "" {
+		return PlatformPublicHeadersFromEnv()
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	headers, err := publicHeadersCache.Get(ctx, tenantID, func(ctx context.Context) (TenantPublicHeaders, error) {
+		headers := PlatformPublicHeadersFromEnv()
+		configs, err := store.ListAllTenantConfigs(ctx, tenantID)
+		if err != nil {
+			return headers, err
+		}
+		for _, config := range configs {
+			if !config.Value.Valid || config.Value.String == "" {
+				continue
+			}
+			if err := headers.set(config.Name, config.Value.String); err != nil {
+				logger.Warn().Err(err).Str("tenantID", tenantID).Msg("Ignoring invalid tenant public header config")
+			}
+		}
+		return headers, nil
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to load tenant public headers, using platform defaults")
+		return PlatformPublicHeadersFromEnv()
+	}
+	return headers
+}
+
+// InvalidateTenantPublicHeaders drops the cached headers of a tenant after a
+// change of its public_* configs
+func InvalidateTenantPublicHeaders(tenantID string) {
+	publicHeadersCache.Invalidate(tenantID)
+}
+
+// Apply sets the headers on the response. Without a Cache-Control, the
+// endpoint keeps its own. Cross-origin reads are only opened to the request
+// origin when it is listed.
+func (h TenantPublicHeaders) Apply(c *gin.Context) {
+	if h.CacheControl != "" {
+		c.Header("Cache-Control", h.CacheControl)
+	}
+	c.Header("X-Robots-Tag", h.Robots)
+	origin := strings.TrimSuffix(c.GetHeader("Origin"), "/")
+	if origin == "" {
+		return
+	}
+	c.Writer.Header().Add("Vary", "Origin")
+	for _, allowed := range h.CORSOrigins {
+		if strings.EqualFold(allowed, origin) {
+			c.Header("Access-Control-Allow-Origin", c.GetHeader("Origin"))
+			return
+		}
+	}
+}
+
+// ApplyTenantPublicHeaders sets the public headers of the request tenant
+func ApplyTenantPublicHeaders(c *gin.Context, store *db.Store) {
+	GetTenantPublicHeaders(c, store, c.GetString(auth.AUTH_TENANT_ID_KEY)).Apply(c)
+}
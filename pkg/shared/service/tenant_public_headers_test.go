@@ -0,0 +1,56 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTenantPublicHeaderConfig(t *testing.T) {
+	valid := map[string]string{
+		TenantPublicCacheControlConfig: "public, max-age=600, stale-while-revalidate=60",
+		TenantPublicRobotsConfig:       "all",
+		TenantPublicCORSOriginsConfig:  "https://www.example.com, http://localhost:3000/",
+		"support_email":                "not checked",
+	}
+	for name, value := range valid {
+		require.NoError(t, ValidateTenantPublicHeaderConfig(name, &value), name)
+	}
+
+	invalid := map[string]string{
+		TenantPublicCacheControlConfig: "max-age=forever",
+		TenantPublicRobotsConfig:       "noindex, sitemap",
+		TenantPublicCORSOriginsConfig:  "https://www.example.com/path",
+	}
+	for name, value := range invalid {
+		require.Error(t, ValidateTenantPublicHeaderConfig(name, &value), name)
+	}
+}
+
+func TestTenantPublicHeadersApply(t *testing.T) {
+	headers := TenantPublicHeaders{Robots: DefaultPublicRobots}
+	require.NoError(t, headers.set(TenantPublicCORSOriginsConfig, "https://www.example.com"))
+
+	apply := func(origin string) http.Header {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodGet, "/public-api/v1/tenant/pictures/logo", nil)
+		if origin != "" {
+			c.Request.Header.Set("Origin", origin)
+		}
+		headers.Apply(c)
+		return recorder.Header()
+	}
+
+	response := apply("https://www.example.com")
+	require.Equal(t, "https://www.example.com", response.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, DefaultPublicRobots, response.Get("X-Robots-Tag"))
+	require.Empty(t, response.Get("Cache-Control"))
+
+	response = apply("https://evil.example.net")
+	require.Empty(t, response.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Origin", response.Get("Vary"))
+}
@@ -0,0 +1,723 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// TenantStatusPendingDeletion is the status of a tenant waiting for its
+// purge. It answers as if it did not exist and cannot be moved to another
+// status but by cancelling the purge.
+const TenantStatusPendingDeletion = "pending_deletion"
+
+// Statuses of a tenant purge
+const (
+	TenantPurgeScheduled = "scheduled"
+	TenantPurgeRunning   = "running"
+	TenantPurgeCompleted = "completed"
+	TenantPurgeFailed    = "failed"
+	TenantPurgeCancelled = "cancelled"
+)
+
+const (
+	DefaultTenantPurgeGracePeriod = 30 * 24 * time.Hour
+	DefaultTenantPurgeInterval    = 15 * time.Minute
+	DefaultTenantPurgeRetryDelay  = time.Hour
+	DefaultTenantPurgeMaxAttempts = 5
+
+	// Due purges run per scan, the next scan takes the rest
+	tenantPurgeBatchSize = 5
+)
+
+// Steps of a purge, the module steps running before them
+const (
+	TenantPurgeStepData         = "data"
+	TenantPurgeStepUsers        = "users"
+	TenantPurgeStepFiles        = "files"
+	TenantPurgeStepAuthProvider = "auth_provider"
+	TenantPurgeStepTenant       = "tenant"
+)
+
+var (
+	// ErrTenantPurgeScheduled is returned when a purge of the tenant is already in progress
+	ErrTenantPurgeScheduled = errors.New("the purge of the tenant is already scheduled")
+	// ErrTenantPurgeNotFound is returned when the tenant has no purge, or no
+	// purge in progress to cancel
+	ErrTenantPurgeNotFound = errors.New("no purge of the tenant")
+	// ErrTenantPurgeStarted is returned when cancelling a purge that started deleting
+	ErrTenantPurgeStarted = errors.New("the purge of the tenant started, it can no longer be cancelled")
+	// ErrTenantPurgeReseller keeps a reseller from being purged while it has tenants
+	ErrTenantPurgeReseller = errors.New("the tenant is the reseller of other tenants, move or purge them first")
+)
+
+// tenantPurgeTable deletes the rows of a tenant from a table
+type tenantPurgeTable struct {
+	table string
+	purge func(q *repository.Queries, ctx context.Context, tenantID string) (int64, error)
+}
+
+// tenantPurgeTables are the tables the data step deletes from: every table
+// with a tenant_id column but the purges themselves, the users, whose accounts
+// may belong to other tenants, and the memberships and the tenant, deleted by
+// their own steps. A new table with a tenant_id column gets its delete here.
+var tenantPurgeTables = []tenantPurgeTable{
+	{table: "core_announcements", purge: (*repository.Queries).PurgeTenantAnnouncements},
+	{table: "core_auth_failure_counts", purge: (*repository.Queries).PurgeTenantAuthFailureCounts},
+	{table: "core_auth_lockouts", purge: (*repository.Queries).PurgeTenantAuthLockouts},
+	{table: "core_client_applications", purge: (*repository.Queries).PurgeTenantClientApplications},
+	{table: "core_directory_connections", purge: (*repository.Queries).PurgeTenantDirectoryConnections},
+	{table: "core_directory_sync_conflicts", purge: (*repository.Queries).PurgeTenantDirectorySyncConflicts},
+	{table: "core_directory_users", purge: (*repository.Queries).PurgeTenantDirectoryUsers},
+	{table: "core_email_templates", purge: (*repository.Queries).PurgeTenantEmailTemplates},
+	{table: "core_email_verification_tokens", purge: (*repository.Queries).PurgeTenantEmailVerificationTokens},
+	{table: "core_execution_webhooks", purge: (*repository.Queries).PurgeTenantExecutionWebhooks},
+	{table: "core_groups", purge: (*repository.Queries).PurgeTenantGroups},
+	{table: "core_jobs", purge: (*repository.Queries).PurgeTenantJobs},
+	{table: "core_llm_consent_policies", purge: (*repository.Queries).PurgeTenantLlmConsentPolicies},
+	{table: "core_permission_delegations", purge: (*repository.Queries).PurgeTenantPermissionDelegations},
+	{table: "core_replay_bundles", purge: (*repository.Queries).PurgeTenantReplayBundles},
+	{table: "core_roles", purge: (*repository.Queries).PurgeTenantRoles},
+	{table: "core_scope_templates", purge: (*repository.Queries).PurgeTenantScopeTemplates},
+	{table: "core_sla_escalations", purge: (*repository.Queries).PurgeTenantSlaEscalations},
+	{table: "core_tenant_configs", purge: (*repository.Queries).PurgeTenantConfigs},
+	{table: "core_tenant_email_providers", purge: (*repository.Queries).PurgeTenantEmailProviders},
+	{table: "core_tenant_export_schedules", purge: (*repository.Queries).PurgeTenantExportSchedules},
+	{table: "core_tenant_exports", purge: (*repository.Queries).PurgeTenantExports},
+	{table: "core_tenant_feature_flags", purge: (*repository.Queries).PurgeTenantFeatureFlags},
+	{table: "core_tenant_file_encryption", purge: (*repository.Queries).PurgeTenantFileEncryption},
+	{table: "core_tenant_file_keys", purge: (*repository.Queries).PurgeTenantFileKeys},
+	{table: "core_tenant_files", purge: (*repository.Queries).PurgeTenantFiles},
+	{table: "core_tenant_prompt_executions", purge: (*repository.Queries).PurgeTenantPromptExecutions},
+	{table: "core_tenant_quotas", purge: (*repository.Queries).PurgeTenantQuotas},
+	{table: "core_tenant_sandboxes", purge: (*repository.Queries).PurgeTenantSandboxes},
+	{table: "core_tenant_setting_values", purge: (*repository.Queries).PurgeTenantSettingValues},
+	{table: "core_tenant_subdomain_aliases", purge: (*repository.Queries).PurgeTenantSubdomainAliases},
+	{table: "core_tenant_templates", purge: (*repository.Queries).PurgeTenantTemplates},
+	{table: "core_tenant_usage_counts", purge: (*repository.Queries).PurgeTenantUsageCounts},
+	{table: "core_tenant_usage_notifications", purge: (*repository.Queries).PurgeTenantUsageNotifications},
+	{table: "core_token_policies", purge: (*repository.Queries).PurgeTenantTokenPolicies},
+	{table: "core_translations", purge: (*repository.Queries).PurgeTenantTranslations},
+	{table: "core_user_activity_events", purge: (*repository.Queries).PurgeTenantUserActivityEvents},
+	{table: "core_user_attributes", purge: (*repository.Queries).PurgeTenantUserAttributes},
+	{table: "core_user_audit_logs", purge: (*repository.Queries).PurgeTenantUserAuditLogs},
+	{table: "core_user_deletion_requests", purge: (*repository.Queries).PurgeTenantUserDeletionRequests},
+	{table: "core_user_email_changes", purge: (*repository.Queries).PurgeTenantUserEmailChanges},
+	{table: "core_user_impersonations", purge: (*repository.Queries).PurgeTenantUserImpersonations},
+	{table: "core_user_invitations", purge: (*repository.Queries).PurgeTenantUserInvitations},
+	{table: "core_user_logins", purge: (*repository.Queries).PurgeTenantUserLogins},
+}
+
+// TenantPurgeConfig configures the tenant purges.
+//
+// Environment:
+//   - TENANT_PURGE_GRACE_PERIOD: delay between the request and the purge,
+//     during which it can be cancelled (default 720h)
+//   - TENANT_PURGE_INTERVAL: scan interval of the job running the due purges
+//     (default 15m, 0 disables the job)
+//   - TENANT_PURGE_RETRY_DELAY: delay before a failed or interrupted purge is
+//     resumed (default 1h)
+//   - TENANT_PURGE_MAX_ATTEMPTS: runs of a purge before it is left failed
+//     for an operator to resume (default 5)
+type TenantPurgeConfig struct {
+	GracePeriod time.Duration
+	Interval    time.Duration
+	RetryDelay  time.Duration
+	MaxAttempts int
+}
+
+func TenantPurgeConfigFromEnv() TenantPurgeConfig {
+	cfg := TenantPurgeConfig{
+		GracePeriod: DefaultTenantPurgeGracePeriod,
+		Interval:    DefaultTenantPurgeInterval,
+		RetryDelay:  DefaultTenantPurgeRetryDelay,
+		MaxAttempts: DefaultTenantPurgeMaxAttempts,
+	}
+	if v := os.Getenv("TENANT_PURGE_GRACE_PERIOD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.GracePeriod = d
+		} else {
+			log.Warn().Str("TENANT_PURGE_GRACE_PERIOD", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("TENANT_PURGE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("TENANT_PURGE_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("TENANT_PURGE_RETRY_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.RetryDelay = d
+		} else {
+			log.Warn().Str("TENANT_PURGE_RETRY_DELAY", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("TENANT_PURGE_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxAttempts = n
+		} else {
+			log.Warn().Str("TENANT_PURGE_MAX_ATTEMPTS", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// TenantPurgeFunc deletes the data a module keeps for a tenant outside of the
+// tables with a tenant_id column, such as an external store, and returns how
+// many items it removed. On a dry run it only counts them. It may run again
+// for a tenant when a purge resumes.
+type TenantPurgeFunc func(ctx context.Context, tenantID string, dryRun bool) (int64, error)
+
+type tenantPurgeStep struct {
+	name  string
+	purge TenantPurgeFunc
+}
+
+var (
+	tenantPurgeStepsMu sync.RWMutex
+	tenantPurgeSteps   []tenantPurgeStep
+)
+
+// RegisterTenantPurgeStep lets a module delete its data when a tenant is
+// purged. Steps run in registration order, before the core steps, and are
+// reported as module:<name>.
+func RegisterTenantPurgeStep(name string, purge TenantPurgeFunc) {
+	tenantPurgeStepsMu.Lock()
+	defer tenantPurgeStepsMu.Unlock()
+	tenantPurgeSteps = append(tenantPurgeSteps, tenantPurgeStep{name: "module:" + name, purge: purge})
+}
+
+func getTenantPurgeSteps() []tenantPurgeStep {
+	tenantPurgeStepsMu.RLock()
+	defer tenantPurgeStepsMu.RUnlock()
+	return append([]tenantPurgeStep{}, tenantPurgeSteps...)
+}
+
+// TenantPurgeStepReport is the outcome of a step of a purge. Details break
+// the deletions down, by table for the data step.
+type TenantPurgeStepReport struct {
+	Name    string           `json:"name"`
+	Deleted int64            `json:"deleted"`
+	Details map[string]int64 `json:"details,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// TenantPurgeReport is what a purge deleted, or would delete on a dry run
+type TenantPurgeReport struct {
+	TenantID string
+	DryRun   bool
+	Steps    []TenantPurgeStepReport
+}
+
+// TenantPurge is a purge with the report of its steps so far
+type TenantPurge struct {
+	repository.CoreTenantPurge
+	Report []TenantPurgeStepReport
+}
+
+func toTenantPurge(purge repository.CoreTenantPurge) (TenantPurge, error) {
+	result := TenantPurge{CoreTenantPurge: purge, Report: []TenantPurgeStepReport{}}
+	if len(purge.Steps) > 0 {
+		if err := json.Unmarshal(purge.Steps, &result.Report); err != nil {
+			return TenantPurge{}, fmt.Errorf("decode purge steps: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// tenantPurgeTarget is the tenant a purge deletes, kept in the purge since
+// the tenant row goes at the end
+type tenantPurgeTarget struct {
+	id       uuid.UUID
+	tenantID string
+}
+
+// TenantPurgeService deletes tenants: the tenant is marked pending_deletion,
+// then once the grace period is over a background job deletes its module
+// data, its rows, its users left without tenant, its files, its tenant in the
+// auth provider and the tenant itself. Each step is recorded as it ends, so
+// a purge that fails or is interrupted resumes after its last completed step.
+type TenantPurgeService struct {
+	store              *db.Store
+	files              *fileservice.FileService
+	authProvider       auth.AuthProvider
+	multiTenantService *MultitenantService
+	cfg                TenantPurgeConfig
+}
+
+func NewTenantPurgeService(store *db.Store, files *fileservice.FileService, authProvider auth.AuthProvider,
+	multiTenantService *MultitenantService, cfg TenantPurgeConfig) *TenantPurgeService {
+	return &TenantPurgeService{
+		store:              store,
+		files:              files,
+		authProvider:       authProvider,
+		multiTenantService: multiTenantService,
+		cfg:                cfg,
+	}
+}
+
+// SchedulePurge marks the tenant pending_deletion and schedules its purge
+// after the grace period. A failed purge of a tenant already pending deletion
+// is resumed at once.
+func (s *TenantPurgeService) SchedulePurge(ctx context.Context, tenant repository.CoreTenant, reason *string, requestedBy string) (TenantPurge, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if tenant.Status == TenantStatusPendingDeletion {
+		purge, err := s.store.ResumeTenantPurge(ctx, tenant.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TenantPurge{}, ErrTenantPurgeScheduled
+		}
+		if err != nil {
+			return TenantPurge{}, fmt.Errorf("service.SchedulePurge: %w", err)
+		}
+		logger.Info().Str("tenant_id", tenant.TenantID).Str("requested_by", requestedBy).Msg("Tenant purge resumed")
+		return toTenantPurge(purge)
+	}
+
+	subTenants, err := s.store.CountResellerSubTenants(ctx, pgtype.Text{String: tenant.TenantID, Valid: true})
+	if err != nil {
+		return TenantPurge{}, fmt.Errorf("service.SchedulePurge: %w", err)
+	}
+	if subTenants > 0 {
+		return TenantPurge{}, ErrTenantPurgeReseller
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return TenantPurge{}, fmt.Errorf("service.SchedulePurge: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	if _, err := qtx.UpdateTenantStatus(ctx, repository.UpdateTenantStatusParams{
+		Status:       TenantStatusPendingDeletion,
+		StatusReason: util.ToNullableText(reason),
+		TenantID:     tenant.TenantID,
+		FromStatuses: []string{TenantStatusActive, TenantStatusArchived, TenantStatusSuspended},
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TenantPurge{}, fmt.Errorf("%w: the status of the tenant changed", ErrTenantStatusTransition)
+		}
+		return TenantPurge{}, fmt.Errorf("service.SchedulePurge: %w", err)
+	}
+	purge, err := qtx.ScheduleTenantPurge(ctx, repository.ScheduleTenantPurgeParams{
+		TenantUuid:     tenant.ID,
+		TenantID:       tenant.TenantID,
+		TenantName:     tenant.Name,
+		PreviousStatus: tenant.Status,
+		Reason:         util.ToNullableText(reason),
+		RequestedBy:    requestedBy,
+		PurgeAfter:     time.Now().Add(s.cfg.GracePeriod),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TenantPurge{}, ErrTenantPurgeScheduled
+	}
+	if err != nil {
+		return TenantPurge{}, fmt.Errorf("service.SchedulePurge: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return TenantPurge{}, fmt.Errorf("service.SchedulePurge: %w", err)
+	}
+	s.multiTenantService.InvalidateTenant(tenant.TenantID)
+	logger.Info().Str("tenant_id", tenant.TenantID).Str("requested_by", requestedBy).
+		Time("purge_after", purge.PurgeAfter).Msg("Tenant purge scheduled")
+	return toTenantPurge(purge)
+}
+
+// GetPurge returns the purge of the tenant, kept once the tenant is deleted
+func (s *TenantPurgeService) GetPurge(ctx context.Context, tenantUUID uuid.UUID) (TenantPurge, error) {
+	purge, err := s.store.GetTenantPurge(ctx, tenantUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TenantPurge{}, ErrTenantPurgeNotFound
+	}
+	if err != nil {
+		return TenantPurge{}, fmt.Errorf("service.GetPurge: %w", err)
+	}
+	return toTenantPurge(purge)
+}
+
+// CancelPurge cancels a purge during its grace period and restores the
+// status the tenant had before
+func (s *TenantPurgeService) CancelPurge(ctx context.Context, tenantUUID uuid.UUID, cancelledBy string) (TenantPurge, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return TenantPurge{}, fmt.Errorf("service.CancelPurge: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	purge, err := qtx.CancelTenantPurge(ctx, tenantUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		existing, err := s.GetPurge(ctx, tenantUUID)
+		if err != nil {
+			return TenantPurge{}, err
+		}
+		if existing.Status == TenantPurgeCancelled || existing.Status == TenantPurgeCompleted {
+			return TenantPurge{}, ErrTenantPurgeNotFound
+		}
+		return TenantPurge{}, ErrTenantPurgeStarted
+	}
+	if err != nil {
+		return TenantPurge{}, fmt.Errorf("service.CancelPurge: %w", err)
+	}
+	if _, err := qtx.UpdateTenantStatus(ctx, repository.UpdateTenantStatusParams{
+		Status:       purge.PreviousStatus,
+		TenantID:     purge.TenantID,
+		FromStatuses: []string{TenantStatusPendingDeletion},
+	}); err != nil {
+		return TenantPurge{}, fmt.Errorf("service.CancelPurge: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return TenantPurge{}, fmt.Errorf("service.CancelPurge: %w", err)
+	}
+	s.multiTenantService.InvalidateTenant(purge.TenantID)
+	logger.Info().Str("tenant_id", purge.TenantID).Str("cancelled_by", cancelledBy).
+		Str("status", purge.PreviousStatus).Msg("Tenant purge cancelled")
+	return toTenantPurge(purge)
+}
+
+// DryRun returns what a purge of the tenant would delete, deleting nothing
+func (s *TenantPurgeService) DryRun(ctx context.Context, tenant repository.CoreTenant) TenantPurgeReport {
+	report := TenantPurgeReport{TenantID: tenant.TenantID, DryRun: true, Steps: []TenantPurgeStepReport{}}
+	target := tenantPurgeTarget{id: tenant.ID, tenantID: tenant.TenantID}
+	for _, step := range s.steps() {
+		report.Steps = append(report.Steps, s.runStep(ctx, step, target, true))
+	}
+	return report
+}
+
+// StartTenantPurges runs RunDuePurges now and then every interval until ctx
+// is done. It does nothing when the interval is 0.
+func (s *TenantPurgeService) StartTenantPurges(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Info().Msg("Tenant purges disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.RunDuePurges(ctx); err != nil {
+				log.Err(err).Msg("Tenant purge scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunDuePurges runs the purges whose grace period is over, and resumes the
+// interrupted or failed ones, returning how many completed. A failed purge
+// keeps its error and the report of its steps.
+func (s *TenantPurgeService) RunDuePurges(ctx context.Context) (int, error) {
+	purges, err := s.store.ClaimDueTenantPurges(ctx, repository.ClaimDueTenantPurgesParams{
+		MaxAttempts: int32(s.cfg.MaxAttempts),
+		RetryBefore: time.Now().Add(-s.cfg.RetryDelay),
+		MaxPurges:   tenantPurgeBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("service.RunDuePurges: %w", err)
+	}
+	completed := 0
+	for _, purge := range purges {
+		if err := s.runPurge(ctx, purge); err != nil {
+			log.Err(err).Str("tenant_id", purge.TenantID).Int32("attempt", purge.Attempts).Msg("Tenant purge failed")
+			if err := s.store.FailTenantPurge(ctx, repository.FailTenantPurgeParams{
+				LastError:  err.Error(),
+				TenantUuid: purge.TenantUuid,
+			}); err != nil {
+				log.Err(err).Str("tenant_id", purge.TenantID).Msg("Failed to record tenant purge failure")
+			}
+			continue
+		}
+		if err := s.store.CompleteTenantPurge(ctx, purge.TenantUuid); err != nil {
+			return completed, fmt.Errorf("service.RunDuePurges: %w", err)
+		}
+		log.Info().Str("tenant_id", purge.TenantID).Msg("Tenant purged")
+		completed++
+	}
+	return completed, nil
+}
+
+// runPurge runs the steps the purge did not complete yet, saving the report
+// after each one
+func (s *TenantPurgeService) runPurge(ctx context.Context, claimed repository.CoreTenantPurge) error {
+	purge, err := toTenantPurge(claimed)
+	if err != nil {
+		return err
+	}
+	target := tenantPurgeTarget{id: purge.TenantUuid, tenantID: purge.TenantID}
+	report := purge.Report
+	for _, step := range s.steps() {
+		if tenantPurgeStepDone(report, step.name) {
+			continue
+		}
+		result := s.runStep(ctx, step, target, false)
+		report = setTenantPurgeStepReport(report, result)
+		steps, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		if err := s.store.SaveTenantPurgeSteps(ctx, repository.SaveTenantPurgeStepsParams{
+			TenantUuid: purge.TenantUuid,
+			Steps:      steps,
+		}); err != nil {
+			return err
+		}
+		if result.Error != "" {
+			return fmt.Errorf("step %s: %s", step.name, result.Error)
+		}
+	}
+	s.multiTenantService.InvalidateTenant(purge.TenantID)
+	return nil
+}
+
+// tenantPurgeStepDone reports whether the step completed in a previous run
+func tenantPurgeStepDone(report []TenantPurgeStepReport, name string) bool {
+	for _, step := range report {
+		if step.Name == name {
+			return step.Error == ""
+		}
+	}
+	return false
+}
+
+// setTenantPurgeStepReport replaces the report of a step run again, keeping
+// the order of the first run
+func setTenantPurgeStepReport(report []TenantPurgeStepReport, result TenantPurgeStepReport) []TenantPurgeStepReport {
+	for i, step := range report {
+		if step.Name == result.Name {
+			report = slices.Clone(report)
+			report[i] = result
+			return report
+		}
+	}
+	return append(report, result)
+}
+
+func (s *TenantPurgeService) runStep(ctx context.Context, step tenantPurgeStep, target tenantPurgeTarget, dryRun bool) TenantPurgeStepReport {
+	result := TenantPurgeStepReport{Name: step.name}
+	var err error
+	switch step.name {
+	case TenantPurgeStepData:
+		result.Details, err = s.purgeData(ctx, target.tenantID, dryRun)
+	case TenantPurgeStepUsers:
+		result.Details, err = s.purgeUsers(ctx, target.tenantID, dryRun)
+	case TenantPurgeStepFiles:
+		result.Details, err = s.purgeFiles(ctx, target.tenantID, dryRun)
+	case TenantPurgeStepAuthProvider:
+		result.Deleted, err = s.purgeAuthProviderTenant(ctx, target.tenantID, dryRun)
+	case TenantPurgeStepTenant:
+		result.Deleted, err = s.purgeTenant(ctx, target, dryRun)
+	default:
+		result.Deleted, err = step.purge(ctx, target.tenantID, dryRun)
+	}
+	for _, count := range result.Details {
+		result.Deleted += count
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// steps are the module steps followed by the core ones. The rows go before
+// the users so no row of the tenant still references them, and the tenant
+// goes last since the other steps look it up.
+func (s *TenantPurgeService) steps() []tenantPurgeStep {
+	steps := getTenantPurgeSteps()
+	for _, name := range []string{TenantPurgeStepData, TenantPurgeStepUsers, TenantPurgeStepFiles, TenantPurgeStepAuthProvider, TenantPurgeStepTenant} {
+		steps = append(steps, tenantPurgeStep{name: name})
+	}
+	return steps
+}
+
+// purgeData deletes the rows of the tenant in the tenantPurgeTables in one
+// transaction, and returns the rows deleted by table. A dry run rolls it back,
+// so it reports exactly what the purge would delete.
+func (s *TenantPurgeService) purgeData(ctx context.Context, tenantID string, dryRun bool) (map[string]int64, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := repository.New(tx)
+
+	deleted := map[string]int64{}
+	for _, table := range tenantPurgeTables {
+		rows, err := table.purge(qtx, ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("delete %s: %w", table.table, err)
+		}
+		if rows > 0 {
+			deleted[table.table] = rows
+		}
+	}
+	if dryRun {
+		return deleted, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// purgeUsers removes the memberships of the tenant and deletes the accounts
+// of the members left without tenant nor global role, in the database and
+// in the auth provider. The users of other tenants only lose the membership.
+func (s *TenantPurgeService) purgeUsers(ctx context.Context, tenantID string, dryRun bool) (map[string]int64, error) {
+	members, err := s.store.ListTenantPurgeMembers(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{"memberships": 0, "users": 0}
+	if dryRun {
+		for _, member := range members {
+			counts["memberships"]++
+			if member.Exclusive {
+				counts["users"]++
+			}
+		}
+		return counts, nil
+	}
+	if len(members) == 0 {
+		return counts, nil
+	}
+	authClient, err := s.authProvider.GetAuthClientForTenant(ctx, tenantID)
+	if err != nil {
+		return counts, err
+	}
+	for _, member := range members {
+		deleted, err := s.removeMember(ctx, authClient, tenantID, member.UserID)
+		if err != nil {
+			return counts, fmt.Errorf("user %s: %w", member.UserID, err)
+		}
+		counts["memberships"]++
+		if deleted {
+			counts["users"]++
+		}
+	}
+	return counts, nil
+}
+
+func (s *TenantPurgeService) removeMember(ctx context.Context, authClient auth.AuthClient, tenantID, memberID string) (bool, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	if err := qtx.RemoveSharedUserFromTenant(ctx, repository.RemoveSharedUserFromTenantParams{
+		UserID:   memberID,
+		TenantID: tenantID,
+	}); err != nil {
+		return false, err
+	}
+	deleted, err := qtx.DeleteUserWithoutMemberships(ctx, memberID)
+	if err != nil {
+		return false, err
+	}
+	if deleted > 0 {
+		if err := authClient.DeleteUser(ctx, memberID); err != nil && !auth.IsUserNotFound(err) {
+			return false, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	if deleted > 0 && s.files != nil {
+		for _, path := range fileservice.ProfilePictureFilePaths(memberID) {
+			if err := s.files.DeleteFile(ctx, path); err != nil {
+				logger := util.GetLoggerFromCtx(ctx)
+				logger.Debug().Err(err).Str("user_id", memberID).Str("path", path).Msg("No profile picture to delete")
+			}
+		}
+	}
+	return deleted > 0, nil
+}
+
+// tenantPurgePrefixes are the storage prefixes of the tenant: its files and
+// the archives of its full exports
+func tenantPurgePrefixes(tenantID string) []string {
+	return []string{fileservice.TenantFilePrefix(tenantID), TenantDataExportPrefix(tenantID)}
+}
+
+// purgeFiles deletes the files under the prefixes of the tenant and returns
+// how many were deleted by prefix
+func (s *TenantPurgeService) purgeFiles(ctx context.Context, tenantID string, dryRun bool) (map[string]int64, error) {
+	counts := map[string]int64{}
+	if s.files == nil {
+		return counts, nil
+	}
+	for _, prefix := range tenantPurgePrefixes(tenantID) {
+		paths, err := s.files.ListFiles(ctx, prefix)
+		if err != nil {
+			return counts, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		if dryRun {
+			counts[prefix] = int64(len(paths))
+			continue
+		}
+		for _, path := range paths {
+			if err := s.files.DeleteFile(ctx, path); err != nil {
+				return counts, fmt.Errorf("delete %s: %w", path, err)
+			}
+			counts[prefix]++
+		}
+	}
+	return counts, nil
+}
+
+// purgeAuthProviderTenant deletes the tenant of the auth provider
+func (s *TenantPurgeService) purgeAuthProviderTenant(ctx context.Context, tenantID string, dryRun bool) (int64, error) {
+	if dryRun {
+		return 1, nil
+	}
+	if err := s.authProvider.GetTenantManager().DeleteTenant(ctx, tenantID); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// purgeTenant deletes the tenant row, the rows still referencing it going
+// with it by cascade
+func (s *TenantPurgeService) purgeTenant(ctx context.Context, target tenantPurgeTarget, dryRun bool) (int64, error) {
+	if dryRun {
+		return 1, nil
+	}
+	_, err := s.store.DeleteTenant(ctx, target.id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deleted by a previous run that failed to record it
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
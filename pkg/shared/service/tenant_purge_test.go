@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantPurgeConfigFromEnv(t *testing.T) {
+	cfg := TenantPurgeConfigFromEnv()
+	require.Equal(t, DefaultTenantPurgeGracePeriod, cfg.GracePeriod)
+	require.Equal(t, DefaultTenantPurgeInterval, cfg.Interval)
+	require.Equal(t, DefaultTenantPurgeRetryDelay, cfg.RetryDelay)
+	require.Equal(t, DefaultTenantPurgeMaxAttempts, cfg.MaxAttempts)
+
+	t.Setenv("TENANT_PURGE_GRACE_PERIOD", "0")
+	t.Setenv("TENANT_PURGE_INTERVAL", "0")
+	t.Setenv("TENANT_PURGE_RETRY_DELAY", "0")
+	t.Setenv("TENANT_PURGE_MAX_ATTEMPTS", "3")
+	cfg = TenantPurgeConfigFromEnv()
+	require.Zero(t, cfg.GracePeriod)
+	require.Zero(t, cfg.Interval)
+	// A purge would be retried in a loop with 0, the default is kept
+	require.Equal(t, DefaultTenantPurgeRetryDelay, cfg.RetryDelay)
+	require.Equal(t, 3, cfg.MaxAttempts)
+}
+
+func TestTenantPurgeSteps(t *testing.T) {
+	tenantPurgeStepsMu.Lock()
+	saved := tenantPurgeSteps
+	tenantPurgeSteps = nil
+	tenantPurgeStepsMu.Unlock()
+	t.Cleanup(func() {
+		tenantPurgeStepsMu.Lock()
+		tenantPurgeSteps = saved
+		tenantPurgeStepsMu.Unlock()
+	})
+
+	RegisterTenantPurgeStep("prompts", func(ctx context.Context, tenantID string, dryRun bool) (int64, error) {
+		return 0, nil
+	})
+	names := []string{}
+	for _, step := range (&TenantPurgeService{}).steps() {
+		names = append(names, step.name)
+	}
+	require.Equal(t, []string{"module:prompts", TenantPurgeStepData, TenantPurgeStepUsers,
+		TenantPurgeStepFiles, TenantPurgeStepAuthProvider, TenantPurgeStepTenant}, names)
+}
+
+func TestTenantPurgeResume(t *testing.T) {
+	report := []TenantPurgeStepReport{
+		{Name: "module:prompts", Deleted: 4},
+		{Name: TenantPurgeStepData, Deleted: 10, Details: map[string]int64{"core_tenant_configs": 10}},
+		{Name: TenantPurgeStepUsers, Error: "auth provider unavailable"},
+	}
+	require.True(t, tenantPurgeStepDone(report, "module:prompts"))
+	require.True(t, tenantPurgeStepDone(report, TenantPurgeStepData))
+	// A failed step runs again, a step never run too
+	require.False(t, tenantPurgeStepDone(report, TenantPurgeStepUsers))
+	require.False(t, tenantPurgeStepDone(report, TenantPurgeStepFiles))
+
+	updated := setTenantPurgeStepReport(report, TenantPurgeStepReport{Name: TenantPurgeStepUsers, Deleted: 2})
+	require.Len(t, updated, 3)
+	require.Equal(t, int64(2), updated[2].Deleted)
+	require.Empty(t, updated[2].Error)
+	require.Equal(t, "auth provider unavailable", report[2].Error)
+
+	updated = setTenantPurgeStepReport(updated, TenantPurgeStepReport{Name: TenantPurgeStepFiles})
+	require.Equal(t, TenantPurgeStepFiles, updated[3].Name)
+}
+
+func TestTenantPurgePrefixes(t *testing.T) {
+	prefixes := tenantPurgePrefixes("tenant-a")
+	require.Equal(t, []string{"/tenants/tenant-a/", "/tenant-data-exports/tenant-a/"}, prefixes)
+	// The prefixes end with a slash so a tenant never matches the files of
+	// another whose ID it starts
+	for _, prefix := range prefixes {
+		require.True(t, strings.HasSuffix(prefix, "/"))
+	}
+}
+
+func TestTenantPurgeScheduleAndCancel(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	multiTenantService := NewMultitenantService(store)
+	s := NewTenantPurgeService(store, nil, nil, multiTenantService, TenantPurgeConfig{GracePeriod: time.Hour})
+	ctx := context.Background()
+	createdBy := commontestutils.RandomString(10)
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    createdBy,
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	tenant, err = multiTenantService.SetTenantStatus(ctx, tenant, TenantStatusSuspended, nil, createdBy)
+	require.NoError(t, err)
+
+	t.Run("a dry run deletes nothing", func(t *testing.T) {
+		report := s.DryRun(ctx, tenant)
+		require.True(t, report.DryRun)
+		require.Len(t, report.Steps, len(s.steps()))
+		for _, step := range report.Steps {
+			require.Empty(t, step.Error, step.Name)
+		}
+		_, err := store.GetTenantByID(ctx, tenant.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("the tenant waits for its purge", func(t *testing.T) {
+		reason := "contract terminated"
+		purge, err := s.SchedulePurge(ctx, tenant, &reason, createdBy)
+		require.NoError(t, err)
+		require.Equal(t, TenantPurgeScheduled, purge.Status)
+		require.Equal(t, TenantStatusSuspended, purge.PreviousStatus)
+		require.WithinDuration(t, time.Now().Add(time.Hour), purge.PurgeAfter, time.Minute)
+		require.Empty(t, purge.Report)
+
+		pending, err := store.GetTenantByID(ctx, tenant.ID)
+		require.NoError(t, err)
+		require.Equal(t, TenantStatusPendingDeletion, pending.Status)
+		_, err = s.SchedulePurge(ctx, pending, nil, createdBy)
+		require.ErrorIs(t, err, ErrTenantPurgeScheduled)
+
+		// The grace period is not over
+		claimed, err := store.ClaimDueTenantPurges(ctx, repository.ClaimDueTenantPurgesParams{
+			MaxAttempts: DefaultTenantPurgeMaxAttempts,
+			RetryBefore: time.Now().Add(-time.Hour),
+			MaxPurges:   100,
+		})
+		require.NoError(t, err)
+		for _, purge := range claimed {
+			require.NotEqual(t, tenant.ID, purge.TenantUuid)
+		}
+	})
+
+	t.Run("a cancelled purge restores the status", func(t *testing.T) {
+		purge, err := s.CancelPurge(ctx, tenant.ID, createdBy)
+		require.NoError(t, err)
+		require.Equal(t, TenantPurgeCancelled, purge.Status)
+
+		restored, err := store.GetTenantByID(ctx, tenant.ID)
+		require.NoError(t, err)
+		require.Equal(t, TenantStatusSuspended, restored.Status)
+
+		_, err = s.CancelPurge(ctx, tenant.ID, createdBy)
+		require.ErrorIs(t, err, ErrTenantPurgeNotFound)
+	})
+}
+
+func TestTenantPurgeTablesCoverSchema(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	columns, err := store.ListTableColumns(context.Background())
+	require.NoError(t, err)
+
+	purged := []string{}
+	for _, table := range tenantPurgeTables {
+		purged = append(purged, table.table)
+	}
+	kept := []string{"core_tenant_purges", "core_tenants", "core_users", "core_user_tenant_memberships"}
+	for _, column := range columns {
+		if column.ColumnName == "tenant_id" && !slices.Contains(kept, column.TableName) {
+			require.Contains(t, purged, column.TableName, "the data step has no delete for the table")
+		}
+	}
+}
+
+func TestTenantPurgeData(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	s := NewTenantPurgeService(store, nil, nil, NewMultitenantService(store), TenantPurgeConfig{})
+	ctx := context.Background()
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = store.CreateTenantConfig(ctx, repository.CreateTenantConfigParams{
+			UserID:   tenant.UserID,
+			Name:     commontestutils.RandomString(10),
+			TenantID: tenant.TenantID,
+		})
+		require.NoError(t, err)
+	}
+	_, err = store.ClaimTenantUsageNotification(ctx, repository.ClaimTenantUsageNotificationParams{
+		TenantID:       tenant.TenantID,
+		Resource:       QuotaResourceUsers,
+		Severity:       UsageRecommendationLimitReached,
+		NotifiedBefore: time.Now(),
+	})
+	require.NoError(t, err)
+	expected := map[string]int64{"core_tenant_configs": 2, "core_tenant_usage_notifications": 1}
+
+	// A dry run reports the rows and leaves them
+	deleted, err := s.purgeData(ctx, tenant.TenantID, true)
+	require.NoError(t, err)
+	require.Equal(t, expected, deleted)
+	configs, err := store.ListAllTenantConfigs(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	deleted, err = s.purgeData(ctx, tenant.TenantID, false)
+	require.NoError(t, err)
+	require.Equal(t, expected, deleted)
+	configs, err = store.ListAllTenantConfigs(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.Empty(t, configs)
+
+	deleted, err = s.purgeData(ctx, tenant.TenantID, false)
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+}
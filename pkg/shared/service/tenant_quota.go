@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+// Resources limited by the quota of a tenant
+const (
+	QuotaResourceUsers              = "users"
+	QuotaResourceClientApplications = "client_applications"
+	QuotaResourceStorageMB          = "storage_mb"
+	QuotaResourcePromptExecutions   = "prompt_executions_per_month"
+)
+
+const bytesPerMB = 1024 * 1024
+
+var (
+	// ErrTenantQuotaExceeded is wrapped by the errors of an operation that
+	// would take the tenant over its quota of a resource
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+	// ErrInvalidTenantQuota is returned for a negative limit
+	ErrInvalidTenantQuota = errors.New("quota limits must not be negative")
+)
+
+// TenantQuotaLimits are the limits of a tenant. A nil field does not limit
+// the resource.
+type TenantQuotaLimits struct {
+	MaxUsers                    *int32
+	MaxClientApplications       *int32
+	MaxStorageMB                *int32
+	MaxPromptExecutionsPerMonth *int32
+}
+
+// TenantQuotaResource is the usage of a resource against its limit. Storage
+// is counted in MB, rounded up.
+type TenantQuotaResource struct {
+	Resource string
+	Used     int64
+	Limit    *int32
+}
+
+// Exceeded reports whether the resource is at or over its limit, so no more
+// of it can be used
+func (r TenantQuotaResource) Exceeded() bool {
+	return r.Limit != nil && r.Used >= int64(*r.Limit)
+}
+
+// TenantQuotaService limits the resources of the tenants. The super admins set
+// the limits; users, client applications and storage are checked by the core,
+// the prompt executions by the modules running them through
+// ReservePromptExecution.
+type TenantQuotaService struct {
+	store *db.Store
+	files *fileservice.FileService
+}
+
+func NewTenantQuotaService(store *db.Store, files *fileservice.FileService) *TenantQuotaService {
+	return &TenantQuotaService{store: store, files: files}
+}
+
+// InitTenantStorageQuota accounts the files of the tenants in the FileService
+// layer, refusing the writes over the storage quota
+func InitTenantStorageQuota(store *db.Store) {
+	fileservice.SetTenantStorageQuota(NewTenantQuotaService(store, nil))
+}
+
+func toTenantQuotaLimits(quota repository.CoreTenantQuota) TenantQuotaLimits {
+	return TenantQuotaLimits{
+		MaxUsers:                    util.FromNullableInt4(quota.MaxUsers),
+		MaxClientApplications:       util.FromNullableInt4(quota.MaxClientApplications),
+		MaxStorageMB:                util.FromNullableInt4(quota.MaxStorageMb),
+		MaxPromptExecutionsPerMonth: util.FromNullableInt4(quota.MaxPromptExecutionsPerMonth),
+	}
+}
+
+// GetLimits returns the limits of the tenant, none when it has no quota
+func (s *TenantQuotaService) GetLimits(ctx context.Context, tenantID string) (TenantQuotaLimits, error) {
+	quota, err := s.store.GetTenantQuota(ctx, tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TenantQuotaLimits{}, nil
+	}
+	if err != nil {
+		return TenantQuotaLimits{}, fmt.Errorf("service.GetLimits: %w", err)
+	}
+	return toTenantQuotaLimits(quota), nil
+}
+
+// SetLimits replaces the limits of the tenant. They apply to what is added
+// afterwards: a tenant already over a lowered limit keeps its resources. The
+// storage of the tenant is recounted from the bucket when it gets a storage
+// limit, to account the files stored before.
+func (s *TenantQuotaService) SetLimits(ctx context.Context, tenantID string, limits TenantQuotaLimits, updatedBy string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	for _, limit := range []*int32{limits.MaxUsers, limits.MaxClientApplications, limits.MaxStorageMB, limits.MaxPromptExecutionsPerMonth} {
+		if limit != nil && *limit < 0 {
+			return ErrInvalidTenantQuota
+		}
+	}
+	previous, err := s.GetLimits(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.store.UpsertTenantQuota(ctx, repository.UpsertTenantQuotaParams{
+		TenantID:                    tenantID,
+		MaxUsers:                    util.ToNullableInt4(limits.MaxUsers),
+		MaxClientApplications:       util.ToNullableInt4(limits.MaxClientApplications),
+		MaxStorageMb:                util.ToNullableInt4(limits.MaxStorageMB),
+		MaxPromptExecutionsPerMonth: util.ToNullableInt4(limits.MaxPromptExecutionsPerMonth),
+		UpdatedBy:                   updatedBy,
+	}); err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to set tenant quota")
+		return fmt.Errorf("service.SetLimits: %w", err)
+	}
+	logger.Info().Str("tenantID", tenantID).Interface("limits", limits).Str("updatedBy", updatedBy).Msg("Tenant quota set")
+
+	if limits.MaxStorageMB != nil && previous.MaxStorageMB == nil && s.files != nil {
+		if err := s.RecountStorage(ctx, tenantID); err != nil {
+			// The files written from now on are accounted anyway
+			logger.Err(err).Str("tenantID", tenantID).Msg("Failed to recount tenant storage")
+		}
+	}
+	return nil
+}
+
+// RecountStorage replaces the sizes recorded for the files of the tenant by
+// those listed in the bucket
+func (s *TenantQuotaService) RecountStorage(ctx context.Context, tenantID string) error {
+	sizes, err := s.files.TenantFileSizes(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.RecountStorage: %w", err)
+	}
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("service.RecountStorage: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+	if err := qtx.DeleteTenantFiles(ctx, tenantID); err != nil {
+		return fmt.Errorf("service.RecountStorage: %w", err)
+	}
+	for path, size := range sizes {
+		if err := qtx.UpsertTenantFile(ctx, repository.UpsertTenantFileParams{
+			TenantID:  tenantID,
+			Path:      path,
+			SizeBytes: size,
+		}); err != nil {
+			return fmt.Errorf("service.RecountStorage: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetUsage returns the usage of every resource of the tenant against its limit
+func (s *TenantQuotaService) GetUsage(ctx context.Context, tenantID string) ([]TenantQuotaResource, error) {
+	limits, err := s.GetLimits(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.store.GetTenantResourceUsage(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetUsage: %w", err)
+	}
+	return tenantQuotaResources(limits, usage), nil
+}
+
+func tenantQuotaResources(limits TenantQuotaLimits, usage repository.GetTenantResourceUsageRow) []TenantQuotaResource {
+	return []TenantQuotaResource{
+		{Resource: QuotaResourceUsers, Used: usage.Users, Limit: limits.MaxUsers},
+		{Resource: QuotaResourceClientApplications, Used: usage.ClientApplications, Limit: limits.MaxClientApplications},
+		{Resource: QuotaResourceStorageMB, Used: (usage.StorageBytes + bytesPerMB - 1) / bytesPerMB, Limit: limits.MaxStorageMB},
+		{Resource: QuotaResourcePromptExecutions, Used: usage.PromptExecutions, Limit: limits.MaxPromptExecutionsPerMonth},
+	}
+}
+
+// CheckQuota returns an error wrapping ErrTenantQuotaExceeded when the tenant
+// cannot add one more of the resource, users or client applications. Two
+// concurrent additions may both pass the check.
+func (s *TenantQuotaService) CheckQuota(ctx context.Context, tenantID, resource string) error {
+	if tenantID == "" {
+		return nil
+	}
+	limits, err := s.GetLimits(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	limit := tenantQuotaLimit(limits, resource)
+	if limit == nil {
+		return nil
+	}
+	usage, err := s.store.GetTenantResourceUsage(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.CheckQuota: %w", err)
+	}
+	for _, r := range tenantQuotaResources(limits, usage) {
+		if r.Resource == resource && r.Exceeded() {
+			return fmt.Errorf("%w: the tenant has reached its limit of %d %s", ErrTenantQuotaExceeded, *limit, resource)
+		}
+	}
+	return nil
+}
+
+// ReservePromptExecution counts a prompt execution of the tenant in the
+// current month, or returns an error wrapping ErrTenantQuotaExceeded when the
+// tenant reached its monthly limit. The modules running prompts call it
+// before each execution.
+func (s *TenantQuotaService) ReservePromptExecution(ctx context.Context, tenantID string) error {
+	limits, err := s.GetLimits(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.ReserveTenantPromptExecution(ctx, repository.ReserveTenantPromptExecutionParams{
+		TenantID:      tenantID,
+		MaxExecutions: util.ToNullableInt4(limits.MaxPromptExecutionsPerMonth),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: the tenant has reached its limit of %d prompt executions this month",
+			ErrTenantQuotaExceeded, *limits.MaxPromptExecutionsPerMonth)
+	}
+	if err != nil {
+		return fmt.Errorf("service.ReservePromptExecution: %w", err)
+	}
+	return nil
+}
+
+// CheckFile implements fileservice.TenantStorageQuota
+func (s *TenantQuotaService) CheckFile(ctx context.Context, tenantID, filename string, size int64) error {
+	limits, err := s.GetLimits(ctx, tenantID)
+	if err != nil || limits.MaxStorageMB == nil {
+		return err
+	}
+	stored, err := s.store.GetTenantStorageExcept(ctx, repository.GetTenantStorageExceptParams{
+		TenantID: tenantID,
+		Path:     filename,
+	})
+	if err != nil {
+		return fmt.Errorf("service.CheckFile: %w", err)
+	}
+	if stored+size > int64(*limits.MaxStorageMB)*bytesPerMB {
+		return fmt.Errorf("%w: the tenant has reached its limit of %d MB of storage", ErrTenantQuotaExceeded, *limits.MaxStorageMB)
+	}
+	return nil
+}
+
+// FileSaved implements fileservice.TenantStorageQuota
+func (s *TenantQuotaService) FileSaved(ctx context.Context, tenantID, filename string, size int64) error {
+	return s.store.UpsertTenantFile(ctx, repository.UpsertTenantFileParams{
+		TenantID:  tenantID,
+		Path:      filename,
+		SizeBytes: size,
+	})
+}
+
+// FileDeleted implements fileservice.TenantStorageQuota
+func (s *TenantQuotaService) FileDeleted(ctx context.Context, tenantID, filename string) error {
+	return s.store.DeleteTenantFile(ctx, repository.DeleteTenantFileParams{
+		TenantID: tenantID,
+		Path:     filename,
+	})
+}
+
+var _ fileservice.TenantStorageQuota = (*TenantQuotaService)(nil)
+
+// tenantQuotaLimit returns the limit of a resource, nil when not limited
+func tenantQuotaLimit(limits TenantQuotaLimits, resource string) *int32 {
+	switch resource {
+	case QuotaResourceUsers:
+		return limits.MaxUsers
+	case QuotaResourceClientApplications:
+		return limits.MaxClientApplications
+	case QuotaResourceStorageMB:
+		return limits.MaxStorageMB
+	case QuotaResourcePromptExecutions:
+		return limits.MaxPromptExecutionsPerMonth
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantQuotaResources(t *testing.T) {
+	two := int32(2)
+	resources := tenantQuotaResources(TenantQuotaLimits{MaxUsers: &two, MaxStorageMB: &two},
+		repository.GetTenantResourceUsageRow{Users: 2, ClientApplications: 5, StorageBytes: bytesPerMB + 1})
+
+	require.Len(t, resources, 4)
+	require.Equal(t, QuotaResourceUsers, resources[0].Resource)
+	require.True(t, resources[0].Exceeded())
+	// Not limited
+	require.False(t, resources[1].Exceeded())
+	// Rounded up to the MB
+	require.Equal(t, int64(2), resources[2].Used)
+	require.True(t, resources[2].Exceeded())
+	require.False(t, resources[3].Exceeded())
+}
+
+func TestTenantQuotas(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	quotas := NewTenantQuotaService(store, nil)
+	ctx := context.Background()
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+
+	t.Run("a tenant without quota is not limited", func(t *testing.T) {
+		require.NoError(t, quotas.CheckQuota(ctx, tenant.TenantID, QuotaResourceUsers))
+		require.NoError(t, quotas.ReservePromptExecution(ctx, tenant.TenantID))
+		require.NoError(t, quotas.CheckFile(ctx, tenant.TenantID, "/tenants/"+tenant.TenantID+"/a.png", 10*bytesPerMB))
+	})
+
+	t.Run("negative limits are refused", func(t *testing.T) {
+		negative := int32(-1)
+		err := quotas.SetLimits(ctx, tenant.TenantID, TenantQuotaLimits{MaxUsers: &negative}, "admin")
+		require.ErrorIs(t, err, ErrInvalidTenantQuota)
+	})
+
+	t.Run("prompt executions are limited per month", func(t *testing.T) {
+		two := int32(2)
+		require.NoError(t, quotas.SetLimits(ctx, tenant.TenantID, TenantQuotaLimits{MaxPromptExecutionsPerMonth: &two}, "admin"))
+
+		// One was reserved before the limit was set
+		require.NoError(t, quotas.ReservePromptExecution(ctx, tenant.TenantID))
+		err := quotas.ReservePromptExecution(ctx, tenant.TenantID)
+		require.True(t, errors.Is(err, ErrTenantQuotaExceeded))
+
+		usage, err := quotas.GetUsage(ctx, tenant.TenantID)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), usage[3].Used)
+		require.True(t, usage[3].Exceeded())
+	})
+
+	t.Run("storage counts the other files of the tenant", func(t *testing.T) {
+		one := int32(1)
+		require.NoError(t, quotas.SetLimits(ctx, tenant.TenantID, TenantQuotaLimits{MaxStorageMB: &one}, "admin"))
+		path := "/tenants/" + tenant.TenantID + "/a.png"
+
+		require.NoError(t, quotas.FileSaved(ctx, tenant.TenantID, path, bytesPerMB/2))
+		// Replacing the file does not count it twice
+		require.NoError(t, quotas.CheckFile(ctx, tenant.TenantID, path, bytesPerMB))
+		err := quotas.CheckFile(ctx, tenant.TenantID, "/tenants/"+tenant.TenantID+"/b.png", bytesPerMB/2+1)
+		require.ErrorIs(t, err, ErrTenantQuotaExceeded)
+
+		require.NoError(t, quotas.FileDeleted(ctx, tenant.TenantID, path))
+		require.NoError(t, quotas.CheckFile(ctx, tenant.TenantID, "/tenants/"+tenant.TenantID+"/b.png", bytesPerMB))
+	})
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/event"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrTenantNotSandbox = errors.New("tenant is not a sandbox")
+	// ErrSandboxResetRunning is returned while another reset of the tenant runs
+	ErrSandboxResetRunning = errors.New("a reset of the tenant is already running")
+	// ErrSandboxResetChanged is returned when the confirmation token no longer
+	// matches the plan, i.e. members or settings changed since review
+	ErrSandboxResetChanged = errors.New("tenant changed since the reset was reviewed")
+)
+
+// sandboxKeptRoles are the tenant roles whose holders survive a reset, along
+// with the tenant owner
+var sandboxKeptRoles = []string{string(core.CUSTOMERADMIN), string(core.ADMIN), string(core.SUPERADMIN)}
+
+// SandboxResetFunc deletes the data a module keeps for a tenant and returns
+// how many items it removed
+type SandboxResetFunc func(ctx context.Context, tenantID string) (int64, error)
+
+type sandboxResetStep struct {
+	name  string
+	reset SandboxResetFunc
+}
+
+var (
+	sandboxResetStepsMu sync.RWMutex
+	sandboxResetSteps   []sandboxResetStep
+)
+
+// RegisterSandboxResetStep lets a module, such as prompts and their
+// executions or stored files, wipe its data when a sandbox tenant is reset.
+// Steps run in registration order, after the members are removed and before
+// the template is re-applied.
+func RegisterSandboxResetStep(name string, reset SandboxResetFunc) {
+	sandboxResetStepsMu.Lock()
+	defer sandboxResetStepsMu.Unlock()
+	sandboxResetSteps = append(sandboxResetSteps, sandboxResetStep{name: name, reset: reset})
+}
+
+func getSandboxResetSteps() []sandboxResetStep {
+	sandboxResetStepsMu.RLock()
+	defer sandboxResetStepsMu.RUnlock()
+	return append([]sandboxResetStep{}, sandboxResetSteps...)
+}
+
+// TenantSandboxResetPlan is what a reset would do. The confirmation token must
+// be sent back to start it.
+type TenantSandboxResetPlan struct {
+	TenantID          string
+	Members           []string
+	SettingsChanges   []TenantSettingsChange
+	Steps             []string
+	ConfirmationToken string
+
+	settingsFingerprint string
+}
+
+// TenantSandboxService resets demo and trial tenants to their provisioning
+// template
+type TenantSandboxService struct {
+	store              *db.Store
+	settings           *TenantSettingsService
+	jobs               *JobService
+	files              *fileservice.FileService
+	multiTenantService *MultitenantService
+}
+
+func NewTenantSandboxService(store *db.Store, files *fileservice.FileService, multiTenantService *MultitenantService) *TenantSandboxService {
+	return &TenantSandboxService{
+		store:              store,
+		settings:           NewTenantSettingsService(store),
+		jobs:               NewJobService(store),
+		files:              files,
+		multiTenantService: multiTenantService,
+	}
+}
+
+// GetSandbox returns the sandbox of the tenant, or ErrTenantNotSandbox
+func (s *TenantSandboxService) GetSandbox(ctx context.Context, tenantID string) (repository.CoreTenantSandbox, error) {
+	sandbox, err := s.store.GetTenantSandbox(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sandbox, ErrTenantNotSandbox
+		}
+		return sandbox, fmt.Errorf("service.GetSandbox: %w", err)
+	}
+	return sandbox, nil
+}
+
+// SetSandbox makes the tenant a sandbox, its current settings becoming the
+// template re-applied by resets. Calling it again replaces the template.
+func (s *TenantSandboxService) SetSandbox(ctx context.Context, tenant repository.CoreTenant, userID string) (repository.CoreTenantSandbox, error) {
+	doc, err := s.settings.ExportTenantSettings(ctx, tenant)
+	if err != nil {
+		return repository.CoreTenantSandbox{}, fmt.Errorf("service.SetSandbox: %w", err)
+	}
+	template, err := json.Marshal(doc.Settings)
+	if err != nil {
+		return repository.CoreTenantSandbox{}, fmt.Errorf("service.SetSandbox: %w", err)
+	}
+	sandbox, err := s.store.UpsertTenantSandbox(ctx, repository.UpsertTenantSandboxParams{
+		TenantID:  tenant.TenantID,
+		Template:  template,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return sandbox, fmt.Errorf("service.SetSandbox: %w", err)
+	}
+	return sandbox, nil
+}
+
+// RemoveSandbox makes the tenant a regular tenant again
+func (s *TenantSandboxService) RemoveSandbox(ctx context.Context, tenantID string) error {
+	deleted, err := s.store.DeleteTenantSandbox(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.RemoveSandbox: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTenantNotSandbox
+	}
+	return nil
+}
+
+// PlanReset computes what a reset of the tenant would remove and change,
+// without touching anything
+func (s *TenantSandboxService) PlanReset(ctx context.Context, tenant repository.CoreTenant) (TenantSandboxResetPlan, error) {
+	sandbox, err := s.GetSandbox(ctx, tenant.TenantID)
+	if err != nil {
+		return TenantSandboxResetPlan{}, err
+	}
+	return s.planReset(ctx, tenant, sandbox)
+}
+
+func (s *TenantSandboxService) planReset(ctx context.Context, tenant repository.CoreTenant, sandbox repository.CoreTenantSandbox) (TenantSandboxResetPlan, error) {
+	members, err := s.store.ListTenantSandboxResetMembers(ctx, repository.ListTenantSandboxResetMembersParams{
+		TenantID:  tenant.TenantID,
+		OwnerID:   tenant.UserID,
+		KeptRoles: sandboxKeptRoles,
+	})
+	if err != nil {
+		return TenantSandboxResetPlan{}, fmt.Errorf("service.PlanReset: %w", err)
+	}
+	doc, err := sandboxTemplateDocument(sandbox)
+	if err != nil {
+		return TenantSandboxResetPlan{}, fmt.Errorf("service.PlanReset: %w", err)
+	}
+	settingsPlan, err := s.settings.PlanTenantSettingsImport(ctx, tenant, doc)
+	if err != nil {
+		return TenantSandboxResetPlan{}, fmt.Errorf("service.PlanReset: %w", err)
+	}
+
+	plan := TenantSandboxResetPlan{
+		TenantID:            tenant.TenantID,
+		Members:             members,
+		SettingsChanges:     settingsPlan.Changes,
+		Steps:               []string{},
+		settingsFingerprint: settingsPlan.Fingerprint,
+	}
+	for _, step := range getSandboxResetSteps() {
+		plan.Steps = append(plan.Steps, step.name)
+	}
+	token, err := json.Marshal(struct {
+		TenantID string   `json:"tenantId"`
+		Members  []string `json:"members"`
+		Settings string   `json:"settings"`
+		Steps    []string `json:"steps"`
+	}{plan.TenantID, plan.Members, plan.settingsFingerprint, plan.Steps})
+	if err != nil {
+		return TenantSandboxResetPlan{}, fmt.Errorf("service.PlanReset: %w", err)
+	}
+	sum := sha256.Sum256(token)
+	plan.ConfirmationToken = hex.EncodeToString(sum[:])
+	return plan, nil
+}
+
+func sandboxTemplateDocument(sandbox repository.CoreTenantSandbox) (TenantSettingsDocument, error) {
+	doc := TenantSettingsDocument{Version: TenantSettingsDocumentVersion, SourceTenantID: sandbox.TenantID}
+	if err := json.Unmarshal(sandbox.Template, &doc.Settings); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
+
+// StartReset starts the reset of the tenant as a job, provided the plan still
+// matches the confirmation token the caller reviewed. Members are removed with
+// authClient, the auth client of the tenant.
+func (s *TenantSandboxService) StartReset(ctx context.Context, authClient auth.AuthClient, tenant repository.CoreTenant, confirmationToken, userID string) (TenantSandboxResetPlan, repository.CoreJob, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	plan, err := s.PlanReset(ctx, tenant)
+	if err != nil {
+		return plan, repository.CoreJob{}, err
+	}
+	if plan.ConfirmationToken != confirmationToken {
+		return plan, repository.CoreJob{}, ErrSandboxResetChanged
+	}
+
+	// The job is recorded first, so refused attempts are audited too
+	job, err := s.jobs.StartJob(ctx, tenant.TenantID, JobKindTenantSandboxReset, userID)
+	if err != nil {
+		return plan, job, fmt.Errorf("service.StartReset: %w", err)
+	}
+	sandbox, err := s.store.StartTenantSandboxReset(ctx, repository.StartTenantSandboxResetParams{
+		ResetBy:  userID,
+		TenantID: tenant.TenantID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrSandboxResetRunning
+		}
+		s.recordEvent(ctx, job, event.NewProgressEvent("ERROR", err.Error(), 100))
+		if finishErr := s.jobs.FinishJob(ctx, job.ID, JobStatusFailed); finishErr != nil {
+			logger.Err(finishErr).Str("jobID", job.ID.String()).Msg("Failed to finish sandbox reset job")
+		}
+		if errors.Is(err, ErrSandboxResetRunning) {
+			return plan, job, err
+		}
+		return plan, job, fmt.Errorf("service.StartReset: %w", err)
+	}
+
+	logger.Info().
+		Str("tenant_id", tenant.TenantID).
+		Str("job_id", job.ID.String()).
+		Str("user_id", userID).
+		Int("members", len(plan.Members)).
+		Msg("Tenant sandbox reset started")
+
+	// The reset goes on when the client goes away
+	go s.runReset(context.WithoutCancel(ctx), authClient, tenant, sandbox, job, userID)
+	return plan, job, nil
+}
+
+// runReset removes the members, runs the module steps and re-applies the
+// template. A failing part does not stop the others, so the tenant is as
+// clean as possible; the job fails when any part failed.
+func (s *TenantSandboxService) runReset(ctx context.Context, authClient auth.AuthClient, tenant repository.CoreTenant, sandbox repository.CoreTenantSandbox, job repository.CoreJob, userID string) {
+	logger := util.GetLoggerFromCtx(ctx).With().Str("tenant_id", tenant.TenantID).Str("job_id", job.ID.String()).Logger()
+	result := event.TenantSandboxReset{TenantID: tenant.TenantID, Steps: []event.TenantSandboxResetStep{}}
+	failed := false
+
+	s.recordEvent(ctx, job, event.NewProgressEvent("INFO", "Removing members", 5))
+	members, err := s.store.ListTenantSandboxResetMembers(ctx, repository.ListTenantSandboxResetMembersParams{
+		TenantID:  tenant.TenantID,
+		OwnerID:   tenant.UserID,
+		KeptRoles: sandboxKeptRoles,
+	})
+	if err != nil {
+		logger.Err(err).Msg("Failed to list sandbox members")
+		s.recordEvent(ctx, job, event.NewProgressEvent("ERROR", "Failed to list members: "+err.Error(), 5))
+		failed = true
+	}
+	for i, memberID := range members {
+		deleted, err := s.removeMember(ctx, authClient, tenant.TenantID, memberID, userID)
+		if err != nil {
+			logger.Err(err).Str("user_id", memberID).Msg("Failed to remove sandbox member")
+			s.recordEvent(ctx, job, event.NewProgressEvent("ERROR", fmt.Sprintf("Failed to remove member %s: %v", memberID, err), 5+50*i/len(members)))
+			failed = true
+			continue
+		}
+		result.MembersRemoved++
+		if deleted {
+			result.UsersDeleted++
+		}
+	}
+	s.recordEvent(ctx, job, event.NewProgressEvent("INFO", fmt.Sprintf("Removed %d members", result.MembersRemoved), 55))
+
+	for _, step := range getSandboxResetSteps() {
+		deleted, err := step.reset(ctx, tenant.TenantID)
+		stepResult := event.TenantSandboxResetStep{Name: step.name, Deleted: deleted}
+		if err != nil {
+			logger.Err(err).Str("step", step.name).Msg("Sandbox reset step failed")
+			stepResult.Error = err.Error()
+			failed = true
+		}
+		result.Steps = append(result.Steps, stepResult)
+		s.recordEvent(ctx, job, event.NewProgressEvent("INFO", fmt.Sprintf("Reset %s: %d deleted", step.name, deleted), 80))
+	}
+
+	if changes, err := s.applyTemplate(ctx, tenant.TenantID, sandbox, userID); err != nil {
+		logger.Err(err).Msg("Failed to re-apply the sandbox template")
+		s.recordEvent(ctx, job, event.NewProgressEvent("ERROR", "Failed to re-apply the template: "+err.Error(), 90))
+		failed = true
+	} else {
+		result.SettingsChanges = changes
+	}
+
+	status := JobStatusCompleted
+	message := "Sandbox reset completed"
+	if failed {
+		status = JobStatusFailed
+		message = "Sandbox reset completed with errors"
+	}
+	s.recordEvent(ctx, job, event.NewProgressEventWithData("INFO", message, 100, result))
+	if err := s.jobs.FinishJob(ctx, job.ID, status); err != nil {
+		logger.Err(err).Msg("Failed to finish sandbox reset job")
+	}
+	if err := s.store.FinishTenantSandboxReset(ctx, repository.FinishTenantSandboxResetParams{
+		JobID:    job.ID,
+		Status:   status,
+		TenantID: tenant.TenantID,
+	}); err != nil {
+		logger.Err(err).Msg("Failed to record the sandbox reset")
+	}
+	logger.Info().
+		Str("user_id", userID).
+		Str("status", status).
+		Int("members_removed", result.MembersRemoved).
+		Int("users_deleted", result.UsersDeleted).
+		Msg("Tenant sandbox reset finished")
+}
+
+// removeMember removes the membership of the user, handing the resources they
+// own in the tenant to its owner. A user left without any tenant is deleted
+// with their identity and profile picture; it reports whether it was.
+func (s *TenantSandboxService) removeMember(ctx context.Context, authClient auth.AuthClient, tenantID, memberID, actorID string) (bool, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	if err := transferOwnedResources(ctx, tx, tenantID, memberID, OwnershipTransfer{ToTenantOwner: true}, actorID); err != nil {
+		return false, err
+	}
+	if err := qtx.RemoveSharedUserFromTenant(ctx, repository.RemoveSharedUserFromTenantParams{
+		UserID:   memberID,
+		TenantID: tenantID,
+	}); err != nil {
+		return false, err
+	}
+	deleted, err := qtx.DeleteUserWithoutMemberships(ctx, memberID)
+	if err != nil {
+		return false, err
+	}
+	if deleted > 0 {
+		if err := authClient.DeleteUser(ctx, memberID); err != nil && !auth.IsUserNotFound(err) {
+			return false, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	if deleted > 0 && s.files != nil {
+		for _, path := range fileservice.ProfilePictureFilePaths(memberID) {
+			if err := s.files.DeleteFile(ctx, path); err != nil {
+				logger := util.GetLoggerFromCtx(ctx)
+				logger.Debug().Err(err).Str("user_id", memberID).Str("path", path).Msg("No profile picture to delete")
+			}
+		}
+	}
+	_ = recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  tenantID,
+		UserID:    memberID,
+		Category:  ActivityCategoryMembership,
+		EventType: "removed",
+		ActorID:   actorID,
+		Data:      map[string]interface{}{"reason": "sandbox_reset"},
+	})
+	return deleted > 0, nil
+}
+
+// applyTemplate re-applies the template on the tenant as read now, since the
+// module steps may have changed it
+func (s *TenantSandboxService) applyTemplate(ctx context.Context, tenantID string, sandbox repository.CoreTenantSandbox, userID string) (int, error) {
+	tenant, err := s.store.GetTenantByTenantID(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	doc, err := sandboxTemplateDocument(sandbox)
+	if err != nil {
+		return 0, err
+	}
+	plan, err := s.settings.PlanTenantSettingsImport(ctx, tenant, doc)
+	if err != nil {
+		return 0, err
+	}
+	plan, err = s.settings.ApplyTenantSettingsImport(ctx, tenant, doc, plan.Fingerprint, userID)
+	if err != nil {
+		return 0, err
+	}
+	if plan.Applied && s.multiTenantService != nil {
+		s.multiTenantService.InvalidateTenant(tenantID)
+	}
+	return len(plan.Changes), nil
+}
+
+func (s *TenantSandboxService) recordEvent(ctx context.Context, job repository.CoreJob, progressEvent event.ProgressEvent) {
+	if _, err := s.jobs.RecordEvent(ctx, job.ID, progressEvent); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("jobID", job.ID.String()).Msg("Failed to record sandbox reset event")
+	}
+}
+
+// IsSandboxResetRunning reports whether a reset of the sandbox is running. A
+// reset claimed more than an hour ago is considered dead, as in
+// StartTenantSandboxReset.
+func IsSandboxResetRunning(sandbox repository.CoreTenantSandbox) bool {
+	return sandbox.ResetStartedAt.Valid && time.Since(sandbox.ResetStartedAt.Time) < time.Hour
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandboxTemplateDocument(t *testing.T) {
+	support := "support@example.com"
+	template, err := json.Marshal(TenantSettings{
+		AllowSignUp: true,
+		Features:    subentity.TenantFeatures{"chat": true},
+		Configs:     map[string]*string{"support_email": &support},
+	})
+	require.NoError(t, err)
+
+	doc, err := sandboxTemplateDocument(repository.CoreTenantSandbox{TenantID: "demo-1", Template: template})
+	require.NoError(t, err)
+	require.Equal(t, TenantSettingsDocumentVersion, doc.Version)
+	require.Equal(t, "demo-1", doc.SourceTenantID)
+	require.True(t, doc.Settings.AllowSignUp)
+	require.True(t, doc.Settings.Features["chat"])
+	require.Equal(t, &support, doc.Settings.Configs["support_email"])
+
+	_, err = sandboxTemplateDocument(repository.CoreTenantSandbox{Template: []byte("not json")})
+	require.Error(t, err)
+}
+
+func TestIsSandboxResetRunning(t *testing.T) {
+	require.False(t, IsSandboxResetRunning(repository.CoreTenantSandbox{}))
+
+	started := repository.CoreTenantSandbox{ResetStartedAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}}
+	require.True(t, IsSandboxResetRunning(started))
+
+	// A reset claimed more than an hour ago died with its instance
+	stale := repository.CoreTenantSandbox{ResetStartedAt: pgtype.Timestamptz{Time: time.Now().Add(-2 * time.Hour), Valid: true}}
+	require.False(t, IsSandboxResetRunning(stale))
+}
+
+func TestRegisterSandboxResetStep(t *testing.T) {
+	sandboxResetStepsMu.Lock()
+	saved := sandboxResetSteps
+	sandboxResetSteps = nil
+	sandboxResetStepsMu.Unlock()
+	t.Cleanup(func() {
+		sandboxResetStepsMu.Lock()
+		sandboxResetSteps = saved
+		sandboxResetStepsMu.Unlock()
+	})
+
+	RegisterSandboxResetStep("prompts", func(ctx context.Context, tenantID string) (int64, error) { return 3, nil })
+	RegisterSandboxResetStep("files", func(ctx context.Context, tenantID string) (int64, error) { return 0, nil })
+
+	steps := getSandboxResetSteps()
+	require.Len(t, steps, 2)
+	require.Equal(t, "prompts", steps[0].name)
+	require.Equal(t, "files", steps[1].name)
+	deleted, err := steps[0].reset(context.Background(), "demo-1")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), deleted)
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+)
+
+// Settings registered by the core. Modules register their own keys with
+// RegisterTenantSetting, prefixed by the name of the module.
+const (
+	TenantSettingLocale            = "locale"
+	TenantSettingTimezone          = "timezone"
+	TenantSettingBrandingDarkMode  = "branding.dark_mode_default"
+	TenantSettingBrandingPoweredBy = "branding.show_powered_by"
+	tenantSettingValuesContextKey  = "tenant_setting_values"
+	maxTenantSettingValueBytes     = 16 * 1024
+)
+
+var tenantSettingKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+var (
+	// ErrInvalidTenantSetting is returned when a setting cannot be registered
+	ErrInvalidTenantSetting = errors.New("invalid tenant setting")
+	// ErrInvalidTenantSettingValues is matched by every TenantSettingValuesError
+	ErrInvalidTenantSettingValues = errors.New("invalid tenant settings")
+)
+
+// TenantSettingDefinition is a key of the tenant settings, with the JSON
+// schema its values must match and the value of the tenants that did not set
+// it. The public settings are served to unauthenticated frontends.
+type TenantSettingDefinition struct {
+	Key         string
+	Description string
+	Schema      string
+	Default     interface{}
+	Public      bool
+
+	schema *jsonSchema
+}
+
+// TenantSettingViolation is a value the schema of its key rejects
+type TenantSettingViolation struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// TenantSettingValuesError lists every rejected value, so a form can show them
+// all at once
+type TenantSettingValuesError struct {
+	Violations []TenantSettingViolation
+}
+
+func (e *TenantSettingValuesError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidTenantSettingValues, strings.Join(messages, ", "))
+}
+
+func (e *TenantSettingValuesError) Is(target error) bool {
+	return target == ErrInvalidTenantSettingValues
+}
+
+var (
+	tenantSettingsMu          sync.RWMutex
+	tenantSettingsDefinitions = map[string]TenantSettingDefinition{}
+)
+
+func init() {
+	for _, def := range []TenantSettingDefinition{
+		{Key: TenantSettingLocale, Description: "Default language of the tenant, such as en or fr-CA",
+			Schema: `{"type": "string", "pattern": "^[a-z]{2}(-[A-Z]{2})?$"}`, Default: "en", Public: true},
+		{Key: TenantSettingTimezone, Description: "IANA time zone the dates are shown in",
+			Schema: `{"type": "string", "minLength": 1, "maxLength": 64}`, Default: "UTC"},
+		{Key: TenantSettingBrandingDarkMode, Description: "Show the dark colors until the user picks a mode",
+			Schema: `{"type": "boolean"}`, Default: false, Public: true},
+		{Key: TenantSettingBrandingPoweredBy, Description: "Show the powered by mention in the footer",
+			Schema: `{"type": "boolean"}`, Default: true, Public: true},
+	} {
+		if err := RegisterTenantSetting(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterTenantSetting adds a key to the tenant settings, or replaces its
+// definition. Modules call it at startup.
+func RegisterTenantSetting(def TenantSettingDefinition) error {
+	if !tenantSettingKeyPattern.MatchString(def.Key) || len(def.Key) > 64 {
+		return fmt.Errorf("%w: the key %q must be dotted lowercase words of up to 64 characters", ErrInvalidTenantSetting, def.Key)
+	}
+	schema, err := parseJSONSchema(def.Schema)
+	if err != nil {
+		return fmt.Errorf("%w: the schema of %s: %v", ErrInvalidTenantSetting, def.Key, err)
+	}
+	if violations := schema.validate(def.Key, def.Default); def.Default != nil && len(violations) > 0 {
+		return fmt.Errorf("%w: the default of %s: %s", ErrInvalidTenantSetting, def.Key, strings.Join(violations, ", "))
+	}
+	def.schema = schema
+	tenantSettingsMu.Lock()
+	tenantSettingsDefinitions[def.Key] = def
+	tenantSettingsMu.Unlock()
+	tenantSettingValuesCache.InvalidateAll()
+	return nil
+}
+
+// TenantSettingDefinitions returns the registered settings by key
+func TenantSettingDefinitions() []TenantSettingDefinition {
+	tenantSettingsMu.RLock()
+	defer tenantSettingsMu.RUnlock()
+	defs := make([]TenantSettingDefinition, 0, len(tenantSettingsDefinitions))
+	for _, def := range tenantSettingsDefinitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Key < defs[j].Key })
+	return defs
+}
+
+func tenantSettingDefinition(key string) (TenantSettingDefinition, bool) {
+	tenantSettingsMu.RLock()
+	defer tenantSettingsMu.RUnlock()
+	def, ok := tenantSettingsDefinitions[key]
+	return def, ok
+}
+
+// tenantSettingValuesCache keeps the stored values of each tenant, keyed by
+// tenant id
+var tenantSettingValuesCache = NewPublicCache[map[string]interface{}](DefaultPublicCacheTTL)
+
+// storedSettingValues returns the values set by the tenant, decoded
+func (s *TenantSettingsService) storedSettingValues(ctx context.Context, tenantID string) (map[string]interface{}, error) {
+	return tenantSettingValuesCache.Get(ctx, tenantID, func(ctx context.Context) (map[string]interface{}, error) {
+		rows, err := s.store.ListTenantSettingValues(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]interface{}, len(rows))
+		for _, row := range rows {
+			var value interface{}
+			if err := json.Unmarshal(row.Value, &value); err != nil {
+				return nil, err
+			}
+			values[row.Key] = value
+		}
+		return values, nil
+	})
+}
+
+// GetSettingValues returns the value of every registered setting of the
+// tenant, the default when it did not set it. A stored value no longer
+// matching the schema of its key is replaced by the default.
+func (s *TenantSettingsService) GetSettingValues(ctx context.Context, tenantID string) (map[string]interface{}, error) {
+	stored, err := s.storedSettingValues(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.GetSettingValues: %w", err)
+	}
+	values := map[string]interface{}{}
+	for _, def := range TenantSettingDefinitions() {
+		value, ok := stored[def.Key]
+		if !ok || len(def.schema.validate(def.Key, value)) > 0 {
+			value = def.Default
+		}
+		values[def.Key] = value
+	}
+	return values, nil
+}
+
+// UpdateSettingValues sets the values of the keys, a null value resetting the
+// key to its default, in one transaction. Every value is checked against the
+// schema of its key first; the rejected ones are returned as a
+// *TenantSettingValuesError and nothing is written.
+func (s *TenantSettingsService) UpdateSettingValues(ctx context.Context, tenantID string, changes map[string]json.RawMessage, updatedBy string) (map[string]interface{}, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	violations := []TenantSettingViolation{}
+	resets := map[string]bool{}
+	for _, key := range keys {
+		def, ok := tenantSettingDefinition(key)
+		if !ok {
+			violations = append(violations, TenantSettingViolation{Key: key, Message: key + " is not a tenant setting"})
+			continue
+		}
+		raw := changes[key]
+		if len(raw) > maxTenantSettingValueBytes {
+			violations = append(violations, TenantSettingViolation{Key: key,
+				Message: fmt.Sprintf("%s must be at most %d bytes", key, maxTenantSettingValueBytes)})
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			violations = append(violations, TenantSettingViolation{Key: key, Message: key + " is not valid JSON"})
+			continue
+		}
+		if value == nil {
+			resets[key] = true
+			continue
+		}
+		for _, msg := range def.schema.validate(key, value) {
+			violations = append(violations, TenantSettingViolation{Key: key, Message: msg})
+		}
+	}
+	if len(violations) > 0 {
+		return nil, &TenantSettingValuesError{Violations: violations}
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.UpdateSettingValues: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+	for _, key := range keys {
+		if resets[key] {
+			err = qtx.DeleteTenantSettingValue(ctx, repository.DeleteTenantSettingValueParams{TenantID: tenantID, Key: key})
+		} else {
+			err = qtx.UpsertTenantSettingValue(ctx, repository.UpsertTenantSettingValueParams{
+				TenantID:  tenantID,
+				Key:       key,
+				Value:     changes[key],
+				UpdatedBy: updatedBy,
+			})
+		}
+		if err != nil {
+			logger.Err(err).Str("tenant_id", tenantID).Str("key", key).Msg("Failed to update tenant setting")
+			return nil, fmt.Errorf("service.UpdateSettingValues: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("service.UpdateSettingValues: %w", err)
+	}
+	tenantSettingValuesCache.Invalidate(tenantID)
+	logger.Info().Str("tenant_id", tenantID).Strs("keys", keys).Str("updated_by", updatedBy).Msg("Tenant settings updated")
+	return s.GetSettingValues(ctx, tenantID)
+}
+
+// PublicSettingValues keeps the public settings of the values
+func PublicSettingValues(values map[string]interface{}) map[string]interface{} {
+	public := map[string]interface{}{}
+	for _, def := range TenantSettingDefinitions() {
+		if value, ok := values[def.Key]; ok && def.Public {
+			public[def.Key] = value
+		}
+	}
+	return public
+}
+
+// TenantSettingValuesFromContext returns the settings of the request tenant,
+// loaded by the tenant middleware; nil outside a tenant. The map is shared
+// and must not be modified.
+func TenantSettingValuesFromContext(c *gin.Context) map[string]interface{} {
+	if values, ok := c.Get(tenantSettingValuesContextKey); ok {
+		return values.(map[string]interface{})
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterTenantSetting(t *testing.T) {
+	err := RegisterTenantSetting(TenantSettingDefinition{Key: "Bad Key", Schema: `{"type": "string"}`})
+	require.ErrorIs(t, err, ErrInvalidTenantSetting)
+
+	err = RegisterTenantSetting(TenantSettingDefinition{Key: "test.bad_default", Schema: `{"type": "string"}`, Default: 3.0})
+	require.ErrorIs(t, err, ErrInvalidTenantSetting)
+
+	err = RegisterTenantSetting(TenantSettingDefinition{Key: "test.bad_schema", Schema: `{"type": "date"}`})
+	require.ErrorIs(t, err, ErrInvalidTenantSetting)
+
+	require.NoError(t, RegisterTenantSetting(TenantSettingDefinition{
+		Key:     "test.page_size",
+		Schema:  `{"type": "integer", "minimum": 10, "maximum": 100}`,
+		Default: 20.0,
+	}))
+	defer func() {
+		tenantSettingsMu.Lock()
+		delete(tenantSettingsDefinitions, "test.page_size")
+		tenantSettingsMu.Unlock()
+	}()
+
+	def, ok := tenantSettingDefinition("test.page_size")
+	require.True(t, ok)
+	require.Equal(t, []string{"test.page_size must be at most 100"}, def.schema.validate(def.Key, 500.0))
+
+	keys := []string{}
+	for _, def := range TenantSettingDefinitions() {
+		keys = append(keys, def.Key)
+	}
+	require.IsIncreasing(t, keys)
+	require.Contains(t, keys, "test.page_size")
+}
+
+func TestPublicSettingValues(t *testing.T) {
+	public := PublicSettingValues(map[string]interface{}{
+		TenantSettingLocale:           "fr",
+		TenantSettingTimezone:         "Europe/Paris",
+		TenantSettingBrandingDarkMode: true,
+	})
+	require.Equal(t, map[string]interface{}{
+		TenantSettingLocale:           "fr",
+		TenantSettingBrandingDarkMode: true,
+	}, public)
+}
+
+func TestTenantSettingValuesError(t *testing.T) {
+	err := error(&TenantSettingValuesError{Violations: []TenantSettingViolation{
+		{Key: "locale", Message: "locale must be a string"},
+		{Key: "other", Message: "other is not a tenant setting"},
+	}})
+	require.True(t, errors.Is(err, ErrInvalidTenantSettingValues))
+	require.Equal(t, "invalid tenant settings: locale must be a string, other is not a tenant setting", err.Error())
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"gopkg.in/yaml.v3"
+)
+
+// TenantSettingsDocumentVersion is the version written by exports and the only
+// one accepted by imports
+const TenantSettingsDocumentVersion = 1
+
+const (
+	TenantSettingsChangeAdd    = "add"
+	TenantSettingsChangeUpdate = "change"
+	TenantSettingsChangeRemove = "remove"
+)
+
+var (
+	ErrUnsupportedSettingsVersion = errors.New("unsupported tenant settings document version")
+	// ErrTenantSettingsDiffChanged is returned when the confirmed fingerprint no
+	// longer matches the diff, i.e. the tenant or the document changed since review
+	ErrTenantSettingsDiffChanged = errors.New("tenant settings changed since the diff was reviewed")
+)
+
+// TenantSettings are the portable settings of a tenant: sign-up options,
+// feature flags, branding and tenant configs
+type TenantSettings struct {
+	AllowPasswordSignUp bool                     `json:"allowPasswordSignUp"`
+	AllowSignUp         bool                     `json:"allowSignUp"`
+	Features            subentity.TenantFeatures `json:"features"`
+	Profile             subentity.TenantProfile  `json:"profile"`
+	Configs             map[string]*string       `json:"configs"`
+}
+
+// TenantSettingsDocument is the versioned export format used to promote
+// settings from one environment to another
+type TenantSettingsDocument struct {
+	Version        int            `json:"version"`
+	ExportedAt     time.Time      `json:"exportedAt"`
+	SourceTenantID string         `json:"sourceTenantId"`
+	Settings       TenantSettings `json:"settings"`
+}
+
+// TenantSettingsChange is one entry of the diff, addressed by a dotted path
+// such as features.chat or configs.support_email
+type TenantSettingsChange struct {
+	Path     string      `json:"path"`
+	Op       string      `json:"op"`
+	OldValue interface{} `json:"oldValue"`
+	NewValue interface{} `json:"newValue"`
+}
+
+// TenantSettingsImportPlan is the diff between a tenant and a document. The
+// fingerprint must be sent back to confirm the import.
+type TenantSettingsImportPlan struct {
+	Changes     []TenantSettingsChange `json:"changes"`
+	Fingerprint string                 `json:"fingerprint"`
+	Applied     bool                   `json:"applied"`
+}
+
+type TenantSettingsService struct {
+	store *db.Store
+}
+
+func NewTenantSettingsService(store *db.Store) *TenantSettingsService {
+	return &TenantSettingsService{store: store}
+}
+
+// ExportTenantSettings reads the current settings of the tenant into a document
+func (s *TenantSettingsService) ExportTenantSettings(ctx context.Context, tenant repository.CoreTenant) (TenantSettingsDocument, error) {
+	settings, err := s.currentSettings(ctx, s.store.Queries, tenant)
+	if err != nil {
+		return TenantSettingsDocument{}, fmt.Errorf("service.ExportTenantSettings: %w", err)
+	}
+	return TenantSettingsDocument{
+		Version:        TenantSettingsDocumentVersion,
+		ExportedAt:     time.Now().UTC(),
+		SourceTenantID: tenant.TenantID,
+		Settings:       settings,
+	}, nil
+}
+
+// PlanTenantSettingsImport computes what importing doc would add, change and
+// remove on the tenant without applying anything
+func (s *TenantSettingsService) PlanTenantSettingsImport(ctx context.Context, tenant repository.CoreTenant, doc TenantSettingsDocument) (TenantSettingsImportPlan, error) {
+	if doc.Version != TenantSettingsDocumentVersion {
+		return TenantSettingsImportPlan{}, ErrUnsupportedSettingsVersion
+	}
+	current, err := s.currentSettings(ctx, s.store.Queries, tenant)
+	if err != nil {
+		return TenantSettingsImportPlan{}, fmt.Errorf("service.PlanTenantSettingsImport: %w", err)
+	}
+	return planTenantSettings(current, doc.Settings)
+}
+
+// ApplyTenantSettingsImport applies doc to the tenant in one transaction,
+// provided the diff still matches the fingerprint the caller reviewed
+func (s *TenantSettingsService) ApplyTenantSettingsImport(ctx context.Context, tenant repository.CoreTenant, doc TenantSettingsDocument, fingerprint string, userID string) (TenantSettingsImportPlan, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if doc.Version != TenantSettingsDocumentVersion {
+		return TenantSettingsImportPlan{}, ErrUnsupportedSettingsVersion
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return TenantSettingsImportPlan{}, fmt.Errorf("service.ApplyTenantSettingsImport: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	current, err := s.currentSettings(ctx, qtx, tenant)
+	if err != nil {
+		return TenantSettingsImportPlan{}, fmt.Errorf("service.ApplyTenantSettingsImport: %w", err)
+	}
+	plan, err := planTenantSettings(current, doc.Settings)
+	if err != nil {
+		return plan, fmt.Errorf("service.ApplyTenantSettingsImport: %w", err)
+	}
+	if plan.Fingerprint != fingerprint {
+		return plan, ErrTenantSettingsDiffChanged
+	}
+	if len(plan.Changes) == 0 {
+		return plan, nil
+	}
+
+	if err := applyTenantSettings(ctx, qtx, tenant, current, doc.Settings, plan.Changes, userID); err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Msg("Failed to apply tenant settings import")
+		return plan, fmt.Errorf("service.ApplyTenantSettingsImport: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return plan, fmt.Errorf("service.ApplyTenantSettingsImport: %w", err)
+	}
+
+	logger.Info().Str("tenant_id", tenant.TenantID).Int("changes", len(plan.Changes)).Msg("Tenant settings imported")
+	plan.Applied = true
+	return plan, nil
+}
+
+func (s *TenantSettingsService) currentSettings(ctx context.Context, q *repository.Queries, tenant repository.CoreTenant) (TenantSettings, error) {
+	configs, err := q.ListAllTenantConfigs(ctx, tenant.TenantID)
+	if err != nil {
+		return TenantSettings{}, err
+	}
+	settings := TenantSettings{
+		AllowPasswordSignUp: tenant.AllowPasswordSignUp,
+		AllowSignUp:         tenant.AllowSignUp,
+		Features:            tenant.Features,
+		Profile:             tenant.Profile,
+		Configs:             make(map[string]*string, len(configs)),
+	}
+	for _, config := range configs {
+		settings.Configs[config.Name] = util.FromNullableText(config.Value)
+	}
+	return settings, nil
+}
+
+func applyTenantSettings(ctx context.Context, q *repository.Queries, tenant repository.CoreTenant, current, target TenantSettings, changes []TenantSettingsChange, userID string) error {
+	var signUpChanged, featuresChanged, profileChanged bool
+	for _, change := range changes {
+		section, name, _ := strings.Cut(change.Path, ".")
+		switch section {
+		case "allowPasswordSignUp", "allowSignUp":
+			signUpChanged = true
+		case "features":
+			featuresChanged = true
+		case "profile":
+			profileChanged = true
+		case "configs":
+			if err := applyTenantConfigChange(ctx, q, tenant.TenantID, name, change.Op, target.Configs[name], userID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if signUpChanged {
+		_, err := q.UpdateTenant(ctx, repository.UpdateTenantParams{
+			ID:                  tenant.ID,
+			Name:                tenant.Name,
+			Subdomain:           tenant.Subdomain,
+			AllowPasswordSignUp: target.AllowPasswordSignUp,
+			AllowSignUp:         target.AllowSignUp,
+			IsReseller:          tenant.IsReseller,
+			ContractEndDate:     tenant.ContractEndDate,
+			IsDisabled:          tenant.IsDisabled,
+			ResellerID:          tenant.ResellerID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if featuresChanged {
+		features := target.Features
+		if features == nil {
+			features = subentity.TenantFeatures{}
+		}
+		if _, err := q.UpdateTenantFeatures(ctx, repository.UpdateTenantFeaturesParams{ID: tenant.ID, Features: features}); err != nil {
+			return err
+		}
+	}
+	if profileChanged {
+		if _, err := q.UpdateTenantProfile(ctx, repository.UpdateTenantProfileParams{TenantID: tenant.TenantID, Profile: target.Profile}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyTenantConfigChange(ctx context.Context, q *repository.Queries, tenantID, name, op string, value *string, userID string) error {
+	if op == TenantSettingsChangeAdd {
+		_, err := q.CreateTenantConfig(ctx, repository.CreateTenantConfigParams{
+			UserID:   userID,
+			Name:     name,
+			Value:    util.ToNullableText(value),
+			TenantID: tenantID,
+		})
+		return err
+	}
+
+	existing, err := q.GetTenantConfigByName(ctx, repository.GetTenantConfigByNameParams{Name: name, TenantID: tenantID})
+	if err != nil {
+		return err
+	}
+	if op == TenantSettingsChangeRemove {
+		_, err = q.DeleteTenantConfig(ctx, repository.DeleteTenantConfigParams{ID: existing.ID, TenantID: tenantID})
+		return err
+	}
+	_, err = q.UpdateTenantConfig(ctx, repository.UpdateTenantConfigParams{
+		ID:       existing.ID,
+		Name:     name,
+		Value:    util.ToNullableText(value),
+		TenantID: tenantID,
+	})
+	return err
+}
+
+// planTenantSettings diffs the flattened settings and fingerprints the result
+func planTenantSettings(current, target TenantSettings) (TenantSettingsImportPlan, error) {
+	currentValues, err := flattenSettings(current)
+	if err != nil {
+		return TenantSettingsImportPlan{}, err
+	}
+	targetValues, err := flattenSettings(target)
+	if err != nil {
+		return TenantSettingsImportPlan{}, err
+	}
+
+	changes := []TenantSettingsChange{}
+	for path, newValue := range targetValues {
+		oldValue, exists := currentValues[path]
+		switch {
+		case !exists:
+			changes = append(changes, TenantSettingsChange{Path: path, Op: TenantSettingsChangeAdd, NewValue: newValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, TenantSettingsChange{Path: path, Op: TenantSettingsChangeUpdate, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for path, oldValue := range currentValues {
+		if _, exists := targetValues[path]; !exists {
+			changes = append(changes, TenantSettingsChange{Path: path, Op: TenantSettingsChangeRemove, OldValue: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return TenantSettingsImportPlan{}, err
+	}
+	sum := sha256.Sum256(raw)
+	return TenantSettingsImportPlan{Changes: changes, Fingerprint: hex.EncodeToString(sum[:])}, nil
+}
+
+// flattenSettings turns the JSON form of the settings into dotted paths. Config
+// entries are always leaves, even when their value is null.
+func flattenSettings(settings TenantSettings) (map[string]interface{}, error) {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	for key, value := range tree {
+		if key == "configs" {
+			configs, _ := value.(map[string]interface{})
+			for name, configValue := range configs {
+				values["configs."+name] = configValue
+			}
+			continue
+		}
+		flattenValue(key, value, values)
+	}
+	return values, nil
+}
+
+func flattenValue(path string, value interface{}, values map[string]interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		if value != nil {
+			values[path] = value
+		}
+		return
+	}
+	for key, child := range object {
+		flattenValue(path+"."+key, child, values)
+	}
+}
+
+// EncodeTenantSettingsYAML renders the document as YAML with the same keys as
+// its JSON form
+func EncodeTenantSettingsYAML(doc TenantSettingsDocument) ([]byte, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(tree)
+}
+
+// DecodeTenantSettingsDocument parses a JSON or YAML document. YAML is a
+// superset of JSON, so both go through the YAML decoder.
+func DecodeTenantSettingsDocument(data []byte) (TenantSettingsDocument, error) {
+	var doc TenantSettingsDocument
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return doc, err
+	}
+	raw, err := json.Marshal(tree)
+	if err != nil {
+		return doc, err
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
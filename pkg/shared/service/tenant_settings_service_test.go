@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"ctoup.com/coreapp/pkg/shared/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func settingValue(value string) *string {
+	return &value
+}
+
+func testTenantSettings() TenantSettings {
+	return TenantSettings{
+		AllowSignUp: true,
+		Features:    subentity.TenantFeatures{"chat": true, "export": false},
+		Profile:     subentity.TenantProfile{DisplayName: "Acme"},
+		Configs: map[string]*string{
+			"support_email": settingValue("help@acme.test"),
+			"legacy":        settingValue("on"),
+			"empty":         nil,
+		},
+	}
+}
+
+func TestPlanTenantSettingsDiff(t *testing.T) {
+	current := testTenantSettings()
+	target := testTenantSettings()
+	target.AllowPasswordSignUp = true
+	target.Features = subentity.TenantFeatures{"chat": false, "export": false, "search": true}
+	target.Profile.DisplayName = "Acme Corp"
+	target.Configs = map[string]*string{
+		"support_email": settingValue("support@acme.test"),
+		"empty":         nil,
+		"timezone":      settingValue("Europe/Paris"),
+	}
+
+	plan, err := planTenantSettings(current, target)
+	require.NoError(t, err)
+	require.False(t, plan.Applied)
+	require.Equal(t, []TenantSettingsChange{
+		{Path: "allowPasswordSignUp", Op: TenantSettingsChangeUpdate, OldValue: false, NewValue: true},
+		{Path: "configs.legacy", Op: TenantSettingsChangeRemove, OldValue: "on"},
+		{Path: "configs.support_email", Op: TenantSettingsChangeUpdate, OldValue: "help@acme.test", NewValue: "support@acme.test"},
+		{Path: "configs.timezone", Op: TenantSettingsChangeAdd, NewValue: "Europe/Paris"},
+		{Path: "features.chat", Op: TenantSettingsChangeUpdate, OldValue: true, NewValue: false},
+		{Path: "features.search", Op: TenantSettingsChangeAdd, NewValue: true},
+		{Path: "profile.displayName", Op: TenantSettingsChangeUpdate, OldValue: "Acme", NewValue: "Acme Corp"},
+	}, plan.Changes)
+
+	// The fingerprint only depends on the changes
+	again, err := planTenantSettings(current, target)
+	require.NoError(t, err)
+	require.Equal(t, plan.Fingerprint, again.Fingerprint)
+	target.Configs["timezone"] = settingValue("UTC")
+	other, err := planTenantSettings(current, target)
+	require.NoError(t, err)
+	require.NotEqual(t, plan.Fingerprint, other.Fingerprint)
+}
+
+func TestPlanTenantSettingsNoChanges(t *testing.T) {
+	plan, err := planTenantSettings(testTenantSettings(), testTenantSettings())
+	require.NoError(t, err)
+	require.Empty(t, plan.Changes)
+	require.NotEmpty(t, plan.Fingerprint)
+}
+
+func TestTenantSettingsDocumentYAMLRoundTrip(t *testing.T) {
+	doc := TenantSettingsDocument{
+		Version:        TenantSettingsDocumentVersion,
+		SourceTenantID: "staging-acme",
+		Settings:       testTenantSettings(),
+	}
+	raw, err := EncodeTenantSettingsYAML(doc)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "sourceTenantId: staging-acme")
+
+	decoded, err := DecodeTenantSettingsDocument(raw)
+	require.NoError(t, err)
+	plan, err := planTenantSettings(doc.Settings, decoded.Settings)
+	require.NoError(t, err)
+	require.Empty(t, plan.Changes)
+
+	_, err = DecodeTenantSettingsDocument([]byte(`{"version": 1, "settings": {"allowSignUp": true}}`))
+	require.NoError(t, err)
+	_, err = DecodeTenantSettingsDocument([]byte("version: [1"))
+	require.Error(t, err)
+}
+
+func TestApplyTenantSettingsImportConflicts(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewTenantSettingsService(store)
+	ctx := context.Background()
+
+	tenantID := commontestutils.RandomString(10)
+	_, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  tenantID,
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	_, err = store.CreateTenantConfig(ctx, repository.CreateTenantConfigParams{
+		UserID:   "admin-1",
+		Name:     "support_email",
+		Value:    util.ToNullableText(settingValue("help@acme.test")),
+		TenantID: tenantID,
+	})
+	require.NoError(t, err)
+	getTenant := func() repository.CoreTenant {
+		tenant, err := store.GetTenantByTenantID(ctx, tenantID)
+		require.NoError(t, err)
+		return tenant
+	}
+
+	export, err := service.ExportTenantSettings(ctx, getTenant())
+	require.NoError(t, err)
+	doc := export
+	doc.Settings.AllowSignUp = true
+	doc.Settings.Configs = map[string]*string{"support_email": settingValue("support@acme.test")}
+
+	t.Run("unsupported version", func(t *testing.T) {
+		stale := doc
+		stale.Version = TenantSettingsDocumentVersion + 1
+		_, err := service.PlanTenantSettingsImport(ctx, getTenant(), stale)
+		require.ErrorIs(t, err, ErrUnsupportedSettingsVersion)
+		_, err = service.ApplyTenantSettingsImport(ctx, getTenant(), stale, "", "admin-1")
+		require.ErrorIs(t, err, ErrUnsupportedSettingsVersion)
+	})
+
+	plan, err := service.PlanTenantSettingsImport(ctx, getTenant(), doc)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+
+	t.Run("tenant changed since review", func(t *testing.T) {
+		// Another admin edits a config between the review and the import
+		_, err := service.store.CreateTenantConfig(ctx, repository.CreateTenantConfigParams{
+			UserID:   "admin-2",
+			Name:     "timezone",
+			Value:    util.ToNullableText(settingValue("UTC")),
+			TenantID: tenantID,
+		})
+		require.NoError(t, err)
+
+		result, err := service.ApplyTenantSettingsImport(ctx, getTenant(), doc, plan.Fingerprint, "admin-1")
+		require.ErrorIs(t, err, ErrTenantSettingsDiffChanged)
+		require.False(t, result.Applied)
+		// The returned plan is the current diff, with the removal of timezone
+		require.Len(t, result.Changes, 3)
+		require.NotEqual(t, plan.Fingerprint, result.Fingerprint)
+
+		// Nothing was applied
+		require.False(t, getTenant().AllowSignUp)
+		config, err := store.GetTenantConfigByName(ctx, repository.GetTenantConfigByNameParams{Name: "support_email", TenantID: tenantID})
+		require.NoError(t, err)
+		require.Equal(t, "help@acme.test", config.Value.String)
+
+		plan = result
+	})
+
+	t.Run("reviewed diff applies", func(t *testing.T) {
+		result, err := service.ApplyTenantSettingsImport(ctx, getTenant(), doc, plan.Fingerprint, "admin-1")
+		require.NoError(t, err)
+		require.True(t, result.Applied)
+
+		require.True(t, getTenant().AllowSignUp)
+		config, err := store.GetTenantConfigByName(ctx, repository.GetTenantConfigByNameParams{Name: "support_email", TenantID: tenantID})
+		require.NoError(t, err)
+		require.Equal(t, "support@acme.test", config.Value.String)
+		_, err = store.GetTenantConfigByName(ctx, repository.GetTenantConfigByNameParams{Name: "timezone", TenantID: tenantID})
+		require.Error(t, err)
+	})
+
+	t.Run("replayed fingerprint is rejected", func(t *testing.T) {
+		_, err := service.ApplyTenantSettingsImport(ctx, getTenant(), doc, plan.Fingerprint, "admin-1")
+		require.ErrorIs(t, err, ErrTenantSettingsDiffChanged)
+
+		current, err := service.PlanTenantSettingsImport(ctx, getTenant(), doc)
+		require.NoError(t, err)
+		require.Empty(t, current.Changes)
+	})
+}
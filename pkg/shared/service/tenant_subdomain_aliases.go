@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrInvalidSubdomain is returned for a subdomain that is not a DNS label
+	// or is reserved for the admin and auth sites
+	ErrInvalidSubdomain = errors.New("subdomain must be a lowercase DNS label and not a reserved name")
+	// ErrSubdomainTaken is returned when the subdomain already belongs to a
+	// tenant, as primary subdomain or alias
+	ErrSubdomainTaken = errors.New("subdomain already taken")
+)
+
+var subdomainLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// TenantSubdomain is a subdomain serving a tenant. The primary one is the
+// subdomain of the tenant; aliases redirect browsers to it.
+type TenantSubdomain struct {
+	Subdomain string
+	Primary   bool
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// NormalizeSubdomain lowercases the subdomain and checks it can be used by a
+// tenant
+func NormalizeSubdomain(subdomain string) (string, error) {
+	subdomain = strings.ToLower(strings.TrimSpace(subdomain))
+	if !subdomainLabel.MatchString(subdomain) || util.IsAdminSubdomain(subdomain) || subdomain == "auth" {
+		return "", ErrInvalidSubdomain
+	}
+	return subdomain, nil
+}
+
+// ListTenantSubdomains returns the primary subdomain of the tenant followed by
+// its aliases
+func (uh *MultitenantService) ListTenantSubdomains(ctx context.Context, tenant repository.CoreTenant) ([]TenantSubdomain, error) {
+	aliases, err := uh.store.ListTenantSubdomainAliases(ctx, tenant.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListTenantSubdomains: %w", err)
+	}
+	subdomains := make([]TenantSubdomain, 0, len(aliases)+1)
+	subdomains = append(subdomains, TenantSubdomain{
+		Subdomain: tenant.Subdomain,
+		Primary:   true,
+		CreatedBy: tenant.UserID,
+		CreatedAt: tenant.CreatedAt,
+	})
+	for _, alias := range aliases {
+		subdomains = append(subdomains, toTenantSubdomain(alias))
+	}
+	return subdomains, nil
+}
+
+func toTenantSubdomain(alias repository.CoreTenantSubdomainAlias) TenantSubdomain {
+	return TenantSubdomain{
+		Subdomain: alias.Subdomain,
+		CreatedBy: alias.CreatedBy,
+		CreatedAt: alias.CreatedAt,
+	}
+}
+
+// AddTenantSubdomainAlias adds an alias to the tenant. With primary set, the
+// alias becomes the primary subdomain and the current one an alias.
+func (uh *MultitenantService) AddTenantSubdomainAlias(ctx context.Context, tenant repository.CoreTenant,
+	subdomain string, primary bool, createdBy string) (TenantSubdomain, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	subdomain, err := NormalizeSubdomain(subdomain)
+	if err != nil {
+		return TenantSubdomain{}, err
+	}
+	alias, err := uh.store.CreateTenantSubdomainAlias(ctx, repository.CreateTenantSubdomainAliasParams{
+		TenantID:  tenant.TenantID,
+		Subdomain: subdomain,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation) {
+			return TenantSubdomain{}, ErrSubdomainTaken
+		}
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Str("subdomain", subdomain).Msg("Failed to add subdomain alias")
+		return TenantSubdomain{}, fmt.Errorf("service.AddTenantSubdomainAlias: %w", err)
+	}
+	logger.Info().Str("tenant_id", tenant.TenantID).Str("subdomain", subdomain).Msg("Subdomain alias added")
+	if primary {
+		return uh.SetPrimaryTenantSubdomain(ctx, tenant, subdomain, createdBy)
+	}
+	return toTenantSubdomain(alias), nil
+}
+
+// RemoveTenantSubdomainAlias removes an alias of the tenant. The primary
+// subdomain cannot be removed, only replaced.
+func (uh *MultitenantService) RemoveTenantSubdomainAlias(ctx context.Context, tenant repository.CoreTenant, subdomain string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	deleted, err := uh.store.DeleteTenantSubdomainAlias(ctx, repository.DeleteTenantSubdomainAliasParams{
+		TenantID:  tenant.TenantID,
+		Subdomain: strings.ToLower(subdomain),
+	})
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenant.TenantID).Str("subdomain", subdomain).Msg("Failed to remove subdomain alias")
+		return fmt.Errorf("service.RemoveTenantSubdomainAlias: %w", err)
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	GetTenantMap().forget(strings.ToLower(subdomain))
+	logger.Info().Str("tenant_id", tenant.TenantID).Str("subdomain", subdomain).Msg("Subdomain alias removed")
+	return nil
+}
+
+// SetPrimaryTenantSubdomain makes an alias the primary subdomain of the
+// tenant, in one transaction; the former primary subdomain becomes an alias
+// so existing links keep working
+func (uh *MultitenantService) SetPrimaryTenantSubdomain(ctx context.Context, tenant repository.CoreTenant,
+	subdomain string, updatedBy string) (TenantSubdomain, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	subdomain = strings.ToLower(subdomain)
+	if subdomain == tenant.Subdomain {
+		return TenantSubdomain{Subdomain: subdomain, Primary: true, CreatedBy: tenant.UserID, CreatedAt: tenant.CreatedAt}, nil
+	}
+
+	tx, err := uh.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return TenantSubdomain{}, fmt.Errorf("service.SetPrimaryTenantSubdomain: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := uh.store.Queries.WithTx(tx)
+
+	alias, err := qtx.GetTenantSubdomainAlias(ctx, subdomain)
+	if err != nil {
+		return TenantSubdomain{}, err
+	}
+	if alias.TenantID != tenant.TenantID {
+		return TenantSubdomain{}, pgx.ErrNoRows
+	}
+	if _, err := qtx.DeleteTenantSubdomainAlias(ctx, repository.DeleteTenantSubdomainAliasParams{
+		TenantID:  tenant.TenantID,
+		Subdomain: subdomain,
+	}); err != nil {
+		return TenantSubdomain{}, fmt.Errorf("service.SetPrimaryTenantSubdomain: %w", err)
+	}
+	if err := qtx.UpdateTenantSubdomain(ctx, repository.UpdateTenantSubdomainParams{
+		TenantID:  tenant.TenantID,
+		Subdomain: subdomain,
+	}); err != nil {
+		return TenantSubdomain{}, fmt.Errorf("service.SetPrimaryTenantSubdomain: %w", err)
+	}
+	if _, err := qtx.CreateTenantSubdomainAlias(ctx, repository.CreateTenantSubdomainAliasParams{
+		TenantID:  tenant.TenantID,
+		Subdomain: tenant.Subdomain,
+		CreatedBy: updatedBy,
+	}); err != nil {
+		return TenantSubdomain{}, fmt.Errorf("service.SetPrimaryTenantSubdomain: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return TenantSubdomain{}, fmt.Errorf("service.SetPrimaryTenantSubdomain: %w", err)
+	}
+
+	tenantMap := GetTenantMap()
+	tenantMap.forget(subdomain)
+	tenantMap.forget(tenant.Subdomain)
+	uh.InvalidateTenant(tenant.TenantID)
+	logger.Info().Str("tenant_id", tenant.TenantID).Str("subdomain", subdomain).Str("previous", tenant.Subdomain).Msg("Primary subdomain changed")
+	return TenantSubdomain{Subdomain: subdomain, Primary: true, CreatedBy: alias.CreatedBy, CreatedAt: alias.CreatedAt}, nil
+}
+
+// EnsureSubdomainNotAlias returns ErrSubdomainTaken when the subdomain is an
+// alias of a tenant. An alias becomes a primary subdomain only through
+// SetPrimaryTenantSubdomain.
+func (uh *MultitenantService) EnsureSubdomainNotAlias(ctx context.Context, subdomain string) error {
+	_, err := uh.store.GetTenantSubdomainAlias(ctx, strings.ToLower(subdomain))
+	switch {
+	case err == nil:
+		return ErrSubdomainTaken
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil
+	}
+	return fmt.Errorf("service.EnsureSubdomainNotAlias: %w", err)
+}
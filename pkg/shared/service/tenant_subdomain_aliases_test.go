@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantSubdomainAliases(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewMultitenantService(store)
+	ctx := context.Background()
+	createdBy := commontestutils.RandomString(10)
+
+	newTenant := func() repository.CoreTenant {
+		tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+			UserID:    createdBy,
+			TenantID:  commontestutils.RandomString(10),
+			Name:      commontestutils.RandomString(10),
+			Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+		})
+		require.NoError(t, err)
+		return tenant
+	}
+	tenant := newTenant()
+	other := newTenant()
+	alias := strings.ToLower(commontestutils.RandomString(10))
+
+	_, err := service.AddTenantSubdomainAlias(ctx, tenant, alias, false, createdBy)
+	require.NoError(t, err)
+
+	t.Run("an alias resolves to its tenant", func(t *testing.T) {
+		resolved, err := service.GetTenantBySubdomainCached(ctx, alias)
+		require.NoError(t, err)
+		require.Equal(t, tenant.TenantID, resolved.TenantID)
+		require.Equal(t, tenant.Subdomain, resolved.Subdomain)
+
+		tenantID, err := service.GetTenantIDWithSubdomain(ctx, alias)
+		require.NoError(t, err)
+		require.Equal(t, tenant.TenantID, tenantID)
+	})
+
+	t.Run("subdomains of another tenant are rejected", func(t *testing.T) {
+		_, err := service.AddTenantSubdomainAlias(ctx, tenant, other.Subdomain, false, createdBy)
+		require.ErrorIs(t, err, ErrSubdomainTaken)
+		_, err = service.AddTenantSubdomainAlias(ctx, other, alias, false, createdBy)
+		require.ErrorIs(t, err, ErrSubdomainTaken)
+		_, err = service.AddTenantSubdomainAlias(ctx, other, "admin", false, createdBy)
+		require.ErrorIs(t, err, ErrInvalidSubdomain)
+		require.ErrorIs(t, service.EnsureSubdomainNotAlias(ctx, alias), ErrSubdomainTaken)
+	})
+
+	t.Run("making an alias primary keeps the former subdomain as alias", func(t *testing.T) {
+		primary, err := service.SetPrimaryTenantSubdomain(ctx, tenant, alias, createdBy)
+		require.NoError(t, err)
+		require.True(t, primary.Primary)
+
+		subdomains, err := service.ListTenantSubdomains(ctx, repository.CoreTenant{
+			TenantID:  tenant.TenantID,
+			Subdomain: alias,
+		})
+		require.NoError(t, err)
+		require.Len(t, subdomains, 2)
+		require.Equal(t, alias, subdomains[0].Subdomain)
+		require.Equal(t, tenant.Subdomain, subdomains[1].Subdomain)
+		require.False(t, subdomains[1].Primary)
+
+		resolved, err := service.GetTenantBySubdomainCached(ctx, tenant.Subdomain)
+		require.NoError(t, err)
+		require.Equal(t, alias, resolved.Subdomain)
+	})
+
+	t.Run("a removed alias no longer resolves", func(t *testing.T) {
+		current, err := store.GetTenantByTenantID(ctx, tenant.TenantID)
+		require.NoError(t, err)
+		require.NoError(t, service.RemoveTenantSubdomainAlias(ctx, current, tenant.Subdomain))
+
+		_, err = service.GetTenantBySubdomainCached(ctx, tenant.Subdomain)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+		require.ErrorIs(t, service.RemoveTenantSubdomainAlias(ctx, current, tenant.Subdomain), pgx.ErrNoRows)
+	})
+}
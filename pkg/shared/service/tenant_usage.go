@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Metered usage of a tenant, counted per day
+const (
+	UsageMetricAPICalls         = "api_calls"
+	UsageMetricLLMTokens        = "llm_tokens"
+	UsageMetricUsersAdded       = "users_added"
+	UsageMetricAPITokensCreated = "api_tokens_created"
+)
+
+// UsageMetrics lists the metered metrics, in the order of the reports
+var UsageMetrics = []string{
+	UsageMetricAPICalls,
+	UsageMetricLLMTokens,
+	UsageMetricUsersAdded,
+	UsageMetricAPITokensCreated,
+}
+
+const (
+	DefaultUsageMeteringFlushInterval = time.Minute
+	DefaultUsageMeteringRetention     = 400 * 24 * time.Hour
+	// MaxTenantUsageRange bounds the range of a usage report
+	MaxTenantUsageRange = 366 * 24 * time.Hour
+)
+
+// ErrInvalidTenantUsageRange is returned for a report range that is empty or
+// longer than MaxTenantUsageRange
+var ErrInvalidTenantUsageRange = errors.New("the range must end after it starts and span at most 366 days")
+
+// UsageMeteringConfig configures the persisted usage counts.
+//
+// Environment:
+//   - USAGE_METERING_FLUSH_INTERVAL: how often the counts are written (default 1m)
+//   - USAGE_METERING_RETENTION: how long the daily counts are kept (default 9600h)
+type UsageMeteringConfig struct {
+	FlushInterval time.Duration
+	Retention     time.Duration
+}
+
+func UsageMeteringConfigFromEnv() UsageMeteringConfig {
+	cfg := UsageMeteringConfig{
+		FlushInterval: DefaultUsageMeteringFlushInterval,
+		Retention:     DefaultUsageMeteringRetention,
+	}
+	if v := os.Getenv("USAGE_METERING_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.FlushInterval = d
+		} else {
+			log.Warn().Str("USAGE_METERING_FLUSH_INTERVAL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("USAGE_METERING_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Retention = d
+		} else {
+			log.Warn().Str("USAGE_METERING_RETENTION", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+type usageKey struct {
+	tenantID string
+	bucket   time.Time
+	metric   string
+}
+
+// UsageMeteringService counts the usage of each tenant. Handlers and modules
+// emit events with RecordUsage; the counts are aggregated in memory and
+// written every flush interval, one upsert per tenant, day and metric.
+type UsageMeteringService struct {
+	store *db.Store
+
+	mu      sync.Mutex
+	pending map[usageKey]int64
+}
+
+func NewUsageMeteringService(store *db.Store) *UsageMeteringService {
+	return &UsageMeteringService{store: store, pending: map[usageKey]int64{}}
+}
+
+var (
+	usageMeteringMu sync.RWMutex
+	usageMetering   *UsageMeteringService
+)
+
+// Start makes s the recorder of the usage and writes the counts every
+// interval until ctx is done, then a last time
+func (s *UsageMeteringService) Start(ctx context.Context, cfg UsageMeteringConfig) {
+	usageMeteringMu.Lock()
+	usageMetering = s
+	usageMeteringMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(cfg.FlushInterval)
+		defer ticker.Stop()
+		var lastPurge time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				s.Flush(context.Background())
+				return
+			case <-ticker.C:
+			}
+			s.Flush(ctx)
+			if time.Since(lastPurge) >= time.Hour {
+				lastPurge = time.Now()
+				if _, err := s.store.DeleteTenantUsageCountsBefore(ctx, time.Now().Add(-cfg.Retention)); err != nil {
+					log.Err(err).Msg("Failed to purge tenant usage counts")
+				}
+			}
+		}
+	}()
+}
+
+// RecordUsage adds quantity to a metric of the tenant, once a
+// UsageMeteringService is started. Usage outside a tenant is not metered.
+func RecordUsage(ctx context.Context, tenantID, metric string, quantity int64) {
+	if tenantID == "" || quantity <= 0 {
+		return
+	}
+	usageMeteringMu.RLock()
+	s := usageMetering
+	usageMeteringMu.RUnlock()
+	if s != nil {
+		s.add(tenantID, metric, quantity, time.Now())
+	}
+}
+
+// UsageMeteringMiddleware counts the API calls of the request tenant. It runs
+// after the authentication, so rejected requests are not counted.
+func UsageMeteringMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		RecordUsage(c, c.GetString(auth.AUTH_TENANT_ID_KEY), UsageMetricAPICalls, 1)
+		c.Next()
+	}
+}
+
+func (s *UsageMeteringService) add(tenantID, metric string, quantity int64, at time.Time) {
+	key := usageKey{tenantID: tenantID, bucket: at.UTC().Truncate(24 * time.Hour), metric: metric}
+	s.mu.Lock()
+	s.pending[key] += quantity
+	s.mu.Unlock()
+}
+
+// Flush writes the pending counts. On failure they are merged back and
+// retried with the next flush.
+func (s *UsageMeteringService) Flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]int64{}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	params := repository.IncrementTenantUsageCountsParams{}
+	for key, count := range pending {
+		params.TenantIds = append(params.TenantIds, key.tenantID)
+		params.Buckets = append(params.Buckets, key.bucket)
+		params.Metrics = append(params.Metrics, key.metric)
+		params.Counts = append(params.Counts, count)
+	}
+	if err := s.store.IncrementTenantUsageCounts(ctx, params); err != nil {
+		log.Err(err).Int("counts", len(pending)).Msg("Failed to write tenant usage counts")
+		s.mu.Lock()
+		for key, count := range pending {
+			s.pending[key] += count
+		}
+		s.mu.Unlock()
+	}
+}
+
+// TenantUsage is the usage of a tenant over a range: the metered counts by
+// metric and by day, and the current users, active API tokens and storage
+type TenantUsage struct {
+	TenantID     string
+	From         time.Time
+	To           time.Time
+	Users        int64
+	APITokens    int64
+	StorageBytes int64
+	Totals       map[string]int64
+	Daily        []repository.CoreTenantUsageCount
+}
+
+// usageRange checks the range of a report and widens it to whole days
+func usageRange(from, to time.Time) (time.Time, time.Time, error) {
+	if !to.After(from) || to.Sub(from) > MaxTenantUsageRange {
+		return time.Time{}, time.Time{}, ErrInvalidTenantUsageRange
+	}
+	return from.UTC().Truncate(24 * time.Hour), to, nil
+}
+
+func newUsageTotals() map[string]int64 {
+	totals := make(map[string]int64, len(UsageMetrics))
+	for _, metric := range UsageMetrics {
+		totals[metric] = 0
+	}
+	return totals
+}
+
+// GetTenantUsage reports the usage of a tenant; the events of the last flush
+// interval are not included yet
+func (s *UsageMeteringService) GetTenantUsage(ctx context.Context, tenantID string, from, to time.Time) (TenantUsage, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	bucketFrom, bucketTo, err := usageRange(from, to)
+	if err != nil {
+		return TenantUsage{}, err
+	}
+	counts, err := s.store.ListTenantUsageCounts(ctx, repository.ListTenantUsageCountsParams{
+		TenantID: tenantID,
+		From:     bucketFrom,
+		To:       bucketTo,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to list tenant usage counts")
+		return TenantUsage{}, fmt.Errorf("service.GetTenantUsage: %w", err)
+	}
+	gauges, err := s.store.GetTenantUsageGauges(ctx, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to get tenant usage gauges")
+		return TenantUsage{}, fmt.Errorf("service.GetTenantUsage: %w", err)
+	}
+	usage := TenantUsage{
+		TenantID:     tenantID,
+		From:         from,
+		To:           to,
+		Users:        gauges.Users,
+		APITokens:    gauges.ApiTokens,
+		StorageBytes: gauges.StorageBytes,
+		Totals:       newUsageTotals(),
+		Daily:        counts,
+	}
+	for _, count := range counts {
+		usage.Totals[count.Metric] += count.Count
+	}
+	return usage, nil
+}
+
+// TenantUsageTotals are the metered counts of a tenant over a range
+type TenantUsageTotals struct {
+	TenantID string
+	Totals   map[string]int64
+}
+
+// ListTenantUsageTotals reports the metered counts of every tenant with usage
+// over the range, ordered by tenant
+func (s *UsageMeteringService) ListTenantUsageTotals(ctx context.Context, from, to time.Time) ([]TenantUsageTotals, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	bucketFrom, bucketTo, err := usageRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.SumTenantUsageCounts(ctx, repository.SumTenantUsageCountsParams{From: bucketFrom, To: bucketTo})
+	if err != nil {
+		logger.Err(err).Msg("Failed to sum tenant usage counts")
+		return nil, fmt.Errorf("service.ListTenantUsageTotals: %w", err)
+	}
+	result := []TenantUsageTotals{}
+	for _, row := range rows {
+		if len(result) == 0 || result[len(result)-1].TenantID != row.TenantID {
+			result = append(result, TenantUsageTotals{TenantID: row.TenantID, Totals: newUsageTotals()})
+		}
+		result[len(result)-1].Totals[row.Metric] += row.Count
+	}
+	return result, nil
+}
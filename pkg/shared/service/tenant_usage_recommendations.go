@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/emailservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"ctoup.com/coreapp/pkg/shared/webhook"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Severities of a usage recommendation
+const (
+	UsageRecommendationLimitReached     = "limit_reached"
+	UsageRecommendationLimitApproaching = "limit_approaching"
+)
+
+const (
+	DefaultUsageRecommendationHorizon = 30 * 24 * time.Hour
+	// UsageTrendWindow is the metered usage the growth of a resource is
+	// measured over
+	UsageTrendWindow = 30 * 24 * time.Hour
+	// usageNearLimitRatio reports the resources without trend from this share
+	// of their limit
+	usageNearLimitRatio = 0.9
+	// The suggested limit covers the projected usage of the suggestion
+	// period, with some headroom
+	usageSuggestionPeriod   = 90 * 24 * time.Hour
+	usageSuggestionHeadroom = 1.2
+	// usageNotificationRepeat is how long before a recommendation still
+	// standing is notified again
+	usageNotificationRepeat       = 7 * 24 * time.Hour
+	usageNotificationBatchSize    = 100
+	usageNotificationTimeout      = 30 * time.Second
+	TenantUsageLimitWebhookEvent  = "tenant_usage.limit"
+	tenantUsageLimitEmailTemplate = "email-usage-limit.html"
+	tenantUsageLimitEmailSubject  = "Your usage is reaching a limit"
+)
+
+// usageResourceUnits names the units of the resources in the messages
+var usageResourceUnits = map[string]string{
+	QuotaResourceUsers:              "users",
+	QuotaResourceClientApplications: "client applications",
+	QuotaResourceStorageMB:          "MB of storage",
+	QuotaResourcePromptExecutions:   "prompt executions",
+}
+
+// UsageRecommendationConfig configures the recommendations and their
+// notifications.
+//
+// Environment:
+//   - USAGE_RECOMMENDATION_HORIZON: report the limits projected to be reached
+//     within it (default 720h)
+//   - USAGE_RECOMMENDATION_CHECK_INTERVAL: notification scan interval
+//     (default 0, notifications disabled)
+//   - USAGE_RECOMMENDATION_WEBHOOK_URL / USAGE_RECOMMENDATION_WEBHOOK_SECRET: optional webhook target
+type UsageRecommendationConfig struct {
+	Horizon       time.Duration
+	Interval      time.Duration
+	WebhookURL    string
+	WebhookSecret string
+}
+
+func UsageRecommendationConfigFromEnv() UsageRecommendationConfig {
+	cfg := UsageRecommendationConfig{
+		Horizon:       DefaultUsageRecommendationHorizon,
+		WebhookURL:    os.Getenv("USAGE_RECOMMENDATION_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("USAGE_RECOMMENDATION_WEBHOOK_SECRET"),
+	}
+	if v := os.Getenv("USAGE_RECOMMENDATION_HORIZON"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Horizon = d
+		} else {
+			log.Warn().Str("USAGE_RECOMMENDATION_HORIZON", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("USAGE_RECOMMENDATION_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			log.Warn().Str("USAGE_RECOMMENDATION_CHECK_INTERVAL", v).Msg("Invalid value, notifications disabled")
+		}
+	}
+	return cfg
+}
+
+// TenantUsageRecommendation is a limit of the tenant reached, or projected
+// to be reached within the horizon, with the limit that would cover its
+// usage
+type TenantUsageRecommendation struct {
+	Resource string
+	Severity string
+	Used     int64
+	Limit    int32
+	// DailyRate is the growth of the resource per day, 0 when it has no trend
+	DailyRate float64
+	// LimitReachedAt is when the limit is projected to be reached, nil when
+	// it is reached already
+	LimitReachedAt *time.Time
+	SuggestedLimit int64
+	Message        string
+}
+
+// TenantUsageRecommendations are the recommendations of a tenant, the
+// reached limits first then the soonest reached
+type TenantUsageRecommendations struct {
+	TenantID        string
+	GeneratedAt     time.Time
+	Recommendations []TenantUsageRecommendation
+}
+
+// TenantUsageNotification is the email data and webhook payload of a
+// recommendation
+type TenantUsageNotification struct {
+	TenantID       string     `json:"tenantId"`
+	Resource       string     `json:"resource"`
+	Severity       string     `json:"severity"`
+	Used           int64      `json:"used"`
+	Limit          int32      `json:"limit"`
+	LimitReachedAt *time.Time `json:"limitReachedAt,omitempty"`
+	SuggestedLimit int64      `json:"suggestedLimit"`
+	Message        string     `json:"message"`
+}
+
+// UsageRecommendationService projects the usage of the tenants against their
+// quotas. The users grow at the rate they were added over the trend window,
+// the prompt executions at their rate of the current month; the client
+// applications and the storage have no metered trend and are reported from
+// 90% of their limit.
+type UsageRecommendationService struct {
+	store  *db.Store
+	quotas *TenantQuotaService
+	cfg    UsageRecommendationConfig
+}
+
+func NewUsageRecommendationService(store *db.Store, cfg UsageRecommendationConfig) *UsageRecommendationService {
+	return &UsageRecommendationService{store: store, quotas: NewTenantQuotaService(store, nil), cfg: cfg}
+}
+
+// GetRecommendations returns the recommendations of the tenant, none when it
+// has no quota
+func (s *UsageRecommendationService) GetRecommendations(ctx context.Context, tenantID string) (TenantUsageRecommendations, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	now := time.Now().UTC()
+	resources, err := s.quotas.GetUsage(ctx, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to get tenant quota usage")
+		return TenantUsageRecommendations{}, fmt.Errorf("service.GetRecommendations: %w", err)
+	}
+	from := now.Add(-UsageTrendWindow).Truncate(24 * time.Hour)
+	counts, err := s.store.ListTenantUsageCounts(ctx, repository.ListTenantUsageCountsParams{
+		TenantID: tenantID,
+		From:     from,
+		To:       now,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to list tenant usage counts")
+		return TenantUsageRecommendations{}, fmt.Errorf("service.GetRecommendations: %w", err)
+	}
+	var usersAdded int64
+	for _, count := range counts {
+		if count.Metric == UsageMetricUsersAdded {
+			usersAdded += count.Count
+		}
+	}
+	usersPerDay := float64(usersAdded) / (float64(now.Sub(from)) / float64(24*time.Hour))
+	return TenantUsageRecommendations{
+		TenantID:        tenantID,
+		GeneratedAt:     now,
+		Recommendations: recommendTenantUsage(resources, usersPerDay, now, s.cfg.Horizon),
+	}, nil
+}
+
+// recommendTenantUsage projects the resources limited by the quota. A zero
+// limit disables a resource on purpose and is not reported.
+func recommendTenantUsage(resources []TenantQuotaResource, usersPerDay float64, now time.Time, horizon time.Duration) []TenantUsageRecommendation {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	recommendations := []TenantUsageRecommendation{}
+	for _, r := range resources {
+		if r.Limit == nil || *r.Limit == 0 {
+			continue
+		}
+		limit := int64(*r.Limit)
+		rec := TenantUsageRecommendation{Resource: r.Resource, Used: r.Used, Limit: *r.Limit}
+		unit := usageResourceUnits[r.Resource]
+
+		// The usage projected over the suggestion period, and the end of the
+		// window the limit applies to
+		projected := float64(r.Used)
+		until := now.Add(horizon)
+		switch r.Resource {
+		case QuotaResourceUsers:
+			rec.DailyRate = usersPerDay
+			projected += usersPerDay * usageSuggestionPeriod.Hours() / 24
+		case QuotaResourcePromptExecutions:
+			elapsed := max(now.Sub(monthStart).Hours()/24, 1)
+			rec.DailyRate = float64(r.Used) / elapsed
+			projected = rec.DailyRate * monthEnd.Sub(monthStart).Hours() / 24
+			if monthEnd.Before(until) {
+				until = monthEnd
+			}
+		}
+		rec.SuggestedLimit = max(int64(math.Ceil(projected*usageSuggestionHeadroom)), limit+1)
+
+		switch {
+		case r.Exceeded():
+			rec.Severity = UsageRecommendationLimitReached
+			rec.Message = fmt.Sprintf("The tenant has reached its limit of %d %s", limit, unit)
+		case rec.DailyRate > 0:
+			days := float64(limit-r.Used) / rec.DailyRate
+			reachedAt := now.Add(time.Duration(days * float64(24*time.Hour)))
+			if !reachedAt.Before(until) {
+				continue
+			}
+			rec.Severity = UsageRecommendationLimitApproaching
+			rec.LimitReachedAt = &reachedAt
+			rec.Message = fmt.Sprintf("At the current rate of %.1f %s a day, the tenant will reach its limit of %d %s in %s",
+				rec.DailyRate, unit, limit, unit, usageDays(days))
+		case float64(r.Used) >= usageNearLimitRatio*float64(limit):
+			rec.Severity = UsageRecommendationLimitApproaching
+			rec.Message = fmt.Sprintf("The tenant uses %d of its %d %s", r.Used, limit, unit)
+		default:
+			continue
+		}
+		recommendations = append(recommendations, rec)
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Severity != b.Severity {
+			return a.Severity == UsageRecommendationLimitReached
+		}
+		if a.LimitReachedAt == nil || b.LimitReachedAt == nil {
+			return a.LimitReachedAt != nil
+		}
+		return a.LimitReachedAt.Before(*b.LimitReachedAt)
+	})
+	return recommendations
+}
+
+// usageDays formats a number of days, rounded up
+func usageDays(days float64) string {
+	n := int(math.Ceil(days))
+	if n <= 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// StartNotifications runs NotifyRecommendations now and then every interval
+// until ctx is done. It does nothing when the interval is 0.
+func (s *UsageRecommendationService) StartNotifications(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Info().Msg("Usage recommendation notifications disabled")
+		return
+	}
+	dispatcher := webhook.NewDispatcher()
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.NotifyRecommendations(ctx, dispatcher); err != nil {
+				log.Err(err).Msg("Usage recommendation scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// NotifyRecommendations notifies the customer admins of every tenant with a
+// quota, and the configured webhook, of its recommendations. A
+// recommendation is claimed before notifying, so it is notified once even
+// with several instances running, and again after a week when it still
+// stands. It returns the number of recommendations notified.
+func (s *UsageRecommendationService) NotifyRecommendations(ctx context.Context, dispatcher *webhook.Dispatcher) (int, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	notified := 0
+	after := ""
+	for {
+		quotas, err := s.store.ListTenantQuotas(ctx, repository.ListTenantQuotasParams{
+			AfterTenantID: after,
+			BatchSize:     usageNotificationBatchSize,
+		})
+		if err != nil {
+			logger.Err(err).Msg("Failed to list tenant quotas")
+			return notified, fmt.Errorf("service.NotifyRecommendations: %w", err)
+		}
+		for _, quota := range quotas {
+			recommendations, err := s.GetRecommendations(ctx, quota.TenantID)
+			if err != nil {
+				return notified, fmt.Errorf("service.NotifyRecommendations: %w", err)
+			}
+			for _, rec := range recommendations.Recommendations {
+				claimed, err := s.notify(ctx, dispatcher, quota.TenantID, rec)
+				if err != nil {
+					return notified, fmt.Errorf("service.NotifyRecommendations: %w", err)
+				}
+				if claimed {
+					notified++
+				}
+			}
+		}
+		if len(quotas) < usageNotificationBatchSize {
+			break
+		}
+		after = quotas[len(quotas)-1].TenantID
+	}
+	if notified > 0 {
+		logger.Info().Int("recommendations", notified).Msg("Notified usage recommendations")
+	}
+	return notified, nil
+}
+
+// notify claims then notifies the recommendation. It reports false when it
+// was notified recently.
+func (s *UsageRecommendationService) notify(ctx context.Context, dispatcher *webhook.Dispatcher, tenantID string, rec TenantUsageRecommendation) (bool, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	_, err := s.store.ClaimTenantUsageNotification(ctx, repository.ClaimTenantUsageNotificationParams{
+		TenantID:       tenantID,
+		Resource:       rec.Resource,
+		Severity:       rec.Severity,
+		NotifiedBefore: time.Now().Add(-usageNotificationRepeat),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		logger.Err(err).Str("tenantID", tenantID).Str("resource", rec.Resource).Msg("Failed to claim usage notification")
+		return false, err
+	}
+
+	notification := TenantUsageNotification{
+		TenantID:       tenantID,
+		Resource:       rec.Resource,
+		Severity:       rec.Severity,
+		Used:           rec.Used,
+		Limit:          rec.Limit,
+		LimitReachedAt: rec.LimitReachedAt,
+		SuggestedLimit: rec.SuggestedLimit,
+		Message:        rec.Message,
+	}
+	notifyCtx, cancel := context.WithTimeout(ctx, usageNotificationTimeout)
+	defer cancel()
+
+	// The claim stays recorded when notifying fails, the recommendation is
+	// still answered by the endpoints
+	emails, err := s.store.ListTenantAdminEmails(notifyCtx, tenantID)
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to list tenant admins for usage notification")
+	}
+	recipients := make([]string, 0, len(emails))
+	for _, email := range emails {
+		if plain := DecryptEmail(email); plain.Valid && plain.String != "" {
+			recipients = append(recipients, plain.String)
+		}
+	}
+	if len(recipients) > 0 {
+		if err := sendTenantUsageLimitEmail(recipients, notification, GetTenantAssets(ctx, s.store.Queries, tenantID)); err != nil {
+			logger.Err(err).Str("tenantID", tenantID).Str("resource", rec.Resource).Msg("Failed to send usage notification email")
+		}
+	}
+	if s.cfg.WebhookURL != "" && dispatcher != nil {
+		envelope := webhook.Envelope{
+			ID:        uuid.New().String(),
+			EventType: TenantUsageLimitWebhookEvent,
+			CreatedAt: time.Now().UTC(),
+			Data:      notification,
+		}
+		target := webhook.Target{URL: s.cfg.WebhookURL, Secret: s.cfg.WebhookSecret}
+		if err := dispatcher.Deliver(notifyCtx, target, envelope, ""); err != nil {
+			logger.Err(err).Str("tenantID", tenantID).Str("resource", rec.Resource).Msg("Failed to deliver usage notification webhook")
+		}
+	}
+	return true, nil
+}
+
+func sendTenantUsageLimitEmail(toEmails []string, notification TenantUsageNotification, assets TenantAssets) error {
+	fromEmail := os.Getenv("SYSTEM_EMAIL")
+	if fromEmail == "" {
+		fromEmail = "noreply@ctoup.com"
+	}
+	r := emailservice.NewEmailRequest(fromEmail, toEmails, tenantUsageLimitEmailSubject, "")
+	r.Assets = assets.Email()
+	r.TenantID = notification.TenantID
+	if err := r.ParseTemplate(filepath.Join("templates", tenantUsageLimitEmailTemplate), notification); err != nil {
+		return err
+	}
+	return r.SendEmail()
+}
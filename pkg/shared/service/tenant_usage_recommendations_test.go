@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func quotaLimit(limit int32) *int32 {
+	return &limit
+}
+
+func TestRecommendTenantUsage(t *testing.T) {
+	// Ten days into a 31-day month
+	now := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	resources := []TenantQuotaResource{
+		{Resource: QuotaResourceUsers, Used: 85, Limit: quotaLimit(100)},
+		{Resource: QuotaResourceClientApplications, Used: 5, Limit: quotaLimit(5)},
+		{Resource: QuotaResourceStorageMB, Used: 95, Limit: quotaLimit(100)},
+		{Resource: QuotaResourcePromptExecutions, Used: 500, Limit: quotaLimit(1000)},
+	}
+
+	recommendations := recommendTenantUsage(resources, 1, now, DefaultUsageRecommendationHorizon)
+	require.Len(t, recommendations, 4)
+
+	// The reached limits first
+	apps := recommendations[0]
+	require.Equal(t, QuotaResourceClientApplications, apps.Resource)
+	require.Equal(t, UsageRecommendationLimitReached, apps.Severity)
+	require.Nil(t, apps.LimitReachedAt)
+	require.Equal(t, int64(6), apps.SuggestedLimit)
+	require.Equal(t, "The tenant has reached its limit of 5 client applications", apps.Message)
+
+	// Then the soonest reached: 50 executions a day reach 1000 in 10 days
+	prompts := recommendations[1]
+	require.Equal(t, QuotaResourcePromptExecutions, prompts.Resource)
+	require.Equal(t, UsageRecommendationLimitApproaching, prompts.Severity)
+	require.Equal(t, 50.0, prompts.DailyRate)
+	require.Equal(t, now.AddDate(0, 0, 10), *prompts.LimitReachedAt)
+	// 50 a day over the 31 days of the month, with 20% headroom
+	require.Equal(t, int64(1860), prompts.SuggestedLimit)
+
+	users := recommendations[2]
+	require.Equal(t, QuotaResourceUsers, users.Resource)
+	require.Equal(t, now.AddDate(0, 0, 15), *users.LimitReachedAt)
+	// 85 users and one more a day over 90 days, with 20% headroom
+	require.Equal(t, int64(210), users.SuggestedLimit)
+	require.Equal(t, "At the current rate of 1.0 users a day, the tenant will reach its limit of 100 users in 15 days", users.Message)
+
+	// Without trend, from 90% of the limit
+	storage := recommendations[3]
+	require.Equal(t, QuotaResourceStorageMB, storage.Resource)
+	require.Equal(t, UsageRecommendationLimitApproaching, storage.Severity)
+	require.Zero(t, storage.DailyRate)
+	require.Nil(t, storage.LimitReachedAt)
+	require.Equal(t, "The tenant uses 95 of its 100 MB of storage", storage.Message)
+}
+
+func TestRecommendTenantUsageSkipsDistantLimits(t *testing.T) {
+	now := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	resources := []TenantQuotaResource{
+		// Reached in 90 days, after the horizon
+		{Resource: QuotaResourceUsers, Used: 10, Limit: quotaLimit(100)},
+		// 20 a day reach 1000 in 40 days, after the month resets
+		{Resource: QuotaResourcePromptExecutions, Used: 200, Limit: quotaLimit(1000)},
+		{Resource: QuotaResourceStorageMB, Used: 50, Limit: quotaLimit(100)},
+		// Not limited, or disabled on purpose
+		{Resource: QuotaResourceClientApplications, Used: 3},
+		{Resource: QuotaResourceClientApplications, Used: 0, Limit: quotaLimit(0)},
+	}
+	require.Empty(t, recommendTenantUsage(resources, 1, now, DefaultUsageRecommendationHorizon))
+
+	// A longer horizon reports the users, not the prompt executions
+	recommendations := recommendTenantUsage(resources, 1, now, 120*24*time.Hour)
+	require.Len(t, recommendations, 1)
+	require.Equal(t, QuotaResourceUsers, recommendations[0].Resource)
+}
+
+func TestUsageRecommendationNotifications(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewUsageRecommendationService(store, UsageRecommendationConfig{Horizon: DefaultUsageRecommendationHorizon})
+	ctx := context.Background()
+
+	tenant, err := store.CreateTenant(ctx, repository.CreateTenantParams{
+		UserID:    commontestutils.RandomString(10),
+		TenantID:  commontestutils.RandomString(10),
+		Name:      commontestutils.RandomString(10),
+		Subdomain: strings.ToLower(commontestutils.RandomString(10)),
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.quotas.SetLimits(ctx, tenant.TenantID, TenantQuotaLimits{MaxPromptExecutionsPerMonth: quotaLimit(1)}, "admin"))
+	require.NoError(t, service.quotas.ReservePromptExecution(ctx, tenant.TenantID))
+
+	recommendations, err := service.GetRecommendations(ctx, tenant.TenantID)
+	require.NoError(t, err)
+	require.Equal(t, tenant.TenantID, recommendations.TenantID)
+	require.Len(t, recommendations.Recommendations, 1)
+	rec := recommendations.Recommendations[0]
+	require.Equal(t, QuotaResourcePromptExecutions, rec.Resource)
+	require.Equal(t, UsageRecommendationLimitReached, rec.Severity)
+
+	// Notified once while it stands
+	claimed, err := service.notify(ctx, nil, tenant.TenantID, rec)
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = service.notify(ctx, nil, tenant.TenantID, rec)
+	require.NoError(t, err)
+	require.False(t, claimed)
+
+	// Another severity of the resource is notified on its own
+	rec.Severity = UsageRecommendationLimitApproaching
+	claimed, err = service.notify(ctx, nil, tenant.TenantID, rec)
+	require.NoError(t, err)
+	require.True(t, claimed)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageMeteringServiceAggregatesByDay(t *testing.T) {
+	s := NewUsageMeteringService(nil)
+	at := time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC)
+
+	s.add("tenant-a", UsageMetricLLMTokens, 1200, at)
+	s.add("tenant-a", UsageMetricLLMTokens, 300, at.Add(10*time.Hour))
+	s.add("tenant-a", UsageMetricLLMTokens, 50, at.Add(15*time.Hour))
+	s.add("tenant-b", UsageMetricLLMTokens, 10, at)
+	s.add("tenant-a", UsageMetricAPICalls, 1, at)
+
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	require.Len(t, s.pending, 4)
+	require.Equal(t, int64(1500), s.pending[usageKey{tenantID: "tenant-a", bucket: day, metric: UsageMetricLLMTokens}])
+	require.Equal(t, int64(50), s.pending[usageKey{tenantID: "tenant-a", bucket: day.AddDate(0, 0, 1), metric: UsageMetricLLMTokens}])
+}
+
+func TestGetTenantUsageRange(t *testing.T) {
+	s := NewUsageMeteringService(nil)
+	to := time.Now()
+
+	_, err := s.GetTenantUsage(context.Background(), "tenant-a", to, to)
+	require.ErrorIs(t, err, ErrInvalidTenantUsageRange)
+
+	_, err = s.ListTenantUsageTotals(context.Background(), to.Add(-MaxTenantUsageRange-time.Hour), to)
+	require.ErrorIs(t, err, ErrInvalidTenantUsageRange)
+
+	from, _, err := usageRange(time.Date(2026, 10, 1, 13, 0, 0, 0, time.UTC), time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), from)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/argon2"
+)
+
+// Token hash versions stored in core_api_tokens.hash_version. A version pins
+// the algorithm and its parameters: changing argon2id parameters needs a new
+// version so existing hashes keep verifying.
+const (
+	TokenHashSHA256     int16 = 1
+	TokenHashHMACSHA256 int16 = 2
+	TokenHashArgon2id   int16 = 3
+)
+
+const (
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+	argon2idTime       = 1
+	argon2idMemory     = 64 * 1024
+	argon2idThreads    = 2
+)
+
+// TokenHasher hashes API tokens for storage
+type TokenHasher interface {
+	// Version is stored along the hash to pick the hasher that verifies it
+	Version() int16
+	Hash(token string) ([]byte, error)
+	Verify(token string, hash []byte) bool
+}
+
+type sha256TokenHasher struct{}
+
+func (sha256TokenHasher) Version() int16 { return TokenHashSHA256 }
+
+func (sha256TokenHasher) Hash(token string) ([]byte, error) {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:], nil
+}
+
+func (h sha256TokenHasher) Verify(token string, hash []byte) bool {
+	expected, _ := h.Hash(token)
+	return subtle.ConstantTimeCompare(expected, hash) == 1
+}
+
+// hmacTokenHasher keys the hash with a server pepper, so a database dump
+// alone is not enough to check guessed tokens
+type hmacTokenHasher struct {
+	pepper []byte
+}
+
+func (hmacTokenHasher) Version() int16 { return TokenHashHMACSHA256 }
+
+func (h hmacTokenHasher) Hash(token string) ([]byte, error) {
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(token))
+	return mac.Sum(nil), nil
+}
+
+func (h hmacTokenHasher) Verify(token string, hash []byte) bool {
+	expected, _ := h.Hash(token)
+	return hmac.Equal(expected, hash)
+}
+
+// argon2idTokenHasher stores the random salt followed by the derived key. The
+// pepper, when set, is appended to the token before derivation.
+type argon2idTokenHasher struct {
+	pepper []byte
+}
+
+func (argon2idTokenHasher) Version() int16 { return TokenHashArgon2id }
+
+func (h argon2idTokenHasher) derive(token string, salt []byte) []byte {
+	password := append([]byte(token), h.pepper...)
+	return argon2.IDKey(password, salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLength)
+}
+
+func (h argon2idTokenHasher) Hash(token string) ([]byte, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return append(salt, h.derive(token, salt)...), nil
+}
+
+func (h argon2idTokenHasher) Verify(token string, hash []byte) bool {
+	if len(hash) != argon2idSaltLength+argon2idKeyLength {
+		return false
+	}
+	salt, key := hash[:argon2idSaltLength], hash[argon2idSaltLength:]
+	return subtle.ConstantTimeCompare(h.derive(token, salt), key) == 1
+}
+
+// TokenHashers hashes new tokens with the current algorithm and verifies the
+// tokens hashed with any known version.
+//
+// Environment:
+//   - API_TOKEN_HASH_ALGORITHM: sha256 (default), hmac-sha256 or argon2id
+//   - API_TOKEN_HASH_PEPPER: server secret required by hmac-sha256 and mixed
+//     into argon2id. It must stay set as long as tokens hashed with it exist.
+type TokenHashers struct {
+	current   TokenHasher
+	byVersion map[int16]TokenHasher
+}
+
+// NewTokenHashers registers the available hashers. The HMAC hasher is only
+// available with a pepper; without one, current falls back to SHA-256.
+func NewTokenHashers(algorithm string, pepper []byte) *TokenHashers {
+	hashers := &TokenHashers{byVersion: map[int16]TokenHasher{}}
+	hashers.register(sha256TokenHasher{})
+	hashers.register(argon2idTokenHasher{pepper: pepper})
+	if len(pepper) > 0 {
+		hashers.register(hmacTokenHasher{pepper: pepper})
+	}
+
+	hashers.current = hashers.byVersion[TokenHashSHA256]
+	switch strings.ToLower(algorithm) {
+	case "", "sha256":
+	case "hmac-sha256":
+		if len(pepper) == 0 {
+			log.Error().Msg("API_TOKEN_HASH_PEPPER is required by hmac-sha256, hashing API tokens with sha256")
+		} else {
+			hashers.current = hashers.byVersion[TokenHashHMACSHA256]
+		}
+	case "argon2id":
+		hashers.current = hashers.byVersion[TokenHashArgon2id]
+	default:
+		log.Error().Str("API_TOKEN_HASH_ALGORITHM", algorithm).Msg("Unknown token hash algorithm, hashing API tokens with sha256")
+	}
+	return hashers
+}
+
+func NewTokenHashersFromEnv() *TokenHashers {
+	return NewTokenHashersFromSecrets(context.Background(), EnvSecretProvider{})
+}
+
+// NewTokenHashersFromSecrets reads the pepper from the secret provider
+func NewTokenHashersFromSecrets(ctx context.Context, secrets SecretProvider) *TokenHashers {
+	return NewTokenHashers(os.Getenv("API_TOKEN_HASH_ALGORITHM"), []byte(readSecret(ctx, secrets, SecretAPITokenHashPepper)))
+}
+
+func (h *TokenHashers) register(hasher TokenHasher) {
+	h.byVersion[hasher.Version()] = hasher
+}
+
+// Current returns the hasher used for new tokens and upgrades
+func (h *TokenHashers) Current() TokenHasher {
+	return h.current
+}
+
+// ForVersion returns the hasher of a stored hash, or nil when the version is
+// unknown or its pepper is not configured
+func (h *TokenHashers) ForVersion(version int16) TokenHasher {
+	return h.byVersion[version]
+}
+
+// NeedsUpgrade reports whether a hash should be recomputed with the current
+// hasher
+func (h *TokenHashers) NeedsUpgrade(version int16) bool {
+	return version != h.current.Version()
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenHashers(t *testing.T) {
+	token := TokenPrefix + "a-test-token-value"
+	pepper := []byte("a-server-side-pepper")
+
+	t.Run("each version verifies its own hashes only", func(t *testing.T) {
+		hashers := NewTokenHashers("sha256", pepper)
+		for _, version := range []int16{TokenHashSHA256, TokenHashHMACSHA256, TokenHashArgon2id} {
+			hasher := hashers.ForVersion(version)
+			require.NotNil(t, hasher)
+			hash, err := hasher.Hash(token)
+			require.NoError(t, err)
+			require.True(t, hasher.Verify(token, hash))
+			require.False(t, hasher.Verify(token+"x", hash))
+		}
+
+		sha256Hash, err := hashers.ForVersion(TokenHashSHA256).Hash(token)
+		require.NoError(t, err)
+		require.False(t, hashers.ForVersion(TokenHashHMACSHA256).Verify(token, sha256Hash))
+	})
+
+	t.Run("argon2id hashes are salted", func(t *testing.T) {
+		hasher := NewTokenHashers("argon2id", nil).Current()
+		first, err := hasher.Hash(token)
+		require.NoError(t, err)
+		second, err := hasher.Hash(token)
+		require.NoError(t, err)
+		require.NotEqual(t, first, second)
+		require.True(t, hasher.Verify(token, second))
+	})
+
+	t.Run("hmac requires a pepper", func(t *testing.T) {
+		hashers := NewTokenHashers("hmac-sha256", nil)
+		require.Equal(t, TokenHashSHA256, hashers.Current().Version())
+		require.Nil(t, hashers.ForVersion(TokenHashHMACSHA256))
+	})
+
+	t.Run("older versions need an upgrade", func(t *testing.T) {
+		hashers := NewTokenHashers("hmac-sha256", pepper)
+		require.Equal(t, TokenHashHMACSHA256, hashers.Current().Version())
+		require.True(t, hashers.NeedsUpgrade(TokenHashSHA256))
+		require.False(t, hashers.NeedsUpgrade(TokenHashHMACSHA256))
+	})
+}
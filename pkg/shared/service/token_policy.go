@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrTokenPolicyViolation is wrapped by the errors of a token creation the
+	// policy of its tenant refuses
+	ErrTokenPolicyViolation = errors.New("token policy violation")
+	// ErrInvalidTokenPolicy is returned for a maximum expiry outside 1 to
+	// MaxTokenExpiry days
+	ErrInvalidTokenPolicy = fmt.Errorf("maximum expiry must be between 1 and %d days", MaxTokenExpiry)
+)
+
+// TokenPolicyInput is the policy of a tenant. A nil field does not constrain
+// the tokens.
+type TokenPolicyInput struct {
+	MaxExpiryDays *int32
+	// AllowedScopes are the scopes tokens can be granted, wildcards and the
+	// admin scope included: "users:*" allows "users:read"
+	AllowedScopes      *[]string
+	RequireDescription bool
+}
+
+// ListTokenPolicies lists the tenants with a token policy
+func (s *ClientApplicationService) ListTokenPolicies(ctx context.Context) ([]repository.CoreTokenPolicy, error) {
+	policies, err := s.store.ListTokenPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.ListTokenPolicies: %w", err)
+	}
+	return policies, nil
+}
+
+// GetTokenPolicy returns the token policy of a tenant, pgx.ErrNoRows when it
+// has none
+func (s *ClientApplicationService) GetTokenPolicy(ctx context.Context, tenantID string) (repository.CoreTokenPolicy, error) {
+	return s.store.GetTokenPolicy(ctx, tenantID)
+}
+
+// SetTokenPolicy creates or replaces the token policy of a tenant. It applies
+// to the tokens created afterwards, existing tokens are left as they are.
+func (s *ClientApplicationService) SetTokenPolicy(ctx context.Context, tenantID string, input TokenPolicyInput, updatedBy string) (repository.CoreTokenPolicy, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if input.MaxExpiryDays != nil && (*input.MaxExpiryDays < 1 || *input.MaxExpiryDays > MaxTokenExpiry) {
+		return repository.CoreTokenPolicy{}, ErrInvalidTokenPolicy
+	}
+	var allowedScopes []string
+	if input.AllowedScopes != nil {
+		allowedScopes = normalizeTemplateScopes(*input.AllowedScopes)
+	}
+
+	policy, err := s.store.UpsertTokenPolicy(ctx, repository.UpsertTokenPolicyParams{
+		TenantID:           tenantID,
+		MaxExpiryDays:      util.ToNullableInt4(input.MaxExpiryDays),
+		AllowedScopes:      allowedScopes,
+		RequireDescription: input.RequireDescription,
+		UpdatedBy:          updatedBy,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenantID", tenantID).Msg("Failed to set token policy")
+		return repository.CoreTokenPolicy{}, fmt.Errorf("service.SetTokenPolicy: %w", err)
+	}
+	logger.Info().Str("tenantID", tenantID).Interface("maxExpiryDays", input.MaxExpiryDays).
+		Strs("allowedScopes", allowedScopes).Bool("requireDescription", input.RequireDescription).Msg("Token policy set")
+	return policy, nil
+}
+
+// DeleteTokenPolicy removes the token policy of a tenant, pgx.ErrNoRows when
+// it has none
+func (s *ClientApplicationService) DeleteTokenPolicy(ctx context.Context, tenantID string) error {
+	deleted, err := s.store.DeleteTokenPolicy(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("service.DeleteTokenPolicy: %w", err)
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// applyTokenPolicy checks a token to create against the policy of the tenant
+// of its application and returns the expiry to give it. Without a requested
+// expiry, the default one is shortened to the maximum of the policy.
+func (s *ClientApplicationService) applyTokenPolicy(ctx context.Context, tenantID pgtype.Text, description string,
+	expiresInDays int, scopes []string) (int, error) {
+	if !tenantID.Valid || tenantID.String == "" {
+		return expiresInDays, nil
+	}
+	policy, err := s.store.GetTokenPolicy(ctx, tenantID.String)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return expiresInDays, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("service.applyTokenPolicy: %w", err)
+	}
+
+	if policy.RequireDescription && strings.TrimSpace(description) == "" {
+		return 0, fmt.Errorf("%w: a description is required", ErrTokenPolicyViolation)
+	}
+	if policy.MaxExpiryDays.Valid {
+		maxExpiry := int(policy.MaxExpiryDays.Int32)
+		switch {
+		case expiresInDays <= 0:
+			expiresInDays = min(DefaultTokenExpiry, maxExpiry)
+		case expiresInDays > maxExpiry:
+			return 0, fmt.Errorf("%w: tokens expire within %d days", ErrTokenPolicyViolation, maxExpiry)
+		}
+	}
+	if policy.AllowedScopes != nil {
+		for _, scope := range scopes {
+			if !HasScope(policy.AllowedScopes, scope) {
+				return 0, fmt.Errorf("%w: scope %s is not allowed", ErrTokenPolicyViolation, scope)
+			}
+		}
+	}
+	return expiresInDays, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenPolicy(t *testing.T) {
+	service, _, ctx := setupTestAPITokenService(t)
+	app := createTestClientApplication(t, service)
+	tenantID := app.TenantID.String
+
+	maxExpiry := int32(30)
+	allowedScopes := []string{"users:*", "files:read"}
+	_, err := service.SetTokenPolicy(ctx, tenantID, TokenPolicyInput{
+		MaxExpiryDays:      &maxExpiry,
+		AllowedScopes:      &allowedScopes,
+		RequireDescription: true,
+	}, app.CreatedBy)
+	require.NoError(t, err)
+
+	create := func(description string, expiresInDays int, scopes ...string) error {
+		_, _, err := service.CreateAPIToken(ctx, app.ID, tenantID, commontestutils.RandomString(10),
+			description, expiresInDays, app.CreatedBy, scopes)
+		return err
+	}
+
+	t.Run("tokens within the policy are created", func(t *testing.T) {
+		_, token, err := service.CreateAPIToken(ctx, app.ID, tenantID, commontestutils.RandomString(10),
+			"nightly sync", 0, app.CreatedBy, []string{"users:read", "files:read"})
+		require.NoError(t, err)
+		require.WithinDuration(t, token.CreatedAt.AddDate(0, 0, 30), token.ExpiresAt, time.Minute)
+	})
+
+	t.Run("tokens outside the policy are refused", func(t *testing.T) {
+		require.ErrorIs(t, create("", 10, "users:read"), ErrTokenPolicyViolation)
+		require.ErrorIs(t, create("sync", 31, "users:read"), ErrTokenPolicyViolation)
+		require.ErrorIs(t, create("sync", 10, "files:write"), ErrTokenPolicyViolation)
+		require.ErrorIs(t, create("sync", 10, AdminScope), ErrTokenPolicyViolation)
+	})
+
+	t.Run("an invalid maximum expiry is rejected", func(t *testing.T) {
+		tooLong := int32(MaxTokenExpiry + 1)
+		_, err := service.SetTokenPolicy(ctx, tenantID, TokenPolicyInput{MaxExpiryDays: &tooLong}, app.CreatedBy)
+		require.ErrorIs(t, err, ErrInvalidTokenPolicy)
+	})
+
+	t.Run("removing the policy lifts the constraints", func(t *testing.T) {
+		require.NoError(t, service.DeleteTokenPolicy(ctx, tenantID))
+		require.ErrorIs(t, service.DeleteTokenPolicy(ctx, tenantID), pgx.ErrNoRows)
+		require.NoError(t, create("", 200, "files:write"))
+	})
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 test vectors
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	// the RFC lists 8 digit codes, the last 6 digits are the 6 digit codes
+	require.Equal(t, "287082", totpCode(key, 59/totpPeriod))
+	require.Equal(t, "081804", totpCode(key, 1111111109/totpPeriod))
+	require.Equal(t, "050471", totpCode(key, 1111111111/totpPeriod))
+	require.Equal(t, "005924", totpCode(key, 1234567890/totpPeriod))
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok := validateTOTP(rfc6238Secret, "081804", now)
+	require.True(t, ok)
+	require.Equal(t, int64(1111111109/totpPeriod), step)
+
+	// codes of the neighbouring steps are accepted for drifting clocks
+	step, ok = validateTOTP(rfc6238Secret, "081804", now.Add(totpPeriod*time.Second))
+	require.True(t, ok)
+	require.Equal(t, int64(1111111109/totpPeriod), step)
+
+	_, ok = validateTOTP(rfc6238Secret, "081804", now.Add(2*totpPeriod*time.Second))
+	require.False(t, ok)
+
+	_, ok = validateTOTP(rfc6238Secret, "081 804", now)
+	require.True(t, ok)
+
+	for _, code := range []string{"", "123456", "81804", "0818040", "abcdef"} {
+		_, ok = validateTOTP(rfc6238Secret, code, now)
+		require.False(t, ok, code)
+	}
+
+	_, ok = validateTOTP("not base32!", "081804", now)
+	require.False(t, ok)
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := newTOTPSecret()
+	require.NoError(t, err)
+	require.NotContains(t, secret, "=")
+
+	key, err := totpEncoding.DecodeString(secret)
+	require.NoError(t, err)
+	require.Len(t, key, totpSecretSize)
+
+	other, err := newTOTPSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := totpURI("CTO Up", "jane@example.com", "JBSWY3DPEHPK3PXP")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/CTO%20Up:jane@example.com?"))
+	require.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	require.Contains(t, uri, "issuer=CTO+Up")
+	require.Contains(t, uri, "digits=6")
+	require.Contains(t, uri, "period=30")
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for i, code := range codes {
+		require.Regexp(t, format, code)
+		require.False(t, seen[code])
+		seen[code] = true
+		require.Equal(t, hashes[i], hashRecoveryCode(code))
+		require.NotContains(t, hashes[i], code)
+	}
+}
+
+func TestHashRecoveryCodeNormalizes(t *testing.T) {
+	hash := hashRecoveryCode("abcde-fghij")
+	require.Equal(t, hash, hashRecoveryCode("ABCDE-FGHIJ"))
+	require.Equal(t, hash, hashRecoveryCode("abcdefghij"))
+	require.Equal(t, hash, hashRecoveryCode(" abcde fghij "))
+	require.NotEqual(t, hash, hashRecoveryCode("abcde-fghik"))
+}
+
+func TestMFASecretEncryption(t *testing.T) {
+	keys, current, err := parseAEADKeys("k1:" + testEmailKey('a') + ",k2:" + testEmailKey('b'))
+	require.NoError(t, err)
+	s := &MFAService{keys: keys, currentKeyID: current}
+
+	stored, err := s.encryptSecret("JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(stored, current+":"))
+	require.NotContains(t, stored, "JBSWY3DPEHPK3PXP")
+
+	secret, err := s.decryptSecret(stored)
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+
+	_, err = s.decryptSecret("k9:" + strings.TrimPrefix(stored, current+":"))
+	require.Error(t, err)
+
+	_, err = (&MFAService{}).encryptSecret("JBSWY3DPEHPK3PXP")
+	require.ErrorIs(t, err, ErrMFANotConfigured)
+}
+
+func TestIsSelfServiceUserRoute(t *testing.T) {
+	require.True(t, isSelfServiceUserRoute(http.MethodPost, "/api/v1/users/me/mfa"))
+	require.True(t, isSelfServiceUserRoute(http.MethodPost, "/api/v1/users/me/mfa/enroll"))
+	require.True(t, isSelfServiceUserRoute(http.MethodDelete, "/api/v1/users/me/sessions/5f0c"))
+	require.True(t, isSelfServiceUserRoute(http.MethodDelete, "/api/v1/users/me"))
+	require.False(t, isSelfServiceUserRoute(http.MethodPost, "/api/v1/users/me/mfa-admin"))
+	require.False(t, isSelfServiceUserRoute(http.MethodPatch, "/api/v1/users/me"))
+	require.False(t, isSelfServiceUserRoute(http.MethodDelete, "/api/v1/users/me/"))
+	require.False(t, isSelfServiceUserRoute(http.MethodPost, "/api/v1/users/42/status"))
+}
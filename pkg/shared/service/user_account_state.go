@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// recordUserAccountState mirrors the state of the account at the auth
+// provider, which the user lists filter on. A null value keeps the recorded
+// one. The provider already changed, so a failure is only logged.
+func recordUserAccountState(ctx context.Context, store *db.Store, userID string, disabled, emailVerified pgtype.Bool) {
+	if err := store.SetUserAccountState(context.WithoutCancel(ctx), repository.SetUserAccountStateParams{
+		Disabled:      disabled,
+		EmailVerified: emailVerified,
+		UserID:        userID,
+	}); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", userID).Msg("Failed to record the account state of the user")
+	}
+}
+
+// setAccountStates fills the disabled and email verified flags of the users
+func setAccountStates(ctx context.Context, store *db.Store, users []core.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.Id
+	}
+	states, err := store.ListUserAccountStates(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("service.setAccountStates: %w", err)
+	}
+	byUser := make(map[string]repository.CoreUserAccountState, len(states))
+	for _, state := range states {
+		byUser[state.UserID] = state
+	}
+	for i := range users {
+		state := byUser[users[i].Id]
+		users[i].Disabled = &state.Disabled
+		users[i].EmailVerified = &state.EmailVerified
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Timeline categories. Core records account, login, membership and file events
+// and derives api_token events from the token audit log; modules record their
+// own categories, such as prompt executions, through RecordUserActivity.
+const (
+	ActivityCategoryAccount    = "account"
+	ActivityCategoryAPIToken   = "api_token"
+	ActivityCategoryFile       = "file"
+	ActivityCategoryLogin      = "login"
+	ActivityCategoryMembership = "membership"
+	ActivityCategoryPrompt     = "prompt"
+)
+
+// TimelineCategories lists the categories accepted as timeline filters
+var TimelineCategories = []string{
+	ActivityCategoryAccount,
+	ActivityCategoryAPIToken,
+	ActivityCategoryFile,
+	ActivityCategoryLogin,
+	ActivityCategoryMembership,
+	ActivityCategoryPrompt,
+}
+
+var ErrUnknownTimelineCategory = errors.New("unknown timeline category")
+
+// UserActivity is one event of a user's timeline. TenantID is empty for
+// global events and ActorID is empty when the user acted on their own.
+type UserActivity struct {
+	TenantID  string
+	UserID    string
+	Category  string
+	EventType string
+	ActorID   string
+	Data      map[string]interface{}
+}
+
+// UserTimelineEvent is a timeline entry as returned by ListUserTimeline
+type UserTimelineEvent struct {
+	ID         uuid.UUID
+	Category   string
+	EventType  string
+	ActorID    *string
+	OccurredAt time.Time
+	Data       map[string]interface{}
+}
+
+// UserActivityService records user activity and reads the merged per-user
+// timeline used by support
+type UserActivityService struct {
+	store *db.Store
+}
+
+func NewUserActivityService(store *db.Store) *UserActivityService {
+	return &UserActivityService{store: store}
+}
+
+// RecordUserActivity stores an activity event on the user's timeline
+func (s *UserActivityService) RecordUserActivity(ctx context.Context, activity UserActivity) error {
+	return recordUserActivity(ctx, s.store, activity)
+}
+
+// recordUserActivity is shared with the services that only hold a store.
+// Callers treat the timeline as best effort: they log a failure and carry on.
+func recordUserActivity(ctx context.Context, store *db.Store, activity UserActivity) error {
+	activity.Data = withAttribution(ctx, activity.Data)
+	var data []byte
+	if len(activity.Data) > 0 {
+		var err error
+		data, err = json.Marshal(activity.Data)
+		if err != nil {
+			return fmt.Errorf("service.RecordUserActivity: %w", err)
+		}
+	}
+	err := store.CreateUserActivityEvent(ctx, repository.CreateUserActivityEventParams{
+		TenantID:  activity.TenantID,
+		UserID:    activity.UserID,
+		Category:  activity.Category,
+		EventType: activity.EventType,
+		ActorID:   pgtype.Text{String: activity.ActorID, Valid: activity.ActorID != ""},
+		Data:      data,
+	})
+	if err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).
+			Str("user_id", activity.UserID).
+			Str("category", activity.Category).
+			Str("event_type", activity.EventType).
+			Msg("Failed to record user activity")
+		return fmt.Errorf("service.RecordUserActivity: %w", err)
+	}
+	return nil
+}
+
+// withAttribution returns the data of an event with the delegator or the
+// impersonator behind the request, without changing data
+func withAttribution(ctx context.Context, data map[string]interface{}) map[string]interface{} {
+	// An action allowed by a delegation is attributed to the delegator too
+	if delegatorID, ok := ctx.Value(auth.AUTH_DELEGATOR_ID).(string); ok && delegatorID != "" {
+		data = maps.Clone(data)
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		data["delegated_by"] = delegatorID
+		data["delegation_id"] = ctx.Value(auth.AUTH_DELEGATION_ID)
+	}
+	// So is an action of a super admin impersonating the user
+	if actorID, ok := ctx.Value(auth.AUTH_IMPERSONATOR_ID).(string); ok && actorID != "" {
+		data = maps.Clone(data)
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		data["impersonated_by"] = actorID
+		data["impersonation_id"] = ctx.Value(auth.AUTH_IMPERSONATION_ID)
+	}
+	return data
+}
+
+// ValidateTimelineCategories rejects filters that no event can match
+func ValidateTimelineCategories(categories []string) error {
+	for _, category := range categories {
+		if !slices.Contains(TimelineCategories, category) {
+			return fmt.Errorf("%w: %s", ErrUnknownTimelineCategory, category)
+		}
+	}
+	return nil
+}
+
+// ListUserTimeline returns the user's events in the tenant, most recent
+// first. An empty categories list returns every category.
+func (s *UserActivityService) ListUserTimeline(ctx context.Context, tenantID string, userID string, categories []string, limit int32, offset int32) ([]UserTimelineEvent, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if categories == nil {
+		categories = []string{}
+	}
+	rows, err := s.store.ListUserTimeline(ctx, repository.ListUserTimelineParams{
+		TenantID:   tenantID,
+		UserID:     userID,
+		Categories: categories,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		logger.Err(err).Str("tenant_id", tenantID).Str("user_id", userID).Msg("Failed to list user timeline")
+		return nil, fmt.Errorf("service.ListUserTimeline: %w", err)
+	}
+
+	events := make([]UserTimelineEvent, len(rows))
+	for i, row := range rows {
+		events[i] = UserTimelineEvent{
+			ID:         row.ID,
+			Category:   row.Category,
+			EventType:  row.EventType,
+			ActorID:    util.FromNullableText(row.ActorID),
+			OccurredAt: row.OccurredAt,
+		}
+		if len(row.Data) > 0 {
+			if err := json.Unmarshal(row.Data, &events[i].Data); err != nil {
+				logger.Warn().Err(err).Str("event_id", row.ID.String()).Msg("Invalid timeline event data")
+			}
+		}
+	}
+	return events, nil
+}
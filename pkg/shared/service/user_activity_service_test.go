@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTimelineCategories(t *testing.T) {
+	require.NoError(t, ValidateTimelineCategories(nil))
+	require.NoError(t, ValidateTimelineCategories(TimelineCategories))
+
+	err := ValidateTimelineCategories([]string{ActivityCategoryLogin, "billing"})
+	require.ErrorIs(t, err, ErrUnknownTimelineCategory)
+	require.Contains(t, err.Error(), "billing")
+}
+
+// recordActivityAt records an activity event that occurred at the given time
+func recordActivityAt(t *testing.T, store *db.Store, activity UserActivity, at time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	require.NoError(t, NewUserActivityService(store).RecordUserActivity(ctx, activity))
+	_, err := store.ConnPool.Exec(ctx,
+		"UPDATE core_user_activity_events SET occurred_at = $1 WHERE tenant_id = $2 AND user_id = $3 AND event_type = $4",
+		at, activity.TenantID, activity.UserID, activity.EventType)
+	require.NoError(t, err)
+}
+
+func TestListUserTimeline(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	service := NewUserActivityService(store)
+	ctx := context.Background()
+
+	tenantID := commontestutils.RandomString(10)
+	otherTenantID := commontestutils.RandomString(10)
+	userID := commontestutils.RandomString(12)
+	_, err := store.CreateSharedUserWithTenant(ctx, repository.CreateSharedUserWithTenantParams{
+		ID:          userID,
+		Email:       strings.ToLower(userID) + "@example.com",
+		Profile:     subentity.UserProfile{Name: "Timeline"},
+		TenantID:    tenantID,
+		TenantRoles: []string{"USER"},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = store.ConnPool.Exec(ctx,
+		"UPDATE core_user_tenant_memberships SET created_at = $1, joined_at = $2 WHERE tenant_id = $3 AND user_id = $4",
+		now.Add(-6*time.Hour), now.Add(-5*time.Hour), tenantID, userID)
+	require.NoError(t, err)
+	recordActivityAt(t, store, UserActivity{TenantID: tenantID, UserID: userID, Category: ActivityCategoryLogin, EventType: "login"}, now.Add(-4*time.Hour))
+	recordActivityAt(t, store, UserActivity{TenantID: tenantID, UserID: userID, Category: ActivityCategoryAccount, EventType: "profile_updated", ActorID: "admin"}, now.Add(-3*time.Hour))
+	recordActivityAt(t, store, UserActivity{TenantID: tenantID, UserID: userID, Category: ActivityCategoryPrompt, EventType: "executed", Data: map[string]interface{}{"prompt_id": "p1"}}, now.Add(-time.Hour))
+
+	// A token created by the user, its creation being the api_token event
+	gctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	gctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	tokens := NewClientApplicationService(store)
+	app, err := tokens.CreateClientApplication(ctx, tenantID, "timeline-app", "", userID)
+	require.NoError(t, err)
+	_, token, err := tokens.CreateAPIToken(gctx, app.ID, tenantID, "timeline-token", "", 30, userID, nil)
+	require.NoError(t, err)
+	_, err = store.ConnPool.Exec(ctx, "UPDATE core_api_token_audit_logs SET timestamp = $1 WHERE token_id = $2", now.Add(-2*time.Hour), token.ID)
+	require.NoError(t, err)
+
+	// Neither the events of the user in another tenant nor those of another
+	// user of the tenant
+	recordActivityAt(t, store, UserActivity{TenantID: otherTenantID, UserID: userID, Category: ActivityCategoryLogin, EventType: "login"}, now)
+	recordActivityAt(t, store, UserActivity{TenantID: tenantID, UserID: commontestutils.RandomString(12), Category: ActivityCategoryLogin, EventType: "login"}, now)
+
+	events, err := service.ListUserTimeline(ctx, tenantID, userID, nil, 50, 0)
+	require.NoError(t, err)
+	type entry struct{ category, eventType string }
+	got := make([]entry, len(events))
+	for i, event := range events {
+		got[i] = entry{event.Category, event.EventType}
+	}
+	require.Equal(t, []entry{
+		{ActivityCategoryPrompt, "executed"},
+		{ActivityCategoryAPIToken, TokenAuditCreated},
+		{ActivityCategoryAccount, "profile_updated"},
+		{ActivityCategoryLogin, "login"},
+		{ActivityCategoryMembership, "joined"},
+		{ActivityCategoryMembership, "added"},
+	}, got)
+	require.True(t, now.Add(-time.Hour).Equal(events[0].OccurredAt))
+	require.Equal(t, map[string]interface{}{"prompt_id": "p1"}, events[0].Data)
+	require.Equal(t, token.ID.String(), events[1].Data["token_id"])
+	require.NotNil(t, events[2].ActorID)
+	require.Equal(t, "admin", *events[2].ActorID)
+	require.Nil(t, events[3].ActorID)
+
+	t.Run("pagination", func(t *testing.T) {
+		page, err := service.ListUserTimeline(ctx, tenantID, userID, nil, 2, 0)
+		require.NoError(t, err)
+		require.Equal(t, events[:2], page)
+
+		page, err = service.ListUserTimeline(ctx, tenantID, userID, nil, 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, events[2:4], page)
+
+		page, err = service.ListUserTimeline(ctx, tenantID, userID, nil, 2, 6)
+		require.NoError(t, err)
+		require.Empty(t, page)
+	})
+
+	t.Run("categories", func(t *testing.T) {
+		filtered, err := service.ListUserTimeline(ctx, tenantID, userID, []string{ActivityCategoryLogin, ActivityCategoryAPIToken}, 50, 0)
+		require.NoError(t, err)
+		require.Len(t, filtered, 2)
+		require.Equal(t, ActivityCategoryAPIToken, filtered[0].Category)
+		require.Equal(t, ActivityCategoryLogin, filtered[1].Category)
+
+		filtered, err = service.ListUserTimeline(ctx, tenantID, userID, []string{ActivityCategoryFile}, 50, 0)
+		require.NoError(t, err)
+		require.Empty(t, filtered)
+	})
+
+	t.Run("other tenant", func(t *testing.T) {
+		other, err := service.ListUserTimeline(ctx, otherTenantID, userID, nil, 50, 0)
+		require.NoError(t, err)
+		require.Len(t, other, 1)
+		require.Equal(t, ActivityCategoryLogin, other[0].Category)
+		require.True(t, now.Equal(other[0].OccurredAt))
+	})
+}
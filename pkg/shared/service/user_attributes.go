@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+)
+
+// Types of the custom user attributes
+const (
+	UserAttributeString  = "string"
+	UserAttributeNumber  = "number"
+	UserAttributeBoolean = "boolean"
+	// UserAttributeDate is a calendar date written as YYYY-MM-DD
+	UserAttributeDate = "date"
+	// UserAttributeEnum is a string among the enum values of the attribute
+	UserAttributeEnum = "enum"
+
+	maxUserAttributes           = 50
+	maxUserAttributeLabelLength = 128
+	userAttributeDateLayout     = "2006-01-02"
+)
+
+var userAttributeTypes = []string{
+	UserAttributeString, UserAttributeNumber, UserAttributeBoolean, UserAttributeDate, UserAttributeEnum,
+}
+
+var userAttributeKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// Codes of the attribute violations, stable for the frontend to localize
+const (
+	UserAttributeRequired    = "required"
+	UserAttributeInvalidType = "invalid_type"
+	UserAttributeUnknown     = "unknown"
+)
+
+var (
+	// ErrInvalidUserAttribute is wrapped by every validation error of an
+	// attribute definition
+	ErrInvalidUserAttribute  = errors.New("invalid user attribute")
+	ErrUserAttributeNotFound = errors.New("user attribute not found")
+	// ErrInvalidUserAttributes is matched by every UserAttributesError
+	ErrInvalidUserAttributes = errors.New("invalid user attributes")
+)
+
+// UserAttributeViolation is an attribute value the schema of the tenant
+// rejects
+type UserAttributeViolation struct {
+	Key     string `json:"key"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UserAttributesError lists every rejected attribute, so a form can show them
+// all at once
+type UserAttributesError struct {
+	Violations []UserAttributeViolation
+}
+
+func (e *UserAttributesError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Key + " " + violation.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidUserAttributes, strings.Join(messages, ", "))
+}
+
+func (e *UserAttributesError) Is(target error) bool {
+	return target == ErrInvalidUserAttributes
+}
+
+// UserAttributeDefinition is an attribute to define, or the new definition of
+// an attribute
+type UserAttributeDefinition struct {
+	Key        string
+	Label      string
+	Type       string
+	Required   bool
+	EnumValues []string
+}
+
+// userAttributesCache keeps the definitions of each tenant, keyed by tenant id
+var userAttributesCache = NewPublicCache[[]repository.CoreUserAttribute](DefaultPublicCacheTTL)
+
+// UserAttributeService manages the custom attributes the tenants define for
+// the profiles of their users, and validates the values against them. The
+// values live in the attributes object of the profile, which is shared by the
+// tenants of the user.
+type UserAttributeService struct {
+	store *db.Store
+}
+
+func NewUserAttributeService(store *db.Store) *UserAttributeService {
+	return &UserAttributeService{store: store}
+}
+
+func normalizeUserAttributeDefinition(def UserAttributeDefinition) (UserAttributeDefinition, error) {
+	def.Label = strings.TrimSpace(def.Label)
+	if !userAttributeKeyPattern.MatchString(def.Key) {
+		return def, fmt.Errorf("%w: the key must start with a letter and have up to 64 letters, digits or underscores", ErrInvalidUserAttribute)
+	}
+	if def.Label == "" || len(def.Label) > maxUserAttributeLabelLength {
+		return def, fmt.Errorf("%w: the label must have between 1 and %d characters", ErrInvalidUserAttribute, maxUserAttributeLabelLength)
+	}
+	if !slices.Contains(userAttributeTypes, def.Type) {
+		return def, fmt.Errorf("%w: the type must be one of %s", ErrInvalidUserAttribute, strings.Join(userAttributeTypes, ", "))
+	}
+	values := []string{}
+	for _, value := range def.EnumValues {
+		if value = strings.TrimSpace(value); value != "" && !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	switch {
+	case def.Type == UserAttributeEnum && len(values) == 0:
+		return def, fmt.Errorf("%w: an enum needs at least one value", ErrInvalidUserAttribute)
+	case def.Type != UserAttributeEnum && len(values) > 0:
+		return def, fmt.Errorf("%w: only an enum has values", ErrInvalidUserAttribute)
+	}
+	def.EnumValues = values
+	return def, nil
+}
+
+// checkUserAttributeValue returns the message of the violation, empty when
+// the value matches the definition
+func checkUserAttributeValue(def repository.CoreUserAttribute, value interface{}) string {
+	switch def.Type {
+	case UserAttributeString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case UserAttributeNumber:
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case UserAttributeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+	case UserAttributeDate:
+		s, ok := value.(string)
+		if !ok {
+			return "must be a date formatted as YYYY-MM-DD"
+		}
+		if _, err := time.Parse(userAttributeDateLayout, s); err != nil {
+			return "must be a date formatted as YYYY-MM-DD"
+		}
+	case UserAttributeEnum:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(def.EnumValues, s) {
+			return "must be one of " + strings.Join(def.EnumValues, ", ")
+		}
+	}
+	return ""
+}
+
+// ListDefinitions lists the attributes defined by the tenant by key
+func (s *UserAttributeService) ListDefinitions(ctx context.Context, tenantID string) ([]repository.CoreUserAttribute, error) {
+	defs, err := userAttributesCache.Get(ctx, tenantID, func(ctx context.Context) ([]repository.CoreUserAttribute, error) {
+		return s.store.ListUserAttributes(ctx, tenantID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListDefinitions: %w", err)
+	}
+	return defs, nil
+}
+
+// SaveDefinition defines an attribute of the tenant, or replaces its
+// definition. The values stored already are checked again on the next update
+// of each profile.
+func (s *UserAttributeService) SaveDefinition(ctx context.Context, tenantID string, def UserAttributeDefinition) (repository.CoreUserAttribute, error) {
+	def, err := normalizeUserAttributeDefinition(def)
+	if err != nil {
+		return repository.CoreUserAttribute{}, err
+	}
+	defs, err := s.store.ListUserAttributes(ctx, tenantID)
+	if err != nil {
+		return repository.CoreUserAttribute{}, fmt.Errorf("service.SaveDefinition: %w", err)
+	}
+	exists := slices.ContainsFunc(defs, func(d repository.CoreUserAttribute) bool { return d.Key == def.Key })
+	if !exists && len(defs) >= maxUserAttributes {
+		return repository.CoreUserAttribute{}, fmt.Errorf("%w: a tenant defines at most %d attributes", ErrInvalidUserAttribute, maxUserAttributes)
+	}
+	saved, err := s.store.UpsertUserAttribute(ctx, repository.UpsertUserAttributeParams{
+		TenantID:   tenantID,
+		Key:        def.Key,
+		Label:      def.Label,
+		Type:       def.Type,
+		Required:   def.Required,
+		EnumValues: def.EnumValues,
+	})
+	if err != nil {
+		return repository.CoreUserAttribute{}, fmt.Errorf("service.SaveDefinition: %w", err)
+	}
+	userAttributesCache.Invalidate(tenantID)
+	return saved, nil
+}
+
+// DeleteDefinition removes an attribute of the tenant. The values stored in
+// the profiles are kept, they are no longer checked.
+func (s *UserAttributeService) DeleteDefinition(ctx context.Context, tenantID, key string) error {
+	deleted, err := s.store.DeleteUserAttribute(ctx, repository.DeleteUserAttributeParams{TenantID: tenantID, Key: key})
+	if err != nil {
+		return fmt.Errorf("service.DeleteDefinition: %w", err)
+	}
+	if deleted == 0 {
+		return ErrUserAttributeNotFound
+	}
+	userAttributesCache.Invalidate(tenantID)
+	return nil
+}
+
+// ValidateProfile checks the attributes of a whole profile against the
+// definitions of the tenant. The attributes it does not define are left
+// alone: they may belong to another tenant of the user. The rejected values
+// are returned as a *UserAttributesError.
+func (s *UserAttributeService) ValidateProfile(ctx context.Context, tenantID string, attributes map[string]interface{}) error {
+	if tenantID == "" {
+		return nil
+	}
+	defs, err := s.ListDefinitions(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	violations := []UserAttributeViolation{}
+	for _, def := range defs {
+		value := attributes[def.Key]
+		if value == nil {
+			if def.Required {
+				violations = append(violations, UserAttributeViolation{Key: def.Key, Code: UserAttributeRequired, Message: "is required"})
+			}
+			continue
+		}
+		if msg := checkUserAttributeValue(def, value); msg != "" {
+			violations = append(violations, UserAttributeViolation{Key: def.Key, Code: UserAttributeInvalidType, Message: msg})
+		}
+	}
+	if len(violations) > 0 {
+		return &UserAttributesError{Violations: violations}
+	}
+	return nil
+}
+
+// CheckAttributes checks attributes to merge into a profile: each must be
+// defined by the tenant, and a null value removes an optional attribute only.
+// The rejected values are returned as a *UserAttributesError.
+func (s *UserAttributeService) CheckAttributes(ctx context.Context, tenantID string, attributes map[string]interface{}) error {
+	defs, err := s.ListDefinitions(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	violations := []UserAttributeViolation{}
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		i := slices.IndexFunc(defs, func(d repository.CoreUserAttribute) bool { return d.Key == key })
+		switch {
+		case i < 0:
+			violations = append(violations, UserAttributeViolation{Key: key, Code: UserAttributeUnknown, Message: "is not defined by the tenant"})
+		case attributes[key] == nil:
+			if defs[i].Required {
+				violations = append(violations, UserAttributeViolation{Key: key, Code: UserAttributeRequired, Message: "is required"})
+			}
+		default:
+			if msg := checkUserAttributeValue(defs[i], attributes[key]); msg != "" {
+				violations = append(violations, UserAttributeViolation{Key: key, Code: UserAttributeInvalidType, Message: msg})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &UserAttributesError{Violations: violations}
+	}
+	return nil
+}
+
+// SetUserAttributes merges the attributes into the profile of the user after
+// CheckAttributes, the other attributes being kept
+func (s *UserAttributeService) SetUserAttributes(ctx context.Context, tenantID, userID string, attributes map[string]interface{}) error {
+	if err := s.CheckAttributes(ctx, tenantID, attributes); err != nil {
+		return err
+	}
+	if len(attributes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return fmt.Errorf("service.SetUserAttributes: %w", err)
+	}
+	if _, err := s.store.MergeSharedUserAttributes(ctx, repository.MergeSharedUserAttributesParams{
+		Attributes: data,
+		ID:         userID,
+	}); err != nil {
+		return fmt.Errorf("service.SetUserAttributes: %w", err)
+	}
+	return nil
+}
+
+// profileAttributes returns the attributes of a profile for the API, nil when
+// there are none
+func profileAttributes(attributes map[string]interface{}) *map[string]interface{} {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return &attributes
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeUserAttributeDefinition(t *testing.T) {
+	def, err := normalizeUserAttributeDefinition(UserAttributeDefinition{
+		Key: "team", Label: " Team ", Type: UserAttributeEnum, EnumValues: []string{"red", " blue", "red", ""},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Team", def.Label)
+	require.Equal(t, []string{"red", "blue"}, def.EnumValues)
+
+	for _, invalid := range []UserAttributeDefinition{
+		{Key: "1team", Label: "Team", Type: UserAttributeString},
+		{Key: "team", Label: " ", Type: UserAttributeString},
+		{Key: "team", Label: "Team", Type: "color"},
+		{Key: "team", Label: "Team", Type: UserAttributeEnum},
+		{Key: "team", Label: "Team", Type: UserAttributeString, EnumValues: []string{"red"}},
+	} {
+		_, err := normalizeUserAttributeDefinition(invalid)
+		require.ErrorIs(t, err, ErrInvalidUserAttribute, invalid)
+	}
+}
+
+func TestValidateUserAttributes(t *testing.T) {
+	ctx := context.Background()
+	attributes := NewUserAttributeService(nil)
+	_, err := userAttributesCache.Get(ctx, "t1", func(ctx context.Context) ([]repository.CoreUserAttribute, error) {
+		return []repository.CoreUserAttribute{
+			{Key: "birthday", Type: UserAttributeDate},
+			{Key: "employeeId", Type: UserAttributeNumber, Required: true},
+			{Key: "team", Type: UserAttributeEnum, EnumValues: []string{"red", "blue"}},
+		}, nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { userAttributesCache.Invalidate("t1") })
+
+	// Attributes the tenant does not define may belong to another tenant
+	require.NoError(t, attributes.ValidateProfile(ctx, "t1", map[string]interface{}{
+		"employeeId": float64(42), "birthday": "1990-02-28", "other": true,
+	}))
+
+	err = attributes.ValidateProfile(ctx, "t1", map[string]interface{}{"birthday": "28/02/1990", "team": "green"})
+	var invalid *UserAttributesError
+	require.ErrorAs(t, err, &invalid)
+	require.ErrorIs(t, err, ErrInvalidUserAttributes)
+	require.Equal(t, []UserAttributeViolation{
+		{Key: "birthday", Code: UserAttributeInvalidType, Message: "must be a date formatted as YYYY-MM-DD"},
+		{Key: "employeeId", Code: UserAttributeRequired, Message: "is required"},
+		{Key: "team", Code: UserAttributeInvalidType, Message: "must be one of red, blue"},
+	}, invalid.Violations)
+
+	// A partial update only checks the attributes given, all defined
+	require.NoError(t, attributes.CheckAttributes(ctx, "t1", map[string]interface{}{"team": nil}))
+	err = attributes.CheckAttributes(ctx, "t1", map[string]interface{}{"employeeId": nil, "other": true})
+	require.ErrorAs(t, err, &invalid)
+	require.Equal(t, []string{UserAttributeRequired, UserAttributeUnknown},
+		[]string{invalid.Violations[0].Code, invalid.Violations[1].Code})
+}
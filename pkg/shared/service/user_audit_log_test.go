@@ -0,0 +1,23 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUserAuditActions(t *testing.T) {
+	assert.NoError(t, ValidateUserAuditActions(nil))
+	assert.NoError(t, ValidateUserAuditActions([]string{UserAuditActionRoleAssigned, UserAuditActionPasswordReset}))
+
+	err := ValidateUserAuditActions([]string{UserAuditActionCreated, "logged_in"})
+	require.ErrorIs(t, err, ErrUnknownUserAuditAction)
+	assert.Contains(t, err.Error(), "logged_in")
+}
+
+func TestTransferDetails(t *testing.T) {
+	assert.Nil(t, transferDetails(OwnershipTransfer{}))
+	assert.Equal(t, map[string]interface{}{"transferred_to": "tenant_owner"}, transferDetails(OwnershipTransfer{ToTenantOwner: true}))
+	assert.Equal(t, map[string]interface{}{"transferred_to": "user-2"}, transferDetails(OwnershipTransfer{ToUserID: "user-2"}))
+}
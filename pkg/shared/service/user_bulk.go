@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxBulkUsers bounds the users of a bulk operation
+const MaxBulkUsers = 100
+
+// Codes of a bulk operation item that failed
+const (
+	BulkUserCodeNotFound     = "NOT_FOUND"
+	BulkUserCodeSelf         = "SELF"
+	BulkUserCodeForbidden    = "FORBIDDEN"
+	BulkUserCodeOwnsResource = "USER_OWNS_RESOURCES"
+	BulkUserCodeFailed       = "FAILED"
+)
+
+// ErrInvalidBulkUserOperation is returned for an unknown action, a missing
+// role or a user list that is empty or too long
+var ErrInvalidBulkUserOperation = errors.New("invalid bulk user operation")
+
+// BulkUserResult is the outcome of a bulk operation for one user
+type BulkUserResult struct {
+	UserID  string
+	Success bool
+	Code    string
+	Error   string
+}
+
+// BulkUserService applies one action to many users, each on its own
+type BulkUserService struct {
+	userService UserService
+	guards      *UserGuardPolicy
+}
+
+func NewBulkUserService(userService UserService, guards *UserGuardPolicy) *BulkUserService {
+	return &BulkUserService{userService: userService, guards: guards}
+}
+
+// Run applies the operation to each user of the tenant, or to the users of the
+// system when tenantID is empty. A user gets the checks of the matching single
+// user endpoint and is changed in its own transaction, so a failure is
+// reported on its item and does not stop the others. An operation the caller
+// may not perform at all fails as a whole with auth.ErrForbidden.
+func (s *BulkUserService) Run(c *gin.Context, authClient auth.AuthClient, tenantID string, op core.BulkUserOperation, transfer OwnershipTransfer) ([]BulkUserResult, error) {
+	userIDs, err := validateBulkUserOperation(tenantID, op)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(c, auth.OpManageUsers); err != nil {
+		return nil, err
+	}
+	if tenantID == "" {
+		if err := auth.Authorize(c, auth.OpManageGlobalUsers); err != nil {
+			return nil, err
+		}
+	}
+	if op.Action == core.AssignRole {
+		if err := auth.HasRightsForRole(c, *op.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]BulkUserResult, len(userIDs))
+	for i, userID := range userIDs {
+		results[i] = s.apply(c, authClient, tenantID, op, transfer, userID)
+	}
+	return results, nil
+}
+
+// validateBulkUserOperation checks the operation and returns its users without
+// duplicates, in the requested order
+func validateBulkUserOperation(tenantID string, op core.BulkUserOperation) ([]string, error) {
+	switch op.Action {
+	case core.Disable, core.Enable, core.Delete:
+	case core.AssignRole:
+		if op.Role == nil {
+			return nil, fmt.Errorf("%w: assign-role needs a role", ErrInvalidBulkUserOperation)
+		}
+		if tenantID != "" {
+			if err := validateTenantScopedRole(*op.Role); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBulkUserOperation, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidBulkUserOperation, op.Action)
+	}
+
+	seen := make(map[string]bool, len(op.UserIds))
+	userIDs := make([]string, 0, len(op.UserIds))
+	for _, userID := range op.UserIds {
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		userIDs = append(userIDs, userID)
+	}
+	if len(userIDs) == 0 || len(userIDs) > MaxBulkUsers {
+		return nil, fmt.Errorf("%w: between 1 and %d users are required", ErrInvalidBulkUserOperation, MaxBulkUsers)
+	}
+	return userIDs, nil
+}
+
+func (s *BulkUserService) apply(c *gin.Context, authClient auth.AuthClient, tenantID string, op core.BulkUserOperation, transfer OwnershipTransfer, userID string) BulkUserResult {
+	logger := util.GetLoggerFromCtx(c)
+	result := BulkUserResult{UserID: userID}
+	fail := func(code string, err error) BulkUserResult {
+		result.Code = code
+		result.Error = err.Error()
+		return result
+	}
+
+	if op.Action != core.AssignRole && userID == c.GetString(auth.AUTH_USER_ID) {
+		return fail(BulkUserCodeSelf, errors.New("cannot apply to yourself"))
+	}
+
+	var user core.User
+	var err error
+	if tenantID == "" {
+		user, err = s.userService.GetUserByID(c, userID)
+	} else {
+		user, err = s.userService.GetUserByTenantIDByID(c, tenantID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fail(BulkUserCodeNotFound, errors.New("user not found"))
+		}
+		return fail(BulkUserCodeFailed, err)
+	}
+	if op.Action != core.AssignRole {
+		if err := auth.HasRightsForRoles(c, user.Roles); err != nil {
+			return fail(BulkUserCodeForbidden, err)
+		}
+	}
+	if op.Action == core.Disable || op.Action == core.Delete {
+		action := UserGuardActionDisable
+		if op.Action == core.Delete {
+			action = UserGuardActionDelete
+		}
+		var refused *UserGuardError
+		if err := s.guards.Check(c, tenantID, c.GetString(auth.AUTH_USER_ID), userID, action, ""); errors.As(err, &refused) {
+			return fail(refused.Code, refused)
+		} else if err != nil {
+			return fail(BulkUserCodeFailed, err)
+		}
+	}
+
+	switch op.Action {
+	case core.Disable, core.Enable:
+		err = s.userService.UpdateUserStatus(c, authClient, tenantID, userID, string(core.DISABLED), op.Action == core.Disable)
+	case core.Delete:
+		if tenantID == "" {
+			err = s.userService.DeleteUser(c, authClient, userID, transfer)
+		} else {
+			err = s.userService.DeleteUserFromTenant(c, authClient, tenantID, userID, transfer)
+		}
+	case core.AssignRole:
+		err = s.userService.AssignRole(c, authClient, tenantID, userID, *op.Role)
+	}
+	if err != nil {
+		var owns *ErrUserOwnsResources
+		switch {
+		case errors.As(err, &owns):
+			return fail(BulkUserCodeOwnsResource, err)
+		case errors.Is(err, auth.ErrForbidden):
+			return fail(BulkUserCodeForbidden, err)
+		}
+		logger.Err(err).Str("userID", userID).Str("action", string(op.Action)).Msg("Bulk user operation failed")
+		return fail(BulkUserCodeFailed, err)
+	}
+	result.Success = true
+	return result
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBulkUserOperation(t *testing.T) {
+	userIDs, err := validateBulkUserOperation("tenant-a", core.BulkUserOperation{
+		Action:  core.Disable,
+		UserIds: []string{"u2", "u1", "u2", ""},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u2", "u1"}, userIDs)
+
+	role := core.CUSTOMERADMIN
+	_, err = validateBulkUserOperation("tenant-a", core.BulkUserOperation{Action: core.AssignRole, UserIds: []string{"u1"}, Role: &role})
+	require.NoError(t, err)
+
+	global := core.ADMIN
+	_, err = validateBulkUserOperation("", core.BulkUserOperation{Action: core.AssignRole, UserIds: []string{"u1"}, Role: &global})
+	require.NoError(t, err)
+
+	tooMany := make([]string, MaxBulkUsers+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("u%d", i)
+	}
+	for name, op := range map[string]core.BulkUserOperation{
+		"unknown action":        {Action: "archive", UserIds: []string{"u1"}},
+		"missing role":          {Action: core.AssignRole, UserIds: []string{"u1"}},
+		"global role in tenant": {Action: core.AssignRole, UserIds: []string{"u1"}, Role: &global},
+		"no users":              {Action: core.Enable, UserIds: []string{""}},
+		"too many users":        {Action: core.Delete, UserIds: tooMany},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := validateBulkUserOperation("tenant-a", op)
+			require.ErrorIs(t, err, ErrInvalidBulkUserOperation)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultEmailChangeTTL = 24 * time.Hour
+	MaxEmailChangeTTL     = 7 * 24 * time.Hour
+	// EmailChangeRevertTTL is how long the previous address can revert a
+	// confirmed change
+	EmailChangeRevertTTL = 7 * 24 * time.Hour
+
+	// At most maxEmailChangeRequests requests per user in emailChangeWindow
+	maxEmailChangeRequests = 3
+	emailChangeWindow      = time.Hour
+
+	emailChangeTokenPrefix       = "emc_"
+	emailChangeRevertTokenPrefix = "emr_"
+)
+
+const (
+	EmailChangeStatusPending   = "pending"
+	EmailChangeStatusConfirmed = "confirmed"
+	EmailChangeStatusReverted  = "reverted"
+)
+
+var (
+	ErrInvalidEmailChange = errors.New("invalid email change")
+	// ErrEmailChangeAddressInUse is returned when the new address belongs to
+	// another account
+	ErrEmailChangeAddressInUse = errors.New("the address is used by another account")
+	// ErrEmailChangeNotFound is returned for an unknown, expired or used
+	// link, or a change overtaken by another one
+	ErrEmailChangeNotFound    = errors.New("email change not found or no longer valid")
+	ErrEmailChangeRateLimited = errors.New("too many email change requests, try again later")
+)
+
+// ConfirmedEmailChange is the outcome of a confirmed change, with the token
+// of the link sent to the previous address to revert it
+type ConfirmedEmailChange struct {
+	Change      repository.CoreUserEmailChange
+	OldEmail    string
+	NewEmail    string
+	RevertToken string
+}
+
+// UserEmailChangeService changes the email address of a user once the new
+// address is confirmed. Updating the address directly would let anyone with a
+// session move the account to an address they do not own: the change waits
+// for the link sent to the new address, then the previous address is told
+// and can revert it.
+//
+// Environment:
+//   - USER_EMAIL_CHANGE_TTL: lifetime of a confirmation link (default 24h, at
+//     most 168h)
+type UserEmailChangeService struct {
+	store       *db.Store
+	userService UserService
+	ttl         time.Duration
+}
+
+func NewUserEmailChangeService(store *db.Store, userService UserService) *UserEmailChangeService {
+	ttl := DefaultEmailChangeTTL
+	if v := os.Getenv("USER_EMAIL_CHANGE_TTL"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+			ttl = min(parsed, MaxEmailChangeTTL)
+		} else {
+			log.Warn().Str("USER_EMAIL_CHANGE_TTL", v).Msg("Invalid duration, using default")
+		}
+	}
+	return &UserEmailChangeService{store: store, userService: userService, ttl: ttl}
+}
+
+// RequestEmailChange records a change of the address of the user and returns
+// the token of the link to send to the new address. It replaces the pending
+// change of the user.
+func (s *UserEmailChangeService) RequestEmailChange(ctx context.Context, tenantID, userID, email string) (string, repository.CoreUserEmailChange, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("%w: invalid email address", ErrInvalidEmailChange)
+	}
+	user, err := s.store.GetSharedUserByID(ctx, userID)
+	if err != nil {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("service.RequestEmailChange: %w", err)
+	}
+	if strings.EqualFold(DecryptEmail(user.Email).String, email) {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("%w: the address is already the one of the account", ErrInvalidEmailChange)
+	}
+	if err := s.checkAddressFree(ctx, userID, email); err != nil {
+		return "", repository.CoreUserEmailChange{}, err
+	}
+	recent, err := s.store.CountRecentUserEmailChanges(ctx, repository.CountRecentUserEmailChangesParams{
+		UserID: userID,
+		Since:  time.Now().Add(-emailChangeWindow),
+	})
+	if err != nil {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("service.RequestEmailChange: %w", err)
+	}
+	if recent >= maxEmailChangeRequests {
+		return "", repository.CoreUserEmailChange{}, ErrEmailChangeRateLimited
+	}
+
+	token, hash, err := newEmailChangeToken(emailChangeTokenPrefix)
+	if err != nil {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("service.RequestEmailChange: %w", err)
+	}
+	stored, _, err := EncryptEmail(email)
+	if err != nil {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("service.RequestEmailChange: %w", err)
+	}
+	if err := s.store.CancelPendingUserEmailChanges(ctx, userID); err != nil {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("service.RequestEmailChange: %w", err)
+	}
+	change, err := s.store.CreateUserEmailChange(ctx, repository.CreateUserEmailChangeParams{
+		TenantID:  tenantID,
+		UserID:    userID,
+		OldEmail:  user.Email.String,
+		NewEmail:  stored,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return "", repository.CoreUserEmailChange{}, fmt.Errorf("service.RequestEmailChange: %w", err)
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("email_change_id", change.ID.String()).Str("user_id", userID).Msg("Email change requested")
+	return token, change, nil
+}
+
+// ConfirmEmailChange uses the token sent to the new address once. The address
+// is changed in the auth provider, marked verified as the link reached it,
+// then in core_users; the provider is put back when the second step fails.
+func (s *UserEmailChangeService) ConfirmEmailChange(ctx context.Context, authClient auth.AuthClient, tenantID, token string) (ConfirmedEmailChange, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if !strings.HasPrefix(token, emailChangeTokenPrefix) {
+		return ConfirmedEmailChange{}, ErrEmailChangeNotFound
+	}
+	change, err := s.store.GetUserEmailChangeByTokenHash(ctx, emailChangeTokenHash(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ConfirmedEmailChange{}, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return ConfirmedEmailChange{}, fmt.Errorf("service.ConfirmEmailChange: %w", err)
+	}
+	if change.TenantID != tenantID || change.Status != EmailChangeStatusPending || !time.Now().Before(change.ExpiresAt) {
+		return ConfirmedEmailChange{}, ErrEmailChangeNotFound
+	}
+	oldEmail := EmailChangeAddress(change.OldEmail)
+	newEmail := EmailChangeAddress(change.NewEmail)
+	user, err := s.store.GetSharedUserByID(ctx, change.UserID)
+	if err != nil {
+		return ConfirmedEmailChange{}, fmt.Errorf("service.ConfirmEmailChange: %w", err)
+	}
+	// The address changed since the request, e.g. by an earlier revert
+	if DecryptEmail(user.Email).String != oldEmail {
+		return ConfirmedEmailChange{}, ErrEmailChangeNotFound
+	}
+	if err := s.checkAddressFree(ctx, change.UserID, newEmail); err != nil {
+		return ConfirmedEmailChange{}, err
+	}
+
+	revertToken, revertHash, err := newEmailChangeToken(emailChangeRevertTokenPrefix)
+	if err != nil {
+		return ConfirmedEmailChange{}, fmt.Errorf("service.ConfirmEmailChange: %w", err)
+	}
+	change, err = s.store.ClaimUserEmailChange(ctx, repository.ClaimUserEmailChangeParams{
+		RevertTokenHash: revertHash,
+		RevertExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(EmailChangeRevertTTL), Valid: true},
+		TokenHash:       emailChangeTokenHash(token),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ConfirmedEmailChange{}, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return ConfirmedEmailChange{}, fmt.Errorf("service.ConfirmEmailChange: %w", err)
+	}
+	if err := s.setEmail(ctx, authClient, user, oldEmail, newEmail); err != nil {
+		// The link works again for another attempt
+		if releaseErr := s.store.ReleaseUserEmailChange(context.WithoutCancel(ctx), change.ID); releaseErr != nil {
+			logger.Err(releaseErr).Str("email_change_id", change.ID.String()).Msg("Failed to release email change")
+		}
+		return ConfirmedEmailChange{}, err
+	}
+
+	s.record(ctx, change, "email_changed", change.UserID, nil)
+	logger.Info().Str("email_change_id", change.ID.String()).Str("user_id", change.UserID).Msg("Email changed")
+	return ConfirmedEmailChange{Change: change, OldEmail: oldEmail, NewEmail: newEmail, RevertToken: revertToken}, nil
+}
+
+// RevertEmailChange uses the token sent to the previous address once, and
+// puts it back. The user is signed out everywhere, as whoever made the change
+// may hold a session.
+func (s *UserEmailChangeService) RevertEmailChange(ctx context.Context, authClient auth.AuthClient, tenantID, token string) (repository.CoreUserEmailChange, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	if !strings.HasPrefix(token, emailChangeRevertTokenPrefix) {
+		return repository.CoreUserEmailChange{}, ErrEmailChangeNotFound
+	}
+	change, err := s.store.GetUserEmailChangeByRevertTokenHash(ctx, emailChangeTokenHash(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserEmailChange{}, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return repository.CoreUserEmailChange{}, fmt.Errorf("service.RevertEmailChange: %w", err)
+	}
+	if change.TenantID != tenantID || change.Status != EmailChangeStatusConfirmed {
+		return repository.CoreUserEmailChange{}, ErrEmailChangeNotFound
+	}
+	oldEmail := EmailChangeAddress(change.OldEmail)
+	newEmail := EmailChangeAddress(change.NewEmail)
+	user, err := s.store.GetSharedUserByID(ctx, change.UserID)
+	if err != nil {
+		return repository.CoreUserEmailChange{}, fmt.Errorf("service.RevertEmailChange: %w", err)
+	}
+	// A later change is reverted with its own link
+	if DecryptEmail(user.Email).String != newEmail {
+		return repository.CoreUserEmailChange{}, ErrEmailChangeNotFound
+	}
+
+	change, err = s.store.ClaimUserEmailChangeRevert(ctx, emailChangeTokenHash(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserEmailChange{}, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return repository.CoreUserEmailChange{}, fmt.Errorf("service.RevertEmailChange: %w", err)
+	}
+	if err := s.setEmail(ctx, authClient, user, newEmail, oldEmail); err != nil {
+		if releaseErr := s.store.ReleaseUserEmailChangeRevert(context.WithoutCancel(ctx), change.ID); releaseErr != nil {
+			logger.Err(releaseErr).Str("email_change_id", change.ID.String()).Msg("Failed to release email change revert")
+		}
+		return repository.CoreUserEmailChange{}, err
+	}
+	if err := authClient.RevokeUserSessions(ctx, change.UserID); err != nil {
+		logger.Err(err).Str("user_id", change.UserID).Msg("Failed to revoke sessions after reverting email change")
+	}
+
+	s.record(ctx, change, "email_change_reverted", "", map[string]interface{}{"reverted": true})
+	logger.Info().Str("email_change_id", change.ID.String()).Str("user_id", change.UserID).Msg("Email change reverted")
+	return change, nil
+}
+
+// setEmail moves the user from one address to the other, in the auth
+// provider then in core_users
+func (s *UserEmailChangeService) setEmail(ctx context.Context, authClient auth.AuthClient, user repository.CoreUser, from, to string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	record, err := authClient.GetUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("service.setEmail: %w", err)
+	}
+	if _, err := authClient.UpdateUser(ctx, user.ID, (&auth.UserToUpdate{}).Email(to).EmailVerified(true)); err != nil {
+		return fmt.Errorf("service.setEmail: %w", err)
+	}
+
+	stored, blindIndex, err := EncryptEmail(to)
+	if err == nil {
+		var updated int64
+		updated, err = s.store.UpdateUserEmailStorage(ctx, repository.UpdateUserEmailStorageParams{
+			Email:           stored,
+			EmailBlindIndex: blindIndex,
+			ID:              user.ID,
+			PreviousEmail:   user.Email.String,
+		})
+		if err == nil && updated == 0 {
+			err = ErrEmailChangeNotFound
+		}
+	}
+	if err != nil {
+		// The provider gets the previous address back, so both agree
+		restore := (&auth.UserToUpdate{}).Email(from).EmailVerified(record.EmailVerified)
+		if _, restoreErr := authClient.UpdateUser(context.WithoutCancel(ctx), user.ID, restore); restoreErr != nil {
+			logger.Err(restoreErr).Str("user_id", user.ID).Msg("Failed to restore the email of the user in the auth provider")
+		}
+		if errors.Is(err, ErrEmailChangeNotFound) {
+			return err
+		}
+		return fmt.Errorf("service.setEmail: %w", err)
+	}
+	recordUserAccountState(ctx, s.store, user.ID, pgtype.Bool{}, pgtype.Bool{Bool: true, Valid: true})
+	return nil
+}
+
+// checkAddressFree returns ErrEmailChangeAddressInUse when the address
+// belongs to another account
+func (s *UserEmailChangeService) checkAddressFree(ctx context.Context, userID, email string) error {
+	existing, err := s.userService.GetUserByEmailGlobal(ctx, email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("service.checkAddressFree: %w", err)
+	}
+	if existing != nil && existing.Id != userID {
+		return ErrEmailChangeAddressInUse
+	}
+	return nil
+}
+
+// record adds the change to the timeline and the audit trail of the user.
+// The addresses are left out, they are encrypted at rest.
+func (s *UserEmailChangeService) record(ctx context.Context, change repository.CoreUserEmailChange, eventType, actorID string, data map[string]interface{}) {
+	logger := util.GetLoggerFromCtx(ctx)
+	details := map[string]interface{}{"email_change_id": change.ID.String()}
+	for k, v := range data {
+		details[k] = v
+	}
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  change.TenantID,
+		UserID:    change.UserID,
+		Category:  ActivityCategoryAccount,
+		EventType: eventType,
+		ActorID:   actorID,
+		Data:      details,
+	}); err != nil {
+		logger.Err(err).Str("email_change_id", change.ID.String()).Msg("Failed to record email change on the timeline")
+	}
+	_ = recordUserAudit(ctx, s.store, UserAuditEntry{
+		TenantID: change.TenantID,
+		UserID:   change.UserID,
+		Action:   UserAuditActionEmailChanged,
+		ActorID:  actorID,
+		Details:  details,
+	})
+}
+
+// EmailChangeAddress returns an address of a change in clear
+func EmailChangeAddress(stored string) string {
+	return DecryptEmail(pgtype.Text{String: stored, Valid: true}).String
+}
+
+func newEmailChangeToken(prefix string) (string, []byte, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, err
+	}
+	token := prefix + base64.RawURLEncoding.EncodeToString(random)
+	return token, emailChangeTokenHash(token), nil
+}
+
+func emailChangeTokenHash(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmailChangeToken(t *testing.T) {
+	token, hash, err := newEmailChangeToken(emailChangeTokenPrefix)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, emailChangeTokenPrefix))
+	require.Equal(t, hash, emailChangeTokenHash(token))
+
+	revertToken, revertHash, err := newEmailChangeToken(emailChangeRevertTokenPrefix)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(revertToken, emailChangeRevertTokenPrefix))
+	require.NotEqual(t, hash, revertHash)
+}
+
+func TestRequestEmailChangeRejectsInvalidAddress(t *testing.T) {
+	s := &UserEmailChangeService{}
+	for _, email := range []string{"", "not-an-address", "Jane <jane@example.com>"} {
+		_, _, err := s.RequestEmailChange(context.Background(), "tenant", "user", email)
+		require.ErrorIs(t, err, ErrInvalidEmailChange, email)
+	}
+}
+
+func TestEmailChangeTokensAreNotInterchangeable(t *testing.T) {
+	s := &UserEmailChangeService{}
+	// The revert token does not confirm a change, and the other way around
+	_, err := s.ConfirmEmailChange(context.Background(), nil, "tenant", emailChangeRevertTokenPrefix+"x")
+	require.ErrorIs(t, err, ErrEmailChangeNotFound)
+	_, err = s.RevertEmailChange(context.Background(), nil, "tenant", emailChangeTokenPrefix+"x")
+	require.ErrorIs(t, err, ErrEmailChangeNotFound)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UserTombstonePrefix starts the ids replacing erased users, so a reader of
+// the audit tables can tell them from live users
+const UserTombstonePrefix = "erased-"
+
+var (
+	// ErrErasureNotConfirmed is returned when the caller did not acknowledge
+	// that the erasure cannot be undone
+	ErrErasureNotConfirmed = errors.New("the erasure cannot be undone and must be confirmed")
+	// ErrErasureOutOfTenant is returned when a tenant admin erases a user who
+	// also belongs to other tenants or holds a global role
+	ErrErasureOutOfTenant = errors.New("the user belongs to other tenants, only a SUPER_ADMIN can erase them")
+)
+
+// UserErasureFunc replaces the user with the tombstone in the tables of a
+// module and returns the number of rows changed. It runs in the transaction of
+// the erasure.
+type UserErasureFunc func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error)
+
+type userErasureStep struct {
+	name  string
+	erase UserErasureFunc
+}
+
+var (
+	userErasureStepsMu sync.RWMutex
+	userErasureSteps   = []userErasureStep{
+		{name: "activity_events", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).AnonymizeUserActivityEvents(ctx, repository.AnonymizeUserActivityEventsParams{
+				UserID:      userID,
+				TombstoneID: tombstoneID,
+			})
+		}},
+		{name: "audit_logs", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).AnonymizeUserAuditLogs(ctx, repository.AnonymizeUserAuditLogsParams{
+				UserID:      userID,
+				TombstoneID: tombstoneID,
+			})
+		}},
+		{name: "impersonations", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).AnonymizeUserImpersonations(ctx, repository.AnonymizeUserImpersonationsParams{
+				UserID:      userID,
+				TombstoneID: tombstoneID,
+			})
+		}},
+		{name: "invitations", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).AnonymizeUserInvitations(ctx, repository.AnonymizeUserInvitationsParams{
+				UserID:      userID,
+				TombstoneID: tombstoneID,
+			})
+		}},
+		{name: "permission_delegations", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).AnonymizePermissionDelegations(ctx, repository.AnonymizePermissionDelegationsParams{
+				UserID:      userID,
+				TombstoneID: tombstoneID,
+			})
+		}},
+		{name: "replay_bundles", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserReplayBundles(ctx, pgtype.Text{String: userID, Valid: true})
+		}},
+		{name: "attributions", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).AnonymizeUserAttributions(ctx, repository.AnonymizeUserAttributionsParams{
+				TombstoneID: tombstoneID,
+				UserID:      userID,
+			})
+		}},
+		{name: "directory_links", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserDirectoryLinks(ctx, userID)
+		}},
+		{name: "email_changes", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserEmailChanges(ctx, userID)
+		}},
+		{name: "phone_verifications", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserPhoneVerifications(ctx, userID)
+		}},
+		{name: "deletion_requests", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserDeletionRequests(ctx, userID)
+		}},
+		{name: "group_memberships", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserGroupMemberships(ctx, userID)
+		}},
+		{name: "custom_role_memberships", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			return repository.New(tx).DeleteUserRoleMemberships(ctx, userID)
+		}},
+		{name: "auth_lockouts", erase: func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+			_, err := repository.New(tx).DeleteAuthLockout(ctx, repository.DeleteAuthLockoutParams{
+				SubjectType: LockoutSubjectUser,
+				Subject:     userID,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+			return 1, nil
+		}},
+	}
+)
+
+// RegisterUserErasureStep lets a module, such as prompts and their
+// executions, replace the erased users in its own tables
+func RegisterUserErasureStep(name string, erase UserErasureFunc) {
+	userErasureStepsMu.Lock()
+	defer userErasureStepsMu.Unlock()
+	userErasureSteps = append(userErasureSteps, userErasureStep{name: name, erase: erase})
+}
+
+func getUserErasureSteps() []userErasureStep {
+	userErasureStepsMu.RLock()
+	defer userErasureStepsMu.RUnlock()
+	return append([]userErasureStep{}, userErasureSteps...)
+}
+
+// UserErasure is the outcome of an erasure, kept on the activity feed under
+// the tombstone
+type UserErasure struct {
+	TombstoneID string           `json:"tombstoneId"`
+	Rows        map[string]int64 `json:"rows"`
+}
+
+// UserErasureService implements the right to erasure: the user is deleted
+// like a hard delete, but the rows recording what they did stay, with a
+// tombstone id in place of theirs, so the audit trail keeps its shape without
+// pointing to a person.
+type UserErasureService struct {
+	store *db.Store
+}
+
+func NewUserErasureService(store *db.Store) *UserErasureService {
+	return &UserErasureService{store: store}
+}
+
+// CheckTenantErasure returns ErrErasureOutOfTenant unless the tenant is the
+// only place the user is known, the condition for a tenant admin to erase them
+func (s *UserErasureService) CheckTenantErasure(ctx context.Context, userID string) error {
+	user, err := s.store.GetSharedUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service.CheckTenantErasure: %w", err)
+	}
+	tenants, err := s.store.CountUserTenants(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service.CheckTenantErasure: %w", err)
+	}
+	if len(user.Roles) > 0 || tenants > 1 {
+		return ErrErasureOutOfTenant
+	}
+	return nil
+}
+
+// AnonymizeUser erases the user: the resources they own go to the transfer
+// target, every reference in the audit tables is replaced with a tombstone,
+// then the user, their memberships and their identity at the auth provider
+// are deleted. The erasure is recorded on the activity feed of the tombstone.
+func (s *UserErasureService) AnonymizeUser(c *gin.Context, authClient auth.AuthClient, userID string, transfer OwnershipTransfer) (UserErasure, error) {
+	return s.EraseUser(c, authClient, userID, c.GetString(auth.AUTH_USER_ID), transfer)
+}
+
+// EraseUser is AnonymizeUser outside of a request, such as the scheduled
+// account deletions. An empty actorID stands for the system.
+func (s *UserErasureService) EraseUser(ctx context.Context, authClient auth.AuthClient, userID, actorID string, transfer OwnershipTransfer) (UserErasure, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	erasure := UserErasure{
+		TombstoneID: UserTombstonePrefix + uuid.NewString(),
+		Rows:        map[string]int64{},
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return UserErasure{}, fmt.Errorf("service.EraseUser: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	if err := transferOwnedResources(ctx, tx, "", userID, transfer, actorID); err != nil {
+		return UserErasure{}, err
+	}
+	for _, step := range getUserErasureSteps() {
+		rows, err := step.erase(ctx, tx, userID, erasure.TombstoneID)
+		if err != nil {
+			return UserErasure{}, fmt.Errorf("service.EraseUser: %s: %w", step.name, err)
+		}
+		erasure.Rows[step.name] = rows
+	}
+	// The row holds the email and the profile, its deletion cascades to the
+	// memberships, the MFA secrets, the verification tokens and the last logins
+	if _, err := qtx.DeleteSharedUser(ctx, userID); err != nil {
+		return UserErasure{}, fmt.Errorf("service.EraseUser: %w", err)
+	}
+
+	data, err := json.Marshal(erasure)
+	if err != nil {
+		return UserErasure{}, fmt.Errorf("service.EraseUser: %w", err)
+	}
+	// An actor erasing themselves is not left in the trail either
+	if actorID == userID {
+		actorID = erasure.TombstoneID
+	}
+	if err := qtx.CreateUserActivityEvent(ctx, repository.CreateUserActivityEventParams{
+		UserID:    erasure.TombstoneID,
+		Category:  ActivityCategoryAccount,
+		EventType: "erased",
+		ActorID:   pgtype.Text{String: actorID, Valid: actorID != ""},
+		Data:      data,
+	}); err != nil {
+		return UserErasure{}, fmt.Errorf("service.EraseUser: %w", err)
+	}
+
+	// Last, as it cannot be rolled back
+	if err := authClient.DeleteUser(ctx, userID); err != nil && !auth.IsUserNotFound(err) {
+		return UserErasure{}, fmt.Errorf("service.EraseUser: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		logger.Err(err).Str("tombstone_id", erasure.TombstoneID).Msg("Failed to commit user erasure after the identity was deleted")
+		return UserErasure{}, fmt.Errorf("service.EraseUser: %w", err)
+	}
+
+	// The user id is not logged, the tombstone is what the trail keeps
+	logger.Info().Str("tombstone_id", erasure.TombstoneID).Str("actor_id", actorID).
+		Interface("rows", erasure.Rows).Msg("User erased")
+	return erasure, nil
+}
+
+// IsUserTombstone reports whether the id replaces an erased user
+func IsUserTombstone(id string) bool {
+	return strings.HasPrefix(id, UserTombstonePrefix)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterUserErasureStep(t *testing.T) {
+	userErasureStepsMu.Lock()
+	saved := userErasureSteps
+	userErasureStepsMu.Unlock()
+	t.Cleanup(func() {
+		userErasureStepsMu.Lock()
+		userErasureSteps = saved
+		userErasureStepsMu.Unlock()
+	})
+
+	RegisterUserErasureStep("prompts", func(ctx context.Context, tx pgx.Tx, userID, tombstoneID string) (int64, error) {
+		return 2, nil
+	})
+
+	steps := getUserErasureSteps()
+	require.Len(t, steps, len(saved)+1)
+	// The core tables come first, the modules after them
+	require.Equal(t, "activity_events", steps[0].name)
+	require.Equal(t, "prompts", steps[len(steps)-1].name)
+	rows, err := steps[len(steps)-1].erase(context.Background(), nil, "user-1", UserTombstonePrefix+"x")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rows)
+}
+
+func TestIsUserTombstone(t *testing.T) {
+	require.True(t, IsUserTombstone(UserTombstonePrefix+"5f1c2b9e-0000-4000-8000-000000000000"))
+	require.False(t, IsUserTombstone("kratos-identity-id"))
+	require.False(t, IsUserTombstone(""))
+}
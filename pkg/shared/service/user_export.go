@@ -0,0 +1,195 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	sqlservice "ctoup.com/coreapp/pkg/shared/sql"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Formats of the user export. The CSV uses semicolons, like the user import.
+const (
+	UserExportFormatCSV  = TenantExportFormatCSV
+	UserExportFormatXLSX = "xlsx"
+
+	userExportPageSize = 500
+)
+
+// ErrInvalidUserExportFormat is returned for an unknown export format
+var ErrInvalidUserExportFormat = errors.New("format must be csv or xlsx")
+
+var userExportHeader = []string{"email", "name", "roles", "status", "created_at", "last_login"}
+
+// UserExportFormat normalizes the requested format, csv by default
+func UserExportFormat(format string) (string, error) {
+	switch format {
+	case "", UserExportFormatCSV:
+		return UserExportFormatCSV, nil
+	case UserExportFormatXLSX:
+		return UserExportFormatXLSX, nil
+	}
+	return "", ErrInvalidUserExportFormat
+}
+
+// UserExportContentType is the media type of an export in the format
+func UserExportContentType(format string) string {
+	if format == UserExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// UserExportFilter holds the filters of ListUsers
+type UserExportFilter struct {
+	TenantID string
+	Search   pgtype.Text
+	// All exports every user system-wide instead of the tenant users, or the
+	// holders of a global role on the admin domain
+	All bool
+}
+
+// UserExportService writes the users listed by ListUsers to a file
+type UserExportService struct {
+	store       *db.Store
+	userService UserService
+}
+
+func NewUserExportService(store *db.Store, userService UserService) *UserExportService {
+	return &UserExportService{store: store, userService: userService}
+}
+
+// userRowWriter writes the rows of an export in its format
+type userRowWriter interface {
+	writeRow(cells []string) error
+	flush() error
+	close() error
+}
+
+// csvRowWriter writes semicolon separated rows after a byte order mark, so
+// spreadsheets detect UTF-8
+type csvRowWriter struct {
+	csv *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer) (*csvRowWriter, error) {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	return &csvRowWriter{csv: writer}, nil
+}
+
+func (r *csvRowWriter) writeRow(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = neutralizeCSVFormula(cell)
+	}
+	return r.csv.Write(escaped)
+}
+
+func (r *csvRowWriter) flush() error {
+	r.csv.Flush()
+	return r.csv.Error()
+}
+
+func (r *csvRowWriter) close() error {
+	return r.flush()
+}
+
+// neutralizeCSVFormula prefixes the values a spreadsheet would evaluate as a
+// formula, such as a user name starting with =
+func neutralizeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ExportUsers writes the users matching the filter to w, with their last
+// login, in pages of the same queries as ListUsers. When w is an
+// http.Flusher it is flushed after each page. It returns the number of users
+// written.
+func (s *UserExportService) ExportUsers(c *gin.Context, filter UserExportFilter, format string, w io.Writer) (int64, error) {
+	logger := util.GetLoggerFromCtx(c)
+	format, err := UserExportFormat(format)
+	if err != nil {
+		return 0, err
+	}
+	var rows userRowWriter
+	if format == UserExportFormatXLSX {
+		rows, err = newXLSXWriter(w)
+	} else {
+		rows, err = newCSVRowWriter(w)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("service.ExportUsers: %w", err)
+	}
+	if err := rows.writeRow(userExportHeader); err != nil {
+		return 0, fmt.Errorf("service.ExportUsers: %w", err)
+	}
+	flusher, _ := w.(http.Flusher)
+
+	var written int64
+	for offset := int32(0); ; offset += userExportPageSize {
+		paging := sqlservice.PagingSQL{Offset: offset, PageSize: userExportPageSize, SortBy: "email", Order: "asc"}
+		var users []core.User
+		if filter.All {
+			users, err = s.userService.ListAllUsers(c, paging, UserListFilter{Search: filter.Search})
+		} else {
+			users, err = s.userService.ListUsers(c, filter.TenantID, paging, UserListFilter{Search: filter.Search})
+		}
+		if err != nil {
+			logger.Err(err).Str("tenantID", filter.TenantID).Msg("Failed to list users for export")
+			return written, fmt.Errorf("service.ExportUsers: %w", err)
+		}
+
+		for _, user := range users {
+			if err := rows.writeRow(userExportRow(user)); err != nil {
+				return written, fmt.Errorf("service.ExportUsers: %w", err)
+			}
+			written++
+		}
+		if len(users) < userExportPageSize {
+			break
+		}
+		if err := rows.flush(); err != nil {
+			return written, fmt.Errorf("service.ExportUsers: %w", err)
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if err := rows.close(); err != nil {
+		return written, fmt.Errorf("service.ExportUsers: %w", err)
+	}
+	return written, nil
+}
+
+func userExportRow(user core.User) []string {
+	roles := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = string(role)
+	}
+	row := []string{user.Email, user.Name, strings.Join(roles, ","), "", "", ""}
+	if user.MembershipStatus != nil {
+		row[3] = *user.MembershipStatus
+	}
+	if user.CreatedAt != nil {
+		row[4] = user.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if user.LastLoginAt != nil {
+		row[5] = user.LastLoginAt.UTC().Format(time.RFC3339)
+	}
+	return row
+}
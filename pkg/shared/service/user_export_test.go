@@ -0,0 +1,92 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserExportFormat(t *testing.T) {
+	format, err := UserExportFormat("")
+	require.NoError(t, err)
+	require.Equal(t, UserExportFormatCSV, format)
+
+	format, err = UserExportFormat("xlsx")
+	require.NoError(t, err)
+	require.Equal(t, UserExportFormatXLSX, format)
+
+	_, err = UserExportFormat("ndjson")
+	require.ErrorIs(t, err, ErrInvalidUserExportFormat)
+}
+
+func TestUserExportRow(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	status := "active"
+	row := userExportRow(core.User{
+		Email:            "jane@example.com",
+		Name:             "Jane",
+		Roles:            []core.Role{core.USER, core.CUSTOMERADMIN},
+		CreatedAt:        &created,
+		MembershipStatus: &status,
+	})
+	require.Equal(t, []string{"jane@example.com", "Jane", "USER,CUSTOMER_ADMIN", "active", "2026-01-02T03:04:05Z", ""}, row)
+
+	lastLogin := time.Date(2026, 2, 3, 4, 5, 6, 0, time.FixedZone("CET", 3600))
+	row = userExportRow(core.User{Email: "john@example.com", LastLoginAt: &lastLogin})
+	require.Equal(t, "2026-02-03T03:05:06Z", row[5])
+}
+
+func TestCSVRowWriterNeutralizesFormulas(t *testing.T) {
+	var out bytes.Buffer
+	w, err := newCSVRowWriter(&out)
+	require.NoError(t, err)
+	require.NoError(t, w.writeRow([]string{"=HYPERLINK(\"x\")", "Jane; Doe", "-1"}))
+	require.NoError(t, w.close())
+
+	require.True(t, strings.HasPrefix(out.String(), "\uFEFF"))
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(out.String(), "\uFEFF")))
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"'=HYPERLINK(\"x\")", "Jane; Doe", "'-1"}}, records)
+}
+
+func TestXLSXWriterProducesWorkbook(t *testing.T) {
+	var out bytes.Buffer
+	w, err := newXLSXWriter(&out)
+	require.NoError(t, err)
+	require.NoError(t, w.writeRow(userExportHeader))
+	require.NoError(t, w.writeRow([]string{"jane@example.com", "Jane <&> \x01", ""}))
+	require.NoError(t, w.close())
+
+	archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = string(content)
+	}
+	require.Contains(t, parts, "[Content_Types].xml")
+	require.Contains(t, parts, "xl/workbook.xml")
+
+	var sheet struct {
+		Rows []struct {
+			Cells []string `xml:"c>is>t"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet))
+	require.Len(t, sheet.Rows, 2)
+	require.Equal(t, userExportHeader, sheet.Rows[0].Cells)
+	require.Equal(t, "Jane <&> \uFFFD", sheet.Rows[1].Cells[1])
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Codes of the changes the user guards refuse
+const (
+	UserGuardCodeDisableSelf       = "CANNOT_DISABLE_SELF"
+	UserGuardCodeDemoteSelf        = "CANNOT_DEMOTE_SELF"
+	UserGuardCodeDeleteSelf        = "CANNOT_DELETE_SELF"
+	UserGuardCodeLastCustomerAdmin = "LAST_CUSTOMER_ADMIN"
+)
+
+// UserGuardAction is a change of a user the guards check
+type UserGuardAction string
+
+const (
+	UserGuardActionDisable UserGuardAction = "disable"
+	UserGuardActionDemote  UserGuardAction = "demote"
+	UserGuardActionDelete  UserGuardAction = "delete"
+)
+
+// UserGuardError is a change refused by the guards, with its code
+type UserGuardError struct {
+	Code    string
+	Message string
+}
+
+func (e *UserGuardError) Error() string {
+	return e.Message
+}
+
+// Status is the HTTP status of the refusal: acting on oneself is forbidden,
+// while the last CUSTOMER_ADMIN conflicts with the state of the tenant
+func (e *UserGuardError) Status() int {
+	if e.Code == UserGuardCodeLastCustomerAdmin {
+		return http.StatusConflict
+	}
+	return http.StatusForbidden
+}
+
+var selfGuardErrors = map[UserGuardAction]*UserGuardError{
+	UserGuardActionDisable: {Code: UserGuardCodeDisableSelf, Message: "you cannot disable your own account"},
+	UserGuardActionDemote:  {Code: UserGuardCodeDemoteSelf, Message: "you cannot remove a role from your own account"},
+	UserGuardActionDelete:  {Code: UserGuardCodeDeleteSelf, Message: "you cannot delete or remove your own account"},
+}
+
+// UserGuardPolicy keeps admins from locking themselves or their tenant out:
+// they cannot disable, demote or delete their own account, nor the last
+// CUSTOMER_ADMIN able to act in a tenant
+type UserGuardPolicy struct {
+	store *db.Store
+}
+
+func NewUserGuardPolicy(store *db.Store) *UserGuardPolicy {
+	return &UserGuardPolicy{store: store}
+}
+
+// Check returns a *UserGuardError when actorID may not apply the action to
+// userID in the tenant. role is the role removed by a demotion. The last
+// CUSTOMER_ADMIN is only checked in a tenant.
+func (p *UserGuardPolicy) Check(ctx context.Context, tenantID, actorID, userID string, action UserGuardAction, role core.Role) error {
+	if userID == actorID {
+		return selfGuardErrors[action]
+	}
+	if tenantID == "" || (action == UserGuardActionDemote && role != core.CUSTOMERADMIN) {
+		return nil
+	}
+	roles, err := p.store.GetUserTenantRoles(ctx, repository.GetUserTenantRolesParams{
+		UserID:   userID,
+		TenantID: tenantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Not an active member, the handler answers for the user
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("service.Check: %w", err)
+	}
+	if !util.Contains(roles, string(core.CUSTOMERADMIN)) {
+		return nil
+	}
+	others, err := p.store.CountOtherActiveCustomerAdmins(ctx, repository.CountOtherActiveCustomerAdminsParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.Check: %w", err)
+	}
+	if others == 0 {
+		return &UserGuardError{
+			Code:    UserGuardCodeLastCustomerAdmin,
+			Message: fmt.Sprintf("the user is the last CUSTOMER_ADMIN of the tenant, make another user CUSTOMER_ADMIN before you %s them", action),
+		}
+	}
+	return nil
+}
+
+// guardedUserRoute is a route changing a user the guards apply to
+type guardedUserRoute struct {
+	method string
+	path   string
+	// superAdmin routes name the tenant by its id in the path
+	superAdmin bool
+	// action returns the action of the request, or false when it is not
+	// guarded, like enabling a user
+	action func(c *gin.Context) (UserGuardAction, bool)
+}
+
+func guardedAction(action UserGuardAction) func(c *gin.Context) (UserGuardAction, bool) {
+	return func(c *gin.Context) (UserGuardAction, bool) { return action, true }
+}
+
+// guardedStatusAction guards the status changes disabling the user
+func guardedStatusAction(c *gin.Context) (UserGuardAction, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return "", false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	var req core.UpdateUserStatusJSONBody
+	// A body the handler cannot bind is rejected by the handler
+	if json.Unmarshal(body, &req) != nil {
+		return "", false
+	}
+	return UserGuardActionDisable, req.Name == "DISABLED" && req.Value
+}
+
+var guardedUserRoutes = []guardedUserRoute{
+	{method: http.MethodDelete, path: "/api/v1/users/:userid", action: guardedAction(UserGuardActionDelete)},
+	{method: http.MethodDelete, path: "/api/v1/users/:userid/remove-from-tenant", action: guardedAction(UserGuardActionDelete)},
+	{method: http.MethodPost, path: "/api/v1/users/:userid/roles/:role/unassign", action: guardedAction(UserGuardActionDemote)},
+	{method: http.MethodPost, path: "/api/v1/users/:userid/status", action: guardedStatusAction},
+	{method: http.MethodDelete, path: "/superadmin-api/v1/tenants/:tenantid/users/:userid", superAdmin: true, action: guardedAction(UserGuardActionDelete)},
+	{method: http.MethodDelete, path: "/superadmin-api/v1/tenants/:tenantid/users/:userid/hard-delete", superAdmin: true, action: guardedAction(UserGuardActionDelete)},
+	{method: http.MethodDelete, path: "/superadmin-api/v1/tenants/:tenantid/users/:userid/remove-from-tenant", superAdmin: true, action: guardedAction(UserGuardActionDelete)},
+	{method: http.MethodPost, path: "/superadmin-api/v1/tenants/:tenantid/users/:userid/roles/:role/unassign", superAdmin: true, action: guardedAction(UserGuardActionDemote)},
+	{method: http.MethodPost, path: "/superadmin-api/v1/tenants/:tenantid/users/:userid/status", superAdmin: true, action: guardedStatusAction},
+}
+
+func findGuardedUserRoute(method, path string) (guardedUserRoute, bool) {
+	for _, route := range guardedUserRoutes {
+		if route.method == method && route.path == path {
+			return route, true
+		}
+	}
+	return guardedUserRoute{}, false
+}
+
+// Middleware applies the guards to the routes disabling, demoting or deleting
+// a user, before their handler. It runs after the auth middleware, as it needs
+// the caller. A refused change answers with the code of the guard.
+func (p *UserGuardPolicy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, ok := findGuardedUserRoute(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+		action, guarded := route.action(c)
+		if !guarded {
+			c.Next()
+			return
+		}
+		logger := util.GetLoggerFromCtx(c.Request.Context())
+
+		tenantID := c.GetString(auth.AUTH_TENANT_ID_KEY)
+		if route.superAdmin {
+			tenantID = ""
+			if id, err := uuid.Parse(c.Param("tenantid")); err == nil {
+				if tenant, err := p.store.GetTenantByID(c, id); err == nil {
+					tenantID = tenant.TenantID
+				}
+			}
+		}
+		err := p.Check(c, tenantID, c.GetString(auth.AUTH_USER_ID), c.Param("userid"), action, core.Role(c.Param("role")))
+		var refused *UserGuardError
+		if errors.As(err, &refused) {
+			logger.Warn().Str("user_id", c.Param("userid")).Str("code", refused.Code).Msg("User change refused by guard")
+			c.AbortWithStatusJSON(refused.Status(), gin.H{
+				"message": refused.Message,
+				"code":    refused.Code,
+			})
+			return
+		}
+		if err != nil {
+			logger.Err(err).Msg("Failed to check user guards")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserGuardSelf(t *testing.T) {
+	policy := NewUserGuardPolicy(nil)
+	for action, code := range map[UserGuardAction]string{
+		UserGuardActionDisable: UserGuardCodeDisableSelf,
+		UserGuardActionDemote:  UserGuardCodeDemoteSelf,
+		UserGuardActionDelete:  UserGuardCodeDeleteSelf,
+	} {
+		err := policy.Check(t.Context(), "tenant", "u1", "u1", action, "USER")
+		var refused *UserGuardError
+		require.ErrorAs(t, err, &refused)
+		require.Equal(t, code, refused.Code)
+		require.Equal(t, http.StatusForbidden, refused.Status())
+	}
+
+	// Out of a tenant and for a role other than CUSTOMER_ADMIN, there is no
+	// last CUSTOMER_ADMIN to check
+	require.NoError(t, policy.Check(t.Context(), "", "u1", "u2", UserGuardActionDelete, ""))
+	require.NoError(t, policy.Check(t.Context(), "tenant", "u1", "u2", UserGuardActionDemote, "USER"))
+	require.Equal(t, http.StatusConflict, (&UserGuardError{Code: UserGuardCodeLastCustomerAdmin}).Status())
+}
+
+func TestGuardedUserRoutes(t *testing.T) {
+	route, ok := findGuardedUserRoute(http.MethodDelete, "/api/v1/users/:userid")
+	require.True(t, ok)
+	action, guarded := route.action(nil)
+	require.True(t, guarded)
+	require.Equal(t, UserGuardActionDelete, action)
+
+	_, ok = findGuardedUserRoute(http.MethodGet, "/api/v1/users/:userid")
+	require.False(t, ok)
+	_, ok = findGuardedUserRoute(http.MethodPost, "/api/v1/users/:userid/roles/:role/assign")
+	require.False(t, ok)
+}
+
+func TestGuardedStatusAction(t *testing.T) {
+	for body, guarded := range map[string]bool{
+		`{"name":"DISABLED","value":true}`:       true,
+		`{"name":"DISABLED","value":false}`:      false,
+		`{"name":"EMAIL_VERIFIED","value":true}`: false,
+		`not json`:                               false,
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/u2/status", strings.NewReader(body))
+		action, ok := guardedStatusAction(c)
+		require.Equal(t, guarded, ok, body)
+		if ok {
+			require.Equal(t, UserGuardActionDisable, action)
+		}
+		// The handler still reads the whole body
+		read, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, string(read))
+	}
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/event"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidUserImportFile is returned when the header of an import file
+// cannot be read or misses a required column
+var ErrInvalidUserImportFile = errors.New("invalid user import file")
+
+// ErrInvalidUserImportMapping is returned when the mapping of an import names
+// an unknown field or locale, or an invalid delimiter
+var ErrInvalidUserImportMapping = errors.New("invalid user import mapping")
+
+// Fields of an imported user, and the default names of their columns
+const (
+	UserImportFieldLastname        = "lastname"
+	UserImportFieldFirstname       = "firstname"
+	UserImportFieldEmail           = "email"
+	UserImportFieldIsCustomerAdmin = "is_customer_admin"
+	UserImportFieldSilent          = "silent"
+)
+
+// userImportColumns are the fields an import file must have a column for
+var userImportColumns = []string{UserImportFieldLastname, UserImportFieldFirstname, UserImportFieldEmail, UserImportFieldIsCustomerAdmin}
+
+// userImportFields are the fields a mapping may name
+var userImportFields = []string{UserImportFieldLastname, UserImportFieldFirstname, UserImportFieldEmail, UserImportFieldIsCustomerAdmin, UserImportFieldSilent}
+
+// userImportTrueValues are the cells read as true, by language. Values are
+// compared case-insensitively.
+var userImportTrueValues = map[string][]string{
+	"en": {"y", "yes", "true", "1"},
+	"fr": {"o", "oui", "vrai", "1"},
+	"de": {"j", "ja", "wahr", "1"},
+	"es": {"s", "si", "sí", "verdadero", "1"},
+	"it": {"s", "si", "sì", "vero", "1"},
+	"nl": {"j", "ja", "waar", "1"},
+	"pt": {"s", "sim", "verdadeiro", "1"},
+}
+
+// UserImportMapping describes the layout of an import file, so exports of
+// other systems can be uploaded unchanged. The zero value is the default
+// layout: semicolon separated, with the field names as column names.
+type UserImportMapping struct {
+	// Delimiter separates the fields, ';' by default
+	Delimiter rune
+	// Columns maps a field to the name of its column in the file
+	Columns map[string]string
+	// AdminValues are the cells of the is_customer_admin column giving the
+	// CUSTOMER_ADMIN role, e.g. the "Manager" value of a job role column.
+	// When empty the column is read as a boolean.
+	AdminValues []string
+	// Locale is the language of the booleans, e.g. "fr" for oui/non. English
+	// values are accepted in every locale.
+	Locale string
+}
+
+// validate checks the mapping and returns the true values of its locale
+func (m UserImportMapping) validate() ([]string, error) {
+	if m.Delimiter != 0 && (m.Delimiter == '"' || m.Delimiter == '\r' || m.Delimiter == '\n' || m.Delimiter == utf8.RuneError) {
+		return nil, fmt.Errorf("%w: invalid delimiter %q", ErrInvalidUserImportMapping, m.Delimiter)
+	}
+	for field, column := range m.Columns {
+		if !slices.Contains(userImportFields, field) {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidUserImportMapping, field)
+		}
+		if strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("%w: no column for field %q", ErrInvalidUserImportMapping, field)
+		}
+	}
+	trueValues := userImportTrueValues["en"]
+	if m.Locale != "" {
+		// Regional variants such as fr-CH use the values of their language
+		language, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(m.Locale), "_", "-"), "-")
+		values, ok := userImportTrueValues[language]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported locale %q", ErrInvalidUserImportMapping, m.Locale)
+		}
+		trueValues = append(slices.Clone(trueValues), values...)
+	}
+	return trueValues, nil
+}
+
+// column returns the name of the column of a field, in lower case
+func (m UserImportMapping) column(field string) string {
+	if column, ok := m.Columns[field]; ok {
+		return strings.ToLower(strings.TrimSpace(column))
+	}
+	return field
+}
+
+// UserImportRow is a user read from an import file
+type UserImportRow struct {
+	Line            int
+	Lastname        string
+	Firstname       string
+	Email           string
+	IsCustomerAdmin bool
+	Silent          bool
+}
+
+// Name is the display name given to the imported user
+func (r UserImportRow) Name() string {
+	return r.Firstname + " " + r.Lastname
+}
+
+// Roles are the roles given to the imported user
+func (r UserImportRow) Roles() []core.Role {
+	if r.IsCustomerAdmin {
+		return []core.Role{core.CUSTOMERADMIN}
+	}
+	return []core.Role{}
+}
+
+// UserImportReader reads the users of a delimited import file
+type UserImportReader struct {
+	reader      *csv.Reader
+	columns     map[string]int
+	minFields   int
+	trueValues  []string
+	adminValues []string
+	line        int
+}
+
+// NewUserImportReader reads the header of the file and checks the columns of
+// the required fields are there
+func NewUserImportReader(src io.Reader, mapping UserImportMapping) (*UserImportReader, error) {
+	trueValues, err := mapping.validate()
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(src)
+	reader.Comma = ';'
+	if mapping.Delimiter != 0 {
+		reader.Comma = mapping.Delimiter
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: error reading CSV header: %v", ErrInvalidUserImportFile, err)
+	}
+	// Strip BOM from the first header column if present
+	if len(header) > 0 {
+		header[0] = util.StripBOM(header[0])
+	}
+
+	indexes := make(map[string]int, len(header))
+	for i, col := range header {
+		indexes[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	columns := make(map[string]int, len(userImportFields))
+	minFields := 0
+	missing := []string{}
+	for _, field := range userImportFields {
+		idx, ok := indexes[mapping.column(field)]
+		if ok {
+			columns[field] = idx
+		}
+		if !slices.Contains(userImportColumns, field) {
+			continue
+		}
+		if !ok {
+			missing = append(missing, mapping.column(field))
+			continue
+		}
+		minFields = max(minFields, idx+1)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing required columns: %v", ErrInvalidUserImportFile, missing)
+	}
+	adminValues := make([]string, len(mapping.AdminValues))
+	for i, value := range mapping.AdminValues {
+		adminValues[i] = strings.ToLower(strings.TrimSpace(value))
+	}
+	return &UserImportReader{
+		reader:      reader,
+		columns:     columns,
+		minFields:   minFields,
+		trueValues:  trueValues,
+		adminValues: adminValues,
+		line:        1,
+	}, nil
+}
+
+// InputOffset returns the bytes read so far
+func (r *UserImportReader) InputOffset() int64 {
+	return r.reader.InputOffset()
+}
+
+// Next reads the next row. A row that cannot be read is returned as a row
+// error, and io.EOF once the file has been read.
+func (r *UserImportReader) Next() (UserImportRow, *event.ImportRowError, error) {
+	r.line++
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return UserImportRow{}, nil, io.EOF
+	}
+	if err != nil {
+		return UserImportRow{}, &event.ImportRowError{
+			Line:  r.line,
+			Code:  event.ImportErrorInvalidRow,
+			Error: fmt.Sprintf("error reading line: %v", err),
+		}, nil
+	}
+	if len(record) < r.minFields {
+		return UserImportRow{}, &event.ImportRowError{
+			Line:  r.line,
+			Code:  event.ImportErrorInvalidRow,
+			Error: fmt.Sprintf("invalid record format, expected at least %d fields, got %d", r.minFields, len(record)),
+		}, nil
+	}
+
+	row := UserImportRow{
+		Line:            r.line,
+		Lastname:        record[r.columns[UserImportFieldLastname]],
+		Firstname:       record[r.columns[UserImportFieldFirstname]],
+		Email:           record[r.columns[UserImportFieldEmail]],
+		IsCustomerAdmin: r.isCustomerAdmin(record[r.columns[UserImportFieldIsCustomerAdmin]]),
+	}
+	if idx, ok := r.columns[UserImportFieldSilent]; ok && idx < len(record) {
+		row.Silent = parseImportFlag(record[idx], r.trueValues)
+	}
+	return row, nil, nil
+}
+
+// isCustomerAdmin reads the is_customer_admin cell, either as one of the
+// admin values of the mapping or as a boolean
+func (r *UserImportReader) isCustomerAdmin(cell string) bool {
+	if len(r.adminValues) > 0 {
+		return slices.Contains(r.adminValues, strings.ToLower(strings.TrimSpace(cell)))
+	}
+	return parseImportFlag(cell, r.trueValues)
+}
+
+// parseImportFlag converts a CSV cell to a boolean. Accepts the true values
+// of the locale (case-insensitive, surrounding whitespace tolerated).
+// Anything else, including empty, is false.
+func parseImportFlag(s string, trueValues []string) bool {
+	return slices.Contains(trueValues, strings.ToLower(strings.TrimSpace(s)))
+}
+
+// UserImportValidator checks the rows of an import the same way in a dry run
+// and in a real import
+type UserImportValidator struct {
+	canManage              bool
+	canAssignCustomerAdmin bool
+	seen                   map[string]int
+}
+
+// NewUserImportValidator resolves the rights of the caller once for the file
+func NewUserImportValidator(c *gin.Context) *UserImportValidator {
+	return &UserImportValidator{
+		canManage:              auth.Allowed(c, auth.OpManageUsers),
+		canAssignCustomerAdmin: auth.HasRightsForRole(c, core.CUSTOMERADMIN) == nil,
+		seen:                   map[string]int{},
+	}
+}
+
+// Check returns the error of a row an import rejects before trying to create
+// the user: an invalid email address, a duplicate of an earlier row, a role
+// the caller may not give or a missing name
+func (v *UserImportValidator) Check(row UserImportRow) *event.ImportRowError {
+	rowError := func(code, message string) *event.ImportRowError {
+		return &event.ImportRowError{Line: row.Line, Email: row.Email, Code: code, Error: message}
+	}
+
+	email := strings.ToLower(strings.TrimSpace(row.Email))
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return rowError(event.ImportErrorInvalidEmail, "invalid email address")
+	}
+	if first, ok := v.seen[email]; ok {
+		return rowError(event.ImportErrorDuplicate, fmt.Sprintf("duplicate of line %d", first))
+	}
+	v.seen[email] = row.Line
+
+	if !v.canManage || (row.IsCustomerAdmin && !v.canAssignCustomerAdmin) {
+		return rowError(event.ImportErrorForbidden, "must be an RESELLER, CUSTOMER_ADMIN or SUPER_ADMIN to assign CUSTOMER_ADMIN role to a user.")
+	}
+	if strings.TrimSpace(row.Name()) == "" {
+		return rowError(event.ImportErrorInvalidRow, "firstname or lastname is required")
+	}
+	return nil
+}
+
+// UserImportReport is the outcome of the validation of an import file
+type UserImportReport struct {
+	Total         int
+	Valid         int
+	AlreadyExists int
+	Invalid       int
+	Errors        []event.ImportRowError
+}
+
+// ValidateUserImport checks every row of the file the way an import would,
+// without creating anyone. Users already in the tenant are reported with the
+// event.ImportErrorEmailExists code but not counted as invalid, an import
+// skips them.
+func ValidateUserImport(c *gin.Context, store *db.Store, tenantID string, reader *UserImportReader) (UserImportReport, error) {
+	report := UserImportReport{Errors: []event.ImportRowError{}}
+	validator := NewUserImportValidator(c)
+
+	for {
+		row, rowErr, err := reader.Next()
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, fmt.Errorf("service.ValidateUserImport: %w", err)
+		}
+		if rowErr == nil {
+			report.Total++
+			rowErr = validator.Check(row)
+		}
+		if rowErr != nil {
+			report.Invalid++
+			report.Errors = append(report.Errors, *rowErr)
+			continue
+		}
+
+		email := strings.ToLower(strings.TrimSpace(row.Email))
+		_, err = store.GetSharedUserByTenantByEmail(c, repository.GetSharedUserByTenantByEmailParams{
+			Email:           email,
+			EmailBlindIndex: EmailBlindIndex(email),
+			TenantID:        tenantID,
+		})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			report.Valid++
+		case err != nil:
+			return report, fmt.Errorf("service.ValidateUserImport: %w", err)
+		default:
+			report.AlreadyExists++
+			report.Errors = append(report.Errors, event.ImportRowError{
+				Line:  row.Line,
+				Email: row.Email,
+				Code:  event.ImportErrorEmailExists,
+				Error: "email already exists",
+			})
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"ctoup.com/coreapp/pkg/shared/event"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserImportReader(t *testing.T) {
+	_, err := NewUserImportReader(strings.NewReader("lastname;firstname;email\n"), UserImportMapping{})
+	require.ErrorIs(t, err, ErrInvalidUserImportFile)
+
+	file := "\uFEFFLastname;Firstname;Email;Is_Customer_Admin;Silent\n" +
+		"Doe;Jane;jane@example.com;yes;1\n" +
+		"Doe;John\n"
+	reader, err := NewUserImportReader(strings.NewReader(file), UserImportMapping{})
+	require.NoError(t, err)
+
+	row, rowErr, err := reader.Next()
+	require.NoError(t, err)
+	require.Nil(t, rowErr)
+	require.Equal(t, UserImportRow{Line: 2, Lastname: "Doe", Firstname: "Jane", Email: "jane@example.com", IsCustomerAdmin: true, Silent: true}, row)
+
+	_, rowErr, err = reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, event.ImportErrorInvalidRow, rowErr.Code)
+	require.Equal(t, 3, rowErr.Line)
+
+	_, _, err = reader.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestUserImportReaderMapping(t *testing.T) {
+	mapping := UserImportMapping{
+		Delimiter: ',',
+		Columns: map[string]string{
+			UserImportFieldLastname:        "Nom",
+			UserImportFieldFirstname:       "Prénom",
+			UserImportFieldEmail:           "E-mail",
+			UserImportFieldIsCustomerAdmin: "Fonction",
+			UserImportFieldSilent:          "Sans email",
+		},
+		AdminValues: []string{"Manager"},
+		Locale:      "fr-CH",
+	}
+	file := "Matricule,Nom,Prénom,E-mail,Fonction,Sans email\n" +
+		"42,Doe,Jane,jane@example.com,manager,Oui\n" +
+		"43,Doe,John,john@example.com,Employé,non\n"
+	reader, err := NewUserImportReader(strings.NewReader(file), mapping)
+	require.NoError(t, err)
+
+	row, rowErr, err := reader.Next()
+	require.NoError(t, err)
+	require.Nil(t, rowErr)
+	require.Equal(t, UserImportRow{Line: 2, Lastname: "Doe", Firstname: "Jane", Email: "jane@example.com", IsCustomerAdmin: true, Silent: true}, row)
+
+	row, rowErr, err = reader.Next()
+	require.NoError(t, err)
+	require.Nil(t, rowErr)
+	require.False(t, row.IsCustomerAdmin)
+	require.False(t, row.Silent)
+
+	_, err = NewUserImportReader(strings.NewReader(file), UserImportMapping{Delimiter: ','})
+	require.ErrorIs(t, err, ErrInvalidUserImportFile)
+	_, err = NewUserImportReader(strings.NewReader(file), UserImportMapping{Columns: map[string]string{"phone": "Tel"}})
+	require.ErrorIs(t, err, ErrInvalidUserImportMapping)
+	_, err = NewUserImportReader(strings.NewReader(file), UserImportMapping{Locale: "xx"})
+	require.ErrorIs(t, err, ErrInvalidUserImportMapping)
+	_, err = NewUserImportReader(strings.NewReader(file), UserImportMapping{Delimiter: '"'})
+	require.ErrorIs(t, err, ErrInvalidUserImportMapping)
+}
+
+func TestUserImportValidatorCheck(t *testing.T) {
+	validator := &UserImportValidator{canManage: true, seen: map[string]int{}}
+
+	require.Nil(t, validator.Check(UserImportRow{Line: 2, Firstname: "Jane", Email: "jane@example.com"}))
+	require.Equal(t, event.ImportErrorDuplicate, validator.Check(UserImportRow{Line: 3, Firstname: "Jane", Email: " Jane@Example.com"}).Code)
+	require.Equal(t, event.ImportErrorInvalidEmail, validator.Check(UserImportRow{Line: 4, Firstname: "Joe", Email: "joe@"}).Code)
+	require.Equal(t, event.ImportErrorInvalidEmail, validator.Check(UserImportRow{Line: 5, Firstname: "Joe", Email: "Joe <joe@example.com>"}).Code)
+	require.Equal(t, event.ImportErrorForbidden, validator.Check(UserImportRow{Line: 6, Firstname: "Ann", Email: "ann@example.com", IsCustomerAdmin: true}).Code)
+	require.Equal(t, event.ImportErrorInvalidRow, validator.Check(UserImportRow{Line: 7, Email: "bob@example.com"}).Code)
+
+	validator = &UserImportValidator{seen: map[string]int{}}
+	require.Equal(t, event.ImportErrorForbidden, validator.Check(UserImportRow{Line: 2, Firstname: "Jane", Email: "jane@example.com"}).Code)
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/api/openapi/core"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultUserInvitationTTL = 7 * 24 * time.Hour
+	MaxUserInvitationTTL     = 30 * 24 * time.Hour
+
+	MinInvitationPasswordLength = 8
+
+	userInvitationTokenPrefix = "inv_"
+)
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+var (
+	ErrInvalidInvitation = errors.New("invalid invitation")
+	// ErrInvitationAlreadyMember is returned when the address already belongs
+	// to the tenant, or holds a global role on the admin domain
+	ErrInvitationAlreadyMember = errors.New("the address is already a member")
+	// ErrInvitationPending is returned when the address already has a pending
+	// invitation, which is sent again instead
+	ErrInvitationPending = errors.New("the address already has a pending invitation, send it again instead")
+	// ErrInvitationNotFound is returned for an unknown, revoked, expired or
+	// used invitation
+	ErrInvitationNotFound         = errors.New("invitation not found or no longer valid")
+	ErrInvitationPasswordRequired = fmt.Errorf("a password of at least %d characters is required", MinInvitationPasswordLength)
+)
+
+// AcceptedInvitation is the outcome of an accepted invitation
+type AcceptedInvitation struct {
+	Invitation repository.CoreUserInvitation
+	UserID     string
+	// Created is false when the address already had an account
+	Created bool
+}
+
+// UserInvitationService invites users by email. An invitation holds the roles
+// the user gets and the hash of the token sent in the link; the user is only
+// created when they accept it, with the name and the password they choose, so
+// nobody is created for an address that never answers. It works with both
+// user strategies: a tenant invites members, the admin domain invites holders
+// of a global role.
+//
+// Environment:
+//   - USER_INVITATION_TTL: lifetime of an invitation link (default 168h, at
+//     most 720h)
+type UserInvitationService struct {
+	store       *db.Store
+	userService UserService
+	ttl         time.Duration
+}
+
+func NewUserInvitationService(store *db.Store, userService UserService) *UserInvitationService {
+	ttl := DefaultUserInvitationTTL
+	if v := os.Getenv("USER_INVITATION_TTL"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+			ttl = min(parsed, MaxUserInvitationTTL)
+		} else {
+			log.Warn().Str("USER_INVITATION_TTL", v).Msg("Invalid duration, using default")
+		}
+	}
+	return &UserInvitationService{store: store, userService: userService, ttl: ttl}
+}
+
+// CreateInvitation invites the address to the tenant and returns the token of
+// the link to send. Without roles the user gets the USER role.
+func (s *UserInvitationService) CreateInvitation(ctx context.Context, tenantID, invitedBy, email string, roles []core.Role) (string, repository.CoreUserInvitation, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("%w: the email is required", ErrInvalidInvitation)
+	}
+	if len(roles) == 0 {
+		roles = []core.Role{core.USER}
+	}
+	if tenantID != "" {
+		if err := validateTenantScopedRoles(roles); err != nil {
+			return "", repository.CoreUserInvitation{}, fmt.Errorf("%w: %s", ErrInvalidInvitation, err)
+		}
+	}
+
+	existing, err := s.userService.GetUserByEmailGlobal(ctx, email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.CreateInvitation: %w", err)
+	}
+	if existing != nil {
+		member, err := s.isMember(ctx, tenantID, existing.Id)
+		if err != nil {
+			return "", repository.CoreUserInvitation{}, fmt.Errorf("service.CreateInvitation: %w", err)
+		}
+		if member {
+			return "", repository.CoreUserInvitation{}, ErrInvitationAlreadyMember
+		}
+	}
+	_, err = s.store.GetPendingUserInvitationByEmail(ctx, repository.GetPendingUserInvitationByEmailParams{
+		TenantID:        tenantID,
+		Email:           email,
+		EmailBlindIndex: EmailBlindIndex(email),
+	})
+	if err == nil {
+		return "", repository.CoreUserInvitation{}, ErrInvitationPending
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.CreateInvitation: %w", err)
+	}
+
+	token, hash, err := newInvitationToken()
+	if err != nil {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.CreateInvitation: %w", err)
+	}
+	stored, blindIndex, err := EncryptEmail(email)
+	if err != nil {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.CreateInvitation: %w", err)
+	}
+	invitation, err := s.store.CreateUserInvitation(ctx, repository.CreateUserInvitationParams{
+		TenantID:        tenantID,
+		Email:           stored,
+		EmailBlindIndex: blindIndex,
+		Roles:           convertToRoles(roles),
+		TokenHash:       hash,
+		InvitedBy:       invitedBy,
+		ExpiresAt:       time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.CreateInvitation: %w", err)
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("invitation_id", invitation.ID.String()).Str("tenant_id", tenantID).
+		Str("invited_by", invitedBy).Strs("roles", invitation.Roles).Msg("User invited")
+	return token, invitation, nil
+}
+
+// ResendInvitation replaces the token of a pending invitation, expired or
+// not, and returns the new one. The previous link stops working.
+func (s *UserInvitationService) ResendInvitation(ctx context.Context, tenantID string, id uuid.UUID) (string, repository.CoreUserInvitation, error) {
+	token, hash, err := newInvitationToken()
+	if err != nil {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.ResendInvitation: %w", err)
+	}
+	invitation, err := s.store.RenewUserInvitation(ctx, repository.RenewUserInvitationParams{
+		ID:        id,
+		TenantID:  tenantID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", repository.CoreUserInvitation{}, ErrInvitationNotFound
+	}
+	if err != nil {
+		return "", repository.CoreUserInvitation{}, fmt.Errorf("service.ResendInvitation: %w", err)
+	}
+	return token, invitation, nil
+}
+
+// RevokeInvitation revokes a pending invitation of the tenant
+func (s *UserInvitationService) RevokeInvitation(ctx context.Context, tenantID string, id uuid.UUID) error {
+	invitation, err := s.store.RevokeUserInvitation(ctx, repository.RevokeUserInvitationParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvitationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("service.RevokeInvitation: %w", err)
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("invitation_id", invitation.ID.String()).Str("tenant_id", tenantID).Msg("User invitation revoked")
+	return nil
+}
+
+// ListInvitations lists the invitations of the tenant, newest first, with
+// the given status when not empty
+func (s *UserInvitationService) ListInvitations(ctx context.Context, tenantID, status string, limit, offset int32) ([]repository.CoreUserInvitation, error) {
+	invitations, err := s.store.ListUserInvitations(ctx, repository.ListUserInvitationsParams{
+		TenantID: tenantID,
+		Status:   pgtype.Text{String: status, Valid: status != ""},
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.ListInvitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// LookupInvitation returns the pending invitation of the token in the tenant,
+// and whether its address already has an account
+func (s *UserInvitationService) LookupInvitation(ctx context.Context, tenantID, token string) (repository.CoreUserInvitation, bool, error) {
+	invitation, err := s.validInvitation(ctx, tenantID, token)
+	if err != nil {
+		return repository.CoreUserInvitation{}, false, err
+	}
+	existing, err := s.userService.GetUserByEmailGlobal(ctx, InvitationEmail(invitation))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserInvitation{}, false, fmt.Errorf("service.LookupInvitation: %w", err)
+	}
+	return invitation, existing != nil, nil
+}
+
+// AcceptInvitation uses the token once. An address without an account gets a
+// new user with the name and the password, its email verified as the link
+// reached it. An address with an account joins the tenant, or gets the
+// global roles on the admin domain, and keeps its name and password.
+func (s *UserInvitationService) AcceptInvitation(ctx context.Context, authClient auth.AuthClient, tenantID, token, name string, password *string) (AcceptedInvitation, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	invitation, err := s.validInvitation(ctx, tenantID, token)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+	email := InvitationEmail(invitation)
+	existing, err := s.userService.GetUserByEmailGlobal(ctx, email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return AcceptedInvitation{}, fmt.Errorf("service.AcceptInvitation: %w", err)
+	}
+	// The input is checked before the token is used, so a mistake can be
+	// corrected with the same link
+	if existing == nil {
+		if password == nil || len(*password) < MinInvitationPasswordLength {
+			return AcceptedInvitation{}, ErrInvitationPasswordRequired
+		}
+		if err := ValidateDisplayName(ctx, s.store, tenantID, "", name); err != nil {
+			return AcceptedInvitation{}, err
+		}
+	}
+
+	invitation, err = s.store.ClaimUserInvitation(ctx, invitationTokenHash(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return AcceptedInvitation{}, ErrInvitationNotFound
+	}
+	if err != nil {
+		return AcceptedInvitation{}, fmt.Errorf("service.AcceptInvitation: %w", err)
+	}
+	accepted, err := s.joinInvitation(ctx, authClient, invitation, email, name, password, existing)
+	if err != nil {
+		// The link works again for another attempt
+		if releaseErr := s.store.ReleaseUserInvitation(context.WithoutCancel(ctx), invitation.ID); releaseErr != nil {
+			logger.Err(releaseErr).Str("invitation_id", invitation.ID.String()).Msg("Failed to release user invitation")
+		}
+		return AcceptedInvitation{}, err
+	}
+
+	if err := s.store.SetUserInvitationUser(ctx, repository.SetUserInvitationUserParams{
+		ID:     invitation.ID,
+		UserID: pgtype.Text{String: accepted.UserID, Valid: true},
+	}); err != nil {
+		logger.Err(err).Str("invitation_id", invitation.ID.String()).Msg("Failed to link user invitation to the user")
+	}
+	accepted.Invitation.UserID = pgtype.Text{String: accepted.UserID, Valid: true}
+	if err := recordUserActivity(ctx, s.store, UserActivity{
+		TenantID:  invitation.TenantID,
+		UserID:    accepted.UserID,
+		Category:  ActivityCategoryMembership,
+		EventType: "invitation_accepted",
+		ActorID:   accepted.UserID,
+		Data: map[string]interface{}{
+			"invitation_id": invitation.ID.String(),
+			"invited_by":    invitation.InvitedBy,
+			"roles":         invitation.Roles,
+			"created":       accepted.Created,
+		},
+	}); err != nil {
+		logger.Err(err).Str("invitation_id", invitation.ID.String()).Msg("Failed to record accepted invitation on the timeline")
+	}
+	logger.Info().Str("invitation_id", invitation.ID.String()).Str("tenant_id", invitation.TenantID).
+		Str("user_id", accepted.UserID).Bool("created", accepted.Created).Msg("User invitation accepted")
+	return accepted, nil
+}
+
+// joinInvitation creates the user of a claimed invitation, or gives the roles
+// to the existing one
+func (s *UserInvitationService) joinInvitation(ctx context.Context, authClient auth.AuthClient, invitation repository.CoreUserInvitation, email, name string, password *string, existing *core.User) (AcceptedInvitation, error) {
+	roles := convertToRoleDTOs(invitation.Roles)
+	if existing != nil {
+		accepted := AcceptedInvitation{Invitation: invitation, UserID: existing.Id}
+		if invitation.TenantID == "" {
+			// Attaches the global roles to the existing identity
+			_, err := s.userService.CreateUser(ctx, authClient, "", core.NewUser{
+				Email: email,
+				Name:  existing.Profile.Name,
+				Roles: roles,
+			}, nil)
+			return accepted, err
+		}
+		member, err := s.isMember(ctx, invitation.TenantID, existing.Id)
+		if err != nil || member {
+			return accepted, err
+		}
+		return accepted, s.userService.AddUserToTenant(ctx, authClient, invitation.TenantID, existing.Id, roles, invitation.InvitedBy)
+	}
+
+	user, err := s.userService.CreateUser(ctx, authClient, invitation.TenantID, core.NewUser{
+		Email: email,
+		Name:  strings.TrimSpace(name),
+		Roles: roles,
+	}, password)
+	if err != nil {
+		return AcceptedInvitation{}, err
+	}
+	// The user cannot be left out for this, they can still verify later
+	if _, err := authClient.UpdateUser(ctx, user.ID, (&auth.UserToUpdate{}).EmailVerified(true)); err != nil {
+		logger := util.GetLoggerFromCtx(ctx)
+		logger.Err(err).Str("user_id", user.ID).Msg("Failed to mark the invited address verified")
+	} else {
+		recordUserAccountState(ctx, s.store, user.ID, pgtype.Bool{}, pgtype.Bool{Bool: true, Valid: true})
+	}
+	return AcceptedInvitation{Invitation: invitation, UserID: user.ID, Created: true}, nil
+}
+
+// validInvitation returns the pending invitation of the token, unless it is
+// expired or belongs to another tenant
+func (s *UserInvitationService) validInvitation(ctx context.Context, tenantID, token string) (repository.CoreUserInvitation, error) {
+	if !strings.HasPrefix(token, userInvitationTokenPrefix) {
+		return repository.CoreUserInvitation{}, ErrInvitationNotFound
+	}
+	invitation, err := s.store.GetUserInvitationByTokenHash(ctx, invitationTokenHash(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.CoreUserInvitation{}, ErrInvitationNotFound
+	}
+	if err != nil {
+		return repository.CoreUserInvitation{}, fmt.Errorf("service.validInvitation: %w", err)
+	}
+	if invitation.TenantID != tenantID || InvitationStatus(invitation, time.Now()) != InvitationStatusPending {
+		return repository.CoreUserInvitation{}, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+func (s *UserInvitationService) isMember(ctx context.Context, tenantID, userID string) (bool, error) {
+	if tenantID == "" {
+		user, err := s.store.GetSharedUserByID(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		return len(user.Roles) > 0, nil
+	}
+	return s.store.IsUserMemberOfTenant(ctx, repository.IsUserMemberOfTenantParams{
+		UserID:   userID,
+		TenantID: tenantID,
+	})
+}
+
+// InvitationStatus returns the status of the invitation at the given time, a
+// pending invitation past its expiry being expired
+func InvitationStatus(invitation repository.CoreUserInvitation, now time.Time) string {
+	if invitation.Status == InvitationStatusPending && !now.Before(invitation.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return invitation.Status
+}
+
+// InvitationEmail returns the invited address in clear
+func InvitationEmail(invitation repository.CoreUserInvitation) string {
+	return DecryptEmail(pgtype.Text{String: invitation.Email, Valid: true}).String
+}
+
+func newInvitationToken() (string, []byte, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, err
+	}
+	token := userInvitationTokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+	return token, invitationTokenHash(token), nil
+}
+
+func invitationTokenHash(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationStatus(t *testing.T) {
+	now := time.Now()
+	invitation := repository.CoreUserInvitation{Status: InvitationStatusPending, ExpiresAt: now.Add(time.Hour)}
+	require.Equal(t, InvitationStatusPending, InvitationStatus(invitation, now))
+
+	invitation.ExpiresAt = now
+	require.Equal(t, InvitationStatusExpired, InvitationStatus(invitation, now))
+
+	// Only a pending invitation expires
+	invitation.Status = InvitationStatusAccepted
+	require.Equal(t, InvitationStatusAccepted, InvitationStatus(invitation, now))
+}
+
+func TestNewInvitationToken(t *testing.T) {
+	token, hash, err := newInvitationToken()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, userInvitationTokenPrefix))
+	require.Equal(t, hash, invitationTokenHash(token))
+
+	other, _, err := newInvitationToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// MaxUserLabels is the number of labels a user may carry in a tenant
+	MaxUserLabels      = 20
+	maxUserLabelLength = 64
+)
+
+var (
+	// ErrInvalidUserLabel is wrapped by every validation error of a label
+	ErrInvalidUserLabel  = errors.New("invalid label")
+	ErrTooManyUserLabels = fmt.Errorf("a user carries at most %d labels", MaxUserLabels)
+)
+
+// A label starts with a letter or a digit, then letters, digits and . _ : -
+var userLabelPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}._:-]*$`)
+
+// NormalizeUserLabel trims and lowercases a label, so "Pilot-Group" and
+// "pilot-group" are the same label
+func NormalizeUserLabel(label string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" || len(label) > maxUserLabelLength {
+		return "", fmt.Errorf("%w: a label has between 1 and %d characters", ErrInvalidUserLabel, maxUserLabelLength)
+	}
+	if !userLabelPattern.MatchString(label) {
+		return "", fmt.Errorf("%w: %q has characters other than letters, digits and . _ : -", ErrInvalidUserLabel, label)
+	}
+	return label, nil
+}
+
+// UserLabelsFilter normalizes the labels filter of ListUsers. nil, the
+// default, keeps every user.
+func UserLabelsFilter(labels *[]string) ([]string, error) {
+	if labels == nil || len(*labels) == 0 {
+		return nil, nil
+	}
+	filter := make([]string, 0, len(*labels))
+	for _, label := range *labels {
+		normalized, err := NormalizeUserLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, normalized)
+	}
+	return slices.Compact(slices.Sorted(slices.Values(filter))), nil
+}
+
+// UserLabelService manages the labels of the users of a tenant. Labels are
+// free-form tags admins put on users to segment them, e.g. contractor or
+// pilot-group. Unlike the roles they grant nothing.
+type UserLabelService struct {
+	store *db.Store
+}
+
+func NewUserLabelService(store *db.Store) *UserLabelService {
+	return &UserLabelService{store: store}
+}
+
+// AddLabel puts the label on the user in the tenant and returns the labels of
+// the user. Adding a label the user already carries changes nothing.
+func (s *UserLabelService) AddLabel(ctx context.Context, tenantID, userID, label string) ([]string, error) {
+	label, err := NormalizeUserLabel(label)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateLabels(ctx, "AddLabel", tenantID, userID, func(labels []string) ([]string, error) {
+		return addUserLabel(labels, label)
+	})
+}
+
+// RemoveLabel takes the label off the user in the tenant and returns the
+// labels of the user. Removing a label the user does not carry changes nothing.
+func (s *UserLabelService) RemoveLabel(ctx context.Context, tenantID, userID, label string) ([]string, error) {
+	label, err := NormalizeUserLabel(label)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateLabels(ctx, "RemoveLabel", tenantID, userID, func(labels []string) ([]string, error) {
+		return removeUserLabel(labels, label), nil
+	})
+}
+
+func addUserLabel(labels []string, label string) ([]string, error) {
+	if slices.Contains(labels, label) {
+		return labels, nil
+	}
+	if len(labels) >= MaxUserLabels {
+		return nil, ErrTooManyUserLabels
+	}
+	return slices.Sorted(slices.Values(append(slices.Clone(labels), label))), nil
+}
+
+func removeUserLabel(labels []string, label string) []string {
+	return slices.DeleteFunc(slices.Clone(labels), func(l string) bool { return l == label })
+}
+
+// updateLabels applies change to the labels of the membership, locked for the
+// time of the change
+func (s *UserLabelService) updateLabels(ctx context.Context, op, tenantID, userID string, change func([]string) ([]string, error)) ([]string, error) {
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service.%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.store.Queries.WithTx(tx)
+
+	current, err := qtx.GetUserTenantMembershipLabelsForUpdate(ctx, repository.GetUserTenantMembershipLabelsForUpdateParams{
+		UserID:   userID,
+		TenantID: tenantID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotTenantMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service.%s: %w", op, err)
+	}
+	labels, err := change(current)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(labels, current) {
+		return current, nil
+	}
+	labels, err = qtx.UpdateUserTenantMembershipLabels(ctx, repository.UpdateUserTenantMembershipLabelsParams{
+		Labels:   labels,
+		UserID:   userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.%s: %w", op, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("service.%s: %w", op, err)
+	}
+	_ = recordUserAudit(ctx, s.store, UserAuditEntry{
+		TenantID: tenantID,
+		UserID:   userID,
+		Action:   UserAuditActionLabelsChanged,
+		ActorID:  auditActor(ctx),
+		Details:  map[string]interface{}{"from": current, "to": labels},
+	})
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("user_id", userID).Strs("labels", labels).Msg("User labels changed")
+	return labels, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeUserLabel(t *testing.T) {
+	label, err := NormalizeUserLabel("  Pilot-Group ")
+	require.NoError(t, err)
+	require.Equal(t, "pilot-group", label)
+
+	label, err = NormalizeUserLabel("Équipe:2024")
+	require.NoError(t, err)
+	require.Equal(t, "équipe:2024", label)
+
+	for _, invalid := range []string{"", "   ", "-contractor", "two words", "a,b", strings.Repeat("x", maxUserLabelLength+1)} {
+		_, err := NormalizeUserLabel(invalid)
+		require.ErrorIs(t, err, ErrInvalidUserLabel, invalid)
+	}
+}
+
+func TestUserLabelsFilter(t *testing.T) {
+	filter, err := UserLabelsFilter(nil)
+	require.NoError(t, err)
+	require.Nil(t, filter)
+
+	filter, err = UserLabelsFilter(&[]string{"Pilot-Group", "contractor", "pilot-group"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"contractor", "pilot-group"}, filter)
+
+	_, err = UserLabelsFilter(&[]string{"contractor", "not valid"})
+	require.ErrorIs(t, err, ErrInvalidUserLabel)
+}
+
+func TestAddAndRemoveUserLabel(t *testing.T) {
+	labels, err := addUserLabel([]string{"pilot-group"}, "contractor")
+	require.NoError(t, err)
+	require.Equal(t, []string{"contractor", "pilot-group"}, labels)
+
+	// Adding a label twice changes nothing
+	again, err := addUserLabel(labels, "contractor")
+	require.NoError(t, err)
+	require.Equal(t, labels, again)
+
+	full := make([]string, MaxUserLabels)
+	for i := range full {
+		full[i] = fmt.Sprintf("label-%02d", i)
+	}
+	_, err = addUserLabel(full, "one-more")
+	require.ErrorIs(t, err, ErrTooManyUserLabels)
+	// A label the user carries is still accepted at the limit
+	_, err = addUserLabel(full, "label-00")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"pilot-group"}, removeUserLabel(labels, "contractor"))
+	require.Equal(t, labels, removeUserLabel(labels, "unknown"))
+	// The labels passed in are left untouched
+	require.Equal(t, []string{"contractor", "pilot-group"}, labels)
+}
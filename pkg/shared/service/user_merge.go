@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	fileservice "ctoup.com/coreapp/pkg/shared/fileservice"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Outcomes of a membership of the duplicate in a merge
+const (
+	UserMergeMembershipMoved  = "moved"
+	UserMergeMembershipMerged = "merged"
+)
+
+var (
+	// ErrInvalidUserMerge is returned when a user is merged into themselves
+	ErrInvalidUserMerge  = errors.New("a user cannot be merged into themselves")
+	ErrUserMergeNotFound = errors.New("user not found")
+	// ErrUserMergeGlobalRole keeps a merge from handing the global roles of
+	// the duplicate to the primary user, they are removed first
+	ErrUserMergeGlobalRole = errors.New("the duplicate holds a global role, remove it before the merge")
+)
+
+// UserMergeColumn is a column holding user ids, moved from the duplicate to
+// the primary user by a merge
+type UserMergeColumn struct {
+	Table  string
+	Column string
+}
+
+func (c UserMergeColumn) String() string {
+	return c.Table + "." + c.Column
+}
+
+// UserMergeFunc moves the rows of a column from the duplicate to the primary
+// user in the transaction of the merge. It returns the rows moved and the rows
+// left to the duplicate because the primary user has the same row already.
+type UserMergeFunc func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (moved int64, kept int64, err error)
+
+type userMergeStep struct {
+	column UserMergeColumn
+	merge  UserMergeFunc
+}
+
+var (
+	userMergeStepsMu sync.RWMutex
+	// userMergeSteps move every column of the core tables holding user ids,
+	// each with its own query. The memberships are merged on their own, and
+	// the MFA factors and sessions, the email verification tokens and the
+	// account state stay with the identity they were issued for.
+	userMergeSteps = []userMergeStep{
+		{UserMergeColumn{Table: "core_group_members", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			row, err := repository.New(tx).MergeUserGroupMembers(ctx, repository.MergeUserGroupMembersParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return row.Moved, row.Kept, err
+		}},
+		{UserMergeColumn{Table: "core_role_members", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			row, err := repository.New(tx).MergeUserRoleMembers(ctx, repository.MergeUserRoleMembersParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return row.Moved, row.Kept, err
+		}},
+		{UserMergeColumn{Table: "core_directory_users", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			row, err := repository.New(tx).MergeUserDirectoryUsers(ctx, repository.MergeUserDirectoryUsersParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return row.Moved, row.Kept, err
+		}},
+		{UserMergeColumn{Table: "core_user_invitations", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			moved, err := repository.New(tx).MergeUserInvitations(ctx, repository.MergeUserInvitationsParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return moved, 0, err
+		}},
+		{UserMergeColumn{Table: "core_user_activity_events", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			moved, err := repository.New(tx).MergeUserActivityEvents(ctx, repository.MergeUserActivityEventsParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return moved, 0, err
+		}},
+		{UserMergeColumn{Table: "core_user_audit_logs", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			moved, err := repository.New(tx).MergeUserAuditLogs(ctx, repository.MergeUserAuditLogsParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return moved, 0, err
+		}},
+		{UserMergeColumn{Table: "core_user_logins", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			row, err := repository.New(tx).MergeUserLogins(ctx, repository.MergeUserLoginsParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return row.Moved, row.Kept, err
+		}},
+		{UserMergeColumn{Table: "core_user_onboarding_steps", Column: "user_id"}, func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+			row, err := repository.New(tx).MergeUserOnboardingSteps(ctx, repository.MergeUserOnboardingStepsParams{PrimaryID: primaryID, DuplicateID: duplicateID})
+			return row.Moved, row.Kept, err
+		}},
+	}
+)
+
+// RegisterUserMergeColumn lets a module, such as prompts, have a column of
+// its tables holding user ids moved by the merges, with its own query
+func RegisterUserMergeColumn(table, column string, merge UserMergeFunc) {
+	userMergeStepsMu.Lock()
+	defer userMergeStepsMu.Unlock()
+	userMergeSteps = append(userMergeSteps, userMergeStep{column: UserMergeColumn{Table: table, Column: column}, merge: merge})
+}
+
+func getUserMergeSteps() []userMergeStep {
+	userMergeStepsMu.RLock()
+	defer userMergeStepsMu.RUnlock()
+	return append([]userMergeStep{}, userMergeSteps...)
+}
+
+// UserMergeMembership is the outcome of a membership of the duplicate: moved
+// to the primary user, or merged into the membership they already had
+type UserMergeMembership struct {
+	TenantID string
+	Action   string
+	Roles    []string
+}
+
+// UserMergeReport is the outcome of a merge, or with DryRun what it would do.
+// Rows counts the rows moved per column; Kept the rows left to the duplicate
+// because the primary user already had one, such as a membership of the same
+// group.
+type UserMergeReport struct {
+	DryRun          bool
+	PrimaryUserID   string
+	DuplicateUserID string
+	Memberships     []UserMergeMembership
+	Rows            map[string]int64
+	Kept            map[string]int64
+	Resources       int
+	Files           []string
+	Disabled        bool
+}
+
+// UserMergeService merges a duplicate user into a primary user: the
+// memberships, the rows referencing the duplicate, the resources and the
+// profile picture go to the primary user, then the duplicate is disabled
+type UserMergeService struct {
+	store  *db.Store
+	files  *fileservice.FileService
+	claims *ClaimsRebuildService
+}
+
+func NewUserMergeService(store *db.Store, files *fileservice.FileService) *UserMergeService {
+	return &UserMergeService{store: store, files: files, claims: NewClaimsRebuildService(store)}
+}
+
+// MergeUsers merges duplicateID into primaryID. Without apply the merge runs
+// in a transaction rolled back at the end, so the report is exactly what
+// applying it would do, and neither the files nor the duplicate's account are
+// touched.
+func (s *UserMergeService) MergeUsers(ctx context.Context, authClient auth.AuthClient, primaryID, duplicateID, actorID string, apply bool) (UserMergeReport, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	report := UserMergeReport{
+		DryRun:          !apply,
+		PrimaryUserID:   primaryID,
+		DuplicateUserID: duplicateID,
+		Memberships:     []UserMergeMembership{},
+		Rows:            map[string]int64{},
+		Kept:            map[string]int64{},
+		Files:           []string{},
+	}
+	if primaryID == duplicateID {
+		return report, ErrInvalidUserMerge
+	}
+	if _, err := s.store.GetSharedUserByID(ctx, primaryID); err != nil {
+		return report, userMergeLookupError(err, "primary", primaryID)
+	}
+	duplicate, err := s.store.GetSharedUserByID(ctx, duplicateID)
+	if err != nil {
+		return report, userMergeLookupError(err, "duplicate", duplicateID)
+	}
+	if len(duplicate.Roles) > 0 {
+		return report, ErrUserMergeGlobalRole
+	}
+
+	tx, err := s.store.ConnPool.Begin(ctx)
+	if err != nil {
+		return report, fmt.Errorf("service.MergeUsers: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := repository.New(tx)
+
+	if report.Memberships, err = mergeUserMemberships(ctx, qtx, primaryID, duplicateID); err != nil {
+		return report, err
+	}
+	resources, err := listOwnedResourcesByTenant(ctx, tx, "", duplicateID, OwnershipTransfer{ToUserID: primaryID})
+	if err != nil {
+		return report, err
+	}
+	for _, owned := range resources {
+		report.Resources += len(owned)
+	}
+	if report.Resources > 0 {
+		if err := transferOwnedResources(ctx, tx, "", duplicateID, OwnershipTransfer{ToUserID: primaryID}, actorID); err != nil {
+			return report, err
+		}
+	}
+	for _, step := range getUserMergeSteps() {
+		moved, kept, err := step.merge(ctx, tx, primaryID, duplicateID)
+		if err != nil {
+			return report, fmt.Errorf("service.MergeUsers: %s: %w", step.column, err)
+		}
+		if moved > 0 {
+			report.Rows[step.column.String()] = moved
+		}
+		if kept > 0 {
+			report.Kept[step.column.String()] = kept
+		}
+	}
+
+	if !apply {
+		report.Files = s.profilePictureToCopy(ctx, primaryID, duplicateID)
+		report.Disabled = true
+		return report, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("service.MergeUsers: %w", err)
+	}
+
+	// The database is the source of truth, what follows only logs its
+	// failures for an admin to finish by hand
+	s.rebuildMergedClaims(ctx, authClient, primaryID, report.Memberships)
+	report.Files = s.copyProfilePicture(ctx, primaryID, duplicateID)
+	if _, err := authClient.UpdateUser(ctx, duplicateID, (&auth.UserToUpdate{}).Disabled(true)); err != nil {
+		logger.Err(err).Str("user_id", duplicateID).Msg("Failed to disable the merged duplicate user")
+	} else {
+		recordUserAccountState(ctx, s.store, duplicateID, pgtype.Bool{Bool: true, Valid: true}, pgtype.Bool{})
+		report.Disabled = true
+	}
+
+	for _, membership := range report.Memberships {
+		_ = recordUserAudit(ctx, s.store, UserAuditEntry{
+			TenantID: membership.TenantID,
+			UserID:   primaryID,
+			Action:   UserAuditActionMerged,
+			ActorID:  actorID,
+			Details:  map[string]interface{}{"duplicate_user_id": duplicateID, "membership": membership.Action},
+		})
+	}
+	_ = recordUserAudit(ctx, s.store, UserAuditEntry{
+		UserID:  duplicateID,
+		Action:  UserAuditActionMerged,
+		ActorID: actorID,
+		Details: map[string]interface{}{"merged_into": primaryID, "rows": report.Rows, "kept": report.Kept},
+	})
+	logger.Info().
+		Str("primary_user_id", primaryID).
+		Str("duplicate_user_id", duplicateID).
+		Int("memberships", len(report.Memberships)).
+		Int("resources", report.Resources).
+		Msg("Users merged")
+	return report, nil
+}
+
+func userMergeLookupError(err error, which, userID string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s user %s", ErrUserMergeNotFound, which, userID)
+	}
+	return fmt.Errorf("service.MergeUsers: %w", err)
+}
+
+// mergeUserMemberships moves the memberships of the duplicate to the primary
+// user, or adds their roles and labels to the primary user's membership of the
+// same tenant
+func mergeUserMemberships(ctx context.Context, qtx *repository.Queries, primaryID, duplicateID string) ([]UserMergeMembership, error) {
+	duplicates, err := qtx.ListUserMembershipsForMerge(ctx, duplicateID)
+	if err != nil {
+		return nil, fmt.Errorf("service.MergeUsers: %w", err)
+	}
+	primaries, err := qtx.ListUserMembershipsForMerge(ctx, primaryID)
+	if err != nil {
+		return nil, fmt.Errorf("service.MergeUsers: %w", err)
+	}
+	memberships := make([]UserMergeMembership, 0, len(duplicates))
+	for _, membership := range duplicates {
+		hasPrimary := slices.ContainsFunc(primaries, func(m repository.CoreUserTenantMembership) bool {
+			return m.TenantID == membership.TenantID
+		})
+		if !hasPrimary {
+			if _, err := qtx.MoveUserTenantMembership(ctx, repository.MoveUserTenantMembershipParams{
+				ToUserID:   primaryID,
+				FromUserID: duplicateID,
+				TenantID:   membership.TenantID,
+			}); err != nil {
+				return nil, fmt.Errorf("service.MergeUsers: %w", err)
+			}
+			memberships = append(memberships, UserMergeMembership{
+				TenantID: membership.TenantID,
+				Action:   UserMergeMembershipMoved,
+				Roles:    membership.Roles,
+			})
+			continue
+		}
+		roles, err := qtx.MergeUserTenantMembership(ctx, repository.MergeUserTenantMembershipParams{
+			Roles:    membership.Roles,
+			Labels:   membership.Labels,
+			Status:   membership.Status,
+			UserID:   primaryID,
+			TenantID: membership.TenantID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("service.MergeUsers: %w", err)
+		}
+		if err := qtx.DeleteUserTenantMembership(ctx, repository.DeleteUserTenantMembershipParams{
+			UserID:   duplicateID,
+			TenantID: membership.TenantID,
+		}); err != nil {
+			return nil, fmt.Errorf("service.MergeUsers: %w", err)
+		}
+		memberships = append(memberships, UserMergeMembership{
+			TenantID: membership.TenantID,
+			Action:   UserMergeMembershipMerged,
+			Roles:    roles,
+		})
+	}
+	return memberships, nil
+}
+
+// rebuildMergedClaims rewrites the provider claims of the primary user for the
+// tenants the merge changed
+func (s *UserMergeService) rebuildMergedClaims(ctx context.Context, authClient auth.AuthClient, primaryID string, merged []UserMergeMembership) {
+	logger := util.GetLoggerFromCtx(ctx)
+	memberships, err := s.store.ListUserMembershipsForMerge(ctx, primaryID)
+	if err != nil {
+		logger.Err(err).Str("user_id", primaryID).Msg("Failed to list the memberships of the merged user")
+		return
+	}
+	for _, membership := range memberships {
+		if slices.ContainsFunc(merged, func(m UserMergeMembership) bool { return m.TenantID == membership.TenantID }) {
+			s.claims.rebuildMemberClaims(ctx, authClient, membership, false)
+		}
+	}
+}
+
+// profilePictureToCopy returns the files of the duplicate's profile picture
+// the merge copies: all of them when the primary user has no picture
+func (s *UserMergeService) profilePictureToCopy(ctx context.Context, primaryID, duplicateID string) []string {
+	files := []string{}
+	if s.files == nil {
+		return files
+	}
+	if exists, err := s.files.FileExists(ctx, fileservice.ProfilePictureFilePath(primaryID)); err != nil || exists {
+		return files
+	}
+	for _, path := range fileservice.ProfilePictureFilePaths(duplicateID) {
+		if exists, err := s.files.FileExists(ctx, path); err == nil && exists {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// copyProfilePicture gives the duplicate's profile picture to the primary user
+// when they have none, and returns the files copied
+func (s *UserMergeService) copyProfilePicture(ctx context.Context, primaryID, duplicateID string) []string {
+	logger := util.GetLoggerFromCtx(ctx)
+	copied := []string{}
+	for _, src := range s.profilePictureToCopy(ctx, primaryID, duplicateID) {
+		dst := strings.Replace(src, "/core/users/"+duplicateID+"/", "/core/users/"+primaryID+"/", 1)
+		if err := s.files.CopyFile(ctx, dst, src); err != nil {
+			logger.Err(err).Str("file", src).Msg("Failed to copy the profile picture of the merged user")
+			continue
+		}
+		copied = append(copied, src)
+	}
+	return copied
+}
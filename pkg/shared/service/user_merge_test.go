@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	commontestutils "ctoup.com/coreapp/internal/testutils"
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/core/db/testutils"
+	"ctoup.com/coreapp/pkg/shared/repository/subentity"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeUsersIntoThemselves(t *testing.T) {
+	_, err := NewUserMergeService(nil, nil).MergeUsers(t.Context(), nil, "u1", "u1", "admin", false)
+	require.ErrorIs(t, err, ErrInvalidUserMerge)
+}
+
+func TestUserMergeColumns(t *testing.T) {
+	RegisterUserMergeColumn("prompts", "created_by", func(ctx context.Context, tx pgx.Tx, primaryID, duplicateID string) (int64, int64, error) {
+		return 0, 0, nil
+	})
+	t.Cleanup(func() {
+		userMergeSteps = userMergeSteps[:len(userMergeSteps)-1]
+	})
+	columns := []UserMergeColumn{}
+	for _, step := range getUserMergeSteps() {
+		columns = append(columns, step.column)
+	}
+	require.Contains(t, columns, UserMergeColumn{Table: "prompts", Column: "created_by"})
+	require.Equal(t, "prompts.created_by", UserMergeColumn{Table: "prompts", Column: "created_by"}.String())
+}
+
+func TestUserMergeColumnsCoverForeignKeys(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	foreignKeys, err := store.ListUserForeignKeys(context.Background())
+	require.NoError(t, err)
+
+	merged := []UserMergeColumn{}
+	for _, step := range getUserMergeSteps() {
+		merged = append(merged, step.column)
+	}
+	kept := []string{
+		"core_user_tenant_memberships", "core_user_mfa", "core_user_mfa_sessions",
+		"core_email_verification_tokens", "core_user_account_states",
+	}
+	for _, fk := range foreignKeys {
+		if slices.Contains(kept, fk.TableName) {
+			continue
+		}
+		require.Contains(t, merged, UserMergeColumn{Table: fk.TableName, Column: fk.ColumnName}, "the merge has no query for the column")
+	}
+}
+
+func createMergeUser(t *testing.T, store *db.Store) string {
+	t.Helper()
+	userID := commontestutils.RandomString(12)
+	_, err := store.CreateSharedUser(context.Background(), repository.CreateSharedUserParams{
+		ID:      userID,
+		Email:   userID + "@example.com",
+		Profile: subentity.UserProfile{Name: userID},
+		Roles:   []string{},
+	})
+	require.NoError(t, err)
+	return userID
+}
+
+func TestMergeUsersRows(t *testing.T) {
+	store := testutils.NewTestStore(t)
+	ctx := context.Background()
+	primaryID := createMergeUser(t, store)
+	duplicateID := createMergeUser(t, store)
+
+	login := func(userID, tenantID string) {
+		_, err := store.RecordUserLogin(ctx, repository.RecordUserLoginParams{
+			UserID:      userID,
+			TenantID:    tenantID,
+			SessionID:   commontestutils.RandomString(16),
+			LastLoginAt: time.Now(),
+		})
+		require.NoError(t, err)
+	}
+	login(primaryID, "tenant-a")
+	login(duplicateID, "tenant-a")
+	login(duplicateID, "tenant-b")
+	_, err := store.CompleteUserOnboardingSteps(ctx, repository.CompleteUserOnboardingStepsParams{
+		UserID: primaryID,
+		Steps:  []string{"profile"},
+	})
+	require.NoError(t, err)
+	_, err = store.CompleteUserOnboardingSteps(ctx, repository.CompleteUserOnboardingStepsParams{
+		UserID: duplicateID,
+		Steps:  []string{"profile", "team", "billing"},
+	})
+	require.NoError(t, err)
+
+	report, err := NewUserMergeService(store, nil).MergeUsers(ctx, nil, primaryID, duplicateID, "admin", false)
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	// The rows the primary user has already stay with the duplicate
+	require.Equal(t, int64(1), report.Rows["core_user_logins.user_id"])
+	require.Equal(t, int64(1), report.Kept["core_user_logins.user_id"])
+	require.Equal(t, int64(2), report.Rows["core_user_onboarding_steps.user_id"])
+	require.Equal(t, int64(1), report.Kept["core_user_onboarding_steps.user_id"])
+	require.NotContains(t, report.Rows, "core_user_audit_logs.user_id")
+
+	// The dry run leaves the rows as they were
+	steps, err := store.ListUserOnboardingSteps(ctx, duplicateID)
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+}
@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/auth"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// SecretMFAEncryptionKeys lists id:base64 AES-256 keys separated by commas,
+// read through the SecretProvider. The first one encrypts the TOTP secrets;
+// the others only decrypt.
+const SecretMFAEncryptionKeys = "MFA_ENCRYPTION_KEYS"
+
+const (
+	// MFATokenHeader carries the token of a session that passed the TOTP check
+	MFATokenHeader = "X-MFA-Token"
+
+	DefaultMFAIssuer          = "CTOUp"
+	DefaultMFASessionTTL      = 12 * time.Hour
+	DefaultMFAMaxAttempts     = 5
+	DefaultMFALockoutDuration = 15 * time.Minute
+
+	mfaTokenPrefix = "mfa_"
+	// mfaRecentVerification is how long a verified session counts as a recent
+	// second factor, like the AAL2 age checked for Kratos
+	mfaRecentVerification = 15 * time.Minute
+)
+
+var (
+	// ErrMFANotConfigured is returned when no MFA_ENCRYPTION_KEYS are set
+	ErrMFANotConfigured  = errors.New("TOTP is not configured")
+	ErrMFAAlreadyEnabled = errors.New("TOTP is already enabled")
+	ErrMFANotEnrolled    = errors.New("TOTP is not enrolled")
+	ErrInvalidMFACode    = errors.New("invalid verification code")
+	// ErrMFALocked is returned after too many wrong codes in a row
+	ErrMFALocked = errors.New("too many invalid codes, try again later")
+)
+
+// MFAConfig configures the TOTP second factor.
+//
+// Environment:
+//   - MFA_ISSUER: name shown in authenticator apps (default CTOUp)
+//   - MFA_SESSION_TTL: validity of an X-MFA-Token (default 12h)
+//   - MFA_MAX_ATTEMPTS: wrong codes in a row before locking (default 5)
+//   - MFA_LOCKOUT_DURATION: lock duration (default 15m)
+type MFAConfig struct {
+	Issuer          string
+	SessionTTL      time.Duration
+	MaxAttempts     int
+	LockoutDuration time.Duration
+}
+
+func MFAConfigFromEnv() MFAConfig {
+	cfg := MFAConfig{
+		Issuer:          DefaultMFAIssuer,
+		SessionTTL:      DefaultMFASessionTTL,
+		MaxAttempts:     DefaultMFAMaxAttempts,
+		LockoutDuration: DefaultMFALockoutDuration,
+	}
+	if v := os.Getenv("MFA_ISSUER"); v != "" {
+		cfg.Issuer = v
+	}
+	if v := os.Getenv("MFA_SESSION_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.SessionTTL = d
+		} else {
+			log.Warn().Str("MFA_SESSION_TTL", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("MFA_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxAttempts = n
+		} else {
+			log.Warn().Str("MFA_MAX_ATTEMPTS", v).Msg("Invalid value, using default")
+		}
+	}
+	if v := os.Getenv("MFA_LOCKOUT_DURATION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.LockoutDuration = d
+		} else {
+			log.Warn().Str("MFA_LOCKOUT_DURATION", v).Msg("Invalid value, using default")
+		}
+	}
+	return cfg
+}
+
+// TOTPStatus is the second factor of a user. Pending is set between the
+// enrollment and the first valid code.
+type TOTPStatus struct {
+	Enabled                bool
+	Pending                bool
+	EnabledAt              *time.Time
+	RecoveryCodesRemaining int
+}
+
+// TOTPEnrollment is what an authenticator app needs to generate codes
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// TOTPVerification is a session that passed the TOTP check. RecoveryCodes is
+// only set when the verification confirmed the enrollment.
+type TOTPVerification struct {
+	Token         string
+	ExpiresAt     time.Time
+	RecoveryCodes []string
+}
+
+// MFAService manages the TOTP second factor of the users, whatever the auth
+// provider. The provider only carries the CLAIM_MFA_TOTP flag, set through
+// the AuthClient; the secrets stay in core_user_mfa.
+type MFAService struct {
+	store        *db.Store
+	cfg          MFAConfig
+	keys         map[string]cipher.AEAD
+	currentKeyID string
+}
+
+func NewMFAService(store *db.Store, cfg MFAConfig) *MFAService {
+	s := &MFAService{store: store, cfg: cfg, keys: map[string]cipher.AEAD{}}
+	keys, currentKeyID, err := parseAEADKeys(readSecret(context.Background(), currentSecretProvider(), SecretMFAEncryptionKeys))
+	if err != nil {
+		log.Err(err).Msgf("Invalid %s, TOTP is disabled", SecretMFAEncryptionKeys)
+		return s
+	}
+	s.keys, s.currentKeyID = keys, currentKeyID
+	return s
+}
+
+func (s *MFAService) encryptSecret(secret string) (string, error) {
+	if s.currentKeyID == "" {
+		return "", ErrMFANotConfigured
+	}
+	aead := s.keys[s.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(secret), nil)
+	return s.currentKeyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *MFAService) decryptSecret(stored string) (string, error) {
+	keyID, encoded, ok := strings.Cut(stored, ":")
+	if !ok {
+		return "", errors.New("malformed TOTP secret")
+	}
+	aead, found := s.keys[keyID]
+	if !found {
+		return "", fmt.Errorf("TOTP secret encrypted with the unknown key %q", keyID)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed TOTP secret")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt TOTP secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// GetTOTPStatus returns the second factor of the user, disabled when there is
+// none
+func (s *MFAService) GetTOTPStatus(ctx context.Context, userID string) (TOTPStatus, error) {
+	row, err := s.store.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TOTPStatus{}, nil
+		}
+		return TOTPStatus{}, fmt.Errorf("service.GetTOTPStatus: %w", err)
+	}
+	status := TOTPStatus{
+		Enabled:                row.Enabled,
+		Pending:                !row.Enabled,
+		RecoveryCodesRemaining: len(row.RecoveryCodeHashes),
+	}
+	if row.EnabledAt.Valid {
+		status.EnabledAt = &row.EnabledAt.Time
+	}
+	return status, nil
+}
+
+// EnrollTOTP generates a new secret for the user. The second factor stays
+// pending, and is not enforced, until VerifyTOTP accepts a first code.
+// Enrolling again replaces a pending secret.
+func (s *MFAService) EnrollTOTP(ctx context.Context, userID, account string) (TOTPEnrollment, error) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("service.EnrollTOTP: %w", err)
+	}
+	encrypted, err := s.encryptSecret(secret)
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("service.EnrollTOTP: %w", err)
+	}
+	_, err = s.store.UpsertPendingUserMFA(ctx, repository.UpsertPendingUserMFAParams{
+		UserID:          userID,
+		SecretEncrypted: encrypted,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TOTPEnrollment{}, ErrMFAAlreadyEnabled
+		}
+		return TOTPEnrollment{}, fmt.Errorf("service.EnrollTOTP: %w", err)
+	}
+	return TOTPEnrollment{Secret: secret, URI: totpURI(s.cfg.Issuer, account, secret)}, nil
+}
+
+// VerifyTOTP checks a code of the user and returns the token of a verified
+// session. The first valid code after the enrollment enables the second
+// factor: the CLAIM_MFA_TOTP flag is set through authClient and recovery
+// codes are returned. Once enabled, a recovery code is accepted instead of a
+// code, once.
+func (s *MFAService) VerifyTOTP(ctx context.Context, authClient auth.AuthClient, userID, code string) (TOTPVerification, error) {
+	logger := util.GetLoggerFromCtx(ctx)
+	row, err := s.lockedRow(ctx, userID)
+	if err != nil {
+		return TOTPVerification{}, err
+	}
+
+	var verification TOTPVerification
+	if !row.Enabled {
+		step, err := s.matchCode(ctx, row, code)
+		if err != nil {
+			return TOTPVerification{}, err
+		}
+		codes, hashes, err := newRecoveryCodes()
+		if err != nil {
+			return TOTPVerification{}, fmt.Errorf("service.VerifyTOTP: %w", err)
+		}
+		// The flag is set first: a flag without an enabled row only asks
+		// for a verification, an enabled row without the flag would not be
+		// enforced
+		if err := authClient.SetCustomUserClaims(ctx, userID, authClient.BuildMFAClaims(true)); err != nil {
+			logger.Err(err).Str("userID", userID).Msg("Failed to set the TOTP claim")
+			return TOTPVerification{}, fmt.Errorf("service.VerifyTOTP: %w", err)
+		}
+		_, err = s.store.EnableUserMFA(ctx, repository.EnableUserMFAParams{
+			RecoveryCodeHashes: hashes,
+			LastUsedStep:       step,
+			UserID:             userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Enabled concurrently, with its own recovery codes
+				return TOTPVerification{}, ErrMFAAlreadyEnabled
+			}
+			return TOTPVerification{}, fmt.Errorf("service.VerifyTOTP: %w", err)
+		}
+		verification.RecoveryCodes = codes
+	} else if err := s.checkCode(ctx, row, code, true); err != nil {
+		return TOTPVerification{}, err
+	}
+
+	token, expiresAt, err := s.createSession(ctx, userID)
+	if err != nil {
+		return TOTPVerification{}, fmt.Errorf("service.VerifyTOTP: %w", err)
+	}
+	verification.Token, verification.ExpiresAt = token, expiresAt
+	return verification, nil
+}
+
+// DisableTOTP removes the second factor of the user after checking a code or
+// a recovery code, and ends its verified sessions
+func (s *MFAService) DisableTOTP(ctx context.Context, authClient auth.AuthClient, userID, code string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	row, err := s.lockedRow(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if row.Enabled {
+		if err := s.checkCode(ctx, row, code, true); err != nil {
+			return err
+		}
+	}
+	// The flag is cleared first: without it nothing asks for a verification
+	// the user could no longer pass
+	if err := authClient.SetCustomUserClaims(ctx, userID, authClient.BuildMFAClaims(false)); err != nil {
+		logger.Err(err).Str("userID", userID).Msg("Failed to clear the TOTP claim")
+		return fmt.Errorf("service.DisableTOTP: %w", err)
+	}
+	if _, err := s.store.DeleteUserMFA(ctx, userID); err != nil {
+		return fmt.Errorf("service.DisableTOTP: %w", err)
+	}
+	if err := s.store.DeleteUserMFASessions(ctx, userID); err != nil {
+		return fmt.Errorf("service.DisableTOTP: %w", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user after
+// checking a code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	row, err := s.lockedRow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !row.Enabled {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.checkCode(ctx, row, code, false); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("service.RegenerateRecoveryCodes: %w", err)
+	}
+	updated, err := s.store.ReplaceUserMFARecoveryCodes(ctx, repository.ReplaceUserMFARecoveryCodesParams{
+		RecoveryCodeHashes: hashes,
+		UserID:             userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service.RegenerateRecoveryCodes: %w", err)
+	}
+	if updated == 0 {
+		return nil, ErrMFANotEnrolled
+	}
+	return codes, nil
+}
+
+// VerifyMFASession checks the X-MFA-Token of a request and returns when its
+// session passed the TOTP check. ok is false for an unknown, expired or
+// foreign token.
+func (s *MFAService) VerifyMFASession(ctx context.Context, userID, token string) (verifiedAt time.Time, ok bool, err error) {
+	if !strings.HasPrefix(token, mfaTokenPrefix) {
+		return time.Time{}, false, nil
+	}
+	hash := sha256.Sum256([]byte(token))
+	verifiedAt, err = s.store.GetUserMFASession(ctx, repository.GetUserMFASessionParams{
+		TokenHash: hash[:],
+		UserID:    userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("service.VerifyMFASession: %w", err)
+	}
+	return verifiedAt, true, nil
+}
+
+// lockedRow returns the second factor of the user, unless it is locked
+func (s *MFAService) lockedRow(ctx context.Context, userID string) (repository.CoreUserMfa, error) {
+	row, err := s.store.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return row, ErrMFANotEnrolled
+		}
+		return row, fmt.Errorf("service.lockedRow: %w", err)
+	}
+	if row.LockedUntil.Valid && row.LockedUntil.Time.After(time.Now()) {
+		return row, ErrMFALocked
+	}
+	return row, nil
+}
+
+// matchCode validates a code against the secret and returns its time step,
+// counting a failure otherwise
+func (s *MFAService) matchCode(ctx context.Context, row repository.CoreUserMfa, code string) (int64, error) {
+	secret, err := s.decryptSecret(row.SecretEncrypted)
+	if err != nil {
+		return 0, fmt.Errorf("service.matchCode: %w", err)
+	}
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok || step <= row.LastUsedStep {
+		return 0, s.recordFailure(ctx, row.UserID)
+	}
+	return step, nil
+}
+
+// checkCode accepts a code of a time step not used yet or, with
+// allowRecovery, an unused recovery code
+func (s *MFAService) checkCode(ctx context.Context, row repository.CoreUserMfa, code string, allowRecovery bool) error {
+	if allowRecovery && len(strings.ReplaceAll(code, " ", "")) != totpDigits {
+		used, err := s.store.UseUserMFARecoveryCode(ctx, repository.UseUserMFARecoveryCodeParams{
+			CodeHash: hashRecoveryCode(code),
+			UserID:   row.UserID,
+		})
+		if err != nil {
+			return fmt.Errorf("service.checkCode: %w", err)
+		}
+		if used == 0 {
+			return s.recordFailure(ctx, row.UserID)
+		}
+		return nil
+	}
+
+	step, err := s.matchCode(ctx, row, code)
+	if err != nil {
+		return err
+	}
+	// Another request may have used the same code in between
+	accepted, err := s.store.RecordUserMFACode(ctx, repository.RecordUserMFACodeParams{
+		LastUsedStep: step,
+		UserID:       row.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.checkCode: %w", err)
+	}
+	if accepted == 0 {
+		return s.recordFailure(ctx, row.UserID)
+	}
+	return nil
+}
+
+// recordFailure counts a wrong code and returns ErrInvalidMFACode
+func (s *MFAService) recordFailure(ctx context.Context, userID string) error {
+	err := s.store.RecordUserMFAFailure(ctx, repository.RecordUserMFAFailureParams{
+		MaxAttempts: int32(s.cfg.MaxAttempts),
+		LockedUntil: time.Now().Add(s.cfg.LockoutDuration),
+		UserID:      userID,
+	})
+	if err != nil {
+		return fmt.Errorf("service.recordFailure: %w", err)
+	}
+	// The second factor has its own lock, the account lockout also counts
+	// the wrong codes over time
+	RecordLockoutFailure(ctx, LockoutSubjectUser, userID, requestTenantID(ctx), LockoutReasonInvalidMFACode)
+	return ErrInvalidMFACode
+}
+
+// createSession stores a new verified session and returns its token
+func (s *MFAService) createSession(ctx context.Context, userID string) (string, time.Time, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", time.Time{}, err
+	}
+	token := mfaTokenPrefix + base64.RawURLEncoding.EncodeToString(random)
+	hash := sha256.Sum256([]byte(token))
+	expiresAt := time.Now().Add(s.cfg.SessionTTL)
+	if err := s.store.DeleteExpiredUserMFASessions(ctx, userID); err != nil {
+		return "", time.Time{}, err
+	}
+	err := s.store.CreateUserMFASession(ctx, repository.CreateUserMFASessionParams{
+		TokenHash: hash[:],
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// MFAAALInfo is the AAL of a session that passed the TOTP check at
+// verifiedAt, for the handlers calling auth.RequireAAL2StepUp
+func MFAAALInfo(verifiedAt time.Time) *auth.AALInfo {
+	return &auth.AALInfo{
+		Current:      "aal2",
+		Available:    "aal2",
+		IsAAL2Recent: time.Since(verifiedAt) < mfaRecentVerification,
+	}
+}
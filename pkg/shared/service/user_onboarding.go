@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db"
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"ctoup.com/coreapp/pkg/shared/util"
+	"github.com/jackc/pgx/v5"
+)
+
+// Onboarding steps of the library. The ones but profile_completed are
+// detected: the application completes them as soon as it sees them done.
+const (
+	OnboardingStepProfileCompleted = "profile_completed"
+	OnboardingStepEmailVerified    = "email_verified"
+	OnboardingStepFirstLogin       = "first_login"
+	OnboardingStepMFAEnrolled      = "mfa_enrolled"
+)
+
+var (
+	// ErrInvalidOnboardingStep is returned for a step that is not registered
+	ErrInvalidOnboardingStep = errors.New("unknown onboarding step")
+	ErrOnboardingUserUnknown = errors.New("user not found")
+)
+
+var onboardingStepPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+var (
+	onboardingStepsMu sync.RWMutex
+	onboardingSteps   = []string{
+		OnboardingStepProfileCompleted,
+		OnboardingStepEmailVerified,
+		OnboardingStepFirstLogin,
+		OnboardingStepMFAEnrolled,
+	}
+)
+
+// RegisterOnboardingStep adds a step of the product to the checklist, after
+// the library ones, for the frontend to mark. A step is registered once, with
+// a name of lowercase letters, digits and underscores.
+func RegisterOnboardingStep(step string) {
+	if !onboardingStepPattern.MatchString(step) {
+		panic(fmt.Sprintf("invalid onboarding step %q", step))
+	}
+	onboardingStepsMu.Lock()
+	defer onboardingStepsMu.Unlock()
+	if !slices.Contains(onboardingSteps, step) {
+		onboardingSteps = append(onboardingSteps, step)
+	}
+}
+
+// OnboardingSteps returns the steps of the checklist in their order
+func OnboardingSteps() []string {
+	onboardingStepsMu.RLock()
+	defer onboardingStepsMu.RUnlock()
+	return slices.Clone(onboardingSteps)
+}
+
+// OnboardingStep is a step of the checklist of a user
+type OnboardingStep struct {
+	Step        string
+	Completed   bool
+	CompletedAt *time.Time
+}
+
+// Onboarding is the checklist of a user, Completed once every step is
+type Onboarding struct {
+	Steps     []OnboardingStep
+	Completed bool
+}
+
+// UserOnboardingService tracks the onboarding checklist of the users, so the
+// frontends embedding the library share one
+type UserOnboardingService struct {
+	store *db.Store
+}
+
+func NewUserOnboardingService(store *db.Store) *UserOnboardingService {
+	return &UserOnboardingService{store: store}
+}
+
+// detectedOnboardingSteps returns the steps the signals show done
+func detectedOnboardingSteps(signals repository.GetUserOnboardingSignalsRow) []string {
+	steps := []string{}
+	if signals.EmailVerified {
+		steps = append(steps, OnboardingStepEmailVerified)
+	}
+	if signals.FirstLoginAt.Valid {
+		steps = append(steps, OnboardingStepFirstLogin)
+	}
+	if signals.MfaEnabledAt.Valid {
+		steps = append(steps, OnboardingStepMFAEnrolled)
+	}
+	return steps
+}
+
+// GetOnboarding returns the checklist of the user, completing first the steps
+// detected since it was last read
+func (s *UserOnboardingService) GetOnboarding(ctx context.Context, userID string) (Onboarding, error) {
+	signals, err := s.store.GetUserOnboardingSignals(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Onboarding{}, ErrOnboardingUserUnknown
+	}
+	if err != nil {
+		return Onboarding{}, fmt.Errorf("service.GetOnboarding: %w", err)
+	}
+	if detected := detectedOnboardingSteps(signals); len(detected) > 0 {
+		if _, err := s.store.CompleteUserOnboardingSteps(ctx, repository.CompleteUserOnboardingStepsParams{
+			UserID: userID,
+			Steps:  detected,
+		}); err != nil {
+			return Onboarding{}, fmt.Errorf("service.GetOnboarding: %w", err)
+		}
+	}
+	return s.listOnboarding(ctx, userID)
+}
+
+// UpdateOnboarding marks the steps completed, true, or not, false, and
+// returns the checklist. A detected step marked not completed is completed
+// again when the checklist is read, if it is still done.
+func (s *UserOnboardingService) UpdateOnboarding(ctx context.Context, userID string, steps map[string]bool) (Onboarding, error) {
+	known := OnboardingSteps()
+	var completed, reset []string
+	for step, done := range steps {
+		if !slices.Contains(known, step) {
+			return Onboarding{}, fmt.Errorf("%w: %q, use one of %v", ErrInvalidOnboardingStep, step, known)
+		}
+		if done {
+			completed = append(completed, step)
+		} else {
+			reset = append(reset, step)
+		}
+	}
+	if _, err := s.store.GetSharedUserByID(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Onboarding{}, ErrOnboardingUserUnknown
+		}
+		return Onboarding{}, fmt.Errorf("service.UpdateOnboarding: %w", err)
+	}
+	if len(completed) > 0 {
+		if _, err := s.store.CompleteUserOnboardingSteps(ctx, repository.CompleteUserOnboardingStepsParams{
+			UserID: userID,
+			Steps:  completed,
+		}); err != nil {
+			return Onboarding{}, fmt.Errorf("service.UpdateOnboarding: %w", err)
+		}
+	}
+	if len(reset) > 0 {
+		if _, err := s.store.ResetUserOnboardingSteps(ctx, repository.ResetUserOnboardingStepsParams{
+			UserID: userID,
+			Steps:  reset,
+		}); err != nil {
+			return Onboarding{}, fmt.Errorf("service.UpdateOnboarding: %w", err)
+		}
+	}
+	logger := util.GetLoggerFromCtx(ctx)
+	logger.Info().Str("user_id", userID).Strs("completed", completed).Strs("reset", reset).Msg("Onboarding updated")
+	return s.listOnboarding(ctx, userID)
+}
+
+func (s *UserOnboardingService) listOnboarding(ctx context.Context, userID string) (Onboarding, error) {
+	rows, err := s.store.ListUserOnboardingSteps(ctx, userID)
+	if err != nil {
+		return Onboarding{}, fmt.Errorf("service.GetOnboarding: %w", err)
+	}
+	return buildOnboarding(OnboardingSteps(), rows), nil
+}
+
+// buildOnboarding lays the completed steps out on the checklist. The steps
+// completed but no longer registered are left out.
+func buildOnboarding(steps []string, rows []repository.CoreUserOnboardingStep) Onboarding {
+	onboarding := Onboarding{Steps: make([]OnboardingStep, len(steps)), Completed: true}
+	for i, step := range steps {
+		onboarding.Steps[i] = OnboardingStep{Step: step}
+		for _, row := range rows {
+			if row.Step == step {
+				completedAt := row.CompletedAt
+				onboarding.Steps[i].Completed = true
+				onboarding.Steps[i].CompletedAt = &completedAt
+			}
+		}
+		onboarding.Completed = onboarding.Completed && onboarding.Steps[i].Completed
+	}
+	return onboarding
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"ctoup.com/coreapp/pkg/core/db/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectedOnboardingSteps(t *testing.T) {
+	require.Empty(t, detectedOnboardingSteps(repository.GetUserOnboardingSignalsRow{}))
+	require.Equal(t,
+		[]string{OnboardingStepEmailVerified, OnboardingStepFirstLogin, OnboardingStepMFAEnrolled},
+		detectedOnboardingSteps(repository.GetUserOnboardingSignalsRow{
+			EmailVerified: true,
+			FirstLoginAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			MfaEnabledAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}))
+}
+
+func TestBuildOnboarding(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	steps := []string{OnboardingStepProfileCompleted, OnboardingStepFirstLogin}
+
+	onboarding := buildOnboarding(steps, []repository.CoreUserOnboardingStep{
+		{Step: OnboardingStepFirstLogin, CompletedAt: at},
+		// No longer registered
+		{Step: "tour_seen", CompletedAt: at},
+	})
+	require.False(t, onboarding.Completed)
+	require.Len(t, onboarding.Steps, 2)
+	require.Equal(t, OnboardingStep{Step: OnboardingStepProfileCompleted}, onboarding.Steps[0])
+	require.True(t, onboarding.Steps[1].Completed)
+	require.Equal(t, at, *onboarding.Steps[1].CompletedAt)
+
+	onboarding = buildOnboarding(steps, []repository.CoreUserOnboardingStep{
+		{Step: OnboardingStepFirstLogin, CompletedAt: at},
+		{Step: OnboardingStepProfileCompleted, CompletedAt: at},
+	})
+	require.True(t, onboarding.Completed)
+}
+
+func TestRegisterOnboardingStep(t *testing.T) {
+	RegisterOnboardingStep("tour_seen")
+	RegisterOnboardingStep("tour_seen")
+	t.Cleanup(func() {
+		onboardingSteps = onboardingSteps[:len(onboardingSteps)-1]
+	})
+	require.Equal(t, "tour_seen", OnboardingSteps()[len(OnboardingSteps())-1])
+	require.Len(t, OnboardingSteps(), 5)
+	require.Panics(t, func() { RegisterOnboardingStep("Tour Seen") })
+
+	_, err := NewUserOnboardingService(nil).UpdateOnboarding(t.Context(), "u1", map[string]bool{"unknown": true})
+	require.ErrorIs(t, err, ErrInvalidOnboardingStep)
+	require.True(t, isSelfServiceUserRoute(http.MethodPatch, "/api/v1/users/me/onboarding"))
+}
@@ -18,7 +18,8 @@ type UserService interface {
 	// Lifecycle
 	CreateUser(c context.Context, authClient auth.AuthClient, tenantId string, req core.NewUser, password *string) (repository.CoreUser, error)
 	UpdateUser(c *gin.Context, authClient auth.AuthClient, tenantId string, userId string, req core.UpdateUserJSONRequestBody) error
-	DeleteUser(c *gin.Context, authClient auth.AuthClient, userId string) error
+	DeleteUser(c *gin.Context, authClient auth.AuthClient, userId string, transfer OwnershipTransfer) error
+	DeleteUserFromTenant(c *gin.Context, authClient auth.AuthClient, tenantId string, userId string, transfer OwnershipTransfer) error
 	RemoveUserFromTenant(c *gin.Context, authClient auth.AuthClient, tenantId string, userId string) error
 
 	InitUserInDatabase(ctx context.Context, tenantId string, userID string) (repository.CoreUser, error)
@@ -29,11 +30,15 @@ type UserService interface {
 	GetUserByID(c context.Context, id string) (core.User, error)
 	GetUserByTenantIDByID(c *gin.Context, tenantID string, id string) (core.User, error)
 	GetUserByEmail(c *gin.Context, tenantId string, email string) (core.User, error)
-	ListUsers(c *gin.Context, tenantId string, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error)
+	ListUsers(c *gin.Context, tenantId string, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error)
 	// ListAllUsers lists every user system-wide, ignoring tenant scope. Intended
 	// for the admin (tenantless) domain so a super admin can find any user to
 	// promote to a global role. Returns global roles only.
-	ListAllUsers(c *gin.Context, pagingSql sqlservice.PagingSQL, like pgtype.Text) ([]core.User, error)
+	ListAllUsers(c *gin.Context, pagingSql sqlservice.PagingSQL, filter UserListFilter) ([]core.User, error)
+	// CountUsers and CountAllUsers count the users matching the filter of
+	// ListUsers and ListAllUsers, for the pagers
+	CountUsers(c *gin.Context, tenantId string, filter UserListFilter) (int64, error)
+	CountAllUsers(c *gin.Context, filter UserListFilter) (int64, error)
 
 	GetUserByEmailGlobal(c context.Context, email string) (*core.User, error)
 
@@ -77,7 +82,7 @@ func (uh *BaseUserService) GetUserByID(c context.Context, id string) (core.User,
 	user := core.User{
 		Id:    dbUser.ID,
 		Name:  dbUser.Profile.Name,
-		Email: dbUser.Email.String,
+		Email: DecryptEmail(dbUser.Email).String,
 		Profile: &core.UserProfileSchema{
 			Name:                 dbUser.Profile.Name,
 			Title:                &dbUser.Profile.Title,
@@ -88,19 +93,28 @@ func (uh *BaseUserService) GetUserByID(c context.Context, id string) (core.User,
 			Interests:            &dbUser.Profile.Interests,
 			Skills:               &dbUser.Profile.Skills,
 			PhoneNumber:          &dbUser.Profile.PhoneNumber,
+			PhoneNumberVerified:  &dbUser.Profile.PhoneNumberVerified,
 			Function:             &dbUser.Profile.Function,
 			Company:              &dbUser.Profile.Company,
+			Attributes:           profileAttributes(dbUser.Profile.Attributes),
 		},
 		Roles:     convertToRoleDTOs(dbUser.Roles),
 		CreatedAt: &dbUser.CreatedAt,
 	}
 
-	return user, err
+	users := []core.User{user}
+	if err := setLastLogins(c, uh.store, users, pgtype.Text{}); err != nil {
+		return core.User{}, err
+	}
+	return users[0], nil
 }
 
 // GetUserByEmailGlobal gets a user by email across all tenants
 func (uh *BaseUserService) GetUserByEmailGlobal(c context.Context, email string) (*core.User, error) {
-	userRow, err := uh.store.GetUserByEmailGlobal(c, email)
+	userRow, err := uh.store.GetUserByEmailGlobal(c, repository.GetUserByEmailGlobalParams{
+		Email:           email,
+		EmailBlindIndex: EmailBlindIndex(email),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +122,7 @@ func (uh *BaseUserService) GetUserByEmailGlobal(c context.Context, email string)
 	// Convert to core.User
 	user := &core.User{
 		Id:    userRow.ID,
-		Email: userRow.Email.String,
+		Email: DecryptEmail(userRow.Email).String,
 		Profile: &core.UserProfileSchema{
 			Name: userRow.Profile.Name,
 		},
@@ -299,3 +299,34 @@ func ExampleUsage() {
 		_ = tld
 	}
 }
+
+// URLWithSubdomain returns the URL of the request on another subdomain of the
+// same domain, keeping the scheme, port, path and query
+func URLWithSubdomain(c *gin.Context, subdomain string) (*url.URL, error) {
+	host, err := GetHost(c)
+	if err != nil {
+		return nil, err
+	}
+	hostname := strings.ToLower(strings.TrimSpace(host.Hostname()))
+	current, domain, tld := extractDomainParts(hostname)
+	if current == "" {
+		return nil, errors.New("no subdomain in host " + hostname)
+	}
+	fullDomain := domain
+	if tld != "" {
+		fullDomain = domain + "." + tld
+	}
+	// Labels in front of the tenant subdomain (e.g. "bo." in
+	// bo.corpa.cto.com) are kept
+	prefix := strings.TrimSuffix(hostname, current+"."+fullDomain)
+	target := prefix + subdomain + "." + fullDomain
+	if host.Port() != "" {
+		target = net.JoinHostPort(target, host.Port())
+	}
+	return &url.URL{
+		Scheme:   host.Scheme,
+		Host:     target,
+		Path:     c.Request.URL.Path,
+		RawQuery: c.Request.URL.RawQuery,
+	}, nil
+}
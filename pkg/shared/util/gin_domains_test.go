@@ -131,3 +131,37 @@ func TestGetDomainInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestURLWithSubdomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"standard subdomain", "http://old.example.com/api/v1/me?x=1", "http://new.example.com/api/v1/me?x=1"},
+		{"with port", "http://old.example.com:8080/", "http://new.example.com:8080/"},
+		{"country tld", "http://old.example.co.uk/login", "http://new.example.co.uk/login"},
+		{"nested subdomain", "http://bo.old.cto.com/", "http://bo.new.cto.com/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", test.url, nil)
+
+			target, err := URLWithSubdomain(c, "new")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if target.String() != test.expected {
+				t.Errorf("Expected URL %q, got %q", test.expected, target.String())
+			}
+		})
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "http://example.com/", nil)
+	if _, err := URLWithSubdomain(c, "new"); err == nil {
+		t.Error("Expected an error for a host without subdomain")
+	}
+}
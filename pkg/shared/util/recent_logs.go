@@ -0,0 +1,61 @@
+package util
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultRecentLogCapacity is the number of log lines kept by RecentLogs
+const DefaultRecentLogCapacity = 500
+
+// RecentLogs keeps the last warnings and errors of the process, for the
+// support bundle. The server only sees them once it is added to the writers
+// of the logger:
+//
+//	log.Logger = zerolog.New(zerolog.MultiLevelWriter(os.Stdout, util.RecentLogs))
+var RecentLogs = NewRecentLogBuffer(DefaultRecentLogCapacity)
+
+// RecentLogBuffer is a zerolog.LevelWriter keeping the last lines logged at
+// the warning level or above in a ring
+type RecentLogBuffer struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+}
+
+func NewRecentLogBuffer(capacity int) *RecentLogBuffer {
+	return &RecentLogBuffer{lines: make([][]byte, capacity)}
+}
+
+// Write drops the lines written without a level
+func (b *RecentLogBuffer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (b *RecentLogBuffer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.WarnLevel || level == zerolog.NoLevel || len(b.lines) == 0 {
+		return len(p), nil
+	}
+	// zerolog reuses its buffers once the write returns
+	line := append([]byte(nil), p...)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+	return len(p), nil
+}
+
+// Lines returns the lines kept, the oldest first
+func (b *RecentLogBuffer) Lines() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([][]byte(nil), b.lines[:b.next]...)
+	}
+	return append(append([][]byte(nil), b.lines[b.next:]...), b.lines[:b.next]...)
+}
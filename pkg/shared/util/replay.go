@@ -0,0 +1,135 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ReplayRecorderKey holds the *ReplayRecorder of a captured request
+const ReplayRecorderKey ContextKey = "replayRecorder"
+
+// maxReplayCalls bounds the calls kept per request, a loop over the database
+// must not grow the bundle without end. The logs are bounded the same way.
+const (
+	maxReplayCalls = 200
+	maxReplayLogs  = 50
+)
+
+// ReplayCall is a call made to a downstream dependency (database, auth
+// provider, mail server) while serving a captured request
+type ReplayCall struct {
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Status    int           `json:"status,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"durationNs"`
+	Rows      int64         `json:"rows,omitempty"`
+}
+
+// ReplayRecorder collects the downstream calls of a request. It is safe for
+// the goroutines a handler starts.
+type ReplayRecorder struct {
+	mu      sync.Mutex
+	calls   []ReplayCall
+	dropped int
+	logs    []string
+}
+
+func NewReplayRecorder() *ReplayRecorder {
+	return &ReplayRecorder{}
+}
+
+// Calls returns the recorded calls and the number of calls dropped over the
+// limit
+func (r *ReplayRecorder) Calls() ([]ReplayCall, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ReplayCall{}, r.calls...), r.dropped
+}
+
+func (r *ReplayRecorder) record(call ReplayCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.calls) >= maxReplayCalls {
+		r.dropped++
+		return
+	}
+	r.calls = append(r.calls, call)
+}
+
+// Logs returns the warnings and errors logged while serving the request
+func (r *ReplayRecorder) Logs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.logs...)
+}
+
+// Run implements zerolog.Hook, to keep the warnings and errors logged with the
+// request logger. The fields of the event cannot be read, only its message.
+func (r *ReplayRecorder) Run(_ *zerolog.Event, level zerolog.Level, message string) {
+	if level < zerolog.WarnLevel || level == zerolog.NoLevel {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.logs) < maxReplayLogs {
+		r.logs = append(r.logs, level.String()+": "+message)
+	}
+}
+
+// WithReplayRecorder returns a context recording the downstream calls made
+// with it
+func WithReplayRecorder(ctx context.Context, recorder *ReplayRecorder) context.Context {
+	return context.WithValue(ctx, ReplayRecorderKey, recorder)
+}
+
+// GetReplayRecorder returns the recorder of a captured request, nil otherwise
+func GetReplayRecorder(ctx context.Context) *ReplayRecorder {
+	recorder, _ := ctx.Value(ReplayRecorderKey).(*ReplayRecorder)
+	return recorder
+}
+
+// RecordReplayCall records a downstream call if the request is captured, and
+// does nothing otherwise
+func RecordReplayCall(ctx context.Context, call ReplayCall) {
+	if recorder := GetReplayRecorder(ctx); recorder != nil {
+		recorder.record(call)
+	}
+}
+
+// ReplayTransport records the HTTP calls made with the context of a captured
+// request. The query string is left out, it may carry credentials.
+type ReplayTransport struct {
+	Kind string
+	Base http.RoundTripper
+}
+
+func (t ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if GetReplayRecorder(req.Context()) == nil {
+		return base.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	call := ReplayCall{
+		Kind:      t.Kind,
+		Name:      req.Method + " " + req.URL.Host + req.URL.Path,
+		StartedAt: start,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		call.Error = err.Error()
+	} else {
+		call.Status = resp.StatusCode
+	}
+	RecordReplayCall(req.Context(), call)
+	return resp, err
+}
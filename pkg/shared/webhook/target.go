@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// targetResolveTimeout bounds the resolution of a target at validation
+const targetResolveTimeout = 5 * time.Second
+
+var (
+	// ErrInvalidTarget is returned for a webhook URL that is not an absolute
+	// http(s) URL
+	ErrInvalidTarget = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrTargetNotAllowed is returned by a public dispatcher for a URL that is
+	// not https or whose host resolves to an internal address
+	ErrTargetNotAllowed = errors.New("webhook URL must be an https URL of a public host")
+)
+
+// nonPublicPrefixes are the ranges a public dispatcher never connects to,
+// besides the loopback, private, link-local (169.254.169.254 is the metadata
+// address of most clouds) and unspecified addresses
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, the metadata address of some clouds
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("224.0.0.0/3"),   // multicast, reserved and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, any IPv4 address
+	netip.MustParsePrefix("2002::/16"),     // 6to4, any IPv4 address
+	netip.MustParsePrefix("fd00:ec2::/32"), // AWS metadata service over IPv6
+	netip.MustParsePrefix("ff00::/8"),      // multicast
+}
+
+// IsPublicAddr reports whether a webhook may be delivered to the address:
+// loopback, private, link-local, metadata and reserved addresses are not public
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateTarget checks that rawURL is an absolute http(s) URL. A public
+// dispatcher also requires https and a host that only resolves to public
+// addresses.
+func (d *Dispatcher) ValidateTarget(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return ErrInvalidTarget
+	}
+	if !d.publicOnly {
+		return nil
+	}
+	if parsed.Scheme != "https" {
+		return ErrTargetNotAllowed
+	}
+	ctx, cancel := context.WithTimeout(ctx, targetResolveTimeout)
+	defer cancel()
+	addrs, err := d.resolver.LookupNetIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %s cannot be resolved", ErrTargetNotAllowed, parsed.Hostname())
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrTargetNotAllowed, parsed.Hostname(), addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// NewPublicDispatcher creates a dispatcher for the URLs set by tenants: it
+// only delivers to https URLs of public hosts. The address of every
+// connection is checked as well, so a host re-pointed to an internal address
+// after validation, or a redirect to one, is refused.
+func NewPublicDispatcher() *Dispatcher {
+	d := NewDispatcher()
+	d.publicOnly = true
+	d.resolver = net.DefaultResolver
+	dialer := &net.Dialer{
+		Timeout: DefaultTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrTargetNotAllowed, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be the address checked, not the target
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	d.client = &http.Client{
+		Timeout:   DefaultTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return ErrTargetNotAllowed
+			}
+			if len(via) >= 5 {
+				return errors.New("stopped after 5 redirects")
+			}
+			return nil
+		},
+	}
+	return d
+}
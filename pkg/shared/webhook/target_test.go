@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	for _, addr := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.100.100.200",
+		"0.0.0.0", "255.255.255.255", "::1", "::", "fe80::1", "fc00::1", "fd00:ec2::254", "::ffff:127.0.0.1",
+	} {
+		assert.False(t, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::6810:85e5"} {
+		assert.True(t, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestPublicDispatcherValidateTarget(t *testing.T) {
+	d := NewPublicDispatcher()
+	ctx := context.Background()
+
+	require.ErrorIs(t, d.ValidateTarget(ctx, "ftp://example.com/hook"), ErrInvalidTarget)
+	require.ErrorIs(t, d.ValidateTarget(ctx, "/hook"), ErrInvalidTarget)
+	for _, rawURL := range []string{
+		"http://93.184.216.34/hook",
+		"https://127.0.0.1/hook",
+		"https://localhost:8443/hook",
+		"https://10.0.0.5/hook",
+		"https://192.168.1.1/hook",
+		"https://169.254.169.254/latest/meta-data/",
+		"https://[::1]/hook",
+		"https://[fd00:ec2::254]/hook",
+		"https://[::ffff:10.0.0.1]/hook",
+	} {
+		assert.ErrorIs(t, d.ValidateTarget(ctx, rawURL), ErrTargetNotAllowed, rawURL)
+	}
+	require.NoError(t, d.ValidateTarget(ctx, "https://93.184.216.34/hook"))
+
+	// Any absolute http(s) URL for the dispatchers of the operators
+	require.NoError(t, NewDispatcher().ValidateTarget(ctx, "http://127.0.0.1/hook"))
+}
+
+func TestPublicDispatcherRefusesInternalAddresses(t *testing.T) {
+	var calls int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d := NewPublicDispatcher().WithRetryPolicy(3, time.Millisecond)
+	err := d.Deliver(context.Background(), Target{URL: server.URL}, Envelope{ID: "1", EventType: "test"}, "")
+	require.ErrorIs(t, err, ErrTargetNotAllowed)
+
+	// The connection is refused as well, for a host resolving to a public
+	// address at validation and to an internal one when delivering
+	retry, err := d.send(context.Background(), Target{URL: server.URL}, "test", []byte("{}"))
+	require.ErrorIs(t, err, ErrTargetNotAllowed)
+	assert.False(t, retry)
+	assert.Zero(t, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ctoup.com/coreapp/pkg/shared/util"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the unix timestamp used in the signature
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader carries the event type of the delivery
+	EventHeader = "X-Webhook-Event"
+
+	SignaturePrefix = "sha256="
+
+	DefaultMaxAttempts     = 5
+	DefaultInitialBackoff  = 2 * time.Second
+	DefaultTimeout         = 10 * time.Second
+	DefaultMaxPayloadBytes = 256 * 1024
+)
+
+// Envelope is the JSON body POSTed to a webhook endpoint
+type Envelope struct {
+	ID        string      `json:"id"`
+	EventType string      `json:"eventType"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data,omitempty"`
+	// Reference is set instead of Data when the payload exceeds MaxPayloadBytes
+	Reference string `json:"reference,omitempty"`
+}
+
+// Target describes where and how a delivery must be sent
+type Target struct {
+	URL    string
+	Secret string
+}
+
+// Dispatcher delivers signed webhook envelopes with retries
+type Dispatcher struct {
+	client          *http.Client
+	maxAttempts     int
+	initialBackoff  time.Duration
+	maxPayloadBytes int
+	// publicOnly restricts the targets to https URLs of public hosts
+	publicOnly bool
+	resolver   *net.Resolver
+}
+
+// NewDispatcher creates a dispatcher with the default retry policy
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		client:          &http.Client{Timeout: DefaultTimeout},
+		maxAttempts:     DefaultMaxAttempts,
+		initialBackoff:  DefaultInitialBackoff,
+		maxPayloadBytes: DefaultMaxPayloadBytes,
+	}
+}
+
+// WithRetryPolicy overrides the number of attempts and the initial backoff
+func (d *Dispatcher) WithRetryPolicy(maxAttempts int, initialBackoff time.Duration) *Dispatcher {
+	if maxAttempts > 0 {
+		d.maxAttempts = maxAttempts
+	}
+	d.initialBackoff = initialBackoff
+	return d
+}
+
+// Sign computes the signature sent in SignatureHeader
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received signature in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	expected := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// Deliver POSTs the envelope to the target, retrying with exponential backoff
+// on network errors and 5xx/429 responses. When the encoded envelope is larger
+// than the payload limit and a reference is provided, the data is replaced by
+// the reference so the receiver can fetch it.
+func (d *Dispatcher) Deliver(ctx context.Context, target Target, envelope Envelope, reference string) error {
+	logger := util.GetLoggerFromCtx(ctx)
+	if d.publicOnly {
+		if err := d.ValidateTarget(ctx, target.URL); err != nil {
+			return fmt.Errorf("webhook.Deliver: %w", err)
+		}
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("webhook.Deliver: %w", err)
+	}
+	if len(body) > d.maxPayloadBytes && reference != "" {
+		envelope.Data = nil
+		envelope.Reference = reference
+		if body, err = json.Marshal(envelope); err != nil {
+			return fmt.Errorf("webhook.Deliver: %w", err)
+		}
+	}
+
+	backoff := d.initialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		retry, err := d.send(ctx, target, envelope.EventType, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		logger.Warn().Err(err).
+			Str("event_id", envelope.ID).
+			Str("url", target.URL).
+			Int("attempt", attempt).
+			Msg("Webhook delivery failed")
+		if !retry || attempt == d.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook.Deliver: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("webhook.Deliver: %w", lastErr)
+}
+
+// send performs a single delivery attempt and reports whether it is worth retrying
+func (d *Dispatcher) send(ctx context.Context, target Target, eventType string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	if target.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(target.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return !errors.Is(err, ErrTargetNotAllowed), err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}